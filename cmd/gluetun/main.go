@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"os/user"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -15,6 +19,8 @@ import (
 	_ "github.com/breml/rootcerts"
 	"github.com/qdm12/dns/pkg/unbound"
 	"github.com/qdm12/gluetun/internal/alpine"
+	"github.com/qdm12/gluetun/internal/bandwidth"
+	"github.com/qdm12/gluetun/internal/capabilities"
 	"github.com/qdm12/gluetun/internal/cli"
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/configuration/sources/env"
@@ -22,28 +28,52 @@ import (
 	mux "github.com/qdm12/gluetun/internal/configuration/sources/merge"
 	"github.com/qdm12/gluetun/internal/configuration/sources/secrets"
 	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/credentials"
+	"github.com/qdm12/gluetun/internal/ddns"
 	"github.com/qdm12/gluetun/internal/dns"
+	"github.com/qdm12/gluetun/internal/docker"
+	"github.com/qdm12/gluetun/internal/droplog"
+	"github.com/qdm12/gluetun/internal/ebpf"
+	"github.com/qdm12/gluetun/internal/events"
+	"github.com/qdm12/gluetun/internal/failover"
 	"github.com/qdm12/gluetun/internal/firewall"
+	"github.com/qdm12/gluetun/internal/geoip"
 	"github.com/qdm12/gluetun/internal/healthcheck"
+	"github.com/qdm12/gluetun/internal/hooks"
 	"github.com/qdm12/gluetun/internal/httpproxy"
 	"github.com/qdm12/gluetun/internal/models"
 	"github.com/qdm12/gluetun/internal/netlink"
+	"github.com/qdm12/gluetun/internal/notification"
 	"github.com/qdm12/gluetun/internal/openvpn"
 	"github.com/qdm12/gluetun/internal/openvpn/extract"
+	"github.com/qdm12/gluetun/internal/ownership"
 	"github.com/qdm12/gluetun/internal/portforward"
 	"github.com/qdm12/gluetun/internal/pprof"
 	"github.com/qdm12/gluetun/internal/provider"
 	"github.com/qdm12/gluetun/internal/publicip"
 	"github.com/qdm12/gluetun/internal/publicip/ipinfo"
+	"github.com/qdm12/gluetun/internal/quota"
 	"github.com/qdm12/gluetun/internal/routing"
+	"github.com/qdm12/gluetun/internal/runtimestate"
+	"github.com/qdm12/gluetun/internal/scheduler"
+	"github.com/qdm12/gluetun/internal/securedns"
 	"github.com/qdm12/gluetun/internal/server"
+	"github.com/qdm12/gluetun/internal/serverstats"
 	"github.com/qdm12/gluetun/internal/shadowsocks"
+	"github.com/qdm12/gluetun/internal/socks5"
+	"github.com/qdm12/gluetun/internal/splittunnel"
 	"github.com/qdm12/gluetun/internal/storage"
+	"github.com/qdm12/gluetun/internal/systemd"
+	"github.com/qdm12/gluetun/internal/tlscert"
 	"github.com/qdm12/gluetun/internal/tun"
+	"github.com/qdm12/gluetun/internal/tunnels"
+	"github.com/qdm12/gluetun/internal/updater/httpclient"
 	updater "github.com/qdm12/gluetun/internal/updater/loop"
 	"github.com/qdm12/gluetun/internal/updater/resolver"
 	"github.com/qdm12/gluetun/internal/updater/unzip"
 	"github.com/qdm12/gluetun/internal/vpn"
+	"github.com/qdm12/gluetun/internal/vpnoutput"
+	"github.com/qdm12/gluetun/internal/wgserver"
 	"github.com/qdm12/golibs/command"
 	"github.com/qdm12/goshutdown"
 	"github.com/qdm12/goshutdown/goroutine"
@@ -134,7 +164,8 @@ func main() {
 }
 
 var (
-	errCommandUnknown = errors.New("command is unknown")
+	errCommandUnknown    = errors.New("command is unknown")
+	errCapabilityMissing = errors.New("capability is missing")
 )
 
 //nolint:gocognit,gocyclo,maintidx
@@ -142,10 +173,19 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 	args []string, logger log.LoggerInterface, source Source,
 	tun Tun, netLinker netLinker, cmder command.RunStarter,
 	cli clier) error {
+	configFilePath, loaded, err := env.LoadConfigFile()
+	if err != nil {
+		return fmt.Errorf("loading configuration file %s: %w", configFilePath, err)
+	} else if loaded {
+		logger.Info("loaded configuration file " + configFilePath)
+	}
+
 	if len(args) > 1 { // cli operation
 		switch args[1] {
 		case "healthcheck":
 			return cli.HealthCheck(ctx, source, logger)
+		case "readiness":
+			return cli.Readiness(ctx, source)
 		case "clientkey":
 			return cli.ClientKey(args[2:])
 		case "openvpnconfig":
@@ -154,6 +194,10 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 			return cli.Update(ctx, args[2:], logger)
 		case "format-servers":
 			return cli.FormatServers(args[2:])
+		case "servers":
+			return cli.Servers(ctx, args[2:], source)
+		case "wireguard":
+			return cli.Wireguard(ctx, args[2:])
 		default:
 			return fmt.Errorf("%w: %s", errCommandUnknown, args[1])
 		}
@@ -192,6 +236,24 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 	logger.Patch(log.SetLevel(*allSettings.Log.Level))
 	netLinker.PatchLoggerLevel(*allSettings.Log.Level)
 
+	nonRoot := os.Geteuid() != 0
+	if nonRoot {
+		err = raiseCapabilities(logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	ipv6Supported, err := netLinker.IsIPv6Supported()
+	if err != nil {
+		return fmt.Errorf("checking for IPv6 support: %w", err)
+	}
+
+	err = registerCloudflareWARP(ctx, &allSettings.VPN, ipv6Supported, logger)
+	if err != nil {
+		return err
+	}
+
 	routingLogger := logger.New(log.SetComponent("routing"))
 	if *allSettings.Firewall.Debug { // To remove in v4
 		routingLogger.Patch(log.SetLevel(log.LevelDebug))
@@ -213,7 +275,80 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 		firewallLogger.Patch(log.SetLevel(log.LevelDebug))
 	}
 	firewallConf, err := firewall.NewConfig(ctx, firewallLogger, cmder,
-		defaultRoutes, localNetworks)
+		allSettings.Firewall.Backend, defaultRoutes, localNetworks,
+		allSettings.Firewall.StartupPolicy != "lan")
+	if err != nil {
+		return err
+	}
+
+	err = firewallConf.SetRulesTemplateFile(ctx, *allSettings.Firewall.RulesTemplateFile)
+	if err != nil {
+		return err
+	}
+
+	portForwardTargetIP, portForwardTargetPort := allSettings.VPN.Provider.PortForwarding.TargetAddress()
+	err = firewallConf.SetPortForwardTarget(ctx, portForwardTargetIP, portForwardTargetPort)
+	if err != nil {
+		return err
+	}
+
+	if *allSettings.Firewall.LogDropped {
+		firewallConf.SetDropLogGroup(droplog.NFLOGGroup)
+	}
+
+	if allSettings.Firewall.StartupPolicy == "permissive" {
+		firewallConf.SetStartupPermissive(*allSettings.Firewall.StartupDuration)
+	}
+
+	firewallConf.SetBypassMark(*allSettings.Firewall.BypassMark, allSettings.Firewall.BypassCgroups)
+	routingConf.SetBypassMark(*allSettings.Firewall.BypassMark)
+	firewallConf.SetVPNRoutes(allSettings.VPN.Routes)
+
+	var gatewayInterfaces []string
+	if gatewayInterface := *allSettings.Firewall.GatewayInterface; gatewayInterface != "" {
+		gatewayInterfaces = append(gatewayInterfaces, gatewayInterface)
+	}
+	if *allSettings.WireguardServer.Enabled {
+		gatewayInterfaces = append(gatewayInterfaces, allSettings.WireguardServer.Interface)
+	}
+	if len(gatewayInterfaces) > 0 {
+		firewallConf.SetGatewayInterfaces(gatewayInterfaces)
+		err = routing.EnableIPv4Forwarding()
+		if err != nil {
+			logger.Warn(err.Error())
+			logger.Warn("💡 Tip: run gluetun with the sysctl net.ipv4.ip_forward=1 to use it as gateway")
+		}
+	}
+
+	firewallTunnels := make([]firewall.Tunnel, len(allSettings.VPN.Tunnels))
+	for i, tunnel := range allSettings.VPN.Tunnels {
+		firewallTunnels[i] = firewall.Tunnel{
+			Interface:   tunnel.Interface,
+			Endpoint:    *tunnel.Endpoint,
+			SourcePorts: tunnel.SourcePorts,
+			Mark:        tunnel.FirewallMark,
+		}
+	}
+	firewallConf.SetTunnels(firewallTunnels)
+
+	_, httpProxyPortString, err := net.SplitHostPort(allSettings.HTTPProxy.ListeningAddress)
+	if err != nil {
+		return fmt.Errorf("splitting HTTP proxy listening address: %w", err)
+	}
+	const base, bitSize = 10, 16
+	httpProxyPort, err := strconv.ParseUint(httpProxyPortString, base, bitSize)
+	if err != nil {
+		return fmt.Errorf("parsing HTTP proxy listening port: %w", err)
+	}
+	err = firewallConf.SetSourcePolicies(*allSettings.Firewall.LANSource,
+		*allSettings.Firewall.HTTPProxySource, uint16(httpProxyPort))
+	if err != nil {
+		return err
+	}
+
+	hooksRunner := hooks.New(allSettings.Hooks, cmder,
+		logger.New(log.SetComponent("hooks")))
+	err = hooksRunner.Run(ctx, constants.HookPreFirewall)
 	if err != nil {
 		return err
 	}
@@ -225,6 +360,18 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 		}
 	}
 
+	var egressFilter *ebpf.Filter
+	if *allSettings.Firewall.EBPF || allSettings.Firewall.Backend == "ebpf" {
+		egressFilter, err = ebpf.New(netLinker, logger.New(log.SetComponent("ebpf")))
+		if err != nil {
+			return fmt.Errorf("creating eBPF egress filter: %w", err)
+		}
+		err = firewallConf.SetEgressFilter(egressFilter)
+		if err != nil {
+			return err
+		}
+	}
+
 	// TODO run this in a loop or in openvpn to reload from file without restarting
 	storageLogger := logger.New(log.SetComponent("storage"))
 	storage, err := storage.New(storageLogger, constants.ServersData)
@@ -232,9 +379,9 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 		return err
 	}
 
-	ipv6Supported, err := netLinker.IsIPv6Supported()
+	providerDefinition, err := storage.LoadProviderDefinition(*allSettings.VPN.Provider.DefinitionFile)
 	if err != nil {
-		return fmt.Errorf("checking for IPv6 support: %w", err)
+		return err
 	}
 
 	err = allSettings.Validate(storage, ipv6Supported)
@@ -249,6 +396,7 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 	}
 
 	puid, pgid := int(*allSettings.System.PUID), int(*allSettings.System.PGID)
+	filesOwner := ownership.NewOwner(allSettings.System.FilesUID, allSettings.System.FilesGID)
 
 	const clientTimeout = 15 * time.Second
 	httpClient := &http.Client{Timeout: clientTimeout}
@@ -262,7 +410,8 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 	dnsConf := unbound.NewConfigurator(nil, cmder, dnsCrypto,
 		"/etc/unbound", "/usr/sbin/unbound", cacertsPath)
 
-	err = printVersions(ctx, logger, []printVersionElement{
+	hostMode := *allSettings.System.HostMode
+	err = printVersions(ctx, logger, hostMode, []printVersionElement{
 		{name: "Alpine", getVersion: alpineConf.Version},
 		{name: "OpenVPN 2.4", getVersion: ovpnConf.Version24},
 		{name: "OpenVPN 2.5", getVersion: ovpnConf.Version25},
@@ -288,21 +437,73 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 		return err
 	}
 
-	const defaultUsername = "nonrootuser"
-	nonRootUsername, err := alpineConf.CreateUser(defaultUsername, puid)
-	if err != nil {
-		return fmt.Errorf("creating user: %w", err)
-	}
-	if nonRootUsername != defaultUsername {
-		logger.Info("using existing username " + nonRootUsername + " corresponding to user id " + fmt.Sprint(puid))
+	var nonRootUsername string
+	if nonRoot {
+		// Already running as a non-root user, which cannot be changed
+		// without CAP_SETUID, and /etc/passwd is not writable.
+		nonRootUsername, err = lookupUsername(os.Geteuid())
+		if err != nil {
+			return fmt.Errorf("finding current user: %w", err)
+		}
+		puid, pgid = os.Geteuid(), os.Getegid()
+		// The files it writes are already owned by it.
+		filesOwner = nil
+		logger.Info("running as non-root user " + nonRootUsername)
+	} else if hostMode {
+		// Do not modify the host /etc/passwd file.
+		nonRootUsername, err = lookupUsername(puid)
+		if err != nil {
+			return fmt.Errorf("finding user for PUID %d on host: %w", puid, err)
+		}
+		logger.Info("using host username " + nonRootUsername + " corresponding to user id " + fmt.Sprint(puid))
+	} else {
+		const defaultUsername = "nonrootuser"
+		nonRootUsername, err = alpineConf.CreateUser(defaultUsername, puid)
+		if err != nil {
+			return fmt.Errorf("creating user: %w", err)
+		}
+		if nonRootUsername != defaultUsername {
+			logger.Info("using existing username " + nonRootUsername + " corresponding to user id " + fmt.Sprint(puid))
+		}
 	}
 	// set it for Unbound
 	// TODO remove this when migrating to qdm12/dns v2
 	allSettings.DNS.DoT.Unbound.Username = nonRootUsername
 	allSettings.VPN.OpenVPN.ProcessUser = nonRootUsername
+	if nonRoot {
+		// OpenVPN already runs as the current user, and its `user`
+		// option would fail to drop privileges without CAP_SETUID.
+		allSettings.VPN.OpenVPN.ProcessUser = "root"
+	}
 
-	if err := os.Chown("/etc/unbound", puid, pgid); err != nil {
-		return err
+	// A non-root user cannot change the ownership of /etc/unbound,
+	// so it must already be writable by that user.
+	if !nonRoot {
+		if err := os.Chown("/etc/unbound", puid, pgid); err != nil {
+			if !hostMode || !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			// Unbound may not be installed on the host.
+			logger.Warn("cannot change ownership of /etc/unbound: " + err.Error())
+		}
+	}
+
+	// The forwarded port and public IP files are owned by PUID
+	// and PGID unless the files owner is set.
+	filesUID, filesGID := puid, pgid
+	if filesOwner != nil {
+		filesUID, filesGID = filesOwner.UID, filesOwner.GID
+		storage.SetFilesOwner(filesOwner)
+	}
+
+	if filesOwner != nil && *allSettings.System.FixOwnership {
+		changed, err := ownership.Fix("/gluetun", os.Geteuid(), *filesOwner)
+		if err != nil {
+			logger.Warn("cannot fix ownership of files in /gluetun: " + err.Error())
+		} else if changed > 0 {
+			logger.Info(fmt.Sprintf("changed ownership of %d paths in /gluetun to %d:%d",
+				changed, filesUID, filesGID))
+		}
 	}
 
 	if err := routingConf.Setup(); err != nil {
@@ -325,11 +526,69 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 		return err
 	}
 
+	if *allSettings.DNS.LAN.Enabled {
+		err = firewallConf.SetDNSInputSubnets(ctx, allSettings.DNS.LAN.Subnets)
+		if err != nil {
+			return err
+		}
+	}
+
+	var vpnOutputDNSServers []net.IP
+	if len(allSettings.Firewall.VPNOutputDomains) > 0 {
+		vpnOutputDNSServers, err = allSettings.DNS.ServerIPs()
+		if err != nil {
+			return fmt.Errorf("getting DNS server IP addresses: %w", err)
+		}
+		// Only allow DNS traffic through the VPN interface
+		// until the domains are resolved.
+		err = firewallConf.RestrictVPNOutputIPs(ctx, nil, vpnOutputDNSServers)
+		if err != nil {
+			return err
+		}
+	}
+
+	countries := allSettings.Firewall.BlockedCountries
+	countriesAllowed := len(allSettings.Firewall.AllowedCountries) > 0
+	if countriesAllowed {
+		countries = allSettings.Firewall.AllowedCountries
+	}
+	if len(countries) > 0 {
+		geoIPDatabase, err := geoip.Open(*allSettings.Firewall.GeoIPDatabase)
+		if err != nil {
+			return fmt.Errorf("opening GeoIP database: %w", err)
+		}
+		countryNetworks, err := geoIPDatabase.CountryNetworks(countries)
+		if err != nil {
+			return fmt.Errorf("finding country networks: %w", err)
+		}
+		logger.Info(fmt.Sprintf("found %d networks for countries %s",
+			len(countryNetworks), strings.Join(countries, ", ")))
+		dnsServers, err := allSettings.DNS.ServerIPs()
+		if err != nil {
+			return fmt.Errorf("getting DNS server IP addresses: %w", err)
+		}
+		err = firewallConf.SetCountryNetworks(ctx, countryNetworks, countriesAllowed, dnsServers)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = routingConf.AddLocalRules(localNetworks)
 	if err != nil {
 		return fmt.Errorf("adding local rules: %w", err)
 	}
 
+	if *allSettings.WireguardServer.Enabled {
+		// Replies to the Wireguard server peers must be routed through
+		// the server interface using the main table, and not the VPN.
+		address := allSettings.WireguardServer.Address
+		serverNetwork := &net.IPNet{IP: address.IP.Mask(address.Mask), Mask: address.Mask}
+		err = routingConf.AddLocalRules([]routing.LocalNetwork{{IPNet: serverNetwork}})
+		if err != nil {
+			return fmt.Errorf("adding Wireguard server local rule: %w", err)
+		}
+	}
+
 	const tunDevice = "/dev/net/tun"
 	if err := tun.Check(tunDevice); err != nil {
 		logger.Info(err.Error() + "; creating it...")
@@ -365,6 +624,13 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 	tickersGroupHandler := goshutdown.NewGroupHandler("tickers", defaultGroupOptions...)
 	otherGroupHandler := goshutdown.NewGroupHandler("other", defaultGroupOptions...)
 
+	if egressFilter != nil {
+		egressFilterHandler, egressFilterCtx, egressFilterDone := goshutdown.NewGoRoutineHandler(
+			"ebpf egress filter", goroutine.OptionTimeout(defaultShutdownTimeout))
+		go egressFilter.Run(egressFilterCtx, egressFilterDone)
+		otherGroupHandler.Add(egressFilterHandler)
+	}
+
 	if *allSettings.Pprof.Enabled {
 		// TODO run in run loop so this can be patched at runtime
 		pprofReady := make(chan struct{})
@@ -374,16 +640,27 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 		<-pprofReady
 	}
 
+	notifier := notification.New(allSettings.Notification, httpClient,
+		logger.New(log.SetComponent("notification")))
+
 	portForwardLogger := logger.New(log.SetComponent("port forwarding"))
 	portForwardLooper := portforward.NewLoop(allSettings.VPN.Provider.PortForwarding,
-		httpClient, firewallConf, portForwardLogger, puid, pgid)
+		httpClient, firewallConf, notifier, portForwardLogger, filesUID, filesGID)
 	portForwardHandler, portForwardCtx, portForwardDone := goshutdown.NewGoRoutineHandler(
 		"port forwarding", goroutine.OptionTimeout(time.Second))
 	go portForwardLooper.Run(portForwardCtx, portForwardDone)
 
+	splitTunnelBypass := splittunnel.New(allSettings.Firewall,
+		firewallConf, routingConf, logger.New(log.SetComponent("split tunnel")))
+	splitTunnelHandler, splitTunnelCtx, splitTunnelDone := goshutdown.NewGoRoutineHandler(
+		"split tunnel", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go splitTunnelBypass.Run(splitTunnelCtx, splitTunnelDone)
+	tickersGroupHandler.Add(splitTunnelHandler)
+
 	unboundLogger := logger.New(log.SetComponent("dns over tls"))
-	unboundLooper := dns.NewLoop(dnsConf, allSettings.DNS, httpClient,
-		unboundLogger)
+	unboundLooper := dns.NewLoop(dnsConf, allSettings.DNS, ipv6Supported,
+		httpClient, unboundLogger)
+	unboundLooper.SetBypassDomains(allSettings.Firewall.BypassDomains, splitTunnelBypass)
 	dnsHandler, dnsCtx, dnsDone := goshutdown.NewGoRoutineHandler(
 		"unbound", goroutine.OptionTimeout(defaultShutdownTimeout))
 	// wait for unboundLooper.Restart or its ticker launched with RunRestartTicker
@@ -395,10 +672,31 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 	go unboundLooper.RunRestartTicker(dnsTickerCtx, dnsTickerDone)
 	controlGroupHandler.Add(dnsTickerHandler)
 
+	dnsFilesHandler, dnsFilesCtx, dnsFilesDone := goshutdown.NewGoRoutineHandler(
+		"dns files", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go unboundLooper.RunFilesWatcher(dnsFilesCtx, dnsFilesDone)
+	controlGroupHandler.Add(dnsFilesHandler)
+
+	defaultInterfaces := make([]string, len(defaultRoutes))
+	for i, defaultRoute := range defaultRoutes {
+		defaultInterfaces[i] = defaultRoute.NetInterface
+	}
+	dockerPolicies, err := docker.NewPolicies(allSettings.DockerLabels,
+		firewallConf, routingConf, unboundLooper, defaultInterfaces,
+		allSettings.Firewall.InputPorts, allSettings.Firewall.OutboundSubnets,
+		logger.New(log.SetComponent("docker labels")))
+	if err != nil {
+		return err
+	}
+	dockerPoliciesHandler, dockerPoliciesCtx, dockerPoliciesDone := goshutdown.NewGoRoutineHandler(
+		"docker labels", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go dockerPolicies.Run(dockerPoliciesCtx, dockerPoliciesDone)
+	otherGroupHandler.Add(dockerPoliciesHandler)
+
 	ipFetcher := ipinfo.New(httpClient)
 	publicIPLooper := publicip.NewLoop(ipFetcher,
 		logger.New(log.SetComponent("ip getter")),
-		allSettings.PublicIP, puid, pgid)
+		allSettings.PublicIP, filesUID, filesGID)
 	pubIPHandler, pubIPCtx, pubIPDone := goshutdown.NewGoRoutineHandler(
 		"public IP", goroutine.OptionTimeout(defaultShutdownTimeout))
 	go publicIPLooper.Run(pubIPCtx, pubIPDone)
@@ -409,25 +707,88 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 	go publicIPLooper.RunRestartTicker(pubIPTickerCtx, pubIPTickerDone)
 	tickersGroupHandler.Add(pubIPTickerHandler)
 
+	ddnsUpdater := ddns.New(allSettings.DDNS, httpClient, publicIPLooper,
+		logger.New(log.SetComponent("ddns")))
+	ddnsHandler, ddnsCtx, ddnsDone := goshutdown.NewGoRoutineHandler(
+		"ddns", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go ddnsUpdater.Run(ddnsCtx, ddnsDone)
+	tickersGroupHandler.Add(ddnsHandler)
+
+	vpnOutputAllowlist := vpnoutput.New(allSettings.Firewall, vpnOutputDNSServers, net.DefaultResolver,
+		firewallConf, logger.New(log.SetComponent("vpn output")))
+	vpnOutputHandler, vpnOutputCtx, vpnOutputDone := goshutdown.NewGoRoutineHandler(
+		"vpn output", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go vpnOutputAllowlist.Run(vpnOutputCtx, vpnOutputDone)
+	tickersGroupHandler.Add(vpnOutputHandler)
+
+	droppedPacketsMonitor := droplog.New(allSettings.Firewall,
+		logger.New(log.SetComponent("dropped packets")))
+	droppedPacketsHandler, droppedPacketsCtx, droppedPacketsDone := goshutdown.NewGoRoutineHandler(
+		"dropped packets", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go droppedPacketsMonitor.Run(droppedPacketsCtx, droppedPacketsDone)
+	otherGroupHandler.Add(droppedPacketsHandler)
+
 	updaterLogger := logger.New(log.SetComponent("updater"))
 
-	unzipper := unzip.New(httpClient)
+	updaterHTTPClient := httpclient.New(clientTimeout)
+	unzipper := unzip.New(updaterHTTPClient)
 	parallelResolver := resolver.NewParallelResolver(allSettings.Updater.DNSAddress)
 	openvpnFileExtractor := extract.New()
-	providers := provider.NewProviders(storage, time.Now, updaterLogger,
-		httpClient, unzipper, parallelResolver, ipFetcher, openvpnFileExtractor)
+	serverStats := serverstats.New(*allSettings.ServerCooldown.Period,
+		logger.New(log.SetComponent("server stats")))
+	providersStorage := serverstats.NewStorage(storage, serverStats)
+	providers := provider.NewProviders(providersStorage, time.Now, updaterLogger,
+		updaterHTTPClient, unzipper, parallelResolver, ipFetcher, openvpnFileExtractor,
+		providerDefinition, *allSettings.VPN.Provider.PluginFile)
+
+	runtimeState := runtimestate.New(allSettings.RuntimeState, storage,
+		publicIPLooper, portForwardLooper, logger.New(log.SetComponent("runtime state")))
+	runtimeState.Load()
+	portForwardLooper.RestoreRuntimeState(runtimeState.PreviousPortForwarded(), runtimeState)
+	runtimeStateHandler, runtimeStateCtx, runtimeStateDone := goshutdown.NewGoRoutineHandler(
+		"runtime state", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go runtimeState.Run(runtimeStateCtx, runtimeStateDone)
+	otherGroupHandler.Add(runtimeStateHandler)
+	vpnProviders := runtimeState.WrapProviders(providers)
 
 	vpnLogger := logger.New(log.SetComponent("vpn"))
+	dockerDependents, err := docker.NewDependents(allSettings.Docker,
+		logger.New(log.SetComponent("docker")))
+	if err != nil {
+		return err
+	}
+
 	vpnLooper := vpn.NewLoop(allSettings.VPN, ipv6Supported, allSettings.Firewall.VPNInputPorts,
-		providers, storage, ovpnConf, netLinker, firewallConf, routingConf, portForwardLooper,
-		cmder, publicIPLooper, unboundLooper, vpnLogger, httpClient,
-		buildInfo, *allSettings.Version.Enabled)
+		vpnProviders, storage, ovpnConf, netLinker, firewallConf, routingConf, portForwardLooper,
+		cmder, publicIPLooper, unboundLooper, dockerDependents, hooksRunner, notifier, serverStats,
+		vpnLogger, httpClient, buildInfo, *allSettings.Version.Enabled)
 	vpnHandler, vpnCtx, vpnDone := goshutdown.NewGoRoutineHandler(
 		"vpn", goroutine.OptionTimeout(time.Second))
 	go vpnLooper.Run(vpnCtx, vpnDone)
 
+	credentialsWatcher := credentials.NewWatcher(secrets.New(), vpnLooper,
+		storage, ipv6Supported, *allSettings.Secrets.Period,
+		logger.New(log.SetComponent("secrets watch")))
+	credentialsHandler, credentialsCtx, credentialsDone := goshutdown.NewGoRoutineHandler(
+		"secrets watch", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go credentialsWatcher.Run(credentialsCtx, credentialsDone)
+	tickersGroupHandler.Add(credentialsHandler)
+
+	quotaTracker := quota.New(allSettings.Quota, vpnLooper, firewallConf,
+		cmder, httpClient, logger.New(log.SetComponent("quota")))
+	quotaHandler, quotaCtx, quotaDone := goshutdown.NewGoRoutineHandler(
+		"quota", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go quotaTracker.Run(quotaCtx, quotaDone)
+	tickersGroupHandler.Add(quotaHandler)
+
+	bandwidthSampler := bandwidth.New(allSettings.Bandwidth, vpnLooper)
+	bandwidthHandler, bandwidthCtx, bandwidthDone := goshutdown.NewGoRoutineHandler(
+		"bandwidth", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go bandwidthSampler.Run(bandwidthCtx, bandwidthDone)
+	tickersGroupHandler.Add(bandwidthHandler)
+
 	updaterLooper := updater.NewLoop(allSettings.Updater,
-		providers, storage, httpClient, updaterLogger)
+		providers, storage, updaterHTTPClient, updaterLogger)
 	updaterHandler, updaterCtx, updaterDone := goshutdown.NewGoRoutineHandler(
 		"updater", goroutine.OptionTimeout(defaultShutdownTimeout))
 	// wait for updaterLooper.Restart() or its ticket launched with RunRestartTicker
@@ -447,6 +808,42 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 	go httpProxyLooper.Run(httpProxyCtx, httpProxyDone)
 	otherGroupHandler.Add(httpProxyHandler)
 
+	socks5Looper := socks5.NewLoop(logger.New(log.SetComponent("socks5 proxy")),
+		allSettings.SOCKS5, firewallConf, defaultInterfaces)
+	socks5Handler, socks5Ctx, socks5Done := goshutdown.NewGoRoutineHandler(
+		"socks5 proxy", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go socks5Looper.Run(socks5Ctx, socks5Done)
+	otherGroupHandler.Add(socks5Handler)
+
+	if *allSettings.DNS.SecureServer.Enabled {
+		secureDNSServer := securedns.New(allSettings.DNS.SecureServer,
+			net.JoinHostPort(allSettings.DNS.ServerAddress.String(), "53"),
+			firewallConf, defaultInterfaces, logger.New(log.SetComponent("secure dns server")))
+		secureDNSHandler, secureDNSCtx, secureDNSDone := goshutdown.NewGoRoutineHandler(
+			"secure dns server", goroutine.OptionTimeout(defaultShutdownTimeout))
+		go secureDNSServer.Run(secureDNSCtx, secureDNSDone)
+		otherGroupHandler.Add(secureDNSHandler)
+	}
+
+	wireguardServer := wgserver.New(allSettings.WireguardServer, netLinker,
+		firewallConf, defaultInterfaces, logger.New(log.SetComponent("wireguard server")))
+	if *allSettings.WireguardServer.Enabled {
+		wireguardServerHandler, wireguardServerCtx, wireguardServerDone := goshutdown.NewGoRoutineHandler(
+			"wireguard server", goroutine.OptionTimeout(defaultShutdownTimeout))
+		go wireguardServer.Run(wireguardServerCtx, wireguardServerDone)
+		otherGroupHandler.Add(wireguardServerHandler)
+	}
+
+	vpnTunnels := tunnels.New(allSettings.VPN.Tunnels, allSettings.VPN.Wireguard.Implementation,
+		allSettings.Health.TargetAddresses[0], netLinker, routingConf, firewallConf,
+		logger.New(log.SetComponent("tunnels")))
+	if len(allSettings.VPN.Tunnels) > 0 {
+		tunnelsHandler, tunnelsCtx, tunnelsDone := goshutdown.NewGoRoutineHandler(
+			"tunnels", goroutine.OptionTimeout(defaultShutdownTimeout))
+		go vpnTunnels.Run(tunnelsCtx, tunnelsDone)
+		otherGroupHandler.Add(tunnelsHandler)
+	}
+
 	shadowsocksLooper := shadowsocks.NewLoop(allSettings.Shadowsocks,
 		logger.New(log.SetComponent("shadowsocks")))
 	shadowsocksHandler, shadowsocksCtx, shadowsocksDone := goshutdown.NewGoRoutineHandler(
@@ -454,14 +851,74 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 	go shadowsocksLooper.Run(shadowsocksCtx, shadowsocksDone)
 	otherGroupHandler.Add(shadowsocksHandler)
 
+	healthLogger := logger.New(log.SetComponent("healthcheck"))
+	healthcheckServer := healthcheck.NewServer(allSettings.Health, healthLogger, vpnLooper,
+		unboundLooper, portForwardLooper, publicIPLooper, serverStats, bandwidthSampler)
+	healthcheckServer.SetFilesOwner(filesOwner)
+
+	failoverSwitcher := failover.New(allSettings.Failover, vpnLooper,
+		healthcheckServer, notifier, logger.New(log.SetComponent("failover")))
+	failoverHandler, failoverCtx, failoverDone := goshutdown.NewGoRoutineHandler(
+		"failover", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go failoverSwitcher.Run(failoverCtx, failoverDone)
+	tickersGroupHandler.Add(failoverHandler)
+
+	taskScheduler := scheduler.New(vpnLooper, updaterLooper, publicIPLooper,
+		healthcheckServer, logger.New(log.SetComponent("scheduler")))
+	err = taskScheduler.SetRotation(*allSettings.Rotation.Period, *allSettings.Rotation.Cron)
+	if err != nil {
+		return fmt.Errorf("setting server rotation: %w", err)
+	}
+	schedulerHandler, schedulerCtx, schedulerDone := goshutdown.NewGoRoutineHandler(
+		"scheduler", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go taskScheduler.Run(schedulerCtx, schedulerDone)
+	tickersGroupHandler.Add(schedulerHandler)
+
+	eventsBroker := events.NewBroker()
+	eventsWatcher := events.NewWatcher(eventsBroker, vpnLooper, publicIPLooper,
+		portForwardLooper, healthcheckServer)
+	eventsHandler, eventsCtx, eventsDone := goshutdown.NewGoRoutineHandler(
+		"events", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go eventsWatcher.Run(eventsCtx, eventsDone)
+	tickersGroupHandler.Add(eventsHandler)
+
 	controlServerAddress := *allSettings.ControlServer.Address
 	controlServerLogging := *allSettings.ControlServer.Log
+	var totpKey []byte
+	if *allSettings.ControlServer.TOTPSecret != "" {
+		// already validated
+		totpKey, _ = settings.DecodeTOTPSecret(*allSettings.ControlServer.TOTPSecret)
+	}
+	var controlServerTLSConfig *tls.Config
+	if *allSettings.ControlServer.TLS {
+		certificate, generated, err := tlscert.LoadOrGenerate(
+			*allSettings.ControlServer.TLSCertificateFile, *allSettings.ControlServer.TLSKeyFile,
+			"/gluetun/controlserver", allSettings.ControlServer.TLSHostnames)
+		if err != nil {
+			return fmt.Errorf("setting up control server TLS certificate: %w", err)
+		} else if generated {
+			logger.Info("generated self-signed control server certificate in /gluetun/controlserver")
+		}
+		controlServerTLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{certificate},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+	var dohHandler http.Handler
+	if *allSettings.ControlServer.DoH {
+		dohHandler = securedns.NewDoHHandler(
+			net.JoinHostPort(allSettings.DNS.ServerAddress.String(), "53"),
+			logger.New(log.SetComponent("dns over https")))
+	}
 	httpServerHandler, httpServerCtx, httpServerDone := goshutdown.NewGoRoutineHandler(
 		"http server", goroutine.OptionTimeout(defaultShutdownTimeout))
 	httpServer, err := server.New(httpServerCtx, controlServerAddress, controlServerLogging,
 		logger.New(log.SetComponent("http server")),
-		buildInfo, vpnLooper, portForwardLooper, unboundLooper, updaterLooper, publicIPLooper,
-		storage, ipv6Supported)
+		buildInfo, vpnLooper, failoverSwitcher, serverStats, bandwidthSampler, portForwardLooper,
+		openvpn.NewManagement(), unboundLooper, updaterLooper, publicIPLooper, socks5Looper,
+		taskScheduler, firewallConf, firewallConf, droppedPacketsMonitor, eventsBroker, wireguardServer, vpnTunnels, storage, healthcheckServer,
+		*allSettings.ControlServer.RollbackWindow, totpKey, allSettings.ControlServer.APIKeys,
+		*allSettings.ControlServer.HealthExempt, controlServerTLSConfig, dohHandler, ipv6Supported)
 	if err != nil {
 		return fmt.Errorf("setting up control server: %w", err)
 	}
@@ -470,8 +927,6 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 	<-httpServerReady
 	controlGroupHandler.Add(httpServerHandler)
 
-	healthLogger := logger.New(log.SetComponent("healthcheck"))
-	healthcheckServer := healthcheck.NewServer(allSettings.Health, healthLogger, vpnLooper)
 	healthServerHandler, healthServerCtx, healthServerDone := goshutdown.NewGoRoutineHandler(
 		"HTTP health server", goroutine.OptionTimeout(defaultShutdownTimeout))
 	go healthcheckServer.Run(healthServerCtx, healthServerDone)
@@ -487,21 +942,113 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 	// until the VPN is launched
 	_, _ = vpnLooper.ApplyStatus(ctx, constants.Running) // TODO option to disable with variable
 
+	systemdNotifier := systemd.New()
+	if err := systemdNotifier.Ready("VPN " + string(vpnLooper.GetStatus())); err != nil {
+		logger.Warn("cannot notify systemd of readiness: " + err.Error())
+	}
+	systemdEvents, unsubscribeSystemd := eventsBroker.Subscribe()
+	defer unsubscribeSystemd()
+	go notifySystemdStatus(ctx, systemdNotifier, systemdEvents, logger)
+
 	<-ctx.Done()
 
+	err = hooksRunner.Run(context.Background(), constants.HookPreShutdown)
+	if err != nil {
+		logger.Error(err.Error())
+	}
+
+	if err := systemdNotifier.Stopping(); err != nil {
+		logger.Warn("cannot notify systemd of stopping: " + err.Error())
+	}
+
 	return orderHandler.Shutdown(context.Background())
 }
 
+// raiseCapabilities raises the capabilities needed when running
+// as a non-root user, and logs which ones are missing.
+func raiseCapabilities(logger infoWarner) (err error) {
+	missing, err := capabilities.Raise(capabilities.NetAdmin, capabilities.NetRaw,
+		capabilities.MKNOD)
+	if err != nil {
+		return fmt.Errorf("raising capabilities: %w", err)
+	}
+
+	for _, capability := range missing {
+		switch capability {
+		case capabilities.NetAdmin:
+			return fmt.Errorf("%w: running as non-root user %d requires %s, "+
+				"for example with --cap-add=NET_ADMIN and the file capabilities "+
+				"of the gluetun binary", errCapabilityMissing, os.Geteuid(), capability)
+		case capabilities.NetRaw:
+			logger.Warn(capability.String() + " is missing, which may be needed by iptables")
+		case capabilities.MKNOD:
+			logger.Warn(capability.String() + " is missing, so the TUN device " +
+				"must already exist, for example with --device /dev/net/tun")
+		}
+	}
+
+	raised := "raised capabilities for non-root user"
+	if len(missing) > 0 {
+		raised += " (missing " + capabilities.Join(missing) + ")"
+	}
+	logger.Info(raised)
+	return nil
+}
+
+// notifySystemdStatus updates the systemd service status message
+// on each VPN tunnel event, until the context is canceled.
+func notifySystemdStatus(ctx context.Context, notifier *systemd.Notifier,
+	subscription <-chan events.Event, logger infoWarner) {
+	for {
+		var event events.Event
+		select {
+		case <-ctx.Done():
+			return
+		case event = <-subscription:
+		}
+
+		var status string
+		switch event.Type {
+		case events.TypeTunnelUp:
+			status = "VPN tunnel up"
+			if server, ok := event.Data.(models.ConnectedServer); ok && server.IP != nil {
+				status += " with " + server.Provider + " server " + server.IP.String()
+			}
+		case events.TypeTunnelDown:
+			status = "VPN tunnel down"
+		default:
+			continue
+		}
+
+		if err := notifier.Status(status); err != nil {
+			logger.Warn("cannot notify systemd of status: " + err.Error())
+		}
+	}
+}
+
+// lookupUsername returns the name of the existing user with the given ID.
+func lookupUsername(uid int) (username string, err error) {
+	u, err := user.LookupId(strconv.Itoa(uid))
+	if err != nil {
+		return "", err
+	}
+	return u.Username, nil
+}
+
 type printVersionElement struct {
 	name       string
 	getVersion func(ctx context.Context) (version string, err error)
 }
 
-type infoer interface {
+type infoWarner interface {
 	Info(s string)
+	Warn(s string)
 }
 
-func printVersions(ctx context.Context, logger infoer,
+// printVersions logs the version of each element. In host mode, some
+// elements such as Alpine or OpenVPN 2.4 may not be installed, so
+// errors getting a version are only logged as warnings.
+func printVersions(ctx context.Context, logger infoWarner, hostMode bool,
 	elements []printVersionElement) (err error) {
 	const timeout = 5 * time.Second
 	ctx, cancel := context.WithTimeout(ctx, timeout)
@@ -509,7 +1056,10 @@ func printVersions(ctx context.Context, logger infoer,
 
 	for _, element := range elements {
 		version, err := element.getVersion(ctx)
-		if err != nil {
+		if err != nil && hostMode {
+			logger.Warn("cannot get " + element.name + " version: " + err.Error())
+			continue
+		} else if err != nil {
 			return fmt.Errorf("getting %s version: %w", element.name, err)
 		}
 		logger.Info(element.name + " version: " + version)
@@ -556,6 +1106,7 @@ type Linker interface {
 	LinkDel(link netlink.Link) (err error)
 	LinkSetUp(link netlink.Link) (err error)
 	LinkSetDown(link netlink.Link) (err error)
+	LinkSubscribe(updates chan<- netlink.LinkUpdate, done <-chan struct{}) (err error)
 }
 
 type clier interface {
@@ -563,7 +1114,10 @@ type clier interface {
 	FormatServers(args []string) error
 	OpenvpnConfig(logger cli.OpenvpnConfigLogger, source cli.Source, ipv6Checker cli.IPv6Checker) error
 	HealthCheck(ctx context.Context, source cli.Source, warner cli.Warner) error
+	Readiness(ctx context.Context, source cli.Source) error
 	Update(ctx context.Context, args []string, logger cli.UpdaterLogger) error
+	Servers(ctx context.Context, args []string, source cli.Source) error
+	Wireguard(ctx context.Context, args []string) error
 }
 
 type Tun interface {
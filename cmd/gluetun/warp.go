@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/provider/cloudflarewarp"
+)
+
+// registerCloudflareWARP sets the Wireguard private key and addresses
+// from the Cloudflare WARP device registration, registering a new
+// device if needed, unless the Wireguard private key is already set.
+// IPv6 addresses are only set if IPv6 is supported.
+// It must run before the firewall is enabled, since the firewall
+// blocks the Cloudflare API until the VPN is connected.
+func registerCloudflareWARP(ctx context.Context, vpnSettings *settings.VPN,
+	ipv6Supported bool, logger infoWarner) (err error) {
+	if vpnSettings.Type != vpn.Wireguard ||
+		*vpnSettings.Provider.Name != providers.CloudflareWARP ||
+		*vpnSettings.Wireguard.PrivateKey != "" {
+		return nil
+	}
+
+	const timeout = 30 * time.Second
+	client := &http.Client{Timeout: timeout}
+	registration, err := cloudflarewarp.Register(ctx, client,
+		constants.CloudflareWARPRegistration, *vpnSettings.Wireguard.WARPLicenseKey, logger)
+	if err != nil {
+		return fmt.Errorf("registering Cloudflare WARP device: %w", err)
+	}
+
+	vpnSettings.Wireguard.PrivateKey = &registration.PrivateKey
+	if len(vpnSettings.Wireguard.Addresses) > 0 {
+		return nil
+	}
+	for _, address := range registration.Addresses {
+		if address.IP.To4() == nil && !ipv6Supported {
+			continue
+		}
+		vpnSettings.Wireguard.Addresses = append(vpnSettings.Wireguard.Addresses, address)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Package bandwidth samples the VPN interface counters to
+// provide a time series of its throughput.
+package bandwidth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+)
+
+// Sample is the throughput of the VPN interface
+// measured between the previous sample and its time.
+type Sample struct {
+	Time             time.Time `json:"time"`
+	RxBytesPerSecond uint64    `json:"rx_bytes_per_second"`
+	TxBytesPerSecond uint64    `json:"tx_bytes_per_second"`
+}
+
+// Series is the time series of the VPN interface throughput,
+// with samples ordered from oldest to newest.
+type Series struct {
+	Interface  string   `json:"interface"`
+	Resolution string   `json:"resolution"`
+	Samples    []Sample `json:"samples"`
+}
+
+// Sampler samples the VPN interface counters at each resolution
+// period, keeping the samples within the window duration.
+type Sampler struct {
+	settings   settings.Bandwidth
+	vpnGetter  VPNSettingsGetter
+	sysNetPath string
+	timeNow    func() time.Time
+
+	// State
+	samplesMu     sync.RWMutex
+	samples       []Sample
+	lastInterface string
+	lastTime      time.Time
+	lastRx        uint64
+	lastTx        uint64
+}
+
+// New creates a bandwidth sampler using the settings given.
+func New(settings settings.Bandwidth, vpnGetter VPNSettingsGetter) *Sampler {
+	return &Sampler{
+		settings:   settings,
+		vpnGetter:  vpnGetter,
+		sysNetPath: "/sys/class/net",
+		timeNow:    time.Now,
+	}
+}
+
+// Run samples the VPN interface counters until the context
+// is canceled. It returns immediately if the resolution is 0.
+func (s *Sampler) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	if *s.settings.Resolution == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(*s.settings.Resolution)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sample()
+		}
+	}
+}
+
+// GetSeries returns the samples within the window duration.
+func (s *Sampler) GetSeries() (series Series) {
+	s.samplesMu.RLock()
+	defer s.samplesMu.RUnlock()
+
+	series.Interface = s.lastInterface
+	series.Resolution = s.settings.Resolution.String()
+	series.Samples = make([]Sample, len(s.samples))
+	copy(series.Samples, s.samples)
+	return series
+}
+
+// GetCounters returns the total number of bytes received and
+// sent by the VPN interface, and an error if the VPN interface
+// does not exist, such as when the VPN is down.
+func (s *Sampler) GetCounters() (rxBytes, txBytes uint64, err error) {
+	vpnInterface := s.vpnInterface()
+	rxBytes, err = readCounter(s.sysNetPath, vpnInterface, "rx_bytes")
+	if err != nil {
+		return 0, 0, err
+	}
+	txBytes, err = readCounter(s.sysNetPath, vpnInterface, "tx_bytes")
+	if err != nil {
+		return 0, 0, err
+	}
+	return rxBytes, txBytes, nil
+}
+
+func (s *Sampler) sample() {
+	now := s.timeNow()
+	vpnInterface := s.vpnInterface()
+	rx, rxErr := readCounter(s.sysNetPath, vpnInterface, "rx_bytes")
+	tx, txErr := readCounter(s.sysNetPath, vpnInterface, "tx_bytes")
+
+	s.samplesMu.Lock()
+	defer s.samplesMu.Unlock()
+
+	previousInterface, previousTime := s.lastInterface, s.lastTime
+	previousRx, previousTx := s.lastRx, s.lastTx
+	s.lastInterface, s.lastTime = vpnInterface, now
+	s.lastRx, s.lastTx = rx, tx
+
+	s.removeOldSamples(now)
+
+	switch {
+	case rxErr != nil || txErr != nil:
+		// The VPN interface does not exist when the VPN is down,
+		// and its counters restart from 0 once it is recreated.
+		s.lastTime = time.Time{}
+		return
+	case previousTime.IsZero(), vpnInterface != previousInterface,
+		rx < previousRx, tx < previousTx:
+		return
+	}
+
+	elapsedSeconds := now.Sub(previousTime).Seconds()
+	if elapsedSeconds <= 0 {
+		return
+	}
+
+	s.samples = append(s.samples, Sample{
+		Time:             now,
+		RxBytesPerSecond: uint64(float64(rx-previousRx) / elapsedSeconds),
+		TxBytesPerSecond: uint64(float64(tx-previousTx) / elapsedSeconds),
+	})
+}
+
+func (s *Sampler) removeOldSamples(now time.Time) {
+	windowStart := now.Add(-*s.settings.Window)
+	firstKept := 0
+	for firstKept < len(s.samples) && !s.samples[firstKept].Time.After(windowStart) {
+		firstKept++
+	}
+	s.samples = s.samples[firstKept:]
+}
+
+func (s *Sampler) vpnInterface() string {
+	vpnSettings := s.vpnGetter.GetSettings()
+	switch vpnSettings.Type {
+	case vpn.Wireguard:
+		return vpnSettings.Wireguard.Interface
+	case vpn.IKEv2:
+		return vpnSettings.IKEv2.Interface
+	case vpn.OpenConnect:
+		return vpnSettings.OpenConnect.Interface
+	default:
+		return vpnSettings.OpenVPN.Interface
+	}
+}
+
+func readCounter(sysNetPath, networkInterface, name string) (counter uint64, err error) {
+	path := filepath.Join(sysNetPath, networkInterface, "statistics", name)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	const base, bitSize = 10, 64
+	counter, err = strconv.ParseUint(strings.TrimSpace(string(b)), base, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return counter, nil
+}
@@ -0,0 +1,80 @@
+package bandwidth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVPNGetter struct{}
+
+func (fakeVPNGetter) GetSettings() (vpn settings.VPN) {
+	vpn.Type = "openvpn"
+	vpn.OpenVPN.Interface = "tun0"
+	return vpn
+}
+
+func writeCounters(t *testing.T, sysNetPath, rx, tx string) {
+	t.Helper()
+	dir := filepath.Join(sysNetPath, "tun0", "statistics")
+	require.NoError(t, os.MkdirAll(dir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rx_bytes"), []byte(rx+"\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tx_bytes"), []byte(tx+"\n"), 0600))
+}
+
+func Test_Sampler_sample(t *testing.T) {
+	t.Parallel()
+
+	resolution, window := time.Second, 2*time.Second
+	sysNetPath := filepath.Join(t.TempDir(), "net")
+	now := time.Unix(1700000000, 0)
+	sampler := &Sampler{
+		settings: settings.Bandwidth{
+			Resolution: &resolution,
+			Window:     &window,
+		},
+		vpnGetter:  fakeVPNGetter{},
+		sysNetPath: sysNetPath,
+		timeNow:    func() time.Time { return now },
+	}
+
+	// No interface
+	sampler.sample()
+	assert.Empty(t, sampler.GetSeries().Samples)
+
+	// First counters read sets the baseline.
+	writeCounters(t, sysNetPath, "1000", "100")
+	now = now.Add(time.Second)
+	sampler.sample()
+	assert.Empty(t, sampler.GetSeries().Samples)
+
+	writeCounters(t, sysNetPath, "3000", "300")
+	now = now.Add(time.Second)
+	sampler.sample()
+	writeCounters(t, sysNetPath, "4000", "400")
+	now = now.Add(time.Second)
+	sampler.sample()
+
+	expected := Series{
+		Interface:  "tun0",
+		Resolution: "1s",
+		Samples: []Sample{
+			{Time: time.Unix(1700000002, 0), RxBytesPerSecond: 2000, TxBytesPerSecond: 200},
+			{Time: time.Unix(1700000003, 0), RxBytesPerSecond: 1000, TxBytesPerSecond: 100},
+		},
+	}
+	assert.Equal(t, expected, sampler.GetSeries())
+
+	// Counters reset when the interface is recreated, and
+	// the oldest sample falls out of the window.
+	writeCounters(t, sysNetPath, "10", "1")
+	now = now.Add(time.Second)
+	sampler.sample()
+	expected.Samples = expected.Samples[1:]
+	assert.Equal(t, expected, sampler.GetSeries())
+}
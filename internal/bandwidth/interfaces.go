@@ -0,0 +1,7 @@
+package bandwidth
+
+import "github.com/qdm12/gluetun/internal/configuration/settings"
+
+type VPNSettingsGetter interface {
+	GetSettings() (settings settings.VPN)
+}
@@ -0,0 +1,78 @@
+// Package capabilities inspects and raises Linux capabilities,
+// so gluetun can run as a non-root user with only the capabilities
+// it needs, such as CAP_NET_ADMIN.
+package capabilities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capability is a Linux capability number, as defined in
+// linux/capability.h.
+type Capability uint
+
+const (
+	Chown       Capability = 0
+	DACOverride Capability = 1
+	SetGID      Capability = 6
+	SetUID      Capability = 7
+	NetAdmin    Capability = 12
+	NetRaw      Capability = 13
+	MKNOD       Capability = 27
+)
+
+func (c Capability) String() string {
+	switch c {
+	case Chown:
+		return "CAP_CHOWN"
+	case DACOverride:
+		return "CAP_DAC_OVERRIDE"
+	case SetGID:
+		return "CAP_SETGID"
+	case SetUID:
+		return "CAP_SETUID"
+	case NetAdmin:
+		return "CAP_NET_ADMIN"
+	case NetRaw:
+		return "CAP_NET_RAW"
+	case MKNOD:
+		return "CAP_MKNOD"
+	default:
+		return fmt.Sprintf("CAP_%d", uint(c))
+	}
+}
+
+// Set is a bit set of capabilities.
+type Set uint64
+
+// Has returns true if the capability is in the set.
+func (s Set) Has(capability Capability) bool {
+	return s&(1<<capability) != 0
+}
+
+func (s Set) with(capabilities ...Capability) Set {
+	for _, capability := range capabilities {
+		s |= 1 << capability
+	}
+	return s
+}
+
+// Missing returns the capabilities given which are not in the set.
+func (s Set) Missing(capabilities ...Capability) (missing []Capability) {
+	for _, capability := range capabilities {
+		if !s.Has(capability) {
+			missing = append(missing, capability)
+		}
+	}
+	return missing
+}
+
+// Join returns the capability names joined with commas.
+func Join(capabilities []Capability) string {
+	names := make([]string, len(capabilities))
+	for i, capability := range capabilities {
+		names[i] = capability.String()
+	}
+	return strings.Join(names, ", ")
+}
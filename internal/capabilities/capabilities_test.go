@@ -0,0 +1,60 @@
+package capabilities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Set_Missing(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		set          Set
+		capabilities []Capability
+		missing      []Capability
+	}{
+		"empty set": {
+			capabilities: []Capability{NetAdmin, MKNOD},
+			missing:      []Capability{NetAdmin, MKNOD},
+		},
+		"all present": {
+			set:          Set(0).with(NetAdmin, NetRaw),
+			capabilities: []Capability{NetAdmin, NetRaw},
+		},
+		"some missing": {
+			set:          Set(0).with(NetAdmin),
+			capabilities: []Capability{NetAdmin, SetUID, SetGID},
+			missing:      []Capability{SetUID, SetGID},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			missing := testCase.set.Missing(testCase.capabilities...)
+
+			assert.Equal(t, testCase.missing, missing)
+		})
+	}
+}
+
+func Test_halves(t *testing.T) {
+	t.Parallel()
+
+	set := Set(0).with(Chown, NetAdmin, 40)
+	low, high := splitHalves(set)
+	assert.Equal(t, uint32(1<<Chown|1<<NetAdmin), low)
+	assert.Equal(t, uint32(1<<(40-32)), high)
+	assert.Equal(t, set, joinHalves(low, high))
+}
+
+func Test_Join(t *testing.T) {
+	t.Parallel()
+
+	s := Join([]Capability{NetAdmin, MKNOD, 40})
+
+	assert.Equal(t, "CAP_NET_ADMIN, CAP_MKNOD, CAP_40", s)
+}
@@ -0,0 +1,92 @@
+package capabilities
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// State contains the capability sets of the current process.
+type State struct {
+	Effective   Set
+	Permitted   Set
+	Inheritable Set
+}
+
+// Get returns the capability sets of the current process.
+func Get() (state State, err error) {
+	header := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var data [2]unix.CapUserData
+	err = unix.Capget(&header, &data[0])
+	if err != nil {
+		return state, fmt.Errorf("getting capabilities: %w", err)
+	}
+
+	return State{
+		Effective:   joinHalves(data[0].Effective, data[1].Effective),
+		Permitted:   joinHalves(data[0].Permitted, data[1].Permitted),
+		Inheritable: joinHalves(data[0].Inheritable, data[1].Inheritable),
+	}, nil
+}
+
+// Raise makes the wanted capabilities available in the permitted set
+// effective for all threads of the process, and raises them in the
+// ambient set so child processes such as iptables or openvpn inherit
+// them. It returns the wanted capabilities which are not permitted
+// and could therefore not be raised.
+func Raise(wanted ...Capability) (missing []Capability, err error) {
+	state, err := Get()
+	if err != nil {
+		return nil, err
+	}
+
+	missing = state.Permitted.Missing(wanted...)
+	available := make([]Capability, 0, len(wanted)-len(missing))
+	for _, capability := range wanted {
+		if state.Permitted.Has(capability) {
+			available = append(available, capability)
+		}
+	}
+	if len(available) == 0 {
+		return missing, nil
+	}
+
+	effective := state.Effective.with(available...)
+	inheritable := state.Inheritable.with(available...)
+	header := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var data [2]unix.CapUserData
+	data[0].Effective, data[1].Effective = splitHalves(effective)
+	data[0].Permitted, data[1].Permitted = splitHalves(state.Permitted)
+	data[0].Inheritable, data[1].Inheritable = splitHalves(inheritable)
+
+	// Capabilities are per thread, so they must be set on all threads
+	// of the Go runtime, since the goroutine running a netlink or exec
+	// call later on may run on any of them.
+	_, _, errno := syscall.AllThreadsSyscall(unix.SYS_CAPSET,
+		uintptr(unsafe.Pointer(&header)), uintptr(unsafe.Pointer(&data[0])), 0)
+	if errno != 0 {
+		return nil, fmt.Errorf("setting capabilities: %w", errno)
+	}
+
+	for _, capability := range available {
+		_, _, errno = syscall.AllThreadsSyscall(unix.SYS_PRCTL,
+			unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_RAISE, uintptr(capability))
+		if errno != 0 {
+			return nil, fmt.Errorf("raising ambient capability %s: %w", capability, errno)
+		}
+	}
+
+	return missing, nil
+}
+
+func joinHalves(low, high uint32) Set {
+	const halfBits = 32
+	return Set(uint64(high)<<halfBits | uint64(low))
+}
+
+func splitHalves(set Set) (low, high uint32) {
+	const halfBits = 32
+	return uint32(set), uint32(set >> halfBits)
+}
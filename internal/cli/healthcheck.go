@@ -10,6 +10,16 @@ import (
 )
 
 func (c *CLI) HealthCheck(ctx context.Context, source Source, _ Warner) error {
+	return checkHealthServer(ctx, source, "")
+}
+
+// Readiness exits with an error unless all the readiness conditions
+// configured are met, for dependent containers to gate their startup.
+func (c *CLI) Readiness(ctx context.Context, source Source) error {
+	return checkHealthServer(ctx, source, "/readiness")
+}
+
+func checkHealthServer(ctx context.Context, source Source, path string) error {
 	// Extract the health server port from the configuration.
 	config, err := source.ReadHealth()
 	if err != nil {
@@ -34,6 +44,6 @@ func (c *CLI) HealthCheck(ctx context.Context, source Source, _ Warner) error {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	url := "http://127.0.0.1:" + port
+	url := "http://127.0.0.1:" + port + path
 	return client.Check(ctx, url)
 }
@@ -51,6 +51,11 @@ func (c *CLI) OpenvpnConfig(logger OpenvpnConfigLogger, source Source,
 		return err
 	}
 
+	providerDefinition, err := storage.LoadProviderDefinition(*allSettings.VPN.Provider.DefinitionFile)
+	if err != nil {
+		return err
+	}
+
 	ipv6Supported, err := ipv6Checker.IsIPv6Supported()
 	if err != nil {
 		return fmt.Errorf("checking for IPv6 support: %w", err)
@@ -69,7 +74,8 @@ func (c *CLI) OpenvpnConfig(logger OpenvpnConfigLogger, source Source,
 	openvpnFileExtractor := extract.New()
 
 	providers := provider.NewProviders(storage, time.Now, warner, client,
-		unzipper, parallelResolver, ipFetcher, openvpnFileExtractor)
+		unzipper, parallelResolver, ipFetcher, openvpnFileExtractor, providerDefinition,
+		*allSettings.VPN.Provider.PluginFile)
 	providerConf := providers.Get(*allSettings.VPN.Provider.Name)
 	connection, err := providerConf.GetConnection(
 		allSettings.VPN.Provider.ServerSelection, ipv6Supported)
@@ -77,8 +83,11 @@ func (c *CLI) OpenvpnConfig(logger OpenvpnConfigLogger, source Source,
 		return err
 	}
 
-	lines := providerConf.OpenVPNConfig(connection,
+	lines, err := providerConf.OpenVPNConfig(connection,
 		allSettings.VPN.OpenVPN, ipv6Supported)
+	if err != nil {
+		return err
+	}
 
 	fmt.Println(strings.Join(lines, "\n"))
 	return nil
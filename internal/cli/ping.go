@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/ping"
+)
+
+var ErrNoIPv4Address = errors.New("server has no IPv4 address")
+
+// pingServers sets the average ICMP echo round trip time of each
+// server result, probing at most parallel servers at once.
+func pingServers(ctx context.Context, results []serverResult,
+	count int, timeout time.Duration, parallel int) {
+	semaphore := make(chan struct{}, parallel)
+	var waitGroup sync.WaitGroup
+	for i := range results {
+		waitGroup.Add(1)
+		go func(result *serverResult) {
+			defer waitGroup.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			result.latency, result.pingErr = pingServerIPs(ctx,
+				result.server.IPs, count, timeout)
+		}(&results[i])
+	}
+	waitGroup.Wait()
+}
+
+func pingServerIPs(ctx context.Context, ips []net.IP,
+	count int, timeout time.Duration) (average time.Duration, err error) {
+	var ip net.IP
+	for _, candidate := range ips {
+		if candidate.To4() != nil {
+			ip = candidate.To4()
+			break
+		}
+	}
+	if ip == nil {
+		return 0, fmt.Errorf("%w", ErrNoIPv4Address)
+	}
+
+	var total time.Duration
+	var received int
+	for i := 0; i < count; i++ {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		rtt, err := ping.Echo(ip, i, timeout)
+		if err != nil {
+			continue
+		}
+		total += rtt
+		received++
+	}
+
+	if received == 0 {
+		return 0, fmt.Errorf("%w: %d echo requests sent to %s",
+			ErrNoEchoReply, count, ip)
+	}
+	return total / time.Duration(received), nil
+}
+
+var ErrNoEchoReply = errors.New("no ICMP echo reply received")
@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/storage"
+)
+
+var (
+	ErrServersSubcommandMissing = errors.New("servers subcommand is missing, it can be 'list' or 'ping'")
+	ErrServersSubcommandUnknown = errors.New("servers subcommand is unknown")
+	ErrPingCountNotValid        = errors.New("ping count must be at least 1")
+	ErrPingParallelNotValid     = errors.New("ping parallel count must be at least 1")
+)
+
+// Servers runs the `servers list` and `servers ping` subcommands, which
+// print the servers matching the configured server selection filters,
+// optionally probing their latency.
+func (c *CLI) Servers(ctx context.Context, args []string, source Source) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w", ErrServersSubcommandMissing)
+	}
+
+	subcommand, args := args[0], args[1:]
+	var ping bool
+	switch subcommand {
+	case "list":
+	case "ping":
+		ping = true
+	default:
+		return fmt.Errorf("%w: %s", ErrServersSubcommandUnknown, subcommand)
+	}
+
+	var format string
+	var count, parallel int
+	var timeout time.Duration
+	flagSet := flag.NewFlagSet("servers "+subcommand, flag.ExitOnError)
+	flagSet.StringVar(&format, "format", "table", "Output format which can be 'table' or 'json'")
+	if ping {
+		flagSet.IntVar(&count, "count", 3, "Number of ICMP echo requests to send to each server")
+		flagSet.DurationVar(&timeout, "timeout", time.Second, "Timeout for each ICMP echo request")
+		flagSet.IntVar(&parallel, "parallel", 16, "Number of servers to probe in parallel")
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	switch {
+	case format != "table" && format != "json":
+		return fmt.Errorf("%w: %s", ErrFormatNotRecognized, format)
+	case ping && count < 1:
+		return fmt.Errorf("%w: %d", ErrPingCountNotValid, count)
+	case ping && parallel < 1:
+		return fmt.Errorf("%w: %d", ErrPingParallelNotValid, parallel)
+	}
+
+	allSettings, err := source.Read()
+	if err != nil {
+		return fmt.Errorf("reading settings: %w", err)
+	}
+
+	logger := newNoopLogger()
+	storage, err := storage.New(logger, constants.ServersData)
+	if err != nil {
+		return fmt.Errorf("creating servers storage: %w", err)
+	}
+
+	providerName := *allSettings.VPN.Provider.Name
+	servers, err := storage.FilterServers(providerName,
+		allSettings.VPN.Provider.ServerSelection)
+	if err != nil {
+		return fmt.Errorf("filtering servers: %w", err)
+	}
+
+	results := make([]serverResult, len(servers))
+	for i := range servers {
+		results[i].server = servers[i]
+	}
+
+	if ping {
+		pingServers(ctx, results, count, timeout, parallel)
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].less(results[j])
+		})
+	}
+
+	if format == "json" {
+		return writeServersJSON(os.Stdout, results, ping)
+	}
+	return writeServersTable(os.Stdout, results, ping)
+}
+
+type serverResult struct {
+	server  models.Server
+	latency time.Duration
+	pingErr error
+}
+
+// less returns true if the result has a lower latency than the
+// other result, sorting servers which could not be probed last.
+func (r serverResult) less(other serverResult) bool {
+	switch {
+	case r.pingErr == nil && other.pingErr != nil:
+		return true
+	case r.pingErr != nil:
+		return false
+	default:
+		return r.latency < other.latency
+	}
+}
+
+func writeServersTable(writer io.Writer, results []serverResult, ping bool) error {
+	const minWidth, tabWidth, padding, flags = 0, 0, 2, 0
+	tabWriter := tabwriter.NewWriter(writer, minWidth, tabWidth, padding, ' ', flags)
+	headers := []string{"COUNTRY", "REGION", "CITY", "NAME", "HOSTNAME", "IP"}
+	if ping {
+		headers = append(headers, "LATENCY")
+	}
+	_, _ = fmt.Fprintln(tabWriter, strings.Join(headers, "\t"))
+
+	for _, result := range results {
+		server := result.server
+		fields := []string{
+			orDash(server.Country), orDash(server.Region), orDash(server.City),
+			orDash(server.ServerName), orDash(server.Hostname), orDash(firstIP(server.IPs)),
+		}
+		if ping {
+			fields = append(fields, formatLatency(result))
+		}
+		_, _ = fmt.Fprintln(tabWriter, strings.Join(fields, "\t"))
+	}
+
+	return tabWriter.Flush()
+}
+
+type serverJSON struct {
+	models.Server
+	Latency   string `json:"latency,omitempty"`
+	PingError string `json:"ping_error,omitempty"`
+}
+
+func writeServersJSON(writer io.Writer, results []serverResult, ping bool) error {
+	data := make([]serverJSON, len(results))
+	for i, result := range results {
+		data[i].Server = result.server
+		if !ping {
+			continue
+		}
+		if result.pingErr != nil {
+			data[i].PingError = result.pingErr.Error()
+		} else {
+			data[i].Latency = result.latency.String()
+		}
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+func formatLatency(result serverResult) string {
+	if result.pingErr != nil {
+		return "unreachable"
+	}
+	const precision = 100 * time.Microsecond
+	return result.latency.Round(precision).String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// firstIP returns the first IPv4 address, or the first
+// IPv6 address if there is no IPv4 address.
+func firstIP(ips []net.IP) string {
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			return ip.String()
+		}
+	}
+	if len(ips) == 0 {
+		return ""
+	}
+	return ips[0].String()
+}
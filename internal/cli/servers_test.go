@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_writeServersTable(t *testing.T) {
+	t.Parallel()
+
+	results := []serverResult{
+		{
+			server: models.Server{
+				Country:  "Switzerland",
+				City:     "Zurich",
+				Hostname: "ch-zrh-001",
+				IPs:      []net.IP{net.ParseIP("2a03::1"), {1, 2, 3, 4}},
+			},
+			latency: 12345 * time.Microsecond,
+		},
+		{
+			server: models.Server{
+				Country:  "France",
+				Hostname: "fr-par-001",
+			},
+			pingErr: errors.New("dummy"),
+		},
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	const ping = true
+	err := writeServersTable(buffer, results, ping)
+	require.NoError(t, err)
+
+	const expected = "" +
+		"COUNTRY      REGION  CITY    NAME  HOSTNAME    IP       LATENCY\n" +
+		"Switzerland  -       Zurich  -     ch-zrh-001  1.2.3.4  12.3ms\n" +
+		"France       -       -       -     fr-par-001  -        unreachable\n"
+	assert.Equal(t, expected, buffer.String())
+}
+
+func Test_serverResult_less(t *testing.T) {
+	t.Parallel()
+
+	fast := serverResult{latency: time.Millisecond}
+	slow := serverResult{latency: time.Second}
+	unreachable := serverResult{pingErr: errors.New("dummy")}
+
+	assert.True(t, fast.less(slow))
+	assert.False(t, slow.less(fast))
+	assert.True(t, slow.less(unreachable))
+	assert.False(t, unreachable.less(fast))
+}
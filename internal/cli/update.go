@@ -5,18 +5,19 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/constants"
 	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/qdm12/gluetun/internal/models"
 	"github.com/qdm12/gluetun/internal/openvpn/extract"
 	"github.com/qdm12/gluetun/internal/provider"
 	"github.com/qdm12/gluetun/internal/publicip/ipinfo"
 	"github.com/qdm12/gluetun/internal/storage"
 	"github.com/qdm12/gluetun/internal/updater"
+	"github.com/qdm12/gluetun/internal/updater/httpclient"
 	"github.com/qdm12/gluetun/internal/updater/resolver"
 	"github.com/qdm12/gluetun/internal/updater/unzip"
 )
@@ -46,6 +47,11 @@ func (c *CLI) Update(ctx context.Context, args []string, logger UpdaterLogger) e
 		"Minimum ratio of servers to find for the update to succeed")
 	flagSet.BoolVar(&updateAll, "all", false, "Update servers for all VPN providers")
 	flagSet.StringVar(&csvProviders, "providers", "", "CSV string of VPN providers to update server data for")
+	const defaultWorkers = 4
+	workers := flagSet.Int("workers", defaultWorkers, "Maximum number of providers to update concurrently")
+	const defaultProviderTimeout = 10 * time.Minute
+	flagSet.DurationVar(&options.ProviderTimeout, "timeout", defaultProviderTimeout,
+		"Maximum duration to update the servers of a single provider")
 	if err := flagSet.Parse(args); err != nil {
 		return err
 	}
@@ -63,6 +69,7 @@ func (c *CLI) Update(ctx context.Context, args []string, logger UpdaterLogger) e
 		options.Providers = strings.Split(csvProviders, ",")
 	}
 
+	options.Workers = workers
 	options.SetDefaults(options.Providers[0])
 
 	err := options.Validate()
@@ -76,17 +83,18 @@ func (c *CLI) Update(ctx context.Context, args []string, logger UpdaterLogger) e
 	}
 
 	const clientTimeout = 10 * time.Second
-	httpClient := &http.Client{Timeout: clientTimeout}
+	httpClient := httpclient.New(clientTimeout)
 	unzipper := unzip.New(httpClient)
 	parallelResolver := resolver.NewParallelResolver(options.DNSAddress)
 	ipFetcher := ipinfo.New(httpClient)
 	openvpnFileExtractor := extract.New()
 
 	providers := provider.NewProviders(storage, time.Now, logger, httpClient,
-		unzipper, parallelResolver, ipFetcher, openvpnFileExtractor)
+		unzipper, parallelResolver, ipFetcher, openvpnFileExtractor,
+		models.ProviderDefinition{}, "") // the user defined and plugin providers are not updated
 
 	updater := updater.New(httpClient, storage, providers, logger)
-	err = updater.UpdateServers(ctx, options.Providers, options.MinRatio)
+	err = updater.UpdateServers(ctx, options)
 	if err != nil {
 		return fmt.Errorf("updating server information: %w", err)
 	}
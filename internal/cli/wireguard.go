@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/qdm12/gluetun/internal/provider/cloudflarewarp"
+	"github.com/qdm12/gluetun/internal/provider/mullvad"
+	"github.com/qdm12/gluetun/internal/provider/windscribe"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+var (
+	ErrWireguardSubcommandMissing = errors.New("wireguard subcommand is missing, it can be 'provision'")
+	ErrWireguardSubcommandUnknown = errors.New("wireguard subcommand is unknown")
+	ErrProvisionAccountMissing    = errors.New("account flag is missing")
+	ErrProvisionSessionMissing    = errors.New("session flag is missing")
+	ErrProvisionServerMissing     = errors.New("server flag is missing")
+	ErrProvisionNotSupported      = errors.New("Wireguard provisioning is not supported for this provider")
+)
+
+// Wireguard runs the `wireguard provision` subcommand, which generates
+// a Wireguard key pair, registers its public key with the VPN provider
+// API and prints the resulting Wireguard settings as environment variables.
+func (c *CLI) Wireguard(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w", ErrWireguardSubcommandMissing)
+	}
+
+	subcommand, args := args[0], args[1:]
+	if subcommand != "provision" {
+		return fmt.Errorf("%w: %s", ErrWireguardSubcommandUnknown, subcommand)
+	}
+
+	var provider, account, licenseKey, session, server, outputPath string
+	flagSet := flag.NewFlagSet("wireguard provision", flag.ExitOnError)
+	flagSet.StringVar(&provider, "provider", providers.Mullvad, "VPN provider to provision a Wireguard key with, "+
+		"which can be "+strings.Join(provisionProviders(), ", "))
+	flagSet.StringVar(&account, "account", "", "Account number for the VPN provider")
+	flagSet.StringVar(&licenseKey, "license", "", "WARP+ license key for Cloudflare WARP")
+	flagSet.StringVar(&session, "session", "", "Session authentication hash for Windscribe")
+	flagSet.StringVar(&server, "server", "", "Wireguard server hostname for Windscribe")
+	flagSet.StringVar(&outputPath, "output", "", "File path to write the settings to, instead of printing them")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	privateKey, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		return fmt.Errorf("generating private key: %w", err)
+	}
+	publicKey := privateKey.PublicKey().String()
+
+	const timeout = 30 * time.Second
+	client := &http.Client{Timeout: timeout}
+
+	var settings wireguardSettings
+	switch strings.ToLower(provider) {
+	case providers.Mullvad:
+		if account == "" {
+			return fmt.Errorf("%w", ErrProvisionAccountMissing)
+		}
+		settings.addresses, err = mullvad.ProvisionWireguard(ctx, client, account, publicKey)
+	case providers.CloudflareWARP:
+		var registration cloudflarewarp.Registration
+		registration, err = cloudflarewarp.ProvisionWireguard(ctx, client, publicKey, licenseKey)
+		settings.addresses = registration.Addresses
+	case providers.Windscribe:
+		switch {
+		case session == "":
+			return fmt.Errorf("%w", ErrProvisionSessionMissing)
+		case server == "":
+			return fmt.Errorf("%w", ErrProvisionServerMissing)
+		}
+		var provisioning windscribe.Provisioning
+		provisioning, err = windscribe.ProvisionWireguard(ctx, client, session, publicKey, server)
+		settings.addresses = provisioning.Addresses
+		settings.presharedKey = provisioning.PresharedKey
+		settings.serverHostname = server
+	default:
+		return fmt.Errorf("%w: %s must be one of %s", ErrProvisionNotSupported,
+			provider, strings.Join(provisionProviders(), ", "))
+	}
+	if err != nil {
+		return fmt.Errorf("provisioning Wireguard key with %s: %w", provider, err)
+	}
+
+	settings.provider = strings.ToLower(provider)
+	settings.privateKey = privateKey.String()
+
+	if outputPath == "" {
+		return writeWireguardSettings(os.Stdout, settings)
+	}
+
+	const perms = os.FileMode(0600)
+	file, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perms)
+	if err != nil {
+		return err
+	}
+
+	err = writeWireguardSettings(file, settings)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// provisionProviders returns the VPN providers
+// Wireguard keys can be provisioned with.
+func provisionProviders() (names []string) {
+	return []string{providers.CloudflareWARP, providers.Mullvad, providers.Windscribe}
+}
+
+type wireguardSettings struct {
+	provider     string
+	privateKey   string
+	presharedKey string
+	addresses    []net.IPNet
+	// serverHostname is the server the settings are
+	// restricted to, and can be empty for any server.
+	serverHostname string
+}
+
+func writeWireguardSettings(w io.Writer, settings wireguardSettings) (err error) {
+	addressStrings := make([]string, len(settings.addresses))
+	for i, address := range settings.addresses {
+		addressStrings[i] = address.String()
+	}
+
+	lines := []string{
+		"VPN_SERVICE_PROVIDER=" + settings.provider,
+		"VPN_TYPE=wireguard",
+		"WIREGUARD_PRIVATE_KEY=" + settings.privateKey,
+	}
+	if settings.presharedKey != "" {
+		lines = append(lines, "WIREGUARD_PRESHARED_KEY="+settings.presharedKey)
+	}
+	lines = append(lines, "WIREGUARD_ADDRESSES="+strings.Join(addressStrings, ","))
+	if settings.serverHostname != "" {
+		lines = append(lines, "SERVER_HOSTNAMES="+settings.serverHostname)
+	}
+
+	_, err = fmt.Fprint(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
@@ -0,0 +1,84 @@
+package settings
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// Bandwidth contains settings to sample the throughput of
+// the VPN interface, exposed as a time series by the control
+// server.
+type Bandwidth struct {
+	// Resolution is the period between each sample of the VPN
+	// interface counters, and 0 disables sampling.
+	// It cannot be nil in the internal state.
+	Resolution *time.Duration
+	// Window is the duration of the time series kept in memory.
+	// It cannot be nil in the internal state.
+	Window *time.Duration
+}
+
+func (b Bandwidth) validate() (err error) {
+	if *b.Resolution == 0 {
+		return nil
+	}
+
+	const minResolution = 100 * time.Millisecond
+	if *b.Resolution < minResolution {
+		return fmt.Errorf("%w: %s must be at least %s",
+			ErrBandwidthResolutionTooSmall, *b.Resolution, minResolution)
+	}
+
+	if *b.Window < *b.Resolution {
+		return fmt.Errorf("%w: %s must be at least the resolution %s",
+			ErrBandwidthWindowTooSmall, *b.Window, *b.Resolution)
+	}
+
+	return nil
+}
+
+func (b *Bandwidth) copy() (copied Bandwidth) {
+	return Bandwidth{
+		Resolution: helpers.CopyDurationPtr(b.Resolution),
+		Window:     helpers.CopyDurationPtr(b.Window),
+	}
+}
+
+// mergeWith merges the other settings into any
+// unset field of the receiver settings object.
+func (b *Bandwidth) mergeWith(other Bandwidth) {
+	b.Resolution = helpers.MergeWithDurationPtr(b.Resolution, other.Resolution)
+	b.Window = helpers.MergeWithDurationPtr(b.Window, other.Window)
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (b *Bandwidth) overrideWith(other Bandwidth) {
+	b.Resolution = helpers.OverrideWithDurationPtr(b.Resolution, other.Resolution)
+	b.Window = helpers.OverrideWithDurationPtr(b.Window, other.Window)
+}
+
+func (b *Bandwidth) setDefaults() {
+	b.Resolution = helpers.DefaultDurationPtr(b.Resolution, time.Second)
+	const defaultWindow = 5 * time.Minute
+	b.Window = helpers.DefaultDurationPtr(b.Window, defaultWindow)
+}
+
+func (b Bandwidth) String() string {
+	return b.toLinesNode().String()
+}
+
+func (b Bandwidth) toLinesNode() (node *gotree.Node) {
+	if *b.Resolution == 0 {
+		return nil
+	}
+
+	node = gotree.New("Bandwidth sampling settings:")
+	node.Appendf("Resolution: %s", *b.Resolution)
+	node.Appendf("Window: %s", *b.Window)
+	return node
+}
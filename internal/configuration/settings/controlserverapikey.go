@@ -0,0 +1,56 @@
+package settings
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gotree"
+)
+
+const (
+	// APIKeyRoleAdmin allows all the control server routes.
+	APIKeyRoleAdmin = "admin"
+	// APIKeyRoleReadOnly only allows the control server
+	// routes not changing the state of gluetun.
+	APIKeyRoleReadOnly = "readonly"
+)
+
+// ControlServerAPIKey is a static API key accepted by the control
+// server, with the role defining which routes it can access.
+type ControlServerAPIKey struct {
+	// Role is the role of the key, and can be
+	// APIKeyRoleAdmin or APIKeyRoleReadOnly.
+	Role string
+	// Key is the API key value. It cannot be the empty string.
+	Key string
+}
+
+func (c ControlServerAPIKey) validate() (err error) {
+	switch c.Role {
+	case APIKeyRoleAdmin, APIKeyRoleReadOnly:
+	default:
+		return fmt.Errorf("%w: %q must be one of %s or %s", ErrAPIKeyRoleNotValid,
+			c.Role, APIKeyRoleAdmin, APIKeyRoleReadOnly)
+	}
+
+	const minKeyLength = 16
+	if len(c.Key) < minKeyLength {
+		return fmt.Errorf("%w: %d characters must be at least %d",
+			ErrAPIKeyTooShort, len(c.Key), minKeyLength)
+	}
+
+	return nil
+}
+
+func copyControlServerAPIKeys(original []ControlServerAPIKey) (
+	copied []ControlServerAPIKey) {
+	if original == nil {
+		return nil
+	}
+	copied = make([]ControlServerAPIKey, len(original))
+	copy(copied, original)
+	return copied
+}
+
+func (c ControlServerAPIKey) toLinesNode() (node *gotree.Node) {
+	return gotree.New("%s: [set]", c.Role)
+}
@@ -0,0 +1,162 @@
+package settings
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gotree"
+)
+
+// DDNS contains settings to update dynamic DNS records
+// with the public IP address of the VPN tunnel.
+type DDNS struct {
+	// Enabled is true if dynamic DNS records should be updated.
+	// It defaults to false and cannot be nil in the internal state.
+	Enabled *bool
+	// Provider is the dynamic DNS provider which can be
+	// 'cloudflare', 'duckdns' or 'dyndns2'.
+	// It cannot be the empty string if Enabled is true.
+	Provider string
+	// Hostnames are the hostnames to update.
+	// It cannot be empty if Enabled is true.
+	Hostnames []string
+	// Token is the Cloudflare API token or the DuckDNS token.
+	// It cannot be nil in the internal state.
+	Token *string
+	// ZoneID is the Cloudflare zone identifier of the
+	// hostnames. It cannot be nil in the internal state.
+	ZoneID *string
+	// Username is the dyndns2 username.
+	// It cannot be nil in the internal state.
+	Username *string
+	// Password is the dyndns2 password.
+	// It cannot be nil in the internal state.
+	Password *string
+	// URL is the dyndns2 update URL, for example
+	// https://dynupdate.no-ip.com/nic/update.
+	// It cannot be nil in the internal state.
+	URL *string
+}
+
+func (d DDNS) validate() (err error) {
+	if !*d.Enabled {
+		return nil
+	}
+
+	if len(d.Hostnames) == 0 {
+		return fmt.Errorf("%w", ErrDDNSHostnamesNotSet)
+	}
+	for _, hostname := range d.Hostnames {
+		if !hostRegex.MatchString(hostname) {
+			return fmt.Errorf("%w: %s", ErrDDNSHostnameNotValid, hostname)
+		}
+	}
+
+	switch d.Provider {
+	case constants.DDNSCloudflare:
+		if *d.Token == "" {
+			return fmt.Errorf("%w: for provider %s", ErrDDNSTokenNotSet, d.Provider)
+		} else if *d.ZoneID == "" {
+			return fmt.Errorf("%w", ErrDDNSZoneIDNotSet)
+		}
+	case constants.DDNSDuckDNS:
+		if *d.Token == "" {
+			return fmt.Errorf("%w: for provider %s", ErrDDNSTokenNotSet, d.Provider)
+		}
+	case constants.DDNSDynDNS2:
+		if *d.URL == "" {
+			return fmt.Errorf("%w", ErrDDNSURLNotSet)
+		}
+		_, err = url.ParseRequestURI(*d.URL)
+		if err != nil {
+			return fmt.Errorf("update URL is not valid: %w", err)
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrDDNSProviderNotValid, d.Provider)
+	}
+
+	return nil
+}
+
+func (d *DDNS) copy() (copied DDNS) {
+	return DDNS{
+		Enabled:   helpers.CopyBoolPtr(d.Enabled),
+		Provider:  d.Provider,
+		Hostnames: helpers.CopyStringSlice(d.Hostnames),
+		Token:     helpers.CopyStringPtr(d.Token),
+		ZoneID:    helpers.CopyStringPtr(d.ZoneID),
+		Username:  helpers.CopyStringPtr(d.Username),
+		Password:  helpers.CopyStringPtr(d.Password),
+		URL:       helpers.CopyStringPtr(d.URL),
+	}
+}
+
+// mergeWith merges the other settings into any
+// unset field of the receiver settings object.
+func (d *DDNS) mergeWith(other DDNS) {
+	d.Enabled = helpers.MergeWithBool(d.Enabled, other.Enabled)
+	d.Provider = helpers.MergeWithString(d.Provider, other.Provider)
+	d.Hostnames = helpers.MergeStringSlices(d.Hostnames, other.Hostnames)
+	d.Token = helpers.MergeWithStringPtr(d.Token, other.Token)
+	d.ZoneID = helpers.MergeWithStringPtr(d.ZoneID, other.ZoneID)
+	d.Username = helpers.MergeWithStringPtr(d.Username, other.Username)
+	d.Password = helpers.MergeWithStringPtr(d.Password, other.Password)
+	d.URL = helpers.MergeWithStringPtr(d.URL, other.URL)
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (d *DDNS) overrideWith(other DDNS) {
+	d.Enabled = helpers.OverrideWithBool(d.Enabled, other.Enabled)
+	d.Provider = helpers.OverrideWithString(d.Provider, other.Provider)
+	d.Hostnames = helpers.OverrideWithStringSlice(d.Hostnames, other.Hostnames)
+	d.Token = helpers.OverrideWithStringPtr(d.Token, other.Token)
+	d.ZoneID = helpers.OverrideWithStringPtr(d.ZoneID, other.ZoneID)
+	d.Username = helpers.OverrideWithStringPtr(d.Username, other.Username)
+	d.Password = helpers.OverrideWithStringPtr(d.Password, other.Password)
+	d.URL = helpers.OverrideWithStringPtr(d.URL, other.URL)
+}
+
+func (d *DDNS) setDefaults() {
+	d.Enabled = helpers.DefaultBool(d.Enabled, false)
+	d.Token = helpers.DefaultStringPtr(d.Token, "")
+	d.ZoneID = helpers.DefaultStringPtr(d.ZoneID, "")
+	d.Username = helpers.DefaultStringPtr(d.Username, "")
+	d.Password = helpers.DefaultStringPtr(d.Password, "")
+	d.URL = helpers.DefaultStringPtr(d.URL, "")
+}
+
+func (d DDNS) String() string {
+	return d.toLinesNode().String()
+}
+
+func (d DDNS) toLinesNode() (node *gotree.Node) {
+	if !*d.Enabled {
+		return nil
+	}
+
+	node = gotree.New("Dynamic DNS settings:")
+	node.Appendf("Provider: %s", d.Provider)
+
+	hostnamesNode := node.Appendf("Hostnames:")
+	for _, hostname := range d.Hostnames {
+		hostnamesNode.Appendf(hostname)
+	}
+
+	switch d.Provider {
+	case constants.DDNSCloudflare:
+		node.Appendf("Token: %s", helpers.ObfuscatePassword(*d.Token))
+		node.Appendf("Zone ID: %s", *d.ZoneID)
+	case constants.DDNSDuckDNS:
+		node.Appendf("Token: %s", helpers.ObfuscatePassword(*d.Token))
+	case constants.DDNSDynDNS2:
+		node.Appendf("Update URL: %s", *d.URL)
+		node.Appendf("Username: %s", *d.Username)
+		node.Appendf("Password: %s", helpers.ObfuscatePassword(*d.Password))
+	}
+
+	return node
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 
+	"github.com/qdm12/dns/pkg/provider"
 	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
 	"github.com/qdm12/gotree"
 )
@@ -23,9 +24,21 @@ type DNS struct {
 	// It defaults to false and cannot be nil in the
 	// internal state.
 	KeepNameserver *bool
+	// FilterAAAA is true to remove AAAA records from the
+	// DNS answers if IPv6 is not supported, so programs do
+	// not try to reach unreachable IPv6 addresses.
+	// It defaults to true and cannot be nil in the
+	// internal state.
+	FilterAAAA *bool
 	// DOT contains settings to configure the DoT
 	// server.
 	DoT DoT
+	// LAN contains settings to serve the DoT
+	// server to clients outside the container.
+	LAN DNSLAN
+	// SecureServer contains settings to serve DNS
+	// over TLS and over HTTPS to clients.
+	SecureServer SecureDNSServer
 }
 
 func (d DNS) validate() (err error) {
@@ -34,6 +47,11 @@ func (d DNS) validate() (err error) {
 		return fmt.Errorf("validating DoT settings: %w", err)
 	}
 
+	err = d.SecureServer.validate()
+	if err != nil {
+		return fmt.Errorf("validating secure DNS server settings: %w", err)
+	}
+
 	return nil
 }
 
@@ -41,7 +59,10 @@ func (d *DNS) Copy() (copied DNS) {
 	return DNS{
 		ServerAddress:  helpers.CopyIP(d.ServerAddress),
 		KeepNameserver: helpers.CopyBoolPtr(d.KeepNameserver),
+		FilterAAAA:     helpers.CopyBoolPtr(d.FilterAAAA),
 		DoT:            d.DoT.copy(),
+		LAN:            d.LAN.copy(),
+		SecureServer:   d.SecureServer.copy(),
 	}
 }
 
@@ -50,7 +71,10 @@ func (d *DNS) Copy() (copied DNS) {
 func (d *DNS) mergeWith(other DNS) {
 	d.ServerAddress = helpers.MergeWithIP(d.ServerAddress, other.ServerAddress)
 	d.KeepNameserver = helpers.MergeWithBool(d.KeepNameserver, other.KeepNameserver)
+	d.FilterAAAA = helpers.MergeWithBool(d.FilterAAAA, other.FilterAAAA)
 	d.DoT.mergeWith(other.DoT)
+	d.LAN.mergeWith(other.LAN)
+	d.SecureServer.mergeWith(other.SecureServer)
 }
 
 // overrideWith overrides fields of the receiver
@@ -59,14 +83,68 @@ func (d *DNS) mergeWith(other DNS) {
 func (d *DNS) overrideWith(other DNS) {
 	d.ServerAddress = helpers.OverrideWithIP(d.ServerAddress, other.ServerAddress)
 	d.KeepNameserver = helpers.OverrideWithBool(d.KeepNameserver, other.KeepNameserver)
+	d.FilterAAAA = helpers.OverrideWithBool(d.FilterAAAA, other.FilterAAAA)
 	d.DoT.overrideWith(other.DoT)
+	d.LAN.overrideWith(other.LAN)
+	d.SecureServer.overrideWith(other.SecureServer)
 }
 
 func (d *DNS) setDefaults() {
 	localhost := net.IPv4(127, 0, 0, 1) //nolint:gomnd
 	d.ServerAddress = helpers.DefaultIP(d.ServerAddress, localhost)
 	d.KeepNameserver = helpers.DefaultBool(d.KeepNameserver, false)
+	d.FilterAAAA = helpers.DefaultBool(d.FilterAAAA, true)
 	d.DoT.setDefaults()
+	d.LAN.setDefaults()
+	d.SecureServer.setDefaults()
+}
+
+// ServerIPs returns the IP addresses of the DNS servers gluetun can
+// reach through the VPN: the DNS upstreams, the DNS over TLS servers
+// of the providers, the plaintext DNS servers of the providers used
+// as fallback, and the DNS server address if it is not the loopback.
+func (d DNS) ServerIPs() (ips []net.IP, err error) {
+	if !d.ServerAddress.IsLoopback() {
+		ips = append(ips, d.ServerAddress)
+	}
+
+	upstreams, err := d.DoT.ForwarderUpstreams()
+	if err != nil {
+		return nil, err
+	}
+	for _, upstream := range upstreams {
+		host, _, err := net.SplitHostPort(upstream.Address)
+		if err != nil {
+			return nil, fmt.Errorf("upstream address %s: %w", upstream.Address, err)
+		}
+		ips = append(ips, net.ParseIP(host))
+	}
+
+	for _, name := range d.DoT.Unbound.Providers {
+		provider, err := provider.Parse(name)
+		if err != nil {
+			return nil, err
+		}
+		server := provider.DNS()
+		ips = append(ips, server.IPv4...)
+		ips = append(ips, server.IPv6...)
+	}
+
+	return uniqueIPs(ips), nil
+}
+
+func uniqueIPs(ips []net.IP) (unique []net.IP) {
+	seen := make(map[string]struct{}, len(ips))
+	unique = make([]net.IP, 0, len(ips))
+	for _, ip := range ips {
+		key := ip.String()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, ip)
+	}
+	return unique
 }
 
 func (d DNS) String() string {
@@ -77,6 +155,9 @@ func (d DNS) toLinesNode() (node *gotree.Node) {
 	node = gotree.New("DNS settings:")
 	node.Appendf("DNS server address to use: %s", d.ServerAddress)
 	node.Appendf("Keep existing nameserver(s): %s", helpers.BoolPtrToYesNo(d.KeepNameserver))
+	node.Appendf("Filter AAAA records without IPv6 support: %s", helpers.BoolPtrToYesNo(d.FilterAAAA))
 	node.AppendNode(d.DoT.toLinesNode())
+	node.AppendNode(d.LAN.toLinesNode())
+	node.AppendNode(d.SecureServer.toLinesNode())
 	return node
 }
@@ -20,6 +20,14 @@ type DNSBlacklist struct {
 	AddBlockedHosts      []string
 	AddBlockedIPs        []netaddr.IP
 	AddBlockedIPPrefixes []netaddr.IPPrefix
+	// BlockListFiles are paths of local files listing hostnames
+	// to block, either in the hosts format or one hostname per
+	// line. They are watched for changes.
+	BlockListFiles []string
+	// AllowListFiles are paths of local files listing hostnames
+	// to allow, in the same formats as BlockListFiles. They are
+	// watched for changes.
+	AllowListFiles []string
 }
 
 func (b *DNSBlacklist) setDefaults() {
@@ -30,6 +38,12 @@ func (b *DNSBlacklist) setDefaults() {
 
 var hostRegex = regexp.MustCompile(`^([a-zA-Z0-9]|[a-zA-Z0-9_][a-zA-Z0-9\-_]{0,61}[a-zA-Z0-9_])(\.([a-zA-Z0-9]|[a-zA-Z0-9_][a-zA-Z0-9\-_]{0,61}[a-zA-Z0-9]))*$`) //nolint:lll
 
+// IsValidHost returns true if the host is a valid hostname
+// to block or allow.
+func IsValidHost(host string) bool {
+	return hostRegex.MatchString(host)
+}
+
 var (
 	ErrAllowedHostNotValid = errors.New("allowed host is not valid")
 	ErrBlockedHostNotValid = errors.New("blocked host is not valid")
@@ -60,6 +74,8 @@ func (b DNSBlacklist) copy() (copied DNSBlacklist) {
 		AddBlockedHosts:      helpers.CopyStringSlice(b.AddBlockedHosts),
 		AddBlockedIPs:        helpers.CopyNetaddrIPsSlice(b.AddBlockedIPs),
 		AddBlockedIPPrefixes: helpers.CopyIPPrefixSlice(b.AddBlockedIPPrefixes),
+		BlockListFiles:       helpers.CopyStringSlice(b.BlockListFiles),
+		AllowListFiles:       helpers.CopyStringSlice(b.AllowListFiles),
 	}
 }
 
@@ -71,6 +87,8 @@ func (b *DNSBlacklist) mergeWith(other DNSBlacklist) {
 	b.AddBlockedHosts = helpers.MergeStringSlices(b.AddBlockedHosts, other.AddBlockedHosts)
 	b.AddBlockedIPs = helpers.MergeNetaddrIPsSlices(b.AddBlockedIPs, other.AddBlockedIPs)
 	b.AddBlockedIPPrefixes = helpers.MergeIPPrefixesSlices(b.AddBlockedIPPrefixes, other.AddBlockedIPPrefixes)
+	b.BlockListFiles = helpers.MergeStringSlices(b.BlockListFiles, other.BlockListFiles)
+	b.AllowListFiles = helpers.MergeStringSlices(b.AllowListFiles, other.AllowListFiles)
 }
 
 func (b *DNSBlacklist) overrideWith(other DNSBlacklist) {
@@ -81,6 +99,8 @@ func (b *DNSBlacklist) overrideWith(other DNSBlacklist) {
 	b.AddBlockedHosts = helpers.OverrideWithStringSlice(b.AddBlockedHosts, other.AddBlockedHosts)
 	b.AddBlockedIPs = helpers.OverrideWithNetaddrIPsSlice(b.AddBlockedIPs, other.AddBlockedIPs)
 	b.AddBlockedIPPrefixes = helpers.OverrideWithIPPrefixesSlice(b.AddBlockedIPPrefixes, other.AddBlockedIPPrefixes)
+	b.BlockListFiles = helpers.OverrideWithStringSlice(b.BlockListFiles, other.BlockListFiles)
+	b.AllowListFiles = helpers.OverrideWithStringSlice(b.AllowListFiles, other.AllowListFiles)
 }
 
 func (b DNSBlacklist) ToBlacklistFormat() (settings blacklist.BuilderSettings, err error) {
@@ -134,5 +154,19 @@ func (b DNSBlacklist) toLinesNode() (node *gotree.Node) {
 		}
 	}
 
+	if len(b.BlockListFiles) > 0 {
+		blockListFilesNode := node.Appendf("Block list files:")
+		for _, path := range b.BlockListFiles {
+			blockListFilesNode.Appendf(path)
+		}
+	}
+
+	if len(b.AllowListFiles) > 0 {
+		allowListFilesNode := node.Appendf("Allow list files:")
+		for _, path := range b.AllowListFiles {
+			allowListFilesNode.Appendf(path)
+		}
+	}
+
 	return node
 }
@@ -0,0 +1,63 @@
+package settings
+
+import (
+	"net"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// DNSLAN contains settings to serve the DNS over TLS server
+// to clients outside the container, such as LAN devices.
+type DNSLAN struct {
+	// Enabled is true to listen on all the container addresses
+	// instead of only on 127.0.0.1, and to allow DNS queries from
+	// the subnets through the firewall. It defaults to true, and
+	// cannot be nil in the internal state.
+	Enabled *bool
+	// Subnets are the source subnets of the clients allowed
+	// through the firewall to query the DNS server. If empty,
+	// DNS queries are not restricted by source subnet.
+	Subnets []net.IPNet
+}
+
+func (d *DNSLAN) copy() (copied DNSLAN) {
+	return DNSLAN{
+		Enabled: helpers.CopyBoolPtr(d.Enabled),
+		Subnets: helpers.CopyIPNetSlice(d.Subnets),
+	}
+}
+
+func (d *DNSLAN) mergeWith(other DNSLAN) {
+	d.Enabled = helpers.MergeWithBool(d.Enabled, other.Enabled)
+	d.Subnets = helpers.MergeIPNetsSlices(d.Subnets, other.Subnets)
+}
+
+func (d *DNSLAN) overrideWith(other DNSLAN) {
+	d.Enabled = helpers.OverrideWithBool(d.Enabled, other.Enabled)
+	d.Subnets = helpers.OverrideWithIPNetsSlice(d.Subnets, other.Subnets)
+}
+
+func (d *DNSLAN) setDefaults() {
+	d.Enabled = helpers.DefaultBool(d.Enabled, true)
+}
+
+func (d DNSLAN) String() string {
+	return d.toLinesNode().String()
+}
+
+func (d DNSLAN) toLinesNode() (node *gotree.Node) {
+	node = gotree.New("Serve DNS to LAN clients: %s", helpers.BoolPtrToYesNo(d.Enabled))
+	if !*d.Enabled {
+		return node
+	}
+
+	subnetsNode := node.Appendf("Allowed subnets:")
+	if len(d.Subnets) == 0 {
+		subnetsNode.Appendf("all")
+	}
+	for _, subnet := range d.Subnets {
+		subnetsNode.Appendf(subnet.String())
+	}
+	return node
+}
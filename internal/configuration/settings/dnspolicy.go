@@ -0,0 +1,111 @@
+package settings
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+	"inet.af/netaddr"
+)
+
+// DNSPolicy contains settings applied by the DNS over TLS
+// server to the clients with a source IP address in one
+// of its subnets.
+type DNSPolicy struct {
+	// Subnets are the source IP networks of the clients
+	// the policy applies to. It cannot be empty.
+	Subnets []netaddr.IPPrefix
+	// BlockMalicious, BlockAds and BlockSurveillance are
+	// true to block the corresponding hostnames for the
+	// clients of the policy, in addition to the hostnames
+	// blocked for all clients.
+	// They default to false and cannot be nil in the
+	// internal state.
+	BlockMalicious    *bool
+	BlockAds          *bool
+	BlockSurveillance *bool
+	// SafeSearch is true to enforce safe search on
+	// the Google, Bing, DuckDuckGo and Youtube search
+	// engines for the clients of the policy.
+	// It defaults to false and cannot be nil in the
+	// internal state.
+	SafeSearch *bool
+	// LogQueries is true to log DNS queries from
+	// the clients of the policy.
+	// It defaults to false and cannot be nil in the
+	// internal state.
+	LogQueries *bool
+}
+
+func (d DNSPolicy) validate() (err error) {
+	if len(d.Subnets) == 0 {
+		return fmt.Errorf("%w", ErrDNSPolicySubnetsNotSet)
+	}
+
+	for _, subnet := range d.Subnets {
+		if subnet.IsZero() {
+			return fmt.Errorf("%w: %s", ErrDNSPolicySubnetNotValid, subnet)
+		}
+	}
+
+	return nil
+}
+
+func (d DNSPolicy) copy() (copied DNSPolicy) {
+	return DNSPolicy{
+		Subnets:           helpers.CopyIPPrefixSlice(d.Subnets),
+		BlockMalicious:    helpers.CopyBoolPtr(d.BlockMalicious),
+		BlockAds:          helpers.CopyBoolPtr(d.BlockAds),
+		BlockSurveillance: helpers.CopyBoolPtr(d.BlockSurveillance),
+		SafeSearch:        helpers.CopyBoolPtr(d.SafeSearch),
+		LogQueries:        helpers.CopyBoolPtr(d.LogQueries),
+	}
+}
+
+func copyDNSPolicies(original []DNSPolicy) (copied []DNSPolicy) {
+	if original == nil {
+		return nil
+	}
+	copied = make([]DNSPolicy, len(original))
+	for i, policy := range original {
+		copied[i] = policy.copy()
+	}
+	return copied
+}
+
+func (d *DNSPolicy) setDefaults() {
+	d.BlockMalicious = helpers.DefaultBool(d.BlockMalicious, false)
+	d.BlockAds = helpers.DefaultBool(d.BlockAds, false)
+	d.BlockSurveillance = helpers.DefaultBool(d.BlockSurveillance, false)
+	d.SafeSearch = helpers.DefaultBool(d.SafeSearch, false)
+	d.LogQueries = helpers.DefaultBool(d.LogQueries, false)
+}
+
+func (d DNSPolicy) toLinesNode(number int) (node *gotree.Node) {
+	node = gotree.New("Policy %d:", number)
+
+	subnetsNode := node.Appendf("Client subnets:")
+	for _, subnet := range d.Subnets {
+		subnetsNode.Appendf(subnet.String())
+	}
+
+	node.Appendf("Block malicious: %s", helpers.BoolPtrToYesNo(d.BlockMalicious))
+	node.Appendf("Block ads: %s", helpers.BoolPtrToYesNo(d.BlockAds))
+	node.Appendf("Block surveillance: %s", helpers.BoolPtrToYesNo(d.BlockSurveillance))
+	node.Appendf("Safe search: %s", helpers.BoolPtrToYesNo(d.SafeSearch))
+	node.Appendf("Log queries: %s", helpers.BoolPtrToYesNo(d.LogQueries))
+
+	return node
+}
+
+func dnsPoliciesToLinesNode(policies []DNSPolicy) (node *gotree.Node) {
+	if len(policies) == 0 {
+		return nil
+	}
+
+	node = gotree.New("Client policies:")
+	for i, policy := range policies {
+		node.AppendNode(policy.toLinesNode(i + 1))
+	}
+	return node
+}
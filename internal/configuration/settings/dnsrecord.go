@@ -0,0 +1,75 @@
+package settings
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gotree"
+)
+
+// DNSRecord is a static DNS record answered directly by
+// the DNS over TLS server, without forwarding the query.
+type DNSRecord struct {
+	// Name is the domain name of the record, such as nas.home.
+	Name string
+	// Type is the record type, which can be A, AAAA or CNAME.
+	Type string
+	// Value is the IPv4 address for an A record, the IPv6
+	// address for an AAAA record or the target hostname
+	// for a CNAME record.
+	Value string
+}
+
+func (d DNSRecord) validate() (err error) {
+	if !hostRegex.MatchString(d.Name) {
+		return fmt.Errorf("%w: name %q", ErrDNSRecordNotValid, d.Name)
+	}
+
+	ip := net.ParseIP(d.Value)
+	switch d.Type {
+	case "A":
+		if ip == nil || ip.To4() == nil {
+			return fmt.Errorf("%w: value %q is not an IPv4 address for an A record",
+				ErrDNSRecordNotValid, d.Value)
+		}
+	case "AAAA":
+		if ip == nil || ip.To4() != nil {
+			return fmt.Errorf("%w: value %q is not an IPv6 address for an AAAA record",
+				ErrDNSRecordNotValid, d.Value)
+		}
+	case "CNAME":
+		if !hostRegex.MatchString(d.Value) {
+			return fmt.Errorf("%w: value %q is not a hostname for a CNAME record",
+				ErrDNSRecordNotValid, d.Value)
+		}
+	default:
+		return fmt.Errorf("%w: type %q can only be one of A, AAAA, CNAME",
+			ErrDNSRecordNotValid, d.Type)
+	}
+
+	return nil
+}
+
+func copyDNSRecords(original []DNSRecord) (copied []DNSRecord) {
+	if original == nil {
+		return nil
+	}
+	copied = make([]DNSRecord, len(original))
+	copy(copied, original)
+	return copied
+}
+
+func dnsRecordsToLinesNode(records []DNSRecord, hostsFile string) (node *gotree.Node) {
+	if len(records) == 0 && hostsFile == "" {
+		return nil
+	}
+
+	node = gotree.New("DNS local records:")
+	if hostsFile != "" {
+		node.Appendf("Hosts file: %s", hostsFile)
+	}
+	for _, record := range records {
+		node.Appendf("%s %s %s", record.Name, record.Type, record.Value)
+	}
+	return node
+}
@@ -0,0 +1,62 @@
+package settings
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/qdm12/gotree"
+)
+
+// DNSRewrite is a rule to rewrite the answers of the
+// DNS over TLS server for a domain.
+type DNSRewrite struct {
+	// Domain is the domain to rewrite answers for, such as
+	// example.com, or *.example.com to match the domain and
+	// all its subdomains.
+	Domain string
+	// Target is either an IP address to answer with, or a
+	// hostname whose IP addresses are answered with, which
+	// flattens any CNAME chain of the target hostname.
+	Target string
+}
+
+// IsIP returns true if the rewrite target is an IP address.
+func (d DNSRewrite) IsIP() bool {
+	return net.ParseIP(d.Target) != nil
+}
+
+func (d DNSRewrite) validate() (err error) {
+	domain := strings.TrimPrefix(d.Domain, "*.")
+	if !hostRegex.MatchString(domain) {
+		return fmt.Errorf("%w: domain %q", ErrDNSRewriteNotValid, d.Domain)
+	}
+
+	if !d.IsIP() && !hostRegex.MatchString(d.Target) {
+		return fmt.Errorf("%w: target %q is neither an IP address nor a hostname",
+			ErrDNSRewriteNotValid, d.Target)
+	}
+
+	return nil
+}
+
+func copyDNSRewrites(original []DNSRewrite) (copied []DNSRewrite) {
+	if original == nil {
+		return nil
+	}
+	copied = make([]DNSRewrite, len(original))
+	copy(copied, original)
+	return copied
+}
+
+func dnsRewritesToLinesNode(rewrites []DNSRewrite) (node *gotree.Node) {
+	if len(rewrites) == 0 {
+		return nil
+	}
+
+	node = gotree.New("DNS rewrites:")
+	for _, rewrite := range rewrites {
+		node.Appendf("%s -> %s", rewrite.Domain, rewrite.Target)
+	}
+	return node
+}
@@ -0,0 +1,132 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+	"github.com/qdm12/govalid/address"
+)
+
+// SecureDNSServer contains settings to serve DNS over TLS (DoT)
+// and DNS over HTTPS (DoH) to clients, for example on the LAN,
+// forwarding their queries to the DNS server address.
+type SecureDNSServer struct {
+	// Enabled is true if the secure DNS server should run.
+	// It cannot be nil in the internal state.
+	Enabled *bool
+	// DoTAddress is the DNS over TLS listening address.
+	// It can be the empty string to not serve DNS over TLS,
+	// and cannot be nil in the internal state.
+	DoTAddress *string
+	// DoHAddress is the DNS over HTTPS listening address,
+	// serving queries on the /dns-query path.
+	// It can be the empty string to not serve DNS over HTTPS,
+	// and cannot be nil in the internal state.
+	DoHAddress *string
+	// CertificateFile and KeyFile are the paths to the PEM encoded
+	// TLS certificate and private key files. If both are empty,
+	// a self-signed certificate is generated and stored in /gluetun,
+	// which clients must then be configured to trust.
+	// They cannot be nil in the internal state.
+	CertificateFile *string
+	KeyFile         *string
+	// Hostnames are the hostnames and IP addresses the generated
+	// self-signed certificate is valid for. It defaults to gluetun.
+	Hostnames []string
+}
+
+func (s SecureDNSServer) validate() (err error) {
+	if !*s.Enabled {
+		return nil
+	}
+
+	if *s.DoTAddress == "" && *s.DoHAddress == "" {
+		return fmt.Errorf("%w", ErrSecureDNSServerNoAddress)
+	}
+
+	uid := os.Getuid()
+	for _, listeningAddress := range []string{*s.DoTAddress, *s.DoHAddress} {
+		if listeningAddress == "" {
+			continue
+		}
+		_, err = address.Validate(listeningAddress, address.OptionListening(uid))
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrServerAddressNotValid, listeningAddress)
+		}
+	}
+
+	if (*s.CertificateFile == "") != (*s.KeyFile == "") {
+		return fmt.Errorf("%w", ErrTLSKeyPairPartial)
+	}
+
+	return nil
+}
+
+func (s *SecureDNSServer) copy() (copied SecureDNSServer) {
+	return SecureDNSServer{
+		Enabled:         helpers.CopyBoolPtr(s.Enabled),
+		DoTAddress:      helpers.CopyStringPtr(s.DoTAddress),
+		DoHAddress:      helpers.CopyStringPtr(s.DoHAddress),
+		CertificateFile: helpers.CopyStringPtr(s.CertificateFile),
+		KeyFile:         helpers.CopyStringPtr(s.KeyFile),
+		Hostnames:       helpers.CopyStringSlice(s.Hostnames),
+	}
+}
+
+func (s *SecureDNSServer) mergeWith(other SecureDNSServer) {
+	s.Enabled = helpers.MergeWithBool(s.Enabled, other.Enabled)
+	s.DoTAddress = helpers.MergeWithStringPtr(s.DoTAddress, other.DoTAddress)
+	s.DoHAddress = helpers.MergeWithStringPtr(s.DoHAddress, other.DoHAddress)
+	s.CertificateFile = helpers.MergeWithStringPtr(s.CertificateFile, other.CertificateFile)
+	s.KeyFile = helpers.MergeWithStringPtr(s.KeyFile, other.KeyFile)
+	s.Hostnames = helpers.MergeStringSlices(s.Hostnames, other.Hostnames)
+}
+
+func (s *SecureDNSServer) overrideWith(other SecureDNSServer) {
+	s.Enabled = helpers.OverrideWithBool(s.Enabled, other.Enabled)
+	s.DoTAddress = helpers.OverrideWithStringPtr(s.DoTAddress, other.DoTAddress)
+	s.DoHAddress = helpers.OverrideWithStringPtr(s.DoHAddress, other.DoHAddress)
+	s.CertificateFile = helpers.OverrideWithStringPtr(s.CertificateFile, other.CertificateFile)
+	s.KeyFile = helpers.OverrideWithStringPtr(s.KeyFile, other.KeyFile)
+	s.Hostnames = helpers.OverrideWithStringSlice(s.Hostnames, other.Hostnames)
+}
+
+func (s *SecureDNSServer) setDefaults() {
+	s.Enabled = helpers.DefaultBool(s.Enabled, false)
+	s.DoTAddress = helpers.DefaultStringPtr(s.DoTAddress, ":853")
+	s.DoHAddress = helpers.DefaultStringPtr(s.DoHAddress, ":8443")
+	s.CertificateFile = helpers.DefaultStringPtr(s.CertificateFile, "")
+	s.KeyFile = helpers.DefaultStringPtr(s.KeyFile, "")
+	if s.Hostnames == nil {
+		s.Hostnames = []string{"gluetun"}
+	}
+}
+
+func (s SecureDNSServer) String() string {
+	return s.toLinesNode().String()
+}
+
+func (s SecureDNSServer) toLinesNode() (node *gotree.Node) {
+	if !*s.Enabled {
+		return nil
+	}
+
+	node = gotree.New("Secure DNS server settings:")
+	if *s.DoTAddress != "" {
+		node.Appendf("DNS over TLS listening address: %s", *s.DoTAddress)
+	}
+	if *s.DoHAddress != "" {
+		node.Appendf("DNS over HTTPS listening address: %s", *s.DoHAddress)
+	}
+
+	if *s.CertificateFile == "" {
+		node.Appendf("Self-signed certificate hostnames: %v", s.Hostnames)
+	} else {
+		node.Appendf("Certificate file: %s", *s.CertificateFile)
+		node.Appendf("Key file: %s", *s.KeyFile)
+	}
+
+	return node
+}
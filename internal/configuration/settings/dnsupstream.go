@@ -0,0 +1,96 @@
+package settings
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gotree"
+)
+
+// DNSUpstream is an encrypted DNS resolver queries are forwarded to
+// by a local forwarder, for protocols Unbound does not support.
+type DNSUpstream struct {
+	// Protocol is the protocol to reach the resolver with. It can be
+	// doq for DNS over QUIC, falling back on DNS over TLS on the same
+	// address if QUIC is blocked, dot for DNS over TLS, doh for DNS
+	// over HTTPS or dnscrypt for DNSCrypt version 2.
+	Protocol string
+	// Address is the IP address and port of the resolver.
+	Address string
+	// ServerName is the name the TLS certificate of the
+	// resolver is verified against, or the provider name
+	// for DNSCrypt, such as 2.dnscrypt-cert.example.com.
+	ServerName string
+	// PublicKey is the hex encoded Ed25519 public key of the
+	// DNSCrypt provider, used to verify the resolver certificates.
+	// It is only used for DNSCrypt.
+	PublicKey string
+}
+
+func (d DNSUpstream) validate() (err error) {
+	protocols := []string{constants.DNSUpstreamDoQ, constants.DNSUpstreamDoT,
+		constants.DNSUpstreamDoH, constants.DNSUpstreamDNSCrypt}
+	if !helpers.IsOneOf(d.Protocol, protocols...) {
+		return fmt.Errorf("%w: protocol %q can only be one of %s", ErrDNSUpstreamNotValid,
+			d.Protocol, strings.Join(protocols, ", "))
+	}
+
+	host, _, err := net.SplitHostPort(d.Address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDNSUpstreamNotValid, err)
+	} else if net.ParseIP(host) == nil {
+		return fmt.Errorf("%w: address %q is not an IP address",
+			ErrDNSUpstreamNotValid, host)
+	}
+
+	if !hostRegex.MatchString(d.ServerName) {
+		return fmt.Errorf("%w: server name %q", ErrDNSUpstreamNotValid, d.ServerName)
+	}
+
+	if d.Protocol == constants.DNSUpstreamDNSCrypt {
+		const publicKeyLength = 32
+		publicKey, err := hex.DecodeString(d.PublicKey)
+		if err != nil || len(publicKey) != publicKeyLength {
+			return fmt.Errorf("%w: public key %q is not %d hex encoded bytes",
+				ErrDNSUpstreamNotValid, d.PublicKey, publicKeyLength)
+		}
+	}
+
+	return nil
+}
+
+func (d DNSUpstream) String() string {
+	return d.Protocol + "://" + d.Address + "#" + d.ServerName
+}
+
+func copyDNSUpstreams(original []DNSUpstream) (copied []DNSUpstream) {
+	if original == nil {
+		return nil
+	}
+	copied = make([]DNSUpstream, len(original))
+	copy(copied, original)
+	return copied
+}
+
+func dnsUpstreamsToLinesNode(upstreams []DNSUpstream,
+	probePeriod time.Duration) (node *gotree.Node) {
+	if len(upstreams) == 0 {
+		return nil
+	}
+
+	node = gotree.New("Upstream resolvers:")
+	probe := "disabled"
+	if probePeriod > 0 {
+		probe = "every " + probePeriod.String()
+	}
+	node.Appendf("Health probe: %s", probe)
+	for _, upstream := range upstreams {
+		node.Appendf(upstream.String())
+	}
+	return node
+}
@@ -0,0 +1,118 @@
+package settings
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// DockerDependents contains settings to act on containers
+// sharing the gluetun network stack, for example with
+// `network_mode: container:gluetun`, once the VPN tunnel
+// is re-established, since their sockets are often stale.
+type DockerDependents struct {
+	// Enabled is true if dependent containers should be restarted
+	// or signaled after a VPN reconnection.
+	// It cannot be nil in the internal state.
+	Enabled *bool
+	// Endpoint is the Docker API endpoint, either a unix socket
+	// path such as unix:///var/run/docker.sock or the http(s)
+	// URL of a Docker socket proxy.
+	// It cannot be the empty string in the internal state.
+	Endpoint string
+	// Label is the label selector to find dependent containers,
+	// in the form key or key=value.
+	// It cannot be the empty string in the internal state.
+	Label string
+	// Action is the action to take on dependent containers,
+	// and can be 'restart' or 'sighup'.
+	// It cannot be the empty string in the internal state.
+	Action string
+}
+
+func (d DockerDependents) validate() (err error) {
+	if !*d.Enabled {
+		return nil
+	}
+
+	err = validateDockerEndpoint(d.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	if d.Label == "" || d.Label[0] == '=' {
+		return fmt.Errorf("%w: %q", ErrDockerLabelNotValid, d.Label)
+	}
+
+	if !helpers.IsOneOf(d.Action, "restart", "sighup") {
+		return fmt.Errorf("%w: %q must be one of restart or sighup",
+			ErrDockerActionNotValid, d.Action)
+	}
+
+	return nil
+}
+
+func validateDockerEndpoint(endpoint string) (err error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDockerEndpointNotValid, err)
+	}
+	if !helpers.IsOneOf(endpointURL.Scheme, "unix", "http", "https") {
+		return fmt.Errorf("%w: scheme %q must be one of unix, http or https",
+			ErrDockerEndpointNotValid, endpointURL.Scheme)
+	}
+	return nil
+}
+
+func (d *DockerDependents) copy() (copied DockerDependents) {
+	return DockerDependents{
+		Enabled:  helpers.CopyBoolPtr(d.Enabled),
+		Endpoint: d.Endpoint,
+		Label:    d.Label,
+		Action:   d.Action,
+	}
+}
+
+// mergeWith merges the other settings into any
+// unset field of the receiver settings object.
+func (d *DockerDependents) mergeWith(other DockerDependents) {
+	d.Enabled = helpers.MergeWithBool(d.Enabled, other.Enabled)
+	d.Endpoint = helpers.MergeWithString(d.Endpoint, other.Endpoint)
+	d.Label = helpers.MergeWithString(d.Label, other.Label)
+	d.Action = helpers.MergeWithString(d.Action, other.Action)
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (d *DockerDependents) overrideWith(other DockerDependents) {
+	d.Enabled = helpers.OverrideWithBool(d.Enabled, other.Enabled)
+	d.Endpoint = helpers.OverrideWithString(d.Endpoint, other.Endpoint)
+	d.Label = helpers.OverrideWithString(d.Label, other.Label)
+	d.Action = helpers.OverrideWithString(d.Action, other.Action)
+}
+
+func (d *DockerDependents) setDefaults() {
+	d.Enabled = helpers.DefaultBool(d.Enabled, false)
+	d.Endpoint = helpers.DefaultString(d.Endpoint, "unix:///var/run/docker.sock")
+	d.Label = helpers.DefaultString(d.Label, "gluetun.dependent=true")
+	d.Action = helpers.DefaultString(d.Action, "restart")
+}
+
+func (d DockerDependents) String() string {
+	return d.toLinesNode().String()
+}
+
+func (d DockerDependents) toLinesNode() (node *gotree.Node) {
+	if !*d.Enabled {
+		return nil
+	}
+
+	node = gotree.New("Docker dependent containers settings:")
+	node.Appendf("Docker endpoint: %s", d.Endpoint)
+	node.Appendf("Label selector: %s", d.Label)
+	node.Appendf("Action on reconnection: %s", d.Action)
+	return node
+}
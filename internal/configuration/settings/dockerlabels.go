@@ -0,0 +1,108 @@
+package settings
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// DockerLabels contains settings to apply policies set with
+// labels on the containers sharing the gluetun network stack,
+// such as extra allowed input ports or DNS allowed hosts.
+type DockerLabels struct {
+	// Enabled is true if the container labels should be
+	// watched and their policies applied.
+	// It cannot be nil in the internal state.
+	Enabled *bool
+	// Endpoint is the Docker API endpoint, either a unix socket
+	// path such as unix:///var/run/docker.sock or the http(s)
+	// URL of a Docker socket proxy.
+	// It cannot be the empty string in the internal state.
+	Endpoint string
+	// Container is the name or ID of the gluetun container,
+	// used to find the containers sharing its network stack.
+	// It can be the empty string to use the container hostname,
+	// which is the short container ID by default.
+	// It cannot be nil in the internal state.
+	Container *string
+	// Period is the period to list the containers and
+	// update the policies applied.
+	// It cannot be nil in the internal state.
+	Period *time.Duration
+}
+
+func (d DockerLabels) validate() (err error) {
+	if !*d.Enabled {
+		return nil
+	}
+
+	err = validateDockerEndpoint(d.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	if *d.Period <= 0 {
+		return fmt.Errorf("%w: %s must be positive",
+			ErrDockerLabelsPeriodNotValid, *d.Period)
+	}
+
+	return nil
+}
+
+func (d *DockerLabels) copy() (copied DockerLabels) {
+	return DockerLabels{
+		Enabled:   helpers.CopyBoolPtr(d.Enabled),
+		Endpoint:  d.Endpoint,
+		Container: helpers.CopyStringPtr(d.Container),
+		Period:    helpers.CopyDurationPtr(d.Period),
+	}
+}
+
+// mergeWith merges the other settings into any
+// unset field of the receiver settings object.
+func (d *DockerLabels) mergeWith(other DockerLabels) {
+	d.Enabled = helpers.MergeWithBool(d.Enabled, other.Enabled)
+	d.Endpoint = helpers.MergeWithString(d.Endpoint, other.Endpoint)
+	d.Container = helpers.MergeWithStringPtr(d.Container, other.Container)
+	d.Period = helpers.MergeWithDurationPtr(d.Period, other.Period)
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (d *DockerLabels) overrideWith(other DockerLabels) {
+	d.Enabled = helpers.OverrideWithBool(d.Enabled, other.Enabled)
+	d.Endpoint = helpers.OverrideWithString(d.Endpoint, other.Endpoint)
+	d.Container = helpers.OverrideWithStringPtr(d.Container, other.Container)
+	d.Period = helpers.OverrideWithDurationPtr(d.Period, other.Period)
+}
+
+func (d *DockerLabels) setDefaults() {
+	d.Enabled = helpers.DefaultBool(d.Enabled, false)
+	d.Endpoint = helpers.DefaultString(d.Endpoint, "unix:///var/run/docker.sock")
+	d.Container = helpers.DefaultStringPtr(d.Container, "")
+	const defaultPeriod = 30 * time.Second
+	d.Period = helpers.DefaultDurationPtr(d.Period, defaultPeriod)
+}
+
+func (d DockerLabels) String() string {
+	return d.toLinesNode().String()
+}
+
+func (d DockerLabels) toLinesNode() (node *gotree.Node) {
+	if !*d.Enabled {
+		return nil
+	}
+
+	node = gotree.New("Docker label policies settings:")
+	node.Appendf("Docker endpoint: %s", d.Endpoint)
+	container := *d.Container
+	if container == "" {
+		container = "[hostname]"
+	}
+	node.Appendf("Gluetun container: %s", container)
+	node.Appendf("Period: %s", *d.Period)
+	return node
+}
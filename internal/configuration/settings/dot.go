@@ -3,9 +3,13 @@ package settings
 import (
 	"errors"
 	"fmt"
+	"net"
+	"strconv"
 	"time"
 
+	"github.com/qdm12/dns/pkg/provider"
 	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gluetun/internal/constants"
 	"github.com/qdm12/gotree"
 )
 
@@ -26,10 +30,39 @@ type DoT struct {
 	// Blacklist contains settings to configure the filter
 	// block lists.
 	Blacklist DNSBlacklist
+	// Upstreams are encrypted DNS resolvers using protocols
+	// Unbound does not support, such as DNS over QUIC, DNS over HTTPS
+	// or DNSCrypt. If any is
+	// set, Unbound forwards queries to a local forwarder which
+	// sends them to the upstreams first, and then to the DNS over
+	// TLS servers of the providers if all the upstreams fail.
+	Upstreams []DNSUpstream
+	// UpstreamsProbePeriod is the period to probe the health of the
+	// upstreams, so unhealthy upstreams are tried last until they
+	// recover. It can be set to 0 to only detect failures and
+	// recoveries with the queries forwarded.
+	// It defaults to 30s and cannot be nil in the internal state.
+	UpstreamsProbePeriod *time.Duration
+	// Records are static DNS records answered directly,
+	// before forwarding queries to the upstream servers.
+	Records []DNSRecord
+	// HostsFile is the path of a hosts format file, where each
+	// line is an IP address followed by one or more hostnames,
+	// answered directly like Records. The file is watched for
+	// changes, and it is disabled if set to the empty string.
+	// It cannot be nil in the internal state.
+	HostsFile *string
+	// Rewrites are rules to rewrite the answers
+	// for specific domains.
+	Rewrites []DNSRewrite
+	// Policies are settings applied to clients
+	// depending on their source IP address.
+	Policies []DNSPolicy
 }
 
 var (
 	ErrDoTUpdatePeriodTooShort = errors.New("update period is too short")
+	ErrDoTProbePeriodNegative  = errors.New("upstreams probe period cannot be negative")
 )
 
 func (d DoT) validate() (err error) {
@@ -49,15 +82,53 @@ func (d DoT) validate() (err error) {
 		return err
 	}
 
+	for _, upstream := range d.Upstreams {
+		err = upstream.validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	if *d.UpstreamsProbePeriod < 0 {
+		return fmt.Errorf("%w: %s", ErrDoTProbePeriodNegative, *d.UpstreamsProbePeriod)
+	}
+
+	for i, policy := range d.Policies {
+		err = policy.validate()
+		if err != nil {
+			return fmt.Errorf("client policy %d: %w", i+1, err)
+		}
+	}
+
+	for _, record := range d.Records {
+		err = record.validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, rewrite := range d.Rewrites {
+		err = rewrite.validate()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 func (d *DoT) copy() (copied DoT) {
 	return DoT{
-		Enabled:      helpers.CopyBoolPtr(d.Enabled),
-		UpdatePeriod: helpers.CopyDurationPtr(d.UpdatePeriod),
-		Unbound:      d.Unbound.copy(),
-		Blacklist:    d.Blacklist.copy(),
+		Enabled:              helpers.CopyBoolPtr(d.Enabled),
+		UpdatePeriod:         helpers.CopyDurationPtr(d.UpdatePeriod),
+		Unbound:              d.Unbound.copy(),
+		Blacklist:            d.Blacklist.copy(),
+		Upstreams:            copyDNSUpstreams(d.Upstreams),
+		UpstreamsProbePeriod: helpers.CopyDurationPtr(d.UpstreamsProbePeriod),
+		Records:              copyDNSRecords(d.Records),
+		HostsFile:            helpers.CopyStringPtr(d.HostsFile),
+		Rewrites:             copyDNSRewrites(d.Rewrites),
+		Policies:             copyDNSPolicies(d.Policies),
 	}
 }
 
@@ -68,6 +139,20 @@ func (d *DoT) mergeWith(other DoT) {
 	d.UpdatePeriod = helpers.MergeWithDurationPtr(d.UpdatePeriod, other.UpdatePeriod)
 	d.Unbound.mergeWith(other.Unbound)
 	d.Blacklist.mergeWith(other.Blacklist)
+	if d.Upstreams == nil {
+		d.Upstreams = copyDNSUpstreams(other.Upstreams)
+	}
+	if d.Records == nil {
+		d.Records = copyDNSRecords(other.Records)
+	}
+	d.UpstreamsProbePeriod = helpers.MergeWithDurationPtr(d.UpstreamsProbePeriod, other.UpstreamsProbePeriod)
+	d.HostsFile = helpers.MergeWithStringPtr(d.HostsFile, other.HostsFile)
+	if d.Rewrites == nil {
+		d.Rewrites = copyDNSRewrites(other.Rewrites)
+	}
+	if d.Policies == nil {
+		d.Policies = copyDNSPolicies(other.Policies)
+	}
 }
 
 // overrideWith overrides fields of the receiver
@@ -78,6 +163,20 @@ func (d *DoT) overrideWith(other DoT) {
 	d.UpdatePeriod = helpers.OverrideWithDurationPtr(d.UpdatePeriod, other.UpdatePeriod)
 	d.Unbound.overrideWith(other.Unbound)
 	d.Blacklist.overrideWith(other.Blacklist)
+	if other.Upstreams != nil {
+		d.Upstreams = copyDNSUpstreams(other.Upstreams)
+	}
+	if other.Records != nil {
+		d.Records = copyDNSRecords(other.Records)
+	}
+	d.UpstreamsProbePeriod = helpers.OverrideWithDurationPtr(d.UpstreamsProbePeriod, other.UpstreamsProbePeriod)
+	d.HostsFile = helpers.OverrideWithStringPtr(d.HostsFile, other.HostsFile)
+	if other.Rewrites != nil {
+		d.Rewrites = copyDNSRewrites(other.Rewrites)
+	}
+	if other.Policies != nil {
+		d.Policies = copyDNSPolicies(other.Policies)
+	}
 }
 
 func (d *DoT) setDefaults() {
@@ -86,6 +185,39 @@ func (d *DoT) setDefaults() {
 	d.UpdatePeriod = helpers.DefaultDurationPtr(d.UpdatePeriod, defaultUpdatePeriod)
 	d.Unbound.setDefaults()
 	d.Blacklist.setDefaults()
+	const defaultProbePeriod = 30 * time.Second
+	d.UpstreamsProbePeriod = helpers.DefaultDurationPtr(d.UpstreamsProbePeriod, defaultProbePeriod)
+	d.HostsFile = helpers.DefaultStringPtr(d.HostsFile, "")
+	for i := range d.Policies {
+		d.Policies[i].setDefaults()
+	}
+}
+
+// ForwarderUpstreams returns the upstreams followed by the DNS over
+// TLS servers of the providers, in the order queries are sent to
+// them by the local forwarder.
+func (d DoT) ForwarderUpstreams() (upstreams []DNSUpstream, err error) {
+	upstreams = copyDNSUpstreams(d.Upstreams)
+	for _, name := range d.Unbound.Providers {
+		provider, err := provider.Parse(name)
+		if err != nil {
+			return nil, err
+		}
+		server := provider.DoT()
+		ips := server.IPv4
+		if *d.Unbound.IPv6 {
+			ips = append(ips, server.IPv6...)
+		}
+		port := strconv.Itoa(int(server.Port))
+		for _, ip := range ips {
+			upstreams = append(upstreams, DNSUpstream{
+				Protocol:   constants.DNSUpstreamDoT,
+				Address:    net.JoinHostPort(ip.String(), port),
+				ServerName: server.Name,
+			})
+		}
+	}
+	return upstreams, nil
 }
 
 func (d DoT) String() string {
@@ -107,7 +239,11 @@ func (d DoT) toLinesNode() (node *gotree.Node) {
 	node.Appendf("Update period: %s", update)
 
 	node.AppendNode(d.Unbound.toLinesNode())
+	node.AppendNode(dnsUpstreamsToLinesNode(d.Upstreams, *d.UpstreamsProbePeriod))
 	node.AppendNode(d.Blacklist.toLinesNode())
+	node.AppendNode(dnsRecordsToLinesNode(d.Records, *d.HostsFile))
+	node.AppendNode(dnsRewritesToLinesNode(d.Rewrites))
+	node.AppendNode(dnsPoliciesToLinesNode(d.Policies))
 
 	return node
 }
@@ -3,47 +3,167 @@ package settings
 import "errors"
 
 var (
-	ErrCityNotValid                    = errors.New("the city specified is not valid")
-	ErrControlServerPrivilegedPort     = errors.New("cannot use privileged port without running as root")
-	ErrCountryNotValid                 = errors.New("the country specified is not valid")
-	ErrFilepathMissing                 = errors.New("filepath is missing")
-	ErrFirewallZeroPort                = errors.New("cannot have a zero port to block")
-	ErrHostnameNotValid                = errors.New("the hostname specified is not valid")
-	ErrISPNotValid                     = errors.New("the ISP specified is not valid")
-	ErrMinRatioNotValid                = errors.New("minimum ratio is not valid")
-	ErrMissingValue                    = errors.New("missing value")
-	ErrNameNotValid                    = errors.New("the server name specified is not valid")
-	ErrOpenVPNClientKeyMissing         = errors.New("client key is missing")
-	ErrOpenVPNCustomPortNotAllowed     = errors.New("custom endpoint port is not allowed")
-	ErrOpenVPNEncryptionPresetNotValid = errors.New("PIA encryption preset is not valid")
-	ErrOpenVPNInterfaceNotValid        = errors.New("interface name is not valid")
-	ErrOpenVPNKeyPassphraseIsEmpty     = errors.New("key passphrase is empty")
-	ErrOpenVPNMSSFixIsTooHigh          = errors.New("mssfix option value is too high")
-	ErrOpenVPNPasswordIsEmpty          = errors.New("password is empty")
-	ErrOpenVPNTCPNotSupported          = errors.New("TCP protocol is not supported")
-	ErrOpenVPNUserIsEmpty              = errors.New("user is empty")
-	ErrOpenVPNVerbosityIsOutOfBounds   = errors.New("verbosity value is out of bounds")
-	ErrOpenVPNVersionIsNotValid        = errors.New("version is not valid")
-	ErrPortForwardingEnabled           = errors.New("port forwarding cannot be enabled")
-	ErrPublicIPPeriodTooShort          = errors.New("public IP address check period is too short")
-	ErrRegionNotValid                  = errors.New("the region specified is not valid")
-	ErrServerAddressNotValid           = errors.New("server listening address is not valid")
-	ErrSystemPGIDNotValid              = errors.New("process group id is not valid")
-	ErrSystemPUIDNotValid              = errors.New("process user id is not valid")
-	ErrSystemTimezoneNotValid          = errors.New("timezone is not valid")
-	ErrUpdaterPeriodTooSmall           = errors.New("VPN server data updater period is too small")
-	ErrVPNProviderNameNotValid         = errors.New("VPN provider name is not valid")
-	ErrVPNTypeNotValid                 = errors.New("VPN type is not valid")
-	ErrWireguardEndpointIPNotSet       = errors.New("endpoint IP is not set")
-	ErrWireguardEndpointPortNotAllowed = errors.New("endpoint port is not allowed")
-	ErrWireguardEndpointPortNotSet     = errors.New("endpoint port is not set")
-	ErrWireguardEndpointPortSet        = errors.New("endpoint port is set")
-	ErrWireguardInterfaceAddressNotSet = errors.New("interface address is not set")
-	ErrWireguardInterfaceAddressIPv6   = errors.New("interface address is IPv6 but IPv6 is not supported")
-	ErrWireguardInterfaceNotValid      = errors.New("interface name is not valid")
-	ErrWireguardPreSharedKeyNotSet     = errors.New("pre-shared key is not set")
-	ErrWireguardPrivateKeyNotSet       = errors.New("private key is not set")
-	ErrWireguardPublicKeyNotSet        = errors.New("public key is not set")
-	ErrWireguardPublicKeyNotValid      = errors.New("public key is not valid")
-	ErrWireguardImplementationNotValid = errors.New("implementation is not valid")
+	ErrAPIKeyRoleNotValid                   = errors.New("API key role is not valid")
+	ErrAPIKeyTooShort                       = errors.New("API key is too short")
+	ErrBandwidthResolutionTooSmall          = errors.New("bandwidth sampling resolution is too small")
+	ErrBandwidthWindowTooSmall              = errors.New("bandwidth sampling window is too small")
+	ErrCityNotValid                         = errors.New("the city specified is not valid")
+	ErrControlServerDoHNeedsTLS             = errors.New("DNS over HTTPS requires TLS to be enabled")
+	ErrControlServerPrivilegedPort          = errors.New("cannot use privileged port without running as root")
+	ErrCountryNotValid                      = errors.New("the country specified is not valid")
+	ErrDDNSHostnameNotValid                 = errors.New("dynamic DNS hostname is not valid")
+	ErrDDNSHostnamesNotSet                  = errors.New("dynamic DNS hostnames are not set")
+	ErrDDNSProviderNotValid                 = errors.New("dynamic DNS provider is not valid")
+	ErrDDNSTokenNotSet                      = errors.New("dynamic DNS token is not set")
+	ErrDDNSURLNotSet                        = errors.New("dynamic DNS update URL is not set")
+	ErrDDNSZoneIDNotSet                     = errors.New("Cloudflare zone ID is not set")
+	ErrDNSPolicySubnetNotValid              = errors.New("DNS policy subnet is not valid")
+	ErrDNSPolicySubnetsNotSet               = errors.New("DNS policy subnets are not set")
+	ErrDNSRecordNotValid                    = errors.New("DNS record is not valid")
+	ErrDNSRewriteNotValid                   = errors.New("DNS rewrite is not valid")
+	ErrDNSUpstreamNotValid                  = errors.New("DNS upstream is not valid")
+	ErrDockerActionNotValid                 = errors.New("docker dependents action is not valid")
+	ErrDockerEndpointNotValid               = errors.New("docker endpoint is not valid")
+	ErrDockerLabelNotValid                  = errors.New("docker label selector is not valid")
+	ErrDockerLabelsPeriodNotValid           = errors.New("docker labels period is not valid")
+	ErrFailoverThresholdTooSmall            = errors.New("failover threshold is too small")
+	ErrFilepathMissing                      = errors.New("filepath is missing")
+	ErrFirewallBackendNotValid              = errors.New("firewall backend is not valid")
+	ErrFirewallBypassCgroupsBackend         = errors.New("firewall bypass cgroups need the iptables backend")
+	ErrFirewallBypassDomainsDNSNotEnabled   = errors.New("firewall bypass domains need DNS over TLS")
+	ErrFirewallBypassMarkMissing            = errors.New("firewall bypass mark must be set to bypass cgroups")
+	ErrFirewallCountriesBothSet             = errors.New("firewall blocked and allowed countries cannot be both set")
+	ErrFirewallCountryNotValid              = errors.New("firewall country code is not valid")
+	ErrFirewallDomainNotValid               = errors.New("firewall domain is not valid")
+	ErrFirewallDomainsRefreshTooShort       = errors.New("firewall domains refresh period is too short")
+	ErrFirewallEBPFNotCompatible            = errors.New("firewall eBPF egress filter cannot be used")
+	ErrFirewallGatewayDNSNotServed          = errors.New("DNS is not served to gateway clients")
+	ErrFirewallGatewayInterfaceNotValid     = errors.New("firewall gateway interface name is not valid")
+	ErrFirewallSourceNotValid               = errors.New("firewall source is not valid")
+	ErrFirewallStartupDurationTooShort      = errors.New("firewall startup permissive duration is too short")
+	ErrFirewallStartupPolicyNotValid        = errors.New("firewall startup policy is not valid")
+	ErrFirewallZeroPort                     = errors.New("cannot have a zero port to block")
+	ErrHooksDirectoryNotValid               = errors.New("hooks directory is not valid")
+	ErrHooksFailurePolicyNotValid           = errors.New("hooks failure policy is not valid")
+	ErrHooksTimeoutNotValid                 = errors.New("hooks timeout is not valid")
+	ErrHostnameNotValid                     = errors.New("the hostname specified is not valid")
+	ErrHTTPProxyZeroPort                    = errors.New("cannot have a zero port for the HTTP proxy")
+	ErrIKEv2InterfaceNotValid               = errors.New("interface name is not valid")
+	ErrIKEv2PasswordNotSet                  = errors.New("password is not set")
+	ErrIKEv2ServerNotValid                  = errors.New("server address is not valid")
+	ErrIKEv2UserNotSet                      = errors.New("user is not set")
+	ErrISPNotValid                          = errors.New("the ISP specified is not valid")
+	ErrMinRatioNotValid                     = errors.New("minimum ratio is not valid")
+	ErrMissingValue                         = errors.New("missing value")
+	ErrNameNotValid                         = errors.New("the server name specified is not valid")
+	ErrOpenConnectInterfaceNotValid         = errors.New("interface name is not valid")
+	ErrOpenConnectPasswordNotSet            = errors.New("password is not set")
+	ErrOpenConnectPortNotSet                = errors.New("port is not set")
+	ErrOpenConnectServerCertificateNotValid = errors.New("server certificate fingerprint is not valid")
+	ErrOpenConnectServerNotValid            = errors.New("server address is not valid")
+	ErrOpenConnectUserNotSet                = errors.New("user is not set")
+	ErrOpenVPNClientKeyMissing              = errors.New("client key is missing")
+	ErrOpenVPNConfigFileIsDirectory         = errors.New("configuration file is a directory")
+	ErrOpenVPNCustomPortNotAllowed          = errors.New("custom endpoint port is not allowed")
+	ErrOpenVPNEncryptionPresetNotValid      = errors.New("PIA encryption preset is not valid")
+	ErrOpenVPNInterfaceNotValid             = errors.New("interface name is not valid")
+	ErrOpenVPNKeyPassphraseIsEmpty          = errors.New("key passphrase is empty")
+	ErrOpenVPNMSSFixIsTooHigh               = errors.New("mssfix option value is too high")
+	ErrOpenVPNObfuscationNotValid           = errors.New("obfuscation type is not valid")
+	ErrOpenVPNObfuscationPortNotSet         = errors.New("obfuscation port is not set")
+	ErrOpenVPNObfuscationProtocolNotTCP     = errors.New("obfuscation requires the OpenVPN TCP protocol")
+	ErrOpenVPNPKCS11IDNotSet                = errors.New("PKCS#11 certificate ID is not set")
+	ErrOpenVPNPKCS11IDNotValid              = errors.New("PKCS#11 certificate ID is not valid")
+	ErrOpenVPNPKCS11PINNotValid             = errors.New("PKCS#11 PIN is not valid")
+	ErrOpenVPNPKCS11ProviderNotSet          = errors.New("PKCS#11 provider library is not set")
+	ErrOpenVPNPKCS11WithClientKey           = errors.New("client certificate and key cannot be set with PKCS#11")
+	ErrOpenVPNPasswordIsEmpty               = errors.New("password is empty")
+	ErrOpenVPNTCPNotSupported               = errors.New("TCP protocol is not supported")
+	ErrOpenVPNUserIsEmpty                   = errors.New("user is empty")
+	ErrOpenVPNVerbosityIsOutOfBounds        = errors.New("verbosity value is out of bounds")
+	ErrOpenVPNVersionIsNotValid             = errors.New("version is not valid")
+	ErrPortForwardCheckPeriodTooShort       = errors.New("port forwarding check period is too short")
+	ErrPortForwardingAPIKeyNotSet           = errors.New("port forwarding API key is not set")
+	ErrPortForwardingCheckURLNotValid       = errors.New("port forwarding check URL is not valid")
+	ErrPortForwardingEnabled                = errors.New("port forwarding cannot be enabled")
+	ErrPortForwardingProviderNotValid       = errors.New("port forwarding provider is not valid")
+	ErrPortForwardingTargetNotValid         = errors.New("port forwarding target is not valid")
+	ErrPublicIPPeriodTooShort               = errors.New("public IP address check period is too short")
+	ErrQuotaActionNotValid                  = errors.New("quota action is not valid")
+	ErrQuotaPeriodNotValid                  = errors.New("quota period is not valid")
+	ErrQuotaThrottleRateNotValid            = errors.New("quota throttle rate is not valid")
+	ErrHealthFailureQuorumNotValid          = errors.New("health failure quorum is not valid")
+	ErrHealthICMPTargetNotIPv4              = errors.New("health ICMP target is not an IPv4 address")
+	ErrHealthICMPTargetsNotSet              = errors.New("health ICMP targets are not set")
+	ErrHealthModeNotValid                   = errors.New("health mode is not valid")
+	ErrHealthStallDurationTooSmall          = errors.New("health throughput stall duration is too small")
+	ErrReadinessConditionNotValid           = errors.New("readiness condition is not valid")
+	ErrRegionNotValid                       = errors.New("the region specified is not valid")
+	ErrRollbackWindowNegative               = errors.New("VPN settings rollback window cannot be negative")
+	ErrRotationPeriodAndCron                = errors.New("rotation period and cron expression cannot be both set")
+	ErrRotationPeriodTooSmall               = errors.New("rotation period is too small")
+	ErrRuntimeStateMaxAgeNotValid           = errors.New("runtime state maximum age is not valid")
+	ErrSecretsWatchPeriodTooSmall           = errors.New("secrets watch period is too small")
+	ErrSecureDNSServerNoAddress             = errors.New("secure DNS server has no listening address")
+	ErrServerAddressNotValid                = errors.New("server listening address is not valid")
+	ErrShadowsocksClientTCPOnly             = errors.New("Shadowsocks client only relays TCP traffic")
+	ErrShadowsocksPasswordNotSet            = errors.New("Shadowsocks password is not set")
+	ErrShadowsocksServerNotValid            = errors.New("Shadowsocks server address is not valid")
+	ErrSOCKS5CredentialsTooLong             = errors.New("SOCKS5 credentials are too long")
+	ErrSOCKS5PasswordNotSet                 = errors.New("SOCKS5 password is not set")
+	ErrSOCKS5ZeroPort                       = errors.New("cannot have a zero port for the SOCKS5 proxy")
+	ErrStaticRouteFamilyMismatch            = errors.New("static route gateway and destination IP families differ")
+	ErrStaticRouteGatewayInterfaceNotSet    = errors.New("static route gateway or interface must be set")
+	ErrStaticRouteInterfaceNotValid         = errors.New("static route interface name is not valid")
+	ErrSystemFilesIDNotSet                  = errors.New("files user or group id is not set")
+	ErrSystemPGIDNotValid                   = errors.New("process group id is not valid")
+	ErrSystemPUIDNotValid                   = errors.New("process user id is not valid")
+	ErrSystemTimezoneNotValid               = errors.New("timezone is not valid")
+	ErrTLSKeyPairPartial                    = errors.New("TLS certificate and key files must be set together")
+	ErrTOTPSecretNotValid                   = errors.New("TOTP secret is not valid")
+	ErrTOTPSecretTooShort                   = errors.New("TOTP secret is too short")
+	ErrTorrentClientURLNotValid             = errors.New("torrent client URL is not valid")
+	ErrUpdaterPeriodTooSmall                = errors.New("VPN server data updater period is too small")
+	ErrUpdaterProviderTimeoutNotValid       = errors.New("VPN server data updater provider timeout is not valid")
+	ErrUpdaterWorkersNotValid               = errors.New("VPN server data updater workers count is not valid")
+	ErrUpstreamHTTPProxyNotValid            = errors.New("upstream HTTP proxy address is not valid")
+	ErrUpstreamOpenVPNNotTCP                = errors.New("upstream gluetun requires the OpenVPN TCP protocol")
+	ErrUpstreamVPNTypeNotOpenVPN            = errors.New("upstream gluetun is only supported with OpenVPN")
+	ErrVPNHopInterfaceConflict              = errors.New("hop interface name is already used by the VPN interface or another hop")
+	ErrVPNHopsIKEv2                         = errors.New("hops are not supported with IKEv2")
+	ErrVPNHopsObfuscation                   = errors.New("hops are not supported with obfuscation")
+	ErrVPNHopsShadowsocks                   = errors.New("hops are not supported with Shadowsocks")
+	ErrVPNHopsTooMany                       = errors.New("too many hops")
+	ErrVPNTunnelFirewallMarkUsed            = errors.New("tunnel firewall mark is already used")
+	ErrVPNTunnelInterfaceUsed               = errors.New("tunnel interface name is already used")
+	ErrVPNTunnelSourcePortZero              = errors.New("tunnel source port cannot be 0")
+	ErrVPNTunnelSourceSubnetNotIPv4         = errors.New("tunnel source subnet is not IPv4")
+	ErrVPNLogRulePatternNotSet              = errors.New("VPN log rule pattern is not set")
+	ErrVPNLogRulePatternNotValid            = errors.New("VPN log rule pattern is not valid")
+	ErrVPNProviderNameNotValid              = errors.New("VPN provider name is not valid")
+	ErrVPNRoutesNotSupported                = errors.New("VPN routes are not supported")
+	ErrVPNTypeNotValid                      = errors.New("VPN type is not valid")
+	ErrWireguardEndpointIPNotSet            = errors.New("endpoint IP is not set")
+	ErrWireguardEndpointPortNotAllowed      = errors.New("endpoint port is not allowed")
+	ErrWireguardEndpointPortNotSet          = errors.New("endpoint port is not set")
+	ErrWireguardEndpointPortSet             = errors.New("endpoint port is set")
+	ErrWireguardInterfaceAddressNotSet      = errors.New("interface address is not set")
+	ErrWireguardInterfaceAddressIPv6        = errors.New("interface address is IPv6 but IPv6 is not supported")
+	ErrWireguardInterfaceNotValid           = errors.New("interface name is not valid")
+	ErrWireguardKeepaliveNegative           = errors.New("persistent keepalive interval is negative")
+	ErrWireguardObfuscationNotValid         = errors.New("obfuscation type is not valid")
+	ErrWireguardPeerAllowedIPsNotSet        = errors.New("peer allowed IPs are not set")
+	ErrWireguardPeerAllowedIPv6             = errors.New("peer allowed IP network is IPv6 but IPv6 is not supported")
+	ErrWireguardPreSharedKeyNotSet          = errors.New("pre-shared key is not set")
+	ErrWireguardPrivateKeyNotSet            = errors.New("private key is not set")
+	ErrWireguardPublicKeyNotSet             = errors.New("public key is not set")
+	ErrWireguardPublicKeyNotValid           = errors.New("public key is not valid")
+	ErrWireguardResolvePeriodTooSmall       = errors.New("endpoint resolve period is too small")
+	ErrWireguardServerAddressNotIPv4        = errors.New("server address is not IPv4")
+	ErrWireguardServerDNSNotServed          = errors.New("DNS is not served to peers")
+	ErrWireguardServerPeerAddressNotValid   = errors.New("peer address is not valid")
+	ErrWireguardServerPeerAddressUsed       = errors.New("peer address is already used")
+	ErrWireguardServerPeerNameUsed          = errors.New("peer name is already used")
+	ErrWireguardServerPortNotSet            = errors.New("listening port is not set")
+	ErrWireguardImplementationNotValid      = errors.New("implementation is not valid")
 )
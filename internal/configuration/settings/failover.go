@@ -0,0 +1,100 @@
+package settings
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// Failover contains settings to switch to a secondary
+// VPN provider or tunnel when the primary one stays unhealthy.
+type Failover struct {
+	// Enabled is true if the failover should be enabled.
+	// It cannot be nil in the internal state.
+	Enabled *bool
+	// Threshold is the duration the VPN must stay unhealthy
+	// before switching to the other VPN settings.
+	// It cannot be nil in the internal state.
+	Threshold *time.Duration
+	// VPN contains the secondary VPN settings, which are
+	// only the fields overriding the primary VPN settings.
+	// For example, only the provider name and credentials
+	// of the secondary VPN provider can be set.
+	VPN VPN
+}
+
+// TODO v4 remove pointer for receiver (because of Surfshark).
+func (f *Failover) validate(primary VPN, storage Storage, ipv6Supported bool) (err error) {
+	if !*f.Enabled {
+		return nil
+	}
+
+	const minThreshold = 10 * time.Second
+	if *f.Threshold < minThreshold {
+		return fmt.Errorf("%w: %s must be at least %s",
+			ErrFailoverThresholdTooSmall, *f.Threshold, minThreshold)
+	}
+
+	secondary := f.Secondary(primary)
+	err = secondary.Validate(storage, ipv6Supported)
+	if err != nil {
+		return fmt.Errorf("secondary VPN settings: %w", err)
+	}
+
+	return nil
+}
+
+// Secondary returns the secondary VPN settings, which are
+// the primary VPN settings overridden by the failover VPN settings.
+func (f *Failover) Secondary(primary VPN) (secondary VPN) {
+	secondary = primary.Copy()
+	secondary.OverrideWith(f.VPN)
+	return secondary
+}
+
+func (f *Failover) copy() (copied Failover) {
+	return Failover{
+		Enabled:   helpers.CopyBoolPtr(f.Enabled),
+		Threshold: helpers.CopyDurationPtr(f.Threshold),
+		VPN:       f.VPN.Copy(),
+	}
+}
+
+// mergeWith merges the other settings into any
+// unset field of the receiver settings object.
+func (f *Failover) mergeWith(other Failover) {
+	f.Enabled = helpers.MergeWithBool(f.Enabled, other.Enabled)
+	f.Threshold = helpers.MergeWithDurationPtr(f.Threshold, other.Threshold)
+	f.VPN.mergeWith(other.VPN)
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (f *Failover) overrideWith(other Failover) {
+	f.Enabled = helpers.OverrideWithBool(f.Enabled, other.Enabled)
+	f.Threshold = helpers.OverrideWithDurationPtr(f.Threshold, other.Threshold)
+	f.VPN.OverrideWith(other.VPN)
+}
+
+func (f *Failover) setDefaults() {
+	f.Enabled = helpers.DefaultBool(f.Enabled, false)
+	const defaultThreshold = 2 * time.Minute
+	f.Threshold = helpers.DefaultDurationPtr(f.Threshold, defaultThreshold)
+	// Secondary VPN settings are not defaulted since
+	// they are layered on top of the primary VPN settings.
+}
+
+func (f Failover) toLinesNode(primary VPN) (node *gotree.Node) {
+	if !*f.Enabled {
+		return nil
+	}
+
+	node = gotree.New("Failover settings:")
+	node.Appendf("Unhealthy threshold: %s", *f.Threshold)
+	secondary := f.Secondary(primary)
+	node.AppendNode(secondary.toLinesNode())
+	return node
+}
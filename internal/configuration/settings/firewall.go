@@ -3,8 +3,12 @@ package settings
 import (
 	"fmt"
 	"net"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gluetun/internal/firewall/rulestemplate"
 	"github.com/qdm12/gotree"
 )
 
@@ -15,9 +19,108 @@ type Firewall struct {
 	OutboundSubnets []net.IPNet
 	Enabled         *bool
 	Debug           *bool
+	// EBPF is true to additionally drop, with an eBPF cgroup
+	// program, egress traffic of processes in the gluetun cgroup
+	// not going through the VPN interface, the loopback interface
+	// or to the local networks, VPN server and outbound subnets.
+	// The egress filter is always used with the "ebpf" backend,
+	// and cannot be used with the firewall disabled or VPN routes.
+	// It is experimental and cannot be nil in the internal state.
+	EBPF *bool
+	// StartupPolicy is the policy applied to direct egress traffic
+	// until the VPN tunnel is up for the first time. It can be:
+	// - "strict" to only allow egress traffic to the VPN server and
+	//   DNS traffic to the local networks.
+	// - "lan" to also allow egress traffic to the local networks
+	//   and to the outbound subnets.
+	// - "permissive" to allow all direct egress traffic for
+	//   StartupDuration, to let DHCP or NTP settle on some networks,
+	//   and to then restrict it like "strict".
+	// Outbound subnets and local networks traffic are allowed once
+	// the tunnel is up, whatever the policy.
+	// It cannot be empty in the internal state.
+	StartupPolicy string
+	// StartupDuration is the duration direct egress traffic is
+	// allowed for with the "permissive" startup policy.
+	// It cannot be nil in the internal state.
+	StartupDuration *time.Duration
+	// Backend is the firewall backend to use, and can be
+	// "iptables", "nftables" or "auto" to use iptables if
+	// available and nftables otherwise. It can also be "ebpf"
+	// to pick a backend like "auto" and always use the eBPF egress
+	// filter in addition, see EBPF.
+	// It cannot be empty in the internal state.
+	Backend string
+	// VPNOutputDomains are the domain names whose IP addresses are
+	// the only destinations allowed through the VPN interface, apart
+	// from DNS traffic. Destinations are not restricted if it is empty.
+	VPNOutputDomains []string
+	// VPNOutputDomainsRefresh is the period to resolve the VPN output
+	// domains again, to keep their IP addresses up to date.
+	// It cannot be nil in the internal state.
+	VPNOutputDomainsRefresh *time.Duration
+	// BypassDomains are the domain names whose IP addresses are
+	// reached through the default routes instead of the VPN, for
+	// example for websites blocking VPN servers. Their IP addresses
+	// are recorded from the DNS answers to the queries for them.
+	BypassDomains []string
+	// BypassCgroups are the cgroup v2 paths, relative to the cgroup v2
+	// root, of the processes whose traffic goes out through the default
+	// routes instead of the VPN, by marking it with BypassMark.
+	BypassCgroups []string
+	// BypassMark is the firewall mark of the traffic going out through
+	// the default routes instead of the VPN. It can also be set by
+	// processes on their own traffic. It is disabled if set to 0, and
+	// defaults to 0x6762 if BypassCgroups is set, and 0 otherwise.
+	// It cannot be nil in the internal state.
+	BypassMark *uint32
+	// RulesTemplateFile is the path of a file of iptables and ip6tables
+	// rules applied after the built-in rules, which can use the
+	// placeholders {{.TunDevice}}, {{.VPNServerIP}} and {{.ForwardedPort}}.
+	// It is disabled if set to the empty string, and cannot be nil
+	// in the internal state.
+	RulesTemplateFile *string
+	// LogDropped is true to log the packets dropped by the firewall
+	// and count them, using NFLOG. It cannot be nil in the internal state.
+	LogDropped *bool
+	// GeoIPDatabase is the path of the MaxMind DB country database
+	// file used to find the networks of BlockedCountries and
+	// AllowedCountries. It cannot be nil in the internal state.
+	GeoIPDatabase *string
+	// BlockedCountries are the ISO 3166-1 alpha-2 country codes, of
+	// countries to block output traffic to through the VPN interface.
+	BlockedCountries []string
+	// AllowedCountries are the ISO 3166-1 alpha-2 country codes, of
+	// the only countries output traffic through the VPN interface is
+	// allowed to, apart from traffic to the DNS servers.
+	// It cannot be set together with BlockedCountries.
+	AllowedCountries []string
+	// LANSource is the only source allowed to open connections to
+	// private networks, for containers sharing the network namespace
+	// of gluetun. It is either a user id such as "uid:1000" or a
+	// packet mark such as "mark:0x1", since these containers share
+	// the same IP addresses. All sources are allowed if it is empty.
+	// It cannot be nil in the internal state.
+	LANSource *string
+	// HTTPProxySource is the only source allowed to connect to the
+	// HTTP proxy, in the same format as LANSource. All sources are
+	// allowed if it is empty. It cannot be nil in the internal state.
+	HTTPProxySource *string
+	// GatewayInterface is the network interface, such as a macvlan
+	// interface, through which other devices of the network use gluetun
+	// as their gateway. Their IPv4 traffic is forwarded through the VPN,
+	// and their DNS traffic is redirected to the gluetun DNS server.
+	// It is disabled if set to the empty string, and cannot be nil
+	// in the internal state.
+	GatewayInterface *string
 }
 
-func (f Firewall) validate() (err error) {
+var (
+	countryCodeRegex = regexp.MustCompile(`^[a-zA-Z]{2}$`)
+	sourceRegex      = regexp.MustCompile(`^(uid:[0-9]+|mark:(0x[0-9a-fA-F]+|[0-9]+))$`)
+)
+
+func (f Firewall) validate(vpn VPN) (err error) {
 	if hasZeroPort(f.VPNInputPorts) {
 		return fmt.Errorf("VPN input ports: %w", ErrFirewallZeroPort)
 	}
@@ -26,9 +129,129 @@ func (f Firewall) validate() (err error) {
 		return fmt.Errorf("input ports: %w", ErrFirewallZeroPort)
 	}
 
+	for _, domain := range f.VPNOutputDomains {
+		if !hostRegex.MatchString(domain) {
+			return fmt.Errorf("%w: %s", ErrFirewallDomainNotValid, domain)
+		}
+	}
+
+	for _, domain := range f.BypassDomains {
+		if !hostRegex.MatchString(domain) {
+			return fmt.Errorf("%w: %s", ErrFirewallDomainNotValid, domain)
+		}
+	}
+
+	if len(f.BypassCgroups) > 0 && *f.BypassMark == 0 {
+		return ErrFirewallBypassMarkMissing
+	}
+
+	if len(f.BypassCgroups) > 0 && f.Backend == "nftables" {
+		return fmt.Errorf("%w: %s", ErrFirewallBypassCgroupsBackend, f.Backend)
+	}
+
+	// The eBPF egress filter only allows traffic through the VPN
+	// interface and to allowed subnets, whatever the firewall state.
+	if *f.EBPF || f.Backend == "ebpf" {
+		switch {
+		case !*f.Enabled:
+			return fmt.Errorf("%w: the firewall is disabled", ErrFirewallEBPFNotCompatible)
+		case len(vpn.Routes) > 0:
+			return fmt.Errorf("%w: VPN routes are set", ErrFirewallEBPFNotCompatible)
+		}
+	}
+
+	if *f.VPNOutputDomainsRefresh <= 0 {
+		return fmt.Errorf("%w: %s", ErrFirewallDomainsRefreshTooShort, *f.VPNOutputDomainsRefresh)
+	}
+
+	if *f.RulesTemplateFile != "" {
+		err = helpers.FileExists(*f.RulesTemplateFile)
+		if err != nil {
+			return fmt.Errorf("rules template file: %w", err)
+		}
+		err = rulestemplate.Validate(*f.RulesTemplateFile)
+		if err != nil {
+			return fmt.Errorf("rules template file: %w", err)
+		}
+	}
+
+	if len(f.BlockedCountries) > 0 && len(f.AllowedCountries) > 0 {
+		return fmt.Errorf("%w", ErrFirewallCountriesBothSet)
+	}
+
+	countries := f.BlockedCountries
+	if len(countries) == 0 {
+		countries = f.AllowedCountries
+	}
+	for _, country := range countries {
+		if !countryCodeRegex.MatchString(country) {
+			return fmt.Errorf("%w: %s", ErrFirewallCountryNotValid, country)
+		}
+	}
+
+	if len(countries) > 0 {
+		err = helpers.FileExists(*f.GeoIPDatabase)
+		if err != nil {
+			return fmt.Errorf("GeoIP database: %w", err)
+		}
+	}
+
+	if *f.LANSource != "" && !sourceRegex.MatchString(*f.LANSource) {
+		return fmt.Errorf("LAN source: %w: %s", ErrFirewallSourceNotValid, *f.LANSource)
+	}
+
+	if *f.HTTPProxySource != "" && !sourceRegex.MatchString(*f.HTTPProxySource) {
+		return fmt.Errorf("HTTP proxy source: %w: %s", ErrFirewallSourceNotValid, *f.HTTPProxySource)
+	}
+
+	if *f.GatewayInterface != "" && !regexpInterfaceName.MatchString(*f.GatewayInterface) {
+		return fmt.Errorf("%w: %s", ErrFirewallGatewayInterfaceNotValid, *f.GatewayInterface)
+	}
+
+	validStartupPolicies := []string{"strict", "lan", "permissive"}
+	if !helpers.IsOneOf(f.StartupPolicy, validStartupPolicies...) {
+		return fmt.Errorf("%w: %s must be one of %s", ErrFirewallStartupPolicyNotValid,
+			f.StartupPolicy, helpers.ChoicesOrString(validStartupPolicies))
+	}
+
+	if f.StartupPolicy == "permissive" && *f.StartupDuration <= 0 {
+		return fmt.Errorf("%w: %s", ErrFirewallStartupDurationTooShort, *f.StartupDuration)
+	}
+
+	validBackends := []string{"auto", "iptables", "nftables", "ebpf"}
+	if !helpers.IsOneOf(f.Backend, validBackends...) {
+		return fmt.Errorf("%w: %s must be one of %s", ErrFirewallBackendNotValid,
+			f.Backend, helpers.ChoicesOrString(validBackends))
+	}
+
 	return nil
 }
 
+// validateGatewayDNS verifies the DNS server listens on the container
+// addresses and not only on 127.0.0.1 if the gateway interface is set,
+// since the DNS traffic of the gateway clients is redirected to the
+// address of the gateway interface.
+func (f Firewall) validateGatewayDNS(dns DNS) (err error) {
+	if *f.GatewayInterface == "" || *dns.LAN.Enabled {
+		return nil
+	}
+	return fmt.Errorf("%w: serving DNS to LAN clients must be enabled "+
+		"to answer DNS queries redirected from %s", ErrFirewallGatewayDNSNotServed,
+		*f.GatewayInterface)
+}
+
+// validateBypassDomainsDNS verifies DNS over TLS is enabled if bypass
+// domains are set, since their IP addresses are recorded from the
+// answers of the DNS over TLS forwarder.
+func (f Firewall) validateBypassDomainsDNS(dns DNS) (err error) {
+	if len(f.BypassDomains) == 0 || *dns.DoT.Enabled {
+		return nil
+	}
+	return fmt.Errorf("%w: DNS over TLS must be enabled to record "+
+		"the IP addresses of %s", ErrFirewallBypassDomainsDNSNotEnabled,
+		strings.Join(f.BypassDomains, ", "))
+}
+
 func hasZeroPort(ports []uint16) (has bool) {
 	for _, port := range ports {
 		if port == 0 {
@@ -40,11 +263,28 @@ func hasZeroPort(ports []uint16) (has bool) {
 
 func (f *Firewall) copy() (copied Firewall) {
 	return Firewall{
-		VPNInputPorts:   helpers.CopyUint16Slice(f.VPNInputPorts),
-		InputPorts:      helpers.CopyUint16Slice(f.InputPorts),
-		OutboundSubnets: helpers.CopyIPNetSlice(f.OutboundSubnets),
-		Enabled:         helpers.CopyBoolPtr(f.Enabled),
-		Debug:           helpers.CopyBoolPtr(f.Debug),
+		VPNInputPorts:           helpers.CopyUint16Slice(f.VPNInputPorts),
+		InputPorts:              helpers.CopyUint16Slice(f.InputPorts),
+		OutboundSubnets:         helpers.CopyIPNetSlice(f.OutboundSubnets),
+		Enabled:                 helpers.CopyBoolPtr(f.Enabled),
+		Debug:                   helpers.CopyBoolPtr(f.Debug),
+		EBPF:                    helpers.CopyBoolPtr(f.EBPF),
+		StartupPolicy:           f.StartupPolicy,
+		StartupDuration:         helpers.CopyDurationPtr(f.StartupDuration),
+		Backend:                 f.Backend,
+		VPNOutputDomains:        helpers.CopyStringSlice(f.VPNOutputDomains),
+		VPNOutputDomainsRefresh: helpers.CopyDurationPtr(f.VPNOutputDomainsRefresh),
+		BypassDomains:           helpers.CopyStringSlice(f.BypassDomains),
+		BypassCgroups:           helpers.CopyStringSlice(f.BypassCgroups),
+		BypassMark:              helpers.CopyUint32Ptr(f.BypassMark),
+		RulesTemplateFile:       helpers.CopyStringPtr(f.RulesTemplateFile),
+		LogDropped:              helpers.CopyBoolPtr(f.LogDropped),
+		GeoIPDatabase:           helpers.CopyStringPtr(f.GeoIPDatabase),
+		BlockedCountries:        helpers.CopyStringSlice(f.BlockedCountries),
+		AllowedCountries:        helpers.CopyStringSlice(f.AllowedCountries),
+		LANSource:               helpers.CopyStringPtr(f.LANSource),
+		HTTPProxySource:         helpers.CopyStringPtr(f.HTTPProxySource),
+		GatewayInterface:        helpers.CopyStringPtr(f.GatewayInterface),
 	}
 }
 
@@ -58,6 +298,23 @@ func (f *Firewall) mergeWith(other Firewall) {
 	f.OutboundSubnets = helpers.MergeIPNetsSlices(f.OutboundSubnets, other.OutboundSubnets)
 	f.Enabled = helpers.MergeWithBool(f.Enabled, other.Enabled)
 	f.Debug = helpers.MergeWithBool(f.Debug, other.Debug)
+	f.EBPF = helpers.MergeWithBool(f.EBPF, other.EBPF)
+	f.StartupPolicy = helpers.MergeWithString(f.StartupPolicy, other.StartupPolicy)
+	f.StartupDuration = helpers.MergeWithDurationPtr(f.StartupDuration, other.StartupDuration)
+	f.Backend = helpers.MergeWithString(f.Backend, other.Backend)
+	f.VPNOutputDomains = helpers.MergeStringSlices(f.VPNOutputDomains, other.VPNOutputDomains)
+	f.VPNOutputDomainsRefresh = helpers.MergeWithDurationPtr(f.VPNOutputDomainsRefresh, other.VPNOutputDomainsRefresh)
+	f.BypassDomains = helpers.MergeStringSlices(f.BypassDomains, other.BypassDomains)
+	f.BypassCgroups = helpers.MergeStringSlices(f.BypassCgroups, other.BypassCgroups)
+	f.BypassMark = helpers.MergeWithUint32(f.BypassMark, other.BypassMark)
+	f.RulesTemplateFile = helpers.MergeWithStringPtr(f.RulesTemplateFile, other.RulesTemplateFile)
+	f.LogDropped = helpers.MergeWithBool(f.LogDropped, other.LogDropped)
+	f.GeoIPDatabase = helpers.MergeWithStringPtr(f.GeoIPDatabase, other.GeoIPDatabase)
+	f.BlockedCountries = helpers.MergeStringSlices(f.BlockedCountries, other.BlockedCountries)
+	f.AllowedCountries = helpers.MergeStringSlices(f.AllowedCountries, other.AllowedCountries)
+	f.LANSource = helpers.MergeWithStringPtr(f.LANSource, other.LANSource)
+	f.HTTPProxySource = helpers.MergeWithStringPtr(f.HTTPProxySource, other.HTTPProxySource)
+	f.GatewayInterface = helpers.MergeWithStringPtr(f.GatewayInterface, other.GatewayInterface)
 }
 
 // overrideWith overrides fields of the receiver
@@ -69,11 +326,48 @@ func (f *Firewall) overrideWith(other Firewall) {
 	f.OutboundSubnets = helpers.OverrideWithIPNetsSlice(f.OutboundSubnets, other.OutboundSubnets)
 	f.Enabled = helpers.OverrideWithBool(f.Enabled, other.Enabled)
 	f.Debug = helpers.OverrideWithBool(f.Debug, other.Debug)
+	f.EBPF = helpers.OverrideWithBool(f.EBPF, other.EBPF)
+	f.StartupPolicy = helpers.OverrideWithString(f.StartupPolicy, other.StartupPolicy)
+	f.StartupDuration = helpers.OverrideWithDurationPtr(f.StartupDuration, other.StartupDuration)
+	f.Backend = helpers.OverrideWithString(f.Backend, other.Backend)
+	f.VPNOutputDomains = helpers.OverrideWithStringSlice(f.VPNOutputDomains, other.VPNOutputDomains)
+	f.VPNOutputDomainsRefresh = helpers.OverrideWithDurationPtr(f.VPNOutputDomainsRefresh,
+		other.VPNOutputDomainsRefresh)
+	f.BypassDomains = helpers.OverrideWithStringSlice(f.BypassDomains, other.BypassDomains)
+	f.BypassCgroups = helpers.OverrideWithStringSlice(f.BypassCgroups, other.BypassCgroups)
+	f.BypassMark = helpers.OverrideWithUint32(f.BypassMark, other.BypassMark)
+	f.RulesTemplateFile = helpers.OverrideWithStringPtr(f.RulesTemplateFile, other.RulesTemplateFile)
+	f.LogDropped = helpers.OverrideWithBool(f.LogDropped, other.LogDropped)
+	f.GeoIPDatabase = helpers.OverrideWithStringPtr(f.GeoIPDatabase, other.GeoIPDatabase)
+	f.BlockedCountries = helpers.OverrideWithStringSlice(f.BlockedCountries, other.BlockedCountries)
+	f.AllowedCountries = helpers.OverrideWithStringSlice(f.AllowedCountries, other.AllowedCountries)
+	f.LANSource = helpers.OverrideWithStringPtr(f.LANSource, other.LANSource)
+	f.HTTPProxySource = helpers.OverrideWithStringPtr(f.HTTPProxySource, other.HTTPProxySource)
+	f.GatewayInterface = helpers.OverrideWithStringPtr(f.GatewayInterface, other.GatewayInterface)
 }
 
 func (f *Firewall) setDefaults() {
 	f.Enabled = helpers.DefaultBool(f.Enabled, true)
 	f.Debug = helpers.DefaultBool(f.Debug, false)
+	f.EBPF = helpers.DefaultBool(f.EBPF, false)
+	f.StartupPolicy = helpers.DefaultString(f.StartupPolicy, "strict")
+	const defaultStartupDuration = 30 * time.Second
+	f.StartupDuration = helpers.DefaultDurationPtr(f.StartupDuration, defaultStartupDuration)
+	f.Backend = helpers.DefaultString(f.Backend, "auto")
+	const defaultDomainsRefresh = 5 * time.Minute
+	f.VPNOutputDomainsRefresh = helpers.DefaultDurationPtr(f.VPNOutputDomainsRefresh, defaultDomainsRefresh)
+	defaultBypassMark := uint32(0)
+	if len(f.BypassCgroups) > 0 {
+		const cgroupsBypassMark = 0x6762
+		defaultBypassMark = cgroupsBypassMark
+	}
+	f.BypassMark = helpers.DefaultUint32(f.BypassMark, defaultBypassMark)
+	f.RulesTemplateFile = helpers.DefaultStringPtr(f.RulesTemplateFile, "")
+	f.LogDropped = helpers.DefaultBool(f.LogDropped, false)
+	f.GeoIPDatabase = helpers.DefaultStringPtr(f.GeoIPDatabase, "/gluetun/GeoLite2-Country.mmdb")
+	f.LANSource = helpers.DefaultStringPtr(f.LANSource, "")
+	f.HTTPProxySource = helpers.DefaultStringPtr(f.HTTPProxySource, "")
+	f.GatewayInterface = helpers.DefaultStringPtr(f.GatewayInterface, "")
 }
 
 func (f Firewall) String() string {
@@ -84,6 +378,9 @@ func (f Firewall) toLinesNode() (node *gotree.Node) {
 	node = gotree.New("Firewall settings:")
 
 	node.Appendf("Enabled: %s", helpers.BoolPtrToYesNo(f.Enabled))
+	if *f.EBPF {
+		node.Appendf("eBPF egress filter: on")
+	}
 	if !*f.Enabled {
 		return node
 	}
@@ -92,6 +389,21 @@ func (f Firewall) toLinesNode() (node *gotree.Node) {
 		node.Appendf("Debug mode: on")
 	}
 
+	if f.Backend != "auto" {
+		node.Appendf("Backend: %s", f.Backend)
+	}
+
+	switch f.StartupPolicy {
+	case "lan":
+		node.Appendf("Startup policy: lan")
+	case "permissive":
+		node.Appendf("Startup policy: permissive for %s", *f.StartupDuration)
+	}
+
+	if *f.LogDropped {
+		node.Appendf("Log dropped packets: on")
+	}
+
 	if len(f.VPNInputPorts) > 0 {
 		vpnInputPortsNode := node.Appendf("VPN input ports:")
 		for _, port := range f.VPNInputPorts {
@@ -114,5 +426,55 @@ func (f Firewall) toLinesNode() (node *gotree.Node) {
 		}
 	}
 
+	if len(f.VPNOutputDomains) > 0 {
+		vpnOutputDomainsNode := node.Appendf("VPN output domains:")
+		for _, domain := range f.VPNOutputDomains {
+			vpnOutputDomainsNode.Appendf(domain)
+		}
+		node.Appendf("VPN output domains refresh period: %s", *f.VPNOutputDomainsRefresh)
+	}
+
+	if len(f.BypassDomains) > 0 {
+		bypassDomainsNode := node.Appendf("VPN bypass domains:")
+		for _, domain := range f.BypassDomains {
+			bypassDomainsNode.Appendf(domain)
+		}
+	}
+
+	if *f.BypassMark != 0 {
+		node.Appendf("VPN bypass mark: 0x%x", *f.BypassMark)
+		if len(f.BypassCgroups) > 0 {
+			bypassCgroupsNode := node.Appendf("VPN bypass cgroups:")
+			for _, cgroup := range f.BypassCgroups {
+				bypassCgroupsNode.Appendf(cgroup)
+			}
+		}
+	}
+
+	if *f.RulesTemplateFile != "" {
+		node.Appendf("Rules template file: %s", *f.RulesTemplateFile)
+	}
+
+	switch {
+	case len(f.BlockedCountries) > 0:
+		node.Appendf("Blocked countries: %s", strings.Join(f.BlockedCountries, ", "))
+		node.Appendf("GeoIP database: %s", *f.GeoIPDatabase)
+	case len(f.AllowedCountries) > 0:
+		node.Appendf("Allowed countries: %s", strings.Join(f.AllowedCountries, ", "))
+		node.Appendf("GeoIP database: %s", *f.GeoIPDatabase)
+	}
+
+	if *f.LANSource != "" {
+		node.Appendf("LAN source: %s", *f.LANSource)
+	}
+
+	if *f.HTTPProxySource != "" {
+		node.Appendf("HTTP proxy source: %s", *f.HTTPProxySource)
+	}
+
+	if *f.GatewayInterface != "" {
+		node.Appendf("LAN gateway interface: %s", *f.GatewayInterface)
+	}
+
 	return node
 }
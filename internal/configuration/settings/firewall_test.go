@@ -0,0 +1,73 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Firewall_validateGatewayDNS(t *testing.T) {
+	t.Parallel()
+
+	var firewall Firewall
+	firewall.setDefaults()
+	var dns DNS
+	dns.setDefaults()
+	dns.LAN.Enabled = boolPtr(false)
+	assert.NoError(t, firewall.validateGatewayDNS(dns))
+
+	firewall.GatewayInterface = stringPtr("macvlan0")
+	err := firewall.validateGatewayDNS(dns)
+	assert.ErrorIs(t, err, ErrFirewallGatewayDNSNotServed)
+	assert.EqualError(t, err, "DNS is not served to gateway clients: serving DNS "+
+		"to LAN clients must be enabled to answer DNS queries redirected from macvlan0")
+
+	dns.LAN.Enabled = boolPtr(true)
+	assert.NoError(t, firewall.validateGatewayDNS(dns))
+}
+
+func Test_Firewall_validateBypassDomainsDNS(t *testing.T) {
+	t.Parallel()
+
+	var firewall Firewall
+	firewall.setDefaults()
+	var dns DNS
+	dns.setDefaults()
+	dns.DoT.Enabled = boolPtr(false)
+	assert.NoError(t, firewall.validateBypassDomainsDNS(dns))
+
+	firewall.BypassDomains = []string{"bank.example.com", "example.org"}
+	err := firewall.validateBypassDomainsDNS(dns)
+	assert.ErrorIs(t, err, ErrFirewallBypassDomainsDNSNotEnabled)
+	assert.EqualError(t, err, "firewall bypass domains need DNS over TLS: "+
+		"DNS over TLS must be enabled to record the IP addresses of "+
+		"bank.example.com, example.org")
+
+	dns.DoT.Enabled = boolPtr(true)
+	assert.NoError(t, firewall.validateBypassDomainsDNS(dns))
+}
+
+func Test_Firewall_validate_rulesTemplateFile(t *testing.T) {
+	t.Parallel()
+
+	templatePath := filepath.Join(t.TempDir(), "rules.tmpl")
+	err := os.WriteFile(templatePath, []byte("iptables --append OUTPUT -o {{.TunDevic}} -j DROP"), 0600)
+	require.NoError(t, err)
+
+	var firewall Firewall
+	firewall.RulesTemplateFile = stringPtr(templatePath)
+	firewall.setDefaults()
+	var vpn VPN
+	vpn.setDefaults()
+
+	err = firewall.validate(vpn)
+	assert.ErrorContains(t, err, `rules template file: rendering rules template line 1: `+
+		`template: line 1:1:30: executing "line 1" at <.TunDevic>: map has no entry for key "TunDevic"`)
+
+	err = os.WriteFile(templatePath, []byte("iptables --append OUTPUT -o {{.TunDevice}} -j DROP"), 0600)
+	require.NoError(t, err)
+	assert.NoError(t, firewall.validate(vpn))
+}
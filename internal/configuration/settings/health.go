@@ -2,12 +2,18 @@ package settings
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
 	"github.com/qdm12/gotree"
 	"github.com/qdm12/govalid/address"
+	"inet.af/netaddr"
 )
 
 // Health contains settings for the healthcheck and health server.
@@ -22,11 +28,67 @@ type Health struct {
 	// ReadTimeout is the HTTP read timeout duration of the
 	//  HTTP server. It defaults to 500 milliseconds.
 	ReadTimeout time.Duration
-	// TargetAddress is the address (host or host:port)
-	// to TCP dial to periodically for the health check.
+	// Mode is the health check mode, which can be "tcp" to TCP dial
+	// the target addresses, or "icmp" to ping the ICMP targets.
 	// It cannot be the empty string in the internal state.
-	TargetAddress string
-	VPN           HealthyWait
+	Mode string
+	// TargetAddresses are the addresses (host or host:port)
+	// to TCP dial to periodically for the health check.
+	// It cannot be empty in the internal state.
+	TargetAddresses []string
+	// ICMPTargets are the IPv4 addresses to ping periodically for the
+	// health check in the icmp mode. It can be empty to ping the
+	// VPN gateway, which may not answer pings with Wireguard.
+	ICMPTargets []netaddr.IP
+	// DNSHostname is the hostname resolved through the internal
+	// DNS server after each successful health check, to detect
+	// and restart a broken DNS server while the tunnel is up.
+	// It can be the empty string to disable this check, and
+	// cannot be nil in the internal state.
+	DNSHostname *string
+	// FailureQuorum is the number of targets which must fail
+	// for a health check to fail, such that an outage of a
+	// single target does not make the VPN unhealthy.
+	// It defaults to the number of targets, and cannot
+	// be nil or 0 in the internal state.
+	FailureQuorum *uint8
+	// StatusFilepath is the file path to write the health
+	// status to, as key="value" lines suitable for tools
+	// reading Kubernetes downward API style files.
+	// It can be the empty string to indicate not to write
+	// to a file. It cannot be nil in the internal state.
+	StatusFilepath *string
+	// ReadyFilepath is the file path of a file existing only
+	// while the VPN is running and healthy, for the healthchecks
+	// of containers behind gluetun to consume.
+	// It can be the empty string to indicate not to write
+	// to a file. It cannot be nil in the internal state.
+	ReadyFilepath *string
+	// UpstreamAddress is the address of the health server of an
+	// upstream gluetun used as egress, such as 172.18.0.2:9999.
+	// The VPN is not restarted while the upstream gluetun is not
+	// ready, since its own tunnel is then the cause of the failure.
+	// It can be the empty string to indicate there is no upstream
+	// gluetun, and cannot be nil in the internal state.
+	UpstreamAddress *string
+	// ReadinessConditions are the conditions which must all be met
+	// for the /readiness route of the health server to respond 200,
+	// for dependent containers to gate their startup on.
+	// It defaults to the tunnel condition only.
+	ReadinessConditions []string
+	VPN                 HealthyWait
+	Throughput          HealthThroughput
+}
+
+// validateICMPTargets verifies ICMP targets are set in the icmp mode
+// for VPN types without a VPN gateway to ping instead, which is the
+// case for Wireguard since its interface is point to point.
+func (h Health) validateICMPTargets(vpnType string) (err error) {
+	if h.Mode != constants.HealthModeICMP || len(h.ICMPTargets) > 0 ||
+		vpnType != vpn.Wireguard {
+		return nil
+	}
+	return fmt.Errorf("%w: the %s VPN has no gateway to ping", ErrHealthICMPTargetsNotSet, vpnType)
 }
 
 func (h Health) Validate() (err error) {
@@ -37,21 +99,93 @@ func (h Health) Validate() (err error) {
 		return fmt.Errorf("server listening address is not valid: %w", err)
 	}
 
+	if !helpers.IsOneOf(h.Mode, constants.HealthModeTCP, constants.HealthModeICMP) {
+		return fmt.Errorf("%w: %s must be one of %s or %s", ErrHealthModeNotValid,
+			h.Mode, constants.HealthModeTCP, constants.HealthModeICMP)
+	}
+
+	for _, ip := range h.ICMPTargets {
+		if !ip.Is4() {
+			return fmt.Errorf("%w: %s", ErrHealthICMPTargetNotIPv4, ip)
+		}
+	}
+
+	targetsCount := h.targetsCount()
+	if int(*h.FailureQuorum) == 0 || int(*h.FailureQuorum) > targetsCount {
+		return fmt.Errorf("%w: %d must be between 1 and the %d targets",
+			ErrHealthFailureQuorumNotValid, *h.FailureQuorum, targetsCount)
+	}
+
+	if *h.StatusFilepath != "" { // optional
+		_, err := filepath.Abs(*h.StatusFilepath)
+		if err != nil {
+			return fmt.Errorf("status filepath is not valid: %w", err)
+		}
+	}
+
+	if *h.ReadyFilepath != "" { // optional
+		_, err := filepath.Abs(*h.ReadyFilepath)
+		if err != nil {
+			return fmt.Errorf("ready filepath is not valid: %w", err)
+		}
+	}
+
+	if *h.UpstreamAddress != "" { // optional
+		_, _, err := net.SplitHostPort(*h.UpstreamAddress)
+		if err != nil {
+			return fmt.Errorf("upstream address is not valid: %w", err)
+		}
+	}
+
+	for _, condition := range h.ReadinessConditions {
+		err = validateReadinessCondition(condition)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = h.VPN.validate()
 	if err != nil {
 		return fmt.Errorf("health VPN settings: %w", err)
 	}
 
+	err = h.Throughput.validate()
+	if err != nil {
+		return fmt.Errorf("health throughput settings: %w", err)
+	}
+
 	return nil
 }
 
+func validateReadinessCondition(condition string) (err error) {
+	switch condition {
+	case constants.ReadinessTunnel, constants.ReadinessPortForwarded,
+		constants.ReadinessDNS, constants.ReadinessPublicIP:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q must be one of %s, %s, %s or %s",
+			ErrReadinessConditionNotValid, condition,
+			constants.ReadinessTunnel, constants.ReadinessPortForwarded,
+			constants.ReadinessDNS, constants.ReadinessPublicIP)
+	}
+}
+
 func (h *Health) copy() (copied Health) {
 	return Health{
-		ServerAddress:     h.ServerAddress,
-		ReadHeaderTimeout: h.ReadHeaderTimeout,
-		ReadTimeout:       h.ReadTimeout,
-		TargetAddress:     h.TargetAddress,
-		VPN:               h.VPN.copy(),
+		ServerAddress:       h.ServerAddress,
+		ReadHeaderTimeout:   h.ReadHeaderTimeout,
+		ReadTimeout:         h.ReadTimeout,
+		Mode:                h.Mode,
+		TargetAddresses:     helpers.CopyStringSlice(h.TargetAddresses),
+		ICMPTargets:         helpers.CopyNetaddrIPsSlice(h.ICMPTargets),
+		DNSHostname:         helpers.CopyStringPtr(h.DNSHostname),
+		FailureQuorum:       helpers.CopyUint8Ptr(h.FailureQuorum),
+		StatusFilepath:      helpers.CopyStringPtr(h.StatusFilepath),
+		ReadyFilepath:       helpers.CopyStringPtr(h.ReadyFilepath),
+		UpstreamAddress:     helpers.CopyStringPtr(h.UpstreamAddress),
+		ReadinessConditions: helpers.CopyStringSlice(h.ReadinessConditions),
+		VPN:                 h.VPN.copy(),
+		Throughput:          h.Throughput.copy(),
 	}
 }
 
@@ -61,8 +195,17 @@ func (h *Health) MergeWith(other Health) {
 	h.ServerAddress = helpers.MergeWithString(h.ServerAddress, other.ServerAddress)
 	h.ReadHeaderTimeout = helpers.MergeWithDuration(h.ReadHeaderTimeout, other.ReadHeaderTimeout)
 	h.ReadTimeout = helpers.MergeWithDuration(h.ReadTimeout, other.ReadTimeout)
-	h.TargetAddress = helpers.MergeWithString(h.TargetAddress, other.TargetAddress)
+	h.Mode = helpers.MergeWithString(h.Mode, other.Mode)
+	h.TargetAddresses = helpers.MergeStringSlices(h.TargetAddresses, other.TargetAddresses)
+	h.ICMPTargets = helpers.MergeNetaddrIPsSlices(h.ICMPTargets, other.ICMPTargets)
+	h.DNSHostname = helpers.MergeWithStringPtr(h.DNSHostname, other.DNSHostname)
+	h.FailureQuorum = helpers.MergeWithUint8(h.FailureQuorum, other.FailureQuorum)
+	h.StatusFilepath = helpers.MergeWithStringPtr(h.StatusFilepath, other.StatusFilepath)
+	h.ReadyFilepath = helpers.MergeWithStringPtr(h.ReadyFilepath, other.ReadyFilepath)
+	h.UpstreamAddress = helpers.MergeWithStringPtr(h.UpstreamAddress, other.UpstreamAddress)
+	h.ReadinessConditions = helpers.MergeStringSlices(h.ReadinessConditions, other.ReadinessConditions)
 	h.VPN.mergeWith(other.VPN)
+	h.Throughput.mergeWith(other.Throughput)
 }
 
 // OverrideWith overrides fields of the receiver
@@ -72,8 +215,17 @@ func (h *Health) OverrideWith(other Health) {
 	h.ServerAddress = helpers.OverrideWithString(h.ServerAddress, other.ServerAddress)
 	h.ReadHeaderTimeout = helpers.OverrideWithDuration(h.ReadHeaderTimeout, other.ReadHeaderTimeout)
 	h.ReadTimeout = helpers.OverrideWithDuration(h.ReadTimeout, other.ReadTimeout)
-	h.TargetAddress = helpers.OverrideWithString(h.TargetAddress, other.TargetAddress)
+	h.Mode = helpers.OverrideWithString(h.Mode, other.Mode)
+	h.TargetAddresses = helpers.OverrideWithStringSlice(h.TargetAddresses, other.TargetAddresses)
+	h.ICMPTargets = helpers.OverrideWithNetaddrIPsSlice(h.ICMPTargets, other.ICMPTargets)
+	h.DNSHostname = helpers.OverrideWithStringPtr(h.DNSHostname, other.DNSHostname)
+	h.FailureQuorum = helpers.OverrideWithUint8(h.FailureQuorum, other.FailureQuorum)
+	h.StatusFilepath = helpers.OverrideWithStringPtr(h.StatusFilepath, other.StatusFilepath)
+	h.ReadyFilepath = helpers.OverrideWithStringPtr(h.ReadyFilepath, other.ReadyFilepath)
+	h.UpstreamAddress = helpers.OverrideWithStringPtr(h.UpstreamAddress, other.UpstreamAddress)
+	h.ReadinessConditions = helpers.OverrideWithStringSlice(h.ReadinessConditions, other.ReadinessConditions)
 	h.VPN.overrideWith(other.VPN)
+	h.Throughput.overrideWith(other.Throughput)
 }
 
 func (h *Health) SetDefaults() {
@@ -82,20 +234,75 @@ func (h *Health) SetDefaults() {
 	h.ReadHeaderTimeout = helpers.DefaultDuration(h.ReadHeaderTimeout, defaultReadHeaderTimeout)
 	const defaultReadTimeout = 500 * time.Millisecond
 	h.ReadTimeout = helpers.DefaultDuration(h.ReadTimeout, defaultReadTimeout)
-	h.TargetAddress = helpers.DefaultString(h.TargetAddress, "cloudflare.com:443")
+	h.Mode = helpers.DefaultString(h.Mode, constants.HealthModeTCP)
+	if len(h.TargetAddresses) == 0 {
+		h.TargetAddresses = []string{"cloudflare.com:443"}
+	}
+	h.DNSHostname = helpers.DefaultStringPtr(h.DNSHostname, "")
+	h.FailureQuorum = helpers.DefaultUint8(h.FailureQuorum, uint8(h.targetsCount()))
+	h.StatusFilepath = helpers.DefaultStringPtr(h.StatusFilepath, "")
+	h.ReadyFilepath = helpers.DefaultStringPtr(h.ReadyFilepath, "")
+	h.UpstreamAddress = helpers.DefaultStringPtr(h.UpstreamAddress, "")
+	if len(h.ReadinessConditions) == 0 {
+		h.ReadinessConditions = []string{constants.ReadinessTunnel}
+	}
 	h.VPN.setDefaults()
+	h.Throughput.setDefaults()
 }
 
 func (h Health) String() string {
 	return h.toLinesNode().String()
 }
 
+// targetsCount returns the number of targets checked
+// for the health mode, the VPN gateway counting as
+// one target in the icmp mode.
+func (h Health) targetsCount() (count int) {
+	switch {
+	case h.Mode != constants.HealthModeICMP:
+		return len(h.TargetAddresses)
+	case len(h.ICMPTargets) == 0:
+		return 1
+	default:
+		return len(h.ICMPTargets)
+	}
+}
+
 func (h Health) toLinesNode() (node *gotree.Node) {
 	node = gotree.New("Health settings:")
 	node.Appendf("Server listening address: %s", h.ServerAddress)
-	node.Appendf("Target address: %s", h.TargetAddress)
+	switch {
+	case h.Mode == constants.HealthModeICMP && len(h.ICMPTargets) == 0:
+		node.Appendf("ICMP ping target: VPN gateway")
+	case h.Mode == constants.HealthModeICMP:
+		targets := make([]string, len(h.ICMPTargets))
+		for i, ip := range h.ICMPTargets {
+			targets[i] = ip.String()
+		}
+		node.Appendf("ICMP ping targets: %s", strings.Join(targets, ", "))
+		node.Appendf("Failure quorum: %d", *h.FailureQuorum)
+	case len(h.TargetAddresses) == 1:
+		node.Appendf("Target address: %s", h.TargetAddresses[0])
+	default:
+		node.Appendf("Target addresses: %s", strings.Join(h.TargetAddresses, ", "))
+		node.Appendf("Failure quorum: %d", *h.FailureQuorum)
+	}
+	if *h.DNSHostname != "" {
+		node.Appendf("DNS check hostname: %s", *h.DNSHostname)
+	}
 	node.Appendf("Read header timeout: %s", h.ReadHeaderTimeout)
 	node.Appendf("Read timeout: %s", h.ReadTimeout)
+	if *h.StatusFilepath != "" {
+		node.Appendf("Status file path: %s", *h.StatusFilepath)
+	}
+	if *h.ReadyFilepath != "" {
+		node.Appendf("Ready file path: %s", *h.ReadyFilepath)
+	}
+	if *h.UpstreamAddress != "" {
+		node.Appendf("Upstream gluetun health address: %s", *h.UpstreamAddress)
+	}
+	node.Appendf("Readiness conditions: %s", strings.Join(h.ReadinessConditions, ", "))
 	node.AppendNode(h.VPN.toLinesNode("VPN"))
+	node.AppendNode(h.Throughput.toLinesNode())
 	return node
 }
@@ -0,0 +1,27 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/stretchr/testify/assert"
+	"inet.af/netaddr"
+)
+
+func Test_Health_validateICMPTargets(t *testing.T) {
+	t.Parallel()
+
+	health := Health{Mode: constants.HealthModeTCP}
+	assert.NoError(t, health.validateICMPTargets(vpn.Wireguard))
+
+	health.Mode = constants.HealthModeICMP
+	assert.NoError(t, health.validateICMPTargets(vpn.OpenVPN))
+	err := health.validateICMPTargets(vpn.Wireguard)
+	assert.ErrorIs(t, err, ErrHealthICMPTargetsNotSet)
+	assert.EqualError(t, err, "health ICMP targets are not set: "+
+		"the wireguard VPN has no gateway to ping")
+
+	health.ICMPTargets = []netaddr.IP{netaddr.IPv4(1, 1, 1, 1)}
+	assert.NoError(t, health.validateICMPTargets(vpn.Wireguard))
+}
@@ -0,0 +1,82 @@
+package settings
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// HealthThroughput contains settings to detect a VPN connection
+// receiving no traffic while traffic is sent through it, which
+// happens with servers blackholing the traffic.
+type HealthThroughput struct {
+	// StallDuration is the duration the VPN interface can receive
+	// no byte while sending bytes before the VPN is considered
+	// degraded. It is 0 to disable the check, and cannot be nil
+	// in the internal state.
+	StallDuration *time.Duration
+	// Reconnect is true if the VPN should be reconnected once it
+	// is degraded, and false to only report it as unhealthy.
+	// It cannot be nil in the internal state.
+	Reconnect *bool
+}
+
+func (h HealthThroughput) validate() (err error) {
+	if *h.StallDuration == 0 {
+		return nil
+	}
+
+	// The health check sends traffic every 5 seconds at most,
+	// so shorter durations would flag healthy connections.
+	const minStallDuration = 30 * time.Second
+	if *h.StallDuration < minStallDuration {
+		return fmt.Errorf("%w: %s must be at least %s",
+			ErrHealthStallDurationTooSmall, *h.StallDuration, minStallDuration)
+	}
+
+	return nil
+}
+
+func (h *HealthThroughput) copy() (copied HealthThroughput) {
+	return HealthThroughput{
+		StallDuration: helpers.CopyDurationPtr(h.StallDuration),
+		Reconnect:     helpers.CopyBoolPtr(h.Reconnect),
+	}
+}
+
+// mergeWith merges the other settings into any
+// unset field of the receiver settings object.
+func (h *HealthThroughput) mergeWith(other HealthThroughput) {
+	h.StallDuration = helpers.MergeWithDurationPtr(h.StallDuration, other.StallDuration)
+	h.Reconnect = helpers.MergeWithBool(h.Reconnect, other.Reconnect)
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (h *HealthThroughput) overrideWith(other HealthThroughput) {
+	h.StallDuration = helpers.OverrideWithDurationPtr(h.StallDuration, other.StallDuration)
+	h.Reconnect = helpers.OverrideWithBool(h.Reconnect, other.Reconnect)
+}
+
+func (h *HealthThroughput) setDefaults() {
+	h.StallDuration = helpers.DefaultDurationPtr(h.StallDuration, 0)
+	h.Reconnect = helpers.DefaultBool(h.Reconnect, false)
+}
+
+func (h HealthThroughput) String() string {
+	return h.toLinesNode().String()
+}
+
+func (h HealthThroughput) toLinesNode() (node *gotree.Node) {
+	if *h.StallDuration == 0 {
+		return nil
+	}
+
+	node = gotree.New("Throughput check:")
+	node.Appendf("Stall duration: %s", *h.StallDuration)
+	node.Appendf("Reconnect on stall: %s", helpers.BoolPtrToYesNo(h.Reconnect))
+	return node
+}
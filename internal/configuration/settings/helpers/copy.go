@@ -53,6 +53,15 @@ func CopyUint32Ptr(original *uint32) (copied *uint32) {
 	return copied
 }
 
+func CopyUint64Ptr(original *uint64) (copied *uint64) {
+	if original == nil {
+		return nil
+	}
+	copied = new(uint64)
+	*copied = *original
+	return copied
+}
+
 func CopyIntPtr(original *int) (copied *int) {
 	if original == nil {
 		return nil
@@ -46,6 +46,16 @@ func DefaultUint32(existing *uint32, defaultValue uint32) (
 	return result
 }
 
+func DefaultUint64(existing *uint64, defaultValue uint64) (
+	result *uint64) {
+	if existing != nil {
+		return existing
+	}
+	result = new(uint64)
+	*result = defaultValue
+	return result
+}
+
 func DefaultBool(existing *bool, defaultValue bool) (
 	result *bool) {
 	if existing != nil {
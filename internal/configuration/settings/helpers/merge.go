@@ -96,6 +96,17 @@ func MergeWithUint32(existing, other *uint32) (result *uint32) {
 	return result
 }
 
+func MergeWithUint64(existing, other *uint64) (result *uint64) {
+	if existing != nil {
+		return existing
+	} else if other == nil {
+		return nil
+	}
+	result = new(uint64)
+	*result = *other
+	return result
+}
+
 func MergeWithIP(existing, other net.IP) (result net.IP) {
 	if existing != nil {
 		return existing
@@ -84,6 +84,15 @@ func OverrideWithUint32(existing, other *uint32) (result *uint32) {
 	return result
 }
 
+func OverrideWithUint64(existing, other *uint64) (result *uint64) {
+	if other == nil {
+		return existing
+	}
+	result = new(uint64)
+	*result = *other
+	return result
+}
+
 func OverrideWithIP(existing, other net.IP) (result net.IP) {
 	if other == nil {
 		return existing
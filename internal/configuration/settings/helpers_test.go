@@ -1,4 +1,6 @@
 package settings
 
-func boolPtr(b bool) *bool    { return &b }
-func uint8Ptr(n uint8) *uint8 { return &n }
+func boolPtr(b bool) *bool       { return &b }
+func uint8Ptr(n uint8) *uint8    { return &n }
+func uint16Ptr(n uint16) *uint16 { return &n }
+func stringPtr(s string) *string { return &s }
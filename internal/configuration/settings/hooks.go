@@ -0,0 +1,102 @@
+package settings
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// Hooks contains settings to run user executables at defined
+// stages of the program lifecycle, so customizations do not
+// require a modified image or entrypoint.
+type Hooks struct {
+	// Directory is the directory containing one subdirectory
+	// per lifecycle stage, such as pre-firewall, each containing
+	// the executables to run in lexical order for that stage.
+	// It can be the empty string to disable hooks, and cannot
+	// be nil in the internal state.
+	Directory *string
+	// Timeout is the maximum duration of each hook executable.
+	// It cannot be nil or 0 in the internal state.
+	Timeout *time.Duration
+	// FailurePolicy is the action to take when a hook fails,
+	// and can be 'continue' to log the error and run the next
+	// hooks, or 'abort' to skip the next hooks and fail the
+	// stage: gluetun exits for pre-firewall, the VPN is stopped
+	// for post-tunnel-up and shutdown continues for pre-shutdown.
+	// It cannot be the empty string in the internal state.
+	FailurePolicy string
+}
+
+func (h Hooks) validate() (err error) {
+	if *h.Directory == "" {
+		return nil
+	}
+
+	if !filepath.IsAbs(*h.Directory) {
+		return fmt.Errorf("%w: %s is not an absolute path",
+			ErrHooksDirectoryNotValid, *h.Directory)
+	}
+
+	if *h.Timeout <= 0 {
+		return fmt.Errorf("%w: %s must be positive", ErrHooksTimeoutNotValid, *h.Timeout)
+	}
+
+	if !helpers.IsOneOf(h.FailurePolicy, "continue", "abort") {
+		return fmt.Errorf("%w: %q must be one of continue or abort",
+			ErrHooksFailurePolicyNotValid, h.FailurePolicy)
+	}
+
+	return nil
+}
+
+func (h *Hooks) copy() (copied Hooks) {
+	return Hooks{
+		Directory:     helpers.CopyStringPtr(h.Directory),
+		Timeout:       helpers.CopyDurationPtr(h.Timeout),
+		FailurePolicy: h.FailurePolicy,
+	}
+}
+
+// mergeWith merges the other settings into any
+// unset field of the receiver settings object.
+func (h *Hooks) mergeWith(other Hooks) {
+	h.Directory = helpers.MergeWithStringPtr(h.Directory, other.Directory)
+	h.Timeout = helpers.MergeWithDurationPtr(h.Timeout, other.Timeout)
+	h.FailurePolicy = helpers.MergeWithString(h.FailurePolicy, other.FailurePolicy)
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (h *Hooks) overrideWith(other Hooks) {
+	h.Directory = helpers.OverrideWithStringPtr(h.Directory, other.Directory)
+	h.Timeout = helpers.OverrideWithDurationPtr(h.Timeout, other.Timeout)
+	h.FailurePolicy = helpers.OverrideWithString(h.FailurePolicy, other.FailurePolicy)
+}
+
+func (h *Hooks) setDefaults() {
+	h.Directory = helpers.DefaultStringPtr(h.Directory, "")
+	const defaultTimeout = 30 * time.Second
+	h.Timeout = helpers.DefaultDurationPtr(h.Timeout, defaultTimeout)
+	h.FailurePolicy = helpers.DefaultString(h.FailurePolicy, "continue")
+}
+
+func (h Hooks) String() string {
+	return h.toLinesNode().String()
+}
+
+func (h Hooks) toLinesNode() (node *gotree.Node) {
+	if *h.Directory == "" {
+		return nil
+	}
+
+	node = gotree.New("Hooks settings:")
+	node.Appendf("Directory: %s", *h.Directory)
+	node.Appendf("Timeout: %s", *h.Timeout)
+	node.Appendf("Failure policy: %s", h.FailurePolicy)
+	return node
+}
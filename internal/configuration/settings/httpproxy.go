@@ -40,6 +40,14 @@ type HTTPProxy struct {
 	// ReadTimeout is the HTTP read timeout duration
 	// of the HTTP server. It defaults to 3 seconds if left unset.
 	ReadTimeout time.Duration
+	// ConnectPorts are the destination ports CONNECT requests
+	// are allowed to target. It defaults to 443 only.
+	ConnectPorts []uint16
+	// PlainHTTPAnyPort is true if plain HTTP requests can be
+	// proxied to any destination port, and false to only proxy
+	// them to the default port of their scheme, 80 for http and
+	// 443 for https. It cannot be nil in the internal state.
+	PlainHTTPAnyPort *bool
 }
 
 func (h HTTPProxy) validate() (err error) {
@@ -51,6 +59,10 @@ func (h HTTPProxy) validate() (err error) {
 		return fmt.Errorf("%w: %s", ErrServerAddressNotValid, h.ListeningAddress)
 	}
 
+	if hasZeroPort(h.ConnectPorts) {
+		return fmt.Errorf("CONNECT ports: %w", ErrHTTPProxyZeroPort)
+	}
+
 	return nil
 }
 
@@ -64,6 +76,8 @@ func (h *HTTPProxy) copy() (copied HTTPProxy) {
 		Log:               helpers.CopyBoolPtr(h.Log),
 		ReadHeaderTimeout: h.ReadHeaderTimeout,
 		ReadTimeout:       h.ReadTimeout,
+		ConnectPorts:      helpers.CopyUint16Slice(h.ConnectPorts),
+		PlainHTTPAnyPort:  helpers.CopyBoolPtr(h.PlainHTTPAnyPort),
 	}
 }
 
@@ -78,6 +92,8 @@ func (h *HTTPProxy) mergeWith(other HTTPProxy) {
 	h.Log = helpers.MergeWithBool(h.Log, other.Log)
 	h.ReadHeaderTimeout = helpers.MergeWithDuration(h.ReadHeaderTimeout, other.ReadHeaderTimeout)
 	h.ReadTimeout = helpers.MergeWithDuration(h.ReadTimeout, other.ReadTimeout)
+	h.ConnectPorts = helpers.MergeUint16Slices(h.ConnectPorts, other.ConnectPorts)
+	h.PlainHTTPAnyPort = helpers.MergeWithBool(h.PlainHTTPAnyPort, other.PlainHTTPAnyPort)
 }
 
 // overrideWith overrides fields of the receiver
@@ -92,6 +108,8 @@ func (h *HTTPProxy) overrideWith(other HTTPProxy) {
 	h.Log = helpers.OverrideWithBool(h.Log, other.Log)
 	h.ReadHeaderTimeout = helpers.OverrideWithDuration(h.ReadHeaderTimeout, other.ReadHeaderTimeout)
 	h.ReadTimeout = helpers.OverrideWithDuration(h.ReadTimeout, other.ReadTimeout)
+	h.ConnectPorts = helpers.OverrideWithUint16Slice(h.ConnectPorts, other.ConnectPorts)
+	h.PlainHTTPAnyPort = helpers.OverrideWithBool(h.PlainHTTPAnyPort, other.PlainHTTPAnyPort)
 }
 
 func (h *HTTPProxy) setDefaults() {
@@ -105,6 +123,11 @@ func (h *HTTPProxy) setDefaults() {
 	h.ReadHeaderTimeout = helpers.DefaultDuration(h.ReadHeaderTimeout, defaultReadHeaderTimeout)
 	const defaultReadTimeout = 3 * time.Second
 	h.ReadTimeout = helpers.DefaultDuration(h.ReadTimeout, defaultReadTimeout)
+	if h.ConnectPorts == nil {
+		const httpsPort = 443
+		h.ConnectPorts = []uint16{httpsPort}
+	}
+	h.PlainHTTPAnyPort = helpers.DefaultBool(h.PlainHTTPAnyPort, false)
 }
 
 func (h HTTPProxy) String() string {
@@ -125,6 +148,8 @@ func (h HTTPProxy) toLinesNode() (node *gotree.Node) {
 	node.Appendf("Log: %s", helpers.BoolPtrToYesNo(h.Log))
 	node.Appendf("Read header timeout: %s", h.ReadHeaderTimeout)
 	node.Appendf("Read timeout: %s", h.ReadTimeout)
+	node.Appendf("CONNECT ports: %v", h.ConnectPorts)
+	node.Appendf("Plain HTTP to any port: %s", helpers.BoolPtrToYesNo(h.PlainHTTPAnyPort))
 
 	return node
 }
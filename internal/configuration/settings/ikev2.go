@@ -0,0 +1,130 @@
+package settings
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// IKEv2 contains settings to connect to an IKEv2/IPsec
+// VPN server, when the VPN type is 'ikev2'.
+type IKEv2 struct {
+	// Server is the IP address of the IKEv2 server.
+	// It cannot be the empty string if the VPN type
+	// is 'ikev2'.
+	Server string
+	// RemoteID is the identity of the server, which its
+	// certificate must be valid for, and is usually the server
+	// hostname. It defaults to the empty string meaning the
+	// server IP address is used, and cannot be nil in the
+	// internal state.
+	RemoteID *string
+	// User is the EAP-MSCHAPv2 username.
+	// It cannot be nil in the internal state.
+	User *string
+	// Password is the EAP-MSCHAPv2 password.
+	// It cannot be nil in the internal state.
+	Password *string
+	// CACertificate is the base64 encoded DER of the certificate
+	// authority the server certificate is signed by. It defaults
+	// to the empty string meaning the system certificate
+	// authorities are used, and cannot be nil in the internal state.
+	CACertificate *string
+	// Interface is the name of the XFRM interface to create.
+	// It cannot be the empty string in the internal state.
+	Interface string
+}
+
+func (i IKEv2) validate() (err error) {
+	if net.ParseIP(i.Server) == nil {
+		return fmt.Errorf("%w: %q is not an IP address", ErrIKEv2ServerNotValid, i.Server)
+	}
+
+	if *i.User == "" {
+		return fmt.Errorf("%w", ErrIKEv2UserNotSet)
+	}
+
+	if *i.Password == "" {
+		return fmt.Errorf("%w", ErrIKEv2PasswordNotSet)
+	}
+
+	if *i.CACertificate != "" {
+		der, err := base64.StdEncoding.DecodeString(*i.CACertificate)
+		if err != nil {
+			return fmt.Errorf("decoding CA certificate: %w", err)
+		}
+		_, err = x509.ParseCertificate(der)
+		if err != nil {
+			return fmt.Errorf("parsing CA certificate: %w", err)
+		}
+	}
+
+	if !regexpInterfaceName.MatchString(i.Interface) {
+		return fmt.Errorf("%w: '%s' does not match regex '%s'",
+			ErrIKEv2InterfaceNotValid, i.Interface, regexpInterfaceName)
+	}
+
+	return nil
+}
+
+func (i *IKEv2) copy() (copied IKEv2) {
+	return IKEv2{
+		Server:        i.Server,
+		RemoteID:      helpers.CopyStringPtr(i.RemoteID),
+		User:          helpers.CopyStringPtr(i.User),
+		Password:      helpers.CopyStringPtr(i.Password),
+		CACertificate: helpers.CopyStringPtr(i.CACertificate),
+		Interface:     i.Interface,
+	}
+}
+
+func (i *IKEv2) mergeWith(other IKEv2) {
+	i.Server = helpers.MergeWithString(i.Server, other.Server)
+	i.RemoteID = helpers.MergeWithStringPtr(i.RemoteID, other.RemoteID)
+	i.User = helpers.MergeWithStringPtr(i.User, other.User)
+	i.Password = helpers.MergeWithStringPtr(i.Password, other.Password)
+	i.CACertificate = helpers.MergeWithStringPtr(i.CACertificate, other.CACertificate)
+	i.Interface = helpers.MergeWithString(i.Interface, other.Interface)
+}
+
+func (i *IKEv2) overrideWith(other IKEv2) {
+	i.Server = helpers.OverrideWithString(i.Server, other.Server)
+	i.RemoteID = helpers.OverrideWithStringPtr(i.RemoteID, other.RemoteID)
+	i.User = helpers.OverrideWithStringPtr(i.User, other.User)
+	i.Password = helpers.OverrideWithStringPtr(i.Password, other.Password)
+	i.CACertificate = helpers.OverrideWithStringPtr(i.CACertificate, other.CACertificate)
+	i.Interface = helpers.OverrideWithString(i.Interface, other.Interface)
+}
+
+func (i *IKEv2) setDefaults() {
+	i.RemoteID = helpers.DefaultStringPtr(i.RemoteID, "")
+	i.User = helpers.DefaultStringPtr(i.User, "")
+	i.Password = helpers.DefaultStringPtr(i.Password, "")
+	i.CACertificate = helpers.DefaultStringPtr(i.CACertificate, "")
+	i.Interface = helpers.DefaultString(i.Interface, "ipsec0")
+}
+
+func (i IKEv2) String() string {
+	return i.toLinesNode().String()
+}
+
+func (i IKEv2) toLinesNode() (node *gotree.Node) {
+	node = gotree.New("IKEv2 settings:")
+	node.Appendf("Server: %s", i.Server)
+	if *i.RemoteID != "" {
+		node.Appendf("Remote ID: %s", *i.RemoteID)
+	}
+	node.Appendf("User: %s", helpers.ObfuscatePassword(*i.User))
+	node.Appendf("Password: %s", helpers.ObfuscatePassword(*i.Password))
+	if *i.CACertificate != "" {
+		node.Appendf("CA certificate: %s", helpers.ObfuscateData(*i.CACertificate))
+	} else {
+		node.Appendf("CA certificate: system certificate authorities")
+	}
+	node.Appendf("Network interface: %s", i.Interface)
+	return node
+}
@@ -0,0 +1,68 @@
+package settings
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// Notification contains settings for the notifications
+// sent to the user, for example about new releases.
+type Notification struct {
+	// WebhookURL is the URL notifications are posted to as JSON,
+	// in addition to being logged. It can be the empty string to
+	// only log notifications, and cannot be nil in the internal state.
+	WebhookURL *string
+}
+
+func (n Notification) validate() (err error) {
+	if *n.WebhookURL == "" {
+		return nil
+	}
+
+	_, err = url.ParseRequestURI(*n.WebhookURL)
+	if err != nil {
+		return fmt.Errorf("webhook URL is not valid: %w", err)
+	}
+
+	return nil
+}
+
+func (n *Notification) copy() (copied Notification) {
+	return Notification{
+		WebhookURL: helpers.CopyStringPtr(n.WebhookURL),
+	}
+}
+
+// mergeWith merges the other settings into any
+// unset field of the receiver settings object.
+func (n *Notification) mergeWith(other Notification) {
+	n.WebhookURL = helpers.MergeWithStringPtr(n.WebhookURL, other.WebhookURL)
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (n *Notification) overrideWith(other Notification) {
+	n.WebhookURL = helpers.OverrideWithStringPtr(n.WebhookURL, other.WebhookURL)
+}
+
+func (n *Notification) setDefaults() {
+	n.WebhookURL = helpers.DefaultStringPtr(n.WebhookURL, "")
+}
+
+func (n Notification) String() string {
+	return n.toLinesNode().String()
+}
+
+func (n Notification) toLinesNode() (node *gotree.Node) {
+	if *n.WebhookURL == "" {
+		return nil
+	}
+
+	node = gotree.New("Notification settings:")
+	node.Appendf("Webhook URL: %s", *n.WebhookURL)
+	return node
+}
@@ -0,0 +1,158 @@
+package settings
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// OpenConnect contains settings to connect to a Cisco AnyConnect
+// compatible gateway, when the VPN type is 'openconnect'.
+type OpenConnect struct {
+	// Server is the IP address of the gateway.
+	// It cannot be the empty string if the VPN type
+	// is 'openconnect'.
+	Server string
+	// Port is the HTTPS port of the gateway.
+	// It defaults to 443 and cannot be nil in the internal state.
+	Port *uint16
+	// Hostname is the hostname of the gateway, used to verify
+	// its certificate and in the HTTP requests to it. It defaults
+	// to the empty string meaning the server IP address is used,
+	// and cannot be nil in the internal state.
+	Hostname *string
+	// AuthGroup is the authentication group to select on the
+	// gateway login form. It defaults to the empty string meaning
+	// the gateway default group is used, and cannot be nil in
+	// the internal state.
+	AuthGroup *string
+	// User is the username to log in with.
+	// It cannot be nil in the internal state.
+	User *string
+	// Password is the password to log in with.
+	// It cannot be nil in the internal state.
+	Password *string
+	// ServerCertificate is the fingerprint of the gateway
+	// certificate to pin, such as pin-sha256:<base64 hash>.
+	// It defaults to the empty string meaning the certificate is
+	// verified against the system certificate authorities, and cannot
+	// be nil in the internal state.
+	ServerCertificate *string
+	// Interface is the name of the TUN interface to create.
+	// It cannot be the empty string in the internal state.
+	Interface string
+}
+
+func (o OpenConnect) validate() (err error) {
+	if net.ParseIP(o.Server) == nil {
+		return fmt.Errorf("%w: %q is not an IP address", ErrOpenConnectServerNotValid, o.Server)
+	}
+
+	if *o.Port == 0 {
+		return fmt.Errorf("%w", ErrOpenConnectPortNotSet)
+	}
+
+	if *o.User == "" {
+		return fmt.Errorf("%w", ErrOpenConnectUserNotSet)
+	}
+
+	if *o.Password == "" {
+		return fmt.Errorf("%w", ErrOpenConnectPasswordNotSet)
+	}
+
+	if *o.ServerCertificate != "" {
+		validPrefixes := []string{"pin-sha256:", "sha256:", "sha1:"}
+		valid := false
+		for _, prefix := range validPrefixes {
+			if strings.HasPrefix(*o.ServerCertificate, prefix) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("%w: %s must start with one of %s",
+				ErrOpenConnectServerCertificateNotValid, *o.ServerCertificate,
+				helpers.ChoicesOrString(validPrefixes))
+		}
+	}
+
+	if !regexpInterfaceName.MatchString(o.Interface) {
+		return fmt.Errorf("%w: '%s' does not match regex '%s'",
+			ErrOpenConnectInterfaceNotValid, o.Interface, regexpInterfaceName)
+	}
+
+	return nil
+}
+
+func (o *OpenConnect) copy() (copied OpenConnect) {
+	return OpenConnect{
+		Server:            o.Server,
+		Port:              helpers.CopyUint16Ptr(o.Port),
+		Hostname:          helpers.CopyStringPtr(o.Hostname),
+		AuthGroup:         helpers.CopyStringPtr(o.AuthGroup),
+		User:              helpers.CopyStringPtr(o.User),
+		Password:          helpers.CopyStringPtr(o.Password),
+		ServerCertificate: helpers.CopyStringPtr(o.ServerCertificate),
+		Interface:         o.Interface,
+	}
+}
+
+func (o *OpenConnect) mergeWith(other OpenConnect) {
+	o.Server = helpers.MergeWithString(o.Server, other.Server)
+	o.Port = helpers.MergeWithUint16(o.Port, other.Port)
+	o.Hostname = helpers.MergeWithStringPtr(o.Hostname, other.Hostname)
+	o.AuthGroup = helpers.MergeWithStringPtr(o.AuthGroup, other.AuthGroup)
+	o.User = helpers.MergeWithStringPtr(o.User, other.User)
+	o.Password = helpers.MergeWithStringPtr(o.Password, other.Password)
+	o.ServerCertificate = helpers.MergeWithStringPtr(o.ServerCertificate, other.ServerCertificate)
+	o.Interface = helpers.MergeWithString(o.Interface, other.Interface)
+}
+
+func (o *OpenConnect) overrideWith(other OpenConnect) {
+	o.Server = helpers.OverrideWithString(o.Server, other.Server)
+	o.Port = helpers.OverrideWithUint16(o.Port, other.Port)
+	o.Hostname = helpers.OverrideWithStringPtr(o.Hostname, other.Hostname)
+	o.AuthGroup = helpers.OverrideWithStringPtr(o.AuthGroup, other.AuthGroup)
+	o.User = helpers.OverrideWithStringPtr(o.User, other.User)
+	o.Password = helpers.OverrideWithStringPtr(o.Password, other.Password)
+	o.ServerCertificate = helpers.OverrideWithStringPtr(o.ServerCertificate, other.ServerCertificate)
+	o.Interface = helpers.OverrideWithString(o.Interface, other.Interface)
+}
+
+func (o *OpenConnect) setDefaults() {
+	const defaultPort = 443
+	o.Port = helpers.DefaultUint16(o.Port, defaultPort)
+	o.Hostname = helpers.DefaultStringPtr(o.Hostname, "")
+	o.AuthGroup = helpers.DefaultStringPtr(o.AuthGroup, "")
+	o.User = helpers.DefaultStringPtr(o.User, "")
+	o.Password = helpers.DefaultStringPtr(o.Password, "")
+	o.ServerCertificate = helpers.DefaultStringPtr(o.ServerCertificate, "")
+	o.Interface = helpers.DefaultString(o.Interface, "tun0")
+}
+
+func (o OpenConnect) String() string {
+	return o.toLinesNode().String()
+}
+
+func (o OpenConnect) toLinesNode() (node *gotree.Node) {
+	node = gotree.New("OpenConnect settings:")
+	node.Appendf("Server: %s:%d", o.Server, *o.Port)
+	if *o.Hostname != "" {
+		node.Appendf("Hostname: %s", *o.Hostname)
+	}
+	if *o.AuthGroup != "" {
+		node.Appendf("Authentication group: %s", *o.AuthGroup)
+	}
+	node.Appendf("User: %s", helpers.ObfuscatePassword(*o.User))
+	node.Appendf("Password: %s", helpers.ObfuscatePassword(*o.Password))
+	if *o.ServerCertificate != "" {
+		node.Appendf("Server certificate: %s", *o.ServerCertificate)
+	} else {
+		node.Appendf("Server certificate: system certificate authorities")
+	}
+	node.Appendf("Network interface: %s", o.Interface)
+	return node
+}
@@ -29,7 +29,9 @@ type OpenVPN struct {
 	// It is usually required but in some cases can be the empty string
 	// to indicate no user+password authentication is needed.
 	Password *string
-	// ConfFile is a custom OpenVPN configuration file path.
+	// ConfFile is a custom OpenVPN configuration file path,
+	// or a directory path of OpenVPN configuration files, each
+	// being a server selectable with its name without extension.
 	// It can be set to the empty string for it to be ignored.
 	// It cannot be nil in the internal state.
 	ConfFile *string
@@ -61,6 +63,22 @@ type OpenVPN struct {
 	// to decrypt the EncryptedPrivateKey. It defaults to the
 	// empty string and must be set if EncryptedPrivateKey is set.
 	KeyPassphrase *string
+	// PKCS11Provider is the path to the PKCS#11 provider library
+	// to use to access the client certificate and private key stored
+	// on a smartcard or hardware security module, instead of using
+	// Cert and Key. It defaults to the empty string meaning it
+	// is not to be used. PKCS11ID must be set if this one is set.
+	PKCS11Provider *string
+	// PKCS11ID is the serialized PKCS#11 identifier of the client
+	// certificate, as shown by `openvpn --show-pkcs11-ids <provider>`.
+	// It defaults to the empty string and must be set if
+	// PKCS11Provider is set.
+	PKCS11ID *string
+	// PKCS11PIN is the PIN of the PKCS#11 token, sent to OpenVPN
+	// through its management interface when it asks for it.
+	// It defaults to the empty string meaning no PIN is sent,
+	// for tokens without PIN or with a protected authentication path.
+	PKCS11PIN *string
 	// PIAEncPreset is the encryption preset for
 	// Private Internet Access. It can be set to an
 	// empty string for other providers.
@@ -82,11 +100,16 @@ type OpenVPN struct {
 	// Flags is a slice of additional flags to be passed
 	// to the OpenVPN program.
 	Flags []string
+	// Obfuscation contains settings to tunnel the OpenVPN
+	// TCP connection inside TLS.
+	Obfuscation OpenVPNObfuscation
 }
 
 var ivpnAccountID = regexp.MustCompile(`^(i|ivpn)\-[a-zA-Z0-9]{4}\-[a-zA-Z0-9]{4}\-[a-zA-Z0-9]{4}$`)
 
-func (o OpenVPN) validate(vpnProvider string) (err error) {
+// validate validates the OpenVPN settings, knowing tcp is true
+// if the OpenVPN protocol selected is TCP.
+func (o OpenVPN) validate(vpnProvider string, tcp bool) (err error) {
 	// Validate version
 	validVersions := []string{openvpn.Openvpn24, openvpn.Openvpn25}
 	if !helpers.IsOneOf(o.Version, validVersions...) {
@@ -97,6 +120,8 @@ func (o OpenVPN) validate(vpnProvider string) (err error) {
 	isCustom := vpnProvider == providers.Custom
 	isUserRequired := !isCustom &&
 		vpnProvider != providers.Airvpn &&
+		vpnProvider != providers.Plugin &&
+		vpnProvider != providers.UserDefined &&
 		vpnProvider != providers.VPNSecure
 
 	if isUserRequired && *o.User == "" {
@@ -110,24 +135,38 @@ func (o OpenVPN) validate(vpnProvider string) (err error) {
 		return fmt.Errorf("%w", ErrOpenVPNPasswordIsEmpty)
 	}
 
-	err = validateOpenVPNConfigFilepath(isCustom, *o.ConfFile)
+	// OVPN does not publish its OpenVPN certificate authority and
+	// tls-auth key separately, so its configuration file is required.
+	isConfFileRequired := isCustom || vpnProvider == providers.Ovpn
+	err = validateOpenVPNConfigFilepath(isConfFileRequired, *o.ConfFile)
 	if err != nil {
 		return fmt.Errorf("custom configuration file: %w", err)
 	}
 
-	err = validateOpenVPNClientCertificate(vpnProvider, *o.Cert)
-	if err != nil {
-		return fmt.Errorf("client certificate: %w", err)
+	if vpnProvider == providers.Ovpn && extract.IsDirectory(*o.ConfFile) {
+		return fmt.Errorf("%w: %s", ErrOpenVPNConfigFileIsDirectory, *o.ConfFile)
 	}
 
-	err = validateOpenVPNClientKey(vpnProvider, *o.Key)
-	if err != nil {
-		return fmt.Errorf("client key: %w", err)
-	}
+	if *o.PKCS11Provider != "" || *o.PKCS11ID != "" {
+		err = o.validatePKCS11()
+		if err != nil {
+			return fmt.Errorf("PKCS#11: %w", err)
+		}
+	} else {
+		err = validateOpenVPNClientCertificate(vpnProvider, *o.Cert)
+		if err != nil {
+			return fmt.Errorf("client certificate: %w", err)
+		}
 
-	err = validateOpenVPNEncryptedKey(vpnProvider, *o.EncryptedKey)
-	if err != nil {
-		return fmt.Errorf("encrypted key: %w", err)
+		err = validateOpenVPNClientKey(vpnProvider, *o.Key)
+		if err != nil {
+			return fmt.Errorf("client key: %w", err)
+		}
+
+		err = validateOpenVPNEncryptedKey(vpnProvider, *o.EncryptedKey)
+		if err != nil {
+			return fmt.Errorf("encrypted key: %w", err)
+		}
 	}
 
 	if *o.EncryptedKey != "" && *o.KeyPassphrase == "" {
@@ -150,12 +189,46 @@ func (o OpenVPN) validate(vpnProvider string) (err error) {
 			ErrOpenVPNVerbosityIsOutOfBounds, o.Verbosity)
 	}
 
+	err = o.Obfuscation.validate(vpnProvider, tcp)
+	if err != nil {
+		return fmt.Errorf("obfuscation settings: %w", err)
+	}
+
+	return nil
+}
+
+// validatePKCS11 validates the PKCS#11 settings, knowing at least
+// one of the PKCS#11 provider or ID is set. Since the client certificate
+// and key are then on the PKCS#11 token, the client certificate
+// and key settings cannot be set.
+func (o OpenVPN) validatePKCS11() (err error) {
+	switch {
+	case *o.PKCS11Provider == "":
+		return fmt.Errorf("%w", ErrOpenVPNPKCS11ProviderNotSet)
+	case *o.PKCS11ID == "":
+		return fmt.Errorf("%w", ErrOpenVPNPKCS11IDNotSet)
+	case *o.Cert != "", *o.Key != "", *o.EncryptedKey != "":
+		return fmt.Errorf("%w", ErrOpenVPNPKCS11WithClientKey)
+	// The ID is single quoted in the OpenVPN configuration and
+	// the PIN is sent on a single management interface line.
+	case strings.ContainsAny(*o.PKCS11ID, "'\r\n"):
+		return fmt.Errorf("%w: it cannot contain a single quote or a new line",
+			ErrOpenVPNPKCS11IDNotValid)
+	case strings.ContainsAny(*o.PKCS11PIN, "\r\n"):
+		return fmt.Errorf("%w: it cannot contain a new line", ErrOpenVPNPKCS11PINNotValid)
+	}
+
+	err = helpers.FileExists(*o.PKCS11Provider)
+	if err != nil {
+		return fmt.Errorf("provider library: %w", err)
+	}
+
 	return nil
 }
 
-func validateOpenVPNConfigFilepath(isCustom bool,
+func validateOpenVPNConfigFilepath(isRequired bool,
 	confFile string) (err error) {
-	if !isCustom {
+	if !isRequired {
 		return nil
 	}
 
@@ -169,9 +242,24 @@ func validateOpenVPNConfigFilepath(isCustom bool,
 	}
 
 	extractor := extract.New()
-	_, _, err = extractor.Data(confFile)
+	if !extract.IsDirectory(confFile) {
+		_, _, err = extractor.Data(confFile)
+		if err != nil {
+			return fmt.Errorf("extracting information from custom configuration file: %w", err)
+		}
+		return nil
+	}
+
+	nameToPath, err := extract.ConfigFiles(confFile)
 	if err != nil {
-		return fmt.Errorf("extracting information from custom configuration file: %w", err)
+		return err
+	}
+
+	for _, path := range nameToPath {
+		_, _, err = extractor.Data(path)
+		if err != nil {
+			return fmt.Errorf("extracting information from custom configuration file %s: %w", path, err)
+		}
 	}
 
 	return nil
@@ -243,22 +331,26 @@ func validateOpenVPNEncryptedKey(vpnProvider,
 
 func (o *OpenVPN) copy() (copied OpenVPN) {
 	return OpenVPN{
-		Version:       o.Version,
-		User:          helpers.CopyStringPtr(o.User),
-		Password:      helpers.CopyStringPtr(o.Password),
-		ConfFile:      helpers.CopyStringPtr(o.ConfFile),
-		Ciphers:       helpers.CopyStringSlice(o.Ciphers),
-		Auth:          helpers.CopyStringPtr(o.Auth),
-		Cert:          helpers.CopyStringPtr(o.Cert),
-		Key:           helpers.CopyStringPtr(o.Key),
-		EncryptedKey:  helpers.CopyStringPtr(o.EncryptedKey),
-		KeyPassphrase: helpers.CopyStringPtr(o.KeyPassphrase),
-		PIAEncPreset:  helpers.CopyStringPtr(o.PIAEncPreset),
-		MSSFix:        helpers.CopyUint16Ptr(o.MSSFix),
-		Interface:     o.Interface,
-		ProcessUser:   o.ProcessUser,
-		Verbosity:     helpers.CopyIntPtr(o.Verbosity),
-		Flags:         helpers.CopyStringSlice(o.Flags),
+		Version:        o.Version,
+		User:           helpers.CopyStringPtr(o.User),
+		Password:       helpers.CopyStringPtr(o.Password),
+		ConfFile:       helpers.CopyStringPtr(o.ConfFile),
+		Ciphers:        helpers.CopyStringSlice(o.Ciphers),
+		Auth:           helpers.CopyStringPtr(o.Auth),
+		Cert:           helpers.CopyStringPtr(o.Cert),
+		Key:            helpers.CopyStringPtr(o.Key),
+		EncryptedKey:   helpers.CopyStringPtr(o.EncryptedKey),
+		KeyPassphrase:  helpers.CopyStringPtr(o.KeyPassphrase),
+		PKCS11Provider: helpers.CopyStringPtr(o.PKCS11Provider),
+		PKCS11ID:       helpers.CopyStringPtr(o.PKCS11ID),
+		PKCS11PIN:      helpers.CopyStringPtr(o.PKCS11PIN),
+		PIAEncPreset:   helpers.CopyStringPtr(o.PIAEncPreset),
+		MSSFix:         helpers.CopyUint16Ptr(o.MSSFix),
+		Interface:      o.Interface,
+		ProcessUser:    o.ProcessUser,
+		Verbosity:      helpers.CopyIntPtr(o.Verbosity),
+		Flags:          helpers.CopyStringSlice(o.Flags),
+		Obfuscation:    o.Obfuscation.copy(),
 	}
 }
 
@@ -275,12 +367,16 @@ func (o *OpenVPN) mergeWith(other OpenVPN) {
 	o.Key = helpers.MergeWithStringPtr(o.Key, other.Key)
 	o.EncryptedKey = helpers.MergeWithStringPtr(o.EncryptedKey, other.EncryptedKey)
 	o.KeyPassphrase = helpers.MergeWithStringPtr(o.KeyPassphrase, other.KeyPassphrase)
+	o.PKCS11Provider = helpers.MergeWithStringPtr(o.PKCS11Provider, other.PKCS11Provider)
+	o.PKCS11ID = helpers.MergeWithStringPtr(o.PKCS11ID, other.PKCS11ID)
+	o.PKCS11PIN = helpers.MergeWithStringPtr(o.PKCS11PIN, other.PKCS11PIN)
 	o.PIAEncPreset = helpers.MergeWithStringPtr(o.PIAEncPreset, other.PIAEncPreset)
 	o.MSSFix = helpers.MergeWithUint16(o.MSSFix, other.MSSFix)
 	o.Interface = helpers.MergeWithString(o.Interface, other.Interface)
 	o.ProcessUser = helpers.MergeWithString(o.ProcessUser, other.ProcessUser)
 	o.Verbosity = helpers.MergeWithIntPtr(o.Verbosity, other.Verbosity)
 	o.Flags = helpers.MergeStringSlices(o.Flags, other.Flags)
+	o.Obfuscation.mergeWith(other.Obfuscation)
 }
 
 // overrideWith overrides fields of the receiver
@@ -297,12 +393,16 @@ func (o *OpenVPN) overrideWith(other OpenVPN) {
 	o.Key = helpers.OverrideWithStringPtr(o.Key, other.Key)
 	o.EncryptedKey = helpers.OverrideWithStringPtr(o.EncryptedKey, other.EncryptedKey)
 	o.KeyPassphrase = helpers.OverrideWithStringPtr(o.KeyPassphrase, other.KeyPassphrase)
+	o.PKCS11Provider = helpers.OverrideWithStringPtr(o.PKCS11Provider, other.PKCS11Provider)
+	o.PKCS11ID = helpers.OverrideWithStringPtr(o.PKCS11ID, other.PKCS11ID)
+	o.PKCS11PIN = helpers.OverrideWithStringPtr(o.PKCS11PIN, other.PKCS11PIN)
 	o.PIAEncPreset = helpers.OverrideWithStringPtr(o.PIAEncPreset, other.PIAEncPreset)
 	o.MSSFix = helpers.OverrideWithUint16(o.MSSFix, other.MSSFix)
 	o.Interface = helpers.OverrideWithString(o.Interface, other.Interface)
 	o.ProcessUser = helpers.OverrideWithString(o.ProcessUser, other.ProcessUser)
 	o.Verbosity = helpers.OverrideWithIntPtr(o.Verbosity, other.Verbosity)
 	o.Flags = helpers.OverrideWithStringSlice(o.Flags, other.Flags)
+	o.Obfuscation.overrideWith(other.Obfuscation)
 }
 
 func (o *OpenVPN) setDefaults(vpnProvider string) {
@@ -320,6 +420,9 @@ func (o *OpenVPN) setDefaults(vpnProvider string) {
 	o.Key = helpers.DefaultStringPtr(o.Key, "")
 	o.EncryptedKey = helpers.DefaultStringPtr(o.EncryptedKey, "")
 	o.KeyPassphrase = helpers.DefaultStringPtr(o.KeyPassphrase, "")
+	o.PKCS11Provider = helpers.DefaultStringPtr(o.PKCS11Provider, "")
+	o.PKCS11ID = helpers.DefaultStringPtr(o.PKCS11ID, "")
+	o.PKCS11PIN = helpers.DefaultStringPtr(o.PKCS11PIN, "")
 
 	var defaultEncPreset string
 	if vpnProvider == providers.PrivateInternetAccess {
@@ -330,6 +433,7 @@ func (o *OpenVPN) setDefaults(vpnProvider string) {
 	o.Interface = helpers.DefaultString(o.Interface, "tun0")
 	o.ProcessUser = helpers.DefaultString(o.ProcessUser, "root")
 	o.Verbosity = helpers.DefaultInt(o.Verbosity, 1)
+	o.Obfuscation.setDefaults()
 }
 
 func (o OpenVPN) String() string {
@@ -367,6 +471,12 @@ func (o OpenVPN) toLinesNode() (node *gotree.Node) {
 			helpers.ObfuscateData(*o.EncryptedKey), helpers.ObfuscatePassword(*o.KeyPassphrase))
 	}
 
+	if *o.PKCS11Provider != "" {
+		node.Appendf("PKCS#11 provider: %s (certificate ID %s, PIN %s)",
+			*o.PKCS11Provider, helpers.ObfuscateData(*o.PKCS11ID),
+			helpers.ObfuscatePassword(*o.PKCS11PIN))
+	}
+
 	if *o.PIAEncPreset != "" {
 		node.Appendf("Private Internet Access encryption preset: %s", *o.PIAEncPreset)
 	}
@@ -387,6 +497,8 @@ func (o OpenVPN) toLinesNode() (node *gotree.Node) {
 		node.Appendf("Flags: %s", o.Flags)
 	}
 
+	node.AppendNode(o.Obfuscation.toLinesNode())
+
 	return node
 }
 
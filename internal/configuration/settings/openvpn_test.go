@@ -1,9 +1,12 @@
 package settings
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_ivpnAccountID(t *testing.T) {
@@ -42,3 +45,81 @@ func Test_ivpnAccountID(t *testing.T) {
 		})
 	}
 }
+
+func Test_OpenVPN_validatePKCS11(t *testing.T) {
+	t.Parallel()
+
+	empty := ""
+	providerPath := filepath.Join(t.TempDir(), "pkcs11.so")
+	err := os.WriteFile(providerPath, nil, 0600)
+	require.NoError(t, err)
+	id := "id"
+	key := "a2V5"
+	quotedID := "pkcs11:id=x'"
+	multilineID := "id\nlog"
+	pin := "1234"
+	multilinePIN := "1234\nsignal"
+
+	testCases := map[string]struct {
+		settings   OpenVPN
+		errMessage string
+	}{
+		"provider not set": {
+			settings:   OpenVPN{PKCS11Provider: &empty, PKCS11ID: &id},
+			errMessage: "PKCS#11 provider library is not set",
+		},
+		"id not set": {
+			settings:   OpenVPN{PKCS11Provider: &providerPath, PKCS11ID: &empty},
+			errMessage: "PKCS#11 certificate ID is not set",
+		},
+		"with client key": {
+			settings: OpenVPN{
+				PKCS11Provider: &providerPath, PKCS11ID: &id,
+				Cert: &empty, Key: &key, EncryptedKey: &empty,
+			},
+			errMessage: "client certificate and key cannot be set with PKCS#11",
+		},
+		"id with single quote": {
+			settings: OpenVPN{
+				PKCS11Provider: &providerPath, PKCS11ID: &quotedID, PKCS11PIN: &empty,
+				Cert: &empty, Key: &empty, EncryptedKey: &empty,
+			},
+			errMessage: "PKCS#11 certificate ID is not valid: it cannot contain a single quote or a new line",
+		},
+		"id with new line": {
+			settings: OpenVPN{
+				PKCS11Provider: &providerPath, PKCS11ID: &multilineID, PKCS11PIN: &empty,
+				Cert: &empty, Key: &empty, EncryptedKey: &empty,
+			},
+			errMessage: "PKCS#11 certificate ID is not valid: it cannot contain a single quote or a new line",
+		},
+		"PIN with new line": {
+			settings: OpenVPN{
+				PKCS11Provider: &providerPath, PKCS11ID: &id, PKCS11PIN: &multilinePIN,
+				Cert: &empty, Key: &empty, EncryptedKey: &empty,
+			},
+			errMessage: "PKCS#11 PIN is not valid: it cannot contain a new line",
+		},
+		"valid": {
+			settings: OpenVPN{
+				PKCS11Provider: &providerPath, PKCS11ID: &id, PKCS11PIN: &pin,
+				Cert: &empty, Key: &empty, EncryptedKey: &empty,
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := testCase.settings.validatePKCS11()
+
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package settings
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/qdm12/gotree"
+)
+
+const obfuscationTLS = "tls"
+
+// OpenVPNObfuscation contains settings to tunnel the OpenVPN
+// TCP connection inside TLS, to an stunnel-like server running
+// on the VPN server, to defeat deep packet inspection.
+type OpenVPNObfuscation struct {
+	// Type is the obfuscation type, which can be "none" or
+	// "tls". It defaults to "none" and cannot be the empty
+	// string in the internal state.
+	Type string
+	// Port is the TLS port of the VPN server to connect to.
+	// It defaults to 443 and cannot be nil in the internal state.
+	Port *uint16
+	// ServerName is the server name to verify the TLS certificate
+	// of the VPN server with, and to send in the TLS handshake.
+	// It defaults to the empty string meaning the VPN server
+	// hostname is used, and cannot be nil in the internal state.
+	ServerName *string
+	// SkipVerify is true to not verify the TLS certificate of
+	// the VPN server, for servers using a self-signed certificate.
+	// It defaults to false and cannot be nil in the internal state.
+	SkipVerify *bool
+}
+
+// validate validates the obfuscation settings, knowing tcp is true
+// if the OpenVPN protocol selected is TCP. For the custom provider,
+// the protocol is the one of the configuration file and is only
+// checked when connecting.
+func (o OpenVPNObfuscation) validate(vpnProvider string, tcp bool) (err error) {
+	validTypes := []string{obfuscationNone, obfuscationTLS}
+	if !helpers.IsOneOf(o.Type, validTypes...) {
+		return fmt.Errorf("%w: %s must be one of %s", ErrOpenVPNObfuscationNotValid,
+			o.Type, helpers.ChoicesOrString(validTypes))
+	}
+
+	if !o.Enabled() {
+		return nil
+	}
+
+	if *o.Port == 0 {
+		return fmt.Errorf("%w", ErrOpenVPNObfuscationPortNotSet)
+	}
+
+	if !tcp && vpnProvider != providers.Custom {
+		return fmt.Errorf("%w: %s obfuscation with the UDP protocol",
+			ErrOpenVPNObfuscationProtocolNotTCP, o.Type)
+	}
+
+	return nil
+}
+
+// Enabled returns true if the OpenVPN connection is obfuscated.
+func (o OpenVPNObfuscation) Enabled() bool {
+	return o.Type != obfuscationNone
+}
+
+func (o *OpenVPNObfuscation) copy() (copied OpenVPNObfuscation) {
+	return OpenVPNObfuscation{
+		Type:       o.Type,
+		Port:       helpers.CopyUint16Ptr(o.Port),
+		ServerName: helpers.CopyStringPtr(o.ServerName),
+		SkipVerify: helpers.CopyBoolPtr(o.SkipVerify),
+	}
+}
+
+func (o *OpenVPNObfuscation) mergeWith(other OpenVPNObfuscation) {
+	o.Type = helpers.MergeWithString(o.Type, other.Type)
+	o.Port = helpers.MergeWithUint16(o.Port, other.Port)
+	o.ServerName = helpers.MergeWithStringPtr(o.ServerName, other.ServerName)
+	o.SkipVerify = helpers.MergeWithBool(o.SkipVerify, other.SkipVerify)
+}
+
+func (o *OpenVPNObfuscation) overrideWith(other OpenVPNObfuscation) {
+	o.Type = helpers.OverrideWithString(o.Type, other.Type)
+	o.Port = helpers.OverrideWithUint16(o.Port, other.Port)
+	o.ServerName = helpers.OverrideWithStringPtr(o.ServerName, other.ServerName)
+	o.SkipVerify = helpers.OverrideWithBool(o.SkipVerify, other.SkipVerify)
+}
+
+func (o *OpenVPNObfuscation) setDefaults() {
+	o.Type = helpers.DefaultString(o.Type, obfuscationNone)
+	const defaultPort = 443
+	o.Port = helpers.DefaultUint16(o.Port, defaultPort)
+	o.ServerName = helpers.DefaultStringPtr(o.ServerName, "")
+	o.SkipVerify = helpers.DefaultBool(o.SkipVerify, false)
+}
+
+func (o OpenVPNObfuscation) String() string {
+	return o.toLinesNode().String()
+}
+
+func (o OpenVPNObfuscation) toLinesNode() (node *gotree.Node) {
+	if !o.Enabled() {
+		return nil
+	}
+	node = gotree.New("Obfuscation settings:")
+	node.Appendf("Type: %s", o.Type)
+	node.Appendf("Port: %d", *o.Port)
+	if *o.ServerName != "" {
+		node.Appendf("Server name: %s", *o.ServerName)
+	}
+	if *o.SkipVerify {
+		node.Appendf("Skip certificate verification: yes")
+	}
+	return node
+}
@@ -0,0 +1,67 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_OpenVPNObfuscation_validate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		obfuscation OpenVPNObfuscation
+		vpnProvider string
+		tcp         bool
+		errWrapped  error
+		errMessage  string
+	}{
+		"invalid type": {
+			obfuscation: OpenVPNObfuscation{Type: "xor"},
+			errWrapped:  ErrOpenVPNObfuscationNotValid,
+			errMessage:  "obfuscation type is not valid: xor must be one of none or tls",
+		},
+		"disabled with UDP": {
+			obfuscation: OpenVPNObfuscation{Type: obfuscationNone},
+			vpnProvider: providers.Mullvad,
+		},
+		"TLS without port": {
+			obfuscation: OpenVPNObfuscation{Type: obfuscationTLS, Port: uint16Ptr(0)},
+			vpnProvider: providers.Mullvad,
+			tcp:         true,
+			errWrapped:  ErrOpenVPNObfuscationPortNotSet,
+			errMessage:  "obfuscation port is not set",
+		},
+		"TLS with UDP": {
+			obfuscation: OpenVPNObfuscation{Type: obfuscationTLS, Port: uint16Ptr(443)},
+			vpnProvider: providers.Mullvad,
+			errWrapped:  ErrOpenVPNObfuscationProtocolNotTCP,
+			errMessage: "obfuscation requires the OpenVPN TCP protocol: " +
+				"tls obfuscation with the UDP protocol",
+		},
+		"TLS with TCP": {
+			obfuscation: OpenVPNObfuscation{Type: obfuscationTLS, Port: uint16Ptr(443)},
+			vpnProvider: providers.Mullvad,
+			tcp:         true,
+		},
+		"TLS with custom provider": {
+			obfuscation: OpenVPNObfuscation{Type: obfuscationTLS, Port: uint16Ptr(443)},
+			vpnProvider: providers.Custom,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := testCase.obfuscation.validate(testCase.vpnProvider, testCase.tcp)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
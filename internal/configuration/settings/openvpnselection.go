@@ -55,7 +55,8 @@ func (o OpenVPNSelection) validate(vpnProvider string) (err error) {
 		switch vpnProvider {
 		// no restriction on port
 		case providers.Cyberghost, providers.HideMyAss,
-			providers.Privatevpn, providers.Torguard:
+			providers.Plugin, providers.Privatevpn,
+			providers.Torguard, providers.UserDefined:
 		// no custom port allowed
 		case providers.Expressvpn, providers.Fastestvpn,
 			providers.Ipvanish, providers.Nordvpn,
@@ -79,6 +80,9 @@ func (o OpenVPNSelection) validate(vpnProvider string) (err error) {
 			case providers.Mullvad:
 				allowedTCP = []uint16{80, 443, 1401}
 				allowedUDP = []uint16{53, 1194, 1195, 1196, 1197, 1300, 1301, 1302, 1303, 1400}
+			case providers.Ovpn:
+				allowedTCP = []uint16{443}
+				allowedUDP = []uint16{1194}
 			case providers.Perfectprivacy:
 				allowedTCP = []uint16{44, 443, 4433}
 				allowedUDP = []uint16{44, 443, 4433}
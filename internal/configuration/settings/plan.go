@@ -0,0 +1,182 @@
+package settings
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Change is a change of the value of a single settings field.
+type Change struct {
+	// Field is the dot separated path of the field,
+	// for example OpenVPN.User.
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// Plan describes the changes resulting from overriding
+// settings, so they can be reviewed before being applied.
+type Plan struct {
+	Changes []Change `json:"changes"`
+	// Loops are the names of the loops affected by the changes.
+	Loops []string `json:"loops"`
+	// RestartRequired is true if applying the changes
+	// restarts the VPN tunnel.
+	RestartRequired bool `json:"restart_required"`
+}
+
+// PlanOverride returns the VPN settings resulting from overriding
+// the receiver settings with the other settings, together with the
+// plan of changes between the receiver and the patched settings.
+// The receiver is left unchanged, and the patched settings should be
+// validated before being applied.
+func (v VPN) PlanOverride(other VPN) (patched VPN, plan Plan) {
+	patched = v.Copy()
+	patched.OverrideWith(other)
+	changes := diffFields("", reflect.ValueOf(v), reflect.ValueOf(patched))
+	return patched, makePlan(changes, vpnFieldLoops)
+}
+
+// PlanOverride returns the settings resulting from overriding the
+// receiver settings with the other settings, together with the plan
+// of changes between the receiver and the patched settings.
+// The receiver is left unchanged, and the patched settings should be
+// validated before being applied, as done by OverrideWith.
+func (s *Settings) PlanOverride(other Settings) (patched Settings, plan Plan) {
+	patched = s.copy()
+	patched.Bandwidth.overrideWith(other.Bandwidth)
+	patched.ControlServer.overrideWith(other.ControlServer)
+	patched.DNS.overrideWith(other.DNS)
+	patched.Docker.overrideWith(other.Docker)
+	patched.DockerLabels.overrideWith(other.DockerLabels)
+	patched.Failover.overrideWith(other.Failover)
+	patched.Firewall.overrideWith(other.Firewall)
+	patched.Health.OverrideWith(other.Health)
+	patched.Hooks.overrideWith(other.Hooks)
+	patched.HTTPProxy.overrideWith(other.HTTPProxy)
+	patched.Log.overrideWith(other.Log)
+	patched.Notification.overrideWith(other.Notification)
+	patched.PublicIP.overrideWith(other.PublicIP)
+	patched.Quota.overrideWith(other.Quota)
+	patched.Rotation.overrideWith(other.Rotation)
+	patched.RuntimeState.overrideWith(other.RuntimeState)
+	patched.DDNS.overrideWith(other.DDNS)
+	patched.Secrets.overrideWith(other.Secrets)
+	patched.ServerCooldown.overrideWith(other.ServerCooldown)
+	patched.Shadowsocks.overrideWith(other.Shadowsocks)
+	patched.SOCKS5.overrideWith(other.SOCKS5)
+	patched.System.overrideWith(other.System)
+	patched.Updater.overrideWith(other.Updater)
+	patched.Version.overrideWith(other.Version)
+	patched.VPN.OverrideWith(other.VPN)
+	patched.WireguardServer.overrideWith(other.WireguardServer)
+	patched.Pprof.OverrideWith(other.Pprof)
+	changes := diffFields("", reflect.ValueOf(*s), reflect.ValueOf(patched))
+	return patched, makePlan(changes, settingsFieldLoops)
+}
+
+// vpnFieldLoops returns the names of the loops affected by a change
+// of the VPN settings field given. Any VPN settings change restarts
+// the VPN loop, which in turn restarts port forwarding.
+func vpnFieldLoops(field string) (loops []string) {
+	loops = []string{"vpn"}
+	if strings.HasPrefix(field, "Provider.PortForwarding.") {
+		loops = append(loops, "port forwarding")
+	}
+	return loops
+}
+
+// settingsFieldLoops returns the names of the loops affected by a
+// change of the settings field given, named after its top level field.
+func settingsFieldLoops(field string) (loops []string) {
+	topField, subField, _ := strings.Cut(field, ".")
+	if topField == "VPN" {
+		return vpnFieldLoops(subField)
+	}
+	loop, ok := topFieldToLoop[topField]
+	if !ok {
+		loop = strings.ToLower(topField)
+	}
+	return []string{loop}
+}
+
+// topFieldToLoop maps the top level settings fields to the name
+// of the loop using them, if it differs from the lowercased field.
+var topFieldToLoop = map[string]string{ //nolint:gochecknoglobals
+	"ControlServer":   "control server",
+	"DDNS":            "dynamic dns",
+	"DockerLabels":    "docker labels",
+	"Health":          "healthcheck",
+	"HTTPProxy":       "http proxy",
+	"PublicIP":        "public ip",
+	"RuntimeState":    "runtime state",
+	"ServerCooldown":  "server cooldown",
+	"WireguardServer": "wireguard server",
+}
+
+// makePlan returns the plan of the changes given, with the loops
+// affected by each change obtained with the fieldLoops function given.
+// Only changes affecting the VPN loop restart the VPN tunnel.
+func makePlan(changes []Change, fieldLoops func(field string) (loops []string)) (plan Plan) {
+	plan.Changes = changes
+	plan.Loops = []string{}
+	seen := make(map[string]struct{})
+	for _, change := range changes {
+		for _, loop := range fieldLoops(change.Field) {
+			if _, ok := seen[loop]; ok {
+				continue
+			}
+			seen[loop] = struct{}{}
+			plan.Loops = append(plan.Loops, loop)
+			if loop == "vpn" {
+				plan.RestartRequired = true
+			}
+		}
+	}
+	return plan
+}
+
+// diffFields returns the changes between the before and after values,
+// recursing in the structs defined in this package. Other values
+// such as slices, maps and structs from other packages are compared
+// as a whole.
+func diffFields(path string, before, after reflect.Value) (changes []Change) {
+	settingsPkgPath := reflect.TypeOf(VPN{}).PkgPath()
+	if before.Kind() == reflect.Struct && before.Type().PkgPath() == settingsPkgPath {
+		for i := 0; i < before.NumField(); i++ {
+			field := before.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			changes = append(changes, diffFields(fieldPath, before.Field(i), after.Field(i))...)
+		}
+		return changes
+	}
+
+	if before.Kind() == reflect.Pointer && !before.IsNil() && !after.IsNil() &&
+		before.Elem().Kind() == reflect.Struct {
+		return diffFields(path, before.Elem(), after.Elem())
+	}
+
+	beforeValue, afterValue := fieldValue(before), fieldValue(after)
+	if reflect.DeepEqual(beforeValue, afterValue) {
+		return nil
+	}
+	return []Change{{Field: path, Old: beforeValue, New: afterValue}}
+}
+
+// fieldValue returns the value pointed to if the value is a
+// pointer, such that changes show values instead of addresses.
+func fieldValue(value reflect.Value) interface{} {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	return value.Interface()
+}
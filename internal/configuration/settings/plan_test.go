@@ -0,0 +1,107 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_VPN_PlanOverride(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		other VPN
+		plan  Plan
+	}{
+		"no change": {
+			other: VPN{Type: "openvpn"},
+			plan: Plan{
+				Loops: []string{},
+			},
+		},
+		"openvpn user changed": {
+			other: VPN{OpenVPN: OpenVPN{User: stringPtr("new")}},
+			plan: Plan{
+				Changes: []Change{
+					{Field: "OpenVPN.User", Old: "old", New: "new"},
+				},
+				Loops:           []string{"vpn"},
+				RestartRequired: true,
+			},
+		},
+		"port forwarding enabled": {
+			other: VPN{
+				Type: "wireguard",
+				Provider: Provider{
+					PortForwarding: PortForwarding{Enabled: boolPtr(true)},
+				},
+			},
+			plan: Plan{
+				Changes: []Change{
+					{Field: "Type", Old: "openvpn", New: "wireguard"},
+					{Field: "Provider.PortForwarding.Enabled", Old: false, New: true},
+				},
+				Loops:           []string{"vpn", "port forwarding"},
+				RestartRequired: true,
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			current := VPN{
+				Type: "openvpn",
+				Provider: Provider{
+					PortForwarding: PortForwarding{Enabled: boolPtr(false)},
+				},
+				OpenVPN: OpenVPN{User: stringPtr("old")},
+			}
+
+			patched, plan := current.PlanOverride(testCase.other)
+
+			assert.Equal(t, testCase.plan, plan)
+			assert.Equal(t, "old", *current.OpenVPN.User)
+			expected := current.Copy()
+			expected.OverrideWith(testCase.other)
+			assert.Equal(t, expected, patched)
+		})
+	}
+}
+
+func Test_Settings_PlanOverride(t *testing.T) {
+	t.Parallel()
+
+	var current Settings
+	current.SetDefaults()
+	other := Settings{
+		DNS:       DNS{KeepNameserver: boolPtr(true)},
+		HTTPProxy: HTTPProxy{Stealth: boolPtr(true)},
+		VPN: VPN{
+			Provider: Provider{
+				PortForwarding: PortForwarding{Enabled: boolPtr(true)},
+			},
+		},
+	}
+
+	patched, plan := current.PlanOverride(other)
+
+	expectedPlan := Plan{
+		Changes: []Change{
+			{Field: "DNS.KeepNameserver", Old: false, New: true},
+			{Field: "HTTPProxy.Stealth", Old: false, New: true},
+			{Field: "VPN.Provider.PortForwarding.Enabled", Old: false, New: true},
+		},
+		Loops:           []string{"dns", "http proxy", "vpn", "port forwarding"},
+		RestartRequired: true,
+	}
+	assert.Equal(t, expectedPlan, plan)
+	assert.False(t, *current.DNS.KeepNameserver)
+	assert.True(t, *patched.DNS.KeepNameserver)
+
+	_, plan = current.PlanOverride(Settings{HTTPProxy: HTTPProxy{Stealth: boolPtr(true)}})
+	assert.Equal(t, []string{"http proxy"}, plan.Loops)
+	assert.False(t, plan.RestartRequired)
+}
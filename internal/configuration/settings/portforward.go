@@ -2,10 +2,16 @@ package settings
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gluetun/internal/constants"
 	"github.com/qdm12/gluetun/internal/constants/providers"
 	"github.com/qdm12/gotree"
 )
@@ -15,11 +21,58 @@ type PortForwarding struct {
 	// Enabled is true if port forwarding should be activated.
 	// It cannot be nil for the internal state.
 	Enabled *bool
+	// Provider is the port forwarding method to use. It can be
+	// natpmp to use NAT-PMP on the VPN gateway with any VPN
+	// provider, or the empty string to use the port forwarding
+	// of the VPN provider. It cannot be nil for the internal state.
+	Provider *string
 	// Filepath is the port forwarding status file path
 	// to use. It can be the empty string to indicate not
 	// to write to a file. It cannot be nil for the
 	// internal state
 	Filepath *string
+	// CheckURL is the URL of a service checking the forwarded
+	// port is reachable from the internet, where {{PORT}} is
+	// replaced by the forwarded port. The port is considered
+	// reachable if the service responds with a 2xx status code.
+	// It can be the empty string to disable the check, and
+	// cannot be nil for the internal state.
+	CheckURL *string
+	// CheckPeriod is the period between reachability checks
+	// of the forwarded port. It cannot be nil for the internal state.
+	CheckPeriod *time.Duration
+	// APIKey is the API key of the VPN provider account,
+	// used to forward a port through the provider API for
+	// AirVPN and OVPN. For Windscribe, it is the session
+	// authentication hash of the account. It can be the empty
+	// string for other providers, and cannot be nil for the
+	// internal state.
+	APIKey *string
+	// WebhookURL is the URL to post to each time the ports
+	// forwarded change. It can be the empty string to disable
+	// the webhook, and cannot be nil for the internal state.
+	WebhookURL *string
+	// WebhookTemplate is the Go text template of the webhook
+	// request body, executed with the fields Port, the first
+	// port forwarded, and Ports, all the ports forwarded.
+	// It can be the empty string to post the ports as JSON,
+	// and cannot be nil for the internal state.
+	WebhookTemplate *string
+	// Target is the address to DNAT the forwarded ports to, such as
+	// a container on a bridge network shared with gluetun, as "ip:port"
+	// or as "ip" to keep the forwarded port numbers. It can be the empty
+	// string to not DNAT the forwarded ports, and cannot be nil for the
+	// internal state.
+	Target *string
+	// QBittorrent contains settings to set the qBittorrent
+	// listening port to the port forwarded.
+	QBittorrent TorrentClient
+	// Transmission contains settings to set the Transmission
+	// peer port to the port forwarded.
+	Transmission TorrentClient
+	// Deluge contains settings to set the Deluge listening
+	// port to the port forwarded. Only its password is used.
+	Deluge TorrentClient
 }
 
 func (p PortForwarding) validate(vpnProvider string) (err error) {
@@ -27,13 +80,35 @@ func (p PortForwarding) validate(vpnProvider string) (err error) {
 		return nil
 	}
 
+	// Validate Provider
+	if !helpers.IsOneOf(*p.Provider, "", constants.PortForwardingNATPMP) {
+		return fmt.Errorf("%w: %s can only be %s or empty",
+			ErrPortForwardingProviderNotValid, *p.Provider, constants.PortForwardingNATPMP)
+	}
+
 	// Validate Enabled
-	validProviders := []string{providers.PrivateInternetAccess}
+	if *p.Provider == constants.PortForwardingNATPMP {
+		return p.validateCommon()
+	}
+	validProviders := []string{providers.Airvpn, providers.Ovpn, providers.Plugin,
+		providers.PrivateInternetAccess, providers.Windscribe}
 	if !helpers.IsOneOf(vpnProvider, validProviders...) {
 		return fmt.Errorf("%w: for provider %s, it is only available for %s",
 			ErrPortForwardingEnabled, vpnProvider, strings.Join(validProviders, ", "))
 	}
 
+	// Validate APIKey
+	apiKeyProviders := []string{providers.Airvpn, providers.Ovpn, providers.Windscribe}
+	if helpers.IsOneOf(vpnProvider, apiKeyProviders...) && *p.APIKey == "" {
+		return fmt.Errorf("%w: for provider %s", ErrPortForwardingAPIKeyNotSet, vpnProvider)
+	}
+
+	return p.validateCommon()
+}
+
+// validateCommon validates settings common
+// to all port forwarding methods.
+func (p PortForwarding) validateCommon() (err error) {
 	// Validate Filepath
 	if *p.Filepath != "" { // optional
 		_, err := filepath.Abs(*p.Filepath)
@@ -42,29 +117,163 @@ func (p PortForwarding) validate(vpnProvider string) (err error) {
 		}
 	}
 
+	// Validate CheckURL
+	if *p.CheckURL != "" {
+		checkURL := strings.ReplaceAll(*p.CheckURL, "{{PORT}}", "1")
+		parsedURL, err := url.Parse(checkURL)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrPortForwardingCheckURLNotValid, err)
+		} else if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+			return fmt.Errorf("%w: scheme must be http or https: %s",
+				ErrPortForwardingCheckURLNotValid, *p.CheckURL)
+		}
+
+		// Validate CheckPeriod
+		const minPeriod = time.Minute
+		if *p.CheckPeriod < minPeriod {
+			return fmt.Errorf("%w: %s must be at least %s",
+				ErrPortForwardCheckPeriodTooShort, *p.CheckPeriod, minPeriod)
+		}
+	}
+
+	// Validate WebhookURL
+	if *p.WebhookURL != "" {
+		_, err = url.ParseRequestURI(*p.WebhookURL)
+		if err != nil {
+			return fmt.Errorf("webhook URL is not valid: %w", err)
+		}
+	}
+
+	// Validate WebhookTemplate
+	_, err = template.New("webhook").Parse(*p.WebhookTemplate)
+	if err != nil {
+		return fmt.Errorf("webhook template is not valid: %w", err)
+	}
+
+	// Validate Target
+	_, _, err = parsePortForwardingTarget(*p.Target)
+	if err != nil {
+		return err
+	}
+
+	err = p.QBittorrent.validate()
+	if err != nil {
+		return fmt.Errorf("qBittorrent: %w", err)
+	}
+
+	err = p.Transmission.validate()
+	if err != nil {
+		return fmt.Errorf("Transmission: %w", err)
+	}
+
+	err = p.Deluge.validate()
+	if err != nil {
+		return fmt.Errorf("Deluge: %w", err)
+	}
+
 	return nil
 }
 
+// TargetAddress returns the IP address and port of the target
+// to DNAT the forwarded ports to. The IP address is nil if no target
+// is set, and the port is 0 to keep the forwarded port numbers.
+// The settings must be validated before calling this method.
+func (p PortForwarding) TargetAddress() (ip net.IP, port uint16) {
+	ip, port, _ = parsePortForwardingTarget(*p.Target)
+	return ip, port
+}
+
+// parsePortForwardingTarget parses the target as an IP
+// address, optionally with a non zero port.
+func parsePortForwardingTarget(target string) (ip net.IP, port uint16, err error) {
+	if target == "" {
+		return nil, 0, nil
+	}
+
+	host, portString, err := net.SplitHostPort(target)
+	if err != nil { // no port
+		host, portString = target, ""
+	}
+
+	ip = net.ParseIP(host)
+	if ip == nil {
+		return nil, 0, fmt.Errorf("%w: %s: IP address %s is not valid",
+			ErrPortForwardingTargetNotValid, target, host)
+	}
+
+	if portString == "" {
+		return ip, 0, nil
+	}
+	const base, bitSize = 10, 16
+	portUint64, err := strconv.ParseUint(portString, base, bitSize)
+	if err != nil || portUint64 == 0 {
+		return nil, 0, fmt.Errorf("%w: %s: port %s is not valid",
+			ErrPortForwardingTargetNotValid, target, portString)
+	}
+	return ip, uint16(portUint64), nil
+}
+
 func (p *PortForwarding) copy() (copied PortForwarding) {
 	return PortForwarding{
-		Enabled:  helpers.CopyBoolPtr(p.Enabled),
-		Filepath: helpers.CopyStringPtr(p.Filepath),
+		Enabled:         helpers.CopyBoolPtr(p.Enabled),
+		Provider:        helpers.CopyStringPtr(p.Provider),
+		Filepath:        helpers.CopyStringPtr(p.Filepath),
+		CheckURL:        helpers.CopyStringPtr(p.CheckURL),
+		CheckPeriod:     helpers.CopyDurationPtr(p.CheckPeriod),
+		APIKey:          helpers.CopyStringPtr(p.APIKey),
+		WebhookURL:      helpers.CopyStringPtr(p.WebhookURL),
+		WebhookTemplate: helpers.CopyStringPtr(p.WebhookTemplate),
+		Target:          helpers.CopyStringPtr(p.Target),
+		QBittorrent:     p.QBittorrent.copy(),
+		Transmission:    p.Transmission.copy(),
+		Deluge:          p.Deluge.copy(),
 	}
 }
 
 func (p *PortForwarding) mergeWith(other PortForwarding) {
 	p.Enabled = helpers.MergeWithBool(p.Enabled, other.Enabled)
+	p.Provider = helpers.MergeWithStringPtr(p.Provider, other.Provider)
 	p.Filepath = helpers.MergeWithStringPtr(p.Filepath, other.Filepath)
+	p.CheckURL = helpers.MergeWithStringPtr(p.CheckURL, other.CheckURL)
+	p.CheckPeriod = helpers.MergeWithDurationPtr(p.CheckPeriod, other.CheckPeriod)
+	p.APIKey = helpers.MergeWithStringPtr(p.APIKey, other.APIKey)
+	p.WebhookURL = helpers.MergeWithStringPtr(p.WebhookURL, other.WebhookURL)
+	p.WebhookTemplate = helpers.MergeWithStringPtr(p.WebhookTemplate, other.WebhookTemplate)
+	p.Target = helpers.MergeWithStringPtr(p.Target, other.Target)
+	p.QBittorrent.mergeWith(other.QBittorrent)
+	p.Transmission.mergeWith(other.Transmission)
+	p.Deluge.mergeWith(other.Deluge)
 }
 
 func (p *PortForwarding) overrideWith(other PortForwarding) {
 	p.Enabled = helpers.OverrideWithBool(p.Enabled, other.Enabled)
+	p.Provider = helpers.OverrideWithStringPtr(p.Provider, other.Provider)
 	p.Filepath = helpers.OverrideWithStringPtr(p.Filepath, other.Filepath)
+	p.CheckURL = helpers.OverrideWithStringPtr(p.CheckURL, other.CheckURL)
+	p.CheckPeriod = helpers.OverrideWithDurationPtr(p.CheckPeriod, other.CheckPeriod)
+	p.APIKey = helpers.OverrideWithStringPtr(p.APIKey, other.APIKey)
+	p.WebhookURL = helpers.OverrideWithStringPtr(p.WebhookURL, other.WebhookURL)
+	p.WebhookTemplate = helpers.OverrideWithStringPtr(p.WebhookTemplate, other.WebhookTemplate)
+	p.Target = helpers.OverrideWithStringPtr(p.Target, other.Target)
+	p.QBittorrent.overrideWith(other.QBittorrent)
+	p.Transmission.overrideWith(other.Transmission)
+	p.Deluge.overrideWith(other.Deluge)
 }
 
 func (p *PortForwarding) setDefaults() {
 	p.Enabled = helpers.DefaultBool(p.Enabled, false)
+	p.Provider = helpers.DefaultStringPtr(p.Provider, "")
 	p.Filepath = helpers.DefaultStringPtr(p.Filepath, "/tmp/gluetun/forwarded_port")
+	p.CheckURL = helpers.DefaultStringPtr(p.CheckURL, "")
+	const defaultCheckPeriod = 10 * time.Minute
+	p.CheckPeriod = helpers.DefaultDurationPtr(p.CheckPeriod, defaultCheckPeriod)
+	p.APIKey = helpers.DefaultStringPtr(p.APIKey, "")
+	p.WebhookURL = helpers.DefaultStringPtr(p.WebhookURL, "")
+	p.WebhookTemplate = helpers.DefaultStringPtr(p.WebhookTemplate, "")
+	p.Target = helpers.DefaultStringPtr(p.Target, "")
+	p.QBittorrent.setDefaults()
+	p.Transmission.setDefaults()
+	p.Deluge.setDefaults()
 }
 
 func (p PortForwarding) String() string {
@@ -79,11 +288,41 @@ func (p PortForwarding) toLinesNode() (node *gotree.Node) {
 	node = gotree.New("Automatic port forwarding settings:")
 	node.Appendf("Enabled: yes")
 
+	if *p.Provider != "" {
+		node.Appendf("Method: %s", *p.Provider)
+	}
+
 	filepath := *p.Filepath
 	if filepath == "" {
 		filepath = "[not set]"
 	}
 	node.Appendf("Forwarded port file path: %s", filepath)
 
+	if *p.CheckURL != "" {
+		checkNode := node.Appendf("Reachability check:")
+		checkNode.Appendf("URL: %s", *p.CheckURL)
+		checkNode.Appendf("Period: %s", *p.CheckPeriod)
+	}
+
+	if *p.APIKey != "" {
+		node.Appendf("API key: %s", helpers.ObfuscatePassword(*p.APIKey))
+	}
+
+	if *p.WebhookURL != "" {
+		webhookNode := node.Appendf("Webhook:")
+		webhookNode.Appendf("URL: %s", *p.WebhookURL)
+		if *p.WebhookTemplate != "" {
+			webhookNode.Appendf("Template: %s", *p.WebhookTemplate)
+		}
+	}
+
+	if *p.Target != "" {
+		node.Appendf("Target: %s", *p.Target)
+	}
+
+	node.AppendNode(p.QBittorrent.toLinesNode("qBittorrent"))
+	node.AppendNode(p.Transmission.toLinesNode("Transmission"))
+	node.AppendNode(p.Deluge.toLinesNode("Deluge"))
+
 	return node
 }
@@ -1,6 +1,7 @@
 package settings
 
 import (
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,3 +18,61 @@ func Test_PortForwarding_String(t *testing.T) {
 
 	assert.Empty(t, s)
 }
+
+func Test_parsePortForwardingTarget(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		target     string
+		ip         net.IP
+		port       uint16
+		errWrapped error
+		errMessage string
+	}{
+		"empty target": {},
+		"ipv4 with port": {
+			target: "172.18.0.5:8080",
+			ip:     net.IPv4(172, 18, 0, 5),
+			port:   8080,
+		},
+		"ipv4 without port": {
+			target: "172.18.0.5",
+			ip:     net.IPv4(172, 18, 0, 5),
+		},
+		"ipv6 with port": {
+			target: "[fd00::5]:8080",
+			ip:     net.ParseIP("fd00::5"),
+			port:   8080,
+		},
+		"ipv6 without port": {
+			target: "fd00::5",
+			ip:     net.ParseIP("fd00::5"),
+		},
+		"hostname": {
+			target:     "qbittorrent:8080",
+			errWrapped: ErrPortForwardingTargetNotValid,
+			errMessage: "port forwarding target is not valid: qbittorrent:8080: IP address qbittorrent is not valid",
+		},
+		"zero port": {
+			target:     "172.18.0.5:0",
+			errWrapped: ErrPortForwardingTargetNotValid,
+			errMessage: "port forwarding target is not valid: 172.18.0.5:0: port 0 is not valid",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ip, port, err := parsePortForwardingTarget(testCase.target)
+
+			assert.True(t, testCase.ip.Equal(ip))
+			assert.Equal(t, testCase.port, port)
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
@@ -19,6 +19,15 @@ type Provider struct {
 	ServerSelection ServerSelection
 	// PortForwarding is the settings about port forwarding.
 	PortForwarding PortForwarding
+	// DefinitionFile is the path to the JSON file defining
+	// the servers of the user defined provider. It defaults
+	// to /gluetun/provider.json and cannot be nil in the
+	// internal state.
+	DefinitionFile *string
+	// PluginFile is the path to the executable implementing
+	// the plugin provider. It defaults to /gluetun/provider-plugin
+	// and cannot be nil in the internal state.
+	PluginFile *string
 }
 
 // TODO v4 remove pointer for receiver (because of Surfshark).
@@ -27,14 +36,19 @@ func (p *Provider) validate(vpnType string, storage Storage) (err error) {
 	var validNames []string
 	if vpnType == vpn.OpenVPN {
 		validNames = providers.AllWithCustom()
+		validNames = append(validNames, providers.UserDefined, providers.Plugin)
 		validNames = append(validNames, "pia") // Retro-compatibility
 	} else { // Wireguard
 		validNames = []string{
 			providers.Airvpn,
+			providers.CloudflareWARP,
 			providers.Custom,
 			providers.Ivpn,
 			providers.Mullvad,
+			providers.Ovpn,
+			providers.Plugin,
 			providers.Surfshark,
+			providers.UserDefined,
 			providers.Windscribe,
 		}
 	}
@@ -43,6 +57,20 @@ func (p *Provider) validate(vpnType string, storage Storage) (err error) {
 			ErrVPNProviderNameNotValid, *p.Name, helpers.ChoicesOrString(validNames))
 	}
 
+	if *p.Name == providers.UserDefined {
+		err = helpers.FileExists(*p.DefinitionFile)
+		if err != nil {
+			return fmt.Errorf("provider definition file: %w", err)
+		}
+	}
+
+	if *p.Name == providers.Plugin {
+		err = helpers.FileExists(*p.PluginFile)
+		if err != nil {
+			return fmt.Errorf("provider plugin file: %w", err)
+		}
+	}
+
 	err = p.ServerSelection.validate(*p.Name, storage)
 	if err != nil {
 		return fmt.Errorf("server selection: %w", err)
@@ -61,6 +89,8 @@ func (p *Provider) copy() (copied Provider) {
 		Name:            helpers.CopyStringPtr(p.Name),
 		ServerSelection: p.ServerSelection.copy(),
 		PortForwarding:  p.PortForwarding.copy(),
+		DefinitionFile:  helpers.CopyStringPtr(p.DefinitionFile),
+		PluginFile:      helpers.CopyStringPtr(p.PluginFile),
 	}
 }
 
@@ -68,18 +98,24 @@ func (p *Provider) mergeWith(other Provider) {
 	p.Name = helpers.MergeWithStringPtr(p.Name, other.Name)
 	p.ServerSelection.mergeWith(other.ServerSelection)
 	p.PortForwarding.mergeWith(other.PortForwarding)
+	p.DefinitionFile = helpers.MergeWithStringPtr(p.DefinitionFile, other.DefinitionFile)
+	p.PluginFile = helpers.MergeWithStringPtr(p.PluginFile, other.PluginFile)
 }
 
 func (p *Provider) overrideWith(other Provider) {
 	p.Name = helpers.OverrideWithStringPtr(p.Name, other.Name)
 	p.ServerSelection.overrideWith(other.ServerSelection)
 	p.PortForwarding.overrideWith(other.PortForwarding)
+	p.DefinitionFile = helpers.OverrideWithStringPtr(p.DefinitionFile, other.DefinitionFile)
+	p.PluginFile = helpers.OverrideWithStringPtr(p.PluginFile, other.PluginFile)
 }
 
 func (p *Provider) setDefaults() {
 	p.Name = helpers.DefaultStringPtr(p.Name, providers.PrivateInternetAccess)
 	p.ServerSelection.setDefaults(*p.Name)
 	p.PortForwarding.setDefaults()
+	p.DefinitionFile = helpers.DefaultStringPtr(p.DefinitionFile, "/gluetun/provider.json")
+	p.PluginFile = helpers.DefaultStringPtr(p.PluginFile, "/gluetun/provider-plugin")
 }
 
 func (p Provider) String() string {
@@ -89,6 +125,12 @@ func (p Provider) String() string {
 func (p Provider) toLinesNode() (node *gotree.Node) {
 	node = gotree.New("VPN provider settings:")
 	node.Appendf("Name: %s", *p.Name)
+	if *p.Name == providers.UserDefined {
+		node.Appendf("Definition file: %s", *p.DefinitionFile)
+	}
+	if *p.Name == providers.Plugin {
+		node.Appendf("Plugin file: %s", *p.PluginFile)
+	}
 	node.AppendNode(p.ServerSelection.toLinesNode())
 	node.AppendNode(p.PortForwarding.toLinesNode())
 	return node
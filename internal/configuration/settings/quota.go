@@ -0,0 +1,144 @@
+package settings
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gotree"
+)
+
+// Quota contains settings to track the VPN tunnel traffic
+// against a traffic quota for each period.
+type Quota struct {
+	// Limit is the traffic quota in bytes for each period,
+	// counting both received and sent bytes.
+	// It can be set to 0 to disable quota tracking.
+	// It cannot be nil in the internal state.
+	Limit *uint64
+	// Period is the quota period which can be
+	// 'monthly' or 'weekly'. It defaults to 'monthly'
+	// and cannot be the empty string in the internal state.
+	Period string
+	// Action is the action to take when the quota is exceeded,
+	// which can be 'log', 'notify', 'throttle' or 'block'.
+	// It defaults to 'log' and cannot be the empty string
+	// in the internal state.
+	Action string
+	// NotifyURL is the webhook URL receiving a JSON POST
+	// request when the quota is exceeded for the 'notify'
+	// action. It cannot be nil in the internal state.
+	NotifyURL *string
+	// ThrottleRate is the tc rate to limit the traffic sent and
+	// received through the VPN interface to for the 'throttle' action,
+	// for example '1mbit'. It defaults to '1mbit' and cannot
+	// be nil in the internal state.
+	ThrottleRate *string
+	// AllowedPorts are the destination ports still reachable
+	// through the VPN for the 'block' action. It defaults to
+	// 53 and 853 so DNS keeps working.
+	AllowedPorts []uint16
+}
+
+var throttleRateRegex = regexp.MustCompile(`^[0-9]+(bit|kbit|mbit|gbit|bps|kbps|mbps|gbps)$`)
+
+func (q Quota) validate() (err error) {
+	if *q.Limit == 0 {
+		return nil
+	}
+
+	switch q.Period {
+	case constants.QuotaMonthly, constants.QuotaWeekly:
+	default:
+		return fmt.Errorf("%w: %s", ErrQuotaPeriodNotValid, q.Period)
+	}
+
+	switch q.Action {
+	case constants.QuotaActionLog, constants.QuotaActionBlock:
+	case constants.QuotaActionNotify:
+		_, err = url.ParseRequestURI(*q.NotifyURL)
+		if err != nil {
+			return fmt.Errorf("notify URL is not valid: %w", err)
+		}
+	case constants.QuotaActionThrottle:
+		if !throttleRateRegex.MatchString(*q.ThrottleRate) {
+			return fmt.Errorf("%w: %s", ErrQuotaThrottleRateNotValid, *q.ThrottleRate)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrQuotaActionNotValid, q.Action)
+	}
+
+	return nil
+}
+
+func (q *Quota) copy() (copied Quota) {
+	return Quota{
+		Limit:        helpers.CopyUint64Ptr(q.Limit),
+		Period:       q.Period,
+		Action:       q.Action,
+		NotifyURL:    helpers.CopyStringPtr(q.NotifyURL),
+		ThrottleRate: helpers.CopyStringPtr(q.ThrottleRate),
+		AllowedPorts: helpers.CopyUint16Slice(q.AllowedPorts),
+	}
+}
+
+// mergeWith merges the other settings into any
+// unset field of the receiver settings object.
+func (q *Quota) mergeWith(other Quota) {
+	q.Limit = helpers.MergeWithUint64(q.Limit, other.Limit)
+	q.Period = helpers.MergeWithString(q.Period, other.Period)
+	q.Action = helpers.MergeWithString(q.Action, other.Action)
+	q.NotifyURL = helpers.MergeWithStringPtr(q.NotifyURL, other.NotifyURL)
+	q.ThrottleRate = helpers.MergeWithStringPtr(q.ThrottleRate, other.ThrottleRate)
+	q.AllowedPorts = helpers.MergeUint16Slices(q.AllowedPorts, other.AllowedPorts)
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (q *Quota) overrideWith(other Quota) {
+	q.Limit = helpers.OverrideWithUint64(q.Limit, other.Limit)
+	q.Period = helpers.OverrideWithString(q.Period, other.Period)
+	q.Action = helpers.OverrideWithString(q.Action, other.Action)
+	q.NotifyURL = helpers.OverrideWithStringPtr(q.NotifyURL, other.NotifyURL)
+	q.ThrottleRate = helpers.OverrideWithStringPtr(q.ThrottleRate, other.ThrottleRate)
+	q.AllowedPorts = helpers.OverrideWithUint16Slice(q.AllowedPorts, other.AllowedPorts)
+}
+
+func (q *Quota) setDefaults() {
+	q.Limit = helpers.DefaultUint64(q.Limit, 0)
+	q.Period = helpers.DefaultString(q.Period, constants.QuotaMonthly)
+	q.Action = helpers.DefaultString(q.Action, constants.QuotaActionLog)
+	q.NotifyURL = helpers.DefaultStringPtr(q.NotifyURL, "")
+	q.ThrottleRate = helpers.DefaultStringPtr(q.ThrottleRate, "1mbit")
+	if q.AllowedPorts == nil {
+		const dnsPort, dotPort = 53, 853
+		q.AllowedPorts = []uint16{dnsPort, dotPort}
+	}
+}
+
+func (q Quota) String() string {
+	return q.toLinesNode().String()
+}
+
+func (q Quota) toLinesNode() (node *gotree.Node) {
+	if *q.Limit == 0 {
+		return nil
+	}
+
+	node = gotree.New("Traffic quota settings:")
+	node.Appendf("Limit: %d bytes %s", *q.Limit, q.Period)
+	node.Appendf("Action: %s", q.Action)
+	switch q.Action {
+	case constants.QuotaActionNotify:
+		node.Appendf("Notify URL: %s", *q.NotifyURL)
+	case constants.QuotaActionThrottle:
+		node.Appendf("Throttle rate: %s", *q.ThrottleRate)
+	case constants.QuotaActionBlock:
+		node.Appendf("Allowed ports: %v", q.AllowedPorts)
+	}
+
+	return node
+}
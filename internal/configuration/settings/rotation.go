@@ -0,0 +1,83 @@
+package settings
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gluetun/internal/scheduler"
+	"github.com/qdm12/gotree"
+)
+
+// Rotation contains settings to periodically reconnect
+// to a different VPN server matching the server selection.
+type Rotation struct {
+	// Period is the period between each server rotation,
+	// and 0 disables the periodic rotation. It cannot be
+	// nil in the internal state.
+	Period *time.Duration
+	// Cron is a cron expression defining the times to rotate
+	// the server at, and the empty string disables it. It cannot
+	// be set together with Period, and it cannot be nil in the
+	// internal state.
+	Cron *string
+}
+
+func (r Rotation) validate() (err error) {
+	if *r.Period != 0 && *r.Cron != "" {
+		return fmt.Errorf("%w", ErrRotationPeriodAndCron)
+	}
+
+	const minPeriod = 5 * time.Minute
+	if *r.Period != 0 && *r.Period < minPeriod {
+		return fmt.Errorf("%w: %s must be at least %s",
+			ErrRotationPeriodTooSmall, *r.Period, minPeriod)
+	}
+
+	if *r.Cron != "" {
+		err = scheduler.ValidateCron(*r.Cron)
+		if err != nil {
+			return fmt.Errorf("cron expression: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Rotation) copy() (copied Rotation) {
+	return Rotation{
+		Period: helpers.CopyDurationPtr(r.Period),
+		Cron:   helpers.CopyStringPtr(r.Cron),
+	}
+}
+
+func (r *Rotation) mergeWith(other Rotation) {
+	r.Period = helpers.MergeWithDurationPtr(r.Period, other.Period)
+	r.Cron = helpers.MergeWithStringPtr(r.Cron, other.Cron)
+}
+
+func (r *Rotation) overrideWith(other Rotation) {
+	r.Period = helpers.OverrideWithDurationPtr(r.Period, other.Period)
+	r.Cron = helpers.OverrideWithStringPtr(r.Cron, other.Cron)
+}
+
+func (r *Rotation) setDefaults() {
+	r.Period = helpers.DefaultDurationPtr(r.Period, 0)
+	r.Cron = helpers.DefaultStringPtr(r.Cron, "")
+}
+
+func (r Rotation) String() string {
+	return r.toLinesNode().String()
+}
+
+func (r Rotation) toLinesNode() (node *gotree.Node) {
+	switch {
+	case *r.Period != 0:
+		node = gotree.New("Server rotation settings:")
+		node.Appendf("Period: %s", *r.Period)
+	case *r.Cron != "":
+		node = gotree.New("Server rotation settings:")
+		node.Appendf("Cron expression: %s", *r.Cron)
+	}
+	return node
+}
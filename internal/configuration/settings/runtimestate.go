@@ -0,0 +1,92 @@
+package settings
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// RuntimeState contains settings to persist the runtime state,
+// such as the VPN server connected to and the last public IP
+// address, and restore it on the next start.
+type RuntimeState struct {
+	// Enabled is true if the runtime state should be
+	// persisted and restored.
+	// It cannot be nil in the internal state.
+	Enabled *bool
+	// Filepath is the file path to persist the runtime state to.
+	// It cannot be the empty string in the internal state.
+	Filepath string
+	// MaxAge is the maximum age of a persisted runtime state for it
+	// to be restored, since older servers and tokens are likely
+	// invalid. It cannot be nil or zero in the internal state.
+	MaxAge *time.Duration
+}
+
+func (r RuntimeState) validate() (err error) {
+	if !*r.Enabled {
+		return nil
+	}
+
+	_, err = filepath.Abs(r.Filepath)
+	if err != nil {
+		return fmt.Errorf("filepath is not valid: %w", err)
+	}
+
+	if *r.MaxAge <= 0 {
+		return fmt.Errorf("%w: %s must be positive",
+			ErrRuntimeStateMaxAgeNotValid, *r.MaxAge)
+	}
+
+	return nil
+}
+
+func (r *RuntimeState) copy() (copied RuntimeState) {
+	return RuntimeState{
+		Enabled:  helpers.CopyBoolPtr(r.Enabled),
+		Filepath: r.Filepath,
+		MaxAge:   helpers.CopyDurationPtr(r.MaxAge),
+	}
+}
+
+// mergeWith merges the other settings into any
+// unset field of the receiver settings object.
+func (r *RuntimeState) mergeWith(other RuntimeState) {
+	r.Enabled = helpers.MergeWithBool(r.Enabled, other.Enabled)
+	r.Filepath = helpers.MergeWithString(r.Filepath, other.Filepath)
+	r.MaxAge = helpers.MergeWithDurationPtr(r.MaxAge, other.MaxAge)
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (r *RuntimeState) overrideWith(other RuntimeState) {
+	r.Enabled = helpers.OverrideWithBool(r.Enabled, other.Enabled)
+	r.Filepath = helpers.OverrideWithString(r.Filepath, other.Filepath)
+	r.MaxAge = helpers.OverrideWithDurationPtr(r.MaxAge, other.MaxAge)
+}
+
+func (r *RuntimeState) setDefaults() {
+	r.Enabled = helpers.DefaultBool(r.Enabled, false)
+	r.Filepath = helpers.DefaultString(r.Filepath, "/gluetun/state.json")
+	const defaultMaxAge = 24 * time.Hour
+	r.MaxAge = helpers.DefaultDurationPtr(r.MaxAge, defaultMaxAge)
+}
+
+func (r RuntimeState) String() string {
+	return r.toLinesNode().String()
+}
+
+func (r RuntimeState) toLinesNode() (node *gotree.Node) {
+	if !*r.Enabled {
+		return nil
+	}
+
+	node = gotree.New("Runtime state settings:")
+	node.Appendf("File path: %s", r.Filepath)
+	node.Appendf("Maximum age to restore: %s", *r.MaxAge)
+	return node
+}
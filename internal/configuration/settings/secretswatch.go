@@ -0,0 +1,65 @@
+package settings
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// SecretsWatch contains settings to periodically re-read the
+// secret files, such as /run/secrets/openvpn_password, and apply
+// rotated VPN credentials to the running VPN.
+type SecretsWatch struct {
+	// Period is the period between each read of the secret files,
+	// and 0 disables watching them.
+	// It cannot be nil in the internal state.
+	Period *time.Duration
+}
+
+func (s SecretsWatch) validate() (err error) {
+	const minPeriod = time.Second
+	if *s.Period != 0 && *s.Period < minPeriod {
+		return fmt.Errorf("%w: %s must be at least %s",
+			ErrSecretsWatchPeriodTooSmall, *s.Period, minPeriod)
+	}
+	return nil
+}
+
+func (s *SecretsWatch) copy() (copied SecretsWatch) {
+	return SecretsWatch{
+		Period: helpers.CopyDurationPtr(s.Period),
+	}
+}
+
+// mergeWith merges the other settings into any
+// unset field of the receiver settings object.
+func (s *SecretsWatch) mergeWith(other SecretsWatch) {
+	s.Period = helpers.MergeWithDurationPtr(s.Period, other.Period)
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (s *SecretsWatch) overrideWith(other SecretsWatch) {
+	s.Period = helpers.OverrideWithDurationPtr(s.Period, other.Period)
+}
+
+func (s *SecretsWatch) setDefaults() {
+	s.Period = helpers.DefaultDurationPtr(s.Period, 0)
+}
+
+func (s SecretsWatch) String() string {
+	return s.toLinesNode().String()
+}
+
+func (s SecretsWatch) toLinesNode() (node *gotree.Node) {
+	if *s.Period == 0 {
+		return nil
+	}
+
+	node = gotree.New("Secret files watch settings:")
+	node.Appendf("Period: %s", *s.Period)
+	return node
+}
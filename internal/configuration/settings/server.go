@@ -1,10 +1,13 @@
 package settings
 
 import (
+	"encoding/base32"
 	"fmt"
 	"net"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
 	"github.com/qdm12/gotree"
@@ -18,6 +21,43 @@ type ControlServer struct {
 	// Log can be true or false to enable logging on requests.
 	// It cannot be nil in the internal state.
 	Log *bool
+	// RollbackWindow is the duration to monitor the VPN health
+	// after VPN settings are changed through the control server.
+	// If the VPN was healthy before the change and is not healthy
+	// at the end of the window, the previous VPN settings are
+	// restored. It is set to 0 to disable rollbacks.
+	// It cannot be nil in the internal state.
+	RollbackWindow *time.Duration
+	// TOTPSecret is the base32 encoded shared secret used to
+	// require a TOTP code for the mutating control server routes.
+	// It is set to the empty string to not require TOTP codes.
+	// It cannot be nil in the internal state.
+	TOTPSecret *string
+	// APIKeys are the API keys accepted by the control server,
+	// in the Authorization Bearer or X-API-Key header.
+	// If no API key is set, no authentication is required.
+	APIKeys []ControlServerAPIKey
+	// HealthExempt can be true to not require an API key for
+	// GET /health and GET /ready, for health checks unaware of the API keys.
+	// It cannot be nil in the internal state.
+	HealthExempt *bool
+	// TLS can be true to serve the control server over HTTPS.
+	// It cannot be nil in the internal state.
+	TLS *bool
+	// TLSCertificateFile and TLSKeyFile are the paths to the PEM
+	// encoded TLS certificate and private key files. If both are
+	// empty and TLS is enabled, a self-signed certificate is
+	// generated at startup and stored in /gluetun.
+	// They cannot be nil in the internal state.
+	TLSCertificateFile *string
+	TLSKeyFile         *string
+	// TLSHostnames are the hostnames and IP addresses the
+	// generated self-signed certificate is valid for.
+	TLSHostnames []string
+	// DoH can be true to serve DNS over HTTPS queries on the
+	// /dns-query path, without authentication. It requires TLS
+	// to be enabled, and cannot be nil in the internal state.
+	DoH *bool
 }
 
 func (c ControlServer) validate() (err error) {
@@ -38,13 +78,65 @@ func (c ControlServer) validate() (err error) {
 			ErrControlServerPrivilegedPort, port, uid)
 	}
 
+	if *c.RollbackWindow < 0 {
+		return fmt.Errorf("%w: %s", ErrRollbackWindowNegative, *c.RollbackWindow)
+	}
+
+	if *c.TOTPSecret != "" {
+		_, err = DecodeTOTPSecret(*c.TOTPSecret)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrTOTPSecretNotValid, err)
+		}
+	}
+
+	for i, apiKey := range c.APIKeys {
+		err = apiKey.validate()
+		if err != nil {
+			return fmt.Errorf("API key %d of %d: %w", i+1, len(c.APIKeys), err)
+		}
+	}
+
+	if (*c.TLSCertificateFile == "") != (*c.TLSKeyFile == "") {
+		return fmt.Errorf("%w", ErrTLSKeyPairPartial)
+	}
+
+	if *c.DoH && !*c.TLS {
+		return fmt.Errorf("%w", ErrControlServerDoHNeedsTLS)
+	}
+
 	return nil
 }
 
+// DecodeTOTPSecret decodes a base32 encoded TOTP secret,
+// ignoring spaces, padding and letter case.
+func DecodeTOTPSecret(secret string) (key []byte, err error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+	key, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return nil, err
+	}
+	const minKeyLength = 10 // 80 bits as recommended by RFC 4226
+	if len(key) < minKeyLength {
+		return nil, fmt.Errorf("%w: %d bytes must be at least %d bytes",
+			ErrTOTPSecretTooShort, len(key), minKeyLength)
+	}
+	return key, nil
+}
+
 func (c *ControlServer) copy() (copied ControlServer) {
 	return ControlServer{
-		Address: helpers.CopyStringPtr(c.Address),
-		Log:     helpers.CopyBoolPtr(c.Log),
+		Address:            helpers.CopyStringPtr(c.Address),
+		Log:                helpers.CopyBoolPtr(c.Log),
+		RollbackWindow:     helpers.CopyDurationPtr(c.RollbackWindow),
+		TOTPSecret:         helpers.CopyStringPtr(c.TOTPSecret),
+		APIKeys:            copyControlServerAPIKeys(c.APIKeys),
+		HealthExempt:       helpers.CopyBoolPtr(c.HealthExempt),
+		TLS:                helpers.CopyBoolPtr(c.TLS),
+		TLSCertificateFile: helpers.CopyStringPtr(c.TLSCertificateFile),
+		TLSKeyFile:         helpers.CopyStringPtr(c.TLSKeyFile),
+		TLSHostnames:       helpers.CopyStringSlice(c.TLSHostnames),
+		DoH:                helpers.CopyBoolPtr(c.DoH),
 	}
 }
 
@@ -53,6 +145,17 @@ func (c *ControlServer) copy() (copied ControlServer) {
 func (c *ControlServer) mergeWith(other ControlServer) {
 	c.Address = helpers.MergeWithStringPtr(c.Address, other.Address)
 	c.Log = helpers.MergeWithBool(c.Log, other.Log)
+	c.RollbackWindow = helpers.MergeWithDurationPtr(c.RollbackWindow, other.RollbackWindow)
+	c.TOTPSecret = helpers.MergeWithStringPtr(c.TOTPSecret, other.TOTPSecret)
+	if c.APIKeys == nil {
+		c.APIKeys = copyControlServerAPIKeys(other.APIKeys)
+	}
+	c.HealthExempt = helpers.MergeWithBool(c.HealthExempt, other.HealthExempt)
+	c.TLS = helpers.MergeWithBool(c.TLS, other.TLS)
+	c.TLSCertificateFile = helpers.MergeWithStringPtr(c.TLSCertificateFile, other.TLSCertificateFile)
+	c.TLSKeyFile = helpers.MergeWithStringPtr(c.TLSKeyFile, other.TLSKeyFile)
+	c.TLSHostnames = helpers.MergeStringSlices(c.TLSHostnames, other.TLSHostnames)
+	c.DoH = helpers.MergeWithBool(c.DoH, other.DoH)
 }
 
 // overrideWith overrides fields of the receiver
@@ -61,11 +164,32 @@ func (c *ControlServer) mergeWith(other ControlServer) {
 func (c *ControlServer) overrideWith(other ControlServer) {
 	c.Address = helpers.OverrideWithStringPtr(c.Address, other.Address)
 	c.Log = helpers.OverrideWithBool(c.Log, other.Log)
+	c.RollbackWindow = helpers.OverrideWithDurationPtr(c.RollbackWindow, other.RollbackWindow)
+	c.TOTPSecret = helpers.OverrideWithStringPtr(c.TOTPSecret, other.TOTPSecret)
+	if other.APIKeys != nil {
+		c.APIKeys = copyControlServerAPIKeys(other.APIKeys)
+	}
+	c.HealthExempt = helpers.OverrideWithBool(c.HealthExempt, other.HealthExempt)
+	c.TLS = helpers.OverrideWithBool(c.TLS, other.TLS)
+	c.TLSCertificateFile = helpers.OverrideWithStringPtr(c.TLSCertificateFile, other.TLSCertificateFile)
+	c.TLSKeyFile = helpers.OverrideWithStringPtr(c.TLSKeyFile, other.TLSKeyFile)
+	c.TLSHostnames = helpers.OverrideWithStringSlice(c.TLSHostnames, other.TLSHostnames)
+	c.DoH = helpers.OverrideWithBool(c.DoH, other.DoH)
 }
 
 func (c *ControlServer) setDefaults() {
 	c.Address = helpers.DefaultStringPtr(c.Address, ":8000")
 	c.Log = helpers.DefaultBool(c.Log, true)
+	c.RollbackWindow = helpers.DefaultDurationPtr(c.RollbackWindow, 0)
+	c.TOTPSecret = helpers.DefaultStringPtr(c.TOTPSecret, "")
+	c.HealthExempt = helpers.DefaultBool(c.HealthExempt, true)
+	c.TLS = helpers.DefaultBool(c.TLS, false)
+	c.TLSCertificateFile = helpers.DefaultStringPtr(c.TLSCertificateFile, "")
+	c.TLSKeyFile = helpers.DefaultStringPtr(c.TLSKeyFile, "")
+	if c.TLSHostnames == nil {
+		c.TLSHostnames = []string{"gluetun", "localhost", "127.0.0.1"}
+	}
+	c.DoH = helpers.DefaultBool(c.DoH, false)
 }
 
 func (c ControlServer) String() string {
@@ -76,5 +200,30 @@ func (c ControlServer) toLinesNode() (node *gotree.Node) {
 	node = gotree.New("Control server settings:")
 	node.Appendf("Listening address: %s", *c.Address)
 	node.Appendf("Logging: %s", helpers.BoolPtrToYesNo(c.Log))
+	if *c.RollbackWindow > 0 {
+		node.Appendf("VPN settings rollback window: %s", *c.RollbackWindow)
+	}
+	if *c.TOTPSecret != "" {
+		node.Appendf("TOTP required for mutating routes: yes")
+	}
+	if len(c.APIKeys) > 0 {
+		apiKeysNode := node.Appendf("API keys:")
+		for _, apiKey := range c.APIKeys {
+			apiKeysNode.AppendNode(apiKey.toLinesNode())
+		}
+		node.Appendf("GET /health and /ready exempt from API keys: %s", helpers.BoolPtrToYesNo(c.HealthExempt))
+	}
+	if *c.TLS {
+		tlsNode := node.Appendf("TLS: yes")
+		if *c.TLSCertificateFile == "" {
+			tlsNode.Appendf("Self-signed certificate hostnames: %v", c.TLSHostnames)
+		} else {
+			tlsNode.Appendf("Certificate file: %s", *c.TLSCertificateFile)
+			tlsNode.Appendf("Key file: %s", *c.TLSKeyFile)
+		}
+		if *c.DoH {
+			node.Appendf("DNS over HTTPS path: /dns-query")
+		}
+	}
 	return node
 }
@@ -0,0 +1,55 @@
+package settings
+
+import (
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// ServerCooldown contains settings to skip VPN servers which
+// failed when selecting the server to reconnect to.
+type ServerCooldown struct {
+	// Period is the duration a server is skipped for after
+	// it failed to connect or the VPN was restarted because
+	// it was unhealthy. It is reset once the server connects
+	// successfully, and 0 disables skipping failed servers.
+	// It cannot be nil in the internal state.
+	Period *time.Duration
+}
+
+func (s ServerCooldown) validate() (err error) {
+	return nil
+}
+
+func (s *ServerCooldown) copy() (copied ServerCooldown) {
+	return ServerCooldown{
+		Period: helpers.CopyDurationPtr(s.Period),
+	}
+}
+
+func (s *ServerCooldown) mergeWith(other ServerCooldown) {
+	s.Period = helpers.MergeWithDurationPtr(s.Period, other.Period)
+}
+
+func (s *ServerCooldown) overrideWith(other ServerCooldown) {
+	s.Period = helpers.OverrideWithDurationPtr(s.Period, other.Period)
+}
+
+func (s *ServerCooldown) setDefaults() {
+	const defaultPeriod = 5 * time.Minute
+	s.Period = helpers.DefaultDurationPtr(s.Period, defaultPeriod)
+}
+
+func (s ServerCooldown) String() string {
+	return s.toLinesNode().String()
+}
+
+func (s ServerCooldown) toLinesNode() (node *gotree.Node) {
+	if *s.Period == 0 {
+		return nil
+	}
+	node = gotree.New("Failed server cooldown settings:")
+	node.Appendf("Period: %s", *s.Period)
+	return node
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
@@ -11,6 +12,7 @@ import (
 	"github.com/qdm12/gluetun/internal/constants/providers"
 	"github.com/qdm12/gluetun/internal/constants/vpn"
 	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/openvpn/extract"
 	"github.com/qdm12/gotree"
 )
 
@@ -81,17 +83,20 @@ func (ss *ServerSelection) validate(vpnServiceProvider string,
 		return fmt.Errorf("%w: %s", ErrVPNTypeNotValid, ss.VPN)
 	}
 
-	filterChoices, err := getLocationFilterChoices(vpnServiceProvider, ss, storage)
-	if err != nil {
-		return err // already wrapped error
-	}
+	// The plugin provider validates the server filters itself.
+	if vpnServiceProvider != providers.Plugin {
+		filterChoices, err := getLocationFilterChoices(vpnServiceProvider, ss, storage)
+		if err != nil {
+			return err // already wrapped error
+		}
 
-	err = validateServerFilters(*ss, filterChoices)
-	if err != nil {
-		if errors.Is(err, helpers.ErrNoChoice) {
-			return fmt.Errorf("for VPN service provider %s: %w", vpnServiceProvider, err)
+		err = validateServerFilters(*ss, filterChoices)
+		if err != nil {
+			if errors.Is(err, helpers.ErrNoChoice) {
+				return fmt.Errorf("for VPN service provider %s: %w", vpnServiceProvider, err)
+			}
+			return err // already wrapped error
 		}
-		return err // already wrapped error
 	}
 
 	if *ss.OwnedOnly &&
@@ -156,6 +161,21 @@ func getLocationFilterChoices(vpnServiceProvider string,
 	err error) {
 	filterChoices = storage.GetFilterChoices(vpnServiceProvider)
 
+	if vpnServiceProvider == providers.Custom && ss.VPN == vpn.OpenVPN &&
+		extract.IsDirectory(*ss.OpenVPN.ConfFile) {
+		// Each configuration file of the directory is a server
+		// named after its file name without extension.
+		nameToPath, err := extract.ConfigFiles(*ss.OpenVPN.ConfFile)
+		if err != nil {
+			return models.FilterChoices{}, fmt.Errorf("custom configuration directory: %w", err)
+		}
+		filterChoices.Names = make([]string, 0, len(nameToPath))
+		for name := range nameToPath {
+			filterChoices.Names = append(filterChoices.Names, name)
+		}
+		sort.Strings(filterChoices.Names)
+	}
+
 	if vpnServiceProvider == providers.Surfshark {
 		// // Retro compatibility
 		// TODO v4 remove
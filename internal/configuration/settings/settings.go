@@ -13,19 +13,33 @@ import (
 )
 
 type Settings struct {
-	ControlServer ControlServer
-	DNS           DNS
-	Firewall      Firewall
-	Health        Health
-	HTTPProxy     HTTPProxy
-	Log           Log
-	PublicIP      PublicIP
-	Shadowsocks   Shadowsocks
-	System        System
-	Updater       Updater
-	Version       Version
-	VPN           VPN
-	Pprof         pprof.Settings
+	Bandwidth       Bandwidth
+	ControlServer   ControlServer
+	DDNS            DDNS
+	DNS             DNS
+	Docker          DockerDependents
+	DockerLabels    DockerLabels
+	Failover        Failover
+	Firewall        Firewall
+	Health          Health
+	Hooks           Hooks
+	HTTPProxy       HTTPProxy
+	Log             Log
+	Notification    Notification
+	PublicIP        PublicIP
+	Quota           Quota
+	Rotation        Rotation
+	RuntimeState    RuntimeState
+	Secrets         SecretsWatch
+	ServerCooldown  ServerCooldown
+	Shadowsocks     Shadowsocks
+	SOCKS5          SOCKS5
+	System          System
+	Updater         Updater
+	Version         Version
+	VPN             VPN
+	WireguardServer WireguardServer
+	Pprof           pprof.Settings
 }
 
 type Storage interface {
@@ -37,21 +51,60 @@ type Storage interface {
 // TODO v4 remove pointer for receiver (because of Surfshark).
 func (s *Settings) Validate(storage Storage, ipv6Supported bool) (err error) {
 	nameToValidation := map[string]func() error{
-		"control server":  s.ControlServer.validate,
-		"dns":             s.DNS.validate,
-		"firewall":        s.Firewall.validate,
-		"health":          s.Health.Validate,
-		"http proxy":      s.HTTPProxy.validate,
-		"log":             s.Log.validate,
-		"public ip check": s.PublicIP.validate,
-		"shadowsocks":     s.Shadowsocks.validate,
-		"system":          s.System.validate,
-		"updater":         s.Updater.Validate,
-		"version":         s.Version.validate,
+		"bandwidth":        s.Bandwidth.validate,
+		"control server":   s.ControlServer.validate,
+		"dynamic dns":      s.DDNS.validate,
+		"dns":              s.DNS.validate,
+		"docker":           s.Docker.validate,
+		"docker labels":    s.DockerLabels.validate,
+		"health":           s.Health.Validate,
+		"hooks":            s.Hooks.validate,
+		"http proxy":       s.HTTPProxy.validate,
+		"log":              s.Log.validate,
+		"notification":     s.Notification.validate,
+		"public ip check":  s.PublicIP.validate,
+		"quota":            s.Quota.validate,
+		"runtime state":    s.RuntimeState.validate,
+		"server rotation":  s.Rotation.validate,
+		"secrets watch":    s.Secrets.validate,
+		"server cooldown":  s.ServerCooldown.validate,
+		"shadowsocks":      s.Shadowsocks.validate,
+		"socks5 proxy":     s.SOCKS5.validate,
+		"system":           s.System.validate,
+		"updater":          s.Updater.Validate,
+		"version":          s.Version.validate,
+		"wireguard server": s.WireguardServer.validate,
 		// Pprof validation done in pprof constructor
 		"VPN": func() error {
 			return s.VPN.Validate(storage, ipv6Supported)
 		},
+		"firewall": func() error {
+			return s.Firewall.validate(s.VPN)
+		},
+		"health ICMP targets": func() error {
+			return s.Health.validateICMPTargets(s.VPN.Type)
+		},
+		"VPN tunnels bypass mark": func() error {
+			return s.VPN.validateTunnelsBypassMark(*s.Firewall.BypassMark)
+		},
+		"firewall bypass domains DNS": func() error {
+			return s.Firewall.validateBypassDomainsDNS(s.DNS)
+		},
+		"firewall gateway DNS": func() error {
+			return s.Firewall.validateGatewayDNS(s.DNS)
+		},
+		"Shadowsocks client": func() error {
+			if s.VPN.Type != vpn.Shadowsocks {
+				return nil
+			}
+			return s.VPN.Shadowsocks.validateTCPOnly(s.DNS, s.Firewall)
+		},
+		"wireguard server DNS": func() error {
+			return s.WireguardServer.validateDNS(s.DNS)
+		},
+		"failover": func() error {
+			return s.Failover.validate(s.VPN, storage, ipv6Supported)
+		},
 	}
 
 	for name, validation := range nameToValidation {
@@ -66,54 +119,69 @@ func (s *Settings) Validate(storage Storage, ipv6Supported bool) (err error) {
 
 func (s *Settings) copy() (copied Settings) {
 	return Settings{
-		ControlServer: s.ControlServer.copy(),
-		DNS:           s.DNS.Copy(),
-		Firewall:      s.Firewall.copy(),
-		Health:        s.Health.copy(),
-		HTTPProxy:     s.HTTPProxy.copy(),
-		Log:           s.Log.copy(),
-		PublicIP:      s.PublicIP.copy(),
-		Shadowsocks:   s.Shadowsocks.copy(),
-		System:        s.System.copy(),
-		Updater:       s.Updater.copy(),
-		Version:       s.Version.copy(),
-		VPN:           s.VPN.Copy(),
-		Pprof:         s.Pprof.Copy(),
+		Bandwidth:       s.Bandwidth.copy(),
+		ControlServer:   s.ControlServer.copy(),
+		DDNS:            s.DDNS.copy(),
+		DNS:             s.DNS.Copy(),
+		Docker:          s.Docker.copy(),
+		DockerLabels:    s.DockerLabels.copy(),
+		Failover:        s.Failover.copy(),
+		Firewall:        s.Firewall.copy(),
+		Health:          s.Health.copy(),
+		Hooks:           s.Hooks.copy(),
+		HTTPProxy:       s.HTTPProxy.copy(),
+		Log:             s.Log.copy(),
+		Notification:    s.Notification.copy(),
+		PublicIP:        s.PublicIP.copy(),
+		Quota:           s.Quota.copy(),
+		Rotation:        s.Rotation.copy(),
+		RuntimeState:    s.RuntimeState.copy(),
+		Secrets:         s.Secrets.copy(),
+		ServerCooldown:  s.ServerCooldown.copy(),
+		Shadowsocks:     s.Shadowsocks.copy(),
+		SOCKS5:          s.SOCKS5.copy(),
+		System:          s.System.copy(),
+		Updater:         s.Updater.copy(),
+		Version:         s.Version.copy(),
+		VPN:             s.VPN.Copy(),
+		WireguardServer: s.WireguardServer.copy(),
+		Pprof:           s.Pprof.Copy(),
 	}
 }
 
 func (s *Settings) MergeWith(other Settings) {
+	s.Bandwidth.mergeWith(other.Bandwidth)
 	s.ControlServer.mergeWith(other.ControlServer)
+	s.DDNS.mergeWith(other.DDNS)
 	s.DNS.mergeWith(other.DNS)
+	s.Docker.mergeWith(other.Docker)
+	s.DockerLabels.mergeWith(other.DockerLabels)
+	s.Failover.mergeWith(other.Failover)
 	s.Firewall.mergeWith(other.Firewall)
 	s.Health.MergeWith(other.Health)
+	s.Hooks.mergeWith(other.Hooks)
 	s.HTTPProxy.mergeWith(other.HTTPProxy)
 	s.Log.mergeWith(other.Log)
+	s.Notification.mergeWith(other.Notification)
 	s.PublicIP.mergeWith(other.PublicIP)
+	s.Quota.mergeWith(other.Quota)
+	s.Rotation.mergeWith(other.Rotation)
+	s.RuntimeState.mergeWith(other.RuntimeState)
+	s.Secrets.mergeWith(other.Secrets)
+	s.ServerCooldown.mergeWith(other.ServerCooldown)
 	s.Shadowsocks.mergeWith(other.Shadowsocks)
+	s.SOCKS5.mergeWith(other.SOCKS5)
 	s.System.mergeWith(other.System)
 	s.Updater.mergeWith(other.Updater)
 	s.Version.mergeWith(other.Version)
 	s.VPN.mergeWith(other.VPN)
+	s.WireguardServer.mergeWith(other.WireguardServer)
 	s.Pprof.MergeWith(other.Pprof)
 }
 
 func (s *Settings) OverrideWith(other Settings,
 	storage Storage, ipv6Supported bool) (err error) {
-	patchedSettings := s.copy()
-	patchedSettings.ControlServer.overrideWith(other.ControlServer)
-	patchedSettings.DNS.overrideWith(other.DNS)
-	patchedSettings.Firewall.overrideWith(other.Firewall)
-	patchedSettings.Health.OverrideWith(other.Health)
-	patchedSettings.HTTPProxy.overrideWith(other.HTTPProxy)
-	patchedSettings.Log.overrideWith(other.Log)
-	patchedSettings.PublicIP.overrideWith(other.PublicIP)
-	patchedSettings.Shadowsocks.overrideWith(other.Shadowsocks)
-	patchedSettings.System.overrideWith(other.System)
-	patchedSettings.Updater.overrideWith(other.Updater)
-	patchedSettings.Version.overrideWith(other.Version)
-	patchedSettings.VPN.OverrideWith(other.VPN)
-	patchedSettings.Pprof.OverrideWith(other.Pprof)
+	patchedSettings, _ := s.PlanOverride(other)
 	err = patchedSettings.Validate(storage, ipv6Supported)
 	if err != nil {
 		return err
@@ -123,17 +191,31 @@ func (s *Settings) OverrideWith(other Settings,
 }
 
 func (s *Settings) SetDefaults() {
+	s.Bandwidth.setDefaults()
 	s.ControlServer.setDefaults()
 	s.DNS.setDefaults()
+	s.Docker.setDefaults()
+	s.DockerLabels.setDefaults()
+	s.Failover.setDefaults()
 	s.Firewall.setDefaults()
 	s.Health.SetDefaults()
+	s.Hooks.setDefaults()
 	s.HTTPProxy.setDefaults()
 	s.Log.setDefaults()
+	s.Notification.setDefaults()
 	s.PublicIP.setDefaults()
+	s.Quota.setDefaults()
+	s.Rotation.setDefaults()
+	s.RuntimeState.setDefaults()
+	s.DDNS.setDefaults()
+	s.Secrets.setDefaults()
+	s.ServerCooldown.setDefaults()
 	s.Shadowsocks.setDefaults()
+	s.SOCKS5.setDefaults()
 	s.System.setDefaults()
 	s.Version.setDefaults()
 	s.VPN.setDefaults()
+	s.WireguardServer.setDefaults()
 	s.Updater.SetDefaults(*s.VPN.Provider.Name)
 	s.Pprof.SetDefaults()
 }
@@ -146,17 +228,31 @@ func (s Settings) toLinesNode() (node *gotree.Node) {
 	node = gotree.New("Settings summary:")
 
 	node.AppendNode(s.VPN.toLinesNode())
+	node.AppendNode(s.Failover.toLinesNode(s.VPN))
+	node.AppendNode(s.Rotation.toLinesNode())
+	node.AppendNode(s.ServerCooldown.toLinesNode())
 	node.AppendNode(s.DNS.toLinesNode())
 	node.AppendNode(s.Firewall.toLinesNode())
 	node.AppendNode(s.Log.toLinesNode())
 	node.AppendNode(s.Health.toLinesNode())
 	node.AppendNode(s.Shadowsocks.toLinesNode())
 	node.AppendNode(s.HTTPProxy.toLinesNode())
+	node.AppendNode(s.SOCKS5.toLinesNode())
+	node.AppendNode(s.WireguardServer.toLinesNode())
 	node.AppendNode(s.ControlServer.toLinesNode())
 	node.AppendNode(s.System.toLinesNode())
+	node.AppendNode(s.Hooks.toLinesNode())
+	node.AppendNode(s.Docker.toLinesNode())
+	node.AppendNode(s.DockerLabels.toLinesNode())
+	node.AppendNode(s.Secrets.toLinesNode())
 	node.AppendNode(s.PublicIP.toLinesNode())
+	node.AppendNode(s.RuntimeState.toLinesNode())
+	node.AppendNode(s.DDNS.toLinesNode())
+	node.AppendNode(s.Quota.toLinesNode())
+	node.AppendNode(s.Bandwidth.toLinesNode())
 	node.AppendNode(s.Updater.toLinesNode())
 	node.AppendNode(s.Version.toLinesNode())
+	node.AppendNode(s.Notification.toLinesNode())
 	node.AppendNode(s.Pprof.ToLinesNode())
 
 	return node
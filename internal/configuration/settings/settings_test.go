@@ -37,28 +37,39 @@ func Test_Settings_String(t *testing.T) {
 |       ├── Network interface: tun0
 |       ├── Run OpenVPN as: root
 |       └── Verbosity level: 1
+├── Failed server cooldown settings:
+|   └── Period: 5m0s
 ├── DNS settings:
 |   ├── DNS server address to use: 127.0.0.1
 |   ├── Keep existing nameserver(s): no
-|   └── DNS over TLS settings:
-|       ├── Enabled: yes
-|       ├── Update period: every 24h0m0s
-|       ├── Unbound settings:
-|       |   ├── Authoritative servers:
-|       |   |   └── Cloudflare
-|       |   ├── Caching: yes
-|       |   ├── IPv6: no
-|       |   ├── Verbosity level: 1
-|       |   ├── Verbosity details level: 0
-|       |   ├── Validation log level: 0
-|       |   ├── System user: root
-|       |   └── Allowed networks:
-|       |       ├── 0.0.0.0/0
-|       |       └── ::/0
-|       └── DNS filtering settings:
-|           ├── Block malicious: yes
-|           ├── Block ads: no
-|           └── Block surveillance: yes
+|   ├── Filter AAAA records without IPv6 support: yes
+|   ├── DNS over TLS settings:
+|   |   ├── Enabled: yes
+|   |   ├── Update period: every 24h0m0s
+|   |   ├── Unbound settings:
+|   |   |   ├── Authoritative servers:
+|   |   |   |   └── Cloudflare
+|   |   |   ├── Caching: yes
+|   |   |   ├── Cache:
+|   |   |   |   ├── Size: 8388608 bytes
+|   |   |   |   ├── TTL: from 1h0m0s to 2h30m0s
+|   |   |   |   ├── Negative cache size: 1048576 bytes
+|   |   |   |   └── Negative answers maximum TTL: 1h0m0s
+|   |   |   ├── IPv6: no
+|   |   |   ├── Verbosity level: 1
+|   |   |   ├── Verbosity details level: 0
+|   |   |   ├── Validation log level: 0
+|   |   |   ├── System user: root
+|   |   |   └── Allowed networks:
+|   |   |       ├── 0.0.0.0/0
+|   |   |       └── ::/0
+|   |   └── DNS filtering settings:
+|   |       ├── Block malicious: yes
+|   |       ├── Block ads: no
+|   |       └── Block surveillance: yes
+|   └── Serve DNS to LAN clients: yes
+|       └── Allowed subnets:
+|           └── all
 ├── Firewall settings:
 |   └── Enabled: yes
 ├── Log settings:
@@ -68,6 +79,7 @@ func Test_Settings_String(t *testing.T) {
 |   ├── Target address: cloudflare.com:443
 |   ├── Read header timeout: 100ms
 |   ├── Read timeout: 500ms
+|   ├── Readiness conditions: tunnel
 |   └── VPN wait durations:
 |       ├── Initial duration: 6s
 |       └── Additional duration: 5s
@@ -75,6 +87,8 @@ func Test_Settings_String(t *testing.T) {
 |   └── Enabled: no
 ├── HTTP proxy settings:
 |   └── Enabled: no
+├── SOCKS5 proxy settings:
+|   └── Enabled: no
 ├── Control server settings:
 |   ├── Listening address: :8000
 |   └── Logging: yes
@@ -84,6 +98,9 @@ func Test_Settings_String(t *testing.T) {
 ├── Public IP settings:
 |   ├── Fetching: every 12h0m0s
 |   └── IP file path: /tmp/gluetun/ip
+├── Bandwidth sampling settings:
+|   ├── Resolution: 1s
+|   └── Window: 5m0s
 └── Version settings:
     └── Enabled: yes`,
 		},
@@ -0,0 +1,113 @@
+package settings
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+	"github.com/qdm12/ss-server/pkg/validation"
+)
+
+// ShadowsocksClient contains settings to relay the container
+// TCP traffic through a remote Shadowsocks server, when the VPN
+// type is 'shadowsocks'. UDP traffic is not relayed and is
+// blocked by the firewall.
+type ShadowsocksClient struct {
+	// Server is the IP address and port of the remote
+	// Shadowsocks server, in the form ip:port.
+	// It cannot be the empty string if the VPN type
+	// is 'shadowsocks'.
+	Server string
+	// Password is the password of the remote server.
+	// It cannot be nil in the internal state.
+	Password *string
+	// CipherName is the AEAD cipher used by the remote
+	// server, and defaults to chacha20-ietf-poly1305.
+	CipherName string
+}
+
+func (s ShadowsocksClient) validate() (err error) {
+	host, portString, err := net.SplitHostPort(s.Server)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrShadowsocksServerNotValid, err)
+	}
+	if net.ParseIP(host) == nil {
+		return fmt.Errorf("%w: %s is not an IP address", ErrShadowsocksServerNotValid, host)
+	}
+	const base, bitSize = 10, 16
+	port, err := strconv.ParseUint(portString, base, bitSize)
+	if err != nil || port == 0 {
+		return fmt.Errorf("%w: port %s is not valid", ErrShadowsocksServerNotValid, portString)
+	}
+
+	if *s.Password == "" {
+		return fmt.Errorf("%w", ErrShadowsocksPasswordNotSet)
+	}
+
+	return validation.ValidateCipher(s.CipherName)
+}
+
+// validateTCPOnly verifies the DNS and firewall settings given do
+// not rely on UDP traffic, which is not relayed through the server,
+// and block it such that it does not leak out of the container.
+func (s ShadowsocksClient) validateTCPOnly(dns DNS, firewall Firewall) (err error) {
+	switch {
+	case !*firewall.Enabled:
+		return fmt.Errorf("%w: the firewall must be enabled to block UDP traffic",
+			ErrShadowsocksClientTCPOnly)
+	case !*dns.DoT.Enabled:
+		return fmt.Errorf("%w: DNS over TLS must be enabled to resolve names over TCP",
+			ErrShadowsocksClientTCPOnly)
+	case *dns.KeepNameserver:
+		return fmt.Errorf("%w: the DNS nameserver cannot be kept since it is reached over UDP",
+			ErrShadowsocksClientTCPOnly)
+	case !dns.ServerAddress.IsLoopback():
+		return fmt.Errorf("%w: DNS server address %s must be a loopback address",
+			ErrShadowsocksClientTCPOnly, dns.ServerAddress)
+	}
+	return nil
+}
+
+func (s *ShadowsocksClient) copy() (copied ShadowsocksClient) {
+	return ShadowsocksClient{
+		Server:     s.Server,
+		Password:   helpers.CopyStringPtr(s.Password),
+		CipherName: s.CipherName,
+	}
+}
+
+// mergeWith merges the other settings into any
+// unset field of the receiver settings object.
+func (s *ShadowsocksClient) mergeWith(other ShadowsocksClient) {
+	s.Server = helpers.MergeWithString(s.Server, other.Server)
+	s.Password = helpers.MergeWithStringPtr(s.Password, other.Password)
+	s.CipherName = helpers.MergeWithString(s.CipherName, other.CipherName)
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (s *ShadowsocksClient) overrideWith(other ShadowsocksClient) {
+	s.Server = helpers.OverrideWithString(s.Server, other.Server)
+	s.Password = helpers.OverrideWithStringPtr(s.Password, other.Password)
+	s.CipherName = helpers.OverrideWithString(s.CipherName, other.CipherName)
+}
+
+func (s *ShadowsocksClient) setDefaults() {
+	s.Password = helpers.DefaultStringPtr(s.Password, "")
+	s.CipherName = helpers.DefaultString(s.CipherName, "chacha20-ietf-poly1305")
+}
+
+func (s ShadowsocksClient) String() string {
+	return s.toLinesNode().String()
+}
+
+func (s ShadowsocksClient) toLinesNode() (node *gotree.Node) {
+	node = gotree.New("Shadowsocks client settings:")
+	node.Appendf("Server: %s", s.Server)
+	node.Appendf("Cipher: %s", s.CipherName)
+	node.Appendf("Password: %s", helpers.ObfuscatePassword(*s.Password))
+	return node
+}
@@ -0,0 +1,42 @@
+package settings
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ShadowsocksClient_validateTCPOnly(t *testing.T) {
+	t.Parallel()
+
+	var dns DNS
+	dns.setDefaults()
+	var firewall Firewall
+	firewall.setDefaults()
+	client := ShadowsocksClient{}
+	assert.NoError(t, client.validateTCPOnly(dns, firewall))
+
+	disabledFirewall := firewall.copy()
+	disabledFirewall.Enabled = boolPtr(false)
+	err := client.validateTCPOnly(dns, disabledFirewall)
+	assert.ErrorIs(t, err, ErrShadowsocksClientTCPOnly)
+	assert.EqualError(t, err, "Shadowsocks client only relays TCP traffic: "+
+		"the firewall must be enabled to block UDP traffic")
+
+	noDoT := dns.Copy()
+	noDoT.DoT.Enabled = boolPtr(false)
+	err = client.validateTCPOnly(noDoT, firewall)
+	assert.ErrorIs(t, err, ErrShadowsocksClientTCPOnly)
+
+	keepNameserver := dns.Copy()
+	keepNameserver.KeepNameserver = boolPtr(true)
+	err = client.validateTCPOnly(keepNameserver, firewall)
+	assert.ErrorIs(t, err, ErrShadowsocksClientTCPOnly)
+
+	remoteServer := dns.Copy()
+	remoteServer.ServerAddress = net.IPv4(1, 1, 1, 1)
+	err = client.validateTCPOnly(remoteServer, firewall)
+	assert.EqualError(t, err, "Shadowsocks client only relays TCP traffic: "+
+		"DNS server address 1.1.1.1 must be a loopback address")
+}
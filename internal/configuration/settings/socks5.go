@@ -0,0 +1,132 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+	"github.com/qdm12/govalid/address"
+)
+
+// SOCKS5 contains settings to configure the SOCKS5 proxy.
+type SOCKS5 struct {
+	// User is the username to use for the SOCKS5 proxy.
+	// It can be the empty string to disable authentication,
+	// and cannot be nil in the internal state.
+	User *string
+	// Password is the password to use for the SOCKS5 proxy.
+	// It cannot be nil in the internal state.
+	Password *string
+	// ListeningAddress is the listening address
+	// of the SOCKS5 proxy server.
+	// It cannot be the empty string in the internal state.
+	ListeningAddress string
+	// Enabled is true if the SOCKS5 proxy server should run,
+	// and false otherwise. It cannot be nil in the
+	// internal state.
+	Enabled *bool
+	// Log is true if the SOCKS5 proxy server should log
+	// each connection. It cannot be nil in the internal state.
+	Log *bool
+	// ConnectPorts are the destination ports CONNECT requests
+	// are allowed to target. It defaults to 443 only, so other
+	// destination ports such as 80 or 22 are refused unless
+	// they are added explicitly.
+	ConnectPorts []uint16
+}
+
+func (s SOCKS5) validate() (err error) {
+	uid := os.Getuid()
+	_, err = address.Validate(s.ListeningAddress, address.OptionListening(uid))
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrServerAddressNotValid, s.ListeningAddress)
+	}
+
+	// RFC 1929 encodes the username and password lengths on one byte.
+	const maxLength = 255
+	switch {
+	case len(*s.User) > maxLength:
+		return fmt.Errorf("%w: user length %d must be at most %d",
+			ErrSOCKS5CredentialsTooLong, len(*s.User), maxLength)
+	case len(*s.Password) > maxLength:
+		return fmt.Errorf("%w: password length %d must be at most %d",
+			ErrSOCKS5CredentialsTooLong, len(*s.Password), maxLength)
+	case *s.User != "" && *s.Password == "":
+		return fmt.Errorf("%w", ErrSOCKS5PasswordNotSet)
+	}
+
+	if hasZeroPort(s.ConnectPorts) {
+		return fmt.Errorf("CONNECT ports: %w", ErrSOCKS5ZeroPort)
+	}
+
+	return nil
+}
+
+func (s *SOCKS5) copy() (copied SOCKS5) {
+	return SOCKS5{
+		User:             helpers.CopyStringPtr(s.User),
+		Password:         helpers.CopyStringPtr(s.Password),
+		ListeningAddress: s.ListeningAddress,
+		Enabled:          helpers.CopyBoolPtr(s.Enabled),
+		Log:              helpers.CopyBoolPtr(s.Log),
+		ConnectPorts:     helpers.CopyUint16Slice(s.ConnectPorts),
+	}
+}
+
+// mergeWith merges the other settings into any
+// unset field of the receiver settings object.
+func (s *SOCKS5) mergeWith(other SOCKS5) {
+	s.User = helpers.MergeWithStringPtr(s.User, other.User)
+	s.Password = helpers.MergeWithStringPtr(s.Password, other.Password)
+	s.ListeningAddress = helpers.MergeWithString(s.ListeningAddress, other.ListeningAddress)
+	s.Enabled = helpers.MergeWithBool(s.Enabled, other.Enabled)
+	s.Log = helpers.MergeWithBool(s.Log, other.Log)
+	s.ConnectPorts = helpers.MergeUint16Slices(s.ConnectPorts, other.ConnectPorts)
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (s *SOCKS5) overrideWith(other SOCKS5) {
+	s.User = helpers.OverrideWithStringPtr(s.User, other.User)
+	s.Password = helpers.OverrideWithStringPtr(s.Password, other.Password)
+	s.ListeningAddress = helpers.OverrideWithString(s.ListeningAddress, other.ListeningAddress)
+	s.Enabled = helpers.OverrideWithBool(s.Enabled, other.Enabled)
+	s.Log = helpers.OverrideWithBool(s.Log, other.Log)
+	s.ConnectPorts = helpers.OverrideWithUint16Slice(s.ConnectPorts, other.ConnectPorts)
+}
+
+func (s *SOCKS5) setDefaults() {
+	s.User = helpers.DefaultStringPtr(s.User, "")
+	s.Password = helpers.DefaultStringPtr(s.Password, "")
+	s.ListeningAddress = helpers.DefaultString(s.ListeningAddress, ":1080")
+	s.Enabled = helpers.DefaultBool(s.Enabled, false)
+	s.Log = helpers.DefaultBool(s.Log, false)
+	if s.ConnectPorts == nil {
+		const httpsPort = 443
+		s.ConnectPorts = []uint16{httpsPort}
+	}
+}
+
+func (s SOCKS5) String() string {
+	return s.toLinesNode().String()
+}
+
+func (s SOCKS5) toLinesNode() (node *gotree.Node) {
+	node = gotree.New("SOCKS5 proxy settings:")
+	node.Appendf("Enabled: %s", helpers.BoolPtrToYesNo(s.Enabled))
+	if !*s.Enabled {
+		return node
+	}
+
+	node.Appendf("Listening address: %s", s.ListeningAddress)
+	if *s.User != "" {
+		node.Appendf("User: %s", *s.User)
+		node.Appendf("Password: %s", helpers.ObfuscatePassword(*s.Password))
+	}
+	node.Appendf("Log: %s", helpers.BoolPtrToYesNo(s.Log))
+	node.Appendf("CONNECT ports: %v", s.ConnectPorts)
+
+	return node
+}
@@ -0,0 +1,63 @@
+package settings
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+)
+
+// StaticRoute is a route set by the user, applied each time
+// the VPN tunnel is up.
+type StaticRoute struct {
+	// Destination is the destination subnet of the route.
+	Destination net.IPNet
+	// Gateway is the IP address of the gateway to route through.
+	// It can be nil to route directly through the Interface.
+	Gateway net.IP
+	// Interface is the name of the network interface to route
+	// through. It can be the empty string to use the interface
+	// reaching the Gateway.
+	Interface string
+}
+
+func (s StaticRoute) validate() (err error) {
+	switch {
+	case s.Gateway == nil && s.Interface == "":
+		return fmt.Errorf("%w", ErrStaticRouteGatewayInterfaceNotSet)
+	case s.Gateway != nil && (s.Gateway.To4() != nil) != (s.Destination.IP.To4() != nil):
+		return fmt.Errorf("%w: gateway %s for destination %s",
+			ErrStaticRouteFamilyMismatch, s.Gateway, &s.Destination)
+	case s.Interface != "" && !regexpInterfaceName.MatchString(s.Interface):
+		return fmt.Errorf("%w: %s", ErrStaticRouteInterfaceNotValid, s.Interface)
+	}
+	return nil
+}
+
+func copyStaticRoutes(original []StaticRoute) (copied []StaticRoute) {
+	if original == nil {
+		return nil
+	}
+	copied = make([]StaticRoute, len(original))
+	for i, route := range original {
+		copied[i] = StaticRoute{
+			Destination: helpers.CopyIPNet(route.Destination),
+			Gateway:     helpers.CopyIP(route.Gateway),
+			Interface:   route.Interface,
+		}
+	}
+	return copied
+}
+
+// String returns the route in the format of the ip route
+// command, for example "10.0.0.0/8 via 172.18.0.1 dev eth1".
+func (s StaticRoute) String() string {
+	str := s.Destination.String()
+	if s.Gateway != nil {
+		str += " via " + s.Gateway.String()
+	}
+	if s.Interface != "" {
+		str += " dev " + s.Interface
+	}
+	return str
+}
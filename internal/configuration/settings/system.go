@@ -1,46 +1,85 @@
 package settings
 
 import (
+	"fmt"
+
 	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
 	"github.com/qdm12/gotree"
 )
 
 // System contains settings to configure system related elements.
 type System struct {
-	PUID     *uint32
-	PGID     *uint32
-	Timezone string
+	PUID *uint32
+	PGID *uint32
+	// FilesUID and FilesGID are the user and group IDs owning
+	// the files written by gluetun, for example under /gluetun.
+	// They are left nil if not set, in which case the forwarded
+	// port and public IP files are owned by PUID and PGID, and
+	// the other files written keep the ownership of the gluetun
+	// process.
+	FilesUID *uint32
+	FilesGID *uint32
+	// FixOwnership is true to change on startup the ownership of
+	// the paths under /gluetun owned by gluetun to FilesUID and
+	// FilesGID, which must then be set. It defaults to false and
+	// cannot be nil in the internal state.
+	FixOwnership *bool
+	Timezone     string
+	// HostMode is true if gluetun runs directly on a host,
+	// for example as a systemd service, instead of in its
+	// container image. It disables container specific setup
+	// such as creating the process user in the Alpine
+	// /etc/passwd file. It cannot be nil in the internal state.
+	HostMode *bool
 }
 
 // Validate validates System settings.
 func (s System) validate() (err error) {
+	if *s.FixOwnership && (s.FilesUID == nil || s.FilesGID == nil) {
+		return fmt.Errorf("%w: files user and group IDs must be set to fix files ownership",
+			ErrSystemFilesIDNotSet)
+	}
 	return nil
 }
 
 func (s *System) copy() (copied System) {
 	return System{
-		PUID:     helpers.CopyUint32Ptr(s.PUID),
-		PGID:     helpers.CopyUint32Ptr(s.PGID),
-		Timezone: s.Timezone,
+		PUID:         helpers.CopyUint32Ptr(s.PUID),
+		PGID:         helpers.CopyUint32Ptr(s.PGID),
+		FilesUID:     helpers.CopyUint32Ptr(s.FilesUID),
+		FilesGID:     helpers.CopyUint32Ptr(s.FilesGID),
+		FixOwnership: helpers.CopyBoolPtr(s.FixOwnership),
+		Timezone:     s.Timezone,
+		HostMode:     helpers.CopyBoolPtr(s.HostMode),
 	}
 }
 
 func (s *System) mergeWith(other System) {
 	s.PUID = helpers.MergeWithUint32(s.PUID, other.PUID)
 	s.PGID = helpers.MergeWithUint32(s.PGID, other.PGID)
+	s.FilesUID = helpers.MergeWithUint32(s.FilesUID, other.FilesUID)
+	s.FilesGID = helpers.MergeWithUint32(s.FilesGID, other.FilesGID)
+	s.FixOwnership = helpers.MergeWithBool(s.FixOwnership, other.FixOwnership)
 	s.Timezone = helpers.MergeWithString(s.Timezone, other.Timezone)
+	s.HostMode = helpers.MergeWithBool(s.HostMode, other.HostMode)
 }
 
 func (s *System) overrideWith(other System) {
 	s.PUID = helpers.OverrideWithUint32(s.PUID, other.PUID)
 	s.PGID = helpers.OverrideWithUint32(s.PGID, other.PGID)
+	s.FilesUID = helpers.OverrideWithUint32(s.FilesUID, other.FilesUID)
+	s.FilesGID = helpers.OverrideWithUint32(s.FilesGID, other.FilesGID)
+	s.FixOwnership = helpers.OverrideWithBool(s.FixOwnership, other.FixOwnership)
 	s.Timezone = helpers.OverrideWithString(s.Timezone, other.Timezone)
+	s.HostMode = helpers.OverrideWithBool(s.HostMode, other.HostMode)
 }
 
 func (s *System) setDefaults() {
 	const defaultID = 1000
 	s.PUID = helpers.DefaultUint32(s.PUID, defaultID)
 	s.PGID = helpers.DefaultUint32(s.PGID, defaultID)
+	s.FixOwnership = helpers.DefaultBool(s.FixOwnership, false)
+	s.HostMode = helpers.DefaultBool(s.HostMode, false)
 }
 
 func (s System) String() string {
@@ -52,10 +91,20 @@ func (s System) toLinesNode() (node *gotree.Node) {
 
 	node.Appendf("Process UID: %d", *s.PUID)
 	node.Appendf("Process GID: %d", *s.PGID)
+	if s.FilesUID != nil && s.FilesGID != nil {
+		node.Appendf("Files UID:GID: %d:%d", *s.FilesUID, *s.FilesGID)
+	}
+	if *s.FixOwnership {
+		node.Appendf("Fix files ownership: yes")
+	}
 
 	if s.Timezone != "" {
 		node.Appendf("Timezone: %s", s.Timezone)
 	}
 
+	if *s.HostMode {
+		node.Appendf("Host mode: yes")
+	}
+
 	return node
 }
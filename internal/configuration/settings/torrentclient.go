@@ -0,0 +1,84 @@
+package settings
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// TorrentClient contains settings to reach the web API of
+// a torrent client, to set its listening port to the port
+// forwarded.
+type TorrentClient struct {
+	// URL is the base URL of the torrent client web API,
+	// for example http://localhost:8080. It can be the empty
+	// string to disable setting the port, and cannot be nil
+	// in the internal state.
+	URL *string
+	// Username is the username to authenticate with.
+	// It cannot be nil in the internal state.
+	Username *string
+	// Password is the password to authenticate with.
+	// It cannot be nil in the internal state.
+	Password *string
+}
+
+func (t TorrentClient) validate() (err error) {
+	if *t.URL == "" {
+		return nil
+	}
+
+	parsedURL, err := url.Parse(*t.URL)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrTorrentClientURLNotValid, err)
+	} else if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https: %s",
+			ErrTorrentClientURLNotValid, *t.URL)
+	}
+
+	return nil
+}
+
+func (t *TorrentClient) copy() (copied TorrentClient) {
+	return TorrentClient{
+		URL:      helpers.CopyStringPtr(t.URL),
+		Username: helpers.CopyStringPtr(t.Username),
+		Password: helpers.CopyStringPtr(t.Password),
+	}
+}
+
+func (t *TorrentClient) mergeWith(other TorrentClient) {
+	t.URL = helpers.MergeWithStringPtr(t.URL, other.URL)
+	t.Username = helpers.MergeWithStringPtr(t.Username, other.Username)
+	t.Password = helpers.MergeWithStringPtr(t.Password, other.Password)
+}
+
+func (t *TorrentClient) overrideWith(other TorrentClient) {
+	t.URL = helpers.OverrideWithStringPtr(t.URL, other.URL)
+	t.Username = helpers.OverrideWithStringPtr(t.Username, other.Username)
+	t.Password = helpers.OverrideWithStringPtr(t.Password, other.Password)
+}
+
+func (t *TorrentClient) setDefaults() {
+	t.URL = helpers.DefaultStringPtr(t.URL, "")
+	t.Username = helpers.DefaultStringPtr(t.Username, "")
+	t.Password = helpers.DefaultStringPtr(t.Password, "")
+}
+
+func (t TorrentClient) toLinesNode(title string) (node *gotree.Node) {
+	if *t.URL == "" {
+		return nil
+	}
+
+	node = gotree.New(title + ":")
+	node.Appendf("URL: %s", *t.URL)
+	if *t.Username != "" {
+		node.Appendf("Username: %s", *t.Username)
+	}
+	if *t.Password != "" {
+		node.Appendf("Password: %s", helpers.ObfuscatePassword(*t.Password))
+	}
+	return node
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/qdm12/dns/pkg/provider"
 	"github.com/qdm12/dns/pkg/unbound"
@@ -22,6 +23,26 @@ type Unbound struct {
 	ValidationLogLevel    *uint8
 	Username              string
 	Allowed               []netaddr.IPPrefix
+	// CacheSize is the size in bytes of each of the message
+	// and resource record set caches. It defaults to 8MiB
+	// and cannot be nil in the internal state.
+	CacheSize *uint64
+	// CacheMinTTL is the minimum time to live of cached
+	// answers, overriding smaller TTL values. It defaults
+	// to 1h and cannot be nil in the internal state.
+	CacheMinTTL *time.Duration
+	// CacheMaxTTL is the maximum time to live of cached
+	// answers, overriding larger TTL values. It defaults
+	// to 2h30m and cannot be nil in the internal state.
+	CacheMaxTTL *time.Duration
+	// NegativeCacheSize is the size in bytes of the cache of
+	// non-existent domains for DNSSEC validation. It defaults
+	// to 1MiB and cannot be nil in the internal state.
+	NegativeCacheSize *uint64
+	// CacheMaxNegativeTTL is the maximum time to live of cached
+	// negative answers such as NXDOMAIN. It defaults to 1h and
+	// cannot be nil in the internal state.
+	CacheMaxNegativeTTL *time.Duration
 }
 
 func (u *Unbound) setDefaults() {
@@ -51,12 +72,23 @@ func (u *Unbound) setDefaults() {
 	}
 
 	u.Username = helpers.DefaultString(u.Username, "root")
+
+	const defaultCacheSize = 8 << 20
+	u.CacheSize = helpers.DefaultUint64(u.CacheSize, defaultCacheSize)
+	u.CacheMinTTL = helpers.DefaultDurationPtr(u.CacheMinTTL, time.Hour)
+	const defaultCacheMaxTTL = 150 * time.Minute
+	u.CacheMaxTTL = helpers.DefaultDurationPtr(u.CacheMaxTTL, defaultCacheMaxTTL)
+	const defaultNegativeCacheSize = 1 << 20
+	u.NegativeCacheSize = helpers.DefaultUint64(u.NegativeCacheSize, defaultNegativeCacheSize)
+	u.CacheMaxNegativeTTL = helpers.DefaultDurationPtr(u.CacheMaxNegativeTTL, time.Hour)
 }
 
 var (
 	ErrUnboundVerbosityLevelNotValid        = errors.New("Unbound verbosity level is not valid")
 	ErrUnboundVerbosityDetailsLevelNotValid = errors.New("Unbound verbosity details level is not valid")
 	ErrUnboundValidationLogLevelNotValid    = errors.New("Unbound validation log level is not valid")
+	ErrUnboundCacheSizeTooSmall             = errors.New("Unbound cache size is too small")
+	ErrUnboundCacheTTLNotValid              = errors.New("Unbound cache TTL is not valid")
 )
 
 func (u Unbound) validate() (err error) {
@@ -90,6 +122,35 @@ func (u Unbound) validate() (err error) {
 			*u.ValidationLogLevel, maxValidationLogLevel)
 	}
 
+	const minCacheSize = 64 << 10
+	if *u.CacheSize < minCacheSize {
+		return fmt.Errorf("%w: %d bytes must be at least %d bytes",
+			ErrUnboundCacheSizeTooSmall, *u.CacheSize, minCacheSize)
+	} else if *u.NegativeCacheSize < minCacheSize {
+		return fmt.Errorf("%w: negative cache size %d bytes must be at least %d bytes",
+			ErrUnboundCacheSizeTooSmall, *u.NegativeCacheSize, minCacheSize)
+	}
+
+	ttls := []struct {
+		name  string
+		value time.Duration
+	}{
+		{name: "minimum", value: *u.CacheMinTTL},
+		{name: "maximum", value: *u.CacheMaxTTL},
+		{name: "maximum negative", value: *u.CacheMaxNegativeTTL},
+	}
+	for _, ttl := range ttls {
+		if ttl.value < 0 || ttl.value%time.Second != 0 {
+			return fmt.Errorf("%w: %s TTL %s must be a positive number of seconds",
+				ErrUnboundCacheTTLNotValid, ttl.name, ttl.value)
+		}
+	}
+
+	if *u.CacheMinTTL > *u.CacheMaxTTL {
+		return fmt.Errorf("%w: minimum TTL %s is larger than maximum TTL %s",
+			ErrUnboundCacheTTLNotValid, *u.CacheMinTTL, *u.CacheMaxTTL)
+	}
+
 	return nil
 }
 
@@ -103,6 +164,11 @@ func (u Unbound) copy() (copied Unbound) {
 		ValidationLogLevel:    helpers.CopyUint8Ptr(u.ValidationLogLevel),
 		Username:              u.Username,
 		Allowed:               helpers.CopyIPPrefixSlice(u.Allowed),
+		CacheSize:             helpers.CopyUint64Ptr(u.CacheSize),
+		CacheMinTTL:           helpers.CopyDurationPtr(u.CacheMinTTL),
+		CacheMaxTTL:           helpers.CopyDurationPtr(u.CacheMaxTTL),
+		NegativeCacheSize:     helpers.CopyUint64Ptr(u.NegativeCacheSize),
+		CacheMaxNegativeTTL:   helpers.CopyDurationPtr(u.CacheMaxNegativeTTL),
 	}
 }
 
@@ -115,6 +181,11 @@ func (u *Unbound) mergeWith(other Unbound) {
 	u.ValidationLogLevel = helpers.MergeWithUint8(u.ValidationLogLevel, other.ValidationLogLevel)
 	u.Username = helpers.MergeWithString(u.Username, other.Username)
 	u.Allowed = helpers.MergeIPPrefixesSlices(u.Allowed, other.Allowed)
+	u.CacheSize = helpers.MergeWithUint64(u.CacheSize, other.CacheSize)
+	u.CacheMinTTL = helpers.MergeWithDurationPtr(u.CacheMinTTL, other.CacheMinTTL)
+	u.CacheMaxTTL = helpers.MergeWithDurationPtr(u.CacheMaxTTL, other.CacheMaxTTL)
+	u.NegativeCacheSize = helpers.MergeWithUint64(u.NegativeCacheSize, other.NegativeCacheSize)
+	u.CacheMaxNegativeTTL = helpers.MergeWithDurationPtr(u.CacheMaxNegativeTTL, other.CacheMaxNegativeTTL)
 }
 
 func (u *Unbound) overrideWith(other Unbound) {
@@ -126,6 +197,11 @@ func (u *Unbound) overrideWith(other Unbound) {
 	u.ValidationLogLevel = helpers.OverrideWithUint8(u.ValidationLogLevel, other.ValidationLogLevel)
 	u.Username = helpers.OverrideWithString(u.Username, other.Username)
 	u.Allowed = helpers.OverrideWithIPPrefixesSlice(u.Allowed, other.Allowed)
+	u.CacheSize = helpers.OverrideWithUint64(u.CacheSize, other.CacheSize)
+	u.CacheMinTTL = helpers.OverrideWithDurationPtr(u.CacheMinTTL, other.CacheMinTTL)
+	u.CacheMaxTTL = helpers.OverrideWithDurationPtr(u.CacheMaxTTL, other.CacheMaxTTL)
+	u.NegativeCacheSize = helpers.OverrideWithUint64(u.NegativeCacheSize, other.NegativeCacheSize)
+	u.CacheMaxNegativeTTL = helpers.OverrideWithDurationPtr(u.CacheMaxNegativeTTL, other.CacheMaxNegativeTTL)
 }
 
 func (u Unbound) ToUnboundFormat() (settings unbound.Settings, err error) {
@@ -178,6 +254,13 @@ func (u Unbound) toLinesNode() (node *gotree.Node) {
 	}
 
 	node.Appendf("Caching: %s", helpers.BoolPtrToYesNo(u.Caching))
+	if *u.Caching {
+		cacheNode := node.Appendf("Cache:")
+		cacheNode.Appendf("Size: %d bytes", *u.CacheSize)
+		cacheNode.Appendf("TTL: from %s to %s", *u.CacheMinTTL, *u.CacheMaxTTL)
+		cacheNode.Appendf("Negative cache size: %d bytes", *u.NegativeCacheSize)
+		cacheNode.Appendf("Negative answers maximum TTL: %s", *u.CacheMaxNegativeTTL)
+	}
 	node.Appendf("IPv6: %s", helpers.BoolPtrToYesNo(u.IPv6))
 	node.Appendf("Verbosity level: %d", *u.VerbosityLevel)
 	node.Appendf("Verbosity details level: %d", *u.VerbosityDetailsLevel)
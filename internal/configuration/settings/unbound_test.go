@@ -31,7 +31,9 @@ func Test_Unbound_JSON(t *testing.T) {
 
 	const expected = `{"Providers":["cloudflare"],"Caching":true,"IPv6":false,` +
 		`"VerbosityLevel":1,"VerbosityDetailsLevel":null,"ValidationLogLevel":0,` +
-		`"Username":"user","Allowed":["0.0.0.0/0","::/0"]}`
+		`"Username":"user","Allowed":["0.0.0.0/0","::/0"],` +
+		`"CacheSize":null,"CacheMinTTL":null,"CacheMaxTTL":null,` +
+		`"NegativeCacheSize":null,"CacheMaxNegativeTTL":null}`
 
 	assert.Equal(t, expected, string(b))
 
@@ -29,6 +29,14 @@ type Updater struct {
 	// Providers is the list of VPN service providers
 	// to update server information for.
 	Providers []string
+	// Workers is the maximum number of providers
+	// to update concurrently. It cannot be nil in
+	// the internal state and defaults to 4.
+	Workers *int
+	// ProviderTimeout is the maximum duration allowed
+	// to update the servers of a single provider.
+	// It defaults to 10 minutes.
+	ProviderTimeout time.Duration
 }
 
 func (u Updater) Validate() (err error) {
@@ -43,6 +51,16 @@ func (u Updater) Validate() (err error) {
 			ErrMinRatioNotValid, u.MinRatio)
 	}
 
+	if *u.Workers < 1 {
+		return fmt.Errorf("%w: %d must be at least 1",
+			ErrUpdaterWorkersNotValid, *u.Workers)
+	}
+
+	if u.ProviderTimeout <= 0 {
+		return fmt.Errorf("%w: %s must be positive",
+			ErrUpdaterProviderTimeoutNotValid, u.ProviderTimeout)
+	}
+
 	validProviders := providers.All()
 	for _, provider := range u.Providers {
 		valid := false
@@ -63,10 +81,12 @@ func (u Updater) Validate() (err error) {
 
 func (u *Updater) copy() (copied Updater) {
 	return Updater{
-		Period:     helpers.CopyDurationPtr(u.Period),
-		DNSAddress: u.DNSAddress,
-		MinRatio:   u.MinRatio,
-		Providers:  helpers.CopyStringSlice(u.Providers),
+		Period:          helpers.CopyDurationPtr(u.Period),
+		DNSAddress:      u.DNSAddress,
+		MinRatio:        u.MinRatio,
+		Providers:       helpers.CopyStringSlice(u.Providers),
+		Workers:         helpers.CopyIntPtr(u.Workers),
+		ProviderTimeout: u.ProviderTimeout,
 	}
 }
 
@@ -77,6 +97,8 @@ func (u *Updater) mergeWith(other Updater) {
 	u.DNSAddress = helpers.MergeWithString(u.DNSAddress, other.DNSAddress)
 	u.MinRatio = helpers.MergeWithFloat64(u.MinRatio, other.MinRatio)
 	u.Providers = helpers.MergeStringSlices(u.Providers, other.Providers)
+	u.Workers = helpers.MergeWithIntPtr(u.Workers, other.Workers)
+	u.ProviderTimeout = helpers.MergeWithDuration(u.ProviderTimeout, other.ProviderTimeout)
 }
 
 // overrideWith overrides fields of the receiver
@@ -87,6 +109,8 @@ func (u *Updater) overrideWith(other Updater) {
 	u.DNSAddress = helpers.OverrideWithString(u.DNSAddress, other.DNSAddress)
 	u.MinRatio = helpers.OverrideWithFloat64(u.MinRatio, other.MinRatio)
 	u.Providers = helpers.OverrideWithStringSlice(u.Providers, other.Providers)
+	u.Workers = helpers.OverrideWithIntPtr(u.Workers, other.Workers)
+	u.ProviderTimeout = helpers.OverrideWithDuration(u.ProviderTimeout, other.ProviderTimeout)
 }
 
 func (u *Updater) SetDefaults(vpnProvider string) {
@@ -101,6 +125,11 @@ func (u *Updater) SetDefaults(vpnProvider string) {
 	if len(u.Providers) == 0 && vpnProvider != providers.Custom {
 		u.Providers = []string{vpnProvider}
 	}
+
+	const defaultWorkers = 4
+	u.Workers = helpers.DefaultInt(u.Workers, defaultWorkers)
+	const defaultProviderTimeout = 10 * time.Minute
+	u.ProviderTimeout = helpers.DefaultDuration(u.ProviderTimeout, defaultProviderTimeout)
 }
 
 func (u Updater) String() string {
@@ -117,6 +146,8 @@ func (u Updater) toLinesNode() (node *gotree.Node) {
 	node.Appendf("DNS address: %s", u.DNSAddress)
 	node.Appendf("Minimum ratio: %.1f", u.MinRatio)
 	node.Appendf("Providers to update: %s", strings.Join(u.Providers, ", "))
+	node.Appendf("Concurrent workers: %d", *u.Workers)
+	node.Appendf("Provider timeout: %s", u.ProviderTimeout)
 
 	return node
 }
@@ -0,0 +1,106 @@
+package settings
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gotree"
+)
+
+// Upstream contains settings to use the HTTP proxy of another
+// gluetun instance as the egress of the OpenVPN connection,
+// to cascade two VPN providers across containers or hosts.
+type Upstream struct {
+	// HTTPProxy is the IP address and port of the HTTP proxy of
+	// the upstream gluetun, such as 172.18.0.2:8888. An IP address
+	// is required since the DNS is only reachable through the VPN.
+	// It can be the empty string to not use an upstream gluetun,
+	// and cannot be nil in the internal state.
+	HTTPProxy *string
+	// HTTPProxyUser is the user for the HTTP proxy of the
+	// upstream gluetun. It can be the empty string for no
+	// authentication, and cannot be nil in the internal state.
+	HTTPProxyUser *string
+	// HTTPProxyPassword is the password for the HTTP proxy
+	// of the upstream gluetun.
+	// It cannot be nil in the internal state.
+	HTTPProxyPassword *string
+}
+
+func (u Upstream) validate(vpnType string, openvpnTCP bool) (err error) {
+	if *u.HTTPProxy == "" {
+		return nil
+	}
+
+	host, port, err := net.SplitHostPort(*u.HTTPProxy)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUpstreamHTTPProxyNotValid, err)
+	} else if net.ParseIP(host) == nil {
+		return fmt.Errorf("%w: host %q is not an IP address",
+			ErrUpstreamHTTPProxyNotValid, host)
+	}
+
+	portUint, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return fmt.Errorf("%w: port %q is not a valid port number",
+			ErrUpstreamHTTPProxyNotValid, port)
+	} else if portUint == 0 {
+		return fmt.Errorf("%w: port cannot be 0", ErrUpstreamHTTPProxyNotValid)
+	}
+
+	switch {
+	case vpnType != vpn.OpenVPN:
+		return fmt.Errorf("%w: VPN type is %s", ErrUpstreamVPNTypeNotOpenVPN, vpnType)
+	case !openvpnTCP:
+		return fmt.Errorf("%w", ErrUpstreamOpenVPNNotTCP)
+	}
+
+	return nil
+}
+
+func (u *Upstream) copy() (copied Upstream) {
+	return Upstream{
+		HTTPProxy:         helpers.CopyStringPtr(u.HTTPProxy),
+		HTTPProxyUser:     helpers.CopyStringPtr(u.HTTPProxyUser),
+		HTTPProxyPassword: helpers.CopyStringPtr(u.HTTPProxyPassword),
+	}
+}
+
+func (u *Upstream) mergeWith(other Upstream) {
+	u.HTTPProxy = helpers.MergeWithStringPtr(u.HTTPProxy, other.HTTPProxy)
+	u.HTTPProxyUser = helpers.MergeWithStringPtr(u.HTTPProxyUser, other.HTTPProxyUser)
+	u.HTTPProxyPassword = helpers.MergeWithStringPtr(u.HTTPProxyPassword, other.HTTPProxyPassword)
+}
+
+func (u *Upstream) overrideWith(other Upstream) {
+	u.HTTPProxy = helpers.OverrideWithStringPtr(u.HTTPProxy, other.HTTPProxy)
+	u.HTTPProxyUser = helpers.OverrideWithStringPtr(u.HTTPProxyUser, other.HTTPProxyUser)
+	u.HTTPProxyPassword = helpers.OverrideWithStringPtr(u.HTTPProxyPassword, other.HTTPProxyPassword)
+}
+
+func (u *Upstream) setDefaults() {
+	u.HTTPProxy = helpers.DefaultStringPtr(u.HTTPProxy, "")
+	u.HTTPProxyUser = helpers.DefaultStringPtr(u.HTTPProxyUser, "")
+	u.HTTPProxyPassword = helpers.DefaultStringPtr(u.HTTPProxyPassword, "")
+}
+
+func (u Upstream) String() string {
+	return u.toLinesNode().String()
+}
+
+func (u Upstream) toLinesNode() (node *gotree.Node) {
+	if *u.HTTPProxy == "" {
+		return nil
+	}
+
+	node = gotree.New("Upstream gluetun settings:")
+	node.Appendf("HTTP proxy: %s", *u.HTTPProxy)
+	if *u.HTTPProxyUser != "" {
+		node.Appendf("HTTP proxy user: %s", *u.HTTPProxyUser)
+		node.Appendf("HTTP proxy password: %s", helpers.ObfuscatePassword(*u.HTTPProxyPassword))
+	}
+	return node
+}
@@ -0,0 +1,99 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Upstream_validate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		httpProxy  string
+		vpnType    string
+		openvpnTCP bool
+		errWrapped error
+		errMessage string
+	}{
+		"disabled": {
+			vpnType: vpn.Wireguard,
+		},
+		"valid": {
+			httpProxy:  "172.18.0.2:8888",
+			vpnType:    vpn.OpenVPN,
+			openvpnTCP: true,
+		},
+		"hostname": {
+			httpProxy:  "gluetun:8888",
+			vpnType:    vpn.OpenVPN,
+			openvpnTCP: true,
+			errWrapped: ErrUpstreamHTTPProxyNotValid,
+			errMessage: `upstream HTTP proxy address is not valid: host "gluetun" is not an IP address`,
+		},
+		"no_port": {
+			httpProxy:  "172.18.0.2",
+			vpnType:    vpn.OpenVPN,
+			openvpnTCP: true,
+			errWrapped: ErrUpstreamHTTPProxyNotValid,
+			errMessage: "upstream HTTP proxy address is not valid: address 172.18.0.2: missing port in address",
+		},
+		"empty_port": {
+			httpProxy:  "172.18.0.2:",
+			vpnType:    vpn.OpenVPN,
+			openvpnTCP: true,
+			errWrapped: ErrUpstreamHTTPProxyNotValid,
+			errMessage: `upstream HTTP proxy address is not valid: port "" is not a valid port number`,
+		},
+		"port_not_a_number": {
+			httpProxy:  "172.18.0.2:http",
+			vpnType:    vpn.OpenVPN,
+			openvpnTCP: true,
+			errWrapped: ErrUpstreamHTTPProxyNotValid,
+			errMessage: `upstream HTTP proxy address is not valid: port "http" is not a valid port number`,
+		},
+		"port_too_big": {
+			httpProxy:  "172.18.0.2:65536",
+			vpnType:    vpn.OpenVPN,
+			openvpnTCP: true,
+			errWrapped: ErrUpstreamHTTPProxyNotValid,
+			errMessage: `upstream HTTP proxy address is not valid: port "65536" is not a valid port number`,
+		},
+		"port_zero": {
+			httpProxy:  "172.18.0.2:0",
+			vpnType:    vpn.OpenVPN,
+			openvpnTCP: true,
+			errWrapped: ErrUpstreamHTTPProxyNotValid,
+			errMessage: "upstream HTTP proxy address is not valid: port cannot be 0",
+		},
+		"wireguard": {
+			httpProxy:  "172.18.0.2:8888",
+			vpnType:    vpn.Wireguard,
+			errWrapped: ErrUpstreamVPNTypeNotOpenVPN,
+			errMessage: "upstream gluetun is only supported with OpenVPN: VPN type is wireguard",
+		},
+		"openvpn_udp": {
+			httpProxy:  "172.18.0.2:8888",
+			vpnType:    vpn.OpenVPN,
+			errWrapped: ErrUpstreamOpenVPNNotTCP,
+			errMessage: "upstream gluetun requires the OpenVPN TCP protocol",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			upstream := Upstream{HTTPProxy: &testCase.httpProxy}
+
+			err := upstream.validate(testCase.vpnType, testCase.openvpnTCP)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
@@ -2,39 +2,124 @@ package settings
 
 import (
 	"fmt"
+	"net"
 	"strings"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gluetun/internal/constants"
 	"github.com/qdm12/gluetun/internal/constants/vpn"
 	"github.com/qdm12/gotree"
 )
 
 type VPN struct {
 	// Type is the VPN type and can only be
-	// 'openvpn' or 'wireguard'. It cannot be the
-	// empty string in the internal state.
-	Type      string
-	Provider  Provider
-	OpenVPN   OpenVPN
-	Wireguard Wireguard
+	// 'openvpn', 'wireguard', 'shadowsocks', 'ikev2' or 'openconnect'.
+	// It cannot be the empty string in the internal state.
+	Type        string
+	Provider    Provider
+	OpenVPN     OpenVPN
+	Wireguard   Wireguard
+	Upstream    Upstream
+	Shadowsocks ShadowsocksClient
+	IKEv2       IKEv2
+	OpenConnect OpenConnect
+	// LogRules are rules applied in order to change the log level
+	// of, or to suppress, the OpenVPN and Wireguard log lines.
+	// The first rule matching a log line is used.
+	LogRules []VPNLogRule
+	// Hops are up to two Wireguard hops the VPN connection is
+	// chained through in order, such that traffic exits through
+	// the OpenVPN, Wireguard or OpenConnect VPN server.
+	Hops []VPNHop
+	// Routes are the only destinations routed through the VPN
+	// when set, with other traffic going out through the default
+	// routes. Traffic to these subnets is only allowed through the VPN.
+	// It is only supported with OpenVPN and Wireguard.
+	Routes []net.IPNet
+	// StaticRoutes are routes applied each time the VPN tunnel is up,
+	// taking precedence over the VPN routes.
+	StaticRoutes []StaticRoute
+	// Tunnels are additional Wireguard tunnels running alongside
+	// the VPN connection, each routing only the traffic selected
+	// by its settings.
+	Tunnels []VPNTunnel
 }
 
 // TODO v4 remove pointer for receiver (because of Surfshark).
 func (v *VPN) Validate(storage Storage, ipv6Supported bool) (err error) {
 	// Validate Type
-	validVPNTypes := []string{vpn.OpenVPN, vpn.Wireguard}
+	validVPNTypes := []string{vpn.OpenVPN, vpn.Wireguard, vpn.Shadowsocks, vpn.IKEv2, vpn.OpenConnect}
 	if !helpers.IsOneOf(v.Type, validVPNTypes...) {
 		return fmt.Errorf("%w: %q and can only be one of %s",
 			ErrVPNTypeNotValid, v.Type, strings.Join(validVPNTypes, ", "))
 	}
 
+	for i, rule := range v.LogRules {
+		err = rule.validate()
+		if err != nil {
+			return fmt.Errorf("log rule %d of %d: %w", i+1, len(v.LogRules), err)
+		}
+	}
+
+	err = v.validateHops()
+	if err != nil {
+		return err // already wrapped
+	}
+
+	if len(v.Routes) > 0 && v.Type != vpn.OpenVPN && v.Type != vpn.Wireguard {
+		return fmt.Errorf("%w: for VPN type %s", ErrVPNRoutesNotSupported, v.Type)
+	}
+
+	for i, route := range v.StaticRoutes {
+		err = route.validate()
+		if err != nil {
+			return fmt.Errorf("static route %d of %d: %w", i+1, len(v.StaticRoutes), err)
+		}
+	}
+
+	err = v.validateTunnels()
+	if err != nil {
+		return err // already wrapped
+	}
+
+	if v.Type == vpn.Shadowsocks {
+		// The VPN provider settings are not used to
+		// connect to a Shadowsocks server.
+		err = v.Shadowsocks.validate()
+		if err != nil {
+			return fmt.Errorf("Shadowsocks client settings: %w", err)
+		}
+		return nil
+	}
+
+	if v.Type == vpn.IKEv2 {
+		// The VPN provider settings are not used to
+		// connect to an IKEv2 server.
+		err = v.IKEv2.validate()
+		if err != nil {
+			return fmt.Errorf("IKEv2 settings: %w", err)
+		}
+		return nil
+	}
+
+	if v.Type == vpn.OpenConnect {
+		// The VPN provider settings are not used to
+		// connect to an OpenConnect gateway.
+		err = v.OpenConnect.validate()
+		if err != nil {
+			return fmt.Errorf("OpenConnect settings: %w", err)
+		}
+		return nil
+	}
+
 	err = v.Provider.validate(v.Type, storage)
 	if err != nil {
 		return fmt.Errorf("provider settings: %w", err)
 	}
 
 	if v.Type == vpn.OpenVPN {
-		err := v.OpenVPN.validate(*v.Provider.Name)
+		err := v.OpenVPN.validate(*v.Provider.Name,
+			*v.Provider.ServerSelection.OpenVPN.TCP)
 		if err != nil {
 			return fmt.Errorf("OpenVPN settings: %w", err)
 		}
@@ -45,15 +130,141 @@ func (v *VPN) Validate(storage Storage, ipv6Supported bool) (err error) {
 		}
 	}
 
+	err = v.Upstream.validate(v.Type, *v.Provider.ServerSelection.OpenVPN.TCP)
+	if err != nil {
+		return fmt.Errorf("upstream settings: %w", err)
+	}
+
+	return nil
+}
+
+func (v *VPN) validateHops() (err error) {
+	if len(v.Hops) == 0 {
+		return nil
+	}
+
+	if v.Type == vpn.Shadowsocks {
+		return fmt.Errorf("%w", ErrVPNHopsShadowsocks)
+	} else if v.Type == vpn.IKEv2 {
+		return fmt.Errorf("%w", ErrVPNHopsIKEv2)
+	}
+
+	if (v.Type == vpn.Wireguard && v.Wireguard.Obfuscation.Enabled()) ||
+		(v.Type == vpn.OpenVPN && v.OpenVPN.Obfuscation.Enabled()) {
+		return fmt.Errorf("%w", ErrVPNHopsObfuscation)
+	}
+
+	// Each hop needs its own firewall mark and routing rule priority,
+	// which are reserved for up to two hops.
+	const maxHops = 2
+	if len(v.Hops) > maxHops {
+		return fmt.Errorf("%w: %d hops exceed the maximum of %d",
+			ErrVPNHopsTooMany, len(v.Hops), maxHops)
+	}
+
+	interfaces := map[string]struct{}{v.interfaceName(): {}}
+	for i, hop := range v.Hops {
+		err = hop.validate(interfaces)
+		if err != nil {
+			return fmt.Errorf("hop %d of %d: %w", i+1, len(v.Hops), err)
+		}
+		interfaces[hop.Interface] = struct{}{}
+	}
+
+	return nil
+}
+
+// interfaceName returns the name of the VPN network interface
+// for the VPN types creating one.
+func (v *VPN) interfaceName() string {
+	switch v.Type {
+	case vpn.Wireguard:
+		return v.Wireguard.Interface
+	case vpn.OpenConnect:
+		return v.OpenConnect.Interface
+	default:
+		return v.OpenVPN.Interface
+	}
+}
+
+func (v *VPN) validateTunnels() (err error) {
+	interfaces := map[string]struct{}{v.interfaceName(): {}}
+	for _, hop := range v.Hops {
+		interfaces[hop.Interface] = struct{}{}
+	}
+
+	reservedMarks := reservedFirewallMarks(len(v.Hops), len(v.Tunnels))
+	marks := make(map[uint32]struct{}, len(v.Tunnels))
+	for i, tunnel := range v.Tunnels {
+		err = tunnel.validate()
+		if err != nil {
+			return fmt.Errorf("tunnel %d of %d: %w", i+1, len(v.Tunnels), err)
+		}
+
+		if _, ok := interfaces[tunnel.Interface]; ok {
+			return fmt.Errorf("tunnel %d of %d: %w: %s",
+				i+1, len(v.Tunnels), ErrVPNTunnelInterfaceUsed, tunnel.Interface)
+		}
+		interfaces[tunnel.Interface] = struct{}{}
+
+		if _, ok := marks[tunnel.FirewallMark]; ok {
+			return fmt.Errorf("tunnel %d of %d: %w: %d",
+				i+1, len(v.Tunnels), ErrVPNTunnelFirewallMarkUsed, tunnel.FirewallMark)
+		} else if usage, ok := reservedMarks[tunnel.FirewallMark]; ok {
+			return fmt.Errorf("tunnel %d of %d: %w: %d by %s",
+				i+1, len(v.Tunnels), ErrVPNTunnelFirewallMarkUsed, tunnel.FirewallMark, usage)
+		}
+		marks[tunnel.FirewallMark] = struct{}{}
+	}
+
+	return nil
+}
+
+// reservedFirewallMarks returns the firewall marks of the encrypted
+// packets of the Wireguard interfaces run for the numbers of hops and
+// tunnels given, mapped to their usage.
+func reservedFirewallMarks(hops, tunnels int) (markToUsage map[uint32]string) {
+	markToUsage = make(map[uint32]string, 1+hops+tunnels)
+	markToUsage[constants.WireguardFirewallMark] = "the Wireguard VPN connection"
+	for i := 0; i < hops; i++ {
+		markToUsage[uint32(constants.FirstHopFirewallMark+i)] = fmt.Sprintf("hop %d", i+1)
+	}
+	for i := 1; i <= tunnels; i++ {
+		markToUsage[uint32(constants.TunnelFirewallMarkBase+i)] = fmt.Sprintf("the encrypted packets of tunnel %d", i)
+	}
+	return markToUsage
+}
+
+// validateTunnelsBypassMark verifies the tunnels firewall
+// marks differ from the bypass firewall mark given.
+func (v *VPN) validateTunnelsBypassMark(bypassMark uint32) (err error) {
+	if bypassMark == 0 {
+		return nil
+	}
+	for i, tunnel := range v.Tunnels {
+		if tunnel.FirewallMark == bypassMark {
+			return fmt.Errorf("tunnel %d of %d: %w: %d by the firewall bypass",
+				i+1, len(v.Tunnels), ErrVPNTunnelFirewallMarkUsed, tunnel.FirewallMark)
+		}
+	}
 	return nil
 }
 
 func (v *VPN) Copy() (copied VPN) {
 	return VPN{
-		Type:      v.Type,
-		Provider:  v.Provider.copy(),
-		OpenVPN:   v.OpenVPN.copy(),
-		Wireguard: v.Wireguard.copy(),
+		Type:         v.Type,
+		Provider:     v.Provider.copy(),
+		OpenVPN:      v.OpenVPN.copy(),
+		Wireguard:    v.Wireguard.copy(),
+		Upstream:     v.Upstream.copy(),
+		Shadowsocks:  v.Shadowsocks.copy(),
+		IKEv2:        v.IKEv2.copy(),
+		OpenConnect:  v.OpenConnect.copy(),
+		LogRules:     copyVPNLogRules(v.LogRules),
+		Hops:         copyVPNHops(v.Hops),
+		Routes:       helpers.CopyIPNetSlice(v.Routes),
+		StaticRoutes: copyStaticRoutes(v.StaticRoutes),
+		Tunnels:      copyVPNTunnels(v.Tunnels),
 	}
 }
 
@@ -62,6 +273,23 @@ func (v *VPN) mergeWith(other VPN) {
 	v.Provider.mergeWith(other.Provider)
 	v.OpenVPN.mergeWith(other.OpenVPN)
 	v.Wireguard.mergeWith(other.Wireguard)
+	v.Upstream.mergeWith(other.Upstream)
+	v.Shadowsocks.mergeWith(other.Shadowsocks)
+	v.IKEv2.mergeWith(other.IKEv2)
+	v.OpenConnect.mergeWith(other.OpenConnect)
+	if v.LogRules == nil {
+		v.LogRules = copyVPNLogRules(other.LogRules)
+	}
+	if v.Hops == nil {
+		v.Hops = copyVPNHops(other.Hops)
+	}
+	v.Routes = helpers.MergeIPNetsSlices(v.Routes, other.Routes)
+	if v.StaticRoutes == nil {
+		v.StaticRoutes = copyStaticRoutes(other.StaticRoutes)
+	}
+	if v.Tunnels == nil {
+		v.Tunnels = copyVPNTunnels(other.Tunnels)
+	}
 }
 
 func (v *VPN) OverrideWith(other VPN) {
@@ -69,6 +297,23 @@ func (v *VPN) OverrideWith(other VPN) {
 	v.Provider.overrideWith(other.Provider)
 	v.OpenVPN.overrideWith(other.OpenVPN)
 	v.Wireguard.overrideWith(other.Wireguard)
+	v.Upstream.overrideWith(other.Upstream)
+	v.Shadowsocks.overrideWith(other.Shadowsocks)
+	v.IKEv2.overrideWith(other.IKEv2)
+	v.OpenConnect.overrideWith(other.OpenConnect)
+	if other.LogRules != nil {
+		v.LogRules = copyVPNLogRules(other.LogRules)
+	}
+	if other.Hops != nil {
+		v.Hops = copyVPNHops(other.Hops)
+	}
+	v.Routes = helpers.OverrideWithIPNetsSlice(v.Routes, other.Routes)
+	if other.StaticRoutes != nil {
+		v.StaticRoutes = copyStaticRoutes(other.StaticRoutes)
+	}
+	if other.Tunnels != nil {
+		v.Tunnels = copyVPNTunnels(other.Tunnels)
+	}
 }
 
 func (v *VPN) setDefaults() {
@@ -76,6 +321,22 @@ func (v *VPN) setDefaults() {
 	v.Provider.setDefaults()
 	v.OpenVPN.setDefaults(*v.Provider.Name)
 	v.Wireguard.setDefaults()
+	v.Upstream.setDefaults()
+	v.Shadowsocks.setDefaults()
+	v.IKEv2.setDefaults()
+	v.OpenConnect.setDefaults()
+	for i := range v.Hops {
+		v.Hops[i].Interface = helpers.DefaultString(v.Hops[i].Interface,
+			"hop"+fmt.Sprint(i+1))
+	}
+	for i := range v.Tunnels {
+		v.Tunnels[i].Interface = helpers.DefaultString(v.Tunnels[i].Interface,
+			"tunnel"+fmt.Sprint(i+1))
+		if v.Tunnels[i].FirewallMark == 0 {
+			const firewallMarkBase = 51830
+			v.Tunnels[i].FirewallMark = firewallMarkBase + uint32(i) + 1
+		}
+	}
 }
 
 func (v VPN) String() string {
@@ -85,12 +346,56 @@ func (v VPN) String() string {
 func (v VPN) toLinesNode() (node *gotree.Node) {
 	node = gotree.New("VPN settings:")
 
-	node.AppendNode(v.Provider.toLinesNode())
-
-	if v.Type == vpn.OpenVPN {
+	switch v.Type {
+	case vpn.OpenVPN:
+		node.AppendNode(v.Provider.toLinesNode())
 		node.AppendNode(v.OpenVPN.toLinesNode())
-	} else {
+		node.AppendNode(v.Upstream.toLinesNode())
+	case vpn.Wireguard:
+		node.AppendNode(v.Provider.toLinesNode())
 		node.AppendNode(v.Wireguard.toLinesNode())
+	case vpn.Shadowsocks:
+		node.AppendNode(v.Shadowsocks.toLinesNode())
+	case vpn.IKEv2:
+		node.AppendNode(v.IKEv2.toLinesNode())
+	case vpn.OpenConnect:
+		node.AppendNode(v.OpenConnect.toLinesNode())
+	}
+
+	if len(v.LogRules) > 0 && v.Type != vpn.Shadowsocks {
+		rulesNode := node.Appendf("Log rules:")
+		for _, rule := range v.LogRules {
+			rulesNode.AppendNode(rule.toLinesNode())
+		}
+	}
+
+	if len(v.Hops) > 0 && v.Type != vpn.Shadowsocks && v.Type != vpn.IKEv2 {
+		hopsNode := node.Appendf("Entry hops:")
+		for _, hop := range v.Hops {
+			hopsNode.AppendNode(hop.toLinesNode())
+		}
+	}
+
+	if len(v.Routes) > 0 {
+		routesNode := node.Appendf("Only routed through VPN:")
+		for _, subnet := range v.Routes {
+			subnet := subnet
+			routesNode.Appendf("%s", &subnet)
+		}
+	}
+
+	if len(v.StaticRoutes) > 0 {
+		staticRoutesNode := node.Appendf("Static routes:")
+		for _, route := range v.StaticRoutes {
+			staticRoutesNode.Appendf(route.String())
+		}
+	}
+
+	if len(v.Tunnels) > 0 {
+		tunnelsNode := node.Appendf("Additional tunnels:")
+		for _, tunnel := range v.Tunnels {
+			tunnelsNode.AppendNode(tunnel.toLinesNode())
+		}
 	}
 
 	return node
@@ -0,0 +1,146 @@
+package settings
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// VPNHop contains settings for a Wireguard hop, through which the
+// VPN connection is chained such that traffic enters through the first
+// hop server, goes through the next hop server if any, and exits
+// through the VPN server.
+type VPNHop struct {
+	// Interface is the name of the Wireguard interface to create
+	// for the hop. It defaults to hop1 for the first hop and cannot
+	// be the empty string in the internal state.
+	Interface string
+	// PrivateKey is the Wireguard client private key for the hop.
+	// It cannot be the empty string.
+	PrivateKey string
+	// PublicKey is the public key of the hop server.
+	// It cannot be the empty string.
+	PublicKey string
+	// PreSharedKey is the pre-shared key for the hop server.
+	// It can be the empty string to indicate there
+	// is no pre-shared key.
+	PreSharedKey string
+	// Endpoint is the UDP address of the hop server.
+	// Both its IP address and port must be set.
+	Endpoint *net.UDPAddr
+	// Addresses are the addresses of the hop Wireguard interface.
+	// Only IPv4 addresses are used, and it cannot be empty.
+	Addresses []net.IPNet
+}
+
+// validate validates the hop settings, where usedInterfaces are the
+// names of the VPN interface and of the previous hops interfaces.
+func (v VPNHop) validate(usedInterfaces map[string]struct{}) (err error) {
+	if !regexpInterfaceName.MatchString(v.Interface) {
+		return fmt.Errorf("%w: '%s' does not match regex '%s'",
+			ErrWireguardInterfaceNotValid, v.Interface, regexpInterfaceName)
+	} else if _, used := usedInterfaces[v.Interface]; used {
+		return fmt.Errorf("%w: %s", ErrVPNHopInterfaceConflict, v.Interface)
+	}
+
+	return validateWireguardClient(v.PrivateKey, v.PublicKey,
+		v.PreSharedKey, v.Endpoint, v.Addresses)
+}
+
+// validateWireguardClient validates the keys, the server endpoint and
+// the interface addresses of a Wireguard client connection set up
+// alongside the main VPN connection, such as an entry hop.
+func validateWireguardClient(privateKey, publicKey, preSharedKey string,
+	endpoint *net.UDPAddr, addresses []net.IPNet) (err error) {
+	if privateKey == "" {
+		return fmt.Errorf("%w", ErrWireguardPrivateKeyNotSet)
+	}
+	_, err = wgtypes.ParseKey(privateKey)
+	if err != nil {
+		return fmt.Errorf("private key is not valid: %w", err)
+	}
+
+	_, err = wgtypes.ParseKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrWireguardPublicKeyNotValid, publicKey)
+	}
+
+	if preSharedKey != "" {
+		_, err = wgtypes.ParseKey(preSharedKey)
+		if err != nil {
+			return fmt.Errorf("pre-shared key is not valid: %w", err)
+		}
+	}
+
+	switch {
+	case endpoint == nil || len(endpoint.IP) == 0:
+		return fmt.Errorf("%w", ErrWireguardEndpointIPNotSet)
+	case endpoint.Port == 0:
+		return fmt.Errorf("%w: for endpoint %s", ErrWireguardEndpointPortNotSet, endpoint.IP)
+	}
+
+	hasIPv4Address := false
+	for _, ipNet := range addresses {
+		if ipNet.IP.To4() != nil {
+			hasIPv4Address = true
+			break
+		}
+	}
+	if !hasIPv4Address {
+		return fmt.Errorf("%w", ErrWireguardInterfaceAddressNotSet)
+	}
+
+	return nil
+}
+
+func (v VPNHop) copy() (copied VPNHop) {
+	return VPNHop{
+		Interface:    v.Interface,
+		PrivateKey:   v.PrivateKey,
+		PublicKey:    v.PublicKey,
+		PreSharedKey: v.PreSharedKey,
+		Endpoint:     copyUDPAddr(v.Endpoint),
+		Addresses:    helpers.CopyIPNetSlice(v.Addresses),
+	}
+}
+
+func copyUDPAddr(original *net.UDPAddr) (copied *net.UDPAddr) {
+	if original == nil {
+		return nil
+	}
+	return &net.UDPAddr{
+		IP:   helpers.CopyIP(original.IP),
+		Port: original.Port,
+	}
+}
+
+func copyVPNHops(original []VPNHop) (copied []VPNHop) {
+	if original == nil {
+		return nil
+	}
+	copied = make([]VPNHop, len(original))
+	for i, hop := range original {
+		copied[i] = hop.copy()
+	}
+	return copied
+}
+
+func (v VPNHop) toLinesNode() (node *gotree.Node) {
+	node = gotree.New("Hop %s:", v.Interface)
+	node.Appendf("Endpoint: %s", v.Endpoint)
+	node.Appendf("Server public key: %s", v.PublicKey)
+	node.Appendf("Private key: %s", helpers.ObfuscateWireguardKey(v.PrivateKey))
+	if v.PreSharedKey != "" {
+		node.Appendf("Pre-shared key: %s", helpers.ObfuscateWireguardKey(v.PreSharedKey))
+	}
+
+	addressesNode := node.Appendf("Interface addresses:")
+	for _, address := range v.Addresses {
+		addressesNode.Appendf(address.String())
+	}
+
+	return node
+}
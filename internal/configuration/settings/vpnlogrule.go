@@ -0,0 +1,52 @@
+package settings
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/qdm12/gotree"
+	"github.com/qdm12/log"
+)
+
+// VPNLogRule is a rule to change the log level of, or to suppress,
+// the OpenVPN and Wireguard log lines matching its pattern.
+type VPNLogRule struct {
+	// Pattern is the regular expression matched against each
+	// log line. It cannot be the empty string.
+	Pattern string
+	// Level is the log level to log matching lines at.
+	// It is ignored if Suppress is true.
+	Level log.Level
+	// Suppress is true if matching lines should not be logged.
+	Suppress bool
+}
+
+func (v VPNLogRule) validate() (err error) {
+	if v.Pattern == "" {
+		return fmt.Errorf("%w", ErrVPNLogRulePatternNotSet)
+	}
+
+	_, err = regexp.Compile(v.Pattern)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrVPNLogRulePatternNotValid, err)
+	}
+
+	return nil
+}
+
+func copyVPNLogRules(original []VPNLogRule) (copied []VPNLogRule) {
+	if original == nil {
+		return nil
+	}
+	copied = make([]VPNLogRule, len(original))
+	copy(copied, original)
+	return copied
+}
+
+func (v VPNLogRule) toLinesNode() (node *gotree.Node) {
+	level := v.Level.String()
+	if v.Suppress {
+		level = "suppressed"
+	}
+	return gotree.New("%s: %s", v.Pattern, level)
+}
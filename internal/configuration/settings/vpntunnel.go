@@ -0,0 +1,147 @@
+package settings
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+// VPNTunnel contains settings for an additional Wireguard tunnel,
+// running alongside the main VPN connection. Only the traffic selected
+// by its source subnets, source ports or firewall mark is routed
+// through it, and other traffic keeps going through the main VPN.
+type VPNTunnel struct {
+	// Interface is the name of the Wireguard interface to create
+	// for the tunnel, which also identifies the tunnel. It defaults
+	// to tunnel1 for the first tunnel and cannot be the empty string
+	// in the internal state.
+	Interface string
+	// PrivateKey is the Wireguard client private key for the tunnel.
+	// It cannot be the empty string.
+	PrivateKey string
+	// PublicKey is the public key of the tunnel server.
+	// It cannot be the empty string.
+	PublicKey string
+	// PreSharedKey is the pre-shared key for the tunnel server.
+	// It can be the empty string to indicate there
+	// is no pre-shared key.
+	PreSharedKey string
+	// Endpoint is the UDP address of the tunnel server.
+	// Both its IP address and port must be set.
+	Endpoint *net.UDPAddr
+	// Addresses are the addresses of the tunnel Wireguard interface.
+	// Only IPv4 addresses are used, and it cannot be empty.
+	Addresses []net.IPNet
+	// SourceSubnets are the source subnets of the traffic routed
+	// through the tunnel, such as the IP address of a device using
+	// gluetun as its gateway. It can be empty.
+	SourceSubnets []net.IPNet
+	// SourcePorts are the TCP and UDP source ports of the traffic
+	// originating from gluetun routed through the tunnel, such as
+	// the listening port of a server running next to gluetun.
+	// It can be empty.
+	SourcePorts []uint16
+	// FirewallMark is the firewall mark of the traffic routed
+	// through the tunnel. Traffic sent by programs setting this mark
+	// on their sockets goes through the tunnel. It defaults to 51830
+	// plus the tunnel number and cannot be 0 in the internal state.
+	FirewallMark uint32
+	// ForwardedPort is a port statically forwarded by the tunnel server
+	// to the tunnel, for example set up in the VPN provider account, and
+	// allowed in through the tunnel interface. No port forwarding is
+	// negotiated for tunnels. It can be 0 to indicate there is no
+	// forwarded port.
+	ForwardedPort uint16
+}
+
+func (v VPNTunnel) validate() (err error) {
+	if !regexpInterfaceName.MatchString(v.Interface) {
+		return fmt.Errorf("%w: '%s' does not match regex '%s'",
+			ErrWireguardInterfaceNotValid, v.Interface, regexpInterfaceName)
+	}
+
+	err = validateWireguardClient(v.PrivateKey, v.PublicKey,
+		v.PreSharedKey, v.Endpoint, v.Addresses)
+	if err != nil {
+		return err
+	}
+
+	for _, subnet := range v.SourceSubnets {
+		if subnet.IP.To4() == nil {
+			// The tunnel is only set up for IPv4.
+			return fmt.Errorf("%w: %s", ErrVPNTunnelSourceSubnetNotIPv4, &subnet)
+		}
+	}
+
+	if hasZeroPort(v.SourcePorts) {
+		return fmt.Errorf("%w", ErrVPNTunnelSourcePortZero)
+	}
+
+	return nil
+}
+
+func (v VPNTunnel) copy() (copied VPNTunnel) {
+	return VPNTunnel{
+		Interface:     v.Interface,
+		PrivateKey:    v.PrivateKey,
+		PublicKey:     v.PublicKey,
+		PreSharedKey:  v.PreSharedKey,
+		Endpoint:      copyUDPAddr(v.Endpoint),
+		Addresses:     helpers.CopyIPNetSlice(v.Addresses),
+		SourceSubnets: helpers.CopyIPNetSlice(v.SourceSubnets),
+		SourcePorts:   helpers.CopyUint16Slice(v.SourcePorts),
+		FirewallMark:  v.FirewallMark,
+		ForwardedPort: v.ForwardedPort,
+	}
+}
+
+func copyVPNTunnels(original []VPNTunnel) (copied []VPNTunnel) {
+	if original == nil {
+		return nil
+	}
+	copied = make([]VPNTunnel, len(original))
+	for i, tunnel := range original {
+		copied[i] = tunnel.copy()
+	}
+	return copied
+}
+
+func (v VPNTunnel) toLinesNode() (node *gotree.Node) {
+	node = gotree.New("Tunnel %s:", v.Interface)
+	node.Appendf("Endpoint: %s", v.Endpoint)
+	node.Appendf("Server public key: %s", v.PublicKey)
+	node.Appendf("Private key: %s", helpers.ObfuscateWireguardKey(v.PrivateKey))
+	if v.PreSharedKey != "" {
+		node.Appendf("Pre-shared key: %s", helpers.ObfuscateWireguardKey(v.PreSharedKey))
+	}
+
+	addressesNode := node.Appendf("Interface addresses:")
+	for _, address := range v.Addresses {
+		addressesNode.Appendf(address.String())
+	}
+
+	if len(v.SourceSubnets) > 0 {
+		sourceSubnetsNode := node.Appendf("Source subnets:")
+		for _, subnet := range v.SourceSubnets {
+			subnet := subnet
+			sourceSubnetsNode.Appendf("%s", &subnet)
+		}
+	}
+
+	if len(v.SourcePorts) > 0 {
+		sourcePortsNode := node.Appendf("Source ports:")
+		for _, port := range v.SourcePorts {
+			sourcePortsNode.Appendf("%d", port)
+		}
+	}
+
+	node.Appendf("Firewall mark: %d", v.FirewallMark)
+
+	if v.ForwardedPort != 0 {
+		node.Appendf("Forwarded port: %d", v.ForwardedPort)
+	}
+
+	return node
+}
@@ -0,0 +1,87 @@
+package settings
+
+import (
+	"net"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_VPN_validateTunnels(t *testing.T) {
+	t.Parallel()
+
+	newTunnel := func() VPNTunnel {
+		return VPNTunnel{
+			PrivateKey: "+CT2emby/clyfh7kqqLz1UI8S4enfR6ceVt9LpXmTUs=",
+			PublicKey:  "FG2NGEENmKk3M2/u4cau8j/UkiAaxMeWQBlE2rEGRn4=",
+			Endpoint:   &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 51820},
+			Addresses: []net.IPNet{{
+				IP:   net.IPv4(10, 2, 0, 2),
+				Mask: net.CIDRMask(32, 32),
+			}},
+		}
+	}
+
+	settings := VPN{
+		Type:      vpn.Wireguard,
+		Wireguard: Wireguard{Interface: "wg0"},
+		Tunnels:   []VPNTunnel{newTunnel(), newTunnel()},
+	}
+	settings.setDefaults()
+	assert.Equal(t, "tunnel1", settings.Tunnels[0].Interface)
+	assert.Equal(t, uint32(51831), settings.Tunnels[0].FirewallMark)
+	assert.Equal(t, "tunnel2", settings.Tunnels[1].Interface)
+	assert.Equal(t, uint32(51832), settings.Tunnels[1].FirewallMark)
+	assert.NoError(t, settings.validateTunnels())
+
+	settings.Tunnels[1].Interface = "wg0"
+	err := settings.validateTunnels()
+	assert.ErrorIs(t, err, ErrVPNTunnelInterfaceUsed)
+
+	settings.Tunnels[1].Interface = "tunnel2"
+	settings.Tunnels[1].FirewallMark = 51831
+	err = settings.validateTunnels()
+	assert.ErrorIs(t, err, ErrVPNTunnelFirewallMarkUsed)
+
+	for _, mark := range []uint32{51820, 51902} {
+		settings.Tunnels[1].FirewallMark = mark
+		err = settings.validateTunnels()
+		assert.ErrorIs(t, err, ErrVPNTunnelFirewallMarkUsed)
+	}
+
+	settings.Hops = []VPNHop{{}}
+	settings.Tunnels[1].FirewallMark = 51811
+	err = settings.validateTunnels()
+	assert.ErrorIs(t, err, ErrVPNTunnelFirewallMarkUsed)
+	assert.EqualError(t, err, "tunnel 2 of 2: tunnel firewall mark is already used: 51811 by hop 1")
+	settings.Hops = nil
+
+	settings.Tunnels[1].FirewallMark = 51832
+	settings.Tunnels[1].SourceSubnets = []net.IPNet{{
+		IP:   net.ParseIP("fd00::"),
+		Mask: net.CIDRMask(64, 128),
+	}}
+	err = settings.validateTunnels()
+	assert.ErrorIs(t, err, ErrVPNTunnelSourceSubnetNotIPv4)
+
+	settings.Tunnels[1].SourceSubnets = nil
+	settings.Tunnels[1].SourcePorts = []uint16{0}
+	err = settings.validateTunnels()
+	assert.ErrorIs(t, err, ErrVPNTunnelSourcePortZero)
+}
+
+func Test_VPN_validateTunnelsBypassMark(t *testing.T) {
+	t.Parallel()
+
+	settings := VPN{
+		Tunnels: []VPNTunnel{{FirewallMark: 51831}},
+	}
+
+	assert.NoError(t, settings.validateTunnelsBypassMark(0))
+	assert.NoError(t, settings.validateTunnelsBypassMark(1))
+
+	err := settings.validateTunnelsBypassMark(51831)
+	assert.ErrorIs(t, err, ErrVPNTunnelFirewallMarkUsed)
+	assert.EqualError(t, err, "tunnel 1 of 1: tunnel firewall mark is already used: 51831 by the firewall bypass")
+}
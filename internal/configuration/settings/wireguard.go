@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"regexp"
+	"time"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
 	"github.com/qdm12/gluetun/internal/constants/providers"
@@ -32,6 +33,24 @@ type Wireguard struct {
 	// It defaults to "auto" and cannot be the empty string
 	// in the internal state.
 	Implementation string
+	// PersistentKeepaliveInterval is the interval between
+	// keepalive packets sent to the VPN server peer, and 0
+	// disables them. It cannot be nil in the internal state.
+	PersistentKeepaliveInterval *time.Duration
+	// Peers are additional peers to set on the Wireguard
+	// interface, each with their own allowed IPs.
+	Peers []WireguardPeer
+	// EndpointResolvePeriod is the period between each resolution
+	// of the additional peers endpoint hostnames, and 0 disables
+	// re-resolving them. It cannot be nil in the internal state.
+	EndpointResolvePeriod *time.Duration
+	// Obfuscation contains settings to obfuscate the Wireguard
+	// traffic, by relaying it through a local wrapper.
+	Obfuscation WireguardObfuscation
+	// WARPLicenseKey is the WARP+ license key to apply to the
+	// Cloudflare WARP device. It can be the empty string to use
+	// the free WARP service, and cannot be nil in the internal state.
+	WARPLicenseKey *string
 }
 
 var regexpInterfaceName = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
@@ -40,10 +59,14 @@ var regexpInterfaceName = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
 // It should only be ran if the VPN type chosen is Wireguard.
 func (w Wireguard) validate(vpnProvider string, ipv6Supported bool) (err error) {
 	if !helpers.IsOneOf(vpnProvider,
+		providers.CloudflareWARP,
 		providers.Custom,
 		providers.Ivpn,
 		providers.Mullvad,
+		providers.Ovpn,
+		providers.Plugin,
 		providers.Surfshark,
+		providers.UserDefined,
 		providers.Windscribe,
 	) {
 		// do not validate for VPN provider not supporting Wireguard
@@ -102,16 +125,44 @@ func (w Wireguard) validate(vpnProvider string, ipv6Supported bool) (err error)
 			w.Implementation, helpers.ChoicesOrString(validImplementations))
 	}
 
+	if *w.PersistentKeepaliveInterval < 0 {
+		return fmt.Errorf("%w: %s", ErrWireguardKeepaliveNegative,
+			*w.PersistentKeepaliveInterval)
+	}
+
+	const minResolvePeriod = 10 * time.Second
+	if *w.EndpointResolvePeriod != 0 && *w.EndpointResolvePeriod < minResolvePeriod {
+		return fmt.Errorf("%w: %s must be at least %s", ErrWireguardResolvePeriodTooSmall,
+			*w.EndpointResolvePeriod, minResolvePeriod)
+	}
+
+	for i, peer := range w.Peers {
+		err = peer.validate(ipv6Supported)
+		if err != nil {
+			return fmt.Errorf("peer %d of %d: %w", i+1, len(w.Peers), err)
+		}
+	}
+
+	err = w.Obfuscation.validate()
+	if err != nil {
+		return fmt.Errorf("obfuscation settings: %w", err)
+	}
+
 	return nil
 }
 
 func (w *Wireguard) copy() (copied Wireguard) {
 	return Wireguard{
-		PrivateKey:     helpers.CopyStringPtr(w.PrivateKey),
-		PreSharedKey:   helpers.CopyStringPtr(w.PreSharedKey),
-		Addresses:      helpers.CopyIPNetSlice(w.Addresses),
-		Interface:      w.Interface,
-		Implementation: w.Implementation,
+		PrivateKey:                  helpers.CopyStringPtr(w.PrivateKey),
+		PreSharedKey:                helpers.CopyStringPtr(w.PreSharedKey),
+		Addresses:                   helpers.CopyIPNetSlice(w.Addresses),
+		Interface:                   w.Interface,
+		Implementation:              w.Implementation,
+		PersistentKeepaliveInterval: helpers.CopyDurationPtr(w.PersistentKeepaliveInterval),
+		Peers:                       copyWireguardPeers(w.Peers),
+		EndpointResolvePeriod:       helpers.CopyDurationPtr(w.EndpointResolvePeriod),
+		Obfuscation:                 w.Obfuscation.copy(),
+		WARPLicenseKey:              helpers.CopyStringPtr(w.WARPLicenseKey),
 	}
 }
 
@@ -121,6 +172,15 @@ func (w *Wireguard) mergeWith(other Wireguard) {
 	w.Addresses = helpers.MergeIPNetsSlices(w.Addresses, other.Addresses)
 	w.Interface = helpers.MergeWithString(w.Interface, other.Interface)
 	w.Implementation = helpers.MergeWithString(w.Implementation, other.Implementation)
+	w.PersistentKeepaliveInterval = helpers.MergeWithDurationPtr(
+		w.PersistentKeepaliveInterval, other.PersistentKeepaliveInterval)
+	if w.Peers == nil {
+		w.Peers = copyWireguardPeers(other.Peers)
+	}
+	w.EndpointResolvePeriod = helpers.MergeWithDurationPtr(
+		w.EndpointResolvePeriod, other.EndpointResolvePeriod)
+	w.Obfuscation.mergeWith(other.Obfuscation)
+	w.WARPLicenseKey = helpers.MergeWithStringPtr(w.WARPLicenseKey, other.WARPLicenseKey)
 }
 
 func (w *Wireguard) overrideWith(other Wireguard) {
@@ -129,6 +189,15 @@ func (w *Wireguard) overrideWith(other Wireguard) {
 	w.Addresses = helpers.OverrideWithIPNetsSlice(w.Addresses, other.Addresses)
 	w.Interface = helpers.OverrideWithString(w.Interface, other.Interface)
 	w.Implementation = helpers.OverrideWithString(w.Implementation, other.Implementation)
+	w.PersistentKeepaliveInterval = helpers.OverrideWithDurationPtr(
+		w.PersistentKeepaliveInterval, other.PersistentKeepaliveInterval)
+	if other.Peers != nil {
+		w.Peers = copyWireguardPeers(other.Peers)
+	}
+	w.EndpointResolvePeriod = helpers.OverrideWithDurationPtr(
+		w.EndpointResolvePeriod, other.EndpointResolvePeriod)
+	w.Obfuscation.overrideWith(other.Obfuscation)
+	w.WARPLicenseKey = helpers.OverrideWithStringPtr(w.WARPLicenseKey, other.WARPLicenseKey)
 }
 
 func (w *Wireguard) setDefaults() {
@@ -136,6 +205,11 @@ func (w *Wireguard) setDefaults() {
 	w.PreSharedKey = helpers.DefaultStringPtr(w.PreSharedKey, "")
 	w.Interface = helpers.DefaultString(w.Interface, "wg0")
 	w.Implementation = helpers.DefaultString(w.Implementation, "auto")
+	w.PersistentKeepaliveInterval = helpers.DefaultDurationPtr(w.PersistentKeepaliveInterval, 0)
+	const defaultResolvePeriod = 5 * time.Minute
+	w.EndpointResolvePeriod = helpers.DefaultDurationPtr(w.EndpointResolvePeriod, defaultResolvePeriod)
+	w.Obfuscation.setDefaults()
+	w.WARPLicenseKey = helpers.DefaultStringPtr(w.WARPLicenseKey, "")
 }
 
 func (w Wireguard) String() string {
@@ -166,5 +240,38 @@ func (w Wireguard) toLinesNode() (node *gotree.Node) {
 		node.Appendf("Implementation: %s", w.Implementation)
 	}
 
+	if *w.PersistentKeepaliveInterval > 0 {
+		node.Appendf("Persistent keepalive interval: %s", *w.PersistentKeepaliveInterval)
+	}
+
+	if len(w.Peers) > 0 {
+		peersNode := node.Appendf("Additional peers:")
+		for _, peer := range w.Peers {
+			peersNode.AppendNode(peer.toLinesNode())
+		}
+		if hasPeerEndpointHostname(w.Peers) {
+			resolvePeriod := "disabled"
+			if *w.EndpointResolvePeriod > 0 {
+				resolvePeriod = w.EndpointResolvePeriod.String()
+			}
+			node.Appendf("Peer endpoints resolve period: %s", resolvePeriod)
+		}
+	}
+
+	node.AppendNode(w.Obfuscation.toLinesNode())
+
+	if *w.WARPLicenseKey != "" {
+		node.Appendf("WARP+ license key: %s", helpers.ObfuscatePassword(*w.WARPLicenseKey))
+	}
+
 	return node
 }
+
+func hasPeerEndpointHostname(peers []WireguardPeer) bool {
+	for _, peer := range peers {
+		if peer.EndpointHostname != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package settings
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+)
+
+const (
+	obfuscationNone        = "none"
+	obfuscationShadowsocks = "shadowsocks"
+)
+
+// WireguardObfuscation contains settings to encapsulate the
+// Wireguard UDP traffic to defeat deep packet inspection.
+type WireguardObfuscation struct {
+	// Type is the obfuscation type, which can be "none"
+	// or "shadowsocks" to relay the Wireguard datagrams
+	// through a Shadowsocks server. It defaults to "none"
+	// and cannot be the empty string in the internal state.
+	Type string
+	// Shadowsocks is the Shadowsocks server to relay the
+	// Wireguard datagrams through, if Type is "shadowsocks".
+	Shadowsocks ShadowsocksClient
+}
+
+func (w WireguardObfuscation) validate() (err error) {
+	validTypes := []string{obfuscationNone, obfuscationShadowsocks}
+	if !helpers.IsOneOf(w.Type, validTypes...) {
+		return fmt.Errorf("%w: %s must be one of %s", ErrWireguardObfuscationNotValid,
+			w.Type, helpers.ChoicesOrString(validTypes))
+	}
+
+	if w.Type == obfuscationShadowsocks {
+		err = w.Shadowsocks.validate()
+		if err != nil {
+			return fmt.Errorf("Shadowsocks settings: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Enabled returns true if the Wireguard traffic is obfuscated.
+func (w WireguardObfuscation) Enabled() bool {
+	return w.Type != obfuscationNone
+}
+
+func (w *WireguardObfuscation) copy() (copied WireguardObfuscation) {
+	return WireguardObfuscation{
+		Type:        w.Type,
+		Shadowsocks: w.Shadowsocks.copy(),
+	}
+}
+
+func (w *WireguardObfuscation) mergeWith(other WireguardObfuscation) {
+	w.Type = helpers.MergeWithString(w.Type, other.Type)
+	w.Shadowsocks.mergeWith(other.Shadowsocks)
+}
+
+func (w *WireguardObfuscation) overrideWith(other WireguardObfuscation) {
+	w.Type = helpers.OverrideWithString(w.Type, other.Type)
+	w.Shadowsocks.overrideWith(other.Shadowsocks)
+}
+
+func (w *WireguardObfuscation) setDefaults() {
+	w.Type = helpers.DefaultString(w.Type, obfuscationNone)
+	w.Shadowsocks.setDefaults()
+}
+
+func (w WireguardObfuscation) String() string {
+	return w.toLinesNode().String()
+}
+
+func (w WireguardObfuscation) toLinesNode() (node *gotree.Node) {
+	if !w.Enabled() {
+		return nil
+	}
+	node = gotree.New("Obfuscation settings:")
+	node.Appendf("Type: %s", w.Type)
+	node.AppendNode(w.Shadowsocks.toLinesNode())
+	return node
+}
@@ -0,0 +1,134 @@
+package settings
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// WireguardPeer contains settings for an additional Wireguard
+// peer on the Wireguard interface, for example to reach a home
+// subnet through a personal Wireguard server.
+type WireguardPeer struct {
+	// PublicKey is the public key of the peer.
+	// It cannot be the empty string.
+	PublicKey string
+	// PreSharedKey is the pre-shared key for the peer.
+	// It can be the empty string to indicate there
+	// is no pre-shared key.
+	PreSharedKey string
+	// Endpoint is the UDP address of the peer.
+	// It can be nil if the peer initiates the connection.
+	// Its IP address is left empty if EndpointHostname is set.
+	// Note the firewall must allow traffic to the endpoint,
+	// for example with FIREWALL_OUTBOUND_SUBNETS.
+	Endpoint *net.UDPAddr
+	// EndpointHostname is the hostname of the peer endpoint,
+	// resolved when connecting and periodically afterwards.
+	// It can be the empty string if the endpoint is an IP address.
+	EndpointHostname string
+	// AllowedIPs are the IP networks routed to the peer
+	// and accepted from the peer. It cannot be empty.
+	AllowedIPs []net.IPNet
+	// PersistentKeepaliveInterval is the interval between
+	// keepalive packets sent to the peer, and 0 disables them.
+	PersistentKeepaliveInterval time.Duration
+}
+
+func (w WireguardPeer) validate(ipv6Supported bool) (err error) {
+	_, err = wgtypes.ParseKey(w.PublicKey)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrWireguardPublicKeyNotValid, w.PublicKey)
+	}
+
+	if w.PreSharedKey != "" {
+		_, err = wgtypes.ParseKey(w.PreSharedKey)
+		if err != nil {
+			return fmt.Errorf("pre-shared key is not valid: %w", err)
+		}
+	}
+
+	if w.EndpointHostname != "" && (w.Endpoint == nil || w.Endpoint.Port == 0) {
+		return fmt.Errorf("%w: for endpoint %s", ErrWireguardEndpointPortNotSet, w.EndpointHostname)
+	} else if w.Endpoint != nil && w.Endpoint.Port == 0 {
+		return fmt.Errorf("%w: for endpoint %s", ErrWireguardEndpointPortNotSet, w.Endpoint.IP)
+	}
+
+	if len(w.AllowedIPs) == 0 {
+		return fmt.Errorf("%w", ErrWireguardPeerAllowedIPsNotSet)
+	}
+	for _, ipNet := range w.AllowedIPs {
+		ipv6Net := ipNet.IP.To4() == nil
+		if ipv6Net && !ipv6Supported {
+			return fmt.Errorf("%w: allowed IP network %s",
+				ErrWireguardPeerAllowedIPv6, ipNet.String())
+		}
+	}
+
+	if w.PersistentKeepaliveInterval < 0 {
+		return fmt.Errorf("%w: %s", ErrWireguardKeepaliveNegative,
+			w.PersistentKeepaliveInterval)
+	}
+
+	return nil
+}
+
+func (w WireguardPeer) copy() (copied WireguardPeer) {
+	var endpoint *net.UDPAddr
+	if w.Endpoint != nil {
+		endpoint = &net.UDPAddr{
+			IP:   helpers.CopyIP(w.Endpoint.IP),
+			Port: w.Endpoint.Port,
+		}
+	}
+	return WireguardPeer{
+		PublicKey:                   w.PublicKey,
+		PreSharedKey:                w.PreSharedKey,
+		Endpoint:                    endpoint,
+		EndpointHostname:            w.EndpointHostname,
+		AllowedIPs:                  helpers.CopyIPNetSlice(w.AllowedIPs),
+		PersistentKeepaliveInterval: w.PersistentKeepaliveInterval,
+	}
+}
+
+func copyWireguardPeers(original []WireguardPeer) (copied []WireguardPeer) {
+	if original == nil {
+		return nil
+	}
+	copied = make([]WireguardPeer, len(original))
+	for i, peer := range original {
+		copied[i] = peer.copy()
+	}
+	return copied
+}
+
+func (w WireguardPeer) toLinesNode() (node *gotree.Node) {
+	node = gotree.New("Peer %s:", w.PublicKey)
+
+	if w.PreSharedKey != "" {
+		s := helpers.ObfuscateWireguardKey(w.PreSharedKey)
+		node.Appendf("Pre-shared key: %s", s)
+	}
+
+	switch {
+	case w.EndpointHostname != "":
+		node.Appendf("Endpoint: %s", net.JoinHostPort(w.EndpointHostname, fmt.Sprint(w.Endpoint.Port)))
+	case w.Endpoint != nil:
+		node.Appendf("Endpoint: %s", w.Endpoint)
+	}
+
+	allowedIPsNode := node.Appendf("Allowed IPs:")
+	for _, ipNet := range w.AllowedIPs {
+		allowedIPsNode.Appendf(ipNet.String())
+	}
+
+	if w.PersistentKeepaliveInterval > 0 {
+		node.Appendf("Persistent keepalive interval: %s", w.PersistentKeepaliveInterval)
+	}
+
+	return node
+}
@@ -36,8 +36,9 @@ type WireguardSelection struct {
 func (w WireguardSelection) validate(vpnProvider string) (err error) {
 	// Validate EndpointIP
 	switch vpnProvider {
-	case providers.Airvpn, providers.Ivpn, providers.Mullvad,
-		providers.Surfshark, providers.Windscribe:
+	case providers.Airvpn, providers.CloudflareWARP, providers.Ivpn,
+		providers.Mullvad, providers.Ovpn, providers.Plugin,
+		providers.Surfshark, providers.UserDefined, providers.Windscribe:
 		// endpoint IP addresses are baked in
 	case providers.Custom:
 		if len(w.EndpointIP) == 0 {
@@ -58,18 +59,25 @@ func (w WireguardSelection) validate(vpnProvider string) (err error) {
 		if *w.EndpointPort != 0 {
 			return fmt.Errorf("%w", ErrWireguardEndpointPortSet)
 		}
-	case providers.Airvpn, providers.Ivpn, providers.Mullvad, providers.Windscribe:
+	case providers.Airvpn, providers.CloudflareWARP, providers.Ivpn,
+		providers.Mullvad, providers.Ovpn, providers.Plugin,
+		providers.UserDefined, providers.Windscribe:
 		// EndpointPort is optional and can be 0
 		if *w.EndpointPort == 0 {
 			break // no custom endpoint port set
 		}
-		if vpnProvider == providers.Mullvad {
+		if helpers.IsOneOf(vpnProvider, providers.Mullvad,
+			providers.Plugin, providers.UserDefined) {
 			break // no restriction on custom endpoint port value
 		}
 		var allowed []uint16
 		switch vpnProvider {
 		case providers.Airvpn:
 			allowed = []uint16{1637, 47107}
+		case providers.CloudflareWARP:
+			allowed = []uint16{500, 1701, 2408, 4500}
+		case providers.Ovpn:
+			allowed = []uint16{9929}
 		case providers.Ivpn:
 			allowed = []uint16{2049, 2050, 53, 30587, 41893, 48574, 58237}
 		case providers.Windscribe:
@@ -87,8 +95,9 @@ func (w WireguardSelection) validate(vpnProvider string) (err error) {
 
 	// Validate PublicKey
 	switch vpnProvider {
-	case providers.Ivpn, providers.Mullvad,
-		providers.Surfshark, providers.Windscribe:
+	case providers.CloudflareWARP, providers.Ivpn, providers.Mullvad,
+		providers.Ovpn, providers.Plugin, providers.Surfshark,
+		providers.UserDefined, providers.Windscribe:
 		// public keys are baked in
 	case providers.Custom:
 		if w.PublicKey == "" {
@@ -0,0 +1,281 @@
+package settings
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+	"github.com/qdm12/gotree"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// WireguardServer contains settings to run a Wireguard server,
+// for remote devices to connect to gluetun and have their traffic
+// go out through the VPN tunnel.
+type WireguardServer struct {
+	// Enabled is true if the Wireguard server should run,
+	// and false otherwise. It cannot be nil in the internal state.
+	Enabled *bool
+	// Interface is the name of the Wireguard server interface
+	// to create. It cannot be the empty string in the internal state.
+	Interface string
+	// ListeningPort is the UDP port the Wireguard server listens on.
+	// It cannot be nil or 0 in the internal state.
+	ListeningPort *uint16
+	// PrivateKey is the private key of the Wireguard server.
+	// It cannot be nil in the internal state, and cannot be
+	// the empty string if the server is enabled.
+	PrivateKey *string
+	// Address is the IPv4 address and network of the Wireguard
+	// server interface, from which the peers addresses are taken.
+	// It cannot be nil in the internal state.
+	Address *net.IPNet
+	// Endpoint is the host, with an optional port, written in the
+	// client configurations for peers to reach the server. It can be
+	// the empty string to use the host the client configuration is
+	// requested with, and cannot be nil in the internal state.
+	Endpoint *string
+	// Peers are the devices allowed to connect to the server.
+	Peers []WireguardServerPeer
+}
+
+// WireguardServerPeer contains settings for a peer
+// of the Wireguard server, such as a phone or laptop.
+type WireguardServerPeer struct {
+	// Name is the name of the peer, used to fetch its client
+	// configuration. It cannot be the empty string in the
+	// internal state.
+	Name string
+	// PublicKey is the public key of the peer. It can be the
+	// empty string if PrivateKey is set, in which case it is
+	// derived from the private key.
+	PublicKey string
+	// PrivateKey is the private key of the peer, only used to write
+	// it in the client configuration. It can be the empty string,
+	// in which case the client configuration contains a placeholder.
+	PrivateKey string
+	// PreSharedKey is the pre-shared key for the peer.
+	// It can be the empty string to indicate there
+	// is no pre-shared key.
+	PreSharedKey string
+	// Address is the IPv4 address of the peer in the network of
+	// the server address. It defaults to the server address plus
+	// the peer number, and cannot be nil in the internal state.
+	Address net.IP
+}
+
+// validateDNS verifies the DNS server listens on the container
+// addresses and not only on 127.0.0.1, since the peers are given
+// the server address as their DNS server.
+func (w WireguardServer) validateDNS(dns DNS) (err error) {
+	if !*w.Enabled || *dns.LAN.Enabled {
+		return nil
+	}
+	return fmt.Errorf("%w: serving DNS to LAN clients must be enabled "+
+		"for peers to use %s as DNS server", ErrWireguardServerDNSNotServed, w.Address.IP)
+}
+
+func (w WireguardServer) validate() (err error) {
+	if !*w.Enabled {
+		return nil
+	}
+
+	if !regexpInterfaceName.MatchString(w.Interface) {
+		return fmt.Errorf("%w: '%s' does not match regex '%s'",
+			ErrWireguardInterfaceNotValid, w.Interface, regexpInterfaceName)
+	}
+
+	if *w.ListeningPort == 0 {
+		return fmt.Errorf("%w", ErrWireguardServerPortNotSet)
+	}
+
+	if *w.PrivateKey == "" {
+		return fmt.Errorf("%w", ErrWireguardPrivateKeyNotSet)
+	}
+	_, err = wgtypes.ParseKey(*w.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("private key is not valid: %w", err)
+	}
+
+	// The forwarding and NAT rules are only set for IPv4.
+	if w.Address.IP.To4() == nil {
+		return fmt.Errorf("%w: %s", ErrWireguardServerAddressNotIPv4, w.Address)
+	}
+
+	names := make(map[string]struct{}, len(w.Peers))
+	addresses := map[string]struct{}{w.Address.IP.String(): {}}
+	for _, peer := range w.Peers {
+		err = peer.validate(*w.Address)
+		if err != nil {
+			return fmt.Errorf("peer %s: %w", peer.Name, err)
+		}
+
+		if _, ok := names[peer.Name]; ok {
+			return fmt.Errorf("%w: %s", ErrWireguardServerPeerNameUsed, peer.Name)
+		}
+		names[peer.Name] = struct{}{}
+
+		address := peer.Address.String()
+		if _, ok := addresses[address]; ok {
+			return fmt.Errorf("%w: %s", ErrWireguardServerPeerAddressUsed, address)
+		}
+		addresses[address] = struct{}{}
+	}
+
+	return nil
+}
+
+func (w WireguardServerPeer) validate(serverNetwork net.IPNet) (err error) {
+	switch {
+	case w.PrivateKey != "":
+		_, err = wgtypes.ParseKey(w.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("private key is not valid: %w", err)
+		}
+	case w.PublicKey == "":
+		return fmt.Errorf("%w", ErrWireguardPublicKeyNotSet)
+	}
+
+	if w.PublicKey != "" {
+		_, err = wgtypes.ParseKey(w.PublicKey)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrWireguardPublicKeyNotValid, w.PublicKey)
+		}
+	}
+
+	if w.PreSharedKey != "" {
+		_, err = wgtypes.ParseKey(w.PreSharedKey)
+		if err != nil {
+			return fmt.Errorf("pre-shared key is not valid: %w", err)
+		}
+	}
+
+	if w.Address == nil || !serverNetwork.Contains(w.Address) {
+		return fmt.Errorf("%w: %s is not in %s",
+			ErrWireguardServerPeerAddressNotValid, w.Address, serverNetwork.String())
+	}
+
+	return nil
+}
+
+func (w *WireguardServer) copy() (copied WireguardServer) {
+	return WireguardServer{
+		Enabled:       helpers.CopyBoolPtr(w.Enabled),
+		Interface:     w.Interface,
+		ListeningPort: helpers.CopyUint16Ptr(w.ListeningPort),
+		PrivateKey:    helpers.CopyStringPtr(w.PrivateKey),
+		Address:       helpers.CopyIPNetPtr(w.Address),
+		Endpoint:      helpers.CopyStringPtr(w.Endpoint),
+		Peers:         copyWireguardServerPeers(w.Peers),
+	}
+}
+
+func copyWireguardServerPeers(original []WireguardServerPeer) (copied []WireguardServerPeer) {
+	if original == nil {
+		return nil
+	}
+	copied = make([]WireguardServerPeer, len(original))
+	for i, peer := range original {
+		copied[i] = peer
+		copied[i].Address = helpers.CopyIP(peer.Address)
+	}
+	return copied
+}
+
+// mergeWith merges the other settings into any
+// unset field of the receiver settings object.
+func (w *WireguardServer) mergeWith(other WireguardServer) {
+	w.Enabled = helpers.MergeWithBool(w.Enabled, other.Enabled)
+	w.Interface = helpers.MergeWithString(w.Interface, other.Interface)
+	w.ListeningPort = helpers.MergeWithUint16(w.ListeningPort, other.ListeningPort)
+	w.PrivateKey = helpers.MergeWithStringPtr(w.PrivateKey, other.PrivateKey)
+	if w.Address == nil {
+		w.Address = helpers.CopyIPNetPtr(other.Address)
+	}
+	w.Endpoint = helpers.MergeWithStringPtr(w.Endpoint, other.Endpoint)
+	if w.Peers == nil {
+		w.Peers = copyWireguardServerPeers(other.Peers)
+	}
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (w *WireguardServer) overrideWith(other WireguardServer) {
+	w.Enabled = helpers.OverrideWithBool(w.Enabled, other.Enabled)
+	w.Interface = helpers.OverrideWithString(w.Interface, other.Interface)
+	w.ListeningPort = helpers.OverrideWithUint16(w.ListeningPort, other.ListeningPort)
+	w.PrivateKey = helpers.OverrideWithStringPtr(w.PrivateKey, other.PrivateKey)
+	if other.Address != nil {
+		w.Address = helpers.CopyIPNetPtr(other.Address)
+	}
+	w.Endpoint = helpers.OverrideWithStringPtr(w.Endpoint, other.Endpoint)
+	if other.Peers != nil {
+		w.Peers = copyWireguardServerPeers(other.Peers)
+	}
+}
+
+func (w *WireguardServer) setDefaults() {
+	w.Enabled = helpers.DefaultBool(w.Enabled, false)
+	w.Interface = helpers.DefaultString(w.Interface, "wgs0")
+	const defaultListeningPort = 51820
+	w.ListeningPort = helpers.DefaultUint16(w.ListeningPort, defaultListeningPort)
+	w.PrivateKey = helpers.DefaultStringPtr(w.PrivateKey, "")
+	if w.Address == nil {
+		const defaultOnes, bits = 24, 32
+		w.Address = &net.IPNet{
+			IP:   net.IPv4(10, 13, 13, 1).To4(), //nolint:gomnd
+			Mask: net.CIDRMask(defaultOnes, bits),
+		}
+	}
+	w.Endpoint = helpers.DefaultStringPtr(w.Endpoint, "")
+	for i := range w.Peers {
+		if w.Peers[i].PublicKey == "" && w.Peers[i].PrivateKey != "" {
+			privateKey, err := wgtypes.ParseKey(w.Peers[i].PrivateKey)
+			if err == nil { // invalid private key caught in validation
+				w.Peers[i].PublicKey = privateKey.PublicKey().String()
+			}
+		}
+		w.Peers[i].Name = helpers.DefaultString(w.Peers[i].Name, fmt.Sprint("peer", i+1))
+		if w.Peers[i].Address == nil && w.Address.IP.To4() != nil {
+			ip := binary.BigEndian.Uint32(w.Address.IP.To4()) + uint32(i) + 1
+			w.Peers[i].Address = make(net.IP, net.IPv4len)
+			binary.BigEndian.PutUint32(w.Peers[i].Address, ip)
+		}
+	}
+}
+
+func (w WireguardServer) String() string {
+	return w.toLinesNode().String()
+}
+
+func (w WireguardServer) toLinesNode() (node *gotree.Node) {
+	if !*w.Enabled {
+		return nil
+	}
+
+	node = gotree.New("Wireguard server settings:")
+	node.Appendf("Interface: %s", w.Interface)
+	node.Appendf("Listening port: %d", *w.ListeningPort)
+	node.Appendf("Private key: %s", helpers.ObfuscateWireguardKey(*w.PrivateKey))
+	node.Appendf("Address: %s", w.Address)
+	if *w.Endpoint != "" {
+		node.Appendf("Endpoint: %s", *w.Endpoint)
+	}
+
+	peersNode := node.Appendf("Peers:")
+	if len(w.Peers) == 0 {
+		peersNode.Appendf("none")
+	}
+	for _, peer := range w.Peers {
+		peerNode := peersNode.Appendf("%s:", peer.Name)
+		peerNode.Appendf("Public key: %s", peer.PublicKey)
+		if peer.PreSharedKey != "" {
+			peerNode.Appendf("Pre-shared key: %s", helpers.ObfuscateWireguardKey(peer.PreSharedKey))
+		}
+		peerNode.Appendf("Address: %s", peer.Address)
+	}
+
+	return node
+}
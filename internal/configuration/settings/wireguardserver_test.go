@@ -0,0 +1,79 @@
+package settings
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WireguardServer_setDefaults(t *testing.T) {
+	t.Parallel()
+
+	settings := WireguardServer{
+		Enabled:    boolPtr(true),
+		PrivateKey: stringPtr("+CT2emby/clyfh7kqqLz1UI8S4enfR6ceVt9LpXmTUs="),
+		Peers: []WireguardServerPeer{
+			{PrivateKey: "mLfk2AhUMnPONaVdqyDsOILB41UuFCIegvs4jWhCUko="},
+			{Name: "laptop", PublicKey: "PHd+VPz9qsYg4VeX/XiYvb5XeAaepEt6qkqk0Bakb0Q="},
+		},
+	}
+
+	settings.setDefaults()
+
+	require.Len(t, settings.Peers, 2)
+	assert.Equal(t, "peer1", settings.Peers[0].Name)
+	assert.Equal(t, "FG2NGEENmKk3M2/u4cau8j/UkiAaxMeWQBlE2rEGRn4=", settings.Peers[0].PublicKey)
+	assert.Equal(t, net.IPv4(10, 13, 13, 2).To4(), settings.Peers[0].Address)
+	assert.Equal(t, "laptop", settings.Peers[1].Name)
+	assert.Equal(t, net.IPv4(10, 13, 13, 3).To4(), settings.Peers[1].Address)
+	assert.NoError(t, settings.validate())
+}
+
+func Test_WireguardServer_validate(t *testing.T) {
+	t.Parallel()
+
+	settings := WireguardServer{
+		Enabled:    boolPtr(true),
+		PrivateKey: stringPtr("+CT2emby/clyfh7kqqLz1UI8S4enfR6ceVt9LpXmTUs="),
+		Peers: []WireguardServerPeer{
+			{PublicKey: "FG2NGEENmKk3M2/u4cau8j/UkiAaxMeWQBlE2rEGRn4=", Address: net.IPv4(10, 13, 13, 2)},
+			{PublicKey: "PHd+VPz9qsYg4VeX/XiYvb5XeAaepEt6qkqk0Bakb0Q=", Address: net.IPv4(10, 13, 13, 2)},
+		},
+	}
+	settings.setDefaults()
+	err := settings.validate()
+	assert.ErrorIs(t, err, ErrWireguardServerPeerAddressUsed)
+
+	settings.Peers[1].Address = net.IPv4(10, 13, 14, 2)
+	err = settings.validate()
+	assert.ErrorIs(t, err, ErrWireguardServerPeerAddressNotValid)
+
+	settings.Peers[1].Address = net.IPv4(10, 13, 13, 3)
+	settings.Peers[1].Name = "peer1"
+	err = settings.validate()
+	assert.ErrorIs(t, err, ErrWireguardServerPeerNameUsed)
+}
+
+func Test_WireguardServer_validateDNS(t *testing.T) {
+	t.Parallel()
+
+	settings := WireguardServer{
+		Enabled:    boolPtr(true),
+		PrivateKey: stringPtr("+CT2emby/clyfh7kqqLz1UI8S4enfR6ceVt9LpXmTUs="),
+	}
+	settings.setDefaults()
+	var dns DNS
+	dns.setDefaults()
+	assert.NoError(t, settings.validateDNS(dns))
+
+	dns.LAN.Enabled = boolPtr(false)
+	err := settings.validateDNS(dns)
+	assert.ErrorIs(t, err, ErrWireguardServerDNSNotServed)
+	assert.EqualError(t, err, "DNS is not served to peers: serving DNS to LAN clients "+
+		"must be enabled for peers to use 10.13.13.1 as DNS server")
+
+	settings.Enabled = boolPtr(false)
+	assert.NoError(t, settings.validateDNS(dns))
+}
@@ -0,0 +1,21 @@
+package env
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readBandwidth() (bandwidth settings.Bandwidth, err error) {
+	bandwidth.Resolution, err = envToDurationPtr("BANDWIDTH_RESOLUTION")
+	if err != nil {
+		return bandwidth, fmt.Errorf("environment variable BANDWIDTH_RESOLUTION: %w", err)
+	}
+
+	bandwidth.Window, err = envToDurationPtr("BANDWIDTH_WINDOW")
+	if err != nil {
+		return bandwidth, fmt.Errorf("environment variable BANDWIDTH_WINDOW: %w", err)
+	}
+
+	return bandwidth, nil
+}
@@ -0,0 +1,91 @@
+package env
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultConfigFile is the default path of the configuration file
+// holding environment variables, for gluetun running directly on a
+// host without a container environment to set them.
+const DefaultConfigFile = "/etc/gluetun/gluetun.env"
+
+var ErrConfigFileLineMalformed = errors.New("configuration file line is not in the format KEY=value")
+
+// LoadConfigFile sets the environment variables defined in the
+// configuration file at the path given by the CONFIG_FILE environment
+// variable, or at DefaultConfigFile if it is not set. Environment
+// variables already set take precedence over the file values.
+// It returns false without error if the default file does not exist.
+func LoadConfigFile() (path string, loaded bool, err error) {
+	path = os.Getenv("CONFIG_FILE")
+	explicit := path != ""
+	if !explicit {
+		path = DefaultConfigFile
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return path, false, nil
+		}
+		return path, false, fmt.Errorf("opening configuration file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		key, value, ok, err := parseConfigFileLine(scanner.Text())
+		if err != nil {
+			return path, false, fmt.Errorf("line %d: %w", lineNumber, err)
+		} else if !ok {
+			continue
+		}
+
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		err = os.Setenv(key, value)
+		if err != nil {
+			return path, false, fmt.Errorf("setting environment variable %s: %w", key, err)
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return path, false, fmt.Errorf("reading configuration file: %w", err)
+	}
+
+	return path, true, file.Close()
+}
+
+// parseConfigFileLine parses a line in the format KEY=value, optionally
+// prefixed with export and with the value quoted, as for a systemd
+// environment file or a shell script. It returns false for empty and
+// comment lines.
+func parseConfigFileLine(line string) (key, value string, ok bool, err error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false, nil
+	}
+	line = strings.TrimPrefix(line, "export ")
+
+	key, value, found := strings.Cut(line, "=")
+	key = strings.TrimSpace(key)
+	if !found || key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", false, fmt.Errorf("%w: %s", ErrConfigFileLineMalformed, line)
+	}
+
+	value = strings.TrimSpace(value)
+	const minQuotedLength = 2
+	if len(value) >= minQuotedLength &&
+		(value[0] == '"' || value[0] == '\'') &&
+		value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+
+	return key, value, true, nil
+}
@@ -0,0 +1,91 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseConfigFileLine(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		line       string
+		key        string
+		value      string
+		ok         bool
+		errWrapped error
+	}{
+		"empty": {},
+		"comment": {
+			line: "  # VPN_SERVICE_PROVIDER=mullvad",
+		},
+		"key value": {
+			line:  "VPN_SERVICE_PROVIDER=mullvad",
+			key:   "VPN_SERVICE_PROVIDER",
+			value: "mullvad",
+			ok:    true,
+		},
+		"export and quotes": {
+			line:  `export SERVER_CITIES = "New York, Paris"`,
+			key:   "SERVER_CITIES",
+			value: "New York, Paris",
+			ok:    true,
+		},
+		"empty value": {
+			line: "TZ=",
+			key:  "TZ",
+			ok:   true,
+		},
+		"missing equal sign": {
+			line:       "VPN_SERVICE_PROVIDER",
+			errWrapped: ErrConfigFileLineMalformed,
+		},
+		"key with space": {
+			line:       "VPN SERVICE=mullvad",
+			errWrapped: ErrConfigFileLineMalformed,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			key, value, ok, err := parseConfigFileLine(testCase.line)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			assert.Equal(t, testCase.key, key)
+			assert.Equal(t, testCase.value, value)
+			assert.Equal(t, testCase.ok, ok)
+		})
+	}
+}
+
+func Test_LoadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gluetun.env")
+	const content = "# Gluetun settings\n" +
+		"GLUETUN_TEST_FROM_FILE=file\n" +
+		"GLUETUN_TEST_OVERRIDDEN=file\n"
+	err := os.WriteFile(path, []byte(content), 0600)
+	require.NoError(t, err)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("GLUETUN_TEST_OVERRIDDEN", "environment")
+	t.Cleanup(func() { os.Unsetenv("GLUETUN_TEST_FROM_FILE") })
+
+	loadedPath, loaded, err := LoadConfigFile()
+
+	require.NoError(t, err)
+	assert.True(t, loaded)
+	assert.Equal(t, path, loadedPath)
+	assert.Equal(t, "file", os.Getenv("GLUETUN_TEST_FROM_FILE"))
+	assert.Equal(t, "environment", os.Getenv("GLUETUN_TEST_OVERRIDDEN"))
+
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.env"))
+	_, loaded, err = LoadConfigFile()
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.False(t, loaded)
+}
@@ -0,0 +1,29 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readDDNS() (ddns settings.DDNS, err error) {
+	defer func() {
+		err = unsetEnvKeys([]string{"DDNS_TOKEN", "DDNS_PASSWORD"}, err)
+	}()
+
+	ddns.Enabled, err = envToBoolPtr("DDNS")
+	if err != nil {
+		return ddns, fmt.Errorf("environment variable DDNS: %w", err)
+	}
+
+	ddns.Provider = strings.ToLower(getCleanedEnv("DDNS_PROVIDER"))
+	ddns.Hostnames = envToCSV("DDNS_HOSTNAMES")
+	ddns.Token = envToStringPtr("DDNS_TOKEN")
+	ddns.ZoneID = envToStringPtr("DDNS_ZONE_ID")
+	ddns.Username = envToStringPtr("DDNS_USERNAME")
+	ddns.Password = envToStringPtr("DDNS_PASSWORD")
+	ddns.URL = envToStringPtr("DDNS_URL")
+
+	return ddns, nil
+}
@@ -18,11 +18,26 @@ func (s *Source) readDNS() (dns settings.DNS, err error) {
 		return dns, fmt.Errorf("environment variable DNS_KEEP_NAMESERVER: %w", err)
 	}
 
+	dns.FilterAAAA, err = envToBoolPtr("DNS_FILTER_AAAA")
+	if err != nil {
+		return dns, fmt.Errorf("environment variable DNS_FILTER_AAAA: %w", err)
+	}
+
 	dns.DoT, err = s.readDoT()
 	if err != nil {
 		return dns, fmt.Errorf("DoT settings: %w", err)
 	}
 
+	dns.LAN, err = readDNSLAN()
+	if err != nil {
+		return dns, err
+	}
+
+	dns.SecureServer, err = readSecureDNSServer()
+	if err != nil {
+		return dns, fmt.Errorf("secure DNS server settings: %w", err)
+	}
+
 	return dns, nil
 }
 
@@ -48,3 +63,17 @@ func (s *Source) readDNSServerAddress() (address net.IP, err error) {
 
 	return address, nil
 }
+
+func readDNSLAN() (lan settings.DNSLAN, err error) {
+	lan.Enabled, err = envToBoolPtr("DNS_SERVE_LAN")
+	if err != nil {
+		return lan, fmt.Errorf("environment variable DNS_SERVE_LAN: %w", err)
+	}
+
+	lan.Subnets, err = stringsToIPNets(envToCSV("DNS_LAN_SUBNETS"))
+	if err != nil {
+		return lan, fmt.Errorf("environment variable DNS_LAN_SUBNETS: %w", err)
+	}
+
+	return lan, nil
+}
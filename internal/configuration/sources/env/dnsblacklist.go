@@ -32,6 +32,8 @@ func (s *Source) readDNSBlacklist() (blacklist settings.DNSBlacklist, err error)
 	}
 
 	blacklist.AllowedHosts = envToCSV("UNBLOCK") // TODO v4 change name
+	blacklist.BlockListFiles = envToCSV("BLOCK_LIST_FILES")
+	blacklist.AllowListFiles = envToCSV("ALLOW_LIST_FILES")
 
 	return blacklist, nil
 }
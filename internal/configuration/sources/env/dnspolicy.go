@@ -0,0 +1,60 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"inet.af/netaddr"
+)
+
+// readDNSPolicies reads the client DNS policies from the
+// environment variables DNS_POLICY_1_SUBNETS,
+// DNS_POLICY_1_BLOCK_ADS and so on, stopping at the
+// first policy number without subnets.
+func readDNSPolicies() (policies []settings.DNSPolicy, err error) {
+	for i := 1; ; i++ {
+		prefix := "DNS_POLICY_" + fmt.Sprint(i) + "_"
+		subnetsKey := prefix + "SUBNETS"
+		subnetsCSV := getCleanedEnv(subnetsKey)
+		if subnetsCSV == "" {
+			return policies, nil
+		}
+
+		var policy settings.DNSPolicy
+		for _, subnet := range strings.Split(subnetsCSV, ",") {
+			ipPrefix, err := netaddr.ParseIPPrefix(strings.TrimSpace(subnet))
+			if err != nil {
+				return nil, fmt.Errorf("environment variable %s: %w", subnetsKey, err)
+			}
+			policy.Subnets = append(policy.Subnets, ipPrefix)
+		}
+
+		policy.BlockMalicious, err = envToBoolPtr(prefix + "BLOCK_MALICIOUS")
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s: %w", prefix+"BLOCK_MALICIOUS", err)
+		}
+
+		policy.BlockAds, err = envToBoolPtr(prefix + "BLOCK_ADS")
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s: %w", prefix+"BLOCK_ADS", err)
+		}
+
+		policy.BlockSurveillance, err = envToBoolPtr(prefix + "BLOCK_SURVEILLANCE")
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s: %w", prefix+"BLOCK_SURVEILLANCE", err)
+		}
+
+		policy.SafeSearch, err = envToBoolPtr(prefix + "SAFE_SEARCH")
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s: %w", prefix+"SAFE_SEARCH", err)
+		}
+
+		policy.LogQueries, err = envToBoolPtr(prefix + "LOG_QUERIES")
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s: %w", prefix+"LOG_QUERIES", err)
+		}
+
+		policies = append(policies, policy)
+	}
+}
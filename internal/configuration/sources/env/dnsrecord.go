@@ -0,0 +1,37 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+var ErrDNSRecordFormat = errors.New("DNS record is not in the format name=type:value")
+
+// readDNSRecords reads the DNS_RECORDS environment variable, which is
+// a comma separated list of name=type:value records, such as
+// nas.home=A:192.168.1.10,nas.home=AAAA:fd00::10,files.home=CNAME:nas.home
+func readDNSRecords() (records []settings.DNSRecord, err error) {
+	recordsCSV := getCleanedEnv("DNS_RECORDS")
+	if recordsCSV == "" {
+		return nil, nil
+	}
+
+	for _, value := range strings.Split(recordsCSV, ",") {
+		name, typeAndValue, ok := strings.Cut(strings.TrimSpace(value), "=")
+		recordType, recordValue, typeOK := strings.Cut(typeAndValue, ":")
+		if !ok || !typeOK || name == "" || recordType == "" || recordValue == "" {
+			return nil, fmt.Errorf("environment variable DNS_RECORDS: %w: %s",
+				ErrDNSRecordFormat, value)
+		}
+		records = append(records, settings.DNSRecord{
+			Name:  strings.ToLower(name),
+			Type:  strings.ToUpper(recordType),
+			Value: strings.ToLower(recordValue),
+		})
+	}
+
+	return records, nil
+}
@@ -0,0 +1,35 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+var ErrDNSRewriteFormat = errors.New("DNS rewrite is not in the format domain=target")
+
+// readDNSRewrites reads the DNS_REWRITES environment variable, which is
+// a comma separated list of domain=target rules, such as
+// nas.example.com=192.168.1.10,*.home.example.com=192.168.1.20
+func readDNSRewrites() (rewrites []settings.DNSRewrite, err error) {
+	rewritesCSV := getCleanedEnv("DNS_REWRITES")
+	if rewritesCSV == "" {
+		return nil, nil
+	}
+
+	for _, rule := range strings.Split(rewritesCSV, ",") {
+		domain, target, ok := strings.Cut(strings.TrimSpace(rule), "=")
+		if !ok || domain == "" || target == "" {
+			return nil, fmt.Errorf("environment variable DNS_REWRITES: %w: %s",
+				ErrDNSRewriteFormat, rule)
+		}
+		rewrites = append(rewrites, settings.DNSRewrite{
+			Domain: strings.ToLower(domain),
+			Target: strings.ToLower(target),
+		})
+	}
+
+	return rewrites, nil
+}
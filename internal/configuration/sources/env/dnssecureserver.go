@@ -0,0 +1,32 @@
+package env
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readSecureDNSServer() (server settings.SecureDNSServer, err error) {
+	server.Enabled, err = envToBoolPtr("DNS_SECURE_SERVER")
+	if err != nil {
+		return server, fmt.Errorf("environment variable DNS_SECURE_SERVER: %w", err)
+	}
+
+	server.DoTAddress = readDisableableAddress("DNS_SECURE_SERVER_DOT_ADDRESS")
+	server.DoHAddress = readDisableableAddress("DNS_SECURE_SERVER_DOH_ADDRESS")
+	server.CertificateFile = envToStringPtr("DNS_SECURE_SERVER_CERTIFICATE_FILE")
+	server.KeyFile = envToStringPtr("DNS_SECURE_SERVER_KEY_FILE")
+	server.Hostnames = envToCSV("DNS_SECURE_SERVER_HOSTNAMES")
+
+	return server, nil
+}
+
+// readDisableableAddress returns the listening address set in the
+// environment variable given, and the empty string if it is set to off.
+func readDisableableAddress(envKey string) (address *string) {
+	address = envToStringPtr(envKey)
+	if address != nil && *address == "off" {
+		return stringPtr("")
+	}
+	return address
+}
@@ -0,0 +1,68 @@
+package env
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/dnsstamp"
+)
+
+var ErrDNSUpstreamFormat = errors.New("DNS upstream is not in the format protocol://ip[:port]#name")
+
+// readDNSUpstreams reads the DNS_UPSTREAMS environment variable, which
+// is a comma separated list of resolvers in the format protocol://ip[:port]#name,
+// such as doq://94.140.14.14#dns.adguard-dns.com, or DNS stamps such as
+// sdns://AQcAAAAAAAAA... The port defaults to 853, or to 443 for doh.
+func readDNSUpstreams() (upstreams []settings.DNSUpstream, err error) {
+	upstreamsCSV := getCleanedEnv("DNS_UPSTREAMS")
+	if upstreamsCSV == "" {
+		return nil, nil
+	}
+
+	for _, value := range strings.Split(upstreamsCSV, ",") {
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "sdns://") {
+			stamp, err := dnsstamp.Parse(value)
+			if err != nil {
+				return nil, fmt.Errorf("environment variable DNS_UPSTREAMS: %w", err)
+			}
+			upstreams = append(upstreams, settings.DNSUpstream{
+				Protocol:   stamp.Protocol,
+				Address:    stamp.Address,
+				ServerName: stamp.ServerName,
+				PublicKey:  hex.EncodeToString(stamp.PublicKey),
+			})
+			continue
+		}
+
+		parsed, err := url.Parse(value)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || parsed.Fragment == "" {
+			return nil, fmt.Errorf("environment variable DNS_UPSTREAMS: %w: %s",
+				ErrDNSUpstreamFormat, value)
+		}
+
+		protocol := strings.ToLower(parsed.Scheme)
+		address := parsed.Host
+		if parsed.Port() == "" {
+			defaultPort := "853"
+			if protocol == constants.DNSUpstreamDoH {
+				defaultPort = "443"
+			}
+			address = net.JoinHostPort(strings.Trim(parsed.Host, "[]"), defaultPort)
+		}
+
+		upstreams = append(upstreams, settings.DNSUpstream{
+			Protocol:   protocol,
+			Address:    address,
+			ServerName: parsed.Fragment,
+		})
+	}
+
+	return upstreams, nil
+}
@@ -0,0 +1,41 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readDockerDependents() (docker settings.DockerDependents, err error) {
+	docker.Enabled, err = envToBoolPtr("DOCKER_DEPENDENTS")
+	if err != nil {
+		return docker, fmt.Errorf("environment variable DOCKER_DEPENDENTS: %w", err)
+	}
+
+	docker.Endpoint = getCleanedEnv("DOCKER_DEPENDENTS_ENDPOINT")
+	docker.Label = getCleanedEnv("DOCKER_DEPENDENTS_LABEL")
+	docker.Action = strings.ToLower(getCleanedEnv("DOCKER_DEPENDENTS_ACTION"))
+
+	return docker, nil
+}
+
+func readDockerLabels() (labels settings.DockerLabels, err error) {
+	labels.Enabled, err = envToBoolPtr("DOCKER_LABELS")
+	if err != nil {
+		return labels, fmt.Errorf("environment variable DOCKER_LABELS: %w", err)
+	}
+
+	labels.Endpoint = getCleanedEnv("DOCKER_LABELS_ENDPOINT")
+
+	if value := getCleanedEnv("DOCKER_LABELS_CONTAINER"); value != "" {
+		labels.Container = stringPtr(value)
+	}
+
+	labels.Period, err = envToDurationPtr("DOCKER_LABELS_PERIOD")
+	if err != nil {
+		return labels, fmt.Errorf("environment variable DOCKER_LABELS_PERIOD: %w", err)
+	}
+
+	return labels, nil
+}
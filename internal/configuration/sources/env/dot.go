@@ -27,5 +27,32 @@ func (s *Source) readDoT() (dot settings.DoT, err error) {
 		return dot, err
 	}
 
+	dot.Upstreams, err = readDNSUpstreams()
+	if err != nil {
+		return dot, err
+	}
+
+	dot.UpstreamsProbePeriod, err = envToDurationPtr("DNS_UPSTREAMS_PROBE_PERIOD")
+	if err != nil {
+		return dot, fmt.Errorf("environment variable DNS_UPSTREAMS_PROBE_PERIOD: %w", err)
+	}
+
+	dot.Records, err = readDNSRecords()
+	if err != nil {
+		return dot, err
+	}
+
+	dot.HostsFile = envToStringPtr("DNS_HOSTS_FILE")
+
+	dot.Rewrites, err = readDNSRewrites()
+	if err != nil {
+		return dot, err
+	}
+
+	dot.Policies, err = readDNSPolicies()
+	if err != nil {
+		return dot, err
+	}
+
 	return dot, nil
 }
@@ -0,0 +1,68 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readFailover() (failover settings.Failover, err error) {
+	defer func() {
+		err = unsetEnvKeys([]string{
+			"FAILOVER_OPENVPN_PASSWORD",
+			"FAILOVER_WIREGUARD_PRIVATE_KEY",
+			"FAILOVER_WIREGUARD_PRESHARED_KEY",
+		}, err)
+	}()
+
+	failover.Enabled, err = envToBoolPtr("FAILOVER")
+	if err != nil {
+		return failover, fmt.Errorf("environment variable FAILOVER: %w", err)
+	}
+
+	failover.Threshold, err = envToDurationPtr("FAILOVER_THRESHOLD")
+	if err != nil {
+		return failover, fmt.Errorf("environment variable FAILOVER_THRESHOLD: %w", err)
+	}
+
+	failover.VPN, err = readFailoverVPN()
+	if err != nil {
+		return failover, err // already wrapped
+	}
+
+	return failover, nil
+}
+
+// readFailoverVPN reads the secondary VPN settings, which
+// only need to contain the fields differing from the primary
+// VPN settings.
+func readFailoverVPN() (vpn settings.VPN, err error) {
+	vpn.Type = strings.ToLower(getCleanedEnv("FAILOVER_VPN_TYPE"))
+
+	vpn.Provider.Name = envToStringPtr("FAILOVER_VPN_SERVICE_PROVIDER")
+	if vpn.Provider.Name != nil {
+		*vpn.Provider.Name = strings.ToLower(*vpn.Provider.Name)
+	}
+
+	serverSelection := &vpn.Provider.ServerSelection
+	serverSelection.VPN = vpn.Type
+	serverSelection.Countries = envToCSV("FAILOVER_SERVER_COUNTRIES")
+	serverSelection.Regions = envToCSV("FAILOVER_SERVER_REGIONS")
+	serverSelection.Cities = envToCSV("FAILOVER_SERVER_CITIES")
+	serverSelection.Hostnames = envToCSV("FAILOVER_SERVER_HOSTNAMES")
+	serverSelection.Names = envToCSV("FAILOVER_SERVER_NAMES")
+
+	vpn.OpenVPN.User = envToStringPtr("FAILOVER_OPENVPN_USER")
+	vpn.OpenVPN.Password = envToStringPtr("FAILOVER_OPENVPN_PASSWORD")
+
+	vpn.Wireguard.PrivateKey = envToStringPtr("FAILOVER_WIREGUARD_PRIVATE_KEY")
+	vpn.Wireguard.PreSharedKey = envToStringPtr("FAILOVER_WIREGUARD_PRESHARED_KEY")
+	vpn.Wireguard.Addresses, err = parseWireguardAddresses("FAILOVER_WIREGUARD_ADDRESSES",
+		getCleanedEnv("FAILOVER_WIREGUARD_ADDRESSES"))
+	if err != nil {
+		return vpn, err // already wrapped
+	}
+
+	return vpn, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 )
@@ -39,6 +40,49 @@ func (s *Source) readFirewall() (firewall settings.Firewall, err error) {
 		return firewall, fmt.Errorf("environment variable FIREWALL_DEBUG: %w", err)
 	}
 
+	firewall.EBPF, err = envToBoolPtr("FIREWALL_EBPF")
+	if err != nil {
+		return firewall, fmt.Errorf("environment variable FIREWALL_EBPF: %w", err)
+	}
+
+	firewall.StartupPolicy = strings.ToLower(getCleanedEnv("FIREWALL_STARTUP_POLICY"))
+
+	firewall.StartupDuration, err = envToDurationPtr("FIREWALL_STARTUP_PERMISSIVE_DURATION")
+	if err != nil {
+		return firewall, fmt.Errorf("environment variable FIREWALL_STARTUP_PERMISSIVE_DURATION: %w", err)
+	}
+
+	firewall.Backend = strings.ToLower(getCleanedEnv("FIREWALL_BACKEND"))
+
+	firewall.VPNOutputDomains = envToCSV("FIREWALL_VPN_OUTPUT_DOMAINS")
+
+	firewall.VPNOutputDomainsRefresh, err = envToDurationPtr("FIREWALL_VPN_OUTPUT_DOMAINS_REFRESH")
+	if err != nil {
+		return firewall, fmt.Errorf("environment variable FIREWALL_VPN_OUTPUT_DOMAINS_REFRESH: %w", err)
+	}
+
+	firewall.BypassDomains = envToCSV("FIREWALL_VPN_BYPASS_DOMAINS")
+	firewall.BypassCgroups = envToCSV("FIREWALL_VPN_BYPASS_CGROUPS")
+
+	firewall.BypassMark, err = envToUint32Ptr("FIREWALL_VPN_BYPASS_MARK")
+	if err != nil {
+		return firewall, fmt.Errorf("environment variable FIREWALL_VPN_BYPASS_MARK: %w", err)
+	}
+
+	firewall.RulesTemplateFile = envToStringPtr("FIREWALL_RULES_TEMPLATE_FILE")
+
+	firewall.LogDropped, err = envToBoolPtr("FIREWALL_LOG_DROPPED")
+	if err != nil {
+		return firewall, fmt.Errorf("environment variable FIREWALL_LOG_DROPPED: %w", err)
+	}
+
+	firewall.GeoIPDatabase = envToStringPtr("FIREWALL_GEOIP_DATABASE")
+	firewall.BlockedCountries = envToCSV("FIREWALL_OUTBOUND_BLOCKED_COUNTRIES")
+	firewall.AllowedCountries = envToCSV("FIREWALL_OUTBOUND_ALLOWED_COUNTRIES")
+	firewall.LANSource = envToStringPtr("FIREWALL_LAN_SOURCE")
+	firewall.HTTPProxySource = envToStringPtr("FIREWALL_HTTP_PROXY_SOURCE")
+	firewall.GatewayInterface = envToStringPtr("FIREWALL_GATEWAY_INTERFACE")
+
 	return firewall, nil
 }
 
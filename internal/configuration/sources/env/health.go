@@ -1,15 +1,48 @@
 package env
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"inet.af/netaddr"
 )
 
 func (s *Source) ReadHealth() (health settings.Health, err error) {
 	health.ServerAddress = getCleanedEnv("HEALTH_SERVER_ADDRESS")
-	_, health.TargetAddress = s.getEnvWithRetro("HEALTH_TARGET_ADDRESS", "HEALTH_ADDRESS_TO_PING")
+	targetAddressKey, _ := s.getEnvWithRetro("HEALTH_TARGET_ADDRESS", "HEALTH_ADDRESS_TO_PING")
+	health.TargetAddresses = envToCSV(targetAddressKey)
+
+	health.Mode = getCleanedEnv("HEALTH_MODE")
+
+	health.ICMPTargets, err = readHealthICMPTargets()
+	if err != nil {
+		return health, err
+	}
+
+	if value := getCleanedEnv("HEALTH_DNS_HOSTNAME"); value != "" {
+		health.DNSHostname = stringPtr(value)
+	}
+
+	health.FailureQuorum, err = envToUint8Ptr("HEALTH_FAILURE_QUORUM")
+	if err != nil {
+		return health, fmt.Errorf("environment variable HEALTH_FAILURE_QUORUM: %w", err)
+	}
+
+	if value := getCleanedEnv("HEALTH_STATUS_FILE"); value != "" {
+		health.StatusFilepath = stringPtr(value)
+	}
+
+	if value := getCleanedEnv("HEALTH_READY_FILE"); value != "" {
+		health.ReadyFilepath = stringPtr(value)
+	}
+
+	if value := getCleanedEnv("UPSTREAM_GLUETUN_HEALTH_ADDRESS"); value != "" {
+		health.UpstreamAddress = stringPtr(value)
+	}
+
+	health.ReadinessConditions = envToCSV("HEALTH_READINESS_CONDITIONS")
 
 	health.VPN.Initial, err = s.readDurationWithRetro(
 		"HEALTH_VPN_DURATION_INITIAL",
@@ -25,9 +58,39 @@ func (s *Source) ReadHealth() (health settings.Health, err error) {
 		return health, err
 	}
 
+	health.Throughput.StallDuration, err = envToDurationPtr("HEALTH_THROUGHPUT_STALL_DURATION")
+	if err != nil {
+		return health, fmt.Errorf("environment variable HEALTH_THROUGHPUT_STALL_DURATION: %w", err)
+	}
+
+	health.Throughput.Reconnect, err = envToBoolPtr("HEALTH_THROUGHPUT_STALL_RECONNECT")
+	if err != nil {
+		return health, fmt.Errorf("environment variable HEALTH_THROUGHPUT_STALL_RECONNECT: %w", err)
+	}
+
 	return health, nil
 }
 
+var ErrHealthICMPTargetNotIP = errors.New("health ICMP target is not a valid IP address")
+
+func readHealthICMPTargets() (ips []netaddr.IP, err error) {
+	targets := envToCSV("HEALTH_ICMP_TARGETS")
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	ips = make([]netaddr.IP, len(targets))
+	for i, target := range targets {
+		ips[i], err = netaddr.ParseIP(target)
+		if err != nil {
+			return nil, fmt.Errorf("environment variable HEALTH_ICMP_TARGETS: %w: %s",
+				ErrHealthICMPTargetNotIP, target)
+		}
+	}
+
+	return ips, nil
+}
+
 func (s *Source) readDurationWithRetro(envKey, retroEnvKey string) (d *time.Duration, err error) {
 	envKey, value := s.getEnvWithRetro(envKey, retroEnvKey)
 	if value == "" {
@@ -112,6 +112,25 @@ func envToUint16Ptr(envKey string) (uint16Ptr *uint16, err error) {
 	return uint16Ptr, nil
 }
 
+// envToUint32Ptr parses the environment variable as an unsigned
+// 32 bits integer, which can be in hexadecimal with the 0x prefix.
+func envToUint32Ptr(envKey string) (uint32Ptr *uint32, err error) {
+	s := getCleanedEnv(envKey)
+	if s == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	const base, bitSize = 0, 32
+	value, err := strconv.ParseUint(s, base, bitSize)
+	if err != nil {
+		return nil, err
+	}
+
+	uint32Ptr = new(uint32)
+	*uint32Ptr = uint32(value)
+	return uint32Ptr, nil
+}
+
 func envToDurationPtr(envKey string) (durationPtr *time.Duration, err error) {
 	s := getCleanedEnv(envKey)
 	if s == "" {
@@ -0,0 +1,23 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readHooks() (hooks settings.Hooks, err error) {
+	if value := getCleanedEnv("HOOKS_DIR"); value != "" {
+		hooks.Directory = stringPtr(value)
+	}
+
+	hooks.Timeout, err = envToDurationPtr("HOOKS_TIMEOUT")
+	if err != nil {
+		return hooks, fmt.Errorf("environment variable HOOKS_TIMEOUT: %w", err)
+	}
+
+	hooks.FailurePolicy = strings.ToLower(getCleanedEnv("HOOKS_FAILURE_POLICY"))
+
+	return hooks, nil
+}
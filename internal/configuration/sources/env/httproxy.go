@@ -27,6 +27,18 @@ func (s *Source) readHTTPProxy() (httpProxy settings.HTTPProxy, err error) {
 		return httpProxy, err
 	}
 
+	if connectPortStrings := envToCSV("HTTPPROXY_CONNECT_PORTS"); connectPortStrings != nil {
+		httpProxy.ConnectPorts, err = stringsToPorts(connectPortStrings)
+		if err != nil {
+			return httpProxy, fmt.Errorf("environment variable HTTPPROXY_CONNECT_PORTS: %w", err)
+		}
+	}
+
+	httpProxy.PlainHTTPAnyPort, err = envToBoolPtr("HTTPPROXY_PLAIN_HTTP_ANY_PORT")
+	if err != nil {
+		return httpProxy, fmt.Errorf("environment variable HTTPPROXY_PLAIN_HTTP_ANY_PORT: %w", err)
+	}
+
 	return httpProxy, nil
 }
 
@@ -0,0 +1,20 @@
+package env
+
+import (
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func (s *Source) readIKEv2() (ikev2 settings.IKEv2, err error) {
+	defer func() {
+		err = unsetEnvKeys([]string{"IKEV2_PASSWORD", "IKEV2_CA_CERTIFICATE"}, err)
+	}()
+
+	ikev2.Server = getCleanedEnv("IKEV2_SERVER")
+	ikev2.RemoteID = envToStringPtr("IKEV2_REMOTE_ID")
+	ikev2.User = envToStringPtr("IKEV2_USER")
+	ikev2.Password = envToStringPtr("IKEV2_PASSWORD")
+	ikev2.CACertificate = envToStringPtr("IKEV2_CA_CERTIFICATE")
+	_, ikev2.Interface = s.getEnvWithRetro("VPN_INTERFACE", "WIREGUARD_INTERFACE")
+
+	return ikev2, nil
+}
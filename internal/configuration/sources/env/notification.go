@@ -0,0 +1,10 @@
+package env
+
+import (
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readNotification() (notification settings.Notification) {
+	notification.WebhookURL = envToStringPtr("NOTIFICATION_WEBHOOK_URL")
+	return notification
+}
@@ -0,0 +1,29 @@
+package env
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func (s *Source) readOpenConnect() (openConnect settings.OpenConnect, err error) {
+	defer func() {
+		err = unsetEnvKeys([]string{"OPENCONNECT_PASSWORD"}, err)
+	}()
+
+	openConnect.Server = getCleanedEnv("OPENCONNECT_SERVER")
+
+	openConnect.Port, err = envToUint16Ptr("OPENCONNECT_PORT")
+	if err != nil {
+		return openConnect, fmt.Errorf("environment variable OPENCONNECT_PORT: %w", err)
+	}
+
+	openConnect.Hostname = envToStringPtr("OPENCONNECT_HOSTNAME")
+	openConnect.AuthGroup = envToStringPtr("OPENCONNECT_AUTH_GROUP")
+	openConnect.User = envToStringPtr("OPENCONNECT_USER")
+	openConnect.Password = envToStringPtr("OPENCONNECT_PASSWORD")
+	openConnect.ServerCertificate = envToStringPtr("OPENCONNECT_SERVER_CERTIFICATE")
+	_, openConnect.Interface = s.getEnvWithRetro("VPN_INTERFACE", "OPENVPN_INTERFACE")
+
+	return openConnect, nil
+}
@@ -36,6 +36,9 @@ func (s *Source) readOpenVPN() (
 	openVPN.EncryptedKey = envToStringPtr("OPENVPN_ENCRYPTED_KEY")
 
 	openVPN.KeyPassphrase = s.readOpenVPNKeyPassphrase()
+	openVPN.PKCS11Provider = envToStringPtr("OPENVPN_PKCS11_PROVIDER")
+	openVPN.PKCS11ID = envToStringPtr("OPENVPN_PKCS11_ID")
+	openVPN.PKCS11PIN = envToStringPtr("OPENVPN_PKCS11_PIN")
 
 	openVPN.PIAEncPreset = s.readPIAEncryptionPreset()
 
@@ -61,6 +64,11 @@ func (s *Source) readOpenVPN() (
 		openVPN.Flags = strings.Fields(flagsStr)
 	}
 
+	openVPN.Obfuscation, err = readOpenVPNObfuscation()
+	if err != nil {
+		return openVPN, err
+	}
+
 	return openVPN, nil
 }
 
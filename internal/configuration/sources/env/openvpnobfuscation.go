@@ -0,0 +1,26 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readOpenVPNObfuscation() (obfuscation settings.OpenVPNObfuscation, err error) {
+	obfuscation.Type = strings.ToLower(getCleanedEnv("OPENVPN_OBFUSCATION"))
+
+	obfuscation.Port, err = envToUint16Ptr("OPENVPN_OBFUSCATION_PORT")
+	if err != nil {
+		return obfuscation, fmt.Errorf("environment variable OPENVPN_OBFUSCATION_PORT: %w", err)
+	}
+
+	obfuscation.ServerName = envToStringPtr("OPENVPN_OBFUSCATION_SERVER_NAME")
+
+	obfuscation.SkipVerify, err = envToBoolPtr("OPENVPN_OBFUSCATION_SKIP_VERIFY")
+	if err != nil {
+		return obfuscation, fmt.Errorf("environment variable OPENVPN_OBFUSCATION_SKIP_VERIFY: %w", err)
+	}
+
+	return obfuscation, nil
+}
@@ -8,6 +8,11 @@ import (
 
 func (s *Source) readPortForward() (
 	portForwarding settings.PortForwarding, err error) {
+	defer func() {
+		err = unsetEnvKeys([]string{"VPN_PORT_FORWARDING_API_KEY",
+			"QBITTORRENT_PASSWORD", "TRANSMISSION_PASSWORD", "DELUGE_PASSWORD"}, err)
+	}()
+
 	key, _ := s.getEnvWithRetro(
 		"PRIVATE_INTERNET_ACCESS_VPN_PORT_FORWARDING",
 		"PORT_FORWARDING")
@@ -16,6 +21,8 @@ func (s *Source) readPortForward() (
 		return portForwarding, fmt.Errorf("environment variable %s: %w", key, err)
 	}
 
+	portForwarding.Provider = envToStringPtr("VPN_PORT_FORWARDING_PROVIDER")
+
 	_, value := s.getEnvWithRetro(
 		"PRIVATE_INTERNET_ACCESS_VPN_PORT_FORWARDING_STATUS_FILE",
 		"PORT_FORWARDING_STATUS_FILE")
@@ -23,5 +30,29 @@ func (s *Source) readPortForward() (
 		portForwarding.Filepath = stringPtr(value)
 	}
 
+	portForwarding.CheckURL = envToStringPtr("VPN_PORT_FORWARDING_CHECK_URL")
+
+	portForwarding.CheckPeriod, err = envToDurationPtr("VPN_PORT_FORWARDING_CHECK_PERIOD")
+	if err != nil {
+		return portForwarding, fmt.Errorf("environment variable VPN_PORT_FORWARDING_CHECK_PERIOD: %w", err)
+	}
+
+	portForwarding.APIKey = envToStringPtr("VPN_PORT_FORWARDING_API_KEY")
+
+	portForwarding.WebhookURL = envToStringPtr("VPN_PORT_FORWARDING_WEBHOOK_URL")
+	portForwarding.WebhookTemplate = envToStringPtr("VPN_PORT_FORWARDING_WEBHOOK_TEMPLATE")
+	portForwarding.Target = envToStringPtr("VPN_PORT_FORWARDING_TARGET")
+
+	portForwarding.QBittorrent.URL = envToStringPtr("QBITTORRENT_URL")
+	portForwarding.QBittorrent.Username = envToStringPtr("QBITTORRENT_USER")
+	portForwarding.QBittorrent.Password = envToStringPtr("QBITTORRENT_PASSWORD")
+
+	portForwarding.Transmission.URL = envToStringPtr("TRANSMISSION_URL")
+	portForwarding.Transmission.Username = envToStringPtr("TRANSMISSION_USER")
+	portForwarding.Transmission.Password = envToStringPtr("TRANSMISSION_PASSWORD")
+
+	portForwarding.Deluge.URL = envToStringPtr("DELUGE_URL")
+	portForwarding.Deluge.Password = envToStringPtr("DELUGE_PASSWORD")
+
 	return portForwarding, nil
 }
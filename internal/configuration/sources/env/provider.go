@@ -26,6 +26,9 @@ func (s *Source) readProvider(vpnType string) (provider settings.Provider, err e
 		return provider, fmt.Errorf("port forwarding: %w", err)
 	}
 
+	provider.DefinitionFile = envToStringPtr("PROVIDER_DEFINITION_FILE")
+	provider.PluginFile = envToStringPtr("PROVIDER_PLUGIN_FILE")
+
 	return provider, nil
 }
 
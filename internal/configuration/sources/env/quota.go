@@ -0,0 +1,78 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readQuota() (quota settings.Quota, err error) {
+	quota.Limit, err = envToByteSizePtr("QUOTA_LIMIT")
+	if err != nil {
+		return quota, fmt.Errorf("environment variable QUOTA_LIMIT: %w", err)
+	}
+
+	quota.Period = strings.ToLower(getCleanedEnv("QUOTA_PERIOD"))
+	quota.Action = strings.ToLower(getCleanedEnv("QUOTA_ACTION"))
+	quota.NotifyURL = envToStringPtr("QUOTA_NOTIFY_URL")
+	quota.ThrottleRate = envToStringPtr("QUOTA_THROTTLE_RATE")
+
+	if allowedPortStrings := envToCSV("QUOTA_ALLOWED_PORTS"); allowedPortStrings != nil {
+		quota.AllowedPorts, err = stringsToPorts(allowedPortStrings)
+		if err != nil {
+			return quota, fmt.Errorf("environment variable QUOTA_ALLOWED_PORTS: %w", err)
+		}
+	}
+
+	return quota, nil
+}
+
+var ErrByteSizeNotValid = errors.New("byte size is not valid")
+
+// envToByteSizePtr parses a byte size such as 500GB or 1TiB
+// from the environment variable given.
+func envToByteSizePtr(envKey string) (size *uint64, err error) {
+	s := getCleanedEnv(envKey)
+	if s == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	units := []struct {
+		suffix     string
+		multiplier uint64
+	}{ // longest suffixes first
+		{suffix: "kib", multiplier: 1 << 10},
+		{suffix: "mib", multiplier: 1 << 20},
+		{suffix: "gib", multiplier: 1 << 30},
+		{suffix: "tib", multiplier: 1 << 40},
+		{suffix: "kb", multiplier: 1e3},
+		{suffix: "mb", multiplier: 1e6},
+		{suffix: "gb", multiplier: 1e9},
+		{suffix: "tb", multiplier: 1e12},
+		{suffix: "b", multiplier: 1},
+	}
+
+	number := strings.ToLower(s)
+	multiplier := uint64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(number, unit.suffix) {
+			number = strings.TrimSpace(strings.TrimSuffix(number, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	const base, bitSize = 10, 64
+	value, err := strconv.ParseUint(number, base, bitSize)
+	if err != nil || value > math.MaxUint64/multiplier {
+		return nil, fmt.Errorf("%w: %s", ErrByteSizeNotValid, s)
+	}
+
+	size = new(uint64)
+	*size = value * multiplier
+	return size, nil
+}
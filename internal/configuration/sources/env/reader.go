@@ -26,6 +26,11 @@ func (s *Source) Read() (settings settings.Settings, err error) {
 		return settings, err
 	}
 
+	settings.Failover, err = readFailover()
+	if err != nil {
+		return settings, err
+	}
+
 	settings.Firewall, err = s.readFirewall()
 	if err != nil {
 		return settings, err
@@ -46,6 +51,16 @@ func (s *Source) Read() (settings settings.Settings, err error) {
 		return settings, err
 	}
 
+	settings.SOCKS5, err = readSOCKS5()
+	if err != nil {
+		return settings, err
+	}
+
+	settings.WireguardServer, err = readWireguardServer()
+	if err != nil {
+		return settings, err
+	}
+
 	settings.Log, err = readLog()
 	if err != nil {
 		return settings, err
@@ -56,6 +71,31 @@ func (s *Source) Read() (settings settings.Settings, err error) {
 		return settings, err
 	}
 
+	settings.Quota, err = readQuota()
+	if err != nil {
+		return settings, err
+	}
+
+	settings.Rotation, err = readRotation()
+	if err != nil {
+		return settings, err
+	}
+
+	settings.ServerCooldown, err = readServerCooldown()
+	if err != nil {
+		return settings, err
+	}
+
+	settings.Bandwidth, err = readBandwidth()
+	if err != nil {
+		return settings, err
+	}
+
+	settings.DDNS, err = readDDNS()
+	if err != nil {
+		return settings, err
+	}
+
 	settings.Updater, err = readUpdater()
 	if err != nil {
 		return settings, err
@@ -66,6 +106,8 @@ func (s *Source) Read() (settings settings.Settings, err error) {
 		return settings, err
 	}
 
+	settings.Notification = readNotification()
+
 	settings.Shadowsocks, err = s.readShadowsocks()
 	if err != nil {
 		return settings, err
@@ -81,6 +123,31 @@ func (s *Source) Read() (settings settings.Settings, err error) {
 		return settings, err
 	}
 
+	settings.Docker, err = readDockerDependents()
+	if err != nil {
+		return settings, err
+	}
+
+	settings.DockerLabels, err = readDockerLabels()
+	if err != nil {
+		return settings, err
+	}
+
+	settings.Hooks, err = readHooks()
+	if err != nil {
+		return settings, err
+	}
+
+	settings.RuntimeState, err = readRuntimeState()
+	if err != nil {
+		return settings, err
+	}
+
+	settings.Secrets, err = readSecretsWatch()
+	if err != nil {
+		return settings, err
+	}
+
 	settings.Pprof, err = readPprof()
 	if err != nil {
 		return settings, err
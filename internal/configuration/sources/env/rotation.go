@@ -0,0 +1,18 @@
+package env
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readRotation() (rotation settings.Rotation, err error) {
+	rotation.Period, err = envToDurationPtr("SERVER_ROTATION_PERIOD")
+	if err != nil {
+		return rotation, fmt.Errorf("environment variable SERVER_ROTATION_PERIOD: %w", err)
+	}
+
+	rotation.Cron = envToStringPtr("SERVER_ROTATION_CRON")
+
+	return rotation, nil
+}
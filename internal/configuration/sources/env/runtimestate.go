@@ -0,0 +1,23 @@
+package env
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readRuntimeState() (runtimeState settings.RuntimeState, err error) {
+	runtimeState.Enabled, err = envToBoolPtr("RUNTIME_STATE")
+	if err != nil {
+		return runtimeState, fmt.Errorf("environment variable RUNTIME_STATE: %w", err)
+	}
+
+	runtimeState.Filepath = getCleanedEnv("RUNTIME_STATE_FILE")
+
+	runtimeState.MaxAge, err = envToDurationPtr("RUNTIME_STATE_MAX_AGE")
+	if err != nil {
+		return runtimeState, fmt.Errorf("environment variable RUNTIME_STATE_MAX_AGE: %w", err)
+	}
+
+	return runtimeState, nil
+}
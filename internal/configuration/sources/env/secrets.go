@@ -0,0 +1,15 @@
+package env
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readSecretsWatch() (secrets settings.SecretsWatch, err error) {
+	secrets.Period, err = envToDurationPtr("SECRETS_WATCH_PERIOD")
+	if err != nil {
+		return secrets, fmt.Errorf("environment variable SECRETS_WATCH_PERIOD: %w", err)
+	}
+	return secrets, nil
+}
@@ -1,13 +1,22 @@
 package env
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/govalid/binary"
 )
 
 func (s *Source) readControlServer() (controlServer settings.ControlServer, err error) {
+	defer func() {
+		err = unsetEnvKeys([]string{
+			"HTTP_CONTROL_SERVER_TOTP_SECRET",
+			"HTTP_CONTROL_SERVER_API_KEYS",
+		}, err)
+	}()
+
 	controlServer.Log, err = readControlServerLog()
 	if err != nil {
 		return controlServer, err
@@ -15,9 +24,62 @@ func (s *Source) readControlServer() (controlServer settings.ControlServer, err
 
 	controlServer.Address = s.readControlServerAddress()
 
+	controlServer.RollbackWindow, err = envToDurationPtr("HTTP_CONTROL_SERVER_ROLLBACK_WINDOW")
+	if err != nil {
+		return controlServer, fmt.Errorf("environment variable HTTP_CONTROL_SERVER_ROLLBACK_WINDOW: %w", err)
+	}
+
+	controlServer.TOTPSecret = envToStringPtr("HTTP_CONTROL_SERVER_TOTP_SECRET")
+
+	controlServer.APIKeys, err = readControlServerAPIKeys()
+	if err != nil {
+		return controlServer, fmt.Errorf("environment variable HTTP_CONTROL_SERVER_API_KEYS: %w", err)
+	}
+
+	controlServer.HealthExempt, err = envToBoolPtr("HTTP_CONTROL_SERVER_AUTH_EXEMPT_HEALTH")
+	if err != nil {
+		return controlServer, fmt.Errorf("environment variable HTTP_CONTROL_SERVER_AUTH_EXEMPT_HEALTH: %w", err)
+	}
+
+	controlServer.TLS, err = envToBoolPtr("HTTP_CONTROL_SERVER_TLS")
+	if err != nil {
+		return controlServer, fmt.Errorf("environment variable HTTP_CONTROL_SERVER_TLS: %w", err)
+	}
+
+	controlServer.TLSCertificateFile = envToStringPtr("HTTP_CONTROL_SERVER_TLS_CERTIFICATE_FILE")
+	controlServer.TLSKeyFile = envToStringPtr("HTTP_CONTROL_SERVER_TLS_KEY_FILE")
+	controlServer.TLSHostnames = envToCSV("HTTP_CONTROL_SERVER_TLS_HOSTNAMES")
+
+	controlServer.DoH, err = envToBoolPtr("HTTP_CONTROL_SERVER_DOH")
+	if err != nil {
+		return controlServer, fmt.Errorf("environment variable HTTP_CONTROL_SERVER_DOH: %w", err)
+	}
+
 	return controlServer, nil
 }
 
+var ErrAPIKeyFormatNotValid = errors.New("API key format is not valid")
+
+// readControlServerAPIKeys reads the comma separated API keys
+// of the form role:key, for example admin:abcd,readonly:efgh.
+func readControlServerAPIKeys() (apiKeys []settings.ControlServerAPIKey, err error) {
+	values := envToCSV("HTTP_CONTROL_SERVER_API_KEYS")
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	apiKeys = make([]settings.ControlServerAPIKey, len(values))
+	for i, value := range values {
+		role, key, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: API key %d of %d must be in the form role:key",
+				ErrAPIKeyFormatNotValid, i+1, len(values))
+		}
+		apiKeys[i] = settings.ControlServerAPIKey{Role: role, Key: key}
+	}
+	return apiKeys, nil
+}
+
 func readControlServerLog() (enabled *bool, err error) {
 	s := getCleanedEnv("HTTP_CONTROL_SERVER_LOG")
 	if s == "" {
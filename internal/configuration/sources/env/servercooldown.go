@@ -0,0 +1,15 @@
+package env
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readServerCooldown() (cooldown settings.ServerCooldown, err error) {
+	cooldown.Period, err = envToDurationPtr("SERVER_COOLDOWN_PERIOD")
+	if err != nil {
+		return cooldown, fmt.Errorf("environment variable SERVER_COOLDOWN_PERIOD: %w", err)
+	}
+	return cooldown, nil
+}
@@ -0,0 +1,19 @@
+package env
+
+import (
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readShadowsocksClient() (client settings.ShadowsocksClient, err error) {
+	defer func() {
+		err = unsetEnvKeys([]string{"SHADOWSOCKS_CLIENT_PASSWORD"}, err)
+	}()
+
+	client.Server = getCleanedEnv("SHADOWSOCKS_CLIENT_SERVER")
+	client.Password = envToStringPtr("SHADOWSOCKS_CLIENT_PASSWORD")
+	client.CipherName = strings.ToLower(getCleanedEnv("SHADOWSOCKS_CLIENT_CIPHER"))
+
+	return client, nil
+}
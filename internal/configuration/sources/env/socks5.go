@@ -0,0 +1,32 @@
+package env
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readSOCKS5() (socks5 settings.SOCKS5, err error) {
+	socks5.User = envToStringPtr("SOCKS5_USER")
+	socks5.Password = envToStringPtr("SOCKS5_PASSWORD")
+	socks5.ListeningAddress = getCleanedEnv("SOCKS5_LISTENING_ADDRESS")
+
+	socks5.Enabled, err = envToBoolPtr("SOCKS5")
+	if err != nil {
+		return socks5, fmt.Errorf("environment variable SOCKS5: %w", err)
+	}
+
+	socks5.Log, err = envToBoolPtr("SOCKS5_LOG")
+	if err != nil {
+		return socks5, fmt.Errorf("environment variable SOCKS5_LOG: %w", err)
+	}
+
+	if connectPortStrings := envToCSV("SOCKS5_CONNECT_PORTS"); connectPortStrings != nil {
+		socks5.ConnectPorts, err = stringsToPorts(connectPortStrings)
+		if err != nil {
+			return socks5, fmt.Errorf("environment variable SOCKS5_CONNECT_PORTS: %w", err)
+		}
+	}
+
+	return socks5, nil
+}
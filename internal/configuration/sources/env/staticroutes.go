@@ -0,0 +1,59 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+var ErrStaticRouteFormat = errors.New("static route format is not valid")
+
+// parseStaticRoutes parses static routes in the format of the ip route
+// command, such as "10.0.0.0/8 via 172.18.0.1 dev eth1", where either
+// the via gateway or the dev interface can be omitted.
+func parseStaticRoutes(ss []string) (routes []settings.StaticRoute, err error) {
+	if len(ss) == 0 {
+		return nil, nil
+	}
+	routes = make([]settings.StaticRoute, len(ss))
+	for i, s := range ss {
+		routes[i], err = parseStaticRoute(s)
+		if err != nil {
+			return nil, fmt.Errorf("parsing static route %q: %w", s, err)
+		}
+	}
+	return routes, nil
+}
+
+func parseStaticRoute(s string) (route settings.StaticRoute, err error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields)%2 == 0 {
+		return route, fmt.Errorf("%w", ErrStaticRouteFormat)
+	}
+
+	_, destination, err := net.ParseCIDR(fields[0])
+	if err != nil {
+		return route, fmt.Errorf("parsing destination: %w", err)
+	}
+	route.Destination = *destination
+
+	for i := 1; i < len(fields); i += 2 {
+		keyword, value := fields[i], fields[i+1]
+		switch keyword {
+		case "via":
+			route.Gateway = net.ParseIP(value)
+			if route.Gateway == nil {
+				return settings.StaticRoute{}, fmt.Errorf("%w: gateway %q is not an IP address",
+					ErrStaticRouteFormat, value)
+			}
+		case "dev":
+			route.Interface = value
+		default:
+			return settings.StaticRoute{}, fmt.Errorf("%w: unknown keyword %q", ErrStaticRouteFormat, keyword)
+		}
+	}
+	return route, nil
+}
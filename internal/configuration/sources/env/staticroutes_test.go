@@ -0,0 +1,71 @@
+package env
+
+import (
+	"net"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseStaticRoute(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		s          string
+		route      settings.StaticRoute
+		errWrapped error
+		errMessage string
+	}{
+		"empty": {
+			errWrapped: ErrStaticRouteFormat,
+			errMessage: "static route format is not valid",
+		},
+		"gateway and interface": {
+			s: "10.0.0.0/8 via 172.18.0.1 dev eth1",
+			route: settings.StaticRoute{
+				Destination: net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)},
+				Gateway:     net.ParseIP("172.18.0.1"),
+				Interface:   "eth1",
+			},
+		},
+		"interface only": {
+			s: "192.168.2.0/24 dev eth1",
+			route: settings.StaticRoute{
+				Destination: net.IPNet{IP: net.IP{192, 168, 2, 0}, Mask: net.CIDRMask(24, 32)},
+				Interface:   "eth1",
+			},
+		},
+		"missing value": {
+			s:          "10.0.0.0/8 via",
+			errWrapped: ErrStaticRouteFormat,
+			errMessage: "static route format is not valid",
+		},
+		"unknown keyword": {
+			s:          "10.0.0.0/8 metric 100",
+			errWrapped: ErrStaticRouteFormat,
+			errMessage: `static route format is not valid: unknown keyword "metric"`,
+		},
+		"gateway not IP": {
+			s:          "10.0.0.0/8 via gateway",
+			errWrapped: ErrStaticRouteFormat,
+			errMessage: `static route format is not valid: gateway "gateway" is not an IP address`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			route, err := parseStaticRoute(testCase.s)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				require.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.route.String(), route.String())
+		})
+	}
+}
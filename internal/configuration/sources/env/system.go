@@ -25,16 +25,36 @@ func (s *Source) readSystem() (system settings.System, err error) {
 		return system, err
 	}
 
+	system.FilesUID, err = s.readID("FILES_UID")
+	if err != nil {
+		return system, err
+	}
+
+	system.FilesGID, err = s.readID("FILES_GID")
+	if err != nil {
+		return system, err
+	}
+
+	system.FixOwnership, err = envToBoolPtr("FILES_FIX_OWNERSHIP")
+	if err != nil {
+		return system, fmt.Errorf("environment variable FILES_FIX_OWNERSHIP: %w", err)
+	}
+
 	system.Timezone = getCleanedEnv("TZ")
 
+	system.HostMode, err = envToBoolPtr("HOST_MODE")
+	if err != nil {
+		return system, fmt.Errorf("environment variable HOST_MODE: %w", err)
+	}
+
 	return system, nil
 }
 
 var ErrSystemIDNotValid = errors.New("system ID is not valid")
 
-func (s *Source) readID(key, retroKey string) (
+func (s *Source) readID(key string, retroKeys ...string) (
 	id *uint32, err error) {
-	idEnvKey, idString := s.getEnvWithRetro(key, retroKey)
+	idEnvKey, idString := s.getEnvWithRetro(key, retroKeys...)
 	if idString == "" {
 		return nil, nil //nolint:nilnil
 	}
@@ -34,5 +34,30 @@ func readUnbound() (unbound settings.Unbound, err error) {
 		return unbound, fmt.Errorf("environment variable DOT_VALIDATION_LOGLEVEL: %w", err)
 	}
 
+	unbound.CacheSize, err = envToByteSizePtr("DOT_CACHE_SIZE")
+	if err != nil {
+		return unbound, fmt.Errorf("environment variable DOT_CACHE_SIZE: %w", err)
+	}
+
+	unbound.CacheMinTTL, err = envToDurationPtr("DOT_CACHE_MIN_TTL")
+	if err != nil {
+		return unbound, fmt.Errorf("environment variable DOT_CACHE_MIN_TTL: %w", err)
+	}
+
+	unbound.CacheMaxTTL, err = envToDurationPtr("DOT_CACHE_MAX_TTL")
+	if err != nil {
+		return unbound, fmt.Errorf("environment variable DOT_CACHE_MAX_TTL: %w", err)
+	}
+
+	unbound.NegativeCacheSize, err = envToByteSizePtr("DOT_NEGATIVE_CACHE_SIZE")
+	if err != nil {
+		return unbound, fmt.Errorf("environment variable DOT_NEGATIVE_CACHE_SIZE: %w", err)
+	}
+
+	unbound.CacheMaxNegativeTTL, err = envToDurationPtr("DOT_CACHE_MAX_NEGATIVE_TTL")
+	if err != nil {
+		return unbound, fmt.Errorf("environment variable DOT_CACHE_MAX_NEGATIVE_TTL: %w", err)
+	}
+
 	return unbound, nil
 }
@@ -25,6 +25,16 @@ func readUpdater() (updater settings.Updater, err error) {
 
 	updater.Providers = envToCSV("UPDATER_VPN_SERVICE_PROVIDERS")
 
+	updater.Workers, err = envToIntPtr("UPDATER_WORKERS")
+	if err != nil {
+		return updater, fmt.Errorf("environment variable UPDATER_WORKERS: %w", err)
+	}
+
+	updater.ProviderTimeout, err = readUpdaterProviderTimeout()
+	if err != nil {
+		return updater, err
+	}
+
 	return updater, nil
 }
 
@@ -41,6 +51,18 @@ func readUpdaterPeriod() (period *time.Duration, err error) {
 	return period, nil
 }
 
+func readUpdaterProviderTimeout() (timeout time.Duration, err error) {
+	s := getCleanedEnv("UPDATER_PROVIDER_TIMEOUT")
+	if s == "" {
+		return 0, nil
+	}
+	timeout, err = time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("environment variable UPDATER_PROVIDER_TIMEOUT: %w", err)
+	}
+	return timeout, nil
+}
+
 func readUpdaterDNSAddress() (address string, err error) {
 	// TODO this is currently using Cloudflare in
 	// plaintext to not be blocked by DNS over TLS by default.
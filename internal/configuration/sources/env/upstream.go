@@ -0,0 +1,18 @@
+package env
+
+import (
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readUpstream() (upstream settings.Upstream) {
+	if value := getCleanedEnv("UPSTREAM_GLUETUN_HTTPPROXY"); value != "" {
+		upstream.HTTPProxy = stringPtr(value)
+	}
+	if value := getCleanedEnv("UPSTREAM_GLUETUN_HTTPPROXY_USER"); value != "" {
+		upstream.HTTPProxyUser = stringPtr(value)
+	}
+	if value := getCleanedEnv("UPSTREAM_GLUETUN_HTTPPROXY_PASSWORD"); value != "" {
+		upstream.HTTPProxyPassword = stringPtr(value)
+	}
+	return upstream
+}
@@ -25,5 +25,47 @@ func (s *Source) readVPN() (vpn settings.VPN, err error) {
 		return vpn, fmt.Errorf("wireguard: %w", err)
 	}
 
+	vpn.Upstream = readUpstream()
+
+	vpn.Shadowsocks, err = readShadowsocksClient()
+	if err != nil {
+		return vpn, fmt.Errorf("Shadowsocks client: %w", err)
+	}
+
+	vpn.IKEv2, err = s.readIKEv2()
+	if err != nil {
+		return vpn, fmt.Errorf("IKEv2: %w", err)
+	}
+
+	vpn.OpenConnect, err = s.readOpenConnect()
+	if err != nil {
+		return vpn, fmt.Errorf("OpenConnect: %w", err)
+	}
+
+	vpn.LogRules, err = readVPNLogRules()
+	if err != nil {
+		return vpn, fmt.Errorf("log rules: %w", err)
+	}
+
+	vpn.Hops, err = readVPNHops()
+	if err != nil {
+		return vpn, fmt.Errorf("hops: %w", err)
+	}
+
+	vpn.Routes, err = stringsToIPNets(envToCSV("VPN_ROUTES"))
+	if err != nil {
+		return vpn, fmt.Errorf("environment variable VPN_ROUTES: %w", err)
+	}
+
+	vpn.StaticRoutes, err = parseStaticRoutes(envToCSV("STATIC_ROUTES"))
+	if err != nil {
+		return vpn, fmt.Errorf("environment variable STATIC_ROUTES: %w", err)
+	}
+
+	vpn.Tunnels, err = readVPNTunnels()
+	if err != nil {
+		return vpn, fmt.Errorf("tunnels: %w", err)
+	}
+
 	return vpn, nil
 }
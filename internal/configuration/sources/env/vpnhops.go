@@ -0,0 +1,61 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// readVPNHops reads the Wireguard entry hops from the environment
+// variables VPN_HOP_1_ENDPOINT, VPN_HOP_1_PUBLIC_KEY and so on,
+// stopping at the first hop number without an endpoint.
+func readVPNHops() (hops []settings.VPNHop, err error) {
+	for i := 1; ; i++ {
+		prefix := "VPN_HOP_" + fmt.Sprint(i) + "_"
+		endpoint := getCleanedEnv(prefix + "ENDPOINT")
+		if endpoint == "" {
+			return hops, nil
+		}
+
+		hop, err := readVPNHop(prefix, endpoint)
+		if err != nil {
+			return nil, err // already wrapped
+		}
+		hops = append(hops, hop)
+	}
+}
+
+var ErrHopEndpointNotIP = errors.New("hop endpoint host is not an IP address")
+
+func readVPNHop(prefix, endpoint string) (hop settings.VPNHop, err error) {
+	privateKeyKey := prefix + "PRIVATE_KEY"
+	preSharedKeyKey := prefix + "PRESHARED_KEY"
+	defer func() {
+		err = unsetEnvKeys([]string{privateKeyKey, preSharedKeyKey}, err)
+	}()
+	hop.PrivateKey = getCleanedEnv(privateKeyKey)
+	hop.PreSharedKey = getCleanedEnv(preSharedKeyKey)
+	hop.PublicKey = getCleanedEnv(prefix + "PUBLIC_KEY")
+	hop.Interface = getCleanedEnv(prefix + "INTERFACE")
+
+	endpointKey := prefix + "ENDPOINT"
+	var hostname string
+	hop.Endpoint, hostname, err = parseEndpoint(endpoint)
+	if err != nil {
+		return hop, fmt.Errorf("environment variable %s: %w", endpointKey, err)
+	} else if hostname != "" {
+		// The hop endpoint is routed outside the tunnels before
+		// connecting, so it cannot be resolved through them.
+		return hop, fmt.Errorf("environment variable %s: %w: %s",
+			endpointKey, ErrHopEndpointNotIP, hostname)
+	}
+
+	addressesKey := prefix + "ADDRESSES"
+	hop.Addresses, err = parseWireguardAddresses(addressesKey, getCleanedEnv(addressesKey))
+	if err != nil {
+		return hop, err // already wrapped
+	}
+
+	return hop, nil
+}
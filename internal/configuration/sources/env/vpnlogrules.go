@@ -0,0 +1,48 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/log"
+)
+
+// readVPNLogRules reads VPN log rules from the environment
+// variables VPN_LOG_RULE_1_PATTERN, VPN_LOG_RULE_1_LEVEL and
+// so on, stopping at the first rule number without a pattern.
+func readVPNLogRules() (rules []settings.VPNLogRule, err error) {
+	for i := 1; ; i++ {
+		prefix := "VPN_LOG_RULE_" + fmt.Sprint(i) + "_"
+		pattern := getCleanedEnv(prefix + "PATTERN")
+		if pattern == "" {
+			return rules, nil
+		}
+
+		rule := settings.VPNLogRule{Pattern: pattern}
+		levelKey := prefix + "LEVEL"
+		rule.Level, rule.Suppress, err = parseVPNLogRuleLevel(getCleanedEnv(levelKey))
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s: %w", levelKey, err)
+		}
+		rules = append(rules, rule)
+	}
+}
+
+var ErrVPNLogRuleLevelNotSet = errors.New("VPN log rule level is not set")
+
+func parseVPNLogRuleLevel(s string) (level log.Level, suppress bool, err error) {
+	switch strings.ToLower(s) {
+	case "":
+		return level, false, fmt.Errorf("%w", ErrVPNLogRuleLevelNotSet)
+	case "suppress":
+		return level, true, nil
+	}
+
+	level, err = parseLogLevel(s)
+	if err != nil {
+		return level, false, err
+	}
+	return level, false, nil
+}
@@ -0,0 +1,89 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// readVPNTunnels reads the additional Wireguard tunnels from the
+// environment variables VPN_TUNNEL_1_ENDPOINT, VPN_TUNNEL_1_PUBLIC_KEY
+// and so on, stopping at the first tunnel number without an endpoint.
+func readVPNTunnels() (tunnels []settings.VPNTunnel, err error) {
+	for i := 1; ; i++ {
+		prefix := "VPN_TUNNEL_" + fmt.Sprint(i) + "_"
+		endpoint := getCleanedEnv(prefix + "ENDPOINT")
+		if endpoint == "" {
+			return tunnels, nil
+		}
+
+		tunnel, err := readVPNTunnel(prefix, endpoint)
+		if err != nil {
+			return nil, err // already wrapped
+		}
+		tunnels = append(tunnels, tunnel)
+	}
+}
+
+var ErrTunnelEndpointNotIP = errors.New("tunnel endpoint host is not an IP address")
+
+func readVPNTunnel(prefix, endpoint string) (tunnel settings.VPNTunnel, err error) {
+	privateKeyKey := prefix + "PRIVATE_KEY"
+	preSharedKeyKey := prefix + "PRESHARED_KEY"
+	defer func() {
+		err = unsetEnvKeys([]string{privateKeyKey, preSharedKeyKey}, err)
+	}()
+	tunnel.PrivateKey = getCleanedEnv(privateKeyKey)
+	tunnel.PreSharedKey = getCleanedEnv(preSharedKeyKey)
+	tunnel.PublicKey = getCleanedEnv(prefix + "PUBLIC_KEY")
+	tunnel.Interface = getCleanedEnv(prefix + "INTERFACE")
+
+	endpointKey := prefix + "ENDPOINT"
+	var hostname string
+	tunnel.Endpoint, hostname, err = parseEndpoint(endpoint)
+	if err != nil {
+		return tunnel, fmt.Errorf("environment variable %s: %w", endpointKey, err)
+	} else if hostname != "" {
+		// The tunnel endpoint is routed outside the tunnels
+		// before connecting, so it must be known upfront.
+		return tunnel, fmt.Errorf("environment variable %s: %w: %s",
+			endpointKey, ErrTunnelEndpointNotIP, hostname)
+	}
+
+	addressesKey := prefix + "ADDRESSES"
+	tunnel.Addresses, err = parseWireguardAddresses(addressesKey, getCleanedEnv(addressesKey))
+	if err != nil {
+		return tunnel, err // already wrapped
+	}
+
+	sourceSubnetsKey := prefix + "SOURCE_SUBNETS"
+	tunnel.SourceSubnets, err = stringsToIPNets(envToCSV(sourceSubnetsKey))
+	if err != nil {
+		return tunnel, fmt.Errorf("environment variable %s: %w", sourceSubnetsKey, err)
+	}
+
+	sourcePortsKey := prefix + "SOURCE_PORTS"
+	tunnel.SourcePorts, err = stringsToPorts(envToCSV(sourcePortsKey))
+	if err != nil {
+		return tunnel, fmt.Errorf("environment variable %s: %w", sourcePortsKey, err)
+	}
+
+	firewallMarkKey := prefix + "FIREWALL_MARK"
+	firewallMark, err := envToUint32Ptr(firewallMarkKey)
+	if err != nil {
+		return tunnel, fmt.Errorf("environment variable %s: %w", firewallMarkKey, err)
+	} else if firewallMark != nil {
+		tunnel.FirewallMark = *firewallMark
+	}
+
+	forwardedPortKey := prefix + "FORWARDED_PORT"
+	forwardedPort, err := envToUint16Ptr(forwardedPortKey)
+	if err != nil {
+		return tunnel, fmt.Errorf("environment variable %s: %w", forwardedPortKey, err)
+	} else if forwardedPort != nil {
+		tunnel.ForwardedPort = *forwardedPort
+	}
+
+	return tunnel, nil
+}
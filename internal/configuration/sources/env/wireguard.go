@@ -1,9 +1,11 @@
 package env
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
@@ -11,7 +13,8 @@ import (
 
 func (s *Source) readWireguard() (wireguard settings.Wireguard, err error) {
 	defer func() {
-		err = unsetEnvKeys([]string{"WIREGUARD_PRIVATE_KEY", "WIREGUARD_PRESHARED_KEY"}, err)
+		err = unsetEnvKeys([]string{"WIREGUARD_PRIVATE_KEY", "WIREGUARD_PRESHARED_KEY",
+			"WARP_LICENSE_KEY"}, err)
 	}()
 	wireguard.PrivateKey = envToStringPtr("WIREGUARD_PRIVATE_KEY")
 	wireguard.PreSharedKey = envToStringPtr("WIREGUARD_PRESHARED_KEY")
@@ -21,11 +24,124 @@ func (s *Source) readWireguard() (wireguard settings.Wireguard, err error) {
 	if err != nil {
 		return wireguard, err // already wrapped
 	}
+
+	wireguard.PersistentKeepaliveInterval, err = envToDurationPtr("WIREGUARD_PERSISTENT_KEEPALIVE_INTERVAL")
+	if err != nil {
+		return wireguard, fmt.Errorf("environment variable WIREGUARD_PERSISTENT_KEEPALIVE_INTERVAL: %w", err)
+	}
+
+	wireguard.Peers, err = readWireguardPeers()
+	if err != nil {
+		return wireguard, err // already wrapped
+	}
+
+	wireguard.EndpointResolvePeriod, err = envToDurationPtr("WIREGUARD_ENDPOINT_RESOLVE_PERIOD")
+	if err != nil {
+		return wireguard, fmt.Errorf("environment variable WIREGUARD_ENDPOINT_RESOLVE_PERIOD: %w", err)
+	}
+
+	wireguard.Obfuscation, err = readWireguardObfuscation()
+	if err != nil {
+		return wireguard, err
+	}
+
+	wireguard.WARPLicenseKey = envToStringPtr("WARP_LICENSE_KEY")
+
 	return wireguard, nil
 }
 
+// readWireguardPeers reads additional Wireguard peers from the
+// environment variables WIREGUARD_PEER_1_PUBLIC_KEY,
+// WIREGUARD_PEER_1_ALLOWED_IPS and so on, stopping at the
+// first peer number without a public key.
+func readWireguardPeers() (peers []settings.WireguardPeer, err error) {
+	for i := 1; ; i++ {
+		prefix := "WIREGUARD_PEER_" + fmt.Sprint(i) + "_"
+		publicKey := getCleanedEnv(prefix + "PUBLIC_KEY")
+		if publicKey == "" {
+			return peers, nil
+		}
+
+		peer, err := readWireguardPeer(prefix)
+		if err != nil {
+			return nil, err // already wrapped
+		}
+		peer.PublicKey = publicKey
+		peers = append(peers, peer)
+	}
+}
+
+func readWireguardPeer(prefix string) (peer settings.WireguardPeer, err error) {
+	preSharedKeyKey := prefix + "PRESHARED_KEY"
+	defer func() {
+		err = unsetEnvKeys([]string{preSharedKeyKey}, err)
+	}()
+	peer.PreSharedKey = getCleanedEnv(preSharedKeyKey)
+
+	endpointKey := prefix + "ENDPOINT"
+	if endpoint := getCleanedEnv(endpointKey); endpoint != "" {
+		peer.Endpoint, peer.EndpointHostname, err = parseEndpoint(endpoint)
+		if err != nil {
+			return peer, fmt.Errorf("environment variable %s: %w", endpointKey, err)
+		}
+	}
+
+	allowedIPsKey := prefix + "ALLOWED_IPS"
+	if allowedIPsCSV := getCleanedEnv(allowedIPsKey); allowedIPsCSV != "" {
+		for _, allowedIP := range strings.Split(allowedIPsCSV, ",") {
+			_, ipNet, err := net.ParseCIDR(strings.TrimSpace(allowedIP))
+			if err != nil {
+				return peer, fmt.Errorf("environment variable %s: %w", allowedIPsKey, err)
+			}
+			peer.AllowedIPs = append(peer.AllowedIPs, *ipNet)
+		}
+	}
+
+	keepaliveKey := prefix + "PERSISTENT_KEEPALIVE_INTERVAL"
+	keepalive, err := envToDurationPtr(keepaliveKey)
+	if err != nil {
+		return peer, fmt.Errorf("environment variable %s: %w", keepaliveKey, err)
+	} else if keepalive != nil {
+		peer.PersistentKeepaliveInterval = *keepalive
+	}
+
+	return peer, nil
+}
+
+var ErrEndpointHostEmpty = errors.New("endpoint host is empty")
+
+// parseEndpoint parses an endpoint in the format host:port.
+// If the host is not an IP address, it is returned as hostname
+// and the address returned only has its port set.
+func parseEndpoint(s string) (address *net.UDPAddr, hostname string, err error) {
+	host, portString, err := net.SplitHostPort(s)
+	if err != nil {
+		return nil, "", err
+	} else if host == "" {
+		return nil, "", fmt.Errorf("%w: %s", ErrEndpointHostEmpty, s)
+	}
+
+	const base, bitSize = 10, 16
+	port, err := strconv.ParseUint(portString, base, bitSize)
+	if err != nil {
+		return nil, "", fmt.Errorf("parsing port: %w", err)
+	}
+	address = &net.UDPAddr{Port: int(port)}
+
+	address.IP = net.ParseIP(host)
+	if address.IP == nil {
+		hostname = host
+	}
+
+	return address, hostname, nil
+}
+
 func (s *Source) readWireguardAddresses() (addresses []net.IPNet, err error) {
 	key, addressesCSV := s.getEnvWithRetro("WIREGUARD_ADDRESSES", "WIREGUARD_ADDRESS")
+	return parseWireguardAddresses(key, addressesCSV)
+}
+
+func parseWireguardAddresses(key, addressesCSV string) (addresses []net.IPNet, err error) {
 	if addressesCSV == "" {
 		return nil, nil
 	}
@@ -0,0 +1,20 @@
+package env
+
+import (
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readWireguardObfuscation() (obfuscation settings.WireguardObfuscation, err error) {
+	defer func() {
+		err = unsetEnvKeys([]string{"WIREGUARD_OBFUSCATION_PASSWORD"}, err)
+	}()
+
+	obfuscation.Type = strings.ToLower(getCleanedEnv("WIREGUARD_OBFUSCATION"))
+	obfuscation.Shadowsocks.Server = getCleanedEnv("WIREGUARD_OBFUSCATION_SERVER")
+	obfuscation.Shadowsocks.Password = envToStringPtr("WIREGUARD_OBFUSCATION_PASSWORD")
+	obfuscation.Shadowsocks.CipherName = strings.ToLower(getCleanedEnv("WIREGUARD_OBFUSCATION_CIPHER"))
+
+	return obfuscation, nil
+}
@@ -0,0 +1,89 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readWireguardServer() (server settings.WireguardServer, err error) {
+	defer func() {
+		err = unsetEnvKeys([]string{"WIREGUARD_SERVER_PRIVATE_KEY"}, err)
+	}()
+
+	server.Enabled, err = envToBoolPtr("WIREGUARD_SERVER")
+	if err != nil {
+		return server, fmt.Errorf("environment variable WIREGUARD_SERVER: %w", err)
+	}
+
+	server.Interface = getCleanedEnv("WIREGUARD_SERVER_INTERFACE")
+
+	server.ListeningPort, err = envToUint16Ptr("WIREGUARD_SERVER_LISTENING_PORT")
+	if err != nil {
+		return server, fmt.Errorf("environment variable WIREGUARD_SERVER_LISTENING_PORT: %w", err)
+	}
+
+	server.PrivateKey = envToStringPtr("WIREGUARD_SERVER_PRIVATE_KEY")
+
+	if address := getCleanedEnv("WIREGUARD_SERVER_ADDRESS"); address != "" {
+		ip, ipNet, err := net.ParseCIDR(address)
+		if err != nil {
+			return server, fmt.Errorf("environment variable WIREGUARD_SERVER_ADDRESS: %w", err)
+		}
+		ipNet.IP = ip
+		server.Address = ipNet
+	}
+
+	server.Endpoint = envToStringPtr("WIREGUARD_SERVER_ENDPOINT")
+
+	server.Peers, err = readWireguardServerPeers()
+	if err != nil {
+		return server, err // already wrapped
+	}
+
+	return server, nil
+}
+
+// readWireguardServerPeers reads the Wireguard server peers from the
+// environment variables WIREGUARD_SERVER_PEER_1_PUBLIC_KEY,
+// WIREGUARD_SERVER_PEER_1_ADDRESS and so on, stopping at the
+// first peer number without a public key nor a private key.
+func readWireguardServerPeers() (peers []settings.WireguardServerPeer, err error) {
+	for i := 1; ; i++ {
+		prefix := "WIREGUARD_SERVER_PEER_" + fmt.Sprint(i) + "_"
+		peer, err := readWireguardServerPeer(prefix)
+		if err != nil {
+			return nil, err // already wrapped
+		} else if peer.PublicKey == "" && peer.PrivateKey == "" {
+			return peers, nil
+		}
+		peers = append(peers, peer)
+	}
+}
+
+var ErrPeerAddressNotIP = errors.New("peer address is not an IP address")
+
+func readWireguardServerPeer(prefix string) (peer settings.WireguardServerPeer, err error) {
+	privateKeyKey := prefix + "PRIVATE_KEY"
+	preSharedKeyKey := prefix + "PRESHARED_KEY"
+	defer func() {
+		err = unsetEnvKeys([]string{privateKeyKey, preSharedKeyKey}, err)
+	}()
+	peer.PrivateKey = getCleanedEnv(privateKeyKey)
+	peer.PreSharedKey = getCleanedEnv(preSharedKeyKey)
+	peer.PublicKey = getCleanedEnv(prefix + "PUBLIC_KEY")
+	peer.Name = getCleanedEnv(prefix + "NAME")
+
+	addressKey := prefix + "ADDRESS"
+	if address := getCleanedEnv(addressKey); address != "" {
+		peer.Address = net.ParseIP(address)
+		if peer.Address == nil {
+			return peer, fmt.Errorf("environment variable %s: %w: %s",
+				addressKey, ErrPeerAddressNotIP, address)
+		}
+	}
+
+	return peer, nil
+}
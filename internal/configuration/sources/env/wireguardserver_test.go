@@ -0,0 +1,40 @@
+package env
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_readWireguardServerPeers(t *testing.T) {
+	t.Setenv("WIREGUARD_SERVER_PEER_1_NAME", "phone")
+	t.Setenv("WIREGUARD_SERVER_PEER_1_PRIVATE_KEY", "private")
+	t.Setenv("WIREGUARD_SERVER_PEER_1_PRESHARED_KEY", "preshared")
+	t.Setenv("WIREGUARD_SERVER_PEER_1_ADDRESS", "10.64.0.2")
+	t.Setenv("WIREGUARD_SERVER_PEER_2_PUBLIC_KEY", "public")
+
+	peers, err := readWireguardServerPeers()
+
+	require.NoError(t, err)
+	expectedPeers := []settings.WireguardServerPeer{
+		{
+			Name:         "phone",
+			PrivateKey:   "private",
+			PreSharedKey: "preshared",
+			Address:      net.ParseIP("10.64.0.2"),
+		},
+		{PublicKey: "public"},
+	}
+	assert.Equal(t, expectedPeers, peers)
+	for _, key := range []string{
+		"WIREGUARD_SERVER_PEER_1_PRIVATE_KEY",
+		"WIREGUARD_SERVER_PEER_1_PRESHARED_KEY",
+	} {
+		_, set := os.LookupEnv(key)
+		assert.False(t, set, key)
+	}
+}
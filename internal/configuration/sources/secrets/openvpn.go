@@ -48,6 +48,14 @@ func readOpenVPN() (
 		return settings, fmt.Errorf("reading key passphrase file: %w", err)
 	}
 
+	settings.PKCS11PIN, err = readSecretFileAsStringPtr(
+		"OPENVPN_PKCS11_PIN_SECRETFILE",
+		"/run/secrets/openvpn_pkcs11_pin",
+	)
+	if err != nil {
+		return settings, fmt.Errorf("reading PKCS#11 PIN file: %w", err)
+	}
+
 	settings.Cert, err = readPEMSecretFile(
 		"OPENVPN_CLIENTCRT_SECRETFILE",
 		"/run/secrets/openvpn_clientcrt",
@@ -23,6 +23,11 @@ func (s *Source) Read() (settings settings.Settings, err error) {
 		return settings, err
 	}
 
+	settings.SOCKS5, err = readSOCKS5()
+	if err != nil {
+		return settings, err
+	}
+
 	settings.Shadowsocks, err = readShadowsocks()
 	if err != nil {
 		return settings, err
@@ -0,0 +1,27 @@
+package secrets
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readSOCKS5() (settings settings.SOCKS5, err error) {
+	settings.User, err = readSecretFileAsStringPtr(
+		"SOCKS5_USER_SECRETFILE",
+		"/run/secrets/socks5_user",
+	)
+	if err != nil {
+		return settings, fmt.Errorf("reading SOCKS5 proxy user secret file: %w", err)
+	}
+
+	settings.Password, err = readSecretFileAsStringPtr(
+		"SOCKS5_PASSWORD_SECRETFILE",
+		"/run/secrets/socks5_password",
+	)
+	if err != nil {
+		return settings, fmt.Errorf("reading SOCKS5 proxy password secret file: %w", err)
+	}
+
+	return settings, nil
+}
@@ -12,5 +12,10 @@ func readVPN() (vpn settings.VPN, err error) {
 		return vpn, fmt.Errorf("reading OpenVPN settings: %w", err)
 	}
 
+	vpn.Wireguard, err = readWireguard()
+	if err != nil {
+		return vpn, fmt.Errorf("reading Wireguard settings: %w", err)
+	}
+
 	return vpn, nil
 }
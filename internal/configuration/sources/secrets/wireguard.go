@@ -0,0 +1,28 @@
+package secrets
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func readWireguard() (
+	settings settings.Wireguard, err error) {
+	settings.PrivateKey, err = readSecretFileAsStringPtr(
+		"WIREGUARD_PRIVATE_KEY_SECRETFILE",
+		"/run/secrets/wireguard_private_key",
+	)
+	if err != nil {
+		return settings, fmt.Errorf("reading private key file: %w", err)
+	}
+
+	settings.PreSharedKey, err = readSecretFileAsStringPtr(
+		"WIREGUARD_PRESHARED_KEY_SECRETFILE",
+		"/run/secrets/wireguard_preshared_key",
+	)
+	if err != nil {
+		return settings, fmt.Errorf("reading pre-shared key file: %w", err)
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,11 @@
+package constants
+
+const (
+	// DDNSCloudflare is the Cloudflare dynamic DNS provider.
+	DDNSCloudflare = "cloudflare"
+	// DDNSDuckDNS is the DuckDNS dynamic DNS provider.
+	DDNSDuckDNS = "duckdns"
+	// DDNSDynDNS2 is any dynamic DNS provider supporting
+	// the dyndns2 update protocol, such as No-IP or Dyn.
+	DDNSDynDNS2 = "dyndns2"
+)
@@ -0,0 +1,14 @@
+package constants
+
+const (
+	// DNSUpstreamDoQ is the DNS over QUIC upstream protocol,
+	// falling back on DNS over TLS if QUIC is blocked.
+	DNSUpstreamDoQ = "doq"
+	// DNSUpstreamDoT is the DNS over TLS upstream protocol.
+	DNSUpstreamDoT = "dot"
+	// DNSUpstreamDoH is the DNS over HTTPS upstream protocol,
+	// querying the /dns-query path of the resolver.
+	DNSUpstreamDoH = "doh"
+	// DNSUpstreamDNSCrypt is the DNSCrypt version 2 upstream protocol.
+	DNSUpstreamDNSCrypt = "dnscrypt"
+)
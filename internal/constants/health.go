@@ -0,0 +1,10 @@
+package constants
+
+const (
+	// HealthModeTCP is the health check mode TCP dialing
+	// the health target addresses through the VPN.
+	HealthModeTCP = "tcp"
+	// HealthModeICMP is the health check mode pinging the
+	// VPN gateway or the ICMP targets through the VPN.
+	HealthModeICMP = "icmp"
+)
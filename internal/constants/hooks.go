@@ -0,0 +1,13 @@
+package constants
+
+const (
+	// HookPreFirewall is the hooks stage run before
+	// the firewall is enabled.
+	HookPreFirewall = "pre-firewall"
+	// HookPostTunnelUp is the hooks stage run each
+	// time the VPN tunnel is up.
+	HookPostTunnelUp = "post-tunnel-up"
+	// HookPreShutdown is the hooks stage run before
+	// the program shuts down.
+	HookPreShutdown = "pre-shutdown"
+)
@@ -0,0 +1,15 @@
+package constants
+
+// Firewall marks of the encrypted packets of the Wireguard interfaces
+// run by gluetun, which are also the numbers of their routing tables.
+const (
+	// WireguardFirewallMark is the firewall mark
+	// of the main Wireguard VPN connection.
+	WireguardFirewallMark = 51820
+	// FirstHopFirewallMark is the firewall mark of the
+	// first VPN hop, the next hops using the next numbers.
+	FirstHopFirewallMark = 51811
+	// TunnelFirewallMarkBase is added to the number of an
+	// additional tunnel, starting at 1, to obtain its firewall mark.
+	TunnelFirewallMarkBase = 51900
+)
@@ -3,6 +3,8 @@ package openvpn
 const (
 	// AuthConf is the file path to the OpenVPN auth file.
 	AuthConf = "/etc/openvpn/auth.conf"
+	// ProxyAuthConf is the file path to the OpenVPN HTTP proxy auth file.
+	ProxyAuthConf = "/etc/openvpn/proxyauth.conf"
 	// AskPassPath is the file path to the decryption passphrase for
 	// and encrypted private key, which is pointed by `askpass`.
 	AskPassPath = "/etc/openvpn/askpass" //nolint:gosec
@@ -3,4 +3,7 @@ package constants
 const (
 	// ServersData is the server information filepath.
 	ServersData = "/gluetun/servers.json"
+	// CloudflareWARPRegistration is the Cloudflare WARP
+	// device registration filepath.
+	CloudflareWARPRegistration = "/gluetun/cloudflare-warp.json"
 )
@@ -0,0 +1,8 @@
+package constants
+
+const (
+	// PortForwardingNATPMP is the port forwarding method using
+	// NAT-PMP on the VPN gateway, usable with any VPN provider
+	// whose gateway supports it.
+	PortForwardingNATPMP = "natpmp"
+)
@@ -14,6 +14,7 @@ const (
 	Ivpn                  = "ivpn"
 	Mullvad               = "mullvad"
 	Nordvpn               = "nordvpn"
+	Ovpn                  = "ovpn"
 	Perfectprivacy        = "perfect privacy"
 	Privado               = "privado"
 	PrivateInternetAccess = "private internet access"
@@ -30,6 +31,20 @@ const (
 	Windscribe            = "windscribe"
 )
 
+// Providers without servers data, which are not
+// part of the providers returned by All.
+const (
+	// CloudflareWARP is the VPN provider name for Cloudflare
+	// WARP, which only has a single anycast Wireguard endpoint.
+	CloudflareWARP = "cloudflare warp"
+	// Plugin is the VPN provider name for the provider
+	// implemented by an out-of-tree plugin executable.
+	Plugin = "plugin"
+	// UserDefined is the VPN provider name for the provider
+	// defined by the user in a provider definition file.
+	UserDefined = "userdefined"
+)
+
 // All returns all the providers except the custom provider.
 func All() []string {
 	return []string{
@@ -42,6 +57,7 @@ func All() []string {
 		Ivpn,
 		Mullvad,
 		Nordvpn,
+		Ovpn,
 		Perfectprivacy,
 		Privado,
 		PrivateInternetAccess,
@@ -0,0 +1,25 @@
+package constants
+
+const (
+	// QuotaMonthly is a traffic quota period starting
+	// on the first day of each month.
+	QuotaMonthly = "monthly"
+	// QuotaWeekly is a traffic quota period starting
+	// on each Monday.
+	QuotaWeekly = "weekly"
+)
+
+const (
+	// QuotaActionLog only logs a warning when the
+	// traffic quota is exceeded.
+	QuotaActionLog = "log"
+	// QuotaActionNotify sends a notification to a webhook
+	// URL when the traffic quota is exceeded.
+	QuotaActionNotify = "notify"
+	// QuotaActionThrottle limits the rate of the VPN
+	// traffic when the traffic quota is exceeded.
+	QuotaActionThrottle = "throttle"
+	// QuotaActionBlock blocks the VPN traffic except for
+	// some ports when the traffic quota is exceeded.
+	QuotaActionBlock = "block"
+)
@@ -0,0 +1,16 @@
+package constants
+
+const (
+	// ReadinessTunnel is the readiness condition met when the
+	// VPN is running and its last health check succeeded.
+	ReadinessTunnel = "tunnel"
+	// ReadinessPortForwarded is the readiness condition met
+	// when a port is forwarded through the VPN.
+	ReadinessPortForwarded = "port_forwarded"
+	// ReadinessDNS is the readiness condition met when the
+	// health target address hostname can be resolved.
+	ReadinessDNS = "dns"
+	// ReadinessPublicIP is the readiness condition met when
+	// the public IP address through the VPN is known.
+	ReadinessPublicIP = "public_ip"
+)
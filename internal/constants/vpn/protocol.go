@@ -1,6 +1,9 @@
 package vpn
 
 const (
-	OpenVPN   = "openvpn"
-	Wireguard = "wireguard"
+	OpenVPN     = "openvpn"
+	Wireguard   = "wireguard"
+	Shadowsocks = "shadowsocks"
+	IKEv2       = "ikev2"
+	OpenConnect = "openconnect"
 )
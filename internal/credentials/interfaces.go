@@ -0,0 +1,21 @@
+package credentials
+
+import (
+	"context"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+type SettingsReader interface {
+	Read() (settings settings.Settings, err error)
+}
+
+type VPNLooper interface {
+	GetSettings() (settings settings.VPN)
+	SetSettings(ctx context.Context, settings settings.VPN) (outcome string)
+}
+
+type Logger interface {
+	Info(s string)
+	Warn(s string)
+}
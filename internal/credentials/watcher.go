@@ -0,0 +1,117 @@
+// Package credentials watches secret files for rotated VPN
+// credentials and applies them to the running VPN.
+package credentials
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// Watcher periodically reads the secret files source and applies
+// any change to the VPN credentials to the VPN loop.
+type Watcher struct {
+	source        SettingsReader
+	vpnLooper     VPNLooper
+	storage       settings.Storage
+	ipv6Supported bool
+	period        time.Duration
+	logger        Logger
+
+	// lastCredentials are the credentials read from the secret files
+	// at the previous check, so credentials set by other sources such
+	// as environment variables are only overridden on file changes.
+	lastCredentials settings.VPN
+}
+
+// NewWatcher creates a watcher reading the secret files from
+// the source given every period.
+func NewWatcher(source SettingsReader, vpnLooper VPNLooper,
+	storage settings.Storage, ipv6Supported bool,
+	period time.Duration, logger Logger) *Watcher {
+	return &Watcher{
+		source:        source,
+		vpnLooper:     vpnLooper,
+		storage:       storage,
+		ipv6Supported: ipv6Supported,
+		period:        period,
+		logger:        logger,
+	}
+}
+
+// Run watches the secret files until the context is canceled.
+// It returns immediately if the period is 0.
+func (w *Watcher) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	if w.period == 0 {
+		return
+	}
+
+	fromSecrets, err := w.source.Read()
+	if err != nil {
+		w.logger.Warn("reading secret files: " + err.Error())
+	}
+	w.lastCredentials = credentialsOnly(fromSecrets.VPN)
+
+	ticker := time.NewTicker(w.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check(ctx)
+		}
+	}
+}
+
+func (w *Watcher) check(ctx context.Context) {
+	fromSecrets, err := w.source.Read()
+	if err != nil {
+		w.logger.Warn("reading secret files: " + err.Error())
+		return
+	}
+
+	credentials := credentialsOnly(fromSecrets.VPN)
+	if reflect.DeepEqual(credentials, w.lastCredentials) {
+		return
+	}
+	w.lastCredentials = credentials
+
+	current := w.vpnLooper.GetSettings()
+	updated := current.Copy()
+	updated.OverrideWith(credentials)
+	if reflect.DeepEqual(current, updated) {
+		return
+	}
+
+	err = updated.Validate(w.storage, w.ipv6Supported)
+	if err != nil {
+		w.logger.Warn("ignoring rotated credentials: " + err.Error())
+		return
+	}
+
+	w.logger.Info("VPN credentials changed in secret files, reconnecting")
+	outcome := w.vpnLooper.SetSettings(ctx, updated)
+	w.logger.Info(outcome)
+}
+
+// credentialsOnly returns VPN settings with only the credential
+// fields set, so other fields read from the secret files cannot
+// change the VPN settings.
+func credentialsOnly(vpn settings.VPN) (credentials settings.VPN) {
+	credentials.OpenVPN.User = vpn.OpenVPN.User
+	credentials.OpenVPN.Password = vpn.OpenVPN.Password
+	credentials.OpenVPN.Key = vpn.OpenVPN.Key
+	credentials.OpenVPN.EncryptedKey = vpn.OpenVPN.EncryptedKey
+	credentials.OpenVPN.KeyPassphrase = vpn.OpenVPN.KeyPassphrase
+	credentials.OpenVPN.PKCS11PIN = vpn.OpenVPN.PKCS11PIN
+	credentials.OpenVPN.Cert = vpn.OpenVPN.Cert
+	credentials.Wireguard.PrivateKey = vpn.Wireguard.PrivateKey
+	credentials.Wireguard.PreSharedKey = vpn.Wireguard.PreSharedKey
+	return credentials
+}
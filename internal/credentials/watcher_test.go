@@ -0,0 +1,94 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSource struct {
+	settings settings.Settings
+	err      error
+}
+
+func (s *fakeSource) Read() (settings.Settings, error) { return s.settings, s.err }
+
+type fakeVPNLooper struct {
+	settings    settings.VPN
+	setSettings []settings.VPN
+}
+
+func (l *fakeVPNLooper) GetSettings() settings.VPN { return l.settings }
+
+func (l *fakeVPNLooper) SetSettings(_ context.Context, vpn settings.VPN) string {
+	l.setSettings = append(l.setSettings, vpn)
+	return "settings updated"
+}
+
+func Test_Watcher_check_unchanged(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	password := "password"
+	source := &fakeSource{}
+	source.settings.VPN.OpenVPN.Password = &password
+	looper := &fakeVPNLooper{}
+
+	watcher := NewWatcher(source, looper, nil, false, 0, NewMockLogger(ctrl))
+	watcher.lastCredentials = credentialsOnly(source.settings.VPN)
+
+	watcher.check(context.Background())
+
+	assert.Empty(t, looper.setSettings)
+}
+
+func Test_Watcher_check_readError(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	source := &fakeSource{err: errors.New("permission denied")}
+	looper := &fakeVPNLooper{}
+	logger := NewMockLogger(ctrl)
+	logger.EXPECT().Warn("reading secret files: permission denied")
+
+	watcher := NewWatcher(source, looper, nil, false, 0, logger)
+
+	watcher.check(context.Background())
+
+	assert.Empty(t, looper.setSettings)
+}
+
+func Test_credentialsOnly(t *testing.T) {
+	t.Parallel()
+
+	user, password, privateKey := "user", "password", "key"
+	interfaceName := "tun5"
+	vpn := settings.VPN{
+		Type: "openvpn",
+		OpenVPN: settings.OpenVPN{
+			User:      &user,
+			Password:  &password,
+			Interface: interfaceName,
+		},
+		Wireguard: settings.Wireguard{
+			PrivateKey: &privateKey,
+		},
+	}
+
+	credentials := credentialsOnly(vpn)
+
+	expected := settings.VPN{
+		OpenVPN: settings.OpenVPN{
+			User:     &user,
+			Password: &password,
+		},
+		Wireguard: settings.Wireguard{
+			PrivateKey: &privateKey,
+		},
+	}
+	assert.Equal(t, expected, credentials)
+}
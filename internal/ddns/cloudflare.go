@@ -0,0 +1,137 @@
+package ddns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type cloudflare struct {
+	client *http.Client
+	apiURL string
+	token  string
+	zoneID string
+}
+
+func newCloudflare(client *http.Client, token, zoneID string) *cloudflare {
+	return &cloudflare{
+		client: client,
+		apiURL: "https://api.cloudflare.com/client/v4",
+		token:  token,
+		zoneID: zoneID,
+	}
+}
+
+type cloudflareResponse struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Result json.RawMessage `json:"result"`
+}
+
+var (
+	ErrCloudflareRequestFailed = errors.New("Cloudflare request failed")
+	ErrCloudflareNoRecord      = errors.New("Cloudflare DNS record not found")
+)
+
+func (c *cloudflare) update(ctx context.Context, hostnames []string, ip net.IP) (err error) {
+	recordType := "AAAA"
+	if ip.To4() != nil {
+		recordType = "A"
+	}
+
+	for _, hostname := range hostnames {
+		recordID, err := c.getRecordID(ctx, hostname, recordType)
+		if err != nil {
+			return fmt.Errorf("getting %s record of %s: %w", recordType, hostname, err)
+		}
+
+		body, err := json.Marshal(struct {
+			Content string `json:"content"`
+		}{Content: ip.String()})
+		if err != nil {
+			return fmt.Errorf("encoding request body: %w", err)
+		}
+
+		recordURL := c.apiURL + "/zones/" + c.zoneID + "/dns_records/" + recordID
+		err = c.request(ctx, http.MethodPatch, recordURL, body, nil)
+		if err != nil {
+			return fmt.Errorf("updating %s record of %s: %w", recordType, hostname, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *cloudflare) getRecordID(ctx context.Context,
+	hostname, recordType string) (id string, err error) {
+	values := url.Values{}
+	values.Set("type", recordType)
+	values.Set("name", hostname)
+	recordsURL := c.apiURL + "/zones/" + c.zoneID + "/dns_records?" + values.Encode()
+
+	var records []struct {
+		ID string `json:"id"`
+	}
+	err = c.request(ctx, http.MethodGet, recordsURL, nil, &records)
+	if err != nil {
+		return "", err
+	}
+
+	if len(records) == 0 {
+		return "", fmt.Errorf("%w", ErrCloudflareNoRecord)
+	}
+	return records[0].ID, nil
+}
+
+// request sends a request to the Cloudflare API and decodes
+// the result of the response into result if it is not nil.
+func (c *cloudflare) request(ctx context.Context, method, requestURL string,
+	body []byte, result interface{}) (err error) {
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+c.token)
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	var data cloudflareResponse
+	err = json.NewDecoder(response.Body).Decode(&data)
+	if err != nil {
+		return fmt.Errorf("%w: %s: decoding response: %s",
+			ErrCloudflareRequestFailed, response.Status, err)
+	}
+
+	if !data.Success {
+		messages := make([]string, len(data.Errors))
+		for i, apiError := range data.Errors {
+			messages[i] = fmt.Sprintf("%s (%d)", apiError.Message, apiError.Code)
+		}
+		return fmt.Errorf("%w: %s: %s", ErrCloudflareRequestFailed,
+			response.Status, strings.Join(messages, ", "))
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	err = json.Unmarshal(data.Result, result)
+	if err != nil {
+		return fmt.Errorf("decoding result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Package ddns updates dynamic DNS records with the
+// public IP address of the VPN tunnel.
+package ddns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+)
+
+type provider interface {
+	update(ctx context.Context, hostnames []string, ip net.IP) (err error)
+}
+
+// Updater updates the dynamic DNS records each time the
+// public IP address of the VPN tunnel changes.
+type Updater struct {
+	publicIP   PublicIPGetter
+	provider   provider
+	hostnames  []string
+	period     time.Duration
+	maxBackoff time.Duration
+	logger     Logger
+
+	// lastIP is the IP address the records were last
+	// successfully updated with.
+	lastIP net.IP
+	// backoff is the duration to wait before retrying
+	// a failed update, and is zero after a successful update.
+	backoff time.Duration
+}
+
+// New creates a dynamic DNS updater using the settings given.
+func New(settings settings.DDNS, client *http.Client,
+	publicIP PublicIPGetter, logger Logger) *Updater {
+	const period = 10 * time.Second
+	const maxBackoff = 10 * time.Minute
+	updater := &Updater{
+		publicIP:   publicIP,
+		hostnames:  settings.Hostnames,
+		period:     period,
+		maxBackoff: maxBackoff,
+		logger:     logger,
+	}
+
+	if !*settings.Enabled {
+		return updater
+	}
+
+	switch settings.Provider {
+	case constants.DDNSCloudflare:
+		updater.provider = newCloudflare(client, *settings.Token, *settings.ZoneID)
+	case constants.DDNSDuckDNS:
+		updater.provider = newDuckDNS(client, *settings.Token)
+	case constants.DDNSDynDNS2:
+		updater.provider = newDynDNS2(client, *settings.URL,
+			*settings.Username, *settings.Password)
+	}
+	return updater
+}
+
+// Run checks for public IP address changes until the context
+// is canceled. It returns immediately if dynamic DNS is disabled,
+// and stops if the provider rejects an update.
+func (u *Updater) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	if u.provider == nil {
+		return
+	}
+
+	timer := time.NewTimer(u.period)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			stop := u.check(ctx)
+			if stop {
+				return
+			}
+			timer.Reset(u.nextWait())
+		}
+	}
+}
+
+// check updates the records if the public IP address changed,
+// and returns stop as true if the provider rejected the update,
+// since retrying would keep on failing until the settings change.
+func (u *Updater) check(ctx context.Context) (stop bool) {
+	ip := u.publicIP.GetData().IP
+	if ip == nil || ip.Equal(u.lastIP) {
+		return false
+	}
+
+	err := u.provider.update(ctx, u.hostnames, ip)
+	switch {
+	case err == nil:
+	case isRejection(err):
+		u.logger.Warn("stopping dynamic DNS updates: " + err.Error())
+		return true
+	default:
+		u.increaseBackoff()
+		u.logger.Warn("updating dynamic DNS records: " + err.Error() +
+			" (retrying in " + u.backoff.String() + ")")
+		return false
+	}
+
+	u.lastIP = ip
+	u.backoff = 0
+	u.logger.Info("updated " + strings.Join(u.hostnames, ", ") + " to " + ip.String())
+	return false
+}
+
+// isRejection returns true if the error given is a rejection
+// of the update by the provider, such as invalid credentials
+// or an unknown hostname.
+func isRejection(err error) bool {
+	return errors.Is(err, ErrDynDNS2Rejected) ||
+		errors.Is(err, ErrCloudflareNoRecord)
+}
+
+// increaseBackoff doubles the backoff, starting from the period
+// and capped at the maximum backoff.
+func (u *Updater) increaseBackoff() {
+	if u.backoff == 0 {
+		u.backoff = u.period
+		return
+	}
+	u.backoff *= 2
+	if u.backoff > u.maxBackoff {
+		u.backoff = u.maxBackoff
+	}
+}
+
+func (u *Updater) nextWait() time.Duration {
+	if u.backoff == 0 {
+		return u.period
+	}
+	return u.backoff
+}
@@ -0,0 +1,211 @@
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePublicIP struct {
+	ip net.IP
+}
+
+func (f *fakePublicIP) GetData() models.PublicIP { return models.PublicIP{IP: f.ip} }
+
+type fakeProvider struct {
+	ips []net.IP
+	err error
+}
+
+func (f *fakeProvider) update(_ context.Context, _ []string, ip net.IP) error {
+	f.ips = append(f.ips, ip)
+	return f.err
+}
+
+func Test_Updater_check(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	gomock.InOrder(
+		logger.EXPECT().Warn("updating dynamic DNS records: test error (retrying in 10s)"),
+		logger.EXPECT().Info("updated home.example.com to 1.2.3.4"),
+		logger.EXPECT().Info("updated home.example.com to 5.6.7.8"),
+	)
+	publicIP := &fakePublicIP{}
+	provider := &fakeProvider{}
+	updater := &Updater{
+		publicIP:  publicIP,
+		provider:  provider,
+		hostnames: []string{"home.example.com"},
+		period:    10 * time.Second,
+		logger:    logger,
+	}
+	ctx := context.Background()
+
+	// No public IP address yet
+	assert.False(t, updater.check(ctx))
+	assert.Empty(t, provider.ips)
+
+	publicIP.ip = net.IPv4(1, 2, 3, 4)
+	provider.err = errors.New("test error")
+	assert.False(t, updater.check(ctx))
+	assert.Equal(t, 10*time.Second, updater.nextWait())
+	// Unchanged IP address is retried after a failure
+	provider.err = nil
+	assert.False(t, updater.check(ctx))
+	assert.Equal(t, 10*time.Second, updater.nextWait())
+	// Unchanged IP address after success
+	assert.False(t, updater.check(ctx))
+	publicIP.ip = net.IPv4(5, 6, 7, 8)
+	assert.False(t, updater.check(ctx))
+
+	expectedIPs := []net.IP{
+		net.IPv4(1, 2, 3, 4),
+		net.IPv4(1, 2, 3, 4),
+		net.IPv4(5, 6, 7, 8),
+	}
+	assert.Equal(t, expectedIPs, provider.ips)
+}
+
+func Test_Updater_check_backoff(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	gomock.InOrder(
+		logger.EXPECT().Warn("updating dynamic DNS records: test error (retrying in 10s)"),
+		logger.EXPECT().Warn("updating dynamic DNS records: test error (retrying in 20s)"),
+		logger.EXPECT().Warn("updating dynamic DNS records: test error (retrying in 30s)"),
+		logger.EXPECT().Warn("stopping dynamic DNS updates: "+
+			"dyndns2 update rejected: 200 OK: badauth"),
+	)
+	provider := &fakeProvider{err: errors.New("test error")}
+	updater := &Updater{
+		publicIP:   &fakePublicIP{ip: net.IPv4(1, 2, 3, 4)},
+		provider:   provider,
+		hostnames:  []string{"home.example.com"},
+		period:     10 * time.Second,
+		maxBackoff: 30 * time.Second,
+		logger:     logger,
+	}
+	ctx := context.Background()
+
+	for _, expectedWait := range []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second} {
+		assert.False(t, updater.check(ctx))
+		assert.Equal(t, expectedWait, updater.nextWait())
+	}
+
+	provider.err = fmt.Errorf("%w: 200 OK: badauth", ErrDynDNS2Rejected)
+	assert.True(t, updater.check(ctx))
+}
+
+func Test_cloudflare_update(t *testing.T) {
+	t.Parallel()
+
+	var patchBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/zones/zone/dns_records" &&
+			r.URL.RawQuery == "name=home.example.com&type=A":
+			_, _ = w.Write([]byte(`{"success":true,"result":[{"id":"record"}]}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/zones/zone/dns_records/record":
+			b, _ := io.ReadAll(r.Body)
+			patchBody = string(b)
+			_, _ = w.Write([]byte(`{"success":true,"result":{}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"success":false,"errors":[{"code":7003,"message":"bad route"}]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	provider := newCloudflare(server.Client(), "token", "zone")
+	provider.apiURL = server.URL
+
+	err := provider.update(context.Background(),
+		[]string{"home.example.com"}, net.IPv4(1, 2, 3, 4))
+
+	require.NoError(t, err)
+	assert.Equal(t, `{"content":"1.2.3.4"}`, patchBody)
+
+	err = provider.update(context.Background(),
+		[]string{"home.example.com"}, net.ParseIP("::1"))
+
+	assert.ErrorIs(t, err, ErrCloudflareRequestFailed)
+	assert.EqualError(t, err, "getting AAAA record of home.example.com: "+
+		"Cloudflare request failed: 400 Bad Request: bad route (7003)")
+}
+
+func Test_duckDNS_update(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") != "token" {
+			_, _ = w.Write([]byte("KO"))
+			return
+		}
+		assert.Equal(t, "a,b", r.URL.Query().Get("domains"))
+		assert.Equal(t, "1.2.3.4", r.URL.Query().Get("ip"))
+		_, _ = w.Write([]byte("OK"))
+	}))
+	t.Cleanup(server.Close)
+
+	provider := newDuckDNS(server.Client(), "token")
+	provider.url = server.URL
+	err := provider.update(context.Background(),
+		[]string{"a.duckdns.org", "b"}, net.IPv4(1, 2, 3, 4))
+	require.NoError(t, err)
+
+	provider.token = "wrong"
+	err = provider.update(context.Background(),
+		[]string{"a.duckdns.org"}, net.IPv4(1, 2, 3, 4))
+	assert.EqualError(t, err, "DuckDNS update failed: 200 OK: KO")
+}
+
+func Test_dynDNS2_update(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "password" {
+			_, _ = w.Write([]byte("badauth"))
+			return
+		}
+		if r.URL.Query().Get("hostname") == "down.example.com" {
+			_, _ = w.Write([]byte("911"))
+			return
+		}
+		assert.Equal(t, "a.example.com,b.example.com", r.URL.Query().Get("hostname"))
+		_, _ = w.Write([]byte("good 1.2.3.4\nnochg 1.2.3.4\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	provider := newDynDNS2(server.Client(), server.URL+"/nic/update", "user", "password")
+	err := provider.update(context.Background(),
+		[]string{"a.example.com", "b.example.com"}, net.IPv4(1, 2, 3, 4))
+	require.NoError(t, err)
+
+	err = provider.update(context.Background(),
+		[]string{"down.example.com"}, net.IPv4(1, 2, 3, 4))
+	assert.ErrorIs(t, err, ErrDynDNS2UpdateFailed)
+	assert.EqualError(t, err, "dyndns2 update failed: 200 OK: 911")
+
+	provider.password = "wrong"
+	err = provider.update(context.Background(),
+		[]string{"a.example.com"}, net.IPv4(1, 2, 3, 4))
+	assert.ErrorIs(t, err, ErrDynDNS2Rejected)
+	assert.EqualError(t, err, "dyndns2 update rejected: 200 OK: badauth")
+}
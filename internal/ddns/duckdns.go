@@ -0,0 +1,67 @@
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type duckDNS struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+func newDuckDNS(client *http.Client, token string) *duckDNS {
+	return &duckDNS{
+		client: client,
+		url:    "https://www.duckdns.org/update",
+		token:  token,
+	}
+}
+
+var ErrDuckDNSUpdateFailed = errors.New("DuckDNS update failed")
+
+func (d *duckDNS) update(ctx context.Context, hostnames []string, ip net.IP) (err error) {
+	domains := make([]string, len(hostnames))
+	for i, hostname := range hostnames {
+		domains[i] = strings.TrimSuffix(hostname, ".duckdns.org")
+	}
+
+	values := url.Values{}
+	values.Set("domains", strings.Join(domains, ","))
+	values.Set("token", d.token)
+	ipKey := "ipv6"
+	if ip.To4() != nil {
+		ipKey = "ip"
+	}
+	values.Set(ipKey, ip.String())
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		d.url+"?"+values.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	b, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("reading response body: %w", err)
+	}
+
+	body := strings.TrimSpace(string(b))
+	if response.StatusCode != http.StatusOK || body != "OK" {
+		return fmt.Errorf("%w: %s: %s", ErrDuckDNSUpdateFailed, response.Status, body)
+	}
+	return nil
+}
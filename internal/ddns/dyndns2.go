@@ -0,0 +1,88 @@
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings/helpers"
+)
+
+type dynDNS2 struct {
+	client   *http.Client
+	url      string
+	username string
+	password string
+}
+
+func newDynDNS2(client *http.Client, updateURL, username, password string) *dynDNS2 {
+	return &dynDNS2{
+		client:   client,
+		url:      updateURL,
+		username: username,
+		password: password,
+	}
+}
+
+var (
+	ErrDynDNS2UpdateFailed = errors.New("dyndns2 update failed")
+	ErrDynDNS2Rejected     = errors.New("dyndns2 update rejected")
+)
+
+// dynDNS2RejectCodes are the return codes meaning the update
+// will keep on failing until the settings are changed.
+var dynDNS2RejectCodes = []string{ //nolint:gochecknoglobals
+	"badauth", "nohost", "notfqdn", "numhost", "badagent", "!donator", "abuse",
+}
+
+func (d *dynDNS2) update(ctx context.Context, hostnames []string, ip net.IP) (err error) {
+	values := url.Values{}
+	values.Set("hostname", strings.Join(hostnames, ","))
+	values.Set("myip", ip.String())
+
+	separator := "?"
+	if strings.Contains(d.url, "?") {
+		separator = "&"
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		d.url+separator+values.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	if d.username != "" || d.password != "" {
+		request.SetBasicAuth(d.username, d.password)
+	}
+	request.Header.Set("User-Agent", "gluetun")
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	b, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("reading response body: %w", err)
+	}
+
+	// The response has one line per hostname,
+	// each starting with a return code.
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		code, _, _ := strings.Cut(line, " ")
+		switch {
+		case code == "good", code == "nochg":
+		case helpers.IsOneOf(code, dynDNS2RejectCodes...):
+			return fmt.Errorf("%w: %s: %s", ErrDynDNS2Rejected, response.Status, line)
+		default:
+			return fmt.Errorf("%w: %s: %s", ErrDynDNS2UpdateFailed, response.Status, line)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package ddns
+
+import "github.com/qdm12/gluetun/internal/models"
+
+type PublicIPGetter interface {
+	GetData() (data models.PublicIP)
+}
+
+type Logger interface {
+	Info(s string)
+	Warn(s string)
+}
@@ -0,0 +1,3 @@
+package ddns
+
+//go:generate mockgen -destination=mocks_test.go -package=$GOPACKAGE . Logger
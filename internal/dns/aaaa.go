@@ -0,0 +1,13 @@
+package dns
+
+// makeAAAAFilterLines returns the Unbound server clause lines to
+// remove AAAA records from answers, if filtering is enabled and
+// IPv6 is not supported. Marking all IPv6 addresses as private
+// makes Unbound strip them from the answers it gets upstream,
+// whilst local data records are left untouched.
+func makeAAAAFilterLines(filter, ipv6Supported bool) (lines []string) {
+	if !filter || ipv6Supported {
+		return nil
+	}
+	return []string{"  private-address: ::/0"}
+}
@@ -0,0 +1,38 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_makeAAAAFilterLines(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		filter        bool
+		ipv6Supported bool
+		lines         []string
+	}{
+		"filter_disabled": {},
+		"ipv6_supported": {
+			filter:        true,
+			ipv6Supported: true,
+		},
+		"ipv6_not_supported": {
+			filter: true,
+			lines:  []string{"  private-address: ::/0"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lines := makeAAAAFilterLines(testCase.filter, testCase.ipv6Supported)
+
+			assert.Equal(t, testCase.lines, lines)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+)
+
+// configureCache modifies the server clause of the Unbound configuration
+// file at the path given to use the cache settings given, and enables
+// the Unbound remote control on the unix socket path given to obtain
+// the cache statistics.
+func configureCache(path string, unbound settings.Unbound, controlSocket string) (err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading Unbound configuration: %w", err)
+	}
+
+	seconds := func(d time.Duration) string {
+		return strconv.Itoa(int(d / time.Second))
+	}
+	options := []struct {
+		key   string
+		value string
+	}{
+		{key: "msg-cache-size", value: strconv.FormatUint(*unbound.CacheSize, 10)},
+		{key: "rrset-cache-size", value: strconv.FormatUint(*unbound.CacheSize, 10)},
+		{key: "cache-min-ttl", value: seconds(*unbound.CacheMinTTL)},
+		{key: "cache-max-ttl", value: seconds(*unbound.CacheMaxTTL)},
+		{key: "neg-cache-size", value: strconv.FormatUint(*unbound.NegativeCacheSize, 10)},
+		{key: "cache-max-negative-ttl", value: seconds(*unbound.CacheMaxNegativeTTL)},
+		{key: "extended-statistics", value: "yes"},
+	}
+	isOption := func(trimmedLine string) bool {
+		for _, option := range options {
+			if strings.HasPrefix(trimmedLine, option.key+":") {
+				return true
+			}
+		}
+		return false
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	modified := make([]string, 0, len(lines)+len(options))
+	inServer := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(line, " ") {
+			inServer = trimmed == "server:"
+		}
+
+		if inServer && isOption(trimmed) {
+			continue
+		}
+		modified = append(modified, line)
+
+		if trimmed == "server:" {
+			for _, option := range options {
+				modified = append(modified, "  "+option.key+": "+option.value)
+			}
+		}
+	}
+
+	modified = append(modified,
+		"remote-control:",
+		"  control-enable: yes",
+		`  control-interface: "`+controlSocket+`"`,
+		"  control-use-cert: no",
+	)
+
+	const perms = os.FileMode(0644)
+	err = os.WriteFile(path, []byte(strings.Join(modified, "\n")+"\n"), perms)
+	if err != nil {
+		return fmt.Errorf("writing Unbound configuration: %w", err)
+	}
+	return nil
+}
+
+// CacheStats are the statistics of the Unbound cache
+// since Unbound last started.
+type CacheStats struct {
+	Queries           uint64  `json:"queries"`
+	CacheHits         uint64  `json:"cache_hits"`
+	CacheMisses       uint64  `json:"cache_misses"`
+	HitRatio          float64 `json:"hit_ratio"`
+	Prefetches        uint64  `json:"prefetches"`
+	MessagesCached    uint64  `json:"messages_cached"`
+	RRSetsCached      uint64  `json:"rrsets_cached"`
+	MessageCacheBytes uint64  `json:"message_cache_bytes"`
+	RRSetCacheBytes   uint64  `json:"rrset_cache_bytes"`
+}
+
+var ErrNotRunning = errors.New("DNS server is not running")
+
+// GetCacheStats returns the cache statistics obtained from Unbound
+// through its remote control, or ErrNotRunning if Unbound is not
+// running.
+func (l *Loop) GetCacheStats(ctx context.Context) (stats CacheStats, err error) {
+	if l.GetStatus() != constants.Running {
+		return stats, fmt.Errorf("%w", ErrNotRunning)
+	}
+	return fetchCacheStats(ctx, l.controlSocket)
+}
+
+var ErrRemoteControl = errors.New("Unbound remote control error")
+
+func fetchCacheStats(ctx context.Context, controlSocket string) (
+	stats CacheStats, err error) {
+	var dialer net.Dialer
+	connection, err := dialer.DialContext(ctx, "unix", controlSocket)
+	if err != nil {
+		return stats, fmt.Errorf("dialing Unbound remote control: %w", err)
+	}
+	defer connection.Close()
+
+	const timeout = 5 * time.Second
+	err = connection.SetDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return stats, fmt.Errorf("setting deadline: %w", err)
+	}
+
+	// The remote control protocol version 1 is a command line
+	// prefixed with UBCT1, and the answer is sent until the
+	// connection is closed.
+	_, err = io.WriteString(connection, "UBCT1 stats_noreset\n")
+	if err != nil {
+		return stats, fmt.Errorf("writing command: %w", err)
+	}
+
+	data, err := io.ReadAll(connection)
+	if err != nil {
+		return stats, fmt.Errorf("reading statistics: %w", err)
+	}
+
+	return parseCacheStats(string(data))
+}
+
+func parseCacheStats(data string) (stats CacheStats, err error) {
+	if strings.HasPrefix(data, "error") {
+		return stats, fmt.Errorf("%w: %s", ErrRemoteControl, strings.TrimSpace(data))
+	}
+
+	fields := map[string]*uint64{
+		"total.num.queries":   &stats.Queries,
+		"total.num.cachehits": &stats.CacheHits,
+		"total.num.cachemiss": &stats.CacheMisses,
+		"total.num.prefetch":  &stats.Prefetches,
+		"msg.cache.count":     &stats.MessagesCached,
+		"rrset.cache.count":   &stats.RRSetsCached,
+		"mem.cache.message":   &stats.MessageCacheBytes,
+		"mem.cache.rrset":     &stats.RRSetCacheBytes,
+	}
+	for _, line := range strings.Split(data, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		field, ok := fields[key]
+		if !ok {
+			continue
+		}
+		const base, bitSize = 10, 64
+		*field, err = strconv.ParseUint(value, base, bitSize)
+		if err != nil {
+			return stats, fmt.Errorf("%w: parsing %s: %s", ErrRemoteControl, key, err)
+		}
+	}
+
+	if stats.Queries > 0 {
+		stats.HitRatio = float64(stats.CacheHits) / float64(stats.Queries)
+	}
+	return stats, nil
+}
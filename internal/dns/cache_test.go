@@ -0,0 +1,122 @@
+package dns
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_configureCache(t *testing.T) {
+	t.Parallel()
+
+	const conf = `server:
+  cache-max-ttl: 9000
+  cache-min-ttl: 3600
+  msg-cache-size: 8m
+  port: 53
+  rrset-cache-size: 8m
+forward-zone:
+  name: "."`
+	path := filepath.Join(t.TempDir(), "unbound.conf")
+	err := os.WriteFile(path, []byte(conf), 0600)
+	require.NoError(t, err)
+
+	cacheSize, negativeCacheSize := uint64(16<<20), uint64(2<<20)
+	minTTL, maxTTL, maxNegativeTTL := time.Minute, time.Hour, 5*time.Minute
+	unbound := settings.Unbound{
+		CacheSize:           &cacheSize,
+		CacheMinTTL:         &minTTL,
+		CacheMaxTTL:         &maxTTL,
+		NegativeCacheSize:   &negativeCacheSize,
+		CacheMaxNegativeTTL: &maxNegativeTTL,
+	}
+
+	err = configureCache(path, unbound, "/etc/unbound/control.sock")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	const expected = `server:
+  msg-cache-size: 16777216
+  rrset-cache-size: 16777216
+  cache-min-ttl: 60
+  cache-max-ttl: 3600
+  neg-cache-size: 2097152
+  cache-max-negative-ttl: 300
+  extended-statistics: yes
+  port: 53
+forward-zone:
+  name: "."
+remote-control:
+  control-enable: yes
+  control-interface: "/etc/unbound/control.sock"
+  control-use-cert: no
+`
+	assert.Equal(t, expected, string(data))
+}
+
+func Test_fetchCacheStats(t *testing.T) {
+	t.Parallel()
+
+	controlSocket := filepath.Join(t.TempDir(), "control.sock")
+	listener, err := net.Listen("unix", controlSocket)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	commands := make(chan string, 1)
+	go func() {
+		connection, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer connection.Close()
+		command, _ := bufio.NewReader(connection).ReadString('\n')
+		commands <- command
+		_, _ = connection.Write([]byte("thread0.num.queries=10\n" +
+			"total.num.queries=10\n" +
+			"total.num.cachehits=8\n" +
+			"total.num.cachemiss=2\n" +
+			"total.num.prefetch=1\n" +
+			"total.requestlist.avg=0.5\n" +
+			"mem.cache.rrset=123456\n" +
+			"mem.cache.message=65432\n" +
+			"msg.cache.count=5\n" +
+			"rrset.cache.count=12\n"))
+	}()
+
+	stats, err := fetchCacheStats(context.Background(), controlSocket)
+	require.NoError(t, err)
+
+	expected := CacheStats{
+		Queries:           10,
+		CacheHits:         8,
+		CacheMisses:       2,
+		HitRatio:          0.8,
+		Prefetches:        1,
+		MessagesCached:    5,
+		RRSetsCached:      12,
+		MessageCacheBytes: 65432,
+		RRSetCacheBytes:   123456,
+	}
+	assert.Equal(t, expected, stats)
+	assert.Equal(t, "UBCT1 stats_noreset\n", <-commands)
+}
+
+func Test_parseCacheStats(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseCacheStats("error command not allowed\n")
+	assert.ErrorIs(t, err, ErrRemoteControl)
+	assert.EqualError(t, err, "Unbound remote control error: error command not allowed")
+
+	_, err = parseCacheStats("total.num.queries=abc\n")
+	assert.ErrorIs(t, err, ErrRemoteControl)
+}
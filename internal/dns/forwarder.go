@@ -0,0 +1,93 @@
+package dns
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// forwarderAddress is the listening address of the local forwarder
+// Unbound forwards queries to if DNS upstreams or bypass domains are set.
+const forwarderAddress = "127.0.0.1:5053"
+
+// useForwarder modifies the forward zone of the Unbound configuration
+// file at the path given to forward all queries over TCP to the local
+// forwarder address given, instead of to the DNS over TLS servers
+// of the providers.
+func useForwarder(path, address string) (err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading Unbound configuration: %w", err)
+	}
+
+	host, port, _ := strings.Cut(address, ":")
+	lines := strings.Split(string(data), "\n")
+	modified := make([]string, 0, len(lines))
+	inForwardZone := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !inForwardZone {
+			modified = append(modified, line)
+			if trimmed == "forward-zone:" {
+				inForwardZone = true
+				modified = append(modified,
+					"  forward-tcp-upstream: yes",
+					"  forward-addr: "+host+"@"+port)
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "forward-tls-upstream:") ||
+			strings.HasPrefix(trimmed, "forward-addr:") {
+			continue
+		}
+		modified = append(modified, line)
+	}
+
+	const perms = os.FileMode(0644)
+	err = os.WriteFile(path, []byte(strings.Join(modified, "\n")), perms)
+	if err != nil {
+		return fmt.Errorf("writing Unbound configuration: %w", err)
+	}
+	return nil
+}
+
+// addForwardZones appends a forward zone for each of the domains given
+// to the Unbound configuration file at the path given, to forward their
+// queries over TCP to the local forwarder address given, which records
+// the IP addresses answered. It is a no-op if no domain is given.
+func addForwardZones(path string, domains []string, address string) (err error) {
+	if len(domains) == 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading Unbound configuration: %w", err)
+	}
+
+	host, port, _ := strings.Cut(address, ":")
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	for _, domain := range domains {
+		lines = append(lines,
+			"forward-zone:",
+			`  name: "`+strings.TrimSuffix(domain, ".")+`."`,
+			"  forward-tcp-upstream: yes",
+			"  forward-addr: "+host+"@"+port)
+	}
+
+	const perms = os.FileMode(0644)
+	err = os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), perms)
+	if err != nil {
+		return fmt.Errorf("writing Unbound configuration: %w", err)
+	}
+	return nil
+}
+
+// SetBypassDomains sets the domains whose queries are forwarded to
+// the local forwarder, for the recorder given to record the IP
+// addresses answered for them. It must be called before Run.
+func (l *Loop) SetBypassDomains(domains []string, recorder AnswerRecorder) {
+	l.bypassDomains = domains
+	l.answerRecorder = recorder
+}
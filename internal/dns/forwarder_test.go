@@ -0,0 +1,83 @@
+package dns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_useForwarder(t *testing.T) {
+	t.Parallel()
+
+	const conf = `server:
+  port: 53
+  include: "/etc/unbound/include.conf"
+forward-zone:
+  forward-no-cache: no
+  forward-tls-upstream: yes
+  name: "."
+  forward-addr: 1.1.1.1@853#cloudflare-dns.com
+  forward-addr: 1.0.0.1@853#cloudflare-dns.com`
+	path := filepath.Join(t.TempDir(), "unbound.conf")
+	err := os.WriteFile(path, []byte(conf), 0600)
+	require.NoError(t, err)
+
+	err = useForwarder(path, "127.0.0.1:5053")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	const expected = `server:
+  port: 53
+  include: "/etc/unbound/include.conf"
+forward-zone:
+  forward-tcp-upstream: yes
+  forward-addr: 127.0.0.1@5053
+  forward-no-cache: no
+  name: "."`
+	assert.Equal(t, expected, string(data))
+}
+
+func Test_addForwardZones(t *testing.T) {
+	t.Parallel()
+
+	const conf = `server:
+  port: 53
+forward-zone:
+  name: "."
+  forward-addr: 1.1.1.1@853#cloudflare-dns.com
+`
+	path := filepath.Join(t.TempDir(), "unbound.conf")
+	err := os.WriteFile(path, []byte(conf), 0600)
+	require.NoError(t, err)
+
+	err = addForwardZones(path, nil, "127.0.0.1:5053")
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, conf, string(data))
+
+	err = addForwardZones(path, []string{"bank.example.com", "example.org."}, "127.0.0.1:5053")
+	require.NoError(t, err)
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	const expected = `server:
+  port: 53
+forward-zone:
+  name: "."
+  forward-addr: 1.1.1.1@853#cloudflare-dns.com
+forward-zone:
+  name: "bank.example.com."
+  forward-tcp-upstream: yes
+  forward-addr: 127.0.0.1@5053
+forward-zone:
+  name: "example.org."
+  forward-tcp-upstream: yes
+  forward-addr: 127.0.0.1@5053
+`
+	assert.Equal(t, expected, string(data))
+}
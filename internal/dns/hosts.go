@@ -0,0 +1,219 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+)
+
+// RuntimeHosts are hostnames blocked or allowed at runtime through
+// the control server, on top of the DNS blacklist settings.
+type RuntimeHosts struct {
+	Blocked []string `json:"blocked"`
+	Allowed []string `json:"allowed"`
+}
+
+// runtimeHostsStore persists the runtime hosts to a JSON file,
+// so they are kept across restarts of gluetun.
+type runtimeHostsStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+func (s *runtimeHostsStore) get() (hosts RuntimeHosts, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.read()
+}
+
+func (s *runtimeHostsStore) read() (hosts RuntimeHosts, err error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return RuntimeHosts{Blocked: []string{}, Allowed: []string{}}, nil
+	} else if err != nil {
+		return hosts, fmt.Errorf("reading runtime hosts file: %w", err)
+	}
+
+	err = json.Unmarshal(data, &hosts)
+	if err != nil {
+		return hosts, fmt.Errorf("decoding runtime hosts file: %w", err)
+	}
+	return hosts, nil
+}
+
+// modify reads the runtime hosts, modifies them with the function
+// given and writes them back to the file if they changed.
+func (s *runtimeHostsStore) modify(modifyFunc func(hosts *RuntimeHosts) (changed bool)) (
+	changed bool, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	hosts, err := s.read()
+	if err != nil {
+		return false, err
+	}
+
+	changed = modifyFunc(&hosts)
+	if !changed {
+		return false, nil
+	}
+
+	data, err := json.MarshalIndent(hosts, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("encoding runtime hosts: %w", err)
+	}
+
+	const dirPerms = os.FileMode(0700)
+	err = os.MkdirAll(filepath.Dir(s.path), dirPerms)
+	if err != nil {
+		return false, fmt.Errorf("creating runtime hosts file directory: %w", err)
+	}
+
+	err = writeFileAtomically(s.path, data)
+	if err != nil {
+		return false, fmt.Errorf("writing runtime hosts file: %w", err)
+	}
+	return true, nil
+}
+
+// writeFileAtomically writes the data to a temporary file with
+// permissions 0600 in the directory of the path given, and renames
+// it to the path, so the file is never left partially written.
+func writeFileAtomically(path string, data []byte) (err error) {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	temporaryPath := file.Name()
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(temporaryPath)
+		return fmt.Errorf("writing temporary file: %w", err)
+	}
+
+	err = os.Rename(temporaryPath, path)
+	if err != nil {
+		_ = os.Remove(temporaryPath)
+		return fmt.Errorf("renaming temporary file: %w", err)
+	}
+
+	return nil
+}
+
+// mergeHosts returns a new slice containing the settings
+// hosts followed by the runtime hosts, so the settings slice
+// backing array is never modified.
+func mergeHosts(settingsHosts, runtimeHosts []string) (merged []string) {
+	merged = make([]string, 0, len(settingsHosts)+len(runtimeHosts))
+	merged = append(merged, settingsHosts...)
+	return append(merged, runtimeHosts...)
+}
+
+func addHost(hosts []string, host string) (result []string, added bool) {
+	for _, existing := range hosts {
+		if existing == host {
+			return hosts, false
+		}
+	}
+	hosts = append(hosts, host)
+	sort.Strings(hosts)
+	return hosts, true
+}
+
+func removeHost(hosts []string, host string) (result []string, removed bool) {
+	for i, existing := range hosts {
+		if existing == host {
+			return append(hosts[:i], hosts[i+1:]...), true
+		}
+	}
+	return hosts, false
+}
+
+var (
+	ErrHostNotValid = errors.New("host is not valid")
+	ErrHostUnknown  = errors.New("host is not in the list")
+)
+
+// GetRuntimeHosts returns the hostnames blocked and allowed at runtime.
+func (l *Loop) GetRuntimeHosts() (hosts RuntimeHosts, err error) {
+	return l.runtimeHosts.get()
+}
+
+// BlockHost adds the host to the hostnames blocked at runtime,
+// removing it from the hostnames allowed at runtime if needed,
+// and restarts Unbound if it is running.
+func (l *Loop) BlockHost(ctx context.Context, host string) (outcome string, err error) {
+	return l.setRuntimeHost(ctx, host, true, true)
+}
+
+// UnblockHost removes the host from the hostnames blocked at runtime,
+// and restarts Unbound if it is running.
+func (l *Loop) UnblockHost(ctx context.Context, host string) (outcome string, err error) {
+	return l.setRuntimeHost(ctx, host, true, false)
+}
+
+// AllowHost adds the host to the hostnames allowed at runtime,
+// removing it from the hostnames blocked at runtime if needed,
+// and restarts Unbound if it is running.
+func (l *Loop) AllowHost(ctx context.Context, host string) (outcome string, err error) {
+	return l.setRuntimeHost(ctx, host, false, true)
+}
+
+// DisallowHost removes the host from the hostnames allowed at runtime,
+// and restarts Unbound if it is running.
+func (l *Loop) DisallowHost(ctx context.Context, host string) (outcome string, err error) {
+	return l.setRuntimeHost(ctx, host, false, false)
+}
+
+func (l *Loop) setRuntimeHost(ctx context.Context, host string,
+	blocked, add bool) (outcome string, err error) {
+	if !settings.IsValidHost(host) {
+		return "", fmt.Errorf("%w: %s", ErrHostNotValid, host)
+	}
+
+	changed, err := l.runtimeHosts.modify(func(hosts *RuntimeHosts) (changed bool) {
+		list, other := &hosts.Allowed, &hosts.Blocked
+		if blocked {
+			list, other = other, list
+		}
+		if !add {
+			*list, changed = removeHost(*list, host)
+			return changed
+		}
+		var removed bool
+		*other, removed = removeHost(*other, host)
+		*list, changed = addHost(*list, host)
+		return changed || removed
+	})
+	switch {
+	case err != nil:
+		return "", err
+	case !changed && !add:
+		return "", fmt.Errorf("%w: %s", ErrHostUnknown, host)
+	case !changed:
+		return "hosts left unchanged", nil
+	}
+
+	if l.GetStatus() != constants.Running {
+		return "hosts updated", nil
+	}
+
+	_, _ = l.statusManager.ApplyStatus(ctx, constants.Stopped)
+	return l.statusManager.ApplyStatus(ctx, constants.Running)
+}
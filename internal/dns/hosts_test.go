@@ -0,0 +1,84 @@
+package dns
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/loopstate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Loop_runtimeHosts(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "dir", "dns_hosts.json")
+	loop := &Loop{
+		statusManager: loopstate.New(constants.Stopped, nil, nil, nil, nil),
+		runtimeHosts:  &runtimeHostsStore{path: path},
+	}
+	ctx := context.Background()
+
+	hosts, err := loop.GetRuntimeHosts()
+	require.NoError(t, err)
+	assert.Equal(t, RuntimeHosts{Blocked: []string{}, Allowed: []string{}}, hosts)
+
+	outcome, err := loop.BlockHost(ctx, "b.com")
+	require.NoError(t, err)
+	assert.Equal(t, "hosts updated", outcome)
+	_, err = loop.BlockHost(ctx, "a.com")
+	require.NoError(t, err)
+	outcome, err = loop.BlockHost(ctx, "a.com")
+	require.NoError(t, err)
+	assert.Equal(t, "hosts left unchanged", outcome)
+
+	// Allowing a blocked host removes it from the blocked hosts.
+	_, err = loop.AllowHost(ctx, "b.com")
+	require.NoError(t, err)
+
+	hosts, err = loop.GetRuntimeHosts()
+	require.NoError(t, err)
+	assert.Equal(t, RuntimeHosts{Blocked: []string{"a.com"}, Allowed: []string{"b.com"}}, hosts)
+
+	_, err = loop.UnblockHost(ctx, "b.com")
+	assert.ErrorIs(t, err, ErrHostUnknown)
+	_, err = loop.DisallowHost(ctx, "b.com")
+	require.NoError(t, err)
+
+	_, err = loop.BlockHost(ctx, "not a host")
+	assert.ErrorIs(t, err, ErrHostNotValid)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	const expectedData = `{
+  "blocked": [
+    "a.com"
+  ],
+  "allowed": []
+}`
+	assert.Equal(t, expectedData, string(data))
+
+	// No temporary file is left behind.
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "dns_hosts.json", entries[0].Name())
+	info, err := entries[0].Info()
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func Test_mergeHosts(t *testing.T) {
+	t.Parallel()
+
+	settingsHosts := make([]string, 1, 2)
+	settingsHosts[0] = "a.com"
+
+	merged := mergeHosts(settingsHosts, []string{"b.com"})
+
+	assert.Equal(t, []string{"a.com", "b.com"}, merged)
+	assert.Equal(t, []string{"a.com", ""}, settingsHosts[:2])
+}
@@ -2,6 +2,7 @@ package dns
 
 import (
 	"context"
+	"net"
 
 	"github.com/qdm12/dns/pkg/unbound"
 )
@@ -13,3 +14,7 @@ type Configurator interface {
 		stdoutLines, stderrLines chan string, waitError chan error, err error)
 	Version(ctx context.Context) (version string, err error)
 }
+
+type AnswerRecorder interface {
+	RecordAnswer(name string, ips []net.IP)
+}
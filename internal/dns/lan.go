@@ -0,0 +1,60 @@
+package dns
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// configureListening modifies the server clause of the Unbound
+// configuration file at the path given so Unbound only listens on
+// 127.0.0.1 if serving LAN clients is disabled. If it is enabled and
+// subnets are set, these subnets are appended to the access control
+// lines of the allowed networks already set.
+func configureListening(path string, lan settings.DNSLAN) (err error) {
+	if *lan.Enabled && len(lan.Subnets) == 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading Unbound configuration: %w", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	lastAccessControl := -1
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "access-control:") {
+			lastAccessControl = i
+		}
+	}
+
+	modified := make([]string, 0, len(lines)+len(lan.Subnets))
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case !*lan.Enabled && strings.HasPrefix(trimmed, "interface:"):
+			modified = append(modified, "  interface: 127.0.0.1")
+		case !*lan.Enabled && strings.HasPrefix(trimmed, "access-control:"):
+			if i == lastAccessControl {
+				modified = append(modified, "  access-control: 127.0.0.1/8 allow")
+			}
+		default:
+			modified = append(modified, line)
+		}
+		if *lan.Enabled && i == lastAccessControl {
+			for _, subnet := range lan.Subnets {
+				modified = append(modified, "  access-control: "+subnet.String()+" allow")
+			}
+		}
+	}
+
+	const perms = os.FileMode(0644)
+	err = os.WriteFile(path, []byte(strings.Join(modified, "\n")), perms)
+	if err != nil {
+		return fmt.Errorf("writing Unbound configuration: %w", err)
+	}
+	return nil
+}
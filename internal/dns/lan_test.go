@@ -0,0 +1,80 @@
+package dns
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_configureListening(t *testing.T) {
+	t.Parallel()
+
+	const conf = `server:
+  interface: 0.0.0.0
+  port: 53
+  access-control: 0.0.0.0/0 allow
+  access-control: ::/0 allow
+forward-zone:
+  name: "."`
+
+	boolPtr := func(b bool) *bool { return &b }
+
+	testCases := map[string]struct {
+		lan      settings.DNSLAN
+		expected string
+	}{
+		"disabled": {
+			lan: settings.DNSLAN{Enabled: boolPtr(false)},
+			expected: `server:
+  interface: 127.0.0.1
+  port: 53
+  access-control: 127.0.0.1/8 allow
+forward-zone:
+  name: "."`,
+		},
+		"enabled_without_subnets": {
+			lan:      settings.DNSLAN{Enabled: boolPtr(true)},
+			expected: conf,
+		},
+		"enabled_with_subnets": {
+			lan: settings.DNSLAN{
+				Enabled: boolPtr(true),
+				Subnets: []net.IPNet{{
+					IP:   net.IPv4(192, 168, 1, 0),
+					Mask: net.IPv4Mask(255, 255, 255, 0),
+				}},
+			},
+			expected: `server:
+  interface: 0.0.0.0
+  port: 53
+  access-control: 0.0.0.0/0 allow
+  access-control: ::/0 allow
+  access-control: 192.168.1.0/24 allow
+forward-zone:
+  name: "."`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "unbound.conf")
+			err := os.WriteFile(path, []byte(conf), 0600)
+			require.NoError(t, err)
+
+			err = configureListening(path, testCase.lan)
+			require.NoError(t, err)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, string(data))
+		})
+	}
+}
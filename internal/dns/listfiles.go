@@ -0,0 +1,90 @@
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// readListFiles returns the hostnames listed in the files at the
+// paths given, logging how many were read from each file. Files
+// which cannot be read are skipped with a warning.
+func readListFiles(paths []string, listName string, logger Logger) (hosts []string) {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Warn("skipping " + listName + " file: " + err.Error())
+			continue
+		}
+
+		fileHosts, skipped := parseHostsList(data)
+		message := fmt.Sprintf("read %d hostnames from %s file %s",
+			len(fileHosts), listName, path)
+		if skipped > 0 {
+			message += fmt.Sprintf(" (%d lines not valid skipped)", skipped)
+		}
+		logger.Info(message)
+		hosts = append(hosts, fileHosts...)
+	}
+	return hosts
+}
+
+// hostsFileLocalNames are hostnames commonly found in hosts format
+// block lists which must not be blocked or allowed.
+var hostsFileLocalNames = map[string]struct{}{ //nolint:gochecknoglobals
+	"localhost":             {},
+	"localhost.localdomain": {},
+	"local":                 {},
+	"broadcasthost":         {},
+	"ip6-localhost":         {},
+	"ip6-loopback":          {},
+	"ip6-localnet":          {},
+	"ip6-mcastprefix":       {},
+	"ip6-allnodes":          {},
+	"ip6-allrouters":        {},
+	"ip6-allhosts":          {},
+}
+
+// parseHostsList parses the data given where each line is either
+// in the hosts format, such as 0.0.0.0 ads.example.com, or is a
+// single hostname. Lines with a hostname not valid are skipped
+// and counted. Comments start with #.
+func parseHostsList(data []byte) (hosts []string, skipped int) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if net.ParseIP(fields[0]) != nil {
+			fields = fields[1:]
+		} else if len(fields) > 1 {
+			skipped++
+			continue
+		}
+
+		lineValid := len(fields) > 0
+		for _, host := range fields {
+			host = strings.ToLower(strings.TrimSuffix(host, "."))
+			if _, ok := hostsFileLocalNames[host]; ok {
+				continue
+			}
+			if !settings.IsValidHost(host) {
+				lineValid = false
+				continue
+			}
+			hosts = append(hosts, host)
+		}
+		if !lineValid {
+			skipped++
+		}
+	}
+	return hosts, skipped
+}
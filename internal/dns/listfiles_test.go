@@ -0,0 +1,47 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseHostsList(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		data    string
+		hosts   []string
+		skipped int
+	}{
+		"empty": {},
+		"hosts_format": {
+			data: "# Title: block list\n" +
+				"127.0.0.1 localhost\n" +
+				"::1 localhost ip6-localhost\n" +
+				"0.0.0.0 ads.example.com Tracker.example.com # trackers\n",
+			hosts: []string{"ads.example.com", "tracker.example.com"},
+		},
+		"domain_per_line": {
+			data:  "ads.example.com\n\n  tracker.example.com.\n",
+			hosts: []string{"ads.example.com", "tracker.example.com"},
+		},
+		"invalid_lines": {
+			data:    "0.0.0.0\nnot valid!\nads.example.com\n",
+			hosts:   []string{"ads.example.com"},
+			skipped: 2,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			hosts, skipped := parseHostsList([]byte(testCase.data))
+
+			assert.Equal(t, testCase.hosts, hosts)
+			assert.Equal(t, testCase.skipped, skipped)
+		})
+	}
+}
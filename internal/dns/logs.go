@@ -18,7 +18,7 @@ const (
 )
 
 func (l *Loop) collectLines(ctx context.Context, done chan<- struct{},
-	stdout, stderr chan string) {
+	stdout, stderr chan string, filter queryLogFilter) {
 	defer close(done)
 
 	var line string
@@ -35,6 +35,10 @@ func (l *Loop) collectLines(ctx context.Context, done chan<- struct{},
 		case line = <-stdout:
 		}
 
+		if !filter.keep(line) {
+			continue
+		}
+
 		line, level := processLogLine(line)
 		switch level {
 		case levelDebug:
@@ -2,6 +2,7 @@ package dns
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"time"
 
@@ -18,23 +19,33 @@ type Loop struct {
 	state         *state.State
 	conf          Configurator
 	resolvConf    string
+	includeConf   string
+	unboundConf   string
+	controlSocket string
+	runtimeHosts  *runtimeHostsStore
 	blockBuilder  blacklist.Builder
 	client        *http.Client
-	logger        Logger
-	userTrigger   bool
-	start         <-chan struct{}
-	running       chan<- models.LoopStatus
-	stop          <-chan struct{}
-	stopped       chan<- struct{}
-	updateTicker  <-chan struct{}
-	backoffTime   time.Duration
-	timeNow       func() time.Time
-	timeSince     func(time.Time) time.Duration
+	ipLookuper    ipLookuper
+	ipv6Supported bool
+	// bypassDomains are the domains whose answered IP addresses
+	// are recorded by the answerRecorder, see SetBypassDomains.
+	bypassDomains  []string
+	answerRecorder AnswerRecorder
+	logger         Logger
+	userTrigger    bool
+	start          <-chan struct{}
+	running        chan<- models.LoopStatus
+	stop           <-chan struct{}
+	stopped        chan<- struct{}
+	updateTicker   <-chan struct{}
+	backoffTime    time.Duration
+	timeNow        func() time.Time
+	timeSince      func(time.Time) time.Duration
 }
 
 const defaultBackoffTime = 10 * time.Second
 
-func NewLoop(conf Configurator, settings settings.DNS,
+func NewLoop(conf Configurator, settings settings.DNS, ipv6Supported bool,
 	client *http.Client, logger Logger) *Loop {
 	start := make(chan struct{})
 	running := make(chan models.LoopStatus)
@@ -50,8 +61,14 @@ func NewLoop(conf Configurator, settings settings.DNS,
 		state:         state,
 		conf:          conf,
 		resolvConf:    "/etc/resolv.conf",
+		includeConf:   "/etc/unbound/include.conf",
+		unboundConf:   "/etc/unbound/unbound.conf",
+		controlSocket: "/etc/unbound/control.sock",
+		runtimeHosts:  &runtimeHostsStore{path: "/gluetun/dns_hosts.json"},
 		blockBuilder:  blacklist.NewBuilder(client),
 		client:        client,
+		ipLookuper:    net.DefaultResolver,
+		ipv6Supported: ipv6Supported,
 		logger:        logger,
 		userTrigger:   true,
 		start:         start,
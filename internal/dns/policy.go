@@ -0,0 +1,205 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/qdm12/dns/pkg/blacklist"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"inet.af/netaddr"
+)
+
+// safeSearchHosts maps search engine hostnames to the hostname
+// answering with their safe search enforced version.
+var safeSearchHosts = map[string]string{ //nolint:gochecknoglobals
+	"www.google.com":           "forcesafesearch.google.com",
+	"www.bing.com":             "strict.bing.com",
+	"duckduckgo.com":           "safe.duckduckgo.com",
+	"www.youtube.com":          "restrict.youtube.com",
+	"m.youtube.com":            "restrict.youtube.com",
+	"youtubei.googleapis.com":  "restrict.youtube.com",
+	"youtube.googleapis.com":   "restrict.youtube.com",
+	"www.youtube-nocookie.com": "restrict.youtube.com",
+}
+
+func policyTag(index int) string {
+	return "policy" + fmt.Sprint(index+1)
+}
+
+// makePolicyLines returns Unbound server clause lines to apply the
+// client policies given. Each policy is an Unbound tag assigned to
+// its client subnets, and the local zones it requires are only
+// applied to clients with a matching tag. Hostnames already blocked
+// for all clients are not repeated since an Unbound local zone can
+// only be defined once.
+func makePolicyLines(ctx context.Context, builder blacklist.Builder,
+	lookuper ipLookuper, policies []settings.DNSPolicy,
+	globallyBlocked, allowedHosts []string, logger Logger) (lines []string) {
+	if len(policies) == 0 {
+		return nil
+	}
+
+	tags := make([]string, len(policies))
+	for i := range policies {
+		tags[i] = policyTag(i)
+	}
+	lines = append(lines, `  define-tag: "`+strings.Join(tags, " ")+`"`)
+
+	subnets := make([]string, 0, len(policies))
+	subnetToTags := make(map[string][]string)
+	for i, policy := range policies {
+		for _, subnet := range policy.Subnets {
+			key := subnet.String()
+			if _, ok := subnetToTags[key]; !ok {
+				subnets = append(subnets, key)
+			}
+			subnetToTags[key] = append(subnetToTags[key], tags[i])
+		}
+	}
+	for _, subnet := range subnets {
+		lines = append(lines,
+			"  access-control: "+subnet+" allow",
+			"  access-control-tag: "+subnet+` "`+strings.Join(subnetToTags[subnet], " ")+`"`)
+	}
+
+	safeSearchZoneTags := make(map[string][]string)
+	blockedZoneTags := make(map[string][]string)
+	globallyBlockedSet := make(map[string]struct{}, len(globallyBlocked))
+	for _, host := range globallyBlocked {
+		globallyBlockedSet[strings.TrimSuffix(host, ".")] = struct{}{}
+	}
+	type categories struct{ malicious, ads, surveillance bool }
+	categoriesToHosts := make(map[categories][]string)
+	for i, policy := range policies {
+		if *policy.SafeSearch {
+			for host := range safeSearchHosts {
+				safeSearchZoneTags[host] = append(safeSearchZoneTags[host], tags[i])
+			}
+		}
+
+		key := categories{
+			malicious:    *policy.BlockMalicious,
+			ads:          *policy.BlockAds,
+			surveillance: *policy.BlockSurveillance,
+		}
+		if key == (categories{}) {
+			continue
+		}
+
+		hosts, ok := categoriesToHosts[key]
+		if !ok {
+			var errs []error
+			hosts, errs = builder.Hostnames(ctx, key.malicious, key.ads,
+				key.surveillance, nil, allowedHosts)
+			for _, err := range errs {
+				logger.Warn("client " + tags[i] + ": " + err.Error())
+			}
+			categoriesToHosts[key] = hosts
+		}
+
+		for _, host := range hosts {
+			host = strings.TrimSuffix(host, ".")
+			if _, blocked := globallyBlockedSet[host]; blocked {
+				continue
+			}
+			blockedZoneTags[host] = append(blockedZoneTags[host], tags[i])
+		}
+	}
+
+	for _, host := range sortedKeys(blockedZoneTags) {
+		if _, ok := safeSearchZoneTags[host]; ok {
+			continue
+		}
+		lines = append(lines,
+			`  local-zone: "`+host+`." static`,
+			`  local-zone-tag: "`+host+`." "`+strings.Join(blockedZoneTags[host], " ")+`"`)
+	}
+
+	targetToIPs := make(map[string][]net.IP)
+	for _, host := range sortedKeys(safeSearchZoneTags) {
+		target := safeSearchHosts[host]
+		ips, ok := targetToIPs[target]
+		if !ok {
+			var err error
+			ips, err = lookuper.LookupIP(ctx, "ip", target)
+			if err != nil {
+				logger.Warn("skipping safe search for " + host +
+					": resolving " + target + ": " + err.Error())
+			}
+			targetToIPs[target] = ips
+		}
+		if len(ips) == 0 {
+			continue
+		}
+
+		lines = append(lines,
+			`  local-zone: "`+host+`." redirect`,
+			`  local-zone-tag: "`+host+`." "`+strings.Join(safeSearchZoneTags[host], " ")+`"`)
+		lines = append(lines, makeLocalDataLines(host+".", ips)...)
+	}
+
+	for _, policy := range policies {
+		if *policy.LogQueries {
+			lines = append(lines, "  log-queries: yes")
+			break
+		}
+	}
+
+	return lines
+}
+
+func sortedKeys(m map[string][]string) (keys []string) {
+	keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// queryLogFilter filters out Unbound query log lines from
+// clients not belonging to a policy logging queries.
+type queryLogFilter struct {
+	subnets []netaddr.IPPrefix
+}
+
+func newQueryLogFilter(policies []settings.DNSPolicy) (filter queryLogFilter) {
+	for _, policy := range policies {
+		if *policy.LogQueries {
+			filter.subnets = append(filter.subnets, policy.Subnets...)
+		}
+	}
+	return filter
+}
+
+// keep returns false if the log line is a query log line
+// from a client not in one of the filter subnets.
+// Query log lines are in the format
+// "info: 172.17.0.2 example.com. A IN".
+func (f queryLogFilter) keep(line string) bool {
+	_, query, ok := strings.Cut(line, "info: ")
+	if !ok {
+		return true
+	}
+
+	fields := strings.Fields(query)
+	const queryFields = 4
+	if len(fields) != queryFields || !strings.HasSuffix(fields[1], ".") {
+		return true
+	}
+
+	ip, err := netaddr.ParseIP(fields[0])
+	if err != nil {
+		return true
+	}
+
+	for _, subnet := range f.subnets {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
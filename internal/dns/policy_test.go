@@ -0,0 +1,128 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/dns/pkg/blacklist"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+	"inet.af/netaddr"
+)
+
+type fakeBuilder struct {
+	blacklist.Builder
+	adsHostnames []string
+}
+
+func (b *fakeBuilder) Hostnames(_ context.Context, _, blockAds, _ bool,
+	_, _ []string) (blockedHostnames []string, errs []error) {
+	if blockAds {
+		return b.adsHostnames, nil
+	}
+	return nil, nil
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+func Test_makePolicyLines(t *testing.T) {
+	t.Parallel()
+
+	builder := &fakeBuilder{adsHostnames: []string{"ads.com", "blocked.com"}}
+	lookuper := fakeLookuper{
+		"forcesafesearch.google.com": {net.IPv4(216, 239, 38, 120)},
+	}
+	policies := []settings.DNSPolicy{
+		{
+			Subnets:           []netaddr.IPPrefix{netaddr.MustParseIPPrefix("10.0.0.0/24")},
+			BlockMalicious:    boolPtr(false),
+			BlockAds:          boolPtr(true),
+			BlockSurveillance: boolPtr(false),
+			SafeSearch:        boolPtr(true),
+			LogQueries:        boolPtr(false),
+		},
+		{
+			Subnets:           []netaddr.IPPrefix{netaddr.MustParseIPPrefix("10.0.0.0/24")},
+			BlockMalicious:    boolPtr(false),
+			BlockAds:          boolPtr(true),
+			BlockSurveillance: boolPtr(false),
+			SafeSearch:        boolPtr(false),
+			LogQueries:        boolPtr(true),
+		},
+	}
+	globallyBlocked := []string{"blocked.com."}
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	// Other safe search hosts are not resolved by the fake lookuper.
+	logger.EXPECT().Warn("skipping safe search for duckduckgo.com: " +
+		"resolving safe.duckduckgo.com: no such host")
+	logger.EXPECT().Warn("skipping safe search for m.youtube.com: " +
+		"resolving restrict.youtube.com: no such host")
+	logger.EXPECT().Warn("skipping safe search for www.bing.com: " +
+		"resolving strict.bing.com: no such host")
+
+	lines := makePolicyLines(context.Background(), builder, lookuper,
+		policies, globallyBlocked, nil, logger)
+
+	expectedLines := []string{
+		`  define-tag: "policy1 policy2"`,
+		`  access-control: 10.0.0.0/24 allow`,
+		`  access-control-tag: 10.0.0.0/24 "policy1 policy2"`,
+		`  local-zone: "ads.com." static`,
+		`  local-zone-tag: "ads.com." "policy1 policy2"`,
+		`  local-zone: "www.google.com." redirect`,
+		`  local-zone-tag: "www.google.com." "policy1"`,
+		`  local-data: "www.google.com. 300 IN A 216.239.38.120"`,
+		`  log-queries: yes`,
+	}
+	assert.Equal(t, expectedLines, lines)
+}
+
+func Test_queryLogFilter_keep(t *testing.T) {
+	t.Parallel()
+
+	policies := []settings.DNSPolicy{
+		{
+			Subnets:    []netaddr.IPPrefix{netaddr.MustParseIPPrefix("10.0.0.0/24")},
+			LogQueries: boolPtr(true),
+		},
+		{
+			Subnets:    []netaddr.IPPrefix{netaddr.MustParseIPPrefix("10.0.1.0/24")},
+			LogQueries: boolPtr(false),
+		},
+	}
+	filter := newQueryLogFilter(policies)
+
+	testCases := map[string]struct {
+		line string
+		keep bool
+	}{
+		"not a query line": {
+			line: "[1647788391] unbound[1:0] info: start of service (unbound 1.13.2).",
+			keep: true,
+		},
+		"query from logging policy": {
+			line: "[1647788391] unbound[1:0] info: 10.0.0.5 example.com. A IN",
+			keep: true,
+		},
+		"query from other policy": {
+			line: "[1647788391] unbound[1:0] info: 10.0.1.5 example.com. A IN",
+		},
+		"query from client without policy": {
+			line: "[1647788391] unbound[1:0] info: 127.0.0.1 example.com. AAAA IN",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			keep := filter.keep(testCase.line)
+
+			assert.Equal(t, testCase.keep, keep)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// makeRecordLines returns Unbound server clause lines answering
+// each record name with its record data.
+func makeRecordLines(records []settings.DNSRecord) (lines []string) {
+	const ttl = 300
+	lines = make([]string, len(records))
+	for i, record := range records {
+		value := record.Value
+		if record.Type == "CNAME" {
+			value = strings.TrimSuffix(value, ".") + "."
+		}
+		lines[i] = fmt.Sprintf(`  local-data: "%s. %d IN %s %s"`,
+			strings.TrimSuffix(record.Name, "."), ttl, record.Type, value)
+	}
+	return lines
+}
+
+// mergeRecords returns a new slice containing the settings records
+// followed by the hosts file records, so the settings slice backing
+// array is never modified.
+func mergeRecords(settingsRecords, hostsFileRecords []settings.DNSRecord) (
+	merged []settings.DNSRecord) {
+	merged = make([]settings.DNSRecord, 0, len(settingsRecords)+len(hostsFileRecords))
+	merged = append(merged, settingsRecords...)
+	return append(merged, hostsFileRecords...)
+}
+
+// recordHosts returns the names of the records, which must not be
+// blocked by the block lists for the records to be answered.
+func recordHosts(records []settings.DNSRecord) (hosts []string) {
+	hosts = make([]string, len(records))
+	for i, record := range records {
+		hosts[i] = record.Name
+	}
+	return hosts
+}
+
+// readHostsFile reads the records from the hosts format file at the
+// path given. It returns no record and no error if the path is empty.
+func readHostsFile(path string) (records []settings.DNSRecord, err error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading hosts file: %w", err)
+	}
+
+	records, err = parseHosts(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing hosts file %s: %w", path, err)
+	}
+	return records, nil
+}
+
+var ErrHostsLineNotValid = errors.New("hosts line is not valid")
+
+// parseHosts parses the hosts format data given, where each line is
+// an IP address followed by one or more hostnames, and # starts a
+// comment.
+func parseHosts(data []byte) (records []settings.DNSRecord, err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		ip := net.ParseIP(fields[0])
+		if ip == nil || len(fields) == 1 {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrHostsLineNotValid,
+				lineNumber, strings.TrimSpace(line))
+		}
+		recordType := "AAAA"
+		if ip.To4() != nil {
+			recordType = "A"
+		}
+
+		for _, name := range fields[1:] {
+			if !settings.IsValidHost(name) {
+				return nil, fmt.Errorf("%w: line %d: hostname %q",
+					ErrHostsLineNotValid, lineNumber, name)
+			}
+			records = append(records, settings.DNSRecord{
+				Name:  strings.ToLower(name),
+				Type:  recordType,
+				Value: ip.String(),
+			})
+		}
+	}
+	return records, scanner.Err()
+}
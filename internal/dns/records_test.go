@@ -0,0 +1,81 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_makeRecordLines(t *testing.T) {
+	t.Parallel()
+
+	records := []settings.DNSRecord{
+		{Name: "nas.home", Type: "A", Value: "192.168.1.10"},
+		{Name: "nas.home", Type: "AAAA", Value: "fd00::10"},
+		{Name: "files.home", Type: "CNAME", Value: "nas.home"},
+	}
+
+	lines := makeRecordLines(records)
+
+	expectedLines := []string{
+		`  local-data: "nas.home. 300 IN A 192.168.1.10"`,
+		`  local-data: "nas.home. 300 IN AAAA fd00::10"`,
+		`  local-data: "files.home. 300 IN CNAME nas.home."`,
+	}
+	assert.Equal(t, expectedLines, lines)
+}
+
+func Test_parseHosts(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		data       string
+		records    []settings.DNSRecord
+		errWrapped error
+		errMessage string
+	}{
+		"empty": {},
+		"records": {
+			data: "# comment\n" +
+				"192.168.1.10 nas.home NAS2.home # inline comment\n" +
+				"\n" +
+				"\tfd00::10   nas.home\n",
+			records: []settings.DNSRecord{
+				{Name: "nas.home", Type: "A", Value: "192.168.1.10"},
+				{Name: "nas2.home", Type: "A", Value: "192.168.1.10"},
+				{Name: "nas.home", Type: "AAAA", Value: "fd00::10"},
+			},
+		},
+		"bad_ip": {
+			data:       "# comment\n192.168.1 nas.home\n",
+			errWrapped: ErrHostsLineNotValid,
+			errMessage: "hosts line is not valid: line 2: 192.168.1 nas.home",
+		},
+		"missing_hostname": {
+			data:       "192.168.1.10\n",
+			errWrapped: ErrHostsLineNotValid,
+			errMessage: "hosts line is not valid: line 1: 192.168.1.10",
+		},
+		"bad_hostname": {
+			data:       "192.168.1.10 nas_home!\n",
+			errWrapped: ErrHostsLineNotValid,
+			errMessage: `hosts line is not valid: line 1: hostname "nas_home!"`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			records, err := parseHosts([]byte(testCase.data))
+
+			assert.Equal(t, testCase.records, records)
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+type ipLookuper interface {
+	LookupIP(ctx context.Context, network, host string) (ips []net.IP, err error)
+}
+
+// makeRewriteLines returns Unbound server clause lines answering
+// each rewrite domain with its target IP addresses. Target hostnames
+// are resolved to their IP addresses, flattening their CNAME chain.
+// Wildcard domains use a redirect zone so subdomains are answered
+// with the same addresses.
+func makeRewriteLines(ctx context.Context, lookuper ipLookuper,
+	rewrites []settings.DNSRewrite, logger Logger) (lines []string) {
+	domainToIPs := make(map[string][]net.IP, len(rewrites))
+	domains := make([]string, 0, len(rewrites))
+	for _, rewrite := range rewrites {
+		var ips []net.IP
+		if rewrite.IsIP() {
+			ips = []net.IP{net.ParseIP(rewrite.Target)}
+		} else {
+			var err error
+			ips, err = lookuper.LookupIP(ctx, "ip", rewrite.Target)
+			if err != nil {
+				logger.Warn("skipping DNS rewrite for " + rewrite.Domain +
+					": resolving " + rewrite.Target + ": " + err.Error())
+				continue
+			}
+		}
+
+		if _, ok := domainToIPs[rewrite.Domain]; !ok {
+			domains = append(domains, rewrite.Domain)
+		}
+		domainToIPs[rewrite.Domain] = append(domainToIPs[rewrite.Domain], ips...)
+	}
+
+	for _, domain := range domains {
+		name := strings.TrimPrefix(domain, "*.") + "."
+		if strings.HasPrefix(domain, "*.") {
+			lines = append(lines, `  local-zone: "`+name+`" redirect`)
+		}
+		lines = append(lines, makeLocalDataLines(name, domainToIPs[domain])...)
+	}
+
+	return lines
+}
+
+func makeLocalDataLines(name string, ips []net.IP) (lines []string) {
+	const ttl = 300
+	lines = make([]string, len(ips))
+	for i, ip := range ips {
+		recordType := "AAAA"
+		if ip.To4() != nil {
+			recordType = "A"
+		}
+		lines[i] = fmt.Sprintf(`  local-data: "%s %d IN %s %s"`,
+			name, ttl, recordType, ip)
+	}
+	return lines
+}
+
+// rewriteHosts returns the hostnames of the rewrite domains,
+// which must not be blocked by the block lists since a local
+// zone can only be defined once in Unbound.
+func rewriteHosts(rewrites []settings.DNSRewrite) (hosts []string) {
+	hosts = make([]string, len(rewrites))
+	for i, rewrite := range rewrites {
+		hosts[i] = strings.TrimPrefix(rewrite.Domain, "*.")
+	}
+	return hosts
+}
@@ -0,0 +1,50 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeLookuper map[string][]net.IP
+
+func (f fakeLookuper) LookupIP(_ context.Context, _, host string) ([]net.IP, error) {
+	ips, ok := f[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return ips, nil
+}
+
+func Test_makeRewriteLines(t *testing.T) {
+	t.Parallel()
+
+	lookuper := fakeLookuper{
+		"local.lan": {net.IPv4(192, 168, 1, 5), net.ParseIP("fd00::5")},
+	}
+	rewrites := []settings.DNSRewrite{
+		{Domain: "a.com", Target: "10.0.0.1"},
+		{Domain: "*.b.com", Target: "local.lan"},
+		{Domain: "a.com", Target: "::1"},
+		{Domain: "c.com", Target: "unknown.lan"},
+	}
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	logger.EXPECT().Warn("skipping DNS rewrite for c.com: resolving unknown.lan: no such host")
+
+	lines := makeRewriteLines(context.Background(), lookuper, rewrites, logger)
+
+	expectedLines := []string{
+		`  local-data: "a.com. 300 IN A 10.0.0.1"`,
+		`  local-data: "a.com. 300 IN AAAA ::1"`,
+		`  local-zone: "b.com." redirect`,
+		`  local-data: "b.com. 300 IN A 192.168.1.5"`,
+		`  local-data: "b.com. 300 IN AAAA fd00::5"`,
+	}
+	assert.Equal(t, expectedLines, lines)
+}
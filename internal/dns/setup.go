@@ -8,6 +8,7 @@ import (
 
 	"github.com/qdm12/dns/pkg/check"
 	"github.com/qdm12/dns/pkg/nameserver"
+	"github.com/qdm12/gluetun/internal/dnsupstream"
 )
 
 var errUpdateFiles = errors.New("cannot update files")
@@ -25,21 +26,52 @@ func (l *Loop) setupUnbound(ctx context.Context) (
 
 	settings := l.GetSettings()
 
+	// stopForwarder stops the local forwarder Unbound forwards
+	// queries to, and is a no-op if no DNS upstream nor bypass
+	// domain is set.
+	stopForwarder := func() {}
+	if len(settings.DoT.Upstreams) > 0 || len(l.bypassDomains) > 0 {
+		upstreams, err := settings.DoT.ForwarderUpstreams()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		forwarder, err := dnsupstream.New(upstreams,
+			*settings.DoT.UpstreamsProbePeriod, l.logger)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("creating DNS upstreams forwarder: %w", err)
+		}
+		if l.answerRecorder != nil {
+			forwarder.SetAnswerRecorder(l.answerRecorder)
+		}
+		forwarderCtx, forwarderCancel := context.WithCancel(context.Background())
+		done, err := forwarder.Start(forwarderCtx, forwarderAddress)
+		if err != nil {
+			forwarderCancel()
+			return nil, nil, nil, fmt.Errorf("starting DNS upstreams forwarder: %w", err)
+		}
+		stopForwarder = func() {
+			forwarderCancel()
+			<-done
+		}
+	}
+
 	unboundCtx, cancel := context.WithCancel(context.Background())
 	stdoutLines, stderrLines, waitError, err := l.conf.Start(unboundCtx,
 		*settings.DoT.Unbound.VerbosityDetailsLevel)
 	if err != nil {
 		cancel()
+		stopForwarder()
 		return nil, nil, nil, err
 	}
 
 	linesCollectionCtx, linesCollectionCancel := context.WithCancel(context.Background())
 	lineCollectionDone := make(chan struct{})
 	go l.collectLines(linesCollectionCtx, lineCollectionDone,
-		stdoutLines, stderrLines)
+		stdoutLines, stderrLines, newQueryLogFilter(settings.DoT.Policies))
 	closeStreams = func() {
 		linesCollectionCancel()
 		<-lineCollectionDone
+		stopForwarder()
 	}
 
 	// use Unbound
@@ -1,6 +1,11 @@
 package dns
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
 
 func (l *Loop) updateFiles(ctx context.Context) (err error) {
 	l.logger.Info("downloading DNS over TLS cryptographic files")
@@ -20,6 +25,29 @@ func (l *Loop) updateFiles(ctx context.Context) (err error) {
 		return err
 	}
 
+	hostsFileRecords, err := readHostsFile(*settings.DoT.HostsFile)
+	if err != nil {
+		l.logger.Warn("skipping hosts file records: " + err.Error())
+	}
+	records := mergeRecords(settings.DoT.Records, hostsFileRecords)
+
+	runtimeHosts, err := l.runtimeHosts.get()
+	if err != nil {
+		return err
+	}
+	blacklistSettings.AddBlockedHosts = mergeHosts(blacklistSettings.AddBlockedHosts,
+		runtimeHosts.Blocked)
+	blacklistSettings.AllowedHosts = mergeHosts(blacklistSettings.AllowedHosts,
+		runtimeHosts.Allowed)
+	blacklistSettings.AddBlockedHosts = mergeHosts(blacklistSettings.AddBlockedHosts,
+		readListFiles(settings.DoT.Blacklist.BlockListFiles, "block list", l.logger))
+	blacklistSettings.AllowedHosts = mergeHosts(blacklistSettings.AllowedHosts,
+		readListFiles(settings.DoT.Blacklist.AllowListFiles, "allow list", l.logger))
+	blacklistSettings.AllowedHosts = mergeHosts(blacklistSettings.AllowedHosts,
+		rewriteHosts(settings.DoT.Rewrites))
+	blacklistSettings.AllowedHosts = mergeHosts(blacklistSettings.AllowedHosts,
+		recordHosts(records))
+
 	blockedHostnames, blockedIPs, blockedIPPrefixes, errs :=
 		l.blockBuilder.All(ctx, blacklistSettings)
 	for _, err := range errs {
@@ -31,5 +59,54 @@ func (l *Loop) updateFiles(ctx context.Context) (err error) {
 	unboundSettings.Blacklist.IPs = blockedIPs
 	unboundSettings.Blacklist.IPPrefixes = blockedIPPrefixes
 
-	return l.conf.MakeUnboundConf(unboundSettings)
+	includeLines := makeAAAAFilterLines(*settings.FilterAAAA, l.ipv6Supported)
+	includeLines = append(includeLines, makeRecordLines(records)...)
+	rewriteLines := makeRewriteLines(ctx, l.ipLookuper, settings.DoT.Rewrites, l.logger)
+	includeLines = append(includeLines, rewriteLines...)
+	policyLines := makePolicyLines(ctx, l.blockBuilder, l.ipLookuper,
+		settings.DoT.Policies, blockedHostnames, blacklistSettings.AllowedHosts, l.logger)
+	includeLines = append(includeLines, policyLines...)
+	err = l.writeIncludeConf(includeLines)
+	if err != nil {
+		return err
+	}
+
+	err = l.conf.MakeUnboundConf(unboundSettings)
+	if err != nil {
+		return err
+	}
+
+	err = configureCache(l.unboundConf, settings.DoT.Unbound, l.controlSocket)
+	if err != nil {
+		return err
+	}
+
+	err = configureListening(l.unboundConf, settings.LAN)
+	if err != nil {
+		return err
+	}
+
+	if len(settings.DoT.Upstreams) > 0 {
+		err = useForwarder(l.unboundConf, forwarderAddress)
+		if err != nil {
+			return err
+		}
+	}
+	return addForwardZones(l.unboundConf, l.bypassDomains, forwarderAddress)
+}
+
+// writeIncludeConf writes the Unbound server clause lines given
+// to the include configuration file of the Unbound configuration.
+func (l *Loop) writeIncludeConf(lines []string) (err error) {
+	data := strings.Join(lines, "\n")
+	if len(lines) > 0 {
+		data += "\n"
+	}
+
+	const perms = os.FileMode(0644)
+	err = os.WriteFile(l.includeConf, []byte(data), perms)
+	if err != nil {
+		return fmt.Errorf("writing Unbound include configuration: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,71 @@
+package dns
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+)
+
+const filesWatchPeriod = 10 * time.Second
+
+// RunFilesWatcher checks the hosts file and the block and allow list
+// files for changes every 10 seconds, and restarts Unbound if it is
+// running and the content of any of the files changed.
+func (l *Loop) RunFilesWatcher(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(filesWatchPeriod)
+	defer ticker.Stop()
+
+	paths := watchedPaths(l.GetSettings())
+	lastContents := readFilesContent(paths)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		newPaths := watchedPaths(l.GetSettings())
+		contents := readFilesContent(newPaths)
+		pathsChanged := !reflect.DeepEqual(newPaths, paths)
+		if !pathsChanged && reflect.DeepEqual(contents, lastContents) {
+			continue
+		}
+		paths, lastContents = newPaths, contents
+
+		// A change of paths comes with a settings
+		// update which already restarts Unbound.
+		if pathsChanged || l.GetStatus() != constants.Running {
+			continue
+		}
+
+		l.logger.Info("DNS local files changed, restarting")
+		_, _ = l.statusManager.ApplyStatus(ctx, constants.Stopped)
+		_, _ = l.statusManager.ApplyStatus(ctx, constants.Running)
+	}
+}
+
+func watchedPaths(settings settings.DNS) (paths []string) {
+	if *settings.DoT.HostsFile != "" {
+		paths = append(paths, *settings.DoT.HostsFile)
+	}
+	paths = append(paths, settings.DoT.Blacklist.BlockListFiles...)
+	return append(paths, settings.DoT.Blacklist.AllowListFiles...)
+}
+
+// readFilesContent returns the content of each file at the paths
+// given, with a nil content if the file cannot be read, in which
+// case the error is logged when the files are read to update
+// the Unbound configuration.
+func readFilesContent(paths []string) (contents [][]byte) {
+	contents = make([][]byte, len(paths))
+	for i, path := range paths {
+		contents[i], _ = os.ReadFile(path)
+	}
+	return contents
+}
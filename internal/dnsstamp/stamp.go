@@ -0,0 +1,236 @@
+// Package dnsstamp parses DNS stamps, which encode in a sdns:// URI
+// all the parameters needed to connect to a DNS resolver, as specified
+// at https://dnscrypt.info/stamps-specifications
+package dnsstamp
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/constants"
+)
+
+// Stamp contains the parameters of a DNS resolver.
+type Stamp struct {
+	// Protocol is the protocol of the resolver, which is one of
+	// constants.DNSUpstreamDNSCrypt, DNSUpstreamDoT, DNSUpstreamDoQ
+	// or DNSUpstreamDoH.
+	Protocol string
+	// Address is the IP address and port of the resolver.
+	Address string
+	// ServerName is the TLS server name for DNS over TLS, QUIC
+	// and HTTPS, or the provider name for DNSCrypt.
+	ServerName string
+	// PublicKey is the Ed25519 public key of the DNSCrypt provider
+	// certificates are signed with. It is only set for DNSCrypt.
+	PublicKey []byte
+}
+
+var (
+	ErrPrefixNotValid      = errors.New("stamp prefix is not sdns://")
+	ErrProtocolUnsupported = errors.New("stamp protocol is not supported")
+	ErrStampTooShort       = errors.New("stamp is too short")
+	ErrAddressNotValid     = errors.New("stamp address is not valid")
+	ErrPublicKeyNotValid   = errors.New("stamp public key is not valid")
+	ErrServerNameNotSet    = errors.New("stamp server name is not set")
+	ErrPathUnsupported     = errors.New("stamp path is not supported")
+)
+
+const (
+	protocolDNSCrypt = 0x01
+	protocolDoH      = 0x02
+	protocolDoT      = 0x03
+	protocolDoQ      = 0x04
+)
+
+// Parse parses the DNS stamp given, for resolvers using
+// DNSCrypt, DNS over TLS, DNS over QUIC or DNS over HTTPS.
+func Parse(s string) (stamp Stamp, err error) {
+	const prefix = "sdns://"
+	if !strings.HasPrefix(s, prefix) {
+		return stamp, fmt.Errorf("%w: %s", ErrPrefixNotValid, s)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(s, prefix))
+	if err != nil {
+		return stamp, fmt.Errorf("decoding base64: %w", err)
+	}
+
+	const propertiesLength = 8
+	if len(data) < 1+propertiesLength {
+		return stamp, fmt.Errorf("%w: %d bytes", ErrStampTooShort, len(data))
+	}
+	protocol := data[0]
+	reader := &reader{data: data[1+propertiesLength:]}
+
+	switch protocol {
+	case protocolDNSCrypt:
+		stamp, err = parseDNSCrypt(reader)
+	case protocolDoT, protocolDoQ, protocolDoH:
+		stamp, err = parseTLS(reader, protocol)
+	default:
+		return stamp, fmt.Errorf("%w: 0x%02x", ErrProtocolUnsupported, protocol)
+	}
+	if err != nil {
+		return Stamp{}, err
+	}
+	return stamp, nil
+}
+
+// parseDNSCrypt parses the DNSCrypt stamp fields following the
+// properties, which are LP(addr) || LP(pk) || LP(providerName).
+func parseDNSCrypt(reader *reader) (stamp Stamp, err error) {
+	stamp.Protocol = constants.DNSUpstreamDNSCrypt
+
+	address, err := reader.lengthPrefixed()
+	if err != nil {
+		return stamp, fmt.Errorf("reading address: %w", err)
+	}
+	const defaultPort = 443
+	stamp.Address, err = parseAddress(string(address), defaultPort)
+	if err != nil {
+		return stamp, err
+	}
+
+	stamp.PublicKey, err = reader.lengthPrefixed()
+	if err != nil {
+		return stamp, fmt.Errorf("reading public key: %w", err)
+	}
+	const publicKeyLength = 32
+	if len(stamp.PublicKey) != publicKeyLength {
+		return stamp, fmt.Errorf("%w: %d bytes instead of %d",
+			ErrPublicKeyNotValid, len(stamp.PublicKey), publicKeyLength)
+	}
+
+	providerName, err := reader.lengthPrefixed()
+	if err != nil {
+		return stamp, fmt.Errorf("reading provider name: %w", err)
+	}
+	stamp.ServerName = string(providerName)
+	if stamp.ServerName == "" {
+		return stamp, fmt.Errorf("%w", ErrServerNameNotSet)
+	}
+
+	return stamp, nil
+}
+
+// parseTLS parses the DNS over TLS, QUIC or HTTPS stamp fields
+// following the properties, which are LP(addr) || VLP(hashes) ||
+// LP(hostname [:port]), followed by LP(path) for DNS over HTTPS,
+// and optionally followed by bootstrap IP addresses which are ignored.
+// Only the /dns-query path is supported for DNS over HTTPS.
+func parseTLS(reader *reader, protocol byte) (stamp Stamp, err error) {
+	port := uint16(853) //nolint:gomnd
+	switch protocol {
+	case protocolDoQ:
+		stamp.Protocol = constants.DNSUpstreamDoQ
+	case protocolDoH:
+		stamp.Protocol = constants.DNSUpstreamDoH
+		port = 443
+	default:
+		stamp.Protocol = constants.DNSUpstreamDoT
+	}
+
+	address, err := reader.lengthPrefixed()
+	if err != nil {
+		return stamp, fmt.Errorf("reading address: %w", err)
+	}
+
+	// Certificate hashes are not used, and the hostname
+	// certificate is verified against the system CAs.
+	err = reader.skipVariableLengthPrefixed()
+	if err != nil {
+		return stamp, fmt.Errorf("reading hashes: %w", err)
+	}
+
+	hostname, err := reader.lengthPrefixed()
+	if err != nil {
+		return stamp, fmt.Errorf("reading hostname: %w", err)
+	}
+	serverName, hostnamePort, hasPort := strings.Cut(string(hostname), ":")
+	if serverName == "" {
+		return stamp, fmt.Errorf("%w", ErrServerNameNotSet)
+	}
+	stamp.ServerName = serverName
+
+	if hasPort {
+		parsedPort, err := strconv.ParseUint(hostnamePort, 10, 16)
+		if err != nil {
+			return stamp, fmt.Errorf("%w: hostname port: %s", ErrAddressNotValid, err)
+		}
+		port = uint16(parsedPort)
+	}
+	stamp.Address, err = parseAddress(string(address), port)
+	if err != nil {
+		return stamp, err
+	}
+
+	if protocol == protocolDoH {
+		path, err := reader.lengthPrefixed()
+		if err != nil {
+			return stamp, fmt.Errorf("reading path: %w", err)
+		} else if string(path) != "/dns-query" {
+			return stamp, fmt.Errorf("%w: %s", ErrPathUnsupported, path)
+		}
+	}
+
+	return stamp, nil
+}
+
+// parseAddress parses the IP address with an optional
+// port given, and returns it as ip:port using the default port
+// given if the address has no port.
+func parseAddress(address string, defaultPort uint16) (ipPort string, err error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host = strings.Trim(address, "[]")
+		port = strconv.Itoa(int(defaultPort))
+	}
+	if net.ParseIP(host) == nil {
+		return "", fmt.Errorf("%w: %q is not an IP address", ErrAddressNotValid, address)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+type reader struct {
+	data []byte
+}
+
+// lengthPrefixed reads a field prefixed with its length on one byte.
+func (r *reader) lengthPrefixed() (field []byte, err error) {
+	if len(r.data) == 0 {
+		return nil, fmt.Errorf("%w: missing length", ErrStampTooShort)
+	}
+	length := int(r.data[0])
+	if len(r.data) < 1+length {
+		return nil, fmt.Errorf("%w: field of %d bytes", ErrStampTooShort, length)
+	}
+	field = r.data[1 : 1+length]
+	r.data = r.data[1+length:]
+	return field, nil
+}
+
+// skipVariableLengthPrefixed skips a set of fields each prefixed with
+// their length, where the high bit of the length is set if another
+// field follows.
+func (r *reader) skipVariableLengthPrefixed() (err error) {
+	for {
+		if len(r.data) == 0 {
+			return fmt.Errorf("%w: missing length", ErrStampTooShort)
+		}
+		const moreFollows = 0x80
+		length := int(r.data[0] &^ moreFollows)
+		last := r.data[0]&moreFollows == 0
+		if len(r.data) < 1+length {
+			return fmt.Errorf("%w: field of %d bytes", ErrStampTooShort, length)
+		}
+		r.data = r.data[1+length:]
+		if last {
+			return nil
+		}
+	}
+}
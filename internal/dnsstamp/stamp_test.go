@@ -0,0 +1,135 @@
+package dnsstamp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+// encode encodes a stamp for the protocol given with
+// empty properties, followed by the raw bytes given.
+func encode(protocol byte, fields ...[]byte) string {
+	data := []byte{protocol, 0, 0, 0, 0, 0, 0, 0, 0}
+	for _, field := range fields {
+		data = append(data, field...)
+	}
+	return "sdns://" + base64.RawURLEncoding.EncodeToString(data)
+}
+
+// lp returns the string given prefixed with its length.
+func lp(s string) []byte {
+	return append([]byte{byte(len(s))}, s...)
+}
+
+func Test_Parse(t *testing.T) {
+	t.Parallel()
+
+	publicKey := bytes.Repeat([]byte{0xab}, 32)
+
+	testCases := map[string]struct {
+		s          string
+		stamp      Stamp
+		errWrap    error
+		errMessage string
+	}{
+		"bad prefix": {
+			s:          "https://dns.example.com",
+			errWrap:    ErrPrefixNotValid,
+			errMessage: "stamp prefix is not sdns://: https://dns.example.com",
+		},
+		"protocol not supported": {
+			s:          encode(0x05, lp("1.2.3.4")),
+			errWrap:    ErrProtocolUnsupported,
+			errMessage: "stamp protocol is not supported: 0x05",
+		},
+		"DoH": {
+			s: encode(protocolDoH, lp("1.2.3.4"), []byte{0},
+				lp("dns.example.com"), lp("/dns-query")),
+			stamp: Stamp{
+				Protocol:   constants.DNSUpstreamDoH,
+				Address:    "1.2.3.4:443",
+				ServerName: "dns.example.com",
+			},
+		},
+		"DoH with unsupported path": {
+			s: encode(protocolDoH, lp("1.2.3.4"), []byte{0},
+				lp("dns.example.com"), lp("/resolve")),
+			errWrap:    ErrPathUnsupported,
+			errMessage: "stamp path is not supported: /resolve",
+		},
+		"DNSCrypt": {
+			s: encode(protocolDNSCrypt, lp("1.2.3.4:8443"), lp(string(publicKey)),
+				lp("2.dnscrypt-cert.example.com")),
+			stamp: Stamp{
+				Protocol:   constants.DNSUpstreamDNSCrypt,
+				Address:    "1.2.3.4:8443",
+				ServerName: "2.dnscrypt-cert.example.com",
+				PublicKey:  publicKey,
+			},
+		},
+		"DNSCrypt with IPv6 address and default port": {
+			s: encode(protocolDNSCrypt, lp("[2001:db8::1]"), lp(string(publicKey)),
+				lp("2.dnscrypt-cert.example.com")),
+			stamp: Stamp{
+				Protocol:   constants.DNSUpstreamDNSCrypt,
+				Address:    "[2001:db8::1]:443",
+				ServerName: "2.dnscrypt-cert.example.com",
+				PublicKey:  publicKey,
+			},
+		},
+		"DNSCrypt with short public key": {
+			s:          encode(protocolDNSCrypt, lp("1.2.3.4"), lp("key"), lp("2.dnscrypt-cert.example.com")),
+			errWrap:    ErrPublicKeyNotValid,
+			errMessage: "stamp public key is not valid: 3 bytes instead of 32",
+		},
+		"DNSCrypt truncated": {
+			s:          encode(protocolDNSCrypt, lp("1.2.3.4"), []byte{32, 1, 2}),
+			errWrap:    ErrStampTooShort,
+			errMessage: "reading public key: stamp is too short: field of 32 bytes",
+		},
+		"DoT with hashes": {
+			s: encode(protocolDoT, lp("9.9.9.9"), []byte{0x82, 1, 2, 0x01, 3},
+				lp("dns.example.com")),
+			stamp: Stamp{
+				Protocol:   constants.DNSUpstreamDoT,
+				Address:    "9.9.9.9:853",
+				ServerName: "dns.example.com",
+			},
+		},
+		"DoQ with hostname port": {
+			s: encode(protocolDoQ, lp("9.9.9.9"), []byte{0}, lp("dns.example.com:8853")),
+			stamp: Stamp{
+				Protocol:   constants.DNSUpstreamDoQ,
+				Address:    "9.9.9.9:8853",
+				ServerName: "dns.example.com",
+			},
+		},
+		"DoT without IP address": {
+			s:          encode(protocolDoT, lp(""), []byte{0}, lp("dns.example.com")),
+			errWrap:    ErrAddressNotValid,
+			errMessage: `stamp address is not valid: "" is not an IP address`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			stamp, err := Parse(testCase.s)
+
+			if testCase.errWrap != nil {
+				assert.ErrorIs(t, err, testCase.errWrap)
+			}
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.stamp, stamp)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package dnsupstream
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// AnswerRecorder records the IP addresses answered for a DNS name.
+type AnswerRecorder interface {
+	RecordAnswer(name string, ips []net.IP)
+}
+
+// SetAnswerRecorder sets the recorder of the IP addresses answered
+// by the upstreams. It must be called before the forwarder is started.
+func (f *Forwarder) SetAnswerRecorder(recorder AnswerRecorder) {
+	f.recorder = recorder
+}
+
+// recordAnswer records the IP addresses of the response given,
+// if an answer recorder is set and the response has any.
+func (f *Forwarder) recordAnswer(response []byte) {
+	if f.recorder == nil {
+		return
+	}
+	name, ips, err := answerIPs(response)
+	if err != nil {
+		f.logger.Warn("recording answer: " + err.Error())
+		return
+	} else if len(ips) == 0 {
+		return
+	}
+	f.recorder.RecordAnswer(name, ips)
+}
+
+// answerIPs returns the name queried and the IP addresses of the A and
+// AAAA records of the answer section of the DNS response given.
+func answerIPs(response []byte) (name string, ips []net.IP, err error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(response)
+	if err != nil {
+		return "", nil, fmt.Errorf("parsing header: %w", err)
+	} else if header.RCode != dnsmessage.RCodeSuccess {
+		return "", nil, nil
+	}
+
+	question, err := parser.Question()
+	if errors.Is(err, dnsmessage.ErrSectionDone) {
+		return "", nil, nil
+	} else if err != nil {
+		return "", nil, fmt.Errorf("parsing question: %w", err)
+	}
+	name = question.Name.String()
+
+	err = parser.SkipAllQuestions()
+	if err != nil {
+		return "", nil, fmt.Errorf("skipping questions: %w", err)
+	}
+
+	for {
+		answerHeader, err := parser.AnswerHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			return name, ips, nil
+		} else if err != nil {
+			return "", nil, fmt.Errorf("parsing answer header: %w", err)
+		}
+
+		switch answerHeader.Type {
+		case dnsmessage.TypeA:
+			resource, err := parser.AResource()
+			if err != nil {
+				return "", nil, fmt.Errorf("parsing A record: %w", err)
+			}
+			ips = append(ips, net.IP(resource.A[:]))
+		case dnsmessage.TypeAAAA:
+			resource, err := parser.AAAAResource()
+			if err != nil {
+				return "", nil, fmt.Errorf("parsing AAAA record: %w", err)
+			}
+			ips = append(ips, net.IP(resource.AAAA[:]))
+		default:
+			err = parser.SkipAnswer()
+			if err != nil {
+				return "", nil, fmt.Errorf("skipping answer: %w", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package dnsupstream
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// buildResponse builds a response to the example.com. A query with
+// the response code and the answer resources given.
+func buildResponse(t *testing.T, rcode dnsmessage.RCode,
+	answers ...dnsmessage.Resource) (response []byte) {
+	t.Helper()
+
+	message := dnsmessage.Message{
+		Header: dnsmessage.Header{Response: true, RCode: rcode},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName("example.com."),
+			Type:  dnsmessage.TypeA,
+			Class: dnsmessage.ClassINET,
+		}},
+		Answers: answers,
+	}
+	response, err := message.Pack()
+	require.NoError(t, err)
+	return response
+}
+
+func Test_answerIPs(t *testing.T) {
+	t.Parallel()
+
+	resourceHeader := func(name string, resourceType dnsmessage.Type) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{
+			Name:  dnsmessage.MustNewName(name),
+			Type:  resourceType,
+			Class: dnsmessage.ClassINET,
+		}
+	}
+
+	testCases := map[string]struct {
+		response   []byte
+		name       string
+		ips        []net.IP
+		errMessage string
+	}{
+		"malformed": {
+			response:   []byte{1, 2},
+			errMessage: "parsing header: unpacking header: bits: insufficient data for base length type",
+		},
+		"no question": {
+			response: []byte{0x12, 0x34, 0x81, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		},
+		"server failure": {
+			response: buildResponse(t, dnsmessage.RCodeServerFailure),
+		},
+		"no answer": {
+			response: buildResponse(t, dnsmessage.RCodeSuccess),
+			name:     "example.com.",
+		},
+		"CNAME then A and AAAA answers": {
+			response: buildResponse(t, dnsmessage.RCodeSuccess,
+				dnsmessage.Resource{
+					Header: resourceHeader("example.com.", dnsmessage.TypeCNAME),
+					Body:   &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("cdn.example.net.")},
+				},
+				dnsmessage.Resource{
+					Header: resourceHeader("cdn.example.net.", dnsmessage.TypeA),
+					Body:   &dnsmessage.AResource{A: [4]byte{1, 2, 3, 4}},
+				},
+				dnsmessage.Resource{
+					Header: resourceHeader("cdn.example.net.", dnsmessage.TypeAAAA),
+					Body: &dnsmessage.AAAAResource{
+						AAAA: [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 1},
+					},
+				},
+			),
+			name: "example.com.",
+			ips:  []net.IP{net.IP{1, 2, 3, 4}, net.ParseIP("2001:db8::1")},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			name, ips, err := answerIPs(testCase.response)
+
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.name, name)
+			assert.Equal(t, testCase.ips, ips)
+		})
+	}
+}
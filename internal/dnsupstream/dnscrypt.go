@@ -0,0 +1,227 @@
+package dnsupstream
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+type dnscryptUpstream struct {
+	address           string
+	providerName      string
+	providerPublicKey ed25519.PublicKey
+	publicKey         *[32]byte
+	privateKey        *[32]byte
+	dialer            *net.Dialer
+	timeNow           func() time.Time
+
+	mutex       sync.Mutex
+	certificate *dnscryptCertificate
+	sharedKey   [32]byte
+	refreshAt   time.Time
+}
+
+func newDNSCryptUpstream(address, providerName string,
+	providerPublicKey []byte) (upstream *dnscryptUpstream, err error) {
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating key pair: %w", err)
+	}
+	const dialTimeout = 5 * time.Second
+	return &dnscryptUpstream{
+		address:           address,
+		providerName:      providerName,
+		providerPublicKey: providerPublicKey,
+		publicKey:         publicKey,
+		privateKey:        privateKey,
+		dialer:            &net.Dialer{Timeout: dialTimeout},
+		timeNow:           time.Now,
+	}, nil
+}
+
+func (d *dnscryptUpstream) String() string {
+	return "dnscrypt://" + d.address + "#" + d.providerName
+}
+
+func (d *dnscryptUpstream) close() {}
+
+// exchange encrypts the query given, sends it to the resolver
+// over TCP and returns the decrypted response. If the exchange
+// fails, it is retried once with a newly fetched certificate
+// in case the resolver rotated its certificate.
+func (d *dnscryptUpstream) exchange(ctx context.Context, query []byte) (
+	response []byte, err error) {
+	const maxTries = 2
+	for try := 1; ; try++ {
+		certificate, sharedKey, err := d.getCertificate(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("getting certificate: %w", err)
+		}
+
+		response, err = d.exchangeEncrypted(ctx, query, certificate, sharedKey)
+		if err == nil || ctx.Err() != nil || try == maxTries {
+			return response, err
+		}
+		d.invalidateCertificate()
+	}
+}
+
+// getCertificate returns the cached certificate and its shared key,
+// fetching a new certificate if it expired or is due for a refresh.
+func (d *dnscryptUpstream) getCertificate(ctx context.Context) (
+	certificate dnscryptCertificate, sharedKey *[32]byte, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := d.timeNow()
+	if d.certificate != nil && now.Before(d.refreshAt) {
+		sharedKey = new([32]byte)
+		*sharedKey = d.sharedKey
+		return *d.certificate, sharedKey, nil
+	}
+
+	certificate, err = fetchCertificate(ctx, d.dialer, d.address,
+		d.providerName, d.providerPublicKey, now)
+	if err != nil {
+		return certificate, nil, err
+	}
+
+	box.Precompute(&d.sharedKey, &certificate.resolverPublicKey, d.privateKey)
+	d.certificate = &certificate
+	// Resolvers rotate their certificates regularly, usually well
+	// before the certificate in use expires, so refresh it hourly.
+	const refreshPeriod = time.Hour
+	d.refreshAt = now.Add(refreshPeriod)
+	if certificate.notAfter.Before(d.refreshAt) {
+		d.refreshAt = certificate.notAfter
+	}
+
+	sharedKey = new([32]byte)
+	*sharedKey = d.sharedKey
+	return certificate, sharedKey, nil
+}
+
+func (d *dnscryptUpstream) invalidateCertificate() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.certificate = nil
+}
+
+var ErrResolverMagicNotValid = errors.New("resolver magic is not valid")
+
+// resolverMagic is the magic prefix of DNSCrypt responses.
+var resolverMagic = []byte{0x72, 0x36, 0x66, 0x6e, 0x76, 0x57, 0x6a, 0x38}
+
+const (
+	nonceLength     = 24
+	halfNonceLength = nonceLength / 2
+)
+
+func (d *dnscryptUpstream) exchangeEncrypted(ctx context.Context, query []byte,
+	certificate dnscryptCertificate, sharedKey *[32]byte) (response []byte, err error) {
+	var nonce [nonceLength]byte
+	_, err = rand.Read(nonce[:halfNonceLength])
+	if err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+
+	// The packet is client-magic || client-pk || client-nonce || encrypted-query
+	padded := pad(query)
+	packet := make([]byte, 0, len(certificate.clientMagic)+len(d.publicKey)+
+		halfNonceLength+len(padded)+box.Overhead)
+	packet = append(packet, certificate.clientMagic[:]...)
+	packet = append(packet, d.publicKey[:]...)
+	packet = append(packet, nonce[:halfNonceLength]...)
+	packet = box.SealAfterPrecomputation(packet, padded, &nonce, sharedKey)
+
+	connection, err := d.dialer.DialContext(ctx, "tcp", d.address)
+	if err != nil {
+		return nil, fmt.Errorf("dialing: %w", err)
+	}
+	defer connection.Close()
+
+	err = connection.SetDeadline(exchangeDeadline(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("setting deadline: %w", err)
+	}
+
+	err = writeMessage(connection, packet)
+	if err != nil {
+		return nil, fmt.Errorf("writing query: %w", err)
+	}
+
+	packet, err = readMessage(connection)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	// The packet is resolver-magic || nonce || encrypted-response
+	// where the nonce starts with the client nonce.
+	if len(packet) < len(resolverMagic)+nonceLength+box.Overhead {
+		return nil, fmt.Errorf("%w: %d bytes is too short", ErrResponseNotValid, len(packet))
+	} else if !bytes.Equal(packet[:len(resolverMagic)], resolverMagic) {
+		return nil, fmt.Errorf("%w", ErrResolverMagicNotValid)
+	}
+	packet = packet[len(resolverMagic):]
+	if !bytes.Equal(packet[:halfNonceLength], nonce[:halfNonceLength]) {
+		return nil, fmt.Errorf("%w: nonce does not match", ErrResponseNotValid)
+	}
+	copy(nonce[:], packet[:nonceLength])
+
+	padded, ok := box.OpenAfterPrecomputation(nil, packet[nonceLength:], &nonce, sharedKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: decryption failed", ErrResponseNotValid)
+	}
+
+	response, err = unpad(padded)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+const (
+	paddingStart       = 0x80
+	paddingBlockLength = 64
+	minPaddedLength    = 256
+)
+
+// pad pads the query with 0x80 followed by zeros, to a length
+// which is a multiple of 64 bytes and at least 256 bytes.
+func pad(query []byte) (padded []byte) {
+	length := len(query) + 1
+	if remainder := length % paddingBlockLength; remainder != 0 {
+		length += paddingBlockLength - remainder
+	}
+	if length < minPaddedLength {
+		length = minPaddedLength
+	}
+	padded = make([]byte, length)
+	copy(padded, query)
+	padded[len(query)] = paddingStart
+	return padded
+}
+
+var ErrPaddingNotValid = errors.New("padding is not valid")
+
+// unpad removes the padding added by pad.
+func unpad(padded []byte) (message []byte, err error) {
+	end := bytes.LastIndexByte(padded, paddingStart)
+	if end == -1 {
+		return nil, fmt.Errorf("%w", ErrPaddingNotValid)
+	}
+	for _, b := range padded[end+1:] {
+		if b != 0 {
+			return nil, fmt.Errorf("%w", ErrPaddingNotValid)
+		}
+	}
+	return padded[:end], nil
+}
@@ -0,0 +1,278 @@
+package dnsupstream
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/nacl/box"
+)
+
+const testProviderName = "2.dnscrypt-cert.example.com"
+
+type testResolverCertificate struct {
+	clientMagic [8]byte
+	publicKey   *[32]byte
+	privateKey  *[32]byte
+	serial      uint32
+}
+
+// testDNSCryptServer is a DNSCrypt server over TCP answering each
+// query using the answer function with the marker 0x83. Only its
+// current certificate is served and accepted.
+type testDNSCryptServer struct {
+	providerPrivateKey ed25519.PrivateKey
+	mutex              sync.Mutex
+	certificate        testResolverCertificate
+}
+
+// rotate replaces the server certificate with a new one.
+func (s *testDNSCryptServer) rotate(t *testing.T) {
+	t.Helper()
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	certificate := testResolverCertificate{
+		publicKey:  publicKey,
+		privateKey: privateKey,
+		serial:     s.certificate.serial + 1,
+	}
+	_, err = rand.Read(certificate.clientMagic[:])
+	require.NoError(t, err)
+	s.certificate = certificate
+}
+
+func (s *testDNSCryptServer) getCertificate() testResolverCertificate {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.certificate
+}
+
+// runDNSCryptServer runs a DNSCrypt server and returns it
+// with its address and its provider public key.
+func runDNSCryptServer(t *testing.T) (server *testDNSCryptServer,
+	address string, providerPublicKey ed25519.PublicKey) {
+	t.Helper()
+
+	providerPublicKey, providerPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	server = &testDNSCryptServer{providerPrivateKey: providerPrivateKey}
+	server.rotate(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer connection.Close()
+				query, err := readMessage(connection)
+				if err != nil {
+					return
+				}
+				certificate := server.getCertificate()
+				var response []byte
+				if bytes.HasPrefix(query, certificate.clientMagic[:]) {
+					response = server.answerEncrypted(query, certificate)
+				} else {
+					response = server.answerCertificate(query, certificate)
+				}
+				if response != nil {
+					_ = writeMessage(connection, response)
+				}
+			}()
+		}
+	}()
+
+	return server, listener.Addr().String(), providerPublicKey
+}
+
+func (s *testDNSCryptServer) answerCertificate(query []byte,
+	certificate testResolverCertificate) (response []byte) {
+	signed := make([]byte, 0, 52) //nolint:gomnd
+	signed = append(signed, certificate.publicKey[:]...)
+	signed = append(signed, certificate.clientMagic[:]...)
+	signed = binary.BigEndian.AppendUint32(signed, certificate.serial)
+	now := time.Now()
+	signed = binary.BigEndian.AppendUint32(signed, uint32(now.Add(-time.Hour).Unix()))
+	signed = binary.BigEndian.AppendUint32(signed, uint32(now.Add(time.Hour).Unix()))
+
+	record := []byte("DNSC")
+	record = append(record, 0, esVersionXSalsa20Poly1305, 0, 0)
+	record = append(record, ed25519.Sign(s.providerPrivateKey, signed)...)
+	record = append(record, signed...)
+
+	response = make([]byte, len(query))
+	copy(response, query)
+	response[2] = 0x81
+	response[7] = 1                                                                // one answer
+	response = append(response, 0xc0, headerLength, 0, typeTXT, 0, 1, 0, 0, 0, 60) //nolint:gomnd
+	response = binary.BigEndian.AppendUint16(response, uint16(1+len(record)))
+	response = append(response, byte(len(record)))
+	response = append(response, record...)
+	return response
+}
+
+func (s *testDNSCryptServer) answerEncrypted(packet []byte,
+	certificate testResolverCertificate) (response []byte) {
+	packet = packet[len(certificate.clientMagic):]
+	var clientPublicKey [32]byte
+	copy(clientPublicKey[:], packet[:32])
+	packet = packet[32:]
+	var nonce [nonceLength]byte
+	copy(nonce[:], packet[:halfNonceLength])
+	packet = packet[halfNonceLength:]
+
+	var sharedKey [32]byte
+	box.Precompute(&sharedKey, &clientPublicKey, certificate.privateKey)
+	padded, ok := box.OpenAfterPrecomputation(nil, packet, &nonce, &sharedKey)
+	if !ok {
+		return nil
+	}
+	query, err := unpad(padded)
+	if err != nil {
+		return nil
+	}
+
+	_, _ = rand.Read(nonce[halfNonceLength:])
+	response = append(response, resolverMagic...)
+	response = append(response, nonce[:]...)
+	return box.SealAfterPrecomputation(response, pad(answer(query, 0x83)), &nonce, &sharedKey)
+}
+
+func Test_dnscryptUpstream_exchange(t *testing.T) {
+	t.Parallel()
+
+	server, address, providerPublicKey := runDNSCryptServer(t)
+
+	upstream, err := newDNSCryptUpstream(address, testProviderName, providerPublicKey)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	response, err := upstream.exchange(ctx, testQuery)
+	require.NoError(t, err)
+	assert.Equal(t, answer(testQuery, 0x83), response)
+	assert.Equal(t, uint32(1), upstream.certificate.serial)
+
+	// The resolver certificate rotates and the previous
+	// certificate is no longer accepted by the resolver.
+	server.rotate(t)
+
+	response, err = upstream.exchange(ctx, testQuery)
+	require.NoError(t, err)
+	assert.Equal(t, answer(testQuery, 0x83), response)
+	assert.Equal(t, uint32(2), upstream.certificate.serial)
+}
+
+func Test_parseCertificate(t *testing.T) {
+	t.Parallel()
+
+	providerPublicKey, providerPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+
+	makeCertificate := func(esVersion byte, notBefore, notAfter time.Time,
+		privateKey ed25519.PrivateKey) []byte {
+		signed := make([]byte, 52) //nolint:gomnd
+		signed[0] = 0xaa
+		copy(signed[32:40], "magic123")
+		binary.BigEndian.PutUint32(signed[40:], 7) //nolint:gomnd
+		binary.BigEndian.PutUint32(signed[44:], uint32(notBefore.Unix()))
+		binary.BigEndian.PutUint32(signed[48:], uint32(notAfter.Unix()))
+		data := []byte{'D', 'N', 'S', 'C', 0, esVersion, 0, 0}
+		data = append(data, ed25519.Sign(privateKey, signed)...)
+		return append(data, signed...)
+	}
+
+	_, otherPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		data        []byte
+		certificate dnscryptCertificate
+		errWrapped  error
+		errMessage  string
+	}{
+		"too_short": {
+			data:       []byte("DNSC"),
+			errWrapped: ErrCertificateNotValid,
+			errMessage: "DNSCrypt certificate is not valid: 4 bytes is too short",
+		},
+		"unsupported_version": {
+			data: makeCertificate(2, now.Add(-time.Hour), now.Add(time.Hour),
+				providerPrivateKey),
+			errWrapped: ErrCertificateNotValid,
+			errMessage: "DNSCrypt certificate is not valid: " +
+				"encryption system version 2 is not supported",
+		},
+		"bad_signature": {
+			data: makeCertificate(1, now.Add(-time.Hour), now.Add(time.Hour),
+				otherPrivateKey),
+			errWrapped: ErrCertificateNotValid,
+			errMessage: "DNSCrypt certificate is not valid: bad signature",
+		},
+		"expired": {
+			data: makeCertificate(1, now.Add(-2*time.Hour), now.Add(-time.Hour),
+				providerPrivateKey),
+			errWrapped: ErrCertificateNotValid,
+			errMessage: "DNSCrypt certificate is not valid: " +
+				"valid from 2023-11-14T20:13:20Z to 2023-11-14T21:13:20Z",
+		},
+		"valid": {
+			data: makeCertificate(1, now.Add(-time.Hour), now.Add(time.Hour),
+				providerPrivateKey),
+			certificate: dnscryptCertificate{
+				resolverPublicKey: [32]byte{0xaa},
+				clientMagic:       [8]byte{'m', 'a', 'g', 'i', 'c', '1', '2', '3'},
+				serial:            7,
+				notBefore:         now.Add(-time.Hour),
+				notAfter:          now.Add(time.Hour),
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			certificate, err := parseCertificate(testCase.data, providerPublicKey, now)
+
+			assert.Equal(t, testCase.certificate, certificate)
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
+
+func Test_pad(t *testing.T) {
+	t.Parallel()
+
+	padded := pad(testQuery)
+	assert.Len(t, padded, minPaddedLength)
+
+	message, err := unpad(padded)
+	require.NoError(t, err)
+	assert.Equal(t, testQuery, message)
+
+	long := make([]byte, minPaddedLength)
+	assert.Len(t, pad(long), minPaddedLength+paddingBlockLength)
+}
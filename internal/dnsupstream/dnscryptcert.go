@@ -0,0 +1,237 @@
+package dnsupstream
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// dnscryptCertificate is a DNSCrypt resolver certificate.
+type dnscryptCertificate struct {
+	resolverPublicKey [32]byte
+	clientMagic       [8]byte
+	serial            uint32
+	notBefore         time.Time
+	notAfter          time.Time
+}
+
+var (
+	ErrCertificateNotValid = errors.New("DNSCrypt certificate is not valid")
+	ErrNoValidCertificate  = errors.New("no valid DNSCrypt certificate")
+	ErrResponseNotValid    = errors.New("DNS response is not valid")
+)
+
+// esVersionXSalsa20Poly1305 is the X25519-XSalsa20Poly1305
+// encryption system version, which is the only one supported.
+const esVersionXSalsa20Poly1305 = 1
+
+// fetchCertificate queries the resolver over TCP for the TXT records
+// of the provider name, and returns the valid certificate signed with
+// the provider public key having the highest serial number.
+func fetchCertificate(ctx context.Context, dialer *net.Dialer, address, providerName string,
+	providerPublicKey ed25519.PublicKey, now time.Time) (
+	certificate dnscryptCertificate, err error) {
+	query, err := newTXTQuery(providerName)
+	if err != nil {
+		return certificate, err
+	}
+
+	connection, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return certificate, fmt.Errorf("dialing: %w", err)
+	}
+	defer connection.Close()
+
+	err = connection.SetDeadline(exchangeDeadline(ctx))
+	if err != nil {
+		return certificate, fmt.Errorf("setting deadline: %w", err)
+	}
+
+	err = writeMessage(connection, query)
+	if err != nil {
+		return certificate, fmt.Errorf("writing query: %w", err)
+	}
+
+	response, err := readMessage(connection)
+	if err != nil {
+		return certificate, fmt.Errorf("reading response: %w", err)
+	}
+
+	records, err := parseTXTRecords(query, response)
+	if err != nil {
+		return certificate, err
+	}
+
+	found := false
+	var errs []string
+	for _, record := range records {
+		candidate, err := parseCertificate(record, providerPublicKey, now)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if !found || candidate.serial > certificate.serial {
+			certificate = candidate
+			found = true
+		}
+	}
+
+	if !found {
+		return certificate, fmt.Errorf("%w: from %d TXT records: %s",
+			ErrNoValidCertificate, len(records), strings.Join(errs, "; "))
+	}
+	return certificate, nil
+}
+
+// parseCertificate parses the binary DNSCrypt certificate given,
+// verifies its signature with the provider public key given and
+// checks it is valid at the time given.
+func parseCertificate(data []byte, providerPublicKey ed25519.PublicKey,
+	now time.Time) (certificate dnscryptCertificate, err error) {
+	const (
+		signatureStart = 8
+		signedStart    = signatureStart + ed25519.SignatureSize
+		minLength      = signedStart + 52
+	)
+	if len(data) < minLength {
+		return certificate, fmt.Errorf("%w: %d bytes is too short", ErrCertificateNotValid, len(data))
+	} else if !bytes.Equal(data[:4], []byte("DNSC")) {
+		return certificate, fmt.Errorf("%w: bad magic", ErrCertificateNotValid)
+	}
+
+	esVersion := binary.BigEndian.Uint16(data[4:6])
+	if esVersion != esVersionXSalsa20Poly1305 {
+		return certificate, fmt.Errorf("%w: encryption system version %d is not supported",
+			ErrCertificateNotValid, esVersion)
+	}
+
+	signature := data[signatureStart:signedStart]
+	signed := data[signedStart:]
+	if !ed25519.Verify(providerPublicKey, signed, signature) {
+		return certificate, fmt.Errorf("%w: bad signature", ErrCertificateNotValid)
+	}
+
+	copy(certificate.resolverPublicKey[:], signed[0:32])
+	copy(certificate.clientMagic[:], signed[32:40])
+	certificate.serial = binary.BigEndian.Uint32(signed[40:44])
+	certificate.notBefore = time.Unix(int64(binary.BigEndian.Uint32(signed[44:48])), 0)
+	certificate.notAfter = time.Unix(int64(binary.BigEndian.Uint32(signed[48:52])), 0)
+
+	if now.Before(certificate.notBefore) || now.After(certificate.notAfter) {
+		return dnscryptCertificate{}, fmt.Errorf("%w: valid from %s to %s", ErrCertificateNotValid,
+			certificate.notBefore.UTC().Format(time.RFC3339),
+			certificate.notAfter.UTC().Format(time.RFC3339))
+	}
+
+	return certificate, nil
+}
+
+const typeTXT = 16
+
+// newTXTQuery returns a DNS query for the TXT records of the name given.
+func newTXTQuery(name string) (query []byte, err error) {
+	query = make([]byte, headerLength, headerLength+len(name)+6) //nolint:gomnd
+	_, err = rand.Read(query[:2])
+	if err != nil {
+		return nil, fmt.Errorf("generating message ID: %w", err)
+	}
+	const flagRecursionDesired = 0x01
+	query[2] = flagRecursionDesired
+	query[5] = 1 // one question
+
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		query = append(query, byte(len(label)))
+		query = append(query, label...)
+	}
+	const classIN = 1
+	query = append(query, 0, 0, typeTXT, 0, classIN)
+	return query, nil
+}
+
+// parseTXTRecords returns the data of each TXT record in the answer
+// section of the response given, for the query given.
+func parseTXTRecords(query, response []byte) (records [][]byte, err error) {
+	if len(response) < headerLength {
+		return nil, fmt.Errorf("%w: %d bytes is too short", ErrResponseNotValid, len(response))
+	} else if response[0] != query[0] || response[1] != query[1] {
+		return nil, fmt.Errorf("%w: message ID does not match", ErrResponseNotValid)
+	}
+	const rcodeMask = 0x0f
+	if rcode := response[3] & rcodeMask; rcode != 0 {
+		return nil, fmt.Errorf("%w: response code %d", ErrResponseNotValid, rcode)
+	}
+
+	questions := binary.BigEndian.Uint16(response[4:6])
+	answers := binary.BigEndian.Uint16(response[6:8])
+	offset := headerLength
+	for i := uint16(0); i < questions; i++ {
+		offset, err = skipName(response, offset)
+		if err != nil {
+			return nil, err
+		}
+		offset += 4 // type and class
+	}
+
+	for i := uint16(0); i < answers; i++ {
+		offset, err = skipName(response, offset)
+		if err != nil {
+			return nil, err
+		}
+		const fixedLength = 10 // type, class, TTL and data length
+		if len(response) < offset+fixedLength {
+			return nil, fmt.Errorf("%w: answer is truncated", ErrResponseNotValid)
+		}
+		recordType := binary.BigEndian.Uint16(response[offset:])
+		dataLength := int(binary.BigEndian.Uint16(response[offset+8:]))
+		offset += fixedLength
+		if len(response) < offset+dataLength {
+			return nil, fmt.Errorf("%w: answer data is truncated", ErrResponseNotValid)
+		}
+		data := response[offset : offset+dataLength]
+		offset += dataLength
+
+		if recordType != typeTXT {
+			continue
+		}
+		// TXT data is a sequence of length prefixed strings
+		var record []byte
+		for len(data) > 0 {
+			length := int(data[0])
+			if len(data) < 1+length {
+				return nil, fmt.Errorf("%w: TXT string is truncated", ErrResponseNotValid)
+			}
+			record = append(record, data[1:1+length]...)
+			data = data[1+length:]
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// skipName returns the offset following the DNS name
+// starting at the offset given in the message.
+func skipName(message []byte, offset int) (next int, err error) {
+	for {
+		if offset >= len(message) {
+			return 0, fmt.Errorf("%w: name is truncated", ErrResponseNotValid)
+		}
+		length := int(message[offset])
+		const pointerMask = 0xc0
+		switch {
+		case length == 0:
+			return offset + 1, nil
+		case length&pointerMask == pointerMask:
+			return offset + 2, nil //nolint:gomnd
+		default:
+			offset += 1 + length
+		}
+	}
+}
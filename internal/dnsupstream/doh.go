@@ -0,0 +1,92 @@
+package dnsupstream
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+const dohContentType = "application/dns-message"
+
+type dohUpstream struct {
+	address    string
+	serverName string
+	url        string
+	client     *http.Client
+}
+
+func newDoHUpstream(address, serverName string) *dohUpstream {
+	const (
+		dialTimeout     = 5 * time.Second
+		idleConnTimeout = 30 * time.Second
+	)
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	transport := &http.Transport{
+		// Always dial the resolver address, since the server name
+		// cannot be resolved without a working DNS.
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		},
+		TLSClientConfig: &tls.Config{
+			ServerName: serverName,
+			MinVersion: tls.VersionTLS12,
+		},
+		ForceAttemptHTTP2: true,
+		IdleConnTimeout:   idleConnTimeout,
+	}
+	return &dohUpstream{
+		address:    address,
+		serverName: serverName,
+		url:        "https://" + serverName + "/dns-query",
+		client:     &http.Client{Transport: transport},
+	}
+}
+
+func (d *dohUpstream) String() string {
+	return "doh://" + d.address + "#" + d.serverName
+}
+
+var ErrHTTPStatusCodeNotOK = errors.New("HTTP status code not OK")
+
+// exchange sends the query given to the resolver in an HTTP POST
+// request as described in RFC 8484, and returns its response.
+func (d *dohUpstream) exchange(ctx context.Context, query []byte) (
+	response []byte, err error) {
+	ctx, cancel := context.WithDeadline(ctx, exchangeDeadline(ctx))
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		d.url, bytes.NewReader(query))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", dohContentType)
+	request.Header.Set("Accept", dohContentType)
+
+	httpResponse, err := d.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrHTTPStatusCodeNotOK, httpResponse.Status)
+	}
+
+	const maxMessageLength = 65535
+	response, err = io.ReadAll(io.LimitReader(httpResponse.Body, maxMessageLength))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	return response, nil
+}
+
+func (d *dohUpstream) close() {
+	d.client.CloseIdleConnections()
+}
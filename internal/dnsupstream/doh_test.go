@@ -0,0 +1,46 @@
+package dnsupstream
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_dohUpstream_exchange(t *testing.T) {
+	t.Parallel()
+
+	certificate, pool := newTestCertificate(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != "/dns-query" ||
+				r.Host != testServerName ||
+				r.Header.Get("Content-Type") != dohContentType {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			query, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", dohContentType)
+			_, _ = w.Write(answer(query, 0x83))
+		}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	upstream := newDoHUpstream(server.Listener.Addr().String(), testServerName)
+	upstream.client.Transport.(*http.Transport).TLSClientConfig.RootCAs = pool //nolint:forcetypeassert
+	t.Cleanup(upstream.close)
+
+	response, err := upstream.exchange(context.Background(), testQuery)
+	require.NoError(t, err)
+	assert.Equal(t, answer(testQuery, 0x83), response)
+}
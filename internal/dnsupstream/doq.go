@@ -0,0 +1,190 @@
+package dnsupstream
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+type doqUpstream struct {
+	address    string
+	tlsConfig  *tls.Config
+	quicConfig *quic.Config
+	// fallback is the DNS over TLS upstream on the same address,
+	// used for fallbackPeriod once dialing over QUIC fails.
+	fallback       *dotUpstream
+	fallbackPeriod time.Duration
+	logger         Logger
+	timeNow        func() time.Time
+
+	mutex         sync.Mutex
+	connection    quic.Connection
+	fallbackUntil time.Time
+}
+
+func newDoQUpstream(address, serverName string, logger Logger) *doqUpstream {
+	const (
+		handshakeTimeout = 3 * time.Second
+		idleTimeout      = 30 * time.Second
+		fallbackPeriod   = 10 * time.Minute
+	)
+	return &doqUpstream{
+		address: address,
+		tlsConfig: &tls.Config{
+			ServerName: serverName,
+			MinVersion: tls.VersionTLS13,
+			NextProtos: []string{"doq"},
+		},
+		quicConfig: &quic.Config{
+			HandshakeIdleTimeout: handshakeTimeout,
+			MaxIdleTimeout:       idleTimeout,
+		},
+		fallback:       newDoTUpstream(address, serverName),
+		fallbackPeriod: fallbackPeriod,
+		logger:         logger,
+		timeNow:        time.Now,
+	}
+}
+
+func (d *doqUpstream) String() string {
+	return "doq://" + d.address + "#" + d.tlsConfig.ServerName
+}
+
+var errDialQUIC = errors.New("dialing over QUIC failed")
+
+// exchange sends the query given to the resolver over QUIC, falling
+// back on DNS over TLS if the QUIC connection cannot be established,
+// which is usually due to UDP port 853 being blocked on the network.
+func (d *doqUpstream) exchange(ctx context.Context, query []byte) (
+	response []byte, err error) {
+	d.mutex.Lock()
+	fallingBack := d.timeNow().Before(d.fallbackUntil)
+	d.mutex.Unlock()
+	if fallingBack {
+		return d.fallback.exchange(ctx, query)
+	}
+
+	response, err = d.exchangeQUIC(ctx, query)
+	if err == nil || ctx.Err() != nil || !errors.Is(err, errDialQUIC) {
+		return response, err
+	}
+
+	d.logger.Warn(d.String() + ": " + err.Error() + ", falling back on DNS over TLS for " +
+		d.fallbackPeriod.String())
+	d.mutex.Lock()
+	d.fallbackUntil = d.timeNow().Add(d.fallbackPeriod)
+	d.mutex.Unlock()
+	return d.fallback.exchange(ctx, query)
+}
+
+func (d *doqUpstream) exchangeQUIC(ctx context.Context, query []byte) (
+	response []byte, err error) {
+	connection, err := d.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err = exchangeStream(ctx, connection, query)
+	if err == nil || ctx.Err() != nil {
+		return response, err
+	}
+
+	// The resolver may have closed the connection after it
+	// was idle, so retry once with a new connection.
+	d.closeConnection(connection)
+	connection, err = d.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return exchangeStream(ctx, connection, query)
+}
+
+func (d *doqUpstream) getConnection(ctx context.Context) (
+	connection quic.Connection, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.connection != nil {
+		return d.connection, nil
+	}
+
+	connection, err = quic.DialAddr(ctx, d.address, d.tlsConfig, d.quicConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errDialQUIC, err)
+	}
+	d.connection = connection
+	return connection, nil
+}
+
+// closeConnection closes the connection given if it is
+// still the connection in use.
+func (d *doqUpstream) closeConnection(connection quic.Connection) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.connection != connection {
+		return
+	}
+	const doqNoError = 0
+	_ = connection.CloseWithError(doqNoError, "")
+	d.connection = nil
+}
+
+func (d *doqUpstream) close() {
+	d.mutex.Lock()
+	connection := d.connection
+	d.mutex.Unlock()
+	if connection != nil {
+		d.closeConnection(connection)
+	}
+}
+
+var ErrMessageTooShort = errors.New("DNS message is too short")
+
+// exchangeStream sends the query on a new stream of the connection
+// given and returns the response. As required by RFC 9250 section
+// 4.2.1, the message ID is set to 0 in the query sent, and is set
+// back to the query message ID in the response returned.
+func exchangeStream(ctx context.Context, connection quic.Connection,
+	query []byte) (response []byte, err error) {
+	if len(query) < headerLength {
+		return nil, fmt.Errorf("%w: query has %d bytes", ErrMessageTooShort, len(query))
+	}
+	doqQuery := make([]byte, len(query))
+	copy(doqQuery, query)
+	doqQuery[0], doqQuery[1] = 0, 0
+
+	stream, err := connection.OpenStreamSync(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("opening stream: %w", err)
+	}
+	defer stream.CancelRead(0)
+
+	err = stream.SetDeadline(exchangeDeadline(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("setting deadline: %w", err)
+	}
+
+	err = writeMessage(stream, doqQuery)
+	if err != nil {
+		return nil, fmt.Errorf("writing query: %w", err)
+	}
+	// The client must indicate it has no more data to send
+	// on the stream, see RFC 9250 section 4.2.
+	err = stream.Close()
+	if err != nil {
+		return nil, fmt.Errorf("closing stream: %w", err)
+	}
+
+	response, err = readMessage(stream)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	} else if len(response) < headerLength {
+		return nil, fmt.Errorf("%w: response has %d bytes", ErrMessageTooShort, len(response))
+	}
+	response[0], response[1] = query[0], query[1]
+	return response, nil
+}
@@ -0,0 +1,168 @@
+package dnsupstream
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/tlscert"
+	"github.com/quic-go/quic-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testServerName = "dns.example.com"
+
+// newTestCertificate returns a self-signed certificate for
+// testServerName and a certificate pool trusting it.
+func newTestCertificate(t *testing.T) (certificate tls.Certificate, pool *x509.CertPool) {
+	t.Helper()
+	certificate, _, err := tlscert.LoadOrGenerate("", "", t.TempDir(),
+		[]string{testServerName})
+	require.NoError(t, err)
+	parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+	require.NoError(t, err)
+	pool = x509.NewCertPool()
+	pool.AddCert(parsed)
+	return certificate, pool
+}
+
+// testQuery is a query for example.com A with the message ID 0x1234.
+var testQuery = []byte{
+	0x12, 0x34, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x07, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 0x03, 'c', 'o', 'm', 0x00,
+	0x00, 0x01, 0x00, 0x01,
+}
+
+// answer returns the query given as a response, with the
+// first byte of its flags set to the marker given.
+func answer(query []byte, marker byte) (response []byte) {
+	response = make([]byte, len(query))
+	copy(response, query)
+	response[2] = marker
+	return response
+}
+
+// runDoQServer runs a DNS over QUIC server answering each query
+// using the answer function, and returns its address.
+func runDoQServer(t *testing.T, certificate tls.Certificate) (address string) {
+	t.Helper()
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		NextProtos:   []string{"doq"},
+		MinVersion:   tls.VersionTLS13,
+	}
+	listener, err := quic.ListenAddr("127.0.0.1:0", tlsConfig, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			connection, err := listener.Accept(context.Background())
+			if err != nil {
+				return
+			}
+			go func() {
+				for {
+					stream, err := connection.AcceptStream(context.Background())
+					if err != nil {
+						return
+					}
+					query, err := readMessage(stream)
+					if err != nil || query[0] != 0 || query[1] != 0 {
+						stream.CancelWrite(1)
+						continue
+					}
+					_ = writeMessage(stream, answer(query, 0x81))
+					_ = stream.Close()
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// runDoTServer runs a DNS over TLS server answering each query
+// using the answer function, and returns its address.
+func runDoTServer(t *testing.T, certificate tls.Certificate) (address string) {
+	t.Helper()
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(connection net.Conn) {
+				defer connection.Close()
+				query, err := readMessage(connection)
+				if err != nil {
+					return
+				}
+				_ = writeMessage(connection, answer(query, 0x82))
+			}(connection)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func Test_doqUpstream_exchange(t *testing.T) {
+	t.Parallel()
+
+	certificate, pool := newTestCertificate(t)
+
+	t.Run("over QUIC", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		upstream := newDoQUpstream(runDoQServer(t, certificate), testServerName, NewMockLogger(ctrl))
+		upstream.tlsConfig.RootCAs = pool
+		t.Cleanup(upstream.close)
+
+		for i := 0; i < 2; i++ { // second exchange reuses the connection
+			response, err := upstream.exchange(context.Background(), testQuery)
+			require.NoError(t, err)
+			assert.Equal(t, answer(testQuery, 0x81), response)
+		}
+	})
+
+	t.Run("fallback on DNS over TLS", func(t *testing.T) {
+		t.Parallel()
+		// Nothing listens on the UDP port of the DNS over TLS server,
+		// as if QUIC was blocked.
+		ctrl := gomock.NewController(t)
+		logger := NewMockLogger(ctrl)
+		address := runDoTServer(t, certificate)
+		upstream := newDoQUpstream(address, testServerName, logger)
+		upstream.tlsConfig.RootCAs = pool
+		logger.EXPECT().Warn(gomock.Any()).Do(func(s string) {
+			assert.True(t, strings.HasPrefix(s, "doq://"+address+"#"+testServerName+": "), s)
+			assert.True(t, strings.HasSuffix(s, ", falling back on DNS over TLS for "+
+				upstream.fallbackPeriod.String()), s)
+		})
+		upstream.fallback.tlsConfig.RootCAs = pool
+		upstream.quicConfig.HandshakeIdleTimeout = 100 * time.Millisecond
+		now := time.Unix(0, 0)
+		upstream.timeNow = func() time.Time { return now }
+
+		response, err := upstream.exchange(context.Background(), testQuery)
+		require.NoError(t, err)
+		assert.Equal(t, answer(testQuery, 0x82), response)
+		assert.Equal(t, now.Add(upstream.fallbackPeriod), upstream.fallbackUntil)
+	})
+}
@@ -0,0 +1,72 @@
+package dnsupstream
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+)
+
+type dotUpstream struct {
+	address   string
+	tlsConfig *tls.Config
+	dialer    *net.Dialer
+}
+
+func newDoTUpstream(address, serverName string) *dotUpstream {
+	const dialTimeout = 5 * time.Second
+	return &dotUpstream{
+		address: address,
+		tlsConfig: &tls.Config{
+			ServerName: serverName,
+			MinVersion: tls.VersionTLS12,
+		},
+		dialer: &net.Dialer{Timeout: dialTimeout},
+	}
+}
+
+func (d *dotUpstream) String() string {
+	return "dot://" + d.address + "#" + d.tlsConfig.ServerName
+}
+
+// exchange sends the query given to the resolver over a new
+// TLS connection and returns its response.
+func (d *dotUpstream) exchange(ctx context.Context, query []byte) (
+	response []byte, err error) {
+	tlsDialer := &tls.Dialer{NetDialer: d.dialer, Config: d.tlsConfig}
+	connection, err := tlsDialer.DialContext(ctx, "tcp", d.address)
+	if err != nil {
+		return nil, fmt.Errorf("dialing: %w", err)
+	}
+	defer connection.Close()
+
+	err = connection.SetDeadline(exchangeDeadline(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("setting deadline: %w", err)
+	}
+
+	err = writeMessage(connection, query)
+	if err != nil {
+		return nil, fmt.Errorf("writing query: %w", err)
+	}
+
+	response, err = readMessage(connection)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	return response, nil
+}
+
+// exchangeDeadline returns the deadline of an exchange with
+// a resolver, which is the context deadline if it is sooner.
+func exchangeDeadline(ctx context.Context) (deadline time.Time) {
+	const timeout = 5 * time.Second
+	deadline = time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	return deadline
+}
+
+func (d *dotUpstream) close() {}
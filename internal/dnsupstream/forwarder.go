@@ -0,0 +1,239 @@
+// Package dnsupstream forwards plain DNS queries received over TCP
+// to encrypted DNS resolvers, for protocols Unbound does not support
+// such as DNS over QUIC and DNSCrypt.
+package dnsupstream
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+)
+
+type upstream interface {
+	exchange(ctx context.Context, query []byte) (response []byte, err error)
+	close()
+	String() string
+}
+
+type Forwarder struct {
+	upstreams   []upstream
+	probePeriod time.Duration
+	recorder    AnswerRecorder
+	logger      Logger
+
+	healthMutex sync.RWMutex
+	healthy     []bool
+}
+
+// New creates a forwarder sending each query to the upstreams
+// given in order, until one of them answers. Upstreams failing
+// are marked as unhealthy and are tried last, until they answer
+// again. If the probe period given is not zero, all the upstreams
+// are probed every period to detect their recovery or failure.
+func New(upstreams []settings.DNSUpstream, probePeriod time.Duration,
+	logger Logger) (forwarder *Forwarder, err error) {
+	forwarder = &Forwarder{
+		upstreams:   make([]upstream, len(upstreams)),
+		probePeriod: probePeriod,
+		logger:      logger,
+		healthy:     make([]bool, len(upstreams)),
+	}
+	for i, upstream := range upstreams {
+		forwarder.healthy[i] = true
+		switch upstream.Protocol {
+		case constants.DNSUpstreamDoH:
+			forwarder.upstreams[i] = newDoHUpstream(upstream.Address,
+				upstream.ServerName)
+		case constants.DNSUpstreamDoQ:
+			forwarder.upstreams[i] = newDoQUpstream(upstream.Address,
+				upstream.ServerName, logger)
+		case constants.DNSUpstreamDNSCrypt:
+			publicKey, err := hex.DecodeString(upstream.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("decoding public key of %s: %w", upstream, err)
+			}
+			forwarder.upstreams[i], err = newDNSCryptUpstream(upstream.Address,
+				upstream.ServerName, publicKey)
+			if err != nil {
+				return nil, fmt.Errorf("creating DNSCrypt upstream %s: %w", upstream, err)
+			}
+		default:
+			forwarder.upstreams[i] = newDoTUpstream(upstream.Address,
+				upstream.ServerName)
+		}
+	}
+	return forwarder, nil
+}
+
+// Start listens for DNS over TCP queries on the address given,
+// and serves them in a goroutine until the context is canceled,
+// at which point the done channel returned is closed.
+func (f *Forwarder) Start(ctx context.Context, address string) (
+	done <-chan struct{}, err error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listening: %w", err)
+	}
+
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		probeDone := make(chan struct{})
+		go func() {
+			defer close(probeDone)
+			if f.probePeriod > 0 {
+				f.probe(ctx, f.probePeriod)
+			}
+		}()
+		f.serve(ctx, listener)
+		<-probeDone
+		for _, upstream := range f.upstreams {
+			upstream.close()
+		}
+	}()
+	return doneCh, nil
+}
+
+// serve accepts connections on the listener until the context is
+// canceled or the listener is closed. Accept errors are retried
+// with an exponential backoff, like the net/http server does.
+func (f *Forwarder) serve(ctx context.Context, listener net.Listener) {
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+
+	const minBackoff, maxBackoff = 5 * time.Millisecond, time.Second
+	backoff := minBackoff
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			} else if errors.Is(err, net.ErrClosed) {
+				f.logger.Error("accepting connection: " + err.Error())
+				return
+			}
+			f.logger.Error("accepting connection: " + err.Error() +
+				" (retrying in " + backoff.String() + ")")
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+			continue
+		}
+		backoff = minBackoff
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.serveConnection(ctx, connection)
+		}()
+	}
+}
+
+// serveConnection answers the queries received on the connection,
+// concurrently since responses over TCP may be sent in any order.
+func (f *Forwarder) serveConnection(ctx context.Context, connection net.Conn) {
+	defer connection.Close()
+
+	connectionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-connectionCtx.Done()
+		_ = connection.SetDeadline(time.Now())
+	}()
+
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	writeMutex := &sync.Mutex{}
+
+	const idleTimeout = 10 * time.Second
+	for {
+		_ = connection.SetReadDeadline(time.Now().Add(idleTimeout))
+		query, err := readMessage(connection)
+		if err != nil {
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response := f.forward(connectionCtx, query)
+			writeMutex.Lock()
+			defer writeMutex.Unlock()
+			_ = writeMessage(connection, response)
+		}()
+	}
+}
+
+var errServerFailure = errors.New("server failure response")
+
+// forward sends the query to each upstream, healthy ones first,
+// until one answers without a server failure, and returns a server
+// failure response if none does.
+func (f *Forwarder) forward(ctx context.Context, query []byte) (response []byte) {
+	for _, index := range f.order() {
+		upstreamResponse, err := f.upstreams[index].exchange(ctx, query)
+		switch {
+		case err == nil && !isServerFailure(upstreamResponse):
+			f.setHealth(index, true, nil)
+			f.recordAnswer(upstreamResponse)
+			return upstreamResponse
+		case err == nil:
+			response = upstreamResponse
+			f.setHealth(index, false, errServerFailure)
+		case ctx.Err() != nil:
+			return serverFailure(query)
+		default:
+			f.setHealth(index, false, err)
+		}
+	}
+	if response != nil {
+		return response
+	}
+	return serverFailure(query)
+}
+
+const (
+	rcodeMask     = 0x0f
+	rcodeServFail = 2
+)
+
+// isServerFailure returns true if the response given
+// has the SERVFAIL response code.
+func isServerFailure(response []byte) bool {
+	return len(response) >= headerLength &&
+		response[3]&rcodeMask == rcodeServFail
+}
+
+// serverFailure returns the query given as a response
+// with the SERVFAIL response code.
+func serverFailure(query []byte) (response []byte) {
+	response = make([]byte, len(query))
+	copy(response, query)
+	if len(response) < headerLength {
+		return response
+	}
+	const flagResponse = 0x80
+	response[2] |= flagResponse
+	response[3] = response[3]&^rcodeMask | rcodeServFail
+	return response
+}
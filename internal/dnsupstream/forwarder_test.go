@@ -0,0 +1,182 @@
+package dnsupstream
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectUnhealthyWarn expects the warning logged once the DNS over
+// TLS upstream at the address given failed, ending with its error.
+func expectUnhealthyWarn(t *testing.T, logger *MockLogger, address string) {
+	t.Helper()
+	prefix := "dot://" + address + "#" + testServerName +
+		" is unhealthy, failing over to other upstreams: "
+	logger.EXPECT().Warn(gomock.Any()).Do(func(s string) {
+		assert.True(t, strings.HasPrefix(s, prefix), s)
+	})
+}
+
+func Test_Forwarder_forward(t *testing.T) {
+	t.Parallel()
+
+	certificate, pool := newTestCertificate(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unreachableAddress := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	testCases := map[string]struct {
+		addresses []string
+		response  []byte
+		unhealthy bool
+	}{
+		"first upstream answers": {
+			addresses: []string{runDoTServer(t, certificate), unreachableAddress},
+			response:  answer(testQuery, 0x82),
+		},
+		"second upstream answers": {
+			addresses: []string{unreachableAddress, runDoTServer(t, certificate)},
+			response:  answer(testQuery, 0x82),
+			unhealthy: true,
+		},
+		"no upstream answers": {
+			addresses: []string{unreachableAddress},
+			response:  serverFailure(testQuery),
+			unhealthy: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			upstreams := make([]settings.DNSUpstream, len(testCase.addresses))
+			for i, address := range testCase.addresses {
+				upstreams[i] = settings.DNSUpstream{
+					Protocol:   constants.DNSUpstreamDoT,
+					Address:    address,
+					ServerName: testServerName,
+				}
+			}
+			ctrl := gomock.NewController(t)
+			logger := NewMockLogger(ctrl)
+			if testCase.unhealthy {
+				expectUnhealthyWarn(t, logger, unreachableAddress)
+			}
+			forwarder, err := New(upstreams, 0, logger)
+			require.NoError(t, err)
+			for _, upstream := range forwarder.upstreams {
+				upstream.(*dotUpstream).tlsConfig.RootCAs = pool //nolint:forcetypeassert
+			}
+
+			response := forwarder.forward(context.Background(), testQuery)
+
+			assert.Equal(t, testCase.response, response)
+		})
+	}
+}
+
+func Test_Forwarder_health(t *testing.T) {
+	t.Parallel()
+
+	certificate, pool := newTestCertificate(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unreachableAddress := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	upstreams := []settings.DNSUpstream{
+		{Protocol: constants.DNSUpstreamDoT, Address: unreachableAddress, ServerName: testServerName},
+		{Protocol: constants.DNSUpstreamDoT, Address: runDoTServer(t, certificate), ServerName: testServerName},
+	}
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	expectUnhealthyWarn(t, logger, unreachableAddress)
+	forwarder, err := New(upstreams, 0, logger)
+	require.NoError(t, err)
+	for _, upstream := range forwarder.upstreams {
+		upstream.(*dotUpstream).tlsConfig.RootCAs = pool //nolint:forcetypeassert
+	}
+
+	assert.Equal(t, []int{0, 1}, forwarder.order())
+
+	response := forwarder.forward(context.Background(), testQuery)
+	assert.Equal(t, answer(testQuery, 0x82), response)
+	assert.Equal(t, []bool{false, true}, forwarder.healthy)
+	assert.Equal(t, []int{1, 0}, forwarder.order())
+
+	logger.EXPECT().Info("dot://" + unreachableAddress + "#" + testServerName + " recovered")
+	forwarder.setHealth(0, true, nil)
+	assert.Equal(t, []int{0, 1}, forwarder.order())
+}
+
+func Test_isServerFailure(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isServerFailure(serverFailure(testQuery)))
+	assert.False(t, isServerFailure(answer(testQuery, 0x81)))
+	assert.False(t, isServerFailure([]byte{0x00, 0x02}))
+}
+
+func Test_serverFailure(t *testing.T) {
+	t.Parallel()
+
+	response := serverFailure(testQuery)
+
+	expected := make([]byte, len(testQuery))
+	copy(expected, testQuery)
+	expected[2], expected[3] = 0x81, 0x02
+	assert.Equal(t, expected, response)
+}
+
+type errorListener struct {
+	net.Listener
+	errs []error
+}
+
+func (l *errorListener) Accept() (net.Conn, error) {
+	err := l.errs[0]
+	l.errs = l.errs[1:]
+	return nil, err
+}
+
+func Test_Forwarder_serve(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	listener := &errorListener{errs: []error{errTest, errTest, net.ErrClosed}}
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	gomock.InOrder(
+		logger.EXPECT().Error("accepting connection: test error (retrying in 5ms)"),
+		logger.EXPECT().Error("accepting connection: test error (retrying in 10ms)"),
+		logger.EXPECT().Error("accepting connection: "+net.ErrClosed.Error()),
+	)
+	forwarder := &Forwarder{logger: logger}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		forwarder.serve(context.Background(), listener)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("serve did not return on a closed listener")
+	}
+	assert.Empty(t, listener.errs)
+}
@@ -0,0 +1,81 @@
+package dnsupstream
+
+import (
+	"context"
+	"time"
+)
+
+// probeQuery is a query for the name servers of the root zone,
+// which every resolver can answer, with the message ID 0 as
+// required for DNS over QUIC.
+var probeQuery = []byte{ //nolint:gochecknoglobals
+	0x00, 0x00, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x02, 0x00, 0x01,
+}
+
+// order returns the indexes of the upstreams in the order to try
+// them, which is the healthy upstreams followed by the unhealthy
+// ones, each in their configured order.
+func (f *Forwarder) order() (indexes []int) {
+	f.healthMutex.RLock()
+	defer f.healthMutex.RUnlock()
+	indexes = make([]int, 0, len(f.upstreams))
+	for i, healthy := range f.healthy {
+		if healthy {
+			indexes = append(indexes, i)
+		}
+	}
+	for i, healthy := range f.healthy {
+		if !healthy {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// setHealth sets the health of the upstream at the index given,
+// and logs if its health changed.
+func (f *Forwarder) setHealth(index int, healthy bool, err error) {
+	f.healthMutex.Lock()
+	defer f.healthMutex.Unlock()
+	if f.healthy[index] == healthy {
+		return
+	}
+	f.healthy[index] = healthy
+
+	upstream := f.upstreams[index]
+	if healthy {
+		f.logger.Info(upstream.String() + " recovered")
+		return
+	}
+	f.logger.Warn(upstream.String() + " is unhealthy, failing over to other upstreams: " +
+		err.Error())
+}
+
+// probe sends the probe query to all upstreams every period
+// given to update their health, until the context is canceled.
+func (f *Forwarder) probe(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for i, upstream := range f.upstreams {
+			response, err := upstream.exchange(ctx, probeQuery)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				f.setHealth(i, false, err)
+			case isServerFailure(response):
+				f.setHealth(i, false, errServerFailure)
+			default:
+				f.setHealth(i, true, nil)
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package dnsupstream
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrMessageTooLong = errors.New("DNS message is too long")
+
+// headerLength is the length of the DNS message header.
+const headerLength = 12
+
+// writeMessage writes the DNS message given prefixed with its
+// two bytes length, as done for DNS over TCP, TLS and QUIC.
+func writeMessage(writer io.Writer, message []byte) (err error) {
+	const maxMessageLength = 65535
+	if len(message) > maxMessageLength {
+		return fmt.Errorf("%w: %d bytes", ErrMessageTooLong, len(message))
+	}
+
+	const lengthPrefixSize = 2
+	data := make([]byte, lengthPrefixSize+len(message))
+	binary.BigEndian.PutUint16(data, uint16(len(message)))
+	copy(data[lengthPrefixSize:], message)
+	_, err = writer.Write(data)
+	return err
+}
+
+// readMessage reads a DNS message prefixed with its two bytes length.
+func readMessage(reader io.Reader) (message []byte, err error) {
+	lengthPrefix := make([]byte, 2) //nolint:gomnd
+	_, err = io.ReadFull(reader, lengthPrefix)
+	if err != nil {
+		return nil, err
+	}
+	message = make([]byte, binary.BigEndian.Uint16(lengthPrefix))
+	_, err = io.ReadFull(reader, message)
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
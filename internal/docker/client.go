@@ -0,0 +1,197 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client is a minimal client for the Docker Engine API,
+// reached either through a unix socket or an http(s) URL
+// such as a Docker socket proxy.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+var ErrEndpointSchemeNotSupported = errors.New("endpoint scheme is not supported")
+
+// New creates a Docker API client for the endpoint given,
+// which is either unix:///path/to/docker.sock or an http(s) URL.
+func New(endpoint string) (client *Client, err error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parsing endpoint: %w", err)
+	}
+
+	const timeout = 30 * time.Second
+	httpClient := &http.Client{Timeout: timeout}
+
+	var baseURL string
+	switch endpointURL.Scheme {
+	case "unix":
+		socketPath := endpointURL.Path
+		dialer := &net.Dialer{}
+		httpClient.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socketPath)
+			},
+		}
+		// The host is ignored when dialing the unix socket.
+		baseURL = "http://docker"
+	case "http", "https":
+		baseURL = strings.TrimSuffix(endpointURL.String(), "/")
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrEndpointSchemeNotSupported, endpointURL.Scheme)
+	}
+
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+	}, nil
+}
+
+// Container is a container as listed by the Docker API.
+type Container struct {
+	ID         string            `json:"Id"`
+	Names      []string          `json:"Names"`
+	Labels     map[string]string `json:"Labels"`
+	HostConfig HostConfig        `json:"HostConfig"`
+}
+
+// HostConfig is the host configuration of a container
+// as listed by the Docker API.
+type HostConfig struct {
+	// NetworkMode is for example bridge, host or
+	// container:<name|id> for a container sharing
+	// the network stack of another container.
+	NetworkMode string `json:"NetworkMode"`
+}
+
+// Name returns the first name of the container without its
+// leading slash, or its short ID if it has no name.
+func (c Container) Name() string {
+	if len(c.Names) > 0 {
+		return strings.TrimPrefix(c.Names[0], "/")
+	}
+	const shortIDLength = 12
+	if len(c.ID) > shortIDLength {
+		return c.ID[:shortIDLength]
+	}
+	return c.ID
+}
+
+// ListContainers lists running containers matching the label
+// selector given, in the form key or key=value. If the label
+// selector is the empty string, all running containers are listed.
+func (c *Client) ListContainers(ctx context.Context, label string) (
+	containers []Container, err error) {
+	path := "/containers/json"
+	if label != "" {
+		filters, err := json.Marshal(map[string][]string{"label": {label}})
+		if err != nil {
+			return nil, fmt.Errorf("encoding filters: %w", err)
+		}
+		query := url.Values{"filters": {string(filters)}}
+		path += "?" + query.Encode()
+	}
+
+	response, err := c.do(ctx, http.MethodGet, path)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	decoder := json.NewDecoder(response.Body)
+	err = decoder.Decode(&containers)
+	if err != nil {
+		return nil, fmt.Errorf("decoding response body: %w", err)
+	}
+
+	return containers, nil
+}
+
+// Inspect returns the container with the given name or ID.
+// Only its ID, name, labels and host configuration are set.
+func (c *Client) Inspect(ctx context.Context, nameOrID string) (
+	container Container, err error) {
+	response, err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(nameOrID)+"/json")
+	if err != nil {
+		return container, err
+	}
+	defer response.Body.Close()
+
+	var data struct {
+		ID     string `json:"Id"`
+		Name   string `json:"Name"`
+		Config struct {
+			Labels map[string]string `json:"Labels"`
+		} `json:"Config"`
+		HostConfig HostConfig `json:"HostConfig"`
+	}
+	decoder := json.NewDecoder(response.Body)
+	err = decoder.Decode(&data)
+	if err != nil {
+		return container, fmt.Errorf("decoding response body: %w", err)
+	}
+
+	return Container{
+		ID:         data.ID,
+		Names:      []string{data.Name},
+		Labels:     data.Config.Labels,
+		HostConfig: data.HostConfig,
+	}, nil
+}
+
+// Restart restarts the container with the given ID.
+func (c *Client) Restart(ctx context.Context, id string) (err error) {
+	response, err := c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/restart")
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
+// Signal sends the signal given, such as SIGHUP, to the
+// container with the given ID.
+func (c *Client) Signal(ctx context.Context, id, signal string) (err error) {
+	query := url.Values{"signal": {signal}}
+	response, err := c.do(ctx, http.MethodPost,
+		"/containers/"+url.PathEscape(id)+"/kill?"+query.Encode())
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
+var ErrHTTPStatusNotOK = errors.New("HTTP status code is not OK")
+
+func (c *Client) do(ctx context.Context, method, path string) (
+	response *http.Response, err error) {
+	request, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	response, err = c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		b, _ := io.ReadAll(response.Body)
+		_ = response.Body.Close()
+		return nil, fmt.Errorf("%w: %s %s: %d %s: %s", ErrHTTPStatusNotOK,
+			method, path, response.StatusCode, http.StatusText(response.StatusCode),
+			strings.TrimSpace(string(b)))
+	}
+
+	return response, nil
+}
@@ -0,0 +1,91 @@
+package docker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.RequestURI())
+			switch r.URL.Path {
+			case "/containers/json":
+				_, _ = w.Write([]byte(`[{"Id":"0123456789abcdef","Names":["/app"]}]`))
+			case "/containers/gluetun/json":
+				_, _ = w.Write([]byte(`{"Id":"fedcba9876543210","Name":"/gluetun",` +
+					`"Config":{"Labels":{"gluetun.input_ports":"8000"}},` +
+					`"HostConfig":{"NetworkMode":"bridge"}}`))
+			case "/containers/0123456789abcdef/restart",
+				"/containers/0123456789abcdef/kill":
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				http.Error(w, "no such container", http.StatusNotFound)
+			}
+		}))
+	t.Cleanup(server.Close)
+
+	client, err := New(server.URL + "/")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	containers, err := client.ListContainers(ctx, "gluetun.dependent=true")
+	require.NoError(t, err)
+	expectedContainers := []Container{{ID: "0123456789abcdef", Names: []string{"/app"}}}
+	assert.Equal(t, expectedContainers, containers)
+	assert.Equal(t, "app", containers[0].Name())
+
+	_, err = client.ListContainers(ctx, "")
+	require.NoError(t, err)
+
+	container, err := client.Inspect(ctx, "gluetun")
+	require.NoError(t, err)
+	expectedContainer := Container{
+		ID:         "fedcba9876543210",
+		Names:      []string{"/gluetun"},
+		Labels:     map[string]string{"gluetun.input_ports": "8000"},
+		HostConfig: HostConfig{NetworkMode: "bridge"},
+	}
+	assert.Equal(t, expectedContainer, container)
+
+	err = client.Restart(ctx, "0123456789abcdef")
+	require.NoError(t, err)
+
+	err = client.Signal(ctx, "0123456789abcdef", "SIGHUP")
+	require.NoError(t, err)
+
+	err = client.Restart(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrHTTPStatusNotOK)
+	assert.EqualError(t, err, "HTTP status code is not OK: "+
+		"POST /containers/unknown/restart: 404 Not Found: no such container")
+
+	expectedRequests := []string{
+		"GET /containers/json?filters=%7B%22label%22%3A%5B%22gluetun.dependent%3Dtrue%22%5D%7D",
+		"GET /containers/json",
+		"GET /containers/gluetun/json",
+		"POST /containers/0123456789abcdef/restart",
+		"POST /containers/0123456789abcdef/kill?signal=SIGHUP",
+		"POST /containers/unknown/restart",
+	}
+	assert.Equal(t, expectedRequests, requests)
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+
+	_, err := New("ftp://host")
+	assert.ErrorIs(t, err, ErrEndpointSchemeNotSupported)
+
+	client, err := New("unix:///var/run/docker.sock")
+	require.NoError(t, err)
+	assert.Equal(t, "http://docker", client.baseURL)
+}
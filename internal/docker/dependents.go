@@ -0,0 +1,88 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// Dependents restarts or signals the containers depending on the
+// gluetun network stack when the VPN tunnel is re-established.
+type Dependents struct {
+	settings settings.DockerDependents
+	client   *Client
+	logger   Logger
+
+	mutex       sync.Mutex
+	tunnelWasUp bool
+}
+
+// NewDependents creates a Dependents object. The client is only
+// created if the settings are enabled.
+func NewDependents(settings settings.DockerDependents,
+	logger Logger) (dependents *Dependents, err error) {
+	dependents = &Dependents{
+		settings: settings,
+		logger:   logger,
+	}
+
+	if !*settings.Enabled {
+		return dependents, nil
+	}
+
+	dependents.client, err = New(settings.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("creating Docker client: %w", err)
+	}
+
+	return dependents, nil
+}
+
+// OnTunnelUp restarts or signals the dependent containers if the
+// tunnel was already up before, since containers started after
+// gluetun do not need any action on the first tunnel up.
+func (d *Dependents) OnTunnelUp(ctx context.Context) {
+	if !*d.settings.Enabled {
+		return
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if !d.tunnelWasUp {
+		d.tunnelWasUp = true
+		return
+	}
+
+	err := d.actOnDependents(ctx)
+	if err != nil {
+		d.logger.Error(err.Error())
+	}
+}
+
+func (d *Dependents) actOnDependents(ctx context.Context) (err error) {
+	containers, err := d.client.ListContainers(ctx, d.settings.Label)
+	if err != nil {
+		return fmt.Errorf("listing dependent containers: %w", err)
+	}
+
+	for _, container := range containers {
+		name := container.Name()
+		switch d.settings.Action {
+		case "sighup":
+			d.logger.Info("sending SIGHUP to dependent container " + name)
+			err = d.client.Signal(ctx, container.ID, "SIGHUP")
+		default:
+			d.logger.Info("restarting dependent container " + name)
+			err = d.client.Restart(ctx, container.ID)
+		}
+		if err != nil {
+			d.logger.Error(fmt.Sprintf("%s dependent container %s: %s",
+				d.settings.Action, name, err))
+		}
+	}
+
+	return nil
+}
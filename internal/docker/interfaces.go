@@ -0,0 +1,23 @@
+package docker
+
+import (
+	"context"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+type Firewall interface {
+	SetAllowedPort(ctx context.Context, port uint16, intf string) (err error)
+	RemoveAllowedPort(ctx context.Context, port uint16) (err error)
+	SetOutboundSubnets(ctx context.Context, subnets []net.IPNet) (err error)
+}
+
+type Routing interface {
+	SetOutboundRoutes(outboundSubnets []net.IPNet) error
+}
+
+type DNSLoop interface {
+	GetSettings() (settings settings.DNS)
+	SetSettings(ctx context.Context, settings settings.DNS) (outcome string)
+}
@@ -0,0 +1,142 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Labels read on the containers sharing the gluetun network stack,
+// each holding a comma separated list of values.
+const (
+	// labelInputPorts is the label for ports to allow
+	// in through the default network interfaces.
+	labelInputPorts = "gluetun.input_ports"
+	// labelDNSAllowedHosts is the label for hosts to never
+	// block with the DNS over TLS block lists.
+	labelDNSAllowedHosts = "gluetun.dns_allowed_hosts"
+	// labelBypassSubnets is the label for subnets to
+	// reach outside of the VPN tunnel.
+	labelBypassSubnets = "gluetun.bypass_subnets"
+)
+
+// policy is the union of the policies set with labels
+// on the containers sharing the gluetun network stack.
+type policy struct {
+	inputPorts    []uint16
+	allowedHosts  []string
+	bypassSubnets []net.IPNet
+}
+
+// sharesNetwork returns true if the network mode given is the
+// one of a container sharing the network stack of the container
+// given, for example with `network_mode: container:gluetun`.
+func sharesNetwork(networkMode string, container Container) bool {
+	target := strings.TrimPrefix(networkMode, "container:")
+	if target == networkMode || target == "" {
+		return false
+	}
+	const shortIDLength = 12
+	return target == container.Name() ||
+		(len(target) >= shortIDLength && strings.HasPrefix(container.ID, target))
+}
+
+// makePolicy returns the policy set with labels on the gluetun
+// container and on the containers sharing its network stack.
+// Label values not valid are logged and ignored.
+func makePolicy(containers []Container, self Container,
+	logger Logger) (p policy) {
+	ports := make(map[uint16]struct{})
+	hosts := make(map[string]struct{})
+	subnets := make(map[string]net.IPNet)
+
+	for _, container := range containers {
+		if container.ID != self.ID &&
+			!sharesNetwork(container.HostConfig.NetworkMode, self) {
+			continue
+		}
+
+		for key, value := range container.Labels {
+			var err error
+			switch key {
+			case labelInputPorts:
+				err = parsePorts(value, ports)
+			case labelDNSAllowedHosts:
+				parseHosts(value, hosts)
+			case labelBypassSubnets:
+				err = parseSubnets(value, subnets)
+			}
+			if err != nil {
+				logger.Error(fmt.Sprintf("container %s: label %s: %s",
+					container.Name(), key, err))
+			}
+		}
+	}
+
+	for port := range ports {
+		p.inputPorts = append(p.inputPorts, port)
+	}
+	sort.Slice(p.inputPorts, func(i, j int) bool {
+		return p.inputPorts[i] < p.inputPorts[j]
+	})
+
+	for host := range hosts {
+		p.allowedHosts = append(p.allowedHosts, host)
+	}
+	sort.Strings(p.allowedHosts)
+
+	subnetStrings := make([]string, 0, len(subnets))
+	for subnetString := range subnets {
+		subnetStrings = append(subnetStrings, subnetString)
+	}
+	sort.Strings(subnetStrings)
+	for _, subnetString := range subnetStrings {
+		p.bypassSubnets = append(p.bypassSubnets, subnets[subnetString])
+	}
+
+	return p
+}
+
+func splitLabelValue(value string) (fields []string) {
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+var ErrPortNotValid = errors.New("port is not valid")
+
+func parsePorts(value string, ports map[uint16]struct{}) (err error) {
+	for _, field := range splitLabelValue(value) {
+		const base, bitSize = 10, 16
+		port, err := strconv.ParseUint(field, base, bitSize)
+		if err != nil || port == 0 {
+			return fmt.Errorf("%w: %s", ErrPortNotValid, field)
+		}
+		ports[uint16(port)] = struct{}{}
+	}
+	return nil
+}
+
+func parseHosts(value string, hosts map[string]struct{}) {
+	for _, field := range splitLabelValue(value) {
+		hosts[strings.ToLower(field)] = struct{}{}
+	}
+}
+
+func parseSubnets(value string, subnets map[string]net.IPNet) (err error) {
+	for _, field := range splitLabelValue(value) {
+		_, subnet, err := net.ParseCIDR(field)
+		if err != nil {
+			return err
+		}
+		subnets[subnet.String()] = *subnet
+	}
+	return nil
+}
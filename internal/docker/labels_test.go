@@ -0,0 +1,98 @@
+package docker
+
+import (
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sharesNetwork(t *testing.T) {
+	t.Parallel()
+
+	self := Container{
+		ID:    "0123456789abcdef0123456789abcdef",
+		Names: []string{"/gluetun"},
+	}
+
+	testCases := map[string]struct {
+		networkMode string
+		shares      bool
+	}{
+		"bridge":          {networkMode: "bridge"},
+		"empty_container": {networkMode: "container:"},
+		"by_name":         {networkMode: "container:gluetun", shares: true},
+		"by_id":           {networkMode: "container:" + self.ID, shares: true},
+		"by_short_id":     {networkMode: "container:0123456789ab", shares: true},
+		"id_too_short":    {networkMode: "container:0123"},
+		"other_container": {networkMode: "container:other"},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			shares := sharesNetwork(testCase.networkMode, self)
+
+			assert.Equal(t, testCase.shares, shares)
+		})
+	}
+}
+
+func Test_makePolicy(t *testing.T) {
+	t.Parallel()
+
+	self := Container{
+		ID:     "0123456789abcdef",
+		Names:  []string{"/gluetun"},
+		Labels: map[string]string{labelInputPorts: "8000"},
+	}
+	containers := []Container{
+		self,
+		{
+			ID:    "1",
+			Names: []string{"/qbittorrent"},
+			Labels: map[string]string{
+				labelInputPorts:      "8080, 8000",
+				labelDNSAllowedHosts: "Tracker.example.com",
+				labelBypassSubnets:   "192.168.1.0/24",
+				"other":              "value",
+			},
+			HostConfig: HostConfig{NetworkMode: "container:gluetun"},
+		},
+		{
+			ID:    "2",
+			Names: []string{"/broken"},
+			Labels: map[string]string{
+				labelInputPorts:    "70000",
+				labelBypassSubnets: "10.0.0.0/8,not a subnet",
+			},
+			HostConfig: HostConfig{NetworkMode: "container:0123456789abcdef"},
+		},
+		{
+			ID:         "3",
+			Names:      []string{"/unrelated"},
+			Labels:     map[string]string{labelInputPorts: "9000"},
+			HostConfig: HostConfig{NetworkMode: "bridge"},
+		},
+	}
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	logger.EXPECT().Error("container broken: label gluetun.input_ports: port is not valid: 70000")
+	logger.EXPECT().Error("container broken: label gluetun.bypass_subnets: " +
+		"invalid CIDR address: not a subnet")
+
+	p := makePolicy(containers, self, logger)
+
+	expected := policy{
+		inputPorts:   []uint16{8000, 8080},
+		allowedHosts: []string{"tracker.example.com"},
+		bypassSubnets: []net.IPNet{
+			{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)},
+			{IP: net.IP{192, 168, 1, 0}, Mask: net.CIDRMask(24, 32)},
+		},
+	}
+	assert.Equal(t, expected, p)
+}
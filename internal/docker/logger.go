@@ -0,0 +1,6 @@
+package docker
+
+type Logger interface {
+	Info(s string)
+	Error(s string)
+}
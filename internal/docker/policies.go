@@ -0,0 +1,264 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// Policies periodically lists the containers sharing the gluetun
+// network stack, and applies the policies set with their labels,
+// such as input ports to allow or subnets to reach outside the VPN.
+type Policies struct {
+	settings settings.DockerLabels
+	client   *Client
+	firewall Firewall
+	routing  Routing
+	dns      DNSLoop
+	logger   Logger
+
+	// inputInterfaces are the interfaces input ports are allowed on.
+	inputInterfaces []string
+	// baseInputPorts are the input ports allowed by the settings,
+	// which are never removed.
+	baseInputPorts []uint16
+	// baseOutboundSubnets are the outbound subnets from the settings,
+	// to which the bypass subnets set with labels are added.
+	baseOutboundSubnets []net.IPNet
+
+	// self is the gluetun container, found on the first update.
+	self    *Container
+	applied policy
+	// addedHosts are the DNS allowed hosts added by the labels,
+	// excluding the ones already allowed otherwise.
+	addedHosts []string
+}
+
+// NewPolicies creates a Policies object. The client is only
+// created if the settings are enabled.
+func NewPolicies(settings settings.DockerLabels, firewall Firewall,
+	routing Routing, dns DNSLoop, inputInterfaces []string,
+	baseInputPorts []uint16, baseOutboundSubnets []net.IPNet,
+	logger Logger) (policies *Policies, err error) {
+	policies = &Policies{
+		settings:            settings,
+		firewall:            firewall,
+		routing:             routing,
+		dns:                 dns,
+		logger:              logger,
+		inputInterfaces:     inputInterfaces,
+		baseInputPorts:      baseInputPorts,
+		baseOutboundSubnets: baseOutboundSubnets,
+	}
+
+	if !*settings.Enabled {
+		return policies, nil
+	}
+
+	policies.client, err = New(settings.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("creating Docker client: %w", err)
+	}
+
+	return policies, nil
+}
+
+// Run updates the policies applied every period, until
+// the context is canceled. It returns immediately if the
+// settings are not enabled.
+func (p *Policies) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	if !*p.settings.Enabled {
+		return
+	}
+
+	ticker := time.NewTicker(*p.settings.Period)
+	defer ticker.Stop()
+
+	for {
+		err := p.update(ctx)
+		if err != nil && ctx.Err() == nil {
+			p.logger.Error(err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Policies) update(ctx context.Context) (err error) {
+	if p.self == nil {
+		self, err := p.findSelf(ctx)
+		if err != nil {
+			return fmt.Errorf("finding gluetun container: %w", err)
+		}
+		p.self = &self
+	}
+
+	containers, err := p.client.ListContainers(ctx, "")
+	if err != nil {
+		return fmt.Errorf("listing containers: %w", err)
+	}
+
+	return p.apply(ctx, makePolicy(containers, *p.self, p.logger))
+}
+
+func (p *Policies) findSelf(ctx context.Context) (self Container, err error) {
+	nameOrID := *p.settings.Container
+	if nameOrID == "" {
+		nameOrID, err = os.Hostname()
+		if err != nil {
+			return self, fmt.Errorf("getting hostname: %w", err)
+		}
+	}
+	return p.client.Inspect(ctx, nameOrID)
+}
+
+// apply applies the policy given, and only records each part of
+// it as applied once it succeeds, so it is retried on the next update.
+func (p *Policies) apply(ctx context.Context, newPolicy policy) (err error) {
+	err = p.applyInputPorts(ctx, newPolicy.inputPorts)
+	if err != nil {
+		return fmt.Errorf("applying input ports: %w", err)
+	}
+
+	err = p.applyBypassSubnets(ctx, newPolicy.bypassSubnets)
+	if err != nil {
+		return fmt.Errorf("applying bypass subnets: %w", err)
+	}
+
+	p.applyAllowedHosts(ctx, newPolicy.allowedHosts)
+
+	return nil
+}
+
+func (p *Policies) applyInputPorts(ctx context.Context, ports []uint16) (err error) {
+	isBasePort := make(map[uint16]struct{}, len(p.baseInputPorts))
+	for _, port := range p.baseInputPorts {
+		isBasePort[port] = struct{}{}
+	}
+
+	newPorts := make(map[uint16]struct{}, len(ports))
+	for _, port := range ports {
+		newPorts[port] = struct{}{}
+	}
+
+	for _, port := range p.applied.inputPorts {
+		_, isBase := isBasePort[port]
+		if _, keep := newPorts[port]; keep || isBase {
+			continue
+		}
+		err = p.firewall.RemoveAllowedPort(ctx, port)
+		if err != nil {
+			return err
+		}
+	}
+	p.applied.inputPorts = nil
+
+	for _, port := range ports {
+		for _, intf := range p.inputInterfaces {
+			err = p.firewall.SetAllowedPort(ctx, port, intf)
+			if err != nil {
+				return err
+			}
+		}
+		p.applied.inputPorts = append(p.applied.inputPorts, port)
+	}
+
+	return nil
+}
+
+func (p *Policies) applyBypassSubnets(ctx context.Context, subnets []net.IPNet) (err error) {
+	if subnetsEqual(p.applied.bypassSubnets, subnets) {
+		return nil
+	}
+
+	outboundSubnets := make([]net.IPNet, 0, len(p.baseOutboundSubnets)+len(subnets))
+	outboundSubnets = append(outboundSubnets, p.baseOutboundSubnets...)
+	outboundSubnets = append(outboundSubnets, subnets...)
+
+	err = p.firewall.SetOutboundSubnets(ctx, outboundSubnets)
+	if err != nil {
+		return err
+	}
+
+	err = p.routing.SetOutboundRoutes(outboundSubnets)
+	if err != nil {
+		return err
+	}
+
+	p.applied.bypassSubnets = subnets
+	return nil
+}
+
+// applyAllowedHosts replaces the DNS allowed hosts previously set
+// with labels by the hosts given, keeping any other allowed host
+// such as the ones set at runtime with the control server.
+func (p *Policies) applyAllowedHosts(ctx context.Context, hosts []string) {
+	if stringsEqual(p.applied.allowedHosts, hosts) {
+		return
+	}
+
+	previousHosts := make(map[string]struct{}, len(p.addedHosts))
+	for _, host := range p.addedHosts {
+		previousHosts[host] = struct{}{}
+	}
+
+	dnsSettings := p.dns.GetSettings()
+	allowedHosts := make([]string, 0, len(dnsSettings.DoT.Blacklist.AllowedHosts)+len(hosts))
+	otherHosts := make(map[string]struct{}, len(dnsSettings.DoT.Blacklist.AllowedHosts))
+	for _, host := range dnsSettings.DoT.Blacklist.AllowedHosts {
+		if _, ok := previousHosts[host]; !ok {
+			allowedHosts = append(allowedHosts, host)
+			otherHosts[host] = struct{}{}
+		}
+	}
+
+	var addedHosts []string
+	for _, host := range hosts {
+		if _, ok := otherHosts[host]; !ok {
+			addedHosts = append(addedHosts, host)
+		}
+	}
+	allowedHosts = append(allowedHosts, addedHosts...)
+	dnsSettings.DoT.Blacklist.AllowedHosts = allowedHosts
+
+	p.logger.Info("DNS allowed hosts from labels: " + strings.Join(hosts, ", "))
+	outcome := p.dns.SetSettings(ctx, dnsSettings)
+	p.logger.Info("DNS settings: " + outcome)
+	p.applied.allowedHosts = hosts
+	p.addedHosts = addedHosts
+}
+
+func subnetsEqual(a, b []net.IPNet) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].String() != b[i].String() {
+			return false
+		}
+	}
+	return true
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package docker
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFirewall struct {
+	allowedPorts    map[uint16][]string
+	outboundSubnets []net.IPNet
+}
+
+func (f *fakeFirewall) SetAllowedPort(_ context.Context, port uint16, intf string) error {
+	for _, existing := range f.allowedPorts[port] {
+		if existing == intf {
+			return nil
+		}
+	}
+	f.allowedPorts[port] = append(f.allowedPorts[port], intf)
+	return nil
+}
+
+func (f *fakeFirewall) RemoveAllowedPort(_ context.Context, port uint16) error {
+	delete(f.allowedPorts, port)
+	return nil
+}
+
+func (f *fakeFirewall) SetOutboundSubnets(_ context.Context, subnets []net.IPNet) error {
+	f.outboundSubnets = subnets
+	return nil
+}
+
+type fakeRouting struct {
+	outboundSubnets []net.IPNet
+}
+
+func (f *fakeRouting) SetOutboundRoutes(subnets []net.IPNet) error {
+	f.outboundSubnets = subnets
+	return nil
+}
+
+type fakeDNSLoop struct {
+	settings settings.DNS
+	sets     int
+}
+
+func (f *fakeDNSLoop) GetSettings() settings.DNS { return f.settings }
+
+func (f *fakeDNSLoop) SetSettings(_ context.Context, settings settings.DNS) string {
+	f.settings = settings
+	f.sets++
+	return "restarted"
+}
+
+func Test_Policies_apply(t *testing.T) {
+	t.Parallel()
+
+	settingsSubnet := net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)}
+	labelSubnet := net.IPNet{IP: net.IP{192, 168, 1, 0}, Mask: net.CIDRMask(24, 32)}
+
+	firewall := &fakeFirewall{allowedPorts: map[uint16][]string{8000: {"eth0"}}}
+	routing := &fakeRouting{}
+	dns := &fakeDNSLoop{}
+	dns.settings.DoT.Blacklist.AllowedHosts = []string{"settings.example.com"}
+
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	gomock.InOrder(
+		logger.EXPECT().Info("DNS allowed hosts from labels: label.example.com, settings.example.com"),
+		logger.EXPECT().Info("DNS settings: restarted"),
+		logger.EXPECT().Info("DNS allowed hosts from labels: "),
+		logger.EXPECT().Info("DNS settings: restarted"),
+	)
+	policies := &Policies{
+		firewall:            firewall,
+		routing:             routing,
+		dns:                 dns,
+		logger:              logger,
+		inputInterfaces:     []string{"eth0"},
+		baseInputPorts:      []uint16{8000},
+		baseOutboundSubnets: []net.IPNet{settingsSubnet},
+	}
+	ctx := context.Background()
+
+	err := policies.apply(ctx, policy{
+		inputPorts:    []uint16{8000, 8080},
+		allowedHosts:  []string{"label.example.com", "settings.example.com"},
+		bypassSubnets: []net.IPNet{labelSubnet},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[uint16][]string{8000: {"eth0"}, 8080: {"eth0"}}, firewall.allowedPorts)
+	assert.Equal(t, []net.IPNet{settingsSubnet, labelSubnet}, firewall.outboundSubnets)
+	assert.Equal(t, []net.IPNet{settingsSubnet, labelSubnet}, routing.outboundSubnets)
+	assert.Equal(t, []string{"settings.example.com", "label.example.com"},
+		dns.settings.DoT.Blacklist.AllowedHosts)
+	assert.Equal(t, 1, dns.sets)
+
+	// Labels removed
+	err = policies.apply(ctx, policy{})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[uint16][]string{8000: {"eth0"}}, firewall.allowedPorts)
+	assert.Equal(t, []net.IPNet{settingsSubnet}, firewall.outboundSubnets)
+	assert.Equal(t, []net.IPNet{settingsSubnet}, routing.outboundSubnets)
+	assert.Equal(t, []string{"settings.example.com"},
+		dns.settings.DoT.Blacklist.AllowedHosts)
+	assert.Equal(t, 2, dns.sets)
+
+	// No change
+	err = policies.apply(ctx, policy{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, dns.sets)
+}
@@ -0,0 +1,167 @@
+// Package droplog reads the packets the firewall logs to an NFLOG group
+// before dropping them, and reports them as log entries and counters.
+package droplog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"golang.org/x/sys/unix"
+)
+
+// NFLOGGroup is the NFLOG group the firewall should
+// log packets to before dropping them.
+const NFLOGGroup uint16 = 100
+
+// Monitor receives the packets dropped by the firewall, logs them
+// and counts them.
+type Monitor struct {
+	enabled bool
+	logger  Logger
+	// logLimit is the maximum number of dropped packets logged
+	// per second, packets above it are only counted.
+	logLimit uint
+	// maxDestinations is the maximum number of destinations
+	// counted separately, to bound the memory used.
+	maxDestinations int
+
+	statsMutex       sync.Mutex
+	stats            Stats
+	logWindowStart   time.Time
+	logWindowLogged  uint
+	logWindowSkipped uint
+}
+
+// Stats contains the counters of packets dropped by the firewall.
+type Stats struct {
+	Enabled bool   `json:"enabled"`
+	Total   uint64 `json:"total"`
+	// Chains maps the firewall chain, such as input or output,
+	// to the number of packets dropped in it.
+	Chains map[string]uint64 `json:"chains"`
+	// Destinations maps destinations, such as "tcp 1.2.3.4:443",
+	// to the number of packets dropped going to them.
+	Destinations map[string]uint64 `json:"destinations"`
+}
+
+// New creates a dropped packets monitor using the firewall settings given.
+func New(settings settings.Firewall, logger Logger) *Monitor {
+	const logLimit, maxDestinations = 10, 256
+	return &Monitor{
+		enabled:         *settings.LogDropped,
+		logger:          logger,
+		logLimit:        logLimit,
+		maxDestinations: maxDestinations,
+		stats: Stats{
+			Enabled:      *settings.LogDropped,
+			Chains:       make(map[string]uint64),
+			Destinations: make(map[string]uint64),
+		},
+	}
+}
+
+// Run receives the dropped packets until the context is canceled.
+// It returns immediately if dropped packets logging is disabled.
+func (m *Monitor) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	if !m.enabled {
+		return
+	}
+
+	conn, err := listen(NFLOGGroup)
+	if err != nil {
+		m.logger.Error("listening for dropped packets: " + err.Error())
+		return
+	}
+
+	receiveDone := make(chan struct{})
+	go func() {
+		defer close(receiveDone)
+		m.receive(ctx, conn)
+	}()
+
+	select {
+	case <-ctx.Done():
+	case <-receiveDone:
+	}
+	_ = conn.Close()
+	<-receiveDone
+}
+
+func (m *Monitor) receive(ctx context.Context, conn packetConn) {
+	for {
+		messages, err := conn.Receive()
+		switch {
+		case ctx.Err() != nil:
+			return
+		case errors.Is(err, unix.ENOBUFS):
+			m.logger.Warn("some dropped packets were not received: receive buffer is full")
+			continue
+		case err != nil:
+			m.logger.Error("receiving dropped packets: " + err.Error())
+			return
+		}
+
+		for _, message := range messages {
+			packet, err := parseMessage(message)
+			if err != nil {
+				m.logger.Debug("parsing dropped packet message: " + err.Error())
+				continue
+			}
+			m.record(packet, time.Now())
+		}
+	}
+}
+
+// record counts the packet given, and logs it unless more than
+// logLimit packets were already logged in the last second.
+func (m *Monitor) record(packet packet, now time.Time) {
+	m.statsMutex.Lock()
+	defer m.statsMutex.Unlock()
+
+	m.stats.Total++
+	m.stats.Chains[packet.chain]++
+	destination := packet.destinationKey()
+	_, counted := m.stats.Destinations[destination]
+	if counted || len(m.stats.Destinations) < m.maxDestinations {
+		m.stats.Destinations[destination]++
+	}
+
+	if now.Sub(m.logWindowStart) >= time.Second {
+		if m.logWindowSkipped > 0 {
+			m.logger.Info(fmt.Sprintf("%d other dropped packets were not logged", m.logWindowSkipped))
+		}
+		m.logWindowStart = now
+		m.logWindowLogged = 0
+		m.logWindowSkipped = 0
+	}
+
+	if m.logWindowLogged == m.logLimit {
+		m.logWindowSkipped++
+		return
+	}
+	m.logWindowLogged++
+	m.logger.Info("dropped packet: " + packet.String())
+}
+
+// GetStats returns a copy of the dropped packets counters.
+func (m *Monitor) GetStats() (stats Stats) {
+	m.statsMutex.Lock()
+	defer m.statsMutex.Unlock()
+
+	stats = m.stats
+	stats.Chains = make(map[string]uint64, len(m.stats.Chains))
+	for chain, count := range m.stats.Chains {
+		stats.Chains[chain] = count
+	}
+	stats.Destinations = make(map[string]uint64, len(m.stats.Destinations))
+	for destination, count := range m.stats.Destinations {
+		stats.Destinations[destination] = count
+	}
+	return stats
+}
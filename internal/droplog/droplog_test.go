@@ -0,0 +1,93 @@
+package droplog
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mdlayher/netlink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func Test_parseMessage(t *testing.T) {
+	t.Parallel()
+
+	payload := make([]byte, 24) //nolint:gomnd
+	payload[0] = 0x45           // IPv4 with a 20 bytes header
+	payload[9] = 6              // TCP
+	copy(payload[12:16], net.IPv4(10, 0, 0, 2).To4())
+	copy(payload[16:20], net.IPv4(1, 2, 3, 4).To4())
+	binary.BigEndian.PutUint16(payload[20:22], 51234)
+	binary.BigEndian.PutUint16(payload[22:24], 443)
+
+	outInterface := make([]byte, 4) //nolint:gomnd
+	binary.BigEndian.PutUint32(outInterface, 1)
+	attributes, err := netlink.MarshalAttributes([]netlink.Attribute{
+		{Type: nfulaPrefix, Data: []byte("output\x00")},
+		{Type: nfulaIfindexOutdev, Data: outInterface},
+		{Type: nfulaPayload, Data: payload},
+	})
+	require.NoError(t, err)
+
+	message := netlink.Message{
+		Header: netlink.Header{
+			Type: netlink.HeaderType(unix.NFNL_SUBSYS_ULOG<<8 | nfulnlMsgPacket),
+		},
+		Data: append(makeNfgenmsg(NFLOGGroup), attributes...),
+	}
+
+	parsed, err := parseMessage(message)
+	require.NoError(t, err)
+	assert.Equal(t, "output", parsed.chain)
+	assert.Equal(t, uint32(1), parsed.outInterfaceIndex)
+	assert.Equal(t, "tcp", parsed.protocol)
+	assert.Equal(t, "10.0.0.2", parsed.source.String())
+	assert.Equal(t, uint16(443), parsed.destinationPort)
+	assert.Equal(t, "tcp 1.2.3.4:443", parsed.destinationKey())
+
+	message.Header.Type = netlink.HeaderType(unix.NFNL_SUBSYS_ULOG<<8 | nfulnlMsgConfig)
+	_, err = parseMessage(message)
+	assert.ErrorIs(t, err, ErrMessageTypeUnexpected)
+}
+
+func Test_Monitor_record(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	monitor := &Monitor{
+		logger:          logger,
+		logLimit:        2,
+		maxDestinations: 1,
+		stats: Stats{
+			Chains:       map[string]uint64{},
+			Destinations: map[string]uint64{},
+		},
+	}
+
+	now := time.Unix(1000, 0)
+	toVPN := packet{chain: "output", protocol: "udp", destination: net.IPv4(1, 2, 3, 4), destinationPort: 53}
+	toLAN := packet{chain: "input", protocol: "icmp", destination: net.IPv4(192, 168, 1, 1)}
+	gomock.InOrder(
+		logger.EXPECT().Info("dropped packet: "+toVPN.String()).Times(2),
+		// The skipped packets are reported once the second elapsed.
+		logger.EXPECT().Info("1 other dropped packets were not logged"),
+		logger.EXPECT().Info("dropped packet: "+toLAN.String()),
+	)
+
+	monitor.record(toVPN, now)
+	monitor.record(toVPN, now)
+	monitor.record(toLAN, now)
+	monitor.record(toLAN, now.Add(time.Second))
+
+	stats := monitor.GetStats()
+	assert.Equal(t, Stats{
+		Total:        4,
+		Chains:       map[string]uint64{"output": 2, "input": 2},
+		Destinations: map[string]uint64{"udp 1.2.3.4:53": 2},
+	}, stats)
+}
@@ -0,0 +1,15 @@
+package droplog
+
+import "github.com/mdlayher/netlink"
+
+type Logger interface {
+	Debug(s string)
+	Info(s string)
+	Warn(s string)
+	Error(s string)
+}
+
+type packetConn interface {
+	Receive() (messages []netlink.Message, err error)
+	Close() (err error)
+}
@@ -0,0 +1,3 @@
+package droplog
+
+//go:generate mockgen -destination=mocks_test.go -package=$GOPACKAGE . Logger
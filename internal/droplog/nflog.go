@@ -0,0 +1,126 @@
+package droplog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// Constants from linux/netfilter/nfnetlink_log.h
+// not defined in the unix package.
+const (
+	nfulnlMsgPacket     = 0
+	nfulnlMsgConfig     = 1
+	nfulaCfgCmd         = 1
+	nfulaCfgMode        = 2
+	nfulnlCfgCmdBind    = 1
+	nfulnlCopyPacket    = 2
+	nfulaIfindexIndev   = 4
+	nfulaIfindexOutdev  = 5
+	nfulaPayload        = 9
+	nfulaPrefix         = 10
+	nfgenmsgLength      = 4
+	packetCopyRange     = 128
+	nflogReadBufferSize = 1 << 20
+)
+
+// listen binds a netlink socket to the NFLOG group given, receiving
+// the first bytes of each packet logged.
+func listen(group uint16) (conn *netlink.Conn, err error) {
+	conn, err = netlink.Dial(unix.NETLINK_NETFILTER, nil)
+	if err != nil {
+		return nil, fmt.Errorf("dialing netfilter netlink: %w", err)
+	}
+
+	err = conn.SetReadBuffer(nflogReadBufferSize)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("setting read buffer size: %w", err)
+	}
+
+	mode := make([]byte, 6) //nolint:gomnd
+	binary.BigEndian.PutUint32(mode, packetCopyRange)
+	mode[4] = nfulnlCopyPacket
+	configAttributes := []netlink.Attribute{
+		{Type: nfulaCfgCmd, Data: []byte{nfulnlCfgCmdBind}},
+		{Type: nfulaCfgMode, Data: mode},
+	}
+	for _, attribute := range configAttributes {
+		data, err := netlink.MarshalAttributes([]netlink.Attribute{attribute})
+		if err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("encoding NFLOG configuration: %w", err)
+		}
+
+		message := netlink.Message{
+			Header: netlink.Header{
+				Type:  netlink.HeaderType(unix.NFNL_SUBSYS_ULOG<<8 | nfulnlMsgConfig),
+				Flags: netlink.Request | netlink.Acknowledge,
+			},
+			Data: append(makeNfgenmsg(group), data...),
+		}
+		_, err = conn.Execute(message)
+		if err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("configuring NFLOG group %d: %w", group, err)
+		}
+	}
+
+	return conn, nil
+}
+
+func makeNfgenmsg(group uint16) (header []byte) {
+	header = []byte{unix.AF_UNSPEC, unix.NFNETLINK_V0, 0, 0}
+	binary.BigEndian.PutUint16(header[2:], group)
+	return header
+}
+
+var (
+	ErrMessageTypeUnexpected = errors.New("message type is unexpected")
+	ErrMessageTooShort       = errors.New("message is too short")
+)
+
+// parseMessage parses an NFLOG packet message.
+func parseMessage(message netlink.Message) (parsed packet, err error) {
+	expectedType := netlink.HeaderType(unix.NFNL_SUBSYS_ULOG<<8 | nfulnlMsgPacket)
+	if message.Header.Type != expectedType {
+		return parsed, fmt.Errorf("%w: %d", ErrMessageTypeUnexpected, message.Header.Type)
+	}
+
+	if len(message.Data) < nfgenmsgLength {
+		return parsed, fmt.Errorf("%w: %d bytes", ErrMessageTooShort, len(message.Data))
+	}
+
+	decoder, err := netlink.NewAttributeDecoder(message.Data[nfgenmsgLength:])
+	if err != nil {
+		return parsed, fmt.Errorf("decoding attributes: %w", err)
+	}
+	decoder.ByteOrder = binary.BigEndian
+
+	var payload []byte
+	for decoder.Next() {
+		switch decoder.Type() {
+		case nfulaPrefix:
+			parsed.chain = decoder.String()
+		case nfulaIfindexIndev:
+			parsed.inInterfaceIndex = decoder.Uint32()
+		case nfulaIfindexOutdev:
+			parsed.outInterfaceIndex = decoder.Uint32()
+		case nfulaPayload:
+			payload = decoder.Bytes()
+		}
+	}
+	if err := decoder.Err(); err != nil {
+		return parsed, fmt.Errorf("decoding attributes: %w", err)
+	}
+
+	err = parsed.parsePayload(payload)
+	if err != nil {
+		return parsed, fmt.Errorf("parsing payload: %w", err)
+	}
+
+	return parsed, nil
+}
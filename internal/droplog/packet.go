@@ -0,0 +1,120 @@
+package droplog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+type packet struct {
+	chain             string
+	inInterfaceIndex  uint32
+	outInterfaceIndex uint32
+	protocol          string
+	source            net.IP
+	destination       net.IP
+	sourcePort        uint16
+	destinationPort   uint16
+}
+
+var (
+	ErrIPVersionUnknown = errors.New("IP version is unknown")
+	ErrPayloadTooShort  = errors.New("payload is too short")
+)
+
+// parsePayload parses the IP and transport headers of the payload
+// given. IPv6 extension headers are not parsed, such that ports are
+// only set for TCP and UDP packets without extension headers.
+func (p *packet) parsePayload(payload []byte) (err error) {
+	if len(payload) == 0 {
+		return fmt.Errorf("%w: 0 byte", ErrPayloadTooShort)
+	}
+
+	var protocol byte
+	var transport []byte
+	const ipv4HeaderMinLength, ipv6HeaderLength = 20, 40
+	switch version := payload[0] >> 4; version { //nolint:gomnd
+	case 4: //nolint:gomnd
+		headerLength := int(payload[0]&0x0f) * 4 //nolint:gomnd
+		if len(payload) < ipv4HeaderMinLength || len(payload) < headerLength {
+			return fmt.Errorf("%w: %d bytes for IPv4 header", ErrPayloadTooShort, len(payload))
+		}
+		protocol = payload[9]
+		p.source = net.IP(payload[12:16])
+		p.destination = net.IP(payload[16:20])
+		transport = payload[headerLength:]
+	case 6: //nolint:gomnd
+		if len(payload) < ipv6HeaderLength {
+			return fmt.Errorf("%w: %d bytes for IPv6 header", ErrPayloadTooShort, len(payload))
+		}
+		protocol = payload[6]
+		p.source = net.IP(payload[8:24])
+		p.destination = net.IP(payload[24:40])
+		transport = payload[ipv6HeaderLength:]
+	default:
+		return fmt.Errorf("%w: %d", ErrIPVersionUnknown, version)
+	}
+
+	const icmp, tcp, udp, icmpv6 = 1, 6, 17, 58
+	switch protocol {
+	case icmp:
+		p.protocol = "icmp"
+	case icmpv6:
+		p.protocol = "icmpv6"
+	case tcp, udp:
+		p.protocol = "tcp"
+		if protocol == udp {
+			p.protocol = "udp"
+		}
+		const portsLength = 4
+		if len(transport) >= portsLength {
+			p.sourcePort = binary.BigEndian.Uint16(transport[0:2])
+			p.destinationPort = binary.BigEndian.Uint16(transport[2:4])
+		}
+	default:
+		p.protocol = strconv.Itoa(int(protocol))
+	}
+
+	return nil
+}
+
+// destinationKey returns the protocol and destination of the
+// packet, for example "tcp 1.2.3.4:443".
+func (p *packet) destinationKey() (key string) {
+	return p.protocol + " " + addressString(p.destination, p.destinationPort)
+}
+
+func (p *packet) String() string {
+	fields := make([]string, 0, 6) //nolint:gomnd
+	fields = append(fields, "chain="+p.chain)
+	if p.inInterfaceIndex != 0 {
+		fields = append(fields, "in="+interfaceName(p.inInterfaceIndex))
+	}
+	if p.outInterfaceIndex != 0 {
+		fields = append(fields, "out="+interfaceName(p.outInterfaceIndex))
+	}
+	fields = append(fields,
+		"protocol="+p.protocol,
+		"source="+addressString(p.source, p.sourcePort),
+		"destination="+addressString(p.destination, p.destinationPort),
+	)
+	return strings.Join(fields, " ")
+}
+
+func addressString(ip net.IP, port uint16) string {
+	if port == 0 {
+		return ip.String()
+	}
+	return net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
+}
+
+func interfaceName(index uint32) (name string) {
+	netInterface, err := net.InterfaceByIndex(int(index))
+	if err != nil {
+		return strconv.Itoa(int(index))
+	}
+	return netInterface.Name
+}
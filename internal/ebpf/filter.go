@@ -0,0 +1,166 @@
+// Package ebpf implements an experimental egress filter using an
+// eBPF cgroup skb program, as a complement to the iptables kill switch
+// or as the kill switch itself with the "ebpf" firewall backend.
+package ebpf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/qdm12/gluetun/internal/netlink"
+	"golang.org/x/sys/unix"
+)
+
+const cgroupPath = "/sys/fs/cgroup"
+
+// Filter drops egress packets of the processes in the gluetun cgroup,
+// unless they leave through the VPN or loopback interfaces, or their
+// destination is allowed. Packets leaving through any other interface,
+// including interfaces created after the last update, are dropped.
+// Note processes of other containers sharing the network namespace
+// of gluetun are in other cgroups, and are not filtered.
+type Filter struct {
+	cgroupFD       int
+	linkSubscriber LinkSubscriber
+	logger         Logger
+
+	// Fields below are protected by the mutex.
+	programFD    int
+	updated      bool
+	vpnInterface string
+	allowed      []net.IPNet
+	mutex        sync.Mutex
+}
+
+var ErrCgroupV2NotMounted = errors.New("cgroup v2 is not mounted")
+
+// New opens the gluetun cgroup for the filter to attach to it.
+// It returns an error if the cgroup v2 hierarchy is not available.
+func New(linkSubscriber LinkSubscriber, logger Logger) (filter *Filter, err error) {
+	var statfs unix.Statfs_t
+	err = unix.Statfs(cgroupPath, &statfs)
+	if err != nil {
+		return nil, fmt.Errorf("getting file system of %s: %w", cgroupPath, err)
+	} else if statfs.Type != unix.CGROUP2_SUPER_MAGIC {
+		return nil, fmt.Errorf("%w: at %s", ErrCgroupV2NotMounted, cgroupPath)
+	}
+
+	cgroupFD, err := unix.Open(cgroupPath, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("opening cgroup: %w", err)
+	}
+
+	return &Filter{
+		cgroupFD:       cgroupFD,
+		linkSubscriber: linkSubscriber,
+		logger:         logger,
+		programFD:      -1,
+	}, nil
+}
+
+// Update replaces the filter program with one allowing egress
+// through the VPN interface given, the loopback interface, and
+// to the allowed networks through any other interface.
+// The VPN interface does not need to exist yet, since the
+// program is rebuilt by Run once the interface is created.
+func (f *Filter) Update(vpnInterface string, allowed []net.IPNet) (err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.updated = true
+	f.vpnInterface = vpnInterface
+	f.allowed = allowed
+	return f.load()
+}
+
+// Run rebuilds the filter program each time a network interface is
+// added, changed or removed, so the interface indexes it allows stay
+// current, until the context is canceled.
+func (f *Filter) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	updates := make(chan netlink.LinkUpdate)
+	subscriptionDone := make(chan struct{})
+	err := f.linkSubscriber.LinkSubscribe(updates, subscriptionDone)
+	if err != nil {
+		f.logger.Error("subscribing to network interface changes: " + err.Error())
+		return
+	}
+	defer func() {
+		close(subscriptionDone)
+		for range updates { //nolint:revive
+			// drain updates until the channel is closed
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-updates:
+			if !ok {
+				f.logger.Error("network interface changes subscription stopped")
+				return
+			}
+			err = f.reload()
+			if err != nil {
+				f.logger.Error("reloading eBPF egress filter: " + err.Error())
+			}
+		}
+	}
+}
+
+func (f *Filter) reload() (err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if !f.updated {
+		return nil
+	}
+	return f.load()
+}
+
+// load builds and attaches the filter program, replacing the
+// current program if any. It must be called with the mutex locked.
+func (f *Filter) load() (err error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return fmt.Errorf("listing network interfaces: %w", err)
+	}
+
+	allowedIfindexes := make([]int, 0, len(interfaces))
+	for _, netInterface := range interfaces {
+		if netInterface.Flags&net.FlagLoopback != 0 ||
+			netInterface.Name == f.vpnInterface {
+			allowedIfindexes = append(allowedIfindexes, netInterface.Index)
+		}
+	}
+
+	program := buildProgram(allowedIfindexes, f.allowed)
+	programFD, err := loadProgram(program)
+	if err != nil {
+		return err
+	}
+
+	// Attach the new program before detaching the old one,
+	// so there is no time without filtering.
+	err = attachProgram(f.cgroupFD, programFD)
+	if err != nil {
+		_ = unix.Close(programFD)
+		return err
+	}
+
+	if f.programFD != -1 {
+		err = detachProgram(f.cgroupFD, f.programFD)
+		_ = unix.Close(f.programFD)
+		if err != nil {
+			f.programFD = programFD
+			return fmt.Errorf("replacing program: %w", err)
+		}
+	}
+	f.programFD = programFD
+
+	return nil
+}
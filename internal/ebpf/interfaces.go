@@ -0,0 +1,7 @@
+package ebpf
+
+import "github.com/qdm12/gluetun/internal/netlink"
+
+type LinkSubscriber interface {
+	LinkSubscribe(updates chan<- netlink.LinkUpdate, done <-chan struct{}) (err error)
+}
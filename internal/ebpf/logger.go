@@ -0,0 +1,5 @@
+package ebpf
+
+type Logger interface {
+	Error(s string)
+}
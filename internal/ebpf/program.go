@@ -0,0 +1,199 @@
+package ebpf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"unsafe"
+)
+
+// instruction is an eBPF instruction as
+// defined by struct bpf_insn in linux/bpf.h.
+type instruction struct {
+	opCode uint8
+	// registers contains the destination register in its
+	// lower 4 bits and the source register in its upper 4 bits.
+	registers uint8
+	offset    int16
+	immediate int32
+}
+
+const (
+	opLoadWord       = 0x61 // BPF_LDX | BPF_MEM | BPF_W
+	opMove64Reg      = 0xbf // BPF_ALU64 | BPF_MOV | BPF_X
+	opMove64Imm      = 0xb7 // BPF_ALU64 | BPF_MOV | BPF_K
+	opAdd64Imm       = 0x07 // BPF_ALU64 | BPF_ADD | BPF_K
+	opAnd32Imm       = 0x54 // BPF_ALU | BPF_AND | BPF_K
+	opJumpEqual32    = 0x16 // BPF_JMP32 | BPF_JEQ | BPF_K
+	opJumpNotEqual32 = 0x56 // BPF_JMP32 | BPF_JNE | BPF_K
+	opJumpNotEqual64 = 0x55 // BPF_JMP | BPF_JNE | BPF_K
+	opJump           = 0x05 // BPF_JMP | BPF_JA
+	opCall           = 0x85 // BPF_JMP | BPF_CALL
+	opExit           = 0x95 // BPF_JMP | BPF_EXIT
+)
+
+const (
+	r0  = 0
+	r1  = 1
+	r2  = 2
+	r3  = 3
+	r4  = 4
+	r6  = 6
+	r10 = 10 // read-only frame pointer
+)
+
+const helperSkbLoadBytes = 26 // BPF_FUNC_skb_load_bytes
+
+// Offsets of fields in struct __sk_buff.
+const (
+	skbProtocolOffset = 16
+	skbIfindexOffset  = 40
+)
+
+// Offsets of the destination address in the IP headers.
+const (
+	ipv4DestinationOffset = 16
+	ipv6DestinationOffset = 24
+)
+
+const (
+	verdictDrop  = 0
+	verdictAllow = 1
+)
+
+// assembler builds eBPF instructions with jumps to labels,
+// which are resolved to relative offsets by the instructions method.
+type assembler struct {
+	program []instruction
+	// labelToIndex maps a label to the index of the
+	// instruction following it.
+	labelToIndex map[string]int
+	// jumpToLabel maps the index of a jump instruction
+	// to the label it jumps to.
+	jumpToLabel map[int]string
+}
+
+func newAssembler() *assembler {
+	return &assembler{
+		labelToIndex: make(map[string]int),
+		jumpToLabel:  make(map[int]string),
+	}
+}
+
+func (a *assembler) emit(opCode uint8, dst, src uint8, offset int16, immediate int32) {
+	a.program = append(a.program, instruction{
+		opCode:    opCode,
+		registers: dst | src<<4, //nolint:gomnd
+		offset:    offset,
+		immediate: immediate,
+	})
+}
+
+func (a *assembler) jump(opCode uint8, register uint8, immediate int32, label string) {
+	a.jumpToLabel[len(a.program)] = label
+	a.emit(opCode, register, 0, 0, immediate)
+}
+
+func (a *assembler) label(label string) {
+	a.labelToIndex[label] = len(a.program)
+}
+
+func (a *assembler) instructions() []instruction {
+	for index, label := range a.jumpToLabel {
+		a.program[index].offset = int16(a.labelToIndex[label] - index - 1)
+	}
+	return a.program
+}
+
+// buildProgram builds a cgroup skb egress program allowing packets
+// leaving through one of the allowed interfaces, or whose destination
+// IP address is within one of the allowed networks, and dropping
+// all other packets.
+func buildProgram(allowedIfindexes []int, allowed []net.IPNet) []instruction {
+	a := newAssembler()
+	a.emit(opMove64Reg, r6, r1, 0, 0) // r6 = context
+
+	// Check the outgoing interface
+	a.emit(opLoadWord, r2, r6, skbIfindexOffset, 0)
+	for _, ifindex := range allowedIfindexes {
+		a.jump(opJumpEqual32, r2, int32(ifindex), "allow")
+	}
+
+	// Check the destination IP address
+	a.emit(opLoadWord, r2, r6, skbProtocolOffset, 0)
+	a.jump(opJumpEqual32, r2, nativeUint16Immediate(0x0800), "ipv4") //nolint:gomnd
+	a.jump(opJumpEqual32, r2, nativeUint16Immediate(0x86dd), "ipv6") //nolint:gomnd
+	a.jump(opJump, 0, 0, "drop")
+
+	const stackOffset = -16
+	a.label("ipv4")
+	emitLoadBytes(a, ipv4DestinationOffset, net.IPv4len, stackOffset)
+	a.emit(opLoadWord, r2, r10, stackOffset, 0)
+	for _, network := range allowed {
+		ip, mask := network.IP.To4(), network.Mask
+		if ip == nil || len(mask) != net.IPv4len {
+			continue
+		}
+		a.emit(opMove64Reg, r3, r2, 0, 0)
+		a.emit(opAnd32Imm, r3, 0, 0, nativeUint32Immediate(mask))
+		a.jump(opJumpEqual32, r3, nativeUint32Immediate(ip.Mask(mask)), "allow")
+	}
+	a.jump(opJump, 0, 0, "drop")
+
+	a.label("ipv6")
+	emitLoadBytes(a, ipv6DestinationOffset, net.IPv6len, stackOffset)
+	for i, network := range allowed {
+		if network.IP.To4() != nil || len(network.Mask) != net.IPv6len {
+			continue
+		}
+		next := fmt.Sprintf("ipv6 network %d", i)
+		ip := network.IP.Mask(network.Mask)
+		const wordLength = 4
+		for offset := 0; offset < net.IPv6len; offset += wordLength {
+			mask := network.Mask[offset : offset+wordLength]
+			if nativeUint32Immediate(mask) == 0 {
+				break // remaining words are not masked
+			}
+			a.emit(opLoadWord, r2, r10, int16(stackOffset+offset), 0)
+			a.emit(opAnd32Imm, r2, 0, 0, nativeUint32Immediate(mask))
+			a.jump(opJumpNotEqual32, r2, nativeUint32Immediate(ip[offset:offset+wordLength]), next)
+		}
+		a.jump(opJump, 0, 0, "allow")
+		a.label(next)
+	}
+
+	a.label("drop")
+	a.emit(opMove64Imm, r0, 0, 0, verdictDrop)
+	a.emit(opExit, 0, 0, 0, 0)
+	a.label("allow")
+	a.emit(opMove64Imm, r0, 0, 0, verdictAllow)
+	a.emit(opExit, 0, 0, 0, 0)
+
+	return a.instructions()
+}
+
+// emitLoadBytes emits instructions to copy length bytes of the packet
+// at the offset given to the stack, jumping to drop on failure.
+func emitLoadBytes(a *assembler, packetOffset, length int, stackOffset int16) {
+	a.emit(opMove64Reg, r1, r6, 0, 0)
+	a.emit(opMove64Imm, r2, 0, 0, int32(packetOffset))
+	a.emit(opMove64Reg, r3, r10, 0, 0)
+	a.emit(opAdd64Imm, r3, 0, 0, int32(stackOffset))
+	a.emit(opMove64Imm, r4, 0, 0, int32(length))
+	a.emit(opCall, 0, 0, 0, helperSkbLoadBytes)
+	a.jump(opJumpNotEqual64, r0, 0, "drop")
+}
+
+// nativeUint32Immediate returns the 4 bytes given as they are
+// read from memory by the eBPF program on this host.
+func nativeUint32Immediate(b []byte) int32 {
+	return *(*int32)(unsafe.Pointer(&b[0]))
+}
+
+// nativeUint16Immediate returns the 16 bits value given in network
+// byte order, as read from memory by the eBPF program on this host.
+func nativeUint16Immediate(value uint16) int32 {
+	b := make([]byte, 2) //nolint:gomnd
+	binary.BigEndian.PutUint16(b, value)
+	return int32(*(*uint16)(unsafe.Pointer(&b[0])))
+}
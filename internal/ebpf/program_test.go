@@ -0,0 +1,129 @@
+package ebpf
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// progTestRunAttr is the BPF_PROG_TEST_RUN part of union bpf_attr.
+type progTestRunAttr struct {
+	programFD      uint32
+	returnValue    uint32
+	dataSizeIn     uint32
+	dataSizeOut    uint32
+	dataIn         uint64
+	dataOut        uint64
+	repeat         uint32
+	duration       uint32
+	contextSizeIn  uint32
+	contextSizeOut uint32
+	contextIn      uint64
+	contextOut     uint64
+	flags          uint32
+	cpu            uint32
+	batchSize      uint32
+	_              uint32
+}
+
+// testRun runs the program with the Ethernet frame given as
+// if it were sent through the loopback interface.
+func testRun(t *testing.T, programFD int, frame []byte) (verdict uint32) {
+	t.Helper()
+	out := make([]byte, len(frame))
+	attr := progTestRunAttr{
+		programFD:   uint32(programFD),
+		dataSizeIn:  uint32(len(frame)),
+		dataSizeOut: uint32(len(out)),
+		dataIn:      uint64(uintptr(unsafe.Pointer(&frame[0]))),
+		dataOut:     uint64(uintptr(unsafe.Pointer(&out[0]))),
+		repeat:      1,
+	}
+	_, err := bpf(unix.BPF_PROG_TEST_RUN, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	require.NoError(t, err)
+	return attr.returnValue
+}
+
+func makeFrame(destination net.IP) (frame []byte) {
+	const ethernetHeaderLength, ipv6HeaderLength = 14, 40
+	frame = make([]byte, ethernetHeaderLength+ipv6HeaderLength)
+	ipHeader := frame[ethernetHeaderLength:]
+	if ipv4 := destination.To4(); ipv4 != nil {
+		binary.BigEndian.PutUint16(frame[12:], 0x0800)
+		ipHeader[0] = 0x45
+		copy(ipHeader[ipv4DestinationOffset:], ipv4)
+	} else {
+		binary.BigEndian.PutUint16(frame[12:], 0x86dd)
+		ipHeader[0] = 0x60
+		copy(ipHeader[ipv6DestinationOffset:], destination)
+	}
+	return frame
+}
+
+func Test_buildProgram(t *testing.T) {
+	t.Parallel()
+
+	allowed := make([]net.IPNet, 0, 3)
+	for _, cidr := range []string{"192.168.1.0/24", "fd00::/8", "2001:db8::1/128"} {
+		_, network, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		allowed = append(allowed, *network)
+	}
+
+	// Packets are run through the program as if they
+	// were sent through the loopback interface.
+	const loopbackIfindex = 1
+	blockingFD, err := loadProgram(buildProgram(nil, allowed))
+	if err != nil {
+		t.Skipf("cannot load eBPF program, running without privileges? %s", err)
+	}
+	t.Cleanup(func() { _ = unix.Close(blockingFD) })
+
+	testCases := map[string]struct {
+		destination string
+		verdict     uint32
+	}{
+		"allowed IPv4":              {destination: "192.168.1.5", verdict: verdictAllow},
+		"blocked IPv4":              {destination: "192.168.2.5", verdict: verdictDrop},
+		"allowed IPv6 network":      {destination: "fd12::1", verdict: verdictAllow},
+		"blocked IPv6":              {destination: "fe12::1", verdict: verdictDrop},
+		"allowed IPv6 address":      {destination: "2001:db8::1", verdict: verdictAllow},
+		"blocked neighbouring IPv6": {destination: "2001:db8::2", verdict: verdictDrop},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			frame := makeFrame(net.ParseIP(testCase.destination))
+			verdict := testRun(t, blockingFD, frame)
+			assert.Equal(t, testCase.verdict, verdict)
+		})
+	}
+
+	t.Run("interface allowed", func(t *testing.T) {
+		t.Parallel()
+		programFD, err := loadProgram(buildProgram([]int{loopbackIfindex}, nil))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = unix.Close(programFD) })
+
+		verdict := testRun(t, programFD, makeFrame(net.ParseIP("1.2.3.4")))
+		assert.Equal(t, uint32(verdictAllow), verdict)
+	})
+
+	t.Run("unknown interface dropped", func(t *testing.T) {
+		t.Parallel()
+		const vpnIfindex = loopbackIfindex + 1
+		programFD, err := loadProgram(buildProgram([]int{vpnIfindex}, nil))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = unix.Close(programFD) })
+
+		verdict := testRun(t, programFD, makeFrame(net.ParseIP("1.2.3.4")))
+		assert.Equal(t, uint32(verdictDrop), verdict)
+	})
+}
@@ -0,0 +1,97 @@
+package ebpf
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// progLoadAttr is the BPF_PROG_LOAD part of union bpf_attr.
+type progLoadAttr struct {
+	progType           uint32
+	instructionsCount  uint32
+	instructions       uint64
+	license            uint64
+	logLevel           uint32
+	logSize            uint32
+	logBuffer          uint64
+	kernelVersion      uint32
+	progFlags          uint32
+	progName           [unix.BPF_OBJ_NAME_LEN]byte
+	progIfindex        uint32
+	expectedAttachType uint32
+}
+
+// progAttachAttr is the BPF_PROG_ATTACH and BPF_PROG_DETACH
+// part of union bpf_attr.
+type progAttachAttr struct {
+	targetFD     uint32
+	attachBPFFD  uint32
+	attachType   uint32
+	attachFlags  uint32
+	replaceBPFFD uint32
+}
+
+func bpf(command int, attr unsafe.Pointer, size uintptr) (result uintptr, err error) {
+	result, _, errno := unix.Syscall(unix.SYS_BPF, uintptr(command), uintptr(attr), size)
+	if errno != 0 {
+		return 0, errno
+	}
+	return result, nil
+}
+
+// loadProgram loads the cgroup skb egress program given
+// and returns its file descriptor.
+func loadProgram(program []instruction) (fd int, err error) {
+	license := []byte("GPL\x00")
+	const logSize = 64 * 1024
+	log := make([]byte, logSize)
+	attr := progLoadAttr{
+		progType:           unix.BPF_PROG_TYPE_CGROUP_SKB,
+		instructionsCount:  uint32(len(program)),
+		instructions:       uint64(uintptr(unsafe.Pointer(&program[0]))),
+		license:            uint64(uintptr(unsafe.Pointer(&license[0]))),
+		logLevel:           1,
+		logSize:            logSize,
+		logBuffer:          uint64(uintptr(unsafe.Pointer(&log[0]))),
+		expectedAttachType: unix.BPF_CGROUP_INET_EGRESS,
+	}
+	copy(attr.progName[:], "gluetun_egress")
+
+	result, err := bpf(unix.BPF_PROG_LOAD, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	runtime.KeepAlive(program)
+	runtime.KeepAlive(license)
+	if err != nil {
+		return 0, fmt.Errorf("loading program: %w: %s", err, unix.ByteSliceToString(log))
+	}
+	return int(result), nil
+}
+
+func attachProgram(cgroupFD, programFD int) (err error) {
+	attr := progAttachAttr{
+		targetFD:    uint32(cgroupFD),
+		attachBPFFD: uint32(programFD),
+		attachType:  unix.BPF_CGROUP_INET_EGRESS,
+		attachFlags: unix.BPF_F_ALLOW_MULTI,
+	}
+	_, err = bpf(unix.BPF_PROG_ATTACH, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	if err != nil {
+		return fmt.Errorf("attaching program: %w", err)
+	}
+	return nil
+}
+
+func detachProgram(cgroupFD, programFD int) (err error) {
+	attr := progAttachAttr{
+		targetFD:    uint32(cgroupFD),
+		attachBPFFD: uint32(programFD),
+		attachType:  unix.BPF_CGROUP_INET_EGRESS,
+	}
+	_, err = bpf(unix.BPF_PROG_DETACH, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	if err != nil {
+		return fmt.Errorf("detaching program: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package events publishes state changes such as the tunnel going up
+// or down to subscribers, for the control server to stream them.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	TypeTunnelUp             = "tunnel_up"
+	TypeTunnelDown           = "tunnel_down"
+	TypePublicIPChanged      = "public_ip_changed"
+	TypePortForwardedChanged = "port_forwarded_changed"
+	TypeHealthChanged        = "health_changed"
+	TypeSettingsOverridden   = "settings_overridden"
+)
+
+// Event is a state change.
+type Event struct {
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// Broker publishes events to all its subscribers.
+type Broker struct {
+	timeNow     func() time.Time
+	mutex       sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		timeNow:     time.Now,
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Publish sends an event of the type and data given to all the
+// subscribers. The event is dropped for subscribers too slow to
+// receive it, so a slow client never blocks the publisher.
+func (b *Broker) Publish(eventType string, data interface{}) {
+	event := Event{
+		Type: eventType,
+		Time: b.timeNow(),
+		Data: data,
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the events published
+// from now on, and a function to call to unsubscribe.
+func (b *Broker) Subscribe() (events <-chan Event, unsubscribe func()) {
+	const bufferSize = 16
+	subscriber := make(chan Event, bufferSize)
+
+	b.mutex.Lock()
+	b.subscribers[subscriber] = struct{}{}
+	b.mutex.Unlock()
+
+	unsubscribe = func() {
+		b.mutex.Lock()
+		delete(b.subscribers, subscriber)
+		b.mutex.Unlock()
+	}
+	return subscriber, unsubscribe
+}
@@ -0,0 +1,37 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Broker(t *testing.T) {
+	t.Parallel()
+
+	broker := NewBroker()
+	broker.timeNow = func() time.Time { return time.Unix(1, 0) }
+
+	events, unsubscribe := broker.Subscribe()
+	broker.Publish(TypeTunnelDown, nil)
+	assert.Equal(t, Event{Type: TypeTunnelDown, Time: time.Unix(1, 0)}, <-events)
+
+	unsubscribe()
+	broker.Publish(TypeTunnelDown, nil)
+	assert.Empty(t, events)
+}
+
+func Test_Broker_slowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	broker := NewBroker()
+	events, unsubscribe := broker.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < 2*cap(events); i++ {
+		broker.Publish(TypeTunnelUp, nil)
+	}
+
+	assert.Len(t, events, cap(events))
+}
@@ -0,0 +1,25 @@
+package events
+
+import (
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+type VPNLooper interface {
+	GetConnectedServer() (server models.ConnectedServer, ok bool)
+}
+
+type PublicIPLoop interface {
+	GetData() (data models.PublicIP)
+}
+
+type PortForwardedGetter interface {
+	GetPortForwarded() (portForwarded uint16)
+}
+
+type ReadinessChecker interface {
+	Ready() (err error)
+}
+
+type Publisher interface {
+	Publish(eventType string, data interface{})
+}
@@ -0,0 +1,119 @@
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// Watcher periodically checks the state of the tunnel, public IP
+// address, forwarded port and health, and publishes an event for
+// each change detected.
+type Watcher struct {
+	publisher     Publisher
+	vpnLooper     VPNLooper
+	publicIP      PublicIPLoop
+	portForwarded PortForwardedGetter
+	readiness     ReadinessChecker
+	period        time.Duration
+
+	state watchedState
+}
+
+type watchedState struct {
+	// tunnelUp is true once the VPN loop ran its tunnel up
+	// actions for the connected server, which sets connectedAt.
+	tunnelUp      bool
+	connectedAt   time.Time
+	publicIP      string
+	portForwarded uint16
+	healthy       bool
+	healthError   string
+}
+
+func NewWatcher(publisher Publisher, vpnLooper VPNLooper,
+	publicIP PublicIPLoop, portForwarded PortForwardedGetter,
+	readiness ReadinessChecker) *Watcher {
+	return &Watcher{
+		publisher:     publisher,
+		vpnLooper:     vpnLooper,
+		publicIP:      publicIP,
+		portForwarded: portForwarded,
+		readiness:     readiness,
+		period:        time.Second,
+	}
+}
+
+func (w *Watcher) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(w.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+type publicIPData struct {
+	PublicIP string `json:"public_ip"`
+}
+
+type portForwardedData struct {
+	Port uint16 `json:"port"`
+}
+
+type healthData struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// check publishes an event for each change since the previous check.
+func (w *Watcher) check() {
+	var state watchedState
+	server, connected := w.vpnLooper.GetConnectedServer()
+	state.tunnelUp = connected && !server.ConnectedAt.IsZero()
+	if state.tunnelUp {
+		state.connectedAt = server.ConnectedAt
+	}
+	if ip := w.publicIP.GetData().IP; ip != nil {
+		state.publicIP = ip.String()
+	}
+	state.portForwarded = w.portForwarded.GetPortForwarded()
+	err := w.readiness.Ready()
+	state.healthy = err == nil
+	if err != nil {
+		state.healthError = err.Error()
+	}
+
+	previous := w.state
+	w.state = state
+
+	// A tunnel reconnected between two checks is
+	// published as going down and then up again.
+	if previous.tunnelUp && (!state.tunnelUp || state.connectedAt != previous.connectedAt) {
+		w.publisher.Publish(TypeTunnelDown, nil)
+	}
+	if state.tunnelUp && state.connectedAt != previous.connectedAt {
+		w.publisher.Publish(TypeTunnelUp, server)
+	}
+
+	if state.publicIP != previous.publicIP {
+		w.publisher.Publish(TypePublicIPChanged, publicIPData{PublicIP: state.publicIP})
+	}
+
+	if state.portForwarded != previous.portForwarded {
+		w.publisher.Publish(TypePortForwardedChanged, portForwardedData{Port: state.portForwarded})
+	}
+
+	if state.healthy != previous.healthy {
+		w.publisher.Publish(TypeHealthChanged, healthData{
+			Healthy: state.healthy,
+			Error:   state.healthError,
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package events
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePublisher struct {
+	events []Event
+}
+
+func (p *fakePublisher) Publish(eventType string, data interface{}) {
+	p.events = append(p.events, Event{Type: eventType, Data: data})
+}
+
+type fakeState struct {
+	server        models.ConnectedServer
+	connected     bool
+	publicIP      net.IP
+	portForwarded uint16
+	readyErr      error
+}
+
+func (s *fakeState) GetConnectedServer() (models.ConnectedServer, bool) {
+	return s.server, s.connected
+}
+
+func (s *fakeState) GetData() models.PublicIP { return models.PublicIP{IP: s.publicIP} }
+func (s *fakeState) GetPortForwarded() uint16 { return s.portForwarded }
+func (s *fakeState) Ready() error             { return s.readyErr }
+
+func Test_Watcher_check(t *testing.T) {
+	t.Parallel()
+
+	publisher := &fakePublisher{}
+	state := &fakeState{readyErr: errors.New("tunnel is down")}
+	watcher := NewWatcher(publisher, state, state, state, state)
+
+	watcher.check()
+	assert.Empty(t, publisher.events)
+
+	// The tunnel is not up until the connected server has its connection time.
+	state.server = models.ConnectedServer{ServerName: "server"}
+	state.connected = true
+	watcher.check()
+	assert.Empty(t, publisher.events)
+
+	state.server.ConnectedAt = time.Unix(1, 0)
+	state.publicIP = net.IPv4(1, 2, 3, 4)
+	state.portForwarded = 5678
+	state.readyErr = nil
+	watcher.check()
+	watcher.check()
+
+	// A reconnection between two checks.
+	state.server.ConnectedAt = time.Unix(2, 0)
+	watcher.check()
+
+	state.connected = false
+	state.portForwarded = 0
+	watcher.check()
+
+	expected := []Event{
+		{Type: TypeTunnelUp, Data: models.ConnectedServer{ServerName: "server", ConnectedAt: time.Unix(1, 0)}},
+		{Type: TypePublicIPChanged, Data: publicIPData{PublicIP: "1.2.3.4"}},
+		{Type: TypePortForwardedChanged, Data: portForwardedData{Port: 5678}},
+		{Type: TypeHealthChanged, Data: healthData{Healthy: true}},
+		{Type: TypeTunnelDown},
+		{Type: TypeTunnelUp, Data: models.ConnectedServer{ServerName: "server", ConnectedAt: time.Unix(2, 0)}},
+		{Type: TypeTunnelDown},
+		{Type: TypePortForwardedChanged, Data: portForwardedData{Port: 0}},
+	}
+	assert.Equal(t, expected, publisher.events)
+}
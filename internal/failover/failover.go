@@ -0,0 +1,181 @@
+// Package failover switches the VPN to secondary settings
+// when the primary VPN stays unhealthy, and back.
+package failover
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/notification"
+)
+
+const (
+	Primary   = "primary"
+	Secondary = "secondary"
+)
+
+// Event is a switch from one VPN settings to the other.
+type Event struct {
+	Time   time.Time `json:"time"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Reason string    `json:"reason"`
+}
+
+// Status is the failover state exposed by the control server.
+type Status struct {
+	Enabled        bool       `json:"enabled"`
+	Active         string     `json:"active"`
+	UnhealthySince *time.Time `json:"unhealthy_since,omitempty"`
+	Events         []Event    `json:"events"`
+}
+
+// Switcher checks the VPN health periodically and switches all
+// the traffic to the other VPN settings once the active VPN has
+// been unhealthy for longer than the threshold.
+// The other VPN is kept as a cold standby: its settings are
+// validated at startup but it is only connected on switchover.
+type Switcher struct {
+	enabled   bool
+	threshold time.Duration
+	vpnLooper VPNLooper
+	readiness ReadinessChecker
+	notifier  Notifier
+	logger    Logger
+	timeNow   func() time.Time
+	failover  settings.Failover
+
+	mutex          sync.RWMutex
+	active         string
+	unhealthySince time.Time
+	events         []Event
+	// primary is the primary VPN settings as they were read
+	// from the VPN looper when switching to the secondary VPN,
+	// and is used to switch back to the primary VPN.
+	primary settings.VPN
+}
+
+// New creates a failover switcher between the primary
+// VPN settings and the secondary VPN settings built from
+// the failover settings. The primary VPN settings are read
+// from the VPN looper when switching, so settings changed
+// at runtime are kept across switchovers.
+func New(failover settings.Failover,
+	vpnLooper VPNLooper, readiness ReadinessChecker,
+	notifier Notifier, logger Logger) *Switcher {
+	return &Switcher{
+		enabled:   *failover.Enabled,
+		threshold: *failover.Threshold,
+		vpnLooper: vpnLooper,
+		readiness: readiness,
+		notifier:  notifier,
+		logger:    logger,
+		timeNow:   time.Now,
+		failover:  failover,
+		active:    Primary,
+		events:    []Event{},
+	}
+}
+
+// Run checks the VPN health until the context is canceled.
+// It returns immediately if the failover is disabled.
+func (s *Switcher) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	if !s.enabled {
+		return
+	}
+
+	const checkPeriod = 5 * time.Second
+	ticker := time.NewTicker(checkPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.check(ctx)
+		}
+	}
+}
+
+func (s *Switcher) check(ctx context.Context) {
+	healthErr := s.readiness.Ready()
+	if healthErr == nil || s.vpnLooper.GetStatus() == constants.Stopped {
+		// A VPN stopped on purpose is not an unhealthy VPN.
+		s.mutex.Lock()
+		s.unhealthySince = time.Time{}
+		s.mutex.Unlock()
+		return
+	}
+
+	now := s.timeNow()
+
+	s.mutex.Lock()
+	if s.unhealthySince.IsZero() {
+		s.unhealthySince = now
+	}
+	unhealthyFor := now.Sub(s.unhealthySince)
+	if unhealthyFor < s.threshold {
+		s.mutex.Unlock()
+		return
+	}
+
+	event := Event{
+		Time: now,
+		From: s.active,
+		To:   otherName(s.active),
+		Reason: fmt.Sprintf("%s VPN unhealthy for %s: %s",
+			s.active, unhealthyFor.Round(time.Second), healthErr),
+	}
+	var vpnSettings settings.VPN
+	if event.To == Secondary {
+		s.primary = s.vpnLooper.GetSettings()
+		vpnSettings = s.failover.Secondary(s.primary)
+	} else {
+		vpnSettings = s.primary
+	}
+	s.active = event.To
+	s.unhealthySince = time.Time{}
+	s.events = append(s.events, event)
+	s.mutex.Unlock()
+
+	s.notifier.Notify(ctx, notification.Notification{
+		Title:     "VPN failover",
+		Message:   "switching from " + event.From + " to " + event.To + " VPN: " + event.Reason,
+		Important: true,
+	})
+	outcome := s.vpnLooper.SetSettings(ctx, vpnSettings)
+	s.logger.Info(outcome)
+}
+
+func otherName(name string) string {
+	if name == Primary {
+		return Secondary
+	}
+	return Primary
+}
+
+// GetStatus returns the current failover status,
+// including all the switchover events so far.
+func (s *Switcher) GetStatus() (status Status) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	status = Status{
+		Enabled: s.enabled,
+		Active:  s.active,
+		Events:  make([]Event, len(s.events)),
+	}
+	copy(status.Events, s.events)
+	if !s.unhealthySince.IsZero() {
+		unhealthySince := s.unhealthySince
+		status.UnhealthySince = &unhealthySince
+	}
+	return status
+}
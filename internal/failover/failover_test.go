@@ -0,0 +1,134 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/notification"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeVPNLooper struct {
+	status      models.LoopStatus
+	settings    settings.VPN
+	setSettings []settings.VPN
+}
+
+func (l *fakeVPNLooper) GetStatus() models.LoopStatus { return l.status }
+
+func (l *fakeVPNLooper) GetSettings() settings.VPN { return l.settings }
+
+func (l *fakeVPNLooper) SetSettings(_ context.Context, vpn settings.VPN) string {
+	l.settings = vpn
+	l.setSettings = append(l.setSettings, vpn)
+	return "settings updated"
+}
+
+type fakeReadiness struct {
+	err error
+}
+
+func (r *fakeReadiness) Ready() error { return r.err }
+
+type fakeNotifier struct {
+	notifications []notification.Notification
+}
+
+func (n *fakeNotifier) Notify(_ context.Context, notification notification.Notification) {
+	n.notifications = append(n.notifications, notification)
+}
+
+func Test_Switcher_check(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	primaryName, secondaryName := "mullvad", "ivpn"
+	enabled := true
+	threshold := time.Minute
+	failoverSettings := settings.Failover{
+		Enabled:   &enabled,
+		Threshold: &threshold,
+		VPN: settings.VPN{
+			Provider: settings.Provider{Name: &secondaryName},
+		},
+	}
+	primary := settings.VPN{
+		Type:     "wireguard",
+		Provider: settings.Provider{Name: &primaryName},
+	}
+
+	looper := &fakeVPNLooper{status: constants.Running, settings: settings.VPN{
+		Type:     "openvpn",
+		Provider: settings.Provider{Name: &primaryName},
+	}}
+	readiness := &fakeReadiness{err: errors.New("dial failed")}
+	notifier := &fakeNotifier{}
+	logger := NewMockLogger(ctrl)
+	switcher := New(failoverSettings, looper, readiness, notifier, logger)
+	start := time.Unix(1700000000, 0)
+	now := start
+	switcher.timeNow = func() time.Time { return now }
+	ctx := context.Background()
+
+	// VPN settings changed at runtime before the failover
+	looper.settings = primary
+
+	// Unhealthy for less than the threshold
+	switcher.check(ctx)
+	now = now.Add(30 * time.Second)
+	switcher.check(ctx)
+	assert.Empty(t, looper.setSettings)
+	status := switcher.GetStatus()
+	assert.Equal(t, Primary, status.Active)
+	assert.Equal(t, &start, status.UnhealthySince)
+
+	// Unhealthy beyond the threshold
+	now = now.Add(30 * time.Second)
+	logger.EXPECT().Info("settings updated")
+	switcher.check(ctx)
+	if assert.Len(t, looper.setSettings, 1) {
+		assert.Equal(t, "wireguard", looper.setSettings[0].Type)
+		assert.Equal(t, secondaryName, *looper.setSettings[0].Provider.Name)
+	}
+	assert.Len(t, notifier.notifications, 1)
+	status = switcher.GetStatus()
+	expectedEvents := []Event{{
+		Time:   now,
+		From:   Primary,
+		To:     Secondary,
+		Reason: "primary VPN unhealthy for 1m0s: dial failed",
+	}}
+	assert.Equal(t, Status{
+		Enabled: true,
+		Active:  Secondary,
+		Events:  expectedEvents,
+	}, status)
+
+	// Stopped VPN resets the unhealthy time
+	now = now.Add(30 * time.Second)
+	switcher.check(ctx)
+	looper.status = constants.Stopped
+	now = now.Add(time.Hour)
+	switcher.check(ctx)
+	assert.Len(t, looper.setSettings, 1)
+	assert.Nil(t, switcher.GetStatus().UnhealthySince)
+
+	// Secondary unhealthy beyond the threshold switches back
+	looper.status = constants.Running
+	switcher.check(ctx)
+	now = now.Add(threshold)
+	logger.EXPECT().Info("settings updated")
+	switcher.check(ctx)
+	if assert.Len(t, looper.setSettings, 2) {
+		assert.Equal(t, primary, looper.setSettings[1])
+	}
+	status = switcher.GetStatus()
+	assert.Equal(t, Primary, status.Active)
+	assert.Len(t, status.Events, 2)
+}
@@ -0,0 +1,27 @@
+package failover
+
+import (
+	"context"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/notification"
+)
+
+type VPNLooper interface {
+	GetStatus() (status models.LoopStatus)
+	GetSettings() (settings settings.VPN)
+	SetSettings(ctx context.Context, settings settings.VPN) (outcome string)
+}
+
+type ReadinessChecker interface {
+	Ready() (err error)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, notification notification.Notification)
+}
+
+type Logger interface {
+	Info(s string)
+}
@@ -0,0 +1,3 @@
+package failover
+
+//go:generate mockgen -destination=mocks_test.go -package=$GOPACKAGE . Logger
@@ -0,0 +1,115 @@
+package firewall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/qdm12/golibs/command"
+)
+
+// backend applies iptables instructions, either using the iptables
+// binaries or by translating them to nftables rules.
+type backend interface {
+	// run runs a single instruction, for IPv6 if ipv6 is true.
+	run(ctx context.Context, instruction string, ipv6 bool) error
+	// applyAtomically applies all the IPv4 and IPv6 instructions
+	// given at once, such that nothing is changed on failure.
+	applyAtomically(ctx context.Context, ipv4, ipv6 []string) error
+	// setNetworks creates or replaces the IP set named name with the
+	// networks given, for IPv6 if ipv6 is true, such that instructions
+	// can match it with `-m set --match-set <name> dst`.
+	setNetworks(ctx context.Context, name string, networks []net.IPNet, ipv6 bool) error
+	// supportsIPv6 returns true if IPv6 instructions can be run.
+	supportsIPv6() bool
+	// name returns the name of the backend, for logging purposes.
+	name() string
+}
+
+var ErrBackendUnknown = errors.New("firewall backend is unknown")
+
+// newBackend returns the backend for the backendName given, which can be
+// "iptables", "nftables", "ebpf" or "auto". For "auto", the iptables backend
+// is used if an iptables binary is supported, and the nftables backend is
+// used otherwise. The "ebpf" backend picks a backend like "auto" does, and
+// the eBPF egress filter set with SetEgressFilter is used in addition.
+func newBackend(ctx context.Context, backendName string, logger Logger,
+	runner command.Runner) (b backend, err error) {
+	switch backendName {
+	case "iptables":
+		return newIptablesBackend(ctx, logger, runner)
+	case "nftables":
+		return newNftablesBackend(logger)
+	case "ebpf":
+		b, err = newBackend(ctx, "auto", logger, runner)
+		if err != nil {
+			return nil, err
+		}
+		return ebpfBackend{backend: b}, nil
+	case "auto":
+		b, err = newIptablesBackend(ctx, logger, runner)
+		if err == nil {
+			return b, nil
+		} else if !errors.Is(err, ErrIPTablesNotSupported) {
+			return nil, err
+		}
+		iptablesErr := err
+		b, err = newNftablesBackend(logger)
+		if err != nil {
+			return nil, fmt.Errorf("%w; nftables: %s", iptablesErr, err)
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrBackendUnknown, backendName)
+	}
+}
+
+// usesIptables returns true if the backend given runs the iptables programs,
+// directly or wrapped in the ebpf backend.
+func usesIptables(b backend) bool {
+	if ebpf, ok := b.(ebpfBackend); ok {
+		b = ebpf.backend
+	}
+	_, ok := b.(*iptablesBackend)
+	return ok
+}
+
+type iptablesBackend struct {
+	runner         command.Runner
+	logger         Logger
+	iptablesMutex  sync.Mutex
+	ip6tablesMutex sync.Mutex
+	ipTables       string
+	// ip6Tables is empty if ip6tables is not supported.
+	ip6Tables string
+}
+
+func newIptablesBackend(ctx context.Context, logger Logger,
+	runner command.Runner) (b *iptablesBackend, err error) {
+	iptables, err := checkIptablesSupport(ctx, runner, "iptables", "iptables-nft")
+	if err != nil {
+		return nil, err
+	}
+
+	ip6tables, err := findIP6tablesSupported(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+
+	return &iptablesBackend{
+		runner:    runner,
+		logger:    logger,
+		ipTables:  iptables,
+		ip6Tables: ip6tables,
+	}, nil
+}
+
+func (i *iptablesBackend) supportsIPv6() bool {
+	return i.ip6Tables != ""
+}
+
+func (i *iptablesBackend) name() string {
+	return i.ipTables
+}
@@ -0,0 +1,72 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// SetBypassIPs accepts output traffic to the IP addresses given through
+// the default route interfaces, for traffic split from the VPN tunnel,
+// replacing the previous IP addresses. The traffic must also be routed
+// through the default routes for it to bypass the VPN.
+func (c *Config) SetBypassIPs(ctx context.Context, ips []net.IP) (err error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	defer func() {
+		egressFilterErr := c.updateEgressFilter()
+		if err == nil {
+			err = egressFilterErr
+		}
+	}()
+
+	if !c.enabled {
+		c.bypassIPs = copyIPs(ips)
+		return nil
+	}
+
+	const remove = true
+	err = c.acceptBypassIPs(ctx, c.bypassIPs, remove)
+	if err != nil {
+		return fmt.Errorf("removing previous bypass IP addresses: %w", err)
+	}
+	c.bypassIPs = nil
+
+	err = c.acceptBypassIPs(ctx, ips, !remove)
+	if err != nil {
+		return fmt.Errorf("accepting bypass IP addresses: %w", err)
+	}
+	c.bypassIPs = copyIPs(ips)
+
+	return nil
+}
+
+// acceptBypassIPs accepts, or removes the acceptance if remove is true,
+// output traffic to the IP addresses given through each default route
+// interface of the same IP family.
+func (c *Config) acceptBypassIPs(ctx context.Context, ips []net.IP, remove bool) (err error) {
+	for _, ip := range ips {
+		destination := ipToIPNet(ip)
+		for _, defaultRoute := range c.defaultRoutes {
+			if (defaultRoute.AssignedIP.To4() != nil) != (ip.To4() != nil) {
+				continue
+			}
+			err = c.acceptOutputFromIPToSubnet(ctx, defaultRoute.NetInterface,
+				defaultRoute.AssignedIP, destination, remove)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func ipToIPNet(ip net.IP) (ipNet net.IPNet) {
+	bits := 8 * net.IPv6len //nolint:gomnd
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 8 * net.IPv4len //nolint:gomnd
+	}
+	return net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+}
@@ -0,0 +1,40 @@
+package firewall
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/routing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Config_SetBypassIPs(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{
+		enabled: true,
+		backend: &iptablesBackend{},
+		defaultRoutes: []routing.DefaultRoute{{
+			NetInterface: "eth0",
+			AssignedIP:   net.IPv4(172, 17, 0, 2),
+		}},
+		ruleSet: new(ruleSet),
+	}
+	ctx := context.Background()
+
+	err := config.SetBypassIPs(ctx, []net.IP{net.IPv4(1, 2, 3, 4), net.ParseIP("2001:db8::1")})
+	require.NoError(t, err)
+	err = config.SetBypassIPs(ctx, []net.IP{net.IPv4(5, 6, 7, 8)})
+	require.NoError(t, err)
+
+	expected := []string{
+		"--append OUTPUT -o eth0 -s 172.17.0.2 -d 1.2.3.4/32 -j ACCEPT",
+		"--delete OUTPUT -o eth0 -s 172.17.0.2 -d 1.2.3.4/32 -j ACCEPT",
+		"--append OUTPUT -o eth0 -s 172.17.0.2 -d 5.6.7.8/32 -j ACCEPT",
+	}
+	assert.Equal(t, expected, config.ruleSet.ipv4)
+	assert.Empty(t, config.ruleSet.ipv6)
+	assert.Equal(t, []net.IP{net.IPv4(5, 6, 7, 8)}, config.bypassIPs)
+}
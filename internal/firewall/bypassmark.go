@@ -0,0 +1,89 @@
+package firewall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrBypassCgroupsNeedIptables = errors.New("bypass cgroups need the iptables backend")
+
+// SetBypassMark sets the firewall mark of the traffic going out through
+// the default route interfaces instead of the VPN, and the cgroup v2
+// paths, relative to the cgroup v2 root, whose traffic is marked with
+// it. A mark of 0 disables the bypass. The marked traffic must also be
+// routed through the default routes for it to bypass the VPN.
+// It must be called before the firewall is enabled.
+func (c *Config) SetBypassMark(mark uint32, cgroups []string) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	c.bypassMark = mark
+	c.bypassCgroups = make([]string, len(cgroups))
+	copy(c.bypassCgroups, cgroups)
+}
+
+// acceptBypassMark accepts output traffic with the bypass mark
+// through each default route interface.
+func (c *Config) acceptBypassMark(ctx context.Context) (err error) {
+	if c.bypassMark == 0 {
+		return nil
+	}
+
+	for _, defaultRoute := range c.defaultRoutes {
+		instruction := fmt.Sprintf("--append OUTPUT -o %s -m mark --mark 0x%x -j ACCEPT",
+			defaultRoute.NetInterface, c.bypassMark)
+		if err = c.runMixedIptablesInstruction(ctx, instruction); err != nil {
+			return fmt.Errorf("accepting bypass mark: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyBypassMark applies the rules marking the traffic of the bypass
+// cgroups and masquerading the marked traffic. It must be called with
+// the state mutex locked, once the firewall rules are applied.
+// Matching cgroups is only possible with the iptables backend, which
+// may not be the one picked by the "auto" backend.
+func (c *Config) applyBypassMark(ctx context.Context) (err error) {
+	if !usesIptables(c.backend) && len(c.bypassCgroups) > 0 {
+		return fmt.Errorf("%w: using the %s backend", ErrBypassCgroupsNeedIptables, c.backend.name())
+	}
+
+	rules := c.renderBypassMarkRules()
+	const remove = false
+	err = c.runUserRules(ctx, rules, remove)
+	if err != nil {
+		return fmt.Errorf("adding bypass mark rules: %w", err)
+	}
+	c.bypassMarkApplied = rules
+	return nil
+}
+
+// renderBypassMarkRules returns the rules to mark the traffic of the
+// bypass cgroups, and to masquerade the marked traffic since its source
+// address may have been chosen for the VPN interface before being marked
+// and rerouted. It returns no rule if the bypass mark is not set.
+func (c *Config) renderBypassMarkRules() (rules []string) {
+	if c.bypassMark == 0 {
+		return nil
+	}
+
+	commands := []string{"iptables"}
+	if c.backend.supportsIPv6() {
+		commands = append(commands, "ip6tables")
+	}
+
+	for _, command := range commands {
+		for _, cgroup := range c.bypassCgroups {
+			rules = append(rules, fmt.Sprintf(
+				"%s -t mangle --append OUTPUT -m cgroup --path %s -j MARK --set-mark 0x%x",
+				command, cgroup, c.bypassMark))
+		}
+		for _, defaultRoute := range c.defaultRoutes {
+			rules = append(rules, fmt.Sprintf(
+				"%s -t nat --append POSTROUTING -o %s -m mark --mark 0x%x -j MASQUERADE",
+				command, defaultRoute.NetInterface, c.bypassMark))
+		}
+	}
+	return rules
+}
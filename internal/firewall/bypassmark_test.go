@@ -0,0 +1,58 @@
+package firewall
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/google/nftables"
+	"github.com/qdm12/gluetun/internal/routing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Config_bypassMark(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{
+		backend: &iptablesBackend{},
+		defaultRoutes: []routing.DefaultRoute{{
+			NetInterface: "eth0",
+			AssignedIP:   net.IPv4(172, 17, 0, 2),
+		}},
+		ruleSet: new(ruleSet),
+	}
+	config.SetBypassMark(0x6762, []string{"/sidecar"})
+
+	err := config.acceptBypassMark(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--append OUTPUT -o eth0 -m mark --mark 0x6762 -j ACCEPT"},
+		config.ruleSet.ipv4)
+
+	expectedRules := []string{
+		"iptables -t mangle --append OUTPUT -m cgroup --path /sidecar -j MARK --set-mark 0x6762",
+		"iptables -t nat --append POSTROUTING -o eth0 -m mark --mark 0x6762 -j MASQUERADE",
+	}
+	assert.Equal(t, expectedRules, config.renderBypassMarkRules())
+
+	assert.True(t, usesIptables(ebpfBackend{backend: &iptablesBackend{}}))
+	config.backend = ebpfBackend{backend: &nftablesBackend{}}
+	err = config.applyBypassMark(context.Background())
+	assert.ErrorIs(t, err, ErrBypassCgroupsNeedIptables)
+
+	config.backend = &nftablesBackend{}
+	err = config.applyBypassMark(context.Background())
+	assert.ErrorIs(t, err, ErrBypassCgroupsNeedIptables)
+	assert.EqualError(t, err, "bypass cgroups need the iptables backend: using the nftables backend")
+
+	config.SetBypassMark(0x6762, nil)
+	expectedRules = []string{
+		"iptables -t nat --append POSTROUTING -o eth0 -m mark --mark 0x6762 -j MASQUERADE",
+	}
+	assert.Equal(t, expectedRules, config.renderBypassMarkRules())
+	assertNftablesTranslatable(t, config.ruleSet.ipv4, nftables.TableFamilyIPv4)
+	assertNftablesTranslatable(t, expectedRules, nftables.TableFamilyIPv4)
+
+	config.SetBypassMark(0, nil)
+	assert.Empty(t, config.renderBypassMarkRules())
+}
@@ -0,0 +1,173 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// countriesSetName and countriesSet6Name are the names of the IPv4
+// and IPv6 sets of the country networks matched by the rules.
+const (
+	countriesSetName  = "gluetun-countries"
+	countriesSet6Name = "gluetun-countries6"
+)
+
+// SetCountryNetworks blocks output traffic through the VPN interface
+// to the networks given, or only allows output traffic through the VPN
+// interface to these networks and to the DNS servers given if allowed
+// is true. The networks are typically the networks of a set of countries,
+// and are matched using an IP set for each IP family.
+// Setting no network and allowed to false removes the restriction.
+func (c *Config) SetCountryNetworks(ctx context.Context,
+	networks []net.IPNet, allowed bool, dnsServers []net.IP) (err error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	if !c.enabled || c.vpnIntf == "" {
+		c.countryNetworks = copyIPNets(networks)
+		c.countryNetworksAllowed = allowed
+		c.countryDNSServers = copyIPs(dnsServers)
+		return nil
+	}
+
+	const remove = true
+	err = c.acceptCountryNetworks(ctx, c.vpnIntf, c.countryNetworks,
+		c.countryNetworksAllowed, c.countryDNSServers, remove)
+	if err != nil {
+		return fmt.Errorf("removing country networks rules: %w", err)
+	}
+	c.countryNetworks = nil
+	c.countryNetworksAllowed = false
+	c.countryDNSServers = nil
+
+	err = c.acceptCountryNetworks(ctx, c.vpnIntf, networks, allowed, dnsServers, !remove)
+	if err != nil {
+		return fmt.Errorf("adding country networks rules: %w", err)
+	}
+	c.countryNetworks = copyIPNets(networks)
+	c.countryNetworksAllowed = allowed
+	c.countryDNSServers = copyIPs(dnsServers)
+
+	return nil
+}
+
+// acceptCountryNetworks inserts, or removes if remove is true, the rules
+// restricting output traffic through the VPN interface to the networks
+// given. The networks are first stored in the IP sets matched by the
+// rules, and the sets are emptied once the rules are removed. The rules
+// are applied in one shot.
+func (c *Config) acceptCountryNetworks(ctx context.Context, intf string,
+	networks []net.IPNet, allowed bool, dnsServers []net.IP, remove bool) (err error) {
+	if len(networks) == 0 && !allowed {
+		return nil
+	}
+
+	if !remove {
+		err = c.setCountrySets(ctx, networks)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.applyRuleSet(ctx, func(ctx context.Context) (err error) {
+		return c.runCountryNetworksInstructions(ctx, intf, allowed, dnsServers, remove)
+	})
+	if err != nil {
+		return err
+	}
+
+	if remove {
+		return c.setCountrySets(ctx, nil)
+	}
+	return nil
+}
+
+// setCountrySets sets the IPv4 and IPv6 networks given in their
+// respective country networks set.
+func (c *Config) setCountrySets(ctx context.Context, networks []net.IPNet) (err error) {
+	var ipv4Networks, ipv6Networks []net.IPNet
+	for _, network := range networks {
+		if network.IP.To4() != nil {
+			ipv4Networks = append(ipv4Networks, network)
+		} else {
+			ipv6Networks = append(ipv6Networks, network)
+		}
+	}
+
+	const ipv6 = true
+	err = c.backend.setNetworks(ctx, countriesSetName, ipv4Networks, !ipv6)
+	if err != nil {
+		return fmt.Errorf("setting IPv4 country networks: %w", err)
+	}
+
+	if !c.backend.supportsIPv6() {
+		return nil
+	}
+	err = c.backend.setNetworks(ctx, countriesSet6Name, ipv6Networks, ipv6)
+	if err != nil {
+		return fmt.Errorf("setting IPv6 country networks: %w", err)
+	}
+	return nil
+}
+
+// runCountryNetworksInstructions runs the instructions inserting, or
+// removing if remove is true, the country networks rules at the start
+// of the OUTPUT chain. If allowed is true, only the traffic to the
+// country networks and to the DNS servers given is accepted.
+func (c *Config) runCountryNetworksInstructions(ctx context.Context, intf string,
+	allowed bool, dnsServers []net.IP, remove bool) (err error) {
+	operation := "--insert"
+	if remove {
+		operation = "--delete"
+	}
+
+	if allowed {
+		// Inserted rules end up in the reverse order,
+		// so the catch all drop rule is inserted first.
+		err = c.runMixedIptablesInstruction(ctx,
+			fmt.Sprintf("%s OUTPUT -o %s -j DROP", operation, intf))
+		if err != nil {
+			return err
+		}
+
+		// The DNS servers can be reached with any DNS protocol,
+		// such as DNS over TLS or DNS over HTTPS.
+		for _, dnsServer := range dnsServers {
+			instruction := fmt.Sprintf("%s OUTPUT -o %s -d %s -j ACCEPT",
+				operation, intf, dnsServer)
+			if dnsServer.To4() != nil {
+				err = c.runIptablesInstruction(ctx, instruction)
+			} else {
+				err = c.runIP6tablesInstruction(ctx, instruction)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	target := "DROP"
+	if allowed {
+		target = "ACCEPT"
+	}
+	const format = "%s OUTPUT -o %s -m set --match-set %s dst -j %s"
+	err = c.runIptablesInstruction(ctx, fmt.Sprintf(format,
+		operation, intf, countriesSetName, target))
+	if err != nil {
+		return err
+	}
+	return c.runIP6tablesInstruction(ctx, fmt.Sprintf(format,
+		operation, intf, countriesSet6Name, target))
+}
+
+func copyIPNets(networks []net.IPNet) (copied []net.IPNet) {
+	copied = make([]net.IPNet, len(networks))
+	for i, network := range networks {
+		copied[i].IP = make(net.IP, len(network.IP))
+		copy(copied[i].IP, network.IP)
+		copied[i].Mask = make(net.IPMask, len(network.Mask))
+		copy(copied[i].Mask, network.Mask)
+	}
+	return copied
+}
@@ -0,0 +1,61 @@
+package firewall
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/google/nftables"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Config_SetCountryNetworks(t *testing.T) {
+	t.Parallel()
+
+	backend := &recordingBackend{}
+	config := &Config{
+		backend: backend,
+		enabled: true,
+		vpnIntf: "tun0",
+	}
+	ctx := context.Background()
+
+	_, network, err := net.ParseCIDR("1.2.3.0/24")
+	require.NoError(t, err)
+	networks := []net.IPNet{*network}
+	dnsServers := []net.IP{net.IPv4(1, 1, 1, 1)}
+
+	const allowed = false
+	err = config.SetCountryNetworks(ctx, networks, allowed, dnsServers)
+	require.NoError(t, err)
+	blockInstructions := []string{
+		"--insert OUTPUT -o tun0 -m set --match-set gluetun-countries dst -j DROP",
+	}
+	assert.Equal(t, blockInstructions, backend.instructions)
+	assert.Equal(t, networks, backend.sets[countriesSetName])
+
+	backend.instructions = nil
+	err = config.SetCountryNetworks(ctx, networks, !allowed, dnsServers)
+	require.NoError(t, err)
+	allowInstructions := []string{
+		"--insert OUTPUT -o tun0 -j DROP",
+		"--insert OUTPUT -o tun0 -d 1.1.1.1 -j ACCEPT",
+		"--insert OUTPUT -o tun0 -m set --match-set gluetun-countries dst -j ACCEPT",
+	}
+	assert.Equal(t, append([]string{
+		"--delete OUTPUT -o tun0 -m set --match-set gluetun-countries dst -j DROP",
+	}, allowInstructions...), backend.instructions)
+	assert.Equal(t, networks, backend.sets[countriesSetName])
+	assertNftablesTranslatable(t, allowInstructions, nftables.TableFamilyIPv4)
+
+	backend.instructions = nil
+	err = config.SetCountryNetworks(ctx, nil, allowed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"--delete OUTPUT -o tun0 -j DROP",
+		"--delete OUTPUT -o tun0 -d 1.1.1.1 -j ACCEPT",
+		"--delete OUTPUT -o tun0 -m set --match-set gluetun-countries dst -j ACCEPT",
+	}, backend.instructions)
+	assert.Empty(t, backend.sets[countriesSetName])
+}
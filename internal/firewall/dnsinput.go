@@ -0,0 +1,106 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// SetDNSInputSubnets restricts DNS queries coming in through the
+// default route interfaces to the source subnets given, which are
+// allowed to query the DNS server. An empty slice of subnets removes
+// the restriction.
+func (c *Config) SetDNSInputSubnets(ctx context.Context, subnets []net.IPNet) (err error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	if !c.enabled {
+		c.logger.Info("firewall disabled, only updating DNS input subnets internal list")
+		c.dnsInputSubnets = make([]net.IPNet, len(subnets))
+		copy(c.dnsInputSubnets, subnets)
+		return nil
+	}
+
+	c.logger.Info("setting DNS input subnets...")
+
+	if len(c.dnsInputSubnets) > 0 {
+		err = c.restrictDNSInput(ctx, c.dnsInputSubnets, "--delete")
+		if err != nil {
+			return fmt.Errorf("removing DNS input restriction: %w", err)
+		}
+		c.dnsInputSubnets = nil
+	}
+
+	if len(subnets) == 0 {
+		return nil
+	}
+
+	// Rules are inserted at the top of the INPUT chain, so they are
+	// evaluated before the rules accepting input to the local networks.
+	err = c.restrictDNSInput(ctx, subnets, "--insert")
+	if err != nil {
+		return fmt.Errorf("restricting DNS input: %w", err)
+	}
+	c.dnsInputSubnets = make([]net.IPNet, len(subnets))
+	copy(c.dnsInputSubnets, subnets)
+
+	return nil
+}
+
+// restrictDNSInput runs the rules accepting DNS queries from the subnets
+// given and dropping all other DNS queries, for each default route
+// interface. The operation is one of --append, --insert or --delete.
+// For --insert, the rules are run in reverse order so they end up in
+// the same order as for --append.
+func (c *Config) restrictDNSInput(ctx context.Context, subnets []net.IPNet,
+	operation string) (err error) {
+	type rule struct {
+		instruction string
+		ipv4, ipv6  bool
+	}
+	var rules []rule
+	for _, defaultRoute := range c.defaultRoutes {
+		for _, subnet := range subnets {
+			isIPv4 := subnet.IP.To4() != nil
+			for _, protocol := range []string{"udp", "tcp"} {
+				rules = append(rules, rule{
+					instruction: fmt.Sprintf("%s INPUT -i %s -s %s -p %s --dport 53 -j ACCEPT",
+						operation, defaultRoute.NetInterface, subnet.String(), protocol),
+					ipv4: isIPv4,
+					ipv6: !isIPv4,
+				})
+			}
+		}
+		for _, protocol := range []string{"udp", "tcp"} {
+			rules = append(rules, rule{
+				instruction: fmt.Sprintf("%s INPUT -i %s -p %s --dport 53 -j DROP",
+					operation, defaultRoute.NetInterface, protocol),
+				ipv4: true,
+				ipv6: true,
+			})
+		}
+	}
+
+	if operation == "--insert" {
+		for i, j := 0, len(rules)-1; i < j; i, j = i+1, j-1 {
+			rules[i], rules[j] = rules[j], rules[i]
+		}
+	}
+
+	for _, rule := range rules {
+		switch {
+		case rule.ipv4 && rule.ipv6:
+			err = c.runMixedIptablesInstruction(ctx, rule.instruction)
+		case rule.ipv4:
+			err = c.runIptablesInstruction(ctx, rule.instruction)
+		case !c.backend.supportsIPv6():
+			err = fmt.Errorf("%s: %w", rule.instruction, ErrNeedIP6Tables)
+		default:
+			err = c.runIP6tablesInstruction(ctx, rule.instruction)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SetDropLogGroup enables logging of the packets about to be dropped by
+// the default DROP policies to the NFLOG group given, with the lowercase
+// chain name as log prefix, for example "input". It must be called before
+// the firewall is enabled.
+func (c *Config) SetDropLogGroup(group uint16) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	c.dropLogGroup = group
+	c.dropLogging = true
+}
+
+var dropLogChains = []string{"INPUT", "OUTPUT", "FORWARD"} //nolint:gochecknoglobals
+
+func (c *Config) makeDropLogRule(chain string, remove bool) (rule string) {
+	return fmt.Sprintf("%s %s -j NFLOG --nflog-group %d --nflog-prefix %s",
+		appendOrDelete(remove), chain, c.dropLogGroup, strings.ToLower(chain))
+}
+
+// addDropLogRules adds the NFLOG rules at the end of the filter
+// chains, if drop logging is enabled.
+func (c *Config) addDropLogRules(ctx context.Context) (err error) {
+	if !c.dropLogging {
+		return nil
+	}
+	for _, chain := range dropLogChains {
+		const remove = false
+		err = c.runMixedIptablesInstruction(ctx, c.makeDropLogRule(chain, remove))
+		if err != nil {
+			return fmt.Errorf("adding drop log rule: %w", err)
+		}
+	}
+	return nil
+}
+
+// keepDropLogRuleLast moves the NFLOG rule of the chain the instruction
+// given appended a rule to at the end of the chain, such that only the
+// packets not matching any other rule are logged. It runs the instructions
+// directly with the backend, and does nothing if drop logging is disabled,
+// if the NFLOG rules are not in place or if the instruction does not append
+// a rule to a chain of the filter table with an NFLOG rule.
+func (c *Config) keepDropLogRuleLast(ctx context.Context,
+	instruction string, ipv6 bool) (err error) {
+	if !c.dropLogging || !c.dropLogRulesAdded {
+		return nil
+	}
+
+	const minFields = 2
+	tableName, fields := extractTable(strings.Fields(instruction))
+	if tableName != "filter" || len(fields) < minFields ||
+		(fields[0] != "--append" && fields[0] != "-A") ||
+		strings.Contains(instruction, " NFLOG ") {
+		return nil
+	}
+
+	chain := fields[1]
+	isDropLogChain := false
+	for _, dropLogChain := range dropLogChains {
+		if chain == dropLogChain {
+			isDropLogChain = true
+			break
+		}
+	}
+	if !isDropLogChain {
+		return nil
+	}
+
+	for _, remove := range []bool{true, false} {
+		err = c.backend.run(ctx, c.makeDropLogRule(chain, remove), ipv6)
+		if err != nil {
+			return fmt.Errorf("moving drop log rule to the end of the %s chain: %w", chain, err)
+		}
+	}
+	return nil
+}
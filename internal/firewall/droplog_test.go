@@ -0,0 +1,65 @@
+package firewall
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingBackend struct {
+	instructions []string
+	sets         map[string][]net.IPNet
+}
+
+func (b *recordingBackend) run(_ context.Context, instruction string, _ bool) error {
+	b.instructions = append(b.instructions, instruction)
+	return nil
+}
+
+func (b *recordingBackend) applyAtomically(_ context.Context, ipv4, _ []string) error {
+	b.instructions = append(b.instructions, ipv4...)
+	return nil
+}
+
+func (b *recordingBackend) setNetworks(_ context.Context, name string,
+	networks []net.IPNet, _ bool) error {
+	if b.sets == nil {
+		b.sets = make(map[string][]net.IPNet)
+	}
+	b.sets[name] = networks
+	return nil
+}
+
+func (b *recordingBackend) supportsIPv6() bool { return false }
+func (b *recordingBackend) name() string       { return "recording" }
+
+func Test_Config_keepDropLogRuleLast(t *testing.T) {
+	t.Parallel()
+
+	backend := &recordingBackend{}
+	config := &Config{
+		backend:           backend,
+		dropLogging:       true,
+		dropLogGroup:      100,
+		dropLogRulesAdded: true,
+	}
+	ctx := context.Background()
+
+	err := config.runIptablesInstruction(ctx, "--append OUTPUT -o tun0 -j ACCEPT")
+	require.NoError(t, err)
+	err = config.runIptablesInstruction(ctx, "--delete OUTPUT -o tun0 -j ACCEPT")
+	require.NoError(t, err)
+	err = config.runIptablesInstruction(ctx, "-t nat --append OUTPUT -p tcp -j REDIRECT --to-ports 1080")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"--append OUTPUT -o tun0 -j ACCEPT",
+		"--delete OUTPUT -j NFLOG --nflog-group 100 --nflog-prefix output",
+		"--append OUTPUT -j NFLOG --nflog-group 100 --nflog-prefix output",
+		"--delete OUTPUT -o tun0 -j ACCEPT",
+		"-t nat --append OUTPUT -p tcp -j REDIRECT --to-ports 1080",
+	}, backend.instructions)
+}
@@ -0,0 +1,13 @@
+package firewall
+
+// ebpfBackend is the backend used for the "ebpf" backend name. It applies
+// the rules with the iptables or nftables backend it wraps, and the eBPF
+// egress filter, see SetEgressFilter, is used in addition as kill switch
+// for the gluetun processes, even if other software flushes the rules.
+// Containers sharing the network namespace are only protected by the
+// rules of the wrapped backend.
+type ebpfBackend struct {
+	backend
+}
+
+func (e ebpfBackend) name() string { return "ebpf with " + e.backend.name() }
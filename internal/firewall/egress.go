@@ -0,0 +1,59 @@
+package firewall
+
+import (
+	"fmt"
+	"net"
+)
+
+// EgressFilter is an additional egress filter, such as the eBPF
+// egress filter, only allowing egress through the VPN interface,
+// the loopback interface and to the allowed networks.
+type EgressFilter interface {
+	Update(vpnInterface string, allowed []net.IPNet) (err error)
+}
+
+// SetEgressFilter sets the egress filter to keep updated with the
+// VPN connection and allowed subnets, and updates it immediately.
+// The egress filter only allows the VPN interface, the loopback interface
+// and the allowed subnets, even while the firewall is disabled, so it
+// must not be used with the firewall disabled or with VPN routes.
+func (c *Config) SetEgressFilter(filter EgressFilter) (err error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	c.egressFilter = filter
+	return c.updateEgressFilter()
+}
+
+// updateEgressFilter updates the egress filter, if any, with the
+// current state. It must be called with the state mutex locked.
+func (c *Config) updateEgressFilter() (err error) {
+	if c.egressFilter == nil {
+		return nil
+	}
+
+	allowed := make([]net.IPNet, 0, 1+len(c.localNetworks)+len(c.outboundSubnets))
+	if c.vpnConnection.IP != nil {
+		bits := 8 * net.IPv6len //nolint:gomnd
+		if c.vpnConnection.IP.To4() != nil {
+			bits = 8 * net.IPv4len //nolint:gomnd
+		}
+		allowed = append(allowed, net.IPNet{
+			IP:   c.vpnConnection.IP,
+			Mask: net.CIDRMask(bits, bits),
+		})
+	}
+	for _, network := range c.localNetworks {
+		allowed = append(allowed, *network.IPNet)
+	}
+	allowed = append(allowed, c.outboundSubnets...)
+	for _, ip := range c.bypassIPs {
+		allowed = append(allowed, ipToIPNet(ip))
+	}
+
+	err = c.egressFilter.Update(c.egressFilterVPNIntf, allowed)
+	if err != nil {
+		return fmt.Errorf("updating egress filter: %w", err)
+	}
+	return nil
+}
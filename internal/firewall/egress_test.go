@@ -0,0 +1,60 @@
+package firewall
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingEgressFilter struct {
+	vpnInterface string
+	allowed      []net.IPNet
+}
+
+func (f *recordingEgressFilter) Update(vpnInterface string, allowed []net.IPNet) error {
+	f.vpnInterface = vpnInterface
+	f.allowed = allowed
+	return nil
+}
+
+func Test_Config_egressFilter_killSwitch(t *testing.T) {
+	t.Parallel()
+
+	_, outboundSubnet, err := net.ParseCIDR("192.168.1.0/24")
+	require.NoError(t, err)
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	gomock.InOrder(
+		logger.EXPECT().Info("enabling..."),
+		logger.EXPECT().Info("startup policy lan: direct egress traffic is blocked "+
+			"until the VPN tunnel is up, except to the local networks and outbound subnets"),
+		logger.EXPECT().Info("enabled successfully"),
+		logger.EXPECT().Info("disabling..."),
+		logger.EXPECT().Info("disabled successfully"),
+	)
+	config := &Config{
+		logger:              logger,
+		backend:             ebpfBackend{backend: &recordingBackend{}},
+		outboundSubnets:     []net.IPNet{*outboundSubnet},
+		egressFilterVPNIntf: "tun0",
+	}
+	filter := &recordingEgressFilter{}
+
+	err = config.SetEgressFilter(filter)
+	require.NoError(t, err)
+	assert.Equal(t, "tun0", filter.vpnInterface)
+	assert.Equal(t, []net.IPNet{*outboundSubnet}, filter.allowed)
+
+	ctx := context.Background()
+	err = config.SetEnabled(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, []net.IPNet{*outboundSubnet}, filter.allowed)
+
+	err = config.SetEnabled(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []net.IPNet{*outboundSubnet}, filter.allowed)
+}
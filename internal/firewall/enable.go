@@ -24,6 +24,9 @@ func (c *Config) SetEnabled(ctx context.Context, enabled bool) (err error) {
 			return fmt.Errorf("disabling firewall: %w", err)
 		}
 		c.enabled = false
+		if err = c.updateEgressFilter(); err != nil {
+			return err
+		}
 		c.logger.Info("disabled successfully")
 		return nil
 	}
@@ -34,15 +37,44 @@ func (c *Config) SetEnabled(ctx context.Context, enabled bool) (err error) {
 		return fmt.Errorf("enabling firewall: %w", err)
 	}
 	c.enabled = true
+	if err = c.updateEgressFilter(); err != nil {
+		return err
+	}
 	c.logger.Info("enabled successfully")
 
 	return nil
 }
 
 func (c *Config) disable(ctx context.Context) (err error) {
+	// NAT and mangle rules are not flushed, so the port forward
+	// target, bypass mark, gateway and tunnels rules are removed
+	// explicitly.
+	const remove = true
+	if err = c.runUserRules(ctx, c.portForwardTargetApplied, remove); err != nil {
+		return fmt.Errorf("removing port forward target rules: %w", err)
+	}
+	c.portForwardTargetApplied = nil
+	if err = c.runUserRules(ctx, c.bypassMarkApplied, remove); err != nil {
+		return fmt.Errorf("removing bypass mark rules: %w", err)
+	}
+	c.bypassMarkApplied = nil
+	if err = c.runUserRules(ctx, c.gatewayApplied, remove); err != nil {
+		return fmt.Errorf("removing gateway rules: %w", err)
+	}
+	c.gatewayApplied = nil
+	if err = c.runUserRules(ctx, c.tunnelsApplied, remove); err != nil {
+		return fmt.Errorf("removing tunnels rules: %w", err)
+	}
+	c.tunnelsApplied = nil
+	if c.startupPermissiveTimer != nil { // rules are flushed below
+		c.startupPermissiveTimer.Stop()
+		c.startupPermissiveTimer = nil
+	}
 	if err = c.clearAllRules(ctx); err != nil {
 		return fmt.Errorf("clearing all rules: %w", err)
 	}
+	c.rulesTemplateApplied = nil
+	c.dropLogRulesAdded = false
 	if err = c.setIPv4AllPolicies(ctx, "ACCEPT"); err != nil {
 		return fmt.Errorf("setting ipv4 policies: %w", err)
 	}
@@ -63,11 +95,68 @@ func (c *Config) fallbackToDisabled(ctx context.Context) {
 }
 
 func (c *Config) enable(ctx context.Context) (err error) {
-	touched := false
+	// The rule set is applied atomically, so nothing was changed
+	// if it fails to be applied.
+	err = c.applyRuleSet(ctx, func(ctx context.Context) (err error) {
+		if err = c.buildEnableRules(ctx); err != nil {
+			return err
+		}
+		// Drop log rules must be last to only log packets not matching
+		// any other rule, see keepDropLogRuleLast.
+		return c.addDropLogRules(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	c.dropLogRulesAdded = c.dropLogging
+
+	defer func() {
+		if err != nil {
+			c.fallbackToDisabled(ctx)
+		}
+	}()
+
+	const remove = false
+	if err := c.runUserPostRules(ctx, c.customRulesPath, remove); err != nil {
+		return fmt.Errorf("running user defined post firewall rules: %w", err)
+	}
+
+	c.rulesTemplateApplied = nil
+	if err := c.applyRulesTemplate(ctx); err != nil {
+		return err
+	}
+
+	c.portForwardTargetApplied = nil
+	if err := c.applyPortForwardTarget(ctx); err != nil {
+		return err
+	}
+
+	c.bypassMarkApplied = nil
+	if err := c.applyBypassMark(ctx); err != nil {
+		return err
+	}
+
+	c.gatewayApplied = nil
+	if err := c.applyGateway(ctx); err != nil {
+		return err
+	}
+
+	c.tunnelsApplied = nil
+	if err := c.applyTunnels(ctx); err != nil {
+		return err
+	}
+
+	if err := c.applyStartupPolicy(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Config) buildEnableRules(ctx context.Context) (err error) {
 	if err = c.setIPv4AllPolicies(ctx, "DROP"); err != nil {
 		return err
 	}
-	touched = true
 
 	if err = c.setIPv6AllPolicies(ctx, "DROP"); err != nil {
 		return err
@@ -75,12 +164,6 @@ func (c *Config) enable(ctx context.Context) (err error) {
 
 	const remove = false
 
-	defer func() {
-		if touched && err != nil {
-			c.fallbackToDisabled(ctx)
-		}
-	}()
-
 	// Loopback traffic
 	if err = c.acceptInputThroughInterface(ctx, "lo", remove); err != nil {
 		return err
@@ -98,7 +181,7 @@ func (c *Config) enable(ctx context.Context) (err error) {
 	}
 
 	for _, network := range c.localNetworks {
-		if err := c.acceptOutputFromIPToSubnet(ctx, network.InterfaceName, network.IP, *network.IPNet, remove); err != nil {
+		if err = c.acceptOutputToLocalNetwork(ctx, network, remove); err != nil {
 			return err
 		}
 		if err = c.acceptIpv6MulticastOutput(ctx, network.InterfaceName, remove); err != nil {
@@ -106,10 +189,30 @@ func (c *Config) enable(ctx context.Context) (err error) {
 		}
 	}
 
-	if err = c.allowOutboundSubnets(ctx); err != nil {
+	if !c.preTunnelRestricted() {
+		if err = c.allowOutboundSubnets(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = c.acceptBypassIPs(ctx, c.bypassIPs, remove); err != nil {
+		return fmt.Errorf("accepting bypass IP addresses: %w", err)
+	}
+
+	if err = c.acceptBypassMark(ctx); err != nil {
 		return err
 	}
 
+	if err = c.acceptOutputOutsideVPNRoutes(ctx); err != nil {
+		return err
+	}
+
+	if len(c.dnsInputSubnets) > 0 {
+		if err = c.restrictDNSInput(ctx, c.dnsInputSubnets, "--append"); err != nil {
+			return fmt.Errorf("restricting DNS input: %w", err)
+		}
+	}
+
 	// Allows packets from any IP address to go through eth0 / local network
 	// to reach Gluetun.
 	for _, network := range c.localNetworks {
@@ -118,15 +221,11 @@ func (c *Config) enable(ctx context.Context) (err error) {
 		}
 	}
 
-	if err = c.allowInputPorts(ctx); err != nil {
+	if err = c.restrictSources(ctx); err != nil {
 		return err
 	}
 
-	if err := c.runUserPostRules(ctx, c.customRulesPath, remove); err != nil {
-		return fmt.Errorf("running user defined post firewall rules: %w", err)
-	}
-
-	return nil
+	return c.allowInputPorts(ctx)
 }
 
 func (c *Config) allowVPNIP(ctx context.Context) (err error) {
@@ -142,6 +241,14 @@ func (c *Config) allowVPNIP(ctx context.Context) (err error) {
 		}
 	}
 
+	for _, hopConnection := range c.vpnHopConnections {
+		err = c.acceptOutputTrafficToVPN(ctx, hopConnection.Interface,
+			hopConnection.Connection, remove)
+		if err != nil {
+			return fmt.Errorf("accepting output traffic through VPN hop: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -170,5 +277,19 @@ func (c *Config) allowInputPorts(ctx context.Context) (err error) {
 			}
 		}
 	}
+
+	for port, temporary := range c.temporaryPorts {
+		for _, netInterface := range temporary.interfaces {
+			if _, allowed := c.allowedInputPorts[port][netInterface]; allowed {
+				continue
+			}
+			const remove = false
+			err = c.acceptInputToPort(ctx, netInterface, port, remove)
+			if err != nil {
+				return fmt.Errorf("accepting temporary input port %d on interface %s: %w",
+					port, netInterface, err)
+			}
+		}
+	}
 	return nil
 }
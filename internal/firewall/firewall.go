@@ -4,6 +4,8 @@ import (
 	"context"
 	"net"
 	"sync"
+	"text/template"
+	"time"
 
 	"github.com/qdm12/gluetun/internal/models"
 	"github.com/qdm12/gluetun/internal/routing"
@@ -11,49 +13,133 @@ import (
 )
 
 type Config struct { //nolint:maligned
-	runner         command.Runner
-	logger         Logger
-	iptablesMutex  sync.Mutex
-	ip6tablesMutex sync.Mutex
-	defaultRoutes  []routing.DefaultRoute
-	localNetworks  []routing.LocalNetwork
+	logger        Logger
+	backend       backend
+	defaultRoutes []routing.DefaultRoute
+	localNetworks []routing.LocalNetwork
 
 	// Fixed state
-	ipTables        string
-	ip6Tables       string
 	customRulesPath string
+	preTunnelStrict bool
+	dropLogging     bool
+	dropLogGroup    uint16
+	// lanSourceMatch and httpProxySourceMatch are the iptables
+	// matches of packets not from the allowed sources, see
+	// SetSourcePolicies.
+	lanSourceMatch       string
+	httpProxySourceMatch string
+	httpProxyPort        uint16
+	// startupPermissiveDuration is the duration direct egress traffic
+	// is allowed for at startup, and 0 for the strict startup policy.
+	startupPermissiveDuration time.Duration
 
 	// State
 	enabled           bool
 	vpnConnection     models.Connection
 	vpnIntf           string
+	vpnHopConnections []HopConnection
+	vpnOutputPorts    []uint16
 	outboundSubnets   []net.IPNet
 	allowedInputPorts map[uint16]map[string]struct{} // port to interfaces set mapping
-	stateMutex        sync.Mutex
+	dnsInputSubnets   []net.IPNet
+	tcpRedirect       tcpRedirect
+	// vpnOutputIPs are the only destination IP addresses allowed
+	// through the VPN interface, together with vpnOutputDNSServers,
+	// if vpnOutputIPsRestricted is true.
+	vpnOutputIPs           []net.IP
+	vpnOutputDNSServers    []net.IP
+	vpnOutputIPsRestricted bool
+	// countryNetworks are the networks blocked through the VPN
+	// interface, or the only ones allowed with the countryDNSServers
+	// if countryNetworksAllowed is true, see SetCountryNetworks.
+	countryNetworks        []net.IPNet
+	countryNetworksAllowed bool
+	countryDNSServers      []net.IP
+	// bypassIPs are the destination IP addresses accepted through
+	// the default route interfaces, see SetBypassIPs.
+	bypassIPs []net.IP
+	// bypassMark is the mark of the traffic accepted through the default
+	// route interfaces, bypassCgroups are the cgroups whose traffic is
+	// marked with it, and bypassMarkApplied are the mangle and nat rules
+	// currently applied, see SetBypassMark.
+	bypassMark        uint32
+	bypassCgroups     []string
+	bypassMarkApplied []string
+	// vpnRoutes are the only destinations routed through the VPN,
+	// see SetVPNRoutes.
+	vpnRoutes []net.IPNet
+	// temporaryPorts maps input ports opened for a limited
+	// time to their expiration, see OpenTemporaryPort.
+	temporaryPorts map[uint16]*temporaryPort
+	// rulesTemplate are the parsed lines of the user rules template,
+	// and rulesTemplateApplied are the rules rendered from it currently
+	// applied, see SetRulesTemplateFile.
+	rulesTemplate        []*template.Template
+	rulesTemplateApplied []string
+	// forwardedPorts are the ports forwarded by the VPN provider,
+	// see SetForwardedPorts.
+	forwardedPorts []uint16
+	// portForwardTargetIP and portForwardTargetPort are the address
+	// the forwarded ports are DNATed to, and portForwardTargetApplied
+	// are the rules currently applied, see SetPortForwardTarget.
+	portForwardTargetIP      net.IP
+	portForwardTargetPort    uint16
+	portForwardTargetApplied []string
+	// gatewayIntfs are the network interfaces other devices use gluetun
+	// as their gateway through, and gatewayApplied are the rules
+	// currently applied, see SetGatewayInterfaces.
+	gatewayIntfs   []string
+	gatewayApplied []string
+	// tunnels are the additional tunnels running alongside the VPN
+	// connection, and tunnelsApplied are the rules currently applied,
+	// see SetTunnels.
+	tunnels        []Tunnel
+	tunnelsApplied []string
+	// dropLogRulesAdded is true if the NFLOG rules are
+	// at the end of the filter chains, see SetDropLogGroup.
+	dropLogRulesAdded bool
+	// tunnelUp is true once the VPN tunnel has been up once,
+	// and is used to relax the pre-tunnel egress restrictions.
+	tunnelUp bool
+	// startupPolicyApplied is true once the startup policy was applied,
+	// and startupPermissiveTimer is set while direct egress traffic is
+	// allowed by the permissive startup policy, see SetStartupPermissive.
+	startupPolicyApplied   bool
+	startupPermissiveTimer *time.Timer
+	// egressFilter is an optional additional egress filter, and
+	// egressFilterVPNIntf is the VPN interface it allows.
+	egressFilter        EgressFilter
+	egressFilterVPNIntf string
+	stateMutex          sync.Mutex
+
+	// ruleSet is set while building a rule set to apply in one shot,
+	// see applyRuleSet.
+	ruleSet *ruleSet
 }
 
 // NewConfig creates a new Config instance and returns an error
-// if no iptables implementation is available.
+// if the firewall backend given is not available. The backend can be
+// "iptables", "nftables", "ebpf" or "auto" to pick the one available.
+// If preTunnelStrict is true, egress traffic is restricted until
+// the VPN tunnel is up, see SetTunnelUp.
 func NewConfig(ctx context.Context, logger Logger,
-	runner command.Runner, defaultRoutes []routing.DefaultRoute,
-	localNetworks []routing.LocalNetwork) (config *Config, err error) {
-	iptables, err := checkIptablesSupport(ctx, runner, "iptables", "iptables-nft")
-	if err != nil {
-		return nil, err
-	}
-
-	ip6tables, err := findIP6tablesSupported(ctx, runner)
+	runner command.Runner, backendName string,
+	defaultRoutes []routing.DefaultRoute,
+	localNetworks []routing.LocalNetwork, preTunnelStrict bool) (
+	config *Config, err error) {
+	backend, err := newBackend(ctx, backendName, logger, runner)
 	if err != nil {
 		return nil, err
 	}
+	logger.Info("using " + backend.name() + " backend")
 
 	return &Config{
-		runner:            runner,
 		logger:            logger,
+		backend:           backend,
 		allowedInputPorts: make(map[uint16]map[string]struct{}),
-		ipTables:          iptables,
-		ip6Tables:         ip6tables,
+		temporaryPorts:    make(map[uint16]*temporaryPort),
 		customRulesPath:   "/iptables/post-rules.txt",
+		preTunnelStrict:   preTunnelStrict,
 		// Obtained from routing
 		defaultRoutes: defaultRoutes,
 		localNetworks: localNetworks,
@@ -0,0 +1,86 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+)
+
+// SetGatewayInterfaces sets the network interfaces through which other
+// devices use gluetun as their gateway, such as a LAN interface or the
+// Wireguard server interface. Their IPv4 traffic is forwarded and
+// masqueraded through the VPN interface only, and their DNS traffic is
+// redirected to the local DNS server. No interface disables the gateway
+// mode. It must be called before the firewall is enabled.
+func (c *Config) SetGatewayInterfaces(intfs []string) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	c.gatewayIntfs = make([]string, len(intfs))
+	copy(c.gatewayIntfs, intfs)
+}
+
+// updateGateway replaces the gateway rules applied if the firewall
+// is enabled. It must be called with the state mutex locked.
+func (c *Config) updateGateway(ctx context.Context) (err error) {
+	if !c.enabled {
+		return nil
+	}
+	return c.applyGateway(ctx)
+}
+
+func (c *Config) applyGateway(ctx context.Context) (err error) {
+	rules := c.renderGatewayRules()
+	if stringsEqual(rules, c.gatewayApplied) {
+		return nil
+	}
+
+	const remove = true
+	err = c.runUserRules(ctx, c.gatewayApplied, remove)
+	if err != nil {
+		return fmt.Errorf("removing previous gateway rules: %w", err)
+	}
+	c.gatewayApplied = nil
+
+	err = c.runUserRules(ctx, rules, !remove)
+	if err != nil {
+		return fmt.Errorf("adding gateway rules: %w", err)
+	}
+	c.gatewayApplied = rules
+	return nil
+}
+
+// renderGatewayRules returns the rules to accept and redirect DNS
+// traffic coming in through the gateway interfaces to the local DNS
+// server and, if the VPN interface is set, to forward and masquerade
+// the traffic coming in through the gateway interfaces through the VPN
+// interface. It returns no rule if no gateway interface is set.
+func (c *Config) renderGatewayRules() (rules []string) {
+	if len(c.gatewayIntfs) == 0 {
+		return nil
+	}
+
+	for _, intf := range c.gatewayIntfs {
+		for _, protocol := range []string{"udp", "tcp"} {
+			rules = append(rules,
+				fmt.Sprintf("iptables --append INPUT -i %s -p %s --dport 53 -j ACCEPT",
+					intf, protocol),
+				fmt.Sprintf("iptables -t nat --append PREROUTING -i %s -p %s --dport 53 -j REDIRECT --to-ports 53",
+					intf, protocol),
+			)
+		}
+	}
+
+	if c.vpnIntf == "" {
+		return rules
+	}
+
+	for _, intf := range c.gatewayIntfs {
+		rules = append(rules,
+			fmt.Sprintf("iptables --append FORWARD -i %s -o %s -j ACCEPT",
+				intf, c.vpnIntf),
+			fmt.Sprintf("iptables --append FORWARD -i %s -o %s -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+				c.vpnIntf, intf),
+		)
+	}
+	return append(rules,
+		fmt.Sprintf("iptables -t nat --append POSTROUTING -o %s -j MASQUERADE", c.vpnIntf))
+}
@@ -0,0 +1,51 @@
+package firewall
+
+import (
+	"testing"
+
+	"github.com/google/nftables"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Config_renderGatewayRules(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{}
+	assert.Empty(t, config.renderGatewayRules())
+
+	config.SetGatewayInterfaces([]string{"eth1"})
+	dnsRules := []string{
+		"iptables --append INPUT -i eth1 -p udp --dport 53 -j ACCEPT",
+		"iptables -t nat --append PREROUTING -i eth1 -p udp --dport 53 -j REDIRECT --to-ports 53",
+		"iptables --append INPUT -i eth1 -p tcp --dport 53 -j ACCEPT",
+		"iptables -t nat --append PREROUTING -i eth1 -p tcp --dport 53 -j REDIRECT --to-ports 53",
+	}
+	assert.Equal(t, dnsRules, config.renderGatewayRules())
+
+	config.vpnIntf = "tun0"
+	expected := append(dnsRules, //nolint:gocritic
+		"iptables --append FORWARD -i eth1 -o tun0 -j ACCEPT",
+		"iptables --append FORWARD -i tun0 -o eth1 -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+		"iptables -t nat --append POSTROUTING -o tun0 -j MASQUERADE",
+	)
+	assert.Equal(t, expected, config.renderGatewayRules())
+
+	config.SetGatewayInterfaces([]string{"eth1", "wg1"})
+	expected = []string{
+		"iptables --append INPUT -i eth1 -p udp --dport 53 -j ACCEPT",
+		"iptables -t nat --append PREROUTING -i eth1 -p udp --dport 53 -j REDIRECT --to-ports 53",
+		"iptables --append INPUT -i eth1 -p tcp --dport 53 -j ACCEPT",
+		"iptables -t nat --append PREROUTING -i eth1 -p tcp --dport 53 -j REDIRECT --to-ports 53",
+		"iptables --append INPUT -i wg1 -p udp --dport 53 -j ACCEPT",
+		"iptables -t nat --append PREROUTING -i wg1 -p udp --dport 53 -j REDIRECT --to-ports 53",
+		"iptables --append INPUT -i wg1 -p tcp --dport 53 -j ACCEPT",
+		"iptables -t nat --append PREROUTING -i wg1 -p tcp --dport 53 -j REDIRECT --to-ports 53",
+		"iptables --append FORWARD -i eth1 -o tun0 -j ACCEPT",
+		"iptables --append FORWARD -i tun0 -o eth1 -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+		"iptables --append FORWARD -i wg1 -o tun0 -j ACCEPT",
+		"iptables --append FORWARD -i tun0 -o wg1 -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+		"iptables -t nat --append POSTROUTING -o tun0 -j MASQUERADE",
+	}
+	assert.Equal(t, expected, config.renderGatewayRules())
+	assertNftablesTranslatable(t, expected, nftables.TableFamilyIPv4)
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os/exec"
-	"strings"
 
 	"github.com/qdm12/golibs/command"
 )
@@ -34,21 +32,17 @@ func (c *Config) runIP6tablesInstructions(ctx context.Context, instructions []st
 }
 
 func (c *Config) runIP6tablesInstruction(ctx context.Context, instruction string) error {
-	if c.ip6Tables == "" {
+	if !c.backend.supportsIPv6() {
 		return nil
 	}
-	c.ip6tablesMutex.Lock() // only one ip6tables command at once
-	defer c.ip6tablesMutex.Unlock()
-
-	c.logger.Debug(c.ip6Tables + " " + instruction)
-
-	flags := strings.Fields(instruction)
-	cmd := exec.CommandContext(ctx, c.ip6Tables, flags...) // #nosec G204
-	if output, err := c.runner.Run(cmd); err != nil {
-		return fmt.Errorf("command failed: \"%s %s\": %s: %w",
-			c.ip6Tables, instruction, output, err)
+	const ipv6 = true
+	if c.recordInstruction(instruction, ipv6) {
+		return nil
 	}
-	return nil
+	if err := c.backend.run(ctx, instruction, ipv6); err != nil {
+		return err
+	}
+	return c.keepDropLogRuleLast(ctx, instruction, ipv6)
 }
 
 var ErrPolicyNotValid = errors.New("policy is not valid")
@@ -30,6 +30,17 @@ func appendOrDelete(remove bool) string {
 // flipRule changes an append rule in a delete rule or a delete rule into an
 // append rule.
 func flipRule(rule string) string {
+	for _, tableOption := range []string{"-t ", "--table "} {
+		if !strings.HasPrefix(rule, tableOption) {
+			continue
+		}
+		const parts = 3 // table option, table name and rule
+		fields := strings.SplitN(rule, " ", parts)
+		if len(fields) == parts {
+			return fields[0] + " " + fields[1] + " " + flipRule(fields[2])
+		}
+	}
+
 	switch {
 	case strings.HasPrefix(rule, "-A"):
 		return strings.Replace(rule, "-A", "-D", 1)
@@ -68,16 +79,32 @@ func (c *Config) runIptablesInstructions(ctx context.Context, instructions []str
 }
 
 func (c *Config) runIptablesInstruction(ctx context.Context, instruction string) error {
-	c.iptablesMutex.Lock() // only one iptables command at once
-	defer c.iptablesMutex.Unlock()
+	const ipv6 = false
+	if c.recordInstruction(instruction, ipv6) {
+		return nil
+	}
+	if err := c.backend.run(ctx, instruction, ipv6); err != nil {
+		return err
+	}
+	return c.keepDropLogRuleLast(ctx, instruction, ipv6)
+}
+
+func (i *iptablesBackend) run(ctx context.Context, instruction string, ipv6 bool) error {
+	path, mutex := i.ipTables, &i.iptablesMutex
+	if ipv6 {
+		path, mutex = i.ip6Tables, &i.ip6tablesMutex
+	}
 
-	c.logger.Debug(c.ipTables + " " + instruction)
+	mutex.Lock() // only one iptables command at once
+	defer mutex.Unlock()
+
+	i.logger.Debug(path + " " + instruction)
 
 	flags := strings.Fields(instruction)
-	cmd := exec.CommandContext(ctx, c.ipTables, flags...) // #nosec G204
-	if output, err := c.runner.Run(cmd); err != nil {
+	cmd := exec.CommandContext(ctx, path, flags...) // #nosec G204
+	if output, err := i.runner.Run(cmd); err != nil {
 		return fmt.Errorf("command failed: \"%s %s\": %s: %w",
-			c.ipTables, instruction, output, err)
+			path, instruction, output, err)
 	}
 	return nil
 }
@@ -122,7 +149,7 @@ func (c *Config) acceptInputToSubnet(ctx context.Context, intf string, destinati
 	if isIP4Subnet {
 		return c.runIptablesInstruction(ctx, instruction)
 	}
-	if c.ip6Tables == "" {
+	if !c.backend.supportsIPv6() {
 		return fmt.Errorf("accept input to subnet %s: %w", destination, ErrNeedIP6Tables)
 	}
 	return c.runIP6tablesInstruction(ctx, instruction)
@@ -149,7 +176,7 @@ func (c *Config) acceptOutputTrafficToVPN(ctx context.Context,
 	isIPv4 := connection.IP.To4() != nil
 	if isIPv4 {
 		return c.runIptablesInstruction(ctx, instruction)
-	} else if c.ip6Tables == "" {
+	} else if !c.backend.supportsIPv6() {
 		return fmt.Errorf("accept output to VPN server: %w", ErrNeedIP6Tables)
 	}
 	return c.runIP6tablesInstruction(ctx, instruction)
@@ -170,12 +197,38 @@ func (c *Config) acceptOutputFromIPToSubnet(ctx context.Context,
 
 	if doIPv4 {
 		return c.runIptablesInstruction(ctx, instruction)
-	} else if c.ip6Tables == "" {
+	} else if !c.backend.supportsIPv6() {
 		return fmt.Errorf("accept output from %s to %s: %w", sourceIP, destinationSubnet, ErrNeedIP6Tables)
 	}
 	return c.runIP6tablesInstruction(ctx, instruction)
 }
 
+// acceptOutputDNSFromIPToSubnet only accepts DNS traffic, and is used
+// instead of acceptOutputFromIPToSubnet before the VPN tunnel is up.
+func (c *Config) acceptOutputDNSFromIPToSubnet(ctx context.Context,
+	intf string, sourceIP net.IP, destinationSubnet net.IPNet, remove bool) error {
+	doIPv4 := sourceIP.To4() != nil && destinationSubnet.IP.To4() != nil
+
+	interfaceFlag := "-o " + intf
+	if intf == "*" { // all interfaces
+		interfaceFlag = ""
+	}
+
+	instructions := []string{
+		fmt.Sprintf("%s OUTPUT %s -s %s -d %s -p udp -m udp --dport 53 -j ACCEPT",
+			appendOrDelete(remove), interfaceFlag, sourceIP.String(), destinationSubnet.String()),
+		fmt.Sprintf("%s OUTPUT %s -s %s -d %s -p tcp -m tcp --dport 53 -j ACCEPT",
+			appendOrDelete(remove), interfaceFlag, sourceIP.String(), destinationSubnet.String()),
+	}
+
+	if doIPv4 {
+		return c.runIptablesInstructions(ctx, instructions)
+	} else if !c.backend.supportsIPv6() {
+		return fmt.Errorf("accept DNS output from %s to %s: %w", sourceIP, destinationSubnet, ErrNeedIP6Tables)
+	}
+	return c.runIP6tablesInstructions(ctx, instructions)
+}
+
 // NDP uses multicast address (theres no broadcast in IPv6 like ARP uses in IPv4).
 func (c *Config) acceptIpv6MulticastOutput(ctx context.Context,
 	intf string, remove bool) error {
@@ -216,6 +269,13 @@ func (c *Config) runUserPostRules(ctx context.Context, filepath string, remove b
 		return err
 	}
 	lines := strings.Split(string(b), "\n")
+	return c.runUserRules(ctx, lines, remove)
+}
+
+// runUserRules runs the user rule lines given, each prefixed with
+// iptables or ip6tables, and ignores any other line. Rules already
+// run are reverted if a rule fails.
+func (c *Config) runUserRules(ctx context.Context, lines []string, remove bool) (err error) {
 	successfulRules := []string{}
 	defer func() {
 		// transaction-like rollback
@@ -253,7 +313,7 @@ func (c *Config) runUserPostRules(ctx context.Context, filepath string, remove b
 		switch {
 		case ipv4:
 			err = c.runIptablesInstruction(ctx, rule)
-		case c.ip6Tables == "":
+		case !c.backend.supportsIPv6():
 			err = fmt.Errorf("running user ip6tables rule: %w", ErrNeedIP6Tables)
 		default: // ipv6
 			err = c.runIP6tablesInstruction(ctx, rule)
@@ -0,0 +1,699 @@
+package firewall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	ErrNftablesNotSupported   = errors.New("nftables is not supported")
+	ErrInstructionUnsupported = errors.New("instruction is not supported by the nftables backend")
+	ErrChainUnsupported       = errors.New("chain is not supported by the nftables backend")
+	ErrRuleNotFound           = errors.New("rule not found")
+)
+
+// nftablesTableName is the name of the nftables tables
+// created for the ip and ip6 families.
+const nftablesTableName = "gluetun"
+
+// nftablesBackend translates iptables instructions to nftables rules,
+// and applies them using netlink without running any program.
+// Rules are placed in base chains named after the iptables chains,
+// in a table created for each of the ip and ip6 families.
+type nftablesBackend struct {
+	logger Logger
+	mutex  sync.Mutex
+	ipv6   bool
+}
+
+func newNftablesBackend(logger Logger) (b *nftablesBackend, err error) {
+	b = &nftablesBackend{
+		logger: logger,
+	}
+
+	conn := &nftables.Conn{}
+	b.ensureChains(conn, nftables.TableFamilyIPv4)
+	err = conn.Flush()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNftablesNotSupported, err)
+	}
+
+	conn = &nftables.Conn{}
+	b.ensureChains(conn, nftables.TableFamilyIPv6)
+	err = conn.Flush()
+	if err != nil {
+		logger.Debug("nftables ip6 family is not supported: " + err.Error())
+	} else {
+		b.ipv6 = true
+	}
+
+	return b, nil
+}
+
+func (n *nftablesBackend) supportsIPv6() bool {
+	return n.ipv6
+}
+
+func (n *nftablesBackend) name() string {
+	return "nftables"
+}
+
+func (n *nftablesBackend) run(_ context.Context, instruction string, ipv6 bool) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	family := nftables.TableFamilyIPv4
+	if ipv6 {
+		family = nftables.TableFamilyIPv6
+	}
+
+	conn := &nftables.Conn{}
+	n.ensureChains(conn, family)
+	err := n.queue(conn, family, instruction)
+	if err != nil {
+		return err
+	}
+
+	err = conn.Flush()
+	if err != nil {
+		return fmt.Errorf("applying nftables instruction %q: %w", instruction, err)
+	}
+	return nil
+}
+
+// applyAtomically applies the IPv4 and IPv6 instructions given in a
+// single nftables transaction, so nothing is changed on failure.
+func (n *nftablesBackend) applyAtomically(_ context.Context,
+	ipv4, ipv6 []string) (err error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	conn := &nftables.Conn{}
+	n.ensureChains(conn, nftables.TableFamilyIPv4)
+	for _, instruction := range ipv4 {
+		err = n.queue(conn, nftables.TableFamilyIPv4, instruction)
+		if err != nil {
+			return err
+		}
+	}
+
+	if n.ipv6 && len(ipv6) > 0 {
+		n.ensureChains(conn, nftables.TableFamilyIPv6)
+		for _, instruction := range ipv6 {
+			err = n.queue(conn, nftables.TableFamilyIPv6, instruction)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = conn.Flush()
+	if err != nil {
+		return fmt.Errorf("applying nftables rules: %w", err)
+	}
+	return nil
+}
+
+// ensureChains adds the table and base chains for the family given
+// to the connection batch. This is a no-op for the ones already present.
+func (n *nftablesBackend) ensureChains(conn *nftables.Conn, family nftables.TableFamily) {
+	table := conn.AddTable(&nftables.Table{Name: nftablesTableName, Family: family})
+	for _, key := range nftablesChainKeys {
+		chain, _ := nftablesChain(table, key.table, key.chain)
+		conn.AddChain(chain)
+	}
+}
+
+// queue adds the nftables messages for the iptables instruction given
+// to the connection batch.
+func (n *nftablesBackend) queue(conn *nftables.Conn,
+	family nftables.TableFamily, instruction string) (err error) {
+	n.logger.Debug("nftables " + familyName(family) + " " + instruction)
+
+	parsed, err := parseNftablesInstruction(instruction, family)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, instruction)
+	}
+
+	table := &nftables.Table{Name: nftablesTableName, Family: family}
+	chains := make([]*nftables.Chain, 0, len(nftablesChainKeys))
+	if parsed.chain == "" { // all chains of the table
+		for _, key := range nftablesChainKeys {
+			if key.table != parsed.table {
+				continue
+			}
+			chain, _ := nftablesChain(table, key.table, key.chain)
+			chains = append(chains, chain)
+		}
+	} else {
+		chain, err := nftablesChain(table, parsed.table, parsed.chain)
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, instruction)
+		}
+		chains = append(chains, chain)
+	}
+
+	switch parsed.operation {
+	case nftablesAppend:
+		conn.AddRule(parsed.rule(table, chains[0]))
+	case nftablesInsert:
+		conn.InsertRule(parsed.rule(table, chains[0]))
+	case nftablesDelete:
+		rules, err := conn.GetRules(table, chains[0])
+		if err != nil {
+			return fmt.Errorf("listing nftables rules: %w", err)
+		}
+		for _, rule := range rules {
+			if string(rule.UserData) != parsed.spec {
+				continue
+			}
+			return conn.DelRule(rule)
+		}
+		return fmt.Errorf("%w: %s", ErrRuleNotFound, instruction)
+	case nftablesPolicy:
+		chain := chains[0]
+		chain.Policy = &parsed.policy
+		conn.AddChain(chain)
+	case nftablesFlush:
+		for _, chain := range chains {
+			conn.FlushChain(chain)
+		}
+	case nftablesDeleteChain:
+		// Only base chains are used and they are kept.
+	}
+	return nil
+}
+
+func familyName(family nftables.TableFamily) string {
+	if family == nftables.TableFamilyIPv6 {
+		return "ip6"
+	}
+	return "ip"
+}
+
+type nftablesChainKey struct {
+	table string
+	chain string
+}
+
+var nftablesChainKeys = []nftablesChainKey{ //nolint:gochecknoglobals
+	{table: "filter", chain: "INPUT"},
+	{table: "filter", chain: "OUTPUT"},
+	{table: "filter", chain: "FORWARD"},
+	{table: "nat", chain: "OUTPUT"},
+	{table: "nat", chain: "PREROUTING"},
+	{table: "nat", chain: "POSTROUTING"},
+	{table: "mangle", chain: "OUTPUT"},
+}
+
+// nftablesChain returns the nftables base chain corresponding
+// to the iptables table and chain given.
+func nftablesChain(table *nftables.Table, iptablesTable, iptablesChain string) (
+	chain *nftables.Chain, err error) {
+	chain = &nftables.Chain{
+		Name:     iptablesChain,
+		Table:    table,
+		Type:     nftables.ChainTypeFilter,
+		Priority: nftables.ChainPriorityFilter,
+	}
+	switch iptablesTable + " " + iptablesChain {
+	case "filter INPUT":
+		chain.Hooknum = nftables.ChainHookInput
+	case "filter OUTPUT":
+		chain.Hooknum = nftables.ChainHookOutput
+	case "filter FORWARD":
+		chain.Hooknum = nftables.ChainHookForward
+	case "nat OUTPUT":
+		chain.Name = "NAT_OUTPUT"
+		chain.Hooknum = nftables.ChainHookOutput
+		chain.Type = nftables.ChainTypeNAT
+		chain.Priority = nftables.ChainPriorityNATDest
+	case "nat PREROUTING":
+		chain.Name = "NAT_PREROUTING"
+		chain.Hooknum = nftables.ChainHookPrerouting
+		chain.Type = nftables.ChainTypeNAT
+		chain.Priority = nftables.ChainPriorityNATDest
+	case "nat POSTROUTING":
+		chain.Name = "NAT_POSTROUTING"
+		chain.Hooknum = nftables.ChainHookPostrouting
+		chain.Type = nftables.ChainTypeNAT
+		chain.Priority = nftables.ChainPriorityNATSource
+	case "mangle OUTPUT":
+		// A route chain re-routes packets whose mark is changed,
+		// like the iptables mangle OUTPUT chain does.
+		chain.Name = "MANGLE_OUTPUT"
+		chain.Hooknum = nftables.ChainHookOutput
+		chain.Type = nftables.ChainTypeRoute
+		chain.Priority = nftables.ChainPriorityMangle
+	default:
+		return nil, fmt.Errorf("%w: %s in table %s",
+			ErrChainUnsupported, iptablesChain, iptablesTable)
+	}
+	return chain, nil
+}
+
+type nftablesOperation uint8
+
+const (
+	nftablesAppend nftablesOperation = iota
+	nftablesInsert
+	nftablesDelete
+	nftablesPolicy
+	nftablesFlush
+	nftablesDeleteChain
+)
+
+type nftablesInstruction struct {
+	operation nftablesOperation
+	table     string
+	// chain is the iptables chain name, and is empty
+	// to flush all the chains of the table.
+	chain  string
+	policy nftables.ChainPolicy
+	exprs  []expr.Any
+	// spec is the rule specification in the iptables format, and is
+	// stored in the rule user data to find the rule to delete.
+	spec string
+}
+
+func (i nftablesInstruction) rule(table *nftables.Table,
+	chain *nftables.Chain) *nftables.Rule {
+	return &nftables.Rule{
+		Table:    table,
+		Chain:    chain,
+		Exprs:    i.exprs,
+		UserData: []byte(i.spec),
+	}
+}
+
+// parseNftablesInstruction parses the iptables instruction given into
+// an nftables operation for the family given. Only the iptables options
+// used by this package are supported.
+func parseNftablesInstruction(instruction string, family nftables.TableFamily) (
+	parsed nftablesInstruction, err error) {
+	tableName, fields := extractTable(strings.Fields(instruction))
+	parsed.table = tableName
+	if len(fields) == 0 {
+		return parsed, ErrInstructionUnsupported
+	}
+
+	operation, fields := fields[0], fields[1:]
+	switch operation {
+	case "-F", "--flush", "-X", "--delete-chain":
+		parsed.operation = nftablesFlush
+		if operation == "-X" || operation == "--delete-chain" {
+			parsed.operation = nftablesDeleteChain
+		}
+		switch len(fields) {
+		case 0:
+		case 1:
+			parsed.chain = fields[0]
+		default:
+			return parsed, ErrInstructionUnsupported
+		}
+		return parsed, nil
+	case "-P", "--policy":
+		const policyFields = 2
+		if len(fields) != policyFields {
+			return parsed, ErrInstructionUnsupported
+		}
+		parsed.operation = nftablesPolicy
+		parsed.chain = fields[0]
+		switch fields[1] {
+		case "ACCEPT":
+			parsed.policy = nftables.ChainPolicyAccept
+		case "DROP":
+			parsed.policy = nftables.ChainPolicyDrop
+		default:
+			return parsed, fmt.Errorf("%w: %s", ErrPolicyUnknown, fields[1])
+		}
+		return parsed, nil
+	case "-A", "--append":
+		parsed.operation = nftablesAppend
+	case "-I", "--insert":
+		parsed.operation = nftablesInsert
+	case "-D", "--delete":
+		parsed.operation = nftablesDelete
+	default:
+		return parsed, ErrInstructionUnsupported
+	}
+
+	if len(fields) == 0 {
+		return parsed, ErrInstructionUnsupported
+	}
+	parsed.chain, fields = fields[0], fields[1:]
+	if parsed.operation == nftablesInsert && len(fields) > 0 && fields[0] == "1" {
+		fields = fields[1:] // inserting at the first position is the default
+	}
+	parsed.spec = strings.Join(fields, " ")
+
+	parsed.exprs, err = ruleToExprs(fields, family)
+	if err != nil {
+		return parsed, err
+	}
+	return parsed, nil
+}
+
+// ruleToExprs converts the iptables rule specification fields
+// to nftables expressions for the family given.
+//
+//nolint:gocognit,gocyclo
+func ruleToExprs(fields []string, family nftables.TableFamily) (
+	exprs []expr.Any, err error) {
+	var protocol string
+	var verdict *expr.Verdict
+	var log *expr.Log
+	// targetExprs are the expressions of the REDIRECT, MASQUERADE,
+	// DNAT and MARK targets, set once their option is parsed.
+	var target string
+	var targetExprs []expr.Any
+	for i := 0; i < len(fields); i++ {
+		option := fields[i]
+		if i+1 == len(fields) {
+			return nil, fmt.Errorf("%w: option %s has no value", ErrInstructionUnsupported, option)
+		}
+		i++
+		value := fields[i]
+
+		negate := option == "!"
+		if negate {
+			if i+1 == len(fields) {
+				return nil, fmt.Errorf("%w: option %s has no value", ErrInstructionUnsupported, value)
+			}
+			option = value
+			i++
+			value = fields[i]
+			if option != "--uid-owner" && option != "--mark" {
+				return nil, fmt.Errorf("%w: negated option %s", ErrInstructionUnsupported, option)
+			}
+		}
+
+		switch option {
+		case "-i", "--in-interface":
+			exprs = append(exprs, interfaceExprs(expr.MetaKeyIIFNAME, value)...)
+		case "-o", "--out-interface":
+			exprs = append(exprs, interfaceExprs(expr.MetaKeyOIFNAME, value)...)
+		case "-s", "--source", "-d", "--destination":
+			source := option == "-s" || option == "--source"
+			addressExprs, err := addressExprs(value, source, family)
+			if err != nil {
+				return nil, err
+			}
+			exprs = append(exprs, addressExprs...)
+		case "-p", "--protocol":
+			protocolNumber, ok := map[string]byte{"tcp": 6, "udp": 17}[value] //nolint:gomnd
+			if !ok {
+				return nil, fmt.Errorf("%w: protocol %s", ErrInstructionUnsupported, value)
+			}
+			protocol = value
+			exprs = append(exprs,
+				&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 1},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{protocolNumber}},
+			)
+		case "--dport", "--destination-port", "--sport", "--source-port":
+			if protocol == "" {
+				return nil, fmt.Errorf("%w: port without protocol", ErrInstructionUnsupported)
+			}
+			port, err := parsePort(value)
+			if err != nil {
+				return nil, err
+			}
+			const sourcePortOffset, destinationPortOffset = 0, 2
+			offset := uint32(destinationPortOffset)
+			if option == "--sport" || option == "--source-port" {
+				offset = sourcePortOffset
+			}
+			exprs = append(exprs,
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: offset, Len: 2},
+				&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: portBytes(port)},
+			)
+		case "-m", "--match":
+			// Matches are implied by the options following them.
+		case "--match-set":
+			if i+1 == len(fields) || fields[i+1] != "dst" {
+				return nil, fmt.Errorf("%w: set %s must be matched on the destination",
+					ErrInstructionUnsupported, value)
+			}
+			i++
+			offset, length := uint32(16), uint32(net.IPv4len) //nolint:gomnd
+			if family == nftables.TableFamilyIPv6 {
+				offset, length = 24, net.IPv6len
+			}
+			exprs = append(exprs,
+				&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader,
+					Offset: offset, Len: length},
+				&expr.Lookup{SourceRegister: 1, SetName: value},
+			)
+		case "--ctstate":
+			var states uint32
+			for _, state := range strings.Split(value, ",") {
+				bit, ok := map[string]uint32{
+					"INVALID":     expr.CtStateBitINVALID,
+					"ESTABLISHED": expr.CtStateBitESTABLISHED,
+					"RELATED":     expr.CtStateBitRELATED,
+					"NEW":         expr.CtStateBitNEW,
+					"UNTRACKED":   expr.CtStateBitUNTRACKED,
+				}[state]
+				if !ok {
+					return nil, fmt.Errorf("%w: connection state %s", ErrInstructionUnsupported, state)
+				}
+				states |= bit
+			}
+			mask := binaryutil.NativeEndian.PutUint32(states)
+			exprs = append(exprs,
+				&expr.Ct{Register: 1, Key: expr.CtKeySTATE},
+				&expr.Bitwise{SourceRegister: 1, DestRegister: 1, Len: 4, Mask: mask, Xor: make([]byte, 4)}, //nolint:gomnd
+				&expr.Cmp{Op: expr.CmpOpNeq, Register: 1, Data: make([]byte, 4)},                            //nolint:gomnd
+			)
+		case "--uid-owner", "--mark":
+			const base, bitSize = 0, 32
+			number, err := strconv.ParseUint(value, base, bitSize)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s %s", ErrInstructionUnsupported, option, value)
+			}
+			key := expr.MetaKeySKUID
+			if option == "--mark" {
+				key = expr.MetaKeyMARK
+			}
+			operator := expr.CmpOpEq
+			if negate {
+				operator = expr.CmpOpNeq
+			}
+			exprs = append(exprs,
+				&expr.Meta{Key: key, Register: 1},
+				&expr.Cmp{Op: operator, Register: 1, Data: binaryutil.NativeEndian.PutUint32(uint32(number))},
+			)
+		case "-j", "--jump":
+			switch value {
+			case "ACCEPT":
+				verdict = &expr.Verdict{Kind: expr.VerdictAccept}
+			case "DROP":
+				verdict = &expr.Verdict{Kind: expr.VerdictDrop}
+			case "RETURN":
+				verdict = &expr.Verdict{Kind: expr.VerdictReturn}
+			case "REDIRECT", "DNAT", "MARK":
+				target = value
+			case "MASQUERADE":
+				target = value
+				targetExprs = []expr.Any{&expr.Masq{}}
+			case "NFLOG":
+				log = &expr.Log{Key: 1<<unix.NFTA_LOG_GROUP | 1<<unix.NFTA_LOG_PREFIX}
+			default:
+				return nil, fmt.Errorf("%w: target %s", ErrInstructionUnsupported, value)
+			}
+		case "--to-ports":
+			if target != "REDIRECT" {
+				return nil, fmt.Errorf("%w: option %s without REDIRECT target", ErrInstructionUnsupported, option)
+			}
+			redirectPort, err := parsePort(value)
+			if err != nil {
+				return nil, err
+			}
+			targetExprs = []expr.Any{
+				&expr.Immediate{Register: 1, Data: portBytes(redirectPort)},
+				&expr.Redir{RegisterProtoMin: 1},
+			}
+		case "--to-destination":
+			if target != "DNAT" {
+				return nil, fmt.Errorf("%w: option %s without DNAT target", ErrInstructionUnsupported, option)
+			}
+			targetExprs, err = destinationNATExprs(value, family)
+			if err != nil {
+				return nil, err
+			}
+		case "--set-mark":
+			if target != "MARK" {
+				return nil, fmt.Errorf("%w: option %s without MARK target", ErrInstructionUnsupported, option)
+			}
+			const base, bitSize = 0, 32
+			mark, err := strconv.ParseUint(value, base, bitSize)
+			if err != nil {
+				return nil, fmt.Errorf("%w: mark %s", ErrInstructionUnsupported, value)
+			}
+			targetExprs = []expr.Any{
+				&expr.Immediate{Register: 1, Data: binaryutil.NativeEndian.PutUint32(uint32(mark))},
+				&expr.Meta{Key: expr.MetaKeyMARK, SourceRegister: true, Register: 1},
+			}
+		case "--path":
+			return nil, fmt.Errorf("%w: cgroup path match %s needs the iptables backend",
+				ErrInstructionUnsupported, value)
+		case "--nflog-group", "--nflog-prefix":
+			if log == nil {
+				return nil, fmt.Errorf("%w: option %s without NFLOG target", ErrInstructionUnsupported, option)
+			}
+			if option == "--nflog-prefix" {
+				log.Data = []byte(value)
+				continue
+			}
+			const base, bitSize = 10, 16
+			group, err := strconv.ParseUint(value, base, bitSize)
+			if err != nil {
+				return nil, fmt.Errorf("%w: NFLOG group %s", ErrInstructionUnsupported, value)
+			}
+			log.Group = uint16(group)
+		default:
+			return nil, fmt.Errorf("%w: option %s", ErrInstructionUnsupported, option)
+		}
+	}
+
+	switch {
+	case verdict != nil:
+		exprs = append(exprs, verdict)
+	case log != nil:
+		exprs = append(exprs, log)
+	case len(targetExprs) > 0:
+		exprs = append(exprs, targetExprs...)
+	default:
+		return nil, fmt.Errorf("%w: no target", ErrInstructionUnsupported)
+	}
+	return exprs, nil
+}
+
+// destinationNATExprs returns the expressions translating the destination
+// to the address and port given in the form ip:port or [ipv6]:port.
+func destinationNATExprs(value string, family nftables.TableFamily) (
+	exprs []expr.Any, err error) {
+	host, portString, err := net.SplitHostPort(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: destination %s", ErrInstructionUnsupported, value)
+	}
+	port, err := parsePort(portString)
+	if err != nil {
+		return nil, err
+	}
+
+	ip := net.ParseIP(host)
+	natFamily := uint32(unix.NFPROTO_IPV4)
+	if family == nftables.TableFamilyIPv6 {
+		natFamily = unix.NFPROTO_IPV6
+		if ip.To4() != nil {
+			ip = nil
+		}
+	} else {
+		ip = ip.To4()
+	}
+	if ip == nil {
+		return nil, fmt.Errorf("%w: destination %s for family %s",
+			ErrInstructionUnsupported, value, familyName(family))
+	}
+
+	return []expr.Any{
+		&expr.Immediate{Register: 1, Data: ip},
+		&expr.Immediate{Register: 2, Data: portBytes(port)}, //nolint:gomnd
+		&expr.NAT{Type: expr.NATTypeDestNAT, Family: natFamily,
+			RegAddrMin: 1, RegProtoMin: 2}, //nolint:gomnd
+	}, nil
+}
+
+// interfaceExprs returns expressions matching the interface name given,
+// where a trailing + matches all interfaces with the name as prefix.
+func interfaceExprs(key expr.MetaKey, name string) []expr.Any {
+	var data []byte
+	if strings.HasSuffix(name, "+") {
+		data = []byte(strings.TrimSuffix(name, "+"))
+	} else {
+		const interfaceNameSize = 16
+		data = make([]byte, interfaceNameSize)
+		copy(data, name)
+	}
+	return []expr.Any{
+		&expr.Meta{Key: key, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: data},
+	}
+}
+
+func addressExprs(value string, source bool, family nftables.TableFamily) (
+	exprs []expr.Any, err error) {
+	var ipNet *net.IPNet
+	if strings.Contains(value, "/") {
+		_, ipNet, err = net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: address %s", ErrInstructionUnsupported, value)
+		}
+	} else {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("%w: address %s", ErrInstructionUnsupported, value)
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			bits = 8 * net.IPv4len
+		}
+		ipNet = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+	}
+
+	ip, mask := ipNet.IP.To4(), ipNet.Mask
+	offset := uint32(12) //nolint:gomnd
+	if !source {
+		offset = 16
+	}
+	if family == nftables.TableFamilyIPv6 {
+		ip = ipNet.IP.To16()
+		offset = 8
+		if !source {
+			offset = 24
+		}
+		if ipNet.IP.To4() != nil {
+			ip = nil
+		}
+	}
+	if ip == nil || len(mask) != len(ip) {
+		return nil, fmt.Errorf("%w: address %s for family %s",
+			ErrInstructionUnsupported, value, familyName(family))
+	}
+
+	exprs = []expr.Any{
+		&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader,
+			Offset: offset, Len: uint32(len(ip))},
+	}
+	ones, bits := mask.Size()
+	if ones != bits {
+		exprs = append(exprs, &expr.Bitwise{SourceRegister: 1, DestRegister: 1,
+			Len: uint32(len(ip)), Mask: mask, Xor: make([]byte, len(ip))})
+	}
+	exprs = append(exprs, &expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: ip.Mask(mask)})
+	return exprs, nil
+}
+
+func parsePort(value string) (port uint16, err error) {
+	const base, bitSize = 10, 16
+	n, err := strconv.ParseUint(value, base, bitSize)
+	if err != nil || n == 0 {
+		return 0, fmt.Errorf("%w: port %s", ErrInstructionUnsupported, value)
+	}
+	return uint16(n), nil
+}
+
+func portBytes(port uint16) []byte {
+	return binaryutil.BigEndian.PutUint16(port)
+}
@@ -0,0 +1,321 @@
+package firewall
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+func Test_parseNftablesInstruction(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		instruction string
+		family      nftables.TableFamily
+		parsed      nftablesInstruction
+		errWrapped  error
+		errMessage  string
+	}{
+		"policy": {
+			instruction: "--policy INPUT DROP",
+			parsed: nftablesInstruction{
+				operation: nftablesPolicy,
+				table:     "filter",
+				chain:     "INPUT",
+				policy:    nftables.ChainPolicyDrop,
+			},
+		},
+		"flush all chains": {
+			instruction: "--flush",
+			parsed: nftablesInstruction{
+				operation: nftablesFlush,
+				table:     "filter",
+			},
+		},
+		"append interface accept": {
+			instruction: "--append OUTPUT -o tun0 -j ACCEPT",
+			parsed: nftablesInstruction{
+				operation: nftablesAppend,
+				table:     "filter",
+				chain:     "OUTPUT",
+				spec:      "-o tun0 -j ACCEPT",
+				exprs: []expr.Any{
+					&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1,
+						Data: []byte{'t', 'u', 'n', '0', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+					&expr.Verdict{Kind: expr.VerdictAccept},
+				},
+			},
+		},
+		"delete ipv4 subnet with port": {
+			instruction: "--delete INPUT -s 192.168.1.0/24 -p udp -m udp --dport 53 -j DROP",
+			family:      nftables.TableFamilyIPv4,
+			parsed: nftablesInstruction{
+				operation: nftablesDelete,
+				table:     "filter",
+				chain:     "INPUT",
+				spec:      "-s 192.168.1.0/24 -p udp -m udp --dport 53 -j DROP",
+				exprs: []expr.Any{
+					&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: 12, Len: 4},
+					&expr.Bitwise{SourceRegister: 1, DestRegister: 1, Len: 4,
+						Mask: []byte{255, 255, 255, 0}, Xor: []byte{0, 0, 0, 0}},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{192, 168, 1, 0}},
+					&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 1},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{17}},
+					&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: 2, Len: 2},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{0, 53}},
+					&expr.Verdict{Kind: expr.VerdictDrop},
+				},
+			},
+		},
+		"insert negated owner": {
+			instruction: "--insert OUTPUT -o lo -m owner ! --uid-owner 1000 -j DROP",
+			parsed: nftablesInstruction{
+				operation: nftablesInsert,
+				table:     "filter",
+				chain:     "OUTPUT",
+				spec:      "-o lo -m owner ! --uid-owner 1000 -j DROP",
+				exprs: []expr.Any{
+					&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1,
+						Data: []byte{'l', 'o', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+					&expr.Meta{Key: expr.MetaKeySKUID, Register: 1},
+					&expr.Cmp{Op: expr.CmpOpNeq, Register: 1, Data: binaryutil.NativeEndian.PutUint32(1000)},
+					&expr.Verdict{Kind: expr.VerdictDrop},
+				},
+			},
+		},
+		"connection state": {
+			instruction: "--append INPUT -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+			parsed: nftablesInstruction{
+				operation: nftablesAppend,
+				table:     "filter",
+				chain:     "INPUT",
+				spec:      "-m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+				exprs: []expr.Any{
+					&expr.Ct{Register: 1, Key: expr.CtKeySTATE},
+					&expr.Bitwise{SourceRegister: 1, DestRegister: 1, Len: 4,
+						Mask: binaryutil.NativeEndian.PutUint32(expr.CtStateBitESTABLISHED | expr.CtStateBitRELATED),
+						Xor:  []byte{0, 0, 0, 0}},
+					&expr.Cmp{Op: expr.CmpOpNeq, Register: 1, Data: []byte{0, 0, 0, 0}},
+					&expr.Verdict{Kind: expr.VerdictAccept},
+				},
+			},
+		},
+		"nat redirect": {
+			instruction: "-t nat --append OUTPUT -p tcp -j REDIRECT --to-ports 8080",
+			parsed: nftablesInstruction{
+				operation: nftablesAppend,
+				table:     "nat",
+				chain:     "OUTPUT",
+				spec:      "-p tcp -j REDIRECT --to-ports 8080",
+				exprs: []expr.Any{
+					&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 1},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{6}},
+					&expr.Immediate{Register: 1, Data: []byte{0x1f, 0x90}},
+					&expr.Redir{RegisterProtoMin: 1},
+				},
+			},
+		},
+		"nat prerouting redirect": {
+			instruction: "-t nat --append PREROUTING -i eth1 -p udp --dport 53 -j REDIRECT --to-ports 53",
+			parsed: nftablesInstruction{
+				operation: nftablesAppend,
+				table:     "nat",
+				chain:     "PREROUTING",
+				spec:      "-i eth1 -p udp --dport 53 -j REDIRECT --to-ports 53",
+				exprs: []expr.Any{
+					&expr.Meta{Key: expr.MetaKeyIIFNAME, Register: 1},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1,
+						Data: []byte{'e', 't', 'h', '1', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+					&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 1},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{17}},
+					&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: 2, Len: 2},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{0, 53}},
+					&expr.Immediate{Register: 1, Data: []byte{0, 53}},
+					&expr.Redir{RegisterProtoMin: 1},
+				},
+			},
+		},
+		"nat prerouting dnat": {
+			instruction: "-t nat --append PREROUTING -i tun0 -p tcp --dport 5000 -j DNAT --to-destination 10.0.0.2:6000",
+			parsed: nftablesInstruction{
+				operation: nftablesAppend,
+				table:     "nat",
+				chain:     "PREROUTING",
+				spec:      "-i tun0 -p tcp --dport 5000 -j DNAT --to-destination 10.0.0.2:6000",
+				exprs: []expr.Any{
+					&expr.Meta{Key: expr.MetaKeyIIFNAME, Register: 1},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1,
+						Data: []byte{'t', 'u', 'n', '0', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+					&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 1},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{6}},
+					&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: 2, Len: 2},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{0x13, 0x88}},
+					&expr.Immediate{Register: 1, Data: []byte{10, 0, 0, 2}},
+					&expr.Immediate{Register: 2, Data: []byte{0x17, 0x70}},
+					&expr.NAT{Type: expr.NATTypeDestNAT, Family: unix.NFPROTO_IPV4,
+						RegAddrMin: 1, RegProtoMin: 2},
+				},
+			},
+		},
+		"ipv6 dnat": {
+			instruction: "-t nat --append PREROUTING -j DNAT --to-destination [::2]:6000",
+			family:      nftables.TableFamilyIPv6,
+			parsed: nftablesInstruction{
+				operation: nftablesAppend,
+				table:     "nat",
+				chain:     "PREROUTING",
+				spec:      "-j DNAT --to-destination [::2]:6000",
+				exprs: []expr.Any{
+					&expr.Immediate{Register: 1,
+						Data: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2}},
+					&expr.Immediate{Register: 2, Data: []byte{0x17, 0x70}},
+					&expr.NAT{Type: expr.NATTypeDestNAT, Family: unix.NFPROTO_IPV6,
+						RegAddrMin: 1, RegProtoMin: 2},
+				},
+			},
+		},
+		"nat postrouting masquerade with mark": {
+			instruction: "-t nat --append POSTROUTING -o eth0 -m mark --mark 0x10 -j MASQUERADE",
+			parsed: nftablesInstruction{
+				operation: nftablesAppend,
+				table:     "nat",
+				chain:     "POSTROUTING",
+				spec:      "-o eth0 -m mark --mark 0x10 -j MASQUERADE",
+				exprs: []expr.Any{
+					&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1,
+						Data: []byte{'e', 't', 'h', '0', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+					&expr.Meta{Key: expr.MetaKeyMARK, Register: 1},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: binaryutil.NativeEndian.PutUint32(0x10)},
+					&expr.Masq{},
+				},
+			},
+		},
+		"mangle set mark": {
+			instruction: "-t mangle --append OUTPUT -p udp --sport 51820 -j MARK --set-mark 0xca6c",
+			parsed: nftablesInstruction{
+				operation: nftablesAppend,
+				table:     "mangle",
+				chain:     "OUTPUT",
+				spec:      "-p udp --sport 51820 -j MARK --set-mark 0xca6c",
+				exprs: []expr.Any{
+					&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 1},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{17}},
+					&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: 0, Len: 2},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{0xca, 0x6c}},
+					&expr.Immediate{Register: 1, Data: binaryutil.NativeEndian.PutUint32(0xca6c)},
+					&expr.Meta{Key: expr.MetaKeyMARK, SourceRegister: true, Register: 1},
+				},
+			},
+		},
+		"ipv6 match set": {
+			instruction: "--insert OUTPUT -o tun0 -m set --match-set gluetun-countries6 dst -j DROP",
+			family:      nftables.TableFamilyIPv6,
+			parsed: nftablesInstruction{
+				operation: nftablesInsert,
+				table:     "filter",
+				chain:     "OUTPUT",
+				spec:      "-o tun0 -m set --match-set gluetun-countries6 dst -j DROP",
+				exprs: []expr.Any{
+					&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1,
+						Data: []byte{'t', 'u', 'n', '0', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+					&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: 24, Len: 16},
+					&expr.Lookup{SourceRegister: 1, SetName: "gluetun-countries6"},
+					&expr.Verdict{Kind: expr.VerdictDrop},
+				},
+			},
+		},
+		"match set on source": {
+			instruction: "--insert OUTPUT -m set --match-set gluetun-countries src -j DROP",
+			errWrapped:  ErrInstructionUnsupported,
+			errMessage: "instruction is not supported by the nftables backend: " +
+				"set gluetun-countries must be matched on the destination",
+		},
+		"cgroup path match": {
+			instruction: "-t mangle --append OUTPUT -m cgroup --path /docker/abc -j MARK --set-mark 0x1",
+			errWrapped:  ErrInstructionUnsupported,
+			errMessage: "instruction is not supported by the nftables backend: " +
+				"cgroup path match /docker/abc needs the iptables backend",
+		},
+		"dnat without destination": {
+			instruction: "-t nat --append PREROUTING -j DNAT",
+			errWrapped:  ErrInstructionUnsupported,
+			errMessage:  "instruction is not supported by the nftables backend: no target",
+		},
+		"ipv6 host": {
+			instruction: "--insert OUTPUT -d ::1 -j ACCEPT",
+			family:      nftables.TableFamilyIPv6,
+			parsed: nftablesInstruction{
+				operation: nftablesInsert,
+				table:     "filter",
+				chain:     "OUTPUT",
+				spec:      "-d ::1 -j ACCEPT",
+				exprs: []expr.Any{
+					&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: 24, Len: 16},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1,
+						Data: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}},
+					&expr.Verdict{Kind: expr.VerdictAccept},
+				},
+			},
+		},
+		"ipv4 address for ipv6 family": {
+			instruction: "--append OUTPUT -d 1.2.3.4 -j ACCEPT",
+			family:      nftables.TableFamilyIPv6,
+			errWrapped:  ErrInstructionUnsupported,
+			errMessage:  "instruction is not supported by the nftables backend: address 1.2.3.4 for family ip6",
+		},
+		"unsupported option": {
+			instruction: "--append OUTPUT -m comment --comment gluetun -j ACCEPT",
+			errWrapped:  ErrInstructionUnsupported,
+			errMessage:  "instruction is not supported by the nftables backend: option --comment",
+		},
+		"port without protocol": {
+			instruction: "--append INPUT --dport 53 -j ACCEPT",
+			errWrapped:  ErrInstructionUnsupported,
+			errMessage:  "instruction is not supported by the nftables backend: port without protocol",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			family := testCase.family
+			if family == nftables.TableFamilyUnspecified {
+				family = nftables.TableFamilyIPv4
+			}
+
+			parsed, err := parseNftablesInstruction(testCase.instruction, family)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+				return
+			}
+			assert.Equal(t, testCase.parsed, parsed)
+		})
+	}
+}
+
+// assertNftablesTranslatable asserts the iptables instructions given,
+// which can be prefixed with their iptables command, can be translated
+// to nftables rules for the family given.
+func assertNftablesTranslatable(t *testing.T, instructions []string,
+	family nftables.TableFamily) {
+	t.Helper()
+	for _, instruction := range instructions {
+		instruction = strings.TrimPrefix(instruction, "iptables ")
+		instruction = strings.TrimPrefix(instruction, "ip6tables ")
+		_, err := parseNftablesInstruction(instruction, family)
+		assert.NoError(t, err, instruction)
+	}
+}
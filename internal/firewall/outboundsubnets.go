@@ -12,11 +12,23 @@ func (c *Config) SetOutboundSubnets(ctx context.Context, subnets []net.IPNet) (e
 	c.stateMutex.Lock()
 	defer c.stateMutex.Unlock()
 
+	defer func() {
+		egressFilterErr := c.updateEgressFilter()
+		if err == nil {
+			err = egressFilterErr
+		}
+	}()
+
 	if !c.enabled {
 		c.logger.Info("firewall disabled, only updating allowed subnets internal list")
 		c.outboundSubnets = make([]net.IPNet, len(subnets))
 		copy(c.outboundSubnets, subnets)
 		return nil
+	} else if c.preTunnelRestricted() {
+		c.logger.Info("VPN tunnel not up yet, only updating allowed subnets internal list")
+		c.outboundSubnets = make([]net.IPNet, len(subnets))
+		copy(c.outboundSubnets, subnets)
+		return nil
 	}
 
 	c.logger.Info("setting allowed subnets...")
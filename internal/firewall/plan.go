@@ -0,0 +1,106 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+)
+
+// Plan contains the iptables and ip6tables instructions the
+// firewall runs to be enabled with its current state.
+type Plan struct {
+	Backend string `json:"backend"`
+	// Enabled is true if the firewall is currently enabled.
+	Enabled bool     `json:"enabled"`
+	IPv4    []string `json:"ipv4"`
+	IPv6    []string `json:"ipv6"`
+}
+
+// Plan returns the full set of instructions the firewall would run
+// to be enabled with its current state, without running them. This
+// includes the built-in rules, the VPN interface rules, the user
+// defined post rules and the templated rules. The instructions are
+// in the iptables format, and are translated for the nftables backend.
+func (c *Config) Plan(ctx context.Context) (plan Plan, err error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	c.ruleSet = new(ruleSet)
+	defer func() {
+		c.ruleSet = nil
+	}()
+
+	err = c.buildPlan(ctx)
+	if err != nil {
+		return plan, err
+	}
+
+	return Plan{
+		Backend: c.backend.name(),
+		Enabled: c.enabled,
+		IPv4:    c.ruleSet.ipv4,
+		IPv6:    c.ruleSet.ipv6,
+	}, nil
+}
+
+func (c *Config) buildPlan(ctx context.Context) (err error) {
+	err = c.buildEnableRules(ctx)
+	if err != nil {
+		return fmt.Errorf("building built-in rules: %w", err)
+	}
+
+	const remove = false
+	if c.vpnIntf != "" {
+		err = c.acceptOutputThroughVPNInterface(ctx, c.vpnIntf, c.vpnOutputPorts,
+			c.vpnOutputIPs, c.vpnOutputDNSServers, c.vpnOutputIPsRestricted, remove)
+		if err != nil {
+			return fmt.Errorf("building VPN interface rules: %w", err)
+		}
+
+		if len(c.countryNetworks) > 0 || c.countryNetworksAllowed {
+			err = c.runCountryNetworksInstructions(ctx, c.vpnIntf,
+				c.countryNetworksAllowed, c.countryDNSServers, remove)
+			if err != nil {
+				return fmt.Errorf("building country networks rules: %w", err)
+			}
+		}
+	}
+
+	if c.tcpRedirect.port != 0 {
+		err = c.redirectTCPOutput(ctx, c.tcpRedirect, remove)
+		if err != nil {
+			return fmt.Errorf("building TCP redirection rules: %w", err)
+		}
+	}
+
+	err = c.runUserPostRules(ctx, c.customRulesPath, remove)
+	if err != nil {
+		return fmt.Errorf("building user defined post rules: %w", err)
+	}
+
+	err = c.runUserRules(ctx, c.renderRulesTemplate(), remove)
+	if err != nil {
+		return fmt.Errorf("building templated rules: %w", err)
+	}
+
+	err = c.runUserRules(ctx, c.renderPortForwardTargetRules(), remove)
+	if err != nil {
+		return fmt.Errorf("building port forward target rules: %w", err)
+	}
+
+	err = c.runUserRules(ctx, c.renderBypassMarkRules(), remove)
+	if err != nil {
+		return fmt.Errorf("building bypass mark rules: %w", err)
+	}
+
+	err = c.runUserRules(ctx, c.renderGatewayRules(), remove)
+	if err != nil {
+		return fmt.Errorf("building gateway rules: %w", err)
+	}
+
+	err = c.runUserRules(ctx, c.renderTunnelsRules(), remove)
+	if err != nil {
+		return fmt.Errorf("building tunnels rules: %w", err)
+	}
+
+	return c.addDropLogRules(ctx)
+}
@@ -0,0 +1,40 @@
+package firewall
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Config_Plan(t *testing.T) {
+	t.Parallel()
+
+	backend := &recordingBackend{}
+	_, network, err := net.ParseCIDR("1.2.3.0/24")
+	require.NoError(t, err)
+	config := &Config{
+		backend:         backend,
+		vpnIntf:         "tun0",
+		countryNetworks: []net.IPNet{*network},
+		dropLogging:     true,
+		dropLogGroup:    100,
+	}
+
+	plan, err := config.Plan(context.Background())
+	require.NoError(t, err)
+
+	assert.Empty(t, backend.instructions)
+	assert.Nil(t, config.ruleSet)
+	assert.Equal(t, "recording", plan.Backend)
+	assert.False(t, plan.Enabled)
+	require.NotEmpty(t, plan.IPv4)
+	assert.Equal(t, "--policy INPUT DROP", plan.IPv4[0])
+	assert.Contains(t, plan.IPv4, "--append OUTPUT -o tun0 -j ACCEPT")
+	assert.Contains(t, plan.IPv4, "--insert OUTPUT -o tun0 -m set --match-set gluetun-countries dst -j DROP")
+	assert.Empty(t, backend.sets)
+	assert.Equal(t, "--append FORWARD -j NFLOG --nflog-group 100 --nflog-prefix forward",
+		plan.IPv4[len(plan.IPv4)-1])
+}
@@ -0,0 +1,109 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// SetPortForwardTarget sets the address to DNAT the ports forwarded
+// by the VPN provider to, such as a sibling container on a shared
+// bridge network. A zero port keeps the forwarded port numbers, and
+// a nil IP address removes the DNAT. The rules are kept in sync with
+// the forwarded ports and the VPN interface while the firewall is
+// enabled.
+func (c *Config) SetPortForwardTarget(ctx context.Context, ip net.IP, port uint16) (err error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	c.portForwardTargetIP = ip
+	c.portForwardTargetPort = port
+	return c.updatePortForwardTarget(ctx)
+}
+
+// updatePortForwardTarget replaces the port forward target rules
+// applied if the firewall is enabled. It must be called with the
+// state mutex locked.
+func (c *Config) updatePortForwardTarget(ctx context.Context) (err error) {
+	if !c.enabled {
+		return nil
+	}
+	return c.applyPortForwardTarget(ctx)
+}
+
+func (c *Config) applyPortForwardTarget(ctx context.Context) (err error) {
+	rules := c.renderPortForwardTargetRules()
+	if stringsEqual(rules, c.portForwardTargetApplied) {
+		return nil
+	}
+
+	const remove = true
+	err = c.runUserRules(ctx, c.portForwardTargetApplied, remove)
+	if err != nil {
+		return fmt.Errorf("removing previous port forward target rules: %w", err)
+	}
+	c.portForwardTargetApplied = nil
+
+	err = c.runUserRules(ctx, rules, !remove)
+	if err != nil {
+		return fmt.Errorf("adding port forward target rules: %w", err)
+	}
+	c.portForwardTargetApplied = rules
+	return nil
+}
+
+// renderPortForwardTargetRules returns the rules to DNAT the forwarded
+// ports coming in through the VPN interface to the target, to accept
+// this forwarded traffic, and to masquerade it so the target replies
+// through gluetun. It returns no rule if the target, VPN interface
+// or forwarded ports are not set.
+func (c *Config) renderPortForwardTargetRules() (rules []string) {
+	if c.portForwardTargetIP == nil || c.vpnIntf == "" || len(c.forwardedPorts) == 0 {
+		return nil
+	}
+
+	command := "iptables"
+	if c.portForwardTargetIP.To4() == nil {
+		command = "ip6tables"
+	}
+
+	targetPortOf := func(forwardedPort uint16) (targetPort uint16) {
+		if c.portForwardTargetPort == 0 {
+			return forwardedPort
+		}
+		return c.portForwardTargetPort
+	}
+
+	targetIP := c.portForwardTargetIP.String()
+	targetPorts := make([]uint16, 0, len(c.forwardedPorts))
+	targetPortSeen := make(map[uint16]struct{}, len(c.forwardedPorts))
+	for _, forwardedPort := range c.forwardedPorts {
+		targetPort := targetPortOf(forwardedPort)
+		if _, seen := targetPortSeen[targetPort]; seen {
+			continue
+		}
+		targetPortSeen[targetPort] = struct{}{}
+		targetPorts = append(targetPorts, targetPort)
+	}
+
+	for _, protocol := range []string{"tcp", "udp"} {
+		for _, forwardedPort := range c.forwardedPorts {
+			destination := net.JoinHostPort(targetIP, fmt.Sprint(targetPortOf(forwardedPort)))
+			rules = append(rules, fmt.Sprintf(
+				"%s -t nat --append PREROUTING -i %s -p %s --dport %d -j DNAT --to-destination %s",
+				command, c.vpnIntf, protocol, forwardedPort, destination))
+		}
+		for _, targetPort := range targetPorts {
+			rules = append(rules,
+				fmt.Sprintf("%s --append FORWARD -i %s -d %s -p %s --dport %d -j ACCEPT",
+					command, c.vpnIntf, targetIP, protocol, targetPort),
+				fmt.Sprintf("%s -t nat --append POSTROUTING -d %s -p %s --dport %d -j MASQUERADE",
+					command, targetIP, protocol, targetPort),
+			)
+		}
+	}
+	rules = append(rules, fmt.Sprintf(
+		"%s --append FORWARD -o %s -s %s -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+		command, c.vpnIntf, targetIP))
+	return rules
+}
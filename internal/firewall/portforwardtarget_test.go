@@ -0,0 +1,125 @@
+package firewall
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/google/nftables"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Config_portForwardTarget(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{
+		backend: &iptablesBackend{},
+		enabled: true,
+		vpnIntf: "tun0",
+		ruleSet: new(ruleSet),
+	}
+	ctx := context.Background()
+
+	err := config.SetPortForwardTarget(ctx, net.IPv4(172, 18, 0, 5), 8080)
+	require.NoError(t, err)
+	assert.Empty(t, config.ruleSet.ipv4)
+
+	err = config.SetForwardedPorts(ctx, []uint16{5000})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"-t nat --append PREROUTING -i tun0 -p tcp --dport 5000 -j DNAT --to-destination 172.18.0.5:8080",
+		"--append FORWARD -i tun0 -d 172.18.0.5 -p tcp --dport 8080 -j ACCEPT",
+		"-t nat --append POSTROUTING -d 172.18.0.5 -p tcp --dport 8080 -j MASQUERADE",
+		"-t nat --append PREROUTING -i tun0 -p udp --dport 5000 -j DNAT --to-destination 172.18.0.5:8080",
+		"--append FORWARD -i tun0 -d 172.18.0.5 -p udp --dport 8080 -j ACCEPT",
+		"-t nat --append POSTROUTING -d 172.18.0.5 -p udp --dport 8080 -j MASQUERADE",
+		"--append FORWARD -o tun0 -s 172.18.0.5 -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+	}, config.ruleSet.ipv4)
+	assertNftablesTranslatable(t, config.ruleSet.ipv4, nftables.TableFamilyIPv4)
+
+	config.ruleSet.ipv4 = nil
+	err = config.SetForwardedPorts(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"-t nat -D PREROUTING -i tun0 -p tcp --dport 5000 -j DNAT --to-destination 172.18.0.5:8080",
+		"-D FORWARD -i tun0 -d 172.18.0.5 -p tcp --dport 8080 -j ACCEPT",
+		"-t nat -D POSTROUTING -d 172.18.0.5 -p tcp --dport 8080 -j MASQUERADE",
+		"-t nat -D PREROUTING -i tun0 -p udp --dport 5000 -j DNAT --to-destination 172.18.0.5:8080",
+		"-D FORWARD -i tun0 -d 172.18.0.5 -p udp --dport 8080 -j ACCEPT",
+		"-t nat -D POSTROUTING -d 172.18.0.5 -p udp --dport 8080 -j MASQUERADE",
+		"-D FORWARD -o tun0 -s 172.18.0.5 -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+	}, config.ruleSet.ipv4)
+	assertNftablesTranslatable(t, config.ruleSet.ipv4, nftables.TableFamilyIPv4)
+}
+
+func Test_Config_renderPortForwardTargetRules(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		targetIP       net.IP
+		targetPort     uint16
+		forwardedPorts []uint16
+		rules          []string
+	}{
+		"no target": {
+			forwardedPorts: []uint16{5000},
+		},
+		"no forwarded port": {
+			targetIP: net.IPv4(172, 18, 0, 5),
+		},
+		"multiple ports keeping their numbers": {
+			targetIP:       net.IPv4(172, 18, 0, 5),
+			forwardedPorts: []uint16{5000, 6000},
+			rules: []string{
+				"iptables -t nat --append PREROUTING -i tun0 -p tcp --dport 5000 -j DNAT --to-destination 172.18.0.5:5000",
+				"iptables -t nat --append PREROUTING -i tun0 -p tcp --dport 6000 -j DNAT --to-destination 172.18.0.5:6000",
+				"iptables --append FORWARD -i tun0 -d 172.18.0.5 -p tcp --dport 5000 -j ACCEPT",
+				"iptables -t nat --append POSTROUTING -d 172.18.0.5 -p tcp --dport 5000 -j MASQUERADE",
+				"iptables --append FORWARD -i tun0 -d 172.18.0.5 -p tcp --dport 6000 -j ACCEPT",
+				"iptables -t nat --append POSTROUTING -d 172.18.0.5 -p tcp --dport 6000 -j MASQUERADE",
+				"iptables -t nat --append PREROUTING -i tun0 -p udp --dport 5000 -j DNAT --to-destination 172.18.0.5:5000",
+				"iptables -t nat --append PREROUTING -i tun0 -p udp --dport 6000 -j DNAT --to-destination 172.18.0.5:6000",
+				"iptables --append FORWARD -i tun0 -d 172.18.0.5 -p udp --dport 5000 -j ACCEPT",
+				"iptables -t nat --append POSTROUTING -d 172.18.0.5 -p udp --dport 5000 -j MASQUERADE",
+				"iptables --append FORWARD -i tun0 -d 172.18.0.5 -p udp --dport 6000 -j ACCEPT",
+				"iptables -t nat --append POSTROUTING -d 172.18.0.5 -p udp --dport 6000 -j MASQUERADE",
+				"iptables --append FORWARD -o tun0 -s 172.18.0.5 -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+			},
+		},
+		"multiple ports to the target port": {
+			targetIP:       net.ParseIP("fd00::5"),
+			targetPort:     8080,
+			forwardedPorts: []uint16{5000, 6000},
+			rules: []string{
+				"ip6tables -t nat --append PREROUTING -i tun0 -p tcp --dport 5000 -j DNAT --to-destination [fd00::5]:8080",
+				"ip6tables -t nat --append PREROUTING -i tun0 -p tcp --dport 6000 -j DNAT --to-destination [fd00::5]:8080",
+				"ip6tables --append FORWARD -i tun0 -d fd00::5 -p tcp --dport 8080 -j ACCEPT",
+				"ip6tables -t nat --append POSTROUTING -d fd00::5 -p tcp --dport 8080 -j MASQUERADE",
+				"ip6tables -t nat --append PREROUTING -i tun0 -p udp --dport 5000 -j DNAT --to-destination [fd00::5]:8080",
+				"ip6tables -t nat --append PREROUTING -i tun0 -p udp --dport 6000 -j DNAT --to-destination [fd00::5]:8080",
+				"ip6tables --append FORWARD -i tun0 -d fd00::5 -p udp --dport 8080 -j ACCEPT",
+				"ip6tables -t nat --append POSTROUTING -d fd00::5 -p udp --dport 8080 -j MASQUERADE",
+				"ip6tables --append FORWARD -o tun0 -s fd00::5 -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := &Config{
+				vpnIntf:               "tun0",
+				portForwardTargetIP:   testCase.targetIP,
+				portForwardTargetPort: testCase.targetPort,
+				forwardedPorts:        testCase.forwardedPorts,
+			}
+
+			rules := config.renderPortForwardTargetRules()
+
+			assert.Equal(t, testCase.rules, rules)
+		})
+	}
+}
@@ -9,7 +9,10 @@ import (
 func (c *Config) SetAllowedPort(ctx context.Context, port uint16, intf string) (err error) {
 	c.stateMutex.Lock()
 	defer c.stateMutex.Unlock()
+	return c.setAllowedPort(ctx, port, intf)
+}
 
+func (c *Config) setAllowedPort(ctx context.Context, port uint16, intf string) (err error) {
 	if port == 0 {
 		return nil
 	}
@@ -32,6 +35,14 @@ func (c *Config) SetAllowedPort(ctx context.Context, port uint16, intf string) (
 		return nil
 	}
 
+	if c.temporaryPortHasInterface(port, intf) {
+		// Input to the port through the interface is already
+		// accepted by the rules of the temporary port.
+		netInterfaces[intf] = struct{}{}
+		c.allowedInputPorts[port] = netInterfaces
+		return nil
+	}
+
 	c.logger.Info("setting allowed input port " + fmt.Sprint(port) + " through interface " + intf + "...")
 
 	const remove = false
@@ -48,7 +59,10 @@ func (c *Config) SetAllowedPort(ctx context.Context, port uint16, intf string) (
 func (c *Config) RemoveAllowedPort(ctx context.Context, port uint16) (err error) {
 	c.stateMutex.Lock()
 	defer c.stateMutex.Unlock()
+	return c.removeAllowedPort(ctx, port)
+}
 
+func (c *Config) removeAllowedPort(ctx context.Context, port uint16) (err error) {
 	if port == 0 {
 		return nil
 	}
@@ -68,6 +82,11 @@ func (c *Config) RemoveAllowedPort(ctx context.Context, port uint16) (err error)
 
 	const remove = true
 	for netInterface := range interfacesSet {
+		if c.temporaryPortHasInterface(port, netInterface) {
+			// Keep the rules accepting input to the temporary port.
+			delete(interfacesSet, netInterface)
+			continue
+		}
 		err := c.acceptInputToPort(ctx, netInterface, port, remove)
 		if err != nil {
 			return fmt.Errorf("removing allowed port %d on interface %s: %w",
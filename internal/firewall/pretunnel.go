@@ -0,0 +1,78 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/routing"
+)
+
+// SetTunnelUp relaxes the pre-tunnel egress restrictions, if any, such
+// that traffic to the local networks and to the outbound subnets is
+// allowed. It should be called once the VPN tunnel is up, and calling
+// it again has no effect.
+func (c *Config) SetTunnelUp(ctx context.Context) (err error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	if c.tunnelUp {
+		return nil
+	}
+
+	if err = c.endStartupPermissive(ctx); err != nil {
+		return err
+	}
+
+	if !c.enabled || !c.preTunnelStrict {
+		c.tunnelUp = true
+		return nil
+	}
+
+	c.logger.Info("VPN tunnel is up, relaxing pre-tunnel egress restrictions...")
+
+	for _, network := range c.localNetworks {
+		const remove = false
+		err = c.acceptOutputFromIPToSubnet(ctx, network.InterfaceName,
+			network.IP, *network.IPNet, remove)
+		if err != nil {
+			return fmt.Errorf("accepting output traffic to local network: %w", err)
+		}
+	}
+
+	if err = c.allowOutboundSubnets(ctx); err != nil {
+		return fmt.Errorf("allowing outbound subnets: %w", err)
+	}
+
+	// The DNS only rules are now redundant and are removed only once
+	// the broader rules are in place, to not interrupt DNS traffic.
+	for _, network := range c.localNetworks {
+		const remove = true
+		err = c.acceptOutputDNSFromIPToSubnet(ctx, network.InterfaceName,
+			network.IP, *network.IPNet, remove)
+		if err != nil {
+			c.logger.Error("cannot remove pre-tunnel DNS rule: " + err.Error())
+		}
+	}
+
+	c.tunnelUp = true
+	return nil
+}
+
+// preTunnelRestricted returns true if egress traffic is restricted
+// because the VPN tunnel was not up yet. It must be called with the
+// state mutex locked.
+func (c *Config) preTunnelRestricted() bool {
+	return c.preTunnelStrict && !c.tunnelUp
+}
+
+// acceptOutputToLocalNetwork accepts output traffic to the local network
+// given, restricting it to DNS traffic if the VPN tunnel was not up yet.
+func (c *Config) acceptOutputToLocalNetwork(ctx context.Context,
+	network routing.LocalNetwork, remove bool) (err error) {
+	if c.preTunnelRestricted() {
+		return c.acceptOutputDNSFromIPToSubnet(ctx, network.InterfaceName,
+			network.IP, *network.IPNet, remove)
+	}
+	return c.acceptOutputFromIPToSubnet(ctx, network.InterfaceName,
+		network.IP, *network.IPNet, remove)
+}
@@ -0,0 +1,86 @@
+package firewall
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/routing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Config_buildEnableRules_preTunnel(t *testing.T) {
+	t.Parallel()
+
+	localNetworks := []routing.LocalNetwork{{
+		IPNet:         &net.IPNet{IP: net.IPv4(172, 17, 0, 0), Mask: net.CIDRMask(16, 32)},
+		InterfaceName: "eth0",
+		IP:            net.IPv4(172, 17, 0, 2),
+	}}
+	defaultRoutes := []routing.DefaultRoute{{
+		NetInterface: "eth0",
+		AssignedIP:   net.IPv4(172, 17, 0, 2),
+	}}
+	outboundSubnets := []net.IPNet{
+		{IP: net.IPv4(192, 168, 1, 0), Mask: net.CIDRMask(24, 32)},
+	}
+
+	testCases := map[string]struct {
+		preTunnelStrict bool
+		tunnelUp        bool
+		outputRules     []string
+	}{
+		"not strict": {
+			outputRules: []string{
+				"--append OUTPUT -o eth0 -s 172.17.0.2 -d 172.17.0.0/16 -j ACCEPT",
+				"--append OUTPUT -o eth0 -s 172.17.0.2 -d 192.168.1.0/24 -j ACCEPT",
+			},
+		},
+		"strict before tunnel up": {
+			preTunnelStrict: true,
+			outputRules: []string{
+				"--append OUTPUT -o eth0 -s 172.17.0.2 -d 172.17.0.0/16 -p udp -m udp --dport 53 -j ACCEPT",
+				"--append OUTPUT -o eth0 -s 172.17.0.2 -d 172.17.0.0/16 -p tcp -m tcp --dport 53 -j ACCEPT",
+			},
+		},
+		"strict after tunnel up": {
+			preTunnelStrict: true,
+			tunnelUp:        true,
+			outputRules: []string{
+				"--append OUTPUT -o eth0 -s 172.17.0.2 -d 172.17.0.0/16 -j ACCEPT",
+				"--append OUTPUT -o eth0 -s 172.17.0.2 -d 192.168.1.0/24 -j ACCEPT",
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := &Config{
+				backend:         &iptablesBackend{},
+				defaultRoutes:   defaultRoutes,
+				localNetworks:   localNetworks,
+				outboundSubnets: outboundSubnets,
+				preTunnelStrict: testCase.preTunnelStrict,
+				tunnelUp:        testCase.tunnelUp,
+				ruleSet:         new(ruleSet),
+			}
+
+			err := config.buildEnableRules(context.Background())
+
+			require.NoError(t, err)
+			var outputRules []string
+			for _, instruction := range config.ruleSet.ipv4 {
+				if strings.HasPrefix(instruction, "--append OUTPUT -o eth0 -s ") {
+					outputRules = append(outputRules, instruction)
+				}
+			}
+			assert.Equal(t, testCase.outputRules, outputRules)
+			assert.Empty(t, config.ruleSet.ipv6)
+		})
+	}
+}
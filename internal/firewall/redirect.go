@@ -0,0 +1,81 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+type tcpRedirect struct {
+	port uint16
+	// excludedDestinations are the destination IPv4 addresses
+	// and subnets not redirected, as used to add the rules.
+	excludedDestinations []string
+}
+
+// SetTCPRedirect redirects the IPv4 TCP connections initiated from the
+// container to the local port given, except for connections to the
+// excluded IP address, to the loopback, local networks and outbound
+// subnets. This is done regardless of the firewall being enabled, since
+// the redirected traffic must go through the local relay listening on
+// the port. UDP traffic is not redirected, and is blocked by the
+// firewall since the VPN interface is set to the loopback interface.
+// Setting the port to 0 removes the redirection.
+func (c *Config) SetTCPRedirect(ctx context.Context, port uint16,
+	excludedIP net.IP) (err error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	if c.tcpRedirect.port != 0 {
+		const remove = true
+		err = c.redirectTCPOutput(ctx, c.tcpRedirect, remove)
+		if err != nil {
+			return fmt.Errorf("removing TCP redirection: %w", err)
+		}
+		c.tcpRedirect = tcpRedirect{}
+	}
+
+	if port == 0 {
+		return nil
+	}
+
+	c.logger.Info(fmt.Sprintf("redirecting TCP output traffic to port %d...", port))
+
+	redirect := tcpRedirect{
+		port:                 port,
+		excludedDestinations: []string{"127.0.0.0/8", excludedIP.String()},
+	}
+	for _, network := range c.localNetworks {
+		if network.IPNet.IP.To4() != nil {
+			redirect.excludedDestinations = append(redirect.excludedDestinations,
+				network.IPNet.String())
+		}
+	}
+	for _, subnet := range c.outboundSubnets {
+		if subnet.IP.To4() != nil {
+			redirect.excludedDestinations = append(redirect.excludedDestinations,
+				subnet.String())
+		}
+	}
+
+	const remove = false
+	err = c.redirectTCPOutput(ctx, redirect, remove)
+	if err != nil {
+		return fmt.Errorf("redirecting TCP output traffic: %w", err)
+	}
+	c.tcpRedirect = redirect
+
+	return nil
+}
+
+func (c *Config) redirectTCPOutput(ctx context.Context,
+	redirect tcpRedirect, remove bool) error {
+	instructions := make([]string, 0, len(redirect.excludedDestinations)+1)
+	for _, destination := range redirect.excludedDestinations {
+		instructions = append(instructions, fmt.Sprintf(
+			"-t nat %s OUTPUT -p tcp -d %s -j RETURN", appendOrDelete(remove), destination))
+	}
+	instructions = append(instructions, fmt.Sprintf(
+		"-t nat %s OUTPUT -p tcp -j REDIRECT --to-ports %d", appendOrDelete(remove), redirect.port))
+	return c.runIptablesInstructions(ctx, instructions)
+}
@@ -0,0 +1,188 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// ruleSet accumulates iptables and ip6tables instructions so they
+// can be applied in one shot by the backend, instead of running
+// them one by one.
+type ruleSet struct {
+	ipv4 []string
+	ipv6 []string
+}
+
+// applyRuleSet runs the build function with the instruction runners
+// recording instructions instead of executing them, and then applies
+// the recorded IPv4 and IPv6 rule sets atomically with the backend.
+// It must be called with the state mutex locked.
+func (c *Config) applyRuleSet(ctx context.Context,
+	build func(ctx context.Context) error) (err error) {
+	c.ruleSet = new(ruleSet)
+	err = build(ctx)
+	set := c.ruleSet
+	c.ruleSet = nil
+	if err != nil {
+		return err
+	}
+
+	return c.backend.applyAtomically(ctx, set.ipv4, set.ipv6)
+}
+
+// applyAtomically applies the IPv4 and IPv6 instructions given with
+// iptables-restore and ip6tables-restore. If the IPv6 instructions fail
+// to apply, the IPv4 rules are rolled back to the state they were in
+// before the call.
+func (i *iptablesBackend) applyAtomically(ctx context.Context,
+	ipv4, ipv6 []string) (err error) {
+	ipv4Backup, err := i.saveRules(ctx, i.ipTables, &i.iptablesMutex)
+	if err != nil {
+		return fmt.Errorf("saving ipv4 rules: %w", err)
+	}
+
+	const noFlush = true
+	err = i.restoreRules(ctx, i.ipTables, &i.iptablesMutex,
+		makeRestoreInput(ipv4), noFlush)
+	if err != nil {
+		return fmt.Errorf("applying ipv4 rules: %w", err)
+	}
+
+	if i.ip6Tables == "" || len(ipv6) == 0 {
+		return nil
+	}
+
+	err = i.restoreRules(ctx, i.ip6Tables, &i.ip6tablesMutex,
+		makeRestoreInput(ipv6), noFlush)
+	if err != nil {
+		err = fmt.Errorf("applying ipv6 rules: %w", err)
+		const noFlush = false
+		rollbackErr := i.restoreRules(ctx, i.ipTables, &i.iptablesMutex,
+			ipv4Backup, noFlush)
+		if rollbackErr != nil {
+			err = fmt.Errorf("%w; rolling back ipv4 rules: %s", err, rollbackErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// recordInstruction records the instruction in the rule set being built
+// and returns true, or returns false if no rule set is being built.
+func (c *Config) recordInstruction(instruction string, ipv6 bool) (recorded bool) {
+	if c.ruleSet == nil {
+		return false
+	}
+	if ipv6 {
+		c.ruleSet.ipv6 = append(c.ruleSet.ipv6, instruction)
+	} else {
+		c.ruleSet.ipv4 = append(c.ruleSet.ipv4, instruction)
+	}
+	return true
+}
+
+func (i *iptablesBackend) saveRules(ctx context.Context, iptablesPath string,
+	mutex *sync.Mutex) (rules string, err error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	savePath := iptablesPath + "-save"
+	cmd := exec.CommandContext(ctx, savePath) // #nosec G204
+	output, err := i.runner.Run(cmd)
+	if err != nil {
+		return "", fmt.Errorf("command failed: \"%s\": %s: %w",
+			savePath, output, err)
+	}
+	return output + "\n", nil
+}
+
+func (i *iptablesBackend) restoreRules(ctx context.Context, iptablesPath string,
+	mutex *sync.Mutex, rules string, noFlush bool) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	restorePath := iptablesPath + "-restore"
+	var flags []string
+	if noFlush {
+		flags = append(flags, "--noflush")
+	}
+
+	i.logger.Debug(restorePath + " " + strings.Join(flags, " ") + "\n" + rules)
+
+	cmd := exec.CommandContext(ctx, restorePath, flags...) // #nosec G204
+	cmd.Stdin = strings.NewReader(rules)
+	if output, err := i.runner.Run(cmd); err != nil {
+		return fmt.Errorf("command failed: \"%s\": %s: %w",
+			restorePath, output, err)
+	}
+	return nil
+}
+
+// makeRestoreInput converts iptables instructions into the iptables-restore
+// input format. Instructions are grouped by table, and policy instructions
+// are converted to chain lines placed before the rules of their table.
+func makeRestoreInput(instructions []string) (input string) {
+	type table struct {
+		chains []string
+		rules  []string
+	}
+	tables := make(map[string]*table)
+	var tableNames []string
+
+	for _, instruction := range instructions {
+		tableName, fields := extractTable(strings.Fields(instruction))
+		t, ok := tables[tableName]
+		if !ok {
+			t = new(table)
+			tables[tableName] = t
+			tableNames = append(tableNames, tableName)
+		}
+
+		const policyFields = 3
+		if len(fields) == policyFields &&
+			(fields[0] == "--policy" || fields[0] == "-P") {
+			chain, policy := fields[1], fields[2]
+			t.chains = append(t.chains, ":"+chain+" "+policy+" [0:0]")
+			continue
+		}
+
+		t.rules = append(t.rules, strings.Join(fields, " "))
+	}
+
+	var builder strings.Builder
+	for _, tableName := range tableNames {
+		t := tables[tableName]
+		builder.WriteString("*" + tableName + "\n")
+		for _, chain := range t.chains {
+			builder.WriteString(chain + "\n")
+		}
+		for _, rule := range t.rules {
+			builder.WriteString(rule + "\n")
+		}
+		builder.WriteString("COMMIT\n")
+	}
+	return builder.String()
+}
+
+// extractTable returns the table name specified in the instruction fields,
+// defaulting to filter, and the fields without the table flag.
+func extractTable(fields []string) (tableName string, otherFields []string) {
+	tableName = "filter"
+	otherFields = make([]string, 0, len(fields))
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "-t", "--table":
+			if i+1 < len(fields) {
+				tableName = fields[i+1]
+				i++
+				continue
+			}
+		}
+		otherFields = append(otherFields, fields[i])
+	}
+	return tableName, otherFields
+}
@@ -0,0 +1,57 @@
+package firewall
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_makeRestoreInput(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		instructions []string
+		input        string
+	}{
+		"no instruction": {},
+		"policies and rules": {
+			instructions: []string{
+				"--policy INPUT DROP",
+				"--append INPUT -i lo -j ACCEPT",
+				"--policy OUTPUT DROP",
+				"--append OUTPUT  -o lo -j ACCEPT",
+			},
+			input: "*filter\n" +
+				":INPUT DROP [0:0]\n" +
+				":OUTPUT DROP [0:0]\n" +
+				"--append INPUT -i lo -j ACCEPT\n" +
+				"--append OUTPUT -o lo -j ACCEPT\n" +
+				"COMMIT\n",
+		},
+		"multiple tables": {
+			instructions: []string{
+				"-t nat -A POSTROUTING -o tun0 -j MASQUERADE",
+				"-A INPUT -i tun0 -j ACCEPT",
+				"--table nat -A PREROUTING -i tun0 -j ACCEPT",
+			},
+			input: "*nat\n" +
+				"-A POSTROUTING -o tun0 -j MASQUERADE\n" +
+				"-A PREROUTING -i tun0 -j ACCEPT\n" +
+				"COMMIT\n" +
+				"*filter\n" +
+				"-A INPUT -i tun0 -j ACCEPT\n" +
+				"COMMIT\n",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			input := makeRestoreInput(testCase.instructions)
+
+			assert.Equal(t, testCase.input, input)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/qdm12/gluetun/internal/firewall/rulestemplate"
+)
+
+// SetRulesTemplateFile reads the rules template file given, where each
+// line is an iptables or ip6tables rule which can use the placeholders
+// {{.TunDevice}}, {{.VPNServerIP}} and {{.ForwardedPort}}, the first
+// port forwarded. The rules are applied after the built-in rules, and
+// applied again each time one of the placeholder values changes. A line is skipped as long as one of
+// the placeholder values it uses is not known, for example if the VPN
+// is not connected yet. An empty filepath removes the templated rules.
+func (c *Config) SetRulesTemplateFile(ctx context.Context, filepath string) (err error) {
+	var lineTemplates []*template.Template
+	if filepath != "" {
+		lineTemplates, err = rulestemplate.Parse(filepath)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	c.rulesTemplate = lineTemplates
+	return c.updateRulesTemplate(ctx)
+}
+
+// SetForwardedPorts sets the forwarded ports used in the port forward
+// target rules, where the first port is also the forwarded port value
+// used in the rules template. No port means no port is forwarded.
+func (c *Config) SetForwardedPorts(ctx context.Context, ports []uint16) (err error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	c.forwardedPorts = make([]uint16, len(ports))
+	copy(c.forwardedPorts, ports)
+	if err = c.updateRulesTemplate(ctx); err != nil {
+		return err
+	}
+	return c.updatePortForwardTarget(ctx)
+}
+
+// updateRulesTemplate renders the rules template and replaces the
+// rules previously applied if the rendered rules changed.
+// It must be called with the state mutex locked.
+func (c *Config) updateRulesTemplate(ctx context.Context) (err error) {
+	if !c.enabled {
+		return nil
+	}
+	return c.applyRulesTemplate(ctx)
+}
+
+func (c *Config) applyRulesTemplate(ctx context.Context) (err error) {
+	rules := c.renderRulesTemplate()
+	if stringsEqual(rules, c.rulesTemplateApplied) {
+		return nil
+	}
+
+	const remove = true
+	err = c.runUserRules(ctx, c.rulesTemplateApplied, remove)
+	if err != nil {
+		return fmt.Errorf("removing previous templated rules: %w", err)
+	}
+	c.rulesTemplateApplied = nil
+
+	err = c.runUserRules(ctx, rules, !remove)
+	if err != nil {
+		return fmt.Errorf("applying templated rules: %w", err)
+	}
+	c.rulesTemplateApplied = rules
+	return nil
+}
+
+// renderRulesTemplate returns the rules rendered from the rules
+// template, without the rules using placeholder values not known yet.
+func (c *Config) renderRulesTemplate() (rules []string) {
+	data := make(map[string]string)
+	if c.vpnIntf != "" {
+		data[rulestemplate.KeyTunDevice] = c.vpnIntf
+	}
+	if c.vpnConnection.IP != nil {
+		data[rulestemplate.KeyVPNServerIP] = c.vpnConnection.IP.String()
+	}
+	if len(c.forwardedPorts) > 0 {
+		data[rulestemplate.KeyForwardedPort] = strconv.Itoa(int(c.forwardedPorts[0]))
+	}
+
+	rules = make([]string, 0, len(c.rulesTemplate))
+	for _, lineTemplate := range c.rulesTemplate {
+		rule := new(strings.Builder)
+		err := lineTemplate.Execute(rule, data)
+		if err != nil {
+			continue
+		}
+		rules = append(rules, rule.String())
+	}
+	return rules
+}
+
+func stringsEqual(a, b []string) (equal bool) {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+// Package rulestemplate parses and validates the user firewall
+// rules template file, where each line is an iptables or ip6tables
+// rule which can use placeholders.
+package rulestemplate
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Placeholder keys which can be used in the rules template lines.
+const (
+	KeyTunDevice     = "TunDevice"
+	KeyVPNServerIP   = "VPNServerIP"
+	KeyForwardedPort = "ForwardedPort"
+)
+
+// Parse reads the rules template file given and returns the templates
+// of its lines, skipping empty lines and lines starting with #.
+// Executing a template fails if it uses a key missing from its data.
+func Parse(filepath string) (lineTemplates []*template.Template, err error) {
+	b, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("reading rules template file: %w", err)
+	}
+
+	lines := strings.Split(string(b), "\n")
+	lineTemplates = make([]*template.Template, 0, len(lines))
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := "line " + strconv.Itoa(i+1)
+		lineTemplate, err := template.New(name).Option("missingkey=error").Parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("parsing rules template line %d: %w", i+1, err)
+		}
+		lineTemplates = append(lineTemplates, lineTemplate)
+	}
+	return lineTemplates, nil
+}
+
+// Validate parses the rules template file given and renders each of
+// its lines with all the placeholder keys set, to detect lines which
+// would never be rendered, for example because of a misspelled key.
+func Validate(filepath string) (err error) {
+	lineTemplates, err := Parse(filepath)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]string{
+		KeyTunDevice:     "tun0",
+		KeyVPNServerIP:   "1.2.3.4",
+		KeyForwardedPort: "1234",
+	}
+	for _, lineTemplate := range lineTemplates {
+		err = lineTemplate.Execute(new(strings.Builder), data)
+		if err != nil {
+			return fmt.Errorf("rendering rules template %s: %w",
+				lineTemplate.Name(), err)
+		}
+	}
+	return nil
+}
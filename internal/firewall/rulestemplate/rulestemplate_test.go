@@ -0,0 +1,82 @@
+package rulestemplate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Parse(t *testing.T) {
+	t.Parallel()
+
+	templatePath := filepath.Join(t.TempDir(), "rules.tmpl")
+	const templateContent = `# comment
+
+iptables --append INPUT -i {{.TunDevice}} -p tcp --dport {{.ForwardedPort}} -j ACCEPT
+`
+	err := os.WriteFile(templatePath, []byte(templateContent), 0600)
+	require.NoError(t, err)
+
+	lineTemplates, err := Parse(templatePath)
+
+	require.NoError(t, err)
+	require.Len(t, lineTemplates, 1)
+	assert.Equal(t, "line 3", lineTemplates[0].Name())
+}
+
+func Test_Parse_error(t *testing.T) {
+	t.Parallel()
+
+	templatePath := filepath.Join(t.TempDir(), "rules.tmpl")
+	err := os.WriteFile(templatePath, []byte("iptables {{.TunDevice"), 0600)
+	require.NoError(t, err)
+
+	_, err = Parse(templatePath)
+	assert.ErrorContains(t, err, "parsing rules template line 1")
+}
+
+func Test_Validate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		content    string
+		errMessage string
+	}{
+		"empty file": {},
+		"all keys": {
+			content: "iptables --append OUTPUT -o {{.TunDevice}} -d {{.VPNServerIP}} -j DROP\n" +
+				"iptables --append INPUT -i {{.TunDevice}} -p tcp --dport {{.ForwardedPort}} -j ACCEPT",
+		},
+		"unknown key": {
+			content: "# comment\niptables --append OUTPUT -o {{.TunDevic}} -j DROP",
+			errMessage: "rendering rules template line 2: template: line 2:1:30: " +
+				`executing "line 2" at <.TunDevic>: map has no entry for key "TunDevic"`,
+		},
+		"parse error": {
+			content:    "iptables {{.TunDevice",
+			errMessage: "parsing rules template line 1: template: line 1:1: unclosed action",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			templatePath := filepath.Join(t.TempDir(), "rules.tmpl")
+			err := os.WriteFile(templatePath, []byte(testCase.content), 0600)
+			require.NoError(t, err)
+
+			err = Validate(templatePath)
+
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
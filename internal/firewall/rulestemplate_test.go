@@ -0,0 +1,57 @@
+package firewall
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Config_rulesTemplate(t *testing.T) {
+	t.Parallel()
+
+	templatePath := filepath.Join(t.TempDir(), "rules.tmpl")
+	const templateContent = `# comment
+iptables --append OUTPUT -o {{.TunDevice}} -d {{.VPNServerIP}} -j DROP
+iptables --append INPUT -i {{.TunDevice}} -p tcp --dport {{.ForwardedPort}} -j ACCEPT
+iptables --append OUTPUT -o eth0 -p icmp -j ACCEPT
+`
+	err := os.WriteFile(templatePath, []byte(templateContent), 0600)
+	require.NoError(t, err)
+
+	config := &Config{
+		backend: &iptablesBackend{},
+		enabled: true,
+		ruleSet: new(ruleSet),
+	}
+	ctx := context.Background()
+
+	err = config.SetRulesTemplateFile(ctx, templatePath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"--append OUTPUT -o eth0 -p icmp -j ACCEPT",
+	}, config.ruleSet.ipv4)
+
+	config.vpnIntf = "tun0"
+	config.vpnConnection = models.Connection{IP: net.IPv4(1, 2, 3, 4)}
+	config.ruleSet.ipv4 = nil
+	err = config.SetForwardedPorts(ctx, []uint16{5000})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"-D OUTPUT -o eth0 -p icmp -j ACCEPT",
+		"--append OUTPUT -o tun0 -d 1.2.3.4 -j DROP",
+		"--append INPUT -i tun0 -p tcp --dport 5000 -j ACCEPT",
+		"--append OUTPUT -o eth0 -p icmp -j ACCEPT",
+	}, config.ruleSet.ipv4)
+
+	// Setting the same forwarded port does not change the rules.
+	config.ruleSet.ipv4 = nil
+	err = config.SetForwardedPorts(ctx, []uint16{5000})
+	require.NoError(t, err)
+	assert.Empty(t, config.ruleSet.ipv4)
+}
@@ -0,0 +1,179 @@
+package firewall
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/google/nftables"
+)
+
+// ipsetMaxElements is the maximum number of networks of an ipset,
+// which is large enough for the networks of all countries.
+const ipsetMaxElements = 1 << 20
+
+// setNetworks creates or replaces the ipset named name with the
+// networks given, using ipset restore on a temporary set swapped
+// with the set, such that rules matching the set never see it partially
+// filled. The set is created if it does not exist yet.
+func (i *iptablesBackend) setNetworks(ctx context.Context, name string,
+	networks []net.IPNet, ipv6 bool) (err error) {
+	family := "inet"
+	if ipv6 {
+		family = "inet6"
+	}
+	create := fmt.Sprintf("create %%s hash:net family %s maxelem %d -exist",
+		family, ipsetMaxElements)
+	temporaryName := name + "-new"
+
+	lines := make([]string, 0, len(networks)+6) //nolint:gomnd
+	lines = append(lines,
+		fmt.Sprintf(create, temporaryName),
+		"flush "+temporaryName)
+	for _, network := range networks {
+		lines = append(lines, "add "+temporaryName+" "+network.String())
+	}
+	lines = append(lines,
+		fmt.Sprintf(create, name),
+		"swap "+temporaryName+" "+name,
+		"destroy "+temporaryName)
+
+	i.logger.Debug(fmt.Sprintf("ipset restore with %d networks for set %s",
+		len(networks), name))
+
+	cmd := exec.CommandContext(ctx, "ipset", "restore")
+	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n") + "\n")
+	if output, err := i.runner.Run(cmd); err != nil {
+		return fmt.Errorf("command failed: \"ipset restore\" for set %s: %s: %w",
+			name, output, err)
+	}
+	return nil
+}
+
+// setNetworks creates or replaces the interval set named name in the
+// table of the family given, in a single nftables transaction.
+func (n *nftablesBackend) setNetworks(_ context.Context, name string,
+	networks []net.IPNet, ipv6 bool) (err error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	family := nftables.TableFamilyIPv4
+	keyType := nftables.TypeIPAddr
+	if ipv6 {
+		family = nftables.TableFamilyIPv6
+		keyType = nftables.TypeIP6Addr
+	}
+
+	conn := &nftables.Conn{}
+	n.ensureChains(conn, family)
+	set := &nftables.Set{
+		Table:    &nftables.Table{Name: nftablesTableName, Family: family},
+		Name:     name,
+		KeyType:  keyType,
+		Interval: true,
+	}
+	err = conn.AddSet(set, nil)
+	if err != nil {
+		return fmt.Errorf("adding nftables set %s: %w", name, err)
+	}
+	conn.FlushSet(set)
+
+	elements := networksToIntervals(networks, ipv6)
+	if len(elements) > 0 {
+		err = conn.SetAddElements(set, elements)
+		if err != nil {
+			return fmt.Errorf("adding nftables set %s elements: %w", name, err)
+		}
+	}
+
+	n.logger.Debug(fmt.Sprintf("nftables %s set %s with %d networks",
+		familyName(family), name, len(networks)))
+
+	err = conn.Flush()
+	if err != nil {
+		return fmt.Errorf("applying nftables set %s: %w", name, err)
+	}
+	return nil
+}
+
+// networksToIntervals converts the networks given to the elements of an
+// nftables interval set, where each interval starts with an element and
+// ends with an interval end element holding the address following the
+// interval. Overlapping and adjacent networks are merged, since intervals
+// of an nftables set cannot overlap.
+func networksToIntervals(networks []net.IPNet, ipv6 bool) (elements []nftables.SetElement) {
+	size := net.IPv4len
+	if ipv6 {
+		size = net.IPv6len
+	}
+
+	type interval struct {
+		first, last []byte
+	}
+	intervals := make([]interval, 0, len(networks))
+	for _, network := range networks {
+		ip := network.IP.To16()
+		if !ipv6 {
+			ip = network.IP.To4()
+		}
+		if ip == nil || len(network.Mask) != size {
+			continue
+		}
+		first := make([]byte, size)
+		last := make([]byte, size)
+		for i := range ip {
+			first[i] = ip[i] & network.Mask[i]
+			last[i] = ip[i] | ^network.Mask[i]
+		}
+		intervals = append(intervals, interval{first: first, last: last})
+	}
+
+	sort.Slice(intervals, func(i, j int) bool {
+		return bytes.Compare(intervals[i].first, intervals[j].first) < 0
+	})
+
+	merged := make([]interval, 0, len(intervals))
+	for _, current := range intervals {
+		if len(merged) > 0 {
+			previous := &merged[len(merged)-1]
+			next, overflow := nextAddress(previous.last)
+			if overflow || bytes.Compare(current.first, next) <= 0 {
+				if bytes.Compare(current.last, previous.last) > 0 {
+					previous.last = current.last
+				}
+				continue
+			}
+		}
+		merged = append(merged, current)
+	}
+
+	elements = make([]nftables.SetElement, 0, 2*len(merged)) //nolint:gomnd
+	for _, interval := range merged {
+		elements = append(elements, nftables.SetElement{Key: interval.first})
+		end, overflow := nextAddress(interval.last)
+		if !overflow {
+			// No interval end element is needed if the
+			// interval goes to the last address.
+			elements = append(elements, nftables.SetElement{Key: end, IntervalEnd: true})
+		}
+	}
+	return elements
+}
+
+// nextAddress returns the address following the address given, and
+// true if the address given is the last address of its family.
+func nextAddress(address []byte) (next []byte, overflow bool) {
+	next = make([]byte, len(address))
+	copy(next, address)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			return next, false
+		}
+	}
+	return next, true
+}
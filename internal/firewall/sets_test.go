@@ -0,0 +1,106 @@
+package firewall
+
+import (
+	"context"
+	"io"
+	"net"
+	"os/exec"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/nftables"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_iptablesBackend_setNetworks(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	runner := NewMockRunner(ctrl)
+	var input []byte
+	runner.EXPECT().Run(gomock.Any()).
+		DoAndReturn(func(cmd *exec.Cmd) (string, error) {
+			assert.Equal(t, []string{"ipset", "restore"}, cmd.Args)
+			var err error
+			input, err = io.ReadAll(cmd.Stdin)
+			return "", err
+		})
+	logger := NewMockLogger(ctrl)
+	logger.EXPECT().Debug("ipset restore with 1 networks for set gluetun-countries6")
+	backend := &iptablesBackend{runner: runner, logger: logger}
+
+	_, network, err := net.ParseCIDR("fd00::/64")
+	require.NoError(t, err)
+	const ipv6 = true
+	err = backend.setNetworks(context.Background(), "gluetun-countries6",
+		[]net.IPNet{*network}, ipv6)
+
+	require.NoError(t, err)
+	assert.Equal(t, "create gluetun-countries6-new hash:net family inet6 maxelem 1048576 -exist\n"+
+		"flush gluetun-countries6-new\n"+
+		"add gluetun-countries6-new fd00::/64\n"+
+		"create gluetun-countries6 hash:net family inet6 maxelem 1048576 -exist\n"+
+		"swap gluetun-countries6-new gluetun-countries6\n"+
+		"destroy gluetun-countries6-new\n", string(input))
+}
+
+func Test_networksToIntervals(t *testing.T) {
+	t.Parallel()
+
+	parseCIDRs := func(t *testing.T, cidrs ...string) (networks []net.IPNet) {
+		t.Helper()
+		for _, cidr := range cidrs {
+			_, network, err := net.ParseCIDR(cidr)
+			require.NoError(t, err)
+			networks = append(networks, *network)
+		}
+		return networks
+	}
+
+	testCases := map[string]struct {
+		cidrs    []string
+		ipv6     bool
+		elements []nftables.SetElement
+	}{
+		"no network": {
+			elements: []nftables.SetElement{},
+		},
+		"sorted and merged": {
+			cidrs: []string{"10.0.1.0/24", "1.2.3.0/24", "10.0.0.0/24", "10.0.0.128/25"},
+			elements: []nftables.SetElement{
+				{Key: []byte{1, 2, 3, 0}},
+				{Key: []byte{1, 2, 4, 0}, IntervalEnd: true},
+				{Key: []byte{10, 0, 0, 0}},
+				{Key: []byte{10, 0, 2, 0}, IntervalEnd: true},
+			},
+		},
+		"last address": {
+			cidrs: []string{"255.255.255.0/24"},
+			elements: []nftables.SetElement{
+				{Key: []byte{255, 255, 255, 0}},
+			},
+		},
+		"other family ignored": {
+			cidrs: []string{"1.2.3.0/24", "fd00::/8"},
+			ipv6:  true,
+			elements: []nftables.SetElement{
+				{Key: []byte{0xfd, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+				{Key: []byte{0xfe, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, IntervalEnd: true},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			networks := parseCIDRs(t, testCase.cidrs...)
+
+			elements := networksToIntervals(networks, testCase.ipv6)
+
+			assert.Equal(t, testCase.elements, elements)
+		})
+	}
+}
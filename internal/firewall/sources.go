@@ -0,0 +1,120 @@
+package firewall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrSourceNotValid = errors.New("source is not valid")
+
+// SetSourcePolicies restricts new connections to private networks to
+// the LAN source given, and new connections to the HTTP proxy listening
+// on the port given to the HTTP proxy source given. This is useful for
+// containers sharing the network namespace of gluetun, which all have
+// the same IP addresses, so sources are a user id such as "uid:1000"
+// or a packet mark such as "mark:0x1". An empty source does not
+// restrict anything. It must be called before the firewall is enabled.
+func (c *Config) SetSourcePolicies(lanSource, httpProxySource string,
+	httpProxyPort uint16) (err error) {
+	lanSourceMatch, err := sourceToMatch(lanSource)
+	if err != nil {
+		return fmt.Errorf("LAN source: %w", err)
+	}
+
+	httpProxySourceMatch, err := sourceToMatch(httpProxySource)
+	if err != nil {
+		return fmt.Errorf("HTTP proxy source: %w", err)
+	}
+
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	c.lanSourceMatch = lanSourceMatch
+	c.httpProxySourceMatch = httpProxySourceMatch
+	c.httpProxyPort = httpProxyPort
+	return nil
+}
+
+// sourceToMatch converts a source such as "uid:1000" or "mark:0x1"
+// to the negated iptables match of packets not from this source.
+func sourceToMatch(source string) (match string, err error) {
+	if source == "" {
+		return "", nil
+	}
+
+	kind, value, _ := strings.Cut(source, ":")
+	const base, bitSize = 0, 32
+	if _, err := strconv.ParseUint(value, base, bitSize); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrSourceNotValid, source)
+	}
+
+	switch kind {
+	case "uid":
+		return "-m owner ! --uid-owner " + value, nil
+	case "mark":
+		return "-m mark ! --mark " + value, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrSourceNotValid, source)
+	}
+}
+
+//nolint:gochecknoglobals
+var (
+	privateIPv4Networks = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "169.254.0.0/16"}
+	privateIPv6Networks = []string{"fc00::/7", "fe80::/10"}
+)
+
+// restrictSources inserts rules at the start of the OUTPUT chain
+// dropping new connections to private networks through the default
+// route interfaces and to the HTTP proxy not coming from their
+// respective allowed source. Private networks are only restricted
+// through the default route interfaces, so connections to private
+// addresses through the VPN, such as the VPN DNS server or port
+// forwarding gateway, are not dropped. Only drop rules are used so
+// the source policies cannot allow more than the other rules.
+func (c *Config) restrictSources(ctx context.Context) (err error) {
+	if c.lanSourceMatch != "" {
+		for _, intf := range c.defaultRouteInterfaces() {
+			for _, network := range privateIPv4Networks {
+				instruction := "--insert OUTPUT -o " + intf + " -d " + network + " " +
+					c.lanSourceMatch + " -m conntrack --ctstate NEW -j DROP"
+				if err = c.runIptablesInstruction(ctx, instruction); err != nil {
+					return fmt.Errorf("restricting LAN source: %w", err)
+				}
+			}
+			for _, network := range privateIPv6Networks {
+				instruction := "--insert OUTPUT -o " + intf + " -d " + network + " " +
+					c.lanSourceMatch + " -m conntrack --ctstate NEW -j DROP"
+				if err = c.runIP6tablesInstruction(ctx, instruction); err != nil {
+					return fmt.Errorf("restricting LAN source: %w", err)
+				}
+			}
+		}
+	}
+
+	if c.httpProxySourceMatch != "" && c.httpProxyPort != 0 {
+		instruction := fmt.Sprintf("--insert OUTPUT -o lo -p tcp --dport %d %s -m conntrack --ctstate NEW -j DROP",
+			c.httpProxyPort, c.httpProxySourceMatch)
+		if err = c.runMixedIptablesInstruction(ctx, instruction); err != nil {
+			return fmt.Errorf("restricting HTTP proxy source: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// defaultRouteInterfaces returns the unique network interfaces
+// of the default routes, in the order of the default routes.
+func (c *Config) defaultRouteInterfaces() (interfaces []string) {
+	seen := make(map[string]struct{}, len(c.defaultRoutes))
+	for _, defaultRoute := range c.defaultRoutes {
+		if _, ok := seen[defaultRoute.NetInterface]; ok {
+			continue
+		}
+		seen[defaultRoute.NetInterface] = struct{}{}
+		interfaces = append(interfaces, defaultRoute.NetInterface)
+	}
+	return interfaces
+}
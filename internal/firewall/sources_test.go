@@ -0,0 +1,82 @@
+package firewall
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/netlink"
+	"github.com/qdm12/gluetun/internal/routing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_sourceToMatch(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		source     string
+		match      string
+		errWrapped error
+		errMessage string
+	}{
+		"empty": {},
+		"uid": {
+			source: "uid:1000",
+			match:  "-m owner ! --uid-owner 1000",
+		},
+		"mark": {
+			source: "mark:0x1",
+			match:  "-m mark ! --mark 0x1",
+		},
+		"unknown kind": {
+			source:     "ip:10.0.0.1",
+			errWrapped: ErrSourceNotValid,
+			errMessage: "source is not valid: ip:10.0.0.1",
+		},
+		"bad value": {
+			source:     "uid:abc",
+			errWrapped: ErrSourceNotValid,
+			errMessage: "source is not valid: uid:abc",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			match, err := sourceToMatch(testCase.source)
+
+			assert.Equal(t, testCase.match, match)
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
+
+func Test_Config_restrictSources(t *testing.T) {
+	t.Parallel()
+
+	backend := &recordingBackend{}
+	config := &Config{
+		backend: backend,
+		defaultRoutes: []routing.DefaultRoute{
+			{NetInterface: "eth0", Family: netlink.FAMILY_V4},
+			{NetInterface: "eth0", Family: netlink.FAMILY_V6},
+		},
+	}
+	err := config.SetSourcePolicies("uid:1000", "mark:0x2", 8888)
+	require.NoError(t, err)
+
+	err = config.restrictSources(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"--insert OUTPUT -o eth0 -d 10.0.0.0/8 -m owner ! --uid-owner 1000 -m conntrack --ctstate NEW -j DROP",
+		"--insert OUTPUT -o eth0 -d 172.16.0.0/12 -m owner ! --uid-owner 1000 -m conntrack --ctstate NEW -j DROP",
+		"--insert OUTPUT -o eth0 -d 192.168.0.0/16 -m owner ! --uid-owner 1000 -m conntrack --ctstate NEW -j DROP",
+		"--insert OUTPUT -o eth0 -d 169.254.0.0/16 -m owner ! --uid-owner 1000 -m conntrack --ctstate NEW -j DROP",
+		"--insert OUTPUT -o lo -p tcp --dport 8888 -m mark ! --mark 0x2 -m conntrack --ctstate NEW -j DROP",
+	}, backend.instructions)
+}
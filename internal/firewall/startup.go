@@ -0,0 +1,92 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SetStartupPermissive sets the startup policy to permissive, such that
+// direct egress traffic through the default route interfaces is allowed
+// for the duration given once the firewall is enabled, or until the VPN
+// tunnel is up if sooner. This lets DHCP or NTP settle on some networks.
+// It must be called before the firewall is enabled.
+func (c *Config) SetStartupPermissive(duration time.Duration) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	c.startupPermissiveDuration = duration
+}
+
+// applyStartupPolicy logs the startup policy and, for the permissive
+// policy, allows direct egress traffic for the startup duration. It
+// only does so the first time the firewall is enabled before the VPN
+// tunnel is up. It must be called with the state mutex locked.
+func (c *Config) applyStartupPolicy(ctx context.Context) (err error) {
+	if c.tunnelUp || c.startupPolicyApplied {
+		return nil
+	}
+	c.startupPolicyApplied = true
+
+	switch {
+	case c.startupPermissiveDuration > 0:
+	case c.preTunnelStrict:
+		c.logger.Info("startup policy strict: direct egress traffic is blocked until the VPN tunnel is up")
+		return nil
+	default:
+		c.logger.Info("startup policy lan: direct egress traffic is blocked until the VPN tunnel is up, " +
+			"except to the local networks and outbound subnets")
+		return nil
+	}
+
+	for _, instruction := range c.startupPermissiveInstructions("--insert") {
+		err = c.runMixedIptablesInstruction(ctx, instruction)
+		if err != nil {
+			return fmt.Errorf("allowing startup direct egress traffic: %w", err)
+		}
+	}
+
+	c.startupPermissiveTimer = time.AfterFunc(c.startupPermissiveDuration, func() {
+		c.stateMutex.Lock()
+		defer c.stateMutex.Unlock()
+		c.logger.Info("startup permissive duration elapsed")
+		err := c.endStartupPermissive(context.Background())
+		if err != nil {
+			c.logger.Error(err.Error())
+		}
+	})
+	c.logger.Info(fmt.Sprintf("startup policy permissive: direct egress traffic is allowed "+
+		"for %s or until the VPN tunnel is up", c.startupPermissiveDuration))
+	return nil
+}
+
+// endStartupPermissive removes the startup permissive rules, if any.
+// It must be called with the state mutex locked.
+func (c *Config) endStartupPermissive(ctx context.Context) (err error) {
+	if c.startupPermissiveTimer == nil {
+		return nil
+	}
+	c.startupPermissiveTimer.Stop()
+	c.startupPermissiveTimer = nil
+
+	for _, instruction := range c.startupPermissiveInstructions("--delete") {
+		err = c.runMixedIptablesInstruction(ctx, instruction)
+		if err != nil {
+			return fmt.Errorf("removing startup direct egress traffic rule: %w", err)
+		}
+	}
+	c.logger.Info("startup permissive period ended, direct egress traffic is now blocked")
+	return nil
+}
+
+func (c *Config) startupPermissiveInstructions(operation string) (instructions []string) {
+	interfaces := make(map[string]struct{}, len(c.defaultRoutes))
+	for _, defaultRoute := range c.defaultRoutes {
+		if _, ok := interfaces[defaultRoute.NetInterface]; ok {
+			continue
+		}
+		interfaces[defaultRoute.NetInterface] = struct{}{}
+		instructions = append(instructions, fmt.Sprintf("%s OUTPUT -o %s -j ACCEPT",
+			operation, defaultRoute.NetInterface))
+	}
+	return instructions
+}
@@ -0,0 +1,52 @@
+package firewall
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/routing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Config_startupPolicy(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	gomock.InOrder(
+		logger.EXPECT().Info("startup policy permissive: direct egress traffic "+
+			"is allowed for 1h0m0s or until the VPN tunnel is up"),
+		logger.EXPECT().Info("startup permissive period ended, "+
+			"direct egress traffic is now blocked"),
+	)
+	backend := &recordingBackend{}
+	config := &Config{
+		logger:  logger,
+		backend: backend,
+		defaultRoutes: []routing.DefaultRoute{
+			{NetInterface: "eth0"},
+			{NetInterface: "eth0"},
+		},
+	}
+	config.SetStartupPermissive(time.Hour)
+	ctx := context.Background()
+
+	err := config.applyStartupPolicy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--insert OUTPUT -o eth0 -j ACCEPT"}, backend.instructions)
+	require.NotNil(t, config.startupPermissiveTimer)
+
+	// The startup policy is only applied once.
+	backend.instructions = nil
+	err = config.applyStartupPolicy(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, backend.instructions)
+
+	err = config.SetTunnelUp(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--delete OUTPUT -o eth0 -j ACCEPT"}, backend.instructions)
+	assert.Nil(t, config.startupPermissiveTimer)
+}
@@ -12,6 +12,7 @@ import (
 )
 
 //go:generate mockgen -destination=runner_mock_test.go -package $GOPACKAGE github.com/qdm12/golibs/command Runner
+//go:generate mockgen -destination=logger_mock_test.go -package $GOPACKAGE . Logger
 
 func newAppendTestRuleMatcher(path string) *cmdMatcher {
 	return newCmdMatcher(path,
@@ -0,0 +1,213 @@
+package firewall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	ErrPortNotValid            = errors.New("port is not valid")
+	ErrTemporaryPortNotFound   = errors.New("temporary port not found")
+	ErrTemporaryPortTTLInvalid = errors.New("temporary port time to live must be positive")
+	ErrTemporaryPortTTLTooLong = errors.New("temporary port time to live is too long")
+)
+
+// maxTemporaryPortTTL is the maximum time to live of a temporary
+// port, so a port cannot be opened forever by mistake.
+const maxTemporaryPortTTL = 24 * time.Hour
+
+// TemporaryPort is an input port opened for a limited time.
+type TemporaryPort struct {
+	Port      uint16    `json:"port"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// RemainingSeconds is the number of seconds left
+	// before the port is closed.
+	RemainingSeconds uint64 `json:"remaining_seconds"`
+}
+
+type temporaryPort struct {
+	expiresAt time.Time
+	timer     *time.Timer
+	// interfaces are the network interfaces input traffic to
+	// the port is allowed through. Their rules are shared with
+	// the allowed input ports on the same interfaces, and are
+	// only removed once neither of them needs them anymore.
+	interfaces []string
+}
+
+// OpenTemporaryPort allows input traffic to the port given through the
+// default route interfaces, and removes it once the time to live given
+// elapses. Opening a port already temporarily open extends its time to
+// live. The port can also be an allowed input port, in which case its
+// rules are kept once the time to live elapses.
+func (c *Config) OpenTemporaryPort(ctx context.Context, port uint16,
+	ttl time.Duration) (opened TemporaryPort, err error) {
+	switch {
+	case port == 0:
+		return opened, fmt.Errorf("%w: %d", ErrPortNotValid, port)
+	case ttl <= 0:
+		return opened, fmt.Errorf("%w: %s", ErrTemporaryPortTTLInvalid, ttl)
+	case ttl > maxTemporaryPortTTL:
+		return opened, fmt.Errorf("%w: %s must be at most %s",
+			ErrTemporaryPortTTLTooLong, ttl, maxTemporaryPortTTL)
+	}
+
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	var interfaces []string
+	existing, ok := c.temporaryPorts[port]
+	if ok {
+		existing.timer.Stop()
+		interfaces = existing.interfaces
+	} else {
+		interfaces, err = c.allowTemporaryPort(ctx, port)
+		if err != nil {
+			return opened, err
+		}
+	}
+
+	c.temporaryPorts[port] = &temporaryPort{
+		expiresAt:  expiresAt,
+		interfaces: interfaces,
+		timer: time.AfterFunc(ttl, func() {
+			c.closeExpiredTemporaryPort(port, expiresAt)
+		}),
+	}
+	c.logger.Info(fmt.Sprintf("opened port %d until %s", port, expiresAt.Format(time.RFC3339)))
+
+	return makeTemporaryPort(port, expiresAt), nil
+}
+
+// allowTemporaryPort allows input traffic to the port given through
+// the default route interfaces, and returns these interfaces.
+// Rules are only added for the interfaces the port is not
+// already allowed through as an allowed input port.
+func (c *Config) allowTemporaryPort(ctx context.Context, port uint16) (
+	interfaces []string, err error) {
+	interfaces = make([]string, 0, len(c.defaultRoutes))
+	for _, defaultRoute := range c.defaultRoutes {
+		netInterface := defaultRoute.NetInterface
+		_, allowed := c.allowedInputPorts[port][netInterface]
+		if c.enabled && !allowed {
+			c.logger.Info("setting allowed input port " + fmt.Sprint(port) +
+				" through interface " + netInterface + "...")
+			const remove = false
+			err = c.acceptInputToPort(ctx, netInterface, port, remove)
+			if err != nil {
+				_ = c.removeTemporaryPortRules(ctx, port, interfaces)
+				return nil, fmt.Errorf("allowing input to port %d through interface %s: %w",
+					port, netInterface, err)
+			}
+		}
+		interfaces = append(interfaces, netInterface)
+	}
+	return interfaces, nil
+}
+
+// removeTemporaryPortRules removes the rules allowing input traffic
+// to the port given through the interfaces given, except for the
+// interfaces the port is also allowed through as an allowed input port.
+func (c *Config) removeTemporaryPortRules(ctx context.Context, port uint16,
+	interfaces []string) (err error) {
+	for _, netInterface := range interfaces {
+		_, allowed := c.allowedInputPorts[port][netInterface]
+		if !c.enabled || allowed {
+			continue
+		}
+		const remove = true
+		err = c.acceptInputToPort(ctx, netInterface, port, remove)
+		if err != nil {
+			return fmt.Errorf("removing allowed port %d on interface %s: %w",
+				port, netInterface, err)
+		}
+	}
+	return nil
+}
+
+// temporaryPortHasInterface returns true if the port given is
+// a temporary port allowed through the network interface given.
+func (c *Config) temporaryPortHasInterface(port uint16, netInterface string) bool {
+	temporary, ok := c.temporaryPorts[port]
+	if !ok {
+		return false
+	}
+	for _, temporaryInterface := range temporary.interfaces {
+		if temporaryInterface == netInterface {
+			return true
+		}
+	}
+	return false
+}
+
+// CloseTemporaryPort removes the temporary input port given
+// before its time to live elapses.
+func (c *Config) CloseTemporaryPort(ctx context.Context, port uint16) (err error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	existing, ok := c.temporaryPorts[port]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrTemporaryPortNotFound, port)
+	}
+	existing.timer.Stop()
+
+	c.logger.Info("removing temporary port " + fmt.Sprint(port) + "...")
+	err = c.removeTemporaryPortRules(ctx, port, existing.interfaces)
+	if err != nil {
+		return err
+	}
+	delete(c.temporaryPorts, port)
+	return nil
+}
+
+// GetTemporaryPorts returns the temporary input ports
+// with their remaining time, sorted by port.
+func (c *Config) GetTemporaryPorts() (ports []TemporaryPort) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	ports = make([]TemporaryPort, 0, len(c.temporaryPorts))
+	for port, temporary := range c.temporaryPorts {
+		ports = append(ports, makeTemporaryPort(port, temporary.expiresAt))
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i].Port < ports[j].Port
+	})
+	return ports
+}
+
+func (c *Config) closeExpiredTemporaryPort(port uint16, expiresAt time.Time) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	existing, ok := c.temporaryPorts[port]
+	if !ok || !existing.expiresAt.Equal(expiresAt) {
+		// closed or extended in the meantime
+		return
+	}
+
+	err := c.removeTemporaryPortRules(context.Background(), port, existing.interfaces)
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("closing expired port %d: %s", port, err))
+		return
+	}
+	delete(c.temporaryPorts, port)
+	c.logger.Info(fmt.Sprintf("closed expired port %d", port))
+}
+
+func makeTemporaryPort(port uint16, expiresAt time.Time) TemporaryPort {
+	remaining := time.Until(expiresAt)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return TemporaryPort{
+		Port:             port,
+		ExpiresAt:        expiresAt,
+		RemainingSeconds: uint64(remaining.Round(time.Second).Seconds()),
+	}
+}
@@ -0,0 +1,114 @@
+package firewall
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/routing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Config_temporaryPorts(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	// expectOpenedLog expects a port opened log, and returns the message
+	// logged to be checked against the expiration time of the port. It
+	// must be called after the other expected logs since it matches any
+	// message.
+	expectOpenedLog := func() (message *string) {
+		message = new(string)
+		logger.EXPECT().Info(gomock.Any()).Do(func(s string) { *message = s })
+		return message
+	}
+	config := &Config{
+		logger:            logger,
+		backend:           &iptablesBackend{},
+		enabled:           true,
+		defaultRoutes:     []routing.DefaultRoute{{NetInterface: "eth0"}},
+		allowedInputPorts: map[uint16]map[string]struct{}{8000: {"eth0": {}}},
+		temporaryPorts:    make(map[uint16]*temporaryPort),
+		ruleSet:           new(ruleSet),
+	}
+	ctx := context.Background()
+
+	_, err := config.OpenTemporaryPort(ctx, 8080, 0)
+	assert.ErrorIs(t, err, ErrTemporaryPortTTLInvalid)
+	_, err = config.OpenTemporaryPort(ctx, 8080, 48*time.Hour)
+	assert.ErrorIs(t, err, ErrTemporaryPortTTLTooLong)
+	assert.EqualError(t, err, "temporary port time to live is too long: 48h0m0s must be at most 24h0m0s")
+
+	// An allowed input port shares its rules with the temporary port.
+	openedLog := expectOpenedLog()
+	opened, err := config.OpenTemporaryPort(ctx, 8000, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "opened port 8000 until "+opened.ExpiresAt.Format(time.RFC3339), *openedLog)
+	logger.EXPECT().Info("removing temporary port 8000...")
+	err = config.CloseTemporaryPort(ctx, 8000)
+	require.NoError(t, err)
+	assert.Empty(t, config.ruleSet.ipv4)
+	assert.Contains(t, config.allowedInputPorts, uint16(8000))
+
+	logger.EXPECT().Info("setting allowed input port 8080 through interface eth0...")
+	openedLog = expectOpenedLog()
+	opened, err = config.OpenTemporaryPort(ctx, 8080, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "opened port 8080 until "+opened.ExpiresAt.Format(time.RFC3339), *openedLog)
+	assert.Equal(t, uint16(8080), opened.Port)
+	assert.Equal(t, uint64(3600), opened.RemainingSeconds)
+	assert.Equal(t, []string{
+		"--append INPUT -i eth0 -p tcp --dport 8080 -j ACCEPT",
+		"--append INPUT -i eth0 -p udp --dport 8080 -j ACCEPT",
+	}, config.ruleSet.ipv4)
+
+	// Opening it again extends its time to live without adding rules.
+	openedLog = expectOpenedLog()
+	opened, err = config.OpenTemporaryPort(ctx, 8080, 2*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "opened port 8080 until "+opened.ExpiresAt.Format(time.RFC3339), *openedLog)
+	assert.Equal(t, uint64(7200), opened.RemainingSeconds)
+	assert.Len(t, config.ruleSet.ipv4, 2)
+	ports := config.GetTemporaryPorts()
+	require.Len(t, ports, 1)
+	assert.Equal(t, opened.ExpiresAt, ports[0].ExpiresAt)
+
+	// Allowing the temporary port keeps its rules once it is closed.
+	err = config.SetAllowedPort(ctx, 8080, "eth0")
+	require.NoError(t, err)
+	logger.EXPECT().Info("removing temporary port 8080...")
+	err = config.CloseTemporaryPort(ctx, 8080)
+	require.NoError(t, err)
+	assert.Empty(t, config.GetTemporaryPorts())
+	assert.Len(t, config.ruleSet.ipv4, 2)
+	err = config.CloseTemporaryPort(ctx, 8080)
+	assert.ErrorIs(t, err, ErrTemporaryPortNotFound)
+	logger.EXPECT().Info("removing allowed port 8080...")
+	err = config.RemoveAllowedPort(ctx, 8080)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"--delete INPUT -i eth0 -p tcp --dport 8080 -j ACCEPT",
+		"--delete INPUT -i eth0 -p udp --dport 8080 -j ACCEPT",
+	}, config.ruleSet.ipv4[2:])
+
+	// The port is closed once its time to live elapses.
+	logger.EXPECT().Info("setting allowed input port 9090 through interface eth0...")
+	logger.EXPECT().Info("closed expired port 9090")
+	openedLog = expectOpenedLog()
+	opened, err = config.OpenTemporaryPort(ctx, 9090, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, "opened port 9090 until "+opened.ExpiresAt.Format(time.RFC3339), *openedLog)
+	assert.Eventually(t, func() bool {
+		return len(config.GetTemporaryPorts()) == 0
+	}, time.Second, time.Millisecond)
+	config.stateMutex.Lock()
+	defer config.stateMutex.Unlock()
+	assert.NotContains(t, config.allowedInputPorts, uint16(9090))
+	assert.Equal(t, []string{
+		"--delete INPUT -i eth0 -p tcp --dport 9090 -j ACCEPT",
+		"--delete INPUT -i eth0 -p udp --dport 9090 -j ACCEPT",
+	}, config.ruleSet.ipv4[len(config.ruleSet.ipv4)-2:])
+}
@@ -0,0 +1,90 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// Tunnel is an additional Wireguard tunnel running
+// alongside the main VPN connection.
+type Tunnel struct {
+	// Interface is the tunnel network interface name.
+	Interface string
+	// Endpoint is the UDP address of the tunnel server.
+	Endpoint net.UDPAddr
+	// SourcePorts are the TCP and UDP source ports
+	// of the output traffic marked with the Mark.
+	SourcePorts []uint16
+	// Mark is the firewall mark of the traffic
+	// routed through the tunnel.
+	Mark uint32
+}
+
+// SetTunnels sets the additional tunnels running alongside the main
+// VPN connection. Their servers are reachable through the default route
+// interfaces, and output traffic, as well as traffic coming in through
+// the gateway interfaces, is accepted through their interfaces.
+// It must be called before the firewall is enabled.
+func (c *Config) SetTunnels(tunnels []Tunnel) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	c.tunnels = make([]Tunnel, len(tunnels))
+	copy(c.tunnels, tunnels)
+}
+
+// applyTunnels applies the rules of the additional tunnels. It must be
+// called with the state mutex locked, once the firewall rules are applied.
+func (c *Config) applyTunnels(ctx context.Context) (err error) {
+	rules := c.renderTunnelsRules()
+	const remove = false
+	err = c.runUserRules(ctx, rules, remove)
+	if err != nil {
+		return fmt.Errorf("adding tunnels rules: %w", err)
+	}
+	c.tunnelsApplied = rules
+	return nil
+}
+
+// renderTunnelsRules returns the rules to accept traffic to each tunnel
+// server and through each tunnel interface, to mark the traffic from the
+// tunnel source ports and to masquerade traffic going out through the
+// tunnel interface, since its source address may have been chosen for
+// the VPN interface before being marked and rerouted. The tunnels are
+// only set up for IPv4.
+func (c *Config) renderTunnelsRules() (rules []string) {
+	for _, tunnel := range c.tunnels {
+		for _, defaultRoute := range c.defaultRoutes {
+			if defaultRoute.AssignedIP.To4() == nil {
+				continue
+			}
+			rules = append(rules, fmt.Sprintf(
+				"iptables --append OUTPUT -d %s -o %s -p udp -m udp --dport %d -j ACCEPT",
+				tunnel.Endpoint.IP, defaultRoute.NetInterface, tunnel.Endpoint.Port))
+		}
+
+		rules = append(rules, fmt.Sprintf(
+			"iptables --append OUTPUT -o %s -j ACCEPT", tunnel.Interface))
+
+		for _, port := range tunnel.SourcePorts {
+			for _, protocol := range []string{"tcp", "udp"} {
+				rules = append(rules, fmt.Sprintf(
+					"iptables -t mangle --append OUTPUT -p %s --sport %d -j MARK --set-mark 0x%x",
+					protocol, port, tunnel.Mark))
+			}
+		}
+
+		for _, intf := range c.gatewayIntfs {
+			rules = append(rules,
+				fmt.Sprintf("iptables --append FORWARD -i %s -o %s -j ACCEPT",
+					intf, tunnel.Interface),
+				fmt.Sprintf("iptables --append FORWARD -i %s -o %s -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+					tunnel.Interface, intf),
+			)
+		}
+
+		rules = append(rules, fmt.Sprintf(
+			"iptables -t nat --append POSTROUTING -o %s -j MASQUERADE", tunnel.Interface))
+	}
+	return rules
+}
@@ -0,0 +1,50 @@
+package firewall
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/nftables"
+	"github.com/qdm12/gluetun/internal/routing"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Config_renderTunnelsRules(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{
+		defaultRoutes: []routing.DefaultRoute{
+			{NetInterface: "eth0", AssignedIP: net.IPv4(172, 17, 0, 2)},
+			{NetInterface: "eth0", AssignedIP: net.ParseIP("fd00::2")},
+		},
+	}
+	assert.Empty(t, config.renderTunnelsRules())
+
+	config.SetTunnels([]Tunnel{{
+		Interface:   "tunnel1",
+		Endpoint:    net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 51820},
+		SourcePorts: []uint16{8000},
+		Mark:        51831,
+	}})
+	expected := []string{
+		"iptables --append OUTPUT -d 1.2.3.4 -o eth0 -p udp -m udp --dport 51820 -j ACCEPT",
+		"iptables --append OUTPUT -o tunnel1 -j ACCEPT",
+		"iptables -t mangle --append OUTPUT -p tcp --sport 8000 -j MARK --set-mark 0xca77",
+		"iptables -t mangle --append OUTPUT -p udp --sport 8000 -j MARK --set-mark 0xca77",
+		"iptables -t nat --append POSTROUTING -o tunnel1 -j MASQUERADE",
+	}
+	assert.Equal(t, expected, config.renderTunnelsRules())
+
+	config.SetGatewayInterfaces([]string{"eth1"})
+	expected = []string{
+		"iptables --append OUTPUT -d 1.2.3.4 -o eth0 -p udp -m udp --dport 51820 -j ACCEPT",
+		"iptables --append OUTPUT -o tunnel1 -j ACCEPT",
+		"iptables -t mangle --append OUTPUT -p tcp --sport 8000 -j MARK --set-mark 0xca77",
+		"iptables -t mangle --append OUTPUT -p udp --sport 8000 -j MARK --set-mark 0xca77",
+		"iptables --append FORWARD -i eth1 -o tunnel1 -j ACCEPT",
+		"iptables --append FORWARD -i tunnel1 -o eth1 -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+		"iptables -t nat --append POSTROUTING -o tunnel1 -j MASQUERADE",
+	}
+	assert.Equal(t, expected, config.renderTunnelsRules())
+	assertNftablesTranslatable(t, expected, nftables.TableFamilyIPv4)
+}
@@ -12,6 +12,14 @@ func (c *Config) SetVPNConnection(ctx context.Context,
 	c.stateMutex.Lock()
 	defer c.stateMutex.Unlock()
 
+	defer func() {
+		c.egressFilterVPNIntf = vpnIntf
+		egressFilterErr := c.updateEgressFilter()
+		if err == nil {
+			err = egressFilterErr
+		}
+	}()
+
 	if !c.enabled {
 		c.logger.Info("firewall disabled, only updating internal VPN connection")
 		c.vpnConnection = connection
@@ -35,9 +43,14 @@ func (c *Config) SetVPNConnection(ctx context.Context,
 	c.vpnConnection = models.Connection{}
 
 	if c.vpnIntf != "" {
-		if err = c.acceptOutputThroughInterface(ctx, c.vpnIntf, remove); err != nil {
+		if err = c.acceptOutputThroughVPNInterface(ctx, c.vpnIntf, c.vpnOutputPorts,
+			c.vpnOutputIPs, c.vpnOutputDNSServers, c.vpnOutputIPsRestricted, remove); err != nil {
 			c.logger.Error("cannot remove outdated VPN interface rule: " + err.Error())
 		}
+		if err = c.acceptCountryNetworks(ctx, c.vpnIntf, c.countryNetworks,
+			c.countryNetworksAllowed, c.countryDNSServers, remove); err != nil {
+			c.logger.Error("cannot remove outdated country networks rules: " + err.Error())
+		}
 	}
 	c.vpnIntf = ""
 
@@ -50,10 +63,28 @@ func (c *Config) SetVPNConnection(ctx context.Context,
 	}
 	c.vpnConnection = connection
 
-	if err = c.acceptOutputThroughInterface(ctx, vpnIntf, remove); err != nil {
+	if err = c.acceptOutputThroughVPNInterface(ctx, vpnIntf, c.vpnOutputPorts,
+		c.vpnOutputIPs, c.vpnOutputDNSServers, c.vpnOutputIPsRestricted, remove); err != nil {
 		return fmt.Errorf("accepting output traffic through interface %s: %w", vpnIntf, err)
 	}
 	c.vpnIntf = vpnIntf
 
+	if err = c.acceptCountryNetworks(ctx, vpnIntf, c.countryNetworks,
+		c.countryNetworksAllowed, c.countryDNSServers, remove); err != nil {
+		return fmt.Errorf("restricting output traffic to country networks: %w", err)
+	}
+
+	if err = c.updateRulesTemplate(ctx); err != nil {
+		return fmt.Errorf("updating templated rules: %w", err)
+	}
+
+	if err = c.updatePortForwardTarget(ctx); err != nil {
+		return fmt.Errorf("updating port forward target rules: %w", err)
+	}
+
+	if err = c.updateGateway(ctx); err != nil {
+		return fmt.Errorf("updating gateway rules: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,80 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+// HopConnection is a connection to a VPN server, either the
+// next hop or the exit VPN server, going through the interface of
+// a VPN hop.
+type HopConnection struct {
+	Connection models.Connection
+	Interface  string
+}
+
+// SetVPNHopConnections allows the connections given through the
+// interfaces of the VPN hops, replacing any previous hop connections.
+// An empty slice only removes the previous hop connections.
+func (c *Config) SetVPNHopConnections(ctx context.Context,
+	hopConnections []HopConnection) (err error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	if !c.enabled {
+		c.vpnHopConnections = copyHopConnections(hopConnections)
+		return nil
+	}
+
+	if hopConnectionsAreEqual(c.vpnHopConnections, hopConnections) {
+		return nil
+	}
+
+	remove := true
+	for _, hopConnection := range c.vpnHopConnections {
+		err = c.acceptOutputTrafficToVPN(ctx, hopConnection.Interface,
+			hopConnection.Connection, remove)
+		if err != nil {
+			c.logger.Error("cannot remove outdated VPN hop connection rule: " + err.Error())
+		}
+	}
+	c.vpnHopConnections = nil
+
+	remove = false
+	for _, hopConnection := range hopConnections {
+		c.logger.Info("allowing VPN connection through hop interface " +
+			hopConnection.Interface + "...")
+		err = c.acceptOutputTrafficToVPN(ctx, hopConnection.Interface,
+			hopConnection.Connection, remove)
+		if err != nil {
+			return fmt.Errorf("allowing output traffic to VPN server through interface %s: %w",
+				hopConnection.Interface, err)
+		}
+		c.vpnHopConnections = append(c.vpnHopConnections, hopConnection)
+	}
+
+	return nil
+}
+
+func copyHopConnections(original []HopConnection) (copied []HopConnection) {
+	if len(original) == 0 {
+		return nil
+	}
+	copied = make([]HopConnection, len(original))
+	copy(copied, original)
+	return copied
+}
+
+func hopConnectionsAreEqual(a, b []HopConnection) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Connection.Equal(b[i].Connection) || a[i].Interface != b[i].Interface {
+			return false
+		}
+	}
+	return true
+}
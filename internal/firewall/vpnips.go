@@ -0,0 +1,60 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// RestrictVPNOutputIPs restricts the output traffic through the VPN
+// interface to the destination IP addresses given, and to the DNS
+// servers given so names can still be resolved through the VPN.
+// It can be called again to update the IP addresses allowed, and
+// setting no IP address only allows traffic to the DNS servers
+// through the VPN interface.
+func (c *Config) RestrictVPNOutputIPs(ctx context.Context,
+	ips, dnsServers []net.IP) (err error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	if !c.enabled || c.vpnIntf == "" {
+		c.vpnOutputIPs = copyIPs(ips)
+		c.vpnOutputDNSServers = copyIPs(dnsServers)
+		c.vpnOutputIPsRestricted = true
+		return nil
+	}
+
+	c.logger.Info(fmt.Sprintf("restricting output through VPN interface %s to %d IP addresses...",
+		c.vpnIntf, len(ips)))
+
+	// Add the new rules first so traffic to the IP addresses
+	// allowed by both sets of rules is not interrupted.
+	const ipsRestricted = true
+	remove := false
+	err = c.acceptOutputThroughVPNInterface(ctx, c.vpnIntf, c.vpnOutputPorts,
+		ips, dnsServers, ipsRestricted, remove)
+	if err != nil {
+		return fmt.Errorf("accepting output traffic through interface %s: %w", c.vpnIntf, err)
+	}
+
+	remove = true
+	err = c.acceptOutputThroughVPNInterface(ctx, c.vpnIntf, c.vpnOutputPorts,
+		c.vpnOutputIPs, c.vpnOutputDNSServers, c.vpnOutputIPsRestricted, remove)
+	if err != nil {
+		return fmt.Errorf("removing output traffic rules through interface %s: %w", c.vpnIntf, err)
+	}
+	c.vpnOutputIPs = copyIPs(ips)
+	c.vpnOutputDNSServers = copyIPs(dnsServers)
+	c.vpnOutputIPsRestricted = true
+
+	return nil
+}
+
+func copyIPs(ips []net.IP) (copied []net.IP) {
+	copied = make([]net.IP, len(ips))
+	for i, ip := range ips {
+		copied[i] = make(net.IP, len(ip))
+		copy(copied[i], ip)
+	}
+	return copied
+}
@@ -0,0 +1,76 @@
+package firewall
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Config_acceptOutputThroughVPNInterface(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		ports         []uint16
+		ips           []net.IP
+		dnsServers    []net.IP
+		ipsRestricted bool
+		ipv4Rules     []string
+	}{
+		"unrestricted": {
+			ipv4Rules: []string{
+				"--append OUTPUT -o tun0 -j ACCEPT",
+			},
+		},
+		"ports only": {
+			ports: []uint16{443},
+			ipv4Rules: []string{
+				"--append OUTPUT -o tun0 -p tcp --dport 443 -j ACCEPT",
+				"--append OUTPUT -o tun0 -p udp --dport 443 -j ACCEPT",
+			},
+		},
+		"no IP address allowed": {
+			dnsServers:    []net.IP{net.IPv4(1, 1, 1, 1)},
+			ipsRestricted: true,
+			ipv4Rules: []string{
+				"--append OUTPUT -o tun0 -d 1.1.1.1 -j ACCEPT",
+			},
+		},
+		"no IP address nor DNS server allowed": {
+			ipsRestricted: true,
+		},
+		"IP addresses and ports": {
+			ports:         []uint16{443},
+			ips:           []net.IP{net.IPv4(1, 2, 3, 4), net.ParseIP("2001:db8::1")},
+			dnsServers:    []net.IP{net.IPv4(1, 1, 1, 1), net.ParseIP("2606:4700:4700::1111")},
+			ipsRestricted: true,
+			ipv4Rules: []string{
+				"--append OUTPUT -o tun0 -d 1.1.1.1 -j ACCEPT",
+				"--append OUTPUT -o tun0 -d 1.2.3.4 -p tcp --dport 443 -j ACCEPT",
+				"--append OUTPUT -o tun0 -d 1.2.3.4 -p udp --dport 443 -j ACCEPT",
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := &Config{
+				backend: &iptablesBackend{},
+				ruleSet: new(ruleSet),
+			}
+
+			const remove = false
+			err := config.acceptOutputThroughVPNInterface(context.Background(), "tun0",
+				testCase.ports, testCase.ips, testCase.dnsServers, testCase.ipsRestricted, remove)
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.ipv4Rules, config.ruleSet.ipv4)
+			assert.Empty(t, config.ruleSet.ipv6)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// SetVPNOutputPorts restricts the output traffic through the VPN
+// interface to the destination ports given. Setting no port lifts
+// the restriction and allows all output traffic through the VPN
+// interface.
+func (c *Config) SetVPNOutputPorts(ctx context.Context, ports []uint16) (err error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	if !c.enabled || c.vpnIntf == "" {
+		c.vpnOutputPorts = ports
+		return nil
+	}
+
+	if len(ports) == 0 {
+		c.logger.Info("allowing all output ports through VPN interface " + c.vpnIntf + "...")
+	} else {
+		c.logger.Info(fmt.Sprintf("restricting output ports through VPN interface %s to %v...",
+			c.vpnIntf, ports))
+	}
+
+	// Add the new rules first so traffic on the ports
+	// allowed by both sets of rules is not interrupted.
+	remove := false
+	err = c.acceptOutputThroughVPNInterface(ctx, c.vpnIntf, ports,
+		c.vpnOutputIPs, c.vpnOutputDNSServers, c.vpnOutputIPsRestricted, remove)
+	if err != nil {
+		return fmt.Errorf("accepting output traffic through interface %s: %w", c.vpnIntf, err)
+	}
+
+	remove = true
+	err = c.acceptOutputThroughVPNInterface(ctx, c.vpnIntf, c.vpnOutputPorts,
+		c.vpnOutputIPs, c.vpnOutputDNSServers, c.vpnOutputIPsRestricted, remove)
+	if err != nil {
+		return fmt.Errorf("removing output traffic rules through interface %s: %w", c.vpnIntf, err)
+	}
+	c.vpnOutputPorts = ports
+
+	return nil
+}
+
+// acceptOutputThroughVPNInterface accepts output traffic through the VPN
+// interface to the destination ports given, or to all ports if no port
+// is given. If ipsRestricted is true, traffic is only accepted to the
+// destination IP addresses given, as well as all traffic to the DNS
+// servers given so the DNS server can still resolve through the VPN.
+func (c *Config) acceptOutputThroughVPNInterface(ctx context.Context,
+	intf string, ports []uint16, ips, dnsServers []net.IP, ipsRestricted bool,
+	remove bool) (err error) {
+	if !ipsRestricted {
+		if len(ports) == 0 {
+			return c.acceptOutputThroughInterface(ctx, intf, remove)
+		}
+		return c.runMixedIptablesInstructions(ctx,
+			makeVPNOutputInstructions(intf, "", ports, remove))
+	}
+
+	for _, dnsServer := range dnsServers {
+		instructions := makeVPNOutputInstructions(intf, "-d "+dnsServer.String()+" ", nil, remove)
+		err = c.runIPInstructions(ctx, dnsServer, instructions)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ip := range ips {
+		instructions := makeVPNOutputInstructions(intf, "-d "+ip.String()+" ", ports, remove)
+		err = c.runIPInstructions(ctx, ip, instructions)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runIPInstructions runs the instructions given with iptables if the
+// IP address given is IPv4, or with ip6tables if it is IPv6 and IPv6
+// is supported.
+func (c *Config) runIPInstructions(ctx context.Context, ip net.IP,
+	instructions []string) (err error) {
+	switch {
+	case ip.To4() != nil:
+		return c.runIptablesInstructions(ctx, instructions)
+	case !c.backend.supportsIPv6():
+		return nil
+	default:
+		return c.runIP6tablesInstructions(ctx, instructions)
+	}
+}
+
+// makeVPNOutputInstructions returns the instructions to accept output
+// traffic through the VPN interface with the destination flag given,
+// which can be empty, to the ports given or to all ports if no port
+// is given.
+func makeVPNOutputInstructions(intf, destinationFlag string,
+	ports []uint16, remove bool) (instructions []string) {
+	if len(ports) == 0 {
+		return []string{fmt.Sprintf("%s OUTPUT -o %s %s-j ACCEPT",
+			appendOrDelete(remove), intf, destinationFlag)}
+	}
+
+	instructions = make([]string, 0, 2*len(ports)) //nolint:gomnd
+	for _, port := range ports {
+		instructions = append(instructions,
+			fmt.Sprintf("%s OUTPUT -o %s %s-p tcp --dport %d -j ACCEPT",
+				appendOrDelete(remove), intf, destinationFlag, port),
+			fmt.Sprintf("%s OUTPUT -o %s %s-p udp --dport %d -j ACCEPT",
+				appendOrDelete(remove), intf, destinationFlag, port),
+		)
+	}
+	return instructions
+}
@@ -0,0 +1,54 @@
+package firewall
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// SetVPNRoutes sets the only destinations routed through the VPN,
+// such that output traffic through the default route interfaces is
+// accepted except to these destinations, which can only be reached
+// through the VPN. Setting no route keeps all output traffic through
+// the VPN. It must be called before the firewall is enabled.
+func (c *Config) SetVPNRoutes(routes []net.IPNet) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	c.vpnRoutes = copyIPNets(routes)
+}
+
+// acceptOutputOutsideVPNRoutes appends the rules dropping output traffic
+// to the VPN routes and then accepting all other output traffic through
+// each default route interface. It does nothing if no VPN route is set.
+func (c *Config) acceptOutputOutsideVPNRoutes(ctx context.Context) (err error) {
+	if len(c.vpnRoutes) == 0 {
+		return nil
+	}
+
+	for _, defaultRoute := range c.defaultRoutes {
+		ipv4 := defaultRoute.AssignedIP.To4() != nil
+		if !ipv4 && !c.backend.supportsIPv6() {
+			continue
+		}
+
+		instructions := make([]string, 0, len(c.vpnRoutes)+1)
+		for _, route := range c.vpnRoutes {
+			if (route.IP.To4() != nil) != ipv4 {
+				continue
+			}
+			instructions = append(instructions, fmt.Sprintf("--append OUTPUT -o %s -d %s -j DROP",
+				defaultRoute.NetInterface, route.String()))
+		}
+		instructions = append(instructions, "--append OUTPUT -o "+defaultRoute.NetInterface+" -j ACCEPT")
+
+		if ipv4 {
+			err = c.runIptablesInstructions(ctx, instructions)
+		} else {
+			err = c.runIP6tablesInstructions(ctx, instructions)
+		}
+		if err != nil {
+			return fmt.Errorf("accepting output outside VPN routes: %w", err)
+		}
+	}
+	return nil
+}
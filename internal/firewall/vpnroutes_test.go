@@ -0,0 +1,43 @@
+package firewall
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/routing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Config_acceptOutputOutsideVPNRoutes(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{
+		backend: &iptablesBackend{},
+		defaultRoutes: []routing.DefaultRoute{{
+			NetInterface: "eth0",
+			AssignedIP:   net.IPv4(172, 17, 0, 2),
+		}},
+		ruleSet: new(ruleSet),
+	}
+	ctx := context.Background()
+
+	err := config.acceptOutputOutsideVPNRoutes(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, config.ruleSet.ipv4)
+
+	config.SetVPNRoutes([]net.IPNet{
+		{IP: net.IP{10, 8, 0, 0}, Mask: net.CIDRMask(16, 32)},
+		{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(32, 128)},
+	})
+	err = config.acceptOutputOutsideVPNRoutes(ctx)
+	require.NoError(t, err)
+
+	expected := []string{
+		"--append OUTPUT -o eth0 -d 10.8.0.0/16 -j DROP",
+		"--append OUTPUT -o eth0 -j ACCEPT",
+	}
+	assert.Equal(t, expected, config.ruleSet.ipv4)
+	assert.Empty(t, config.ruleSet.ipv6)
+}
@@ -0,0 +1,198 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrDataOffsetOutOfRange = errors.New("data offset is out of range")
+	ErrDataTypeUnknown      = errors.New("data type is unknown")
+	ErrMapKeyNotString      = errors.New("map key is not a string")
+)
+
+// Data types from the MaxMind DB format specification.
+const (
+	typeExtended = 0
+	typePointer  = 1
+	typeString   = 2
+	typeDouble   = 3
+	typeBytes    = 4
+	typeUint16   = 5
+	typeUint32   = 6
+	typeMap      = 7
+	typeInt32    = 8
+	typeUint64   = 9
+	typeUint128  = 10
+	typeArray    = 11
+	typeBool     = 14
+	typeFloat    = 15
+)
+
+// decoder decodes values of a MaxMind DB data section,
+// where pointers are offsets relative to the section start.
+type decoder struct {
+	data []byte
+}
+
+// decode decodes the value at the offset given, and returns
+// the offset following it. Maps decode to map[string]interface{},
+// arrays to []interface{}, unsigned integers to uint64 except for
+// uint128 decoding to []byte, and floats to float64.
+func (d *decoder) decode(offset uint) (value interface{}, next uint, err error) {
+	dataType, size, offset, err := d.decodeControl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if dataType == typePointer {
+		pointer, next, err := d.decodePointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err = d.decode(pointer)
+		return value, next, err
+	}
+
+	if dataType == typeBool {
+		return size != 0, offset, nil
+	}
+
+	if dataType == typeMap {
+		return d.decodeMap(size, offset)
+	} else if dataType == typeArray {
+		return d.decodeArray(size, offset)
+	}
+
+	end := offset + size
+	if end > uint(len(d.data)) {
+		return nil, 0, fmt.Errorf("%w: %d", ErrDataOffsetOutOfRange, end)
+	}
+	b := d.data[offset:end]
+
+	switch dataType {
+	case typeString:
+		return string(b), end, nil
+	case typeBytes, typeUint128:
+		return b, end, nil
+	case typeDouble:
+		const doubleSize = 8
+		if size != doubleSize {
+			return nil, 0, fmt.Errorf("%w: double of %d bytes", ErrDataTypeUnknown, size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), end, nil
+	case typeFloat:
+		const floatSize = 4
+		if size != floatSize {
+			return nil, 0, fmt.Errorf("%w: float of %d bytes", ErrDataTypeUnknown, size)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), end, nil
+	case typeUint16, typeUint32, typeUint64, typeInt32:
+		var integer uint64
+		for _, byteValue := range b {
+			integer = integer<<8 | uint64(byteValue) //nolint:gomnd
+		}
+		return integer, end, nil
+	default:
+		return nil, 0, fmt.Errorf("%w: %d", ErrDataTypeUnknown, dataType)
+	}
+}
+
+// decodeControl decodes the control byte at the offset given, and
+// any extended type and size bytes following it. For pointers, the
+// size returned are the 5 bits of the control byte unchanged.
+func (d *decoder) decodeControl(offset uint) (dataType, size, next uint, err error) {
+	if offset >= uint(len(d.data)) {
+		return 0, 0, 0, fmt.Errorf("%w: %d", ErrDataOffsetOutOfRange, offset)
+	}
+	control := d.data[offset]
+	offset++
+
+	dataType = uint(control >> 5) //nolint:gomnd
+	size = uint(control & 0x1f)   //nolint:gomnd
+	if dataType == typeExtended {
+		if offset >= uint(len(d.data)) {
+			return 0, 0, 0, fmt.Errorf("%w: %d", ErrDataOffsetOutOfRange, offset)
+		}
+		const extendedTypeOffset = 7
+		dataType = uint(d.data[offset]) + extendedTypeOffset
+		offset++
+	}
+
+	if dataType == typePointer {
+		return dataType, size, offset, nil
+	}
+
+	const oneByte, twoBytes, threeBytes = 29, 30, 31
+	var extraBytes, base uint
+	switch size {
+	case oneByte:
+		extraBytes, base = 1, 29 //nolint:gomnd
+	case twoBytes:
+		extraBytes, base = 2, 285 //nolint:gomnd
+	case threeBytes:
+		extraBytes, base = 3, 65821 //nolint:gomnd
+	default:
+		return dataType, size, offset, nil
+	}
+
+	if offset+extraBytes > uint(len(d.data)) {
+		return 0, 0, 0, fmt.Errorf("%w: %d", ErrDataOffsetOutOfRange, offset+extraBytes)
+	}
+	size = 0
+	for _, byteValue := range d.data[offset : offset+extraBytes] {
+		size = size<<8 | uint(byteValue) //nolint:gomnd
+	}
+	return dataType, base + size, offset + extraBytes, nil
+}
+
+func (d *decoder) decodePointer(size, offset uint) (pointer, next uint, err error) {
+	pointerSize := ((size >> 3) & 0x3) + 1 //nolint:gomnd
+	if offset+pointerSize > uint(len(d.data)) {
+		return 0, 0, fmt.Errorf("%w: %d", ErrDataOffsetOutOfRange, offset+pointerSize)
+	}
+
+	if pointerSize != 4 { //nolint:gomnd
+		pointer = size & 0x7 //nolint:gomnd
+	}
+	for _, byteValue := range d.data[offset : offset+pointerSize] {
+		pointer = pointer<<8 | uint(byteValue) //nolint:gomnd
+	}
+
+	pointerBases := [...]uint{0, 0, 2048, 526336, 0}
+	return pointer + pointerBases[pointerSize], offset + pointerSize, nil
+}
+
+func (d *decoder) decodeMap(size, offset uint) (value interface{}, next uint, err error) {
+	m := make(map[string]interface{}, size)
+	for i := uint(0); i < size; i++ {
+		var key, mapValue interface{}
+		key, offset, err = d.decode(offset)
+		if err != nil {
+			return nil, 0, fmt.Errorf("decoding map key: %w", err)
+		}
+		keyString, ok := key.(string)
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: %T", ErrMapKeyNotString, key)
+		}
+		mapValue, offset, err = d.decode(offset)
+		if err != nil {
+			return nil, 0, fmt.Errorf("decoding map value for key %s: %w", keyString, err)
+		}
+		m[keyString] = mapValue
+	}
+	return m, offset, nil
+}
+
+func (d *decoder) decodeArray(size, offset uint) (value interface{}, next uint, err error) {
+	array := make([]interface{}, size)
+	for i := range array {
+		array[i], offset, err = d.decode(offset)
+		if err != nil {
+			return nil, 0, fmt.Errorf("decoding array element %d: %w", i, err)
+		}
+	}
+	return array, offset, nil
+}
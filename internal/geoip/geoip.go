@@ -0,0 +1,249 @@
+// Package geoip reads MaxMind DB (MMDB) country databases, such as
+// GeoLite2 Country, to find the networks of a set of countries.
+package geoip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+var (
+	ErrMetadataNotFound     = errors.New("metadata not found")
+	ErrMetadataNotValid     = errors.New("metadata is not valid")
+	ErrRecordSizeNotValid   = errors.New("record size is not valid")
+	ErrSearchTreeOutOfRange = errors.New("search tree is out of range")
+)
+
+// Database is a MaxMind DB database loaded in memory.
+type Database struct {
+	tree       []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	data       decoder
+}
+
+// Open reads the MaxMind DB database file at the path given.
+func Open(path string) (database *Database, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading database file: %w", err)
+	}
+	return parse(b)
+}
+
+func parse(b []byte) (database *Database, err error) {
+	metadataMarker := []byte("\xab\xcd\xefMaxMind.com")
+	markerIndex := bytes.LastIndex(b, metadataMarker)
+	if markerIndex == -1 {
+		return nil, ErrMetadataNotFound
+	}
+
+	metadataDecoder := decoder{data: b[markerIndex+len(metadataMarker):]}
+	metadataValue, _, err := metadataDecoder.decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("decoding metadata: %w", err)
+	}
+	metadata, ok := metadataValue.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: metadata is a %T", ErrMetadataNotValid, metadataValue)
+	}
+
+	database = new(Database)
+	fields := map[string]*uint{
+		"node_count":  &database.nodeCount,
+		"record_size": &database.recordSize,
+		"ip_version":  &database.ipVersion,
+	}
+	for key, field := range fields {
+		value, ok := metadata[key].(uint64)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is missing or not an integer", ErrMetadataNotValid, key)
+		}
+		*field = uint(value)
+	}
+
+	switch database.recordSize {
+	case 24, 28, 32: //nolint:gomnd
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrRecordSizeNotValid, database.recordSize)
+	}
+
+	const bitsPerByte, recordsPerNode, dataSectionSeparator = 8, 2, 16
+	treeSize := database.nodeCount * database.recordSize * recordsPerNode / bitsPerByte
+	if treeSize+dataSectionSeparator > uint(markerIndex) {
+		return nil, fmt.Errorf("%w: %d bytes", ErrSearchTreeOutOfRange, treeSize)
+	}
+	database.tree = b[:treeSize]
+	database.data = decoder{data: b[treeSize+dataSectionSeparator : markerIndex]}
+
+	return database, nil
+}
+
+// CountryNetworks returns the networks located in any of the countries
+// given, as ISO 3166-1 alpha-2 codes such as "US". The country of the
+// network is used, falling back on its registered country.
+func (d *Database) CountryNetworks(countries []string) (networks []net.IPNet, err error) {
+	countriesSet := make(map[string]struct{}, len(countries))
+	for _, country := range countries {
+		countriesSet[strings.ToUpper(country)] = struct{}{}
+	}
+
+	// Many networks share the same data record,
+	// so each record is only decoded once.
+	matchingRecords := make(map[uint]bool)
+
+	bitCount := uint(net.IPv6len * 8) //nolint:gomnd
+	if d.ipVersion == 4 {             //nolint:gomnd
+		bitCount = net.IPv4len * 8 //nolint:gomnd
+	}
+
+	// In IPv6 databases, IPv4 networks are in ::/96 and aliased
+	// from other networks, such as ::ffff:0:0/96, pointing to the
+	// same node. The aliases are skipped to not list networks twice.
+	const ipv4SubtreeDepth = 96
+	ipv4Node, err := d.followZeros(ipv4SubtreeDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	type searchNode struct {
+		node  uint
+		ip    []byte
+		depth uint
+	}
+	stack := []searchNode{{ip: make([]byte, bitCount/8)}} //nolint:gomnd
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current.depth == bitCount {
+			continue // malformed search tree
+		}
+
+		for bit := uint(0); bit < 2; bit++ { //nolint:gomnd
+			record, err := d.readRecord(current.node, bit)
+			if err != nil {
+				return nil, err
+			}
+
+			ip := make([]byte, len(current.ip))
+			copy(ip, current.ip)
+			if bit == 1 {
+				ip[current.depth/8] |= 0x80 >> (current.depth % 8) //nolint:gomnd
+			}
+			depth := current.depth + 1
+
+			switch {
+			case record < d.nodeCount:
+				isAlias := bitCount == net.IPv6len*8 && record == ipv4Node && //nolint:gomnd
+					depth != ipv4SubtreeDepth
+				if !isAlias {
+					stack = append(stack, searchNode{node: record, ip: ip, depth: depth})
+				}
+			case record == d.nodeCount: // no data
+			default:
+				const dataSectionSeparator = 16
+				offset := record - d.nodeCount - dataSectionSeparator
+				matching, ok := matchingRecords[offset]
+				if !ok {
+					matching, err = d.recordMatches(offset, countriesSet)
+					if err != nil {
+						return nil, err
+					}
+					matchingRecords[offset] = matching
+				}
+				if matching {
+					networks = append(networks, makeNetwork(ip, depth, bitCount))
+				}
+			}
+		}
+	}
+
+	return networks, nil
+}
+
+// followZeros returns the node reached following the left
+// records of each node, for the number of bits given.
+func (d *Database) followZeros(bits uint) (node uint, err error) {
+	if d.ipVersion == 4 { //nolint:gomnd
+		return 0, nil
+	}
+	for i := uint(0); i < bits && node < d.nodeCount; i++ {
+		node, err = d.readRecord(node, 0)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return node, nil
+}
+
+// readRecord returns the left record of the node given
+// if bit is 0, and its right record otherwise.
+func (d *Database) readRecord(node, bit uint) (record uint, err error) {
+	nodeSize := d.recordSize * 2 / 8 //nolint:gomnd
+	offset := node * nodeSize
+	if offset+nodeSize > uint(len(d.tree)) {
+		return 0, fmt.Errorf("%w: node %d", ErrSearchTreeOutOfRange, node)
+	}
+	b := d.tree[offset : offset+nodeSize]
+
+	switch d.recordSize {
+	case 24: //nolint:gomnd
+		b = b[bit*3 : bit*3+3]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+	case 28: //nolint:gomnd
+		if bit == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6]), nil
+	default: // 32
+		b = b[bit*4 : bit*4+4]
+		return uint(b[0])<<24 | uint(b[1])<<16 | uint(b[2])<<8 | uint(b[3]), nil
+	}
+}
+
+func (d *Database) recordMatches(offset uint, countries map[string]struct{}) (
+	matches bool, err error) {
+	value, _, err := d.data.decode(offset)
+	if err != nil {
+		return false, fmt.Errorf("decoding data record: %w", err)
+	}
+
+	record, ok := value.(map[string]interface{})
+	if !ok {
+		return false, nil
+	}
+
+	for _, key := range []string{"country", "registered_country"} {
+		country, ok := record[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		code, ok := country["iso_code"].(string)
+		if !ok {
+			continue
+		}
+		_, matches = countries[code]
+		return matches, nil
+	}
+	return false, nil
+}
+
+func makeNetwork(ip []byte, depth, bitCount uint) (network net.IPNet) {
+	const ipv4SubtreeDepth = 96
+	if bitCount == net.IPv6len*8 && depth >= ipv4SubtreeDepth && //nolint:gomnd
+		bytes.Equal(ip[:12], make([]byte, 12)) { //nolint:gomnd
+		return net.IPNet{
+			IP:   net.IP(ip[12:]),
+			Mask: net.CIDRMask(int(depth-ipv4SubtreeDepth), net.IPv4len*8), //nolint:gomnd
+		}
+	}
+	return net.IPNet{
+		IP:   net.IP(ip),
+		Mask: net.CIDRMask(int(depth), int(bitCount)),
+	}
+}
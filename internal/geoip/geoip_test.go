@@ -0,0 +1,83 @@
+package geoip
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeString(s string) []byte {
+	return append([]byte{0x40 | byte(len(s))}, s...)
+}
+
+func encodeMap(entries ...[]byte) (b []byte) {
+	const keyValueParts = 2
+	b = []byte{0xe0 | byte(len(entries)/keyValueParts)}
+	for _, entry := range entries {
+		b = append(b, entry...)
+	}
+	return b
+}
+
+func encodeUint32(value byte) []byte {
+	return []byte{0xc1, value}
+}
+
+// makeTestDatabase returns an IPv4 database where 64.0.0.0/2 is
+// located in FR, 128.0.0.0/1 is registered in US and 0.0.0.0/2
+// has no data, with records of 24 bits.
+func makeTestDatabase() []byte {
+	frRecord := encodeMap(encodeString("country"),
+		encodeMap(encodeString("iso_code"), encodeString("FR")))
+	usRecord := encodeMap(encodeString("registered_country"),
+		encodeMap(encodeString("iso_code"), encodeString("US")))
+
+	const nodeCount, dataSectionSeparator = 2, 16
+	frPointer := byte(nodeCount + dataSectionSeparator)
+	usPointer := frPointer + byte(len(frRecord))
+	tree := []byte{
+		0, 0, 1, 0, 0, usPointer, // node 0
+		0, 0, nodeCount, 0, 0, frPointer, // node 1
+	}
+
+	b := append(tree, make([]byte, dataSectionSeparator)...)
+	b = append(b, frRecord...)
+	b = append(b, usRecord...)
+	b = append(b, "\xab\xcd\xefMaxMind.com"...)
+	b = append(b, encodeMap(
+		encodeString("node_count"), encodeUint32(nodeCount),
+		encodeString("record_size"), encodeUint32(24),
+		encodeString("ip_version"), encodeUint32(4),
+	)...)
+	return b
+}
+
+func Test_Database_CountryNetworks(t *testing.T) {
+	t.Parallel()
+
+	database, err := parse(makeTestDatabase())
+	require.NoError(t, err)
+
+	networks, err := database.CountryNetworks([]string{"fr"})
+	require.NoError(t, err)
+	assert.Equal(t, []net.IPNet{{
+		IP:   net.IP{64, 0, 0, 0},
+		Mask: net.CIDRMask(2, 32),
+	}}, networks)
+
+	networks, err = database.CountryNetworks([]string{"US", "DE"})
+	require.NoError(t, err)
+	assert.Equal(t, []net.IPNet{{
+		IP:   net.IP{128, 0, 0, 0},
+		Mask: net.CIDRMask(1, 32),
+	}}, networks)
+}
+
+func Test_parse(t *testing.T) {
+	t.Parallel()
+
+	_, err := parse([]byte("not a database"))
+	assert.ErrorIs(t, err, ErrMetadataNotFound)
+}
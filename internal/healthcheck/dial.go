@@ -0,0 +1,136 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// fallbackDelay is the delay to wait for a dial attempt to
+// complete before starting the next dial attempt in parallel.
+const fallbackDelay = 300 * time.Millisecond
+
+type dialResult struct {
+	connection net.Conn
+	address    string
+	duration   time.Duration
+	err        error
+}
+
+var (
+	ErrNoIPToDial     = errors.New("no IP address to dial")
+	ErrAllDialsFailed = errors.New("all dial attempts failed")
+)
+
+// dialHappyEyeballs dials the IP addresses given with the port given,
+// in the fashion of RFC 8305: addresses are interleaved by IP family,
+// starting with IPv4 since the VPN tunnel may not support IPv6, and a
+// new attempt starts every fallbackDelay or as soon as the previous
+// attempt fails. The first successful connection is returned together
+// with the duration of its own attempt, and the others are closed.
+func dialHappyEyeballs(ctx context.Context, dialer *net.Dialer,
+	ips []net.IP, port string) (connection net.Conn, address string,
+	duration time.Duration, err error) {
+	ips = interleaveIPFamilies(ips)
+	if len(ips) == 0 {
+		return nil, "", 0, ErrNoIPToDial
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan dialResult)
+	startAttempt := func(ip net.IP) {
+		address := net.JoinHostPort(ip.String(), port)
+		go func() {
+			start := time.Now()
+			connection, err := dialer.DialContext(ctx, "tcp", address)
+			result := dialResult{
+				connection: connection,
+				address:    address,
+				duration:   time.Since(start),
+				err:        err,
+			}
+			select {
+			case results <- result:
+			case <-ctx.Done():
+				if connection != nil {
+					_ = connection.Close()
+				}
+			}
+		}()
+	}
+
+	fallbackTimer := time.NewTimer(fallbackDelay)
+	defer fallbackTimer.Stop()
+
+	startAttempt(ips[0])
+	next, pending := 1, 1
+	errs := make([]error, 0, len(ips))
+	for pending > 0 {
+		select {
+		case <-fallbackTimer.C:
+			if next < len(ips) {
+				startAttempt(ips[next])
+				next++
+				pending++
+				fallbackTimer.Reset(fallbackDelay)
+			}
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				return result.connection, result.address, result.duration, nil
+			}
+			errs = append(errs, result.err)
+			if next < len(ips) {
+				startAttempt(ips[next])
+				next++
+				pending++
+				if !fallbackTimer.Stop() {
+					select {
+					case <-fallbackTimer.C:
+					default:
+					}
+				}
+				fallbackTimer.Reset(fallbackDelay)
+			}
+		}
+	}
+
+	if len(errs) == 1 {
+		return nil, "", 0, errs[0]
+	}
+	errMessages := make([]string, len(errs))
+	for i, err := range errs {
+		errMessages[i] = err.Error()
+	}
+	return nil, "", 0, fmt.Errorf("%w: %s", ErrAllDialsFailed,
+		strings.Join(errMessages, "; "))
+}
+
+// interleaveIPFamilies returns the IP addresses alternating
+// between IPv4 and IPv6, starting with IPv4.
+func interleaveIPFamilies(ips []net.IP) (interleaved []net.IP) {
+	var ipv4s, ipv6s []net.IP
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			ipv4s = append(ipv4s, ip)
+		} else {
+			ipv6s = append(ipv6s, ip)
+		}
+	}
+
+	interleaved = make([]net.IP, 0, len(ips))
+	for i := 0; i < len(ipv4s) || i < len(ipv6s); i++ {
+		if i < len(ipv4s) {
+			interleaved = append(interleaved, ipv4s[i])
+		}
+		if i < len(ipv6s) {
+			interleaved = append(interleaved, ipv6s[i])
+		}
+	}
+	return interleaved
+}
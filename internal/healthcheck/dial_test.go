@@ -0,0 +1,46 @@
+package healthcheck
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_interleaveIPFamilies(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		ips         []net.IP
+		interleaved []net.IP
+	}{
+		"no IP": {
+			interleaved: []net.IP{},
+		},
+		"IPv4 only": {
+			ips:         []net.IP{{1, 1, 1, 1}, {2, 2, 2, 2}},
+			interleaved: []net.IP{{1, 1, 1, 1}, {2, 2, 2, 2}},
+		},
+		"mixed families": {
+			ips: []net.IP{
+				net.ParseIP("::1"), net.ParseIP("::2"),
+				{1, 1, 1, 1},
+			},
+			interleaved: []net.IP{
+				{1, 1, 1, 1}, net.ParseIP("::1"),
+				net.ParseIP("::2"),
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			interleaved := interleaveIPFamilies(testCase.ips)
+
+			assert.Equal(t, testCase.interleaved, interleaved)
+		})
+	}
+}
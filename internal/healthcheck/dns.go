@@ -0,0 +1,96 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+type DNSLoop interface {
+	StatusApplier
+	GetStatus() (status models.LoopStatus)
+	GetSettings() (settings settings.DNS)
+}
+
+type dnsHealth struct {
+	loop     DNSLoop
+	resolver *net.Resolver
+	failures uint
+}
+
+func newDNSHealth(loop DNSLoop) dnsHealth {
+	return dnsHealth{
+		loop: loop,
+		resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				dialer := net.Dialer{}
+				return dialer.DialContext(ctx, network, "127.0.0.1:53")
+			},
+		},
+	}
+}
+
+var ErrDNSNotRunning = errors.New("DNS server is not running")
+
+// checkDNS resolves the DNS check hostname through the internal
+// DNS server, and returns nil if the check is disabled or if the
+// internal DNS server is not used.
+func (s *Server) checkDNS(ctx context.Context) (err error) {
+	hostname := *s.config.DNSHostname
+	if hostname == "" || !*s.dns.loop.GetSettings().DoT.Enabled {
+		return nil
+	}
+
+	switch status := s.dns.loop.GetStatus(); status {
+	case constants.Running:
+	case constants.Stopped:
+		return nil
+	default:
+		return fmt.Errorf("%w: DNS server is %s", ErrDNSNotRunning, status)
+	}
+
+	const timeout = 3 * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	_, err = s.dns.resolver.LookupIPAddr(ctx, hostname)
+	if err != nil {
+		return fmt.Errorf("resolving %s through the DNS server: %w", hostname, err)
+	}
+	return nil
+}
+
+// onDNSResult counts the consecutive DNS check failures, and
+// restarts the DNS server once there are too many of them,
+// without restarting the VPN since the tunnel is healthy.
+func (s *Server) onDNSResult(ctx context.Context, err error) {
+	if err == nil {
+		if s.dns.failures > 0 {
+			s.logger.Info("DNS is healthy again")
+		}
+		s.dns.failures = 0
+		return
+	}
+
+	if errors.Is(err, ErrDNSNotRunning) {
+		return // the DNS server is already (re)starting
+	}
+
+	s.dns.failures++
+	const maxFailures = 3
+	if s.dns.failures < maxFailures {
+		return
+	}
+
+	s.logger.Info("DNS check failed " + fmt.Sprint(s.dns.failures) +
+		" times in a row: restarting DNS server: " + err.Error())
+	s.dns.failures = 0
+	_, _ = s.dns.loop.ApplyStatus(ctx, constants.Stopped)
+	_, _ = s.dns.loop.ApplyStatus(ctx, constants.Running)
+}
@@ -0,0 +1,105 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDNSLoop struct {
+	status     models.LoopStatus
+	dotEnabled bool
+	statuses   []models.LoopStatus
+}
+
+func (l *fakeDNSLoop) GetStatus() models.LoopStatus { return l.status }
+
+func (l *fakeDNSLoop) ApplyStatus(_ context.Context, status models.LoopStatus) (string, error) {
+	l.statuses = append(l.statuses, status)
+	return "", nil
+}
+
+func (l *fakeDNSLoop) GetSettings() (settings settings.DNS) {
+	settings.DoT.Enabled = &l.dotEnabled
+	return settings
+}
+
+func Test_Server_checkDNS(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		hostname   string
+		loop       *fakeDNSLoop
+		errWrapped error
+	}{
+		"check disabled": {
+			loop: &fakeDNSLoop{status: constants.Crashed, dotEnabled: true},
+		},
+		"DNS over TLS disabled": {
+			hostname: "github.com",
+			loop:     &fakeDNSLoop{status: constants.Crashed},
+		},
+		"DNS stopped": {
+			hostname: "github.com",
+			loop:     &fakeDNSLoop{status: constants.Stopped, dotEnabled: true},
+		},
+		"DNS crashed": {
+			hostname:   "github.com",
+			loop:       &fakeDNSLoop{status: constants.Crashed, dotEnabled: true},
+			errWrapped: ErrDNSNotRunning,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			hostname := testCase.hostname
+			server := &Server{
+				config: settings.Health{DNSHostname: &hostname},
+				dns:    newDNSHealth(testCase.loop),
+			}
+
+			err := server.checkDNS(context.Background())
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+		})
+	}
+}
+
+func Test_Server_onDNSResult(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	gomock.InOrder(
+		logger.EXPECT().Info("DNS is healthy again"),
+		logger.EXPECT().Info("DNS check failed 3 times in a row: "+
+			"restarting DNS server: test error"),
+	)
+	loop := &fakeDNSLoop{}
+	server := &Server{
+		logger: logger,
+		dns:    newDNSHealth(loop),
+	}
+	ctx := context.Background()
+	errTest := errors.New("test error")
+
+	server.onDNSResult(ctx, errTest)
+	server.onDNSResult(ctx, nil)
+	server.onDNSResult(ctx, errTest)
+	server.onDNSResult(ctx, errTest)
+	server.onDNSResult(ctx, ErrDNSNotRunning)
+	assert.Empty(t, loop.statuses)
+
+	server.onDNSResult(ctx, errTest)
+	assert.Equal(t, []models.LoopStatus{constants.Stopped, constants.Running}, loop.statuses)
+	assert.Zero(t, server.dns.failures)
+}
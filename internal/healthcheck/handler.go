@@ -1,40 +1,157 @@
 package healthcheck
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"sync"
+
+	"github.com/qdm12/gluetun/internal/constants"
 )
 
 type handler struct {
+	vpnLoop     VPNLoop
+	readiness   readinessSettings
 	healthErr   error
+	timings     checkTimings
 	healthErrMu sync.RWMutex
 }
 
 var errHealthcheckNotRunYet = errors.New("healthcheck did not run yet")
 
-func newHandler() *handler {
+func newHandler(vpnLoop VPNLoop, readiness readinessSettings) *handler {
 	return &handler{
+		vpnLoop:   vpnLoop,
+		readiness: readiness,
 		healthErr: errHealthcheckNotRunYet,
 	}
 }
 
 func (h *handler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	if request.URL.Path == "/prestop" {
+		h.postPreStop(responseWriter, request)
+		return
+	}
+
 	if request.Method != http.MethodGet {
 		http.Error(responseWriter, "method not supported for healthcheck", http.StatusBadRequest)
 		return
 	}
-	if err := h.getErr(); err != nil {
+
+	switch request.URL.Path {
+	case "/live":
+		h.getLiveness(responseWriter)
+	case "/ready":
+		h.getReadiness(responseWriter)
+	case "/readiness":
+		h.getCompositeReadiness(responseWriter, request)
+	default:
+		h.getHealth(responseWriter)
+	}
+}
+
+// getLiveness always responds with 200 as long as the program is
+// able to serve HTTP requests. It does not depend on the tunnel
+// state, since gluetun restarts an unhealthy VPN by itself, and
+// restarting the whole pod would only delay the recovery.
+func (h *handler) getLiveness(responseWriter http.ResponseWriter) {
+	responseWriter.WriteHeader(http.StatusOK)
+}
+
+// getReadiness responds with 200 only if the VPN is running and
+// the last health check succeeded, so Kubernetes does not route
+// traffic to the pod while the tunnel is down.
+func (h *handler) getReadiness(responseWriter http.ResponseWriter) {
+	if err := h.ready(); err != nil {
+		http.Error(responseWriter, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	responseWriter.WriteHeader(http.StatusOK)
+}
+
+var ErrVPNNotRunning = errors.New("VPN is not running")
+
+// ready returns nil if the VPN is running and the last
+// health check succeeded, and an error otherwise.
+func (h *handler) ready() (err error) {
+	if status := h.vpnLoop.GetStatus(); status != constants.Running {
+		return fmt.Errorf("%w: VPN is %s", ErrVPNNotRunning, status)
+	}
+	return h.getErr()
+}
+
+// postPreStop stops the VPN and responds once it is stopped, to be
+// used as a Kubernetes preStop exec hook so the tunnel is torn down
+// cleanly before the container receives its termination signal, for
+// example with `wget -q -O- --post-data= http://127.0.0.1:9999/prestop`.
+// Since it stops the VPN, only POST requests from the loopback
+// interface are accepted.
+func (h *handler) postPreStop(responseWriter http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		http.Error(responseWriter, "method not supported for prestop", http.StatusMethodNotAllowed)
+		return
+	} else if !isLoopbackRequest(request) {
+		http.Error(responseWriter, "prestop is only allowed from the loopback interface",
+			http.StatusForbidden)
+		return
+	}
+
+	outcome, err := h.vpnLoop.ApplyStatus(request.Context(), constants.Stopped)
+	if err != nil {
+		http.Error(responseWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	responseWriter.WriteHeader(http.StatusOK)
+	_, _ = responseWriter.Write([]byte(outcome + "\n"))
+}
+
+func isLoopbackRequest(request *http.Request) bool {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (h *handler) getHealth(responseWriter http.ResponseWriter) {
+	timings, err := h.getResult()
+	if err != nil {
 		http.Error(responseWriter, err.Error(), http.StatusInternalServerError)
 		return
 	}
+
+	data := timingsData{
+		Address:       timings.Address,
+		Resolve:       timings.Resolve.String(),
+		ResolveCached: timings.ResolveCached,
+		Connect:       timings.Connect.String(),
+	}
+	responseWriter.Header().Set("Content-Type", "application/json")
 	responseWriter.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(responseWriter).Encode(data)
+}
+
+type timingsData struct {
+	Address       string `json:"address"`
+	Resolve       string `json:"resolve"`
+	ResolveCached bool   `json:"resolve_cached"`
+	Connect       string `json:"connect"`
 }
 
-func (h *handler) setErr(err error) {
+func (h *handler) setResult(err error, timings checkTimings) {
 	h.healthErrMu.Lock()
 	defer h.healthErrMu.Unlock()
 	h.healthErr = err
+	h.timings = timings
+}
+
+func (h *handler) getResult() (timings checkTimings, err error) {
+	h.healthErrMu.RLock()
+	defer h.healthErrMu.RUnlock()
+	return h.timings, h.healthErr
 }
 
 func (h *handler) getErr() (err error) {
@@ -0,0 +1,63 @@
+package healthcheck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_handler_postPreStop(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		method     string
+		remoteAddr string
+		status     int
+		statuses   []models.LoopStatus
+	}{
+		"GET request": {
+			method:     http.MethodGet,
+			remoteAddr: "127.0.0.1:1234",
+			status:     http.StatusMethodNotAllowed,
+		},
+		"POST request from the network": {
+			method:     http.MethodPost,
+			remoteAddr: "10.0.0.2:1234",
+			status:     http.StatusForbidden,
+		},
+		"POST request from IPv4 loopback": {
+			method:     http.MethodPost,
+			remoteAddr: "127.0.0.1:1234",
+			status:     http.StatusOK,
+			statuses:   []models.LoopStatus{constants.Stopped},
+		},
+		"POST request from IPv6 loopback": {
+			method:     http.MethodPost,
+			remoteAddr: "[::1]:1234",
+			status:     http.StatusOK,
+			statuses:   []models.LoopStatus{constants.Stopped},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			vpnLoop := &fakeVPNLoop{status: constants.Running}
+			handler := newHandler(vpnLoop, readinessSettings{})
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(testCase.method, "/prestop", nil)
+			request.RemoteAddr = testCase.remoteAddr
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, testCase.status, recorder.Code)
+			assert.Equal(t, testCase.statuses, vpnLoop.statuses)
+		})
+	}
+}
@@ -5,24 +5,49 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sync"
 	"time"
+
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/ping"
 )
 
 func (s *Server) runHealthcheckLoop(ctx context.Context, done chan<- struct{}) {
 	defer close(done)
+	defer func() {
+		// Dependent containers must not consider gluetun
+		// ready once it stops.
+		if path := *s.config.ReadyFilepath; path != "" {
+			if err := removeReadyFile(path); err != nil {
+				s.logger.Error(err.Error())
+			}
+		}
+	}()
 
 	s.vpn.healthyTimer = time.NewTimer(s.vpn.healthyWait)
 
+	previousErr := s.handler.getErr()
 	for {
-		previousErr := s.handler.getErr()
-
-		const healthcheckTimeout = 3 * time.Second
-		healthcheckCtx, healthcheckCancel := context.WithTimeout(
-			ctx, healthcheckTimeout)
-		err := s.healthCheck(healthcheckCtx)
-		healthcheckCancel()
-
-		s.handler.setErr(err)
+		timings, err := s.healthCheck(ctx)
+		// The throughput is checked at each iteration to track the
+		// counters, and the DNS is only checked with a healthy tunnel.
+		// Their failures do not arm the VPN healthy wait timer.
+		stallErr := s.checkThroughput()
+		healthErr := err
+		if healthErr == nil {
+			healthErr = stallErr
+		}
+		if healthErr == nil {
+			healthErr = s.checkDNS(ctx)
+			s.onDNSResult(ctx, healthErr)
+		}
+		s.handler.setResult(healthErr, timings)
+		if fileErr := s.writeStatusFile(healthErr); fileErr != nil {
+			s.logger.Error(fileErr.Error())
+		}
+		if fileErr := s.updateReadyFile(); fileErr != nil {
+			s.logger.Error(fileErr.Error())
+		}
 
 		if previousErr != nil && err == nil {
 			s.logger.Info("healthy!")
@@ -33,8 +58,10 @@ func (s *Server) runHealthcheckLoop(ctx context.Context, done chan<- struct{}) {
 			s.vpn.healthyTimer.Stop()
 			s.vpn.healthyTimer = time.NewTimer(s.vpn.healthyWait)
 		}
+		previousErr = err
+		s.onThroughputResult(ctx, stallErr)
 
-		if err != nil { // try again after 1 second
+		if healthErr != nil { // try again after 1 second
 			timer := time.NewTimer(time.Second)
 			select {
 			case <-ctx.Done():
@@ -63,26 +90,172 @@ func (s *Server) runHealthcheckLoop(ctx context.Context, done chan<- struct{}) {
 	}
 }
 
-func (s *Server) healthCheck(ctx context.Context) (err error) {
+// checkTimings contains the duration of each phase
+// of a health check.
+type checkTimings struct {
+	// Address is the IP address and port dialed successfully.
+	Address string
+	// Resolve is the duration of the DNS resolution,
+	// and is zero if the cached resolution result is used.
+	Resolve time.Duration
+	// ResolveCached is true if the cached resolution result
+	// is used instead of resolving the target host.
+	ResolveCached bool
+	// Connect is the duration of the TCP handshake through the
+	// tunnel, or of the ICMP echo round trip in the icmp mode.
+	Connect time.Duration
+}
+
+var ErrFailureQuorumReached = errors.New("failure quorum reached")
+
+// healthCheck checks all the targets of the health mode in parallel,
+// and fails only if at least the failure quorum of targets fail, such
+// that an outage of a single target does not make the VPN unhealthy.
+// The timings returned are the ones of the first target, in the
+// order of the settings, checked successfully.
+func (s *Server) healthCheck(ctx context.Context) (
+	timings checkTimings, err error) {
 	// TODO use mullvad API if current provider is Mullvad
 
-	address, err := makeAddressToDial(s.config.TargetAddress)
+	targets, checkTarget, err := s.makeTargets()
+	if err != nil {
+		return timings, err
+	}
+
+	type result struct {
+		timings checkTimings
+		err     error
+	}
+	results := make([]result, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target string) {
+			defer wg.Done()
+			results[i].timings, results[i].err = checkTarget(ctx, target)
+		}(i, target)
+	}
+	wg.Wait()
+
+	var errs []error
+	firstSuccess := -1
+	for i, result := range results {
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", targets[i], result.err))
+		} else if firstSuccess == -1 {
+			firstSuccess = i
+		}
+	}
+
+	quorum := len(targets)
+	if s.config.FailureQuorum != nil && int(*s.config.FailureQuorum) < quorum {
+		quorum = int(*s.config.FailureQuorum)
+	}
+
+	switch {
+	case len(targets) == 1:
+		return results[0].timings, results[0].err
+	case len(errs) >= quorum:
+		return timings, fmt.Errorf("%w: %d of %d targets failed: %w",
+			ErrFailureQuorumReached, len(errs), len(targets), errors.Join(errs...))
+	default:
+		return results[firstSuccess].timings, nil
+	}
+}
+
+var ErrVPNGatewayNotFound = errors.New("VPN gateway not found")
+
+// makeTargets returns the targets to check for the health mode,
+// and the function to check each of them with.
+func (s *Server) makeTargets() (targets []string,
+	checkTarget func(ctx context.Context, target string) (checkTimings, error),
+	err error) {
+	if s.config.Mode != constants.HealthModeICMP {
+		return s.config.TargetAddresses, s.dialTarget, nil
+	}
+
+	if len(s.config.ICMPTargets) == 0 {
+		gateway, ok := s.vpn.loop.GetVPNGateway()
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: set ICMP targets to ping instead",
+				ErrVPNGatewayNotFound)
+		}
+		return []string{gateway.String()}, pingTarget, nil
+	}
+
+	targets = make([]string, len(s.config.ICMPTargets))
+	for i, ip := range s.config.ICMPTargets {
+		targets[i] = ip.String()
+	}
+	return targets, pingTarget, nil
+}
+
+// pingTarget sends an ICMP echo request to the target IP address
+// given through the tunnel, returning its round trip time.
+func pingTarget(ctx context.Context, target string) (
+	timings checkTimings, err error) {
+	if err := ctx.Err(); err != nil {
+		return timings, err
+	}
+	timeout := 3 * time.Second
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+
+	const sequence = 0
+	rtt, err := ping.Echo(net.ParseIP(target), sequence, timeout)
 	if err != nil {
-		return err
+		return timings, fmt.Errorf("pinging: %w", err)
+	}
+	timings.Address = target
+	timings.Connect = rtt
+	return timings, nil
+}
+
+// dialTarget resolves and dials the target address given
+// through the tunnel, returning the timings of each phase.
+func (s *Server) dialTarget(ctx context.Context, target string) (
+	timings checkTimings, err error) {
+	address, err := makeAddressToDial(target)
+	if err != nil {
+		return timings, err
+	}
+	host, port, _ := net.SplitHostPort(address)
+
+	// Each phase has its own timeout so a slow DNS resolution
+	// does not eat into the time allowed to dial through the tunnel.
+	const resolveTimeout = 2 * time.Second
+	resolveCtx, resolveCancel := context.WithTimeout(ctx, resolveTimeout)
+	start := time.Now()
+	ips, cached, err := s.resolver.lookup(resolveCtx, host)
+	resolveCancel()
+	if err != nil {
+		return timings, fmt.Errorf("resolving: %w", err)
+	}
+	timings.ResolveCached = cached
+	if !cached {
+		timings.Resolve = time.Since(start)
 	}
 
-	const dialNetwork = "tcp4"
-	connection, err := s.dialer.DialContext(ctx, dialNetwork, address)
+	const connectTimeout = 3 * time.Second
+	connectCtx, connectCancel := context.WithTimeout(ctx, connectTimeout)
+	defer connectCancel()
+	connection, dialedAddress, connectDuration, err := dialHappyEyeballs(
+		connectCtx, s.dialer, ips, port)
 	if err != nil {
-		return fmt.Errorf("dialing: %w", err)
+		// The IP addresses may have changed, so resolve again next time.
+		s.resolver.invalidate(host)
+		return timings, fmt.Errorf("dialing: %w", err)
 	}
+	timings.Address = dialedAddress
+	timings.Connect = connectDuration
 
 	err = connection.Close()
 	if err != nil {
-		return fmt.Errorf("closing connection: %w", err)
+		return timings, fmt.Errorf("closing connection: %w", err)
 	}
 
-	return nil
+	return timings, nil
 }
 
 func makeAddressToDial(address string) (addressToDial string, err error) {
@@ -8,8 +8,10 @@ import (
 	"time"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"inet.af/netaddr"
 )
 
 func Test_Server_healthCheck(t *testing.T) {
@@ -22,16 +24,17 @@ func Test_Server_healthCheck(t *testing.T) {
 		const address = "cloudflare.com:443"
 
 		server := &Server{
-			dialer: dialer,
+			dialer:   dialer,
+			resolver: newResolver(),
 			config: settings.Health{
-				TargetAddress: address,
+				TargetAddresses: []string{address},
 			},
 		}
 
 		canceledCtx, cancel := context.WithCancel(context.Background())
 		cancel()
 
-		err := server.healthCheck(canceledCtx)
+		_, err := server.healthCheck(canceledCtx)
 
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "operation was canceled")
@@ -51,9 +54,10 @@ func Test_Server_healthCheck(t *testing.T) {
 
 		dialer := &net.Dialer{}
 		server := &Server{
-			dialer: dialer,
+			dialer:   dialer,
+			resolver: newResolver(),
 			config: settings.Health{
-				TargetAddress: listeningAddress.String(),
+				TargetAddresses: []string{listeningAddress.String()},
 			},
 		}
 
@@ -61,10 +65,112 @@ func Test_Server_healthCheck(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
 
-		err = server.healthCheck(ctx)
+		timings, err := server.healthCheck(ctx)
 
 		assert.NoError(t, err)
+		assert.Equal(t, listeningAddress.String(), timings.Address)
+		assert.True(t, timings.ResolveCached)
 	})
+
+	t.Run("failure quorum", func(t *testing.T) {
+		t.Parallel()
+
+		listener, err := net.Listen("tcp4", "localhost:0")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			err = listener.Close()
+			assert.NoError(t, err)
+		})
+		listeningAddress := listener.Addr().String()
+
+		// Reserve a port and close its listener so dialing it fails.
+		closedListener, err := net.Listen("tcp4", "localhost:0")
+		require.NoError(t, err)
+		closedAddress := closedListener.Addr().String()
+		err = closedListener.Close()
+		require.NoError(t, err)
+
+		newServer := func(quorum uint8) *Server {
+			return &Server{
+				dialer:   &net.Dialer{},
+				resolver: newResolver(),
+				config: settings.Health{
+					TargetAddresses: []string{closedAddress, listeningAddress, closedAddress},
+					FailureQuorum:   &quorum,
+				},
+			}
+		}
+
+		const timeout = time.Second
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		timings, err := newServer(3).healthCheck(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, listeningAddress, timings.Address)
+
+		_, err = newServer(2).healthCheck(ctx)
+		assert.ErrorIs(t, err, ErrFailureQuorumReached)
+	})
+}
+
+func Test_Server_makeTargets(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		config     settings.Health
+		gateway    net.IP
+		targets    []string
+		errWrapped error
+	}{
+		"tcp mode": {
+			config: settings.Health{
+				Mode:            constants.HealthModeTCP,
+				TargetAddresses: []string{"cloudflare.com:443", "github.com"},
+			},
+			targets: []string{"cloudflare.com:443", "github.com"},
+		},
+		"icmp mode with targets": {
+			config: settings.Health{
+				Mode:        constants.HealthModeICMP,
+				ICMPTargets: []netaddr.IP{netaddr.MustParseIP("1.1.1.1"), netaddr.MustParseIP("9.9.9.9")},
+			},
+			gateway: net.IPv4(10, 8, 0, 1),
+			targets: []string{"1.1.1.1", "9.9.9.9"},
+		},
+		"icmp mode with gateway": {
+			config: settings.Health{
+				Mode: constants.HealthModeICMP,
+			},
+			gateway: net.IPv4(10, 8, 0, 1),
+			targets: []string{"10.8.0.1"},
+		},
+		"icmp mode without gateway": {
+			config: settings.Health{
+				Mode: constants.HealthModeICMP,
+			},
+			errWrapped: ErrVPNGatewayNotFound,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := &Server{
+				config: testCase.config,
+				vpn: vpnHealth{
+					loop: &fakeVPNLoop{gateway: testCase.gateway},
+				},
+			}
+
+			targets, _, err := server.makeTargets()
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			assert.Equal(t, testCase.targets, targets)
+		})
+	}
 }
 
 func Test_makeAddressToDial(t *testing.T) {
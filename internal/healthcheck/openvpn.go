@@ -5,18 +5,34 @@ import (
 	"time"
 
 	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/serverstats"
 )
 
 type vpnHealth struct {
-	loop         StatusApplier
+	loop         VPNLoop
+	serverStats  ServerFailureRecorder
 	healthyWait  time.Duration
 	healthyTimer *time.Timer
 }
 
 func (s *Server) onUnhealthyVPN(ctx context.Context) {
+	if err := s.upstream.check(ctx); err != nil {
+		// Restarting the VPN would not help, so wait for the
+		// upstream gluetun to recover from its own failure.
+		s.logger.Info("program has been unhealthy for " +
+			s.vpn.healthyWait.String() + " but not restarting VPN: " + err.Error())
+		s.vpn.healthyTimer = time.NewTimer(s.vpn.healthyWait)
+		return
+	}
+
 	s.logger.Info("program has been unhealthy for " +
 		s.vpn.healthyWait.String() + ": restarting VPN " +
 		"(see https://github.com/qdm12/gluetun/wiki/Healthcheck)")
+	// Record the failure so the server is skipped for its cooldown
+	// period when the VPN reconnects.
+	if server, ok := s.vpn.loop.GetConnectedServer(); ok {
+		s.vpn.serverStats.Failure(serverstats.Key(server.Hostname, server.IP))
+	}
 	_, _ = s.vpn.loop.ApplyStatus(ctx, constants.Stopped)
 	_, _ = s.vpn.loop.ApplyStatus(ctx, constants.Running)
 	s.vpn.healthyWait += *s.config.VPN.Addition
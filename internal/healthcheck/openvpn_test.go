@@ -0,0 +1,73 @@
+package healthcheck
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeFailureRecorder struct {
+	failures []string
+}
+
+func (f *fakeFailureRecorder) Failure(hostname string) {
+	f.failures = append(f.failures, hostname)
+}
+
+func Test_Server_onUnhealthyVPN(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		server   *models.ConnectedServer
+		failures []string
+	}{
+		"not connected": {},
+		"server with hostname": {
+			server:   &models.ConnectedServer{Hostname: "a.example.com", IP: net.IP{1, 2, 3, 4}},
+			failures: []string{"a.example.com"},
+		},
+		"server without hostname": {
+			server:   &models.ConnectedServer{IP: net.IP{1, 2, 3, 4}},
+			failures: []string{"1.2.3.4"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			loop := &fakeVPNLoop{server: testCase.server}
+			recorder := &fakeFailureRecorder{}
+			ctrl := gomock.NewController(t)
+			logger := NewMockLogger(ctrl)
+			logger.EXPECT().Info("program has been unhealthy for 1s: restarting VPN " +
+				"(see https://github.com/qdm12/gluetun/wiki/Healthcheck)")
+			addition := time.Second
+			server := &Server{
+				logger: logger,
+				config: settings.Health{
+					VPN: settings.HealthyWait{Addition: &addition},
+				},
+				vpn: vpnHealth{
+					loop:        loop,
+					serverStats: recorder,
+					healthyWait: time.Second,
+				},
+			}
+
+			server.onUnhealthyVPN(context.Background())
+
+			assert.Equal(t, testCase.failures, recorder.failures)
+			assert.Equal(t, []models.LoopStatus{constants.Stopped, constants.Running}, loop.statuses)
+			assert.Equal(t, 2*time.Second, server.vpn.healthyWait)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+type PortForwardedGetter interface {
+	GetPortForwarded() (port uint16)
+}
+
+type PublicIPGetter interface {
+	GetData() (data models.PublicIP)
+}
+
+type readinessCondition struct {
+	Name   string `json:"name"`
+	Ready  bool   `json:"ready"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type readinessData struct {
+	Ready      bool                 `json:"ready"`
+	Conditions []readinessCondition `json:"conditions"`
+}
+
+// getCompositeReadiness responds with 200 only if all the configured
+// readiness conditions are met, and with 503 otherwise. The response
+// body details the state of each condition.
+func (h *handler) getCompositeReadiness(responseWriter http.ResponseWriter,
+	request *http.Request) {
+	data := readinessData{
+		Ready:      true,
+		Conditions: make([]readinessCondition, len(h.readiness.conditions)),
+	}
+	for i, name := range h.readiness.conditions {
+		condition := readinessCondition{Name: name, Ready: true}
+		if err := h.checkCondition(request.Context(), name); err != nil {
+			condition.Ready = false
+			condition.Reason = err.Error()
+			data.Ready = false
+		}
+		data.Conditions[i] = condition
+	}
+
+	responseWriter.Header().Set("Content-Type", "application/json")
+	if data.Ready {
+		responseWriter.WriteHeader(http.StatusOK)
+	} else {
+		responseWriter.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(responseWriter).Encode(data)
+}
+
+var (
+	ErrPortNotForwarded          = errors.New("no port is forwarded")
+	ErrPublicIPNotKnown          = errors.New("public IP address is not known")
+	ErrReadinessConditionUnknown = errors.New("readiness condition is unknown")
+)
+
+func (h *handler) checkCondition(ctx context.Context, name string) (err error) {
+	switch name {
+	case constants.ReadinessTunnel:
+		return h.ready()
+	case constants.ReadinessPortForwarded:
+		if h.readiness.portForwarded.GetPortForwarded() == 0 {
+			return fmt.Errorf("%w", ErrPortNotForwarded)
+		}
+		return nil
+	case constants.ReadinessDNS:
+		const timeout = 3 * time.Second
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		_, err = h.readiness.resolver.LookupIPAddr(ctx, h.readiness.dnsHost)
+		if err != nil {
+			return fmt.Errorf("resolving %s: %w", h.readiness.dnsHost, err)
+		}
+		return nil
+	case constants.ReadinessPublicIP:
+		if h.readiness.publicIP.GetData().IP == nil {
+			return fmt.Errorf("%w", ErrPublicIPNotKnown)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrReadinessConditionUnknown, name)
+	}
+}
+
+type readinessSettings struct {
+	conditions    []string
+	portForwarded PortForwardedGetter
+	publicIP      PublicIPGetter
+	resolver      *net.Resolver
+	// dnsHost is the host resolved to check DNS works.
+	dnsHost string
+}
+
+func newReadinessSettings(conditions []string, targetAddress string,
+	portForwarded PortForwardedGetter, publicIP PublicIPGetter) readinessSettings {
+	dnsHost, _, err := net.SplitHostPort(targetAddress)
+	if err != nil { // target address without port
+		dnsHost = targetAddress
+	}
+	return readinessSettings{
+		conditions:    conditions,
+		portForwarded: portForwarded,
+		publicIP:      publicIP,
+		resolver:      &net.Resolver{},
+		dnsHost:       dnsHost,
+	}
+}
@@ -0,0 +1,105 @@
+package healthcheck
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeVPNLoop struct {
+	status   models.LoopStatus
+	server   *models.ConnectedServer
+	gateway  net.IP
+	statuses []models.LoopStatus
+}
+
+func (l *fakeVPNLoop) GetStatus() models.LoopStatus { return l.status }
+
+func (l *fakeVPNLoop) ApplyStatus(_ context.Context, status models.LoopStatus) (string, error) {
+	l.statuses = append(l.statuses, status)
+	return "", nil
+}
+
+func (l *fakeVPNLoop) GetConnectedServer() (models.ConnectedServer, bool) {
+	if l.server == nil {
+		return models.ConnectedServer{}, false
+	}
+	return *l.server, true
+}
+
+func (l *fakeVPNLoop) GetVPNGateway() (net.IP, bool) {
+	return l.gateway, l.gateway != nil
+}
+
+type fakePortForwarded struct{ port uint16 }
+
+func (f fakePortForwarded) GetPortForwarded() uint16 { return f.port }
+
+type fakePublicIP struct{ ip net.IP }
+
+func (f fakePublicIP) GetData() models.PublicIP { return models.PublicIP{IP: f.ip} }
+
+func Test_handler_getCompositeReadiness(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		conditions    []string
+		vpnStatus     models.LoopStatus
+		healthErr     error
+		portForwarded uint16
+		publicIP      net.IP
+		status        int
+		body          string
+	}{
+		"all ready": {
+			conditions: []string{constants.ReadinessTunnel,
+				constants.ReadinessPortForwarded, constants.ReadinessPublicIP},
+			vpnStatus:     constants.Running,
+			portForwarded: 5000,
+			publicIP:      net.IPv4(1, 2, 3, 4),
+			status:        http.StatusOK,
+			body: `{"ready":true,"conditions":[{"name":"tunnel","ready":true},` +
+				`{"name":"port_forwarded","ready":true},{"name":"public_ip","ready":true}]}` + "\n",
+		},
+		"port not forwarded": {
+			conditions: []string{constants.ReadinessTunnel, constants.ReadinessPortForwarded},
+			vpnStatus:  constants.Running,
+			status:     http.StatusServiceUnavailable,
+			body: `{"ready":false,"conditions":[{"name":"tunnel","ready":true},` +
+				`{"name":"port_forwarded","ready":false,"reason":"no port is forwarded"}]}` + "\n",
+		},
+		"tunnel down": {
+			conditions: []string{constants.ReadinessTunnel},
+			vpnStatus:  constants.Stopped,
+			status:     http.StatusServiceUnavailable,
+			body: `{"ready":false,"conditions":[{"name":"tunnel","ready":false,` +
+				`"reason":"VPN is not running: VPN is stopped"}]}` + "\n",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			readiness := newReadinessSettings(testCase.conditions, "cloudflare.com:443",
+				fakePortForwarded{port: testCase.portForwarded},
+				fakePublicIP{ip: testCase.publicIP})
+			handler := newHandler(&fakeVPNLoop{status: testCase.vpnStatus}, readiness)
+			handler.setResult(testCase.healthErr, checkTimings{})
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/readiness", nil)
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, testCase.status, recorder.Code)
+			assert.Equal(t, testCase.body, recorder.Body.String())
+		})
+	}
+}
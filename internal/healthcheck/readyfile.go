@@ -0,0 +1,51 @@
+package healthcheck
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// updateReadyFile creates the ready file if the VPN is running and
+// healthy, and removes it otherwise. Containers behind gluetun sharing
+// the file through a volume can then use a healthcheck as simple as
+// `test -f /path/to/ready` without querying any API.
+func (s *Server) updateReadyFile() (err error) {
+	path := *s.config.ReadyFilepath
+	if path == "" {
+		return nil
+	}
+
+	ready := s.handler.ready() == nil
+	if s.readyFileExists != nil && *s.readyFileExists == ready {
+		return nil
+	}
+
+	if ready {
+		const perms = 0644
+		err = os.WriteFile(path, []byte("ready\n"), perms)
+		if err != nil {
+			return fmt.Errorf("writing ready file: %w", err)
+		}
+		err = s.filesOwner.Chown(path)
+		if err != nil {
+			return fmt.Errorf("changing ownership of ready file: %w", err)
+		}
+	} else {
+		err = removeReadyFile(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.readyFileExists = &ready
+	return nil
+}
+
+func removeReadyFile(path string) (err error) {
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing ready file: %w", err)
+	}
+	return nil
+}
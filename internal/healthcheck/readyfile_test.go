@@ -0,0 +1,119 @@
+package healthcheck
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Server_updateReadyFile(t *testing.T) {
+	t.Parallel()
+
+	errHealth := errors.New("health error")
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		server := &Server{
+			config: settings.Health{ReadyFilepath: new(string)},
+			handler: newHandler(&fakeVPNLoop{status: constants.Running},
+				readinessSettings{}),
+		}
+		server.handler.setResult(nil, checkTimings{})
+
+		err := server.updateReadyFile()
+
+		require.NoError(t, err)
+		assert.Nil(t, server.readyFileExists)
+	})
+
+	t.Run("follows readiness", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "ready")
+		vpnLoop := &fakeVPNLoop{status: constants.Running}
+		server := &Server{
+			config:  settings.Health{ReadyFilepath: &path},
+			handler: newHandler(vpnLoop, readinessSettings{}),
+		}
+
+		// Health check did not run yet
+		err := server.updateReadyFile()
+		require.NoError(t, err)
+		assert.NoFileExists(t, path)
+
+		server.handler.setResult(nil, checkTimings{})
+		err = server.updateReadyFile()
+		require.NoError(t, err)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "ready\n", string(data))
+
+		// The file is not written again while the readiness is unchanged.
+		err = os.Remove(path)
+		require.NoError(t, err)
+		err = server.updateReadyFile()
+		require.NoError(t, err)
+		assert.NoFileExists(t, path)
+
+		err = os.WriteFile(path, []byte("ready\n"), 0600)
+		require.NoError(t, err)
+		server.handler.setResult(errHealth, checkTimings{})
+		err = server.updateReadyFile()
+		require.NoError(t, err)
+		assert.NoFileExists(t, path)
+
+		server.handler.setResult(nil, checkTimings{})
+		vpnLoop.status = constants.Stopped
+		err = server.updateReadyFile()
+		require.NoError(t, err)
+		assert.NoFileExists(t, path)
+
+		vpnLoop.status = constants.Running
+		err = server.updateReadyFile()
+		require.NoError(t, err)
+		assert.FileExists(t, path)
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "missing", "ready")
+		server := &Server{
+			config: settings.Health{ReadyFilepath: &path},
+			handler: newHandler(&fakeVPNLoop{status: constants.Running},
+				readinessSettings{}),
+		}
+		server.handler.setResult(nil, checkTimings{})
+
+		err := server.updateReadyFile()
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.Nil(t, server.readyFileExists)
+	})
+}
+
+func Test_removeReadyFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "ready")
+	err := os.WriteFile(path, []byte("ready\n"), 0600)
+	require.NoError(t, err)
+
+	err = removeReadyFile(path)
+	require.NoError(t, err)
+	assert.NoFileExists(t, path)
+
+	// Removing a file which does not exist is not an error.
+	err = removeReadyFile(path)
+	assert.NoError(t, err)
+
+	err = removeReadyFile(t.TempDir() + "/missing/ready")
+	assert.NoError(t, err)
+}
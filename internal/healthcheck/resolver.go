@@ -0,0 +1,84 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// resolver resolves hosts to IP addresses and caches the result
+// for a fixed duration, so periodic health checks do not include
+// DNS resolution overhead every time.
+type resolver struct {
+	resolver *net.Resolver
+	ttl      time.Duration
+	timeNow  func() time.Time
+	mutex    sync.Mutex
+	cache    map[string]cachedIPs
+}
+
+type cachedIPs struct {
+	ips     []net.IP
+	expires time.Time
+}
+
+func newResolver() *resolver {
+	const ttl = 5 * time.Minute
+	return &resolver{
+		resolver: &net.Resolver{},
+		ttl:      ttl,
+		timeNow:  time.Now,
+		cache:    make(map[string]cachedIPs),
+	}
+}
+
+var ErrNoIPFound = errors.New("no IP address found")
+
+// lookup returns the IP addresses for the host given, using the
+// cached result if it exists and has not expired. The cached
+// boolean is true if no DNS resolution was done.
+func (r *resolver) lookup(ctx context.Context, host string) (
+	ips []net.IP, cached bool, err error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, true, nil
+	}
+
+	r.mutex.Lock()
+	entry, ok := r.cache[host]
+	r.mutex.Unlock()
+	if ok && r.timeNow().Before(entry.expires) {
+		return entry.ips, true, nil
+	}
+
+	ipAddresses, err := r.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, false, err
+	} else if len(ipAddresses) == 0 {
+		return nil, false, fmt.Errorf("%w: for %s", ErrNoIPFound, host)
+	}
+
+	ips = make([]net.IP, len(ipAddresses))
+	for i := range ipAddresses {
+		ips[i] = ipAddresses[i].IP
+	}
+
+	r.mutex.Lock()
+	r.cache[host] = cachedIPs{
+		ips:     ips,
+		expires: r.timeNow().Add(r.ttl),
+	}
+	r.mutex.Unlock()
+
+	return ips, false, nil
+}
+
+// invalidate removes the cached result for the host given,
+// for example if dialing all its IP addresses failed.
+func (r *resolver) invalidate(host string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.cache, host)
+}
@@ -3,34 +3,82 @@ package healthcheck
 import (
 	"context"
 	"net"
+	"time"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/ownership"
 )
 
 type Server struct {
-	logger  Logger
-	handler *handler
-	dialer  *net.Dialer
-	config  settings.Health
-	vpn     vpnHealth
+	logger   Logger
+	handler  *handler
+	dialer   *net.Dialer
+	resolver *resolver
+	config   settings.Health
+	vpn      vpnHealth
+	upstream upstreamHealth
+	dns      dnsHealth
+
+	throughput throughputHealth
+
+	lastStatusContent string
+	readyFileExists   *bool
+	// filesOwner is the owner of the status and ready files once
+	// written, and is nil to leave their ownership unchanged.
+	filesOwner *ownership.Owner
+}
+
+// SetFilesOwner sets the owner of the status and ready files each time
+// they are written. A nil owner leaves the ownership of the files
+// unchanged. It must be called before the server is run.
+func (s *Server) SetFilesOwner(owner *ownership.Owner) {
+	s.filesOwner = owner
 }
 
-func NewServer(config settings.Health,
-	logger Logger, vpnLoop StatusApplier) *Server {
+func NewServer(config settings.Health, logger Logger, vpnLoop VPNLoop,
+	dnsLoop DNSLoop, portForwarded PortForwardedGetter, publicIP PublicIPGetter,
+	serverStats ServerFailureRecorder, vpnCounters VPNCountersGetter) *Server {
+	readiness := newReadinessSettings(config.ReadinessConditions,
+		config.TargetAddresses[0], portForwarded, publicIP)
 	return &Server{
-		logger:  logger,
-		handler: newHandler(),
-		dialer:  &net.Dialer{},
-		config:  config,
+		logger:   logger,
+		handler:  newHandler(vpnLoop, readiness),
+		dialer:   &net.Dialer{},
+		resolver: newResolver(),
+		config:   config,
 		vpn: vpnHealth{
 			loop:        vpnLoop,
+			serverStats: serverStats,
 			healthyWait: *config.VPN.Initial,
 		},
+		upstream: newUpstreamHealth(*config.UpstreamAddress),
+		dns:      newDNSHealth(dnsLoop),
+		throughput: throughputHealth{
+			counters: vpnCounters,
+			timeNow:  time.Now,
+		},
 	}
 }
 
+type VPNLoop interface {
+	StatusApplier
+	GetStatus() (status models.LoopStatus)
+	GetConnectedServer() (server models.ConnectedServer, ok bool)
+	GetVPNGateway() (gateway net.IP, ok bool)
+}
+
+type ServerFailureRecorder interface {
+	Failure(hostname string)
+}
+
 type StatusApplier interface {
 	ApplyStatus(ctx context.Context, status models.LoopStatus) (
 		outcome string, err error)
 }
+
+// Ready returns nil if the VPN is running and the last health
+// check succeeded, and an error describing why otherwise.
+func (s *Server) Ready() (err error) {
+	return s.handler.ready()
+}
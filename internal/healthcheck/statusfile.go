@@ -0,0 +1,69 @@
+package healthcheck
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/constants"
+)
+
+// writeStatusFile writes the health status to the status file
+// if one is configured, as key="value" lines in the fashion of
+// Kubernetes downward API files. The file is only written if
+// its content changed, and is replaced atomically so readers
+// never see a partially written file.
+func (s *Server) writeStatusFile(healthErr error) (err error) {
+	path := *s.config.StatusFilepath
+	if path == "" {
+		return nil
+	}
+
+	vpnStatus := s.vpn.loop.GetStatus()
+	healthy := healthErr == nil
+	ready := healthy && vpnStatus == constants.Running
+	errMessage := ""
+	if healthErr != nil {
+		errMessage = healthErr.Error()
+	}
+
+	content := makeStatusFileContent([][2]string{
+		{"healthy", strconv.FormatBool(healthy)},
+		{"ready", strconv.FormatBool(ready)},
+		{"vpn_status", string(vpnStatus)},
+		{"error", errMessage},
+	})
+	if content == s.lastStatusContent {
+		return nil
+	}
+
+	const perms = 0644
+	temporaryPath := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	err = os.WriteFile(temporaryPath, []byte(content), perms)
+	if err != nil {
+		return fmt.Errorf("writing temporary status file: %w", err)
+	}
+
+	err = s.filesOwner.Chown(temporaryPath)
+	if err != nil {
+		return fmt.Errorf("changing ownership of temporary status file: %w", err)
+	}
+
+	err = os.Rename(temporaryPath, path)
+	if err != nil {
+		return fmt.Errorf("replacing status file: %w", err)
+	}
+
+	s.lastStatusContent = content
+	return nil
+}
+
+func makeStatusFileContent(keyValues [][2]string) (content string) {
+	lines := make([]string, len(keyValues))
+	for i, keyValue := range keyValues {
+		lines[i] = keyValue[0] + "=" + strconv.Quote(keyValue[1])
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
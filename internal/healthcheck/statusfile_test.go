@@ -0,0 +1,20 @@
+package healthcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_makeStatusFileContent(t *testing.T) {
+	t.Parallel()
+
+	content := makeStatusFileContent([][2]string{
+		{"healthy", "false"},
+		{"error", `dialing: "quoted" error`},
+	})
+
+	const expected = "healthy=\"false\"\n" +
+		"error=\"dialing: \\\"quoted\\\" error\"\n"
+	assert.Equal(t, expected, content)
+}
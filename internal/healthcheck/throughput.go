@@ -0,0 +1,97 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/serverstats"
+)
+
+type VPNCountersGetter interface {
+	GetCounters() (rxBytes, txBytes uint64, err error)
+}
+
+type throughputHealth struct {
+	counters VPNCountersGetter
+	timeNow  func() time.Time
+	// lastRx and lastTx are the counters read at the last check,
+	// and are only valid if lastCheck is not the zero time.
+	lastRx    uint64
+	lastTx    uint64
+	lastCheck time.Time
+	// receivedAt is the last time bytes were received, or no byte
+	// was sent such that no byte was expected to be received.
+	receivedAt time.Time
+	// degraded is true if the throughput is stalled.
+	degraded bool
+}
+
+var ErrThroughputStalled = errors.New("VPN throughput is stalled")
+
+// checkThroughput reads the VPN interface counters and returns an
+// error if no byte was received while bytes were sent for at least
+// the stall duration. It returns nil if the check is disabled.
+func (s *Server) checkThroughput() (err error) {
+	stallDuration := *s.config.Throughput.StallDuration
+	if stallDuration == 0 {
+		return nil
+	}
+
+	t := &s.throughput
+	now := t.timeNow()
+	rx, tx, err := t.counters.GetCounters()
+	switch {
+	case err != nil:
+		// The VPN interface does not exist while the VPN is down.
+		t.lastCheck = time.Time{}
+		return nil
+	case t.lastCheck.IsZero(), rx < t.lastRx, tx < t.lastTx:
+		// First check or the counters restarted from 0 with
+		// a new VPN interface.
+		t.receivedAt = now
+	case rx > t.lastRx, tx == t.lastTx:
+		t.receivedAt = now
+	}
+	t.lastRx, t.lastTx, t.lastCheck = rx, tx, now
+
+	stalledFor := now.Sub(t.receivedAt)
+	if stalledFor < stallDuration {
+		return nil
+	}
+	return fmt.Errorf("%w: no byte received for %s while sending bytes",
+		ErrThroughputStalled, stalledFor.Round(time.Second))
+}
+
+// onThroughputResult logs the VPN as degraded once its throughput
+// is stalled, and reconnects it if reconnecting is enabled, recording
+// the failure of the server so another server is tried.
+func (s *Server) onThroughputResult(ctx context.Context, err error) {
+	t := &s.throughput
+	if err == nil {
+		if t.degraded {
+			s.logger.Info("VPN throughput recovered")
+		}
+		t.degraded = false
+		return
+	}
+
+	if !*s.config.Throughput.Reconnect {
+		if !t.degraded {
+			s.logger.Info("degraded: " + err.Error())
+		}
+		t.degraded = true
+		return
+	}
+
+	s.logger.Info("degraded: " + err.Error() + ": reconnecting VPN")
+	if server, ok := s.vpn.loop.GetConnectedServer(); ok {
+		s.vpn.serverStats.Failure(serverstats.Key(server.Hostname, server.IP))
+	}
+	_, _ = s.vpn.loop.ApplyStatus(ctx, constants.Stopped)
+	_, _ = s.vpn.loop.ApplyStatus(ctx, constants.Running)
+	t.lastCheck = time.Time{}
+	t.degraded = false
+}
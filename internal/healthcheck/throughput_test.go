@@ -0,0 +1,94 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCounters struct {
+	rx, tx uint64
+	err    error
+}
+
+func (f *fakeCounters) GetCounters() (rxBytes, txBytes uint64, err error) {
+	return f.rx, f.tx, f.err
+}
+
+func Test_Server_checkThroughput(t *testing.T) {
+	t.Parallel()
+
+	counters := &fakeCounters{}
+	now := time.Unix(0, 0)
+	stallDuration := time.Minute
+	server := &Server{
+		config: settings.Health{
+			Throughput: settings.HealthThroughput{StallDuration: &stallDuration},
+		},
+		throughput: throughputHealth{
+			counters: counters,
+			timeNow:  func() time.Time { return now },
+		},
+	}
+
+	check := func(elapsed time.Duration, rx, tx uint64) error {
+		now = now.Add(elapsed)
+		counters.rx, counters.tx = rx, tx
+		return server.checkThroughput()
+	}
+
+	assert.NoError(t, check(0, 100, 100))
+	// Nothing sent so nothing expected to be received.
+	assert.NoError(t, check(time.Minute, 100, 100))
+	// Bytes sent and none received.
+	assert.NoError(t, check(30*time.Second, 100, 200))
+	assert.ErrorIs(t, check(30*time.Second, 100, 300), ErrThroughputStalled)
+	// Bytes received again.
+	assert.NoError(t, check(30*time.Second, 200, 400))
+	assert.NoError(t, check(30*time.Second, 200, 500))
+
+	// A new VPN interface restarts its counters from 0.
+	counters.err = errors.New("no interface")
+	assert.NoError(t, server.checkThroughput())
+	counters.err = nil
+	assert.NoError(t, check(time.Hour, 0, 10))
+	assert.ErrorIs(t, check(time.Minute, 0, 20), ErrThroughputStalled)
+}
+
+func Test_Server_onThroughputResult(t *testing.T) {
+	t.Parallel()
+
+	reconnect := true
+	loop := &fakeVPNLoop{server: &models.ConnectedServer{Hostname: "a.example.com"}}
+	recorder := &fakeFailureRecorder{}
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	logger.EXPECT().Info("degraded: " + ErrThroughputStalled.Error() + ": reconnecting VPN")
+	server := &Server{
+		logger: logger,
+		config: settings.Health{
+			Throughput: settings.HealthThroughput{Reconnect: &reconnect},
+		},
+		vpn: vpnHealth{
+			loop:        loop,
+			serverStats: recorder,
+		},
+		throughput: throughputHealth{lastCheck: time.Unix(1, 0)},
+	}
+	ctx := context.Background()
+
+	server.onThroughputResult(ctx, nil)
+	assert.Empty(t, loop.statuses)
+
+	server.onThroughputResult(ctx, ErrThroughputStalled)
+	assert.Equal(t, []models.LoopStatus{constants.Stopped, constants.Running}, loop.statuses)
+	assert.Equal(t, []string{"a.example.com"}, recorder.failures)
+	assert.True(t, server.throughput.lastCheck.IsZero())
+}
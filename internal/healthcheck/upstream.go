@@ -0,0 +1,59 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// upstreamHealth checks the readiness of an upstream
+// gluetun used as the egress of the VPN connection.
+type upstreamHealth struct {
+	// address is the address of the health server of the upstream
+	// gluetun, and is the empty string if there is no upstream gluetun.
+	address string
+	client  *http.Client
+}
+
+func newUpstreamHealth(address string) upstreamHealth {
+	const timeout = 3 * time.Second
+	return upstreamHealth{
+		address: address,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+var ErrUpstreamNotReady = errors.New("upstream gluetun is not ready")
+
+// check returns nil if there is no upstream gluetun, or if its
+// health server responds its VPN is running and healthy.
+func (u *upstreamHealth) check(ctx context.Context) (err error) {
+	if u.address == "" {
+		return nil
+	}
+
+	url := "http://" + u.address + "/ready"
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	response, err := u.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUpstreamNotReady, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("%w: %d %s: %s", ErrUpstreamNotReady,
+			response.StatusCode, http.StatusText(response.StatusCode),
+			strings.TrimSpace(string(b)))
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_upstreamHealth_check(t *testing.T) {
+	t.Parallel()
+
+	var ready atomic.Bool
+	ready.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/ready" {
+				http.NotFound(w, r)
+				return
+			}
+			if !ready.Load() {
+				http.Error(w, "VPN is not running: VPN is stopped", http.StatusServiceUnavailable)
+			}
+		}))
+	t.Cleanup(server.Close)
+	address := strings.TrimPrefix(server.URL, "http://")
+
+	ctx := context.Background()
+
+	noUpstream := newUpstreamHealth("")
+	err := noUpstream.check(ctx)
+	assert.NoError(t, err)
+
+	upstream := newUpstreamHealth(address)
+	err = upstream.check(ctx)
+	assert.NoError(t, err)
+
+	ready.Store(false)
+	err = upstream.check(ctx)
+	assert.ErrorIs(t, err, ErrUpstreamNotReady)
+	assert.EqualError(t, err, "upstream gluetun is not ready: "+
+		"503 Service Unavailable: VPN is not running: VPN is stopped")
+
+	server.Close()
+	err = upstream.check(ctx)
+	assert.ErrorIs(t, err, ErrUpstreamNotReady)
+}
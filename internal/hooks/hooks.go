@@ -0,0 +1,109 @@
+// Package hooks runs the user executables found in the
+// stage directories of the hooks directory at defined
+// stages of the program lifecycle.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/golibs/command"
+)
+
+type Runner struct {
+	settings settings.Hooks
+	cmder    command.Runner
+	logger   Logger
+}
+
+func New(settings settings.Hooks, cmder command.Runner, logger Logger) *Runner {
+	return &Runner{
+		settings: settings,
+		cmder:    cmder,
+		logger:   logger,
+	}
+}
+
+// Run runs the executables of the stage directory given in lexical
+// order, each with the hooks timeout. It returns an error only if a
+// hook fails and the failure policy is abort, in which case the
+// remaining hooks of the stage are not run.
+func (r *Runner) Run(ctx context.Context, stage string) (err error) {
+	if *r.settings.Directory == "" {
+		return nil
+	}
+
+	paths, err := listExecutables(filepath.Join(*r.settings.Directory, stage))
+	if err != nil {
+		return r.onFailure(fmt.Errorf("listing %s hooks: %w", stage, err))
+	}
+
+	for _, path := range paths {
+		err = r.runHook(ctx, stage, path)
+		if err != nil {
+			err = r.onFailure(fmt.Errorf("%s hook %s: %w", stage, filepath.Base(path), err))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *Runner) onFailure(err error) error {
+	if r.settings.FailurePolicy == "abort" {
+		return err
+	}
+	r.logger.Error(err.Error())
+	return nil
+}
+
+func (r *Runner) runHook(ctx context.Context, stage, path string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, *r.settings.Timeout)
+	defer cancel()
+
+	r.logger.Info("running " + stage + " hook " + filepath.Base(path))
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Env = append(os.Environ(), "GLUETUN_HOOK_STAGE="+stage)
+	output, err := r.cmder.Run(cmd)
+	if output = strings.TrimSpace(output); output != "" {
+		r.logger.Info(filepath.Base(path) + ": " + output)
+	}
+	if ctxErr := ctx.Err(); errors.Is(ctxErr, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", *r.settings.Timeout)
+	}
+	return err
+}
+
+// listExecutables returns the paths of the executable files in
+// the directory given, in lexical order. It returns no path if
+// the directory does not exist.
+func listExecutables(directory string) (paths []string, err error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, entry := range entries { // already sorted by name
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		const executableBits = 0o111
+		if !info.Mode().IsRegular() || info.Mode().Perm()&executableBits == 0 {
+			continue
+		}
+		paths = append(paths, filepath.Join(directory, entry.Name()))
+	}
+	return paths, nil
+}
@@ -0,0 +1,123 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/golibs/command"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeHook(t *testing.T, directory, name, content string, mode os.FileMode) {
+	t.Helper()
+	path := filepath.Join(directory, name)
+	err := os.WriteFile(path, []byte("#!/bin/sh\n"+content+"\n"), mode)
+	require.NoError(t, err)
+}
+
+func Test_Runner_Run(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		failurePolicy string
+		hooks         map[string]string
+		output        string
+		errMessage    string
+		expectLogs    func(logger *MockLogger)
+	}{
+		"no stage directory": {
+			failurePolicy: "continue",
+		},
+		"hooks run in order": {
+			failurePolicy: "continue",
+			hooks: map[string]string{
+				"20-second": `echo second >> "$OUTPUT"`,
+				"10-first":  `echo "first $GLUETUN_HOOK_STAGE" >> "$OUTPUT"`,
+			},
+			output: "first pre-firewall\nsecond\n",
+			expectLogs: func(logger *MockLogger) {
+				gomock.InOrder(
+					logger.EXPECT().Info("running pre-firewall hook 10-first"),
+					logger.EXPECT().Info("running pre-firewall hook 20-second"),
+				)
+			},
+		},
+		"failure continues": {
+			failurePolicy: "continue",
+			hooks: map[string]string{
+				"10-fail":  "exit 1",
+				"20-after": `echo after >> "$OUTPUT"`,
+			},
+			output: "after\n",
+			expectLogs: func(logger *MockLogger) {
+				gomock.InOrder(
+					logger.EXPECT().Info("running pre-firewall hook 10-fail"),
+					logger.EXPECT().Error("pre-firewall hook 10-fail: exit status 1"),
+					logger.EXPECT().Info("running pre-firewall hook 20-after"),
+				)
+			},
+		},
+		"failure aborts": {
+			failurePolicy: "abort",
+			hooks: map[string]string{
+				"10-fail":  "exit 1",
+				"20-after": `echo after >> "$OUTPUT"`,
+			},
+			errMessage: "pre-firewall hook 10-fail: exit status 1",
+			expectLogs: func(logger *MockLogger) {
+				logger.EXPECT().Info("running pre-firewall hook 10-fail")
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			logger := NewMockLogger(ctrl)
+			if testCase.expectLogs != nil {
+				testCase.expectLogs(logger)
+			}
+
+			directory := t.TempDir()
+			outputPath := filepath.Join(directory, "output")
+			if testCase.hooks != nil {
+				stageDirectory := filepath.Join(directory, "pre-firewall")
+				err := os.Mkdir(stageDirectory, 0o700)
+				require.NoError(t, err)
+				for hookName, content := range testCase.hooks {
+					content = "OUTPUT=" + outputPath + "\n" + content
+					writeHook(t, stageDirectory, hookName, content, 0o700)
+				}
+				// Non executable files are ignored.
+				writeHook(t, stageDirectory, "00-not-executable", "exit 1", 0o600)
+			}
+
+			timeout := time.Second
+			runner := New(settings.Hooks{
+				Directory:     &directory,
+				Timeout:       &timeout,
+				FailurePolicy: testCase.failurePolicy,
+			}, command.NewCmder(), logger)
+
+			err := runner.Run(context.Background(), "pre-firewall")
+
+			if testCase.errMessage != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			output, _ := os.ReadFile(outputPath)
+			assert.Equal(t, testCase.output, string(output))
+		})
+	}
+}
@@ -0,0 +1,6 @@
+package hooks
+
+type Logger interface {
+	Info(s string)
+	Error(s string)
+}
@@ -9,7 +9,8 @@ import (
 )
 
 func newHandler(ctx context.Context, wg *sync.WaitGroup, logger Logger,
-	stealth, verbose bool, username, password string) http.Handler {
+	stealth, verbose bool, username, password string,
+	connectPorts []uint16, plainHTTPAnyPort bool) http.Handler {
 	const httpTimeout = 24 * time.Hour
 	return &handler{
 		ctx: ctx,
@@ -17,11 +18,13 @@ func newHandler(ctx context.Context, wg *sync.WaitGroup, logger Logger,
 		client: &http.Client{
 			Timeout:       httpTimeout,
 			CheckRedirect: returnRedirect},
-		logger:   logger,
-		verbose:  verbose,
-		stealth:  stealth,
-		username: username,
-		password: password,
+		logger:           logger,
+		verbose:          verbose,
+		stealth:          stealth,
+		username:         username,
+		password:         password,
+		connectPorts:     connectPorts,
+		plainHTTPAnyPort: plainHTTPAnyPort,
 	}
 }
 
@@ -32,6 +35,8 @@ type handler struct {
 	logger             Logger
 	verbose, stealth   bool
 	username, password string
+	connectPorts       []uint16
+	plainHTTPAnyPort   bool
 }
 
 func (h *handler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
@@ -41,6 +46,9 @@ func (h *handler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Re
 	if !h.isAuthorized(responseWriter, request) {
 		return
 	}
+	if !h.isPortAllowed(responseWriter, request) {
+		return
+	}
 	request.Header.Del("Proxy-Connection")
 	request.Header.Del("Proxy-Authenticate")
 	request.Header.Del("Proxy-Authorization")
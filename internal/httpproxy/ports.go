@@ -0,0 +1,54 @@
+package httpproxy
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// isPortAllowed returns true if the destination port of the request
+// is allowed, and responds with a forbidden status otherwise, so the
+// proxy cannot be used as an open relay to any port.
+func (h *handler) isPortAllowed(responseWriter http.ResponseWriter, request *http.Request) (allowed bool) {
+	var port string
+	if request.Method == http.MethodConnect {
+		_, port, _ = net.SplitHostPort(request.Host)
+		allowed = isPortOneOf(port, h.connectPorts)
+	} else {
+		port = request.URL.Port()
+		allowed = port == "" || h.plainHTTPAnyPort ||
+			port == defaultSchemePort(request.URL.Scheme)
+	}
+
+	if !allowed {
+		h.logger.Info("destination port " + port + " is not allowed for " +
+			request.Method + " from " + request.RemoteAddr)
+		http.Error(responseWriter, "destination port not allowed", http.StatusForbidden)
+	}
+	return allowed
+}
+
+func isPortOneOf(port string, ports []uint16) bool {
+	const base, bitSize = 10, 16
+	value, err := strconv.ParseUint(port, base, bitSize)
+	if err != nil {
+		return false
+	}
+	for _, allowedPort := range ports {
+		if uint16(value) == allowedPort {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultSchemePort(scheme string) (port string) {
+	switch scheme {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	default:
+		return ""
+	}
+}
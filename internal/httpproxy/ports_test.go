@@ -0,0 +1,86 @@
+package httpproxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_handler_isPortAllowed(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		method           string
+		target           string
+		plainHTTPAnyPort bool
+		allowed          bool
+		logMessage       string
+	}{
+		"CONNECT allowed port": {
+			method:  http.MethodConnect,
+			target:  "example.com:443",
+			allowed: true,
+		},
+		"CONNECT allowlisted port": {
+			method:  http.MethodConnect,
+			target:  "example.com:8443",
+			allowed: true,
+		},
+		"CONNECT port not allowed": {
+			method:     http.MethodConnect,
+			target:     "example.com:22",
+			logMessage: "destination port 22 is not allowed for CONNECT from 192.0.2.1:1234",
+		},
+		"HTTP default port": {
+			method:  http.MethodGet,
+			target:  "http://example.com/",
+			allowed: true,
+		},
+		"HTTP explicit default port": {
+			method:  http.MethodGet,
+			target:  "http://example.com:80/",
+			allowed: true,
+		},
+		"HTTP port not allowed": {
+			method:     http.MethodGet,
+			target:     "http://example.com:25/",
+			logMessage: "destination port 25 is not allowed for GET from 192.0.2.1:1234",
+		},
+		"HTTP any port allowed": {
+			method:           http.MethodGet,
+			target:           "http://example.com:8080/",
+			plainHTTPAnyPort: true,
+			allowed:          true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			logger := NewMockLogger(ctrl)
+			if testCase.logMessage != "" {
+				logger.EXPECT().Info(testCase.logMessage)
+			}
+			h := &handler{
+				logger:           logger,
+				connectPorts:     []uint16{443, 8443},
+				plainHTTPAnyPort: testCase.plainHTTPAnyPort,
+			}
+			request := httptest.NewRequest(testCase.method, testCase.target, nil)
+			recorder := httptest.NewRecorder()
+
+			allowed := h.isPortAllowed(recorder, request)
+
+			assert.Equal(t, testCase.allowed, allowed)
+			if !testCase.allowed {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			}
+		})
+	}
+}
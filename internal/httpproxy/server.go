@@ -18,11 +18,14 @@ type Server struct {
 
 func New(ctx context.Context, address string, logger Logger,
 	stealth, verbose bool, username, password string,
+	connectPorts []uint16, plainHTTPAnyPort bool,
 	readHeaderTimeout, readTimeout time.Duration) *Server {
 	wg := &sync.WaitGroup{}
+	handler := newHandler(ctx, wg, logger, stealth, verbose,
+		username, password, connectPorts, plainHTTPAnyPort)
 	return &Server{
 		address:           address,
-		handler:           newHandler(ctx, wg, logger, stealth, verbose, username, password),
+		handler:           handler,
 		logger:            logger,
 		internalWG:        wg,
 		readHeaderTimeout: readHeaderTimeout,
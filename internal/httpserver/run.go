@@ -2,6 +2,7 @@ package httpserver
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"net"
 	"net/http"
@@ -50,8 +51,14 @@ func (s *Server) Run(ctx context.Context, ready chan<- struct{}, done chan<- str
 	s.address = listener.Addr().String()
 	close(s.addressSet)
 
+	scheme := "http"
+	if s.tlsConfig != nil {
+		listener = tls.NewListener(listener, s.tlsConfig)
+		scheme = "https"
+	}
+
 	// note: no further write so no need to mutex
-	s.logger.Info("http server listening on " + s.address)
+	s.logger.Info(scheme + " server listening on " + s.address)
 	close(ready)
 
 	err = server.Serve(listener)
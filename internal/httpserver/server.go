@@ -1,6 +1,7 @@
 package httpserver
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"time"
@@ -16,6 +17,7 @@ type Server struct {
 	readHeaderTimeout time.Duration
 	readTimeout       time.Duration
 	shutdownTimeout   time.Duration
+	tlsConfig         *tls.Config
 }
 
 // New creates a new HTTP server with the given settings.
@@ -35,5 +37,6 @@ func New(settings Settings) (s *Server, err error) {
 		readHeaderTimeout: settings.ReadHeaderTimeout,
 		readTimeout:       settings.ReadTimeout,
 		shutdownTimeout:   settings.ShutdownTimeout,
+		tlsConfig:         settings.TLSConfig,
 	}, nil
 }
@@ -1,6 +1,7 @@
 package httpserver
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
@@ -31,6 +32,9 @@ type Settings struct {
 	// ShutdownTimeout is the shutdown timeout duration
 	// of the HTTP server. It defaults to 3 seconds if left unset.
 	ShutdownTimeout time.Duration
+	// TLSConfig is the TLS configuration to serve HTTPS.
+	// It can be left to nil to serve plain HTTP.
+	TLSConfig *tls.Config
 }
 
 func (s *Settings) SetDefaults() {
@@ -50,6 +54,7 @@ func (s Settings) Copy() Settings {
 		ReadHeaderTimeout: s.ReadHeaderTimeout,
 		ReadTimeout:       s.ReadTimeout,
 		ShutdownTimeout:   s.ShutdownTimeout,
+		TLSConfig:         s.TLSConfig,
 	}
 }
 
@@ -62,6 +67,9 @@ func (s *Settings) MergeWith(other Settings) {
 	s.ReadHeaderTimeout = helpers.MergeWithDuration(s.ReadHeaderTimeout, other.ReadHeaderTimeout)
 	s.ReadTimeout = helpers.MergeWithDuration(s.ReadTimeout, other.ReadTimeout)
 	s.ShutdownTimeout = helpers.MergeWithDuration(s.ShutdownTimeout, other.ShutdownTimeout)
+	if s.TLSConfig == nil {
+		s.TLSConfig = other.TLSConfig
+	}
 }
 
 func (s *Settings) OverrideWith(other Settings) {
@@ -73,6 +81,9 @@ func (s *Settings) OverrideWith(other Settings) {
 	s.ReadHeaderTimeout = helpers.OverrideWithDuration(s.ReadHeaderTimeout, other.ReadHeaderTimeout)
 	s.ReadTimeout = helpers.OverrideWithDuration(s.ReadTimeout, other.ReadTimeout)
 	s.ShutdownTimeout = helpers.OverrideWithDuration(s.ShutdownTimeout, other.ShutdownTimeout)
+	if other.TLSConfig != nil {
+		s.TLSConfig = other.TLSConfig
+	}
 }
 
 var (
@@ -127,6 +138,9 @@ func (s Settings) ToLinesNode() (node *gotree.Node) {
 	node.Appendf("Read header timeout: %s", s.ReadHeaderTimeout)
 	node.Appendf("Read timeout: %s", s.ReadTimeout)
 	node.Appendf("Shutdown timeout: %s", s.ShutdownTimeout)
+	if s.TLSConfig != nil {
+		node.Appendf("TLS: yes")
+	}
 	return node
 }
 
@@ -0,0 +1,191 @@
+package ikev2
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+const (
+	strongswanConfPath = "/etc/strongswan.conf"
+	swanctlConfPath    = "/etc/swanctl/swanctl.conf"
+	caDirectory        = "/etc/swanctl/x509ca"
+	systemCAsPath      = "/etc/ssl/certs/ca-certificates.crt"
+)
+
+// connectionName is the name of both the IKE connection
+// and its child security association.
+const connectionName = "gluetun"
+
+func (r *Runner) writeConfig() (err error) {
+	caFiles, err := writeCACertificates(*r.settings.CACertificate)
+	if err != nil {
+		return fmt.Errorf("writing CA certificates: %w", err)
+	}
+
+	const permission = 0600
+	err = os.WriteFile(strongswanConfPath,
+		[]byte(strongswanConf(r.settings.Interface)), permission)
+	if err != nil {
+		return fmt.Errorf("writing strongSwan configuration: %w", err)
+	}
+
+	err = os.WriteFile(swanctlConfPath,
+		[]byte(swanctlConf(r.settings, caFiles)), permission)
+	if err != nil {
+		return fmt.Errorf("writing swanctl configuration: %w", err)
+	}
+
+	return nil
+}
+
+// strongswanConf returns the charon daemon configuration. Charon does
+// not install any route, only the virtual IP address on the XFRM
+// interface given, and marks its IKE socket so the IKE traffic is
+// routed outside the XFRM interface.
+func strongswanConf(interfaceName string) string {
+	return `charon {
+  install_routes = no
+  install_virtual_ip_on = ` + interfaceName + `
+  filelog {
+    stderr {
+      default = 0
+      dmn = 1
+      ike = 1
+    }
+  }
+  load_modular = yes
+  plugins {
+    include strongswan.d/charon/*.conf
+    socket-default {
+      fwmark = ` + fmt.Sprint(firewallMark) + `
+    }
+  }
+}
+`
+}
+
+// swanctlConf returns the swanctl configuration of the connection
+// to the IKEv2 server, authenticating with EAP-MSCHAPv2 and verifying
+// the server certificate against the CA files given, relative to the
+// swanctl x509ca directory. ESP is always encapsulated in UDP on port
+// 4500, so only that port has to be allowed through the firewall.
+func swanctlConf(settings settings.IKEv2, caFiles []string) string {
+	remoteID := *settings.RemoteID
+	if remoteID == "" {
+		remoteID = settings.Server
+	}
+
+	lines := []string{
+		"connections {",
+		"  " + connectionName + " {",
+		"    version = 2",
+		"    remote_addrs = " + settings.Server,
+		"    remote_port = 4500",
+		"    encap = yes",
+		"    vips = 0.0.0.0",
+		"    dpd_delay = 30s",
+		"    local {",
+		"      auth = eap-mschapv2",
+		"      eap_id = " + quote(*settings.User),
+		"    }",
+		"    remote {",
+		"      auth = pubkey",
+		"      id = " + quote(remoteID),
+		"      cacerts = " + strings.Join(caFiles, ","),
+		"    }",
+		"    children {",
+		"      " + connectionName + " {",
+		"        remote_ts = 0.0.0.0/0",
+		fmt.Sprintf("        if_id_in = %d", interfaceID),
+		fmt.Sprintf("        if_id_out = %d", interfaceID),
+		fmt.Sprintf("        set_mark_out = %d", firewallMark),
+		"      }",
+		"    }",
+		"  }",
+		"}",
+		"secrets {",
+		"  eap-" + connectionName + " {",
+		"    id = " + quote(*settings.User),
+		"    secret = " + quote(*settings.Password),
+		"  }",
+		"}",
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// quote returns the string given as a quoted swanctl value.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+var ErrSystemCANotFound = errors.New("no system CA certificate found")
+
+// writeCACertificates writes the base64 DER CA certificate given,
+// or each of the system CA certificates if it is empty, to the swanctl
+// x509ca directory. It returns the file names written.
+func writeCACertificates(base64DER string) (fileNames []string, err error) {
+	var blocks []*pem.Block
+	if base64DER != "" {
+		der, err := base64.StdEncoding.DecodeString(base64DER)
+		if err != nil {
+			return nil, fmt.Errorf("decoding CA certificate: %w", err)
+		}
+		blocks = []*pem.Block{{Type: "CERTIFICATE", Bytes: der}}
+	} else {
+		bundle, err := os.ReadFile(systemCAsPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading system CA certificates: %w", err)
+		}
+		blocks = certificateBlocks(bundle)
+		if len(blocks) == 0 {
+			return nil, fmt.Errorf("%w: in %s", ErrSystemCANotFound, systemCAsPath)
+		}
+	}
+
+	// Remove CA certificates left from a previous connection.
+	err = os.RemoveAll(caDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("removing CA directory: %w", err)
+	}
+	const dirPermission = 0700
+	err = os.MkdirAll(caDirectory, dirPermission)
+	if err != nil {
+		return nil, fmt.Errorf("creating CA directory: %w", err)
+	}
+
+	fileNames = make([]string, len(blocks))
+	const filePermission = 0600
+	for i, block := range blocks {
+		fileNames[i] = fmt.Sprintf("ca%d.pem", i)
+		path := filepath.Join(caDirectory, fileNames[i])
+		err = os.WriteFile(path, pem.EncodeToMemory(block), filePermission)
+		if err != nil {
+			return nil, fmt.Errorf("writing CA certificate: %w", err)
+		}
+	}
+	return fileNames, nil
+}
+
+// certificateBlocks returns the PEM certificate blocks of the
+// PEM bundle given, since swanctl only loads one certificate per file.
+func certificateBlocks(bundle []byte) (blocks []*pem.Block) {
+	for {
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			return blocks
+		}
+		if block.Type == "CERTIFICATE" {
+			blocks = append(blocks, block)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package ikev2
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptrTo(s string) *string { return &s }
+
+func Test_swanctlConf(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		settings settings.IKEv2
+		caFiles  []string
+		conf     string
+	}{
+		"remote ID defaults to server": {
+			settings: settings.IKEv2{
+				Server:   "1.2.3.4",
+				RemoteID: ptrTo(""),
+				User:     ptrTo("user"),
+				Password: ptrTo("password"),
+			},
+			caFiles: []string{"ca0.pem", "ca1.pem"},
+			conf: `connections {
+  gluetun {
+    version = 2
+    remote_addrs = 1.2.3.4
+    remote_port = 4500
+    encap = yes
+    vips = 0.0.0.0
+    dpd_delay = 30s
+    local {
+      auth = eap-mschapv2
+      eap_id = "user"
+    }
+    remote {
+      auth = pubkey
+      id = "1.2.3.4"
+      cacerts = ca0.pem,ca1.pem
+    }
+    children {
+      gluetun {
+        remote_ts = 0.0.0.0/0
+        if_id_in = 1
+        if_id_out = 1
+        set_mark_out = 51822
+      }
+    }
+  }
+}
+secrets {
+  eap-gluetun {
+    id = "user"
+    secret = "password"
+  }
+}
+`,
+		},
+		"remote ID and quoted values": {
+			settings: settings.IKEv2{
+				Server:   "1.2.3.4",
+				RemoteID: ptrTo("vpn.example.com"),
+				User:     ptrTo("us\"er"),
+				Password: ptrTo(`pass\word`),
+			},
+			caFiles: []string{"ca0.pem"},
+			conf: `connections {
+  gluetun {
+    version = 2
+    remote_addrs = 1.2.3.4
+    remote_port = 4500
+    encap = yes
+    vips = 0.0.0.0
+    dpd_delay = 30s
+    local {
+      auth = eap-mschapv2
+      eap_id = "us\"er"
+    }
+    remote {
+      auth = pubkey
+      id = "vpn.example.com"
+      cacerts = ca0.pem
+    }
+    children {
+      gluetun {
+        remote_ts = 0.0.0.0/0
+        if_id_in = 1
+        if_id_out = 1
+        set_mark_out = 51822
+      }
+    }
+  }
+}
+secrets {
+  eap-gluetun {
+    id = "us\"er"
+    secret = "pass\\word"
+  }
+}
+`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := swanctlConf(testCase.settings, testCase.caFiles)
+
+			assert.Equal(t, testCase.conf, conf)
+		})
+	}
+}
+
+func Test_strongswanConf(t *testing.T) {
+	t.Parallel()
+
+	conf := strongswanConf("ipsec0")
+
+	assert.Contains(t, conf, "install_routes = no\n")
+	assert.Contains(t, conf, "install_virtual_ip_on = ipsec0\n")
+	assert.Contains(t, conf, "fwmark = 51822\n")
+}
+
+func Test_certificateBlocks(t *testing.T) {
+	t.Parallel()
+
+	der := newTestCertificate(t)
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	key := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1}})
+	bundle := append(append(append([]byte{}, certificate...), key...), certificate...)
+
+	blocks := certificateBlocks(bundle)
+
+	require.Len(t, blocks, 2)
+	for _, block := range blocks {
+		assert.Equal(t, der, block.Bytes)
+	}
+}
+
+func newTestCertificate(t *testing.T) (der []byte) {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test CA"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err = x509.CreateCertificate(rand.Reader, template, template,
+		&privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+	return der
+}
+
+func Test_isSADownLine(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]bool{
+		"05[IKE] IKE_SA gluetun[1] established between 10.0.0.2[user]...1.2.3.4[1.2.3.4]": false,
+		"13[IKE] deleting IKE_SA gluetun[1] between 10.0.0.2[user]...1.2.3.4[1.2.3.4]":    true,
+		"07[IKE] giving up after 5 retransmits":                                           true,
+	}
+
+	for line, down := range testCases {
+		line, down := line, down
+		t.Run(line, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, down, isSADownLine(line))
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package ikev2
+
+import (
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/golibs/command"
+)
+
+const (
+	// firewallMark marks the IKE and encrypted ESP packets so they
+	// are routed outside the XFRM interface, and is also the routing
+	// table number of the XFRM interface default route.
+	firewallMark = 51822
+	// rulePriority is the priority of the routing rule sending
+	// all unmarked traffic to the XFRM interface routing table.
+	rulePriority = 101
+	// interfaceID is the XFRM interface ID the IPsec
+	// policies and security associations are bound to.
+	interfaceID = 1
+)
+
+// Runner connects to an IKEv2/IPsec server using the strongSwan
+// charon daemon, and routes all traffic through an XFRM interface.
+type Runner struct {
+	settings  settings.IKEv2
+	netlinker NetLinker
+	cmder     command.RunStarter
+	logger    Logger
+}
+
+func New(settings settings.IKEv2, netlinker NetLinker,
+	cmder command.RunStarter, logger Logger) *Runner {
+	return &Runner{
+		settings:  settings,
+		netlinker: netlinker,
+		cmder:     cmder,
+		logger:    logger,
+	}
+}
@@ -0,0 +1,47 @@
+package ikev2
+
+import (
+	"context"
+	"strings"
+)
+
+func streamLines(ctx context.Context, done chan<- struct{},
+	logger Logger, stdout, stderr chan string, saDown chan<- struct{}) {
+	defer close(done)
+
+	var line string
+	for {
+		select {
+		case <-ctx.Done():
+			// Context should only be canceled after stdout and stderr are done
+			// being written to.
+			close(stdout)
+			close(stderr)
+			return
+		case line = <-stdout:
+		case line = <-stderr:
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		logger.Info(line)
+
+		if isSADownLine(line) {
+			select {
+			case saDown <- struct{}{}:
+			default: // already signaled
+			}
+		}
+	}
+}
+
+// isSADownLine returns true if the charon log line given
+// indicates the IKE security association is down, either
+// because it got deleted or because the server stopped
+// answering dead peer detection requests.
+func isSADownLine(line string) bool {
+	return strings.Contains(line, "deleting IKE_SA") ||
+		strings.Contains(line, "giving up after")
+}
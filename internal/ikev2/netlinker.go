@@ -0,0 +1,12 @@
+package ikev2
+
+import "github.com/qdm12/gluetun/internal/netlink"
+
+type NetLinker interface {
+	LinkAdd(link netlink.Link) (err error)
+	LinkDel(link netlink.Link) (err error)
+	LinkSetUp(link netlink.Link) (err error)
+	RouteAdd(route *netlink.Route) error
+	RuleAdd(rule *netlink.Rule) error
+	RuleDel(rule *netlink.Rule) error
+}
@@ -0,0 +1,185 @@
+package ikev2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/netlink"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	charonPath  = "/usr/lib/strongswan/charon"
+	swanctlPath = "swanctl"
+)
+
+var (
+	ErrCharonExited = errors.New("charon exited")
+	ErrSADown       = errors.New("IKE security association is down")
+)
+
+// Run starts the charon daemon, connects to the IKEv2 server and routes
+// all the traffic through the XFRM interface. It signals the tunnel is
+// ready once the connection is established, and exits with an error
+// if the security association goes down or charon exits.
+func (r *Runner) Run(ctx context.Context, waitError chan<- error, ready chan<- struct{}) {
+	err := r.writeConfig()
+	if err != nil {
+		waitError <- fmt.Errorf("writing configuration: %w", err)
+		return
+	}
+
+	link := &netlink.Xfrmi{
+		LinkAttrs: netlink.LinkAttrs{Name: r.settings.Interface},
+		Ifid:      interfaceID,
+	}
+	err = r.netlinker.LinkAdd(link)
+	if err != nil {
+		waitError <- fmt.Errorf("adding XFRM interface: %w", err)
+		return
+	}
+	defer func() {
+		err := r.netlinker.LinkDel(link)
+		if err != nil {
+			r.logger.Error("deleting XFRM interface: " + err.Error())
+		}
+	}()
+
+	err = r.netlinker.LinkSetUp(link)
+	if err != nil {
+		waitError <- fmt.Errorf("setting XFRM interface up: %w", err)
+		return
+	}
+
+	charonCtx, charonCancel := context.WithCancel(context.Background())
+	defer charonCancel()
+	cmd := exec.CommandContext(charonCtx, charonPath)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	stdoutLines, stderrLines, charonWaitError, err := r.cmder.Start(cmd)
+	if err != nil {
+		waitError <- fmt.Errorf("starting charon: %w", err)
+		return
+	}
+
+	streamCtx, streamCancel := context.WithCancel(context.Background())
+	streamDone := make(chan struct{})
+	saDown := make(chan struct{}, 1)
+	go streamLines(streamCtx, streamDone, r.logger, stdoutLines, stderrLines, saDown)
+	stopCharon := func() (err error) {
+		charonCancel()
+		err = <-charonWaitError
+		close(charonWaitError)
+		streamCancel()
+		<-streamDone
+		return err
+	}
+
+	err = r.connect(ctx, link)
+	if err != nil {
+		_ = stopCharon()
+		waitError <- err
+		return
+	}
+	defer r.removeRule()
+
+	r.logger.Info("IKEv2 is up")
+	ready <- struct{}{}
+
+	select {
+	case <-ctx.Done():
+		_ = stopCharon()
+		waitError <- ctx.Err()
+	case <-saDown:
+		_ = stopCharon()
+		waitError <- fmt.Errorf("%w", ErrSADown)
+	case err := <-charonWaitError:
+		close(charonWaitError)
+		streamCancel()
+		<-streamDone
+		waitError <- fmt.Errorf("%w: %s", ErrCharonExited, err)
+	}
+}
+
+// connect loads the configuration in charon, initiates the
+// connection and routes all the traffic through the link given.
+func (r *Runner) connect(ctx context.Context, link netlink.Link) (err error) {
+	err = r.loadConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	r.logger.Info("Connecting to " + r.settings.Server)
+	cmd := exec.CommandContext(ctx, swanctlPath, "--initiate",
+		"--child", connectionName, "--timeout", "30")
+	output, err := r.cmder.Run(cmd)
+	if err != nil {
+		r.logger.Debug(output)
+		return fmt.Errorf("initiating connection: %w", err)
+	}
+
+	route := &netlink.Route{
+		LinkIndex: link.Attrs().Index,
+		Dst:       &net.IPNet{IP: net.IPv4zero, Mask: net.IPv4Mask(0, 0, 0, 0)},
+		Table:     firewallMark,
+	}
+	err = r.netlinker.RouteAdd(route)
+	if err != nil {
+		return fmt.Errorf("adding route %s: %w", route, err)
+	}
+
+	err = r.netlinker.RuleAdd(newRule())
+	if err != nil {
+		return fmt.Errorf("adding rule: %w", err)
+	}
+	return nil
+}
+
+// loadConfig loads the swanctl configuration in charon, retrying
+// until charon is ready to accept it on its control socket.
+func (r *Runner) loadConfig(ctx context.Context) (err error) {
+	const tries = 20
+	const retryPeriod = 250 * time.Millisecond
+	for i := 0; i < tries; i++ {
+		cmd := exec.CommandContext(ctx, swanctlPath, "--load-all", "--noprompt")
+		var output string
+		output, err = r.cmder.Run(cmd)
+		if err == nil {
+			return nil
+		}
+		r.logger.Debug(output)
+
+		timer := time.NewTimer(retryPeriod)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+// newRule returns the rule routing all the traffic not marked
+// by charon or the kernel IPsec stack to the XFRM interface table.
+func newRule() (rule *netlink.Rule) {
+	rule = netlink.NewRule()
+	rule.Invert = true
+	rule.Priority = rulePriority
+	rule.Mark = firewallMark
+	rule.Table = firewallMark
+	rule.Family = unix.AF_INET
+	return rule
+}
+
+func (r *Runner) removeRule() {
+	rule := newRule()
+	err := r.netlinker.RuleDel(rule)
+	if err != nil {
+		r.logger.Error(fmt.Sprintf("deleting rule %s: %s", rule, err))
+	}
+}
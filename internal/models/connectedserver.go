@@ -0,0 +1,27 @@
+package models
+
+import (
+	"net"
+	"time"
+)
+
+// ConnectedServer contains information on the VPN
+// server the VPN is connected to.
+type ConnectedServer struct {
+	Provider   string `json:"provider"`
+	VPN        string `json:"vpn"`
+	Country    string `json:"country,omitempty"`
+	Region     string `json:"region,omitempty"`
+	City       string `json:"city,omitempty"`
+	ISP        string `json:"isp,omitempty"`
+	ServerName string `json:"server_name,omitempty"`
+	Hostname   string `json:"hostname,omitempty"`
+	IP         net.IP `json:"ip"`
+	Port       uint16 `json:"port"`
+	Protocol   string `json:"protocol"`
+	// ConnectedAt is the time the VPN tunnel was last
+	// up, and is the zero time until the tunnel is up.
+	ConnectedAt time.Time `json:"connected_at"`
+	// SelectionReason describes why this server was picked.
+	SelectionReason string `json:"selection_reason"`
+}
@@ -121,6 +121,8 @@ func getMarkdownHeaders(vpnProvider string) (headers []string) {
 		return []string{countryHeader, cityHeader, ispHeader, ownedHeader, hostnameHeader, vpnHeader}
 	case providers.Nordvpn:
 		return []string{countryHeader, regionHeader, cityHeader, hostnameHeader}
+	case providers.Ovpn:
+		return []string{countryHeader, cityHeader, nameHeader, hostnameHeader, vpnHeader, tcpHeader, udpHeader}
 	case providers.Perfectprivacy:
 		return []string{cityHeader, tcpHeader, udpHeader}
 	case providers.Privado:
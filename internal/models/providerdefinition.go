@@ -0,0 +1,91 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+)
+
+// ProviderDefinition is a VPN provider defined by the user,
+// with its servers and how to connect to them.
+type ProviderDefinition struct {
+	// Name is the name of the provider, only used in logs.
+	Name      string                      `json:"name"`
+	OpenVPN   ProviderDefinitionOpenVPN   `json:"openvpn"`
+	Wireguard ProviderDefinitionWireguard `json:"wireguard"`
+	Servers   []Server                    `json:"servers"`
+}
+
+type ProviderDefinitionOpenVPN struct {
+	// TCPPort is the default OpenVPN TCP port of the servers.
+	TCPPort uint16 `json:"tcp_port"`
+	// UDPPort is the default OpenVPN UDP port of the servers.
+	UDPPort uint16 `json:"udp_port"`
+	// Template contains the OpenVPN configuration lines common
+	// to all the servers. The proto, remote and dev lines are
+	// set by Gluetun for the server picked.
+	Template []string `json:"template"`
+}
+
+type ProviderDefinitionWireguard struct {
+	// Port is the default Wireguard port of the servers.
+	Port uint16 `json:"port"`
+}
+
+var (
+	ErrProviderNameEmpty     = errors.New("provider name is empty")
+	ErrNoServer              = errors.New("no server defined")
+	ErrOpenVPNTemplateEmpty  = errors.New("OpenVPN template is empty")
+	ErrOpenVPNTCPPortNotSet  = errors.New("OpenVPN TCP port is not set")
+	ErrOpenVPNUDPPortNotSet  = errors.New("OpenVPN UDP port is not set")
+	ErrWireguardPortNotSet   = errors.New("Wireguard port is not set")
+	ErrServerVPNTypeNotValid = errors.New("server VPN type is not valid")
+)
+
+// Validate returns an error if the provider definition is
+// missing information required to connect to any of its servers.
+func (p ProviderDefinition) Validate() (err error) {
+	if p.Name == "" {
+		return fmt.Errorf("%w", ErrProviderNameEmpty)
+	}
+
+	if len(p.Servers) == 0 {
+		return fmt.Errorf("%w", ErrNoServer)
+	}
+
+	for i, server := range p.Servers {
+		err = p.validateServer(server)
+		if err != nil {
+			return fmt.Errorf("server %d of %d: %w", i+1, len(p.Servers), err)
+		}
+	}
+
+	return nil
+}
+
+func (p ProviderDefinition) validateServer(server Server) (err error) {
+	err = server.HasMinimumInformation()
+	if err != nil {
+		return err
+	}
+
+	switch server.VPN {
+	case vpn.OpenVPN:
+		switch {
+		case len(p.OpenVPN.Template) == 0:
+			return fmt.Errorf("%w", ErrOpenVPNTemplateEmpty)
+		case server.TCP && p.OpenVPN.TCPPort == 0:
+			return fmt.Errorf("%w", ErrOpenVPNTCPPortNotSet)
+		case server.UDP && p.OpenVPN.UDPPort == 0:
+			return fmt.Errorf("%w", ErrOpenVPNUDPPortNotSet)
+		}
+	case vpn.Wireguard:
+		if p.Wireguard.Port == 0 {
+			return fmt.Errorf("%w", ErrWireguardPortNotSet)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrServerVPNTypeNotValid, server.VPN)
+	}
+	return nil
+}
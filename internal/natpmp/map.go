@@ -0,0 +1,64 @@
+package natpmp
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/constants"
+)
+
+var ErrProtocolUnknown = errors.New("protocol is unknown")
+
+// Map requests a port mapping for the protocol given, which can be
+// tcp or udp, on the gateway given. The external port given is only
+// a suggestion and can be 0, and a lifetime of 0 removes the mapping.
+// It returns the external port and lifetime assigned by the gateway.
+func (c *Client) Map(ctx context.Context, gateway net.IP, protocol string,
+	internalPort, requestedExternalPort uint16, requestedLifetime time.Duration) (
+	assignedExternalPort uint16, assignedLifetime time.Duration, err error) {
+	request := make([]byte, 12) //nolint:gomnd
+	// version 0 is request[0]
+	switch protocol {
+	case constants.UDP:
+		request[1] = 1
+	case constants.TCP:
+		request[1] = 2
+	default:
+		return 0, 0, fmt.Errorf("%w: %s", ErrProtocolUnknown, protocol)
+	}
+	// request[2:4] are reserved
+	binary.BigEndian.PutUint16(request[4:6], internalPort)
+	binary.BigEndian.PutUint16(request[6:8], requestedExternalPort)
+	binary.BigEndian.PutUint32(request[8:12], uint32(requestedLifetime/time.Second))
+
+	const responseSize = 16
+	response, err := c.rpc(ctx, gateway, request, responseSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("mapping %s port: %w", protocol, err)
+	}
+
+	// response[4:8] is the seconds since start of epoch
+	// response[8:10] is the internal port
+	assignedExternalPort = binary.BigEndian.Uint16(response[10:12])
+	lifetimeSeconds := binary.BigEndian.Uint32(response[12:16])
+	assignedLifetime = time.Duration(lifetimeSeconds) * time.Second
+	return assignedExternalPort, assignedLifetime, nil
+}
+
+// ExternalAddress returns the public IP address of the gateway given.
+func (c *Client) ExternalAddress(ctx context.Context, gateway net.IP) (
+	externalIP net.IP, err error) {
+	request := []byte{0, 0} // version 0, operation code 0
+	const responseSize = 12
+	response, err := c.rpc(ctx, gateway, request, responseSize)
+	if err != nil {
+		return nil, fmt.Errorf("getting external address: %w", err)
+	}
+
+	// response[4:8] is the seconds since start of epoch
+	return net.IPv4(response[8], response[9], response[10], response[11]), nil
+}
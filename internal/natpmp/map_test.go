@@ -0,0 +1,124 @@
+package natpmp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// launchServer launches a UDP server on localhost replying with
+// the response given, and returns a client using it and a channel
+// receiving the request received.
+func launchServer(t *testing.T, response []byte) (
+	client *Client, requests <-chan []byte) {
+	t.Helper()
+
+	connection, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = connection.Close()
+	})
+
+	requestsCh := make(chan []byte, 1)
+	go func() {
+		buffer := make([]byte, 16) //nolint:gomnd
+		n, address, err := connection.ReadFromUDP(buffer)
+		if err != nil {
+			return
+		}
+		requestsCh <- buffer[:n]
+		_, _ = connection.WriteToUDP(response, address)
+	}()
+
+	client = New()
+	client.serverPort = uint16(connection.LocalAddr().(*net.UDPAddr).Port)
+	client.initialRetry = time.Millisecond
+	client.maxRetries = 2
+	return client, requestsCh
+}
+
+func Test_Client_Map(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		protocol   string
+		response   []byte
+		request    []byte
+		port       uint16
+		lifetime   time.Duration
+		errWrap    error
+		errMessage string
+	}{
+		"unknown protocol": {
+			protocol:   "sctp",
+			errWrap:    ErrProtocolUnknown,
+			errMessage: "protocol is unknown: sctp",
+		},
+		"refused": {
+			protocol: constants.TCP,
+			response: []byte{0, 130, 0, 2, 0, 0, 0, 1, 0, 1, 0, 0, 0, 0, 0, 0},
+			request:  []byte{0, 2, 0, 0, 0, 1, 0, 0, 0, 0, 0, 60},
+			errWrap:  ErrResultCodeNotSuccess,
+			errMessage: "mapping tcp port: result code is not success: " +
+				"not authorized or refused",
+		},
+		"operation code mismatch": {
+			protocol: constants.UDP,
+			response: []byte{0, 130, 0, 0, 0, 0, 0, 1, 0, 1, 0x3, 0xe8, 0, 0, 0, 60},
+			request:  []byte{0, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 60},
+			errWrap:  ErrOperationCodeUnexpected,
+			errMessage: "mapping udp port: operation code is unexpected: " +
+				"130 instead of 129",
+		},
+		"success": {
+			protocol: constants.UDP,
+			response: []byte{0, 129, 0, 0, 0, 0, 0, 1, 0, 1, 0x3, 0xe8, 0, 0, 0, 60},
+			request:  []byte{0, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 60},
+			port:     1000,
+			lifetime: time.Minute,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client, requests := launchServer(t, testCase.response)
+
+			port, lifetime, err := client.Map(context.Background(),
+				net.IPv4(127, 0, 0, 1), testCase.protocol, 1, 0, time.Minute)
+
+			if testCase.errWrap != nil {
+				assert.ErrorIs(t, err, testCase.errWrap)
+			}
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.port, port)
+			assert.Equal(t, testCase.lifetime, lifetime)
+			if testCase.request != nil {
+				assert.Equal(t, testCase.request, <-requests)
+			}
+		})
+	}
+}
+
+func Test_Client_ExternalAddress(t *testing.T) {
+	t.Parallel()
+
+	client, requests := launchServer(t, []byte{0, 128, 0, 0, 0, 0, 0, 1, 1, 2, 3, 4})
+
+	externalIP, err := client.ExternalAddress(context.Background(), net.IPv4(127, 0, 0, 1))
+
+	require.NoError(t, err)
+	assert.Equal(t, net.IPv4(1, 2, 3, 4), externalIP)
+	assert.Equal(t, []byte{0, 0}, <-requests)
+}
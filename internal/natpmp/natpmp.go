@@ -0,0 +1,33 @@
+// Package natpmp implements a NAT-PMP client as defined in RFC 6886,
+// to map ports on a VPN gateway. PCP servers also answer NAT-PMP
+// requests, as described in RFC 6887 Appendix A.
+package natpmp
+
+import (
+	"time"
+)
+
+// Client is a NAT-PMP client.
+type Client struct {
+	serverPort uint16
+	// initialRetry is the initial duration to wait for a response,
+	// which is doubled on each retry as described in RFC 6886 3.1.
+	initialRetry time.Duration
+	maxRetries   int
+}
+
+// New creates a new NAT-PMP client.
+func New() *Client {
+	const (
+		serverPort   = 5351
+		initialRetry = 250 * time.Millisecond
+		// 250ms, 500ms, 1s, 2s, 4s, 8s which is about 15s in total,
+		// shorter than the 64s in total recommended by RFC 6886 3.1.
+		maxRetries = 6
+	)
+	return &Client{
+		serverPort:   serverPort,
+		initialRetry: initialRetry,
+		maxRetries:   maxRetries,
+	}
+}
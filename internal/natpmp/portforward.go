@@ -0,0 +1,100 @@
+package natpmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/provider/utils"
+)
+
+var ErrPortForwardedChanged = errors.New("port forwarded changed")
+
+// lifetime is the lifetime requested for the port mappings,
+// which are renewed before they expire.
+const lifetime = 60 * time.Second
+
+// PortForwarder obtains and keeps a port forwarded on a VPN
+// gateway supporting NAT-PMP, for any VPN provider.
+type PortForwarder struct {
+	client *Client
+	// port is the port obtained in PortForward
+	// and kept forwarded in KeepPortForward.
+	port uint16
+}
+
+func NewPortForwarder() *PortForwarder {
+	return &PortForwarder{
+		client: New(),
+	}
+}
+
+// PortForward maps the same external port for both TCP and UDP on the
+// VPN gateway and returns it.
+func (p *PortForwarder) PortForward(ctx context.Context,
+	objects utils.PortForwardObjects) (ports []uint16, err error) {
+	externalIP, err := p.client.ExternalAddress(ctx, objects.Gateway)
+	if err != nil {
+		return nil, err
+	}
+	objects.Logger.Info("gateway external IPv4 address is " + externalIP.String())
+
+	p.port, err = p.mapPorts(ctx, objects, 0)
+	if err != nil {
+		return nil, err
+	}
+	return []uint16{p.port}, nil
+}
+
+// KeepPortForward renews the port mappings before their lifetime
+// expires, and returns an error if the gateway changes the port.
+func (p *PortForwarder) KeepPortForward(ctx context.Context,
+	objects utils.PortForwardObjects) (err error) {
+	const renewPeriod = lifetime / 2
+	ticker := time.NewTicker(renewPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			renewedPort, err := p.mapPorts(ctx, objects, p.port)
+			if err != nil {
+				return err
+			} else if renewedPort != p.port {
+				return fmt.Errorf("%w: from %d to %d",
+					ErrPortForwardedChanged, p.port, renewedPort)
+			}
+			objects.Renewed()
+		}
+	}
+}
+
+// mapPorts maps the external port suggested, which can be 0,
+// for both UDP and TCP, and returns the external port assigned.
+func (p *PortForwarder) mapPorts(ctx context.Context,
+	objects utils.PortForwardObjects, suggestedPort uint16) (port uint16, err error) {
+	// Internal port 1 and suggested external port 0 let the gateway
+	// pick the external port, as done by ProtonVPN for example.
+	const internalPort = 1
+	udpPort, _, err := p.client.Map(ctx, objects.Gateway, constants.UDP,
+		internalPort, suggestedPort, lifetime)
+	if err != nil {
+		return 0, err
+	}
+
+	tcpPort, _, err := p.client.Map(ctx, objects.Gateway, constants.TCP,
+		internalPort, udpPort, lifetime)
+	if err != nil {
+		return 0, err
+	}
+
+	if tcpPort != udpPort {
+		objects.Logger.Warn(fmt.Sprintf("gateway mapped different TCP port %d and UDP port %d",
+			tcpPort, udpPort))
+	}
+
+	return udpPort, nil
+}
@@ -0,0 +1,135 @@
+package natpmp
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+var (
+	ErrGatewayIPIsNil          = errors.New("gateway IP address is nil")
+	ErrResponseSizeTooSmall    = errors.New("response size is too small")
+	ErrVersionNotSupported     = errors.New("version is not supported")
+	ErrOperationCodeUnexpected = errors.New("operation code is unexpected")
+	ErrResultCodeNotSuccess    = errors.New("result code is not success")
+	ErrNoResponse              = errors.New("no response received")
+)
+
+// rpc sends the request given to the gateway NAT-PMP server and returns
+// the response, retrying with an exponential backoff as described in
+// RFC 6886 3.1. The response is checked to have at least the size given,
+// a matching operation code and a success result code.
+func (c *Client) rpc(ctx context.Context, gateway net.IP,
+	request []byte, minResponseSize int) (response []byte, err error) {
+	if gateway == nil {
+		return nil, fmt.Errorf("%w", ErrGatewayIPIsNil)
+	}
+
+	gatewayAddress := &net.UDPAddr{IP: gateway, Port: int(c.serverPort)}
+	connection, err := net.DialUDP("udp", nil, gatewayAddress)
+	if err != nil {
+		return nil, fmt.Errorf("dialing udp: %w", err)
+	}
+	defer connection.Close()
+
+	// Unblock the connection read if the context is canceled.
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			_ = connection.SetDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+	defer func() {
+		close(stop)
+		<-stopped
+	}()
+
+	const maxResponseSize = 16
+	buffer := make([]byte, maxResponseSize)
+	retryDuration := c.initialRetry
+	for i := 0; i < c.maxRetries; i++ {
+		_, err = connection.Write(request)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("writing request: %w", err)
+		}
+
+		err = connection.SetReadDeadline(time.Now().Add(retryDuration))
+		if err != nil {
+			return nil, fmt.Errorf("setting read deadline: %w", err)
+		}
+
+		n, err := connection.Read(buffer)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				retryDuration *= 2
+				continue
+			}
+			return nil, fmt.Errorf("reading response: %w", err)
+		}
+
+		response = buffer[:n]
+		err = checkResponse(request, response, minResponseSize)
+		if err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+
+	return nil, fmt.Errorf("%w: after %d attempts", ErrNoResponse, c.maxRetries)
+}
+
+func checkResponse(request, response []byte, minResponseSize int) (err error) {
+	const headerSize = 4
+	if len(response) < headerSize || len(response) < minResponseSize {
+		return fmt.Errorf("%w: %d bytes", ErrResponseSizeTooSmall, len(response))
+	}
+
+	if response[0] != 0 {
+		return fmt.Errorf("%w: %d", ErrVersionNotSupported, response[0])
+	}
+
+	const responseOperationCodeOffset = 128
+	expectedOperationCode := request[1] + responseOperationCodeOffset
+	if response[1] != expectedOperationCode {
+		return fmt.Errorf("%w: %d instead of %d", ErrOperationCodeUnexpected,
+			response[1], expectedOperationCode)
+	}
+
+	resultCode := binary.BigEndian.Uint16(response[2:4])
+	if resultCode != 0 {
+		return fmt.Errorf("%w: %s", ErrResultCodeNotSuccess, resultCodeToString(resultCode))
+	}
+
+	return nil
+}
+
+func resultCodeToString(resultCode uint16) string {
+	switch resultCode {
+	case 1: //nolint:gomnd
+		return "unsupported version"
+	case 2: //nolint:gomnd
+		return "not authorized or refused"
+	case 3: //nolint:gomnd
+		return "network failure"
+	case 4: //nolint:gomnd
+		return "out of resources"
+	case 5: //nolint:gomnd
+		return "unsupported operation code"
+	default:
+		return fmt.Sprintf("result code %d", resultCode)
+	}
+}
@@ -6,6 +6,7 @@ type (
 	Link      = netlink.Link
 	Bridge    = netlink.Bridge
 	Wireguard = netlink.Wireguard
+	Xfrmi     = netlink.Xfrmi
 )
 
 func (n *NetLink) LinkList() (links []Link, err error) {
@@ -35,3 +36,12 @@ func (n *NetLink) LinkSetUp(link Link) (err error) {
 func (n *NetLink) LinkSetDown(link Link) (err error) {
 	return netlink.LinkSetDown(link)
 }
+
+type LinkUpdate = netlink.LinkUpdate
+
+// LinkSubscribe sends a link update to the channel given each time a
+// network interface is added, changed or removed, until done is closed.
+// The updates channel is closed once the subscription stops.
+func (n *NetLink) LinkSubscribe(updates chan<- LinkUpdate, done <-chan struct{}) (err error) {
+	return netlink.LinkSubscribe(updates, done)
+}
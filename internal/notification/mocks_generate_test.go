@@ -0,0 +1,3 @@
+package notification
+
+//go:generate mockgen -destination=mocks_test.go -package=$GOPACKAGE . Logger
@@ -0,0 +1,93 @@
+// Package notification defines the notifications sent to the user
+// and the bus publishing them to the logs and an optional webhook.
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// Notification is a message for the user.
+type Notification struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	// Important is true if the notification requires the user
+	// to take action, for example for a breaking change.
+	Important bool `json:"important"`
+}
+
+func (n Notification) String() string {
+	if n.Message == "" {
+		return n.Title
+	}
+	return n.Title + ": " + n.Message
+}
+
+// Bus publishes notifications to the logger and, if set,
+// to the webhook URL.
+type Bus struct {
+	webhookURL string
+	client     *http.Client
+	logger     Logger
+}
+
+func New(settings settings.Notification, client *http.Client,
+	logger Logger) *Bus {
+	return &Bus{
+		webhookURL: *settings.WebhookURL,
+		client:     client,
+		logger:     logger,
+	}
+}
+
+// Notify logs the notification given and posts it to the
+// webhook URL if one is set. Webhook errors are logged.
+func (b *Bus) Notify(ctx context.Context, notification Notification) {
+	if notification.Important {
+		b.logger.Warn(notification.String())
+	} else {
+		b.logger.Info(notification.String())
+	}
+
+	if b.webhookURL == "" {
+		return
+	}
+
+	err := b.post(ctx, notification)
+	if err != nil {
+		b.logger.Error("posting notification to webhook: " + err.Error())
+	}
+}
+
+var ErrWebhookFailed = errors.New("webhook failed")
+
+func (b *Bus) post(ctx context.Context, notification Notification) (err error) {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("encoding notification: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		b.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := b.client.Do(request)
+	if err != nil {
+		return err
+	}
+	_ = response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %s", ErrWebhookFailed, response.Status)
+	}
+	return nil
+}
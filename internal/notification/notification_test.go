@@ -0,0 +1,51 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Bus_Notify(t *testing.T) {
+	t.Parallel()
+
+	var received []Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notification Notification
+		err := json.NewDecoder(r.Body).Decode(&notification)
+		if err != nil || notification.Title == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, notification)
+	}))
+	t.Cleanup(server.Close)
+
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	gomock.InOrder(
+		logger.EXPECT().Info("New release: v3.35.0"),
+		logger.EXPECT().Warn("Breaking change: X"),
+		logger.EXPECT().Info("fail"),
+		logger.EXPECT().Error("posting notification to webhook: webhook failed: 400 Bad Request"),
+	)
+	webhookURL := server.URL
+	bus := New(settings.Notification{WebhookURL: &webhookURL},
+		server.Client(), logger)
+
+	ctx := context.Background()
+	bus.Notify(ctx, Notification{Title: "New release", Message: "v3.35.0"})
+	bus.Notify(ctx, Notification{Title: "Breaking change", Message: "X", Important: true})
+	bus.Notify(ctx, Notification{Title: "fail"})
+
+	require.Equal(t, []Notification{
+		{Title: "New release", Message: "v3.35.0"},
+		{Title: "Breaking change", Message: "X", Important: true},
+	}, received)
+}
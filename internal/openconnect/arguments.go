@@ -0,0 +1,46 @@
+package openconnect
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// arguments returns the openconnect arguments to connect to the
+// gateway with the settings given. The password is read from stdin,
+// and DTLS is disabled since only the HTTPS port of the gateway is
+// allowed through the firewall.
+func arguments(settings settings.OpenConnect) (args []string) {
+	host := settings.Server
+	if *settings.Hostname != "" {
+		host = *settings.Hostname
+	}
+
+	args = []string{
+		"--protocol=anyconnect",
+		"--interface=" + settings.Interface,
+		"--script=" + scriptPath,
+		"--non-inter",
+		"--passwd-on-stdin",
+		"--no-dtls",
+		"--user=" + *settings.User,
+	}
+
+	if *settings.AuthGroup != "" {
+		args = append(args, "--authgroup="+*settings.AuthGroup)
+	}
+
+	if *settings.ServerCertificate != "" {
+		args = append(args, "--servercert="+*settings.ServerCertificate)
+	}
+
+	if *settings.Hostname != "" {
+		// Connect to the gateway IP address without resolving its hostname,
+		// since DNS is not reachable before the VPN connection is up.
+		args = append(args, "--resolve="+*settings.Hostname+":"+settings.Server)
+	}
+
+	server := net.JoinHostPort(host, fmt.Sprint(*settings.Port))
+	return append(args, "https://"+server+"/")
+}
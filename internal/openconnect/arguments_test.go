@@ -0,0 +1,76 @@
+package openconnect
+
+import (
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func ptrTo[T any](value T) *T { return &value }
+
+func Test_arguments(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		settings settings.OpenConnect
+		args     []string
+	}{
+		"minimal": {
+			settings: settings.OpenConnect{
+				Server:            "1.2.3.4",
+				Port:              ptrTo(uint16(443)),
+				Hostname:          ptrTo(""),
+				AuthGroup:         ptrTo(""),
+				User:              ptrTo("user"),
+				ServerCertificate: ptrTo(""),
+				Interface:         "tun0",
+			},
+			args: []string{
+				"--protocol=anyconnect",
+				"--interface=tun0",
+				"--script=/etc/openconnect/gluetun.sh",
+				"--non-inter",
+				"--passwd-on-stdin",
+				"--no-dtls",
+				"--user=user",
+				"https://1.2.3.4:443/",
+			},
+		},
+		"all options": {
+			settings: settings.OpenConnect{
+				Server:            "1.2.3.4",
+				Port:              ptrTo(uint16(8443)),
+				Hostname:          ptrTo("vpn.example.com"),
+				AuthGroup:         ptrTo("employees"),
+				User:              ptrTo("user"),
+				ServerCertificate: ptrTo("pin-sha256:abc="),
+				Interface:         "tun1",
+			},
+			args: []string{
+				"--protocol=anyconnect",
+				"--interface=tun1",
+				"--script=/etc/openconnect/gluetun.sh",
+				"--non-inter",
+				"--passwd-on-stdin",
+				"--no-dtls",
+				"--user=user",
+				"--authgroup=employees",
+				"--servercert=pin-sha256:abc=",
+				"--resolve=vpn.example.com:1.2.3.4",
+				"https://vpn.example.com:8443/",
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			args := arguments(testCase.settings)
+
+			assert.Equal(t, testCase.args, args)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package openconnect
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+func streamLines(ctx context.Context, done chan<- struct{},
+	logger Logger, stdout, stderr chan string,
+	tunnelReady chan<- struct{}) {
+	defer close(done)
+
+	var line string
+
+	for {
+		errLine := false
+		select {
+		case <-ctx.Done():
+			// Context should only be canceled after stdout and stderr are done
+			// being written to.
+			close(stdout)
+			close(stderr)
+			return
+		case line = <-stdout:
+		case line = <-stderr:
+			errLine = true
+		}
+
+		line = processLogLine(line)
+		switch {
+		case line == "":
+			continue // filtered out
+		case isTunnelUpLine(line):
+			logger.Info(color.HiGreenString(line))
+			// do not close tunnelReady in case the tunnel
+			// is set up again after a reconnection
+			tunnelReady <- struct{}{}
+		case errLine:
+			logger.Error(line)
+		default:
+			logger.Info(line)
+		}
+	}
+}
+
+func processLogLine(s string) (filtered string) {
+	filtered = strings.TrimSpace(s)
+	if filtered == "Login failed." {
+		filtered += `
+
+Your credentials or authentication group might be wrong 🤨
+
+`
+	}
+	return filtered
+}
+
+// isTunnelUpLine returns true if the openconnect log line given
+// indicates the tunnel is configured, such as
+// "Configured as 10.0.0.2, with SSL connected and DTLS disabled".
+func isTunnelUpLine(line string) bool {
+	return strings.HasPrefix(line, "Configured as ")
+}
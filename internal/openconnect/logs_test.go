@@ -0,0 +1,25 @@
+package openconnect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_isTunnelUpLine(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]bool{
+		"Configured as 10.0.0.2, with SSL connected and DTLS disabled": true,
+		"Connected to HTTPS on 1.2.3.4 with ciphersuite (TLS1.3)":      false,
+		"Got CONNECT response: HTTP/1.1 200 OK":                        false,
+	}
+
+	for line, up := range testCases {
+		line, up := line, up
+		t.Run(line, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, up, isTunnelUpLine(line))
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package openconnect
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/golibs/command"
+)
+
+type Runner struct {
+	settings settings.OpenConnect
+	starter  command.Starter
+	logger   Logger
+}
+
+func NewRunner(settings settings.OpenConnect, starter command.Starter,
+	logger Logger) *Runner {
+	return &Runner{
+		settings: settings,
+		starter:  starter,
+		logger:   logger,
+	}
+}
+
+func (r *Runner) Run(ctx context.Context, errCh chan<- error, ready chan<- struct{}) {
+	err := writeScript()
+	if err != nil {
+		errCh <- fmt.Errorf("writing script: %w", err)
+		return
+	}
+
+	cmd := exec.CommandContext(ctx, "openconnect", arguments(r.settings)...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Stdin = strings.NewReader(*r.settings.Password + "\n")
+	stdoutLines, stderrLines, waitError, err := r.starter.Start(cmd)
+	if err != nil {
+		errCh <- fmt.Errorf("starting openconnect: %w", err)
+		return
+	}
+
+	streamCtx, streamCancel := context.WithCancel(context.Background())
+	streamDone := make(chan struct{})
+	go streamLines(streamCtx, streamDone, r.logger,
+		stdoutLines, stderrLines, ready)
+
+	select {
+	case <-ctx.Done():
+		<-waitError
+		close(waitError)
+		streamCancel()
+		<-streamDone
+		errCh <- ctx.Err()
+	case err := <-waitError:
+		close(waitError)
+		streamCancel()
+		<-streamDone
+		errCh <- err
+	}
+}
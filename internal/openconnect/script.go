@@ -0,0 +1,45 @@
+package openconnect
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const scriptPath = "/etc/openconnect/gluetun.sh"
+
+// script is run by OpenConnect instead of the vpnc-script to set the
+// TUN interface up and route all the traffic through it, leaving the
+// DNS settings to Gluetun. Similarly to the OpenVPN redirect-gateway
+// def1 option, the gateway stays routed through the default route.
+const script = `#!/bin/sh
+set -e
+case "$reason" in
+connect)
+  ip link set dev "$TUNDEV" up mtu "${INTERNAL_IP4_MTU:-1400}"
+  ip addr replace "$INTERNAL_IP4_ADDRESS/32" dev "$TUNDEV"
+  default_route="$(ip route show default | sed -n 's/^default \(via [^ ]* dev [^ ]*\).*/\1/p' | head -n 1)"
+  ip route replace "$VPNGATEWAY/32" $default_route
+  ip route replace 0.0.0.0/1 dev "$TUNDEV"
+  ip route replace 128.0.0.0/1 dev "$TUNDEV"
+  ;;
+disconnect)
+  ip route del "$VPNGATEWAY/32" || true
+  ;;
+esac
+`
+
+func writeScript() (err error) {
+	const dirPermission = 0700
+	err = os.MkdirAll(filepath.Dir(scriptPath), dirPermission)
+	if err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+
+	const permission = 0700
+	err = os.WriteFile(scriptPath, []byte(script), permission)
+	if err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+	return nil
+}
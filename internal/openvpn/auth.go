@@ -12,6 +12,13 @@ func (c *Configurator) WriteAuthFile(user, password string) error {
 	return writeIfDifferent(c.authFilePath, content, c.puid, c.pgid)
 }
 
+// WriteProxyAuthFile writes the OpenVPN HTTP proxy auth file
+// to disk with the right permissions.
+func (c *Configurator) WriteProxyAuthFile(user, password string) error {
+	content := strings.Join([]string{user, password}, "\n")
+	return writeIfDifferent(c.proxyAuthPath, content, c.puid, c.pgid)
+}
+
 // WriteAskPassFile writes the OpenVPN askpass file to disk with the right permissions.
 func (c *Configurator) WriteAskPassFile(passphrase string) error {
 	return writeIfDifferent(c.askPassPath, passphrase, c.puid, c.pgid)
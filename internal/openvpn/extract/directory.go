@@ -0,0 +1,48 @@
+package extract
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNoConfigFile = errors.New("no OpenVPN configuration file found")
+
+// ConfigFiles returns the paths of the OpenVPN configuration files
+// with the .ovpn or .conf extension in the directory given, keyed
+// by their file name without extension.
+func ConfigFiles(directory string) (nameToPath map[string]string, err error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("reading directory: %w", err)
+	}
+
+	nameToPath = make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		extension := filepath.Ext(entry.Name())
+		switch strings.ToLower(extension) {
+		case ".ovpn", ".conf":
+		default:
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), extension)
+		nameToPath[name] = filepath.Join(directory, entry.Name())
+	}
+
+	if len(nameToPath) == 0 {
+		return nil, fmt.Errorf("%w: in directory %s", ErrNoConfigFile, directory)
+	}
+
+	return nameToPath, nil
+}
+
+// IsDirectory returns true if the path given is a directory.
+func IsDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
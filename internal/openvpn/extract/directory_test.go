@@ -0,0 +1,46 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ConfigFiles(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no configuration file", func(t *testing.T) {
+		t.Parallel()
+		directory := t.TempDir()
+		err := os.WriteFile(filepath.Join(directory, "readme.txt"), nil, 0o600)
+		require.NoError(t, err)
+
+		nameToPath, err := ConfigFiles(directory)
+
+		assert.Nil(t, nameToPath)
+		assert.ErrorIs(t, err, ErrNoConfigFile)
+	})
+
+	t.Run("configuration files", func(t *testing.T) {
+		t.Parallel()
+		directory := t.TempDir()
+		for _, name := range []string{"us-east.ovpn", "eu.conf", "notes.txt"} {
+			err := os.WriteFile(filepath.Join(directory, name), nil, 0o600)
+			require.NoError(t, err)
+		}
+		err := os.Mkdir(filepath.Join(directory, "sub.ovpn"), 0o700)
+		require.NoError(t, err)
+
+		nameToPath, err := ConfigFiles(directory)
+
+		require.NoError(t, err)
+		expected := map[string]string{
+			"us-east": filepath.Join(directory, "us-east.ovpn"),
+			"eu":      filepath.Join(directory, "eu.conf"),
+		}
+		assert.Equal(t, expected, nameToPath)
+	})
+}
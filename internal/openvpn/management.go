@@ -0,0 +1,211 @@
+package openvpn
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const managementSocketPath = "/tmp/gluetun/openvpn-management.sock"
+
+// managementFlags returns the OpenVPN flags to expose its
+// management interface on a unix socket only reachable
+// from within the container.
+func managementFlags() []string {
+	return []string{"--management", managementSocketPath, "unix"}
+}
+
+// Management is a client for the OpenVPN management interface,
+// only used to query read-only information about the connection.
+type Management struct {
+	socketPath string
+	dialer     *net.Dialer
+}
+
+// NewManagement creates a client for the management interface
+// of the OpenVPN process started by gluetun.
+func NewManagement() *Management {
+	return &Management{
+		socketPath: managementSocketPath,
+		dialer:     &net.Dialer{},
+	}
+}
+
+// ConnectionInfo is the connection information
+// obtained from the OpenVPN management interface.
+type ConnectionInfo struct {
+	State      string    `json:"state"`
+	StateSince time.Time `json:"state_since"`
+	TunnelIP   string    `json:"tunnel_ip,omitempty"`
+	RemoteIP   string    `json:"remote_ip,omitempty"`
+	RemotePort uint16    `json:"remote_port,omitempty"`
+	BytesIn    uint64    `json:"bytes_in"`
+	BytesOut   uint64    `json:"bytes_out"`
+}
+
+var ErrManagementNotAvailable = errors.New("OpenVPN management interface is not available")
+
+// ConnectionInfo returns the current state, remote address and
+// byte counts of the OpenVPN connection.
+func (m *Management) ConnectionInfo(ctx context.Context) (info ConnectionInfo, err error) {
+	connection, err := m.dialer.DialContext(ctx, "unix", m.socketPath)
+	if err != nil {
+		return info, fmt.Errorf("%w: %s", ErrManagementNotAvailable, err)
+	}
+	defer connection.Close()
+
+	const timeout = 3 * time.Second
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	err = connection.SetDeadline(deadline)
+	if err != nil {
+		return info, fmt.Errorf("setting deadline: %w", err)
+	}
+
+	reader := bufio.NewReader(connection)
+
+	stateLines, err := managementCommand(connection, reader, "state", isEndLine)
+	if err != nil {
+		return info, err
+	}
+	if len(stateLines) == 0 {
+		return info, fmt.Errorf("%w: no state line", ErrManagementResponseMalformed)
+	}
+	// Only the last state line is relevant.
+	err = parseStateLine(stateLines[len(stateLines)-1], &info)
+	if err != nil {
+		return info, err
+	}
+
+	statsLines, err := managementCommand(connection, reader, "load-stats", isStatusLine)
+	if err != nil {
+		return info, err
+	}
+	if len(statsLines) == 0 {
+		return info, fmt.Errorf("%w: no load-stats line", ErrManagementResponseMalformed)
+	}
+	err = parseLoadStatsLine(statsLines[len(statsLines)-1], &info)
+	if err != nil {
+		return info, err
+	}
+
+	return info, nil
+}
+
+var (
+	ErrManagementCommandFailed     = errors.New("OpenVPN management command failed")
+	ErrManagementResponseMalformed = errors.New("OpenVPN management response is malformed")
+)
+
+// managementCommand sends the command given and returns the
+// response lines until and including the line for which isLast
+// returns true, or until the END line which is excluded.
+// Real time notification lines starting with '>' are ignored.
+func managementCommand(connection net.Conn, reader *bufio.Reader,
+	command string, isLast func(line string) bool) (lines []string, err error) {
+	_, err = connection.Write([]byte(command + "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("writing %s command: %w", command, err)
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("reading %s response: %w", command, err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case strings.HasPrefix(line, ">"):
+			continue
+		case strings.HasPrefix(line, "ERROR:"):
+			return nil, fmt.Errorf("%w: %s: %s", ErrManagementCommandFailed,
+				command, strings.TrimSpace(strings.TrimPrefix(line, "ERROR:")))
+		case line == "END":
+			return lines, nil
+		}
+
+		lines = append(lines, line)
+		if isLast(line) {
+			return lines, nil
+		}
+	}
+}
+
+func isEndLine(string) bool { return false }
+
+func isStatusLine(line string) bool {
+	return strings.HasPrefix(line, "SUCCESS:")
+}
+
+// parseStateLine parses a state line in the format
+// time,state,description,tunnel IP,remote IP,remote port,...
+func parseStateLine(line string, info *ConnectionInfo) (err error) {
+	fields := strings.Split(line, ",")
+	const minFields = 2
+	if len(fields) < minFields {
+		return fmt.Errorf("%w: state line: %s", ErrManagementResponseMalformed, line)
+	}
+
+	const base, bitSize = 10, 64
+	unixTime, err := strconv.ParseInt(fields[0], base, bitSize)
+	if err != nil {
+		return fmt.Errorf("%w: state time: %s", ErrManagementResponseMalformed, err)
+	}
+	info.StateSince = time.Unix(unixTime, 0)
+	info.State = fields[1]
+
+	const tunnelIPIndex, remoteIPIndex, remotePortIndex = 3, 4, 5
+	if len(fields) > tunnelIPIndex {
+		info.TunnelIP = fields[tunnelIPIndex]
+	}
+	if len(fields) > remoteIPIndex {
+		info.RemoteIP = fields[remoteIPIndex]
+	}
+	if len(fields) > remotePortIndex && fields[remotePortIndex] != "" {
+		const portBitSize = 16
+		port, err := strconv.ParseUint(fields[remotePortIndex], base, portBitSize)
+		if err != nil {
+			return fmt.Errorf("%w: state remote port: %s", ErrManagementResponseMalformed, err)
+		}
+		info.RemotePort = uint16(port)
+	}
+
+	return nil
+}
+
+// parseLoadStatsLine parses a load-stats line in the format
+// SUCCESS: nclients=0,bytesin=123,bytesout=456
+func parseLoadStatsLine(line string, info *ConnectionInfo) (err error) {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "SUCCESS:"))
+	for _, field := range strings.Split(line, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return fmt.Errorf("%w: load-stats field: %s", ErrManagementResponseMalformed, field)
+		}
+
+		var counter *uint64
+		switch key {
+		case "bytesin":
+			counter = &info.BytesIn
+		case "bytesout":
+			counter = &info.BytesOut
+		default:
+			continue
+		}
+
+		const base, bitSize = 10, 64
+		*counter, err = strconv.ParseUint(value, base, bitSize)
+		if err != nil {
+			return fmt.Errorf("%w: load-stats %s: %s", ErrManagementResponseMalformed, key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package openvpn
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Management_ConnectionInfo(t *testing.T) {
+	t.Parallel()
+
+	socketPath := filepath.Join(t.TempDir(), "management.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	responses := map[string]string{
+		"state": "1700000000,CONNECTING,,,,,,\n" +
+			">BYTECOUNT:10,20\n" +
+			"1700000010,CONNECTED,SUCCESS,10.8.0.2,1.2.3.4,1194,,\n" +
+			"END\n",
+		"load-stats": "SUCCESS: nclients=0,bytesin=1234,bytesout=5678\n",
+	}
+
+	serverDone := make(chan struct{})
+	go func() {
+		defer close(serverDone)
+		connection, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer connection.Close()
+		_, _ = connection.Write([]byte(">INFO:OpenVPN Management Interface Version 3\n"))
+		scanner := bufio.NewScanner(connection)
+		for scanner.Scan() {
+			_, _ = connection.Write([]byte(responses[strings.TrimSpace(scanner.Text())]))
+		}
+	}()
+
+	management := &Management{
+		socketPath: socketPath,
+		dialer:     &net.Dialer{},
+	}
+
+	info, err := management.ConnectionInfo(context.Background())
+
+	require.NoError(t, err)
+	expected := ConnectionInfo{
+		State:      "CONNECTED",
+		StateSince: time.Unix(1700000010, 0),
+		TunnelIP:   "10.8.0.2",
+		RemoteIP:   "1.2.3.4",
+		RemotePort: 1194,
+		BytesIn:    1234,
+		BytesOut:   5678,
+	}
+	assert.Equal(t, expected, info)
+
+	_ = listener.Close()
+	<-serverDone
+}
+
+func Test_Management_ConnectionInfo_notAvailable(t *testing.T) {
+	t.Parallel()
+
+	management := &Management{
+		socketPath: filepath.Join(t.TempDir(), "missing.sock"),
+		dialer:     &net.Dialer{},
+	}
+
+	_, err := management.ConnectionInfo(context.Background())
+
+	assert.ErrorIs(t, err, ErrManagementNotAvailable)
+}
+
+func Test_parseStateLine(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		line       string
+		info       ConnectionInfo
+		errMessage string
+	}{
+		"malformed line": {
+			line:       "CONNECTED",
+			errMessage: "OpenVPN management response is malformed: state line: CONNECTED",
+		},
+		"reconnecting without addresses": {
+			line: "1700000000,RECONNECTING,ping-restart,,,,,",
+			info: ConnectionInfo{
+				State:      "RECONNECTING",
+				StateSince: time.Unix(1700000000, 0),
+			},
+		},
+		"bad remote port": {
+			line: "1700000000,CONNECTED,SUCCESS,10.8.0.2,1.2.3.4,port,,",
+			info: ConnectionInfo{
+				State:      "CONNECTED",
+				StateSince: time.Unix(1700000000, 0),
+				TunnelIP:   "10.8.0.2",
+				RemoteIP:   "1.2.3.4",
+			},
+			errMessage: "OpenVPN management response is malformed: state remote port: " +
+				"strconv.ParseUint: parsing \"port\": invalid syntax",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var info ConnectionInfo
+			err := parseStateLine(testCase.line, &info)
+
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.info, info)
+		})
+	}
+}
@@ -6,23 +6,25 @@ import (
 )
 
 type Configurator struct {
-	logger       Infoer
-	cmder        command.RunStarter
-	configPath   string
-	authFilePath string
-	askPassPath  string
-	puid, pgid   int
+	logger        Infoer
+	cmder         command.RunStarter
+	configPath    string
+	authFilePath  string
+	proxyAuthPath string
+	askPassPath   string
+	puid, pgid    int
 }
 
 func New(logger Infoer, cmder command.RunStarter,
 	puid, pgid int) *Configurator {
 	return &Configurator{
-		logger:       logger,
-		cmder:        cmder,
-		configPath:   configPath,
-		authFilePath: openvpn.AuthConf,
-		askPassPath:  openvpn.AskPassPath,
-		puid:         puid,
-		pgid:         pgid,
+		logger:        logger,
+		cmder:         cmder,
+		configPath:    configPath,
+		authFilePath:  openvpn.AuthConf,
+		proxyAuthPath: openvpn.ProxyAuthConf,
+		askPassPath:   openvpn.AskPassPath,
+		puid:          puid,
+		pgid:          pgid,
 	}
 }
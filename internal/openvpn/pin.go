@@ -0,0 +1,104 @@
+package openvpn
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// pinFlags returns the OpenVPN flags to query the PKCS#11
+// token PIN through the management interface.
+func pinFlags() []string {
+	return []string{"--management-query-passwords"}
+}
+
+// sendPKCS11PIN answers the first PKCS#11 token PIN query of OpenVPN
+// on its management interface at the socket path given, and then
+// disconnects so the management interface is available to other
+// clients. OpenVPN caches the PIN for the lifetime of its process.
+// It returns once the PIN is sent or the context is canceled.
+func sendPKCS11PIN(ctx context.Context, socketPath, pin string, logger Logger) {
+	connection, err := dialWhenAvailable(ctx, socketPath)
+	if err != nil {
+		return
+	}
+	defer connection.Close()
+
+	go func() {
+		<-ctx.Done()
+		_ = connection.SetDeadline(time.Now())
+	}()
+
+	reader := bufio.NewReader(connection)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Warn("reading OpenVPN management interface: " + err.Error())
+			}
+			return
+		}
+
+		name, ok := tokenPINQueryName(strings.TrimRight(line, "\r\n"))
+		if !ok {
+			continue
+		}
+
+		_, err = fmt.Fprintf(connection, "password %s %s\n",
+			quoteManagementArg(name), quoteManagementArg(pin))
+		if err != nil {
+			logger.Warn("sending PKCS#11 PIN: " + err.Error())
+			return
+		}
+		logger.Info("PKCS#11 PIN sent for " + name)
+		return
+	}
+}
+
+// dialWhenAvailable connects to the unix socket at the path given,
+// retrying until OpenVPN creates it or the context is canceled.
+func dialWhenAvailable(ctx context.Context, socketPath string) (
+	connection net.Conn, err error) {
+	dialer := &net.Dialer{}
+	const retryPeriod = 100 * time.Millisecond
+	for {
+		connection, err = dialer.DialContext(ctx, "unix", socketPath)
+		if err == nil {
+			return connection, nil
+		}
+
+		timer := time.NewTimer(retryPeriod)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// tokenPINQueryName returns the name of the password queried if the
+// management interface line given is a PKCS#11 token PIN query, in
+// the format ">PASSWORD:Need 'My label token' password".
+func tokenPINQueryName(line string) (name string, ok bool) {
+	query, ok := strings.CutPrefix(line, ">PASSWORD:Need '")
+	if !ok {
+		return "", false
+	}
+	name, rest, ok := strings.Cut(query, "'")
+	if !ok || rest != " password" || !strings.HasSuffix(name, " token") {
+		return "", false
+	}
+	return name, true
+}
+
+// quoteManagementArg double quotes the management command
+// argument given, escaping backslashes and double quotes.
+func quoteManagementArg(arg string) string {
+	arg = strings.ReplaceAll(arg, `\`, `\\`)
+	arg = strings.ReplaceAll(arg, `"`, `\"`)
+	return `"` + arg + `"`
+}
@@ -0,0 +1,92 @@
+package openvpn
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type infoRecorder struct {
+	Logger
+	infos []string
+}
+
+func (r *infoRecorder) Info(s string) { r.infos = append(r.infos, s) }
+
+func Test_sendPKCS11PIN(t *testing.T) {
+	t.Parallel()
+
+	socketPath := filepath.Join(t.TempDir(), "management.sock")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	logger := &infoRecorder{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sendPKCS11PIN(ctx, socketPath, `12"34`, logger)
+	}()
+
+	// The socket is created after the PIN sender started waiting for it.
+	time.Sleep(10 * time.Millisecond)
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	connection, err := listener.Accept()
+	require.NoError(t, err)
+	defer connection.Close()
+
+	_, err = connection.Write([]byte(">INFO:OpenVPN Management Interface Version 3\n" +
+		">PASSWORD:Need 'Auth' username/password\n" +
+		">PASSWORD:Need 'My \\ card token' password\n"))
+	require.NoError(t, err)
+
+	line, err := bufio.NewReader(connection).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, `password "My \\ card token" "12\"34"`+"\n", line)
+	<-done
+	assert.Equal(t, []string{`PKCS#11 PIN sent for My \ card token`}, logger.infos)
+}
+
+func Test_tokenPINQueryName(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		line string
+		name string
+		ok   bool
+	}{
+		"token PIN query": {
+			line: ">PASSWORD:Need 'My card token' password",
+			name: "My card token",
+			ok:   true,
+		},
+		"private key query": {
+			line: ">PASSWORD:Need 'Private Key' password",
+		},
+		"auth query": {
+			line: ">PASSWORD:Need 'Auth' username/password",
+		},
+		"other line": {
+			line: ">INFO:OpenVPN Management Interface Version 3",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			name, ok := tokenPINQueryName(testCase.line)
+
+			assert.Equal(t, testCase.name, name)
+			assert.Equal(t, testCase.ok, ok)
+		})
+	}
+}
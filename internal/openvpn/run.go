@@ -2,6 +2,7 @@ package openvpn
 
 import (
 	"context"
+	"os"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/golibs/command"
@@ -23,12 +24,31 @@ func NewRunner(settings settings.OpenVPN, starter command.Starter,
 }
 
 func (r *Runner) Run(ctx context.Context, errCh chan<- error, ready chan<- struct{}) {
-	stdoutLines, stderrLines, waitError, err := start(ctx, r.starter, r.settings.Version, r.settings.Flags)
+	// Remove any management socket left over by a previous OpenVPN process.
+	_ = os.Remove(managementSocketPath)
+	flags := append(managementFlags(), r.settings.Flags...)
+	if *r.settings.PKCS11PIN != "" {
+		flags = append(flags, pinFlags()...)
+	}
+	stdoutLines, stderrLines, waitError, err := start(ctx, r.starter, r.settings.Version, flags)
 	if err != nil {
 		errCh <- err
 		return
 	}
 
+	pinCtx, pinCancel := context.WithCancel(context.Background())
+	pinDone := make(chan struct{})
+	go func() {
+		defer close(pinDone)
+		if *r.settings.PKCS11PIN != "" {
+			sendPKCS11PIN(pinCtx, managementSocketPath, *r.settings.PKCS11PIN, r.logger)
+		}
+	}()
+	defer func() {
+		pinCancel()
+		<-pinDone
+	}()
+
 	streamCtx, streamCancel := context.WithCancel(context.Background())
 	streamDone := make(chan struct{})
 	go streamLines(streamCtx, streamDone, r.logger,
@@ -0,0 +1,3 @@
+package tlstunnel
+
+//go:generate mockgen -destination=mocks_test.go -package=$GOPACKAGE . Logger
@@ -0,0 +1,144 @@
+// Package tlstunnel implements a TLS client tunnel, similarly to
+// stunnel in client mode, to wrap the OpenVPN TCP connection in TLS.
+package tlstunnel
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Tunnel relays the TCP connections made to its local address
+// to a TLS server.
+type Tunnel struct {
+	server        string
+	dialer        *tls.Dialer
+	listenAddress string
+	logger        Logger
+}
+
+// New creates a tunnel listening on the loopback address at the
+// local port given, and relaying each connection over TLS to the
+// server address given, in the form ip:port. The TLS certificate
+// of the server is verified against the server name given.
+func New(server, serverName string, skipVerify bool,
+	localPort uint16, logger Logger) *Tunnel {
+	return &Tunnel{
+		server: server,
+		dialer: &tls.Dialer{
+			NetDialer: &net.Dialer{},
+			Config: &tls.Config{
+				ServerName:         serverName,
+				InsecureSkipVerify: skipVerify, //nolint:gosec
+				MinVersion:         tls.VersionTLS12,
+			},
+		},
+		listenAddress: net.JoinHostPort("127.0.0.1", strconv.Itoa(int(localPort))),
+		logger:        logger,
+	}
+}
+
+var ErrServerUnreachable = errors.New("TLS server is unreachable")
+
+// Run relays connections to the TLS server until the context
+// is canceled. It signals it is ready once the TLS server has
+// been reached and it listens for connections.
+func (t *Tunnel) Run(ctx context.Context, waitError chan<- error, ready chan<- struct{}) {
+	listenConfig := net.ListenConfig{}
+	listener, err := listenConfig.Listen(ctx, "tcp4", t.listenAddress)
+	if err != nil {
+		waitError <- fmt.Errorf("listening: %w", err)
+		return
+	}
+
+	const checkTimeout = 10 * time.Second
+	checkCtx, checkCancel := context.WithTimeout(ctx, checkTimeout)
+	connection, err := t.dialer.DialContext(checkCtx, "tcp", t.server)
+	checkCancel()
+	if err != nil {
+		_ = listener.Close()
+		waitError <- fmt.Errorf("%w: %s", ErrServerUnreachable, err)
+		return
+	}
+	_ = connection.Close()
+
+	t.logger.Info("tunneling connections through TLS to " + t.server)
+
+	select {
+	case ready <- struct{}{}:
+	case <-ctx.Done():
+	}
+
+	waitError <- t.serve(ctx, listener)
+}
+
+func (t *Tunnel) serve(ctx context.Context, listener net.Listener) (err error) {
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			_ = listener.Close()
+			return fmt.Errorf("accepting connection: %w", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t.handle(ctx, connection)
+		}()
+	}
+}
+
+func (t *Tunnel) handle(ctx context.Context, client net.Conn) {
+	defer client.Close()
+
+	server, err := t.dialer.DialContext(ctx, "tcp", t.server)
+	if err != nil {
+		t.logger.Error("connecting to TLS server: " + err.Error())
+		return
+	}
+	defer server.Close()
+
+	t.logger.Debug("tunneling connection from " + client.RemoteAddr().String())
+	relay(ctx, client, server)
+}
+
+// relay copies data in both directions until either direction
+// is done or the context is canceled.
+func relay(ctx context.Context, client, server net.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		now := time.Now()
+		_ = client.SetDeadline(now)
+		_ = server.SetDeadline(now)
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(server, client)
+		cancel()
+	}()
+
+	_, _ = io.Copy(client, server)
+	cancel()
+	<-done
+}
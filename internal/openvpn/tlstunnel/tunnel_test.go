@@ -0,0 +1,95 @@
+package tlstunnel
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/tlscert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func freePort(t *testing.T) (port uint16) {
+	t.Helper()
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	port = uint16(listener.Addr().(*net.TCPAddr).Port) //nolint:forcetypeassert
+	require.NoError(t, listener.Close())
+	return port
+}
+
+func Test_Tunnel(t *testing.T) {
+	t.Parallel()
+
+	certificate, _, err := tlscert.LoadOrGenerate("", "", t.TempDir(),
+		[]string{"vpn.example.com"})
+	require.NoError(t, err)
+
+	// TLS echo server
+	server, err := tls.Listen("tcp4", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = server.Close() })
+	go func() {
+		for {
+			connection, err := server.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer connection.Close()
+				_, _ = io.Copy(connection, connection)
+			}()
+		}
+	}()
+
+	t.Run("certificate not trusted", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		tunnel := New(server.Addr().String(), "vpn.example.com", false,
+			freePort(t), NewMockLogger(ctrl))
+
+		waitError := make(chan error)
+		go tunnel.Run(context.Background(), waitError, make(chan struct{}))
+
+		err := <-waitError
+		assert.ErrorIs(t, err, ErrServerUnreachable)
+	})
+
+	t.Run("relay", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctrl := gomock.NewController(t)
+		localPort := freePort(t)
+		logger := NewMockLogger(ctrl)
+		logger.EXPECT().Info("tunneling connections through TLS to " + server.Addr().String())
+		tunnel := New(server.Addr().String(), "vpn.example.com", true,
+			localPort, logger)
+
+		waitError := make(chan error)
+		ready := make(chan struct{})
+		go tunnel.Run(ctx, waitError, ready)
+		<-ready
+
+		client, err := net.Dial("tcp4", tunnel.listenAddress)
+		require.NoError(t, err)
+		logger.EXPECT().Debug("tunneling connection from " + client.LocalAddr().String())
+		_, err = client.Write([]byte("hello"))
+		require.NoError(t, err)
+		response := make([]byte, len("hello"))
+		_, err = io.ReadFull(client, response)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(response))
+		require.NoError(t, client.Close())
+
+		cancel()
+		assert.NoError(t, <-waitError)
+	})
+}
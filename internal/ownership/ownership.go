@@ -0,0 +1,80 @@
+// Package ownership changes the ownership of files written by gluetun.
+package ownership
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Owner is the user and group IDs owning the files written by gluetun.
+// A nil owner leaves the ownership of files unchanged.
+type Owner struct {
+	UID int
+	GID int
+}
+
+// NewOwner returns the owner with the user and group IDs given,
+// or nil if any of them is nil.
+func NewOwner(uid, gid *uint32) *Owner {
+	if uid == nil || gid == nil {
+		return nil
+	}
+	return &Owner{UID: int(*uid), GID: int(*gid)}
+}
+
+// Chown changes the ownership of the file at the path given to the
+// owner, and does nothing if the owner is nil.
+func (o *Owner) Chown(path string) (err error) {
+	if o == nil {
+		return nil
+	}
+	return os.Chown(path, o.UID, o.GID)
+}
+
+// Fix changes the ownership of the root directory given and of all the
+// files and directories it contains owned by the user ID fromUID, which
+// is typically the user ID of gluetun, to the user and group IDs of the
+// owner. Paths owned by other users, such as mounted files, are left
+// unchanged. It returns the number of paths for which the ownership was
+// changed, and returns no error if the root directory does not exist.
+// Symbolic links are changed themselves and not followed.
+func Fix(root string, fromUID int, owner Owner) (changed int, err error) {
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("getting information on %s: %w", path, err)
+		}
+
+		uid, gid, ok := ownerIDs(info)
+		if !ok || uid != fromUID || (uid == owner.UID && gid == owner.GID) {
+			return nil
+		}
+
+		err = os.Lchown(path, owner.UID, owner.GID)
+		if err != nil {
+			return err
+		}
+		changed++
+		return nil
+	})
+	return changed, err
+}
+
+func ownerIDs(info fs.FileInfo) (uid, gid int, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(stat.Uid), int(stat.Gid), true
+}
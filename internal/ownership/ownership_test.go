@@ -0,0 +1,89 @@
+package ownership
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewOwner(t *testing.T) {
+	t.Parallel()
+
+	id := uint32(1000)
+	assert.Nil(t, NewOwner(nil, nil))
+	assert.Nil(t, NewOwner(&id, nil))
+	assert.Equal(t, &Owner{UID: 1000, GID: 1000}, NewOwner(&id, &id))
+
+	var owner *Owner
+	assert.NoError(t, owner.Chown(filepath.Join(t.TempDir(), "does-not-exist")))
+}
+
+func Test_Fix(t *testing.T) {
+	t.Parallel()
+
+	t.Run("root does not exist", func(t *testing.T) {
+		t.Parallel()
+
+		root := filepath.Join(t.TempDir(), "gluetun")
+
+		changed, err := Fix(root, os.Getuid(), Owner{UID: os.Getuid(), GID: os.Getgid()})
+
+		require.NoError(t, err)
+		assert.Zero(t, changed)
+	})
+
+	t.Run("already owned", func(t *testing.T) {
+		t.Parallel()
+
+		root := t.TempDir()
+		err := os.WriteFile(filepath.Join(root, "servers.json"), nil, 0600)
+		require.NoError(t, err)
+		err = os.Mkdir(filepath.Join(root, "subdirectory"), 0700)
+		require.NoError(t, err)
+
+		changed, err := Fix(root, os.Getuid(), Owner{UID: os.Getuid(), GID: os.Getgid()})
+
+		require.NoError(t, err)
+		assert.Zero(t, changed)
+	})
+
+	t.Run("owned by another user", func(t *testing.T) {
+		t.Parallel()
+
+		root := t.TempDir()
+		err := os.WriteFile(filepath.Join(root, "mounted.conf"), nil, 0600)
+		require.NoError(t, err)
+
+		changed, err := Fix(root, os.Getuid()+1, Owner{UID: 1000, GID: 1000})
+
+		require.NoError(t, err)
+		assert.Zero(t, changed)
+	})
+
+	t.Run("change ownership", func(t *testing.T) {
+		t.Parallel()
+
+		if os.Geteuid() != 0 {
+			t.Skip("changing files ownership requires root")
+		}
+
+		root := t.TempDir()
+		path := filepath.Join(root, "servers.json")
+		err := os.WriteFile(path, nil, 0600)
+		require.NoError(t, err)
+
+		changed, err := Fix(root, 0, Owner{UID: 1000, GID: 1000})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, changed)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		uid, gid, ok := ownerIDs(info)
+		assert.True(t, ok)
+		assert.Equal(t, 1000, uid)
+		assert.Equal(t, 1000, gid)
+	})
+}
@@ -0,0 +1,96 @@
+// Package ping sends ICMP echo requests.
+package ping
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+var echoID uint32 //nolint:gochecknoglobals
+
+// Echo sends a single ICMP echo request to the IPv4 address given and
+// returns the round trip time. It uses a raw ICMP socket if permitted, and
+// falls back on an unprivileged datagram ICMP socket otherwise.
+func Echo(ip net.IP, sequence int, timeout time.Duration) (
+	rtt time.Duration, err error) {
+	var destination net.Addr = &net.IPAddr{IP: ip}
+	connection, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		connection, err = icmp.ListenPacket("udp4", "0.0.0.0")
+		if err != nil {
+			return 0, fmt.Errorf("listening for ICMP: %w", err)
+		}
+		destination = &net.UDPAddr{IP: ip}
+	}
+	defer connection.Close()
+
+	const idMask = 0xffff
+	id := int(atomic.AddUint32(&echoID, 1)+uint32(os.Getpid())) & idMask
+	message := icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Body: &icmp.Echo{
+			ID:   id,
+			Seq:  sequence,
+			Data: []byte("gluetun"),
+		},
+	}
+	request, err := message.Marshal(nil)
+	if err != nil {
+		return 0, fmt.Errorf("encoding ICMP echo request: %w", err)
+	}
+
+	start := time.Now()
+	err = connection.SetDeadline(start.Add(timeout))
+	if err != nil {
+		return 0, fmt.Errorf("setting deadline: %w", err)
+	}
+
+	_, err = connection.WriteTo(request, destination)
+	if err != nil {
+		return 0, fmt.Errorf("sending ICMP echo request: %w", err)
+	}
+
+	const maxPacketSize = 1500
+	buffer := make([]byte, maxPacketSize)
+	for {
+		n, peer, err := connection.ReadFrom(buffer)
+		if err != nil {
+			return 0, fmt.Errorf("reading ICMP echo reply: %w", err)
+		}
+
+		const protocolICMP = 1
+		reply, err := icmp.ParseMessage(protocolICMP, buffer[:n])
+		if err != nil || reply.Type != ipv4.ICMPTypeEchoReply {
+			continue
+		}
+
+		// Raw sockets receive all ICMP packets, so check
+		// the reply corresponds to our request.
+		echo, ok := reply.Body.(*icmp.Echo)
+		if !ok || echo.Seq != sequence || !peerIPEqual(peer, ip) {
+			continue
+		}
+		if _, raw := destination.(*net.IPAddr); raw && echo.ID != id {
+			continue
+		}
+
+		return time.Since(start), nil
+	}
+}
+
+func peerIPEqual(peer net.Addr, ip net.IP) bool {
+	switch address := peer.(type) {
+	case *net.IPAddr:
+		return address.IP.Equal(ip)
+	case *net.UDPAddr:
+		return address.IP.Equal(ip)
+	default:
+		return false
+	}
+}
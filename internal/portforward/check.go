@@ -0,0 +1,99 @@
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/notification"
+)
+
+// checkReachability checks the port given is reachable from the
+// internet using the check URL given, at each period, until the
+// context is canceled. It notifies the user when the port becomes
+// unreachable, and when it becomes reachable again.
+func (l *Loop) checkReachability(ctx context.Context, checkURL string,
+	period time.Duration, port uint16, done chan<- struct{}) {
+	defer close(done)
+
+	reachability := Reachability{Port: port}
+	l.state.SetReachability(reachability)
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		reachable, err := checkPort(ctx, l.client, checkURL, port)
+		if ctx.Err() != nil {
+			return
+		}
+
+		previouslyReachable := reachability.Reachable
+		reachability.LastCheck = time.Now()
+		reachability.Error = ""
+		if err != nil {
+			l.logger.Warn("checking forwarded port reachability: " + err.Error())
+			reachability.Error = err.Error()
+		} else {
+			reachability.Reachable = &reachable
+			l.notifyReachabilityChange(ctx, port, previouslyReachable, reachable)
+		}
+		l.state.SetReachability(reachability)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *Loop) notifyReachabilityChange(ctx context.Context, port uint16,
+	previouslyReachable *bool, reachable bool) {
+	portString := strconv.Itoa(int(port))
+	switch {
+	case !reachable && (previouslyReachable == nil || *previouslyReachable):
+		l.notifier.Notify(ctx, notification.Notification{
+			Title: "Forwarded port not reachable",
+			Message: "port " + portString + " is forwarded but cannot be reached " +
+				"from the internet, check a program is listening on it",
+			Important: true,
+		})
+	case reachable && previouslyReachable != nil && !*previouslyReachable:
+		l.notifier.Notify(ctx, notification.Notification{
+			Title:   "Forwarded port reachable again",
+			Message: "port " + portString + " can be reached from the internet",
+		})
+	case reachable && previouslyReachable == nil:
+		l.logger.Info("forwarded port " + portString + " is reachable from the internet")
+	}
+}
+
+// checkPort requests the check URL given with the port given and returns
+// true if the response status code is 2xx. An error is returned if no
+// response could be obtained.
+func checkPort(ctx context.Context, client *http.Client,
+	checkURL string, port uint16) (reachable bool, err error) {
+	url := strings.ReplaceAll(checkURL, "{{PORT}}", strconv.Itoa(int(port)))
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("creating request: %w", err)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return false, err
+	}
+	_, _ = io.Copy(io.Discard, response.Body)
+	err = response.Body.Close()
+	if err != nil {
+		return false, fmt.Errorf("closing response body: %w", err)
+	}
+
+	const minSuccess, maxSuccess = 200, 299
+	return response.StatusCode >= minSuccess && response.StatusCode <= maxSuccess, nil
+}
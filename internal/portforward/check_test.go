@@ -0,0 +1,122 @@
+package portforward
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/notification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkPort(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("port") {
+		case "1000":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	testCases := map[string]struct {
+		port      uint16
+		reachable bool
+	}{
+		"reachable": {
+			port:      1000,
+			reachable: true,
+		},
+		"not reachable": {
+			port: 2000,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			reachable, err := checkPort(context.Background(), server.Client(),
+				server.URL+"/?port={{PORT}}", testCase.port)
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.reachable, reachable)
+		})
+	}
+}
+
+type fakeNotifier struct {
+	notifications []notification.Notification
+}
+
+func (n *fakeNotifier) Notify(_ context.Context, notification notification.Notification) {
+	n.notifications = append(n.notifications, notification)
+}
+
+func Test_Loop_notifyReachabilityChange(t *testing.T) {
+	t.Parallel()
+
+	boolPtr := func(b bool) *bool { return &b }
+
+	testCases := map[string]struct {
+		previouslyReachable *bool
+		reachable           bool
+		titles              []string
+		logMessage          string
+	}{
+		"first check reachable": {
+			reachable:  true,
+			logMessage: "forwarded port 1000 is reachable from the internet",
+		},
+		"first check not reachable": {
+			titles: []string{"Forwarded port not reachable"},
+		},
+		"still reachable": {
+			previouslyReachable: boolPtr(true),
+			reachable:           true,
+		},
+		"went dark": {
+			previouslyReachable: boolPtr(true),
+			titles:              []string{"Forwarded port not reachable"},
+		},
+		"still not reachable": {
+			previouslyReachable: boolPtr(false),
+		},
+		"reachable again": {
+			previouslyReachable: boolPtr(false),
+			reachable:           true,
+			titles:              []string{"Forwarded port reachable again"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			logger := NewMockLogger(ctrl)
+			if testCase.logMessage != "" {
+				logger.EXPECT().Info(testCase.logMessage)
+			}
+			notifier := &fakeNotifier{}
+			loop := &Loop{notifier: notifier, logger: logger}
+
+			loop.notifyReachabilityChange(context.Background(), 1000,
+				testCase.previouslyReachable, testCase.reachable)
+
+			var titles []string
+			for _, notification := range notifier.notifications {
+				titles = append(titles, notification.Title)
+			}
+			assert.Equal(t, testCase.titles, titles)
+		})
+	}
+}
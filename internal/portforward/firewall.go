@@ -2,31 +2,38 @@ package portforward
 
 import "context"
 
-// firewallBlockPort obtains the state port thread safely and blocks
-// it in the firewall if it is not the zero value (0).
-func (l *Loop) firewallBlockPort(ctx context.Context) {
-	port := l.state.GetPortForwarded()
-	if port == 0 {
-		return
+// firewallBlockPorts obtains the state ports thread safely
+// and blocks each of them in the firewall.
+func (l *Loop) firewallBlockPorts(ctx context.Context) {
+	for _, port := range l.state.GetPortsForwarded() {
+		err := l.portAllower.RemoveAllowedPort(ctx, port)
+		if err != nil {
+			l.logger.Error("cannot block previous port in firewall: " + err.Error())
+		}
 	}
 
-	err := l.portAllower.RemoveAllowedPort(ctx, port)
+	err := l.portAllower.SetForwardedPorts(ctx, nil)
 	if err != nil {
-		l.logger.Error("cannot block previous port in firewall: " + err.Error())
+		l.logger.Error("cannot clear forwarded ports in firewall: " + err.Error())
 	}
 }
 
-// firewallAllowPort obtains the state port thread safely and allows
-// it in the firewall if it is not the zero value (0).
-func (l *Loop) firewallAllowPort(ctx context.Context) {
-	port := l.state.GetPortForwarded()
-	if port == 0 {
-		return
+// firewallAllowPorts obtains the state ports thread safely
+// and allows each of them in the firewall. The ports are also set
+// as the forwarded ports of the firewall rules template and port
+// forward target rules.
+func (l *Loop) firewallAllowPorts(ctx context.Context) {
+	startData := l.state.GetStartData()
+	ports := l.state.GetPortsForwarded()
+	for _, port := range ports {
+		err := l.portAllower.SetAllowedPort(ctx, port, startData.Interface)
+		if err != nil {
+			l.logger.Error("cannot allow port: " + err.Error())
+		}
 	}
 
-	startData := l.state.GetStartData()
-	err := l.portAllower.SetAllowedPort(ctx, port, startData.Interface)
+	err := l.portAllower.SetForwardedPorts(ctx, ports)
 	if err != nil {
-		l.logger.Error("cannot allow port: " + err.Error())
+		l.logger.Error("cannot set forwarded ports in firewall: " + err.Error())
 	}
 }
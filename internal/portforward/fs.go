@@ -3,6 +3,7 @@ package portforward
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 func (l *Loop) removePortForwardedFile() {
@@ -13,17 +14,25 @@ func (l *Loop) removePortForwardedFile() {
 	}
 }
 
-func (l *Loop) writePortForwardedFile(port uint16) {
+func (l *Loop) writePortForwardedFile(ports []uint16) {
 	filepath := *l.state.GetSettings().Filepath
 	l.logger.Info("writing port file " + filepath)
-	if err := writePortForwardedToFile(filepath, port, l.puid, l.pgid); err != nil {
-		l.logger.Error("writing port forwarded to file: " + err.Error())
+	if err := writePortsForwardedToFile(filepath, ports, l.puid, l.pgid); err != nil {
+		l.logger.Error("writing ports forwarded to file: " + err.Error())
 	}
 }
 
-func writePortForwardedToFile(filepath string, port uint16, uid, gid int) (err error) {
+// writePortsForwardedToFile writes the ports given to the file
+// at the path given, one port per line.
+func writePortsForwardedToFile(filepath string, ports []uint16, uid, gid int) (err error) {
+	portStrings := make([]string, len(ports))
+	for i, port := range ports {
+		portStrings[i] = fmt.Sprint(port)
+	}
+	data := []byte(strings.Join(portStrings, "\n"))
+
 	const perms = os.FileMode(0644)
-	err = os.WriteFile(filepath, []byte(fmt.Sprint(port)), perms)
+	err = os.WriteFile(filepath, data, perms)
 	if err != nil {
 		return fmt.Errorf("writing file: %w", err)
 	}
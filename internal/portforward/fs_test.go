@@ -0,0 +1,72 @@
+package portforward
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_writePortsForwardedToFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		ports   []uint16
+		content string
+	}{
+		"no port": {},
+		"single port": {
+			ports:   []uint16{1000},
+			content: "1000",
+		},
+		"multiple ports": {
+			ports:   []uint16{1000, 2000, 65535},
+			content: "1000\n2000\n65535",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "forwarded_port")
+
+			err := writePortsForwardedToFile(path, testCase.ports,
+				os.Getuid(), os.Getgid())
+
+			require.NoError(t, err)
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.content, string(data))
+		})
+	}
+}
+
+func Test_writePortsForwardedToFile_overwrites(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "forwarded_port")
+	uid, gid := os.Getuid(), os.Getgid()
+
+	err := writePortsForwardedToFile(path, []uint16{1000, 2000}, uid, gid)
+	require.NoError(t, err)
+	err = writePortsForwardedToFile(path, []uint16{3000}, uid, gid)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "3000", string(data))
+}
+
+func Test_writePortsForwardedToFile_error(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "missing", "forwarded_port")
+
+	err := writePortsForwardedToFile(path, []uint16{1000}, os.Getuid(), os.Getgid())
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -1,5 +1,29 @@
 package portforward
 
+import "github.com/qdm12/gluetun/internal/portforward/state"
+
 func (l *Loop) GetPortForwarded() (port uint16) {
 	return l.state.GetPortForwarded()
 }
+
+func (l *Loop) GetPortsForwarded() (ports []uint16) {
+	return l.state.GetPortsForwarded()
+}
+
+type Reachability = state.Reachability
+
+type TorrentClientStatus = state.TorrentClientStatus
+
+func (l *Loop) GetTorrentClientsStatus() (statuses []TorrentClientStatus) {
+	return l.state.GetTorrentClientsStatus()
+}
+
+type HistoryEvent = state.HistoryEvent
+
+func (l *Loop) GetHistory() (events []HistoryEvent) {
+	return l.state.GetHistory()
+}
+
+func (l *Loop) GetReachability() (reachability Reachability) {
+	return l.state.GetReachability()
+}
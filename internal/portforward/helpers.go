@@ -2,6 +2,9 @@ package portforward
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,3 +23,51 @@ func (l *Loop) logAndWait(ctx context.Context, err error) {
 		}
 	}
 }
+
+func portsToString(ports []uint16) string {
+	if len(ports) == 0 {
+		return "none"
+	}
+	portStrings := make([]string, len(ports))
+	for i, port := range ports {
+		portStrings[i] = strconv.Itoa(int(port))
+	}
+	return strings.Join(portStrings, ", ")
+}
+
+func portsEqual(a, b []uint16) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// keepPreviousPortFirst moves the port forwarded on the previous run
+// first in the ports given if it is forwarded again, so the port given
+// to torrent clients stays the same across restarts where providers
+// keep it. It only applies to the first ports forwarded after startup.
+func (l *Loop) keepPreviousPortFirst(ports []uint16) []uint16 {
+	previous := l.previousPort
+	l.previousPort = 0
+	if previous == 0 {
+		return ports
+	}
+
+	for i, port := range ports {
+		if port != previous {
+			continue
+		}
+		l.logger.Info(fmt.Sprintf("keeping previous forwarded port %d", previous))
+		copy(ports[1:i+1], ports[:i])
+		ports[0] = previous
+		return ports
+	}
+
+	l.logger.Info(fmt.Sprintf("previous forwarded port %d is not forwarded anymore", previous))
+	return ports
+}
@@ -0,0 +1,64 @@
+package portforward
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Loop_keepPreviousPortFirst(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		previousPort uint16
+		ports        []uint16
+		expected     []uint16
+		logMessage   string
+	}{
+		"no previous port": {
+			ports:    []uint16{1000, 2000},
+			expected: []uint16{1000, 2000},
+		},
+		"previous port already first": {
+			previousPort: 1000,
+			ports:        []uint16{1000, 2000},
+			expected:     []uint16{1000, 2000},
+			logMessage:   "keeping previous forwarded port 1000",
+		},
+		"previous port moved first": {
+			previousPort: 3000,
+			ports:        []uint16{1000, 2000, 3000},
+			expected:     []uint16{3000, 1000, 2000},
+			logMessage:   "keeping previous forwarded port 3000",
+		},
+		"previous port not forwarded anymore": {
+			previousPort: 4000,
+			ports:        []uint16{1000, 2000},
+			expected:     []uint16{1000, 2000},
+			logMessage:   "previous forwarded port 4000 is not forwarded anymore",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			logger := NewMockLogger(ctrl)
+			if testCase.logMessage != "" {
+				logger.EXPECT().Info(testCase.logMessage)
+			}
+			loop := &Loop{
+				previousPort: testCase.previousPort,
+				logger:       logger,
+			}
+
+			ports := loop.keepPreviousPortFirst(testCase.ports)
+
+			assert.Equal(t, testCase.expected, ports)
+			assert.Zero(t, loop.previousPort)
+		})
+	}
+}
@@ -2,9 +2,16 @@ package portforward
 
 import (
 	"context"
+
+	"github.com/qdm12/gluetun/internal/notification"
 )
 
 type PortAllower interface {
 	SetAllowedPort(ctx context.Context, port uint16, intf string) (err error)
 	RemoveAllowedPort(ctx context.Context, port uint16) (err error)
+	SetForwardedPorts(ctx context.Context, ports []uint16) (err error)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, notification notification.Notification)
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/qdm12/gluetun/internal/loopstate"
 	"github.com/qdm12/gluetun/internal/models"
 	"github.com/qdm12/gluetun/internal/portforward/state"
+	"github.com/qdm12/gluetun/internal/provider/utils"
 )
 
 type Loop struct {
@@ -21,7 +22,11 @@ type Loop struct {
 	// Objects
 	client      *http.Client
 	portAllower PortAllower
+	notifier    Notifier
 	logger      Logger
+	// Runtime state restored, see RestoreRuntimeState.
+	previousPort uint16
+	tokens       utils.TokenStore
 	// Internal channels and locks
 	start       chan struct{}
 	running     chan models.LoopStatus
@@ -35,7 +40,7 @@ type Loop struct {
 const defaultBackoffTime = 5 * time.Second
 
 func NewLoop(settings settings.PortForwarding,
-	client *http.Client, portAllower PortAllower,
+	client *http.Client, portAllower PortAllower, notifier Notifier,
 	logger Logger, puid, pgid int) *Loop {
 	start := make(chan struct{})
 	running := make(chan models.LoopStatus)
@@ -53,6 +58,7 @@ func NewLoop(settings settings.PortForwarding,
 		// Objects
 		client:      client,
 		portAllower: portAllower,
+		notifier:    notifier,
 		logger:      logger,
 		start:       start,
 		running:     running,
@@ -62,3 +68,12 @@ func NewLoop(settings settings.PortForwarding,
 		backoffTime: defaultBackoffTime,
 	}
 }
+
+// RestoreRuntimeState sets the port forwarded on the previous run,
+// kept first among the ports forwarded if it is forwarded again,
+// and the token store persisting the provider authentication tokens
+// across restarts. It must be called before the loop is started.
+func (l *Loop) RestoreRuntimeState(previousPort uint16, tokens utils.TokenStore) {
+	l.previousPort = previousPort
+	l.tokens = tokens
+}
@@ -2,9 +2,10 @@ package portforward
 
 import (
 	"context"
-	"strconv"
 
 	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/portforward/state"
+	"github.com/qdm12/gluetun/internal/provider/utils"
 )
 
 func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
@@ -19,23 +20,33 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 	for ctx.Err() == nil {
 		pfCtx, pfCancel := context.WithCancel(ctx)
 
-		portCh := make(chan uint16)
+		portsCh := make(chan []uint16)
 		errorCh := make(chan error)
 
 		startData := l.state.GetStartData()
 
 		go func(ctx context.Context, startData StartData) {
-			port, err := startData.PortForwarder.PortForward(ctx, l.client, l.logger,
-				startData.Gateway, startData.ServerName)
+			objects := utils.PortForwardObjects{
+				Logger:     l.logger,
+				Client:     l.client,
+				Gateway:    startData.Gateway,
+				ServerName: startData.ServerName,
+				APIKey:     *l.state.GetSettings().APIKey,
+				OnRenewal: func() {
+					l.state.AddHistoryEvent(state.HistoryEventRenewed,
+						l.state.GetPortsForwarded(), nil)
+				},
+				Tokens: l.tokens,
+			}
+			ports, err := startData.PortForwarder.PortForward(ctx, objects)
 			if err != nil {
 				errorCh <- err
 				return
 			}
-			portCh <- port
+			portsCh <- ports
 
 			// Infinite loop
-			err = startData.PortForwarder.KeepPortForward(ctx,
-				startData.Gateway, startData.ServerName)
+			err = startData.PortForwarder.KeepPortForward(ctx, objects)
 			errorCh <- err
 		}(pfCtx, startData)
 
@@ -47,47 +58,93 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 			l.statusManager.SetStatus(constants.Running)
 		}
 
+		// checkDone is set when the reachability check of the
+		// forwarded port is running, and closed once it exits.
+		var checkDone chan struct{}
+		// torrentDone is set when the torrent clients listening ports
+		// are being set, and closed once they are all set or given up on.
+		var torrentDone chan struct{}
+		waitBackground := func() {
+			if checkDone != nil {
+				<-checkDone
+				checkDone = nil
+			}
+			l.state.SetReachability(Reachability{})
+			if torrentDone != nil {
+				<-torrentDone
+				torrentDone = nil
+			}
+			l.state.ClearTorrentClientsStatus()
+		}
+
 		stayHere := true
 		stopped := false
 		for stayHere {
 			select {
 			case <-ctx.Done():
 				pfCancel()
+				waitBackground()
 				if stopped {
 					return
 				}
 				<-errorCh
 				close(errorCh)
-				close(portCh)
+				close(portsCh)
 				l.removePortForwardedFile()
-				l.firewallBlockPort(ctx)
-				l.state.SetPortForwarded(0)
+				l.firewallBlockPorts(ctx)
+				l.state.SetPortsForwarded(nil)
 				return
 			case <-l.start:
 				l.userTrigger = true
 				l.logger.Info("starting")
 				pfCancel()
+				waitBackground()
 				stayHere = false
 			case <-l.stop:
 				l.userTrigger = true
 				l.logger.Info("stopping")
 				pfCancel()
+				waitBackground()
 				<-errorCh
 				l.removePortForwardedFile()
-				l.firewallBlockPort(ctx)
-				l.state.SetPortForwarded(0)
+				l.firewallBlockPorts(ctx)
+				if ports := l.state.GetPortsForwarded(); len(ports) > 0 {
+					l.state.AddHistoryEvent(state.HistoryEventReleased, ports, nil)
+					l.postWebhook(ctx, nil)
+				}
+				l.state.SetPortsForwarded(nil)
 				l.stopped <- struct{}{}
 				stopped = true
-			case port := <-portCh:
-				l.logger.Info("port forwarded is " + strconv.Itoa(int(port)))
-				l.firewallBlockPort(ctx)
-				l.state.SetPortForwarded(port)
-				l.firewallAllowPort(ctx)
-				l.writePortForwardedFile(port)
+			case ports := <-portsCh:
+				ports = l.keepPreviousPortFirst(ports)
+				l.logger.Info("ports forwarded are " + portsToString(ports))
+				previousPorts := l.state.GetPortsForwarded()
+				l.firewallBlockPorts(ctx)
+				l.state.SetPortsForwarded(ports)
+				l.state.AddHistoryEvent(state.HistoryEventAcquired, ports, nil)
+				l.firewallAllowPorts(ctx)
+				l.writePortForwardedFile(ports)
+				if !portsEqual(previousPorts, ports) {
+					l.postWebhook(pfCtx, ports)
+				}
+				if len(ports) > 0 {
+					torrentDone = make(chan struct{})
+					go l.updateTorrentClients(pfCtx, ports[0], torrentDone)
+				}
+				settings := l.state.GetSettings()
+				// Only the first port forwarded is checked for reachability.
+				if *settings.CheckURL != "" && len(ports) > 0 {
+					checkDone = make(chan struct{})
+					go l.checkReachability(pfCtx, *settings.CheckURL,
+						*settings.CheckPeriod, ports[0], checkDone)
+				}
 			case err := <-errorCh:
 				pfCancel()
+				waitBackground()
 				close(errorCh)
-				close(portCh)
+				close(portsCh)
+				l.state.AddHistoryEvent(state.HistoryEventFailed,
+					l.state.GetPortsForwarded(), err)
 				l.statusManager.SetStatus(constants.Crashed)
 				l.logAndWait(ctx, err)
 				stayHere = false
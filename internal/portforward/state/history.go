@@ -0,0 +1,66 @@
+package state
+
+import "time"
+
+// HistoryEventType is the type of a port forwarding history event.
+type HistoryEventType string
+
+const (
+	// HistoryEventAcquired is for ports newly forwarded.
+	HistoryEventAcquired HistoryEventType = "acquired"
+	// HistoryEventRenewed is for ports forwarded renewed
+	// with the VPN server or gateway.
+	HistoryEventRenewed HistoryEventType = "renewed"
+	// HistoryEventFailed is for port forwarding failures.
+	HistoryEventFailed HistoryEventType = "failed"
+	// HistoryEventReleased is for ports no longer forwarded
+	// since port forwarding got stopped.
+	HistoryEventReleased HistoryEventType = "released"
+)
+
+// HistoryEvent is an event of the port forwarding history.
+type HistoryEvent struct {
+	Time  time.Time        `json:"time"`
+	Type  HistoryEventType `json:"type"`
+	Ports []uint16         `json:"ports,omitempty"`
+	Error string           `json:"error,omitempty"`
+}
+
+// maxHistoryEvents is the maximum number of events kept
+// in the history, older events being dropped first.
+const maxHistoryEvents = 50
+
+// GetHistory is used by the control HTTP server to obtain
+// the port forwarding history events, oldest first.
+func (s *State) GetHistory() (events []HistoryEvent) {
+	s.historyMu.RLock()
+	defer s.historyMu.RUnlock()
+	events = make([]HistoryEvent, len(s.history))
+	copy(events, s.history)
+	return events
+}
+
+// AddHistoryEvent is only used from within the port forwarding
+// loop to add an event to the history, with its time set to now.
+func (s *State) AddHistoryEvent(eventType HistoryEventType,
+	ports []uint16, err error) {
+	event := HistoryEvent{
+		Time: time.Now(),
+		Type: eventType,
+	}
+	if len(ports) > 0 {
+		event.Ports = make([]uint16, len(ports))
+		copy(event.Ports, ports)
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+	if len(s.history) == maxHistoryEvents {
+		copy(s.history, s.history[1:])
+		s.history = s.history[:maxHistoryEvents-1]
+	}
+	s.history = append(s.history, event)
+}
@@ -1,17 +1,32 @@
 package state
 
 // GetPortForwarded is used by the control HTTP server
-// to obtain the port currently forwarded.
+// to obtain the first port currently forwarded.
 func (s *State) GetPortForwarded() (port uint16) {
-	s.portForwardedMu.RLock()
-	defer s.portForwardedMu.RUnlock()
-	return s.portForwarded
+	s.portsForwardedMu.RLock()
+	defer s.portsForwardedMu.RUnlock()
+	if len(s.portsForwarded) == 0 {
+		return 0
+	}
+	return s.portsForwarded[0]
 }
 
-// SetPortForwarded is only used from within the OpenVPN loop
-// to set the port forwarded.
-func (s *State) SetPortForwarded(port uint16) {
-	s.portForwardedMu.Lock()
-	defer s.portForwardedMu.Unlock()
-	s.portForwarded = port
+// GetPortsForwarded is used by the control HTTP server
+// to obtain the ports currently forwarded. The slice returned
+// is never nil, so it is encoded as an empty JSON array.
+func (s *State) GetPortsForwarded() (ports []uint16) {
+	s.portsForwardedMu.RLock()
+	defer s.portsForwardedMu.RUnlock()
+	ports = make([]uint16, len(s.portsForwarded))
+	copy(ports, s.portsForwarded)
+	return ports
+}
+
+// SetPortsForwarded is only used from within the OpenVPN loop
+// to set the ports forwarded.
+func (s *State) SetPortsForwarded(ports []uint16) {
+	s.portsForwardedMu.Lock()
+	defer s.portsForwardedMu.Unlock()
+	s.portsForwarded = make([]uint16, len(ports))
+	copy(s.portsForwarded, ports)
 }
@@ -0,0 +1,66 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_State_PortsForwarded(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		ports         []uint16
+		expectedPort  uint16
+		expectedPorts []uint16
+	}{
+		"nil ports": {
+			expectedPorts: []uint16{},
+		},
+		"empty ports": {
+			ports:         []uint16{},
+			expectedPorts: []uint16{},
+		},
+		"single port": {
+			ports:         []uint16{1000},
+			expectedPort:  1000,
+			expectedPorts: []uint16{1000},
+		},
+		"multiple ports": {
+			ports:         []uint16{1000, 2000},
+			expectedPort:  1000,
+			expectedPorts: []uint16{1000, 2000},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			state := New(nil, settings.PortForwarding{})
+			state.SetPortsForwarded(testCase.ports)
+
+			assert.Equal(t, testCase.expectedPort, state.GetPortForwarded())
+			assert.Equal(t, testCase.expectedPorts, state.GetPortsForwarded())
+		})
+	}
+}
+
+func Test_State_PortsForwarded_copies(t *testing.T) {
+	t.Parallel()
+
+	state := New(nil, settings.PortForwarding{})
+
+	assert.Equal(t, []uint16{}, state.GetPortsForwarded())
+
+	ports := []uint16{1000, 2000}
+	state.SetPortsForwarded(ports)
+	ports[0] = 3000
+	assert.Equal(t, []uint16{1000, 2000}, state.GetPortsForwarded())
+
+	gotPorts := state.GetPortsForwarded()
+	gotPorts[0] = 3000
+	assert.Equal(t, []uint16{1000, 2000}, state.GetPortsForwarded())
+}
@@ -0,0 +1,30 @@
+package state
+
+import "time"
+
+// Reachability is the result of the last reachability check
+// of the forwarded port.
+type Reachability struct {
+	Port uint16 `json:"port"`
+	// Reachable is nil if the port was not checked yet or
+	// if the last check failed to get an answer.
+	Reachable *bool     `json:"reachable"`
+	LastCheck time.Time `json:"last_check"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// GetReachability is used by the control HTTP server to
+// obtain the reachability of the port currently forwarded.
+func (s *State) GetReachability() (reachability Reachability) {
+	s.reachabilityMu.RLock()
+	defer s.reachabilityMu.RUnlock()
+	return s.reachability
+}
+
+// SetReachability is only used from within the port forwarding
+// loop to set the reachability of the port forwarded.
+func (s *State) SetReachability(reachability Reachability) {
+	s.reachabilityMu.Lock()
+	defer s.reachabilityMu.Unlock()
+	s.reachability = reachability
+}
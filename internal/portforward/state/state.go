@@ -22,8 +22,17 @@ type State struct {
 	settings   settings.PortForwarding
 	settingsMu sync.RWMutex
 
-	portForwarded   uint16
-	portForwardedMu sync.RWMutex
+	portsForwarded   []uint16
+	portsForwardedMu sync.RWMutex
+
+	reachability   Reachability
+	reachabilityMu sync.RWMutex
+
+	torrentClients   []TorrentClientStatus
+	torrentClientsMu sync.RWMutex
+
+	history   []HistoryEvent
+	historyMu sync.RWMutex
 
 	startData   StartData
 	startDataMu sync.RWMutex
@@ -0,0 +1,49 @@
+package state
+
+import "time"
+
+// TorrentClientStatus is the status of the last attempt
+// to set the listening port of a torrent client.
+type TorrentClientStatus struct {
+	Name     string `json:"name"`
+	Port     uint16 `json:"port"`
+	Updated  bool   `json:"updated"`
+	Attempts int    `json:"attempts"`
+	// LastAttempt is the time of the last attempt
+	// to set the listening port.
+	LastAttempt time.Time `json:"last_attempt"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// GetTorrentClientsStatus is used by the control HTTP server to
+// obtain the status of each torrent client configured.
+func (s *State) GetTorrentClientsStatus() (statuses []TorrentClientStatus) {
+	s.torrentClientsMu.RLock()
+	defer s.torrentClientsMu.RUnlock()
+	statuses = make([]TorrentClientStatus, len(s.torrentClients))
+	copy(statuses, s.torrentClients)
+	return statuses
+}
+
+// SetTorrentClientStatus is only used from within the port forwarding
+// loop to set the status of the torrent client with the same name as
+// the status given.
+func (s *State) SetTorrentClientStatus(status TorrentClientStatus) {
+	s.torrentClientsMu.Lock()
+	defer s.torrentClientsMu.Unlock()
+	for i, existing := range s.torrentClients {
+		if existing.Name == status.Name {
+			s.torrentClients[i] = status
+			return
+		}
+	}
+	s.torrentClients = append(s.torrentClients, status)
+}
+
+// ClearTorrentClientsStatus is only used from within the port
+// forwarding loop to clear the torrent clients status.
+func (s *State) ClearTorrentClientsStatus() {
+	s.torrentClientsMu.Lock()
+	defer s.torrentClientsMu.Unlock()
+	s.torrentClients = nil
+}
@@ -0,0 +1,101 @@
+package portforward
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/torrent"
+)
+
+type torrentClient interface {
+	SetListenPort(ctx context.Context, port uint16) (err error)
+}
+
+// updateTorrentClients sets the listening port of each torrent client
+// configured to the port given, retrying with an exponential backoff
+// on failure, until the context is canceled. It closes the done
+// channel once all the torrent clients are updated or given up on.
+func (l *Loop) updateTorrentClients(ctx context.Context, port uint16,
+	done chan<- struct{}) {
+	defer close(done)
+
+	settings := l.state.GetSettings()
+	nameToClient := make(map[string]torrentClient, 3) //nolint:gomnd
+	if *settings.QBittorrent.URL != "" {
+		nameToClient["qBittorrent"] = torrent.NewQBittorrent(l.client,
+			*settings.QBittorrent.URL, *settings.QBittorrent.Username,
+			*settings.QBittorrent.Password)
+	}
+	if *settings.Transmission.URL != "" {
+		nameToClient["Transmission"] = torrent.NewTransmission(l.client,
+			*settings.Transmission.URL, *settings.Transmission.Username,
+			*settings.Transmission.Password)
+	}
+	if *settings.Deluge.URL != "" {
+		nameToClient["Deluge"] = torrent.NewDeluge(l.client,
+			*settings.Deluge.URL, *settings.Deluge.Password)
+	}
+
+	wg := new(sync.WaitGroup)
+	for name, client := range nameToClient {
+		wg.Add(1)
+		go func(name string, client torrentClient) {
+			defer wg.Done()
+			l.updateTorrentClient(ctx, name, client, port)
+		}(name, client)
+	}
+	wg.Wait()
+}
+
+func (l *Loop) updateTorrentClient(ctx context.Context, name string,
+	client torrentClient, port uint16) {
+	const (
+		maxAttempts    = 5
+		initialBackoff = 5 * time.Second
+		attemptTimeout = 10 * time.Second
+	)
+
+	status := TorrentClientStatus{Name: name, Port: port}
+	backoff := initialBackoff
+	for {
+		attemptCtx, cancel := context.WithTimeout(ctx, attemptTimeout)
+		err := client.SetListenPort(attemptCtx, port)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		status.Attempts++
+		status.LastAttempt = time.Now()
+		if err == nil {
+			status.Updated = true
+			status.Error = ""
+			l.state.SetTorrentClientStatus(status)
+			l.logger.Info(name + " listening port set to " + strconv.Itoa(int(port)))
+			return
+		}
+
+		status.Error = err.Error()
+		l.state.SetTorrentClientStatus(status)
+		if status.Attempts == maxAttempts {
+			l.logger.Error("setting " + name + " listening port: " + err.Error() +
+				" (giving up after " + strconv.Itoa(maxAttempts) + " attempts)")
+			return
+		}
+		l.logger.Warn("setting " + name + " listening port: " + err.Error() +
+			" (retrying in " + backoff.String() + ")")
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			if !timer.Stop() {
+				<-timer.C
+			}
+			return
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
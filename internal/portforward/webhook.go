@@ -0,0 +1,85 @@
+package portforward
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+)
+
+type webhookData struct {
+	Port  uint16   `json:"port"`
+	Ports []uint16 `json:"ports"`
+}
+
+// postWebhook posts the ports given to the webhook URL if one
+// is set, with the body being the webhook template executed
+// with the ports, or the ports JSON encoded if no template
+// is set. Errors are logged.
+func (l *Loop) postWebhook(ctx context.Context, ports []uint16) {
+	settings := l.state.GetSettings()
+	if *settings.WebhookURL == "" {
+		return
+	}
+
+	const timeout = 10 * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := postWebhook(ctx, l.client, *settings.WebhookURL,
+		*settings.WebhookTemplate, ports)
+	if err != nil {
+		l.logger.Error("posting ports forwarded to webhook: " + err.Error())
+	}
+}
+
+var ErrWebhookFailed = errors.New("webhook failed")
+
+func postWebhook(ctx context.Context, client *http.Client,
+	url, bodyTemplate string, ports []uint16) (err error) {
+	data := webhookData{Ports: ports}
+	if data.Ports == nil {
+		data.Ports = []uint16{}
+	}
+	if len(ports) > 0 {
+		data.Port = ports[0]
+	}
+
+	body := bytes.NewBuffer(nil)
+	if bodyTemplate == "" {
+		err = json.NewEncoder(body).Encode(data)
+		if err != nil {
+			return fmt.Errorf("encoding body: %w", err)
+		}
+	} else {
+		tmpl, err := template.New("webhook").Parse(bodyTemplate)
+		if err != nil {
+			return fmt.Errorf("parsing body template: %w", err)
+		}
+		err = tmpl.Execute(body, data)
+		if err != nil {
+			return fmt.Errorf("executing body template: %w", err)
+		}
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	_ = response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %s", ErrWebhookFailed, response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package portforward
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_postWebhook(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		template   string
+		ports      []uint16
+		statusCode int
+		body       string
+		errWrap    error
+		errMessage string
+	}{
+		"default body": {
+			ports:      []uint16{1000, 2000},
+			statusCode: http.StatusOK,
+			body:       `{"port":1000,"ports":[1000,2000]}` + "\n",
+		},
+		"default body without ports": {
+			statusCode: http.StatusNoContent,
+			body:       `{"port":0,"ports":[]}` + "\n",
+		},
+		"template body": {
+			template:   `{"content":"port {{.Port}} of{{range .Ports}} {{.}}{{end}}"}`,
+			ports:      []uint16{1000, 2000},
+			statusCode: http.StatusOK,
+			body:       `{"content":"port 1000 of 1000 2000"}`,
+		},
+		"template execution error": {
+			template: `{{.Missing}}`,
+			ports:    []uint16{1000},
+			errMessage: "executing body template: template: webhook:1:2: " +
+				"executing \"webhook\" at <.Missing>: can't evaluate field " +
+				"Missing in type portforward.webhookData",
+		},
+		"status code not OK": {
+			ports:      []uint16{1000},
+			statusCode: http.StatusInternalServerError,
+			body:       `{"port":1000,"ports":[1000]}` + "\n",
+			errWrap:    ErrWebhookFailed,
+			errMessage: "webhook failed: 500 Internal Server Error",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, testCase.body, string(body))
+				w.WriteHeader(testCase.statusCode)
+			}))
+			t.Cleanup(server.Close)
+
+			err := postWebhook(context.Background(), server.Client(),
+				server.URL, testCase.template, testCase.ports)
+
+			if testCase.errWrap != nil {
+				assert.ErrorIs(t, err, testCase.errWrap)
+			}
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
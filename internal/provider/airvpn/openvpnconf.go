@@ -10,7 +10,7 @@ import (
 )
 
 func (p *Provider) OpenVPNConfig(connection models.Connection,
-	settings settings.OpenVPN, ipv6Supported bool) (lines []string) {
+	settings settings.OpenVPN, ipv6Supported bool) (lines []string, err error) {
 	providerSettings := utils.OpenVPNProviderSettings{
 		AuthUserPass:  true,
 		RemoteCertTLS: true,
@@ -40,5 +40,5 @@ func (p *Provider) OpenVPNConfig(connection models.Connection,
 		providerSettings.SetEnv["UV_IPV6"] = "yes"
 	}
 
-	return utils.OpenVPNConfig(providerSettings, connection, settings, ipv6Supported)
+	return utils.OpenVPNConfig(providerSettings, connection, settings, ipv6Supported), nil
 }
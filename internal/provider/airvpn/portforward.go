@@ -0,0 +1,84 @@
+package airvpn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/qdm12/gluetun/internal/provider/common"
+	"github.com/qdm12/gluetun/internal/provider/utils"
+)
+
+const apiURL = "https://airvpn.org/api"
+
+var (
+	ErrPortNotValid   = errors.New("port is not valid")
+	ErrAPIResultNotOK = errors.New("API result is not ok")
+)
+
+type apiPorts struct {
+	Ports []apiPort `json:"ports"`
+}
+
+type apiPort struct {
+	Port    uint16 `json:"port"`
+	Enabled bool   `json:"enabled"`
+}
+
+type apiAddPort struct {
+	Result string `json:"result"`
+	Port   uint16 `json:"port"`
+}
+
+// PortForward obtains a port forwarded on the AirVPN servers, using
+// the account API key. All the enabled static port forwards of the
+// account are used, otherwise a new port forward is created.
+func (p *Provider) PortForward(ctx context.Context,
+	objects utils.PortForwardObjects) (ports []uint16, err error) {
+	var data apiPorts
+	err = common.DoAPI(ctx, objects.Client, http.MethodGet, p.apiURL+"/ports/",
+		apiKeyHeader(objects.APIKey), &data)
+	if err != nil {
+		return nil, fmt.Errorf("listing forwarded ports: %w", err)
+	}
+
+	for _, apiPort := range data.Ports {
+		if !apiPort.Enabled {
+			objects.Logger.Warn(fmt.Sprintf("skipping disabled port forward %d", apiPort.Port))
+			continue
+		}
+		objects.Logger.Info(fmt.Sprintf("using existing port forward %d", apiPort.Port))
+		ports = append(ports, apiPort.Port)
+	}
+	if len(ports) > 0 {
+		return ports, nil
+	}
+
+	var added apiAddPort
+	err = common.DoAPI(ctx, objects.Client, http.MethodPost, p.apiURL+"/ports/?action=add",
+		apiKeyHeader(objects.APIKey), &added)
+	if err != nil {
+		return nil, fmt.Errorf("adding port forward: %w", err)
+	}
+
+	if added.Result != "ok" {
+		return nil, fmt.Errorf("adding port forward: %w: %s", ErrAPIResultNotOK, added.Result)
+	} else if added.Port == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrPortNotValid, added.Port)
+	}
+
+	return []uint16{added.Port}, nil
+}
+
+// KeepPortForward blocks until the context is canceled, since AirVPN
+// static port forwards are kept until they are removed from the account.
+func (p *Provider) KeepPortForward(ctx context.Context,
+	_ utils.PortForwardObjects) (err error) {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func apiKeyHeader(apiKey string) http.Header {
+	return http.Header{"Api-Key": {apiKey}}
+}
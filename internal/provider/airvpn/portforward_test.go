@@ -0,0 +1,112 @@
+package airvpn
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/provider/common"
+	"github.com/qdm12/gluetun/internal/provider/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Provider_PortForward(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		listStatus int
+		listBody   string
+		addBody    string
+		ports      []uint16
+		errWrap    error
+		errMessage string
+		addCalled  bool
+		expectLogs func(logger *MockLogger)
+	}{
+		"unauthorized": {
+			listStatus: http.StatusUnauthorized,
+			errWrap:    common.ErrHTTPStatusCodeNotOK,
+			errMessage: "listing forwarded ports: HTTP status code not OK: 401 401 Unauthorized",
+		},
+		"existing enabled ports": {
+			listStatus: http.StatusOK,
+			listBody: `{"ports":[{"port":1000,"enabled":false},{"port":2000,"enabled":true},` +
+				`{"port":2500,"enabled":true}]}`,
+			ports: []uint16{2000, 2500},
+			expectLogs: func(logger *MockLogger) {
+				logger.EXPECT().Warn("skipping disabled port forward 1000")
+				logger.EXPECT().Info("using existing port forward 2000")
+				logger.EXPECT().Info("using existing port forward 2500")
+			},
+		},
+		"added port": {
+			listStatus: http.StatusOK,
+			listBody:   `{"ports":[{"port":1000,"enabled":false}]}`,
+			addBody:    `{"result":"ok","port":3000}`,
+			ports:      []uint16{3000},
+			addCalled:  true,
+			expectLogs: func(logger *MockLogger) {
+				logger.EXPECT().Warn("skipping disabled port forward 1000")
+			},
+		},
+		"add port failed": {
+			listStatus: http.StatusOK,
+			listBody:   `{"ports":[]}`,
+			addBody:    `{"result":"maximum ports reached"}`,
+			errWrap:    ErrAPIResultNotOK,
+			errMessage: "adding port forward: API result is not ok: maximum ports reached",
+			addCalled:  true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			logger := NewMockLogger(ctrl)
+			if testCase.expectLogs != nil {
+				testCase.expectLogs(logger)
+			}
+
+			addCalled := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/ports/", r.URL.Path)
+				assert.Equal(t, "key", r.Header.Get("API-KEY"))
+				switch r.Method {
+				case http.MethodGet:
+					w.WriteHeader(testCase.listStatus)
+					_, _ = w.Write([]byte(testCase.listBody))
+				case http.MethodPost:
+					addCalled = true
+					assert.Equal(t, "add", r.URL.Query().Get("action"))
+					_, _ = w.Write([]byte(testCase.addBody))
+				}
+			}))
+			defer server.Close()
+
+			provider := &Provider{apiURL: server.URL}
+			objects := utils.PortForwardObjects{
+				Logger: logger,
+				Client: server.Client(),
+				APIKey: "key",
+			}
+
+			ports, err := provider.PortForward(context.Background(), objects)
+
+			if testCase.errWrap != nil {
+				assert.ErrorIs(t, err, testCase.errWrap)
+			}
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.ports, ports)
+			assert.Equal(t, testCase.addCalled, addCalled)
+		})
+	}
+}
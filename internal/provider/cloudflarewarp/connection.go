@@ -0,0 +1,39 @@
+package cloudflarewarp
+
+import (
+	"net"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+const (
+	endpointHostname  = "engage.cloudflareclient.com"
+	endpointPort      = 2408
+	endpointPublicKey = "bmXOC+F1FxEMF9dyiK2H5/1SUtzH0JuVo51h2wPfgyo="
+)
+
+// GetConnection returns the connection to the Cloudflare WARP
+// anycast endpoint, which is the same for all devices.
+func (p *Provider) GetConnection(selection settings.ServerSelection, _ bool) (
+	connection models.Connection, err error) {
+	connection = models.Connection{
+		Type:     vpn.Wireguard,
+		IP:       net.IPv4(162, 159, 192, 1), //nolint:gomnd
+		Port:     endpointPort,
+		Protocol: constants.UDP,
+		Hostname: endpointHostname,
+		PubKey:   endpointPublicKey,
+	}
+
+	if len(selection.Wireguard.EndpointIP) > 0 {
+		connection.IP = selection.Wireguard.EndpointIP
+	}
+	if *selection.Wireguard.EndpointPort != 0 {
+		connection.Port = *selection.Wireguard.EndpointPort
+	}
+
+	return connection, nil
+}
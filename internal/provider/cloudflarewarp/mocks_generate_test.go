@@ -0,0 +1,3 @@
+package cloudflarewarp
+
+//go:generate mockgen -destination=mocks_test.go -package=$GOPACKAGE . Infoer
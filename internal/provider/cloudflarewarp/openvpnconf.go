@@ -0,0 +1,17 @@
+package cloudflarewarp
+
+import (
+	"errors"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+var ErrOpenVPNNotSupported = errors.New("OpenVPN is not supported by Cloudflare WARP")
+
+// OpenVPNConfig returns an error since Cloudflare WARP only supports
+// Wireguard, which is enforced by the settings validation.
+func (p *Provider) OpenVPNConfig(models.Connection, settings.OpenVPN, bool) (
+	lines []string, err error) {
+	return nil, ErrOpenVPNNotSupported
+}
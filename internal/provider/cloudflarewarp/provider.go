@@ -0,0 +1,23 @@
+package cloudflarewarp
+
+import (
+	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/qdm12/gluetun/internal/provider/common"
+	"github.com/qdm12/gluetun/internal/provider/utils"
+)
+
+type Provider struct {
+	utils.NoPortForwarder
+	common.Fetcher
+}
+
+func New() *Provider {
+	return &Provider{
+		NoPortForwarder: utils.NewNoPortForwarding(providers.CloudflareWARP),
+		Fetcher:         utils.NewNoFetcher(providers.CloudflareWARP),
+	}
+}
+
+func (p *Provider) Name() string {
+	return providers.CloudflareWARP
+}
@@ -0,0 +1,163 @@
+package cloudflarewarp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrHTTPStatusCodeNotOK = errors.New("HTTP status code not OK")
+	ErrAddressNotValid     = errors.New("address is not valid")
+)
+
+const baseURL = "https://api.cloudflareclient.com/v0a2158"
+
+// Registration is a Cloudflare WARP device registration.
+type Registration struct {
+	ID         string
+	Token      string
+	PrivateKey string
+	Addresses  []net.IPNet
+	License    string
+}
+
+// ProvisionWireguard registers the Wireguard public key given as a
+// new Cloudflare WARP device, applies the WARP+ license key given if
+// it is not empty, and returns the device registration.
+func ProvisionWireguard(ctx context.Context, client *http.Client,
+	publicKey, licenseKey string) (registration Registration, err error) {
+	return provisionWireguard(ctx, client, baseURL, publicKey, licenseKey, time.Now)
+}
+
+func provisionWireguard(ctx context.Context, client *http.Client,
+	baseURL, publicKey, licenseKey string, timeNow func() time.Time) (
+	registration Registration, err error) {
+	deviceRequest := struct {
+		Key       string `json:"key"`
+		InstallID string `json:"install_id"`
+		FCMToken  string `json:"fcm_token"`
+		TOS       string `json:"tos"`
+		Type      string `json:"type"`
+		Model     string `json:"model"`
+		Locale    string `json:"locale"`
+	}{
+		Key:    publicKey,
+		TOS:    timeNow().UTC().Format(time.RFC3339),
+		Type:   "Linux",
+		Model:  "PC",
+		Locale: "en_US",
+	}
+	var deviceResponse struct {
+		ID     string `json:"id"`
+		Token  string `json:"token"`
+		Config struct {
+			Interface struct {
+				Addresses struct {
+					V4 string `json:"v4"`
+					V6 string `json:"v6"`
+				} `json:"addresses"`
+			} `json:"interface"`
+		} `json:"config"`
+	}
+	err = doJSON(ctx, client, http.MethodPost, baseURL+"/reg", "",
+		deviceRequest, &deviceResponse)
+	if err != nil {
+		return registration, fmt.Errorf("registering device: %w", err)
+	}
+
+	registration.ID = deviceResponse.ID
+	registration.Token = deviceResponse.Token
+	addresses := deviceResponse.Config.Interface.Addresses
+	for _, address := range []string{addresses.V4, addresses.V6} {
+		if address == "" {
+			continue
+		}
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return registration, fmt.Errorf("%w: %s", ErrAddressNotValid, address)
+		}
+		bits := 8 * net.IPv6len
+		if ipv4 := ip.To4(); ipv4 != nil {
+			ip = ipv4
+			bits = 8 * net.IPv4len
+		}
+		registration.Addresses = append(registration.Addresses,
+			net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+
+	if len(registration.Addresses) == 0 {
+		return registration, fmt.Errorf("%w: no address assigned to device", ErrAddressNotValid)
+	}
+
+	if licenseKey != "" {
+		err = applyLicense(ctx, client, baseURL, registration, licenseKey)
+		if err != nil {
+			return registration, err
+		}
+		registration.License = licenseKey
+	}
+
+	return registration, nil
+}
+
+// applyLicense applies the WARP+ license key given
+// to the Cloudflare WARP device registered.
+func applyLicense(ctx context.Context, client *http.Client, baseURL string,
+	registration Registration, licenseKey string) (err error) {
+	licenseRequest := struct {
+		License string `json:"license"`
+	}{License: licenseKey}
+	var licenseResponse struct {
+		WarpPlus bool `json:"warp_plus"`
+	}
+	err = doJSON(ctx, client, http.MethodPut,
+		baseURL+"/reg/"+registration.ID+"/account", registration.Token,
+		licenseRequest, &licenseResponse)
+	if err != nil {
+		return fmt.Errorf("applying license key: %w", err)
+	}
+	return nil
+}
+
+func doJSON(ctx context.Context, client *http.Client, method, url, bearerToken string,
+	requestBody, responseBody interface{}) (err error) {
+	b, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("encoding request body: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("CF-Client-Version", "a-6.10-2158")
+	if bearerToken != "" {
+		request.Header.Set("Authorization", "Bearer "+bearerToken)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %d %s", ErrHTTPStatusCodeNotOK,
+			response.StatusCode, response.Status)
+	}
+
+	decoder := json.NewDecoder(response.Body)
+	err = decoder.Decode(responseBody)
+	if err != nil {
+		return fmt.Errorf("decoding response body: %w", err)
+	}
+
+	return response.Body.Close()
+}
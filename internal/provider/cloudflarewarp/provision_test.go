@@ -0,0 +1,79 @@
+package cloudflarewarp
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPI(t *testing.T) (server *httptest.Server) {
+	t.Helper()
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/reg":
+			assert.Equal(t, "public", body["key"])
+			assert.Equal(t, "2024-05-15T10:32:00Z", body["tos"])
+			_, _ = w.Write([]byte(`{"id":"device","token":"token","config":{"interface":` +
+				`{"addresses":{"v4":"172.16.0.2","v6":"2606:4700:110:8a36::1"}}}}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/reg/device/account":
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			if body["license"] != "license" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"warp_plus":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_provisionWireguard(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPI(t)
+	timeNow := func() time.Time { return time.Date(2024, time.May, 15, 10, 32, 0, 0, time.UTC) }
+
+	registration, err := provisionWireguard(context.Background(), server.Client(),
+		server.URL, "public", "license", timeNow)
+
+	require.NoError(t, err)
+	expected := Registration{
+		ID:    "device",
+		Token: "token",
+		Addresses: []net.IPNet{
+			{IP: net.IPv4(172, 16, 0, 2).To4(), Mask: net.CIDRMask(32, 32)},
+			{IP: net.ParseIP("2606:4700:110:8a36::1"), Mask: net.CIDRMask(128, 128)},
+		},
+		License: "license",
+	}
+	assert.Equal(t, expected, registration)
+}
+
+func Test_provisionWireguard_badLicense(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPI(t)
+	timeNow := func() time.Time { return time.Date(2024, time.May, 15, 10, 32, 0, 0, time.UTC) }
+
+	_, err := provisionWireguard(context.Background(), server.Client(),
+		server.URL, "public", "bad", timeNow)
+
+	assert.ErrorIs(t, err, ErrHTTPStatusCodeNotOK)
+	assert.EqualError(t, err, "applying license key: HTTP status code not OK: 400 400 Bad Request")
+}
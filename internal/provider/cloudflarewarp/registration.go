@@ -0,0 +1,136 @@
+package cloudflarewarp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+type Infoer interface {
+	Info(message string)
+}
+
+// Register returns the Cloudflare WARP device registration stored
+// in the file at the path given. If the file does not exist, a new
+// device is registered with a generated private key and stored in
+// the file, so the same device is used across restarts. The WARP+
+// license key given is applied if it is not empty and differs from
+// the license key of the stored registration.
+func Register(ctx context.Context, client *http.Client, path,
+	licenseKey string, logger Infoer) (registration Registration, err error) {
+	return register(ctx, client, baseURL, path, licenseKey, time.Now, logger)
+}
+
+func register(ctx context.Context, client *http.Client, baseURL, path,
+	licenseKey string, timeNow func() time.Time, logger Infoer) (
+	registration Registration, err error) {
+	registration, err = readRegistration(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		privateKey, err := wgtypes.GeneratePrivateKey()
+		if err != nil {
+			return registration, fmt.Errorf("generating private key: %w", err)
+		}
+		registration, err = provisionWireguard(ctx, client, baseURL,
+			privateKey.PublicKey().String(), licenseKey, timeNow)
+		if err != nil {
+			return registration, err
+		}
+		registration.PrivateKey = privateKey.String()
+		logger.Info("registered Cloudflare WARP device " + registration.ID)
+	case err != nil:
+		return registration, err
+	case licenseKey == "" || licenseKey == registration.License:
+		return registration, nil
+	default:
+		err = applyLicense(ctx, client, baseURL, registration, licenseKey)
+		if err != nil {
+			return registration, err
+		}
+		registration.License = licenseKey
+		logger.Info("applied WARP+ license key to Cloudflare WARP device " + registration.ID)
+	}
+
+	err = writeRegistration(path, registration)
+	if err != nil {
+		return registration, err
+	}
+	return registration, nil
+}
+
+type registrationFile struct {
+	ID         string   `json:"id"`
+	Token      string   `json:"token"`
+	PrivateKey string   `json:"private_key"`
+	Addresses  []string `json:"addresses"`
+	License    string   `json:"license,omitempty"`
+}
+
+func readRegistration(path string) (registration Registration, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return registration, err
+	}
+
+	var file registrationFile
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return registration, fmt.Errorf("decoding registration file: %w", err)
+	}
+
+	registration = Registration{
+		ID:         file.ID,
+		Token:      file.Token,
+		PrivateKey: file.PrivateKey,
+		Addresses:  make([]net.IPNet, len(file.Addresses)),
+		License:    file.License,
+	}
+	for i, address := range file.Addresses {
+		ip, ipNet, err := net.ParseCIDR(address)
+		if err != nil {
+			return registration, fmt.Errorf("%w: %s", ErrAddressNotValid, err)
+		}
+		ipNet.IP = ip
+		registration.Addresses[i] = *ipNet
+	}
+	return registration, nil
+}
+
+func writeRegistration(path string, registration Registration) (err error) {
+	file := registrationFile{
+		ID:         registration.ID,
+		Token:      registration.Token,
+		PrivateKey: registration.PrivateKey,
+		Addresses:  make([]string, len(registration.Addresses)),
+		License:    registration.License,
+	}
+	for i, address := range registration.Addresses {
+		file.Addresses[i] = address.String()
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding registration file: %w", err)
+	}
+
+	const dirPerms = os.FileMode(0700)
+	err = os.MkdirAll(filepath.Dir(path), dirPerms)
+	if err != nil {
+		return fmt.Errorf("creating registration file directory: %w", err)
+	}
+
+	const perms = os.FileMode(0600)
+	err = os.WriteFile(path, data, perms)
+	if err != nil {
+		return fmt.Errorf("writing registration file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package cloudflarewarp
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_register(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	logger := NewMockInfoer(ctrl)
+	path := filepath.Join(t.TempDir(), "cloudflare-warp.json")
+	timeNow := func() time.Time { return time.Date(2024, time.May, 15, 10, 32, 0, 0, time.UTC) }
+
+	registerCalls := 0
+	api := newTestAPI(t)
+	handler := api.Config.Handler
+	api.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			registerCalls++
+			// The request key is the generated public key
+			// so the device request is answered directly.
+			_, _ = w.Write([]byte(`{"id":"device","token":"token","config":{"interface":` +
+				`{"addresses":{"v4":"172.16.0.2"}}}}`))
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+
+	logger.EXPECT().Info("registered Cloudflare WARP device device")
+	registration, err := register(context.Background(), api.Client(), api.URL,
+		path, "", timeNow, logger)
+	require.NoError(t, err)
+	assert.Equal(t, "device", registration.ID)
+	assert.NotEmpty(t, registration.PrivateKey)
+	assert.Empty(t, registration.License)
+
+	// The stored registration is reused
+	stored, err := register(context.Background(), api.Client(), api.URL,
+		path, "", timeNow, logger)
+	require.NoError(t, err)
+	assert.Equal(t, registration.PrivateKey, stored.PrivateKey)
+	assert.Equal(t, registration.Addresses[0].String(), stored.Addresses[0].String())
+
+	// The license key is applied to the stored registration
+	logger.EXPECT().Info("applied WARP+ license key to Cloudflare WARP device device")
+	licensed, err := register(context.Background(), api.Client(), api.URL,
+		path, "license", timeNow, logger)
+	require.NoError(t, err)
+	assert.Equal(t, registration.PrivateKey, licensed.PrivateKey)
+	assert.Equal(t, "license", licensed.License)
+	assert.Equal(t, 1, registerCalls)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
@@ -0,0 +1,48 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// DoAPI sends an HTTP request with the method and the header given to
+// the URL given, and decodes the JSON body of the response in the
+// responseBody given. It returns an error wrapping ErrHTTPStatusCodeNotOK
+// if the response status code is not 200 OK or 201 Created.
+func DoAPI(ctx context.Context, client *http.Client, method, url string,
+	header http.Header, responseBody interface{}) (err error) {
+	request, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return fmt.Errorf("creating HTTP request: %w", err)
+	}
+	for key, values := range header {
+		request.Header[key] = values
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("doing HTTP request: %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		_ = response.Body.Close()
+		return fmt.Errorf("%w: %d %s", ErrHTTPStatusCodeNotOK,
+			response.StatusCode, response.Status)
+	}
+
+	decoder := json.NewDecoder(response.Body)
+	err = decoder.Decode(responseBody)
+	if err != nil {
+		_ = response.Body.Close()
+		return fmt.Errorf("decoding response body: %w", err)
+	}
+
+	err = response.Body.Close()
+	if err != nil {
+		return fmt.Errorf("closing response body: %w", err)
+	}
+
+	return nil
+}
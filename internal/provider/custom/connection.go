@@ -3,11 +3,15 @@ package custom
 import (
 	"errors"
 	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/constants"
 	"github.com/qdm12/gluetun/internal/constants/vpn"
 	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/openvpn/extract"
 )
 
 var (
@@ -19,6 +23,9 @@ func (p *Provider) GetConnection(selection settings.ServerSelection, _ bool) (
 	connection models.Connection, err error) {
 	switch selection.VPN {
 	case vpn.OpenVPN:
+		if extract.IsDirectory(*selection.OpenVPN.ConfFile) {
+			return getDirectoryOpenVPNConnection(p.extractor, selection, p.randSource)
+		}
 		return getOpenVPNConnection(p.extractor, selection)
 	case vpn.Wireguard:
 		return getWireguardConnection(selection), nil
@@ -43,6 +50,53 @@ func getOpenVPNConnection(extractor Extractor,
 	return connection, nil
 }
 
+var ErrNoConfigFileMatch = errors.New("no configuration file matches the server names")
+
+// getDirectoryOpenVPNConnection picks a random configuration file from
+// the custom configuration directory, amongst the ones matching the
+// server names if any, and returns its connection with the server name
+// set to the file name without extension.
+func getDirectoryOpenVPNConnection(extractor Extractor,
+	selection settings.ServerSelection, randSource rand.Source) (
+	connection models.Connection, err error) {
+	nameToPath, err := extract.ConfigFiles(*selection.OpenVPN.ConfFile)
+	if err != nil {
+		return connection, err
+	}
+
+	selectedNames := make(map[string]struct{}, len(selection.Names))
+	for _, name := range selection.Names {
+		selectedNames[name] = struct{}{}
+	}
+
+	names := make([]string, 0, len(nameToPath))
+	for name := range nameToPath {
+		if _, selected := selectedNames[name]; len(selectedNames) > 0 && !selected {
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return connection, fmt.Errorf("%w: %s",
+			ErrNoConfigFileMatch, strings.Join(selection.Names, ", "))
+	}
+	sort.Strings(names) // for a deterministic pick given the random source
+
+	name := names[rand.New(randSource).Intn(len(names))] //nolint:gosec
+	_, connection, err = extractor.Data(nameToPath[name])
+	if err != nil {
+		return connection, fmt.Errorf("extracting connection from %s: %w", nameToPath[name], err)
+	}
+	connection.ServerName = name
+
+	customPort := *selection.OpenVPN.CustomPort
+	if customPort > 0 {
+		connection.Port = customPort
+	}
+
+	return connection, nil
+}
+
 func getWireguardConnection(selection settings.ServerSelection) (
 	connection models.Connection) {
 	return models.Connection{
@@ -0,0 +1,95 @@
+package custom
+
+import (
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/openvpn/extract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getDirectoryOpenVPNConnection(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+	nameToContent := map[string]string{
+		"alpha.ovpn": "client\nremote 1.1.1.1 1194\n",
+		"beta.ovpn":  "client\nproto tcp\nremote 2.2.2.2 443\n",
+	}
+	for name, content := range nameToContent {
+		err := os.WriteFile(filepath.Join(directory, name), []byte(content), 0o600)
+		require.NoError(t, err)
+	}
+
+	testCases := map[string]struct {
+		names      []string
+		connection models.Connection
+		errMessage string
+	}{
+		"server name selected": {
+			names: []string{"beta"},
+			connection: models.Connection{
+				IP:         net.IPv4(2, 2, 2, 2),
+				Port:       443,
+				Protocol:   constants.TCP,
+				ServerName: "beta",
+			},
+		},
+		"no matching server name": {
+			names:      []string{"gamma"},
+			errMessage: "no configuration file matches the server names: gamma",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			selection := settings.ServerSelection{
+				Names: testCase.names,
+				OpenVPN: settings.OpenVPNSelection{
+					ConfFile:   stringPtr(directory),
+					CustomPort: uint16Ptr(0),
+				},
+			}
+			randSource := rand.NewSource(0)
+
+			connection, err := getDirectoryOpenVPNConnection(extract.New(),
+				selection, randSource)
+
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.True(t, testCase.connection.Equal(connection),
+				"expected %+v, got %+v", testCase.connection, connection)
+		})
+	}
+}
+
+func Test_Provider_OpenVPNConfig_directory(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+	err := os.WriteFile(filepath.Join(directory, "alpha.ovpn"),
+		[]byte("client\nremote 1.1.1.1 1194\n"), 0o600)
+	require.NoError(t, err)
+
+	provider := New(extract.New(), rand.NewSource(0))
+	openvpnSettings := settings.OpenVPN{ConfFile: stringPtr(directory)}
+
+	_, err = provider.OpenVPNConfig(models.Connection{ServerName: "gamma"},
+		openvpnSettings, false)
+
+	assert.ErrorIs(t, err, ErrConfigFileNotFound)
+	assert.EqualError(t, err, "configuration file not found: gamma in "+directory)
+}
@@ -9,27 +9,46 @@ import (
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/constants/openvpn"
 	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/openvpn/extract"
 	"github.com/qdm12/gluetun/internal/provider/utils"
 )
 
-var ErrExtractData = errors.New("failed extracting information from custom configuration file")
+var (
+	ErrExtractData        = errors.New("failed extracting information from custom configuration file")
+	ErrConfigFileNotFound = errors.New("configuration file not found")
+)
 
 func (p *Provider) OpenVPNConfig(connection models.Connection,
-	settings settings.OpenVPN, ipv6Supported bool) (lines []string) {
-	lines, _, err := p.extractor.Data(*settings.ConfFile)
+	settings settings.OpenVPN, ipv6Supported bool) (lines []string, err error) {
+	confFile := *settings.ConfFile
+	if extract.IsDirectory(confFile) {
+		// The server name is the name of the configuration
+		// file picked in getDirectoryOpenVPNConnection.
+		nameToPath, err := extract.ConfigFiles(confFile)
+		if err != nil {
+			return nil, fmt.Errorf("listing custom configuration files: %w", err)
+		}
+		var ok bool
+		confFile, ok = nameToPath[connection.ServerName]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s in %s", ErrConfigFileNotFound,
+				connection.ServerName, *settings.ConfFile)
+		}
+	}
+
+	lines, _, err = p.extractor.Data(confFile)
 	if err != nil {
-		// Configuration file is already validated in settings validation in
-		// internal/configuration/settings/openvpn.go in `validateOpenVPNConfigFilepath`.
-		// Therefore this error is the result of a programming error.
-		panic(fmt.Sprintf("failed extracting information from custom configuration file: %s", err))
+		return nil, fmt.Errorf("extracting information from custom configuration file: %w", err)
 	}
 
-	lines = modifyConfig(lines, connection, settings, ipv6Supported)
+	lines = ModifyConfig(lines, connection, settings, ipv6Supported)
 
-	return lines
+	return lines, nil
 }
 
-func modifyConfig(lines []string, connection models.Connection,
+// ModifyConfig modifies the OpenVPN configuration lines given
+// to connect to the connection given with the settings given.
+func ModifyConfig(lines []string, connection models.Connection,
 	settings settings.OpenVPN, ipv6Supported bool) (modified []string) {
 	// Remove some lines
 	for _, line := range lines {
@@ -57,6 +76,8 @@ func modifyConfig(lines []string, connection models.Connection,
 				"cipher ", "ncp-ciphers ", "data-ciphers ", "data-ciphers-fallback "),
 			*settings.Auth != "" && strings.HasPrefix(line, "auth "),
 			*settings.MSSFix > 0 && strings.HasPrefix(line, "mssfix "),
+			*settings.PKCS11Provider != "" && hasPrefixOneOf(line,
+				"pkcs11-providers ", "pkcs11-id "),
 			!ipv6Supported && hasPrefixOneOf(line, "tun-ipv6",
 				`pull-filter ignore "route-ipv6"`,
 				`pull-filter ignore "ifconfig-ipv6"`):
@@ -87,6 +108,7 @@ func modifyConfig(lines []string, connection models.Connection,
 	if *settings.MSSFix > 0 {
 		modified = append(modified, "mssfix "+strconv.Itoa(int(*settings.MSSFix)))
 	}
+	modified = append(modified, utils.PKCS11Lines(settings)...)
 	if !ipv6Supported {
 		modified = append(modified, `pull-filter ignore "route-ipv6"`)
 		modified = append(modified, `pull-filter ignore "ifconfig-ipv6"`)
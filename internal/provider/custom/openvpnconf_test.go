@@ -15,7 +15,7 @@ func intPtr(n int) *int          { return &n }
 func uint16Ptr(n uint16) *uint16 { return &n }
 func stringPtr(s string) *string { return &s }
 
-func Test_modifyConfig(t *testing.T) {
+func Test_ModifyConfig(t *testing.T) {
 	t.Parallel()
 
 	testCases := map[string]struct {
@@ -76,6 +76,38 @@ func Test_modifyConfig(t *testing.T) {
 				"",
 			},
 		},
+		"pkcs11": {
+			lines: []string{
+				"pkcs11-providers /usr/lib/old.so",
+				"pkcs11-id 'old'",
+				"keep me here",
+			},
+			settings: settings.OpenVPN{
+				PKCS11Provider: stringPtr("/usr/lib/opensc-pkcs11.so"),
+				PKCS11ID:       stringPtr(`piv_II/PKCS\x2315\x20emulated/1234/PIV_II/01`),
+			}.WithDefaults(providers.Custom),
+			connection: models.Connection{
+				IP:       net.IPv4(1, 2, 3, 4),
+				Port:     1194,
+				Protocol: constants.UDP,
+			},
+			ipv6Supported: true,
+			modified: []string{
+				"keep me here",
+				"proto udp",
+				"remote 1.2.3.4 1194",
+				"dev tun0",
+				"mute-replay-warnings",
+				"auth-nocache",
+				"pull-filter ignore \"auth-token\"",
+				"auth-retry nointeract",
+				"suppress-timestamps",
+				"verb 1",
+				"pkcs11-providers /usr/lib/opensc-pkcs11.so",
+				`pkcs11-id 'piv_II/PKCS\x2315\x20emulated/1234/PIV_II/01'`,
+				"",
+			},
+		},
 	}
 
 	for name, testCase := range testCases {
@@ -83,7 +115,7 @@ func Test_modifyConfig(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			modified := modifyConfig(testCase.lines,
+			modified := ModifyConfig(testCase.lines,
 				testCase.connection, testCase.settings, testCase.ipv6Supported)
 
 			assert.Equal(t, testCase.modified, modified)
@@ -1,20 +1,24 @@
 package custom
 
 import (
+	"math/rand"
+
 	"github.com/qdm12/gluetun/internal/constants/providers"
 	"github.com/qdm12/gluetun/internal/provider/common"
 	"github.com/qdm12/gluetun/internal/provider/utils"
 )
 
 type Provider struct {
-	extractor Extractor
+	extractor  Extractor
+	randSource rand.Source
 	utils.NoPortForwarder
 	common.Fetcher
 }
 
-func New(extractor Extractor) *Provider {
+func New(extractor Extractor, randSource rand.Source) *Provider {
 	return &Provider{
 		extractor:       extractor,
+		randSource:      randSource,
 		NoPortForwarder: utils.NewNoPortForwarding(providers.Custom),
 		Fetcher:         utils.NewNoFetcher(providers.Custom),
 	}
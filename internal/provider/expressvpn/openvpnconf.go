@@ -8,7 +8,7 @@ import (
 )
 
 func (p *Provider) OpenVPNConfig(connection models.Connection,
-	settings settings.OpenVPN, ipv6Supported bool) (lines []string) {
+	settings settings.OpenVPN, ipv6Supported bool) (lines []string, err error) {
 	//nolint:gomnd
 	providerSettings := utils.OpenVPNProviderSettings{
 		RemoteCertTLS: true,
@@ -31,5 +31,5 @@ func (p *Provider) OpenVPNConfig(connection models.Connection,
 		// Always verify against `Server` x509 name prefix, security hole I guess?
 		VerifyX509Name: "Server",
 	}
-	return utils.OpenVPNConfig(providerSettings, connection, settings, ipv6Supported)
+	return utils.OpenVPNConfig(providerSettings, connection, settings, ipv6Supported), nil
 }
@@ -8,7 +8,7 @@ import (
 )
 
 func (p *Provider) OpenVPNConfig(connection models.Connection,
-	settings settings.OpenVPN, ipv6Supported bool) (lines []string) {
+	settings settings.OpenVPN, ipv6Supported bool) (lines []string, err error) {
 	//nolint:gomnd
 	providerSettings := utils.OpenVPNProviderSettings{
 		AuthUserPass: true,
@@ -25,5 +25,5 @@ func (p *Provider) OpenVPNConfig(connection models.Connection,
 			"key-direction 1",
 		},
 	}
-	return utils.OpenVPNConfig(providerSettings, connection, settings, ipv6Supported)
+	return utils.OpenVPNConfig(providerSettings, connection, settings, ipv6Supported), nil
 }
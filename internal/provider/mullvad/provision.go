@@ -0,0 +1,108 @@
+package mullvad
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+var (
+	ErrHTTPStatusCodeNotOK = errors.New("HTTP status code not OK")
+	ErrAddressNotValid     = errors.New("address is not valid")
+)
+
+// ProvisionWireguard registers the Wireguard public key given as
+// a new device of the Mullvad account given, and returns the
+// Wireguard interface addresses assigned to the device.
+func ProvisionWireguard(ctx context.Context, client *http.Client,
+	accountNumber, publicKey string) (addresses []net.IPNet, err error) {
+	const baseURL = "https://api.mullvad.net"
+	return provisionWireguard(ctx, client, baseURL, accountNumber, publicKey)
+}
+
+func provisionWireguard(ctx context.Context, client *http.Client,
+	baseURL, accountNumber, publicKey string) (addresses []net.IPNet, err error) {
+	tokenRequest := struct {
+		AccountNumber string `json:"account_number"`
+	}{AccountNumber: accountNumber}
+	var tokenResponse struct {
+		AccessToken string `json:"access_token"`
+	}
+	err = postJSON(ctx, client, baseURL+"/auth/v1/token", "",
+		tokenRequest, &tokenResponse)
+	if err != nil {
+		return nil, fmt.Errorf("obtaining access token: %w", err)
+	}
+
+	deviceRequest := struct {
+		PublicKey string `json:"pubkey"`
+		HijackDNS bool   `json:"hijack_dns"`
+	}{PublicKey: publicKey}
+	var deviceResponse struct {
+		IPv4Address string `json:"ipv4_address"`
+		IPv6Address string `json:"ipv6_address"`
+	}
+	err = postJSON(ctx, client, baseURL+"/accounts/v1/devices", tokenResponse.AccessToken,
+		deviceRequest, &deviceResponse)
+	if err != nil {
+		return nil, fmt.Errorf("registering device: %w", err)
+	}
+
+	for _, address := range []string{deviceResponse.IPv4Address, deviceResponse.IPv6Address} {
+		if address == "" {
+			continue
+		}
+		ip, ipNet, err := net.ParseCIDR(address)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrAddressNotValid, err)
+		}
+		ipNet.IP = ip
+		addresses = append(addresses, *ipNet)
+	}
+
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("%w: no address assigned to device", ErrAddressNotValid)
+	}
+
+	return addresses, nil
+}
+
+func postJSON(ctx context.Context, client *http.Client, url, bearerToken string,
+	requestBody, responseBody interface{}) (err error) {
+	b, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("encoding request body: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if bearerToken != "" {
+		request.Header.Set("Authorization", "Bearer "+bearerToken)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		return fmt.Errorf("%w: %d %s", ErrHTTPStatusCodeNotOK,
+			response.StatusCode, response.Status)
+	}
+
+	decoder := json.NewDecoder(response.Body)
+	err = decoder.Decode(responseBody)
+	if err != nil {
+		return fmt.Errorf("decoding response body: %w", err)
+	}
+
+	return response.Body.Close()
+}
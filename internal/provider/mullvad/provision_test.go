@@ -0,0 +1,64 @@
+package mullvad
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_provisionWireguard(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		switch r.URL.Path {
+		case "/auth/v1/token":
+			assert.Equal(t, map[string]interface{}{"account_number": "1234"}, body)
+			_, _ = w.Write([]byte(`{"access_token":"token","expiry":"2030-01-01T00:00:00Z"}`))
+		case "/accounts/v1/devices":
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			assert.Equal(t, map[string]interface{}{"pubkey": "public", "hijack_dns": false}, body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"ipv4_address":"10.64.1.2/32","ipv6_address":"fc00::1/128"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	addresses, err := provisionWireguard(context.Background(), server.Client(),
+		server.URL, "1234", "public")
+
+	require.NoError(t, err)
+	expected := []net.IPNet{
+		{IP: net.ParseIP("10.64.1.2"), Mask: net.CIDRMask(32, 32)},
+		{IP: net.ParseIP("fc00::1"), Mask: net.CIDRMask(128, 128)},
+	}
+	assert.Equal(t, expected, addresses)
+}
+
+func Test_provisionWireguard_badAccount(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := provisionWireguard(context.Background(), server.Client(),
+		server.URL, "1234", "public")
+
+	assert.ErrorIs(t, err, ErrHTTPStatusCodeNotOK)
+	assert.EqualError(t, err, "obtaining access token: HTTP status code not OK: 401 401 Unauthorized")
+}
@@ -0,0 +1,3 @@
+package ovpn
+
+//go:generate mockgen -destination=mocks_test.go -package=$GOPACKAGE github.com/qdm12/gluetun/internal/provider/utils Logger
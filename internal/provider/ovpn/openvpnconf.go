@@ -0,0 +1,23 @@
+package ovpn
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/provider/custom"
+)
+
+// OpenVPNConfig returns the OpenVPN configuration lines built from
+// the OpenVPN configuration file downloaded from the OVPN website,
+// since its certificate authority and tls-auth key are not published
+// separately, modified to connect to the OVPN server picked.
+func (p *Provider) OpenVPNConfig(connection models.Connection,
+	settings settings.OpenVPN, ipv6Supported bool) (lines []string, err error) {
+	lines, _, err = p.extractor.Data(*settings.ConfFile)
+	if err != nil {
+		return nil, fmt.Errorf("extracting information from configuration file: %w", err)
+	}
+
+	return custom.ModifyConfig(lines, connection, settings, ipv6Supported), nil
+}
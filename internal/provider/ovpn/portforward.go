@@ -0,0 +1,69 @@
+package ovpn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/qdm12/gluetun/internal/provider/common"
+	"github.com/qdm12/gluetun/internal/provider/utils"
+)
+
+const apiURL = "https://www.ovpn.com/v2/api/client"
+
+var ErrPortNotValid = errors.New("port is not valid")
+
+type apiPort struct {
+	Port     uint16 `json:"port"`
+	Protocol string `json:"protocol"`
+}
+
+// PortForward obtains a port forwarded on the OVPN public IPv4 address
+// of the account, using the account API key. All the existing port forwards
+// are re-used, otherwise a new one is created.
+func (p *Provider) PortForward(ctx context.Context,
+	objects utils.PortForwardObjects) (ports []uint16, err error) {
+	var apiPorts []apiPort
+	err = common.DoAPI(ctx, objects.Client, http.MethodGet, p.apiURL+"/ports",
+		apiKeyHeader(objects.APIKey), &apiPorts)
+	if err != nil {
+		return nil, fmt.Errorf("listing forwarded ports: %w", err)
+	}
+
+	if len(apiPorts) > 0 {
+		ports = make([]uint16, len(apiPorts))
+		for i, apiPort := range apiPorts {
+			ports[i] = apiPort.Port
+			objects.Logger.Info(fmt.Sprintf("using existing port forward %d", apiPort.Port))
+		}
+	} else {
+		var created apiPort
+		err = common.DoAPI(ctx, objects.Client, http.MethodPost, p.apiURL+"/ports",
+			apiKeyHeader(objects.APIKey), &created)
+		if err != nil {
+			return nil, fmt.Errorf("creating port forward: %w", err)
+		}
+		ports = []uint16{created.Port}
+	}
+
+	for _, port := range ports {
+		if port == 0 {
+			return nil, fmt.Errorf("%w: %d", ErrPortNotValid, port)
+		}
+	}
+
+	return ports, nil
+}
+
+// KeepPortForward blocks until the context is canceled, since
+// OVPN port forwards are kept until they are deleted from the account.
+func (p *Provider) KeepPortForward(ctx context.Context,
+	_ utils.PortForwardObjects) (err error) {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func apiKeyHeader(apiKey string) http.Header {
+	return http.Header{"Authorization": {"Bearer " + apiKey}}
+}
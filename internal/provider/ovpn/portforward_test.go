@@ -0,0 +1,107 @@
+package ovpn
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/provider/common"
+	"github.com/qdm12/gluetun/internal/provider/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Provider_PortForward(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		listStatus   int
+		listBody     string
+		createBody   string
+		ports        []uint16
+		errWrap      error
+		errMessage   string
+		createCalled bool
+		expectLogs   func(logger *MockLogger)
+	}{
+		"unauthorized": {
+			listStatus: http.StatusUnauthorized,
+			errWrap:    common.ErrHTTPStatusCodeNotOK,
+			errMessage: "listing forwarded ports: HTTP status code not OK: 401 401 Unauthorized",
+		},
+		"existing ports": {
+			listStatus: http.StatusOK,
+			listBody:   `[{"port":45678,"protocol":"tcp"},{"port":45679,"protocol":"tcp"}]`,
+			ports:      []uint16{45678, 45679},
+			expectLogs: func(logger *MockLogger) {
+				logger.EXPECT().Info("using existing port forward 45678")
+				logger.EXPECT().Info("using existing port forward 45679")
+			},
+		},
+		"created port": {
+			listStatus:   http.StatusOK,
+			listBody:     `[]`,
+			createBody:   `{"port":34567,"protocol":"tcp"}`,
+			ports:        []uint16{34567},
+			createCalled: true,
+		},
+		"created port not valid": {
+			listStatus:   http.StatusOK,
+			listBody:     `[]`,
+			createBody:   `{}`,
+			errWrap:      ErrPortNotValid,
+			errMessage:   "port is not valid: 0",
+			createCalled: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			logger := NewMockLogger(ctrl)
+			if testCase.expectLogs != nil {
+				testCase.expectLogs(logger)
+			}
+
+			createCalled := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/ports", r.URL.Path)
+				assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+				switch r.Method {
+				case http.MethodGet:
+					w.WriteHeader(testCase.listStatus)
+					_, _ = w.Write([]byte(testCase.listBody))
+				case http.MethodPost:
+					createCalled = true
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(testCase.createBody))
+				}
+			}))
+			defer server.Close()
+
+			provider := &Provider{apiURL: server.URL}
+			objects := utils.PortForwardObjects{
+				Logger: logger,
+				Client: server.Client(),
+				APIKey: "key",
+			}
+
+			ports, err := provider.PortForward(context.Background(), objects)
+
+			if testCase.errWrap != nil {
+				assert.ErrorIs(t, err, testCase.errWrap)
+			}
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.ports, ports)
+			assert.Equal(t, testCase.createCalled, createCalled)
+		})
+	}
+}
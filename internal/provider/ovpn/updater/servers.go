@@ -0,0 +1,61 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/provider/common"
+)
+
+func (u *Updater) FetchServers(ctx context.Context, minServers int) (
+	servers []models.Server, err error) {
+	data, err := fetchAPI(ctx, u.client)
+	if err != nil {
+		return nil, fmt.Errorf("fetching API: %w", err)
+	}
+
+	// every online API server has an OpenVPN TCP+UDP server and
+	// a Wireguard server, both using the same IP address.
+	for _, dataCenter := range data.DataCenters {
+		for _, apiServer := range dataCenter.Servers {
+			if !apiServer.Online {
+				continue
+			}
+
+			baseServer := models.Server{
+				Country:    dataCenter.CountryName,
+				City:       dataCenter.City,
+				ServerName: apiServer.Name,
+				Hostname:   apiServer.Ptr,
+				IPs:        []net.IP{apiServer.IP},
+			}
+
+			openvpnServer := baseServer
+			openvpnServer.VPN = vpn.OpenVPN
+			openvpnServer.TCP = true
+			openvpnServer.UDP = true
+			servers = append(servers, openvpnServer)
+
+			if apiServer.PublicKey == "" {
+				continue
+			}
+			wireguardServer := baseServer
+			wireguardServer.VPN = vpn.Wireguard
+			wireguardServer.WgPubKey = apiServer.PublicKey
+			servers = append(servers, wireguardServer)
+		}
+	}
+
+	if len(servers) < minServers {
+		return nil, fmt.Errorf("%w: %d and expected at least %d",
+			common.ErrNotEnoughServers, len(servers), minServers)
+	}
+
+	sort.Sort(models.SortableServers(servers))
+
+	return servers, nil
+}
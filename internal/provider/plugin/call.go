@@ -0,0 +1,56 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+var (
+	ErrPluginFailed     = errors.New("plugin failed")
+	ErrResponseNotValid = errors.New("plugin response is not valid")
+)
+
+// call runs the plugin executable for the method given, with the
+// request given JSON encoded on its standard input, and decodes
+// its standard output into the response given if it is not nil.
+func (p *Provider) call(ctx context.Context, method string,
+	request, response interface{}) (err error) {
+	requestData, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("encoding %s request: %w", method, err)
+	}
+
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	cmd := exec.CommandContext(ctx, p.path, method) //nolint:gosec
+	cmd.Stdin = bytes.NewReader(requestData)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return fmt.Errorf("%w: %s: %s", ErrPluginFailed, method, message)
+	}
+
+	if response == nil {
+		return nil
+	}
+
+	err = json.Unmarshal(stdout.Bytes(), response)
+	if err != nil {
+		return fmt.Errorf("decoding %s response: %w", method, err)
+	}
+	return nil
+}
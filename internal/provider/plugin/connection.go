@@ -0,0 +1,100 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+type connectionRequest struct {
+	VPN           string   `json:"vpn"`
+	TCP           bool     `json:"tcp"`
+	CustomPort    uint16   `json:"custom_port,omitempty"`
+	Countries     []string `json:"countries,omitempty"`
+	Regions       []string `json:"regions,omitempty"`
+	Cities        []string `json:"cities,omitempty"`
+	ISPs          []string `json:"isps,omitempty"`
+	Hostnames     []string `json:"hostnames,omitempty"`
+	Names         []string `json:"names,omitempty"`
+	Numbers       []uint16 `json:"numbers,omitempty"`
+	OwnedOnly     bool     `json:"owned_only,omitempty"`
+	FreeOnly      bool     `json:"free_only,omitempty"`
+	PremiumOnly   bool     `json:"premium_only,omitempty"`
+	StreamOnly    bool     `json:"stream_only,omitempty"`
+	MultiHopOnly  bool     `json:"multihop_only,omitempty"`
+	IPv6Supported bool     `json:"ipv6_supported"`
+}
+
+type connectionResponse struct {
+	Connection    models.Connection `json:"connection"`
+	OpenVPNConfig []string          `json:"openvpn_config,omitempty"`
+}
+
+// callTimeout is the maximum duration of a plugin call
+// made without a context.
+const callTimeout = time.Minute
+
+func (p *Provider) GetConnection(selection settings.ServerSelection, ipv6Supported bool) (
+	connection models.Connection, err error) {
+	request := connectionRequest{
+		VPN:           selection.VPN,
+		TCP:           *selection.OpenVPN.TCP,
+		Countries:     selection.Countries,
+		Regions:       selection.Regions,
+		Cities:        selection.Cities,
+		ISPs:          selection.ISPs,
+		Hostnames:     selection.Hostnames,
+		Names:         selection.Names,
+		Numbers:       selection.Numbers,
+		OwnedOnly:     *selection.OwnedOnly,
+		FreeOnly:      *selection.FreeOnly,
+		PremiumOnly:   *selection.PremiumOnly,
+		StreamOnly:    *selection.StreamOnly,
+		MultiHopOnly:  *selection.MultiHopOnly,
+		IPv6Supported: ipv6Supported,
+	}
+	if selection.VPN == vpn.OpenVPN {
+		request.CustomPort = *selection.OpenVPN.CustomPort
+	} else {
+		request.CustomPort = *selection.Wireguard.EndpointPort
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	var response connectionResponse
+	err = p.call(ctx, "get-connection", request, &response)
+	if err != nil {
+		return connection, err
+	}
+
+	err = validateConnection(response, selection.VPN)
+	if err != nil {
+		return connection, err
+	}
+
+	p.openvpnConfigMutex.Lock()
+	p.openvpnConfig = response.OpenVPNConfig
+	p.openvpnConfigMutex.Unlock()
+
+	return response.Connection, nil
+}
+
+func validateConnection(response connectionResponse, vpnType string) (err error) {
+	connection := response.Connection
+	switch {
+	case connection.Type != vpnType:
+		return fmt.Errorf("%w: connection type %q does not match VPN type %q",
+			ErrResponseNotValid, connection.Type, vpnType)
+	case connection.IP == nil:
+		return fmt.Errorf("%w: connection IP address is not set", ErrResponseNotValid)
+	case connection.Port == 0:
+		return fmt.Errorf("%w: connection port is not set", ErrResponseNotValid)
+	case connection.Type == vpn.OpenVPN && len(response.OpenVPNConfig) == 0:
+		return fmt.Errorf("%w: OpenVPN configuration is empty", ErrResponseNotValid)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package plugin
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePlugin(t *testing.T, script string) (path string) {
+	t.Helper()
+	path = filepath.Join(t.TempDir(), "plugin")
+	err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0700) //nolint:gosec
+	require.NoError(t, err)
+	return path
+}
+
+func Test_Provider_GetConnection(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		script        string
+		connection    models.Connection
+		openvpnConfig []string
+		errWrap       error
+		errMessage    string
+	}{
+		"plugin failure": {
+			script:     `echo "no server matching filters" >&2; exit 1`,
+			errWrap:    ErrPluginFailed,
+			errMessage: "plugin failed: get-connection: no server matching filters",
+		},
+		"bad response": {
+			script:     `echo garbage`,
+			errMessage: "decoding get-connection response: invalid character 'g' looking for beginning of value",
+		},
+		"missing OpenVPN configuration": {
+			script:     `echo '{"connection":{"type":"openvpn","ip":"1.2.3.4","port":1194,"protocol":"udp"}}'`,
+			errWrap:    ErrResponseNotValid,
+			errMessage: "plugin response is not valid: OpenVPN configuration is empty",
+		},
+		"success": {
+			script: `read -r request
+case "$request" in
+*'"countries":["canada"]'*) ;;
+*) echo "unexpected request $request" >&2; exit 1 ;;
+esac
+echo '{"connection":{"type":"openvpn","ip":"1.2.3.4","port":1194,"protocol":"udp"},"openvpn_config":["client"]}'`,
+			connection: models.Connection{
+				Type:     vpn.OpenVPN,
+				IP:       net.IPv4(1, 2, 3, 4),
+				Port:     1194,
+				Protocol: constants.UDP,
+			},
+			openvpnConfig: []string{"client"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			provider := New(writePlugin(t, testCase.script))
+			selection := settings.ServerSelection{
+				VPN:       vpn.OpenVPN,
+				Countries: []string{"canada"},
+			}.WithDefaults(provider.Name())
+
+			connection, err := provider.GetConnection(selection, false)
+
+			if testCase.errWrap != nil {
+				assert.ErrorIs(t, err, testCase.errWrap)
+			}
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.connection.IP.To4(), connection.IP.To4())
+			assert.Equal(t, testCase.connection.Port, connection.Port)
+			assert.Equal(t, testCase.openvpnConfig, provider.openvpnConfig)
+		})
+	}
+}
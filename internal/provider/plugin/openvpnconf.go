@@ -0,0 +1,16 @@
+package plugin
+
+import (
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/provider/custom"
+)
+
+// OpenVPNConfig modifies the OpenVPN configuration returned by the
+// plugin with the last connection, using the settings given.
+func (p *Provider) OpenVPNConfig(connection models.Connection,
+	settings settings.OpenVPN, ipv6Supported bool) (lines []string, err error) {
+	p.openvpnConfigMutex.RLock()
+	defer p.openvpnConfigMutex.RUnlock()
+	return custom.ModifyConfig(p.openvpnConfig, connection, settings, ipv6Supported), nil
+}
@@ -0,0 +1,60 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/provider/utils"
+)
+
+type portForwardRequest struct {
+	Gateway    net.IP `json:"gateway"`
+	ServerName string `json:"server_name"`
+}
+
+type portForwardResponse struct {
+	Port  uint16   `json:"port,omitempty"`
+	Ports []uint16 `json:"ports,omitempty"`
+}
+
+// PortForward obtains a port forwarded on the VPN server from the plugin.
+func (p *Provider) PortForward(ctx context.Context, objects utils.PortForwardObjects) (
+	ports []uint16, err error) {
+	request := portForwardRequest{
+		Gateway:    objects.Gateway,
+		ServerName: objects.ServerName,
+	}
+	var response portForwardResponse
+	err = p.call(ctx, "port-forward", request, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	ports = response.Ports
+	if response.Port != 0 {
+		ports = append([]uint16{response.Port}, ports...)
+	}
+	if len(ports) == 0 {
+		objects.Logger.Error("The plugin did not forward any port")
+	}
+	return ports, nil
+}
+
+var ErrKeepPortForwardExited = errors.New("plugin stopped keeping the port forwarded")
+
+// KeepPortForward runs the plugin to keep the port forwarded
+// until the context is canceled or the plugin fails.
+func (p *Provider) KeepPortForward(ctx context.Context,
+	objects utils.PortForwardObjects) (err error) {
+	request := portForwardRequest{
+		Gateway:    objects.Gateway,
+		ServerName: objects.ServerName,
+	}
+	err = p.call(ctx, "keep-port-forward", request, nil)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w", ErrKeepPortForwardExited)
+}
@@ -0,0 +1,41 @@
+// Package plugin implements a VPN provider delegating its work to
+// an out-of-tree plugin executable, so third parties can ship
+// provider integrations without recompiling gluetun.
+//
+// The plugin executable is run with the method name as its only
+// argument, receives its JSON encoded request on its standard input
+// and writes its JSON encoded response on its standard output.
+// It must exit with a non-zero code on failure, in which case its
+// standard error is used as error message. The methods are:
+//
+//   - get-connection: picks a server and returns the connection to it,
+//     and its OpenVPN configuration if the VPN type is OpenVPN.
+//   - fetch-servers: returns the servers of the provider.
+//   - port-forward: obtains a port forwarded on the VPN server.
+//   - keep-port-forward: keeps the port forwarded until it is
+//     terminated, and must only exit if the port forwarding fails.
+package plugin
+
+import (
+	"sync"
+
+	"github.com/qdm12/gluetun/internal/constants/providers"
+)
+
+type Provider struct {
+	path string
+	// openvpnConfig is the OpenVPN configuration returned by the
+	// plugin alongside the last connection it picked.
+	openvpnConfig      []string
+	openvpnConfigMutex sync.RWMutex
+}
+
+func New(path string) *Provider {
+	return &Provider{
+		path: path,
+	}
+}
+
+func (p *Provider) Name() string {
+	return providers.Plugin
+}
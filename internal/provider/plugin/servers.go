@@ -0,0 +1,33 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/provider/common"
+)
+
+type serversRequest struct {
+	MinServers int `json:"min_servers"`
+}
+
+type serversResponse struct {
+	Servers []models.Server `json:"servers"`
+}
+
+func (p *Provider) FetchServers(ctx context.Context, minServers int) (
+	servers []models.Server, err error) {
+	var response serversResponse
+	err = p.call(ctx, "fetch-servers", serversRequest{MinServers: minServers}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Servers) < minServers {
+		return nil, fmt.Errorf("%w: %d and expected at least %d",
+			common.ErrNotEnoughServers, len(response.Servers), minServers)
+	}
+
+	return response.Servers, nil
+}
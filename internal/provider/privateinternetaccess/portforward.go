@@ -27,33 +27,33 @@ var (
 )
 
 // PortForward obtains a VPN server side port forwarded from PIA.
-func (p *Provider) PortForward(ctx context.Context, client *http.Client,
-	logger utils.Logger, gateway net.IP, serverName string) (
-	port uint16, err error) {
+func (p *Provider) PortForward(ctx context.Context, objects utils.PortForwardObjects) (
+	ports []uint16, err error) {
+	logger, gateway, serverName := objects.Logger, objects.Gateway, objects.ServerName
 	server, ok := p.storage.GetServerByName(providers.PrivateInternetAccess, serverName)
 	if !ok {
-		return 0, fmt.Errorf("%w: %s", ErrServerNameNotFound, serverName)
+		return nil, fmt.Errorf("%w: %s", ErrServerNameNotFound, serverName)
 	}
 
 	if !server.PortForward {
 		logger.Error("The server " + serverName +
 			" (region " + server.Region + ") does not support port forwarding")
-		return 0, nil
+		return nil, nil
 	}
 	if gateway == nil {
-		return 0, ErrGatewayIPIsNil
+		return nil, ErrGatewayIPIsNil
 	} else if serverName == "" {
-		return 0, ErrServerNameEmpty
+		return nil, ErrServerNameEmpty
 	}
 
 	privateIPClient, err := newHTTPClient(serverName)
 	if err != nil {
-		return 0, fmt.Errorf("creating custom HTTP client: %w", err)
+		return nil, fmt.Errorf("creating custom HTTP client: %w", err)
 	}
 
 	data, err := readPIAPortForwardData(p.portForwardPath)
 	if err != nil {
-		return 0, fmt.Errorf("reading saved port forwarded data: %w", err)
+		return nil, fmt.Errorf("reading saved port forwarded data: %w", err)
 	}
 
 	dataFound := data.Port > 0
@@ -69,10 +69,10 @@ func (p *Provider) PortForward(ctx context.Context, client *http.Client,
 	}
 
 	if !dataFound || expired {
-		data, err = refreshPIAPortForwardData(ctx, client, privateIPClient, gateway,
-			p.portForwardPath, p.authFilePath)
+		data, err = refreshPIAPortForwardData(ctx, objects.Client, privateIPClient, gateway,
+			p.portForwardPath, p.authFilePath, objects.Tokens, p.timeNow())
 		if err != nil {
-			return 0, fmt.Errorf("refreshing port forward data: %w", err)
+			return nil, fmt.Errorf("refreshing port forward data: %w", err)
 		}
 		durationToExpiration = data.Expiration.Sub(p.timeNow())
 	}
@@ -80,10 +80,10 @@ func (p *Provider) PortForward(ctx context.Context, client *http.Client,
 
 	// First time binding
 	if err := bindPort(ctx, privateIPClient, gateway, data); err != nil {
-		return 0, fmt.Errorf("binding port: %w", err)
+		return nil, fmt.Errorf("binding port: %w", err)
 	}
 
-	return data.Port, nil
+	return []uint16{data.Port}, nil
 }
 
 var (
@@ -91,8 +91,9 @@ var (
 )
 
 func (p *Provider) KeepPortForward(ctx context.Context,
-	gateway net.IP, serverName string) (err error) {
-	privateIPClient, err := newHTTPClient(serverName)
+	objects utils.PortForwardObjects) (err error) {
+	gateway := objects.Gateway
+	privateIPClient, err := newHTTPClient(objects.ServerName)
 	if err != nil {
 		return fmt.Errorf("creating custom HTTP client: %w", err)
 	}
@@ -123,6 +124,7 @@ func (p *Provider) KeepPortForward(ctx context.Context,
 			if err != nil {
 				return fmt.Errorf("binding port: %w", err)
 			}
+			objects.Renewed()
 			keepAliveTimer.Reset(keepAlivePeriod)
 		case <-expiryTimer.C:
 			return fmt.Errorf("%w: on %s", ErrPortForwardedExpired,
@@ -132,14 +134,21 @@ func (p *Provider) KeepPortForward(ctx context.Context,
 }
 
 func refreshPIAPortForwardData(ctx context.Context, client, privateIPClient *http.Client,
-	gateway net.IP, portForwardPath, authFilePath string) (data piaPortForwardData, err error) {
-	data.Token, err = fetchToken(ctx, client, authFilePath)
+	gateway net.IP, portForwardPath, authFilePath string, tokens utils.TokenStore,
+	now time.Time) (data piaPortForwardData, err error) {
+	var restored bool
+	data.Token, restored, err = getToken(ctx, client, authFilePath, tokens, now)
 	if err != nil {
 		return data, fmt.Errorf("fetching token: %w", err)
 	}
 
 	data.Port, data.Signature, data.Expiration, err = fetchPortForwardData(ctx, privateIPClient, gateway, data.Token)
 	if err != nil {
+		if restored {
+			// The token persisted may no longer be valid,
+			// so fetch a new one on the next try.
+			tokens.SetToken(tokenName, "", time.Time{})
+		}
 		return data, fmt.Errorf("fetching port forwarding data: %w", err)
 	}
 
@@ -232,6 +241,34 @@ var (
 	errEmptyToken = errors.New("token received is empty")
 )
 
+// tokenName is the name of the PIA token in the token store.
+const tokenName = "pia"
+
+// getToken returns the token persisted in the token store if it is not
+// expired, and otherwise fetches a new token and persists it. The token
+// store can be nil, in which case a new token is always fetched.
+func getToken(ctx context.Context, client *http.Client, authFilePath string,
+	tokens utils.TokenStore, now time.Time) (token string, restored bool, err error) {
+	if tokens != nil {
+		token, ok := tokens.GetToken(tokenName)
+		if ok {
+			return token, true, nil
+		}
+	}
+
+	token, err = fetchToken(ctx, client, authFilePath)
+	if err != nil {
+		return "", false, err
+	}
+
+	if tokens != nil {
+		// PIA tokens are valid for 24 hours, keep a margin.
+		const tokenLifetime = 23 * time.Hour
+		tokens.SetToken(tokenName, token, now.Add(tokenLifetime))
+	}
+	return token, false, nil
+}
+
 func fetchToken(ctx context.Context, client *http.Client,
 	authFilePath string) (token string, err error) {
 	username, password, err := getOpenvpnCredentials(authFilePath)
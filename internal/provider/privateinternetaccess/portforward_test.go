@@ -1,9 +1,12 @@
 package privateinternetaccess
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"net/http"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -105,3 +108,35 @@ func Test_replaceInString(t *testing.T) {
 		})
 	}
 }
+
+type mapTokenStore map[string]string
+
+func (m mapTokenStore) GetToken(name string) (token string, ok bool) {
+	token, ok = m[name]
+	return token, ok
+}
+
+func (m mapTokenStore) SetToken(name, token string, _ time.Time) {
+	m[name] = token
+}
+
+func Test_getToken(t *testing.T) {
+	t.Parallel()
+
+	missingAuthFile := filepath.Join(t.TempDir(), "auth.conf")
+	now := time.Unix(1000, 0)
+
+	tokens := mapTokenStore{tokenName: "persisted"}
+	token, restored, err := getToken(context.Background(), http.DefaultClient,
+		missingAuthFile, tokens, now)
+	require.NoError(t, err)
+	assert.True(t, restored)
+	assert.Equal(t, "persisted", token)
+
+	tokens = mapTokenStore{}
+	_, restored, err = getToken(context.Background(), http.DefaultClient,
+		missingAuthFile, tokens, now)
+	assert.Error(t, err)
+	assert.False(t, restored)
+	assert.Empty(t, tokens)
+}
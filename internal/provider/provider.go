@@ -2,8 +2,6 @@ package provider
 
 import (
 	"context"
-	"net"
-	"net/http"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/models"
@@ -13,7 +11,8 @@ import (
 // Provider contains methods to read and modify the openvpn configuration to connect as a client.
 type Provider interface {
 	GetConnection(selection settings.ServerSelection, ipv6Supported bool) (connection models.Connection, err error)
-	OpenVPNConfig(connection models.Connection, settings settings.OpenVPN, ipv6Supported bool) (lines []string)
+	OpenVPNConfig(connection models.Connection, settings settings.OpenVPN,
+		ipv6Supported bool) (lines []string, err error)
 	Name() string
 	PortForwarder
 	FetchServers(ctx context.Context, minServers int) (
@@ -21,9 +20,7 @@ type Provider interface {
 }
 
 type PortForwarder interface {
-	PortForward(ctx context.Context, client *http.Client,
-		logger utils.Logger, gateway net.IP, serverName string) (
-		port uint16, err error)
-	KeepPortForward(ctx context.Context, gateway net.IP,
-		serverName string) (err error)
+	PortForward(ctx context.Context, objects utils.PortForwardObjects) (
+		ports []uint16, err error)
+	KeepPortForward(ctx context.Context, objects utils.PortForwardObjects) (err error)
 }
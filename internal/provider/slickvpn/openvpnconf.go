@@ -8,7 +8,7 @@ import (
 )
 
 func (p *Provider) OpenVPNConfig(connection models.Connection,
-	settings settings.OpenVPN, ipv6Supported bool) (lines []string) {
+	settings settings.OpenVPN, ipv6Supported bool) (lines []string, err error) {
 	const pingSeconds = 10
 	const bufSize = 393216
 	providerSettings := utils.OpenVPNProviderSettings{
@@ -36,5 +36,5 @@ func (p *Provider) OpenVPNConfig(connection models.Connection,
 		providerSettings.TLSCipher = "DEFAULT:@SECLEVEL=0"
 	}
 
-	return utils.OpenVPNConfig(providerSettings, connection, settings, ipv6Supported)
+	return utils.OpenVPNConfig(providerSettings, connection, settings, ipv6Supported), nil
 }
@@ -0,0 +1,13 @@
+package userdefined
+
+import (
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/provider/custom"
+)
+
+func (p *Provider) OpenVPNConfig(connection models.Connection,
+	settings settings.OpenVPN, ipv6Supported bool) (lines []string, err error) {
+	return custom.ModifyConfig(p.definition.OpenVPN.Template,
+		connection, settings, ipv6Supported), nil
+}
@@ -0,0 +1,33 @@
+package userdefined
+
+import (
+	"math/rand"
+
+	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/provider/common"
+	"github.com/qdm12/gluetun/internal/provider/utils"
+)
+
+type Provider struct {
+	storage    common.Storage
+	randSource rand.Source
+	definition models.ProviderDefinition
+	utils.NoPortForwarder
+	common.Fetcher
+}
+
+func New(storage common.Storage, randSource rand.Source,
+	definition models.ProviderDefinition) *Provider {
+	return &Provider{
+		storage:         storage,
+		randSource:      randSource,
+		definition:      definition,
+		NoPortForwarder: utils.NewNoPortForwarding(providers.UserDefined),
+		Fetcher:         utils.NewNoFetcher(providers.UserDefined),
+	}
+}
+
+func (p *Provider) Name() string {
+	return providers.UserDefined
+}
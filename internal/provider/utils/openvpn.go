@@ -221,6 +221,8 @@ func OpenVPNConfig(provider OpenVPNProviderSettings,
 		lines.addLines(WrapOpenvpnKey(*settings.Key))
 	}
 
+	lines.addLines(PKCS11Lines(settings))
+
 	lines.addLines(provider.ExtraLines)
 
 	// Add a trailing empty line
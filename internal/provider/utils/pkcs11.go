@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// PKCS11Lines returns the OpenVPN configuration lines to use the
+// client certificate and private key from a PKCS#11 token, or nil
+// if PKCS#11 is not configured.
+func PKCS11Lines(settings settings.OpenVPN) (lines []string) {
+	if *settings.PKCS11Provider == "" {
+		return nil
+	}
+	// The ID is single quoted since serialized IDs can contain
+	// spaces, and single quoted strings are not unescaped by OpenVPN.
+	return []string{
+		"pkcs11-providers " + *settings.PKCS11Provider,
+		"pkcs11-id '" + *settings.PKCS11ID + "'",
+	}
+}
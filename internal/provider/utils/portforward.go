@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// PortForwardObjects contains the objects and data needed to
+// obtain and keep a port forwarded on the VPN server.
+type PortForwardObjects struct {
+	// Logger is a logger to log messages.
+	Logger Logger
+	// Client is the HTTP client to use to reach the
+	// port forwarding API of the VPN provider.
+	Client *http.Client
+	// Gateway is the VPN local gateway IP address, used by PIA.
+	Gateway net.IP
+	// ServerName is the name of the VPN server connected to, used by PIA.
+	ServerName string
+	// APIKey is the API key of the VPN provider account,
+	// used by OVPN and AirVPN.
+	APIKey string
+	// OnRenewal, if not nil, is called each time the ports
+	// forwarded are renewed in KeepPortForward.
+	OnRenewal func()
+	// Tokens, if not nil, persists authentication tokens
+	// across restarts, used by PIA.
+	Tokens TokenStore
+}
+
+// TokenStore persists authentication tokens by name.
+type TokenStore interface {
+	// GetToken returns the token of the name given and true
+	// if it is persisted and not expired.
+	GetToken(name string) (token string, ok bool)
+	// SetToken persists the token of the name given until its
+	// expiration time. An empty token removes the token persisted.
+	SetToken(name, token string, expiration time.Time)
+}
+
+// Renewed signals the ports forwarded got renewed.
+func (o PortForwardObjects) Renewed() {
+	if o.OnRenewal != nil {
+		o.OnRenewal()
+	}
+}
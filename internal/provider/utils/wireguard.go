@@ -39,5 +39,27 @@ func BuildWireguardSettings(connection models.Connection,
 		settings.Addresses = append(settings.Addresses, addressCopy)
 	}
 
+	settings.PersistentKeepaliveInterval = *userSettings.PersistentKeepaliveInterval
+	settings.EndpointResolvePeriod = *userSettings.EndpointResolvePeriod
+
+	for _, userPeer := range userSettings.Peers {
+		peer := wireguard.Peer{
+			PublicKey:                   userPeer.PublicKey,
+			PreSharedKey:                userPeer.PreSharedKey,
+			EndpointHostname:            userPeer.EndpointHostname,
+			PersistentKeepaliveInterval: userPeer.PersistentKeepaliveInterval,
+			AllowedIPs:                  make([]net.IPNet, len(userPeer.AllowedIPs)),
+		}
+		if userPeer.Endpoint != nil {
+			peer.Endpoint = &net.UDPAddr{
+				IP:   make(net.IP, len(userPeer.Endpoint.IP)),
+				Port: userPeer.Endpoint.Port,
+			}
+			copy(peer.Endpoint.IP, userPeer.Endpoint.IP)
+		}
+		copy(peer.AllowedIPs, userPeer.AllowedIPs)
+		settings.Peers = append(settings.Peers, peer)
+	}
+
 	return settings
 }
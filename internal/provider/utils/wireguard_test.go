@@ -3,6 +3,7 @@ package utils
 import (
 	"net"
 	"testing"
+	"time"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/models"
@@ -12,6 +13,8 @@ import (
 
 func stringPtr(s string) *string { return &s }
 
+func durationPtr(d time.Duration) *time.Duration { return &d }
+
 func Test_BuildWireguardSettings(t *testing.T) {
 	t.Parallel()
 
@@ -34,7 +37,19 @@ func Test_BuildWireguardSettings(t *testing.T) {
 					{IP: net.IPv4(1, 1, 1, 1), Mask: net.IPv4Mask(255, 255, 255, 255)},
 					{IP: net.IPv6zero, Mask: net.IPv4Mask(255, 255, 255, 255)},
 				},
-				Interface: "wg1",
+				Interface:                   "wg1",
+				PersistentKeepaliveInterval: durationPtr(25 * time.Second),
+				Peers: []settings.WireguardPeer{{
+					PublicKey:  "peer",
+					Endpoint:   &net.UDPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 51820},
+					AllowedIPs: []net.IPNet{{IP: net.IPv4(192, 168, 1, 0), Mask: net.IPv4Mask(255, 255, 255, 0)}},
+				}, {
+					PublicKey:        "roaming",
+					Endpoint:         &net.UDPAddr{Port: 51822},
+					EndpointHostname: "peer.example.com",
+					AllowedIPs:       []net.IPNet{{IP: net.IPv4(192, 168, 2, 0), Mask: net.IPv4Mask(255, 255, 255, 0)}},
+				}},
+				EndpointResolvePeriod: durationPtr(time.Minute),
 			},
 			ipv6Supported: false,
 			settings: wireguard.Settings{
@@ -49,8 +64,20 @@ func Test_BuildWireguardSettings(t *testing.T) {
 				Addresses: []*net.IPNet{
 					{IP: net.IPv4(1, 1, 1, 1), Mask: net.IPv4Mask(255, 255, 255, 255)},
 				},
-				RulePriority: 101,
-				IPv6:         boolPtr(false),
+				RulePriority:                101,
+				IPv6:                        boolPtr(false),
+				PersistentKeepaliveInterval: 25 * time.Second,
+				Peers: []wireguard.Peer{{
+					PublicKey:  "peer",
+					Endpoint:   &net.UDPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 51820},
+					AllowedIPs: []net.IPNet{{IP: net.IPv4(192, 168, 1, 0), Mask: net.IPv4Mask(255, 255, 255, 0)}},
+				}, {
+					PublicKey:        "roaming",
+					Endpoint:         &net.UDPAddr{IP: net.IP{}, Port: 51822},
+					EndpointHostname: "peer.example.com",
+					AllowedIPs:       []net.IPNet{{IP: net.IPv4(192, 168, 2, 0), Mask: net.IPv4Mask(255, 255, 255, 0)}},
+				}},
+				EndpointResolvePeriod: time.Minute,
 			},
 		},
 	}
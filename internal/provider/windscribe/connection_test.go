@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/qdm12/gluetun/internal/configuration/settings"
@@ -102,7 +103,7 @@ func Test_Provider_GetConnection(t *testing.T) {
 
 			client := (*http.Client)(nil)
 			warner := (common.Warner)(nil)
-			provider := New(storage, randSource, client, warner)
+			provider := New(storage, randSource, time.Now, client, warner)
 
 			if testCase.panicMessage != "" {
 				assert.PanicsWithValue(t, testCase.panicMessage, func() {
@@ -0,0 +1,189 @@
+package windscribe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/provider/common"
+	"github.com/qdm12/gluetun/internal/provider/utils"
+)
+
+const apiURL = "https://windscribe.com/staticips"
+
+const (
+	// ephemeralPortLifetime is the duration an ephemeral port
+	// stays forwarded after it is requested.
+	ephemeralPortLifetime = 7 * 24 * time.Hour
+	// expirationMargin is the duration before the expiration
+	// of an ephemeral port at which it gets renewed.
+	expirationMargin = time.Hour
+)
+
+var (
+	ErrRequestNotSuccessful = errors.New("request is not successful")
+	ErrPortNotValid         = errors.New("port is not valid")
+	ErrPortForwardedExpired = errors.New("port forwarded data expired")
+	ErrPortForwardedChanged = errors.New("port forwarded changed")
+)
+
+type ephemeralPort struct {
+	Port       uint16
+	Expiration time.Time
+}
+
+// PortForward obtains an ephemeral port forwarded from Windscribe, using
+// the account session authentication hash. The ephemeral port currently
+// forwarded is re-used if it is not expired, otherwise it is deleted and
+// a new ephemeral port is requested.
+func (p *Provider) PortForward(ctx context.Context,
+	objects utils.PortForwardObjects) (ports []uint16, err error) {
+	current, err := p.getEphemeralPort(ctx, objects.Client, objects.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("getting ephemeral port: %w", err)
+	}
+
+	if current.Port > 0 && current.Expiration.Sub(p.timeNow()) > expirationMargin {
+		objects.Logger.Info(fmt.Sprintf("using existing ephemeral port %d expiring on %s",
+			current.Port, current.Expiration.Format(time.RFC1123)))
+		return []uint16{current.Port}, nil
+	}
+
+	if current.Port > 0 {
+		err = p.deleteEphemeralPort(ctx, objects.Client, objects.APIKey)
+		if err != nil {
+			return nil, fmt.Errorf("deleting expiring ephemeral port: %w", err)
+		}
+	}
+
+	requested, err := p.requestEphemeralPort(ctx, objects.Client, objects.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("requesting ephemeral port: %w", err)
+	} else if requested.Port == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrPortNotValid, requested.Port)
+	}
+
+	objects.Logger.Info(fmt.Sprintf("ephemeral port %d expires on %s",
+		requested.Port, requested.Expiration.Format(time.RFC1123)))
+	return []uint16{requested.Port}, nil
+}
+
+// KeepPortForward checks periodically the ephemeral port is still
+// forwarded, and returns an error once it expires so a new ephemeral
+// port gets requested.
+func (p *Provider) KeepPortForward(ctx context.Context,
+	objects utils.PortForwardObjects) (err error) {
+	current, err := p.getEphemeralPort(ctx, objects.Client, objects.APIKey)
+	if err != nil {
+		return fmt.Errorf("getting ephemeral port: %w", err)
+	}
+
+	durationToExpiration := current.Expiration.Sub(p.timeNow()) - expirationMargin
+	expiryTimer := time.NewTimer(durationToExpiration)
+	const keepAlivePeriod = 15 * time.Minute
+	// Timer behaving as a ticker
+	keepAliveTimer := time.NewTimer(keepAlivePeriod)
+
+	for {
+		select {
+		case <-ctx.Done():
+			if !keepAliveTimer.Stop() {
+				<-keepAliveTimer.C
+			}
+			if !expiryTimer.Stop() {
+				<-expiryTimer.C
+			}
+			return ctx.Err()
+		case <-keepAliveTimer.C:
+			latest, err := p.getEphemeralPort(ctx, objects.Client, objects.APIKey)
+			if err != nil {
+				return fmt.Errorf("getting ephemeral port: %w", err)
+			} else if latest.Port != current.Port {
+				return fmt.Errorf("%w: from %d to %d", ErrPortForwardedChanged,
+					current.Port, latest.Port)
+			}
+			keepAliveTimer.Reset(keepAlivePeriod)
+		case <-expiryTimer.C:
+			return fmt.Errorf("%w: on %s", ErrPortForwardedExpired,
+				current.Expiration.Format(time.RFC1123))
+		}
+	}
+}
+
+type apiEphemeralPortResponse struct {
+	Success int    `json:"success"`
+	Message string `json:"message"`
+	EPF     struct {
+		External       uint16 `json:"ext"`
+		Internal       uint16 `json:"int"`
+		StartTimestamp int64  `json:"start_ts"`
+	} `json:"epf"`
+}
+
+func (p *Provider) getEphemeralPort(ctx context.Context, client *http.Client,
+	sessionAuthHash string) (port ephemeralPort, err error) {
+	return p.doEphemeralPort(ctx, client, sessionAuthHash, "getEphPort")
+}
+
+func (p *Provider) requestEphemeralPort(ctx context.Context, client *http.Client,
+	sessionAuthHash string) (port ephemeralPort, err error) {
+	return p.doEphemeralPort(ctx, client, sessionAuthHash, "postEphPort")
+}
+
+func (p *Provider) deleteEphemeralPort(ctx context.Context, client *http.Client,
+	sessionAuthHash string) (err error) {
+	_, err = p.doEphemeralPort(ctx, client, sessionAuthHash, "deleteEphPort")
+	return err
+}
+
+func (p *Provider) doEphemeralPort(ctx context.Context, client *http.Client,
+	sessionAuthHash, action string) (port ephemeralPort, err error) {
+	// Leave the port empty to have the internal port match the external port.
+	form := url.Values{"port": []string{""}}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		p.apiURL+"/"+action, strings.NewReader(form.Encode()))
+	if err != nil {
+		return port, fmt.Errorf("creating HTTP request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.AddCookie(&http.Cookie{Name: "ws_session_auth_hash", Value: sessionAuthHash})
+
+	response, err := client.Do(request)
+	if err != nil {
+		return port, fmt.Errorf("doing HTTP request: %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		_ = response.Body.Close()
+		return port, fmt.Errorf("%w: %d %s", common.ErrHTTPStatusCodeNotOK,
+			response.StatusCode, response.Status)
+	}
+
+	var data apiEphemeralPortResponse
+	decoder := json.NewDecoder(response.Body)
+	err = decoder.Decode(&data)
+	if err != nil {
+		_ = response.Body.Close()
+		return port, fmt.Errorf("decoding response body: %w", err)
+	}
+
+	err = response.Body.Close()
+	if err != nil {
+		return port, fmt.Errorf("closing response body: %w", err)
+	}
+
+	if data.Success != 1 {
+		return port, fmt.Errorf("%w: %s", ErrRequestNotSuccessful, data.Message)
+	}
+
+	port.Port = data.EPF.External
+	if port.Port > 0 {
+		port.Expiration = time.Unix(data.EPF.StartTimestamp, 0).Add(ephemeralPortLifetime)
+	}
+	return port, nil
+}
@@ -0,0 +1,124 @@
+package windscribe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/provider/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Provider_PortForward(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	// expiration returns the expiration time of an ephemeral port
+	// started at the Unix timestamp given, formatted as logged.
+	expiration := func(startTimestamp int64) string {
+		return time.Unix(startTimestamp, 0).Add(ephemeralPortLifetime).Format(time.RFC1123)
+	}
+
+	testCases := map[string]struct {
+		responses  map[string]string
+		ports      []uint16
+		errWrap    error
+		errMessage string
+		actions    []string
+		expectLogs func(logger *MockLogger)
+	}{
+		"session not valid": {
+			responses: map[string]string{
+				"getEphPort": `{"success":0,"message":"Please login"}`,
+			},
+			errWrap:    ErrRequestNotSuccessful,
+			errMessage: "getting ephemeral port: request is not successful: Please login",
+			actions:    []string{"getEphPort"},
+		},
+		"existing port": {
+			responses: map[string]string{
+				"getEphPort": `{"success":1,"epf":{"ext":12345,"int":12345,"start_ts":1699990000}}`,
+			},
+			ports:   []uint16{12345},
+			actions: []string{"getEphPort"},
+			expectLogs: func(logger *MockLogger) {
+				logger.EXPECT().Info("using existing ephemeral port 12345 expiring on " +
+					expiration(1699990000))
+			},
+		},
+		"no existing port": {
+			responses: map[string]string{
+				"getEphPort":  `{"success":1}`,
+				"postEphPort": `{"success":1,"epf":{"ext":23456,"int":23456,"start_ts":1700000000}}`,
+			},
+			ports:   []uint16{23456},
+			actions: []string{"getEphPort", "postEphPort"},
+			expectLogs: func(logger *MockLogger) {
+				logger.EXPECT().Info("ephemeral port 23456 expires on " + expiration(1700000000))
+			},
+		},
+		"expiring port renewed": {
+			responses: map[string]string{
+				"getEphPort":    `{"success":1,"epf":{"ext":12345,"int":12345,"start_ts":1699396000}}`,
+				"deleteEphPort": `{"success":1}`,
+				"postEphPort":   `{"success":1,"epf":{"ext":34567,"int":34567,"start_ts":1700000000}}`,
+			},
+			ports:   []uint16{34567},
+			actions: []string{"getEphPort", "deleteEphPort", "postEphPort"},
+			expectLogs: func(logger *MockLogger) {
+				logger.EXPECT().Info("ephemeral port 34567 expires on " + expiration(1700000000))
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			logger := NewMockLogger(ctrl)
+			if testCase.expectLogs != nil {
+				testCase.expectLogs(logger)
+			}
+
+			var actions []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				cookie, err := r.Cookie("ws_session_auth_hash")
+				if assert.NoError(t, err) {
+					assert.Equal(t, "hash", cookie.Value)
+				}
+				action := r.URL.Path[1:]
+				actions = append(actions, action)
+				_, _ = w.Write([]byte(testCase.responses[action]))
+			}))
+			defer server.Close()
+
+			provider := &Provider{
+				timeNow: func() time.Time { return now },
+				apiURL:  server.URL,
+			}
+			objects := utils.PortForwardObjects{
+				Logger: logger,
+				Client: server.Client(),
+				APIKey: "hash",
+			}
+
+			ports, err := provider.PortForward(context.Background(), objects)
+
+			if testCase.errWrap != nil {
+				assert.ErrorIs(t, err, testCase.errWrap)
+			}
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.ports, ports)
+			assert.Equal(t, testCase.actions, actions)
+		})
+	}
+}
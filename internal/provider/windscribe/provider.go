@@ -3,27 +3,31 @@ package windscribe
 import (
 	"math/rand"
 	"net/http"
+	"time"
 
 	"github.com/qdm12/gluetun/internal/constants/providers"
 	"github.com/qdm12/gluetun/internal/provider/common"
-	"github.com/qdm12/gluetun/internal/provider/utils"
 	"github.com/qdm12/gluetun/internal/provider/windscribe/updater"
 )
 
 type Provider struct {
 	storage    common.Storage
 	randSource rand.Source
-	utils.NoPortForwarder
+	timeNow    func() time.Time
 	common.Fetcher
+	// Port forwarding
+	apiURL string
 }
 
 func New(storage common.Storage, randSource rand.Source,
-	client *http.Client, updaterWarner common.Warner) *Provider {
+	timeNow func() time.Time, client *http.Client,
+	updaterWarner common.Warner) *Provider {
 	return &Provider{
-		storage:         storage,
-		randSource:      randSource,
-		NoPortForwarder: utils.NewNoPortForwarding(providers.Windscribe),
-		Fetcher:         updater.New(client, updaterWarner),
+		storage:    storage,
+		randSource: randSource,
+		timeNow:    timeNow,
+		Fetcher:    updater.New(client, updaterWarner),
+		apiURL:     apiURL,
 	}
 }
 
@@ -0,0 +1,129 @@
+package windscribe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrAddressNotValid      = errors.New("address is not valid")
+	ErrPresharedKeyNotFound = errors.New("preshared key not found")
+)
+
+// Provisioning contains the Wireguard settings obtained by registering
+// a Wireguard public key with the Windscribe API.
+type Provisioning struct {
+	PresharedKey string
+	Addresses    []net.IPNet
+}
+
+// ProvisionWireguard registers the Wireguard public key given with the
+// Windscribe account of the session authentication hash given, for the
+// Windscribe Wireguard server hostname given, and returns the preshared
+// key and interface addresses to use with it.
+func ProvisionWireguard(ctx context.Context, client *http.Client,
+	sessionAuthHash, publicKey, hostname string) (
+	provisioning Provisioning, err error) {
+	const baseURL = "https://api.windscribe.com"
+	return provisionWireguard(ctx, client, baseURL, sessionAuthHash, publicKey, hostname)
+}
+
+func provisionWireguard(ctx context.Context, client *http.Client,
+	baseURL, sessionAuthHash, publicKey, hostname string) (
+	provisioning Provisioning, err error) {
+	form := url.Values{
+		"session_auth_hash": []string{sessionAuthHash},
+		"wg_pubkey":         []string{publicKey},
+		"force_init":        []string{"1"},
+	}
+	var initConfig struct {
+		PresharedKey string `json:"PresharedKey"`
+	}
+	err = postWgConfigs(ctx, client, baseURL+"/WgConfigs/init", form, &initConfig)
+	if err != nil {
+		return provisioning, fmt.Errorf("registering public key: %w", err)
+	} else if initConfig.PresharedKey == "" {
+		return provisioning, fmt.Errorf("%w", ErrPresharedKeyNotFound)
+	}
+	provisioning.PresharedKey = initConfig.PresharedKey
+
+	form = url.Values{
+		"session_auth_hash": []string{sessionAuthHash},
+		"wg_pubkey":         []string{publicKey},
+		"hostname":          []string{hostname},
+	}
+	var connectConfig struct {
+		Address string `json:"Address"`
+	}
+	err = postWgConfigs(ctx, client, baseURL+"/WgConfigs/connect", form, &connectConfig)
+	if err != nil {
+		return provisioning, fmt.Errorf("obtaining interface address: %w", err)
+	}
+
+	for _, address := range strings.Split(connectConfig.Address, ",") {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		ip, ipNet, err := net.ParseCIDR(address)
+		if err != nil {
+			return provisioning, fmt.Errorf("%w: %s", ErrAddressNotValid, err)
+		}
+		ipNet.IP = ip
+		provisioning.Addresses = append(provisioning.Addresses, *ipNet)
+	}
+
+	if len(provisioning.Addresses) == 0 {
+		return provisioning, fmt.Errorf("%w: no address assigned", ErrAddressNotValid)
+	}
+
+	return provisioning, nil
+}
+
+func postWgConfigs(ctx context.Context, client *http.Client, endpoint string,
+	form url.Values, config interface{}) (err error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	var data struct {
+		ErrorMessage string `json:"errorMessage"`
+		Data         struct {
+			Success int             `json:"success"`
+			Config  json.RawMessage `json:"config"`
+		} `json:"data"`
+	}
+	decoder := json.NewDecoder(response.Body)
+	err = decoder.Decode(&data)
+	if err != nil {
+		return fmt.Errorf("%w: %d %s: decoding response body: %s",
+			ErrRequestNotSuccessful, response.StatusCode, response.Status, err)
+	}
+
+	if response.StatusCode != http.StatusOK || data.Data.Success != 1 {
+		return fmt.Errorf("%w: %d %s: %s", ErrRequestNotSuccessful,
+			response.StatusCode, response.Status, data.ErrorMessage)
+	}
+
+	err = json.Unmarshal(data.Data.Config, config)
+	if err != nil {
+		return fmt.Errorf("decoding config: %w", err)
+	}
+
+	return response.Body.Close()
+}
@@ -0,0 +1,68 @@
+package windscribe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_provisionWireguard(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseForm()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "hash", r.PostForm.Get("session_auth_hash"))
+		assert.Equal(t, "public", r.PostForm.Get("wg_pubkey"))
+
+		switch r.URL.Path {
+		case "/WgConfigs/init":
+			assert.Equal(t, "1", r.PostForm.Get("force_init"))
+			_, _ = w.Write([]byte(`{"data":{"success":1,"config":` +
+				`{"PresharedKey":"preshared","AllowedIPs":"0.0.0.0/0"}}}`))
+		case "/WgConfigs/connect":
+			assert.Equal(t, "us-central-001.windscribe.com", r.PostForm.Get("hostname"))
+			_, _ = w.Write([]byte(`{"data":{"success":1,"config":` +
+				`{"Address":"100.64.1.2/32","DNS":"10.255.255.1"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	provisioning, err := provisionWireguard(context.Background(), server.Client(),
+		server.URL, "hash", "public", "us-central-001.windscribe.com")
+
+	require.NoError(t, err)
+	expected := Provisioning{
+		PresharedKey: "preshared",
+		Addresses: []net.IPNet{
+			{IP: net.ParseIP("100.64.1.2"), Mask: net.CIDRMask(32, 32)},
+		},
+	}
+	assert.Equal(t, expected, provisioning)
+}
+
+func Test_provisionWireguard_badSession(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"errorCode":701,"errorMessage":"Submitted session is invalid"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := provisionWireguard(context.Background(), server.Client(),
+		server.URL, "hash", "public", "us-central-001.windscribe.com")
+
+	assert.ErrorIs(t, err, ErrRequestNotSuccessful)
+	assert.EqualError(t, err, "registering public key: request is not successful: "+
+		"401 401 Unauthorized: Submitted session is invalid")
+}
@@ -0,0 +1,110 @@
+package quota
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/constants"
+)
+
+func (t *Tracker) applyAction(ctx context.Context, vpnInterface string) {
+	switch t.settings.Action {
+	case constants.QuotaActionNotify:
+		err := t.notify(ctx)
+		if err != nil {
+			t.logger.Error("notifying quota exceeded: " + err.Error())
+		}
+	case constants.QuotaActionThrottle:
+		t.throttle(ctx, vpnInterface)
+	case constants.QuotaActionBlock:
+		err := t.firewall.SetVPNOutputPorts(ctx, t.settings.AllowedPorts)
+		if err != nil {
+			t.logger.Error("blocking VPN traffic: " + err.Error())
+		}
+	}
+}
+
+func (t *Tracker) liftAction(ctx context.Context) {
+	switch t.settings.Action {
+	case constants.QuotaActionThrottle:
+		for _, parent := range []string{"root", "ingress"} {
+			cmd := exec.CommandContext(ctx, "tc", "qdisc", "del", "dev", t.lastInterface, parent)
+			_, err := t.runner.Run(cmd)
+			if err != nil {
+				t.logger.Error("removing VPN traffic throttling: " + err.Error())
+			}
+		}
+	case constants.QuotaActionBlock:
+		err := t.firewall.SetVPNOutputPorts(ctx, nil)
+		if err != nil {
+			t.logger.Error("unblocking VPN traffic: " + err.Error())
+		}
+	}
+}
+
+// throttle limits the rate of the traffic sent through the VPN
+// interface using a token bucket filter, and the rate of the traffic
+// received through the VPN interface using an ingress policer, which
+// drops the packets received above the rate.
+func (t *Tracker) throttle(ctx context.Context, vpnInterface string) {
+	rate := *t.settings.ThrottleRate
+	commands := [][]string{
+		{"qdisc", "replace", "dev", vpnInterface, "root",
+			"tbf", "rate", rate, "burst", "32kbit", "latency", "400ms"},
+		{"qdisc", "replace", "dev", vpnInterface, "ingress"},
+		{"filter", "replace", "dev", vpnInterface, "parent", "ffff:",
+			"protocol", "all", "prio", "1", "handle", "1", "matchall",
+			"action", "police", "rate", rate, "burst", "32kbit", "drop"},
+	}
+	for _, args := range commands {
+		cmd := exec.CommandContext(ctx, "tc", args...)
+		_, err := t.runner.Run(cmd)
+		if err != nil {
+			t.logger.Error("throttling VPN traffic: " + err.Error())
+			return
+		}
+	}
+}
+
+var ErrNotificationFailed = errors.New("notification failed")
+
+func (t *Tracker) notify(ctx context.Context) (err error) {
+	body, err := json.Marshal(struct {
+		Message     string    `json:"message"`
+		Bytes       uint64    `json:"bytes"`
+		Limit       uint64    `json:"limit"`
+		PeriodStart time.Time `json:"period_start"`
+	}{
+		Message:     "gluetun traffic quota exceeded",
+		Bytes:       t.usage.Bytes,
+		Limit:       *t.settings.Limit,
+		PeriodStart: t.usage.PeriodStart,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding notification: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		*t.settings.NotifyURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := t.client.Do(request)
+	if err != nil {
+		return err
+	}
+	_ = response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %s", ErrNotificationFailed, response.Status)
+	}
+	return nil
+}
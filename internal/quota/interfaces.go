@@ -0,0 +1,21 @@
+package quota
+
+import (
+	"context"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+type VPNSettingsGetter interface {
+	GetSettings() (settings settings.VPN)
+}
+
+type Firewall interface {
+	SetVPNOutputPorts(ctx context.Context, ports []uint16) (err error)
+}
+
+type Logger interface {
+	Info(s string)
+	Warn(s string)
+	Error(s string)
+}
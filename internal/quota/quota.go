@@ -0,0 +1,207 @@
+// Package quota tracks the VPN tunnel traffic against a traffic
+// quota and takes an action when the quota is exceeded.
+package quota
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/golibs/command"
+)
+
+// Tracker periodically adds the traffic of the VPN interface
+// to the usage of the current quota period.
+type Tracker struct {
+	settings   settings.Quota
+	vpnGetter  VPNSettingsGetter
+	firewall   Firewall
+	runner     command.Runner
+	client     *http.Client
+	logger     Logger
+	period     time.Duration
+	usagePath  string
+	sysNetPath string
+	timeNow    func() time.Time
+
+	// State
+	usage         usage
+	lastInterface string
+	lastCounter   uint64
+	exceeded      bool
+}
+
+// New creates a traffic quota tracker using the settings given.
+func New(settings settings.Quota, vpnGetter VPNSettingsGetter,
+	firewall Firewall, runner command.Runner, client *http.Client,
+	logger Logger) *Tracker {
+	const period = time.Minute
+	return &Tracker{
+		settings:   settings,
+		vpnGetter:  vpnGetter,
+		firewall:   firewall,
+		runner:     runner,
+		client:     client,
+		logger:     logger,
+		period:     period,
+		usagePath:  "/gluetun/quota.json",
+		sysNetPath: "/sys/class/net",
+		timeNow:    time.Now,
+	}
+}
+
+// Run tracks the VPN traffic until the context is canceled.
+// It returns immediately if the quota limit is 0.
+func (t *Tracker) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	if *t.settings.Limit == 0 {
+		return
+	}
+
+	var err error
+	t.usage, err = readUsage(t.usagePath)
+	if err != nil {
+		t.logger.Warn(err.Error())
+	}
+
+	t.check(ctx)
+
+	ticker := time.NewTicker(t.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			t.persist()
+			return
+		case <-ticker.C:
+			t.check(ctx)
+		}
+	}
+}
+
+func (t *Tracker) check(ctx context.Context) {
+	periodStart := getPeriodStart(t.timeNow(), t.settings.Period)
+	if !t.usage.PeriodStart.Equal(periodStart) {
+		if !t.usage.PeriodStart.IsZero() {
+			t.logger.Info(fmt.Sprintf("new quota period started, %s used in the previous period",
+				formatBytes(t.usage.Bytes)))
+		}
+		t.usage = usage{PeriodStart: periodStart}
+		t.persist()
+		if t.exceeded {
+			t.exceeded = false
+			t.liftAction(ctx)
+		}
+	}
+
+	vpnInterface := t.vpnInterface()
+	counter, err := t.readCounter(vpnInterface)
+	if err != nil {
+		// The VPN interface does not exist when the VPN is down,
+		// and its counters restart from 0 once it is recreated.
+		t.lastCounter = 0
+		return
+	}
+
+	delta := counter
+	if vpnInterface == t.lastInterface && counter >= t.lastCounter {
+		delta = counter - t.lastCounter
+	}
+	t.lastInterface = vpnInterface
+	t.lastCounter = counter
+
+	if delta > 0 {
+		t.usage.Bytes += delta
+		t.persist()
+	}
+
+	if t.usage.Bytes < *t.settings.Limit {
+		return
+	}
+
+	if !t.exceeded {
+		t.exceeded = true
+		t.logger.Warn(fmt.Sprintf("traffic quota of %s exceeded with %s used since %s",
+			formatBytes(*t.settings.Limit), formatBytes(t.usage.Bytes),
+			t.usage.PeriodStart.Format("2006-01-02")))
+		t.applyAction(ctx, vpnInterface)
+	} else if t.settings.Action == constants.QuotaActionThrottle {
+		// Throttle again in case the VPN interface was recreated.
+		t.throttle(ctx, vpnInterface)
+	}
+}
+
+func (t *Tracker) vpnInterface() string {
+	vpnSettings := t.vpnGetter.GetSettings()
+	switch vpnSettings.Type {
+	case vpn.Wireguard:
+		return vpnSettings.Wireguard.Interface
+	case vpn.IKEv2:
+		return vpnSettings.IKEv2.Interface
+	case vpn.OpenConnect:
+		return vpnSettings.OpenConnect.Interface
+	default:
+		return vpnSettings.OpenVPN.Interface
+	}
+}
+
+// readCounter returns the sum of the received and
+// sent bytes of the network interface given.
+func (t *Tracker) readCounter(networkInterface string) (counter uint64, err error) {
+	for _, name := range []string{"rx_bytes", "tx_bytes"} {
+		path := filepath.Join(t.sysNetPath, networkInterface, "statistics", name)
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return 0, err
+		}
+		const base, bitSize = 10, 64
+		value, err := strconv.ParseUint(strings.TrimSpace(string(b)), base, bitSize)
+		if err != nil {
+			return 0, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		counter += value
+	}
+	return counter, nil
+}
+
+func (t *Tracker) persist() {
+	err := writeUsage(t.usagePath, t.usage)
+	if err != nil {
+		t.logger.Error(err.Error())
+	}
+}
+
+// getPeriodStart returns the start time of the quota period
+// containing the time given.
+func getPeriodStart(now time.Time, period string) (start time.Time) {
+	year, month, day := now.Date()
+	if period == constants.QuotaWeekly {
+		const daysInWeek = 7
+		daysSinceMonday := (int(now.Weekday()) + daysInWeek - 1) % daysInWeek
+		return time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, now.Location())
+	}
+	return time.Date(year, month, 1, 0, 0, 0, 0, now.Location())
+}
+
+func formatBytes(bytes uint64) string {
+	const unit = 1000
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	divisor, exponent := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		divisor *= unit
+		exponent++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(bytes)/float64(divisor), "kMGTPE"[exponent])
+}
@@ -0,0 +1,189 @@
+package quota
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/golibs/command"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVPNGetter struct{}
+
+func (fakeVPNGetter) GetSettings() (vpn settings.VPN) {
+	vpn.Type = "openvpn"
+	vpn.OpenVPN.Interface = "tun0"
+	return vpn
+}
+
+type fakeFirewall struct {
+	ports [][]uint16
+}
+
+func (f *fakeFirewall) SetVPNOutputPorts(_ context.Context, ports []uint16) error {
+	f.ports = append(f.ports, ports)
+	return nil
+}
+
+type fakeRunner struct {
+	commands []string
+}
+
+func (r *fakeRunner) Run(cmd command.ExecCmd) (output string, err error) {
+	r.commands = append(r.commands, strings.Join(cmd.(*exec.Cmd).Args, " "))
+	return "", nil
+}
+
+func writeCounters(t *testing.T, sysNetPath string, rx, tx string) {
+	t.Helper()
+	dir := filepath.Join(sysNetPath, "tun0", "statistics")
+	require.NoError(t, os.MkdirAll(dir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rx_bytes"), []byte(rx+"\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tx_bytes"), []byte(tx+"\n"), 0600))
+}
+
+func Test_Tracker_check(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	limit := uint64(1000)
+	firewall := &fakeFirewall{}
+	tempDir := t.TempDir()
+	sysNetPath := filepath.Join(tempDir, "net")
+	now := time.Date(2022, time.March, 15, 12, 0, 0, 0, time.UTC)
+	tracker := &Tracker{
+		settings: settings.Quota{
+			Limit:        &limit,
+			Period:       constants.QuotaMonthly,
+			Action:       constants.QuotaActionBlock,
+			AllowedPorts: []uint16{853},
+		},
+		vpnGetter:  fakeVPNGetter{},
+		firewall:   firewall,
+		logger:     logger,
+		usagePath:  filepath.Join(tempDir, "quota.json"),
+		sysNetPath: sysNetPath,
+		timeNow:    func() time.Time { return now },
+	}
+	ctx := context.Background()
+
+	// VPN interface is down
+	tracker.check(ctx)
+	assert.Equal(t, uint64(0), tracker.usage.Bytes)
+
+	writeCounters(t, sysNetPath, "300", "100")
+	tracker.check(ctx)
+	assert.Equal(t, uint64(400), tracker.usage.Bytes)
+
+	// VPN interface recreated with reset counters
+	writeCounters(t, sysNetPath, "200", "0")
+	tracker.check(ctx)
+	assert.Equal(t, uint64(600), tracker.usage.Bytes)
+	assert.Empty(t, firewall.ports)
+
+	writeCounters(t, sysNetPath, "500", "200")
+	logger.EXPECT().Warn("traffic quota of 1.0kB exceeded with 1.1kB used since 2022-03-01")
+	tracker.check(ctx)
+	assert.Equal(t, uint64(1100), tracker.usage.Bytes)
+	assert.Equal(t, [][]uint16{{853}}, firewall.ports)
+
+	persisted, err := readUsage(tracker.usagePath)
+	require.NoError(t, err)
+	assert.Equal(t, tracker.usage, persisted)
+
+	// New period lifts the block
+	now = time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC)
+	logger.EXPECT().Info("new quota period started, 1.1kB used in the previous period")
+	tracker.check(ctx)
+	assert.Equal(t, uint64(0), tracker.usage.Bytes)
+	assert.Equal(t, [][]uint16{{853}, nil}, firewall.ports)
+}
+
+func Test_Tracker_throttle(t *testing.T) {
+	t.Parallel()
+
+	runner := &fakeRunner{}
+	throttleRate := "1mbit"
+	tracker := &Tracker{
+		settings: settings.Quota{
+			Action:       constants.QuotaActionThrottle,
+			ThrottleRate: &throttleRate,
+		},
+		runner:        runner,
+		lastInterface: "tun0",
+	}
+	ctx := context.Background()
+
+	tracker.applyAction(ctx, "tun0")
+	tracker.liftAction(ctx)
+
+	expectedCommands := []string{
+		"tc qdisc replace dev tun0 root tbf rate 1mbit burst 32kbit latency 400ms",
+		"tc qdisc replace dev tun0 ingress",
+		"tc filter replace dev tun0 parent ffff: protocol all prio 1 handle 1 " +
+			"matchall action police rate 1mbit burst 32kbit drop",
+		"tc qdisc del dev tun0 root",
+		"tc qdisc del dev tun0 ingress",
+	}
+	assert.Equal(t, expectedCommands, runner.commands)
+}
+
+func Test_getPeriodStart(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		now    time.Time
+		period string
+		start  time.Time
+	}{
+		"monthly": {
+			now:    time.Date(2022, time.March, 15, 12, 0, 0, 0, time.UTC),
+			period: constants.QuotaMonthly,
+			start:  time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"weekly on sunday": {
+			now:    time.Date(2022, time.March, 20, 12, 0, 0, 0, time.UTC),
+			period: constants.QuotaWeekly,
+			start:  time.Date(2022, time.March, 14, 0, 0, 0, 0, time.UTC),
+		},
+		"weekly on monday": {
+			now:    time.Date(2022, time.March, 14, 0, 0, 0, 0, time.UTC),
+			period: constants.QuotaWeekly,
+			start:  time.Date(2022, time.March, 14, 0, 0, 0, 0, time.UTC),
+		},
+		"weekly across months": {
+			now:    time.Date(2022, time.March, 2, 8, 0, 0, 0, time.UTC),
+			period: constants.QuotaWeekly,
+			start:  time.Date(2022, time.February, 28, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			start := getPeriodStart(testCase.now, testCase.period)
+
+			assert.Equal(t, testCase.start, start)
+		})
+	}
+}
+
+func Test_formatBytes(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "999B", formatBytes(999))
+	assert.Equal(t, "1.5kB", formatBytes(1500))
+	assert.Equal(t, "500.0GB", formatBytes(500e9))
+}
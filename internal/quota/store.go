@@ -0,0 +1,51 @@
+package quota
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// usage is the traffic usage persisted across restarts.
+type usage struct {
+	PeriodStart time.Time `json:"period_start"`
+	Bytes       uint64    `json:"bytes"`
+}
+
+func readUsage(path string) (data usage, err error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return data, nil
+	} else if err != nil {
+		return data, fmt.Errorf("reading quota usage file: %w", err)
+	}
+
+	err = json.Unmarshal(b, &data)
+	if err != nil {
+		return data, fmt.Errorf("decoding quota usage file: %w", err)
+	}
+	return data, nil
+}
+
+func writeUsage(path string, data usage) (err error) {
+	const dirPerms = os.FileMode(0700)
+	err = os.MkdirAll(filepath.Dir(path), dirPerms)
+	if err != nil {
+		return fmt.Errorf("creating quota usage file directory: %w", err)
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encoding quota usage: %w", err)
+	}
+
+	const perms = os.FileMode(0600)
+	err = os.WriteFile(path, b, perms)
+	if err != nil {
+		return fmt.Errorf("writing quota usage file: %w", err)
+	}
+	return nil
+}
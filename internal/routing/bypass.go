@@ -0,0 +1,100 @@
+package routing
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/netlink"
+	"github.com/qdm12/gluetun/internal/subnet"
+)
+
+const (
+	bypassTable    = 197
+	bypassPriority = 96
+)
+
+// SetBypassIPs routes the traffic to the IP addresses given through the
+// default routes instead of the VPN, replacing the previous IP addresses.
+// IP addresses without a default route of their family are ignored.
+func (r *Routing) SetBypassIPs(ips []net.IP) (err error) {
+	defaultRoutes, err := r.DefaultRoutes()
+	if err != nil {
+		return err
+	}
+
+	subnets := make([]net.IPNet, 0, len(ips))
+	for _, ip := range ips {
+		bits := 8 * net.IPv6len //nolint:gomnd
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 8 * net.IPv4len //nolint:gomnd
+		}
+		subnets = append(subnets, net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+
+	r.stateMutex.Lock()
+	defer r.stateMutex.Unlock()
+
+	subnetsToAdd, subnetsToRemove := subnet.FindSubnetsToChange(r.bypassSubnets, subnets)
+
+	const add = true
+	for _, subnetToRemove := range subnetsToRemove {
+		err = r.routeBypassSubnet(subnetToRemove, defaultRoutes, !add)
+		if err != nil {
+			r.logger.Warn("cannot remove outdated bypass IP address from routing: " + err.Error())
+			continue
+		}
+		r.bypassSubnets = subnet.RemoveSubnetFromSubnets(r.bypassSubnets, subnetToRemove)
+	}
+
+	for _, subnetToAdd := range subnetsToAdd {
+		err = r.routeBypassSubnet(subnetToAdd, defaultRoutes, add)
+		if err != nil {
+			return fmt.Errorf("adding bypass IP address to routes: %w", err)
+		}
+		r.bypassSubnets = append(r.bypassSubnets, subnetToAdd)
+	}
+
+	return nil
+}
+
+// routeBypassSubnet adds, or removes if add is false, the route through
+// each default route of the same family and the rule for the subnet given.
+func (r *Routing) routeBypassSubnet(destination net.IPNet,
+	defaultRoutes []DefaultRoute, add bool) (err error) {
+	family := netlink.FAMILY_V6
+	if destination.IP.To4() != nil {
+		family = netlink.FAMILY_V4
+	}
+
+	routed := false
+	for _, defaultRoute := range defaultRoutes {
+		if defaultRoute.Family != family {
+			continue
+		}
+		if add {
+			err = r.addRouteVia(destination, defaultRoute.Gateway, defaultRoute.NetInterface, bypassTable)
+		} else {
+			err = r.deleteRouteVia(destination, defaultRoute.Gateway, defaultRoute.NetInterface, bypassTable)
+		}
+		if err != nil {
+			return err
+		}
+		routed = true
+	}
+
+	if !routed {
+		return nil
+	}
+
+	ruleSrcNet := (*net.IPNet)(nil)
+	if add {
+		err = r.addIPRule(ruleSrcNet, &destination, bypassTable, bypassPriority)
+	} else {
+		err = r.deleteIPRule(ruleSrcNet, &destination, bypassTable, bypassPriority)
+	}
+	if err != nil {
+		return fmt.Errorf("for IP address %s: %w", destination.IP, err)
+	}
+	return nil
+}
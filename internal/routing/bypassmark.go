@@ -0,0 +1,69 @@
+package routing
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/netlink"
+)
+
+const (
+	bypassMarkTable    = 196
+	bypassMarkPriority = 95
+)
+
+// SetBypassMark sets the firewall mark of the traffic to route through
+// the default routes instead of the VPN. A mark of 0 disables it.
+// It must be called before Setup.
+func (r *Routing) SetBypassMark(mark uint32) {
+	r.stateMutex.Lock()
+	defer r.stateMutex.Unlock()
+	r.bypassMark = mark
+}
+
+// routeBypassMark adds, or removes if add is false, the default routes
+// in the bypass mark table and the rules looking up this table for the
+// traffic with the bypass mark, for each IP family of the default routes.
+func (r *Routing) routeBypassMark(defaultRoutes []DefaultRoute, add bool) (err error) {
+	r.stateMutex.RLock()
+	mark := r.bypassMark
+	r.stateMutex.RUnlock()
+	if mark == 0 {
+		return nil
+	}
+
+	for _, defaultRoute := range defaultRoutes {
+		destination := net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 8*net.IPv4len)} //nolint:gomnd
+		if defaultRoute.Family == netlink.FAMILY_V6 {
+			destination = net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 8*net.IPv6len)} //nolint:gomnd
+		}
+
+		if add {
+			err = r.addRouteVia(destination, defaultRoute.Gateway, defaultRoute.NetInterface, bypassMarkTable)
+		} else {
+			err = r.deleteRouteVia(destination, defaultRoute.Gateway, defaultRoute.NetInterface, bypassMarkTable)
+		}
+		if err != nil {
+			return err
+		}
+
+		rule := netlink.NewRule()
+		rule.Family = defaultRoute.Family
+		rule.Mark = int(mark)
+		rule.Table = bypassMarkTable
+		rule.Priority = bypassMarkPriority
+		if add {
+			r.logger.Debug(fmt.Sprintf("ip rule add fwmark 0x%x lookup %d pref %d",
+				mark, bypassMarkTable, bypassMarkPriority))
+			err = r.addRule(rule)
+		} else {
+			r.logger.Debug(fmt.Sprintf("ip rule del fwmark 0x%x lookup %d pref %d",
+				mark, bypassMarkTable, bypassMarkPriority))
+			err = r.deleteRule(rule)
+		}
+		if err != nil {
+			return fmt.Errorf("for mark 0x%x: %w", mark, err)
+		}
+	}
+	return nil
+}
@@ -33,6 +33,11 @@ func (r *Routing) Setup() (err error) {
 		return fmt.Errorf("setting outbound subnets routes: %w", err)
 	}
 
+	const add = true
+	if err := r.routeBypassMark(defaultRoutes, add); err != nil {
+		return fmt.Errorf("adding routes for bypass mark: %w", err)
+	}
+
 	return nil
 }
 
@@ -51,5 +56,18 @@ func (r *Routing) TearDown() error {
 		return fmt.Errorf("setting outbound subnets routes: %w", err)
 	}
 
+	const add = false
+	if err := r.routeBypassMark(defaultRoutes, add); err != nil {
+		return fmt.Errorf("removing routes for bypass mark: %w", err)
+	}
+
+	if err := r.SetBypassIPs(nil); err != nil {
+		return fmt.Errorf("removing bypass routes: %w", err)
+	}
+
+	if err := r.SetStaticRoutes(nil); err != nil {
+		return fmt.Errorf("removing static routes: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,30 @@
+package routing
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const ipv4ForwardingPath = "/proc/sys/net/ipv4/ip_forward"
+
+// EnableIPv4Forwarding enables IPv4 packets forwarding between
+// network interfaces, if it is not already enabled. This usually
+// fails in containers, where it has to be set with the
+// sysctl net.ipv4.ip_forward=1 instead.
+func EnableIPv4Forwarding() (err error) {
+	data, err := os.ReadFile(ipv4ForwardingPath)
+	if err != nil {
+		return fmt.Errorf("reading IPv4 forwarding: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "1" {
+		return nil
+	}
+
+	const perms = 0644
+	err = os.WriteFile(ipv4ForwardingPath, []byte("1"), perms)
+	if err != nil {
+		return fmt.Errorf("enabling IPv4 forwarding: %w", err)
+	}
+	return nil
+}
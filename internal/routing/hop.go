@@ -0,0 +1,104 @@
+package routing
+
+import (
+	"fmt"
+	"net"
+)
+
+const (
+	hopTable    = 198
+	hopPriority = 97
+)
+
+// SetHopEndpoint routes the IP address given through the default
+// routes, such that the connection to a VPN entry hop server is not
+// routed through the tunnels it carries. The previous hop endpoint
+// routes are removed, and setting a nil IP address only removes them.
+func (r *Routing) SetHopEndpoint(ip net.IP) (err error) {
+	defaultRoutes, err := r.DefaultRoutes()
+	if err != nil {
+		return err
+	}
+
+	r.stateMutex.Lock()
+	defer r.stateMutex.Unlock()
+
+	if r.hopEndpoint.Equal(ip) {
+		return nil
+	}
+
+	if r.hopEndpoint != nil {
+		warnings := r.removeHopEndpoint(r.hopEndpoint, defaultRoutes)
+		for _, warning := range warnings {
+			r.logger.Warn("cannot remove outdated hop endpoint from routing: " + warning)
+		}
+		r.hopEndpoint = nil
+	}
+
+	if ip == nil {
+		return nil
+	}
+
+	err = r.addHopEndpoint(ip, defaultRoutes)
+	if err != nil {
+		return fmt.Errorf("adding hop endpoint to routes: %w", err)
+	}
+	r.hopEndpoint = ip
+
+	return nil
+}
+
+func (r *Routing) removeHopEndpoint(ip net.IP,
+	defaultRoutes []DefaultRoute) (warnings []string) {
+	destination := hostIPNet(ip)
+	for _, defaultRoute := range defaultRoutes {
+		if !ipMatchesFamily(ip, defaultRoute.Family) {
+			continue
+		}
+		err := r.deleteRouteVia(destination, defaultRoute.Gateway, defaultRoute.NetInterface, hopTable)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+		}
+	}
+
+	ruleSrcNet := (*net.IPNet)(nil)
+	err := r.deleteIPRule(ruleSrcNet, &destination, hopTable, hopPriority)
+	if err != nil {
+		warnings = append(warnings,
+			"cannot delete rule: for hop endpoint "+ip.String()+": "+err.Error())
+	}
+
+	return warnings
+}
+
+func (r *Routing) addHopEndpoint(ip net.IP,
+	defaultRoutes []DefaultRoute) (err error) {
+	destination := hostIPNet(ip)
+	for _, defaultRoute := range defaultRoutes {
+		if !ipMatchesFamily(ip, defaultRoute.Family) {
+			continue
+		}
+		err = r.addRouteVia(destination, defaultRoute.Gateway, defaultRoute.NetInterface, hopTable)
+		if err != nil {
+			return fmt.Errorf("adding route for hop endpoint %s: %w", ip, err)
+		}
+	}
+
+	ruleSrcNet := (*net.IPNet)(nil)
+	err = r.addIPRule(ruleSrcNet, &destination, hopTable, hopPriority)
+	if err != nil {
+		return fmt.Errorf("adding rule: for hop endpoint %s: %w", ip, err)
+	}
+
+	return nil
+}
+
+// hostIPNet returns the IP network containing only the IP given.
+func hostIPNet(ip net.IP) net.IPNet {
+	if ipv4 := ip.To4(); ipv4 != nil {
+		const bits = 8 * net.IPv4len
+		return net.IPNet{IP: ipv4, Mask: net.CIDRMask(bits, bits)}
+	}
+	const bits = 8 * net.IPv6len
+	return net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+}
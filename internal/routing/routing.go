@@ -49,6 +49,10 @@ type Routing struct {
 	netLinker       NetLinker
 	logger          Logger
 	outboundSubnets []net.IPNet
+	bypassSubnets   []net.IPNet
+	bypassMark      uint32
+	staticRoutes    []StaticRoute
+	hopEndpoint     net.IP
 	stateMutex      sync.RWMutex
 }
 
@@ -17,8 +17,13 @@ func (r *Routing) addIPRule(src, dst *net.IPNet, table, priority int) error {
 	rule.Dst = dst
 	rule.Priority = priority
 	rule.Table = table
+	return r.addRule(rule)
+}
 
-	existingRules, err := r.netLinker.RuleList(netlink.FAMILY_ALL)
+// addRule adds the rule given if no equal rule of the
+// same family already exists.
+func (r *Routing) addRule(rule *netlink.Rule) error {
+	existingRules, err := r.netLinker.RuleList(rule.Family)
 	if err != nil {
 		return fmt.Errorf("listing rules: %w", err)
 	}
@@ -44,8 +49,13 @@ func (r *Routing) deleteIPRule(src, dst *net.IPNet, table, priority int) error {
 	rule.Dst = dst
 	rule.Priority = priority
 	rule.Table = table
+	return r.deleteRule(rule)
+}
 
-	existingRules, err := r.netLinker.RuleList(netlink.FAMILY_ALL)
+// deleteRule deletes the rule given for each equal
+// rule of the same family existing.
+func (r *Routing) deleteRule(rule *netlink.Rule) error {
+	existingRules, err := r.netLinker.RuleList(rule.Family)
 	if err != nil {
 		return fmt.Errorf("listing rules: %w", err)
 	}
@@ -99,7 +109,8 @@ func rulesAreEqual(a, b *netlink.Rule) bool {
 	return ipNetsAreEqual(a.Src, b.Src) &&
 		ipNetsAreEqual(a.Dst, b.Dst) &&
 		a.Priority == b.Priority &&
-		a.Table == b.Table
+		a.Table == b.Table &&
+		a.Mark == b.Mark
 }
 
 func ipNetsAreEqual(a, b *net.IPNet) bool {
@@ -346,6 +346,18 @@ func Test_rulesAreEqual(t *testing.T) {
 			},
 			equal: true,
 		},
+		"different marks": {
+			a: &netlink.Rule{
+				Priority: 95,
+				Table:    196,
+				Mark:     0x6762,
+			},
+			b: &netlink.Rule{
+				Priority: 95,
+				Table:    196,
+				Mark:     -1,
+			},
+		},
 	}
 
 	for name, testCase := range testCases {
@@ -0,0 +1,105 @@
+package routing
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/netlink"
+)
+
+const (
+	staticTable    = 195
+	staticPriority = 94
+)
+
+// StaticRoute is a route set by the user.
+type StaticRoute struct {
+	Destination net.IPNet
+	// Gateway can be nil to route directly through the interface.
+	Gateway net.IP
+	// Interface can be empty to use the interface reaching the gateway.
+	Interface string
+}
+
+func (s StaticRoute) equal(other StaticRoute) bool {
+	return ipNetsAreEqual(&s.Destination, &other.Destination) &&
+		s.Gateway.Equal(other.Gateway) &&
+		s.Interface == other.Interface
+}
+
+// SetStaticRoutes sets the static routes given in their own table,
+// looked up before the VPN routes, and removes the static routes
+// previously set and no longer present. Routes already set are
+// replaced, so this can be called again after a VPN reconnection
+// to add back routes through the VPN interface.
+func (r *Routing) SetStaticRoutes(routes []StaticRoute) (err error) {
+	r.stateMutex.Lock()
+	defer r.stateMutex.Unlock()
+
+	const add = true
+	kept := make([]StaticRoute, 0, len(routes))
+	for _, existing := range r.staticRoutes {
+		if containsStaticRoute(routes, existing) {
+			kept = append(kept, existing)
+			continue
+		}
+		err = r.setStaticRoute(existing, !add)
+		if err != nil {
+			r.logger.Warn("cannot remove outdated static route: " + err.Error())
+		}
+	}
+	r.staticRoutes = kept
+
+	for _, route := range routes {
+		err = r.setStaticRoute(route, add)
+		if err != nil {
+			return fmt.Errorf("adding static route %s: %w", &route.Destination, err)
+		}
+		if !containsStaticRoute(r.staticRoutes, route) {
+			r.staticRoutes = append(r.staticRoutes, route)
+		}
+	}
+	return nil
+}
+
+func containsStaticRoute(routes []StaticRoute, route StaticRoute) bool {
+	for _, element := range routes {
+		if element.equal(route) {
+			return true
+		}
+	}
+	return false
+}
+
+// setStaticRoute replaces, or deletes if add is false, the route and
+// the rule to look up the static routes table for its destination.
+func (r *Routing) setStaticRoute(staticRoute StaticRoute, add bool) (err error) {
+	destination := staticRoute.Destination
+	route := netlink.Route{
+		Dst:   &destination,
+		Gw:    staticRoute.Gateway,
+		Table: staticTable,
+	}
+	if staticRoute.Interface != "" {
+		link, err := r.netLinker.LinkByName(staticRoute.Interface)
+		if err != nil {
+			return fmt.Errorf("finding link for interface %s: %w", staticRoute.Interface, err)
+		}
+		route.LinkIndex = link.Attrs().Index
+	}
+
+	ruleSrcNet := (*net.IPNet)(nil)
+	if add {
+		r.logger.Info("adding static route for " + destination.String())
+		if err = r.netLinker.RouteReplace(&route); err != nil {
+			return fmt.Errorf("replacing route: %w", err)
+		}
+		return r.addIPRule(ruleSrcNet, &destination, staticTable, staticPriority)
+	}
+
+	r.logger.Info("deleting static route for " + destination.String())
+	if err = r.netLinker.RouteDel(&route); err != nil {
+		return fmt.Errorf("deleting route: %w", err)
+	}
+	return r.deleteIPRule(ruleSrcNet, &destination, staticTable, staticPriority)
+}
@@ -0,0 +1,285 @@
+package routing
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/netlink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Routing_setStaticRoute(t *testing.T) {
+	t.Parallel()
+
+	errDummy := errors.New("dummy error")
+
+	const linkIndex = 88
+	newLink := func() netlink.Link {
+		linkAttrs := netlink.NewLinkAttrs()
+		linkAttrs.Name = "eth1"
+		linkAttrs.Index = linkIndex
+		return &netlink.Bridge{
+			LinkAttrs: linkAttrs,
+		}
+	}
+
+	type linkByNameCall struct {
+		expected bool
+		link     netlink.Link
+		err      error
+	}
+
+	type routeCall struct {
+		expected bool
+		route    *netlink.Route
+		err      error
+	}
+
+	type ruleCall struct {
+		expected bool
+		existing []netlink.Rule
+		rule     *netlink.Rule
+	}
+
+	testCases := map[string]struct {
+		staticRoute StaticRoute
+		add         bool
+		linkByName  linkByNameCall
+		infoMsg     string
+		route       routeCall
+		dbgMsg      string
+		rule        ruleCall
+		err         error
+	}{
+		"link error": {
+			staticRoute: StaticRoute{
+				Destination: *makeIPNet(t, 1),
+				Interface:   "eth1",
+			},
+			add: true,
+			linkByName: linkByNameCall{
+				expected: true,
+				err:      errDummy,
+			},
+			err: errors.New("finding link for interface eth1: dummy error"),
+		},
+		"add route error": {
+			staticRoute: StaticRoute{
+				Destination: *makeIPNet(t, 1),
+				Gateway:     net.IPv4(2, 2, 2, 2),
+			},
+			add:     true,
+			infoMsg: "adding static route for 1.1.1.0/24",
+			route: routeCall{
+				expected: true,
+				route: &netlink.Route{
+					Dst:   makeIPNet(t, 1),
+					Gw:    net.IPv4(2, 2, 2, 2),
+					Table: staticTable,
+				},
+				err: errDummy,
+			},
+			err: errors.New("replacing route: dummy error"),
+		},
+		"add route success": {
+			staticRoute: StaticRoute{
+				Destination: *makeIPNet(t, 1),
+				Interface:   "eth1",
+			},
+			add: true,
+			linkByName: linkByNameCall{
+				expected: true,
+				link:     newLink(),
+			},
+			infoMsg: "adding static route for 1.1.1.0/24",
+			route: routeCall{
+				expected: true,
+				route: &netlink.Route{
+					Dst:       makeIPNet(t, 1),
+					LinkIndex: linkIndex,
+					Table:     staticTable,
+				},
+			},
+			dbgMsg: "ip rule add to 1.1.1.0/24 lookup 195 pref 94",
+			rule: ruleCall{
+				expected: true,
+				rule:     makeIPRule(t, nil, makeIPNet(t, 1), staticTable, staticPriority),
+			},
+		},
+		"delete route error": {
+			staticRoute: StaticRoute{
+				Destination: *makeIPNet(t, 1),
+				Gateway:     net.IPv4(2, 2, 2, 2),
+			},
+			infoMsg: "deleting static route for 1.1.1.0/24",
+			route: routeCall{
+				expected: true,
+				route: &netlink.Route{
+					Dst:   makeIPNet(t, 1),
+					Gw:    net.IPv4(2, 2, 2, 2),
+					Table: staticTable,
+				},
+				err: errDummy,
+			},
+			err: errors.New("deleting route: dummy error"),
+		},
+		"delete route success": {
+			staticRoute: StaticRoute{
+				Destination: *makeIPNet(t, 1),
+				Gateway:     net.IPv4(2, 2, 2, 2),
+			},
+			infoMsg: "deleting static route for 1.1.1.0/24",
+			route: routeCall{
+				expected: true,
+				route: &netlink.Route{
+					Dst:   makeIPNet(t, 1),
+					Gw:    net.IPv4(2, 2, 2, 2),
+					Table: staticTable,
+				},
+			},
+			dbgMsg: "ip rule del to 1.1.1.0/24 lookup 195 pref 94",
+			rule: ruleCall{
+				expected: true,
+				existing: []netlink.Rule{
+					*makeIPRule(t, nil, makeIPNet(t, 1), staticTable, staticPriority),
+				},
+				rule: makeIPRule(t, nil, makeIPNet(t, 1), staticTable, staticPriority),
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			logger := NewMockLogger(ctrl)
+			netLinker := NewMockNetLinker(ctrl)
+
+			if testCase.linkByName.expected {
+				netLinker.EXPECT().LinkByName(testCase.staticRoute.Interface).
+					Return(testCase.linkByName.link, testCase.linkByName.err)
+			}
+			if testCase.infoMsg != "" {
+				logger.EXPECT().Info(testCase.infoMsg)
+			}
+			if testCase.route.expected {
+				if testCase.add {
+					netLinker.EXPECT().RouteReplace(testCase.route.route).
+						Return(testCase.route.err)
+				} else {
+					netLinker.EXPECT().RouteDel(testCase.route.route).
+						Return(testCase.route.err)
+				}
+			}
+			if testCase.dbgMsg != "" {
+				logger.EXPECT().Debug(testCase.dbgMsg)
+			}
+			if testCase.rule.expected {
+				netLinker.EXPECT().RuleList(netlink.FAMILY_ALL).
+					Return(testCase.rule.existing, nil)
+				if testCase.add {
+					netLinker.EXPECT().RuleAdd(testCase.rule.rule).Return(nil)
+				} else {
+					netLinker.EXPECT().RuleDel(testCase.rule.rule).Return(nil)
+				}
+			}
+
+			r := Routing{
+				logger:    logger,
+				netLinker: netLinker,
+			}
+
+			err := r.setStaticRoute(testCase.staticRoute, testCase.add)
+
+			if testCase.err != nil {
+				require.Error(t, err)
+				assert.Equal(t, testCase.err.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_Routing_SetStaticRoutes(t *testing.T) {
+	t.Parallel()
+
+	errDummy := errors.New("dummy error")
+
+	newStaticRoute := func(n byte) StaticRoute {
+		return StaticRoute{
+			Destination: *makeIPNet(t, n),
+			Gateway:     net.IPv4(n, n, n, 1),
+		}
+	}
+	newRoute := func(n byte) *netlink.Route {
+		return &netlink.Route{
+			Dst:   makeIPNet(t, n),
+			Gw:    net.IPv4(n, n, n, 1),
+			Table: staticTable,
+		}
+	}
+	newRule := func(n byte) *netlink.Rule {
+		return makeIPRule(t, nil, makeIPNet(t, n), staticTable, staticPriority)
+	}
+
+	t.Run("replace routes", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+
+		logger := NewMockLogger(ctrl)
+		netLinker := NewMockNetLinker(ctrl)
+
+		// Outdated route 1 fails to be deleted, and is dropped.
+		logger.EXPECT().Info("deleting static route for 1.1.1.0/24")
+		netLinker.EXPECT().RouteDel(newRoute(1)).Return(errDummy)
+		logger.EXPECT().Warn("cannot remove outdated static route: deleting route: dummy error")
+
+		// Existing route 2 and new route 3 are replaced.
+		for _, n := range []byte{2, 3} {
+			logger.EXPECT().Info("adding static route for " + makeIPNet(t, n).String())
+			netLinker.EXPECT().RouteReplace(newRoute(n)).Return(nil)
+			logger.EXPECT().Debug("ip rule add to " + makeIPNet(t, n).String() + " lookup 195 pref 94")
+			netLinker.EXPECT().RuleList(netlink.FAMILY_ALL).Return(nil, nil)
+			netLinker.EXPECT().RuleAdd(newRule(n)).Return(nil)
+		}
+
+		r := Routing{
+			logger:       logger,
+			netLinker:    netLinker,
+			staticRoutes: []StaticRoute{newStaticRoute(1), newStaticRoute(2)},
+		}
+
+		err := r.SetStaticRoutes([]StaticRoute{newStaticRoute(2), newStaticRoute(3)})
+
+		require.NoError(t, err)
+		assert.Equal(t, []StaticRoute{newStaticRoute(2), newStaticRoute(3)}, r.staticRoutes)
+	})
+
+	t.Run("add route error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+
+		logger := NewMockLogger(ctrl)
+		netLinker := NewMockNetLinker(ctrl)
+
+		logger.EXPECT().Info("adding static route for 1.1.1.0/24")
+		netLinker.EXPECT().RouteReplace(newRoute(1)).Return(errDummy)
+
+		r := Routing{
+			logger:    logger,
+			netLinker: netLinker,
+		}
+
+		err := r.SetStaticRoutes([]StaticRoute{newStaticRoute(1), newStaticRoute(2)})
+
+		require.Error(t, err)
+		assert.Equal(t, "adding static route 1.1.1.0/24: replacing route: dummy error", err.Error())
+		assert.Empty(t, r.staticRoutes)
+	})
+}
@@ -0,0 +1,160 @@
+package routing
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/netlink"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	tunnelEndpointsTable    = 193
+	tunnelEndpointsPriority = 92
+	// tunnelPolicyPriority is lower than the priorities of the other
+	// rules, such that the traffic selected for an additional tunnel
+	// goes through it and not through the main VPN connection.
+	tunnelPolicyPriority = 93
+	// tunnelUnreachableMetric is the metric of the unreachable default
+	// route of a tunnel table, higher than the metric of the default
+	// route through the tunnel interface, such that the traffic selected
+	// for the tunnel is dropped while the tunnel is down.
+	tunnelUnreachableMetric = 4096
+)
+
+// TunnelPolicy is the routing policy of an additional
+// tunnel running alongside the main VPN connection.
+type TunnelPolicy struct {
+	// Table is the routing table containing the tunnel routes.
+	Table int
+	// Endpoint is the IP address of the tunnel server, routed
+	// through the default routes.
+	Endpoint net.IP
+	// SourceSubnets are the source subnets routed through the tunnel.
+	SourceSubnets []net.IPNet
+	// Mark is the firewall mark of the traffic routed through the tunnel.
+	Mark uint32
+}
+
+// AddTunnelPolicy routes the tunnel endpoint through the default
+// routes, adds an unreachable default route to the tunnel table and
+// adds the rules looking up the tunnel table for the traffic from the
+// source subnets or marked with the mark of the policy given.
+func (r *Routing) AddTunnelPolicy(policy TunnelPolicy) (err error) {
+	const add = true
+	return r.setTunnelPolicy(policy, add)
+}
+
+// RemoveTunnelPolicy removes the routes and rules added
+// by AddTunnelPolicy for the policy given.
+func (r *Routing) RemoveTunnelPolicy(policy TunnelPolicy) (err error) {
+	const add = false
+	return r.setTunnelPolicy(policy, add)
+}
+
+func (r *Routing) setTunnelPolicy(policy TunnelPolicy, add bool) (err error) {
+	defaultRoutes, err := r.DefaultRoutes()
+	if err != nil {
+		return err
+	}
+
+	r.stateMutex.Lock()
+	defer r.stateMutex.Unlock()
+
+	destination := hostIPNet(policy.Endpoint)
+	for _, defaultRoute := range defaultRoutes {
+		if !ipMatchesFamily(policy.Endpoint, defaultRoute.Family) {
+			continue
+		}
+		if add {
+			err = r.addRouteVia(destination, defaultRoute.Gateway,
+				defaultRoute.NetInterface, tunnelEndpointsTable)
+		} else {
+			err = r.deleteRouteVia(destination, defaultRoute.Gateway,
+				defaultRoute.NetInterface, tunnelEndpointsTable)
+		}
+		if err != nil {
+			return fmt.Errorf("for tunnel endpoint %s: %w", policy.Endpoint, err)
+		}
+	}
+
+	ruleSrcNet := (*net.IPNet)(nil)
+	if add {
+		err = r.addIPRule(ruleSrcNet, &destination, tunnelEndpointsTable, tunnelEndpointsPriority)
+	} else {
+		err = r.deleteIPRule(ruleSrcNet, &destination, tunnelEndpointsTable, tunnelEndpointsPriority)
+	}
+	if err != nil {
+		return fmt.Errorf("for tunnel endpoint %s: %w", policy.Endpoint, err)
+	}
+
+	err = r.setUnreachableDefaultRoute(policy.Table, add)
+	if err != nil {
+		return fmt.Errorf("for tunnel table %d: %w", policy.Table, err)
+	}
+
+	for _, sourceSubnet := range policy.SourceSubnets {
+		sourceSubnet := sourceSubnet
+		ruleDstNet := (*net.IPNet)(nil)
+		if add {
+			err = r.addIPRule(&sourceSubnet, ruleDstNet, policy.Table, tunnelPolicyPriority)
+		} else {
+			err = r.deleteIPRule(&sourceSubnet, ruleDstNet, policy.Table, tunnelPolicyPriority)
+		}
+		if err != nil {
+			return fmt.Errorf("for source subnet %s: %w", &sourceSubnet, err)
+		}
+	}
+
+	rule := netlink.NewRule()
+	rule.Family = netlink.FAMILY_V4
+	rule.Mark = int(policy.Mark)
+	rule.Table = policy.Table
+	rule.Priority = tunnelPolicyPriority
+	if add {
+		r.logger.Debug(fmt.Sprintf("ip rule add fwmark 0x%x lookup %d pref %d",
+			policy.Mark, policy.Table, tunnelPolicyPriority))
+		err = r.addRule(rule)
+	} else {
+		r.logger.Debug(fmt.Sprintf("ip rule del fwmark 0x%x lookup %d pref %d",
+			policy.Mark, policy.Table, tunnelPolicyPriority))
+		err = r.deleteRule(rule)
+	}
+	if err != nil {
+		return fmt.Errorf("for mark 0x%x: %w", policy.Mark, err)
+	}
+
+	return nil
+}
+
+// setUnreachableDefaultRoute adds or deletes an IPv4 unreachable default
+// route in the table given, so the traffic looking up the table does not
+// fall through to the next rules when the table has no other route.
+func (r *Routing) setUnreachableDefaultRoute(table int, add bool) (err error) {
+	route := netlink.Route{
+		Dst: &net.IPNet{
+			IP:   net.IPv4zero.To4(),
+			Mask: net.CIDRMask(0, net.IPv4len*8),
+		},
+		Table:    table,
+		Type:     unix.RTN_UNREACHABLE,
+		Priority: tunnelUnreachableMetric,
+	}
+	if add {
+		r.logger.Debug(fmt.Sprintf("ip route replace unreachable default table %d metric %d",
+			table, tunnelUnreachableMetric))
+		err = r.netLinker.RouteReplace(&route)
+		if err != nil {
+			return fmt.Errorf("replacing unreachable default route: %w", err)
+		}
+		return nil
+	}
+
+	r.logger.Debug(fmt.Sprintf("ip route del unreachable default table %d metric %d",
+		table, tunnelUnreachableMetric))
+	err = r.netLinker.RouteDel(&route)
+	if err != nil {
+		return fmt.Errorf("deleting unreachable default route: %w", err)
+	}
+	return nil
+}
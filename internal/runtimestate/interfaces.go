@@ -0,0 +1,25 @@
+package runtimestate
+
+import (
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/provider"
+)
+
+type Storage interface {
+	FilterServers(provider string, selection settings.ServerSelection) (
+		servers []models.Server, err error)
+}
+
+type Providers interface {
+	Get(providerName string) provider.Provider
+}
+
+type PublicIPLoop interface {
+	GetData() (data models.PublicIP)
+	SetData(data models.PublicIP)
+}
+
+type PortForwardedGetter interface {
+	GetPortForwarded() (port uint16)
+}
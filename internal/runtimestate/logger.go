@@ -0,0 +1,7 @@
+package runtimestate
+
+type Logger interface {
+	Info(s string)
+	Warn(s string)
+	Error(s string)
+}
@@ -0,0 +1,168 @@
+package runtimestate
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/provider"
+)
+
+// WrapProviders returns providers for which the first connection
+// obtained restores the connection of the previous run if it still
+// matches the server selection, and the connections obtained are
+// saved. The providers given are returned unchanged if the settings
+// are not enabled.
+func (s *Store) WrapProviders(providers Providers) Providers {
+	if !*s.settings.Enabled {
+		return providers
+	}
+	return &stateProviders{Providers: providers, store: s}
+}
+
+type stateProviders struct {
+	Providers
+	store *Store
+}
+
+func (p *stateProviders) Get(providerName string) provider.Provider {
+	return &stateProvider{
+		Provider: p.Providers.Get(providerName),
+		name:     providerName,
+		store:    p.store,
+	}
+}
+
+type stateProvider struct {
+	provider.Provider
+	name  string
+	store *Store
+}
+
+func (p *stateProvider) GetConnection(selection settings.ServerSelection,
+	ipv6Supported bool) (connection models.Connection, err error) {
+	connection, ok := p.store.restoreConnection(p.name, selection, ipv6Supported)
+	if ok {
+		return connection, nil
+	}
+
+	connection, err = p.Provider.GetConnection(selection, ipv6Supported)
+	if err != nil {
+		return connection, err
+	}
+
+	p.store.setConnection(p.name, connection)
+	return connection, nil
+}
+
+// restoreConnection returns the connection of the previous run and
+// true if it is still valid. It only returns it once, so another
+// connection is obtained if the restored connection fails.
+func (s *Store) restoreConnection(providerName string,
+	selection settings.ServerSelection, ipv6Supported bool) (
+	connection models.Connection, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous := s.previous
+	s.previous = nil
+	if previous == nil || previous.Connection == nil {
+		return connection, false
+	}
+
+	connection = *previous.Connection
+	err := s.checkConnection(previous.Provider, connection,
+		providerName, selection, ipv6Supported)
+	if err != nil {
+		s.logger.Info("not restoring previous VPN server: " + err.Error())
+		return connection, false
+	}
+
+	s.logger.Info("restoring previous VPN server " + connection.Hostname +
+		" (" + connection.IP.String() + ")")
+	s.state.Provider = providerName
+	s.state.Connection = &connection
+	s.save()
+	if previous.PublicIP != nil {
+		// Known until it is fetched again once the tunnel is up.
+		s.publicIP.SetData(*previous.PublicIP)
+	}
+	return connection, true
+}
+
+var (
+	ErrProviderChanged   = errors.New("VPN provider changed")
+	ErrVPNTypeChanged    = errors.New("VPN type changed")
+	ErrProtocolChanged   = errors.New("protocol changed")
+	ErrPortChanged       = errors.New("port changed")
+	ErrIPv6NotSupported  = errors.New("IPv6 is not supported")
+	ErrServerNotSelected = errors.New("server is not matching the server selection")
+)
+
+// checkConnection checks the connection persisted for the
+// provider given still matches the current server selection
+// and the servers data.
+func (s *Store) checkConnection(previousProvider string,
+	connection models.Connection, providerName string,
+	selection settings.ServerSelection, ipv6Supported bool) (err error) {
+	switch {
+	case previousProvider != providerName:
+		return fmt.Errorf("%w: from %s to %s", ErrProviderChanged, previousProvider, providerName)
+	case connection.Type != selection.VPN:
+		return fmt.Errorf("%w: from %s to %s", ErrVPNTypeChanged, connection.Type, selection.VPN)
+	case connection.IP.To4() == nil && !ipv6Supported:
+		return fmt.Errorf("%w: for IP address %s", ErrIPv6NotSupported, connection.IP)
+	}
+
+	var customPort uint16
+	if selection.VPN == vpn.OpenVPN {
+		protocol := constants.UDP
+		if *selection.OpenVPN.TCP {
+			protocol = constants.TCP
+		}
+		if connection.Protocol != protocol {
+			return fmt.Errorf("%w: from %s to %s", ErrProtocolChanged, connection.Protocol, protocol)
+		}
+		customPort = *selection.OpenVPN.CustomPort
+	} else {
+		customPort = *selection.Wireguard.EndpointPort
+	}
+	if customPort != 0 && connection.Port != customPort {
+		return fmt.Errorf("%w: from %d to %d", ErrPortChanged, connection.Port, customPort)
+	}
+
+	servers, err := s.storage.FilterServers(providerName, selection)
+	if err != nil {
+		return fmt.Errorf("filtering servers: %w", err)
+	}
+
+	for _, server := range servers {
+		if server.VPN == connection.Type && ipIsOneOf(connection.IP, server.IPs) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrServerNotSelected, connection.IP)
+}
+
+func ipIsOneOf(ip net.IP, ips []net.IP) bool {
+	for _, element := range ips {
+		if ip.Equal(element) {
+			return true
+		}
+	}
+	return false
+}
+
+// setConnection saves the connection obtained for the provider given.
+func (s *Store) setConnection(providerName string, connection models.Connection) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.state.Provider = providerName
+	s.state.Connection = &connection
+	s.save()
+}
@@ -0,0 +1,202 @@
+package runtimestate
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStorage struct {
+	servers []models.Server
+}
+
+func (f *fakeStorage) FilterServers(string, settings.ServerSelection) (
+	[]models.Server, error) {
+	return f.servers, nil
+}
+
+type fakeProviders struct {
+	provider provider.Provider
+}
+
+func (f *fakeProviders) Get(string) provider.Provider { return f.provider }
+
+type fakeProvider struct {
+	provider.Provider
+	connection models.Connection
+}
+
+func (f *fakeProvider) GetConnection(settings.ServerSelection, bool) (
+	models.Connection, error) {
+	return f.connection, nil
+}
+
+type fakePublicIP struct {
+	data models.PublicIP
+}
+
+func (f *fakePublicIP) GetData() models.PublicIP     { return f.data }
+func (f *fakePublicIP) SetData(data models.PublicIP) { f.data = data }
+
+func Test_Store_restoreConnection(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	previousConnection := models.Connection{
+		Type:     "openvpn",
+		IP:       net.IPv4(1, 2, 3, 4),
+		Port:     1194,
+		Protocol: "udp",
+		Hostname: "se1",
+	}
+	newConnection := models.Connection{
+		Type:     "openvpn",
+		IP:       net.IPv4(5, 6, 7, 8),
+		Port:     1194,
+		Protocol: "udp",
+		Hostname: "se2",
+	}
+
+	tcp := false
+	customPort := uint16(0)
+	selection := settings.ServerSelection{
+		VPN: "openvpn",
+		OpenVPN: settings.OpenVPNSelection{
+			TCP:        &tcp,
+			CustomPort: &customPort,
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	err := writeState(path, State{
+		Version:    stateVersion,
+		SavedAt:    now.Add(-time.Minute),
+		Provider:   "mullvad",
+		Connection: &previousConnection,
+		PublicIP:   &models.PublicIP{IP: net.IPv4(1, 2, 3, 4)},
+	})
+	require.NoError(t, err)
+
+	enabled := true
+	maxAge := time.Hour
+	storage := &fakeStorage{servers: []models.Server{
+		{VPN: "openvpn", IPs: []net.IP{net.IPv4(1, 2, 3, 4)}},
+	}}
+	publicIP := &fakePublicIP{}
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	logger.EXPECT().Info("restoring previous VPN server se1 (1.2.3.4)")
+	store := New(settings.RuntimeState{
+		Enabled:  &enabled,
+		Filepath: path,
+		MaxAge:   &maxAge,
+	}, storage, publicIP, nil, logger)
+	store.timeNow = func() time.Time { return now }
+	store.Load()
+
+	providers := store.WrapProviders(&fakeProviders{
+		provider: &fakeProvider{connection: newConnection},
+	})
+
+	// First connection is restored
+	connection, err := providers.Get("mullvad").GetConnection(selection, false)
+	require.NoError(t, err)
+	assert.Equal(t, previousConnection, connection)
+	assert.Equal(t, models.PublicIP{IP: net.IPv4(1, 2, 3, 4)}, publicIP.data)
+
+	// Next connection is obtained from the provider and saved
+	connection, err = providers.Get("mullvad").GetConnection(selection, false)
+	require.NoError(t, err)
+	assert.Equal(t, newConnection, connection)
+
+	state, err := readState(path, maxAge, now)
+	require.NoError(t, err)
+	require.NotNil(t, state.Connection)
+	assert.Equal(t, "se2", state.Connection.Hostname)
+}
+
+func Test_Store_checkConnection(t *testing.T) {
+	t.Parallel()
+
+	tcp := false
+	customPort := uint16(0)
+	selection := settings.ServerSelection{
+		VPN: "openvpn",
+		OpenVPN: settings.OpenVPNSelection{
+			TCP:        &tcp,
+			CustomPort: &customPort,
+		},
+	}
+	connection := models.Connection{
+		Type:     "openvpn",
+		IP:       net.IPv4(1, 2, 3, 4),
+		Port:     1194,
+		Protocol: "udp",
+	}
+
+	testCases := map[string]struct {
+		previousProvider string
+		connection       models.Connection
+		servers          []models.Server
+		errWrapped       error
+	}{
+		"valid": {
+			previousProvider: "mullvad",
+			connection:       connection,
+			servers: []models.Server{
+				{VPN: "openvpn", IPs: []net.IP{net.IPv4(1, 2, 3, 4)}},
+			},
+		},
+		"provider_changed": {
+			previousProvider: "ivpn",
+			connection:       connection,
+			errWrapped:       ErrProviderChanged,
+		},
+		"vpn_type_changed": {
+			previousProvider: "mullvad",
+			connection:       models.Connection{Type: "wireguard", IP: connection.IP},
+			errWrapped:       ErrVPNTypeChanged,
+		},
+		"ipv6_not_supported": {
+			previousProvider: "mullvad",
+			connection:       models.Connection{Type: "openvpn", IP: net.ParseIP("::1")},
+			errWrapped:       ErrIPv6NotSupported,
+		},
+		"protocol_changed": {
+			previousProvider: "mullvad",
+			connection: models.Connection{Type: "openvpn",
+				IP: connection.IP, Protocol: "tcp"},
+			errWrapped: ErrProtocolChanged,
+		},
+		"server_not_selected": {
+			previousProvider: "mullvad",
+			connection:       connection,
+			servers: []models.Server{
+				{VPN: "openvpn", IPs: []net.IP{net.IPv4(5, 6, 7, 8)}},
+			},
+			errWrapped: ErrServerNotSelected,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store := &Store{storage: &fakeStorage{servers: testCase.servers}}
+
+			err := store.checkConnection(testCase.previousProvider,
+				testCase.connection, "mullvad", selection, false)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+		})
+	}
+}
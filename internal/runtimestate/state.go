@@ -0,0 +1,106 @@
+package runtimestate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+// stateVersion is the version of the state file format,
+// to increment on incompatible changes.
+const stateVersion = 1
+
+// State is the runtime state persisted to file.
+type State struct {
+	Version       int                `json:"version"`
+	SavedAt       time.Time          `json:"saved_at"`
+	Provider      string             `json:"provider,omitempty"`
+	Connection    *models.Connection `json:"connection,omitempty"`
+	PortForwarded uint16             `json:"port_forwarded,omitempty"`
+	PublicIP      *models.PublicIP   `json:"public_ip,omitempty"`
+	Tokens        map[string]Token   `json:"tokens,omitempty"`
+}
+
+// Token is an authentication token persisted until its expiration.
+type Token struct {
+	Value      string    `json:"value"`
+	Expiration time.Time `json:"expiration"`
+}
+
+var (
+	ErrVersionNotSupported = errors.New("state version is not supported")
+	ErrStateTooOld         = errors.New("state is too old")
+	ErrStateInFuture       = errors.New("state is saved in the future")
+)
+
+// readState reads the state from the file at the path given and checks
+// it is still valid for the maximum age given. It returns a nil state
+// and no error if the file does not exist.
+func readState(path string, maxAge time.Duration, now time.Time) (
+	state *State, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	state = new(State)
+	decoder := json.NewDecoder(file)
+	err = decoder.Decode(state)
+	if err != nil {
+		return nil, fmt.Errorf("decoding state: %w", err)
+	}
+
+	switch {
+	case state.Version != stateVersion:
+		return nil, fmt.Errorf("%w: %d", ErrVersionNotSupported, state.Version)
+	case state.SavedAt.After(now):
+		return nil, fmt.Errorf("%w: %s", ErrStateInFuture, state.SavedAt.Format(time.RFC3339))
+	case now.Sub(state.SavedAt) > maxAge:
+		return nil, fmt.Errorf("%w: saved on %s, more than %s ago", ErrStateTooOld,
+			state.SavedAt.Format(time.RFC3339), maxAge)
+	}
+
+	return state, nil
+}
+
+// writeState writes the state to a temporary file renamed to the
+// path given, such that the file is never partially written.
+func writeState(path string, state State) (err error) {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	temporaryPath := file.Name()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(state)
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(temporaryPath)
+		return fmt.Errorf("writing temporary file: %w", err)
+	}
+
+	err = os.Rename(temporaryPath, path)
+	if err != nil {
+		_ = os.Remove(temporaryPath)
+		return fmt.Errorf("renaming temporary file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package runtimestate
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_writeState_readState(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	const maxAge = time.Hour
+
+	state, err := readState(path, maxAge, now)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	written := State{
+		Version:  stateVersion,
+		SavedAt:  now.Add(-time.Minute),
+		Provider: "mullvad",
+		Connection: &models.Connection{
+			Type:     "openvpn",
+			IP:       net.IPv4(1, 2, 3, 4),
+			Port:     1194,
+			Protocol: "udp",
+			Hostname: "se1",
+		},
+		PortForwarded: 5000,
+	}
+	err = writeState(path, written)
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1) // no temporary file left
+
+	state, err = readState(path, maxAge, now)
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.True(t, written.SavedAt.Equal(state.SavedAt))
+	state.SavedAt = written.SavedAt
+	assert.True(t, written.Connection.IP.Equal(state.Connection.IP))
+	state.Connection.IP = written.Connection.IP
+	assert.Equal(t, written, *state)
+
+	_, err = readState(path, maxAge, now.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrStateTooOld)
+
+	_, err = readState(path, maxAge, now.Add(-time.Hour))
+	assert.ErrorIs(t, err, ErrStateInFuture)
+
+	written.Version = stateVersion + 1
+	err = writeState(path, written)
+	require.NoError(t, err)
+	_, err = readState(path, maxAge, now)
+	assert.ErrorIs(t, err, ErrVersionNotSupported)
+}
+
+func Test_Store_tokens(t *testing.T) {
+	t.Parallel()
+
+	enabled := true
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "state.json")
+	store := &Store{
+		settings: settings.RuntimeState{Enabled: &enabled, Filepath: path},
+		timeNow:  func() time.Time { return now },
+		state:    State{Version: stateVersion},
+	}
+
+	_, ok := store.GetToken("pia")
+	assert.False(t, ok)
+
+	store.SetToken("pia", "token", now.Add(time.Hour))
+	token, ok := store.GetToken("pia")
+	assert.True(t, ok)
+	assert.Equal(t, "token", token)
+
+	maxAge := time.Hour
+	state, err := readState(path, maxAge, now)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Token{
+		"pia": {Value: "token", Expiration: now.Add(time.Hour)},
+	}, state.Tokens)
+
+	now = now.Add(time.Hour)
+	_, ok = store.GetToken("pia")
+	assert.False(t, ok, "expired token")
+
+	store.SetToken("pia", "", time.Time{})
+	assert.Empty(t, store.state.Tokens)
+}
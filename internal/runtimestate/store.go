@@ -0,0 +1,175 @@
+package runtimestate
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+// Store persists the runtime state to file, and restores
+// the state persisted on the previous run.
+type Store struct {
+	settings    settings.RuntimeState
+	storage     Storage
+	publicIP    PublicIPLoop
+	portForward PortForwardedGetter
+	logger      Logger
+	timeNow     func() time.Time
+
+	mutex sync.Mutex
+	state State
+	// previous is the valid state read from file on startup,
+	// and is nil if there is none or once it is restored.
+	previous *State
+	// previousPortForwarded is the port forwarded on the
+	// previous run, and is zero if there is none.
+	previousPortForwarded uint16
+}
+
+func New(settings settings.RuntimeState, storage Storage,
+	publicIP PublicIPLoop, portForward PortForwardedGetter,
+	logger Logger) *Store {
+	return &Store{
+		settings:    settings,
+		storage:     storage,
+		publicIP:    publicIP,
+		portForward: portForward,
+		logger:      logger,
+		timeNow:     time.Now,
+		state:       State{Version: stateVersion},
+	}
+}
+
+// Load reads the state persisted on the previous run, to be
+// restored by the providers returned by WrapProviders.
+// A state not valid anymore is logged and ignored.
+func (s *Store) Load() {
+	if !*s.settings.Enabled {
+		return
+	}
+
+	previous, err := readState(s.settings.Filepath, *s.settings.MaxAge, s.timeNow())
+	if err != nil {
+		s.logger.Warn("not restoring runtime state: " + err.Error())
+		return
+	} else if previous == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.previous = previous
+	s.state.PortForwarded = previous.PortForwarded
+	s.state.PublicIP = previous.PublicIP
+	s.state.Tokens = previous.Tokens
+	s.previousPortForwarded = previous.PortForwarded
+}
+
+// PreviousPortForwarded returns the port forwarded on the previous
+// run, or zero if there is none or if the settings are not enabled.
+func (s *Store) PreviousPortForwarded() (port uint16) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.previousPortForwarded
+}
+
+// GetToken returns the token of the name given and true if it is
+// persisted and not expired.
+func (s *Store) GetToken(name string) (token string, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	persisted, ok := s.state.Tokens[name]
+	if !ok || !s.timeNow().Before(persisted.Expiration) {
+		return "", false
+	}
+	return persisted.Value, true
+}
+
+// SetToken persists the token of the name given until its expiration
+// time, and removes the token persisted if the token given is empty.
+// It does nothing if the settings are not enabled.
+func (s *Store) SetToken(name, token string, expiration time.Time) {
+	if !*s.settings.Enabled {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if token == "" {
+		if _, ok := s.state.Tokens[name]; !ok {
+			return
+		}
+		delete(s.state.Tokens, name)
+	} else {
+		if s.state.Tokens == nil {
+			s.state.Tokens = make(map[string]Token)
+		}
+		s.state.Tokens[name] = Token{Value: token, Expiration: expiration}
+	}
+	s.save()
+}
+
+// Run saves the forwarded port and public IP address each time
+// they change, until the context is canceled. It returns immediately
+// if the settings are not enabled.
+func (s *Store) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	if !*s.settings.Enabled {
+		return
+	}
+
+	const period = 5 * time.Second
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.update(s.portForward.GetPortForwarded(), s.publicIP.GetData())
+	}
+}
+
+// update saves the port forwarded and public IP given if they
+// changed. Unset values are ignored, so the last values known
+// are kept while the VPN is reconnecting.
+func (s *Store) update(portForwarded uint16, publicIP models.PublicIP) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	changed := false
+	if portForwarded != 0 && portForwarded != s.state.PortForwarded {
+		s.state.PortForwarded = portForwarded
+		changed = true
+	}
+	if publicIP.IP != nil &&
+		(s.state.PublicIP == nil || !s.state.PublicIP.IP.Equal(publicIP.IP)) {
+		publicIP = publicIP.Copy()
+		s.state.PublicIP = &publicIP
+		changed = true
+	}
+
+	// Refresh the time the state is saved at, so the state
+	// of a long running connection is not considered too old.
+	const refreshPeriod = time.Hour
+	if changed || s.timeNow().Sub(s.state.SavedAt) >= refreshPeriod {
+		s.save()
+	}
+}
+
+// save writes the state to file, and must be called
+// with the mutex locked.
+func (s *Store) save() {
+	s.state.SavedAt = s.timeNow()
+	err := writeState(s.settings.Filepath, s.state)
+	if err != nil {
+		s.logger.Error("saving runtime state: " + err.Error())
+	}
+}
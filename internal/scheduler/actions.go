@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+const (
+	// ActionReconnect restarts the VPN connection.
+	ActionReconnect = "reconnect"
+	// ActionRotateServer restarts the VPN connection until it connects
+	// to a different server, as long as the server selection allows it.
+	ActionRotateServer = "rotate_server"
+	// ActionUpdate runs the servers data updater.
+	ActionUpdate = "update"
+	// ActionDiagnostics reports the VPN status, the connected server,
+	// the public IP address and the health status.
+	ActionDiagnostics = "diagnostics"
+)
+
+var ErrActionNotValid = errors.New("action is not valid")
+
+func validateAction(action string) (err error) {
+	switch action {
+	case ActionReconnect, ActionRotateServer, ActionUpdate, ActionDiagnostics:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s must be one of %s, %s, %s or %s",
+			ErrActionNotValid, action, ActionReconnect, ActionRotateServer,
+			ActionUpdate, ActionDiagnostics)
+	}
+}
+
+func (s *Scheduler) runAction(ctx context.Context, action string) (
+	outcome string, err error) {
+	switch action {
+	case ActionReconnect:
+		return s.reconnect(ctx)
+	case ActionRotateServer:
+		return s.rotateServer(ctx)
+	case ActionUpdate:
+		return s.updaterLooper.SetStatus(ctx, constants.Running)
+	case ActionDiagnostics:
+		return s.diagnostics(), nil
+	default:
+		return "", validateAction(action)
+	}
+}
+
+func (s *Scheduler) reconnect(ctx context.Context) (outcome string, err error) {
+	_, err = s.vpnLooper.ApplyStatus(ctx, constants.Stopped)
+	if err != nil {
+		return "", fmt.Errorf("stopping VPN: %w", err)
+	}
+	outcome, err = s.vpnLooper.ApplyStatus(ctx, constants.Running)
+	if err != nil {
+		return "", fmt.Errorf("starting VPN: %w", err)
+	}
+	return outcome, nil
+}
+
+func (s *Scheduler) rotateServer(ctx context.Context) (outcome string, err error) {
+	previous, _ := s.vpnLooper.GetConnectedServer()
+
+	// The VPN picks a random server amongst the servers matching the
+	// server selection at each connection, so it may pick the same
+	// server again.
+	const maxAttempts = 3
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		_, err = s.reconnect(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		current, ok := s.vpnLooper.GetConnectedServer()
+		if !ok {
+			return "reconnected", nil
+		} else if current.Hostname != previous.Hostname ||
+			current.ServerName != previous.ServerName {
+			return "rotated to server " + serverName(current), nil
+		}
+	}
+	return fmt.Sprintf("still connected to server %s after %d attempts",
+		serverName(previous), maxAttempts), nil
+}
+
+func (s *Scheduler) diagnostics() (outcome string) {
+	parts := []string{"VPN " + string(s.vpnLooper.GetStatus())}
+
+	if server, ok := s.vpnLooper.GetConnectedServer(); ok {
+		parts = append(parts, "server "+serverName(server))
+	}
+
+	if publicIP := s.publicIP.GetData(); publicIP.IP != nil {
+		parts = append(parts, "public IP "+publicIP.IP.String())
+	}
+
+	if err := s.readiness.Ready(); err != nil {
+		parts = append(parts, "unhealthy: "+err.Error())
+	} else {
+		parts = append(parts, "healthy")
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func serverName(server models.ConnectedServer) string {
+	switch {
+	case server.ServerName != "":
+		return server.ServerName
+	case server.Hostname != "":
+		return server.Hostname
+	default:
+		return server.IP.String()
+	}
+}
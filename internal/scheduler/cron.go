@@ -0,0 +1,185 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCronFieldsCount = errors.New("cron expression must have 5 fields")
+	ErrCronFieldValue  = errors.New("cron field value is not valid")
+)
+
+// cron is a parsed cron expression with the standard 5 fields
+// minute, hour, day of month, month and day of week.
+// Each field is a bit set of the values it matches.
+type cron struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+	daysStar bool
+	weekStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [...]cronField{ //nolint:gochecknoglobals,gomnd
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+var cronMacros = map[string]string{ //nolint:gochecknoglobals
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCron parses a cron expression with 5 space separated fields,
+// each field being a comma separated list of `*`, values or ranges,
+// optionally followed by a `/step`. The macros @yearly, @annually,
+// @monthly, @weekly, @daily and @hourly are also supported.
+func parseCron(expression string) (c cron, err error) {
+	if macro, ok := cronMacros[expression]; ok {
+		expression = macro
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != len(cronFields) {
+		return c, fmt.Errorf("%w: %d fields in %q",
+			ErrCronFieldsCount, len(fields), expression)
+	}
+
+	sets := make([]uint64, len(fields))
+	for i, field := range fields {
+		sets[i], err = parseCronField(field, cronFields[i])
+		if err != nil {
+			return c, fmt.Errorf("%s field: %w", cronFields[i].name, err)
+		}
+	}
+
+	const sunday = 7
+	weekdays := sets[4]
+	if weekdays&(1<<sunday) != 0 {
+		weekdays |= 1
+		weekdays &^= 1 << sunday
+	}
+
+	return cron{
+		minutes:  sets[0],
+		hours:    sets[1],
+		days:     sets[2],
+		months:   sets[3],
+		weekdays: weekdays,
+		daysStar: fields[2] == "*",
+		weekStar: fields[4] == "*",
+	}, nil
+}
+
+func parseCronField(field string, bounds cronField) (set uint64, err error) {
+	for _, item := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("%w: step %q", ErrCronFieldValue, stepPart)
+			}
+		}
+
+		start, end := bounds.min, bounds.max
+		if rangePart != "*" {
+			startPart, endPart, isRange := strings.Cut(rangePart, "-")
+			start, err = parseCronValue(startPart, bounds)
+			if err != nil {
+				return 0, err
+			}
+			switch {
+			case isRange:
+				end, err = parseCronValue(endPart, bounds)
+				if err != nil {
+					return 0, err
+				} else if end < start {
+					return 0, fmt.Errorf("%w: range %q", ErrCronFieldValue, rangePart)
+				}
+			case !hasStep:
+				end = start
+			}
+		}
+
+		for value := start; value <= end; value += step {
+			set |= 1 << value
+		}
+	}
+	return set, nil
+}
+
+func parseCronValue(s string, bounds cronField) (value int, err error) {
+	value, err = strconv.Atoi(s)
+	if err != nil || value < bounds.min || value > bounds.max {
+		return 0, fmt.Errorf("%w: %q is not between %d and %d",
+			ErrCronFieldValue, s, bounds.min, bounds.max)
+	}
+	return value, nil
+}
+
+// next returns the first time strictly after the time given
+// matching the cron expression, or the zero time if no time
+// matches within the next 5 years.
+func (c cron) next(t time.Time) time.Time {
+	location := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0) //nolint:gomnd
+
+	for t.Before(limit) {
+		year, month, day := t.Date()
+		switch {
+		case !has(c.months, int(month)):
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, location)
+		case !c.dayMatches(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, location)
+		case !has(c.hours, t.Hour()):
+			t = time.Date(year, month, day, t.Hour()+1, 0, 0, 0, location)
+		case !has(c.minutes, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches follows the cron convention where, if both the day of
+// month and the day of week fields are restricted, a day matching
+// either field matches.
+func (c cron) dayMatches(t time.Time) bool {
+	dayMatch := has(c.days, t.Day())
+	weekdayMatch := has(c.weekdays, int(t.Weekday()))
+	switch {
+	case c.daysStar && c.weekStar:
+		return true
+	case c.daysStar:
+		return weekdayMatch
+	case c.weekStar:
+		return dayMatch
+	default:
+		return dayMatch || weekdayMatch
+	}
+}
+
+func has(set uint64, value int) bool {
+	return set&(1<<value) != 0
+}
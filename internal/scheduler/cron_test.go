@@ -0,0 +1,104 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseCron(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		expression string
+		errMessage string
+	}{
+		"valid":            {expression: "*/15 2-4,6 * 1-12/2 1-5"},
+		"macro":            {expression: "@daily"},
+		"missing field":    {expression: "* * * *", errMessage: `cron expression must have 5 fields: 4 fields in "* * * *"`},
+		"value too large":  {expression: "60 * * * *", errMessage: `minute field: cron field value is not valid: "60" is not between 0 and 59`},
+		"invalid step":     {expression: "*/0 * * * *", errMessage: `minute field: cron field value is not valid: step "0"`},
+		"reversed range":   {expression: "* 5-2 * * *", errMessage: `hour field: cron field value is not valid: range "5-2"`},
+		"not a number":     {expression: "* * x * *", errMessage: `day of month field: cron field value is not valid: "x" is not between 1 and 31`},
+		"day of week zero": {expression: "* * * * 0-7"},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseCron(testCase.expression)
+
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_cron_next(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday 15 May 2024 10:32:45 UTC
+	from := time.Date(2024, time.May, 15, 10, 32, 45, 0, time.UTC)
+
+	testCases := map[string]struct {
+		expression string
+		next       time.Time
+	}{
+		"every minute": {
+			expression: "* * * * *",
+			next:       time.Date(2024, time.May, 15, 10, 33, 0, 0, time.UTC),
+		},
+		"every 15 minutes": {
+			expression: "*/15 * * * *",
+			next:       time.Date(2024, time.May, 15, 10, 45, 0, 0, time.UTC),
+		},
+		"daily": {
+			expression: "@daily",
+			next:       time.Date(2024, time.May, 16, 0, 0, 0, 0, time.UTC),
+		},
+		"weekdays at 4am": {
+			expression: "0 4 * * 1-5",
+			next:       time.Date(2024, time.May, 16, 4, 0, 0, 0, time.UTC),
+		},
+		"sunday as 7": {
+			expression: "30 3 * * 7",
+			next:       time.Date(2024, time.May, 19, 3, 30, 0, 0, time.UTC),
+		},
+		"day of month or day of week": {
+			expression: "0 0 1 * 5",
+			next:       time.Date(2024, time.May, 17, 0, 0, 0, 0, time.UTC),
+		},
+		"next year": {
+			expression: "0 0 1 1 *",
+			next:       time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"leap day": {
+			expression: "0 0 29 2 *",
+			next:       time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC),
+		},
+		"never": {
+			expression: "0 0 31 2 *",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := parseCron(testCase.expression)
+			require.NoError(t, err)
+
+			next := c.next(from)
+
+			assert.Equal(t, testCase.next, next)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+type VPNLooper interface {
+	GetStatus() (status models.LoopStatus)
+	ApplyStatus(ctx context.Context, status models.LoopStatus) (
+		outcome string, err error)
+	GetConnectedServer() (server models.ConnectedServer, ok bool)
+}
+
+type UpdaterLooper interface {
+	SetStatus(ctx context.Context, status models.LoopStatus) (
+		outcome string, err error)
+}
+
+type PublicIPLoop interface {
+	GetData() (data models.PublicIP)
+}
+
+type ReadinessChecker interface {
+	Ready() (err error)
+}
+
+type Logger interface {
+	Info(s string)
+	Warn(s string)
+	Error(s string)
+}
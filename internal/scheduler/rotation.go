@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Rotation is the state of the server rotation, which rotates
+// the VPN server at a fixed period or at the times matching a
+// cron expression, honoring the server selection filters.
+type Rotation struct {
+	Enabled      bool       `json:"enabled"`
+	Period       string     `json:"period,omitempty"`
+	Cron         string     `json:"cron,omitempty"`
+	NextRotation *time.Time `json:"next_rotation,omitempty"`
+	LastRotation *time.Time `json:"last_rotation,omitempty"`
+	LastOutcome  string     `json:"last_outcome,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+}
+
+// rotationTiming gives the next rotation time after a given time.
+type rotationTiming interface {
+	next(t time.Time) time.Time
+}
+
+type period time.Duration
+
+func (p period) next(t time.Time) time.Time {
+	return t.Add(time.Duration(p))
+}
+
+// ValidateCron returns an error if the cron expression given
+// cannot be parsed.
+func ValidateCron(expression string) (err error) {
+	_, err = parseCron(expression)
+	return err
+}
+
+var ErrRotationPeriodAndCron = errors.New("rotation period and cron expression cannot be both set")
+
+// SetRotation sets the server rotation to run every rotation
+// period, or at the times matching the cron expression given.
+// Setting both to their zero value disables the rotation.
+func (s *Scheduler) SetRotation(rotationPeriod time.Duration,
+	cronExpression string) (err error) {
+	var timing rotationTiming
+	rotation := Rotation{}
+	switch {
+	case rotationPeriod > 0 && cronExpression != "":
+		return fmt.Errorf("%w", ErrRotationPeriodAndCron)
+	case rotationPeriod > 0:
+		timing = period(rotationPeriod)
+		rotation.Period = rotationPeriod.String()
+	case cronExpression != "":
+		timing, err = parseCron(cronExpression)
+		if err != nil {
+			return fmt.Errorf("parsing cron expression: %w", err)
+		}
+		rotation.Cron = cronExpression
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if timing != nil {
+		rotation.Enabled = true
+		rotation.NextRotation = timePtr(timing.next(s.timeNow()))
+	}
+	rotation.LastRotation = s.rotation.LastRotation
+	rotation.LastOutcome = s.rotation.LastOutcome
+	rotation.LastError = s.rotation.LastError
+	s.rotation = rotation
+	s.rotationTiming = timing
+	s.signalChange()
+	return nil
+}
+
+// GetRotation returns the current state of the server rotation.
+func (s *Scheduler) GetRotation() (rotation Rotation) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.rotation
+}
+
+// RotateNow rotates the VPN server immediately, even if the
+// server rotation is disabled. If it is enabled, the next
+// rotation is rescheduled from the current time.
+func (s *Scheduler) RotateNow(ctx context.Context) (outcome string, err error) {
+	outcome, err = s.rotate(ctx)
+	s.signalChange()
+	return outcome, err
+}
+
+func (s *Scheduler) rotationDue(now time.Time) (due bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.rotation.NextRotation != nil && !s.rotation.NextRotation.After(now)
+}
+
+// rotate rotates the VPN server and records the outcome.
+// Rotations are serialized, since they can also be triggered
+// from the control server.
+func (s *Scheduler) rotate(ctx context.Context) (outcome string, err error) {
+	s.rotateMutex.Lock()
+	defer s.rotateMutex.Unlock()
+
+	rotationTime := s.timeNow()
+	outcome, err = s.rotateServer(ctx)
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.rotation.LastRotation = timePtr(rotationTime)
+	s.rotation.LastOutcome = outcome
+	s.rotation.LastError = ""
+	if err != nil {
+		s.rotation.LastError = err.Error()
+	}
+	if s.rotationTiming != nil {
+		s.rotation.NextRotation = timePtr(s.rotationTiming.next(s.timeNow()))
+	}
+	return outcome, err
+}
@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Scheduler_SetRotation(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.May, 15, 10, 32, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		period   time.Duration
+		cron     string
+		rotation Rotation
+		errWrap  error
+		errMsg   string
+	}{
+		"disabled": {},
+		"period": {
+			period: 6 * time.Hour,
+			rotation: Rotation{
+				Enabled:      true,
+				Period:       "6h0m0s",
+				NextRotation: timePtr(now.Add(6 * time.Hour)),
+			},
+		},
+		"cron": {
+			cron: "0 */6 * * *",
+			rotation: Rotation{
+				Enabled:      true,
+				Cron:         "0 */6 * * *",
+				NextRotation: timePtr(time.Date(2024, time.May, 15, 12, 0, 0, 0, time.UTC)),
+			},
+		},
+		"period and cron": {
+			period:  time.Hour,
+			cron:    "@daily",
+			errWrap: ErrRotationPeriodAndCron,
+			errMsg:  "rotation period and cron expression cannot be both set",
+		},
+		"invalid cron": {
+			cron:    "* * *",
+			errWrap: ErrCronFieldsCount,
+			errMsg:  "parsing cron expression: cron expression must have 5 fields: 3 fields in \"* * *\"",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			scheduler := newTestScheduler(t, &fakeVPNLooper{}, NewMockLogger(ctrl), now)
+
+			err := scheduler.SetRotation(testCase.period, testCase.cron)
+
+			assert.ErrorIs(t, err, testCase.errWrap)
+			if testCase.errWrap != nil {
+				assert.EqualError(t, err, testCase.errMsg)
+			}
+			assert.Equal(t, testCase.rotation, scheduler.GetRotation())
+		})
+	}
+}
+
+func Test_Scheduler_rotation(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.May, 15, 10, 32, 0, 0, time.UTC)
+	vpnLooper := &fakeVPNLooper{
+		servers: []models.ConnectedServer{
+			{ServerName: "a"}, {ServerName: "b"}, {ServerName: "c"},
+		},
+	}
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	scheduler := newTestScheduler(t, vpnLooper, logger, now)
+	err := scheduler.SetRotation(time.Hour, "")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), scheduler.nextRun())
+
+	// Not due yet
+	scheduler.runDue(context.Background())
+	assert.Empty(t, vpnLooper.statuses)
+
+	rotationTime := now.Add(time.Hour)
+	scheduler.timeNow = func() time.Time { return rotationTime }
+	gomock.InOrder(
+		logger.EXPECT().Info("rotating VPN server"),
+		logger.EXPECT().Info("rotating VPN server: rotated to server b"),
+	)
+	scheduler.runDue(context.Background())
+
+	expected := Rotation{
+		Enabled:      true,
+		Period:       "1h0m0s",
+		NextRotation: timePtr(rotationTime.Add(time.Hour)),
+		LastRotation: timePtr(rotationTime),
+		LastOutcome:  "rotated to server b",
+	}
+	assert.Equal(t, expected, scheduler.GetRotation())
+
+	manualTime := rotationTime.Add(10 * time.Minute)
+	scheduler.timeNow = func() time.Time { return manualTime }
+	outcome, err := scheduler.RotateNow(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "rotated to server c", outcome)
+	expected = Rotation{
+		Enabled:      true,
+		Period:       "1h0m0s",
+		NextRotation: timePtr(manualTime.Add(time.Hour)),
+		LastRotation: timePtr(manualTime),
+		LastOutcome:  "rotated to server c",
+	}
+	assert.Equal(t, expected, scheduler.GetRotation())
+}
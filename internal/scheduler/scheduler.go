@@ -0,0 +1,293 @@
+// Package scheduler runs recurring actions such as reconnecting
+// the VPN or running the servers updater, at times defined by
+// cron expressions. Schedules are persisted across restarts.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Schedule is a recurring action run at the times
+// matching its cron expression.
+type Schedule struct {
+	ID          string     `json:"id"`
+	Cron        string     `json:"cron"`
+	Action      string     `json:"action"`
+	NextRun     *time.Time `json:"next_run,omitempty"`
+	LastRun     *time.Time `json:"last_run,omitempty"`
+	LastOutcome string     `json:"last_outcome,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+type Scheduler struct {
+	path          string
+	vpnLooper     VPNLooper
+	updaterLooper UpdaterLooper
+	publicIP      PublicIPLoop
+	readiness     ReadinessChecker
+	logger        Logger
+	timeNow       func() time.Time
+
+	mutex     sync.RWMutex
+	schedules []Schedule
+	idToCron  map[string]cron
+	lastID    uint64
+	changed   chan struct{}
+
+	rotation       Rotation
+	rotationTiming rotationTiming
+	rotateMutex    sync.Mutex
+}
+
+// New creates a scheduler loading and persisting the
+// schedules in a JSON file at /gluetun/schedules.json.
+func New(vpnLooper VPNLooper, updaterLooper UpdaterLooper,
+	publicIP PublicIPLoop, readiness ReadinessChecker,
+	logger Logger) *Scheduler {
+	scheduler := &Scheduler{
+		path:          "/gluetun/schedules.json",
+		vpnLooper:     vpnLooper,
+		updaterLooper: updaterLooper,
+		publicIP:      publicIP,
+		readiness:     readiness,
+		logger:        logger,
+		timeNow:       time.Now,
+		idToCron:      make(map[string]cron),
+		changed:       make(chan struct{}, 1),
+	}
+
+	schedules, err := readSchedules(scheduler.path)
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+	scheduler.load(schedules)
+
+	return scheduler
+}
+
+func (s *Scheduler) load(schedules []Schedule) {
+	now := s.timeNow()
+	s.schedules = make([]Schedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		parsed, err := parseCron(schedule.Cron)
+		if err == nil {
+			err = validateAction(schedule.Action)
+		}
+		if err != nil {
+			s.logger.Warn("ignoring schedule " + schedule.ID + ": " + err.Error())
+			continue
+		}
+
+		const base, bitSize = 10, 64
+		id, err := strconv.ParseUint(schedule.ID, base, bitSize)
+		if err == nil && id > s.lastID {
+			s.lastID = id
+		}
+
+		schedule.NextRun = timePtr(parsed.next(now))
+		s.idToCron[schedule.ID] = parsed
+		s.schedules = append(s.schedules, schedule)
+	}
+}
+
+// GetSchedules returns a copy of the current schedules.
+func (s *Scheduler) GetSchedules() (schedules []Schedule) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	schedules = make([]Schedule, len(s.schedules))
+	copy(schedules, s.schedules)
+	return schedules
+}
+
+// Add adds a schedule running the action given at the times
+// matching the cron expression given, and returns it.
+func (s *Scheduler) Add(cronExpression, action string) (
+	schedule Schedule, err error) {
+	parsed, err := parseCron(cronExpression)
+	if err != nil {
+		return schedule, fmt.Errorf("parsing cron expression: %w", err)
+	}
+	err = validateAction(action)
+	if err != nil {
+		return schedule, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastID++
+	schedule = Schedule{
+		ID:      fmt.Sprint(s.lastID),
+		Cron:    cronExpression,
+		Action:  action,
+		NextRun: timePtr(parsed.next(s.timeNow())),
+	}
+	s.idToCron[schedule.ID] = parsed
+	s.schedules = append(s.schedules, schedule)
+	s.persist()
+	s.signalChange()
+	return schedule, nil
+}
+
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+// Remove removes the schedule with the ID given.
+func (s *Scheduler) Remove(id string) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, schedule := range s.schedules {
+		if schedule.ID != id {
+			continue
+		}
+		s.schedules = append(s.schedules[:i], s.schedules[i+1:]...)
+		delete(s.idToCron, id)
+		s.persist()
+		s.signalChange()
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+}
+
+// persist writes the schedules to file, and must be
+// called with the mutex locked.
+func (s *Scheduler) persist() {
+	err := writeSchedules(s.path, s.schedules)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+func (s *Scheduler) signalChange() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Run runs the scheduled actions when they are due,
+// until the context is canceled.
+func (s *Scheduler) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	for {
+		var timer *time.Timer
+		var timerCh <-chan time.Time
+		if nextRun := s.nextRun(); !nextRun.IsZero() {
+			timer = time.NewTimer(nextRun.Sub(s.timeNow()))
+			timerCh = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			stopTimer(timer)
+			return
+		case <-s.changed:
+			stopTimer(timer)
+		case <-timerCh:
+			s.runDue(ctx)
+		}
+	}
+}
+
+func stopTimer(timer *time.Timer) {
+	if timer != nil {
+		timer.Stop()
+	}
+}
+
+func (s *Scheduler) nextRun() (nextRun time.Time) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, schedule := range s.schedules {
+		if schedule.NextRun == nil {
+			continue
+		}
+		if nextRun.IsZero() || schedule.NextRun.Before(nextRun) {
+			nextRun = *schedule.NextRun
+		}
+	}
+	nextRotation := s.rotation.NextRotation
+	if nextRotation != nil && (nextRun.IsZero() || nextRotation.Before(nextRun)) {
+		nextRun = *nextRotation
+	}
+	return nextRun
+}
+
+// runDue runs the actions of the schedules due, one after the
+// other, and then the server rotation if it is due, and records
+// their outcome.
+func (s *Scheduler) runDue(ctx context.Context) {
+	now := s.timeNow()
+	var due []Schedule
+	s.mutex.RLock()
+	for _, schedule := range s.schedules {
+		if schedule.NextRun != nil && !schedule.NextRun.After(now) {
+			due = append(due, schedule)
+		}
+	}
+	s.mutex.RUnlock()
+
+	for _, schedule := range due {
+		s.logger.Info("running scheduled action " + schedule.Action +
+			" of schedule " + schedule.ID)
+		outcome, err := s.runAction(ctx, schedule.Action)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.logger.Error("scheduled action " + schedule.Action + ": " + err.Error())
+		} else {
+			s.logger.Info("scheduled action " + schedule.Action + ": " + outcome)
+		}
+		s.recordRun(schedule.ID, now, outcome, err)
+	}
+
+	if s.rotationDue(now) {
+		s.logger.Info("rotating VPN server")
+		outcome, err := s.rotate(ctx)
+		switch {
+		case ctx.Err() != nil:
+		case err != nil:
+			s.logger.Error("rotating VPN server: " + err.Error())
+		default:
+			s.logger.Info("rotating VPN server: " + outcome)
+		}
+	}
+}
+
+func (s *Scheduler) recordRun(id string, runTime time.Time,
+	outcome string, runErr error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, schedule := range s.schedules {
+		if schedule.ID != id {
+			continue
+		}
+		schedule.LastRun = timePtr(runTime)
+		schedule.LastOutcome = outcome
+		schedule.LastError = ""
+		if runErr != nil {
+			schedule.LastError = runErr.Error()
+		}
+		// Compute the next run from the current time, in case the
+		// action took longer than the period of the schedule.
+		schedule.NextRun = timePtr(s.idToCron[id].next(s.timeNow()))
+		s.schedules[i] = schedule
+		s.persist()
+		return
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,168 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVPNLooper struct {
+	statuses []models.LoopStatus
+	servers  []models.ConnectedServer
+}
+
+func (l *fakeVPNLooper) GetStatus() models.LoopStatus { return constants.Running }
+
+func (l *fakeVPNLooper) ApplyStatus(_ context.Context, status models.LoopStatus) (
+	string, error) {
+	l.statuses = append(l.statuses, status)
+	if status == constants.Running && len(l.servers) > 1 {
+		l.servers = l.servers[1:]
+	}
+	return "status applied", nil
+}
+
+func (l *fakeVPNLooper) GetConnectedServer() (models.ConnectedServer, bool) {
+	if len(l.servers) == 0 {
+		return models.ConnectedServer{}, false
+	}
+	return l.servers[0], true
+}
+
+type fakeUpdaterLooper struct{}
+
+func (fakeUpdaterLooper) SetStatus(context.Context, models.LoopStatus) (string, error) {
+	return "updater started", nil
+}
+
+type fakePublicIP struct{}
+
+func (fakePublicIP) GetData() models.PublicIP {
+	return models.PublicIP{IP: net.IPv4(1, 2, 3, 4)}
+}
+
+type fakeReadiness struct{ err error }
+
+func (r fakeReadiness) Ready() error { return r.err }
+
+func newTestScheduler(t *testing.T, vpnLooper VPNLooper,
+	logger Logger, now time.Time) *Scheduler {
+	t.Helper()
+	return &Scheduler{
+		path:          filepath.Join(t.TempDir(), "schedules.json"),
+		vpnLooper:     vpnLooper,
+		updaterLooper: fakeUpdaterLooper{},
+		publicIP:      fakePublicIP{},
+		readiness:     fakeReadiness{err: errors.New("connection refused")},
+		logger:        logger,
+		timeNow:       func() time.Time { return now },
+		idToCron:      make(map[string]cron),
+		changed:       make(chan struct{}, 1),
+	}
+}
+
+func Test_Scheduler_Add_Remove(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	now := time.Date(2024, time.May, 15, 10, 32, 0, 0, time.UTC)
+	scheduler := newTestScheduler(t, &fakeVPNLooper{}, logger, now)
+
+	_, err := scheduler.Add("* * *", ActionReconnect)
+	assert.EqualError(t, err, `parsing cron expression: cron expression `+
+		`must have 5 fields: 3 fields in "* * *"`)
+	_, err = scheduler.Add("@hourly", "reboot")
+	assert.EqualError(t, err, "action is not valid: reboot must be one of "+
+		"reconnect, rotate_server, update or diagnostics")
+
+	schedule, err := scheduler.Add("@hourly", ActionUpdate)
+	require.NoError(t, err)
+	nextRun := time.Date(2024, time.May, 15, 11, 0, 0, 0, time.UTC)
+	expected := Schedule{ID: "1", Cron: "@hourly", Action: ActionUpdate, NextRun: &nextRun}
+	assert.Equal(t, expected, schedule)
+
+	// Schedules are persisted and loaded back.
+	schedules, err := readSchedules(scheduler.path)
+	require.NoError(t, err)
+	assert.Equal(t, []Schedule{expected}, schedules)
+	loaded := newTestScheduler(t, &fakeVPNLooper{}, logger, now)
+	loaded.load(schedules)
+	assert.Equal(t, []Schedule{expected}, loaded.GetSchedules())
+	schedule, err = loaded.Add("@daily", ActionDiagnostics)
+	require.NoError(t, err)
+	assert.Equal(t, "2", schedule.ID)
+
+	err = scheduler.Remove("2")
+	assert.EqualError(t, err, "schedule not found: 2")
+	err = scheduler.Remove("1")
+	require.NoError(t, err)
+	assert.Empty(t, scheduler.GetSchedules())
+}
+
+func Test_Scheduler_runDue(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.May, 15, 10, 32, 0, 0, time.UTC)
+	vpnLooper := &fakeVPNLooper{
+		servers: []models.ConnectedServer{
+			{ServerName: "a"}, {ServerName: "a"}, {ServerName: "b"},
+		},
+	}
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	gomock.InOrder(
+		logger.EXPECT().Info("running scheduled action rotate_server of schedule 1"),
+		logger.EXPECT().Info("scheduled action rotate_server: rotated to server b"),
+		logger.EXPECT().Info("running scheduled action diagnostics of schedule 2"),
+		logger.EXPECT().Info("scheduled action diagnostics: VPN running, server b, "+
+			"public IP 1.2.3.4, unhealthy: connection refused"),
+	)
+	scheduler := newTestScheduler(t, vpnLooper, logger, now)
+
+	_, err := scheduler.Add("* * * * *", ActionRotateServer)
+	require.NoError(t, err)
+	_, err = scheduler.Add("* * * * *", ActionDiagnostics)
+	require.NoError(t, err)
+	_, err = scheduler.Add("@daily", ActionUpdate)
+	require.NoError(t, err)
+
+	scheduler.timeNow = func() time.Time { return now.Add(time.Minute) }
+	scheduler.runDue(context.Background())
+
+	expectedStatuses := []models.LoopStatus{
+		constants.Stopped, constants.Running,
+		constants.Stopped, constants.Running,
+	}
+	assert.Equal(t, expectedStatuses, vpnLooper.statuses)
+
+	lastRun := now.Add(time.Minute)
+	nextRun := now.Add(2 * time.Minute)
+	nextDaily := time.Date(2024, time.May, 16, 0, 0, 0, 0, time.UTC)
+	expected := []Schedule{
+		{
+			ID: "1", Cron: "* * * * *", Action: ActionRotateServer,
+			NextRun: &nextRun, LastRun: &lastRun,
+			LastOutcome: "rotated to server b",
+		},
+		{
+			ID: "2", Cron: "* * * * *", Action: ActionDiagnostics,
+			NextRun: &nextRun, LastRun: &lastRun,
+			LastOutcome: "VPN running, server b, public IP 1.2.3.4, unhealthy: connection refused",
+		},
+		{
+			ID: "3", Cron: "@daily", Action: ActionUpdate,
+			NextRun: &nextDaily,
+		},
+	}
+	assert.Equal(t, expected, scheduler.GetSchedules())
+}
@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func readSchedules(path string) (schedules []Schedule, err error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading schedules file: %w", err)
+	}
+
+	err = json.Unmarshal(b, &schedules)
+	if err != nil {
+		return nil, fmt.Errorf("decoding schedules file: %w", err)
+	}
+	return schedules, nil
+}
+
+func writeSchedules(path string, schedules []Schedule) (err error) {
+	const dirPerms = os.FileMode(0700)
+	err = os.MkdirAll(filepath.Dir(path), dirPerms)
+	if err != nil {
+		return fmt.Errorf("creating schedules file directory: %w", err)
+	}
+
+	b, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding schedules: %w", err)
+	}
+
+	const perms = os.FileMode(0600)
+	err = os.WriteFile(path, b, perms)
+	if err != nil {
+		return fmt.Errorf("writing schedules file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package securedns
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	dohPath        = "/dns-query"
+	dohContentType = "application/dns-message"
+)
+
+type dohHandler struct {
+	server *Server
+}
+
+func newDoHHandler(server *Server) http.Handler {
+	return &dohHandler{server: server}
+}
+
+// ServeHTTP handles DNS over HTTPS queries as described in RFC 8484.
+func (h *dohHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != dohPath {
+		http.NotFound(w, r)
+		return
+	}
+
+	var message []byte
+	switch r.Method {
+	case http.MethodGet:
+		var err error
+		message, err = base64.RawURLEncoding.DecodeString(r.URL.Query().Get("dns"))
+		if err != nil || len(message) == 0 {
+			http.Error(w, "dns query parameter is not valid", http.StatusBadRequest)
+			return
+		}
+	case http.MethodPost:
+		if r.Header.Get("Content-Type") != dohContentType {
+			http.Error(w, "content type must be "+dohContentType, http.StatusUnsupportedMediaType)
+			return
+		}
+		const maxMessageLength = 65535
+		var err error
+		message, err = io.ReadAll(io.LimitReader(r.Body, maxMessageLength+1))
+		if err != nil {
+			http.Error(w, "reading body: "+err.Error(), http.StatusBadRequest)
+			return
+		} else if len(message) == 0 || len(message) > maxMessageLength {
+			http.Error(w, "DNS message length is not valid", http.StatusRequestEntityTooLarge)
+			return
+		}
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	const timeout = 5 * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	response, err := h.server.exchange(ctx, message)
+	if err != nil {
+		h.server.logger.Error("DNS over HTTPS: " + err.Error())
+		http.Error(w, "upstream DNS server failed", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", dohContentType)
+	_, err = w.Write(response)
+	if err != nil {
+		h.server.logger.Error("DNS over HTTPS: writing response: " + err.Error())
+	}
+}
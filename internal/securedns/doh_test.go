@@ -0,0 +1,125 @@
+package securedns
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runEchoUpstream runs a DNS over TCP server answering each
+// query with the query itself, and returns its address.
+func runEchoUpstream(t *testing.T) (address string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer connection.Close()
+				lengthPrefix := make([]byte, 2)
+				if _, err := io.ReadFull(connection, lengthPrefix); err != nil {
+					return
+				}
+				message := make([]byte, binary.BigEndian.Uint16(lengthPrefix))
+				if _, err := io.ReadFull(connection, message); err != nil {
+					return
+				}
+				_, _ = connection.Write(append(lengthPrefix, message...))
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func Test_dohHandler(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	server := &Server{
+		upstream: runEchoUpstream(t),
+		logger:   NewMockLogger(ctrl),
+		dialer:   &net.Dialer{},
+	}
+	handler := newDoHHandler(server)
+	message := []byte{0x12, 0x34, 0x01, 0x00, 0x00, 0x01}
+
+	testCases := map[string]struct {
+		request     *http.Request
+		status      int
+		contentType string
+		body        []byte
+	}{
+		"GET": {
+			request: httptest.NewRequest(http.MethodGet,
+				"/dns-query?dns="+base64.RawURLEncoding.EncodeToString(message), nil),
+			status:      http.StatusOK,
+			contentType: dohContentType,
+			body:        message,
+		},
+		"GET invalid query": {
+			request:     httptest.NewRequest(http.MethodGet, "/dns-query?dns=*", nil),
+			status:      http.StatusBadRequest,
+			contentType: "text/plain; charset=utf-8",
+			body:        []byte("dns query parameter is not valid\n"),
+		},
+		"POST": {
+			request: func() *http.Request {
+				request := httptest.NewRequest(http.MethodPost, "/dns-query", bytes.NewReader(message))
+				request.Header.Set("Content-Type", dohContentType)
+				return request
+			}(),
+			status:      http.StatusOK,
+			contentType: dohContentType,
+			body:        message,
+		},
+		"POST bad content type": {
+			request:     httptest.NewRequest(http.MethodPost, "/dns-query", bytes.NewReader(message)),
+			status:      http.StatusUnsupportedMediaType,
+			contentType: "text/plain; charset=utf-8",
+			body:        []byte("content type must be application/dns-message\n"),
+		},
+		"bad method": {
+			request:     httptest.NewRequest(http.MethodPut, "/dns-query", nil),
+			status:      http.StatusMethodNotAllowed,
+			contentType: "text/plain; charset=utf-8",
+			body:        []byte("method not allowed\n"),
+		},
+		"bad path": {
+			request:     httptest.NewRequest(http.MethodGet, "/other", nil),
+			status:      http.StatusNotFound,
+			contentType: "text/plain; charset=utf-8",
+			body:        []byte("404 page not found\n"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, testCase.request)
+
+			assert.Equal(t, testCase.status, recorder.Code)
+			assert.Equal(t, testCase.contentType, recorder.Header().Get("Content-Type"))
+			assert.Equal(t, testCase.body, recorder.Body.Bytes())
+		})
+	}
+}
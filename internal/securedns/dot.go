@@ -0,0 +1,101 @@
+package securedns
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// serveDoT accepts DNS over TLS connections on the listener given
+// until the context is canceled. Since DNS over TLS uses the same
+// length prefixed framing as DNS over TCP, each connection is relayed
+// as is to the upstream DNS server.
+func (s *Server) serveDoT(ctx context.Context, listener net.Listener) {
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.Error("accepting DNS over TLS connection: " + err.Error())
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.relayDoT(ctx, connection)
+		}()
+	}
+}
+
+func (s *Server) relayDoT(ctx context.Context, client net.Conn) {
+	defer client.Close()
+
+	// Close idle client connections, as recommended by RFC 7858 section 3.4.
+	const idleTimeout = 10 * time.Second
+	_ = client.SetDeadline(time.Now().Add(idleTimeout))
+
+	upstream, err := s.dialer.DialContext(ctx, "tcp", s.upstream)
+	if err != nil {
+		s.logger.Error("dialing upstream DNS server: " + err.Error())
+		return
+	}
+	defer upstream.Close()
+
+	connectionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-connectionCtx.Done()
+		_ = client.SetDeadline(time.Now())
+		_ = upstream.SetDeadline(time.Now())
+	}()
+
+	errs := make(chan error, 2) //nolint:gomnd
+	go func() {
+		_, err := io.Copy(upstream, &deadlineExtender{conn: client, timeout: idleTimeout})
+		errs <- err
+	}()
+	go func() {
+		_, err := io.Copy(client, upstream)
+		errs <- err
+	}()
+
+	err = <-errs
+	cancel()
+	<-errs
+	if err != nil && ctx.Err() == nil && !isTimeout(err) {
+		s.logger.Error("relaying DNS over TLS connection: " + err.Error())
+	}
+}
+
+// deadlineExtender extends the deadline of the connection
+// each time data is read from it.
+type deadlineExtender struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (d *deadlineExtender) Read(b []byte) (n int, err error) {
+	n, err = d.conn.Read(b)
+	if n > 0 {
+		_ = d.conn.SetDeadline(time.Now().Add(d.timeout))
+	}
+	return n, err
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,59 @@
+package securedns
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var ErrMessageTooLong = errors.New("DNS message is too long")
+
+// exchange sends the DNS message given to the upstream server
+// over TCP and returns the DNS response message.
+func (s *Server) exchange(ctx context.Context, message []byte) (
+	response []byte, err error) {
+	const maxMessageLength = 65535
+	if len(message) > maxMessageLength {
+		return nil, fmt.Errorf("%w: %d bytes", ErrMessageTooLong, len(message))
+	}
+
+	connection, err := s.dialer.DialContext(ctx, "tcp", s.upstream)
+	if err != nil {
+		return nil, fmt.Errorf("dialing upstream: %w", err)
+	}
+	defer connection.Close()
+
+	const timeout = 5 * time.Second
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	err = connection.SetDeadline(deadline)
+	if err != nil {
+		return nil, fmt.Errorf("setting deadline: %w", err)
+	}
+
+	const lengthPrefixSize = 2
+	query := make([]byte, lengthPrefixSize+len(message))
+	binary.BigEndian.PutUint16(query, uint16(len(message)))
+	copy(query[lengthPrefixSize:], message)
+	_, err = connection.Write(query)
+	if err != nil {
+		return nil, fmt.Errorf("writing query: %w", err)
+	}
+
+	lengthPrefix := make([]byte, lengthPrefixSize)
+	_, err = io.ReadFull(connection, lengthPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("reading response length: %w", err)
+	}
+	response = make([]byte, binary.BigEndian.Uint16(lengthPrefix))
+	_, err = io.ReadFull(connection, response)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	return response, nil
+}
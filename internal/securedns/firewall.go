@@ -0,0 +1,51 @@
+package securedns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// allowPorts allows the port of each non empty listening address
+// given through the firewall on each of the server interfaces,
+// and returns the ports allowed.
+func (s *Server) allowPorts(ctx context.Context, listeningAddresses ...string) (
+	ports []uint16, err error) {
+	for _, address := range listeningAddresses {
+		if address == "" {
+			continue
+		}
+
+		_, portString, err := net.SplitHostPort(address)
+		if err != nil {
+			return ports, fmt.Errorf("splitting listening address: %w", err)
+		}
+		const base, bitSize = 10, 16
+		portUint64, err := strconv.ParseUint(portString, base, bitSize)
+		if err != nil {
+			return ports, fmt.Errorf("parsing listening port: %w", err)
+		}
+		port := uint16(portUint64)
+
+		for _, intf := range s.interfaces {
+			err = s.portAllower.SetAllowedPort(ctx, port, intf)
+			if err != nil {
+				return ports, fmt.Errorf("allowing port %d through firewall: %w", port, err)
+			}
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+func (s *Server) blockPorts(ports []uint16) {
+	for _, port := range ports {
+		// Use a background context so the port is removed
+		// even if the server context is canceled.
+		err := s.portAllower.RemoveAllowedPort(context.Background(), port)
+		if err != nil {
+			s.logger.Error("removing port " + fmt.Sprint(port) + " from firewall: " + err.Error())
+		}
+	}
+}
@@ -0,0 +1,6 @@
+package securedns
+
+type Logger interface {
+	Info(s string)
+	Error(s string)
+}
@@ -0,0 +1,145 @@
+// Package securedns serves DNS over TLS and DNS over HTTPS,
+// forwarding queries to a plain DNS server over TCP.
+package securedns
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/tlscert"
+)
+
+type Server struct {
+	settings    settings.SecureDNSServer
+	upstream    string
+	certDir     string
+	portAllower PortAllower
+	interfaces  []string
+	logger      Logger
+	dialer      *net.Dialer
+}
+
+// New creates a secure DNS server forwarding queries to the
+// upstream plain DNS server address given, in the form host:port.
+// The listening ports are allowed through the firewall on the
+// interfaces given, so LAN clients can reach the server.
+func New(settings settings.SecureDNSServer, upstream string,
+	portAllower PortAllower, interfaces []string, logger Logger) *Server {
+	const dialTimeout = 5 * time.Second
+	return &Server{
+		settings:    settings,
+		upstream:    upstream,
+		certDir:     "/gluetun/securedns",
+		portAllower: portAllower,
+		interfaces:  interfaces,
+		logger:      logger,
+		dialer:      &net.Dialer{Timeout: dialTimeout},
+	}
+}
+
+// NewDoHHandler creates a DNS over HTTPS handler serving queries
+// on the /dns-query path, and forwarding them to the upstream plain
+// DNS server address given, in the form host:port. It is used to serve
+// DNS over HTTPS from another HTTPS server, such as the control server.
+func NewDoHHandler(upstream string, logger Logger) http.Handler {
+	const dialTimeout = 5 * time.Second
+	return newDoHHandler(&Server{
+		upstream: upstream,
+		logger:   logger,
+		dialer:   &net.Dialer{Timeout: dialTimeout},
+	})
+}
+
+// Run runs the DNS over TLS and DNS over HTTPS servers until
+// the context is canceled. Errors are logged.
+func (s *Server) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	certificate, generated, err := tlscert.LoadOrGenerate(*s.settings.CertificateFile,
+		*s.settings.KeyFile, s.certDir, s.settings.Hostnames)
+	if err != nil {
+		s.logger.Error("getting TLS certificate: " + err.Error())
+		return
+	} else if generated {
+		s.logger.Info("generated self-signed certificate in " + s.certDir)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	ports, err := s.allowPorts(ctx, *s.settings.DoTAddress, *s.settings.DoHAddress)
+	defer s.blockPorts(ports)
+	if err != nil {
+		s.logger.Error(err.Error())
+		return
+	}
+
+	wg := &sync.WaitGroup{}
+
+	if address := *s.settings.DoTAddress; address != "" {
+		listener, err := tls.Listen("tcp", address, tlsConfig)
+		if err != nil {
+			s.logger.Error("listening for DNS over TLS: " + err.Error())
+		} else {
+			s.logger.Info("DNS over TLS listening on " + address)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.serveDoT(ctx, listener)
+			}()
+		}
+	}
+
+	if address := *s.settings.DoHAddress; address != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.serveDoH(ctx, address, tlsConfig)
+			if err != nil {
+				s.logger.Error("serving DNS over HTTPS: " + err.Error())
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (s *Server) serveDoH(ctx context.Context, address string,
+	tlsConfig *tls.Config) (err error) {
+	const readHeaderTimeout = 3 * time.Second
+	const readTimeout = 5 * time.Second
+	server := &http.Server{
+		Addr:              address,
+		Handler:           newDoHHandler(s),
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+	}
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		const shutdownGraceDuration = 100 * time.Millisecond
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownGraceDuration)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			s.logger.Error("failed shutting down DNS over HTTPS server: " + err.Error())
+		}
+	}()
+
+	s.logger.Info("DNS over HTTPS listening on " + address + dohPath)
+	err = server.ListenAndServeTLS("", "")
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("listening: %w", err)
+	}
+	<-shutdownDone
+	return nil
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// withAPIKeyMiddleware requires a valid API key in the Authorization
+// Bearer header or in the X-API-Key header for all the routes.
+// Read only API keys are only accepted for requests not mutating the
+// state of gluetun nor reading secrets. If healthExempt is true, GET /health and GET /ready
+// requests do not require an API key. The static files of the web user interface never
+// require an API key, since the interface asks for it to call the API.
+// No API key disables the middleware.
+func withAPIKeyMiddleware(childHandler http.Handler,
+	apiKeys []settings.ControlServerAPIKey, healthExempt bool) http.Handler {
+	if len(apiKeys) == 0 {
+		return childHandler
+	}
+
+	keys := make([]apiKey, len(apiKeys))
+	for i, key := range apiKeys {
+		keys[i] = apiKey{
+			role:   key.Role,
+			digest: sha256.Sum256([]byte(key.Key)),
+		}
+	}
+
+	return &apiKeyMiddleware{
+		childHandler: childHandler,
+		keys:         keys,
+		healthExempt: healthExempt,
+	}
+}
+
+type apiKey struct {
+	role string
+	// digest is the SHA256 digest of the key, such that keys
+	// of different lengths are compared in constant time.
+	digest [sha256.Size]byte
+}
+
+type apiKeyMiddleware struct {
+	childHandler http.Handler
+	keys         []apiKey
+	healthExempt bool
+}
+
+func (m *apiKeyMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if m.healthExempt && isHealthRequest(r) {
+		m.childHandler.ServeHTTP(w, r)
+		return
+	} else if isUIRequest(r) {
+		m.childHandler.ServeHTTP(w, r)
+		return
+	}
+
+	role, ok := m.role(requestAPIKey(r))
+	switch {
+	case !ok:
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "API key is missing or not valid", http.StatusUnauthorized)
+		return
+	case role == settings.APIKeyRoleReadOnly && isMutatingRequest(r):
+		http.Error(w, "API key role "+role+" cannot change the state of gluetun",
+			http.StatusForbidden)
+		return
+	case role != settings.APIKeyRoleAdmin && isSecretRequest(r):
+		http.Error(w, "API key role "+role+" cannot read secrets",
+			http.StatusForbidden)
+		return
+	}
+
+	m.childHandler.ServeHTTP(w, r)
+}
+
+// isHealthRequest returns true for GET requests to the health
+// routes, matching on the URL path to ignore any query string.
+func isHealthRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	return path == "/health" || path == "/ready"
+}
+
+// isSecretRequest returns true for requests to routes responding
+// with secrets, which is the Wireguard client configuration route
+// since it contains the peer private key.
+func isSecretRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/v1/wireguard/server/peers/")
+}
+
+func requestAPIKey(r *http.Request) (key string) {
+	authorization := r.Header.Get("Authorization")
+	const bearerPrefix = "Bearer "
+	if len(authorization) > len(bearerPrefix) &&
+		strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		return authorization[len(bearerPrefix):]
+	}
+	return r.Header.Get(apiKeyHeader)
+}
+
+// role returns the role of the key given, and false if the key
+// does not match any API key. All the API keys are compared,
+// to not leak through timing which key matched.
+func (m *apiKeyMiddleware) role(key string) (role string, ok bool) {
+	if key == "" {
+		return "", false
+	}
+	digest := sha256.Sum256([]byte(key))
+	for _, apiKey := range m.keys {
+		if subtle.ConstantTimeCompare(digest[:], apiKey.digest[:]) == 1 {
+			role, ok = apiKey.role, true
+		}
+	}
+	return role, ok
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_apiKeyMiddleware(t *testing.T) {
+	t.Parallel()
+
+	childHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	apiKeys := []settings.ControlServerAPIKey{
+		{Role: settings.APIKeyRoleAdmin, Key: "admin-key-0123456789"},
+		{Role: settings.APIKeyRoleReadOnly, Key: "readonly-key-0123456789"},
+	}
+	middleware := withAPIKeyMiddleware(childHandler, apiKeys, true)
+
+	serve := func(method, uri, authorization, apiKey string) (statusCode int) {
+		request := httptest.NewRequest(method, uri, nil)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		if apiKey != "" {
+			request.Header.Set(apiKeyHeader, apiKey)
+		}
+		recorder := httptest.NewRecorder()
+		middleware.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/health", "", ""))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/health/?verbose=1", "", ""))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/ready", "", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPut, "/health", "", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/v1/vpn/status?/health", "", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/v1/vpn/status", "", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/v1/vpn/status", "Bearer wrong", ""))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/v1/vpn/status", "Bearer admin-key-0123456789", ""))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPut, "/v1/vpn/status", "bearer admin-key-0123456789", ""))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/v1/vpn/status", "", "readonly-key-0123456789"))
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPut, "/v1/vpn/status", "", "readonly-key-0123456789"))
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodGet, "/updater/restart", "", "readonly-key-0123456789"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/v1/wireguard/server/peers", "", "readonly-key-0123456789"))
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodGet,
+		"/v1/wireguard/server/peers/phone", "", "readonly-key-0123456789"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet,
+		"/v1/wireguard/server/peers/phone", "", "admin-key-0123456789"))
+
+	notExempt := withAPIKeyMiddleware(childHandler, apiKeys, false)
+	for _, path := range []string{"/health", "/ready"} {
+		recorder := httptest.NewRecorder()
+		notExempt.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	}
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+func withCacheMiddleware(childHandler http.Handler, ttl time.Duration,
+	cachedPaths ...string) *cacheMiddleware {
+	cachedPathsSet := make(map[string]struct{}, len(cachedPaths))
+	for _, path := range cachedPaths {
+		cachedPathsSet[path] = struct{}{}
+	}
+
+	return &cacheMiddleware{
+		childHandler: childHandler,
+		ttl:          ttl,
+		cachedPaths:  cachedPathsSet,
+		pathToEntry:  make(map[string]cacheEntry, len(cachedPaths)),
+		timeNow:      time.Now,
+	}
+}
+
+// cacheMiddleware caches GET responses of heavy endpoints for a short
+// duration, and sets an ETag header on them so clients can skip
+// downloading unchanged responses using the If-None-Match header.
+// Any non GET request invalidates the entire cache, since it may
+// change the state of the program.
+type cacheMiddleware struct {
+	childHandler http.Handler
+	ttl          time.Duration
+	cachedPaths  map[string]struct{}
+	pathToEntry  map[string]cacheEntry
+	timeNow      func() time.Time
+	mutex        sync.Mutex
+}
+
+type cacheEntry struct {
+	header  http.Header
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+func (m *cacheMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		m.invalidate()
+		m.childHandler.ServeHTTP(w, r)
+		return
+	}
+
+	path := strings.TrimSuffix(r.RequestURI, "/")
+	if _, ok := m.cachedPaths[path]; !ok {
+		m.childHandler.ServeHTTP(w, r)
+		return
+	}
+
+	entry, ok := m.get(path)
+	if !ok {
+		recorder := &recordingResponseWriter{
+			header:     make(http.Header),
+			statusCode: http.StatusOK,
+		}
+		m.childHandler.ServeHTTP(recorder, r)
+		if recorder.statusCode != http.StatusOK {
+			recorder.writeTo(w)
+			return
+		}
+		entry = m.set(path, recorder.header, recorder.body.Bytes())
+	}
+
+	for key, values := range entry.header {
+		w.Header()[key] = values
+	}
+	w.Header().Set("ETag", entry.etag)
+	if r.Header.Get("If-None-Match") == entry.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	_, _ = w.Write(entry.body)
+}
+
+func (m *cacheMiddleware) get(path string) (entry cacheEntry, ok bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	entry, ok = m.pathToEntry[path]
+	if !ok || m.timeNow().After(entry.expires) {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (m *cacheMiddleware) set(path string, header http.Header,
+	body []byte) (entry cacheEntry) {
+	digest := sha256.Sum256(body)
+	const etagLength = 16
+	entry = cacheEntry{
+		header:  header,
+		body:    body,
+		etag:    `"` + hex.EncodeToString(digest[:])[:etagLength] + `"`,
+		expires: m.timeNow().Add(m.ttl),
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.pathToEntry[path] = entry
+	return entry
+}
+
+func (m *cacheMiddleware) invalidate() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for path := range m.pathToEntry {
+		delete(m.pathToEntry, path)
+	}
+}
+
+// recordingResponseWriter records the response of a handler.
+type recordingResponseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *recordingResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *recordingResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+}
+
+func (w *recordingResponseWriter) Write(b []byte) (n int, err error) {
+	return w.body.Write(b)
+}
+
+func (w *recordingResponseWriter) writeTo(httpWriter http.ResponseWriter) {
+	for key, values := range w.header {
+		httpWriter.Header()[key] = values
+	}
+	httpWriter.WriteHeader(w.statusCode)
+	_, _ = httpWriter.Write(w.body.Bytes())
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_cacheMiddleware(t *testing.T) {
+	t.Parallel()
+
+	const ttl = time.Second
+	const etagState1 = `"2780e2d008eba4f8"`
+
+	type request struct {
+		method      string
+		path        string
+		ifNoneMatch string
+		// elapsed is the time elapsed since the previous request.
+		elapsed time.Duration
+	}
+
+	testCases := map[string]struct {
+		requests   []request
+		statusCode int
+		etag       string
+		body       string
+		childCalls int
+	}{
+		"first request": {
+			requests:   []request{{method: http.MethodGet, path: "/v1/servers"}},
+			statusCode: http.StatusOK,
+			etag:       etagState1,
+			body:       "state 1",
+			childCalls: 1,
+		},
+		"cached response": {
+			requests: []request{
+				{method: http.MethodGet, path: "/v1/servers"},
+				{method: http.MethodGet, path: "/v1/servers/", elapsed: ttl},
+			},
+			statusCode: http.StatusOK,
+			etag:       etagState1,
+			body:       "state 1",
+			childCalls: 1,
+		},
+		"not modified": {
+			requests: []request{
+				{method: http.MethodGet, path: "/v1/servers"},
+				{method: http.MethodGet, path: "/v1/servers", ifNoneMatch: etagState1},
+			},
+			statusCode: http.StatusNotModified,
+			etag:       etagState1,
+			childCalls: 1,
+		},
+		"etag mismatch": {
+			requests: []request{
+				{method: http.MethodGet, path: "/v1/servers"},
+				{method: http.MethodGet, path: "/v1/servers", ifNoneMatch: `"other"`},
+			},
+			statusCode: http.StatusOK,
+			etag:       etagState1,
+			body:       "state 1",
+			childCalls: 1,
+		},
+		"ttl expired": {
+			requests: []request{
+				{method: http.MethodGet, path: "/v1/servers"},
+				{method: http.MethodGet, path: "/v1/servers", elapsed: ttl + time.Nanosecond},
+			},
+			statusCode: http.StatusOK,
+			etag:       `"a130a9a4785f14da"`,
+			body:       "state 2",
+			childCalls: 2,
+		},
+		"invalidated by state change": {
+			requests: []request{
+				{method: http.MethodGet, path: "/v1/servers"},
+				{method: http.MethodPut, path: "/v1/vpn/status"},
+				{method: http.MethodGet, path: "/v1/servers", ifNoneMatch: etagState1},
+			},
+			statusCode: http.StatusOK,
+			etag:       `"437248afd69ac74d"`,
+			body:       "state 3",
+			childCalls: 3,
+		},
+		"path not cached": {
+			requests: []request{
+				{method: http.MethodGet, path: "/v1/vpn/status"},
+				{method: http.MethodGet, path: "/v1/vpn/status"},
+			},
+			statusCode: http.StatusOK,
+			body:       "state 2",
+			childCalls: 2,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			childCalls := 0
+			childHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				childCalls++
+				if r.Method != http.MethodGet {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				w.Header().Set("Content-Type", "text/plain")
+				_, _ = fmt.Fprintf(w, "state %d", childCalls)
+			})
+			now := time.Unix(0, 0)
+			middleware := withCacheMiddleware(childHandler, ttl, "/v1/servers")
+			middleware.timeNow = func() time.Time { return now }
+
+			var recorder *httptest.ResponseRecorder
+			for _, request := range testCase.requests {
+				now = now.Add(request.elapsed)
+				httpRequest := httptest.NewRequest(request.method, request.path, nil)
+				if request.ifNoneMatch != "" {
+					httpRequest.Header.Set("If-None-Match", request.ifNoneMatch)
+				}
+				recorder = httptest.NewRecorder()
+				middleware.ServeHTTP(recorder, httpRequest)
+			}
+
+			assert.Equal(t, testCase.statusCode, recorder.Code)
+			assert.Equal(t, testCase.etag, recorder.Header().Get("ETag"))
+			assert.Equal(t, testCase.body, recorder.Body.String())
+			assert.Equal(t, testCase.childCalls, childCalls)
+			if testCase.etag != "" {
+				assert.Equal(t, "text/plain", recorder.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// settingsCanary applies VPN settings changed through the control server
+// and rolls back to the previous VPN settings if the VPN is not healthy
+// at the end of the rollback window.
+type settingsCanary struct {
+	ctx       context.Context //nolint:containedctx
+	looper    VPNLooper
+	readiness ReadinessChecker
+	window    time.Duration
+	logger    infoWarner
+	timeNow   func() time.Time
+
+	mutex sync.Mutex
+	// generation is incremented on each settings change so a
+	// monitoring goroutine can detect it has been superseded.
+	generation uint64
+	// previous is the last VPN settings known to be healthy,
+	// and is only set while a canary is in progress.
+	previous  settings.VPN
+	deadline  time.Time
+	rollbacks []rollbackEvent
+}
+
+type rollbackEvent struct {
+	Time   time.Time `json:"time"`
+	Reason string    `json:"reason"`
+}
+
+type canaryStatus struct {
+	InProgress bool            `json:"in_progress"`
+	Deadline   *time.Time      `json:"deadline,omitempty"`
+	Rollbacks  []rollbackEvent `json:"rollbacks"`
+}
+
+func newSettingsCanary(ctx context.Context, looper VPNLooper,
+	readiness ReadinessChecker, window time.Duration,
+	logger infoWarner) *settingsCanary {
+	return &settingsCanary{
+		ctx:       ctx,
+		looper:    looper,
+		readiness: readiness,
+		window:    window,
+		logger:    logger,
+		timeNow:   time.Now,
+		rollbacks: []rollbackEvent{},
+	}
+}
+
+// apply applies the updated VPN settings and, if the VPN is healthy
+// with its current settings, monitors the VPN health with the updated
+// settings in the background.
+func (c *settingsCanary) apply(updated settings.VPN) (outcome string) {
+	if c.window == 0 {
+		return c.looper.SetSettings(c.ctx, updated)
+	}
+
+	c.mutex.Lock()
+	if c.deadline.IsZero() {
+		// Changes made during a canary keep the settings
+		// snapshot from before the first change.
+		if c.readiness.Ready() != nil {
+			// No healthy settings to roll back to.
+			c.mutex.Unlock()
+			return c.looper.SetSettings(c.ctx, updated)
+		}
+		c.previous = c.looper.GetSettings() // already copied
+	}
+	c.generation++
+	c.deadline = c.timeNow().Add(c.window)
+	go c.monitor(c.generation)
+	c.mutex.Unlock()
+
+	return c.looper.SetSettings(c.ctx, updated)
+}
+
+func (c *settingsCanary) monitor(generation uint64) {
+	timer := time.NewTimer(c.window)
+	select {
+	case <-c.ctx.Done():
+		timer.Stop()
+		return
+	case <-timer.C:
+	}
+
+	c.mutex.Lock()
+	if generation != c.generation {
+		c.mutex.Unlock()
+		return // superseded by a more recent settings change
+	}
+	c.deadline = time.Time{}
+
+	err := c.readiness.Ready()
+	if err == nil {
+		c.mutex.Unlock()
+		c.logger.Info("VPN is healthy " + c.window.String() + " after its settings change")
+		return
+	}
+
+	c.rollbacks = append(c.rollbacks, rollbackEvent{
+		Time:   c.timeNow(),
+		Reason: err.Error(),
+	})
+	previous := c.previous
+	c.mutex.Unlock()
+
+	c.logger.Warn("rolling back VPN settings since the VPN is unhealthy " +
+		c.window.String() + " after its settings change: " + err.Error())
+	outcome := c.looper.SetSettings(c.ctx, previous)
+	c.logger.Info(outcome)
+}
+
+func (c *settingsCanary) getStatus() (status canaryStatus) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	status = canaryStatus{
+		InProgress: !c.deadline.IsZero(),
+		Rollbacks:  make([]rollbackEvent, len(c.rollbacks)),
+	}
+	copy(status.Rollbacks, c.rollbacks)
+	if status.InProgress {
+		deadline := c.deadline
+		status.Deadline = &deadline
+	}
+	return status
+}
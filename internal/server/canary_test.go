@@ -0,0 +1,227 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVPNLooper struct {
+	mutex    sync.Mutex
+	settings settings.VPN
+	applied  []settings.VPN
+}
+
+func (f *fakeVPNLooper) GetStatus() (status models.LoopStatus) { return "" }
+
+func (f *fakeVPNLooper) ApplyStatus(context.Context, models.LoopStatus) (
+	outcome string, err error) {
+	return "", nil
+}
+
+func (f *fakeVPNLooper) GetSettings() (vpnSettings settings.VPN) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.settings
+}
+
+func (f *fakeVPNLooper) SetSettings(_ context.Context, vpnSettings settings.VPN) (outcome string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.settings = vpnSettings
+	f.applied = append(f.applied, vpnSettings)
+	return "settings applied to " + vpnSettings.Type
+}
+
+func (f *fakeVPNLooper) GetConnectedServer() (server models.ConnectedServer, ok bool) {
+	return server, false
+}
+
+func (f *fakeVPNLooper) getApplied() (applied []settings.VPN) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]settings.VPN(nil), f.applied...)
+}
+
+type fakeReadiness struct {
+	mutex sync.Mutex
+	err   error
+}
+
+func (f *fakeReadiness) Ready() (err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.err
+}
+
+func (f *fakeReadiness) setErr(err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.err = err
+}
+
+type fakeInfoWarner struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (f *fakeInfoWarner) Info(message string) { f.log("info: " + message) }
+func (f *fakeInfoWarner) Warn(message string) { f.log("warn: " + message) }
+
+func (f *fakeInfoWarner) log(message string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.messages = append(f.messages, message)
+}
+
+func (f *fakeInfoWarner) getMessages() (messages []string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]string(nil), f.messages...)
+}
+
+func Test_settingsCanary_apply(t *testing.T) {
+	t.Parallel()
+
+	errNotReady := errors.New("VPN is not healthy")
+	previous := settings.VPN{Type: vpn.OpenVPN}
+	updated := settings.VPN{Type: vpn.Wireguard}
+	now := time.Unix(1000, 0).UTC()
+
+	t.Run("no rollback window", func(t *testing.T) {
+		t.Parallel()
+
+		looper := &fakeVPNLooper{settings: previous}
+		canary := newSettingsCanary(context.Background(), looper,
+			&fakeReadiness{}, 0, &fakeInfoWarner{})
+
+		outcome := canary.apply(updated)
+
+		assert.Equal(t, "settings applied to wireguard", outcome)
+		assert.Equal(t, []settings.VPN{updated}, looper.getApplied())
+		assert.Equal(t, canaryStatus{Rollbacks: []rollbackEvent{}}, canary.getStatus())
+	})
+
+	t.Run("unhealthy before the change", func(t *testing.T) {
+		t.Parallel()
+
+		looper := &fakeVPNLooper{settings: previous}
+		readiness := &fakeReadiness{err: errNotReady}
+		canary := newSettingsCanary(context.Background(), looper,
+			readiness, time.Hour, &fakeInfoWarner{})
+
+		outcome := canary.apply(updated)
+
+		assert.Equal(t, "settings applied to wireguard", outcome)
+		assert.Equal(t, []settings.VPN{updated}, looper.getApplied())
+		assert.Equal(t, canaryStatus{Rollbacks: []rollbackEvent{}}, canary.getStatus())
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		looper := &fakeVPNLooper{settings: previous}
+		canary := newSettingsCanary(ctx, looper,
+			&fakeReadiness{}, time.Hour, &fakeInfoWarner{})
+		canary.timeNow = func() time.Time { return now }
+
+		canary.apply(updated)
+
+		deadline := now.Add(time.Hour)
+		assert.Equal(t, canaryStatus{
+			InProgress: true,
+			Deadline:   &deadline,
+			Rollbacks:  []rollbackEvent{},
+		}, canary.getStatus())
+	})
+
+	t.Run("healthy after the change", func(t *testing.T) {
+		t.Parallel()
+
+		looper := &fakeVPNLooper{settings: previous}
+		logger := &fakeInfoWarner{}
+		const window = time.Millisecond
+		canary := newSettingsCanary(context.Background(), looper,
+			&fakeReadiness{}, window, logger)
+
+		canary.apply(updated)
+
+		assert.Eventually(t, func() bool {
+			return len(logger.getMessages()) > 0
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, []string{"info: VPN is healthy 1ms after its settings change"},
+			logger.getMessages())
+		assert.Equal(t, []settings.VPN{updated}, looper.getApplied())
+		assert.Equal(t, canaryStatus{Rollbacks: []rollbackEvent{}}, canary.getStatus())
+	})
+
+	t.Run("unhealthy after the change", func(t *testing.T) {
+		t.Parallel()
+
+		looper := &fakeVPNLooper{settings: previous}
+		readiness := &fakeReadiness{}
+		logger := &fakeInfoWarner{}
+		const window = 10 * time.Millisecond
+		canary := newSettingsCanary(context.Background(), looper,
+			readiness, window, logger)
+		canary.timeNow = func() time.Time { return now }
+
+		canary.apply(updated)
+		readiness.setErr(errNotReady)
+
+		assert.Eventually(t, func() bool {
+			return len(logger.getMessages()) == 2 //nolint:gomnd
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, []string{
+			"warn: rolling back VPN settings since the VPN is unhealthy " +
+				"10ms after its settings change: VPN is not healthy",
+			"info: settings applied to openvpn",
+		}, logger.getMessages())
+		assert.Equal(t, []settings.VPN{updated, previous}, looper.getApplied())
+		assert.Equal(t, canaryStatus{
+			Rollbacks: []rollbackEvent{{Time: now, Reason: "VPN is not healthy"}},
+		}, canary.getStatus())
+	})
+}
+
+func Test_vpnHandler_getRollback(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	looper := &fakeVPNLooper{settings: settings.VPN{Type: vpn.OpenVPN}}
+	canary := newSettingsCanary(ctx, looper, &fakeReadiness{},
+		time.Hour, &fakeInfoWarner{})
+	canary.timeNow = func() time.Time { return time.Unix(1000, 0).UTC() }
+	handler := newVPNHandler(ctx, looper, nil, canary, nil, nil,
+		nil, nil, false, nil)
+
+	serve := func() (statusCode int, responseBody string) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/vpn/settings/rollback", nil)
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code, recorder.Body.String()
+	}
+
+	code, body := serve()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"in_progress":false,"rollbacks":[]}`+"\n", body)
+
+	canary.apply(settings.VPN{Type: vpn.Wireguard})
+
+	code, body = serve()
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"in_progress":true,"deadline":"1970-01-01T01:16:40Z","rollbacks":[]}`+"\n", body)
+}
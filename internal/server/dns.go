@@ -3,8 +3,11 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
+
+	"github.com/qdm12/gluetun/internal/dns"
 )
 
 func newDNSHandler(ctx context.Context, loop DNSLoop,
@@ -34,6 +37,38 @@ func (h *dnsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		default:
 			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
 		}
+	case "/hosts":
+		switch r.Method {
+		case http.MethodGet:
+			h.getHosts(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case "/hosts/blocked":
+		switch r.Method {
+		case http.MethodPut:
+			h.setHost(w, r, h.loop.BlockHost)
+		case http.MethodDelete:
+			h.setHost(w, r, h.loop.UnblockHost)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case "/hosts/allowed":
+		switch r.Method {
+		case http.MethodPut:
+			h.setHost(w, r, h.loop.AllowHost)
+		case http.MethodDelete:
+			h.setHost(w, r, h.loop.DisallowHost)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case "/cache/stats":
+		switch r.Method {
+		case http.MethodGet:
+			h.getCacheStats(w, r)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
 	default:
 		http.Error(w, "route "+r.RequestURI+" not supported", http.StatusBadRequest)
 	}
@@ -74,3 +109,57 @@ func (h *dnsHandler) setStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (h *dnsHandler) getHosts(w http.ResponseWriter) {
+	hosts, err := h.loop.GetRuntimeHosts()
+	if err != nil {
+		h.warner.Warn(err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(hosts); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *dnsHandler) getCacheStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.loop.GetCacheStats(r.Context())
+	if errors.Is(err, dns.ErrNotRunning) {
+		http.Error(w, "DNS server is not running", http.StatusServiceUnavailable)
+		return
+	} else if err != nil {
+		h.warner.Warn(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(stats); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *dnsHandler) setHost(w http.ResponseWriter, r *http.Request,
+	setHost func(ctx context.Context, host string) (outcome string, err error)) {
+	decoder := json.NewDecoder(r.Body)
+	var data hostWrapper
+	if err := decoder.Decode(&data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	outcome, err := setHost(h.ctx, data.Host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(outcomeWrapper{Outcome: outcome}); err != nil {
+		h.warner.Warn(err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
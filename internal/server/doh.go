@@ -0,0 +1,36 @@
+package server
+
+import (
+	"net/http"
+)
+
+const dohPath = "/dns-query"
+
+// withDoHRoute returns a handler serving requests to the DNS over
+// HTTPS path with the DoH handler given, and all other requests with
+// the child handler given. DNS over HTTPS requests therefore bypass
+// the authentication middlewares of the child handler, since DNS over
+// HTTPS clients such as web browsers cannot be configured to send
+// credentials. If the DoH handler is nil, the child handler is returned.
+func withDoHRoute(childHandler, dohHandler http.Handler) http.Handler {
+	if dohHandler == nil {
+		return childHandler
+	}
+	return &dohRoute{
+		childHandler: childHandler,
+		dohHandler:   dohHandler,
+	}
+}
+
+type dohRoute struct {
+	childHandler http.Handler
+	dohHandler   http.Handler
+}
+
+func (d *dohRoute) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == dohPath {
+		d.dohHandler.ServeHTTP(w, r)
+		return
+	}
+	d.childHandler.ServeHTTP(w, r)
+}
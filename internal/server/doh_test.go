@@ -0,0 +1,33 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_withDoHRoute(t *testing.T) {
+	t.Parallel()
+
+	childHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	dohHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(handler http.Handler, uri string) (statusCode int) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, uri, nil))
+		return recorder.Code
+	}
+
+	handler := withDoHRoute(childHandler, dohHandler)
+	assert.Equal(t, http.StatusOK, serve(handler, "/dns-query?dns=AAABAAABAAAAAAAAA2NvbQAAAQAB"))
+	assert.Equal(t, http.StatusUnauthorized, serve(handler, "/v1/vpn/status"))
+
+	handler = withDoHRoute(childHandler, nil)
+	assert.Equal(t, http.StatusUnauthorized, serve(handler, "/dns-query"))
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/events"
+)
+
+type EventsBroker interface {
+	eventPublisher
+	Subscribe() (events <-chan events.Event, unsubscribe func())
+}
+
+type eventPublisher interface {
+	Publish(eventType string, data interface{})
+}
+
+func newEventsHandler(ctx context.Context, broker EventsBroker, w warner) http.Handler {
+	return &eventsHandler{
+		ctx:    ctx,
+		broker: broker,
+		warner: w,
+	}
+}
+
+type eventsHandler struct {
+	ctx    context.Context //nolint:containedctx
+	broker EventsBroker
+	warner warner
+}
+
+func (h *eventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.RequestURI = strings.TrimPrefix(r.RequestURI, "/events")
+	switch r.RequestURI {
+	case "":
+		switch r.Method {
+		case http.MethodGet:
+			h.stream(w, r)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	default:
+		http.Error(w, "route "+r.RequestURI+" not supported", http.StatusBadRequest)
+	}
+}
+
+// stream streams events as server-sent events until the client
+// disconnects or the server shuts down. A comment line is sent
+// periodically so proxies do not close idle streams.
+func (h *eventsHandler) stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := h.broker.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	const keepAlivePeriod = 30 * time.Second
+	keepAlive := time.NewTicker(keepAlivePeriod)
+	defer keepAlive.Stop()
+
+	for {
+		var message []byte
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			message = []byte(": keep-alive\n\n")
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.warner.Warn("encoding event: " + err.Error())
+				continue
+			}
+			message = []byte("event: " + event.Type + "\ndata: " + string(data) + "\n\n")
+		}
+
+		_, err := w.Write(message)
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/droplog"
+	"github.com/qdm12/gluetun/internal/firewall"
+)
+
+type FirewallPortOpener interface {
+	OpenTemporaryPort(ctx context.Context, port uint16, ttl time.Duration) (
+		opened firewall.TemporaryPort, err error)
+	CloseTemporaryPort(ctx context.Context, port uint16) (err error)
+	GetTemporaryPorts() (ports []firewall.TemporaryPort)
+}
+
+type FirewallPlanner interface {
+	Plan(ctx context.Context) (plan firewall.Plan, err error)
+}
+
+type DroppedPacketsGetter interface {
+	GetStats() (stats droplog.Stats)
+}
+
+func newFirewallHandler(ctx context.Context, portOpener FirewallPortOpener,
+	planner FirewallPlanner, droppedPackets DroppedPacketsGetter,
+	warner warner) http.Handler {
+	return &firewallHandler{
+		ctx:            ctx,
+		portOpener:     portOpener,
+		planner:        planner,
+		droppedPackets: droppedPackets,
+		warner:         warner,
+	}
+}
+
+type firewallHandler struct {
+	ctx            context.Context //nolint:containedctx
+	portOpener     FirewallPortOpener
+	planner        FirewallPlanner
+	droppedPackets DroppedPacketsGetter
+	warner         warner
+}
+
+func (h *firewallHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.RequestURI = strings.TrimPrefix(r.RequestURI, "/firewall")
+	switch {
+	case r.RequestURI == "/ports":
+		switch r.Method {
+		case http.MethodGet:
+			h.getTemporaryPorts(w)
+		case http.MethodPost:
+			h.openTemporaryPort(w, r)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case r.RequestURI == "/plan":
+		switch r.Method {
+		case http.MethodGet:
+			h.getPlan(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case r.RequestURI == "/dropped":
+		switch r.Method {
+		case http.MethodGet:
+			h.getDroppedPackets(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case strings.HasPrefix(r.RequestURI, "/ports/"):
+		switch r.Method {
+		case http.MethodDelete:
+			h.closeTemporaryPort(w, strings.TrimPrefix(r.RequestURI, "/ports/"))
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	default:
+		http.Error(w, "route "+r.RequestURI+" not supported", http.StatusBadRequest)
+	}
+}
+
+type temporaryPortsWrapper struct {
+	Ports []firewall.TemporaryPort `json:"ports"`
+}
+
+// getTemporaryPorts returns the input ports opened temporarily
+// with their remaining time.
+func (h *firewallHandler) getTemporaryPorts(w http.ResponseWriter) {
+	data := temporaryPortsWrapper{Ports: h.portOpener.GetTemporaryPorts()}
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(data); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// getPlan returns the firewall instructions run to enable
+// the firewall with its current state, without running them.
+func (h *firewallHandler) getPlan(w http.ResponseWriter) {
+	plan, err := h.planner.Plan(h.ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(plan); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// getDroppedPackets returns the counters of packets
+// dropped by the firewall.
+func (h *firewallHandler) getDroppedPackets(w http.ResponseWriter) {
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(h.droppedPackets.GetStats()); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+type temporaryPortRequest struct {
+	Port uint16 `json:"port"`
+	// TTL is the time to live of the port opening,
+	// for example 2h or 30m.
+	TTL string `json:"ttl"`
+}
+
+// openTemporaryPort opens an input port for a limited time.
+func (h *firewallHandler) openTemporaryPort(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	var data temporaryPortRequest
+	if err := decoder.Decode(&data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ttl, err := time.ParseDuration(data.TTL)
+	if err != nil {
+		http.Error(w, "ttl: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opened, err := h.portOpener.OpenTemporaryPort(h.ctx, data.Port, ttl)
+	switch {
+	case errors.Is(err, firewall.ErrPortNotValid),
+		errors.Is(err, firewall.ErrTemporaryPortTTLInvalid),
+		errors.Is(err, firewall.ErrTemporaryPortTTLTooLong):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(opened); err != nil {
+		h.warner.Warn(err.Error())
+		return
+	}
+}
+
+// closeTemporaryPort closes a temporary input port before
+// its time to live elapses.
+func (h *firewallHandler) closeTemporaryPort(w http.ResponseWriter, portString string) {
+	const base, bitSize = 10, 16
+	port, err := strconv.ParseUint(portString, base, bitSize)
+	if err != nil {
+		http.Error(w, "port: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.portOpener.CloseTemporaryPort(h.ctx, uint16(port))
+	switch {
+	case errors.Is(err, firewall.ErrTemporaryPortNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(outcomeWrapper{Outcome: "closed"}); err != nil {
+		h.warner.Warn(err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/droplog"
+	"github.com/qdm12/gluetun/internal/firewall"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePortOpener struct {
+	ports map[uint16]time.Duration
+}
+
+func (f *fakePortOpener) OpenTemporaryPort(_ context.Context, port uint16,
+	ttl time.Duration) (opened firewall.TemporaryPort, err error) {
+	if port == 80 {
+		return opened, fmt.Errorf("%w: %s", firewall.ErrTemporaryPortTTLTooLong, ttl)
+	}
+	f.ports[port] = ttl
+	return firewall.TemporaryPort{Port: port, RemainingSeconds: uint64(ttl.Seconds())}, nil
+}
+
+func (f *fakePortOpener) CloseTemporaryPort(_ context.Context, port uint16) error {
+	if _, ok := f.ports[port]; !ok {
+		return fmt.Errorf("%w: %d", firewall.ErrTemporaryPortNotFound, port)
+	}
+	delete(f.ports, port)
+	return nil
+}
+
+func (f *fakePortOpener) GetTemporaryPorts() (ports []firewall.TemporaryPort) {
+	for port, ttl := range f.ports {
+		ports = append(ports, firewall.TemporaryPort{Port: port, RemainingSeconds: uint64(ttl.Seconds())})
+	}
+	return ports
+}
+
+type fakePlanner struct{}
+
+func (fakePlanner) Plan(context.Context) (firewall.Plan, error) {
+	return firewall.Plan{Backend: "iptables", IPv4: []string{"--policy INPUT DROP"}}, nil
+}
+
+type fakeDroppedPackets struct{}
+
+func (fakeDroppedPackets) GetStats() droplog.Stats {
+	return droplog.Stats{Enabled: true, Total: 1, Chains: map[string]uint64{"output": 1}}
+}
+
+func Test_firewallHandler(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	portOpener := &fakePortOpener{ports: map[uint16]time.Duration{}}
+	handler := newFirewallHandler(context.Background(), portOpener,
+		fakePlanner{}, fakeDroppedPackets{}, logger)
+
+	serve := func(method, uri, body string) (statusCode int, responseBody string) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, uri, strings.NewReader(body))
+		request.RequestURI = uri
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code, recorder.Body.String()
+	}
+
+	code, body := serve(http.MethodPost, "/firewall/ports", `{"port":8080,"ttl":"2h"}`)
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, `{"port":8080,"expires_at":"0001-01-01T00:00:00Z","remaining_seconds":7200}`+"\n", body)
+
+	code, _ = serve(http.MethodPost, "/firewall/ports", `{"port":8081,"ttl":"forever"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = serve(http.MethodPost, "/firewall/ports", `{"port":80,"ttl":"48h"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, body = serve(http.MethodGet, "/firewall/ports", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"ports":[{"port":8080,"expires_at":"0001-01-01T00:00:00Z","remaining_seconds":7200}]}`+"\n", body)
+
+	code, _ = serve(http.MethodDelete, "/firewall/ports/8080", "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = serve(http.MethodDelete, "/firewall/ports/8080", "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = serve(http.MethodDelete, "/firewall/ports/abc", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, body = serve(http.MethodGet, "/firewall/plan", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"backend":"iptables","enabled":false,"ipv4":["--policy INPUT DROP"],"ipv6":null}`+"\n", body)
+
+	code, body = serve(http.MethodGet, "/firewall/dropped", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"enabled":true,"total":1,"chains":{"output":1},"destinations":null}`+"\n", body)
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+func withGzipMiddleware(childHandler http.Handler) *gzipMiddleware {
+	return &gzipMiddleware{
+		childHandler: childHandler,
+		writersPool: sync.Pool{
+			New: func() any { return gzip.NewWriter(nil) },
+		},
+	}
+}
+
+// gzipMiddleware compresses response bodies with gzip
+// if the client accepts the gzip content encoding.
+type gzipMiddleware struct {
+	childHandler http.Handler
+	writersPool  sync.Pool
+}
+
+func (m *gzipMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept-Encoding")
+	if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+		m.childHandler.ServeHTTP(w, r)
+		return
+	}
+
+	gzipWriter := &gzipResponseWriter{
+		httpWriter: w,
+		pool:       &m.writersPool,
+	}
+	defer gzipWriter.close()
+	m.childHandler.ServeHTTP(gzipWriter, r)
+}
+
+func acceptsGzip(acceptEncoding string) (ok bool) {
+	for _, encoding := range strings.Split(acceptEncoding, ",") {
+		encoding, _, _ = strings.Cut(encoding, ";")
+		if strings.TrimSpace(encoding) == "gzip" {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter lazily creates its gzip writer on the first
+// body write, so responses without body such as 304 Not Modified
+// are not given a gzip content encoding.
+type gzipResponseWriter struct {
+	httpWriter    http.ResponseWriter
+	pool          *sync.Pool
+	gzipWriter    *gzip.Writer
+	headerWritten bool
+	noBody        bool
+}
+
+func (w *gzipResponseWriter) Header() http.Header {
+	return w.httpWriter.Header()
+}
+
+func (w *gzipResponseWriter) WriteHeader(statusCode int) {
+	if w.headerWritten {
+		return
+	}
+	w.headerWritten = true
+
+	w.noBody = statusCode == http.StatusNoContent ||
+		statusCode == http.StatusNotModified
+	if !w.noBody {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+	}
+	w.httpWriter.WriteHeader(statusCode)
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (n int, err error) {
+	if !w.headerWritten {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.noBody {
+		return w.httpWriter.Write(b)
+	}
+
+	if w.gzipWriter == nil {
+		w.gzipWriter = w.pool.Get().(*gzip.Writer) //nolint:forcetypeassert
+		w.gzipWriter.Reset(w.httpWriter)
+	}
+	return w.gzipWriter.Write(b)
+}
+
+// Flush flushes the compressed data written so far to the
+// client, for streamed responses such as server-sent events.
+func (w *gzipResponseWriter) Flush() {
+	if w.gzipWriter != nil {
+		_ = w.gzipWriter.Flush()
+	}
+	if flusher, ok := w.httpWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *gzipResponseWriter) close() {
+	if w.gzipWriter == nil {
+		return
+	}
+	_ = w.gzipWriter.Close()
+	w.pool.Put(w.gzipWriter)
+	w.gzipWriter = nil
+}
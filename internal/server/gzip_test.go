@@ -0,0 +1,93 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_gzipMiddleware(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		acceptEncoding  string
+		childStatusCode int
+		contentEncoding string
+		statusCode      int
+		body            string
+	}{
+		"no accept encoding": {
+			childStatusCode: http.StatusOK,
+			statusCode:      http.StatusOK,
+			body:            "response body",
+		},
+		"gzip not accepted": {
+			acceptEncoding:  "deflate, br",
+			childStatusCode: http.StatusOK,
+			statusCode:      http.StatusOK,
+			body:            "response body",
+		},
+		"gzip accepted": {
+			acceptEncoding:  "gzip",
+			childStatusCode: http.StatusOK,
+			contentEncoding: "gzip",
+			statusCode:      http.StatusOK,
+			body:            "response body",
+		},
+		"gzip accepted among others with quality": {
+			acceptEncoding:  "br;q=1.0, gzip;q=0.8",
+			childStatusCode: http.StatusOK,
+			contentEncoding: "gzip",
+			statusCode:      http.StatusOK,
+			body:            "response body",
+		},
+		"not modified": {
+			acceptEncoding:  "gzip",
+			childStatusCode: http.StatusNotModified,
+			statusCode:      http.StatusNotModified,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			childHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Length", "13")
+				w.WriteHeader(testCase.childStatusCode)
+				if testCase.childStatusCode == http.StatusOK {
+					_, _ = w.Write([]byte("response body"))
+				}
+			})
+			middleware := withGzipMiddleware(childHandler)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/servers", nil)
+			if testCase.acceptEncoding != "" {
+				request.Header.Set("Accept-Encoding", testCase.acceptEncoding)
+			}
+			recorder := httptest.NewRecorder()
+			middleware.ServeHTTP(recorder, request)
+
+			assert.Equal(t, testCase.statusCode, recorder.Code)
+			assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+			assert.Equal(t, testCase.contentEncoding, recorder.Header().Get("Content-Encoding"))
+
+			body := recorder.Body.Bytes()
+			if testCase.contentEncoding == "gzip" {
+				assert.Empty(t, recorder.Header().Get("Content-Length"))
+				reader, err := gzip.NewReader(bytes.NewReader(body))
+				require.NoError(t, err)
+				body, err = io.ReadAll(reader)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, testCase.body, string(body))
+		})
+	}
+}
@@ -4,38 +4,80 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/models"
 )
 
 func newHandler(ctx context.Context, logger infoWarner, logging bool,
 	buildInfo models.BuildInformation,
 	vpnLooper VPNLooper,
+	failoverGetter FailoverGetter,
+	serverStats ServerStatsGetter,
+	bandwidth BandwidthGetter,
 	pfGetter PortForwardedGetter,
+	openvpnManagement OpenVPNManagement,
 	unboundLooper DNSLoop,
 	updaterLooper UpdaterLooper,
 	publicIPLooper PublicIPLoop,
+	socks5Looper SOCKS5Looper,
+	scheduler Scheduler,
+	portOpener FirewallPortOpener,
+	firewallPlanner FirewallPlanner,
+	droppedPackets DroppedPacketsGetter,
+	eventsBroker EventsBroker,
+	wireguardServer WireguardServer,
+	tunnels Tunnels,
 	storage Storage,
+	readiness ReadinessChecker,
+	rollbackWindow time.Duration,
+	totpKey []byte,
+	apiKeys []settings.ControlServerAPIKey,
+	healthExempt bool,
+	dohHandler http.Handler,
 	ipv6Supported bool,
 ) http.Handler {
-	handler := &handler{}
+	handler := &handler{
+		ready: newReadyHandler(readiness),
+		ui:    newUIHandler(logger),
+	}
 
-	vpn := newVPNHandler(ctx, vpnLooper, storage, ipv6Supported, logger)
-	openvpn := newOpenvpnHandler(ctx, vpnLooper, pfGetter, logger)
+	canary := newSettingsCanary(ctx, vpnLooper, readiness, rollbackWindow, logger)
+	vpn := newVPNHandler(ctx, vpnLooper, failoverGetter, canary, serverStats,
+		bandwidth, storage, eventsBroker, ipv6Supported, logger)
+	openvpn := newOpenvpnHandler(ctx, vpnLooper, pfGetter, openvpnManagement, logger)
+	portForwarding := newPortForwardingHandler(pfGetter, logger)
 	dns := newDNSHandler(ctx, unboundLooper, logger)
 	updater := newUpdaterHandler(ctx, updaterLooper, logger)
 	publicip := newPublicIPHandler(publicIPLooper, logger)
+	socks5 := newSOCKS5Handler(ctx, socks5Looper, logger)
+	schedules := newSchedulesHandler(ctx, scheduler, logger)
+	events := newEventsHandler(ctx, eventsBroker, logger)
+	firewall := newFirewallHandler(ctx, portOpener, firewallPlanner, droppedPackets, logger)
+	wireguard := newWireguardHandler(wireguardServer, len(apiKeys) > 0, logger)
+	tunnelsHandler := newTunnelsHandler(tunnels, logger)
 
 	handler.v0 = newHandlerV0(ctx, logger, vpnLooper, unboundLooper, updaterLooper)
-	handler.v1 = newHandlerV1(logger, buildInfo, vpn, openvpn, dns, updater, publicip)
+	handler.v1 = newHandlerV1(logger, buildInfo, vpn, openvpn, portForwarding,
+		dns, updater, publicip, socks5, schedules, events, firewall, wireguard, tunnelsHandler)
 
-	handlerWithLog := withLogMiddleware(handler, logger, logging)
+	const cacheTTL = 2 * time.Second
+	handlerWithTOTP := withTOTPMiddleware(handler, totpKey)
+	handlerWithCache := withCacheMiddleware(handlerWithTOTP, cacheTTL,
+		"/v1/vpn/settings", "/v1/openvpn/settings")
+	handlerWithAPIKey := withAPIKeyMiddleware(handlerWithCache, apiKeys, healthExempt)
+	handlerWithGzip := withGzipMiddleware(handlerWithAPIKey)
+	handlerWithDoH := withDoHRoute(handlerWithGzip, dohHandler)
+	handlerWithLog := withLogMiddleware(handlerWithDoH, logger, logging)
 	handler.setLogEnabled = handlerWithLog.setEnabled
 
 	return handlerWithLog
 }
 
 type handler struct {
+	ready         http.Handler
+	ui            http.Handler
 	v0            http.Handler
 	v1            http.Handler
 	setLogEnabled func(enabled bool)
@@ -43,6 +85,14 @@ type handler struct {
 
 func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	r.RequestURI = strings.TrimSuffix(r.RequestURI, "/")
+	if r.RequestURI == "/health" || r.RequestURI == "/ready" {
+		h.ready.ServeHTTP(w, r)
+		return
+	}
+	if isUIRequest(r) {
+		h.ui.ServeHTTP(w, r)
+		return
+	}
 	if !strings.HasPrefix(r.RequestURI, "/v1/") && r.RequestURI != "/v1" {
 		h.v0.ServeHTTP(w, r)
 		return
@@ -10,42 +10,73 @@ import (
 )
 
 func newHandlerV1(w warner, buildInfo models.BuildInformation,
-	vpn, openvpn, dns, updater, publicip http.Handler) http.Handler {
+	vpn, openvpn, portForwarding, dns, updater, publicip, socks5,
+	schedules, events, firewall, wireguard, tunnels http.Handler) http.Handler {
 	return &handlerV1{
-		warner:    w,
-		buildInfo: buildInfo,
-		vpn:       vpn,
-		openvpn:   openvpn,
-		dns:       dns,
-		updater:   updater,
-		publicip:  publicip,
+		warner:         w,
+		buildInfo:      buildInfo,
+		vpn:            vpn,
+		openvpn:        openvpn,
+		portForwarding: portForwarding,
+		dns:            dns,
+		updater:        updater,
+		publicip:       publicip,
+		socks5:         socks5,
+		schedules:      schedules,
+		events:         events,
+		firewall:       firewall,
+		wireguard:      wireguard,
+		tunnels:        tunnels,
 	}
 }
 
 type handlerV1 struct {
-	warner    warner
-	buildInfo models.BuildInformation
-	vpn       http.Handler
-	openvpn   http.Handler
-	dns       http.Handler
-	updater   http.Handler
-	publicip  http.Handler
+	warner         warner
+	buildInfo      models.BuildInformation
+	vpn            http.Handler
+	openvpn        http.Handler
+	portForwarding http.Handler
+	dns            http.Handler
+	updater        http.Handler
+	publicip       http.Handler
+	socks5         http.Handler
+	schedules      http.Handler
+	events         http.Handler
+	firewall       http.Handler
+	wireguard      http.Handler
+	tunnels        http.Handler
 }
 
 func (h *handlerV1) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch {
 	case r.RequestURI == "/version" && r.Method == http.MethodGet:
 		h.getVersion(w)
+	case r.RequestURI == "/openapi.json" && r.Method == http.MethodGet:
+		h.getOpenAPISpec(w)
 	case strings.HasPrefix(r.RequestURI, "/vpn"):
 		h.vpn.ServeHTTP(w, r)
 	case strings.HasPrefix(r.RequestURI, "/openvpn"):
 		h.openvpn.ServeHTTP(w, r)
+	case strings.HasPrefix(r.RequestURI, "/portforwarding"):
+		h.portForwarding.ServeHTTP(w, r)
 	case strings.HasPrefix(r.RequestURI, "/dns"):
 		h.dns.ServeHTTP(w, r)
 	case strings.HasPrefix(r.RequestURI, "/updater"):
 		h.updater.ServeHTTP(w, r)
 	case strings.HasPrefix(r.RequestURI, "/publicip"):
 		h.publicip.ServeHTTP(w, r)
+	case strings.HasPrefix(r.RequestURI, "/socks5"):
+		h.socks5.ServeHTTP(w, r)
+	case strings.HasPrefix(r.RequestURI, "/schedules"):
+		h.schedules.ServeHTTP(w, r)
+	case strings.HasPrefix(r.RequestURI, "/events"):
+		h.events.ServeHTTP(w, r)
+	case strings.HasPrefix(r.RequestURI, "/firewall"):
+		h.firewall.ServeHTTP(w, r)
+	case strings.HasPrefix(r.RequestURI, "/wireguard"):
+		h.wireguard.ServeHTTP(w, r)
+	case strings.HasPrefix(r.RequestURI, "/tunnels"):
+		h.tunnels.ServeHTTP(w, r)
 	default:
 		errString := fmt.Sprintf("%s %s not found", r.Method, r.RequestURI)
 		http.Error(w, errString, http.StatusBadRequest)
@@ -3,8 +3,14 @@ package server
 import (
 	"context"
 
+	"github.com/qdm12/gluetun/internal/bandwidth"
 	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/dns"
+	"github.com/qdm12/gluetun/internal/failover"
 	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/openvpn"
+	"github.com/qdm12/gluetun/internal/portforward"
+	"github.com/qdm12/gluetun/internal/serverstats"
 )
 
 type VPNLooper interface {
@@ -13,16 +19,43 @@ type VPNLooper interface {
 		outcome string, err error)
 	GetSettings() (settings settings.VPN)
 	SetSettings(ctx context.Context, settings settings.VPN) (outcome string)
+	GetConnectedServer() (server models.ConnectedServer, ok bool)
+}
+
+type FailoverGetter interface {
+	GetStatus() (status failover.Status)
+}
+
+type BandwidthGetter interface {
+	GetSeries() (series bandwidth.Series)
+}
+
+type ServerStatsGetter interface {
+	GetStats() (hostToStats map[string]serverstats.Stats)
 }
 
 type DNSLoop interface {
 	ApplyStatus(ctx context.Context, status models.LoopStatus) (
 		outcome string, err error)
 	GetStatus() (status models.LoopStatus)
+	GetRuntimeHosts() (hosts dns.RuntimeHosts, err error)
+	BlockHost(ctx context.Context, host string) (outcome string, err error)
+	UnblockHost(ctx context.Context, host string) (outcome string, err error)
+	AllowHost(ctx context.Context, host string) (outcome string, err error)
+	DisallowHost(ctx context.Context, host string) (outcome string, err error)
+	GetCacheStats(ctx context.Context) (stats dns.CacheStats, err error)
+}
+
+type OpenVPNManagement interface {
+	ConnectionInfo(ctx context.Context) (info openvpn.ConnectionInfo, err error)
 }
 
 type PortForwardedGetter interface {
 	GetPortForwarded() (portForwarded uint16)
+	GetPortsForwarded() (portsForwarded []uint16)
+	GetReachability() (reachability portforward.Reachability)
+	GetTorrentClientsStatus() (statuses []portforward.TorrentClientStatus)
+	GetHistory() (events []portforward.HistoryEvent)
 }
 
 type PublicIPLoop interface {
@@ -32,3 +65,7 @@ type PublicIPLoop interface {
 type Storage interface {
 	GetFilterChoices(provider string) models.FilterChoices
 }
+
+type ReadinessChecker interface {
+	Ready() (err error)
+}
@@ -74,3 +74,9 @@ func (w *statefulResponseWriter) WriteHeader(statusCode int) {
 func (w *statefulResponseWriter) Header() http.Header {
 	return w.httpWriter.Header()
 }
+
+func (w *statefulResponseWriter) Flush() {
+	if flusher, ok := w.httpWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
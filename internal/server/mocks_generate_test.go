@@ -0,0 +1,3 @@
+package server
+
+//go:generate mockgen -destination=mocks_test.go -package=$GOPACKAGE . Logger
@@ -0,0 +1,22 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:generate go run openapi_generate.go
+
+// openAPISpec is the OpenAPI specification of the v1 routes,
+// generated from the handlers with go generate.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+func (h *handlerV1) getOpenAPISpec(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_, err := w.Write(openAPISpec)
+	if err != nil {
+		h.warner.Warn("writing response: " + err.Error())
+	}
+}
@@ -0,0 +1,383 @@
+//go:build ignore
+
+// This program generates openapi.json from the routes matched in the
+// ServeHTTP methods of the v1 handlers, such that the specification
+// cannot drift from the handlers. Run it with go generate.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+func main() {
+	err := generate("openapi.json")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// nonV1Files are the files of handlers not serving v1 routes.
+var nonV1Files = map[string]struct{}{
+	"handler.go":   {},
+	"handlerv0.go": {},
+	"ready.go":     {},
+	"ui.go":        {},
+}
+
+type route struct {
+	method  string
+	path    string
+	summary string
+	tag     string
+}
+
+func generate(outputPath string) (err error) {
+	fileSet := token.NewFileSet()
+	packages, err := parser.ParseDir(fileSet, ".", func(info os.FileInfo) bool {
+		_, excluded := nonV1Files[info.Name()]
+		return !excluded && !strings.HasSuffix(info.Name(), "_test.go") &&
+			info.Name() != "openapi_generate.go"
+	}, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("parsing package: %w", err)
+	}
+
+	pkg, ok := packages["server"]
+	if !ok {
+		return fmt.Errorf("package server not found")
+	}
+
+	docs := methodDocs(pkg)
+	routes := []route{{
+		method:  "GET",
+		path:    "/health",
+		summary: "Same as /ready, for health checks",
+		tag:     "health",
+	}, {
+		method:  "GET",
+		path:    "/ready",
+		summary: "Responds with 200 if gluetun is ready and 503 otherwise",
+		tag:     "health",
+	}}
+	for _, file := range pkg.Files {
+		for _, declaration := range file.Decls {
+			function, ok := declaration.(*ast.FuncDecl)
+			if !ok || function.Name.Name != "ServeHTTP" || function.Recv == nil {
+				continue
+			}
+			routes = append(routes, parseServeHTTP(function, docs)...)
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].path != routes[j].path {
+			return routes[i].path < routes[j].path
+		}
+		return routes[i].method < routes[j].method
+	})
+
+	data, err := json.MarshalIndent(buildSpec(routes), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding specification: %w", err)
+	}
+	data = append(data, '\n')
+	return os.WriteFile(outputPath, data, 0o644) //nolint:gosec
+}
+
+// methodDocs returns the first sentence of the doc comment of each
+// method, keyed by method name, without the method name prefix.
+func methodDocs(pkg *ast.Package) (docs map[string]string) {
+	docs = make(map[string]string)
+	for _, file := range pkg.Files {
+		for _, declaration := range file.Decls {
+			function, ok := declaration.(*ast.FuncDecl)
+			if !ok || function.Recv == nil || function.Doc == nil {
+				continue
+			}
+			text := strings.Join(strings.Fields(function.Doc.Text()), " ")
+			text = strings.TrimPrefix(text, function.Name.Name+" ")
+			text, _, _ = strings.Cut(text, ". ")
+			docs[function.Name.Name] = capitalize(strings.TrimSuffix(text, "."))
+		}
+	}
+	return docs
+}
+
+func parseServeHTTP(function *ast.FuncDecl, docs map[string]string) (routes []route) {
+	prefix := ""
+	var uriSwitch *ast.SwitchStmt
+	for _, statement := range function.Body.List {
+		switch statement := statement.(type) {
+		case *ast.AssignStmt:
+			if call, ok := statement.Rhs[0].(*ast.CallExpr); ok &&
+				isSelector(call.Fun, "strings", "TrimPrefix") {
+				prefix = stringLiteral(call.Args[1])
+			}
+		case *ast.SwitchStmt:
+			uriSwitch = statement
+		}
+	}
+	if uriSwitch == nil {
+		return nil
+	}
+
+	tag := strings.TrimPrefix(prefix, "/")
+	for _, statement := range uriSwitch.Body.List {
+		clause := statement.(*ast.CaseClause) //nolint:forcetypeassert
+		for _, expression := range clause.List {
+			path, method, ok := parseCaseExpression(expression, uriSwitch.Tag != nil)
+			if !ok {
+				continue
+			}
+			path = "/v1" + prefix + path
+
+			if method != "" {
+				routes = append(routes, route{
+					method:  method,
+					path:    path,
+					summary: summarize(clause.Body, docs),
+					tag:     tagOrDefault(tag),
+				})
+				continue
+			}
+
+			methodSwitch := findMethodSwitch(clause.Body)
+			if methodSwitch == nil {
+				continue
+			}
+			for _, methodStatement := range methodSwitch.Body.List {
+				methodClause := methodStatement.(*ast.CaseClause) //nolint:forcetypeassert
+				for _, methodExpression := range methodClause.List {
+					routes = append(routes, route{
+						method:  httpMethod(methodExpression),
+						path:    path,
+						summary: summarize(methodClause.Body, docs),
+						tag:     tagOrDefault(tag),
+					})
+				}
+			}
+		}
+	}
+	return routes
+}
+
+// parseCaseExpression returns the path and, if the expression
+// constrains it, the HTTP method of the case expression given.
+// Cases delegating to another handler by prefix are ignored.
+func parseCaseExpression(expression ast.Expr, switchOnURI bool) (
+	path, method string, ok bool) {
+	if switchOnURI {
+		return stringLiteral(expression), "", true
+	}
+
+	switch expression := expression.(type) {
+	case *ast.BinaryExpr:
+		switch expression.Op { //nolint:exhaustive
+		case token.EQL:
+			if isSelector(expression.X, "r", "RequestURI") {
+				return stringLiteral(expression.Y), "", true
+			}
+		case token.LAND:
+			path, _, ok = parseCaseExpression(expression.X, false)
+			if binary, isBinary := expression.Y.(*ast.BinaryExpr); isBinary &&
+				isSelector(binary.X, "r", "Method") {
+				method = httpMethod(binary.Y)
+			}
+			return path, method, ok
+		}
+	case *ast.CallExpr:
+		// strings.HasPrefix(r.RequestURI, "/") matches a path parameter,
+		// and so does strings.HasPrefix(r.RequestURI, "/path/").
+		if isSelector(expression.Fun, "strings", "HasPrefix") &&
+			strings.HasSuffix(stringLiteral(expression.Args[1]), "/") {
+			return stringLiteral(expression.Args[1]) + "{id}", "", true
+		}
+	}
+	return "", "", false
+}
+
+func findMethodSwitch(statements []ast.Stmt) *ast.SwitchStmt {
+	for _, statement := range statements {
+		switchStatement, ok := statement.(*ast.SwitchStmt)
+		if ok && isSelector(switchStatement.Tag, "r", "Method") {
+			return switchStatement
+		}
+	}
+	return nil
+}
+
+// summarize returns the doc comment of the first handler method
+// called in the statements given, or its name split in words.
+// If the method is given a method value argument, such as
+// h.loop.BlockHost, the name of that method value is used instead.
+func summarize(statements []ast.Stmt, docs map[string]string) string {
+	for _, statement := range statements {
+		expressionStatement, ok := statement.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := expressionStatement.X.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+		name := selector.Sel.Name
+		for _, argument := range call.Args {
+			if argumentSelector, ok := argument.(*ast.SelectorExpr); ok &&
+				unicode.IsUpper([]rune(argumentSelector.Sel.Name)[0]) {
+				name = argumentSelector.Sel.Name
+			}
+		}
+		if doc, ok := docs[name]; ok {
+			return doc
+		}
+		return capitalize(splitCamelCase(name))
+	}
+	return ""
+}
+
+// splitCamelCase splits a camel case name in lower case words,
+// keeping acronyms together, for example getOpenAPISpec gives
+// get open api spec.
+func splitCamelCase(s string) string {
+	runes := []rune(s)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		previousLower := !unicode.IsUpper(runes[i-1])
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if previousLower || nextLower {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	words = append(words, string(runes[start:]))
+	return strings.ToLower(strings.Join(words, " "))
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func tagOrDefault(tag string) string {
+	if tag == "" {
+		return "gluetun"
+	}
+	return tag
+}
+
+func isSelector(expression ast.Expr, x, name string) bool {
+	selector, ok := expression.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	identifier, ok := selector.X.(*ast.Ident)
+	return ok && identifier.Name == x && selector.Sel.Name == name
+}
+
+func httpMethod(expression ast.Expr) string {
+	selector, ok := expression.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	return strings.ToUpper(strings.TrimPrefix(selector.Sel.Name, "Method"))
+}
+
+func stringLiteral(expression ast.Expr) string {
+	literal, ok := expression.(*ast.BasicLit)
+	if !ok || literal.Kind != token.STRING {
+		return ""
+	}
+	value, _ := strconv.Unquote(literal.Value)
+	return value
+}
+
+type object = map[string]interface{}
+
+func buildSpec(routes []route) object {
+	paths := make(object)
+	for _, route := range routes {
+		operation := object{
+			"operationId": operationID(route),
+			"summary":     route.summary,
+			"tags":        []string{route.tag},
+			"responses": object{
+				"200":     object{"description": "Success"},
+				"default": object{"description": "Error message as plain text"},
+			},
+		}
+		if route.method == "PUT" || route.method == "POST" {
+			operation["requestBody"] = object{
+				"content": object{
+					"application/json": object{
+						"schema": object{"type": "object"},
+					},
+				},
+			}
+		}
+		if strings.Contains(route.path, "{id}") {
+			operation["parameters"] = []object{{
+				"name":     "id",
+				"in":       "path",
+				"required": true,
+				"schema":   object{"type": "string"},
+			}}
+		}
+
+		pathItem, ok := paths[route.path].(object)
+		if !ok {
+			pathItem = make(object)
+			paths[route.path] = pathItem
+		}
+		pathItem[strings.ToLower(route.method)] = operation
+	}
+
+	return object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":       "Gluetun control server",
+			"description": "HTTP API to control and monitor gluetun.",
+			"version":     "1",
+		},
+		"paths": paths,
+		"components": object{
+			"securitySchemes": object{
+				"bearer": object{"type": "http", "scheme": "bearer"},
+				"apiKey": object{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			},
+		},
+		// API keys are only required if set in the settings
+		"security": []object{{}, {"bearer": []string{}}, {"apiKey": []string{}}},
+	}
+}
+
+func operationID(route route) string {
+	parts := []string{strings.ToLower(route.method)}
+	for _, part := range strings.FieldsFunc(route.path, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		parts = append(parts, capitalize(part))
+	}
+	return strings.Join(parts, "")
+}
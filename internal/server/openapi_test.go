@@ -0,0 +1,34 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_handlerV1_getOpenAPISpec(t *testing.T) {
+	t.Parallel()
+
+	handler := &handlerV1{}
+	request := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	var spec struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &spec)
+	require.NoError(t, err)
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+	assert.Contains(t, spec.Paths["/v1/openapi.json"], "get")
+	assert.Contains(t, spec.Paths["/v1/vpn/settings"], "put")
+	assert.Contains(t, spec.Paths["/v1/schedules/{id}"], "delete")
+}
@@ -3,25 +3,31 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
+
+	"github.com/qdm12/gluetun/internal/openvpn"
 )
 
 func newOpenvpnHandler(ctx context.Context, looper VPNLooper,
-	pfGetter PortForwardedGetter, w warner) http.Handler {
+	pfGetter PortForwardedGetter, management OpenVPNManagement,
+	w warner) http.Handler {
 	return &openvpnHandler{
-		ctx:    ctx,
-		looper: looper,
-		pf:     pfGetter,
-		warner: w,
+		ctx:        ctx,
+		looper:     looper,
+		pf:         pfGetter,
+		management: management,
+		warner:     w,
 	}
 }
 
 type openvpnHandler struct {
-	ctx    context.Context //nolint:containedctx
-	looper VPNLooper
-	pf     PortForwardedGetter
-	warner warner
+	ctx        context.Context //nolint:containedctx
+	looper     VPNLooper
+	pf         PortForwardedGetter
+	management OpenVPNManagement
+	warner     warner
 }
 
 func (h *openvpnHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -50,6 +56,27 @@ func (h *openvpnHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		default:
 			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
 		}
+	case "/portforwarded/reachability":
+		switch r.Method {
+		case http.MethodGet:
+			h.getPortReachability(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case "/portforwarded/clients":
+		switch r.Method {
+		case http.MethodGet:
+			h.getTorrentClientsStatus(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case "/connection":
+		switch r.Method {
+		case http.MethodGet:
+			h.getConnection(w, r)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
 	default:
 		http.Error(w, "route "+r.RequestURI+" not supported", http.StatusBadRequest)
 	}
@@ -103,12 +130,52 @@ func (h *openvpnHandler) getSettings(w http.ResponseWriter) {
 }
 
 func (h *openvpnHandler) getPortForwarded(w http.ResponseWriter) {
-	port := h.pf.GetPortForwarded()
 	encoder := json.NewEncoder(w)
-	data := portWrapper{Port: port}
+	data := portsWrapper{
+		Port:  h.pf.GetPortForwarded(),
+		Ports: h.pf.GetPortsForwarded(),
+	}
 	if err := encoder.Encode(data); err != nil {
 		h.warner.Warn(err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 }
+
+func (h *openvpnHandler) getPortReachability(w http.ResponseWriter) {
+	reachability := h.pf.GetReachability()
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(reachability); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *openvpnHandler) getTorrentClientsStatus(w http.ResponseWriter) {
+	statuses := h.pf.GetTorrentClientsStatus()
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(statuses); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *openvpnHandler) getConnection(w http.ResponseWriter, r *http.Request) {
+	info, err := h.management.ConnectionInfo(r.Context())
+	if errors.Is(err, openvpn.ErrManagementNotAvailable) {
+		http.Error(w, "OpenVPN is not running", http.StatusServiceUnavailable)
+		return
+	} else if err != nil {
+		h.warner.Warn(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(info); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/portforward/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_openvpnHandler_getPortForwarded(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		ports []uint16
+		body  string
+	}{
+		"no port forwarded": {
+			body: `{"port":0,"ports":[]}` + "\n",
+		},
+		"single port forwarded": {
+			ports: []uint16{1000},
+			body:  `{"port":1000,"ports":[1000]}` + "\n",
+		},
+		"multiple ports forwarded": {
+			ports: []uint16{1000, 2000},
+			body:  `{"port":1000,"ports":[1000,2000]}` + "\n",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pfState := state.New(nil, settings.PortForwarding{})
+			pfState.SetPortsForwarded(testCase.ports)
+			handler := newOpenvpnHandler(context.Background(), nil, pfState, nil, nil)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/openvpn/portforwarded", nil)
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, testCase.body, recorder.Body.String())
+		})
+	}
+}
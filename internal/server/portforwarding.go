@@ -0,0 +1,46 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+func newPortForwardingHandler(pfGetter PortForwardedGetter, w warner) http.Handler {
+	return &portForwardingHandler{
+		pf:     pfGetter,
+		warner: w,
+	}
+}
+
+type portForwardingHandler struct {
+	pf     PortForwardedGetter
+	warner warner
+}
+
+func (h *portForwardingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.RequestURI = strings.TrimPrefix(r.RequestURI, "/portforwarding")
+	switch r.RequestURI {
+	case "/history":
+		switch r.Method {
+		case http.MethodGet:
+			h.getHistory(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	default:
+		http.Error(w, "route "+r.RequestURI+" not supported", http.StatusBadRequest)
+	}
+}
+
+// getHistory returns the recent port forwarding acquisitions,
+// renewals, failures and releases, oldest first.
+func (h *portForwardingHandler) getHistory(w http.ResponseWriter) {
+	events := h.pf.GetHistory()
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(events); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"net/http"
+)
+
+// newReadyHandler returns a minimal handler, served on /health and
+// /ready, responding 200 if gluetun is ready and 503 with the reason
+// otherwise, so containers behind gluetun can use it in their
+// healthchecks without parsing the API.
+func newReadyHandler(readiness ReadinessChecker) http.Handler {
+	return &readyHandler{
+		readiness: readiness,
+	}
+}
+
+type readyHandler struct {
+	readiness ReadinessChecker
+}
+
+func (h *readyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.readiness.Ready(); err != nil {
+		http.Error(w, "not ready: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	_, _ = w.Write([]byte("ready\n"))
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_readyHandler(t *testing.T) {
+	t.Parallel()
+
+	errNotReady := errors.New("VPN is not running")
+
+	testCases := map[string]struct {
+		method   string
+		readyErr error
+		status   int
+		body     string
+	}{
+		"ready": {
+			method: http.MethodGet,
+			status: http.StatusOK,
+			body:   "ready\n",
+		},
+		"ready HEAD": {
+			method: http.MethodHead,
+			status: http.StatusOK,
+			body:   "ready\n",
+		},
+		"not ready": {
+			method:   http.MethodGet,
+			readyErr: errNotReady,
+			status:   http.StatusServiceUnavailable,
+			body:     "not ready: VPN is not running\n",
+		},
+		"method not supported": {
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			body:   "method POST not supported\n",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newReadyHandler(&fakeReadiness{err: testCase.readyErr})
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(testCase.method, "/ready", nil)
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, testCase.status, recorder.Code)
+			assert.Equal(t, testCase.body, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/scheduler"
+)
+
+type Scheduler interface {
+	GetSchedules() (schedules []scheduler.Schedule)
+	Add(cronExpression, action string) (schedule scheduler.Schedule, err error)
+	Remove(id string) (err error)
+	GetRotation() (rotation scheduler.Rotation)
+	RotateNow(ctx context.Context) (outcome string, err error)
+}
+
+func newSchedulesHandler(ctx context.Context, scheduler Scheduler,
+	warner warner) http.Handler {
+	return &schedulesHandler{
+		ctx:       ctx,
+		scheduler: scheduler,
+		warner:    warner,
+	}
+}
+
+type schedulesHandler struct {
+	ctx       context.Context //nolint:containedctx
+	scheduler Scheduler
+	warner    warner
+}
+
+func (h *schedulesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.RequestURI = strings.TrimPrefix(r.RequestURI, "/schedules")
+	switch {
+	case r.RequestURI == "":
+		switch r.Method {
+		case http.MethodGet:
+			h.getSchedules(w)
+		case http.MethodPost:
+			h.addSchedule(w, r)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case r.RequestURI == "/rotation":
+		switch r.Method {
+		case http.MethodGet:
+			h.getRotation(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case r.RequestURI == "/rotation/now":
+		switch r.Method {
+		case http.MethodPost:
+			h.rotateNow(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case strings.HasPrefix(r.RequestURI, "/"):
+		switch r.Method {
+		case http.MethodDelete:
+			h.removeSchedule(w, strings.TrimPrefix(r.RequestURI, "/"))
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	default:
+		http.Error(w, "route "+r.RequestURI+" not supported", http.StatusBadRequest)
+	}
+}
+
+type schedulesWrapper struct {
+	Schedules []scheduler.Schedule `json:"schedules"`
+}
+
+func (h *schedulesHandler) getSchedules(w http.ResponseWriter) {
+	data := schedulesWrapper{Schedules: h.scheduler.GetSchedules()}
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(data); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+type scheduleRequest struct {
+	Cron   string `json:"cron"`
+	Action string `json:"action"`
+}
+
+func (h *schedulesHandler) addSchedule(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	var data scheduleRequest
+	if err := decoder.Decode(&data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.scheduler.Add(data.Cron, data.Action)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(schedule); err != nil {
+		h.warner.Warn(err.Error())
+		return
+	}
+}
+
+func (h *schedulesHandler) removeSchedule(w http.ResponseWriter, id string) {
+	err := h.scheduler.Remove(id)
+	switch {
+	case errors.Is(err, scheduler.ErrScheduleNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(outcomeWrapper{Outcome: "removed"}); err != nil {
+		h.warner.Warn(err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *schedulesHandler) getRotation(w http.ResponseWriter) {
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(h.scheduler.GetRotation()); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// rotateNow rotates the VPN server immediately.
+func (h *schedulesHandler) rotateNow(w http.ResponseWriter) {
+	outcome, err := h.scheduler.RotateNow(h.ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(outcomeWrapper{Outcome: outcome}); err != nil {
+		h.warner.Warn(err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
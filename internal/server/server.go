@@ -2,26 +2,39 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net/http"
+	"time"
 
+	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/httpserver"
 	"github.com/qdm12/gluetun/internal/models"
 )
 
 func New(ctx context.Context, address string, logEnabled bool, logger Logger,
 	buildInfo models.BuildInformation, openvpnLooper VPNLooper,
-	pfGetter PortForwardedGetter, unboundLooper DNSLoop,
-	updaterLooper UpdaterLooper, publicIPLooper PublicIPLoop, storage Storage,
-	ipv6Supported bool) (
+	failoverGetter FailoverGetter, serverStats ServerStatsGetter, bandwidth BandwidthGetter,
+	pfGetter PortForwardedGetter, openvpnManagement OpenVPNManagement,
+	unboundLooper DNSLoop,
+	updaterLooper UpdaterLooper, publicIPLooper PublicIPLoop, socks5Looper SOCKS5Looper,
+	scheduler Scheduler, portOpener FirewallPortOpener, firewallPlanner FirewallPlanner,
+	droppedPackets DroppedPacketsGetter,
+	eventsBroker EventsBroker, wireguardServer WireguardServer, tunnels Tunnels, storage Storage,
+	readiness ReadinessChecker, rollbackWindow time.Duration,
+	totpKey []byte, apiKeys []settings.ControlServerAPIKey, healthExempt bool,
+	tlsConfig *tls.Config, dohHandler http.Handler, ipv6Supported bool) (
 	server *httpserver.Server, err error) {
 	handler := newHandler(ctx, logger, logEnabled, buildInfo,
-		openvpnLooper, pfGetter, unboundLooper, updaterLooper, publicIPLooper,
-		storage, ipv6Supported)
+		openvpnLooper, failoverGetter, serverStats, bandwidth, pfGetter, openvpnManagement,
+		unboundLooper, updaterLooper, publicIPLooper, socks5Looper, scheduler, portOpener, firewallPlanner, droppedPackets, eventsBroker, wireguardServer, tunnels, storage, readiness,
+		rollbackWindow, totpKey, apiKeys, healthExempt, dohHandler, ipv6Supported)
 
 	httpServerSettings := httpserver.Settings{
-		Address: address,
-		Handler: handler,
-		Logger:  logger,
+		Address:   address,
+		Handler:   handler,
+		Logger:    logger,
+		TLSConfig: tlsConfig,
 	}
 
 	server, err = httpserver.New(httpServerSettings)
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const totpHeader = "X-TOTP"
+
+// withTOTPMiddleware requires a valid TOTP code in the X-TOTP header
+// for requests mutating the state of gluetun. The key is the decoded
+// shared secret, and a nil key disables the middleware.
+func withTOTPMiddleware(childHandler http.Handler, key []byte) http.Handler {
+	if key == nil {
+		return childHandler
+	}
+	return &totpMiddleware{
+		childHandler: childHandler,
+		key:          key,
+		timeNow:      time.Now,
+	}
+}
+
+type totpMiddleware struct {
+	childHandler http.Handler
+	key          []byte
+	timeNow      func() time.Time
+	// lastCounter is the time step counter of the last code
+	// accepted, to prevent replaying an intercepted code.
+	lastCounter uint64
+	mutex       sync.Mutex
+}
+
+func (m *totpMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if isMutatingRequest(r) && !m.validCode(r.Header.Get(totpHeader)) {
+		http.Error(w, "TOTP code is missing or not valid", http.StatusUnauthorized)
+		return
+	}
+	m.childHandler.ServeHTTP(w, r)
+}
+
+// isMutatingRequest returns true for requests changing the state of
+// gluetun, which are all non GET requests of the v1 API and the
+// unversioned API action routes.
+func isMutatingRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return true
+	}
+
+	switch strings.TrimSuffix(r.RequestURI, "/") {
+	case "/openvpn/actions/restart",
+		"/unbound/actions/restart",
+		"/updater/restart":
+		return true
+	default:
+		return false
+	}
+}
+
+func (m *totpMiddleware) validCode(code string) (valid bool) {
+	const timeStep = 30
+	counter := uint64(m.timeNow().Unix()) / timeStep
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Accept codes from one time step before and after
+	// to account for clock drift between the client and gluetun.
+	for _, candidate := range []uint64{counter - 1, counter, counter + 1} {
+		if candidate <= m.lastCounter {
+			continue
+		}
+		expected := totpCode(m.key, candidate)
+		if subtle.ConstantTimeCompare([]byte(code), []byte(expected)) == 1 {
+			m.lastCounter = candidate
+			return true
+		}
+	}
+	return false
+}
+
+// totpCode returns the 6 digits code for the key and time
+// step counter given, as described in RFC 4226 and RFC 6238.
+func totpCode(key []byte, counter uint64) (code string) {
+	message := make([]byte, 8) //nolint:gomnd
+	binary.BigEndian.PutUint64(message, counter)
+	mac := hmac.New(sha1.New, key)
+	_, _ = mac.Write(message)
+	sum := mac.Sum(nil)
+
+	const offsetMask = 0x0f
+	offset := sum[len(sum)-1] & offsetMask
+	truncated := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff //nolint:gomnd
+	const modulo = 1000000
+	return fmt.Sprintf("%06d", truncated%modulo)
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_totpCode(t *testing.T) {
+	t.Parallel()
+
+	// Test vectors from RFC 6238 appendix B, truncated to 6 digits.
+	key := []byte("12345678901234567890")
+	testCases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unixTime, expected := range testCases {
+		const timeStep = 30
+		code := totpCode(key, uint64(unixTime)/timeStep)
+		assert.Equal(t, expected, code, "time %d", unixTime)
+	}
+}
+
+func Test_totpMiddleware(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("12345678901234567890")
+	childHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	middleware := &totpMiddleware{
+		childHandler: childHandler,
+		key:          key,
+		timeNow:      func() time.Time { return time.Unix(1234567890, 0) },
+	}
+
+	serve := func(method, uri, code string) (statusCode int) {
+		request := httptest.NewRequest(method, uri, nil)
+		if code != "" {
+			request.Header.Set(totpHeader, code)
+		}
+		recorder := httptest.NewRecorder()
+		middleware.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/v1/vpn/status", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPut, "/v1/vpn/status", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/updater/restart", "000000"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPut, "/v1/vpn/status", "005924"))
+	// Replaying the same code is refused
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPut, "/v1/vpn/status", "005924"))
+}
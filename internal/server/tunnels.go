@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/tunnels"
+)
+
+type Tunnels interface {
+	Statuses() (statuses []tunnels.Status)
+	Status(name string) (status tunnels.Status, err error)
+}
+
+func newTunnelsHandler(tunnels Tunnels, warner warner) http.Handler {
+	return &tunnelsHandler{
+		tunnels: tunnels,
+		warner:  warner,
+	}
+}
+
+type tunnelsHandler struct {
+	tunnels Tunnels
+	warner  warner
+}
+
+func (h *tunnelsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.RequestURI = strings.TrimPrefix(r.RequestURI, "/tunnels")
+	switch {
+	case r.RequestURI == "":
+		switch r.Method {
+		case http.MethodGet:
+			h.getTunnelStatuses(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case strings.HasPrefix(r.RequestURI, "/"):
+		switch r.Method {
+		case http.MethodGet:
+			h.getTunnelStatus(w, r)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	default:
+		http.Error(w, "route "+r.RequestURI+" not supported", http.StatusBadRequest)
+	}
+}
+
+type tunnelsStatusesWrapper struct {
+	Tunnels []tunnels.Status `json:"tunnels"`
+}
+
+// getTunnelStatuses writes the status, health, public IP address
+// and forwarded port of each additional tunnel.
+func (h *tunnelsHandler) getTunnelStatuses(w http.ResponseWriter) {
+	data := tunnelsStatusesWrapper{Tunnels: h.tunnels.Statuses()}
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(data); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// getTunnelStatus writes the status, health, public IP address
+// and forwarded port of the additional tunnel.
+func (h *tunnelsHandler) getTunnelStatus(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.RequestURI, "/")
+	status, err := h.tunnels.Status(name)
+	if errors.Is(err, tunnels.ErrTunnelNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(status); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
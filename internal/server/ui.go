@@ -0,0 +1,68 @@
+package server
+
+import (
+	"embed"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// uiFS contains the static files of the web user interface.
+//
+//go:embed ui
+var uiFS embed.FS
+
+func newUIHandler(w warner) http.Handler {
+	return &uiHandler{
+		warner: w,
+	}
+}
+
+// uiHandler serves the web user interface at /ui. The interface
+// only calls the v1 routes from the browser, so it needs no state.
+type uiHandler struct {
+	warner warner
+}
+
+func (h *uiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimPrefix(r.RequestURI, "/ui")
+	name = strings.TrimPrefix(name, "/")
+	if name == "" {
+		name = "index.html"
+	}
+
+	data, err := uiFS.ReadFile(path.Join("ui", path.Clean("/"+name)))
+	if err != nil {
+		http.Error(w, "file "+name+" not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", uiContentType(name))
+	_, err = w.Write(data)
+	if err != nil {
+		h.warner.Warn("writing response: " + err.Error())
+	}
+}
+
+func isUIRequest(r *http.Request) bool {
+	uri := strings.TrimSuffix(r.RequestURI, "/")
+	return uri == "/ui" || strings.HasPrefix(uri, "/ui/")
+}
+
+func uiContentType(name string) string {
+	switch path.Ext(name) {
+	case ".html":
+		return "text/html; charset=utf-8"
+	case ".css":
+		return "text/css; charset=utf-8"
+	case ".js":
+		return "text/javascript; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
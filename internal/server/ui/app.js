@@ -0,0 +1,97 @@
+"use strict";
+
+const refreshPeriodMs = 5000;
+const apiKeyInput = document.getElementById("api-key");
+const totpInput = document.getElementById("totp-code");
+
+apiKeyInput.value = localStorage.getItem("gluetun-api-key") || "";
+apiKeyInput.addEventListener("change", () => {
+  localStorage.setItem("gluetun-api-key", apiKeyInput.value);
+  refresh();
+});
+
+async function request(method, path, body) {
+  const headers = { "Content-Type": "application/json" };
+  if (apiKeyInput.value !== "") {
+    headers["X-API-Key"] = apiKeyInput.value;
+  }
+  if (method !== "GET" && totpInput.value !== "") {
+    headers["X-TOTP"] = totpInput.value;
+  }
+  const response = await fetch(path, {
+    method: method,
+    headers: headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  if (!response.ok) {
+    const message = (await response.text()).trim();
+    throw new Error(method + " " + path + ": " + response.status + " " + message);
+  }
+  return response.json();
+}
+
+function setText(id, text) {
+  document.getElementById(id).textContent = text;
+}
+
+async function refresh() {
+  const errors = [];
+  const update = async (path, onData) => {
+    try {
+      onData(await request("GET", path));
+    } catch (error) {
+      errors.push(error.message);
+    }
+  };
+
+  await Promise.all([
+    update("/v1/version", (data) => setText("version", data.version)),
+    update("/v1/vpn/status", (data) => setText("vpn-status", data.status)),
+    update("/v1/publicip/ip", (data) => {
+      setText("public-ip", data.public_ip || "-");
+      setText("public-ip-location", [data.city, data.country].filter(Boolean).join(", "));
+    }),
+    update("/v1/openvpn/portforwarded", (data) => setText("port-forwarded", data.port || "-")),
+    update("/v1/dns/status", (data) => setText("dns-status", data.status)),
+  ]);
+
+  try {
+    const server = await request("GET", "/v1/vpn/server");
+    setText("vpn-server", [server.provider, server.server_name || server.hostname]
+      .filter(Boolean).join(" - "));
+  } catch (error) {
+    setText("vpn-server", ""); // not connected
+  }
+
+  setText("error", errors.join("\n"));
+}
+
+async function action(button, run) {
+  button.disabled = true;
+  try {
+    setText("outcome", await run());
+    setText("error", "");
+  } catch (error) {
+    setText("error", error.message);
+  } finally {
+    button.disabled = false;
+    refresh();
+  }
+}
+
+document.getElementById("restart-vpn").addEventListener("click", (event) => {
+  action(event.target, async () => {
+    const data = await request("POST", "/v1/vpn/actions/restart");
+    return data.outcome;
+  });
+});
+
+document.getElementById("run-updater").addEventListener("click", (event) => {
+  action(event.target, async () => {
+    const data = await request("PUT", "/v1/updater/status", { status: "running" });
+    return data.outcome;
+  });
+});
+
+refresh();
+setInterval(refresh, refreshPeriodMs);
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_uiHandler(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		method      string
+		uri         string
+		statusCode  int
+		contentType string
+	}{
+		"index": {
+			method:      http.MethodGet,
+			uri:         "/ui",
+			statusCode:  http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+		},
+		"script": {
+			method:      http.MethodGet,
+			uri:         "/ui/app.js",
+			statusCode:  http.StatusOK,
+			contentType: "text/javascript; charset=utf-8",
+		},
+		"not found": {
+			method:     http.MethodGet,
+			uri:        "/ui/../ui.go",
+			statusCode: http.StatusNotFound,
+		},
+		"method not supported": {
+			method:     http.MethodPost,
+			uri:        "/ui",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			handler := newUIHandler(NewMockLogger(ctrl))
+			request := httptest.NewRequest(testCase.method, "/", nil)
+			request.RequestURI = testCase.uri
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, testCase.statusCode, recorder.Code)
+			if testCase.contentType != "" {
+				assert.Equal(t, testCase.contentType, recorder.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -2,19 +2,31 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
 	"strings"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/events"
 )
 
 func newVPNHandler(ctx context.Context, looper VPNLooper,
-	storage Storage, ipv6Supported bool, w warner) http.Handler {
+	failover FailoverGetter, canary *settingsCanary,
+	serverStats ServerStatsGetter, bandwidth BandwidthGetter,
+	storage Storage, publisher eventPublisher, ipv6Supported bool,
+	w warner) http.Handler {
 	return &vpnHandler{
 		ctx:           ctx,
 		looper:        looper,
+		failover:      failover,
+		canary:        canary,
+		serverStats:   serverStats,
+		bandwidth:     bandwidth,
 		storage:       storage,
+		publisher:     publisher,
 		ipv6Supported: ipv6Supported,
 		warner:        w,
 	}
@@ -23,7 +35,12 @@ func newVPNHandler(ctx context.Context, looper VPNLooper,
 type vpnHandler struct {
 	ctx           context.Context //nolint:containedctx
 	looper        VPNLooper
+	failover      FailoverGetter
+	canary        *settingsCanary
+	serverStats   ServerStatsGetter
+	bandwidth     BandwidthGetter
 	storage       Storage
+	publisher     eventPublisher
 	ipv6Supported bool
 	warner        warner
 }
@@ -40,6 +57,13 @@ func (h *vpnHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		default:
 			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
 		}
+	case "/actions/restart":
+		switch r.Method {
+		case http.MethodPost:
+			h.restart(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
 	case "/settings":
 		switch r.Method {
 		case http.MethodGet:
@@ -49,6 +73,48 @@ func (h *vpnHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		default:
 			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
 		}
+	case "/settings/plan":
+		switch r.Method {
+		case http.MethodPost:
+			h.planSettings(w, r)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case "/settings/rollback":
+		switch r.Method {
+		case http.MethodGet:
+			h.getRollback(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case "/server":
+		switch r.Method {
+		case http.MethodGet:
+			h.getServer(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case "/failover":
+		switch r.Method {
+		case http.MethodGet:
+			h.getFailover(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case "/bandwidth":
+		switch r.Method {
+		case http.MethodGet:
+			h.getBandwidth(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case "/servers/stats":
+		switch r.Method {
+		case http.MethodGet:
+			h.getServerStats(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
 	default:
 		http.Error(w, "route "+r.RequestURI+" not supported", http.StatusBadRequest)
 	}
@@ -90,8 +156,29 @@ func (h *vpnHandler) setStatus(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// restart stops and starts the VPN in a single request.
+func (h *vpnHandler) restart(w http.ResponseWriter) {
+	_, err := h.looper.ApplyStatus(h.ctx, constants.Stopped)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	outcome, err := h.looper.ApplyStatus(h.ctx, constants.Running)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(outcomeWrapper{Outcome: outcome}); err != nil {
+		h.warner.Warn(err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
 func (h *vpnHandler) getSettings(w http.ResponseWriter) {
 	settings := h.looper.GetSettings()
+	w.Header().Set("ETag", settingsETag(settings))
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(settings); err != nil {
 		h.warner.Warn(err.Error())
@@ -100,31 +187,155 @@ func (h *vpnHandler) getSettings(w http.ResponseWriter) {
 	}
 }
 
+func (h *vpnHandler) getRollback(w http.ResponseWriter) {
+	status := h.canary.getStatus()
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(status); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *vpnHandler) getServer(w http.ResponseWriter) {
+	server, ok := h.looper.GetConnectedServer()
+	if !ok {
+		http.Error(w, "VPN is not connected", http.StatusServiceUnavailable)
+		return
+	}
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(server); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// patchSettings applies the VPN settings given in the request body.
+// If the If-Match header is set, the settings are only applied if it
+// matches the ETag of the current settings, such that a plan reviewed
+// with planSettings is not applied on top of settings changed since.
 func (h *vpnHandler) patchSettings(w http.ResponseWriter, r *http.Request) {
-	var overrideSettings settings.VPN
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&overrideSettings)
+	currentSettings := h.looper.GetSettings() // already copied
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" && ifMatch != settingsETag(currentSettings) {
+		http.Error(w, "VPN settings changed since the plan was made", http.StatusPreconditionFailed)
+		return
+	}
+
+	updatedSettings, plan, err := h.decodeAndPlan(r, currentSettings)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	outcome := h.canary.apply(updatedSettings)
+	if len(plan.Changes) > 0 {
+		// only field names are published since values can be secrets
+		fields := make([]string, len(plan.Changes))
+		for i, change := range plan.Changes {
+			fields[i] = change.Field
+		}
+		h.publisher.Publish(events.TypeSettingsOverridden, settingsOverriddenData{
+			Fields:  fields,
+			Outcome: outcome,
+		})
+	}
+	_, err = w.Write([]byte(outcome))
+	if err != nil {
+		h.warner.Warn("writing response: " + err.Error())
+	}
+}
+
+type settingsOverriddenData struct {
+	Fields  []string `json:"fields"`
+	Outcome string   `json:"outcome"`
+}
+
+type settingsPlan struct {
+	settings.Plan
+	// ETag is to be set in the If-Match header when applying
+	// the plan, to only apply it if the settings did not change.
+	ETag string `json:"etag"`
+}
+
+// planSettings responds with the plan of changes the VPN settings given
+// in the request body would make, without applying them.
+func (h *vpnHandler) planSettings(w http.ResponseWriter, r *http.Request) {
+	currentSettings := h.looper.GetSettings() // already copied
+	_, plan, err := h.decodeAndPlan(r, currentSettings)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	encoder := json.NewEncoder(w)
+	data := settingsPlan{Plan: plan, ETag: settingsETag(currentSettings)}
+	if err := encoder.Encode(data); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// decodeAndPlan decodes the VPN settings from the request body,
+// overrides the current settings with them and validates the
+// resulting settings.
+func (h *vpnHandler) decodeAndPlan(r *http.Request, currentSettings settings.VPN) (
+	updatedSettings settings.VPN, plan settings.Plan, err error) {
+	var overrideSettings settings.VPN
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&overrideSettings)
+	if err != nil {
+		return updatedSettings, plan, err
+	}
+
 	err = r.Body.Close()
 	if err != nil {
 		h.warner.Warn("closing body: " + err.Error())
 	}
 
-	updatedSettings := h.looper.GetSettings() // already copied
-	updatedSettings.OverrideWith(overrideSettings)
+	updatedSettings, plan = currentSettings.PlanOverride(overrideSettings)
 	err = updatedSettings.Validate(h.storage, h.ipv6Supported)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		return updatedSettings, plan, err
+	}
+	return updatedSettings, plan, nil
+}
+
+// settingsETag returns a quoted digest of the VPN settings given.
+func settingsETag(vpnSettings settings.VPN) string {
+	data, _ := json.Marshal(vpnSettings) // settings always marshal
+	digest := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(digest[:16]) + `"`
+}
+
+func (h *vpnHandler) getFailover(w http.ResponseWriter) {
+	status := h.failover.GetStatus()
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(status); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+}
 
-	outcome := h.looper.SetSettings(h.ctx, updatedSettings)
-	_, err = w.Write([]byte(outcome))
-	if err != nil {
-		h.warner.Warn("writing response: " + err.Error())
+func (h *vpnHandler) getBandwidth(w http.ResponseWriter) {
+	series := h.bandwidth.GetSeries()
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(series); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *vpnHandler) getServerStats(w http.ResponseWriter) {
+	hostToStats := h.serverStats.GetStats()
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(hostToStats); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
 }
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/wgserver"
+)
+
+type WireguardServer interface {
+	Peers() (peers []wgserver.Peer)
+	ClientConfig(name, host string) (config string, err error)
+}
+
+// newWireguardHandler returns the handler of the Wireguard routes.
+// The client configuration route is only served if clientConfigs is
+// true, since the configuration contains the peer private key, and
+// is set to true only if API keys are configured.
+func newWireguardHandler(wireguardServer WireguardServer,
+	clientConfigs bool, warner warner) http.Handler {
+	return &wireguardHandler{
+		server:        wireguardServer,
+		clientConfigs: clientConfigs,
+		warner:        warner,
+	}
+}
+
+type wireguardHandler struct {
+	server        WireguardServer
+	clientConfigs bool
+	warner        warner
+}
+
+func (h *wireguardHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.RequestURI = strings.TrimPrefix(r.RequestURI, "/wireguard")
+	switch {
+	case r.RequestURI == "/server/peers":
+		switch r.Method {
+		case http.MethodGet:
+			h.getPeers(w)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	case strings.HasPrefix(r.RequestURI, "/server/peers/"):
+		switch r.Method {
+		case http.MethodGet:
+			h.getClientConfig(w, r)
+		default:
+			http.Error(w, "method "+r.Method+" not supported", http.StatusBadRequest)
+		}
+	default:
+		http.Error(w, "route "+r.RequestURI+" not supported", http.StatusBadRequest)
+	}
+}
+
+type wireguardPeersWrapper struct {
+	Peers []wgserver.Peer `json:"peers"`
+}
+
+// getPeers writes the name, public key and address
+// of each peer of the Wireguard server.
+func (h *wireguardHandler) getPeers(w http.ResponseWriter) {
+	data := wireguardPeersWrapper{Peers: h.server.Peers()}
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(data); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// getClientConfig writes the Wireguard client configuration of the peer
+// in plain text. This text is also the data to encode in a QR code for
+// mobile clients. It requires an admin API key, and is not served
+// if no API key is configured.
+func (h *wireguardHandler) getClientConfig(w http.ResponseWriter, r *http.Request) {
+	if !h.clientConfigs {
+		http.Error(w, "route "+r.RequestURI+" requires an API key to be configured",
+			http.StatusNotFound)
+		return
+	}
+
+	name := strings.TrimPrefix(r.RequestURI, "/server/peers/")
+	config, err := h.server.ClientConfig(name, r.Host)
+	switch {
+	case errors.Is(err, wgserver.ErrPeerNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := w.Write([]byte(config)); err != nil {
+		h.warner.Warn(err.Error())
+	}
+}
@@ -29,6 +29,17 @@ type portWrapper struct {
 	Port uint16 `json:"port"`
 }
 
+type portsWrapper struct {
+	// Port is the first port forwarded, kept for
+	// backward compatibility.
+	Port  uint16   `json:"port"`
+	Ports []uint16 `json:"ports"`
+}
+
+type hostWrapper struct {
+	Host string `json:"host"`
+}
+
 type outcomeWrapper struct {
 	Outcome string `json:"outcome"`
 }
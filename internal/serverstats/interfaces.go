@@ -0,0 +1,16 @@
+package serverstats
+
+import (
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+type Logger interface {
+	Warn(message string)
+}
+
+type ServersStorage interface {
+	FilterServers(provider string, selection settings.ServerSelection) (
+		servers []models.Server, err error)
+	GetServerByName(provider, name string) (server models.Server, ok bool)
+}
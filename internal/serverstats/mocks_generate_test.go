@@ -0,0 +1,3 @@
+package serverstats
+
+//go:generate mockgen -destination=mocks_test.go -package=$GOPACKAGE . Logger
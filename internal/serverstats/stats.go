@@ -0,0 +1,160 @@
+// Package serverstats records connection statistics for each
+// VPN server, persisted across restarts, to de-prioritize servers
+// failing to connect most of the time, and to skip servers which
+// just failed for a cooldown period.
+package serverstats
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// Stats are the connection statistics of a VPN server.
+type Stats struct {
+	Attempts    uint      `json:"attempts"`
+	Successes   uint      `json:"successes"`
+	Failures    uint      `json:"failures"`
+	LastFailure time.Time `json:"last_failure,omitempty"`
+	// ConsecutiveFailures is the number of failures since
+	// the last successful connection.
+	ConsecutiveFailures uint `json:"consecutive_failures"`
+	// AverageHandshake is the average duration between the
+	// start of the VPN and the tunnel being up.
+	AverageHandshake time.Duration `json:"average_handshake_ns"`
+}
+
+// Recorder records connection statistics per server hostname.
+type Recorder struct {
+	path        string
+	cooldown    time.Duration
+	logger      Logger
+	timeNow     func() time.Time
+	statsMu     sync.RWMutex
+	hostToStats map[string]Stats
+}
+
+// New creates a recorder loading and persisting the
+// statistics in a JSON file at /gluetun/serverstats.json.
+// Servers which failed are skipped for the cooldown given,
+// and a zero cooldown disables skipping them.
+func New(cooldown time.Duration, logger Logger) *Recorder {
+	recorder := &Recorder{
+		path:     "/gluetun/serverstats.json",
+		cooldown: cooldown,
+		logger:   logger,
+		timeNow:  time.Now,
+	}
+
+	var err error
+	recorder.hostToStats, err = readStats(recorder.path)
+	if err != nil {
+		logger.Warn(err.Error())
+		recorder.hostToStats = make(map[string]Stats)
+	}
+
+	return recorder
+}
+
+// Attempt records a connection attempt to the server hostname given.
+func (r *Recorder) Attempt(hostname string) {
+	r.update(hostname, func(stats *Stats) {
+		stats.Attempts++
+	})
+}
+
+// Success records a successful connection to the server hostname
+// given, with the handshake duration given.
+func (r *Recorder) Success(hostname string, handshake time.Duration) {
+	r.update(hostname, func(stats *Stats) {
+		stats.Successes++
+		stats.ConsecutiveFailures = 0
+		// Running average over all the successful connections.
+		previousTotal := stats.AverageHandshake * time.Duration(stats.Successes-1)
+		stats.AverageHandshake = (previousTotal + handshake) / time.Duration(stats.Successes)
+	})
+}
+
+// Failure records a failed connection to the server hostname
+// given, or a failure of the server once connected, such as
+// the VPN being restarted because it is unhealthy.
+func (r *Recorder) Failure(hostname string) {
+	r.update(hostname, func(stats *Stats) {
+		stats.Failures++
+		stats.ConsecutiveFailures++
+		stats.LastFailure = r.timeNow()
+	})
+}
+
+func (r *Recorder) update(hostname string, modify func(stats *Stats)) {
+	if hostname == "" {
+		return
+	}
+
+	r.statsMu.Lock()
+	stats := r.hostToStats[hostname]
+	modify(&stats)
+	r.hostToStats[hostname] = stats
+	hostToStats := copyStats(r.hostToStats)
+	r.statsMu.Unlock()
+
+	err := writeStats(r.path, hostToStats)
+	if err != nil {
+		r.logger.Warn(err.Error())
+	}
+}
+
+// Key returns the key to record the statistics of a server with,
+// which is its hostname or its IP address if it has no hostname.
+func Key(hostname string, ip net.IP) string {
+	if hostname != "" {
+		return hostname
+	}
+	return ip.String()
+}
+
+// GetStats returns a copy of the statistics for each server hostname.
+func (r *Recorder) GetStats() (hostToStats map[string]Stats) {
+	r.statsMu.RLock()
+	defer r.statsMu.RUnlock()
+	return copyStats(r.hostToStats)
+}
+
+func copyStats(original map[string]Stats) (copied map[string]Stats) {
+	copied = make(map[string]Stats, len(original))
+	for hostname, stats := range original {
+		copied[hostname] = stats
+	}
+	return copied
+}
+
+// isFlaky returns true if the server failed to connect more often
+// than it succeeded, over enough attempts and with a recent failure,
+// such that a server recovering is not de-prioritized forever.
+func (r *Recorder) isFlaky(hostname string) bool {
+	r.statsMu.RLock()
+	stats, ok := r.hostToStats[hostname]
+	r.statsMu.RUnlock()
+	if !ok {
+		return false
+	}
+
+	const minAttempts = 5
+	const recentFailureWindow = 24 * time.Hour
+	return stats.Attempts >= minAttempts &&
+		stats.Failures > stats.Successes &&
+		r.timeNow().Sub(stats.LastFailure) < recentFailureWindow
+}
+
+// isCoolingDown returns true if the server failed since its last
+// successful connection, less than the cooldown duration ago.
+func (r *Recorder) isCoolingDown(key string) bool {
+	if r.cooldown == 0 {
+		return false
+	}
+	r.statsMu.RLock()
+	stats, ok := r.hostToStats[key]
+	r.statsMu.RUnlock()
+	return ok && stats.ConsecutiveFailures > 0 &&
+		r.timeNow().Sub(stats.LastFailure) < r.cooldown
+}
@@ -0,0 +1,172 @@
+package serverstats
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Recorder(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	now := time.Unix(1700000000, 0).UTC()
+	path := filepath.Join(t.TempDir(), "serverstats.json")
+	recorder := &Recorder{
+		path:        path,
+		logger:      NewMockLogger(ctrl),
+		timeNow:     func() time.Time { return now },
+		hostToStats: map[string]Stats{},
+	}
+
+	recorder.Attempt("a.example.com")
+	recorder.Success("a.example.com", time.Second)
+	recorder.Attempt("a.example.com")
+	recorder.Success("a.example.com", 3*time.Second)
+	recorder.Attempt("b.example.com")
+	recorder.Failure("b.example.com")
+	recorder.Attempt("c.example.com")
+	recorder.Failure("c.example.com")
+	recorder.Attempt("c.example.com")
+	recorder.Success("c.example.com", time.Second)
+	recorder.Attempt("")
+
+	expected := map[string]Stats{
+		"a.example.com": {Attempts: 2, Successes: 2, AverageHandshake: 2 * time.Second},
+		"b.example.com": {Attempts: 1, Failures: 1, LastFailure: now, ConsecutiveFailures: 1},
+		"c.example.com": {Attempts: 2, Successes: 1, Failures: 1, LastFailure: now, AverageHandshake: time.Second},
+	}
+	assert.Equal(t, expected, recorder.GetStats())
+
+	persisted, err := readStats(path)
+	require.NoError(t, err)
+	assert.Equal(t, expected, persisted)
+}
+
+func Test_Recorder_removeFlaky(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+
+	testCases := map[string]struct {
+		cooldown    time.Duration
+		hostToStats map[string]Stats
+		servers     []models.Server
+		filtered    []models.Server
+	}{
+		"no stats": {
+			servers:  []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+			filtered: []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+		},
+		"flaky server removed": {
+			hostToStats: map[string]Stats{
+				"a": {Attempts: 5, Successes: 1, Failures: 4, LastFailure: now.Add(-time.Hour)},
+			},
+			servers:  []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+			filtered: []models.Server{{Hostname: "b"}},
+		},
+		"flaky server by x509 name removed": {
+			hostToStats: map[string]Stats{
+				"x509-a": {Attempts: 5, Failures: 5, LastFailure: now},
+			},
+			servers:  []models.Server{{Hostname: "a", OvpnX509: "x509-a"}, {Hostname: "b"}},
+			filtered: []models.Server{{Hostname: "b"}},
+		},
+		"not enough attempts": {
+			hostToStats: map[string]Stats{
+				"a": {Attempts: 4, Failures: 4, LastFailure: now},
+			},
+			servers:  []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+			filtered: []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+		},
+		"old failure": {
+			hostToStats: map[string]Stats{
+				"a": {Attempts: 5, Failures: 5, LastFailure: now.Add(-48 * time.Hour)},
+			},
+			servers:  []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+			filtered: []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+		},
+		"cooling down server removed": {
+			cooldown: 5 * time.Minute,
+			hostToStats: map[string]Stats{
+				"a": {Attempts: 1, Failures: 1, ConsecutiveFailures: 1, LastFailure: now.Add(-time.Minute)},
+			},
+			servers:  []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+			filtered: []models.Server{{Hostname: "b"}},
+		},
+		"cooling down server by IP removed": {
+			cooldown: 5 * time.Minute,
+			hostToStats: map[string]Stats{
+				"1.2.3.4": {Attempts: 1, Failures: 1, ConsecutiveFailures: 1, LastFailure: now},
+			},
+			servers: []models.Server{
+				{IPs: []net.IP{{1, 2, 3, 4}}},
+				{IPs: []net.IP{{5, 6, 7, 8}}},
+			},
+			filtered: []models.Server{{IPs: []net.IP{{5, 6, 7, 8}}}},
+		},
+		"cooldown elapsed": {
+			cooldown: 5 * time.Minute,
+			hostToStats: map[string]Stats{
+				"a": {Attempts: 1, Failures: 1, ConsecutiveFailures: 1, LastFailure: now.Add(-5 * time.Minute)},
+			},
+			servers:  []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+			filtered: []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+		},
+		"cooldown disabled": {
+			hostToStats: map[string]Stats{
+				"a": {Attempts: 1, Failures: 1, ConsecutiveFailures: 1, LastFailure: now},
+			},
+			servers:  []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+			filtered: []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+		},
+		"succeeded since failure": {
+			cooldown: 5 * time.Minute,
+			hostToStats: map[string]Stats{
+				"a": {Attempts: 2, Successes: 1, Failures: 1, LastFailure: now},
+			},
+			servers:  []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+			filtered: []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+		},
+		"all servers cooling down": {
+			cooldown: 5 * time.Minute,
+			hostToStats: map[string]Stats{
+				"a": {Attempts: 1, Failures: 1, ConsecutiveFailures: 1, LastFailure: now},
+				"b": {Attempts: 1, Failures: 1, ConsecutiveFailures: 1, LastFailure: now},
+			},
+			servers:  []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+			filtered: []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+		},
+		"all servers flaky": {
+			hostToStats: map[string]Stats{
+				"a": {Attempts: 5, Failures: 5, LastFailure: now},
+				"b": {Attempts: 6, Successes: 1, Failures: 5, LastFailure: now},
+			},
+			servers:  []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+			filtered: []models.Server{{Hostname: "a"}, {Hostname: "b"}},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := &Recorder{
+				cooldown:    testCase.cooldown,
+				timeNow:     func() time.Time { return now },
+				hostToStats: testCase.hostToStats,
+			}
+
+			filtered := recorder.removeFlaky(testCase.servers)
+
+			assert.Equal(t, testCase.filtered, filtered)
+		})
+	}
+}
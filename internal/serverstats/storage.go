@@ -0,0 +1,64 @@
+package serverstats
+
+import (
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+// Storage wraps a servers storage to remove flaky servers and
+// servers cooling down after a failure from the filtered servers,
+// as long as other servers remain.
+type Storage struct {
+	ServersStorage
+	recorder *Recorder
+}
+
+// NewStorage returns a servers storage de-prioritizing the
+// flaky servers according to the recorder given.
+func NewStorage(storage ServersStorage, recorder *Recorder) *Storage {
+	return &Storage{
+		ServersStorage: storage,
+		recorder:       recorder,
+	}
+}
+
+func (s *Storage) FilterServers(provider string, selection settings.ServerSelection) (
+	servers []models.Server, err error) {
+	servers, err = s.ServersStorage.FilterServers(provider, selection)
+	if err != nil {
+		return nil, err
+	}
+	return s.recorder.removeFlaky(servers), nil
+}
+
+func (r *Recorder) removeFlaky(servers []models.Server) (filtered []models.Server) {
+	filtered = make([]models.Server, 0, len(servers))
+	for _, server := range servers {
+		if r.skip(server) {
+			continue
+		}
+		filtered = append(filtered, server)
+	}
+
+	if len(filtered) == 0 {
+		// all servers are flaky, do not de-prioritize any of them.
+		return servers
+	}
+	return filtered
+}
+
+// skip returns true if the server is flaky or cooling down,
+// checking each of the keys its statistics can be recorded with.
+func (r *Recorder) skip(server models.Server) bool {
+	// The connection hostname is the OpenVPN x509 name for Windscribe.
+	keys := []string{server.Hostname, server.OvpnX509}
+	for _, ip := range server.IPs {
+		keys = append(keys, ip.String())
+	}
+	for _, key := range keys {
+		if key != "" && (r.isFlaky(key) || r.isCoolingDown(key)) {
+			return true
+		}
+	}
+	return false
+}
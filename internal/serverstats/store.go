@@ -0,0 +1,67 @@
+package serverstats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func readStats(path string) (hostToStats map[string]Stats, err error) {
+	hostToStats = make(map[string]Stats)
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return hostToStats, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading server statistics file: %w", err)
+	}
+
+	err = json.Unmarshal(b, &hostToStats)
+	if err != nil {
+		return nil, fmt.Errorf("decoding server statistics file: %w", err)
+	}
+	return hostToStats, nil
+}
+
+// writeStats writes the statistics to a temporary file renamed to
+// the path given, such that the file is never partially written.
+func writeStats(path string, hostToStats map[string]Stats) (err error) {
+	const dirPerms = os.FileMode(0700)
+	err = os.MkdirAll(filepath.Dir(path), dirPerms)
+	if err != nil {
+		return fmt.Errorf("creating server statistics file directory: %w", err)
+	}
+
+	b, err := json.Marshal(hostToStats)
+	if err != nil {
+		return fmt.Errorf("encoding server statistics: %w", err)
+	}
+
+	// CreateTemp creates the file with the 0600 permissions.
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating server statistics temporary file: %w", err)
+	}
+	temporaryPath := file.Name()
+
+	_, err = file.Write(b)
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(temporaryPath)
+		return fmt.Errorf("writing server statistics temporary file: %w", err)
+	}
+
+	err = os.Rename(temporaryPath, path)
+	if err != nil {
+		_ = os.Remove(temporaryPath)
+		return fmt.Errorf("renaming server statistics temporary file: %w", err)
+	}
+	return nil
+}
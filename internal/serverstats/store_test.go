@@ -0,0 +1,37 @@
+package serverstats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_writeStats(t *testing.T) {
+	t.Parallel()
+
+	directory := filepath.Join(t.TempDir(), "stats")
+	path := filepath.Join(directory, "servers.json")
+	hostToStats := map[string]Stats{
+		"host": {Attempts: 2, Failures: 1, LastFailure: time.Unix(1, 0).UTC()},
+	}
+
+	err := writeStats(path, hostToStats)
+	require.NoError(t, err)
+	err = writeStats(path, hostToStats)
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(directory)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary file left over")
+	info, err := entries[0].Info()
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	readHostToStats, err := readStats(path)
+	require.NoError(t, err)
+	assert.Equal(t, hostToStats, readHostToStats)
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/constants"
 	"github.com/qdm12/gluetun/internal/models"
-	shadowsockslib "github.com/qdm12/ss-server/pkg/tcpudp"
 )
 
 type Loop struct {
@@ -75,7 +74,7 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 
 	for ctx.Err() == nil {
 		settings := l.GetSettings()
-		server, err := shadowsockslib.NewServer(settings.Settings, l.logger)
+		server, err := newServer(settings.Settings, l.logger)
 		if err != nil {
 			crashed = true
 			l.logAndWait(ctx, err)
@@ -0,0 +1,93 @@
+package redir
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"  //nolint:gosec
+	"crypto/sha1" //nolint:gosec
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	aes128gcm            = "aes-128-gcm"
+	aes256gcm            = "aes-256-gcm"
+	chacha20IetfPoly1305 = "chacha20-ietf-poly1305"
+)
+
+var ErrCipherNotSupported = errors.New("cipher is not supported")
+
+// streamCipher creates the AEAD ciphers of Shadowsocks TCP
+// streams, each stream direction using its own salt, and of
+// Shadowsocks UDP packets, each packet using its own salt.
+type streamCipher struct {
+	preSharedKey []byte
+	newAEAD      func(key []byte) (cipher.AEAD, error)
+	saltSize     int
+}
+
+func newStreamCipher(name, password string) (c *streamCipher, err error) {
+	var keySize int
+	var newAEAD func(key []byte) (cipher.AEAD, error)
+	switch strings.ToLower(name) {
+	case aes128gcm:
+		keySize = 16
+		newAEAD = newAESGCM
+	case aes256gcm:
+		keySize = 32
+		newAEAD = newAESGCM
+	case chacha20IetfPoly1305:
+		keySize = 32
+		newAEAD = chacha20poly1305.New
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrCipherNotSupported, name)
+	}
+
+	const minimumSaltSize = 16
+	saltSize := keySize
+	if saltSize < minimumSaltSize {
+		saltSize = minimumSaltSize
+	}
+
+	return &streamCipher{
+		preSharedKey: kdf(password, keySize),
+		newAEAD:      newAEAD,
+		saltSize:     saltSize,
+	}, nil
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// kdf is the key derivation function from the original
+// Shadowsocks specification based on md5.
+func kdf(password string, length int) (key []byte) {
+	var b, prev []byte
+	hasher := md5.New() //nolint:gosec
+	for len(b) < length {
+		_, _ = hasher.Write(prev)
+		_, _ = hasher.Write([]byte(password))
+		b = hasher.Sum(b)
+		prev = b[len(b)-hasher.Size():]
+		hasher.Reset()
+	}
+	return b[:length]
+}
+
+func (c *streamCipher) aead(salt []byte) (aead cipher.AEAD, err error) {
+	subkey := make([]byte, len(c.preSharedKey))
+	const keyInfo = "ss-subkey"
+	reader := hkdf.New(sha1.New, c.preSharedKey, salt, []byte(keyInfo))
+	_, _ = io.ReadFull(reader, subkey)
+	return c.newAEAD(subkey)
+}
@@ -0,0 +1,100 @@
+package redir
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+)
+
+// serverConn is a connection to the Shadowsocks server which
+// encrypts data written and decrypts data read.
+type serverConn struct {
+	net.Conn
+	cipher *streamCipher
+	writer *streamWriter
+	reader *streamReader
+}
+
+// dialServer connects to the Shadowsocks server and requests it
+// to relay the connection to the target address given.
+func dialServer(ctx context.Context, dialer *net.Dialer, server string,
+	cipher *streamCipher, target *net.TCPAddr) (conn *serverConn, err error) {
+	netConn, err := dialer.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, cipher.saltSize)
+	_, err = io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		_ = netConn.Close()
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+
+	aead, err := cipher.aead(salt)
+	if err != nil {
+		_ = netConn.Close()
+		return nil, fmt.Errorf("creating AEAD cipher: %w", err)
+	}
+
+	_, err = netConn.Write(salt)
+	if err != nil {
+		_ = netConn.Close()
+		return nil, fmt.Errorf("writing salt: %w", err)
+	}
+
+	conn = &serverConn{
+		Conn:   netConn,
+		cipher: cipher,
+		writer: newStreamWriter(netConn, aead),
+	}
+
+	_, err = conn.writer.Write(socksAddress(target.IP, target.Port))
+	if err != nil {
+		_ = netConn.Close()
+		return nil, fmt.Errorf("writing target address: %w", err)
+	}
+
+	return conn, nil
+}
+
+func (c *serverConn) Write(p []byte) (n int, err error) {
+	return c.writer.Write(p)
+}
+
+func (c *serverConn) Read(p []byte) (n int, err error) {
+	if c.reader == nil {
+		// The server salt is only sent with its first response.
+		salt := make([]byte, c.cipher.saltSize)
+		_, err = io.ReadFull(c.Conn, salt)
+		if err != nil {
+			return 0, fmt.Errorf("reading salt: %w", err)
+		}
+
+		aead, err := c.cipher.aead(salt)
+		if err != nil {
+			return 0, fmt.Errorf("creating AEAD cipher: %w", err)
+		}
+		c.reader = newStreamReader(c.Conn, aead)
+	}
+	return c.reader.Read(p)
+}
+
+// SOCKS address types.
+const (
+	addressTypeIPv4 = 1
+	addressTypeIPv6 = 4
+)
+
+// socksAddress returns the SOCKS address of the IP address and port given.
+func socksAddress(ip net.IP, port int) (b []byte) {
+	if ipv4 := ip.To4(); ipv4 != nil {
+		b = append([]byte{addressTypeIPv4}, ipv4...)
+	} else {
+		b = append([]byte{addressTypeIPv6}, ip.To16()...)
+	}
+	return binary.BigEndian.AppendUint16(b, uint16(port))
+}
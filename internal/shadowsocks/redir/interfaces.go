@@ -0,0 +1,16 @@
+package redir
+
+import (
+	"context"
+	"net"
+)
+
+type Firewall interface {
+	SetTCPRedirect(ctx context.Context, port uint16, excludedIP net.IP) (err error)
+}
+
+type Logger interface {
+	Debug(s string)
+	Info(s string)
+	Error(s string)
+}
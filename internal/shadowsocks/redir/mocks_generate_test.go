@@ -0,0 +1,3 @@
+package redir
+
+//go:generate mockgen -destination=mocks_test.go -package=$GOPACKAGE . Logger
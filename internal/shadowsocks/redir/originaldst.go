@@ -0,0 +1,39 @@
+package redir
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// originalDestination returns the destination address of the
+// connection given before it was redirected by iptables.
+func originalDestination(conn *net.TCPConn) (address *net.TCPAddr, err error) {
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return nil, fmt.Errorf("getting raw connection: %w", err)
+	}
+
+	var sockoptErr error
+	err = rawConn.Control(func(fd uintptr) {
+		// The IPv6Mreq structure is large enough to hold the
+		// sockaddr_in structure returned by the kernel.
+		var mreq *unix.IPv6Mreq
+		mreq, sockoptErr = unix.GetsockoptIPv6Mreq(int(fd), unix.IPPROTO_IP, unix.SO_ORIGINAL_DST)
+		if sockoptErr != nil {
+			return
+		}
+		sockaddr := mreq.Multiaddr
+		address = &net.TCPAddr{
+			IP:   net.IPv4(sockaddr[4], sockaddr[5], sockaddr[6], sockaddr[7]),
+			Port: int(sockaddr[2])<<8 | int(sockaddr[3]), //nolint:gomnd
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("controlling raw connection: %w", err)
+	} else if sockoptErr != nil {
+		return nil, fmt.Errorf("getting original destination: %w", sockoptErr)
+	}
+	return address, nil
+}
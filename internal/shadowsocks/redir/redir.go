@@ -0,0 +1,188 @@
+// Package redir implements a Shadowsocks client relaying the TCP
+// connections redirected to it by iptables to a remote Shadowsocks
+// server, similarly to ss-redir, as well as a UDP tunnel relaying
+// datagrams to a fixed target, similarly to ss-tunnel.
+package redir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// listenPort is the port of the loopback address the
+// TCP traffic of the container is redirected to.
+const listenPort uint16 = 8389
+
+// Redirector relays redirected TCP connections through
+// a Shadowsocks server.
+type Redirector struct {
+	server        *net.TCPAddr
+	cipher        *streamCipher
+	firewall      Firewall
+	logger        Logger
+	listenAddress string
+	dialer        *net.Dialer
+}
+
+func New(settings settings.ShadowsocksClient, firewall Firewall,
+	logger Logger) (r *Redirector, err error) {
+	server, err := net.ResolveTCPAddr("tcp", settings.Server)
+	if err != nil {
+		return nil, fmt.Errorf("parsing server address: %w", err)
+	}
+
+	cipher, err := newStreamCipher(settings.CipherName, *settings.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Redirector{
+		server:        server,
+		cipher:        cipher,
+		firewall:      firewall,
+		logger:        logger,
+		listenAddress: net.JoinHostPort("127.0.0.1", strconv.Itoa(int(listenPort))),
+		dialer:        &net.Dialer{},
+	}, nil
+}
+
+var ErrServerUnreachable = errors.New("Shadowsocks server is unreachable")
+
+// Run redirects the TCP traffic of the container to the local relay
+// and relays it through the Shadowsocks server until the context is
+// canceled. Note UDP traffic is not relayed.
+func (r *Redirector) Run(ctx context.Context, waitError chan<- error, ready chan<- struct{}) {
+	listenConfig := net.ListenConfig{}
+	listener, err := listenConfig.Listen(ctx, "tcp4", r.listenAddress)
+	if err != nil {
+		waitError <- fmt.Errorf("listening: %w", err)
+		return
+	}
+
+	const checkTimeout = 5 * time.Second
+	checkCtx, checkCancel := context.WithTimeout(ctx, checkTimeout)
+	connection, err := r.dialer.DialContext(checkCtx, "tcp", r.server.String())
+	checkCancel()
+	if err != nil {
+		_ = listener.Close()
+		waitError <- fmt.Errorf("%w: %s", ErrServerUnreachable, err)
+		return
+	}
+	_ = connection.Close()
+
+	err = r.firewall.SetTCPRedirect(ctx, listenPort, r.server.IP)
+	if err != nil {
+		_ = listener.Close()
+		waitError <- fmt.Errorf("redirecting TCP traffic: %w", err)
+		return
+	}
+
+	r.logger.Info("relaying TCP traffic through " + r.server.String())
+
+	select {
+	case ready <- struct{}{}:
+	case <-ctx.Done():
+	}
+
+	err = r.serve(ctx, listener)
+
+	// Remove the redirection before signaling the run ended, so
+	// it cannot remove the redirection of a subsequent run.
+	removeErr := r.firewall.SetTCPRedirect(context.Background(), 0, nil)
+	if removeErr != nil && err == nil {
+		err = fmt.Errorf("removing TCP redirection: %w", removeErr)
+	}
+	waitError <- err
+}
+
+func (r *Redirector) serve(ctx context.Context, listener net.Listener) (err error) {
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			_ = listener.Close()
+			return fmt.Errorf("accepting connection: %w", err)
+		}
+
+		tcpConnection, ok := connection.(*net.TCPConn)
+		if !ok {
+			_ = connection.Close()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.handle(ctx, tcpConnection)
+		}()
+	}
+}
+
+func (r *Redirector) handle(ctx context.Context, client *net.TCPConn) {
+	defer client.Close()
+
+	target, err := originalDestination(client)
+	if err != nil {
+		r.logger.Error(err.Error())
+		return
+	}
+
+	server, err := dialServer(ctx, r.dialer, r.server.String(), r.cipher, target)
+	if err != nil {
+		r.logger.Error("connecting to Shadowsocks server: " + err.Error())
+		return
+	}
+	defer server.Close()
+
+	r.logger.Debug("relaying connection to " + target.String())
+	relay(ctx, client, server)
+}
+
+type closeWriter interface {
+	CloseWrite() error
+}
+
+// relay copies data in both directions until both directions
+// are done or the context is canceled. The end of a direction
+// is propagated by closing the write side of the destination.
+func relay(ctx context.Context, client *net.TCPConn, server *serverConn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		now := time.Now()
+		_ = client.SetDeadline(now)
+		_ = server.SetDeadline(now)
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(server, client)
+		if writeCloser, ok := server.Conn.(closeWriter); ok {
+			_ = writeCloser.CloseWrite()
+		}
+	}()
+
+	_, _ = io.Copy(client, server)
+	_ = client.CloseWrite()
+	<-done
+}
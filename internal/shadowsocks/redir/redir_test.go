@@ -0,0 +1,155 @@
+package redir
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/ss-server/pkg/tcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_stream_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	cipher, err := newStreamCipher(aes256gcm, "password")
+	require.NoError(t, err)
+	salt := make([]byte, cipher.saltSize)
+
+	writeAEAD, err := cipher.aead(salt)
+	require.NoError(t, err)
+	buffer := bytes.NewBuffer(nil)
+	writer := newStreamWriter(buffer, writeAEAD)
+
+	data := bytes.Repeat([]byte{1, 2, 3}, maxPayloadSize)
+	n, err := writer.Write(data)
+	require.NoError(t, err)
+	assert.Equal(t, len(data), n)
+
+	readAEAD, err := cipher.aead(salt)
+	require.NoError(t, err)
+	reader := newStreamReader(buffer, readAEAD)
+
+	read, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, data, read)
+}
+
+func Test_socksAddress(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		address *net.TCPAddr
+		b       []byte
+	}{
+		"IPv4": {
+			address: &net.TCPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 443},
+			b:       []byte{addressTypeIPv4, 1, 2, 3, 4, 1, 187},
+		},
+		"IPv6": {
+			address: &net.TCPAddr{IP: net.ParseIP("::1"), Port: 80},
+			b: []byte{addressTypeIPv6, 0, 0, 0, 0, 0, 0, 0, 0,
+				0, 0, 0, 0, 0, 0, 0, 1, 0, 80},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			b := socksAddress(testCase.address.IP, testCase.address.Port)
+
+			assert.Equal(t, testCase.b, b)
+		})
+	}
+}
+
+func freeAddress(t *testing.T) (address string) {
+	t.Helper()
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	address = listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return address
+}
+
+func Test_dialServer(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// Echo target server
+	target, err := net.ListenTCP("tcp4", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = target.Close() })
+	go func() {
+		connection, err := target.Accept()
+		if err != nil {
+			return
+		}
+		defer connection.Close()
+		_, _ = io.Copy(connection, connection)
+	}()
+
+	const password = "password"
+	serverAddress := freeAddress(t)
+	logAddresses := false
+	serverLogger := NewMockLogger(ctrl)
+	serverLogger.EXPECT().Info("listening TCP on " + serverAddress)
+	// The server relay ends with a read deadline exceeded
+	// once the connection is closed.
+	relayDone := make(chan struct{})
+	serverLogger.EXPECT().Debug(gomock.Any()).Do(func(s string) {
+		assert.True(t, strings.HasPrefix(s, "TCP relay error: "), s)
+		assert.True(t, strings.HasSuffix(s, "i/o timeout"), s)
+		close(relayDone)
+	})
+	server, err := tcp.NewServer(tcp.Settings{
+		Address:      serverAddress,
+		LogAddresses: &logAddresses,
+		CipherName:   chacha20IetfPoly1305,
+		Password:     stringPtr(password),
+	}, serverLogger)
+	require.NoError(t, err)
+	serverDone := make(chan struct{})
+	go func() {
+		defer close(serverDone)
+		_ = server.Listen(ctx)
+	}()
+
+	cipher, err := newStreamCipher(chacha20IetfPoly1305, password)
+	require.NoError(t, err)
+
+	var connection *serverConn
+	dialer := &net.Dialer{}
+	targetAddress := target.Addr().(*net.TCPAddr) //nolint:forcetypeassert
+	require.Eventually(t, func() bool {
+		connection, err = dialServer(ctx, dialer, serverAddress, cipher, targetAddress)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	t.Cleanup(func() { _ = connection.Close() })
+
+	_, err = connection.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	response := make([]byte, len("hello"))
+	_, err = io.ReadFull(connection, response)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(response))
+
+	require.NoError(t, connection.Close())
+	<-relayDone
+	cancel()
+	<-serverDone
+}
+
+func stringPtr(s string) *string { return &s }
@@ -0,0 +1,131 @@
+package redir
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// maxPayloadSize is the maximum size of the payload
+// of a chunk of a Shadowsocks AEAD TCP stream.
+const maxPayloadSize = 0x3FFF
+
+// lengthSize is the size of the encrypted payload length
+// preceding each chunk payload.
+const lengthSize = 2
+
+// streamWriter encrypts data written to it in chunks made of
+// the encrypted payload length followed by the encrypted payload.
+type streamWriter struct {
+	writer io.Writer
+	aead   cipher.AEAD
+	nonce  []byte
+	buffer []byte
+}
+
+func newStreamWriter(writer io.Writer, aead cipher.AEAD) *streamWriter {
+	overhead := aead.Overhead()
+	return &streamWriter{
+		writer: writer,
+		aead:   aead,
+		nonce:  make([]byte, aead.NonceSize()),
+		buffer: make([]byte, lengthSize+overhead+maxPayloadSize+overhead),
+	}
+}
+
+func (w *streamWriter) Write(p []byte) (n int, err error) {
+	overhead := w.aead.Overhead()
+	for len(p) > 0 {
+		payload := p
+		if len(payload) > maxPayloadSize {
+			payload = payload[:maxPayloadSize]
+		}
+
+		chunk := w.buffer[:lengthSize+overhead+len(payload)+overhead]
+		binary.BigEndian.PutUint16(chunk, uint16(len(payload)))
+		w.aead.Seal(chunk[:0], w.nonce, chunk[:lengthSize], nil)
+		incrementNonce(w.nonce)
+		payloadStart := lengthSize + overhead
+		w.aead.Seal(chunk[payloadStart:payloadStart], w.nonce, payload, nil)
+		incrementNonce(w.nonce)
+
+		_, err = w.writer.Write(chunk)
+		if err != nil {
+			return n, err
+		}
+		n += len(payload)
+		p = p[len(payload):]
+	}
+	return n, nil
+}
+
+// streamReader decrypts the chunks read from the reader.
+type streamReader struct {
+	reader   io.Reader
+	aead     cipher.AEAD
+	nonce    []byte
+	buffer   []byte
+	leftover []byte
+}
+
+func newStreamReader(reader io.Reader, aead cipher.AEAD) *streamReader {
+	overhead := aead.Overhead()
+	return &streamReader{
+		reader: reader,
+		aead:   aead,
+		nonce:  make([]byte, aead.NonceSize()),
+		buffer: make([]byte, maxPayloadSize+overhead),
+	}
+}
+
+func (r *streamReader) Read(p []byte) (n int, err error) {
+	if len(r.leftover) == 0 {
+		r.leftover, err = r.readChunk()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n = copy(p, r.leftover)
+	r.leftover = r.leftover[n:]
+	return n, nil
+}
+
+func (r *streamReader) readChunk() (payload []byte, err error) {
+	overhead := r.aead.Overhead()
+
+	lengthChunk := r.buffer[:lengthSize+overhead]
+	_, err = io.ReadFull(r.reader, lengthChunk)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.aead.Open(lengthChunk[:0], r.nonce, lengthChunk, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting payload length: %w", err)
+	}
+	incrementNonce(r.nonce)
+	length := int(binary.BigEndian.Uint16(lengthChunk)) & maxPayloadSize
+
+	payloadChunk := r.buffer[:length+overhead]
+	_, err = io.ReadFull(r.reader, payloadChunk)
+	if err != nil {
+		return nil, err
+	}
+	payload, err = r.aead.Open(payloadChunk[:0], r.nonce, payloadChunk, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting payload: %w", err)
+	}
+	incrementNonce(r.nonce)
+	return payload, nil
+}
+
+// incrementNonce increments the nonce given as a
+// little endian unsigned integer.
+func incrementNonce(nonce []byte) {
+	for i := range nonce {
+		nonce[i]++
+		if nonce[i] != 0 {
+			return
+		}
+	}
+}
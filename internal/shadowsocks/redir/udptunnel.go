@@ -0,0 +1,298 @@
+package redir
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"golang.org/x/sys/unix"
+)
+
+// UDPTunnel relays the UDP datagrams sent to its local address to
+// a fixed target address through a Shadowsocks server, similarly to
+// ss-tunnel. Datagrams received from the target are sent back to the
+// last local client, since it is meant to be used by a single client
+// such as the Wireguard interface.
+type UDPTunnel struct {
+	server       *net.UDPAddr
+	target       *net.UDPAddr
+	local        *net.UDPAddr
+	cipher       *streamCipher
+	firewallMark int
+	logger       Logger
+}
+
+// NewUDPTunnel creates a UDP tunnel listening on the loopback
+// address at the local port given, and relaying datagrams to the
+// target given. The socket to the Shadowsocks server is marked
+// with the firewall mark given, if it is not zero, so it can be
+// routed outside the VPN tunnel.
+func NewUDPTunnel(settings settings.ShadowsocksClient, target *net.UDPAddr,
+	localPort uint16, firewallMark int, logger Logger) (t *UDPTunnel, err error) {
+	server, err := net.ResolveUDPAddr("udp", settings.Server)
+	if err != nil {
+		return nil, fmt.Errorf("parsing server address: %w", err)
+	}
+
+	cipher, err := newStreamCipher(settings.CipherName, *settings.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UDPTunnel{
+		server:       server,
+		target:       target,
+		local:        &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: int(localPort)},
+		cipher:       cipher,
+		firewallMark: firewallMark,
+		logger:       logger,
+	}, nil
+}
+
+// LocalAddress returns the loopback address the
+// datagrams to relay should be sent to.
+func (t *UDPTunnel) LocalAddress() *net.UDPAddr {
+	return &net.UDPAddr{IP: t.local.IP, Port: t.local.Port}
+}
+
+// Run relays datagrams through the Shadowsocks server
+// until the context is canceled.
+func (t *UDPTunnel) Run(ctx context.Context, waitError chan<- error, ready chan<- struct{}) {
+	local, err := net.ListenUDP("udp4", t.local)
+	if err != nil {
+		waitError <- fmt.Errorf("listening: %w", err)
+		return
+	}
+
+	listenConfig := net.ListenConfig{Control: markControl(t.firewallMark)}
+	remote, err := listenConfig.ListenPacket(ctx, "udp", ":0")
+	if err != nil {
+		_ = local.Close()
+		waitError <- fmt.Errorf("listening for the server: %w", err)
+		return
+	}
+
+	t.logger.Info("relaying UDP datagrams for " + t.target.String() +
+		" through " + t.server.String())
+
+	select {
+	case ready <- struct{}{}:
+	case <-ctx.Done():
+	}
+
+	waitError <- t.relay(ctx, local, remote)
+}
+
+func markControl(firewallMark int) func(network, address string, c syscall.RawConn) error {
+	return func(_, _ string, c syscall.RawConn) (err error) {
+		if firewallMark == 0 {
+			return nil
+		}
+		controlErr := c.Control(func(fd uintptr) {
+			err = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_MARK, firewallMark)
+		})
+		if controlErr != nil {
+			return controlErr
+		}
+		if err != nil {
+			return fmt.Errorf("setting firewall mark: %w", err)
+		}
+		return nil
+	}
+}
+
+// relay relays datagrams in both directions until the context
+// is canceled or one of the connections fails.
+func (t *UDPTunnel) relay(ctx context.Context, local *net.UDPConn,
+	remote net.PacketConn) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		_ = local.Close()
+		_ = remote.Close()
+	}()
+
+	client := &clientAddress{}
+	errs := make(chan error, 2) //nolint:gomnd
+	go func() {
+		errs <- t.relayToServer(local, remote, client)
+		cancel()
+	}()
+	go func() {
+		errs <- t.relayToClient(remote, local, client)
+		cancel()
+	}()
+
+	for i := 0; i < cap(errs); i++ {
+		relayErr := <-errs
+		if err == nil && ctx.Err() == nil {
+			// first error not caused by the context being canceled
+			err = relayErr
+		}
+	}
+	return err
+}
+
+// maxDatagramSize is the maximum size of a UDP datagram.
+const maxDatagramSize = 65535
+
+func (t *UDPTunnel) relayToServer(local *net.UDPConn, remote net.PacketConn,
+	client *clientAddress) (err error) {
+	buffer := make([]byte, maxDatagramSize)
+	header := socksAddress(t.target.IP, t.target.Port)
+	plaintext := make([]byte, 0, len(header)+maxDatagramSize)
+	for {
+		n, address, err := local.ReadFromUDP(buffer)
+		if err != nil {
+			return fmt.Errorf("reading from local client: %w", err)
+		}
+		client.set(address)
+
+		plaintext = append(plaintext[:0], header...)
+		plaintext = append(plaintext, buffer[:n]...)
+		packet, err := t.seal(plaintext)
+		if err != nil {
+			return err
+		}
+
+		_, err = remote.WriteTo(packet, t.server)
+		if err != nil {
+			t.logger.Debug("sending datagram to Shadowsocks server: " + err.Error())
+		}
+	}
+}
+
+func (t *UDPTunnel) relayToClient(remote net.PacketConn, local *net.UDPConn,
+	client *clientAddress) (err error) {
+	buffer := make([]byte, maxDatagramSize)
+	for {
+		n, address, err := remote.ReadFrom(buffer)
+		if err != nil {
+			return fmt.Errorf("reading from Shadowsocks server: %w", err)
+		}
+		udpAddress, ok := address.(*net.UDPAddr)
+		if !ok || !udpAddress.IP.Equal(t.server.IP) || udpAddress.Port != t.server.Port {
+			continue
+		}
+
+		payload, err := t.open(buffer[:n])
+		if err != nil {
+			t.logger.Debug("dropping datagram from Shadowsocks server: " + err.Error())
+			continue
+		}
+
+		clientAddress := client.get()
+		if clientAddress == nil {
+			continue
+		}
+		_, err = local.WriteToUDP(payload, clientAddress)
+		if err != nil {
+			t.logger.Debug("sending datagram to local client: " + err.Error())
+		}
+	}
+}
+
+// seal encrypts the plaintext given in a Shadowsocks AEAD UDP
+// packet made of a random salt followed by the encrypted plaintext.
+// Each packet uses its own salt, so the nonce is always zero.
+func (t *UDPTunnel) seal(plaintext []byte) (packet []byte, err error) {
+	salt := make([]byte, t.cipher.saltSize)
+	_, err = io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+
+	aead, err := t.cipher.aead(salt)
+	if err != nil {
+		return nil, fmt.Errorf("creating AEAD cipher: %w", err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	return aead.Seal(salt, nonce, plaintext, nil), nil
+}
+
+var (
+	ErrPacketTooShort     = errors.New("packet is too short")
+	ErrAddressTypeUnknown = errors.New("address type is unknown")
+)
+
+// open decrypts the Shadowsocks AEAD UDP packet given and
+// returns its payload without its leading SOCKS address.
+func (t *UDPTunnel) open(packet []byte) (payload []byte, err error) {
+	if len(packet) < t.cipher.saltSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrPacketTooShort, len(packet))
+	}
+	salt, ciphertext := packet[:t.cipher.saltSize], packet[t.cipher.saltSize:]
+
+	aead, err := t.cipher.aead(salt)
+	if err != nil {
+		return nil, fmt.Errorf("creating AEAD cipher: %w", err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	plaintext, err := aead.Open(ciphertext[:0], nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting packet: %w", err)
+	}
+
+	addressLength, err := socksAddressLength(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return plaintext[addressLength:], nil
+}
+
+// addressTypeDomain is the SOCKS address type of domain names.
+const addressTypeDomain = 3
+
+// socksAddressLength returns the length of the SOCKS
+// address at the start of the bytes given.
+func socksAddressLength(b []byte) (length int, err error) {
+	const portLength = 2
+	if len(b) == 0 {
+		return 0, fmt.Errorf("%w: no address type", ErrPacketTooShort)
+	}
+	switch b[0] {
+	case addressTypeIPv4:
+		length = 1 + net.IPv4len + portLength
+	case addressTypeIPv6:
+		length = 1 + net.IPv6len + portLength
+	case addressTypeDomain:
+		if len(b) < 2 { //nolint:gomnd
+			return 0, fmt.Errorf("%w: no domain name length", ErrPacketTooShort)
+		}
+		length = 2 + int(b[1]) + portLength
+	default:
+		return 0, fmt.Errorf("%w: %d", ErrAddressTypeUnknown, b[0])
+	}
+	if len(b) < length {
+		return 0, fmt.Errorf("%w: address is %d bytes but packet is %d bytes",
+			ErrPacketTooShort, length, len(b))
+	}
+	return length, nil
+}
+
+// clientAddress is the address of the last local client.
+type clientAddress struct {
+	mutex   sync.RWMutex
+	address *net.UDPAddr
+}
+
+func (c *clientAddress) set(address *net.UDPAddr) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.address = address
+}
+
+func (c *clientAddress) get() (address *net.UDPAddr) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.address
+}
@@ -0,0 +1,146 @@
+package redir
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/ss-server/pkg/udp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func freeUDPPort(t *testing.T) (port int) {
+	t.Helper()
+	connection, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	port = connection.LocalAddr().(*net.UDPAddr).Port //nolint:forcetypeassert
+	require.NoError(t, connection.Close())
+	return port
+}
+
+func Test_UDPTunnel(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// Echo target server
+	target, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = target.Close() })
+	go func() {
+		buffer := make([]byte, maxDatagramSize)
+		for {
+			n, address, err := target.ReadFromUDP(buffer)
+			if err != nil {
+				return
+			}
+			_, _ = target.WriteToUDP(buffer[:n], address)
+		}
+	}()
+
+	const password = "password"
+	serverAddress := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: freeUDPPort(t)}
+	logAddresses := false
+	serverLogger := NewMockLogger(ctrl)
+	serverListening := make(chan struct{})
+	serverLogger.EXPECT().Info("listening UDP on " + serverAddress.String()).
+		Do(func(string) { close(serverListening) })
+	server, err := udp.NewServer(udp.Settings{
+		Address:      serverAddress.String(),
+		LogAddresses: &logAddresses,
+		CipherName:   aes128gcm,
+		Password:     stringPtr(password),
+	}, serverLogger)
+	require.NoError(t, err)
+	// The server is not stopped since canceling its context
+	// triggers a data race within the ss-server library.
+	go func() { _ = server.Listen(context.Background()) }()
+	// Wait for the server to listen, such that no datagram sent
+	// by the tunnel is refused by the server host.
+	<-serverListening
+
+	targetAddress := target.LocalAddr().(*net.UDPAddr) //nolint:forcetypeassert
+	logger := NewMockLogger(ctrl)
+	logger.EXPECT().Info("relaying UDP datagrams for " + targetAddress.String() +
+		" through " + serverAddress.String())
+	tunnel, err := NewUDPTunnel(settings.ShadowsocksClient{
+		Server:     serverAddress.String(),
+		Password:   stringPtr(password),
+		CipherName: aes128gcm,
+	}, targetAddress, uint16(freeUDPPort(t)), 0, logger)
+	require.NoError(t, err)
+
+	waitError := make(chan error)
+	ready := make(chan struct{})
+	go tunnel.Run(ctx, waitError, ready)
+	<-ready
+
+	client, err := net.DialUDP("udp4", nil, tunnel.LocalAddress())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	response := make([]byte, maxDatagramSize)
+	require.Eventually(t, func() bool {
+		_, err = client.Write([]byte("hello"))
+		require.NoError(t, err)
+		err = client.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+		require.NoError(t, err)
+		n, err := client.Read(response)
+		return err == nil && string(response[:n]) == "hello"
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-waitError)
+}
+
+func Test_socksAddressLength(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		b      []byte
+		length int
+		errMsg string
+	}{
+		"empty": {
+			errMsg: "packet is too short: no address type",
+		},
+		"IPv4": {
+			b:      []byte{addressTypeIPv4, 1, 2, 3, 4, 0, 80, 9},
+			length: 7,
+		},
+		"IPv6 too short": {
+			b:      []byte{addressTypeIPv6, 1, 2, 3, 4},
+			errMsg: "packet is too short: address is 19 bytes but packet is 5 bytes",
+		},
+		"domain": {
+			b:      []byte{addressTypeDomain, 1, 'a', 0, 80},
+			length: 5,
+		},
+		"unknown type": {
+			b:      []byte{9},
+			errMsg: "address type is unknown: 9",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			length, err := socksAddressLength(testCase.b)
+
+			if testCase.errMsg != "" {
+				assert.EqualError(t, err, testCase.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.length, length)
+		})
+	}
+}
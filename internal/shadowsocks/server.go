@@ -0,0 +1,70 @@
+package shadowsocks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/shadowsocks/udp"
+	"github.com/qdm12/ss-server/pkg/tcp"
+	"github.com/qdm12/ss-server/pkg/tcpudp"
+)
+
+// server runs the Shadowsocks TCP server from qdm12/ss-server
+// together with the batching UDP relay server.
+type server struct {
+	tcpServer *tcp.Server
+	udpServer *udp.Server
+	logger    Logger
+}
+
+func newServer(settings tcpudp.Settings, logger Logger) (s *server, err error) {
+	settings.SetDefaults()
+
+	tcpServer, err := tcp.NewServer(settings.TCP, logger)
+	if err != nil {
+		return nil, fmt.Errorf("creating TCP server: %w", err)
+	}
+
+	udpServer, err := udp.NewServer(settings.UDP, logger)
+	if err != nil {
+		return nil, fmt.Errorf("creating UDP server: %w", err)
+	}
+
+	return &server{
+		tcpServer: tcpServer,
+		udpServer: udpServer,
+		logger:    logger,
+	}, nil
+}
+
+// Listen runs the TCP and UDP servers until the context is
+// canceled or one of them exits, in which case the other server
+// is stopped and the error of the first server to exit is returned.
+func (s *server) Listen(ctx context.Context) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	tcpErrorCh := make(chan error)
+	udpErrorCh := make(chan error)
+	go func() {
+		tcpErrorCh <- s.tcpServer.Listen(ctx)
+	}()
+	go func() {
+		udpErrorCh <- s.udpServer.Listen(ctx)
+	}()
+
+	select {
+	case err = <-tcpErrorCh:
+		s.logger.Info("TCP server exited")
+		cancel()
+		<-udpErrorCh
+		s.logger.Info("UDP server exited")
+	case err = <-udpErrorCh:
+		s.logger.Info("UDP server exited")
+		cancel()
+		<-tcpErrorCh
+		s.logger.Info("TCP server exited")
+	}
+
+	return err
+}
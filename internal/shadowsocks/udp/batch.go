@@ -0,0 +1,51 @@
+package udp
+
+import (
+	"net"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// batchSize is the maximum number of packets read or
+// written in a single recvmmsg or sendmmsg system call.
+const batchSize = 32
+
+// batchConn reads and writes several packets per system call.
+// Note ipv4.Message and ipv6.Message are both aliases for the
+// same type, so both ipv4 and ipv6 packet conns implement it.
+type batchConn interface {
+	ReadBatch(messages []ipv4.Message, flags int) (n int, err error)
+	WriteBatch(messages []ipv4.Message, flags int) (n int, err error)
+}
+
+// newBatchConn returns a batch connection for the UDP connection
+// which must be bound to a single IP family network, either
+// "udp4" or "udp6". This is required since batched writes cannot
+// send to IPv4 addresses on a dual stack IPv6 socket.
+func newBatchConn(conn *net.UDPConn, network string) batchConn { //nolint:ireturn
+	if network == "udp4" {
+		return ipv4.NewPacketConn(conn)
+	}
+	return ipv6.NewPacketConn(conn)
+}
+
+func networkOf(ip net.IP) (network string) {
+	if ip.To4() != nil {
+		return "udp4"
+	}
+	return "udp6"
+}
+
+// writeAll writes all the messages given, calling
+// WriteBatch as many times as needed.
+func writeAll(conn batchConn, messages []ipv4.Message) (err error) {
+	for len(messages) > 0 {
+		n, err := conn.WriteBatch(messages, 0)
+		if err != nil {
+			return err
+		}
+		messages = messages[n:]
+	}
+	return nil
+}
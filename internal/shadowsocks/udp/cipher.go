@@ -0,0 +1,158 @@
+package udp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5" //nolint:gosec
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	aes128gcm            = "aes-128-gcm"
+	aes256gcm            = "aes-256-gcm"
+	chacha20IetfPoly1305 = "chacha20-ietf-poly1305"
+)
+
+var ErrCipherNotSupported = errors.New("cipher is not supported")
+
+// packetCipher encrypts and decrypts Shadowsocks AEAD
+// UDP packets, each packet using its own random salt.
+type packetCipher struct {
+	preSharedKey []byte
+	newAEAD      func(key []byte) (cipher.AEAD, error)
+	saltSize     int
+	saltFilter   *saltFilter
+}
+
+func newPacketCipher(name, password string) (c *packetCipher, err error) {
+	var keySize int
+	var newAEAD func(key []byte) (cipher.AEAD, error)
+	switch strings.ToLower(name) {
+	case aes128gcm:
+		keySize = 16
+		newAEAD = newAESGCM
+	case aes256gcm:
+		keySize = 32
+		newAEAD = newAESGCM
+	case chacha20IetfPoly1305:
+		keySize = 32
+		newAEAD = chacha20poly1305.New
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrCipherNotSupported, name)
+	}
+
+	const minimumSaltSize = 16
+	saltSize := keySize
+	if saltSize < minimumSaltSize {
+		saltSize = minimumSaltSize
+	}
+
+	return &packetCipher{
+		preSharedKey: kdf(password, keySize),
+		newAEAD:      newAEAD,
+		saltSize:     saltSize,
+		saltFilter:   newSaltFilter(),
+	}, nil
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// kdf is the key derivation function from the original
+// Shadowsocks specification based on md5.
+func kdf(password string, length int) (key []byte) {
+	var b, prev []byte
+	hasher := md5.New() //nolint:gosec
+	for len(b) < length {
+		_, _ = hasher.Write(prev)
+		_, _ = hasher.Write([]byte(password))
+		b = hasher.Sum(b)
+		prev = b[len(b)-hasher.Size():]
+		hasher.Reset()
+	}
+	return b[:length]
+}
+
+func (c *packetCipher) aead(salt []byte) (aead cipher.AEAD, err error) {
+	subkey := make([]byte, len(c.preSharedKey))
+	const keyInfo = "ss-subkey"
+	reader := hkdf.New(sha1.New, c.preSharedKey, salt, []byte(keyInfo))
+	_, _ = io.ReadFull(reader, subkey)
+	return c.newAEAD(subkey)
+}
+
+// zeroNonce is a read-only zeroed array used as nonce
+// for UDP packets, since each packet has its own subkey.
+var zeroNonce [32]byte //nolint:gochecknoglobals
+
+// pack encrypts plaintext into dst and returns the slice
+// of dst containing the salt followed by the ciphertext.
+func (c *packetCipher) pack(dst, plaintext []byte) (packet []byte, err error) {
+	salt := dst[:c.saltSize]
+	_, err = io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+
+	aead, err := c.aead(salt)
+	if err != nil {
+		return nil, fmt.Errorf("creating AEAD cipher: %w", err)
+	}
+	c.saltFilter.add(salt)
+
+	if len(dst) < c.saltSize+len(plaintext)+aead.Overhead() {
+		return nil, io.ErrShortBuffer
+	}
+	ciphertext := aead.Seal(dst[c.saltSize:c.saltSize],
+		zeroNonce[:aead.NonceSize()], plaintext, nil)
+	return dst[:c.saltSize+len(ciphertext)], nil
+}
+
+var (
+	ErrPacketTooShort = errors.New("packet is too short")
+	ErrSaltRepeated   = errors.New("repeated salt detected")
+)
+
+// unpack decrypts the packet in place and returns the
+// slice of the packet containing the plaintext.
+func (c *packetCipher) unpack(packet []byte) (plaintext []byte, err error) {
+	if len(packet) < c.saltSize {
+		return nil, fmt.Errorf("%w: %d bytes instead of minimum of %d bytes",
+			ErrPacketTooShort, len(packet), c.saltSize)
+	}
+	salt := packet[:c.saltSize]
+	if c.saltFilter.contains(salt) {
+		return nil, fmt.Errorf("%w: possible replay attack, dropping the packet", ErrSaltRepeated)
+	}
+
+	aead, err := c.aead(salt)
+	if err != nil {
+		return nil, fmt.Errorf("creating AEAD cipher: %w", err)
+	}
+
+	if len(packet) < c.saltSize+aead.Overhead() {
+		return nil, fmt.Errorf("%w: %d bytes is too short to be a valid encrypted packet",
+			ErrPacketTooShort, len(packet))
+	}
+
+	ciphertext := packet[c.saltSize:]
+	plaintext, err = aead.Open(ciphertext[:0], zeroNonce[:aead.NonceSize()], ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting packet: %w", err)
+	}
+	c.saltFilter.add(salt)
+	return plaintext, nil
+}
@@ -0,0 +1,6 @@
+package udp
+
+type Logger interface {
+	Info(s string)
+	Error(s string)
+}
@@ -0,0 +1,78 @@
+package udp
+
+import (
+	"fmt"
+	"net"
+	"sync"
+)
+
+// natEntry is the outbound UDP socket used for all the
+// targets of a single client and of a single IP family.
+type natEntry struct {
+	key       string
+	client    *net.UDPAddr
+	listener  batchConn
+	conn      *net.UDPConn
+	batchConn batchConn
+}
+
+// natMap maps client addresses to their outbound sockets.
+type natMap struct {
+	keyToEntry map[string]*natEntry
+	mutex      sync.Mutex
+}
+
+func newNATMap() *natMap {
+	return &natMap{
+		keyToEntry: make(map[string]*natEntry),
+	}
+}
+
+// getOrCreate returns the NAT entry for the client and the IP
+// family of the target, creating it if it does not exist yet.
+func (m *natMap) getOrCreate(client, target *net.UDPAddr, listener batchConn) (
+	entry *natEntry, created bool, err error) {
+	network := networkOf(target.IP)
+	key := client.String() + "/" + network
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entry, ok := m.keyToEntry[key]
+	if ok {
+		return entry, false, nil
+	}
+
+	conn, err := net.ListenUDP(network, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("listening on outbound UDP socket: %w", err)
+	}
+
+	entry = &natEntry{
+		key:       key,
+		client:    client,
+		listener:  listener,
+		conn:      conn,
+		batchConn: newBatchConn(conn, network),
+	}
+	m.keyToEntry[key] = entry
+	return entry, true, nil
+}
+
+func (m *natMap) remove(entry *natEntry) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.keyToEntry[entry.key] == entry {
+		delete(m.keyToEntry, entry.key)
+	}
+	_ = entry.conn.Close()
+}
+
+func (m *natMap) closeAll() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for key, entry := range m.keyToEntry {
+		_ = entry.conn.Close()
+		delete(m.keyToEntry, key)
+	}
+}
@@ -0,0 +1,32 @@
+package udp
+
+import "sync"
+
+// maxPacketSize is the maximum size of a UDP packet.
+const maxPacketSize = 64 * 1024
+
+// bufferPool re-uses packet buffers across reads and
+// writes to avoid allocating 64KiB for every packet.
+type bufferPool struct {
+	pool sync.Pool
+}
+
+func newBufferPool() *bufferPool {
+	return &bufferPool{
+		pool: sync.Pool{
+			New: func() any {
+				buffer := make([]byte, maxPacketSize)
+				return &buffer
+			},
+		},
+	}
+}
+
+func (p *bufferPool) get() (buffer []byte) {
+	return *p.pool.Get().(*[]byte) //nolint:forcetypeassert
+}
+
+func (p *bufferPool) put(buffer []byte) {
+	buffer = buffer[:cap(buffer)]
+	p.pool.Put(&buffer)
+}
@@ -0,0 +1,45 @@
+package udp
+
+import "sync"
+
+// saltFilter remembers the most recent salts seen
+// in order to detect replayed packets.
+type saltFilter struct {
+	capacity int
+	salts    map[string]struct{}
+	ring     []string
+	position int
+	mutex    sync.Mutex
+}
+
+func newSaltFilter() *saltFilter {
+	const capacity = 1 << 16
+	return &saltFilter{
+		capacity: capacity,
+		salts:    make(map[string]struct{}, capacity),
+		ring:     make([]string, capacity),
+	}
+}
+
+func (f *saltFilter) add(salt []byte) {
+	key := string(salt)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if _, exists := f.salts[key]; exists {
+		return
+	}
+	evicted := f.ring[f.position]
+	if evicted != "" {
+		delete(f.salts, evicted)
+	}
+	f.ring[f.position] = key
+	f.position = (f.position + 1) % f.capacity
+	f.salts[key] = struct{}{}
+}
+
+func (f *saltFilter) contains(salt []byte) (ok bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	_, ok = f.salts[string(salt)]
+	return ok
+}
@@ -0,0 +1,350 @@
+package udp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/qdm12/ss-server/pkg/udp"
+	"golang.org/x/net/ipv4"
+)
+
+// Server is a Shadowsocks UDP relay server reading and
+// writing packets in batches to reduce system calls overhead.
+type Server struct {
+	address      string
+	logAddresses bool
+	logger       Logger
+	cipher       *packetCipher
+	buffers      *bufferPool
+	timeNow      func() time.Time
+	resolver     *net.Resolver
+	// resolutions limits the number of domain names
+	// resolved concurrently, see relayToDomain.
+	resolutions chan struct{}
+}
+
+// maxResolutions is the maximum number of target domain names
+// resolved concurrently, packets to domain names being dropped
+// beyond it.
+const maxResolutions = 64
+
+// NewServer creates a new UDP relay server using the
+// settings given, which should have their defaults set.
+func NewServer(settings udp.Settings, logger Logger) (server *Server, err error) {
+	settings.SetDefaults()
+	err = settings.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating settings: %w", err)
+	}
+
+	cipher, err := newPacketCipher(settings.CipherName, *settings.Password)
+	if err != nil {
+		return nil, fmt.Errorf("creating packet cipher: %w", err)
+	}
+
+	return &Server{
+		address:      settings.Address,
+		logAddresses: *settings.LogAddresses,
+		logger:       logger,
+		cipher:       cipher,
+		buffers:      newBufferPool(),
+		timeNow:      time.Now,
+		resolver:     net.DefaultResolver,
+		resolutions:  make(chan struct{}, maxResolutions),
+	}, nil
+}
+
+// Listen listens for encrypted packets and does UDP NATing
+// until the context is canceled.
+func (s *Server) Listen(ctx context.Context) (err error) {
+	host, port, err := net.SplitHostPort(s.address)
+	if err != nil {
+		return fmt.Errorf("splitting listening address: %w", err)
+	}
+
+	networks := []string{"udp4", "udp6"}
+	if ip := net.ParseIP(host); ip != nil && !ip.IsUnspecified() {
+		networks = []string{networkOf(ip)}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	nat := newNATMap()
+	defer nat.closeAll()
+
+	listenConfig := net.ListenConfig{}
+	errCh := make(chan error)
+	listening := 0
+	for _, network := range networks {
+		listenHost := host
+		if len(networks) > 1 {
+			listenHost = "" // wildcard address for the network
+		}
+		address := net.JoinHostPort(listenHost, port)
+		packetConn, err := listenConfig.ListenPacket(ctx, network, address)
+		if err != nil {
+			if len(networks) > 1 && network == "udp6" && listening > 0 {
+				s.logger.Info("not listening UDP over IPv6: " + err.Error())
+				continue
+			}
+			cancel()
+			for ; listening > 0; listening-- {
+				<-errCh
+			}
+			return err
+		}
+		udpConn := packetConn.(*net.UDPConn) //nolint:forcetypeassert
+		listening++
+		go func(network string) {
+			errCh <- s.serve(ctx, udpConn, network, nat)
+		}(network)
+	}
+
+	s.logger.Info("listening UDP on " + s.address)
+	err = <-errCh
+	cancel()
+	for listening--; listening > 0; listening-- {
+		<-errCh
+	}
+	return err
+}
+
+// serve reads packets in batches from the UDP connection
+// until the context is canceled or the connection is closed.
+// Read errors are retried with an exponential backoff.
+func (s *Server) serve(ctx context.Context, udpConn *net.UDPConn,
+	network string, nat *natMap) (err error) {
+	go func() {
+		<-ctx.Done()
+		if err := udpConn.Close(); err != nil {
+			s.logger.Error(err.Error())
+		}
+	}()
+
+	listener := newBatchConn(udpConn, network)
+
+	messages := make([]ipv4.Message, batchSize)
+	for i := range messages {
+		buffer := s.buffers.get()
+		defer s.buffers.put(buffer)
+		messages[i].Buffers = [][]byte{buffer}
+	}
+
+	const minBackoff, maxBackoff = 10 * time.Millisecond, time.Second
+	backoff := minBackoff
+	for {
+		n, err := listener.ReadBatch(messages, 0)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			} else if errors.Is(err, net.ErrClosed) {
+				return fmt.Errorf("reading UDP packets: %w", err)
+			}
+			s.logger.Error("reading UDP packets: " + err.Error() +
+				" (retrying in " + backoff.String() + ")")
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+			continue
+		}
+		backoff = minBackoff
+
+		s.relayBatch(ctx, messages[:n], listener, nat)
+	}
+}
+
+// relayBatch decrypts each packet read from clients and sends
+// their payload to their targets, batching writes per client.
+func (s *Server) relayBatch(ctx context.Context, messages []ipv4.Message,
+	listener batchConn, nat *natMap) {
+	entryToMessages := make(map[*natEntry][]ipv4.Message)
+	for _, message := range messages {
+		clientAddress, ok := message.Addr.(*net.UDPAddr)
+		if !ok {
+			continue
+		}
+
+		packet := message.Buffers[0][:message.N]
+		plaintext, err := s.cipher.unpack(packet)
+		if err != nil {
+			s.logger.Error("decrypting packet from " + clientAddress.String() + ": " + err.Error())
+			continue
+		}
+
+		targetAddress, domain, targetLength, err := extractTarget(plaintext)
+		if err != nil {
+			s.logger.Error("extracting target address: " + err.Error())
+			continue
+		} else if domain != "" {
+			s.relayToDomain(ctx, clientAddress, domain, targetAddress.Port,
+				plaintext[targetLength:], listener, nat)
+			continue
+		}
+
+		entry, err := s.getOrCreateEntry(ctx, clientAddress, targetAddress, listener, nat)
+		if err != nil {
+			s.logger.Error(err.Error())
+			continue
+		}
+
+		entryToMessages[entry] = append(entryToMessages[entry], ipv4.Message{
+			Buffers: [][]byte{plaintext[targetLength:]},
+			Addr:    targetAddress,
+		})
+	}
+
+	for entry, targetMessages := range entryToMessages {
+		err := writeAll(entry.batchConn, targetMessages)
+		if err != nil {
+			s.logger.Error("writing UDP packets for " + entry.client.String() + ": " + err.Error())
+		}
+	}
+}
+
+// relayToDomain resolves the target domain name in a goroutine, to not
+// block the packets read loop, and then sends the payload to the target.
+// The payload is copied since the read buffer is reused, and is dropped
+// if maxResolutions domain names are already being resolved.
+func (s *Server) relayToDomain(ctx context.Context, client *net.UDPAddr,
+	domain string, port int, payload []byte, listener batchConn, nat *natMap) {
+	select {
+	case s.resolutions <- struct{}{}:
+	default:
+		s.logger.Error("dropping packet to " + domain +
+			": too many domain names being resolved")
+		return
+	}
+
+	payload = append([]byte(nil), payload...)
+	go func() {
+		defer func() { <-s.resolutions }()
+
+		const timeout = 5 * time.Second
+		resolveCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		ips, err := s.resolver.LookupIP(resolveCtx, "ip", domain)
+		if err != nil {
+			s.logger.Error("resolving target address: " + err.Error())
+			return
+		}
+		target := &net.UDPAddr{IP: ips[0], Port: port}
+		for _, ip := range ips { // prefer IPv4 like net.ResolveUDPAddr
+			if ip.To4() != nil {
+				target.IP = ip
+				break
+			}
+		}
+
+		entry, err := s.getOrCreateEntry(ctx, client, target, listener, nat)
+		if err != nil {
+			s.logger.Error(err.Error())
+			return
+		}
+
+		err = writeAll(entry.batchConn, []ipv4.Message{{Buffers: [][]byte{payload}, Addr: target}})
+		if err != nil {
+			s.logger.Error("writing UDP packet for " + client.String() + ": " + err.Error())
+		}
+	}()
+}
+
+// getOrCreateEntry returns the NAT entry of the client for the
+// target given, and starts relaying packets back to the client
+// if the entry is created.
+func (s *Server) getOrCreateEntry(ctx context.Context, client, target *net.UDPAddr,
+	listener batchConn, nat *natMap) (entry *natEntry, err error) {
+	entry, created, err := nat.getOrCreate(client, target, listener)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		if s.logAddresses {
+			s.logger.Info("UDP proxying " + client.String() + " to " + target.String())
+		}
+		go s.relayBack(ctx, entry, nat)
+	}
+	return entry, nil
+}
+
+// relayBack reads packets from the targets of a client,
+// encrypts them and sends them back to the client,
+// until no packet is received for the idle timeout duration.
+func (s *Server) relayBack(ctx context.Context, entry *natEntry, nat *natMap) {
+	defer nat.remove(entry)
+
+	// headroom is reserved at the start of each read buffer
+	// to prepend the SOCKS address of the packet source.
+	const headroom = 1 + net.IPv6len + 2
+
+	readMessages := make([]ipv4.Message, batchSize)
+	readBuffers := make([][]byte, batchSize)
+	for i := range readMessages {
+		readBuffers[i] = s.buffers.get()
+		defer s.buffers.put(readBuffers[i])
+		readMessages[i].Buffers = [][]byte{readBuffers[i][headroom:]}
+	}
+
+	writeMessages := make([]ipv4.Message, 0, batchSize)
+	const idleTimeout = time.Minute
+	for ctx.Err() == nil {
+		err := entry.conn.SetReadDeadline(s.timeNow().Add(idleTimeout))
+		if err != nil {
+			s.logger.Error("setting read deadline: " + err.Error())
+			return
+		}
+
+		n, err := entry.batchConn.ReadBatch(readMessages, 0)
+		if err != nil {
+			return // idle timeout or connection closed
+		}
+
+		writeMessages = writeMessages[:0]
+		for i, message := range readMessages[:n] {
+			sourceAddress, ok := message.Addr.(*net.UDPAddr)
+			if !ok {
+				continue
+			}
+
+			var header [headroom]byte
+			headerLength := putSource(header[:], sourceAddress)
+			start := headroom - headerLength
+			copy(readBuffers[i][start:headroom], header[:headerLength])
+			plaintext := readBuffers[i][start : headroom+message.N]
+
+			packetBuffer := s.buffers.get()
+			packet, err := s.cipher.pack(packetBuffer, plaintext)
+			if err != nil {
+				s.buffers.put(packetBuffer)
+				s.logger.Error("encrypting packet: " + err.Error())
+				continue
+			}
+			writeMessages = append(writeMessages, ipv4.Message{
+				Buffers: [][]byte{packet},
+				Addr:    entry.client,
+			})
+		}
+
+		err = writeAll(entry.listener, writeMessages)
+		for _, message := range writeMessages {
+			s.buffers.put(message.Buffers[0])
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.Error("writing UDP packets to " + entry.client.String() + ": " + err.Error())
+		}
+	}
+}
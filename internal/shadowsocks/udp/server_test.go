@@ -0,0 +1,164 @@
+package udp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/ss-server/pkg/udp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_packetCipher(t *testing.T) {
+	t.Parallel()
+
+	for _, cipherName := range []string{aes128gcm, aes256gcm, chacha20IetfPoly1305} {
+		cipherName := cipherName
+		t.Run(cipherName, func(t *testing.T) {
+			t.Parallel()
+
+			cipher, err := newPacketCipher(cipherName, "password")
+			require.NoError(t, err)
+
+			plaintext := []byte("some plaintext")
+			packet, err := cipher.pack(make([]byte, maxPacketSize), plaintext)
+			require.NoError(t, err)
+
+			// Use a separate cipher to not trigger the replay filter.
+			otherCipher, err := newPacketCipher(cipherName, "password")
+			require.NoError(t, err)
+			decrypted, err := otherCipher.unpack(packet)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, decrypted)
+
+			_, err = otherCipher.unpack(packet)
+			assert.ErrorIs(t, err, ErrSaltRepeated)
+		})
+	}
+}
+
+func Test_extractTarget(t *testing.T) {
+	t.Parallel()
+
+	packet := make([]byte, 1+net.IPv6len+2)
+	n := putSource(packet, &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 53})
+	packet = append(packet[:n], []byte("payload")...)
+
+	address, domain, length, err := extractTarget(packet)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3.4:53", address.String())
+	assert.Empty(t, domain)
+	assert.Equal(t, []byte("payload"), packet[length:])
+
+	packet = append([]byte{addressTypeDomainName, 9}, []byte("localhost")...)
+	packet = append(packet, 0, 53)
+	packet = append(packet, []byte("payload")...)
+	address, domain, length, err = extractTarget(packet)
+	require.NoError(t, err)
+	assert.Equal(t, 53, address.Port)
+	assert.Nil(t, address.IP)
+	assert.Equal(t, "localhost", domain)
+	assert.Equal(t, []byte("payload"), packet[length:])
+
+	_, _, _, err = extractTarget([]byte{addressTypeIPv4, 1})
+	assert.ErrorIs(t, err, ErrSocksAddressTooShort)
+}
+
+func Test_Server(t *testing.T) {
+	t.Parallel()
+
+	echoConn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = echoConn.Close() })
+	go func() {
+		buffer := make([]byte, maxPacketSize)
+		for {
+			n, address, err := echoConn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			_, _ = echoConn.WriteTo(buffer[:n], address)
+		}
+	}()
+
+	serverConn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	serverAddress := serverConn.LocalAddr().String()
+	require.NoError(t, serverConn.Close())
+
+	settings := udp.Settings{
+		Address:  serverAddress,
+		Password: stringPtr("password"),
+	}
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	listening := make(chan struct{})
+	logger.EXPECT().Info("listening UDP on " + serverAddress).
+		Do(func(string) { close(listening) })
+	server, err := NewServer(settings, logger)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	listenErr := make(chan error)
+	go func() {
+		listenErr <- server.Listen(ctx)
+	}()
+	<-listening
+
+	clientCipher, err := newPacketCipher(chacha20IetfPoly1305, "password")
+	require.NoError(t, err)
+	clientConn, err := net.Dial("udp4", serverAddress)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = clientConn.Close() })
+
+	echoAddress := echoConn.LocalAddr().(*net.UDPAddr) //nolint:forcetypeassert
+	plaintext := make([]byte, 1+net.IPv6len+2)
+	n := putSource(plaintext, echoAddress)
+	plaintext = append(plaintext[:n], []byte("hello")...)
+
+	packet, err := clientCipher.pack(make([]byte, maxPacketSize), plaintext)
+	require.NoError(t, err)
+	_, err = clientConn.Write(packet)
+	require.NoError(t, err)
+	err = clientConn.SetReadDeadline(time.Now().Add(time.Second))
+	require.NoError(t, err)
+	response := make([]byte, maxPacketSize)
+	responseLength, err := clientConn.Read(response)
+	require.NoError(t, err)
+
+	decrypted, err := clientCipher.unpack(response[:responseLength])
+	require.NoError(t, err)
+	source, _, length, err := extractTarget(decrypted)
+	require.NoError(t, err)
+	assert.Equal(t, echoAddress.String(), source.String())
+	assert.Equal(t, []byte("hello"), decrypted[length:])
+
+	// The domain name target is resolved away from the read loop
+	// and its packet is sent through the same NAT entry.
+	plaintext = append([]byte{addressTypeDomainName, byte(len("localhost"))}, []byte("localhost")...)
+	plaintext = append(plaintext, byte(echoAddress.Port>>8), byte(echoAddress.Port)) //nolint:gomnd
+	plaintext = append(plaintext, []byte("world")...)
+	packet, err = clientCipher.pack(make([]byte, maxPacketSize), plaintext)
+	require.NoError(t, err)
+	_, err = clientConn.Write(packet)
+	require.NoError(t, err)
+	err = clientConn.SetReadDeadline(time.Now().Add(time.Second))
+	require.NoError(t, err)
+	responseLength, err = clientConn.Read(response)
+	require.NoError(t, err)
+	decrypted, err = clientCipher.unpack(response[:responseLength])
+	require.NoError(t, err)
+	source, _, length, err = extractTarget(decrypted)
+	require.NoError(t, err)
+	assert.Equal(t, echoAddress.String(), source.String())
+	assert.Equal(t, []byte("world"), decrypted[length:])
+
+	cancel()
+	err = <-listenErr
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func stringPtr(s string) *string { return &s }
@@ -0,0 +1,78 @@
+package udp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// SOCKS address types.
+const (
+	addressTypeIPv4       = 1
+	addressTypeDomainName = 3
+	addressTypeIPv6       = 4
+)
+
+var (
+	ErrPacketEmpty                 = errors.New("packet is empty")
+	ErrSocksAddressTypeUnsupported = errors.New("socks address type not supported")
+	ErrSocksAddressTooShort        = errors.New("packet is too short to contain the socks address")
+)
+
+// extractTarget extracts the SOCKS target address at the
+// beginning of the packet and returns its length in bytes.
+// If the target is a domain name, it is returned as domain and
+// the address returned only has its port set, such that the
+// domain name can be resolved away from the packets read loop.
+func extractTarget(packet []byte) (address *net.UDPAddr, domain string,
+	length int, err error) {
+	if len(packet) == 0 {
+		return nil, "", 0, fmt.Errorf("%w", ErrPacketEmpty)
+	}
+
+	switch packet[0] {
+	case addressTypeIPv4:
+		length = 1 + net.IPv4len + 2
+	case addressTypeIPv6:
+		length = 1 + net.IPv6len + 2
+	case addressTypeDomainName:
+		if len(packet) < 2 { //nolint:gomnd
+			return nil, "", 0, fmt.Errorf("%w: domain name length byte is missing",
+				ErrSocksAddressTooShort)
+		}
+		length = 1 + 1 + int(packet[1]) + 2
+	default:
+		return nil, "", 0, fmt.Errorf("%w: %d", ErrSocksAddressTypeUnsupported, packet[0])
+	}
+
+	if len(packet) < length {
+		return nil, "", 0, fmt.Errorf("%w: %d bytes but expected at least %d bytes",
+			ErrSocksAddressTooShort, len(packet), length)
+	}
+
+	address = &net.UDPAddr{Port: int(binary.BigEndian.Uint16(packet[length-2 : length]))}
+	if packet[0] == addressTypeDomainName {
+		domain = string(packet[2 : length-2])
+		return address, domain, length, nil
+	}
+
+	address.IP = make(net.IP, length-3) //nolint:gomnd
+	copy(address.IP, packet[1:length-2])
+	return address, "", length, nil
+}
+
+// putSource writes the SOCKS address of the source
+// UDP address to dst and returns the number of bytes written.
+// dst must be at least 1+16+2 bytes long.
+func putSource(dst []byte, source *net.UDPAddr) (n int) {
+	if ipv4 := source.IP.To4(); ipv4 != nil {
+		dst[0] = addressTypeIPv4
+		n = 1 + copy(dst[1:], ipv4)
+	} else {
+		dst[0] = addressTypeIPv6
+		n = 1 + copy(dst[1:], source.IP.To16())
+	}
+	binary.BigEndian.PutUint16(dst[n:], uint16(source.Port))
+	return n + 2 //nolint:gomnd
+}
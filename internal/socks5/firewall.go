@@ -0,0 +1,46 @@
+package socks5
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// allowPort allows the port of the listening address given
+// through the firewall on each of the loop interfaces, and
+// returns the port allowed.
+func (l *Loop) allowPort(ctx context.Context, listeningAddress string) (
+	port uint16, err error) {
+	_, portString, err := net.SplitHostPort(listeningAddress)
+	if err != nil {
+		return 0, fmt.Errorf("splitting listening address: %w", err)
+	}
+
+	const base, bitSize = 10, 16
+	portUint64, err := strconv.ParseUint(portString, base, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("parsing listening port: %w", err)
+	}
+	port = uint16(portUint64)
+
+	for _, intf := range l.interfaces {
+		err = l.portAllower.SetAllowedPort(ctx, port, intf)
+		if err != nil {
+			return 0, fmt.Errorf("allowing port %d through firewall: %w", port, err)
+		}
+	}
+	return port, nil
+}
+
+func (l *Loop) blockPort(port uint16) {
+	if port == 0 {
+		return
+	}
+	// Use a background context so the port is removed
+	// even if the loop context is canceled.
+	err := l.portAllower.RemoveAllowedPort(context.Background(), port)
+	if err != nil {
+		l.logger.Error("removing port " + fmt.Sprint(port) + " from firewall: " + err.Error())
+	}
+}
@@ -0,0 +1,273 @@
+package socks5
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"syscall"
+)
+
+// See RFC 1928 for the SOCKS5 protocol and RFC 1929
+// for the username and password authentication.
+const (
+	socksVersion = 5
+
+	methodNoAuthentication = 0x00
+	methodUserPassword     = 0x02
+	methodNoAcceptable     = 0xff
+
+	userPasswordVersion = 1
+	userPasswordSuccess = 0x00
+	userPasswordFailure = 0x01
+
+	commandConnect = 0x01
+
+	addressTypeIPv4   = 0x01
+	addressTypeDomain = 0x03
+	addressTypeIPv6   = 0x04
+
+	replySucceeded           = 0x00
+	replyGeneralFailure      = 0x01
+	replyNotAllowed          = 0x02
+	replyNetworkUnreachable  = 0x03
+	replyHostUnreachable     = 0x04
+	replyConnectionRefused   = 0x05
+	replyCommandNotSupported = 0x07
+	replyAddressNotSupported = 0x08
+)
+
+var (
+	ErrVersionNotSupported     = errors.New("SOCKS version not supported")
+	ErrNoAcceptableMethod      = errors.New("no acceptable authentication method")
+	ErrAuthenticationFailed    = errors.New("authentication failed")
+	ErrCommandNotSupported     = errors.New("command not supported")
+	ErrAddressTypeNotSupported = errors.New("address type not supported")
+	ErrPortNotAllowed          = errors.New("destination port not allowed")
+)
+
+// handshake negotiates the authentication method, authenticates the
+// client if needed and connects to the destination requested by the
+// client. It returns the destination connection and address.
+func (s *Server) handshake(ctx context.Context, client io.ReadWriter) (
+	destinationConnection net.Conn, destination string, err error) {
+	err = s.negotiateMethod(client)
+	if err != nil {
+		return nil, "", fmt.Errorf("negotiating method: %w", err)
+	}
+
+	destination, err = readRequest(client)
+	if err != nil {
+		return nil, "", fmt.Errorf("reading request: %w", err)
+	}
+
+	if !s.isPortAllowed(destination) {
+		_ = writeReply(client, replyNotAllowed, nil)
+		return nil, "", fmt.Errorf("%w: %s", ErrPortNotAllowed, destination)
+	}
+
+	destinationConnection, err = s.dialer.DialContext(ctx, "tcp", destination)
+	if err != nil {
+		_ = writeReply(client, dialErrorToReply(err), nil)
+		return nil, "", fmt.Errorf("connecting to %s: %w", destination, err)
+	}
+
+	localAddress, _ := destinationConnection.LocalAddr().(*net.TCPAddr)
+	err = writeReply(client, replySucceeded, localAddress)
+	if err != nil {
+		_ = destinationConnection.Close()
+		return nil, "", fmt.Errorf("writing reply: %w", err)
+	}
+
+	return destinationConnection, destination, nil
+}
+
+func (s *Server) negotiateMethod(client io.ReadWriter) (err error) {
+	header := make([]byte, 2) //nolint:gomnd
+	if _, err = io.ReadFull(client, header); err != nil {
+		return err
+	} else if header[0] != socksVersion {
+		return fmt.Errorf("%w: %d", ErrVersionNotSupported, header[0])
+	}
+
+	methods := make([]byte, header[1])
+	if _, err = io.ReadFull(client, methods); err != nil {
+		return err
+	}
+
+	method := byte(methodNoAuthentication)
+	if s.username != "" {
+		method = methodUserPassword
+	}
+
+	methodOffered := false
+	for _, offered := range methods {
+		if offered == method {
+			methodOffered = true
+			break
+		}
+	}
+	if !methodOffered {
+		_, _ = client.Write([]byte{socksVersion, methodNoAcceptable})
+		return fmt.Errorf("%w: client does not offer method %d", ErrNoAcceptableMethod, method)
+	}
+
+	if _, err = client.Write([]byte{socksVersion, method}); err != nil {
+		return err
+	}
+
+	if method == methodUserPassword {
+		return s.authenticate(client)
+	}
+	return nil
+}
+
+func (s *Server) authenticate(client io.ReadWriter) (err error) {
+	version := make([]byte, 1)
+	if _, err = io.ReadFull(client, version); err != nil {
+		return err
+	} else if version[0] != userPasswordVersion {
+		return fmt.Errorf("%w: authentication version %d",
+			ErrVersionNotSupported, version[0])
+	}
+
+	username, err := readLengthPrefixed(client)
+	if err != nil {
+		return fmt.Errorf("reading username: %w", err)
+	}
+
+	password, err := readLengthPrefixed(client)
+	if err != nil {
+		return fmt.Errorf("reading password: %w", err)
+	}
+
+	usernameMatch := subtle.ConstantTimeCompare([]byte(username), []byte(s.username))
+	passwordMatch := subtle.ConstantTimeCompare([]byte(password), []byte(s.password))
+	if usernameMatch&passwordMatch != 1 {
+		_, _ = client.Write([]byte{userPasswordVersion, userPasswordFailure})
+		return fmt.Errorf("%w: for username %q", ErrAuthenticationFailed, username)
+	}
+
+	_, err = client.Write([]byte{userPasswordVersion, userPasswordSuccess})
+	return err
+}
+
+// readRequest reads the client request and returns the destination
+// address to connect to. It writes an error reply to the client if
+// the request is not supported.
+func readRequest(client io.ReadWriter) (destination string, err error) {
+	header := make([]byte, 4) //nolint:gomnd
+	if _, err = io.ReadFull(client, header); err != nil {
+		return "", err
+	} else if header[0] != socksVersion {
+		return "", fmt.Errorf("%w: %d", ErrVersionNotSupported, header[0])
+	}
+
+	if header[1] != commandConnect {
+		_ = writeReply(client, replyCommandNotSupported, nil)
+		return "", fmt.Errorf("%w: %d", ErrCommandNotSupported, header[1])
+	}
+
+	var host string
+	switch header[3] {
+	case addressTypeIPv4, addressTypeIPv6:
+		ip := make(net.IP, net.IPv4len)
+		if header[3] == addressTypeIPv6 {
+			ip = make(net.IP, net.IPv6len)
+		}
+		if _, err = io.ReadFull(client, ip); err != nil {
+			return "", err
+		}
+		host = ip.String()
+	case addressTypeDomain:
+		host, err = readLengthPrefixed(client)
+		if err != nil {
+			return "", fmt.Errorf("reading domain name: %w", err)
+		}
+	default:
+		_ = writeReply(client, replyAddressNotSupported, nil)
+		return "", fmt.Errorf("%w: %d", ErrAddressTypeNotSupported, header[3])
+	}
+
+	port := make([]byte, 2) //nolint:gomnd
+	if _, err = io.ReadFull(client, port); err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+}
+
+// isPortAllowed returns true if the port of the destination address
+// given is one of the connect ports, so the proxy cannot be used as
+// an open relay to any port.
+func (s *Server) isPortAllowed(destination string) (allowed bool) {
+	_, portString, err := net.SplitHostPort(destination)
+	if err != nil {
+		return false
+	}
+	const base, bitSize = 10, 16
+	port, err := strconv.ParseUint(portString, base, bitSize)
+	if err != nil {
+		return false
+	}
+	for _, allowedPort := range s.connectPorts {
+		if uint16(port) == allowedPort {
+			return true
+		}
+	}
+	return false
+}
+
+func readLengthPrefixed(reader io.Reader) (s string, err error) {
+	length := make([]byte, 1)
+	if _, err = io.ReadFull(reader, length); err != nil {
+		return "", err
+	}
+	b := make([]byte, length[0])
+	if _, err = io.ReadFull(reader, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// writeReply writes a reply with the code and bound address given.
+// A nil bound address is written as the IPv4 address 0.0.0.0:0.
+func writeReply(writer io.Writer, code byte, bound *net.TCPAddr) (err error) {
+	addressType := byte(addressTypeIPv4)
+	ip := net.IPv4zero.To4()
+	port := 0
+	if bound != nil {
+		port = bound.Port
+		if ipv4 := bound.IP.To4(); ipv4 != nil {
+			ip = ipv4
+		} else {
+			addressType = addressTypeIPv6
+			ip = bound.IP.To16()
+		}
+	}
+
+	reply := make([]byte, 0, 4+len(ip)+2) //nolint:gomnd
+	reply = append(reply, socksVersion, code, 0, addressType)
+	reply = append(reply, ip...)
+	reply = binary.BigEndian.AppendUint16(reply, uint16(port))
+	_, err = writer.Write(reply)
+	return err
+}
+
+func dialErrorToReply(err error) (code byte) {
+	var dnsErr *net.DNSError
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return replyConnectionRefused
+	case errors.Is(err, syscall.ENETUNREACH):
+		return replyNetworkUnreachable
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.As(err, &dnsErr):
+		return replyHostUnreachable
+	default:
+		return replyGeneralFailure
+	}
+}
@@ -0,0 +1,130 @@
+package socks5
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Server_handshake(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = connection.Close()
+		}
+	}()
+	destinationPort := uint16(listener.Addr().(*net.TCPAddr).Port)
+	connectRequest := []byte{socksVersion, commandConnect, 0, addressTypeIPv4,
+		127, 0, 0, 1, byte(destinationPort >> 8), byte(destinationPort)}
+
+	testCases := map[string]struct {
+		username    string
+		password    string
+		clientWrite []byte
+		clientRead  []byte
+		errMessage  string
+	}{
+		"no authentication": {
+			clientWrite: append([]byte{socksVersion, 1, methodNoAuthentication},
+				connectRequest...),
+			clientRead: []byte{socksVersion, methodNoAuthentication,
+				socksVersion, replySucceeded, 0, addressTypeIPv4},
+		},
+		"user password": {
+			username: "user",
+			password: "pass",
+			clientWrite: append([]byte{socksVersion, 2, methodNoAuthentication, methodUserPassword,
+				userPasswordVersion, 4, 'u', 's', 'e', 'r', 4, 'p', 'a', 's', 's'},
+				connectRequest...),
+			clientRead: []byte{socksVersion, methodUserPassword,
+				userPasswordVersion, userPasswordSuccess,
+				socksVersion, replySucceeded, 0, addressTypeIPv4},
+		},
+		"wrong password": {
+			username: "user",
+			password: "pass",
+			clientWrite: []byte{socksVersion, 1, methodUserPassword,
+				userPasswordVersion, 4, 'u', 's', 'e', 'r', 4, 'p', 'a', 's', 'x'},
+			clientRead: []byte{socksVersion, methodUserPassword,
+				userPasswordVersion, userPasswordFailure},
+			errMessage: `negotiating method: authentication failed: for username "user"`,
+		},
+		"authentication method not offered": {
+			username:    "user",
+			password:    "pass",
+			clientWrite: []byte{socksVersion, 1, methodNoAuthentication},
+			clientRead:  []byte{socksVersion, methodNoAcceptable},
+			errMessage: "negotiating method: no acceptable authentication method: " +
+				"client does not offer method 2",
+		},
+		"bind command": {
+			clientWrite: []byte{socksVersion, 1, methodNoAuthentication,
+				socksVersion, 0x02, 0, addressTypeIPv4, 127, 0, 0, 1, 0, 80},
+			clientRead: []byte{socksVersion, methodNoAuthentication,
+				socksVersion, replyCommandNotSupported, 0, addressTypeIPv4},
+			errMessage: "reading request: command not supported: 2",
+		},
+		"port not allowed": {
+			clientWrite: []byte{socksVersion, 1, methodNoAuthentication,
+				socksVersion, commandConnect, 0, addressTypeIPv4, 127, 0, 0, 1, 0, 25},
+			clientRead: []byte{socksVersion, methodNoAuthentication,
+				socksVersion, replyNotAllowed, 0, addressTypeIPv4},
+			errMessage: "destination port not allowed: 127.0.0.1:25",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := New("", nil, false, testCase.username, testCase.password,
+				[]uint16{destinationPort})
+			client, serverSide := net.Pipe()
+			t.Cleanup(func() { _ = client.Close() })
+			deadline := time.Now().Add(time.Second)
+			_ = client.SetDeadline(deadline)
+			_ = serverSide.SetDeadline(deadline)
+
+			type handshakeResult struct {
+				connection net.Conn
+				err        error
+			}
+			resultCh := make(chan handshakeResult)
+			go func() {
+				connection, _, err := server.handshake(context.Background(), serverSide)
+				_ = serverSide.Close()
+				resultCh <- handshakeResult{connection: connection, err: err}
+			}()
+
+			go func() {
+				_, _ = client.Write(testCase.clientWrite)
+			}()
+			read, _ := io.ReadAll(client)
+
+			result := <-resultCh
+			if testCase.errMessage != "" {
+				assert.EqualError(t, result.err, testCase.errMessage)
+			} else {
+				require.NoError(t, result.err)
+				_ = result.connection.Close()
+			}
+			// Only compare the reply header since the bound address is random.
+			require.GreaterOrEqual(t, len(read), len(testCase.clientRead))
+			assert.Equal(t, testCase.clientRead, read[:len(testCase.clientRead)])
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package socks5
+
+type Logger interface {
+	Info(s string)
+	Warn(s string)
+	Error(s string)
+}
@@ -0,0 +1,20 @@
+package socks5
+
+import (
+	"io"
+	"net"
+)
+
+// relay copies data between the two connections given until one of
+// them is closed or fails, and then closes both connections.
+func relay(a, b net.Conn) {
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(a, b)
+		_ = a.Close()
+		close(done)
+	}()
+	_, _ = io.Copy(b, a)
+	_ = b.Close()
+	<-done
+}
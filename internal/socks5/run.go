@@ -0,0 +1,86 @@
+package socks5
+
+import (
+	"context"
+
+	"github.com/qdm12/gluetun/internal/constants"
+)
+
+func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	if !*l.state.GetSettings().Enabled {
+		select {
+		case <-l.start:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	for ctx.Err() == nil {
+		runCtx, runCancel := context.WithCancel(ctx)
+
+		settings := l.state.GetSettings()
+		server := New(settings.ListeningAddress, l.logger, *settings.Log,
+			*settings.User, *settings.Password, settings.ConnectPorts)
+
+		port, err := l.allowPort(runCtx, settings.ListeningAddress)
+		if err != nil {
+			l.logger.Error(err.Error())
+		}
+
+		errorCh := make(chan error)
+		go server.Run(runCtx, errorCh)
+
+		if l.userTrigger {
+			l.running <- constants.Running
+			l.userTrigger = false
+		} else {
+			l.backoffTime = defaultBackoffTime
+			l.statusManager.SetStatus(constants.Running)
+		}
+
+		stayHere := true
+		for stayHere {
+			select {
+			case <-ctx.Done():
+				runCancel()
+				<-errorCh
+				close(errorCh)
+				l.blockPort(port)
+				return
+			case <-l.start:
+				l.userTrigger = true
+				l.logger.Info("starting")
+				runCancel()
+				<-errorCh
+				close(errorCh)
+				l.blockPort(port)
+				stayHere = false
+			case <-l.stop:
+				l.userTrigger = true
+				l.logger.Info("stopping")
+				runCancel()
+				<-errorCh
+				close(errorCh)
+				l.blockPort(port)
+				l.stopped <- struct{}{}
+				// Wait to be started again to re-create the server.
+				select {
+				case <-l.start:
+					l.logger.Info("starting")
+				case <-ctx.Done():
+					return
+				}
+				stayHere = false
+			case err := <-errorCh:
+				close(errorCh)
+				l.blockPort(port)
+				l.statusManager.SetStatus(constants.Crashed)
+				l.logAndWait(ctx, err)
+				stayHere = false
+			}
+		}
+		runCancel() // repetition for linter only
+	}
+}
@@ -0,0 +1,112 @@
+package socks5
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+type Server struct {
+	address  string
+	logger   Logger
+	verbose  bool
+	username string
+	password string
+	// connectPorts are the destination ports clients
+	// are allowed to connect to.
+	connectPorts []uint16
+	dialer       *net.Dialer
+}
+
+func New(address string, logger Logger, verbose bool,
+	username, password string, connectPorts []uint16) *Server {
+	const dialTimeout = 10 * time.Second
+	return &Server{
+		address:      address,
+		logger:       logger,
+		verbose:      verbose,
+		username:     username,
+		password:     password,
+		connectPorts: connectPorts,
+		dialer:       &net.Dialer{Timeout: dialTimeout},
+	}
+}
+
+// Run listens on the server address and serves SOCKS5 connections
+// until the context is canceled. It sends a nil error or the error
+// encountered to the error channel once all connections are closed.
+func (s *Server) Run(ctx context.Context, errorCh chan<- error) {
+	listenConfig := &net.ListenConfig{}
+	listener, err := listenConfig.Listen(ctx, "tcp", s.address)
+	if err != nil {
+		errorCh <- err
+		return
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := listener.Close(); err != nil {
+			s.logger.Error("failed closing listener: " + err.Error())
+		}
+	}()
+
+	s.logger.Info("listening on " + s.address)
+
+	wg := &sync.WaitGroup{}
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			wg.Wait()
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				errorCh <- nil
+			} else {
+				errorCh <- err
+			}
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serve(ctx, connection)
+		}()
+	}
+}
+
+func (s *Server) serve(ctx context.Context, clientConnection net.Conn) {
+	defer clientConnection.Close()
+
+	// Close the client connection once the context is canceled,
+	// which unblocks any read or write done on it.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		_ = clientConnection.Close()
+	}()
+
+	const handshakeTimeout = 10 * time.Second
+	_ = clientConnection.SetDeadline(time.Now().Add(handshakeTimeout))
+
+	clientAddress := clientConnection.RemoteAddr().String()
+	destinationConnection, destination, err := s.handshake(ctx, clientConnection)
+	if err != nil {
+		s.logger.Info("handshake with " + clientAddress + " failed: " + err.Error())
+		return
+	}
+	defer destinationConnection.Close()
+
+	_ = clientConnection.SetDeadline(time.Time{})
+
+	if s.verbose {
+		s.logger.Info(clientAddress + " -> " + destination)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = destinationConnection.Close()
+	}()
+	relay(clientConnection, destinationConnection)
+}
@@ -0,0 +1,16 @@
+package socks5
+
+import (
+	"context"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+func (l *Loop) GetSettings() (settings settings.SOCKS5) {
+	return l.state.GetSettings()
+}
+
+func (l *Loop) SetSettings(ctx context.Context, settings settings.SOCKS5) (
+	outcome string) {
+	return l.state.SetSettings(ctx, settings)
+}
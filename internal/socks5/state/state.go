@@ -0,0 +1,28 @@
+package state
+
+import (
+	"context"
+	"sync"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+func New(statusApplier StatusApplier,
+	settings settings.SOCKS5) *State {
+	return &State{
+		statusApplier: statusApplier,
+		settings:      settings,
+	}
+}
+
+type State struct {
+	statusApplier StatusApplier
+	settings      settings.SOCKS5
+	settingsMu    sync.RWMutex
+}
+
+type StatusApplier interface {
+	ApplyStatus(ctx context.Context, status models.LoopStatus) (
+		outcome string, err error)
+}
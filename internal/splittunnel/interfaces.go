@@ -0,0 +1,19 @@
+package splittunnel
+
+import (
+	"context"
+	"net"
+)
+
+type Firewall interface {
+	SetBypassIPs(ctx context.Context, ips []net.IP) (err error)
+}
+
+type Routing interface {
+	SetBypassIPs(ips []net.IP) (err error)
+}
+
+type Logger interface {
+	Info(s string)
+	Warn(s string)
+}
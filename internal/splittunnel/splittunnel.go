@@ -0,0 +1,179 @@
+// Package splittunnel sends the traffic to the IP addresses of a set
+// of domain names outside the VPN tunnel, through the default routes.
+// The IP addresses are recorded from the DNS answers to the queries
+// for these domains, to keep the firewall rules and the routes in line
+// with the addresses clients actually connect to.
+package splittunnel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// Bypass records the IP addresses answered for the bypass domains and
+// sets them as destinations going out of the VPN tunnel in the firewall
+// and the routing.
+type Bypass struct {
+	domains     []string
+	retryPeriod time.Duration
+	firewall    Firewall
+	routing     Routing
+	logger      Logger
+	// domainIPs maps each domain name queried to the IP addresses
+	// last answered for it, and changed is signaled when it changes.
+	domainIPs      map[string][]net.IP
+	domainIPsMutex sync.Mutex
+	changed        chan struct{}
+	// lastIPs are the IP addresses last set in the firewall
+	// and the routing.
+	lastIPs []net.IP
+}
+
+// New creates a split tunnel bypass using the firewall settings given.
+func New(settings settings.Firewall, firewall Firewall,
+	routing Routing, logger Logger) *Bypass {
+	const retryPeriod = 10 * time.Second
+	domains := make([]string, len(settings.BypassDomains))
+	for i, domain := range settings.BypassDomains {
+		domains[i] = normalizeName(domain)
+	}
+	return &Bypass{
+		domains:     domains,
+		retryPeriod: retryPeriod,
+		firewall:    firewall,
+		routing:     routing,
+		logger:      logger,
+		domainIPs:   make(map[string][]net.IP, len(domains)),
+		changed:     make(chan struct{}, 1),
+	}
+}
+
+// RecordAnswer records the IP addresses answered for the
+// name given, if it is a bypass domain or a subdomain of one.
+func (b *Bypass) RecordAnswer(name string, ips []net.IP) {
+	name = normalizeName(name)
+	if !b.isBypassed(name) {
+		return
+	}
+
+	b.domainIPsMutex.Lock()
+	b.domainIPs[name] = ips
+	b.domainIPsMutex.Unlock()
+
+	select {
+	case b.changed <- struct{}{}:
+	default: // a change is already pending
+	}
+}
+
+// Run updates the firewall and the routing every time IP addresses
+// are recorded, until the context is canceled. Updates failing are
+// retried every retry period. It returns immediately if no domain is set.
+func (b *Bypass) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+	if len(b.domains) == 0 {
+		return
+	}
+
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.changed:
+		case <-retry:
+		}
+
+		retry = nil
+		if !b.update(ctx) && ctx.Err() == nil {
+			retry = time.After(b.retryPeriod)
+		}
+	}
+}
+
+// update updates the firewall and the routing if the IP addresses
+// recorded changed. It returns false if the firewall or the routing
+// failed to be updated.
+func (b *Bypass) update(ctx context.Context) (ok bool) {
+	ips := b.uniqueIPs()
+	if ipsEqual(ips, b.lastIPs) {
+		return true
+	}
+
+	// The firewall is updated first so routed traffic is not dropped.
+	err := b.firewall.SetBypassIPs(ctx, ips)
+	if err != nil {
+		b.logger.Warn("setting bypass IP addresses in firewall: " + err.Error())
+		return false
+	}
+
+	err = b.routing.SetBypassIPs(ips)
+	if err != nil {
+		b.logger.Warn("setting bypass IP addresses in routing: " + err.Error())
+		return false
+	}
+
+	b.lastIPs = ips
+	b.logger.Info(fmt.Sprintf("bypassing VPN for %d IP addresses of %s",
+		len(ips), strings.Join(b.domains, ", ")))
+	return true
+}
+
+// isBypassed returns true if the normalized name given
+// is one of the domains or a subdomain of one of them.
+func (b *Bypass) isBypassed(name string) bool {
+	for _, domain := range b.domains {
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueIPs returns the sorted and deduplicated
+// IP addresses of all the domains.
+func (b *Bypass) uniqueIPs() (ips []net.IP) {
+	b.domainIPsMutex.Lock()
+	defer b.domainIPsMutex.Unlock()
+	seen := make(map[string]struct{})
+	for _, domainIPs := range b.domainIPs {
+		for _, ip := range domainIPs {
+			key := ip.String()
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			ips = append(ips, ip)
+		}
+	}
+	sort.Slice(ips, func(i, j int) bool {
+		return bytes.Compare(ips[i].To16(), ips[j].To16()) < 0
+	})
+	return ips
+}
+
+// normalizeName returns the name given in lower case
+// and without its trailing dot.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+func ipsEqual(a, b []net.IP) (equal bool) {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package splittunnel
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeFirewall struct {
+	calls [][]net.IP
+	err   error
+}
+
+func (f *fakeFirewall) SetBypassIPs(_ context.Context, ips []net.IP) error {
+	f.calls = append(f.calls, ips)
+	return f.err
+}
+
+type fakeRouting struct {
+	calls [][]net.IP
+	err   error
+}
+
+func (f *fakeRouting) SetBypassIPs(ips []net.IP) error {
+	f.calls = append(f.calls, ips)
+	return f.err
+}
+
+func Test_Bypass_RecordAnswer(t *testing.T) {
+	t.Parallel()
+
+	settings := settings.Firewall{BypassDomains: []string{"Bank.example.com."}}
+	bypass := New(settings, nil, nil, nil)
+
+	bypass.RecordAnswer("example.com.", []net.IP{net.IPv4(9, 9, 9, 9)})
+	bypass.RecordAnswer("notbank.example.com.", []net.IP{net.IPv4(9, 9, 9, 9)})
+	assert.Empty(t, bypass.changed)
+	assert.Empty(t, bypass.uniqueIPs())
+
+	bypass.RecordAnswer("bank.example.com.", []net.IP{net.IPv4(2, 2, 2, 2)})
+	bypass.RecordAnswer("WWW.bank.example.com.", []net.IP{net.IPv4(1, 1, 1, 1), net.IPv4(2, 2, 2, 2)})
+	assert.Len(t, bypass.changed, 1)
+	assert.Equal(t, []net.IP{net.IPv4(1, 1, 1, 1), net.IPv4(2, 2, 2, 2)}, bypass.uniqueIPs())
+
+	// A new answer replaces the IP addresses previously answered.
+	bypass.RecordAnswer("www.bank.example.com", []net.IP{net.IPv4(3, 3, 3, 3)})
+	assert.Equal(t, []net.IP{net.IPv4(2, 2, 2, 2), net.IPv4(3, 3, 3, 3)}, bypass.uniqueIPs())
+}
+
+func Test_Bypass_update(t *testing.T) {
+	t.Parallel()
+
+	firewall := &fakeFirewall{}
+	routing := &fakeRouting{}
+	ctrl := gomock.NewController(t)
+	logger := NewMockLogger(ctrl)
+	gomock.InOrder(
+		logger.EXPECT().Info("bypassing VPN for 2 IP addresses of bank.example.com"),
+		logger.EXPECT().Warn("setting bypass IP addresses in routing: test error"),
+		logger.EXPECT().Info("bypassing VPN for 1 IP addresses of bank.example.com"),
+		logger.EXPECT().Warn("setting bypass IP addresses in firewall: test error"),
+	)
+	settings := settings.Firewall{BypassDomains: []string{"bank.example.com"}}
+	bypass := New(settings, firewall, routing, logger)
+	ctx := context.Background()
+
+	// Nothing is recorded yet, the firewall and routing are left as is.
+	ok := bypass.update(ctx)
+	assert.True(t, ok)
+	assert.Empty(t, firewall.calls)
+	assert.Empty(t, routing.calls)
+
+	bypass.RecordAnswer("bank.example.com.", []net.IP{net.IPv4(2, 2, 2, 2), net.IPv4(1, 1, 1, 1)})
+	ok = bypass.update(ctx)
+	assert.True(t, ok)
+	expected := [][]net.IP{{net.IPv4(1, 1, 1, 1), net.IPv4(2, 2, 2, 2)}}
+	assert.Equal(t, expected, firewall.calls)
+	assert.Equal(t, expected, routing.calls)
+
+	// Unchanged IP addresses do not update anything.
+	ok = bypass.update(ctx)
+	assert.True(t, ok)
+	assert.Len(t, firewall.calls, 1)
+	assert.Len(t, routing.calls, 1)
+
+	// Routing failures are retried on the next update.
+	bypass.RecordAnswer("bank.example.com.", []net.IP{net.IPv4(3, 3, 3, 3)})
+	routing.err = errors.New("test error")
+	ok = bypass.update(ctx)
+	assert.False(t, ok)
+	routing.err = nil
+	ok = bypass.update(ctx)
+	assert.True(t, ok)
+	assert.Len(t, firewall.calls, 3)
+	assert.Len(t, routing.calls, 3)
+	assert.Equal(t, []net.IP{net.IPv4(3, 3, 3, 3)}, routing.calls[2])
+
+	// Firewall failures do not update the routing.
+	bypass.RecordAnswer("bank.example.com.", []net.IP{net.IPv4(4, 4, 4, 4)})
+	firewall.err = errors.New("test error")
+	ok = bypass.update(ctx)
+	assert.False(t, ok)
+	assert.Len(t, routing.calls, 3)
+}
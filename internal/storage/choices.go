@@ -7,10 +7,10 @@ import (
 )
 
 func (s *Storage) GetFilterChoices(provider string) models.FilterChoices {
-	if provider == providers.Custom {
+	switch provider {
+	case providers.CloudflareWARP, providers.Custom, providers.Plugin:
 		return models.FilterChoices{}
 	}
-
 	s.mergedMutex.RLock()
 	defer s.mergedMutex.RUnlock()
 
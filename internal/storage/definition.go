@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+// LoadProviderDefinition reads the provider definition JSON file at
+// the path given and sets its servers as the servers of the user
+// defined provider. If the file does not exist, the user defined
+// provider has no server and an empty definition is returned.
+func (s *Storage) LoadProviderDefinition(path string) (
+	definition models.ProviderDefinition, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return definition, fmt.Errorf("reading provider definition file: %w", err)
+	}
+
+	if err == nil {
+		err = json.Unmarshal(data, &definition)
+		if err != nil {
+			return definition, fmt.Errorf("decoding provider definition: %w", err)
+		}
+
+		err = definition.Validate()
+		if err != nil {
+			return definition, fmt.Errorf("validating provider definition: %w", err)
+		}
+
+		s.logger.Info(fmt.Sprintf("loaded %d servers for provider %s from %s",
+			len(definition.Servers), definition.Name, path))
+	}
+
+	s.mergedMutex.Lock()
+	defer s.mergedMutex.Unlock()
+	s.mergedServers.ProviderToServers[providers.UserDefined] = models.Servers{
+		Servers: definition.Servers,
+	}
+	return definition, nil
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Storage_LoadProviderDefinition(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		content    string
+		logged     string
+		definition models.ProviderDefinition
+		errMessage string
+	}{
+		"file does not exist": {},
+		"bad JSON": {
+			content: "garbage",
+			errMessage: "decoding provider definition: " +
+				"invalid character 'g' looking for beginning of value",
+		},
+		"missing Wireguard port": {
+			content: `{"name": "My VPN", "servers": [{"vpn": "wireguard",
+"hostname": "a.example.com", "ips": ["1.2.3.4"], "wgpubkey": "key"}]}`,
+			errMessage: "validating provider definition: server 1 of 1: " +
+				"Wireguard port is not set",
+		},
+		"valid definition": {
+			content: `{"name": "My VPN",
+"openvpn": {"udp_port": 1194, "template": ["client", "nobind"]},
+"servers": [{"vpn": "openvpn", "country": "Canada",
+"hostname": "a.example.com", "udp": true, "ips": ["1.2.3.4"]}]}`,
+			logged: "loaded 1 servers for provider My VPN from ",
+			definition: models.ProviderDefinition{
+				Name: "My VPN",
+				OpenVPN: models.ProviderDefinitionOpenVPN{
+					UDPPort:  1194,
+					Template: []string{"client", "nobind"},
+				},
+				Servers: []models.Server{{
+					VPN:      vpn.OpenVPN,
+					Country:  "Canada",
+					Hostname: "a.example.com",
+					UDP:      true,
+					IPs:      []net.IP{net.IPv4(1, 2, 3, 4)},
+				}},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			path := filepath.Join(t.TempDir(), "provider.json")
+			if testCase.content != "" {
+				const permission = 0600
+				err := os.WriteFile(path, []byte(testCase.content), permission)
+				require.NoError(t, err)
+			}
+
+			logger := NewMockInfoer(ctrl)
+			if testCase.logged != "" {
+				logger.EXPECT().Info(testCase.logged + path)
+			}
+			storage := &Storage{
+				mergedServers: models.AllServers{
+					ProviderToServers: map[string]models.Servers{},
+				},
+				logger: logger,
+			}
+
+			definition, err := storage.LoadProviderDefinition(path)
+
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.definition, definition)
+			servers := storage.mergedServers.ProviderToServers[providers.UserDefined]
+			assert.Equal(t, testCase.definition.Servers, servers.Servers)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"sort"
 
+	"github.com/qdm12/gluetun/internal/constants/providers"
 	"github.com/qdm12/gluetun/internal/models"
 )
 
@@ -34,15 +35,29 @@ func (s *Storage) flushToFile(path string) error {
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
 
-	for _, obj := range s.mergedServers.ProviderToServers {
+	allServers := models.AllServers{
+		Version:           s.mergedServers.Version,
+		ProviderToServers: make(map[string]models.Servers, len(s.mergedServers.ProviderToServers)),
+	}
+	for provider, obj := range s.mergedServers.ProviderToServers {
+		if provider == providers.UserDefined {
+			// servers are read from the provider definition file
+			continue
+		}
 		sort.Sort(models.SortableServers(obj.Servers))
+		allServers.ProviderToServers[provider] = obj
 	}
 
-	err = encoder.Encode(&s.mergedServers)
+	err = encoder.Encode(&allServers)
 	if err != nil {
 		_ = file.Close()
 		return err
 	}
 
-	return file.Close()
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	return s.filesOwner.Chown(path)
 }
@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/ownership"
 )
 
 type Storage struct {
@@ -15,6 +16,17 @@ type Storage struct {
 	hardcodedServers models.AllServers
 	logger           Infoer
 	filepath         string
+	// filesOwner is the owner of the servers file once written,
+	// and is nil to leave its ownership unchanged.
+	filesOwner *ownership.Owner
+}
+
+// SetFilesOwner sets the owner of the servers file each time it is
+// written. A nil owner leaves the ownership of the file unchanged.
+func (s *Storage) SetFilesOwner(owner *ownership.Owner) {
+	s.mergedMutex.Lock()
+	defer s.mergedMutex.Unlock()
+	s.filesOwner = owner
 }
 
 type Infoer interface {
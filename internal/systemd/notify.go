@@ -0,0 +1,77 @@
+// Package systemd implements the systemd service notification
+// protocol, to signal readiness and status changes when gluetun
+// runs as a systemd service with Type=notify.
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+)
+
+// Notifier sends notifications to the systemd service manager.
+type Notifier struct {
+	socketPath string
+}
+
+// New creates a notifier using the socket path from the
+// NOTIFY_SOCKET environment variable. If it is not set,
+// gluetun is not running as a systemd notify service and
+// notifications are not sent.
+func New() *Notifier {
+	return &Notifier{
+		socketPath: os.Getenv("NOTIFY_SOCKET"),
+	}
+}
+
+// Notify sends the state given, such as READY=1 or STOPPING=1,
+// to the service manager. It returns false without error if the
+// notification socket is not set.
+func (n *Notifier) Notify(state string) (sent bool, err error) {
+	if n.socketPath == "" {
+		return false, nil
+	}
+
+	socketPath := n.socketPath
+	if socketPath[0] == '@' { // abstract namespace socket
+		socketPath = "\x00" + socketPath[1:]
+	}
+
+	address := &net.UnixAddr{Name: socketPath, Net: "unixgram"}
+	connection, err := net.DialUnix(address.Net, nil, address)
+	if err != nil {
+		return false, fmt.Errorf("dialing notify socket: %w", err)
+	}
+
+	_, err = connection.Write([]byte(state))
+	if err != nil {
+		_ = connection.Close()
+		return false, fmt.Errorf("writing to notify socket: %w", err)
+	}
+
+	err = connection.Close()
+	if err != nil {
+		return false, fmt.Errorf("closing notify socket: %w", err)
+	}
+
+	return true, nil
+}
+
+// Ready notifies the service manager the service is ready,
+// with the status message given.
+func (n *Notifier) Ready(status string) (err error) {
+	_, err = n.Notify("READY=1\nSTATUS=" + status)
+	return err
+}
+
+// Status notifies the service manager of the status message given.
+func (n *Notifier) Status(status string) (err error) {
+	_, err = n.Notify("STATUS=" + status)
+	return err
+}
+
+// Stopping notifies the service manager the service is stopping.
+func (n *Notifier) Stopping() (err error) {
+	_, err = n.Notify("STOPPING=1")
+	return err
+}
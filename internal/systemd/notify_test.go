@@ -0,0 +1,43 @@
+package systemd
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Notifier(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no socket", func(t *testing.T) {
+		t.Parallel()
+		notifier := &Notifier{}
+		sent, err := notifier.Notify("READY=1")
+		require.NoError(t, err)
+		assert.False(t, sent)
+	})
+
+	t.Run("send to socket", func(t *testing.T) {
+		t.Parallel()
+		socketPath := filepath.Join(t.TempDir(), "notify.sock")
+		listener, err := net.ListenUnixgram("unixgram",
+			&net.UnixAddr{Name: socketPath, Net: "unixgram"})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			err := listener.Close()
+			assert.NoError(t, err)
+		})
+
+		notifier := &Notifier{socketPath: socketPath}
+		err = notifier.Ready("connected")
+		require.NoError(t, err)
+
+		buffer := make([]byte, 64)
+		n, err := listener.Read(buffer)
+		require.NoError(t, err)
+		assert.Equal(t, "READY=1\nSTATUS=connected", string(buffer[:n]))
+	})
+}
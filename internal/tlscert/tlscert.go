@@ -0,0 +1,119 @@
+// Package tlscert loads TLS certificates and generates
+// self-signed TLS certificates for the servers of gluetun.
+package tlscert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LoadOrGenerate returns the certificate key pair from the certificate
+// and key files if they are set. Otherwise it returns the self-signed
+// certificate stored in the directory given, generating it for the
+// hostnames and IP addresses given if it does not exist yet.
+// The generated return value is true if a certificate was generated.
+func LoadOrGenerate(certificateFile, keyFile, dir string, hostnames []string) (
+	certificate tls.Certificate, generated bool, err error) {
+	if certificateFile != "" {
+		certificate, err = tls.LoadX509KeyPair(certificateFile, keyFile)
+		if err != nil {
+			return certificate, false, fmt.Errorf("loading key pair: %w", err)
+		}
+		return certificate, false, nil
+	}
+
+	certificateFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	certificate, err = tls.LoadX509KeyPair(certificateFile, keyFile)
+	if err == nil {
+		return certificate, false, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return certificate, false, fmt.Errorf("loading self-signed key pair: %w", err)
+	}
+
+	certificate, err = generateSelfSigned(certificateFile, keyFile, hostnames)
+	if err != nil {
+		return certificate, false, fmt.Errorf("generating self-signed certificate: %w", err)
+	}
+	return certificate, true, nil
+}
+
+// generateSelfSigned generates a self-signed ECDSA certificate valid for
+// the hostnames and IP addresses given, writes it and its key as PEM files
+// at the paths given and returns it.
+func generateSelfSigned(certificateFile, keyFile string,
+	hostnames []string) (certificate tls.Certificate, err error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return certificate, fmt.Errorf("generating private key: %w", err)
+	}
+
+	const serialNumberBits = 128
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), serialNumberBits))
+	if err != nil {
+		return certificate, fmt.Errorf("generating serial number: %w", err)
+	}
+
+	const validity = 10 * 365 * 24 * time.Hour
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{Organization: []string{"Gluetun"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, hostname := range hostnames {
+		if ip := net.ParseIP(hostname); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, hostname)
+		}
+	}
+	if len(template.DNSNames) > 0 {
+		template.Subject.CommonName = template.DNSNames[0]
+	}
+
+	certificateDER, err := x509.CreateCertificate(rand.Reader,
+		template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return certificate, fmt.Errorf("creating certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return certificate, fmt.Errorf("encoding private key: %w", err)
+	}
+
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	const dirPerms, certificatePerms, keyPerms = 0700, 0644, 0600
+	err = os.MkdirAll(filepath.Dir(certificateFile), dirPerms)
+	if err != nil {
+		return certificate, fmt.Errorf("creating directory: %w", err)
+	}
+	err = os.WriteFile(certificateFile, certificatePEM, certificatePerms)
+	if err != nil {
+		return certificate, fmt.Errorf("writing certificate file: %w", err)
+	}
+	err = os.WriteFile(keyFile, keyPEM, keyPerms)
+	if err != nil {
+		return certificate, fmt.Errorf("writing key file: %w", err)
+	}
+
+	return tls.X509KeyPair(certificatePEM, keyPEM)
+}
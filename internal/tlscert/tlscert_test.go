@@ -0,0 +1,42 @@
+package tlscert
+
+import (
+	"crypto/x509"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LoadOrGenerate(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "certs")
+	hostnames := []string{"gluetun", "192.168.1.2"}
+
+	certificate, generated, err := LoadOrGenerate("", "", dir, hostnames)
+	require.NoError(t, err)
+	assert.True(t, generated)
+	require.Len(t, certificate.Certificate, 1)
+
+	parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gluetun"}, parsed.DNSNames)
+	require.Len(t, parsed.IPAddresses, 1)
+	assert.True(t, parsed.IPAddresses[0].Equal(net.IPv4(192, 168, 1, 2)))
+
+	// The certificate generated is re-used.
+	loaded, generated, err := LoadOrGenerate("", "", dir, hostnames)
+	require.NoError(t, err)
+	assert.False(t, generated)
+	assert.Equal(t, certificate.Certificate, loaded.Certificate)
+
+	// The certificate files given are used.
+	loaded, generated, err = LoadOrGenerate(filepath.Join(dir, "cert.pem"),
+		filepath.Join(dir, "key.pem"), "", nil)
+	require.NoError(t, err)
+	assert.False(t, generated)
+	assert.Equal(t, certificate.Certificate, loaded.Certificate)
+}
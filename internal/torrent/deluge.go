@@ -0,0 +1,150 @@
+package torrent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrJSONRPCError = errors.New("JSON-RPC error")
+	ErrNoDaemonHost = errors.New("no daemon host configured")
+)
+
+// Deluge is a client for the Deluge Web JSON-RPC API.
+type Deluge struct {
+	client   *http.Client
+	url      string
+	password string
+}
+
+// NewDeluge creates a Deluge Web API client for the base
+// URL given, such as http://localhost:8112. Deluge Web
+// only uses a password to authenticate.
+func NewDeluge(client *http.Client, url, password string) *Deluge {
+	return &Deluge{
+		client:   client,
+		url:      strings.TrimSuffix(url, "/"),
+		password: password,
+	}
+}
+
+// SetListenPort logs in Deluge Web, connects it to its first daemon
+// if it is not connected, and sets the daemon listening port range
+// to only the port given.
+func (d *Deluge) SetListenPort(ctx context.Context, port uint16) (err error) {
+	var loggedIn bool
+	cookies, err := d.call(ctx, "auth.login", []interface{}{d.password}, nil, &loggedIn)
+	if err != nil {
+		return fmt.Errorf("logging in: %w", err)
+	} else if !loggedIn {
+		return fmt.Errorf("logging in: %w", ErrLoginFailed)
+	}
+
+	var connected bool
+	_, err = d.call(ctx, "web.connected", []interface{}{}, cookies, &connected)
+	if err != nil {
+		return fmt.Errorf("checking daemon connection: %w", err)
+	}
+
+	if !connected {
+		err = d.connect(ctx, cookies)
+		if err != nil {
+			return fmt.Errorf("connecting to daemon: %w", err)
+		}
+	}
+
+	config := map[string]interface{}{
+		"listen_ports": []uint16{port, port},
+		"random_port":  false,
+	}
+	_, err = d.call(ctx, "core.set_config", []interface{}{config}, cookies, nil)
+	if err != nil {
+		return fmt.Errorf("setting config: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Deluge) connect(ctx context.Context, cookies []*http.Cookie) (err error) {
+	// Each host is an array of host id, address, port and status.
+	var hosts [][]interface{}
+	_, err = d.call(ctx, "web.get_hosts", []interface{}{}, cookies, &hosts)
+	if err != nil {
+		return fmt.Errorf("getting hosts: %w", err)
+	} else if len(hosts) == 0 || len(hosts[0]) == 0 {
+		return fmt.Errorf("%w", ErrNoDaemonHost)
+	}
+
+	hostID := hosts[0][0]
+	_, err = d.call(ctx, "web.connect", []interface{}{hostID}, cookies, nil)
+	if err != nil {
+		return fmt.Errorf("connecting to host %v: %w", hostID, err)
+	}
+
+	return nil
+}
+
+// call calls the JSON-RPC method given with the parameters given, and
+// decodes the result into the result given if it is not nil. It returns
+// the cookies set by the response.
+func (d *Deluge) call(ctx context.Context, method string, params []interface{},
+	cookies []*http.Cookie, result interface{}) (responseCookies []*http.Cookie, err error) {
+	requestBody, err := json.Marshal(struct {
+		Method string        `json:"method"`
+		Params []interface{} `json:"params"`
+		ID     int           `json:"id"`
+	}{Method: method, Params: params, ID: 1})
+	if err != nil {
+		return nil, fmt.Errorf("encoding request body: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		d.url+"/json", bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for _, cookie := range cookies {
+		request.AddCookie(cookie)
+	}
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %d %s", ErrHTTPStatusCodeNotOK,
+			response.StatusCode, response.Status)
+	}
+
+	var data struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&data)
+	if err != nil {
+		return nil, fmt.Errorf("decoding response body: %w", err)
+	}
+
+	if data.Error != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrJSONRPCError, method, data.Error.Message)
+	}
+
+	if result != nil {
+		err = json.Unmarshal(data.Result, result)
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s result: %w", method, err)
+		}
+	}
+
+	return response.Cookies(), response.Body.Close()
+}
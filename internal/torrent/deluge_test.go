@@ -0,0 +1,106 @@
+package torrent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Deluge_SetListenPort(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		password   string
+		connected  bool
+		methods    []string
+		errWrap    error
+		errMessage string
+	}{
+		"wrong password": {
+			password:   "wrong",
+			methods:    []string{"auth.login"},
+			errWrap:    ErrLoginFailed,
+			errMessage: "logging in: login failed",
+		},
+		"connected": {
+			password:  "deluge",
+			connected: true,
+			methods:   []string{"auth.login", "web.connected", "core.set_config"},
+		},
+		"not connected": {
+			password: "deluge",
+			methods: []string{"auth.login", "web.connected",
+				"web.get_hosts", "web.connect", "core.set_config"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var methods []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/json", r.URL.Path)
+				var body struct {
+					Method string            `json:"method"`
+					Params []json.RawMessage `json:"params"`
+				}
+				err := json.NewDecoder(r.Body).Decode(&body)
+				assert.NoError(t, err)
+				methods = append(methods, body.Method)
+
+				if body.Method != "auth.login" {
+					cookie, err := r.Cookie("_session_id")
+					if assert.NoError(t, err) {
+						assert.Equal(t, "session", cookie.Value)
+					}
+				}
+
+				var result string
+				switch body.Method {
+				case "auth.login":
+					result = "false"
+					if string(body.Params[0]) == `"deluge"` {
+						http.SetCookie(w, &http.Cookie{Name: "_session_id", Value: "session"})
+						result = "true"
+					}
+				case "web.connected":
+					result = "false"
+					if testCase.connected {
+						result = "true"
+					}
+				case "web.get_hosts":
+					result = `[["abc123","127.0.0.1",58846,"Online"]]`
+				case "web.connect":
+					assert.Equal(t, `"abc123"`, string(body.Params[0]))
+					result = "null"
+				case "core.set_config":
+					assert.JSONEq(t, `{"listen_ports":[12345,12345],"random_port":false}`,
+						string(body.Params[0]))
+					result = "null"
+				}
+				_, _ = w.Write([]byte(`{"result":` + result + `,"error":null,"id":1}`))
+			}))
+			defer server.Close()
+
+			client := NewDeluge(server.Client(), server.URL, testCase.password)
+
+			err := client.SetListenPort(context.Background(), 12345)
+
+			if testCase.errWrap != nil {
+				assert.ErrorIs(t, err, testCase.errWrap)
+			}
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.methods, methods)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Package torrent implements clients for the web APIs of
+// torrent clients, to set their listening port.
+package torrent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrHTTPStatusCodeNotOK = errors.New("HTTP status code not OK")
+	ErrLoginFailed         = errors.New("login failed")
+)
+
+// QBittorrent is a client for the qBittorrent WebUI API.
+type QBittorrent struct {
+	client   *http.Client
+	url      string
+	username string
+	password string
+}
+
+// NewQBittorrent creates a qBittorrent WebUI API client for the
+// base URL given, such as http://localhost:8080. The username and
+// password can be left empty if authentication is disabled for
+// the client address in qBittorrent.
+func NewQBittorrent(client *http.Client, url, username, password string) *QBittorrent {
+	return &QBittorrent{
+		client:   client,
+		url:      strings.TrimSuffix(url, "/"),
+		username: username,
+		password: password,
+	}
+}
+
+// SetListenPort logs in qBittorrent and sets its listening port
+// to the port given.
+func (q *QBittorrent) SetListenPort(ctx context.Context, port uint16) (err error) {
+	cookies, err := q.login(ctx)
+	if err != nil {
+		return fmt.Errorf("logging in: %w", err)
+	}
+
+	preferences, err := json.Marshal(struct {
+		ListenPort uint16 `json:"listen_port"`
+	}{ListenPort: port})
+	if err != nil {
+		return fmt.Errorf("encoding preferences: %w", err)
+	}
+
+	form := url.Values{"json": []string{string(preferences)}}
+	_, _, err = q.postForm(ctx, "/api/v2/app/setPreferences", form, cookies)
+	if err != nil {
+		return fmt.Errorf("setting preferences: %w", err)
+	}
+
+	return nil
+}
+
+// login logs in qBittorrent and returns the session cookies to use.
+func (q *QBittorrent) login(ctx context.Context) (cookies []*http.Cookie, err error) {
+	form := url.Values{
+		"username": []string{q.username},
+		"password": []string{q.password},
+	}
+	body, cookies, err := q.postForm(ctx, "/api/v2/auth/login", form, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// qBittorrent responds with a 200 status code even if
+	// the credentials are wrong, with the body "Fails.".
+	if body != "Ok." {
+		return nil, fmt.Errorf("%w: %s", ErrLoginFailed, body)
+	}
+
+	return cookies, nil
+}
+
+func (q *QBittorrent) postForm(ctx context.Context, path string, form url.Values,
+	cookies []*http.Cookie) (body string, responseCookies []*http.Cookie, err error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		q.url+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", nil, fmt.Errorf("creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// qBittorrent rejects requests with a Referer or Origin
+	// header not matching its host, to prevent CSRF attacks.
+	request.Header.Set("Referer", q.url)
+	for _, cookie := range cookies {
+		request.AddCookie(cookie)
+	}
+
+	response, err := q.client.Do(request)
+	if err != nil {
+		return "", nil, err
+	}
+
+	b, err := io.ReadAll(response.Body)
+	if err != nil {
+		_ = response.Body.Close()
+		return "", nil, fmt.Errorf("reading response body: %w", err)
+	}
+
+	err = response.Body.Close()
+	if err != nil {
+		return "", nil, fmt.Errorf("closing response body: %w", err)
+	}
+
+	body = strings.TrimSpace(string(b))
+	if response.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("%w: %d %s: %s", ErrHTTPStatusCodeNotOK,
+			response.StatusCode, response.Status, body)
+	}
+
+	return body, response.Cookies(), nil
+}
@@ -0,0 +1,92 @@
+package torrent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_QBittorrent_SetListenPort(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		password    string
+		errWrap     error
+		errMessage  string
+		preferences string
+		loginStatus int
+	}{
+		"login refused": {
+			password:    "wrong",
+			loginStatus: http.StatusOK,
+			errWrap:     ErrLoginFailed,
+			errMessage:  "logging in: login failed: Fails.",
+		},
+		"banned": {
+			password:    "wrong",
+			loginStatus: http.StatusForbidden,
+			errWrap:     ErrHTTPStatusCodeNotOK,
+			errMessage: "logging in: HTTP status code not OK: " +
+				"403 403 Forbidden: Your IP address has been banned",
+		},
+		"success": {
+			password:    "secret",
+			loginStatus: http.StatusOK,
+			preferences: `{"listen_port":12345}`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var preferences string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.NotEmpty(t, r.Header.Get("Referer"))
+				err := r.ParseForm()
+				assert.NoError(t, err)
+				switch r.URL.Path {
+				case "/api/v2/auth/login":
+					assert.Equal(t, "admin", r.PostForm.Get("username"))
+					switch {
+					case testCase.loginStatus != http.StatusOK:
+						w.WriteHeader(testCase.loginStatus)
+						_, _ = w.Write([]byte("Your IP address has been banned"))
+					case r.PostForm.Get("password") != "secret":
+						_, _ = w.Write([]byte("Fails."))
+					default:
+						http.SetCookie(w, &http.Cookie{Name: "SID", Value: "session"})
+						_, _ = w.Write([]byte("Ok."))
+					}
+				case "/api/v2/app/setPreferences":
+					cookie, err := r.Cookie("SID")
+					if assert.NoError(t, err) {
+						assert.Equal(t, "session", cookie.Value)
+					}
+					preferences = r.PostForm.Get("json")
+				default:
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			client := NewQBittorrent(server.Client(), server.URL+"/", "admin", testCase.password)
+
+			err := client.SetListenPort(context.Background(), 12345)
+
+			if testCase.errWrap != nil {
+				assert.ErrorIs(t, err, testCase.errWrap)
+			}
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.preferences, preferences)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package torrent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var ErrRPCResultNotSuccess = errors.New("RPC result is not success")
+
+// Transmission is a client for the Transmission RPC API.
+type Transmission struct {
+	client   *http.Client
+	url      string
+	username string
+	password string
+}
+
+// NewTransmission creates a Transmission RPC client for the base
+// URL given, such as http://localhost:9091. The username and
+// password can be left empty if authentication is disabled.
+func NewTransmission(client *http.Client, url, username, password string) *Transmission {
+	return &Transmission{
+		client:   client,
+		url:      strings.TrimSuffix(url, "/"),
+		username: username,
+		password: password,
+	}
+}
+
+// SetListenPort sets the Transmission peer port to the port given.
+func (t *Transmission) SetListenPort(ctx context.Context, port uint16) (err error) {
+	type sessionSetArguments struct {
+		PeerPort         uint16 `json:"peer-port"`
+		PeerPortRandomOn bool   `json:"peer-port-random-on-start"`
+	}
+	requestBody, err := json.Marshal(struct {
+		Method    string              `json:"method"`
+		Arguments sessionSetArguments `json:"arguments"`
+	}{
+		Method:    "session-set",
+		Arguments: sessionSetArguments{PeerPort: port},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding request body: %w", err)
+	}
+
+	// The first request is expected to fail with a 409 status code
+	// and the session id to use, as a CSRF protection.
+	sessionID := ""
+	const maxTries = 2
+	for i := 0; i < maxTries; i++ {
+		var retry bool
+		sessionID, retry, err = t.rpc(ctx, requestBody, sessionID)
+		if err != nil {
+			return err
+		} else if !retry {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: session id not accepted", ErrHTTPStatusCodeNotOK)
+}
+
+// rpc sends the request body given with the session id given, and
+// returns retry as true with the new session id to use if the session
+// id was not accepted.
+func (t *Transmission) rpc(ctx context.Context, requestBody []byte, sessionID string) (
+	newSessionID string, retry bool, err error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		t.url+"/transmission/rpc", bytes.NewReader(requestBody))
+	if err != nil {
+		return "", false, fmt.Errorf("creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	const sessionIDHeader = "X-Transmission-Session-Id"
+	if sessionID != "" {
+		request.Header.Set(sessionIDHeader, sessionID)
+	}
+	if t.username != "" || t.password != "" {
+		request.SetBasicAuth(t.username, t.password)
+	}
+
+	response, err := t.client.Do(request)
+	if err != nil {
+		return "", false, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusConflict:
+		return response.Header.Get(sessionIDHeader), true, nil
+	case http.StatusOK:
+	default:
+		return "", false, fmt.Errorf("%w: %d %s", ErrHTTPStatusCodeNotOK,
+			response.StatusCode, response.Status)
+	}
+
+	var data struct {
+		Result string `json:"result"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&data)
+	if err != nil {
+		return "", false, fmt.Errorf("decoding response body: %w", err)
+	}
+
+	if data.Result != "success" {
+		return "", false, fmt.Errorf("%w: %s", ErrRPCResultNotSuccess, data.Result)
+	}
+
+	return sessionID, false, response.Body.Close()
+}
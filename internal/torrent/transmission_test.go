@@ -0,0 +1,51 @@
+package torrent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Transmission_SetListenPort(t *testing.T) {
+	t.Parallel()
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/transmission/rpc", r.URL.Path)
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "pass", password)
+
+		if r.Header.Get("X-Transmission-Session-Id") != "session" {
+			w.Header().Set("X-Transmission-Session-Id", "session")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		var body struct {
+			Method    string `json:"method"`
+			Arguments struct {
+				PeerPort uint16 `json:"peer-port"`
+			} `json:"arguments"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Equal(t, "session-set", body.Method)
+		assert.Equal(t, uint16(12345), body.Arguments.PeerPort)
+		_, _ = w.Write([]byte(`{"result":"success","arguments":{}}`))
+	}))
+	defer server.Close()
+
+	client := NewTransmission(server.Client(), server.URL, "user", "pass")
+
+	err := client.SetListenPort(context.Background(), 12345)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+}
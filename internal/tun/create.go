@@ -1,6 +1,7 @@
 package tun
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,8 +9,14 @@ import (
 	"golang.org/x/sys/unix"
 )
 
+var ErrTUNCreateNotPermitted = errors.New("TUN device cannot be created in this container")
+
 // Create creates a TUN device at the path specified.
-func (t *Tun) Create(path string) error {
+func (t *Tun) Create(path string) (err error) {
+	defer func() {
+		err = explainCreateError(err)
+	}()
+
 	parentDir := filepath.Dir(path)
 	if err := os.MkdirAll(parentDir, 0751); err != nil {
 		return err
@@ -20,7 +27,7 @@ func (t *Tun) Create(path string) error {
 		minor = 200
 	)
 	dev := unix.Mkdev(major, minor)
-	err := t.mknod(path, unix.S_IFCHR, int(dev))
+	err = t.mknod(path, unix.S_IFCHR, int(dev))
 	if err != nil {
 		return fmt.Errorf("creating TUN device file node: %w", err)
 	}
@@ -38,3 +45,20 @@ func (t *Tun) Create(path string) error {
 
 	return nil
 }
+
+// explainCreateError wraps errors caused by the container lacking
+// the privileges to create the TUN device, which is common on
+// Kubernetes, with a hint on how to provide the device instead.
+func explainCreateError(err error) error {
+	switch {
+	case errors.Is(err, unix.EPERM),
+		errors.Is(err, unix.EACCES),
+		errors.Is(err, unix.EROFS):
+		return fmt.Errorf("%w: %s; either mount /dev/net/tun in the container "+
+			"(e.g. with a hostPath volume or a device plugin on Kubernetes) "+
+			"or give it the NET_ADMIN and MKNOD capabilities",
+			ErrTUNCreateNotPermitted, err)
+	default:
+		return err
+	}
+}
@@ -1,10 +1,13 @@
 package tun
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
 )
 
 func Test_Tun(t *testing.T) {
@@ -66,3 +69,21 @@ func getTempPath(t *testing.T) (path string) {
 	require.NoError(t, err)
 	return path
 }
+
+func Test_explainCreateError(t *testing.T) {
+	t.Parallel()
+
+	err := explainCreateError(nil)
+	require.NoError(t, err)
+
+	errDummy := errors.New("dummy")
+	err = explainCreateError(errDummy)
+	require.ErrorIs(t, err, errDummy)
+
+	err = explainCreateError(fmt.Errorf("creating TUN device file node: %w", unix.EPERM))
+	require.ErrorIs(t, err, ErrTUNCreateNotPermitted)
+	require.EqualError(t, err, "TUN device cannot be created in this container: "+
+		"creating TUN device file node: operation not permitted; "+
+		"either mount /dev/net/tun in the container (e.g. with a hostPath volume "+
+		"or a device plugin on Kubernetes) or give it the NET_ADMIN and MKNOD capabilities")
+}
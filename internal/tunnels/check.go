@@ -0,0 +1,118 @@
+package tunnels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/publicip/ipinfo"
+	"golang.org/x/sys/unix"
+)
+
+// monitor fetches the public IP address of the tunnel and checks its
+// health periodically, until the context is canceled or a health check
+// fails, in which case the health check error is returned.
+func (t *Tunnels) monitor(ctx context.Context, tunnel *tunnel) (err error) {
+	dialer := &net.Dialer{
+		Control: markControl(int(tunnel.settings.FirewallMark)),
+	}
+	name := tunnel.settings.Interface
+
+	const checkPeriod = time.Minute
+	ticker := time.NewTicker(checkPeriod)
+	defer ticker.Stop()
+
+	for fetchPublicIP := true; ; {
+		err = t.checkHealth(ctx, dialer)
+		if ctx.Err() != nil {
+			return nil
+		}
+		tunnel.setHealth(err)
+		if err != nil {
+			return fmt.Errorf("health check failed: %w", err)
+		}
+
+		if fetchPublicIP {
+			err = t.fetchPublicIP(ctx, dialer, tunnel)
+			if ctx.Err() != nil {
+				return nil
+			} else if err != nil {
+				t.logger.Error(name + ": fetching public IP address: " + err.Error())
+			} else {
+				fetchPublicIP = false
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// checkHealth dials the health target address through the tunnel.
+func (t *Tunnels) checkHealth(ctx context.Context, dialer *net.Dialer) (err error) {
+	const timeout = 10 * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	connection, err := dialer.DialContext(ctx, "tcp", makeAddressToDial(t.healthTarget))
+	if err != nil {
+		return err
+	}
+	return connection.Close()
+}
+
+func (t *Tunnels) fetchPublicIP(ctx context.Context, dialer *net.Dialer,
+	tunnel *tunnel) (err error) {
+	const timeout = 10 * time.Second
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+	}
+	defer client.CloseIdleConnections()
+
+	result, err := ipinfo.New(client).FetchInfo(ctx, nil)
+	if err != nil {
+		return err
+	}
+	publicIP := result.ToPublicIPModel()
+	tunnel.setPublicIP(publicIP)
+	t.logger.Info(tunnel.settings.Interface + ": public IP address is " + publicIP.IP.String())
+	return nil
+}
+
+// makeAddressToDial returns the address given with
+// the port 443 if it does not contain a port.
+func makeAddressToDial(address string) (addressToDial string) {
+	_, _, err := net.SplitHostPort(address)
+	addrErr := new(net.AddrError)
+	if errors.As(err, &addrErr) && addrErr.Err == "missing port in address" {
+		const defaultPort = "443"
+		return net.JoinHostPort(address, defaultPort)
+	}
+	return address
+}
+
+// markControl returns a dialer control function setting the firewall
+// mark given on the socket, such that its traffic is routed through
+// the tunnel.
+func markControl(firewallMark int) func(network, address string, c syscall.RawConn) error {
+	return func(_, _ string, c syscall.RawConn) (err error) {
+		controlErr := c.Control(func(fd uintptr) {
+			err = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_MARK, firewallMark)
+		})
+		if controlErr != nil {
+			return controlErr
+		}
+		if err != nil {
+			return fmt.Errorf("setting firewall mark: %w", err)
+		}
+		return nil
+	}
+}
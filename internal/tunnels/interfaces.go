@@ -0,0 +1,17 @@
+package tunnels
+
+import (
+	"context"
+
+	"github.com/qdm12/gluetun/internal/routing"
+)
+
+type Routing interface {
+	AddTunnelPolicy(policy routing.TunnelPolicy) (err error)
+	RemoveTunnelPolicy(policy routing.TunnelPolicy) (err error)
+}
+
+type PortAllower interface {
+	SetAllowedPort(ctx context.Context, port uint16, intf string) (err error)
+	RemoveAllowedPort(ctx context.Context, port uint16) (err error)
+}
@@ -0,0 +1,134 @@
+package tunnels
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/routing"
+	"github.com/qdm12/gluetun/internal/wireguard"
+)
+
+// runTunnel sets up the routing policy of the tunnel and runs the tunnel
+// until the context is canceled, restarting it after a delay if it fails.
+func (t *Tunnels) runTunnel(ctx context.Context, tunnel *tunnel) {
+	name := tunnel.settings.Interface
+	policy := routing.TunnelPolicy{
+		Table:         tunnel.firewallMark,
+		Endpoint:      tunnel.settings.Endpoint.IP,
+		SourceSubnets: tunnel.settings.SourceSubnets,
+		Mark:          tunnel.settings.FirewallMark,
+	}
+	err := t.routing.AddTunnelPolicy(policy)
+	if err != nil {
+		err = fmt.Errorf("adding routing policy: %w", err)
+		tunnel.setRunning(false, err)
+		t.logger.Error(name + ": " + err.Error())
+		return
+	}
+	defer func() {
+		err := t.routing.RemoveTunnelPolicy(policy)
+		if err != nil {
+			t.logger.Error(name + ": removing routing policy: " + err.Error())
+		}
+	}()
+
+	const restartDelay = 10 * time.Second
+	for {
+		err = t.runTunnelOnce(ctx, tunnel)
+		if ctx.Err() != nil {
+			tunnel.setRunning(false, nil)
+			return
+		}
+		tunnel.setRunning(false, err)
+		t.logger.Error(name + ": " + err.Error() + ", restarting in " + restartDelay.String())
+
+		timer := time.NewTimer(restartDelay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			tunnel.setRunning(false, nil)
+			return
+		}
+	}
+}
+
+// runTunnelOnce runs the tunnel Wireguard interface until the context
+// is canceled, the interface fails or the tunnel becomes unhealthy.
+func (t *Tunnels) runTunnelOnce(ctx context.Context, tunnel *tunnel) (err error) {
+	wireguarder, err := wireguard.New(t.makeWireguardSettings(tunnel), t.netLinker, t.logger)
+	if err != nil {
+		return fmt.Errorf("creating Wireguard: %w", err)
+	}
+
+	runCtx, runCancel := context.WithCancel(ctx)
+	defer runCancel()
+	waitError := make(chan error)
+	ready := make(chan struct{})
+	go wireguarder.Run(runCtx, waitError, ready)
+
+	select {
+	case <-ready:
+	case err = <-waitError:
+		return err
+	}
+
+	tunnel.setRunning(true, nil)
+	t.logger.Info(tunnel.settings.Interface + ": tunnel is up")
+
+	if port := tunnel.settings.ForwardedPort; port != 0 {
+		err = t.portAllower.SetAllowedPort(ctx, port, tunnel.settings.Interface)
+		if err != nil {
+			runCancel()
+			<-waitError
+			return fmt.Errorf("allowing forwarded port %d through firewall: %w", port, err)
+		}
+		defer func() {
+			// Use a background context so the port is removed
+			// even if the tunnel context is canceled.
+			err := t.portAllower.RemoveAllowedPort(context.Background(), port)
+			if err != nil {
+				t.logger.Error(tunnel.settings.Interface + ": removing forwarded port " +
+					fmt.Sprint(port) + " from firewall: " + err.Error())
+			}
+		}()
+	}
+
+	monitorError := make(chan error)
+	go func() {
+		monitorError <- t.monitor(runCtx, tunnel)
+	}()
+
+	select {
+	case err = <-waitError:
+		runCancel()
+		<-monitorError
+	case err = <-monitorError:
+		runCancel()
+		<-waitError
+	}
+	return err
+}
+
+func (t *Tunnels) makeWireguardSettings(tunnel *tunnel) (settings wireguard.Settings) {
+	ipv6 := false
+	settings = wireguard.Settings{
+		InterfaceName:  tunnel.settings.Interface,
+		PrivateKey:     tunnel.settings.PrivateKey,
+		PublicKey:      tunnel.settings.PublicKey,
+		PreSharedKey:   tunnel.settings.PreSharedKey,
+		Endpoint:       &net.UDPAddr{IP: tunnel.settings.Endpoint.IP, Port: tunnel.settings.Endpoint.Port},
+		FirewallMark:   tunnel.firewallMark,
+		SkipRule:       true, // rules added with the routing policy
+		IPv6:           &ipv6,
+		Implementation: t.implementation,
+	}
+	for i := range tunnel.settings.Addresses {
+		if tunnel.settings.Addresses[i].IP.To4() != nil {
+			settings.Addresses = append(settings.Addresses, &tunnel.settings.Addresses[i])
+		}
+	}
+	return settings
+}
@@ -0,0 +1,105 @@
+package tunnels
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+type tunnel struct {
+	settings settings.VPNTunnel
+	// firewallMark is the firewall mark of the tunnel encrypted
+	// packets and the routing table of the tunnel routes.
+	firewallMark int
+
+	statusMutex sync.RWMutex
+	running     bool
+	healthy     bool
+	err         error
+	publicIP    models.PublicIP
+}
+
+// Status is the status of an additional tunnel.
+type Status struct {
+	Interface string `json:"interface"`
+	Endpoint  string `json:"endpoint"`
+	// Running is true if the tunnel interface is up.
+	Running bool `json:"running"`
+	// Healthy is true if the last health check through
+	// the tunnel succeeded.
+	Healthy bool `json:"healthy"`
+	// Error is the last error of the tunnel, if any.
+	Error    string          `json:"error,omitempty"`
+	PublicIP models.PublicIP `json:"public_ip"`
+	// ForwardedPort is the static forwarded port from the tunnel
+	// settings, set only while the tunnel is running.
+	ForwardedPort uint16 `json:"forwarded_port,omitempty"`
+}
+
+func (t *tunnel) status() (status Status) {
+	t.statusMutex.RLock()
+	defer t.statusMutex.RUnlock()
+
+	status = Status{
+		Interface: t.settings.Interface,
+		Endpoint:  t.settings.Endpoint.String(),
+		Running:   t.running,
+		Healthy:   t.healthy,
+		PublicIP:  t.publicIP.Copy(),
+	}
+	if t.err != nil {
+		status.Error = t.err.Error()
+	}
+	if t.running {
+		status.ForwardedPort = t.settings.ForwardedPort
+	}
+	return status
+}
+
+func (t *tunnel) setRunning(running bool, err error) {
+	t.statusMutex.Lock()
+	defer t.statusMutex.Unlock()
+	t.running = running
+	t.err = err
+	if !running {
+		t.healthy = false
+		t.publicIP = models.PublicIP{}
+	}
+}
+
+func (t *tunnel) setHealth(err error) {
+	t.statusMutex.Lock()
+	defer t.statusMutex.Unlock()
+	t.healthy = err == nil
+	t.err = err
+}
+
+func (t *tunnel) setPublicIP(publicIP models.PublicIP) {
+	t.statusMutex.Lock()
+	defer t.statusMutex.Unlock()
+	t.publicIP = publicIP
+}
+
+// Statuses returns the status of each tunnel.
+func (t *Tunnels) Statuses() (statuses []Status) {
+	statuses = make([]Status, len(t.tunnels))
+	for i, tunnel := range t.tunnels {
+		statuses[i] = tunnel.status()
+	}
+	return statuses
+}
+
+var ErrTunnelNotFound = errors.New("tunnel not found")
+
+// Status returns the status of the tunnel with the interface name given.
+func (t *Tunnels) Status(name string) (status Status, err error) {
+	for _, tunnel := range t.tunnels {
+		if tunnel.settings.Interface == name {
+			return tunnel.status(), nil
+		}
+	}
+	return status, fmt.Errorf("%w: %s", ErrTunnelNotFound, name)
+}
@@ -0,0 +1,63 @@
+package tunnels
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Tunnels_Status(t *testing.T) {
+	t.Parallel()
+
+	tunnels := New([]settings.VPNTunnel{{
+		Interface:     "tunnel1",
+		Endpoint:      &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 51820},
+		ForwardedPort: 12345,
+	}, {
+		Interface: "tunnel2",
+		Endpoint:  &net.UDPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 51820},
+	}}, "auto", "cloudflare.com:443", nil, nil, nil, nil)
+
+	assert.Equal(t, 51901, tunnels.tunnels[0].firewallMark)
+	assert.Equal(t, 51902, tunnels.tunnels[1].firewallMark)
+
+	tunnels.tunnels[0].setRunning(true, nil)
+	tunnels.tunnels[0].setHealth(nil)
+	tunnels.tunnels[0].setPublicIP(models.PublicIP{IP: net.IPv4(9, 9, 9, 9)})
+	tunnels.tunnels[1].setRunning(false, errors.New("handshake failed"))
+
+	status, err := tunnels.Status("tunnel1")
+	require.NoError(t, err)
+	assert.Equal(t, Status{
+		Interface:     "tunnel1",
+		Endpoint:      "1.2.3.4:51820",
+		Running:       true,
+		Healthy:       true,
+		PublicIP:      models.PublicIP{IP: net.IPv4(9, 9, 9, 9)},
+		ForwardedPort: 12345,
+	}, status)
+
+	statuses := tunnels.Statuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, Status{
+		Interface: "tunnel2",
+		Endpoint:  "5.6.7.8:51820",
+		Error:     "handshake failed",
+		PublicIP:  models.PublicIP{IP: net.IP{}},
+	}, statuses[1])
+
+	_, err = tunnels.Status("tunnel3")
+	assert.ErrorIs(t, err, ErrTunnelNotFound)
+}
+
+func Test_makeAddressToDial(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "cloudflare.com:443", makeAddressToDial("cloudflare.com"))
+	assert.Equal(t, "1.1.1.1:53", makeAddressToDial("1.1.1.1:53"))
+}
@@ -0,0 +1,71 @@
+// Package tunnels runs additional Wireguard tunnels alongside the
+// main VPN connection, each routing only the traffic selected by its
+// source subnets, source ports or firewall mark, and keeps track of
+// their health, public IP address and forwarded port.
+package tunnels
+
+import (
+	"context"
+	"sync"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/wireguard"
+)
+
+// firewallMarkBase is added to the tunnel number to obtain the
+// firewall mark of the tunnel encrypted packets, which is also the
+// routing table number of the tunnel routes.
+const firewallMarkBase = constants.TunnelFirewallMarkBase
+
+type Tunnels struct {
+	tunnels        []*tunnel
+	implementation string
+	healthTarget   string
+	netLinker      wireguard.NetLinker
+	routing        Routing
+	portAllower    PortAllower
+	logger         Logger
+}
+
+// New creates the additional tunnels of the settings given, using the
+// Wireguard implementation given. The health of each tunnel is checked
+// by periodically dialing the health target address through it.
+func New(settings []settings.VPNTunnel, implementation, healthTarget string,
+	netLinker wireguard.NetLinker, routing Routing, portAllower PortAllower,
+	logger Logger) *Tunnels {
+	tunnels := make([]*tunnel, len(settings))
+	for i, tunnelSettings := range settings {
+		tunnels[i] = &tunnel{
+			settings:     tunnelSettings,
+			firewallMark: firewallMarkBase + i + 1,
+		}
+	}
+
+	return &Tunnels{
+		tunnels:        tunnels,
+		implementation: implementation,
+		healthTarget:   healthTarget,
+		netLinker:      netLinker,
+		routing:        routing,
+		portAllower:    portAllower,
+		logger:         logger,
+	}
+}
+
+// Run runs each tunnel until the context is canceled, restarting
+// a tunnel if it fails or becomes unhealthy. Errors are logged.
+func (t *Tunnels) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	var wg sync.WaitGroup
+	for _, tunnel := range t.tunnels {
+		wg.Add(1)
+		tunnel := tunnel
+		go func() {
+			defer wg.Done()
+			t.runTunnel(ctx, tunnel)
+		}()
+	}
+	wg.Wait()
+}
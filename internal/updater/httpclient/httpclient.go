@@ -0,0 +1,27 @@
+// Package httpclient provides an HTTP client suited to fetch
+// VPN servers data from many providers concurrently.
+package httpclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// New creates an HTTP client with the given timeout, sharing
+// a single transport with HTTP/2 enabled and idle connections
+// kept around so they can be re-used across provider updates.
+func New(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+	transport.ForceAttemptHTTP2 = true
+	const maxIdleConns = 100
+	transport.MaxIdleConns = maxIdleConns
+	const maxIdleConnsPerHost = 10
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	const idleConnTimeout = 90 * time.Second
+	transport.IdleConnTimeout = idleConnTimeout
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
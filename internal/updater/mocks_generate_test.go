@@ -0,0 +1,4 @@
+package updater
+
+//go:generate mockgen -destination=mocks_test.go -package=$GOPACKAGE . Providers,Storage,Logger
+//go:generate mockgen -destination=provider_mock_test.go -package=$GOPACKAGE github.com/qdm12/gluetun/internal/provider Provider
@@ -3,8 +3,10 @@ package updater
 import (
 	"context"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/updater/unzip"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -36,32 +38,58 @@ func New(httpClient *http.Client, storage Storage,
 	}
 }
 
-func (u *Updater) UpdateServers(ctx context.Context, providers []string,
-	minRatio float64) (err error) {
-	caser := cases.Title(language.English)
-	for _, providerName := range providers {
-		u.logger.Info("updating " + caser.String(providerName) + " servers...")
-
-		fetcher := u.providers.Get(providerName)
-		// TODO support servers offering only TCP or only UDP
-		// for NordVPN and PureVPN
-		err := u.updateProvider(ctx, fetcher, minRatio)
-		if err == nil {
-			continue
+// UpdateServers updates the servers data of each provider
+// given in the settings, running at most settings.Workers
+// provider updates concurrently.
+func (u *Updater) UpdateServers(ctx context.Context,
+	settings settings.Updater) (err error) {
+	workers := make(chan struct{}, *settings.Workers)
+	errs := make([]error, len(settings.Providers))
+	wg := new(sync.WaitGroup)
+	for i, providerName := range settings.Providers {
+		select {
+		case workers <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
 		}
 
-		// return the only error for the single provider.
-		if len(providers) == 1 {
-			return err
-		}
+		wg.Add(1)
+		go func(i int, providerName string) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
 
-		// stop updating the next providers if context is canceled.
-		if ctxErr := ctx.Err(); ctxErr != nil {
-			return ctxErr
-		}
+			// A caser is not safe for concurrent use.
+			caser := cases.Title(language.English)
+			u.logger.Info("updating " + caser.String(providerName) + " servers...")
+			providerCtx, cancel := context.WithTimeout(ctx, settings.ProviderTimeout)
+			defer cancel()
+			fetcher := u.providers.Get(providerName)
+			// TODO support servers offering only TCP or only UDP
+			// for NordVPN and PureVPN
+			errs[i] = u.updateProvider(providerCtx, fetcher, settings.MinRatio)
+		}(i, providerName)
+	}
+	wg.Wait()
 
-		// Log the error and continue updating the next provider.
-		u.logger.Error(err.Error())
+	// return the only error for the single provider.
+	if len(settings.Providers) == 1 {
+		return errs[0]
+	}
+
+	// return the context error if the context is canceled.
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	// Log each provider error.
+	caser := cases.Title(language.English)
+	for i, err := range errs {
+		if err != nil {
+			u.logger.Error(caser.String(settings.Providers[i]) + ": " + err.Error())
+		}
 	}
 
 	return nil
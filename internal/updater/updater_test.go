@@ -0,0 +1,158 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Updater_UpdateServers(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+
+	// expectProvider sets the provider mock of the provider name given
+	// to be returned by the providers mock, and to run fetch once, or
+	// at most once if optional is true.
+	expectProvider := func(ctrl *gomock.Controller, providers *MockProviders,
+		storage *MockStorage, name string, optional bool,
+		fetch func(ctx context.Context) error) {
+		minTimes := 1
+		if optional {
+			minTimes = 0
+		}
+		provider := NewMockProvider(ctrl)
+		providers.EXPECT().Get(name).Return(provider).MinTimes(minTimes).MaxTimes(1)
+		provider.EXPECT().Name().Return(name).MinTimes(minTimes).MaxTimes(1)
+		storage.EXPECT().GetServersCount(name).Return(0).MinTimes(minTimes).MaxTimes(1)
+		provider.EXPECT().FetchServers(gomock.Any(), 0).
+			DoAndReturn(func(ctx context.Context, _ int) ([]models.Server, error) {
+				return nil, fetch(ctx)
+			}).MinTimes(minTimes).MaxTimes(1)
+		storage.EXPECT().ServersAreEqual(name, nil).Return(true).AnyTimes()
+	}
+
+	makeSettings := func(workers int, providers ...string) settings.Updater {
+		return settings.Updater{
+			Providers:       providers,
+			Workers:         &workers,
+			ProviderTimeout: time.Minute,
+		}
+	}
+
+	t.Run("single provider error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		providers := NewMockProviders(ctrl)
+		storage := NewMockStorage(ctrl)
+		logger := NewMockLogger(ctrl)
+		logger.EXPECT().Info("updating Mullvad servers...")
+		expectProvider(ctrl, providers, storage, "mullvad", false,
+			func(context.Context) error { return errTest })
+		updater := New(nil, storage, providers, logger)
+
+		err := updater.UpdateServers(context.Background(), makeSettings(4, "mullvad"))
+
+		assert.ErrorIs(t, err, errTest)
+		assert.EqualError(t, err, "getting servers: test error")
+	})
+
+	t.Run("concurrent providers errors logged", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		providers := NewMockProviders(ctrl)
+		storage := NewMockStorage(ctrl)
+		logger := NewMockLogger(ctrl)
+		logger.EXPECT().Info("updating Mullvad servers...")
+		logger.EXPECT().Info("updating Ivpn servers...")
+		logger.EXPECT().Error("Ivpn: getting servers: test error")
+
+		// Each fetch waits for the other one to start, which only
+		// succeeds if both providers are updated concurrently.
+		started := new(sync.WaitGroup)
+		const providersCount = 2
+		started.Add(providersCount)
+		fetch := func(fetchErr error) func(ctx context.Context) error {
+			return func(ctx context.Context) error {
+				started.Done()
+				started.Wait()
+				return fetchErr
+			}
+		}
+		expectProvider(ctrl, providers, storage, "mullvad", false, fetch(nil))
+		expectProvider(ctrl, providers, storage, "ivpn", false, fetch(errTest))
+		updater := New(nil, storage, providers, logger)
+
+		err := updater.UpdateServers(context.Background(),
+			makeSettings(providersCount, "mullvad", "ivpn"))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("workers limit", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		providers := NewMockProviders(ctrl)
+		storage := NewMockStorage(ctrl)
+		logger := NewMockLogger(ctrl)
+		logger.EXPECT().Info(gomock.Any()).Times(3)
+
+		mutex := new(sync.Mutex)
+		running, maxRunning := 0, 0
+		fetch := func(context.Context) error {
+			mutex.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mutex.Unlock()
+			time.Sleep(time.Millisecond)
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+			return nil
+		}
+		for _, name := range []string{"mullvad", "ivpn", "surfshark"} {
+			expectProvider(ctrl, providers, storage, name, false, fetch)
+		}
+		updater := New(nil, storage, providers, logger)
+
+		err := updater.UpdateServers(context.Background(),
+			makeSettings(1, "mullvad", "ivpn", "surfshark"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, maxRunning)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		providers := NewMockProviders(ctrl)
+		storage := NewMockStorage(ctrl)
+		logger := NewMockLogger(ctrl)
+		logger.EXPECT().Info(gomock.Any()).MinTimes(1).MaxTimes(2)
+
+		// The second provider may not be updated once the
+		// context is canceled by the first provider update.
+		ctx, cancel := context.WithCancel(context.Background())
+		fetch := func(ctx context.Context) error {
+			cancel()
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		expectProvider(ctrl, providers, storage, "mullvad", false, fetch)
+		expectProvider(ctrl, providers, storage, "ivpn", true, fetch)
+		updater := New(nil, storage, providers, logger)
+
+		err := updater.UpdateServers(ctx, makeSettings(2, "mullvad", "ivpn"))
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
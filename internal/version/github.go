@@ -14,6 +14,7 @@ type githubRelease struct {
 	Name        string    `json:"name"`
 	Prerelease  bool      `json:"prerelease"`
 	PublishedAt time.Time `json:"published_at"`
+	Body        string    `json:"body"`
 }
 
 type githubCommit struct {
@@ -0,0 +1,124 @@
+package version
+
+import (
+	"regexp"
+	"strings"
+)
+
+type changeKind string
+
+const (
+	changeBreaking    changeKind = "Breaking change"
+	changeDeprecation changeKind = "Deprecation"
+)
+
+// releaseChange is a breaking change or deprecation
+// listed in the notes of a release.
+type releaseChange struct {
+	kind changeKind
+	text string
+	// variables are the environment variables mentioned
+	// in the change text.
+	variables []string
+}
+
+// relevant returns true if the change mentions one of the
+// environment variables set, or if it mentions no variable
+// and may therefore concern every user.
+func (r releaseChange) relevant(setVariables map[string]struct{}) bool {
+	if len(r.variables) == 0 {
+		return true
+	}
+	for _, variable := range r.variables {
+		if _, ok := setVariables[variable]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseReleaseNotes returns the changes listed as bullet points
+// in the markdown sections of the release notes body given, whose
+// heading mentions breaking changes or deprecations.
+func parseReleaseNotes(body string) (changes []releaseChange) {
+	var kind changeKind
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			kind = headingToChangeKind(line)
+			continue
+		}
+
+		if kind == "" {
+			continue
+		}
+
+		text, isBullet := trimBullet(line)
+		if !isBullet || text == "" {
+			continue
+		}
+
+		changes = append(changes, releaseChange{
+			kind:      kind,
+			text:      text,
+			variables: extractVariables(text),
+		})
+	}
+	return changes
+}
+
+func headingToChangeKind(heading string) (kind changeKind) {
+	heading = strings.ToLower(heading)
+	switch {
+	case strings.Contains(heading, "breaking"):
+		return changeBreaking
+	case strings.Contains(heading, "deprecat"):
+		return changeDeprecation
+	default:
+		return ""
+	}
+}
+
+func trimBullet(line string) (text string, isBullet bool) {
+	for _, prefix := range []string{"- ", "* "} {
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(line, prefix)), true
+		}
+	}
+	return "", false
+}
+
+// variableRegex matches quoted upper case words such as `DOT` and
+// unquoted upper case words containing an underscore such as HTTP_PROXY.
+var variableRegex = regexp.MustCompile("`([A-Z][A-Z0-9_]*)`|\\b([A-Z][A-Z0-9]*(?:_[A-Z0-9]+)+)\\b")
+
+func extractVariables(text string) (variables []string) {
+	seen := make(map[string]struct{})
+	for _, match := range variableRegex.FindAllStringSubmatch(text, -1) {
+		variable := match[1]
+		if variable == "" {
+			variable = match[2]
+		}
+		if _, ok := seen[variable]; ok {
+			continue
+		}
+		seen[variable] = struct{}{}
+		variables = append(variables, variable)
+	}
+	return variables
+}
+
+// environToSetVariables returns the set of environment
+// variable keys with a non empty value, from the environment
+// given in the form KEY=VALUE.
+func environToSetVariables(environ []string) (setVariables map[string]struct{}) {
+	setVariables = make(map[string]struct{}, len(environ))
+	for _, keyValue := range environ {
+		key, value, ok := strings.Cut(keyValue, "=")
+		if !ok || value == "" {
+			continue
+		}
+		setVariables[key] = struct{}{}
+	}
+	return setVariables
+}
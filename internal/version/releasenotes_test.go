@@ -0,0 +1,147 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseReleaseNotes(t *testing.T) {
+	t.Parallel()
+
+	const body = `## Features
+
+- Add ` + "`DNS_REWRITES`" + ` option
+
+## Breaking changes
+
+- OPENVPN_CUSTOM_CONFIG is renamed to OPENVPN_CONFIG_FILE
+* Drop support for OpenVPN 2.4
+Not a bullet point
+
+### Deprecations
+
+- ` + "`DOT`" + ` replaced by DNS_OVER_TLS and DOT_PROVIDERS
+
+## Fixes
+
+- Fix HTTP_PROXY crash`
+
+	changes := parseReleaseNotes(body)
+
+	expected := []releaseChange{
+		{
+			kind:      changeBreaking,
+			text:      "OPENVPN_CUSTOM_CONFIG is renamed to OPENVPN_CONFIG_FILE",
+			variables: []string{"OPENVPN_CUSTOM_CONFIG", "OPENVPN_CONFIG_FILE"},
+		},
+		{
+			kind: changeBreaking,
+			text: "Drop support for OpenVPN 2.4",
+		},
+		{
+			kind:      changeDeprecation,
+			text:      "`DOT` replaced by DNS_OVER_TLS and DOT_PROVIDERS",
+			variables: []string{"DOT", "DNS_OVER_TLS", "DOT_PROVIDERS"},
+		},
+	}
+	assert.Equal(t, expected, changes)
+}
+
+func Test_releaseChange_relevant(t *testing.T) {
+	t.Parallel()
+
+	setVariables := environToSetVariables([]string{
+		"OPENVPN_CUSTOM_CONFIG=/gluetun/custom.conf",
+		"DOT=",
+		"INVALID",
+	})
+
+	testCases := map[string]struct {
+		change   releaseChange
+		relevant bool
+	}{
+		"no variable": {
+			change:   releaseChange{},
+			relevant: true,
+		},
+		"variable set": {
+			change: releaseChange{
+				variables: []string{"OPENVPN_CONFIG_FILE", "OPENVPN_CUSTOM_CONFIG"},
+			},
+			relevant: true,
+		},
+		"variable set to empty value": {
+			change: releaseChange{
+				variables: []string{"DOT"},
+			},
+		},
+		"variable not set": {
+			change: releaseChange{
+				variables: []string{"HTTP_PROXY"},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			relevant := testCase.change.relevant(setVariables)
+
+			assert.Equal(t, testCase.relevant, relevant)
+		})
+	}
+}
+
+func Test_getNewerReleases(t *testing.T) {
+	t.Parallel()
+
+	releases := []githubRelease{
+		{TagName: "v3.36.0", Prerelease: true},
+		{TagName: "v3.35.0"},
+		{TagName: "v3.34.1"},
+		{TagName: "v3.34.0"},
+	}
+
+	testCases := map[string]struct {
+		releases       []githubRelease
+		currentVersion string
+		newer          []githubRelease
+		errWrapped     error
+	}{
+		"no release": {
+			currentVersion: "v3.34.0",
+			errWrapped:     errReleaseNotFound,
+		},
+		"latest release": {
+			releases:       releases,
+			currentVersion: "v3.35.0",
+		},
+		"older release": {
+			releases:       releases,
+			currentVersion: "v3.34.0",
+			newer:          []githubRelease{{TagName: "v3.35.0"}, {TagName: "v3.34.1"}},
+		},
+		"unknown release": {
+			releases:       releases,
+			currentVersion: "v3.0.0",
+			newer: []githubRelease{
+				{TagName: "v3.35.0"}, {TagName: "v3.34.1"}, {TagName: "v3.34.0"},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			newer, err := getNewerReleases(testCase.releases, testCase.currentVersion)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			assert.Equal(t, testCase.newer, newer)
+		})
+	}
+}
@@ -8,54 +8,105 @@ import (
 	"time"
 
 	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/notification"
 	"github.com/qdm12/golibs/format"
 )
 
-// GetMessage returns a message for the user describing if there is a newer version
-// available. It should only be called once the tunnel is established.
-func GetMessage(ctx context.Context, buildInfo models.BuildInformation,
-	client *http.Client) (message string, err error) {
+// GetNotifications returns notifications for the user describing if there is
+// a newer version available, and the breaking changes and deprecations of
+// newer releases relevant to the environment given, in the form KEY=VALUE.
+// It should only be called once the tunnel is established.
+func GetNotifications(ctx context.Context, buildInfo models.BuildInformation,
+	client *http.Client, environ []string) (
+	notifications []notification.Notification, err error) {
 	if buildInfo.Version == "latest" {
-		// Find # of commits between current commit and latest commit
-		commitsSince, err := getCommitsSince(ctx, client, buildInfo.Commit)
+		message, err := getLatestCommitMessage(ctx, client, buildInfo)
 		if err != nil {
-			return "", err
-		} else if commitsSince == 0 {
-			return fmt.Sprintf("You are running on the bleeding edge of %s!", buildInfo.Version), nil
+			return nil, err
 		}
-		commits := "commits"
-		if commitsSince == 1 {
-			commits = "commit"
+		return []notification.Notification{{Title: message}}, nil
+	}
+
+	releases, err := getGithubReleases(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	newerReleases, err := getNewerReleases(releases, buildInfo.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(newerReleases) == 0 {
+		return []notification.Notification{{
+			Title: "You are running the latest release " + buildInfo.Version,
+		}}, nil
+	}
+
+	latest := newerReleases[0]
+	timeSinceRelease := format.FriendlyDuration(time.Since(latest.PublishedAt))
+	notifications = append(notifications, notification.Notification{
+		Title:   "There is a new release " + latest.TagName,
+		Message: fmt.Sprintf("%s created %s ago", latest.Name, timeSinceRelease),
+	})
+
+	setVariables := environToSetVariables(environ)
+	for _, release := range newerReleases {
+		for _, change := range parseReleaseNotes(release.Body) {
+			if !change.relevant(setVariables) {
+				continue
+			}
+			notifications = append(notifications, notification.Notification{
+				Title:     fmt.Sprintf("%s in release %s", change.kind, release.TagName),
+				Message:   change.text,
+				Important: true,
+			})
 		}
-		return fmt.Sprintf("You are running %d %s behind the most recent %s", commitsSince, commits, buildInfo.Version), nil
 	}
-	tagName, name, releaseTime, err := getLatestRelease(ctx, client)
+
+	return notifications, nil
+}
+
+func getLatestCommitMessage(ctx context.Context, client *http.Client,
+	buildInfo models.BuildInformation) (message string, err error) {
+	// Find # of commits between current commit and latest commit
+	commitsSince, err := getCommitsSince(ctx, client, buildInfo.Commit)
 	if err != nil {
 		return "", err
+	} else if commitsSince == 0 {
+		return fmt.Sprintf("You are running on the bleeding edge of %s!", buildInfo.Version), nil
 	}
-	if tagName == buildInfo.Version {
-		return fmt.Sprintf("You are running the latest release %s", buildInfo.Version), nil
+	commits := "commits"
+	if commitsSince == 1 {
+		commits = "commit"
 	}
-	timeSinceRelease := format.FriendlyDuration(time.Since(releaseTime))
-	return fmt.Sprintf("There is a new release %s (%s) created %s ago",
-			tagName, name, timeSinceRelease),
-		nil
+	return fmt.Sprintf("You are running %d %s behind the most recent %s", commitsSince, commits, buildInfo.Version), nil
 }
 
 var errReleaseNotFound = errors.New("release not found")
 
-func getLatestRelease(ctx context.Context, client *http.Client) (tagName, name string, time time.Time, err error) {
-	releases, err := getGithubReleases(ctx, client)
-	if err != nil {
-		return "", "", time, err
-	}
+// getNewerReleases returns the non prerelease releases newer than
+// the current version given, sorted from the newest to the oldest.
+// If the current version is not in the releases given, all of them
+// are considered newer.
+func getNewerReleases(releases []githubRelease, currentVersion string) (
+	newer []githubRelease, err error) {
+	found := false
 	for _, release := range releases {
 		if release.Prerelease {
 			continue
 		}
-		return release.TagName, release.Name, release.PublishedAt, nil
+		found = true
+		if release.TagName == currentVersion {
+			break
+		}
+		newer = append(newer, release)
+	}
+
+	if !found {
+		return nil, errReleaseNotFound
 	}
-	return "", "", time, errReleaseNotFound
+	return newer, nil
 }
 
 var errCommitNotFound = errors.New("commit not found")
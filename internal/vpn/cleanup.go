@@ -16,6 +16,7 @@ func (l *Loop) cleanup(ctx context.Context, pfEnabled bool) {
 	}
 
 	l.publicip.SetData(models.PublicIP{}) // clear public IP address data
+	l.clearConnectedServer()
 
 	if pfEnabled {
 		const pfTimeout = 100 * time.Millisecond
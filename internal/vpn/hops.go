@@ -0,0 +1,206 @@
+package vpn
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/firewall"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/wireguard"
+)
+
+type vpnRunner interface {
+	Run(ctx context.Context, waitError chan<- error, tunnelReady chan<- struct{})
+}
+
+const (
+	// firstHopFirewallMark is the firewall mark and routing table
+	// of the first hop, the next hops using the next numbers.
+	firstHopFirewallMark = constants.FirstHopFirewallMark
+	// firstHopRulePriority is the routing rule priority of the first
+	// hop, the next hops using the priorities below it. The hop rules
+	// come before all other routing rules, since each hop routing table
+	// only contains the route to the next hop or exit VPN server.
+	firstHopRulePriority = 91
+)
+
+// setupHops chains the VPN connection given through the hops of the
+// settings given, if any. The first hop server is reached through the
+// default routes, each next hop server through the previous hop, and the
+// exit VPN server through the last hop, which are allowed in the firewall.
+// It returns a runner running the hops in order before the exit runner
+// given, or the exit runner given if there is no hop.
+func setupHops(ctx context.Context, netlinker NetLinker, fw Firewall,
+	routing Routing, settings settings.VPN, vpnInterface string,
+	connection models.Connection, exitRunner vpnRunner, logger wireguard.Logger) (
+	runner vpnRunner, err error) {
+	if len(settings.Hops) == 0 {
+		// Remove any hop left from previous settings
+		err = fw.SetVPNHopConnections(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("removing hops from firewall: %w", err)
+		}
+		err = routing.SetHopEndpoint(nil)
+		if err != nil {
+			return nil, fmt.Errorf("removing hop endpoint from routing: %w", err)
+		}
+		return exitRunner, nil
+	}
+
+	// nextConnections[i] is the connection going through hop i.
+	nextConnections := make([]models.Connection, len(settings.Hops))
+	for i := range settings.Hops {
+		if i == len(settings.Hops)-1 {
+			nextConnections[i] = connection
+			break
+		}
+		nextConnections[i] = hopConnection(settings.Hops[i+1])
+	}
+
+	hopConnections := make([]firewall.HopConnection, len(settings.Hops))
+	runner = exitRunner
+	for i := len(settings.Hops) - 1; i >= 0; i-- {
+		hop := settings.Hops[i]
+		hopWireguard, err := wireguard.New(makeHopWireguardSettings(hop, i,
+			settings.Wireguard.Implementation, nextConnections[i].IP), netlinker, logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating hop %d Wireguard: %w", i+1, err)
+		}
+		hopConnections[i] = firewall.HopConnection{
+			Connection: nextConnections[i],
+			Interface:  hop.Interface,
+		}
+
+		entryName := "hop " + fmt.Sprint(i+1)
+		if i == 0 {
+			entryName = "entry hop"
+		}
+		runner = &chainedRunner{
+			hop:       hopWireguard,
+			exit:      runner,
+			entryName: entryName,
+			logger:    logger,
+		}
+	}
+
+	entryHop := settings.Hops[0]
+	err = routing.SetHopEndpoint(entryHop.Endpoint.IP)
+	if err != nil {
+		return nil, fmt.Errorf("routing hop endpoint: %w", err)
+	}
+
+	// Only the entry hop server is reachable through the default route,
+	// and the next servers are only reachable through the hop interfaces.
+	err = fw.SetVPNConnection(ctx, hopConnection(entryHop), vpnInterface)
+	if err != nil {
+		return nil, fmt.Errorf("allowing hop connection through firewall: %w", err)
+	}
+	err = fw.SetVPNHopConnections(ctx, hopConnections)
+	if err != nil {
+		return nil, fmt.Errorf("allowing VPN connections through hops in firewall: %w", err)
+	}
+
+	return runner, nil
+}
+
+// makeHopWireguardSettings returns the Wireguard settings of the hop
+// given at the index given, only routing the next IP address given.
+func makeHopWireguardSettings(hop settings.VPNHop, index int,
+	implementation string, nextIP net.IP) (hopSettings wireguard.Settings) {
+	ipv6 := false
+	hopSettings = wireguard.Settings{
+		InterfaceName:  hop.Interface,
+		PrivateKey:     hop.PrivateKey,
+		PublicKey:      hop.PublicKey,
+		PreSharedKey:   hop.PreSharedKey,
+		Endpoint:       &net.UDPAddr{IP: hop.Endpoint.IP, Port: hop.Endpoint.Port},
+		FirewallMark:   firstHopFirewallMark + index,
+		RulePriority:   firstHopRulePriority - index,
+		IPv6:           &ipv6,
+		Implementation: implementation,
+		Routes:         []*net.IPNet{hostIPNet(nextIP)},
+	}
+	for i := range hop.Addresses {
+		if hop.Addresses[i].IP.To4() != nil {
+			hopSettings.Addresses = append(hopSettings.Addresses, &hop.Addresses[i])
+		}
+	}
+	return hopSettings
+}
+
+func hopConnection(hop settings.VPNHop) models.Connection {
+	return models.Connection{
+		Type:     vpn.Wireguard,
+		IP:       hop.Endpoint.IP,
+		Port:     uint16(hop.Endpoint.Port),
+		Protocol: constants.UDP,
+	}
+}
+
+func hostIPNet(ip net.IP) *net.IPNet {
+	if ipv4 := ip.To4(); ipv4 != nil {
+		const bits = 8 * net.IPv4len
+		return &net.IPNet{IP: ipv4, Mask: net.CIDRMask(bits, bits)}
+	}
+	const bits = 8 * net.IPv6len
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+}
+
+// chainedRunner runs the exit VPN runner once the hop runner
+// tunnel is ready, and stops both if either of them fails.
+// The hop runner can be an entry hop or an obfuscation relay,
+// named by entryName in logs and errors.
+type chainedRunner struct {
+	hop       vpnRunner
+	exit      vpnRunner
+	entryName string
+	logger    Infoer
+}
+
+func (c *chainedRunner) Run(ctx context.Context, waitError chan<- error,
+	tunnelReady chan<- struct{}) {
+	hopCtx, hopCancel := context.WithCancel(context.Background())
+	defer hopCancel()
+	hopWaitError := make(chan error)
+	hopReady := make(chan struct{})
+	go c.hop.Run(hopCtx, hopWaitError, hopReady)
+
+	select {
+	case <-ctx.Done():
+		hopCancel()
+		for {
+			select {
+			case <-hopReady: // unblock the hop runner if it just got ready
+			case <-hopWaitError:
+				waitError <- ctx.Err()
+				return
+			}
+		}
+	case err := <-hopWaitError:
+		waitError <- fmt.Errorf("running %s: %w", c.entryName, err)
+		return
+	case <-hopReady:
+	}
+
+	c.logger.Info(c.entryName + " is up, connecting to the VPN server through it")
+
+	exitCtx, exitCancel := context.WithCancel(ctx)
+	defer exitCancel()
+	exitWaitError := make(chan error)
+	go c.exit.Run(exitCtx, exitWaitError, tunnelReady)
+
+	select {
+	case err := <-exitWaitError:
+		hopCancel()
+		<-hopWaitError
+		waitError <- err
+	case err := <-hopWaitError:
+		exitCancel()
+		<-exitWaitError
+		waitError <- fmt.Errorf("running %s: %w", c.entryName, err)
+	}
+}
@@ -0,0 +1,261 @@
+package vpn
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/firewall"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// fakeRunner signals its tunnel is ready unless it has a start
+// error, and then exits with its run error or once its context
+// is canceled.
+type fakeRunner struct {
+	startErr error
+	runErr   <-chan error
+	started  chan struct{}
+}
+
+func (r *fakeRunner) Run(ctx context.Context, waitError chan<- error,
+	tunnelReady chan<- struct{}) {
+	if r.started != nil {
+		close(r.started)
+	}
+	if r.startErr != nil {
+		waitError <- r.startErr
+		return
+	}
+	tunnelReady <- struct{}{}
+	select {
+	case <-ctx.Done():
+		waitError <- ctx.Err()
+	case err := <-r.runErr:
+		waitError <- err
+	}
+}
+
+func Test_chainedRunner_Run(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+
+	t.Run("hop start error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		exit := &fakeRunner{started: make(chan struct{})}
+		runner := &chainedRunner{
+			hop:       &fakeRunner{startErr: errTest},
+			exit:      exit,
+			entryName: "entry hop",
+			logger:    NewMockLogger(ctrl),
+		}
+		waitError := make(chan error)
+
+		go runner.Run(context.Background(), waitError, make(chan struct{}))
+
+		assert.EqualError(t, <-waitError, "running entry hop: test error")
+		select {
+		case <-exit.started:
+			t.Error("exit runner should not be started")
+		default:
+		}
+	})
+
+	t.Run("exit error stops hop", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		logger := NewMockLogger(ctrl)
+		logger.EXPECT().Info("entry hop is up, connecting to the VPN server through it")
+		runner := &chainedRunner{
+			hop:       &fakeRunner{},
+			exit:      &fakeRunner{startErr: errTest},
+			entryName: "entry hop",
+			logger:    logger,
+		}
+		waitError := make(chan error)
+
+		go runner.Run(context.Background(), waitError, make(chan struct{}))
+
+		assert.ErrorIs(t, <-waitError, errTest)
+	})
+
+	t.Run("hop error stops exit", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		logger := NewMockLogger(ctrl)
+		logger.EXPECT().Info("entry hop is up, connecting to the VPN server through it")
+		hopRunErr := make(chan error)
+		runner := &chainedRunner{
+			hop:       &fakeRunner{runErr: hopRunErr},
+			exit:      &fakeRunner{},
+			entryName: "entry hop",
+			logger:    logger,
+		}
+		waitError := make(chan error)
+		tunnelReady := make(chan struct{})
+
+		go runner.Run(context.Background(), waitError, tunnelReady)
+		<-tunnelReady
+		hopRunErr <- errTest
+
+		assert.EqualError(t, <-waitError, "running entry hop: test error")
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		logger := NewMockLogger(ctrl)
+		logger.EXPECT().Info("entry hop is up, connecting to the VPN server through it")
+		ctx, cancel := context.WithCancel(context.Background())
+		runner := &chainedRunner{
+			hop:       &fakeRunner{},
+			exit:      &fakeRunner{},
+			entryName: "entry hop",
+			logger:    logger,
+		}
+		waitError := make(chan error)
+		tunnelReady := make(chan struct{})
+
+		go runner.Run(ctx, waitError, tunnelReady)
+		<-tunnelReady
+		cancel()
+
+		select {
+		case err := <-waitError:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("chained runner did not exit")
+		}
+	})
+}
+
+type hopsFirewall struct {
+	Firewall
+	vpnConnection  models.Connection
+	vpnInterface   string
+	hopConnections []firewall.HopConnection
+}
+
+func (f *hopsFirewall) SetVPNConnection(_ context.Context,
+	connection models.Connection, interfaceName string) error {
+	f.vpnConnection = connection
+	f.vpnInterface = interfaceName
+	return nil
+}
+
+func (f *hopsFirewall) SetVPNHopConnections(_ context.Context,
+	hopConnections []firewall.HopConnection) error {
+	f.hopConnections = hopConnections
+	return nil
+}
+
+type hopsRouting struct {
+	Routing
+	hopEndpoint net.IP
+}
+
+func (r *hopsRouting) SetHopEndpoint(ip net.IP) error {
+	r.hopEndpoint = ip
+	return nil
+}
+
+func Test_setupHops(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	makeHop := func(intf string, endpoint net.IP) settings.VPNHop {
+		privateKey, err := wgtypes.GeneratePrivateKey()
+		require.NoError(t, err)
+		return settings.VPNHop{
+			Interface:  intf,
+			PrivateKey: privateKey.String(),
+			PublicKey:  privateKey.PublicKey().String(),
+			Endpoint:   &net.UDPAddr{IP: endpoint, Port: 51820},
+			Addresses: []net.IPNet{
+				{IP: net.IPv4(10, 0, 0, 2), Mask: net.CIDRMask(32, 32)},
+			},
+		}
+	}
+	vpnSettings := settings.VPN{
+		Hops: []settings.VPNHop{
+			makeHop("hop1", net.IPv4(1, 1, 1, 1)),
+			makeHop("hop2", net.IPv4(2, 2, 2, 2)),
+		},
+	}
+	connection := models.Connection{
+		Type:     vpn.OpenVPN,
+		IP:       net.IPv4(3, 3, 3, 3),
+		Port:     1194,
+		Protocol: constants.UDP,
+	}
+	fw := &hopsFirewall{}
+	routing := &hopsRouting{}
+	exit := &fakeRunner{}
+
+	runner, err := setupHops(context.Background(), nil, fw, routing,
+		vpnSettings, "tun0", connection, exit, NewMockLogger(ctrl))
+	require.NoError(t, err)
+
+	assert.Equal(t, net.IPv4(1, 1, 1, 1), routing.hopEndpoint)
+	assert.Equal(t, models.Connection{
+		Type:     vpn.Wireguard,
+		IP:       net.IPv4(1, 1, 1, 1),
+		Port:     51820,
+		Protocol: constants.UDP,
+	}, fw.vpnConnection)
+	assert.Equal(t, "tun0", fw.vpnInterface)
+	assert.Equal(t, []firewall.HopConnection{
+		{
+			Connection: models.Connection{
+				Type:     vpn.Wireguard,
+				IP:       net.IPv4(2, 2, 2, 2),
+				Port:     51820,
+				Protocol: constants.UDP,
+			},
+			Interface: "hop1",
+		},
+		{Connection: connection, Interface: "hop2"},
+	}, fw.hopConnections)
+
+	entry, ok := runner.(*chainedRunner)
+	require.True(t, ok)
+	assert.Equal(t, "entry hop", entry.entryName)
+	second, ok := entry.exit.(*chainedRunner)
+	require.True(t, ok)
+	assert.Equal(t, "hop 2", second.entryName)
+	assert.Same(t, exit, second.exit)
+}
+
+func Test_makeHopWireguardSettings(t *testing.T) {
+	t.Parallel()
+
+	hop := settings.VPNHop{
+		Interface: "hop2",
+		Endpoint:  &net.UDPAddr{IP: net.IPv4(2, 2, 2, 2), Port: 51820},
+		Addresses: []net.IPNet{
+			{IP: net.IPv4(10, 0, 0, 2), Mask: net.CIDRMask(32, 32)},
+			{IP: net.ParseIP("fd00::2"), Mask: net.CIDRMask(128, 128)},
+		},
+	}
+
+	hopSettings := makeHopWireguardSettings(hop, 1, "auto", net.IPv4(3, 3, 3, 3))
+
+	assert.Equal(t, "hop2", hopSettings.InterfaceName)
+	assert.Equal(t, 51812, hopSettings.FirewallMark)
+	assert.Equal(t, 90, hopSettings.RulePriority)
+	assert.Equal(t, []*net.IPNet{
+		{IP: net.IPv4(3, 3, 3, 3).To4(), Mask: net.CIDRMask(32, 32)},
+	}, hopSettings.Routes)
+	assert.Equal(t, []*net.IPNet{&hop.Addresses[0]}, hopSettings.Addresses)
+}
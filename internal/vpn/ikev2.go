@@ -0,0 +1,40 @@
+package vpn
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/ikev2"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/golibs/command"
+)
+
+// ikev2Port is the IKEv2 server UDP port used for both
+// IKE and the ESP traffic always encapsulated in UDP.
+const ikev2Port = 4500
+
+// setupIKEv2 sets the IKEv2 connection up using the settings given.
+// It returns the connection used and an error if it fails.
+func setupIKEv2(ctx context.Context, netLinker NetLinker, fw Firewall,
+	settings settings.VPN, cmder command.RunStarter, logger ikev2.Logger) (
+	runner *ikev2.Runner, connection models.Connection, err error) {
+	connection = models.Connection{
+		Type:     vpn.IKEv2,
+		IP:       net.ParseIP(settings.IKEv2.Server),
+		Port:     ikev2Port,
+		Protocol: constants.UDP,
+		Hostname: *settings.IKEv2.RemoteID,
+	}
+
+	err = fw.SetVPNConnection(ctx, connection, settings.IKEv2.Interface)
+	if err != nil {
+		return nil, connection, fmt.Errorf("allowing VPN connection through firewall: %w", err)
+	}
+
+	runner = ikev2.New(settings.IKEv2, netLinker, cmder, logger)
+	return runner, connection, nil
+}
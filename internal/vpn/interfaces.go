@@ -3,22 +3,31 @@ package vpn
 import (
 	"context"
 	"net"
+	"time"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/firewall"
 	"github.com/qdm12/gluetun/internal/models"
 	"github.com/qdm12/gluetun/internal/netlink"
+	"github.com/qdm12/gluetun/internal/notification"
 	"github.com/qdm12/gluetun/internal/portforward"
 	"github.com/qdm12/gluetun/internal/provider"
+	"github.com/qdm12/gluetun/internal/routing"
 )
 
 type Firewall interface {
 	SetVPNConnection(ctx context.Context, connection models.Connection, interfaceName string) error
+	SetVPNHopConnections(ctx context.Context, hopConnections []firewall.HopConnection) error
 	SetAllowedPort(ctx context.Context, port uint16, interfaceName string) error
 	RemoveAllowedPort(ctx context.Context, port uint16) error
+	SetTCPRedirect(ctx context.Context, port uint16, excludedIP net.IP) error
+	SetTunnelUp(ctx context.Context) error
 }
 
 type Routing interface {
 	VPNLocalGatewayIP(vpnInterface string) (gateway net.IP, err error)
+	SetHopEndpoint(ip net.IP) (err error)
+	SetStaticRoutes(routes []routing.StaticRoute) (err error)
 }
 
 type PortForward interface {
@@ -29,9 +38,16 @@ type PortForward interface {
 type OpenVPN interface {
 	WriteConfig(lines []string) error
 	WriteAuthFile(user, password string) error
+	WriteProxyAuthFile(user, password string) error
 	WriteAskPassFile(passphrase string) error
 }
 
+type ServerStats interface {
+	Attempt(hostname string)
+	Success(hostname string, handshake time.Duration)
+	Failure(hostname string)
+}
+
 type Providers interface {
 	Get(providerName string) provider.Provider
 }
@@ -80,3 +96,19 @@ type PublicIPLoop interface {
 		outcome string, err error)
 	SetData(data models.PublicIP)
 }
+
+type Dependents interface {
+	OnTunnelUp(ctx context.Context)
+}
+
+type Hooks interface {
+	Run(ctx context.Context, stage string) (err error)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, notification notification.Notification)
+}
+
+type Infoer interface {
+	Info(s string)
+}
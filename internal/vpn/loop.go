@@ -31,16 +31,22 @@ type Loop struct {
 	portForward PortForward
 	publicip    PublicIPLoop
 	dnsLooper   DNSLoop
+	dependents  Dependents
+	hooks       Hooks
+	notifier    Notifier
+	serverStats ServerStats
 	// Other objects
-	starter command.Starter // for OpenVPN
-	logger  log.LoggerInterface
-	client  *http.Client
+	cmder  command.RunStarter // for OpenVPN, OpenConnect and IKEv2
+	logger log.LoggerInterface
+	client *http.Client
 	// Internal channels and values
 	stop        <-chan struct{}
 	stopped     chan<- struct{}
 	start       <-chan struct{}
 	running     chan<- models.LoopStatus
 	userTrigger bool
+	// Connected server information
+	connectedServer connectedServer
 	// Internal constant values
 	backoffTime time.Duration
 }
@@ -52,8 +58,9 @@ const (
 func NewLoop(vpnSettings settings.VPN, ipv6Supported bool, vpnInputPorts []uint16,
 	providers Providers, storage Storage, openvpnConf OpenVPN,
 	netLinker NetLinker, fw Firewall, routing Routing,
-	portForward PortForward, starter command.Starter,
-	publicip PublicIPLoop, dnsLooper DNSLoop,
+	portForward PortForward, cmder command.RunStarter,
+	publicip PublicIPLoop, dnsLooper DNSLoop, dependents Dependents,
+	hooks Hooks, notifier Notifier, serverStats ServerStats,
 	logger log.LoggerInterface, client *http.Client,
 	buildInfo models.BuildInformation, versionInfo bool) *Loop {
 	start := make(chan struct{})
@@ -80,7 +87,11 @@ func NewLoop(vpnSettings settings.VPN, ipv6Supported bool, vpnInputPorts []uint1
 		portForward:   portForward,
 		publicip:      publicip,
 		dnsLooper:     dnsLooper,
-		starter:       starter,
+		dependents:    dependents,
+		hooks:         hooks,
+		notifier:      notifier,
+		serverStats:   serverStats,
+		cmder:         cmder,
 		logger:        logger,
 		client:        client,
 		start:         start,
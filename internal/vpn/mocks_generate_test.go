@@ -0,0 +1,3 @@
+package vpn
+
+//go:generate mockgen -destination=mocks_test.go -package=$GOPACKAGE github.com/qdm12/gluetun/internal/wireguard Logger
@@ -0,0 +1,36 @@
+package vpn
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/openconnect"
+	"github.com/qdm12/golibs/command"
+)
+
+// setupOpenConnect sets OpenConnect up using the settings given.
+// It returns the connection used and an error if it fails.
+func setupOpenConnect(ctx context.Context, fw Firewall,
+	settings settings.VPN, starter command.Starter, logger openconnect.Logger) (
+	runner *openconnect.Runner, connection models.Connection, err error) {
+	connection = models.Connection{
+		Type:     vpn.OpenConnect,
+		IP:       net.ParseIP(settings.OpenConnect.Server),
+		Port:     *settings.OpenConnect.Port,
+		Protocol: constants.TCP,
+		Hostname: *settings.OpenConnect.Hostname,
+	}
+
+	err = fw.SetVPNConnection(ctx, connection, settings.OpenConnect.Interface)
+	if err != nil {
+		return nil, connection, fmt.Errorf("allowing VPN connection through firewall: %w", err)
+	}
+
+	runner = openconnect.NewRunner(settings.OpenConnect, starter, logger)
+	return runner, connection, nil
+}
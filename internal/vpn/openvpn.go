@@ -2,50 +2,168 @@ package vpn
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"strings"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/models"
 	"github.com/qdm12/gluetun/internal/openvpn"
+	"github.com/qdm12/gluetun/internal/openvpn/tlstunnel"
 	"github.com/qdm12/gluetun/internal/provider"
 	"github.com/qdm12/golibs/command"
 )
 
 // setupOpenVPN sets OpenVPN up using the configurators and settings given.
-// It returns a serverName for port forwarding (PIA) and an error if it fails.
+// It returns the connection used and an error if it fails.
 func setupOpenVPN(ctx context.Context, fw Firewall,
 	openvpnConf OpenVPN, providerConf provider.Provider,
 	settings settings.VPN, ipv6Supported bool, starter command.Starter,
-	logger openvpn.Logger) (runner *openvpn.Runner, serverName string, err error) {
-	connection, err := providerConf.GetConnection(settings.Provider.ServerSelection, ipv6Supported)
+	logger openvpn.Logger) (runner vpnRunner, connection models.Connection, err error) {
+	connection, err = providerConf.GetConnection(settings.Provider.ServerSelection, ipv6Supported)
 	if err != nil {
-		return nil, "", fmt.Errorf("finding a valid server connection: %w", err)
+		return nil, connection, fmt.Errorf("finding a valid server connection: %w", err)
 	}
 
-	lines := providerConf.OpenVPNConfig(connection, settings.OpenVPN, ipv6Supported)
+	obfuscation := settings.OpenVPN.Obfuscation
+	configConnection := connection
+	firewallConnection := connection
+	var tunnel *tlstunnel.Tunnel
+	if obfuscation.Enabled() {
+		tunnel, configConnection, firewallConnection, err = setupOpenVPNObfuscation(
+			connection, obfuscation, logger)
+		if err != nil {
+			return nil, connection, fmt.Errorf("setting up obfuscation: %w", err)
+		}
+	}
+
+	lines, err := providerConf.OpenVPNConfig(configConnection, settings.OpenVPN, ipv6Supported)
+	if err != nil {
+		return nil, connection, fmt.Errorf("building OpenVPN configuration: %w", err)
+	}
+	if tunnel != nil {
+		// OpenVPN only routes its remote loopback address outside the
+		// tunnel, so route the VPN server the TLS tunnel connects to.
+		lines = append(lines, "route "+connection.IP.String()+" 255.255.255.255 net_gateway")
+	}
+	if len(settings.Routes) > 0 {
+		lines = routeOnly(lines, settings.Routes)
+	}
+
+	if *settings.Upstream.HTTPProxy != "" {
+		lines, firewallConnection, err = useUpstreamProxy(lines,
+			connection, settings.Upstream, openvpnConf)
+		if err != nil {
+			return nil, connection, fmt.Errorf("using upstream gluetun: %w", err)
+		}
+	}
 
 	if err := openvpnConf.WriteConfig(lines); err != nil {
-		return nil, "", fmt.Errorf("writing configuration to file: %w", err)
+		return nil, connection, fmt.Errorf("writing configuration to file: %w", err)
 	}
 
 	if *settings.OpenVPN.User != "" {
 		err := openvpnConf.WriteAuthFile(*settings.OpenVPN.User, *settings.OpenVPN.Password)
 		if err != nil {
-			return nil, "", fmt.Errorf("writing auth to file: %w", err)
+			return nil, connection, fmt.Errorf("writing auth to file: %w", err)
 		}
 	}
 
 	if *settings.OpenVPN.KeyPassphrase != "" {
 		err := openvpnConf.WriteAskPassFile(*settings.OpenVPN.KeyPassphrase)
 		if err != nil {
-			return nil, "", fmt.Errorf("writing askpass file: %w", err)
+			return nil, connection, fmt.Errorf("writing askpass file: %w", err)
 		}
 	}
 
-	if err := fw.SetVPNConnection(ctx, connection, settings.OpenVPN.Interface); err != nil {
-		return nil, "", fmt.Errorf("allowing VPN connection through firewall: %w", err)
+	if err := fw.SetVPNConnection(ctx, firewallConnection, settings.OpenVPN.Interface); err != nil {
+		return nil, connection, fmt.Errorf("allowing VPN connection through firewall: %w", err)
 	}
 
 	runner = openvpn.NewRunner(settings.OpenVPN, starter, logger)
+	if tunnel == nil {
+		return runner, connection, nil
+	}
+	return &chainedRunner{
+		hop:       tunnel,
+		exit:      runner,
+		entryName: "TLS tunnel",
+		logger:    logger,
+	}, connection, nil
+}
+
+// routeOnly modifies the OpenVPN configuration lines given such that
+// only the routes given go through the tunnel, ignoring the gateway
+// redirection and the routes pushed by the server.
+func routeOnly(lines []string, routes []net.IPNet) (modified []string) {
+	const extraLines = 3
+	modified = make([]string, 0, len(lines)+extraLines+len(routes))
+	for _, line := range lines {
+		if strings.HasPrefix(line, "redirect-gateway") {
+			continue
+		}
+		modified = append(modified, line)
+	}
+
+	modified = append(modified,
+		`pull-filter ignore "redirect-gateway"`,
+		`pull-filter ignore "route "`,
+		`pull-filter ignore "route-ipv6 "`,
+	)
+	for _, route := range routes {
+		if route.IP.To4() != nil {
+			modified = append(modified,
+				"route "+route.IP.String()+" "+net.IP(route.Mask).String())
+		} else {
+			modified = append(modified, "route-ipv6 "+route.String())
+		}
+	}
+	return modified
+}
+
+// tlsTunnelLocalPort is the loopback port OpenVPN connects
+// to when its connection is tunneled inside TLS.
+const tlsTunnelLocalPort uint16 = 1195
+
+var (
+	ErrObfuscationProtocolNotTCP = errors.New("obfuscation requires the OpenVPN TCP protocol")
+	ErrObfuscationServerNotIPv4  = errors.New("obfuscation requires an IPv4 VPN server address")
+)
+
+// setupOpenVPNObfuscation creates a TLS tunnel to the VPN server of
+// the connection given. It returns the connection OpenVPN should use
+// to connect through the tunnel, and the connection to the TLS port
+// of the VPN server to allow in the firewall.
+func setupOpenVPNObfuscation(connection models.Connection,
+	obfuscation settings.OpenVPNObfuscation, logger tlstunnel.Logger) (
+	tunnel *tlstunnel.Tunnel, configConnection, firewallConnection models.Connection,
+	err error) {
+	// The protocol is validated in the settings except for the custom
+	// provider, whose protocol comes from its configuration file.
+	if connection.Protocol != constants.TCP {
+		return nil, connection, connection, fmt.Errorf("%w: protocol is %s",
+			ErrObfuscationProtocolNotTCP, connection.Protocol)
+	} else if connection.IP.To4() == nil {
+		return nil, connection, connection, fmt.Errorf("%w: %s",
+			ErrObfuscationServerNotIPv4, connection.IP)
+	}
+
+	serverName := *obfuscation.ServerName
+	if serverName == "" {
+		serverName = connection.Hostname
+	}
+	server := net.JoinHostPort(connection.IP.String(), fmt.Sprint(*obfuscation.Port))
+	tunnel = tlstunnel.New(server, serverName, *obfuscation.SkipVerify,
+		tlsTunnelLocalPort, logger)
+
+	configConnection = connection
+	configConnection.IP = net.IPv4(127, 0, 0, 1) //nolint:gomnd
+	configConnection.Port = tlsTunnelLocalPort
+
+	firewallConnection = connection
+	firewallConnection.Port = *obfuscation.Port
 
-	return runner, connection.ServerName, nil
+	return tunnel, configConnection, firewallConnection, nil
 }
@@ -0,0 +1,93 @@
+package vpn
+
+import (
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_setupOpenVPNObfuscation(t *testing.T) {
+	t.Parallel()
+
+	port := uint16(443)
+	empty := ""
+	skipVerify := false
+	obfuscation := settings.OpenVPNObfuscation{
+		Type:       "tls",
+		Port:       &port,
+		ServerName: &empty,
+		SkipVerify: &skipVerify,
+	}
+
+	t.Run("UDP protocol", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		connection := models.Connection{IP: net.IP{1, 2, 3, 4}, Port: 1194, Protocol: constants.UDP}
+
+		_, _, _, err := setupOpenVPNObfuscation(connection, obfuscation, NewMockLogger(ctrl))
+
+		assert.ErrorIs(t, err, ErrObfuscationProtocolNotTCP)
+	})
+
+	t.Run("TCP protocol", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		connection := models.Connection{
+			IP:       net.IP{1, 2, 3, 4},
+			Port:     1194,
+			Protocol: constants.TCP,
+			Hostname: "vpn.example.com",
+		}
+
+		tunnel, configConnection, firewallConnection, err :=
+			setupOpenVPNObfuscation(connection, obfuscation, NewMockLogger(ctrl))
+
+		require.NoError(t, err)
+		assert.NotNil(t, tunnel)
+		assert.Equal(t, models.Connection{
+			IP:       net.IPv4(127, 0, 0, 1),
+			Port:     tlsTunnelLocalPort,
+			Protocol: constants.TCP,
+			Hostname: "vpn.example.com",
+		}, configConnection)
+		assert.Equal(t, models.Connection{
+			IP:       net.IP{1, 2, 3, 4},
+			Port:     443,
+			Protocol: constants.TCP,
+			Hostname: "vpn.example.com",
+		}, firewallConnection)
+	})
+}
+
+func Test_routeOnly(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{
+		"client",
+		"redirect-gateway def1",
+		"remote 1.2.3.4 1194",
+	}
+	routes := []net.IPNet{
+		{IP: net.IP{10, 8, 0, 0}, Mask: net.CIDRMask(16, 32)},
+		{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(32, 128)},
+	}
+
+	modified := routeOnly(lines, routes)
+
+	expected := []string{
+		"client",
+		"remote 1.2.3.4 1194",
+		`pull-filter ignore "redirect-gateway"`,
+		`pull-filter ignore "route "`,
+		`pull-filter ignore "route-ipv6 "`,
+		"route 10.8.0.0 255.255.0.0",
+		"route-ipv6 2001:db8::/32",
+	}
+	assert.Equal(t, expected, modified)
+}
@@ -13,7 +13,7 @@ func (l *Loop) startPortForwarding(ctx context.Context, data tunnelUpData) (err
 		return nil
 	}
 
-	// only used for PIA for now
+	// only used for PIA and NAT-PMP for now
 	gateway, err := l.routing.VPNLocalGatewayIP(data.vpnIntf)
 	if err != nil {
 		return fmt.Errorf("obtaining VPN local gateway IP for interface %s: %w", data.vpnIntf, err)
@@ -2,9 +2,16 @@ package vpn
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/qdm12/gluetun/internal/constants"
 	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/natpmp"
+	"github.com/qdm12/gluetun/internal/provider"
+	"github.com/qdm12/gluetun/internal/serverstats"
+	"github.com/qdm12/gluetun/internal/vpnlog"
 	"github.com/qdm12/log"
 )
 
@@ -23,40 +30,75 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 		providerConf := l.providers.Get(*settings.Provider.Name)
 
 		portForwarding := *settings.Provider.PortForwarding.Enabled
-		var vpnRunner interface {
-			Run(ctx context.Context, waitError chan<- error, tunnelReady chan<- struct{})
+		var portForwarder provider.PortForwarder = providerConf
+		if *settings.Provider.PortForwarding.Provider == constants.PortForwardingNATPMP {
+			portForwarder = natpmp.NewPortForwarder()
 		}
-		var serverName, vpnInterface string
-		var err error
+		var vpnRunner vpnRunner
+		var vpnInterface string
+		var connection models.Connection
 		subLogger := l.logger.New(log.SetComponent(settings.Type))
-		if settings.Type == vpn.OpenVPN {
+		vpnLogger, err := vpnlog.New(subLogger, settings.LogRules)
+		if err != nil {
+			l.crashed(ctx, fmt.Errorf("creating VPN logger: %w", err))
+			continue
+		}
+		switch settings.Type {
+		case vpn.OpenVPN:
 			vpnInterface = settings.OpenVPN.Interface
-			vpnRunner, serverName, err = setupOpenVPN(ctx, l.fw,
-				l.openvpnConf, providerConf, settings, l.ipv6Supported, l.starter, subLogger)
-		} else { // Wireguard
+			vpnRunner, connection, err = setupOpenVPN(ctx, l.fw,
+				l.openvpnConf, providerConf, settings, l.ipv6Supported, l.cmder, vpnLogger)
+		case vpn.Shadowsocks:
+			vpnInterface = shadowsocksInterface
+			vpnRunner, connection, err = setupShadowsocks(ctx, l.fw, settings, subLogger)
+		case vpn.IKEv2:
+			vpnInterface = settings.IKEv2.Interface
+			vpnRunner, connection, err = setupIKEv2(ctx, l.netLinker, l.fw,
+				settings, l.cmder, vpnLogger)
+		case vpn.OpenConnect:
+			vpnInterface = settings.OpenConnect.Interface
+			vpnRunner, connection, err = setupOpenConnect(ctx, l.fw,
+				settings, l.cmder, vpnLogger)
+		default: // Wireguard
 			vpnInterface = settings.Wireguard.Interface
-			vpnRunner, serverName, err = setupWireguard(ctx, l.netLinker, l.fw,
-				providerConf, settings, l.ipv6Supported, subLogger)
+			vpnRunner, connection, err = setupWireguard(ctx, l.netLinker, l.fw,
+				providerConf, settings, l.ipv6Supported, vpnLogger)
 		}
 		if err != nil {
 			l.crashed(ctx, err)
 			continue
 		}
+		vpnRunner, err = setupHops(ctx, l.netLinker, l.fw, l.routing,
+			settings, vpnInterface, connection, vpnRunner, vpnLogger)
+		if err != nil {
+			l.crashed(ctx, fmt.Errorf("setting up entry hop: %w", err))
+			continue
+		}
+		l.setConnectedServer(settings, connection)
 		tunnelUpData := tunnelUpData{
 			portForwarding: portForwarding,
-			serverName:     serverName,
-			portForwarder:  providerConf,
+			serverName:     connection.ServerName,
+			portForwarder:  portForwarder,
 			vpnIntf:        vpnInterface,
+			staticRoutes:   makeStaticRoutes(settings.StaticRoutes),
 		}
 
 		openvpnCtx, openvpnCancel := context.WithCancel(context.Background())
 		waitError := make(chan error)
 		tunnelReady := make(chan struct{})
 
+		statsKey := serverStatsKey(connection)
+		startTime := time.Now()
+		l.serverStats.Attempt(statsKey)
+		tunnelUp := false
+
 		go vpnRunner.Run(openvpnCtx, waitError, tunnelReady)
 
 		if err := l.waitForError(ctx, waitError); err != nil {
 			openvpnCancel()
+			if ctx.Err() == nil {
+				l.serverStats.Failure(statsKey)
+			}
 			l.crashed(ctx, err)
 			continue
 		}
@@ -68,6 +110,10 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 		for stayHere {
 			select {
 			case <-tunnelReady:
+				if !tunnelUp {
+					tunnelUp = true
+					l.serverStats.Success(statsKey, time.Since(startTime))
+				}
 				go l.onTunnelUp(openvpnCtx, tunnelUpData)
 			case <-ctx.Done():
 				l.cleanup(context.Background(), portForwarding)
@@ -89,6 +135,11 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 				l.logger.Info("starting")
 				stayHere = false
 			case err := <-waitError: // unexpected error
+				// Only tunnel errors before the tunnel is up are server
+				// failures, and not the user stopping or restarting it.
+				if !tunnelUp {
+					l.serverStats.Failure(statsKey)
+				}
 				l.statusManager.Lock() // prevent SetStatus from running in parallel
 
 				l.cleanup(context.Background(), portForwarding)
@@ -103,3 +154,9 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 		openvpnCancel()
 	}
 }
+
+// serverStatsKey returns the key to record connection statistics
+// for the connection given.
+func serverStatsKey(connection models.Connection) string {
+	return serverstats.Key(connection.Hostname, connection.IP)
+}
@@ -0,0 +1,171 @@
+package vpn
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+type connectedServer struct {
+	server  *models.ConnectedServer
+	gateway net.IP
+	mutex   sync.RWMutex
+}
+
+// GetConnectedServer returns information on the server the
+// VPN is connected to, and false if the VPN is not connected.
+func (l *Loop) GetConnectedServer() (server models.ConnectedServer, ok bool) {
+	l.connectedServer.mutex.RLock()
+	defer l.connectedServer.mutex.RUnlock()
+	if l.connectedServer.server == nil {
+		return server, false
+	}
+	server = *l.connectedServer.server
+	server.IP = append(server.IP[:0:0], server.IP...)
+	return server, true
+}
+
+func (l *Loop) setConnectedServer(settings settings.VPN, connection models.Connection) {
+	providerName := *settings.Provider.Name
+	switch settings.Type {
+	case vpn.Shadowsocks, vpn.IKEv2, vpn.OpenConnect:
+		// The provider settings are not used for these VPN types.
+		providerName = providers.Custom
+	}
+	server := &models.ConnectedServer{
+		Provider:        providerName,
+		VPN:             connection.Type,
+		ServerName:      connection.ServerName,
+		Hostname:        connection.Hostname,
+		IP:              connection.IP,
+		Port:            connection.Port,
+		Protocol:        connection.Protocol,
+		SelectionReason: selectionReason(providerName, settings.Provider.ServerSelection),
+	}
+
+	switch providerName {
+	case providers.CloudflareWARP, providers.Custom, providers.Plugin:
+		// no servers data to find the server information from
+	default:
+		servers, err := l.storage.FilterServers(providerName, settings.Provider.ServerSelection)
+		if err != nil {
+			l.logger.Debug("cannot find connected server information: " + err.Error())
+		}
+		for _, candidate := range servers {
+			if matchesConnection(candidate, connection) {
+				server.Country = candidate.Country
+				server.Region = candidate.Region
+				server.City = candidate.City
+				server.ISP = candidate.ISP
+				break
+			}
+		}
+	}
+
+	l.connectedServer.mutex.Lock()
+	defer l.connectedServer.mutex.Unlock()
+	l.connectedServer.server = server
+}
+
+func (l *Loop) setConnectedAt(t time.Time) {
+	l.connectedServer.mutex.Lock()
+	defer l.connectedServer.mutex.Unlock()
+	if l.connectedServer.server != nil {
+		l.connectedServer.server.ConnectedAt = t
+	}
+}
+
+// GetVPNGateway returns the local gateway IP address of the VPN
+// interface, and false if the VPN is not connected or if its
+// interface has no gateway, which is the case for Wireguard.
+func (l *Loop) GetVPNGateway() (gateway net.IP, ok bool) {
+	l.connectedServer.mutex.RLock()
+	defer l.connectedServer.mutex.RUnlock()
+	if l.connectedServer.gateway == nil {
+		return nil, false
+	}
+	return append(gateway, l.connectedServer.gateway...), true
+}
+
+func (l *Loop) setGateway(gateway net.IP) {
+	l.connectedServer.mutex.Lock()
+	defer l.connectedServer.mutex.Unlock()
+	l.connectedServer.gateway = gateway
+}
+
+func (l *Loop) clearConnectedServer() {
+	l.connectedServer.mutex.Lock()
+	defer l.connectedServer.mutex.Unlock()
+	l.connectedServer.server = nil
+	l.connectedServer.gateway = nil
+}
+
+// matchesConnection returns true if the connection was built from the
+// server given. The target IP address can override the server IP address,
+// so the hostname and server name are also compared.
+func matchesConnection(server models.Server, connection models.Connection) bool {
+	for _, ip := range server.IPs {
+		if ip.Equal(connection.IP) {
+			return true
+		}
+	}
+	return server.ServerName == connection.ServerName &&
+		(server.Hostname == connection.Hostname || server.OvpnX509 == connection.Hostname)
+}
+
+func selectionReason(providerName string, selection settings.ServerSelection) string {
+	if providerName == providers.Custom {
+		return "custom " + selection.VPN + " configuration"
+	}
+
+	var filters []string
+	appendFilter := func(name string, values []string) {
+		if len(values) > 0 {
+			filters = append(filters, name+" "+strings.Join(values, ", "))
+		}
+	}
+	appendFilter("countries", selection.Countries)
+	appendFilter("regions", selection.Regions)
+	appendFilter("cities", selection.Cities)
+	appendFilter("ISPs", selection.ISPs)
+	appendFilter("names", selection.Names)
+	appendFilter("hostnames", selection.Hostnames)
+	if len(selection.Numbers) > 0 {
+		numbers := make([]string, len(selection.Numbers))
+		for i, number := range selection.Numbers {
+			numbers[i] = fmt.Sprint(number)
+		}
+		appendFilter("numbers", numbers)
+	}
+	boolFilters := []struct {
+		name  string
+		value *bool
+	}{
+		{name: "owned only", value: selection.OwnedOnly},
+		{name: "free only", value: selection.FreeOnly},
+		{name: "premium only", value: selection.PremiumOnly},
+		{name: "stream only", value: selection.StreamOnly},
+		{name: "multi hop only", value: selection.MultiHopOnly},
+	}
+	for _, boolFilter := range boolFilters {
+		if boolFilter.value != nil && *boolFilter.value {
+			filters = append(filters, boolFilter.name)
+		}
+	}
+
+	reason := "random server"
+	if len(filters) > 0 {
+		reason += " matching " + strings.Join(filters, "; ")
+	}
+	if len(selection.TargetIP) > 0 {
+		reason += " with target IP address " + selection.TargetIP.String()
+	}
+	return reason
+}
@@ -0,0 +1,53 @@
+package vpn
+
+import (
+	"net"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_selectionReason(t *testing.T) {
+	t.Parallel()
+
+	ownedOnly := true
+
+	testCases := map[string]struct {
+		providerName string
+		selection    settings.ServerSelection
+		reason       string
+	}{
+		"custom provider": {
+			providerName: "custom",
+			selection:    settings.ServerSelection{VPN: "wireguard"},
+			reason:       "custom wireguard configuration",
+		},
+		"no filter": {
+			providerName: "mullvad",
+			reason:       "random server",
+		},
+		"filters and target IP": {
+			providerName: "mullvad",
+			selection: settings.ServerSelection{
+				TargetIP:  net.IPv4(1, 2, 3, 4),
+				Countries: []string{"sweden", "norway"},
+				Numbers:   []uint16{1, 2},
+				OwnedOnly: &ownedOnly,
+			},
+			reason: "random server matching countries sweden, norway; " +
+				"numbers 1, 2; owned only with target IP address 1.2.3.4",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			reason := selectionReason(testCase.providerName, testCase.selection)
+
+			assert.Equal(t, testCase.reason, reason)
+		})
+	}
+}
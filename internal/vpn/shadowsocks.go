@@ -0,0 +1,52 @@
+package vpn
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/shadowsocks/redir"
+)
+
+// shadowsocksInterface is the interface the traffic relayed
+// through the Shadowsocks server goes through.
+const shadowsocksInterface = "lo"
+
+// setupShadowsocks sets the Shadowsocks client up using the
+// settings given. It returns the connection used and an error if it fails.
+func setupShadowsocks(ctx context.Context, fw Firewall,
+	settings settings.VPN, logger redir.Logger) (
+	redirector *redir.Redirector, connection models.Connection, err error) {
+	host, portString, err := net.SplitHostPort(settings.Shadowsocks.Server)
+	if err != nil {
+		return nil, connection, fmt.Errorf("parsing server address: %w", err)
+	}
+	const base, bitSize = 10, 16
+	port, err := strconv.ParseUint(portString, base, bitSize)
+	if err != nil {
+		return nil, connection, fmt.Errorf("parsing server port: %w", err)
+	}
+	connection = models.Connection{
+		Type:     vpn.Shadowsocks,
+		IP:       net.ParseIP(host),
+		Port:     uint16(port),
+		Protocol: constants.TCP,
+	}
+
+	redirector, err = redir.New(settings.Shadowsocks, fw, logger)
+	if err != nil {
+		return nil, connection, fmt.Errorf("creating Shadowsocks client: %w", err)
+	}
+
+	err = fw.SetVPNConnection(ctx, connection, shadowsocksInterface)
+	if err != nil {
+		return nil, connection, fmt.Errorf("setting firewall: %w", err)
+	}
+
+	return redirector, connection, nil
+}
@@ -2,9 +2,13 @@ package vpn
 
 import (
 	"context"
+	"os"
+	"time"
 
+	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/constants"
 	"github.com/qdm12/gluetun/internal/provider"
+	"github.com/qdm12/gluetun/internal/routing"
 	"github.com/qdm12/gluetun/internal/version"
 )
 
@@ -14,10 +18,43 @@ type tunnelUpData struct {
 	vpnIntf        string
 	serverName     string
 	portForwarder  provider.PortForwarder
+	// Static routes
+	staticRoutes []routing.StaticRoute
+}
+
+func makeStaticRoutes(staticRoutes []settings.StaticRoute) (routes []routing.StaticRoute) {
+	routes = make([]routing.StaticRoute, len(staticRoutes))
+	for i, staticRoute := range staticRoutes {
+		routes[i] = routing.StaticRoute{
+			Destination: staticRoute.Destination,
+			Gateway:     staticRoute.Gateway,
+			Interface:   staticRoute.Interface,
+		}
+	}
+	return routes
 }
 
 func (l *Loop) onTunnelUp(ctx context.Context, data tunnelUpData) {
 	l.client.CloseIdleConnections()
+	l.setConnectedAt(time.Now())
+
+	gateway, err := l.routing.VPNLocalGatewayIP(data.vpnIntf)
+	if err != nil {
+		l.logger.Debug("cannot find VPN gateway: " + err.Error())
+	}
+	l.setGateway(gateway)
+
+	err = l.fw.SetTunnelUp(ctx)
+	if err != nil {
+		l.logger.Error("cannot relax pre-tunnel firewall restrictions: " + err.Error())
+	}
+
+	// Static routes through the VPN interface are removed with it,
+	// so they are set again each time the tunnel is up.
+	err = l.routing.SetStaticRoutes(data.staticRoutes)
+	if err != nil {
+		l.logger.Error("cannot set static routes: " + err.Error())
+	}
 
 	for _, vpnPort := range l.vpnInputPorts {
 		err := l.fw.SetAllowedPort(ctx, vpnPort, data.vpnIntf)
@@ -34,16 +71,28 @@ func (l *Loop) onTunnelUp(ctx context.Context, data tunnelUpData) {
 	_, _ = l.publicip.ApplyStatus(ctx, constants.Running)
 	if l.versionInfo {
 		l.versionInfo = false // only get the version information once
-		message, err := version.GetMessage(ctx, l.buildInfo, l.client)
+		notifications, err := version.GetNotifications(ctx, l.buildInfo, l.client, os.Environ())
 		if err != nil {
 			l.logger.Error("cannot get version information: " + err.Error())
-		} else {
-			l.logger.Info(message)
+		}
+		for _, notification := range notifications {
+			l.notifier.Notify(ctx, notification)
 		}
 	}
 
-	err := l.startPortForwarding(ctx, data)
+	err = l.startPortForwarding(ctx, data)
 	if err != nil {
 		l.logger.Error(err.Error())
 	}
+
+	err = l.hooks.Run(ctx, constants.HookPostTunnelUp)
+	if err != nil {
+		// Stop the VPN so no traffic goes through a tunnel
+		// missing the customizations of the failed hook.
+		l.logger.Error(err.Error() + ": stopping VPN")
+		_, _ = l.ApplyStatus(context.Background(), constants.Stopped)
+		return
+	}
+
+	l.dependents.OnTunnelUp(ctx)
 }
@@ -0,0 +1,46 @@
+package vpn
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/openvpn"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+// useUpstreamProxy returns the OpenVPN configuration lines given with
+// the option to connect through the HTTP proxy of the upstream gluetun,
+// and the connection to the proxy to allow through the firewall instead
+// of the connection to the VPN server.
+func useUpstreamProxy(lines []string, connection models.Connection,
+	upstream settings.Upstream, openvpnConf OpenVPN) (
+	proxyLines []string, proxyConnection models.Connection, err error) {
+	// The address is validated in the settings.
+	host, portString, _ := net.SplitHostPort(*upstream.HTTPProxy)
+	const base, bitSize = 10, 16
+	port, _ := strconv.ParseUint(portString, base, bitSize)
+
+	option := "http-proxy " + host + " " + portString
+	if *upstream.HTTPProxyUser != "" {
+		err = openvpnConf.WriteProxyAuthFile(*upstream.HTTPProxyUser,
+			*upstream.HTTPProxyPassword)
+		if err != nil {
+			return nil, proxyConnection, fmt.Errorf("writing proxy auth to file: %w", err)
+		}
+		option += " " + openvpn.ProxyAuthConf + " basic"
+	}
+
+	proxyLines = make([]string, 0, len(lines)+1)
+	proxyLines = append(proxyLines, lines...)
+	proxyLines = append(proxyLines, option)
+
+	proxyConnection = connection
+	proxyConnection.IP = net.ParseIP(host)
+	proxyConnection.Port = uint16(port)
+	proxyConnection.Protocol = constants.TCP
+
+	return proxyLines, proxyConnection, nil
+}
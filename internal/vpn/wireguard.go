@@ -3,39 +3,102 @@ package vpn
 import (
 	"context"
 	"fmt"
+	"net"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/models"
 	"github.com/qdm12/gluetun/internal/provider"
 	"github.com/qdm12/gluetun/internal/provider/utils"
+	"github.com/qdm12/gluetun/internal/shadowsocks/redir"
 	"github.com/qdm12/gluetun/internal/wireguard"
 )
 
 // setupWireguard sets Wireguard up using the configurators and settings given.
-// It returns a serverName for port forwarding (PIA) and an error if it fails.
+// It returns the connection used and an error if it fails.
 func setupWireguard(ctx context.Context, netlinker NetLinker,
 	fw Firewall, providerConf provider.Provider,
 	settings settings.VPN, ipv6Supported bool, logger wireguard.Logger) (
-	wireguarder *wireguard.Wireguard, serverName string, err error) {
-	connection, err := providerConf.GetConnection(settings.Provider.ServerSelection, ipv6Supported)
+	runner vpnRunner, connection models.Connection, err error) {
+	connection, err = providerConf.GetConnection(settings.Provider.ServerSelection, ipv6Supported)
 	if err != nil {
-		return nil, "", fmt.Errorf("finding a VPN server: %w", err)
+		return nil, connection, fmt.Errorf("finding a VPN server: %w", err)
 	}
 
 	wireguardSettings := utils.BuildWireguardSettings(connection, settings.Wireguard, ipv6Supported)
+	for _, route := range settings.Routes {
+		route := route
+		wireguardSettings.Routes = append(wireguardSettings.Routes, &route)
+	}
 
 	logger.Debug("Wireguard server public key: " + wireguardSettings.PublicKey)
 	logger.Debug("Wireguard client private key: " + wireguardSettings.PrivateKey)
 	logger.Debug("Wireguard pre-shared key: " + wireguardSettings.PreSharedKey)
 
-	wireguarder, err = wireguard.New(wireguardSettings, netlinker, logger)
+	obfuscation := settings.Wireguard.Obfuscation
+	firewallConnection := connection
+	var tunnel *redir.UDPTunnel
+	if obfuscation.Enabled() {
+		tunnel, firewallConnection, err = setupObfuscation(obfuscation,
+			&wireguardSettings, logger)
+		if err != nil {
+			return nil, connection, fmt.Errorf("setting up obfuscation: %w", err)
+		}
+	}
+
+	wireguarder, err := wireguard.New(wireguardSettings, netlinker, logger)
+	if err != nil {
+		return nil, connection, fmt.Errorf("creating Wireguard: %w", err)
+	}
+
+	err = fw.SetVPNConnection(ctx, firewallConnection, settings.Wireguard.Interface)
+	if err != nil {
+		return nil, connection, fmt.Errorf("setting firewall: %w", err)
+	}
+
+	if tunnel == nil {
+		return wireguarder, connection, nil
+	}
+	return &chainedRunner{
+		hop:       tunnel,
+		exit:      wireguarder,
+		entryName: "obfuscation relay",
+		logger:    logger,
+	}, connection, nil
+}
+
+// obfuscationLocalPort is the loopback port Wireguard sends
+// its datagrams to when its traffic is obfuscated.
+const obfuscationLocalPort uint16 = 51830
+
+// setupObfuscation creates a Shadowsocks UDP tunnel relaying the
+// Wireguard datagrams to the Wireguard endpoint, and rewrites the
+// Wireguard endpoint to the local address of the tunnel. It returns
+// the connection to the Shadowsocks server to allow in the firewall.
+func setupObfuscation(obfuscation settings.WireguardObfuscation,
+	wireguardSettings *wireguard.Settings, logger redir.Logger) (
+	tunnel *redir.UDPTunnel, serverConnection models.Connection, err error) {
+	server, err := net.ResolveUDPAddr("udp", obfuscation.Shadowsocks.Server)
 	if err != nil {
-		return nil, "", fmt.Errorf("creating Wireguard: %w", err)
+		return nil, serverConnection, fmt.Errorf("parsing server address: %w", err)
+	}
+	serverConnection = models.Connection{
+		Type:     vpn.Wireguard,
+		IP:       server.IP,
+		Port:     uint16(server.Port),
+		Protocol: constants.UDP,
 	}
 
-	err = fw.SetVPNConnection(ctx, connection, settings.Wireguard.Interface)
+	// The tunnel socket uses the Wireguard firewall mark
+	// so its traffic is not routed through the tunnel.
+	wireguardSettings.SetDefaults()
+	tunnel, err = redir.NewUDPTunnel(obfuscation.Shadowsocks, wireguardSettings.Endpoint,
+		obfuscationLocalPort, wireguardSettings.FirewallMark, logger)
 	if err != nil {
-		return nil, "", fmt.Errorf("setting firewall: %w", err)
+		return nil, serverConnection, fmt.Errorf("creating Shadowsocks tunnel: %w", err)
 	}
+	wireguardSettings.Endpoint = tunnel.LocalAddress()
 
-	return wireguarder, connection.ServerName, nil
+	return tunnel, serverConnection, nil
 }
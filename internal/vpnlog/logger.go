@@ -0,0 +1,107 @@
+// Package vpnlog maps the log levels of the log lines produced by the
+// OpenVPN process and the Wireguard userspace implementation, using
+// rules set by the user. Note the Wireguard kernelspace implementation
+// does not produce any log line in gluetun.
+package vpnlog
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/log"
+)
+
+type ParentLogger interface {
+	Debug(s string)
+	Info(s string)
+	Warn(s string)
+	Error(s string)
+}
+
+// Logger is a logger changing the level of, or suppressing,
+// log lines matching a rule before logging them with its
+// parent logger.
+type Logger struct {
+	parent ParentLogger
+	rules  []rule
+}
+
+type rule struct {
+	regex    *regexp.Regexp
+	level    log.Level
+	suppress bool
+}
+
+// New creates a logger applying the rules given to each log line,
+// before logging it with the parent logger given.
+func New(parent ParentLogger, rules []settings.VPNLogRule) (
+	logger *Logger, err error) {
+	logger = &Logger{
+		parent: parent,
+		rules:  make([]rule, len(rules)),
+	}
+
+	for i, settingsRule := range rules {
+		logger.rules[i].regex, err = regexp.Compile(settingsRule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compiling pattern of rule %d of %d: %w",
+				i+1, len(rules), err)
+		}
+		logger.rules[i].level = settingsRule.Level
+		logger.rules[i].suppress = settingsRule.Suppress
+	}
+
+	return logger, nil
+}
+
+func (l *Logger) Debug(s string) { l.log(log.LevelDebug, s) }
+func (l *Logger) Info(s string)  { l.log(log.LevelInfo, s) }
+func (l *Logger) Warn(s string)  { l.log(log.LevelWarn, s) }
+func (l *Logger) Error(s string) { l.log(log.LevelError, s) }
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.Debug(fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.Error(fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) log(level log.Level, s string) {
+	level, suppress := l.mapLevel(level, s)
+	if suppress {
+		return
+	}
+
+	switch level {
+	case log.LevelDebug:
+		l.parent.Debug(s)
+	case log.LevelInfo:
+		l.parent.Info(s)
+	case log.LevelWarn:
+		l.parent.Warn(s)
+	default:
+		l.parent.Error(s)
+	}
+}
+
+var regexANSIEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// mapLevel returns the level of the first rule matching the line,
+// or the level given if no rule matches it. Color escape sequences
+// are removed from the line before matching it.
+func (l *Logger) mapLevel(level log.Level, line string) (
+	mapped log.Level, suppress bool) {
+	if len(l.rules) == 0 {
+		return level, false
+	}
+
+	line = regexANSIEscape.ReplaceAllString(line, "")
+	for _, rule := range l.rules {
+		if rule.regex.MatchString(line) {
+			return rule.level, rule.suppress
+		}
+	}
+	return level, false
+}
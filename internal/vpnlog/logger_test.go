@@ -0,0 +1,49 @@
+package vpnlog
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	_, err := New(NewMockParentLogger(ctrl), []settings.VPNLogRule{{Pattern: "("}})
+
+	assert.EqualError(t, err, "compiling pattern of rule 1 of 1: "+
+		"error parsing regexp: missing closing ): `(`")
+}
+
+func Test_Logger(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	parent := NewMockParentLogger(ctrl)
+	gomock.InOrder(
+		parent.EXPECT().Debug("\x1b[37mWARNING: you are using chroot without persist-tun\x1b[0m"),
+		parent.EXPECT().Warn("peer(abc) - Handshake did not complete after 5 seconds"),
+		parent.EXPECT().Error("real error: boom"),
+		parent.EXPECT().Info("unmatched line"),
+	)
+	rules := []settings.VPNLogRule{
+		{Pattern: "^MANAGEMENT: ", Suppress: true},
+		{Pattern: "persist-tun", Level: log.LevelDebug},
+		{Pattern: "Handshake did not complete", Level: log.LevelWarn},
+		{Pattern: "tun", Level: log.LevelError},
+	}
+	logger, err := New(parent, rules)
+	require.NoError(t, err)
+
+	logger.Info("MANAGEMENT: Client connected")
+	logger.Warn("\x1b[37mWARNING: you are using chroot without persist-tun\x1b[0m")
+	logger.Debugf("peer(%s) - Handshake did not complete after %d seconds", "abc", 5)
+	logger.Errorf("real error: %s", "boom")
+	logger.Info("unmatched line")
+}
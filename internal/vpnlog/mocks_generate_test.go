@@ -0,0 +1,3 @@
+package vpnlog
+
+//go:generate mockgen -destination=mocks_test.go -package=$GOPACKAGE . ParentLogger
@@ -0,0 +1,19 @@
+package vpnoutput
+
+import (
+	"context"
+	"net"
+)
+
+type Resolver interface {
+	LookupIP(ctx context.Context, network, host string) (ips []net.IP, err error)
+}
+
+type Firewall interface {
+	RestrictVPNOutputIPs(ctx context.Context, ips, dnsServers []net.IP) (err error)
+}
+
+type Logger interface {
+	Info(s string)
+	Warn(s string)
+}
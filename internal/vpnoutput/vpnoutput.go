@@ -0,0 +1,146 @@
+// Package vpnoutput restricts the traffic going out through the VPN
+// interface to the IP addresses of a set of domain names, resolving
+// them periodically to keep the firewall rules up to date.
+package vpnoutput
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// Allowlist resolves the VPN output domains and sets their
+// IP addresses as the only destinations allowed in the firewall.
+type Allowlist struct {
+	domains     []string
+	dnsServers  []net.IP
+	period      time.Duration
+	retryPeriod time.Duration
+	resolver    Resolver
+	firewall    Firewall
+	logger      Logger
+
+	// domainIPs maps each domain to the IP addresses it last
+	// resolved to successfully.
+	domainIPs map[string][]net.IP
+	// lastIPs are the IP addresses last set in the firewall.
+	lastIPs []net.IP
+}
+
+// New creates a VPN output allowlist using the firewall settings given.
+// The DNS servers given are also allowed through the VPN interface
+// such that the domains can be resolved.
+func New(settings settings.Firewall, dnsServers []net.IP, resolver Resolver,
+	firewall Firewall, logger Logger) *Allowlist {
+	const retryPeriod = 10 * time.Second
+	return &Allowlist{
+		domains:     settings.VPNOutputDomains,
+		dnsServers:  dnsServers,
+		period:      *settings.VPNOutputDomainsRefresh,
+		retryPeriod: retryPeriod,
+		resolver:    resolver,
+		firewall:    firewall,
+		logger:      logger,
+		domainIPs:   make(map[string][]net.IP, len(settings.VPNOutputDomains)),
+	}
+}
+
+// Run resolves the domains and updates the firewall periodically until
+// the context is canceled. Resolution is retried more often if a domain
+// cannot be resolved, which is the case until the VPN tunnel is up.
+// It returns immediately if no domain is set.
+func (a *Allowlist) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	if len(a.domains) == 0 {
+		return
+	}
+
+	for {
+		period := a.period
+		if !a.refresh(ctx) && a.retryPeriod < period {
+			period = a.retryPeriod
+		}
+
+		timer := time.NewTimer(period)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// refresh resolves all the domains and updates the firewall if the
+// IP addresses changed. Domains failing to resolve keep their previous
+// IP addresses. It returns false if any domain failed to resolve or if
+// the firewall failed to be updated.
+func (a *Allowlist) refresh(ctx context.Context) (ok bool) {
+	ok = true
+	for _, domain := range a.domains {
+		ips, err := a.resolver.LookupIP(ctx, "ip", domain)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false
+			}
+			a.logger.Warn("resolving " + domain + ": " + err.Error())
+			ok = false
+			continue
+		}
+		a.domainIPs[domain] = ips
+	}
+
+	ips := a.uniqueIPs()
+	if ipsEqual(ips, a.lastIPs) {
+		return ok
+	}
+
+	err := a.firewall.RestrictVPNOutputIPs(ctx, ips, a.dnsServers)
+	if err != nil {
+		a.logger.Warn("restricting VPN output IP addresses: " + err.Error())
+		return false
+	}
+	a.lastIPs = ips
+	a.logger.Info(fmt.Sprintf("allowing %d IP addresses for %s",
+		len(ips), strings.Join(a.domains, ", ")))
+	return ok
+}
+
+// uniqueIPs returns the sorted and deduplicated
+// IP addresses of all the domains.
+func (a *Allowlist) uniqueIPs() (ips []net.IP) {
+	seen := make(map[string]struct{})
+	for _, domainIPs := range a.domainIPs {
+		for _, ip := range domainIPs {
+			key := ip.String()
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			ips = append(ips, ip)
+		}
+	}
+	sort.Slice(ips, func(i, j int) bool {
+		return bytes.Compare(ips[i].To16(), ips[j].To16()) < 0
+	})
+	return ips
+}
+
+func ipsEqual(a, b []net.IP) (equal bool) {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package vpnoutput
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeResolver struct {
+	hostToIPs map[string][]net.IP
+	hostToErr map[string]error
+}
+
+func (f *fakeResolver) LookupIP(_ context.Context, _, host string) ([]net.IP, error) {
+	return f.hostToIPs[host], f.hostToErr[host]
+}
+
+type fakeFirewall struct {
+	calls [][]net.IP
+	err   error
+}
+
+func (f *fakeFirewall) RestrictVPNOutputIPs(_ context.Context, ips, _ []net.IP) error {
+	f.calls = append(f.calls, ips)
+	return f.err
+}
+
+func Test_Allowlist_refresh(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	resolver := &fakeResolver{
+		hostToIPs: map[string][]net.IP{},
+		hostToErr: map[string]error{
+			"a.example.com": errors.New("test error"),
+			"b.example.com": errors.New("test error"),
+		},
+	}
+	firewall := &fakeFirewall{}
+	logger := NewMockLogger(ctrl)
+	allowlist := &Allowlist{
+		domains:   []string{"a.example.com", "b.example.com"},
+		resolver:  resolver,
+		firewall:  firewall,
+		logger:    logger,
+		domainIPs: map[string][]net.IP{},
+	}
+	ctx := context.Background()
+
+	// Nothing resolves yet, the firewall is left as is.
+	logger.EXPECT().Warn("resolving a.example.com: test error")
+	logger.EXPECT().Warn("resolving b.example.com: test error")
+	ok := allowlist.refresh(ctx)
+	assert.False(t, ok)
+	assert.Empty(t, firewall.calls)
+
+	// Only one domain resolves.
+	resolver.hostToErr["a.example.com"] = nil
+	resolver.hostToIPs["a.example.com"] = []net.IP{net.IPv4(2, 2, 2, 2), net.IPv4(1, 1, 1, 1)}
+	logger.EXPECT().Warn("resolving b.example.com: test error")
+	logger.EXPECT().Info("allowing 2 IP addresses for a.example.com, b.example.com")
+	ok = allowlist.refresh(ctx)
+	assert.False(t, ok)
+	assert.Equal(t, [][]net.IP{{net.IPv4(1, 1, 1, 1), net.IPv4(2, 2, 2, 2)}}, firewall.calls)
+
+	// Both domains resolve, with a duplicate IP address.
+	resolver.hostToErr["b.example.com"] = nil
+	resolver.hostToIPs["b.example.com"] = []net.IP{net.IPv4(1, 1, 1, 1), net.IPv4(3, 3, 3, 3)}
+	logger.EXPECT().Info("allowing 3 IP addresses for a.example.com, b.example.com")
+	ok = allowlist.refresh(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []net.IP{net.IPv4(1, 1, 1, 1), net.IPv4(2, 2, 2, 2), net.IPv4(3, 3, 3, 3)},
+		firewall.calls[1])
+
+	// Unchanged IP addresses do not update the firewall.
+	ok = allowlist.refresh(ctx)
+	assert.True(t, ok)
+	assert.Len(t, firewall.calls, 2)
+
+	// A domain failing to resolve keeps its previous IP addresses.
+	resolver.hostToErr["a.example.com"] = errors.New("test error")
+	logger.EXPECT().Warn("resolving a.example.com: test error")
+	ok = allowlist.refresh(ctx)
+	assert.False(t, ok)
+	assert.Len(t, firewall.calls, 2)
+
+	// Firewall failures are retried on the next refresh.
+	resolver.hostToErr["a.example.com"] = nil
+	resolver.hostToIPs["a.example.com"] = []net.IP{net.IPv4(4, 4, 4, 4)}
+	firewall.err = errors.New("test error")
+	logger.EXPECT().Warn("restricting VPN output IP addresses: test error")
+	ok = allowlist.refresh(ctx)
+	assert.False(t, ok)
+	firewall.err = nil
+	logger.EXPECT().Info("allowing 3 IP addresses for a.example.com, b.example.com")
+	ok = allowlist.refresh(ctx)
+	assert.True(t, ok)
+	assert.Len(t, firewall.calls, 4)
+	assert.Equal(t, []net.IP{net.IPv4(1, 1, 1, 1), net.IPv4(3, 3, 3, 3), net.IPv4(4, 4, 4, 4)},
+		firewall.calls[3])
+}
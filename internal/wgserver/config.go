@@ -0,0 +1,160 @@
+package wgserver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+func makeDeviceConfig(settings settings.WireguardServer) (
+	config wgtypes.Config, err error) {
+	privateKey, err := wgtypes.ParseKey(*settings.PrivateKey)
+	if err != nil {
+		return config, fmt.Errorf("parsing private key: %w", err)
+	}
+	listeningPort := int(*settings.ListeningPort)
+
+	peers := make([]wgtypes.PeerConfig, len(settings.Peers))
+	for i, peer := range settings.Peers {
+		peers[i].PublicKey, err = wgtypes.ParseKey(peer.PublicKey)
+		if err != nil {
+			return config, fmt.Errorf("parsing public key of peer %s: %w", peer.Name, err)
+		}
+
+		if peer.PreSharedKey != "" {
+			preSharedKey, err := wgtypes.ParseKey(peer.PreSharedKey)
+			if err != nil {
+				return config, fmt.Errorf("parsing pre-shared key of peer %s: %w", peer.Name, err)
+			}
+			peers[i].PresharedKey = &preSharedKey
+		}
+
+		const ipv4Bits = 32
+		peers[i].AllowedIPs = []net.IPNet{{
+			IP:   peer.Address.To4(),
+			Mask: net.CIDRMask(ipv4Bits, ipv4Bits),
+		}}
+		peers[i].ReplaceAllowedIPs = true
+	}
+
+	return wgtypes.Config{
+		PrivateKey:   &privateKey,
+		ListenPort:   &listeningPort,
+		ReplacePeers: true,
+		Peers:        peers,
+	}, nil
+}
+
+var (
+	ErrEndpointNotSet   = errors.New("endpoint is not set")
+	ErrPeerNotFound     = errors.New("peer not found")
+	ErrServerNotEnabled = errors.New("wireguard server is not enabled")
+)
+
+// ClientConfig returns the Wireguard configuration for the peer with
+// the name given, in the wg-quick format. This text can be imported
+// as a file or encoded as a QR code in Wireguard client applications.
+// The host given is used as the server endpoint host if no endpoint
+// is set in the settings, and is typically the host the configuration
+// is requested with.
+func (s *Server) ClientConfig(name, host string) (config string, err error) {
+	if !*s.settings.Enabled {
+		return "", fmt.Errorf("%w", ErrServerNotEnabled)
+	}
+
+	var peer *settings.WireguardServerPeer
+	for i := range s.settings.Peers {
+		if s.settings.Peers[i].Name == name {
+			peer = &s.settings.Peers[i]
+			break
+		}
+	}
+	if peer == nil {
+		return "", fmt.Errorf("%w: %s", ErrPeerNotFound, name)
+	}
+
+	endpoint, err := makeEndpoint(*s.settings.Endpoint, host, *s.settings.ListeningPort)
+	if err != nil {
+		return "", err
+	}
+
+	serverPrivateKey, err := wgtypes.ParseKey(*s.settings.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("parsing private key: %w", err)
+	}
+
+	privateKey := peer.PrivateKey
+	if privateKey == "" {
+		privateKey = "<private key of " + peer.PublicKey + ">"
+	}
+
+	lines := []string{
+		"[Interface]",
+		"PrivateKey = " + privateKey,
+		"Address = " + peer.Address.String() + "/32",
+		"DNS = " + s.settings.Address.IP.String(),
+		"",
+		"[Peer]",
+		"PublicKey = " + serverPrivateKey.PublicKey().String(),
+	}
+	if peer.PreSharedKey != "" {
+		lines = append(lines, "PresharedKey = "+peer.PreSharedKey)
+	}
+	lines = append(lines,
+		"AllowedIPs = 0.0.0.0/0",
+		"Endpoint = "+endpoint,
+		"PersistentKeepalive = 25",
+	)
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// makeEndpoint returns the endpoint address, in the form host:port,
+// using the endpoint setting or the fallback host given if the setting
+// is empty, and the listening port if no port is specified.
+func makeEndpoint(setting, fallbackHost string, listeningPort uint16) (
+	endpoint string, err error) {
+	host := setting
+	if host == "" {
+		host = fallbackHost
+		if splitHost, _, err := net.SplitHostPort(host); err == nil {
+			host = splitHost // discard the port of the fallback host
+		}
+	} else if _, _, err := net.SplitHostPort(setting); err == nil {
+		return setting, nil
+	}
+
+	if host == "" {
+		return "", fmt.Errorf("%w", ErrEndpointNotSet)
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return net.JoinHostPort(host, strconv.Itoa(int(listeningPort))), nil
+}
+
+// Peer is the public information of a peer of the Wireguard server.
+type Peer struct {
+	Name      string `json:"name"`
+	PublicKey string `json:"public_key"`
+	Address   net.IP `json:"address"`
+}
+
+// Peers returns the peers of the Wireguard server,
+// or no peer if the server is not enabled.
+func (s *Server) Peers() (peers []Peer) {
+	if !*s.settings.Enabled {
+		return nil
+	}
+	peers = make([]Peer, len(s.settings.Peers))
+	for i, peer := range s.settings.Peers {
+		peers[i] = Peer{
+			Name:      peer.Name,
+			PublicKey: peer.PublicKey,
+			Address:   peer.Address,
+		}
+	}
+	return peers
+}
@@ -0,0 +1,132 @@
+package wgserver
+
+import (
+	"net"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSettings() settings.WireguardServer {
+	enabled := true
+	port := uint16(51820)
+	privateKey := "+CT2emby/clyfh7kqqLz1UI8S4enfR6ceVt9LpXmTUs="
+	endpoint := ""
+	return settings.WireguardServer{
+		Enabled:       &enabled,
+		Interface:     "wgs0",
+		ListeningPort: &port,
+		PrivateKey:    &privateKey,
+		Address: &net.IPNet{
+			IP:   net.IPv4(10, 13, 13, 1),
+			Mask: net.CIDRMask(24, 32),
+		},
+		Endpoint: &endpoint,
+		Peers: []settings.WireguardServerPeer{{
+			Name:         "phone",
+			PublicKey:    "FG2NGEENmKk3M2/u4cau8j/UkiAaxMeWQBlE2rEGRn4=",
+			PrivateKey:   "mLfk2AhUMnPONaVdqyDsOILB41UuFCIegvs4jWhCUko=",
+			PreSharedKey: "Ub7sdsbMrecVya37E84qseL0/MiN6bfVvRE/YIq8t+s=",
+			Address:      net.IPv4(10, 13, 13, 2),
+		}, {
+			Name:      "laptop",
+			PublicKey: "PHd+VPz9qsYg4VeX/XiYvb5XeAaepEt6qkqk0Bakb0Q=",
+			Address:   net.IPv4(10, 13, 13, 3),
+		}},
+	}
+}
+
+func Test_makeDeviceConfig(t *testing.T) {
+	t.Parallel()
+
+	config, err := makeDeviceConfig(newTestSettings())
+	require.NoError(t, err)
+
+	require.NotNil(t, config.ListenPort)
+	assert.Equal(t, 51820, *config.ListenPort)
+	assert.True(t, config.ReplacePeers)
+	require.Len(t, config.Peers, 2)
+	assert.Equal(t, "FG2NGEENmKk3M2/u4cau8j/UkiAaxMeWQBlE2rEGRn4=", config.Peers[0].PublicKey.String())
+	require.NotNil(t, config.Peers[0].PresharedKey)
+	assert.Nil(t, config.Peers[1].PresharedKey)
+	assert.Equal(t, []net.IPNet{{
+		IP:   net.IPv4(10, 13, 13, 3).To4(),
+		Mask: net.CIDRMask(32, 32),
+	}}, config.Peers[1].AllowedIPs)
+}
+
+func Test_Server_ClientConfig(t *testing.T) {
+	t.Parallel()
+
+	server := New(newTestSettings(), nil, nil, nil, nil)
+
+	config, err := server.ClientConfig("phone", "192.168.1.10:8000")
+	require.NoError(t, err)
+	const expected = `[Interface]
+PrivateKey = mLfk2AhUMnPONaVdqyDsOILB41UuFCIegvs4jWhCUko=
+Address = 10.13.13.2/32
+DNS = 10.13.13.1
+
+[Peer]
+PublicKey = PHd+VPz9qsYg4VeX/XiYvb5XeAaepEt6qkqk0Bakb0Q=
+PresharedKey = Ub7sdsbMrecVya37E84qseL0/MiN6bfVvRE/YIq8t+s=
+AllowedIPs = 0.0.0.0/0
+Endpoint = 192.168.1.10:51820
+PersistentKeepalive = 25
+`
+	assert.Equal(t, expected, config)
+
+	config, err = server.ClientConfig("laptop", "192.168.1.10")
+	require.NoError(t, err)
+	assert.Contains(t, config,
+		"PrivateKey = <private key of PHd+VPz9qsYg4VeX/XiYvb5XeAaepEt6qkqk0Bakb0Q=>\n")
+
+	_, err = server.ClientConfig("tablet", "192.168.1.10")
+	assert.ErrorIs(t, err, ErrPeerNotFound)
+}
+
+func Test_makeEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		setting      string
+		fallbackHost string
+		endpoint     string
+		errWrapped   error
+	}{
+		"no_endpoint": {
+			errWrapped: ErrEndpointNotSet,
+		},
+		"fallback_host_with_port": {
+			fallbackHost: "gluetun.local:8000",
+			endpoint:     "gluetun.local:51820",
+		},
+		"fallback_ipv6_host": {
+			fallbackHost: "[::1]:8000",
+			endpoint:     "[::1]:51820",
+		},
+		"setting_host": {
+			setting:      "vpn.example.com",
+			fallbackHost: "gluetun.local:8000",
+			endpoint:     "vpn.example.com:51820",
+		},
+		"setting_host_and_port": {
+			setting:  "vpn.example.com:443",
+			endpoint: "vpn.example.com:443",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			endpoint, err := makeEndpoint(testCase.setting, testCase.fallbackHost, 51820)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			assert.Equal(t, testCase.endpoint, endpoint)
+		})
+	}
+}
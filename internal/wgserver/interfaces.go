@@ -0,0 +1,20 @@
+package wgserver
+
+import (
+	"context"
+
+	"github.com/qdm12/gluetun/internal/netlink"
+)
+
+type NetLinker interface {
+	AddrAdd(link netlink.Link, addr *netlink.Addr) error
+	LinkAdd(link netlink.Link) (err error)
+	LinkSetUp(link netlink.Link) error
+	LinkDel(link netlink.Link) error
+	IsWireguardSupported() (ok bool, err error)
+}
+
+type PortAllower interface {
+	SetAllowedPort(ctx context.Context, port uint16, intf string) (err error)
+	RemoveAllowedPort(ctx context.Context, port uint16) (err error)
+}
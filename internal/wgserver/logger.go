@@ -0,0 +1,6 @@
+package wgserver
+
+type Logger interface {
+	Info(s string)
+	Error(s string)
+}
@@ -0,0 +1,133 @@
+// Package wgserver runs a Wireguard server for remote devices
+// to connect to gluetun and have their traffic go out through
+// the VPN tunnel.
+package wgserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/netlink"
+	"golang.zx2c4.com/wireguard/wgctrl"
+)
+
+var ErrKernelNotSupported = errors.New("kernel does not support Wireguard")
+
+type Server struct {
+	settings    settings.WireguardServer
+	netLinker   NetLinker
+	portAllower PortAllower
+	interfaces  []string
+	logger      Logger
+}
+
+// New creates a Wireguard server. Its listening port is allowed
+// through the firewall on the interfaces given, so remote devices
+// can reach the server.
+func New(settings settings.WireguardServer, netLinker NetLinker,
+	portAllower PortAllower, interfaces []string, logger Logger) *Server {
+	return &Server{
+		settings:    settings,
+		netLinker:   netLinker,
+		portAllower: portAllower,
+		interfaces:  interfaces,
+		logger:      logger,
+	}
+}
+
+// Run runs the Wireguard server until the context is canceled,
+// and then removes its interface. Errors are logged.
+func (s *Server) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	link, err := s.setupInterface()
+	if link != nil {
+		defer s.deleteInterface(link)
+	}
+	if err != nil {
+		s.logger.Error(err.Error())
+		return
+	}
+
+	port := *s.settings.ListeningPort
+	defer s.blockPort(port)
+	for _, intf := range s.interfaces {
+		err = s.portAllower.SetAllowedPort(ctx, port, intf)
+		if err != nil {
+			s.logger.Error(fmt.Sprintf("allowing port %d through firewall: %s", port, err))
+			return
+		}
+	}
+
+	s.logger.Info(fmt.Sprintf("listening on UDP port %d with interface %s for %d peer(s)",
+		port, s.settings.Interface, len(s.settings.Peers)))
+	<-ctx.Done()
+}
+
+// setupInterface creates, configures and sets up the Wireguard server
+// interface. The link created is returned even if an error occurs
+// afterwards, so it can be deleted by the caller.
+func (s *Server) setupInterface() (link netlink.Link, err error) {
+	ok, err := s.netLinker.IsWireguardSupported()
+	if err != nil {
+		return nil, fmt.Errorf("checking kernel Wireguard support: %w", err)
+	} else if !ok {
+		return nil, fmt.Errorf("%w", ErrKernelNotSupported)
+	}
+
+	link = &netlink.Wireguard{
+		LinkAttrs: netlink.LinkAttrs{Name: s.settings.Interface},
+	}
+	err = s.netLinker.LinkAdd(link)
+	if err != nil {
+		return nil, fmt.Errorf("adding link %s: %w", s.settings.Interface, err)
+	}
+
+	deviceConfig, err := makeDeviceConfig(s.settings)
+	if err != nil {
+		return link, fmt.Errorf("making device configuration: %w", err)
+	}
+
+	client, err := wgctrl.New()
+	if err != nil {
+		return link, fmt.Errorf("opening wgctrl: %w", err)
+	}
+	defer client.Close()
+
+	err = client.ConfigureDevice(s.settings.Interface, deviceConfig)
+	if err != nil {
+		return link, fmt.Errorf("configuring device: %w", err)
+	}
+
+	address := &netlink.Addr{IPNet: s.settings.Address}
+	err = s.netLinker.AddrAdd(link, address)
+	if err != nil {
+		return link, fmt.Errorf("adding address %s to link %s: %w",
+			address, s.settings.Interface, err)
+	}
+
+	err = s.netLinker.LinkSetUp(link)
+	if err != nil {
+		return link, fmt.Errorf("setting link %s up: %w", s.settings.Interface, err)
+	}
+
+	return link, nil
+}
+
+func (s *Server) deleteInterface(link netlink.Link) {
+	err := s.netLinker.LinkDel(link)
+	if err != nil {
+		s.logger.Error("deleting link " + s.settings.Interface + ": " + err.Error())
+	}
+}
+
+func (s *Server) blockPort(port uint16) {
+	// Use a background context so the port is removed
+	// even if the server context is canceled.
+	err := s.portAllower.RemoveAllowedPort(context.Background(), port)
+	if err != nil {
+		s.logger.Error("removing port " + fmt.Sprint(port) + " from firewall: " + err.Error())
+	}
+}
@@ -44,9 +44,11 @@ func (c *closers) cleanup(logger Logger) {
 type step int
 
 const (
+	// stepZero stops the peer endpoints resolution.
+	stepZero step = iota
 	// stepOne closes the wireguard controller client,
 	// and removes the IP rule.
-	stepOne step = iota
+	stepOne
 	// stepTwo closes the UAPI listener.
 	stepTwo
 	// stepThree closes the UAPI file.
@@ -3,6 +3,7 @@ package wireguard
 import (
 	"fmt"
 	"net"
+	"time"
 
 	"golang.zx2c4.com/wireguard/wgctrl"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
@@ -56,15 +57,64 @@ func makeDeviceConfig(settings Settings) (config wgtypes.Config, err error) {
 					*allIPv4(),
 					*allIPv6(),
 				},
-				ReplaceAllowedIPs: true,
-				Endpoint:          settings.Endpoint,
+				ReplaceAllowedIPs:           true,
+				Endpoint:                    settings.Endpoint,
+				PersistentKeepaliveInterval: keepaliveInterval(settings.PersistentKeepaliveInterval),
 			},
 		},
 	}
 
+	for _, peer := range settings.Peers {
+		peerConfig, err := makePeerConfig(peer)
+		if err != nil {
+			return config, fmt.Errorf("for peer %s: %w", peer.PublicKey, err)
+		}
+		config.Peers = append(config.Peers, peerConfig)
+	}
+
 	return config, nil
 }
 
+func makePeerConfig(peer Peer) (config wgtypes.PeerConfig, err error) {
+	publicKey, err := wgtypes.ParseKey(peer.PublicKey)
+	if err != nil {
+		return config, fmt.Errorf("%w: %s", ErrPublicKeyInvalid, peer.PublicKey)
+	}
+
+	var preSharedKey *wgtypes.Key
+	if peer.PreSharedKey != "" {
+		preSharedKeyValue, err := wgtypes.ParseKey(peer.PreSharedKey)
+		if err != nil {
+			return config, ErrPreSharedKeyInvalid
+		}
+		preSharedKey = &preSharedKeyValue
+	}
+
+	endpoint := peer.Endpoint
+	if endpoint != nil && len(endpoint.IP) == 0 {
+		// endpoint hostname not resolved yet
+		endpoint = nil
+	}
+
+	return wgtypes.PeerConfig{
+		PublicKey:                   publicKey,
+		PresharedKey:                preSharedKey,
+		Endpoint:                    endpoint,
+		AllowedIPs:                  peer.AllowedIPs,
+		ReplaceAllowedIPs:           true,
+		PersistentKeepaliveInterval: keepaliveInterval(peer.PersistentKeepaliveInterval),
+	}, nil
+}
+
+// keepaliveInterval returns nil if the interval is 0, to leave
+// persistent keepalive disabled on the device.
+func keepaliveInterval(interval time.Duration) *time.Duration {
+	if interval == 0 {
+		return nil
+	}
+	return &interval
+}
+
 func allIPv4() (ipNet *net.IPNet) {
 	return &net.IPNet{
 		IP:   net.IPv4(0, 0, 0, 0),
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,6 +28,7 @@ func Test_makeDeviceConfig(t *testing.T) {
 	}
 
 	intPtr := func(n int) *int { return &n }
+	durationPtr := func(d time.Duration) *time.Duration { return &d }
 
 	testCases := map[string]struct {
 		settings Settings
@@ -92,6 +94,52 @@ func Test_makeDeviceConfig(t *testing.T) {
 				},
 			},
 		},
+		"additional peer": {
+			settings: Settings{
+				PrivateKey:                  validKey1,
+				PublicKey:                   validKey2,
+				FirewallMark:                9876,
+				PersistentKeepaliveInterval: 25 * time.Second,
+				Peers: []Peer{{
+					PublicKey: validKey3,
+					Endpoint: &net.UDPAddr{
+						IP:   net.IPv4(88, 88, 88, 88),
+						Port: 51820,
+					},
+					AllowedIPs: []net.IPNet{{
+						IP:   net.IPv4(192, 168, 1, 0),
+						Mask: net.IPv4Mask(255, 255, 255, 0),
+					}},
+					PersistentKeepaliveInterval: 10 * time.Second,
+				}},
+			},
+			config: wgtypes.Config{
+				PrivateKey:   parseKey(t, validKey1),
+				ReplacePeers: true,
+				FirewallMark: intPtr(9876),
+				Peers: []wgtypes.PeerConfig{
+					{
+						PublicKey:                   *parseKey(t, validKey2),
+						AllowedIPs:                  []net.IPNet{*allIPv4(), *allIPv6()},
+						ReplaceAllowedIPs:           true,
+						PersistentKeepaliveInterval: durationPtr(25 * time.Second),
+					},
+					{
+						PublicKey: *parseKey(t, validKey3),
+						Endpoint: &net.UDPAddr{
+							IP:   net.IPv4(88, 88, 88, 88),
+							Port: 51820,
+						},
+						AllowedIPs: []net.IPNet{{
+							IP:   net.IPv4(192, 168, 1, 0),
+							Mask: net.IPv4Mask(255, 255, 255, 0),
+						}},
+						ReplaceAllowedIPs:           true,
+						PersistentKeepaliveInterval: durationPtr(10 * time.Second),
+					},
+				},
+			},
+		},
 	}
 
 	for name, testCase := range testCases {
@@ -1,9 +1,12 @@
 package wireguard
 
+import "net"
+
 type Wireguard struct {
 	logger   Logger
 	settings Settings
 	netlink  NetLinker
+	resolver Resolver
 }
 
 func New(settings Settings, netlink NetLinker,
@@ -17,5 +20,6 @@ func New(settings Settings, netlink NetLinker,
 		logger:   logger,
 		settings: settings,
 		netlink:  netlink,
+		resolver: net.DefaultResolver,
 	}, nil
 }
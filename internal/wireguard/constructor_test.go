@@ -40,8 +40,9 @@ func Test_New(t *testing.T) {
 				FirewallMark: 100,
 			},
 			wireguard: &Wireguard{
-				logger:  logger,
-				netlink: netLinker,
+				logger:   logger,
+				netlink:  netLinker,
+				resolver: net.DefaultResolver,
 				settings: Settings{
 					InterfaceName: "wg0",
 					PrivateKey:    validKeyString,
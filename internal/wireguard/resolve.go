@@ -0,0 +1,150 @@
+package wireguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+type Resolver interface {
+	LookupIP(ctx context.Context, network, host string) (ips []net.IP, err error)
+}
+
+type deviceConfigurer interface {
+	ConfigureDevice(name string, config wgtypes.Config) error
+}
+
+// copyPeers returns a copy of the peers given, such that the
+// endpoint of each copied peer can be changed safely.
+func copyPeers(peers []Peer) (copied []Peer) {
+	copied = make([]Peer, len(peers))
+	for i, peer := range peers {
+		copied[i] = peer
+		if peer.Endpoint != nil {
+			endpoint := *peer.Endpoint
+			copied[i].Endpoint = &endpoint
+		}
+	}
+	return copied
+}
+
+// resolvePeerEndpoints resolves the endpoint hostname of each peer
+// and sets its endpoint IP address. It returns the indexes of the
+// peers for which the endpoint IP address changed.
+// Resolution errors are logged and the peer endpoint is left unchanged.
+func (w *Wireguard) resolvePeerEndpoints(ctx context.Context,
+	peers []Peer) (changed []int) {
+	for i, peer := range peers {
+		if peer.EndpointHostname == "" {
+			continue
+		}
+
+		ip, err := w.resolveEndpoint(ctx, peer.EndpointHostname, peer.Endpoint.IP)
+		if err != nil {
+			w.logger.Error("resolving endpoint of peer " + peer.PublicKey + ": " + err.Error())
+			continue
+		} else if ip.Equal(peer.Endpoint.IP) {
+			continue
+		}
+
+		peers[i].Endpoint = &net.UDPAddr{IP: ip, Port: peer.Endpoint.Port}
+		changed = append(changed, i)
+	}
+	return changed
+}
+
+var ErrEndpointNoIPFound = errors.New("no IP address found for endpoint")
+
+// resolveEndpoint resolves the hostname given and returns the current IP
+// address if it is still one of the resolved IP addresses, such that a
+// hostname resolving to multiple IP addresses does not change the endpoint
+// at each resolution. Otherwise it returns the first IP address resolved.
+func (w *Wireguard) resolveEndpoint(ctx context.Context, hostname string,
+	currentIP net.IP) (ip net.IP, err error) {
+	network := "ip4"
+	if *w.settings.IPv6 {
+		network = "ip"
+	}
+
+	const timeout = 5 * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ips, err := w.resolver.LookupIP(ctx, network, hostname)
+	if err != nil {
+		return nil, err
+	} else if len(ips) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrEndpointNoIPFound, hostname)
+	}
+
+	for _, ip := range ips {
+		if ip.Equal(currentIP) {
+			return currentIP, nil
+		}
+	}
+	return ips[0], nil
+}
+
+// watchPeerEndpoints periodically resolves the peers endpoint hostnames
+// and updates the endpoint of each peer for which the IP address changed,
+// until the context is canceled.
+func (w *Wireguard) watchPeerEndpoints(ctx context.Context,
+	client deviceConfigurer, peers []Peer, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(w.settings.EndpointResolvePeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed := w.resolvePeerEndpoints(ctx, peers)
+		for _, i := range changed {
+			peer := peers[i]
+			err := updatePeerEndpoint(client, w.settings.InterfaceName, peer)
+			if err != nil {
+				w.logger.Error("updating endpoint of peer " + peer.PublicKey + ": " + err.Error())
+				continue
+			}
+			w.logger.Info("endpoint of peer " + peer.PublicKey + " changed to " +
+				peer.Endpoint.String() + " for " + peer.EndpointHostname)
+		}
+	}
+}
+
+func updatePeerEndpoint(client deviceConfigurer, interfaceName string,
+	peer Peer) (err error) {
+	publicKey, err := wgtypes.ParseKey(peer.PublicKey)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPublicKeyInvalid, peer.PublicKey)
+	}
+
+	config := wgtypes.Config{
+		Peers: []wgtypes.PeerConfig{{
+			PublicKey:  publicKey,
+			UpdateOnly: true,
+			Endpoint:   peer.Endpoint,
+		}},
+	}
+	err = client.ConfigureDevice(interfaceName, config)
+	if err != nil {
+		return fmt.Errorf("configuring device: %w", err)
+	}
+	return nil
+}
+
+func hasEndpointHostname(peers []Peer) bool {
+	for _, peer := range peers {
+		if peer.EndpointHostname != "" {
+			return true
+		}
+	}
+	return false
+}
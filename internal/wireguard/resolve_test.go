@@ -0,0 +1,158 @@
+package wireguard
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+type fakeResolver struct {
+	ips map[string][]net.IP
+	err error
+}
+
+func (r *fakeResolver) LookupIP(_ context.Context, _, host string) (
+	ips []net.IP, err error) {
+	return r.ips[host], r.err
+}
+
+type fakeDeviceConfigurer struct {
+	configs []wgtypes.Config
+}
+
+func (c *fakeDeviceConfigurer) ConfigureDevice(_ string, config wgtypes.Config) error {
+	c.configs = append(c.configs, config)
+	return nil
+}
+
+func Test_Wireguard_resolvePeerEndpoints(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+
+	testCases := map[string]struct {
+		resolver  *fakeResolver
+		peers     []Peer
+		logError  string
+		changed   []int
+		endpoints []*net.UDPAddr
+	}{
+		"no hostname": {
+			resolver: &fakeResolver{},
+			peers: []Peer{
+				{Endpoint: &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1}},
+			},
+			endpoints: []*net.UDPAddr{{IP: net.IPv4(1, 2, 3, 4), Port: 1}},
+		},
+		"first resolution": {
+			resolver: &fakeResolver{ips: map[string][]net.IP{
+				"peer.example.com": {net.IPv4(5, 6, 7, 8)},
+			}},
+			peers: []Peer{
+				{Endpoint: &net.UDPAddr{Port: 1}, EndpointHostname: "peer.example.com"},
+			},
+			changed:   []int{0},
+			endpoints: []*net.UDPAddr{{IP: net.IPv4(5, 6, 7, 8), Port: 1}},
+		},
+		"current IP still resolved": {
+			resolver: &fakeResolver{ips: map[string][]net.IP{
+				"peer.example.com": {net.IPv4(9, 9, 9, 9), net.IPv4(5, 6, 7, 8)},
+			}},
+			peers: []Peer{{
+				Endpoint:         &net.UDPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 1},
+				EndpointHostname: "peer.example.com",
+			}},
+			endpoints: []*net.UDPAddr{{IP: net.IPv4(5, 6, 7, 8), Port: 1}},
+		},
+		"IP changed": {
+			resolver: &fakeResolver{ips: map[string][]net.IP{
+				"peer.example.com": {net.IPv4(9, 9, 9, 9)},
+			}},
+			peers: []Peer{
+				{Endpoint: &net.UDPAddr{IP: net.IPv4(1, 1, 1, 1), Port: 2}},
+				{
+					Endpoint:         &net.UDPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 1},
+					EndpointHostname: "peer.example.com",
+				},
+			},
+			changed: []int{1},
+			endpoints: []*net.UDPAddr{
+				{IP: net.IPv4(1, 1, 1, 1), Port: 2},
+				{IP: net.IPv4(9, 9, 9, 9), Port: 1},
+			},
+		},
+		"resolution error": {
+			resolver: &fakeResolver{err: errTest},
+			peers: []Peer{{
+				PublicKey:        "key",
+				Endpoint:         &net.UDPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 1},
+				EndpointHostname: "peer.example.com",
+			}},
+			logError:  "resolving endpoint of peer key: test error",
+			endpoints: []*net.UDPAddr{{IP: net.IPv4(5, 6, 7, 8), Port: 1}},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			logger := NewMockLogger(ctrl)
+			if testCase.logError != "" {
+				logger.EXPECT().Error(testCase.logError)
+			}
+			ipv6 := false
+			wireguard := &Wireguard{
+				logger:   logger,
+				settings: Settings{IPv6: &ipv6},
+				resolver: testCase.resolver,
+			}
+
+			changed := wireguard.resolvePeerEndpoints(context.Background(), testCase.peers)
+
+			assert.Equal(t, testCase.changed, changed)
+			endpoints := make([]*net.UDPAddr, len(testCase.peers))
+			for i, peer := range testCase.peers {
+				endpoints[i] = peer.Endpoint
+			}
+			assert.Equal(t, testCase.endpoints, endpoints)
+		})
+	}
+}
+
+func Test_updatePeerEndpoint(t *testing.T) {
+	t.Parallel()
+
+	const keyString = "oMNSf/zJ0pt1ciy+qIRk8Rlyfs9accwuRLnKd85Yl1Q="
+	key, err := wgtypes.ParseKey(keyString)
+	require.NoError(t, err)
+
+	client := &fakeDeviceConfigurer{}
+	peer := Peer{
+		PublicKey:                   keyString,
+		Endpoint:                    &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 51820},
+		EndpointHostname:            "peer.example.com",
+		AllowedIPs:                  []net.IPNet{*allIPv4()},
+		PersistentKeepaliveInterval: 1,
+	}
+
+	err = updatePeerEndpoint(client, "wg0", peer)
+
+	require.NoError(t, err)
+	expected := []wgtypes.Config{{
+		Peers: []wgtypes.PeerConfig{{
+			PublicKey:  key,
+			UpdateOnly: true,
+			Endpoint:   &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 51820},
+		}},
+	}}
+	assert.Equal(t, expected, client.configs)
+}
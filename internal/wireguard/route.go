@@ -26,3 +26,12 @@ func (w *Wireguard) addRoute(link netlink.Link, dst *net.IPNet,
 
 	return err
 }
+
+func hasIPv6Route(routes []*net.IPNet) bool {
+	for _, route := range routes {
+		if route.IP.To4() == nil {
+			return true
+		}
+	}
+	return false
+}
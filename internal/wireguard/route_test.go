@@ -83,3 +83,14 @@ func Test_Wireguard_addRoute(t *testing.T) {
 		})
 	}
 }
+
+func Test_hasIPv6Route(t *testing.T) {
+	t.Parallel()
+
+	ipv4Route := &net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)}
+	ipv6Route := &net.IPNet{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(32, 128)}
+
+	assert.False(t, hasIPv6Route(nil))
+	assert.False(t, hasIPv6Route([]*net.IPNet{ipv4Route}))
+	assert.True(t, hasIPv6Route([]*net.IPNet{ipv4Route, ipv6Route}))
+}
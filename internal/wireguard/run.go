@@ -86,8 +86,12 @@ func (w *Wireguard) Run(ctx context.Context, waitError chan<- error, ready chan<
 		return
 	}
 
+	settings := w.settings
+	settings.Peers = copyPeers(w.settings.Peers)
+	w.resolvePeerEndpoints(ctx, settings.Peers)
+
 	w.logger.Info("Connecting to " + w.settings.Endpoint.String())
-	err = configureDevice(client, w.settings)
+	err = configureDevice(client, settings)
 	if err != nil {
 		waitError <- fmt.Errorf("%w: %s", ErrConfigure, err)
 		return
@@ -101,29 +105,59 @@ func (w *Wireguard) Run(ctx context.Context, waitError chan<- error, ready chan<
 		return w.netlink.LinkSetDown(link)
 	})
 
-	err = w.addRoute(link, allIPv4(), w.settings.FirewallMark)
-	if err != nil {
-		waitError <- fmt.Errorf("%w: %s", ErrRouteAdd, err)
-		return
+	routes := w.settings.Routes
+	if len(routes) == 0 {
+		routes = []*net.IPNet{allIPv4()}
+	}
+	for _, route := range routes {
+		err = w.addRoute(link, route, w.settings.FirewallMark)
+		if err != nil {
+			waitError <- fmt.Errorf("%w: %s", ErrRouteAdd, err)
+			return
+		}
 	}
 
-	if *w.settings.IPv6 {
+	if *w.settings.IPv6 && len(w.settings.Routes) == 0 {
 		// requires net.ipv6.conf.all.disable_ipv6=0
 		err = w.setupIPv6(link, &closers)
 		if err != nil {
 			waitError <- fmt.Errorf("setting up IPv6: %w", err)
 			return
 		}
+	} else if hasIPv6Route(w.settings.Routes) && !w.settings.SkipRule {
+		// IPv6 routes are added to the table and need an
+		// IPv6 rule for traffic to be routed through it.
+		ruleCleanup6, err := w.addRule(w.settings.RulePriority,
+			w.settings.FirewallMark, unix.AF_INET6)
+		if err != nil {
+			waitError <- fmt.Errorf("adding IPv6 rule: %w", err)
+			return
+		}
+		closers.add("removing IPv6 rule", stepOne, ruleCleanup6)
 	}
 
-	ruleCleanup, err := w.addRule(w.settings.RulePriority,
-		w.settings.FirewallMark, unix.AF_INET)
-	if err != nil {
-		waitError <- fmt.Errorf("adding IPv4 rule: %w", err)
-		return
+	if !w.settings.SkipRule {
+		ruleCleanup, err := w.addRule(w.settings.RulePriority,
+			w.settings.FirewallMark, unix.AF_INET)
+		if err != nil {
+			waitError <- fmt.Errorf("adding IPv4 rule: %w", err)
+			return
+		}
+
+		closers.add("removing IPv4 rule", stepOne, ruleCleanup)
+	}
+
+	if w.settings.EndpointResolvePeriod > 0 && hasEndpointHostname(settings.Peers) {
+		watchCtx, watchCancel := context.WithCancel(ctx)
+		watchDone := make(chan struct{})
+		go w.watchPeerEndpoints(watchCtx, client, settings.Peers, watchDone)
+		closers.add("stopping peer endpoints resolution", stepZero, func() error {
+			watchCancel()
+			<-watchDone
+			return nil
+		})
 	}
 
-	closers.add("removing IPv4 rule", stepOne, ruleCleanup)
 	w.logger.Info("Wireguard is up")
 	ready <- struct{}{}
 
@@ -144,6 +178,10 @@ func (w *Wireguard) setupIPv6(link netlink.Link, closers *closers) (err error) {
 		return fmt.Errorf("%w: %s", ErrRouteAdd, err)
 	}
 
+	if w.settings.SkipRule {
+		return nil
+	}
+
 	ruleCleanup6, ruleErr := w.addRule(
 		w.settings.RulePriority, w.settings.FirewallMark,
 		unix.AF_INET6)
@@ -6,7 +6,9 @@ import (
 	"net"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/qdm12/gluetun/internal/constants"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 )
 
@@ -31,12 +33,52 @@ type Settings struct {
 	// RulePriority is the priority for the rule created with the
 	// FirewallMark.
 	RulePriority int
+	// SkipRule can be set to true to not create the rule routing
+	// the traffic not marked with the FirewallMark through the
+	// interface, for the caller to select the traffic to route
+	// through it with its own rules on the FirewallMark table.
+	SkipRule bool
 	// IPv6 can bet set to true if IPv6 should be handled.
 	// It defaults to false if left unset.
 	IPv6 *bool
 	// Implementation is the implementation to use.
 	// It can be auto, kernelspace or userspace, and defaults to auto.
 	Implementation string
+	// PersistentKeepaliveInterval is the keepalive interval for the
+	// server peer. It defaults to 0 which disables keepalive packets.
+	PersistentKeepaliveInterval time.Duration
+	// Peers are additional peers, each with their own allowed IPs.
+	Peers []Peer
+	// EndpointResolvePeriod is the period between each resolution
+	// of the peers endpoint hostnames, to follow IP address changes.
+	// It defaults to 0 which disables re-resolving them.
+	EndpointResolvePeriod time.Duration
+	// Routes are the destinations routed through the interface
+	// for traffic not marked with the FirewallMark. It defaults
+	// to all IP addresses if left empty, and IPv6 is not set up
+	// if it is set. This is used to route only the VPN server
+	// through an entry hop interface.
+	Routes []*net.IPNet
+}
+
+// Peer is an additional peer set on the Wireguard interface,
+// on top of the server peer which is allowed all IP addresses.
+type Peer struct {
+	// Public key in base 64 format
+	PublicKey string
+	// Pre shared key in base 64 format
+	PreSharedKey string
+	// Endpoint is the peer UDP address and can be left nil.
+	// Only its port needs to be set if EndpointHostname is set.
+	Endpoint *net.UDPAddr
+	// EndpointHostname is the hostname to resolve to obtain
+	// the endpoint IP address, and can be left empty.
+	EndpointHostname string
+	// AllowedIPs are the IP networks routed to the peer.
+	AllowedIPs []net.IPNet
+	// PersistentKeepaliveInterval is the keepalive interval for the
+	// peer, and 0 disables keepalive packets.
+	PersistentKeepaliveInterval time.Duration
 }
 
 func (s *Settings) SetDefaults() {
@@ -51,8 +93,7 @@ func (s *Settings) SetDefaults() {
 	}
 
 	if s.FirewallMark == 0 {
-		const defaultFirewallMark = 51820
-		s.FirewallMark = defaultFirewallMark
+		s.FirewallMark = constants.WireguardFirewallMark
 	}
 
 	if s.IPv6 == nil {
@@ -82,6 +123,10 @@ var (
 	ErrAddressMaskMissing    = errors.New("interface address mask is missing")
 	ErrFirewallMarkMissing   = errors.New("firewall mark is missing")
 	ErrImplementationInvalid = errors.New("invalid implementation")
+	ErrKeepaliveNegative     = errors.New("persistent keepalive interval is negative")
+	ErrAllowedIPsMissing     = errors.New("allowed IPs are missing")
+	ErrResolvePeriodNegative = errors.New("endpoint resolve period is negative")
+	ErrRouteNil              = errors.New("route is nil")
 )
 
 var interfaceNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
@@ -145,6 +190,65 @@ func (s *Settings) Check() (err error) {
 		return fmt.Errorf("%w: %s", ErrImplementationInvalid, s.Implementation)
 	}
 
+	if s.PersistentKeepaliveInterval < 0 {
+		return fmt.Errorf("%w: %s", ErrKeepaliveNegative, s.PersistentKeepaliveInterval)
+	}
+
+	if s.EndpointResolvePeriod < 0 {
+		return fmt.Errorf("%w: %s", ErrResolvePeriodNegative, s.EndpointResolvePeriod)
+	}
+
+	for i, route := range s.Routes {
+		if route == nil {
+			return fmt.Errorf("%w: for route %d of %d",
+				ErrRouteNil, i+1, len(s.Routes))
+		}
+	}
+
+	for i, peer := range s.Peers {
+		err = peer.check()
+		if err != nil {
+			return fmt.Errorf("for peer %d of %d: %w", i+1, len(s.Peers), err)
+		}
+	}
+
+	return nil
+}
+
+func (p Peer) check() (err error) {
+	if p.PublicKey == "" {
+		return fmt.Errorf("%w", ErrPublicKeyMissing)
+	} else if _, err := wgtypes.ParseKey(p.PublicKey); err != nil {
+		return fmt.Errorf("%w: %s", ErrPublicKeyInvalid, p.PublicKey)
+	}
+
+	if p.PreSharedKey != "" {
+		if _, err := wgtypes.ParseKey(p.PreSharedKey); err != nil {
+			return fmt.Errorf("%w", ErrPreSharedKeyInvalid)
+		}
+	}
+
+	if p.EndpointHostname != "" && p.Endpoint == nil {
+		return fmt.Errorf("%w: for hostname %s", ErrEndpointPortMissing, p.EndpointHostname)
+	}
+
+	if p.Endpoint != nil {
+		switch {
+		case len(p.Endpoint.IP) == 0 && p.EndpointHostname == "":
+			return fmt.Errorf("%w", ErrEndpointIPMissing)
+		case p.Endpoint.Port == 0:
+			return fmt.Errorf("%w", ErrEndpointPortMissing)
+		}
+	}
+
+	if len(p.AllowedIPs) == 0 {
+		return fmt.Errorf("%w", ErrAllowedIPsMissing)
+	}
+
+	if p.PersistentKeepaliveInterval < 0 {
+		return fmt.Errorf("%w: %s", ErrKeepaliveNegative, p.PersistentKeepaliveInterval)
+	}
+
 	return nil
 }
 
@@ -229,6 +333,28 @@ func (s Settings) ToLines(settings ToLinesSettings) (lines []string) {
 		lines = append(lines, fieldPrefix+"Implementation: "+s.Implementation)
 	}
 
+	if s.PersistentKeepaliveInterval > 0 {
+		lines = append(lines, fieldPrefix+"Persistent keepalive interval: "+
+			s.PersistentKeepaliveInterval.String())
+	}
+
+	for _, peer := range s.Peers {
+		allowedIPs := make([]string, len(peer.AllowedIPs))
+		for i, allowedIP := range peer.AllowedIPs {
+			allowedIPs[i] = allowedIP.String()
+		}
+		lines = append(lines, fieldPrefix+"Peer "+peer.PublicKey+
+			" allowed IPs: "+strings.Join(allowedIPs, ", "))
+	}
+
+	if len(s.Routes) > 0 {
+		routes := make([]string, len(s.Routes))
+		for i, route := range s.Routes {
+			routes[i] = route.String()
+		}
+		lines = append(lines, fieldPrefix+"Routes: "+strings.Join(routes, ", "))
+	}
+
 	if len(s.Addresses) == 0 {
 		lines = append(lines, lastFieldPrefix+"Addresses: "+notSet)
 	} else {
@@ -244,6 +244,22 @@ func Test_Settings_Check(t *testing.T) {
 			},
 			err: errors.New("invalid implementation: x"),
 		},
+		"nil route": {
+			settings: Settings{
+				InterfaceName: "wg0",
+				PrivateKey:    validKey1,
+				PublicKey:     validKey2,
+				Endpoint: &net.UDPAddr{
+					IP:   net.IPv4(1, 2, 3, 4),
+					Port: 51820,
+				},
+				Addresses:      []*net.IPNet{{IP: net.IPv4(1, 2, 3, 4), Mask: net.CIDRMask(24, 32)}},
+				FirewallMark:   999,
+				Implementation: "userspace",
+				Routes:         []*net.IPNet{nil},
+			},
+			err: errors.New("route is nil: for route 1 of 1"),
+		},
 		"all valid": {
 			settings: Settings{
 				InterfaceName: "wg0",